@@ -1,7 +1,20 @@
 // Package eks implements EKS related commands.
 package eks
 
-import "github.com/spf13/cobra"
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/aws/aws-k8s-tester/eksconfig"
+	pkg_aws "github.com/aws/aws-k8s-tester/pkg/aws"
+	aws_secrets "github.com/aws/aws-k8s-tester/pkg/aws/secrets"
+	"github.com/aws/aws-k8s-tester/pkg/fileutil"
+	"github.com/aws/aws-k8s-tester/pkg/logutil"
+	"github.com/aws/aws-sdk-go/service/secretsmanager"
+	"github.com/aws/aws-sdk-go/service/ssm"
+	"github.com/spf13/cobra"
+)
 
 func init() {
 	cobra.EnablePrefixMatching = true
@@ -11,6 +24,9 @@ var (
 	path         string
 	autoPath     bool
 	enablePrompt bool
+	specPath     string
+	strict       bool
+	preset       string
 )
 
 // NewCommand implements "aws-k8s-tester eks" command.
@@ -22,12 +38,83 @@ func NewCommand() *cobra.Command {
 	}
 	cmd.PersistentFlags().StringVarP(&path, "path", "p", "", "aws-k8s-tester EKS configuration file path")
 	cmd.PersistentFlags().BoolVarP(&autoPath, "auto-path", "a", false, "'true' to auto-generate path for create config/cluster, overwrites existing --path value")
+	cmd.PersistentFlags().StringVar(&specPath, "config", "", "aws-k8s-tester EKS YAML spec file path (never written; states go to --path, or <spec>.state.yaml if empty)")
+	cmd.PersistentFlags().BoolVar(&strict, "strict", false, "'true' to reject unknown or mistyped configuration fields and unknown AWS_K8S_TESTER_EKS_* environment variables")
+	cmd.PersistentFlags().StringVar(&preset, "preset", "", fmt.Sprintf("preset to pre-populate a new configuration %q (overrides $%s, fields still overridable by environment variables)", eksconfig.Presets(), eksconfig.AWS_K8S_TESTER_EKS_PRESET))
 	cmd.PersistentFlags().BoolVarP(&enablePrompt, "enable-prompt", "e", true, "'true' to enable prompt mode")
 	cmd.AddCommand(
 		newCreate(),
 		newDelete(),
 		newCheck(),
 		newList(),
+		newMigrate(),
+		newEffectiveConfig(),
+		newReap(),
 	)
 	return cmd
 }
+
+// validateStrict exits if the configuration file has unknown or
+// mistyped fields, only with "--strict".
+func validateStrict(p string) {
+	if !strict || p == "" || !fileutil.Exist(p) {
+		return
+	}
+	d, err := ioutil.ReadFile(p)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to read configuration %q (%v)\n", p, err)
+		os.Exit(1)
+	}
+	if err = eksconfig.ValidateStrict(d); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to validate configuration %q (%v)\n", p, err)
+		os.Exit(1)
+	}
+}
+
+// updateFromEnvs calls "UpdateFromEnvsStrict" with "--strict",
+// or "UpdateFromEnvs" otherwise.
+func updateFromEnvs(cfg *eksconfig.Config) error {
+	if strict {
+		return cfg.UpdateFromEnvsStrict()
+	}
+	return cfg.UpdateFromEnvs()
+}
+
+// resolveSecrets resolves the "ssm://" and "secretsmanager://" URIs
+// of the sensitive fields, if any. It must be called after "updateFromEnvs".
+func resolveSecrets(cfg *eksconfig.Config) error {
+	if !cfg.HasSecretURIs() {
+		return nil
+	}
+	lg, err := logutil.GetDefaultZapLogger()
+	if err != nil {
+		return err
+	}
+	ss, _, _, err := pkg_aws.New(&pkg_aws.Config{
+		Logger:    lg,
+		Partition: cfg.Partition,
+		Region:    cfg.Region,
+	})
+	if err != nil {
+		return err
+	}
+	rs := &aws_secrets.Resolver{
+		Logger:            lg,
+		SSMAPI:            ssm.New(ss),
+		SecretsManagerAPI: secretsmanager.New(ss),
+	}
+	return cfg.ResolveSecrets(rs.Resolve)
+}
+
+// applyPreset applies the "--preset" flag value, or
+// "AWS_K8S_TESTER_EKS_PRESET" if the flag is empty.
+// It must be called before "updateFromEnvs", and only for new
+// configurations, since specs and existing configurations are
+// already fully populated.
+func applyPreset(cfg *eksconfig.Config) error {
+	name := preset
+	if name == "" {
+		name = os.Getenv(eksconfig.AWS_K8S_TESTER_EKS_PRESET)
+	}
+	return cfg.ApplyPreset(name)
+}
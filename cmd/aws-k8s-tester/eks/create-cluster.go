@@ -27,14 +27,37 @@ func newCreateCluster() *cobra.Command {
 }
 
 func createClusterFunc(cmd *cobra.Command, args []string) {
-	if !autoPath && path == "" {
-		fmt.Fprintln(os.Stderr, "'--path' flag is not specified")
+	if !autoPath && path == "" && specPath == "" {
+		fmt.Fprintln(os.Stderr, "'--path' or '--config' flag is not specified")
 		os.Exit(1)
 	}
 
+	validateStrict(specPath)
+	validateStrict(path)
+
 	var cfg *eksconfig.Config
 	var err error
-	if !autoPath && fileutil.Exist(path) {
+	if specPath != "" {
+		cfg, err = eksconfig.LoadSpec(specPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to load spec %q (%v)\n", specPath, err)
+			os.Exit(1)
+		}
+		if path != "" {
+			cfg.ConfigPath = path
+		}
+		path = cfg.ConfigPath
+		if fileutil.Exist(path) {
+			// resume from the previous states (e.g. delete after create)
+			prev, perr := eksconfig.Load(path)
+			if perr != nil {
+				fmt.Fprintf(os.Stderr, "failed to load states %q (%v)\n", path, perr)
+				os.Exit(1)
+			}
+			cfg.Status = prev.Status
+		}
+		fmt.Fprintf(os.Stderr, "loaded spec %q; writing states to %q\n", specPath, path)
+	} else if !autoPath && fileutil.Exist(path) {
 		cfg, err = eksconfig.Load(path)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "failed to load configuration %q (%v)\n", path, err)
@@ -50,16 +73,24 @@ func createClusterFunc(cmd *cobra.Command, args []string) {
 			path = filepath.Join(os.TempDir(), cfg.Name+".yaml")
 		}
 		cfg.ConfigPath = path
+		if err = applyPreset(cfg); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to apply preset (%v)\n", err)
+			os.Exit(1)
+		}
 		fmt.Fprintf(os.Stderr, "cannot find configuration; wrote a new one %q\n", path)
 	}
 
 	fmt.Printf("\n*********************************\n")
 	fmt.Printf("overwriting config file from environment variables with %s\n", version.Version())
-	err = cfg.UpdateFromEnvs()
+	err = updateFromEnvs(cfg)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "failed to load configuration from environment variables: %v\n", err)
 		os.Exit(1)
 	}
+	if err = resolveSecrets(cfg); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to resolve secrets (%v)\n", err)
+		os.Exit(1)
+	}
 
 	if err = cfg.ValidateAndSetDefaults(); err != nil {
 		fmt.Fprintf(os.Stderr, "failed to validate configuration %q (%v)\n", path, err)
@@ -22,20 +22,39 @@ func newCreateConfig() *cobra.Command {
 }
 
 func createConfigFunc(cmd *cobra.Command, args []string) {
-	if !autoPath && path == "" {
-		fmt.Fprintln(os.Stderr, "'--path' flag is not specified")
+	if !autoPath && path == "" && specPath == "" {
+		fmt.Fprintln(os.Stderr, "'--path' or '--config' flag is not specified")
 		os.Exit(1)
 	}
 
+	validateStrict(specPath)
+
 	cfg := eksconfig.NewDefault()
-	if autoPath {
-		path = filepath.Join(os.TempDir(), cfg.Name+".yaml")
+	if specPath != "" {
+		var err error
+		cfg, err = eksconfig.LoadSpec(specPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to load spec %q (%v)\n", specPath, err)
+			os.Exit(1)
+		}
+		if path != "" {
+			cfg.ConfigPath = path
+		}
+		path = cfg.ConfigPath
+	} else {
+		if autoPath {
+			path = filepath.Join(os.TempDir(), cfg.Name+".yaml")
+		}
+		cfg.ConfigPath = path
+		if err := applyPreset(cfg); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to apply preset (%v)\n", err)
+			os.Exit(1)
+		}
 	}
-	cfg.ConfigPath = path
 
 	fmt.Printf("\n*********************************\n")
 	fmt.Printf("overwriting config file from environment variables with %s\n", version.Version())
-	err := cfg.UpdateFromEnvs()
+	err := updateFromEnvs(cfg)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "failed to load configuration from environment variables: %v", err)
 		os.Exit(1)
@@ -14,6 +14,7 @@ import (
 	"github.com/aws/aws-k8s-tester/pkg/fileutil"
 	k8s_client "github.com/aws/aws-k8s-tester/pkg/k8s-client"
 	"github.com/aws/aws-k8s-tester/pkg/logutil"
+	"github.com/aws/aws-k8s-tester/pkg/metrics"
 	"github.com/aws/aws-k8s-tester/pkg/randutil"
 	"github.com/aws/aws-sdk-go/service/s3"
 	"github.com/spf13/cobra"
@@ -38,6 +39,9 @@ var (
 	stresserNamespaceWrite string
 	stresserNamespacesRead []string
 
+	stresserHistogramBackend      string
+	stresserHDRSignificantFigures int
+
 	stresserRequestsRawWritesJSONS3Dir      string
 	stresserRequestsSummaryWritesJSONS3Dir  string
 	stresserRequestsSummaryWritesTableS3Dir string
@@ -68,6 +72,8 @@ func newCreateStresser() *cobra.Command {
 	cmd.PersistentFlags().DurationVar(&stresserDuration, "duration", 5*time.Minute, "duration to run cluster loader")
 	cmd.PersistentFlags().StringVar(&stresserNamespaceWrite, "namespace-write", "default", "namespaces to send writes")
 	cmd.PersistentFlags().StringSliceVar(&stresserNamespacesRead, "namespaces-read", []string{"default"}, "namespaces to send reads")
+	cmd.PersistentFlags().StringVar(&stresserHistogramBackend, "histogram-backend", metrics.HistogramBackendFixed, "latency histogram backend ('fixed' or 'hdr')")
+	cmd.PersistentFlags().IntVar(&stresserHDRSignificantFigures, "hdr-significant-figures", metrics.DefaultHDRSignificantFigures, "significant figures of 'hdr' latency histogram")
 
 	cmd.PersistentFlags().StringVar(&stresserRequestsRawWritesJSONS3Dir, "requests-raw-writes-json-s3-dir", "", "s3 directory prefix to upload")
 	cmd.PersistentFlags().StringVar(&stresserRequestsSummaryWritesJSONS3Dir, "requests-summary-writes-json-s3-dir", "", "s3 directory prefix to upload")
@@ -152,6 +158,8 @@ func createStresserFunc(cmd *cobra.Command, args []string) {
 		NamespacesRead:                  stresserNamespacesRead,
 		ObjectSize:                      stresserObjectSize,
 		ListLimit:                       stresserListLimit,
+		HistogramBackend:                stresserHistogramBackend,
+		HDRSignificantFigures:           stresserHDRSignificantFigures,
 		RequestsRawWritesJSONPath:       "/var/log/" + stresserWritesOutputNamePrefix + "-" + sfx + "-writes-raw.json",
 		RequestsRawWritesJSONS3Key:      filepath.Join(stresserRequestsRawWritesJSONS3Dir, stresserWritesOutputNamePrefix+"-"+sfx+"-writes-raw.json"),
 		RequestsSummaryWritesJSONPath:   "/var/log/" + stresserWritesOutputNamePrefix + "-" + sfx + "-writes-summary.json",
@@ -10,6 +10,7 @@ func newCreate() *cobra.Command {
 	ac.AddCommand(
 		newCreateConfig(),
 		newCreateCluster(),
+		newCreateFleet(),
 		newCreateCSRs(),
 		newCreateConfigMaps(),
 		newCreateSecrets(),
@@ -18,7 +18,7 @@ func newDelete() *cobra.Command {
 		Use:   "delete <subcommand>",
 		Short: "Delete commands",
 	}
-	ac.AddCommand(newDeleteCluster())
+	ac.AddCommand(newDeleteCluster(), newDeleteFleet())
 	return ac
 }
 
@@ -31,6 +31,9 @@ func newDeleteCluster() *cobra.Command {
 }
 
 func deleteClusterFunc(cmd *cobra.Command, args []string) {
+	if path == "" && specPath != "" {
+		path = eksconfig.SpecStatePath(specPath)
+	}
 	if !fileutil.Exist(path) {
 		fmt.Fprintf(os.Stderr, "cannot find configuration %q\n", path)
 		os.Exit(1)
@@ -0,0 +1,76 @@
+package eks
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/aws/aws-k8s-tester/eksconfig"
+	"github.com/aws/aws-k8s-tester/pkg/fileutil"
+	"github.com/spf13/cobra"
+)
+
+var effectiveConfigOutput string
+
+func newEffectiveConfig() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "effective-config",
+		Short: "Prints the eks configuration with all defaults and computed fields populated",
+		Long: `
+aws-k8s-tester eks effective-config --config cluster.yaml
+aws-k8s-tester eks effective-config --path /tmp/config.yaml --output json
+
+Loads the spec (--config), the existing configuration (--path), or
+a new default configuration, applies the preset and environment variables,
+and prints the result, without any AWS API call.
+`,
+		Run: effectiveConfigFunc,
+	}
+	cmd.PersistentFlags().StringVarP(&effectiveConfigOutput, "output", "o", "yaml", "output format, \"yaml\" or \"json\"")
+	return cmd
+}
+
+func effectiveConfigFunc(cmd *cobra.Command, args []string) {
+	validateStrict(specPath)
+
+	var cfg *eksconfig.Config
+	var err error
+	switch {
+	case specPath != "":
+		cfg, err = eksconfig.LoadSpec(specPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to load spec %q (%v)\n", specPath, err)
+			os.Exit(1)
+		}
+		if path != "" {
+			cfg.ConfigPath = path
+		}
+
+	case path != "" && fileutil.Exist(path):
+		validateStrict(path)
+		cfg, err = eksconfig.Load(path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to load configuration %q (%v)\n", path, err)
+			os.Exit(1)
+		}
+
+	default:
+		cfg = eksconfig.NewDefault()
+		cfg.ConfigPath = path
+		if err = applyPreset(cfg); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to apply preset (%v)\n", err)
+			os.Exit(1)
+		}
+	}
+
+	if err = updateFromEnvs(cfg); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to load configuration from environment variables: %v\n", err)
+		os.Exit(1)
+	}
+
+	d, err := cfg.Effective(effectiveConfigOutput)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to populate effective configuration (%v)\n", err)
+		os.Exit(1)
+	}
+	fmt.Println(string(d))
+}
@@ -0,0 +1,130 @@
+package eks
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/aws/aws-k8s-tester/eks/fleet"
+	"github.com/aws/aws-k8s-tester/eksconfig"
+	"github.com/aws/aws-k8s-tester/pkg/logutil"
+	"github.com/aws/aws-k8s-tester/version"
+	"github.com/manifoldco/promptui"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+var fleetManifestPath string
+
+func newCreateFleet() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "fleet",
+		Short: "Create eks clusters of a fleet manifest concurrently",
+		Long:  "Configuration values of each cluster are overwritten by environment variables, except the name, the region, and the add-ons in the manifest.",
+		Run:   createFleetFunc,
+	}
+	cmd.PersistentFlags().StringVar(&fleetManifestPath, "manifest", "", "aws-k8s-tester EKS fleet manifest file path")
+	return cmd
+}
+
+func newDeleteFleet() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "fleet",
+		Short: "Delete eks clusters of a fleet manifest concurrently",
+		Run:   deleteFleetFunc,
+	}
+	cmd.PersistentFlags().StringVar(&fleetManifestPath, "manifest", "", "aws-k8s-tester EKS fleet manifest file path")
+	return cmd
+}
+
+func createFleetFunc(cmd *cobra.Command, args []string) {
+	f := loadFleet()
+	fmt.Printf("\n*********************************\n")
+	fmt.Printf("creating fleet %q (%d clusters, parallelism %d) with %s\n", f.Name, len(f.Clusters), f.Parallelism, version.Version())
+
+	if !confirmFleet("create") {
+		return
+	}
+
+	update := func(cfg *eksconfig.Config) error {
+		if err := updateFromEnvs(cfg); err != nil {
+			return err
+		}
+		return resolveSecrets(cfg)
+	}
+	rs, err := fleet.Up(fleetLogger(), f, update)
+	printFleetResults("create", rs, f.ResultsPath, err)
+}
+
+func deleteFleetFunc(cmd *cobra.Command, args []string) {
+	f := loadFleet()
+	fmt.Printf("\n*********************************\n")
+	fmt.Printf("deleting fleet %q (%d clusters, parallelism %d)\n", f.Name, len(f.Clusters), f.Parallelism)
+
+	if !confirmFleet("delete") {
+		return
+	}
+
+	rs, err := fleet.Down(fleetLogger(), f)
+	printFleetResults("delete", rs, f.ResultsPath, err)
+}
+
+func loadFleet() *eksconfig.Fleet {
+	if fleetManifestPath == "" {
+		fmt.Fprintln(os.Stderr, "'--manifest' flag is not specified")
+		os.Exit(1)
+	}
+	f, err := eksconfig.LoadFleet(fleetManifestPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to load fleet manifest %q (%v)\n", fleetManifestPath, err)
+		os.Exit(1)
+	}
+	return f
+}
+
+func confirmFleet(op string) bool {
+	if !enablePrompt {
+		return true
+	}
+	prompt := promptui.Select{
+		Label: fmt.Sprintf("Ready to %s EKS resources of all clusters, should we continue?", op),
+		Items: []string{
+			"No, cancel it!",
+			fmt.Sprintf("Yes, let's %s!", op),
+		},
+	}
+	idx, answer, err := prompt.Run()
+	if err != nil {
+		panic(err)
+	}
+	if idx != 1 {
+		fmt.Printf("returning '%s' [index %d, answer %q]\n", op, idx, answer)
+		return false
+	}
+	return true
+}
+
+func fleetLogger() *zap.Logger {
+	lg, err := logutil.GetDefaultZapLogger()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to create logger (%v)\n", err)
+		os.Exit(1)
+	}
+	return lg
+}
+
+func printFleetResults(op string, rs []eksconfig.FleetResult, resultsPath string, err error) {
+	fmt.Printf("\n\n\n*********************************\n")
+	for _, r := range rs {
+		if r.Success {
+			fmt.Printf("%q (%s) SUCCESS [took %v, states %q]\n", r.Name, r.Region, r.Took, r.ConfigPath)
+		} else {
+			fmt.Printf("%q (%s) FAIL [took %v, states %q] (%s)\n", r.Name, r.Region, r.Took, r.ConfigPath, r.Error)
+		}
+	}
+	fmt.Printf("\nresults written to %q\n", resultsPath)
+	if err != nil {
+		fmt.Printf("aws-k8s-tester eks %s fleet FAIL (%v)\n", op, err)
+		os.Exit(1)
+	}
+	fmt.Printf("aws-k8s-tester eks %s fleet SUCCESS\n", op)
+}
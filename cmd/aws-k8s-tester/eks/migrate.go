@@ -0,0 +1,59 @@
+package eks
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/aws/aws-k8s-tester/eksconfig"
+	"github.com/aws/aws-k8s-tester/pkg/fileutil"
+	"github.com/spf13/cobra"
+)
+
+func newMigrate() *cobra.Command {
+	return &cobra.Command{
+		Use:   "migrate",
+		Short: "Migrate eks configuration from an older version to the current field layout",
+		Long: `
+aws-k8s-tester eks migrate --path /tmp/config.yaml
+
+The original configuration is backed up to "<path>.bak".
+`,
+		Run: migrateFunc,
+	}
+}
+
+func migrateFunc(cmd *cobra.Command, args []string) {
+	if path == "" && specPath != "" {
+		path = eksconfig.SpecStatePath(specPath)
+	}
+	if !fileutil.Exist(path) {
+		fmt.Fprintf(os.Stderr, "cannot find configuration %q\n", path)
+		os.Exit(1)
+	}
+
+	d, err := ioutil.ReadFile(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to read configuration %q (%v)\n", path, err)
+		os.Exit(1)
+	}
+	cfg, err := eksconfig.Migrate(d)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to migrate configuration %q (%v)\n", path, err)
+		os.Exit(1)
+	}
+
+	bak := path + ".bak"
+	if err = fileutil.Copy(path, bak); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to back up configuration %q (%v)\n", path, err)
+		os.Exit(1)
+	}
+	cfg.ConfigPath = path
+	if err = cfg.Sync(); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to write configuration %q (%v)\n", path, err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("\n*********************************\n")
+	fmt.Printf("'aws-k8s-tester eks migrate --path %q' success (backed up to %q)\n", path, bak)
+}
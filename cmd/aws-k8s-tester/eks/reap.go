@@ -0,0 +1,118 @@
+package eks
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/aws/aws-k8s-tester/eks/reaper"
+	pkg_aws "github.com/aws/aws-k8s-tester/pkg/aws"
+	"github.com/aws/aws-k8s-tester/pkg/logutil"
+	"github.com/aws/aws-sdk-go/service/autoscaling"
+	"github.com/aws/aws-sdk-go/service/cloudformation"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	aws_eks "github.com/aws/aws-sdk-go/service/eks"
+	"github.com/aws/aws-sdk-go/service/elbv2"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/manifoldco/promptui"
+	"github.com/spf13/cobra"
+)
+
+var (
+	reapPartition    string
+	reapRegion       string
+	reapAge          time.Duration
+	reapDryRun       bool
+	reapS3BucketName string
+)
+
+func newReap() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "reap",
+		Short: "Delete orphaned resources created by aws-k8s-tester",
+		Long: "Scans the region for clusters, CloudFormation stacks, ASGs, ELBv2, ENIs, and S3 prefixes tagged by aws-k8s-tester " +
+			"and older than '--age' (e.g. left behind by crashed CI runs), and deletes them. " +
+			"Resources created for a reaped cluster (e.g. load balancers, ENIs) are deleted regardless of the age.",
+		Run: reapFunc,
+	}
+	cmd.PersistentFlags().StringVar(&reapPartition, "partition", "aws", "AWS partition")
+	cmd.PersistentFlags().StringVar(&reapRegion, "region", "us-west-2", "AWS region")
+	cmd.PersistentFlags().DurationVar(&reapAge, "age", 24*time.Hour, "Minimum age of the resources to delete")
+	cmd.PersistentFlags().BoolVar(&reapDryRun, "dry-run", true, "'true' to only list the resources to delete")
+	cmd.PersistentFlags().StringVar(&reapS3BucketName, "s3-bucket-name", "", "aws-k8s-tester S3 bucket name to delete the prefixes of reaped clusters (skipped if empty)")
+	return cmd
+}
+
+func reapFunc(cmd *cobra.Command, args []string) {
+	if reapAge <= 0 {
+		fmt.Fprintf(os.Stderr, "invalid '--age' %v\n", reapAge)
+		os.Exit(1)
+	}
+	lg, err := logutil.GetDefaultZapLogger()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to create logger (%v)\n", err)
+		os.Exit(1)
+	}
+	ss, _, _, err := pkg_aws.New(&pkg_aws.Config{
+		Logger:    lg,
+		Partition: reapPartition,
+		Region:    reapRegion,
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to create AWS session (%v)\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("\n*********************************\n")
+	fmt.Printf("reaping resources in %q older than %v (dry-run %v)\n", reapRegion, reapAge, reapDryRun)
+	if !reapDryRun && enablePrompt {
+		prompt := promptui.Select{
+			Label: "Ready to delete orphaned resources, should we continue?",
+			Items: []string{
+				"No, cancel it!",
+				"Yes, let's delete!",
+			},
+		}
+		idx, answer, err := prompt.Run()
+		if err != nil {
+			panic(err)
+		}
+		if idx != 1 {
+			fmt.Printf("returning 'reap' [index %d, answer %q]\n", idx, answer)
+			return
+		}
+	}
+
+	cfg := reaper.Config{
+		Logger:   lg,
+		Age:      reapAge,
+		DryRun:   reapDryRun,
+		EKSAPI:   aws_eks.New(ss),
+		CFNAPI:   cloudformation.New(ss),
+		ASGAPI:   autoscaling.New(ss),
+		ELBV2API: elbv2.New(ss),
+		EC2API:   ec2.New(ss),
+	}
+	if reapS3BucketName != "" {
+		cfg.S3BucketName = reapS3BucketName
+		cfg.S3API = s3.New(ss)
+	}
+	rs, err := reaper.New(cfg).Reap()
+
+	fmt.Printf("\n\n\n*********************************\n")
+	for _, r := range rs {
+		status := "FOUND"
+		switch {
+		case r.Error != "":
+			status = "FAIL"
+		case r.Deleted:
+			status = "DELETED"
+		}
+		fmt.Printf("[%s] %s %q (cluster %q, %s) %s\n", status, r.Type, r.ID, r.Cluster, r.Reason, r.Error)
+	}
+	if err != nil {
+		fmt.Printf("aws-k8s-tester eks reap FAIL (%v)\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("aws-k8s-tester eks reap SUCCESS (%d resources)\n", len(rs))
+}
@@ -397,7 +397,7 @@ func (ts *Tester) fetchImageID(ssmParam string) (img string, err error) {
 // MUST install SSM agent, otherwise, it will "InvalidInstanceId:"
 // ref. https://docs.aws.amazon.com/systems-manager/latest/userguide/agent-install-al2.html
 func (ts *Tester) generateUserData(region string, amiType string) (d string, err error) {
-	if amiType == ec2config.AMITypeBottleRocketCPU {
+	if ec2config.IsBottlerocket(amiType) {
 		// BottleRocket comes with SSM agent
 		return "", nil
 	}
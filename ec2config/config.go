@@ -26,6 +26,8 @@ const (
 	// AMITypeBottleRocketCPU is the AMI type for Bottlerocket OS.
 	// https://github.com/bottlerocket-os/bottlerocket
 	AMITypeBottleRocketCPU = "BOTTLEROCKET_x86_64"
+	// AMITypeBottleRocketARM64 is the AMI type for Bottlerocket OS on ARM.
+	AMITypeBottleRocketARM64 = "BOTTLEROCKET_ARM_64"
 	// AMITypeAL2X8664 is the AMI type for Amazon Linux 2 AMI.
 	AMITypeAL2X8664 = "AL2_x86_64"
 	// AMITypeAL2X8664GPU is the AMI type for Amazon Linux 2 AMI with GPU.
@@ -53,6 +55,17 @@ const (
 	ASGMaxLimit = 100
 )
 
+// IsBottlerocket returns true if the AMI type is Bottlerocket OS,
+// which is configured with TOML user data and has no shell on the host.
+// ref. https://github.com/bottlerocket-os/bottlerocket
+func IsBottlerocket(amiType string) bool {
+	switch amiType {
+	case AMITypeBottleRocketCPU, AMITypeBottleRocketARM64:
+		return true
+	}
+	return false
+}
+
 // Config defines EC2 configuration.
 type Config struct {
 	mu *sync.RWMutex
@@ -13,4 +13,10 @@ func init() {
 	if AMITypeAL2X8664GPU != eks.AMITypesAl2X8664Gpu {
 		panic(fmt.Errorf("ec2config.AMITypeAL2X8664GPU %q != eks.AMITypesAl2X8664Gpu %q", AMITypeAL2X8664GPU, eks.AMITypesAl2X8664Gpu))
 	}
+	if AMITypeBottleRocketCPU != eks.AMITypesBottlerocketX8664 {
+		panic(fmt.Errorf("ec2config.AMITypeBottleRocketCPU %q != eks.AMITypesBottlerocketX8664 %q", AMITypeBottleRocketCPU, eks.AMITypesBottlerocketX8664))
+	}
+	if AMITypeBottleRocketARM64 != eks.AMITypesBottlerocketArm64 {
+		panic(fmt.Errorf("ec2config.AMITypeBottleRocketARM64 %q != eks.AMITypesBottlerocketArm64 %q", AMITypeBottleRocketARM64, eks.AMITypesBottlerocketArm64))
+	}
 }
@@ -337,7 +337,7 @@ func (cfg *Config) validateASGs() error {
 			if cur.RemoteAccessUserName != "ec2-user" {
 				return fmt.Errorf("AMIType %q but unexpected RemoteAccessUserName %q", cur.AMIType, cur.RemoteAccessUserName)
 			}
-		case AMITypeBottleRocketCPU:
+		case AMITypeBottleRocketCPU, AMITypeBottleRocketARM64:
 			if cur.RemoteAccessUserName != "ec2-user" {
 				return fmt.Errorf("AMIType %q but unexpected RemoteAccessUserName %q", cur.AMIType, cur.RemoteAccessUserName)
 			}
@@ -355,7 +355,7 @@ func (cfg *Config) validateASGs() error {
 		}
 
 		switch cur.AMIType {
-		case AMITypeAL2ARM64:
+		case AMITypeAL2ARM64, AMITypeBottleRocketARM64:
 			if cur.InstanceType == "" {
 				cur.InstanceType = DefaultNodeInstanceTypeCPUARM
 			}
@@ -0,0 +1,399 @@
+// Package accessentries implements tester for EKS access entries, which maps
+// a test IAM role to an access policy and asserts kubectl access works with the role.
+// It also manages the access entries of the tester caller identity and the extra
+// principals, and validates that every principal (including node roles) can list nodes.
+// ref. https://docs.aws.amazon.com/eks/latest/userguide/access-entries.html
+package accessentries
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	eks_tester "github.com/aws/aws-k8s-tester/eks/tester"
+	"github.com/aws/aws-k8s-tester/eksconfig"
+	k8s_client "github.com/aws/aws-k8s-tester/pkg/k8s-client"
+	"github.com/aws/aws-k8s-tester/pkg/timeutil"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/eks/eksiface"
+	"github.com/aws/aws-sdk-go/service/iam"
+	"github.com/aws/aws-sdk-go/service/iam/iamiface"
+	"go.uber.org/zap"
+	"k8s.io/utils/exec"
+)
+
+// Config defines access entries tester configuration.
+type Config struct {
+	Logger    *zap.Logger
+	LogWriter io.Writer
+	Stopc     chan struct{}
+	EKSConfig *eksconfig.Config
+	K8SClient k8s_client.EKS
+	EKSAPI    eksiface.EKSAPI
+	IAMAPI    iamiface.IAMAPI
+}
+
+var pkgName = reflect.TypeOf(tester{}).PkgPath()
+
+func (ts *tester) Name() string { return pkgName }
+
+// New creates a new access entries tester.
+func New(cfg Config) eks_tester.Tester {
+	cfg.Logger.Info("creating tester", zap.String("tester", pkgName))
+	return &tester{cfg: cfg}
+}
+
+type tester struct {
+	cfg Config
+}
+
+func (ts *tester) Create() error {
+	if !ts.cfg.EKSConfig.IsEnabledAddOnAccessEntries() {
+		ts.cfg.Logger.Info("skipping tester.Create", zap.String("tester", pkgName))
+		return nil
+	}
+	if ts.cfg.EKSConfig.AddOnAccessEntries.Created {
+		ts.cfg.Logger.Info("skipping tester.Create", zap.String("tester", pkgName))
+		return nil
+	}
+
+	ts.cfg.Logger.Info("starting tester.Create", zap.String("tester", pkgName))
+	ts.cfg.EKSConfig.AddOnAccessEntries.Created = true
+	ts.cfg.EKSConfig.Sync()
+	createStart := time.Now()
+	defer func() {
+		createEnd := time.Now()
+		ts.cfg.EKSConfig.AddOnAccessEntries.TimeFrameCreate = timeutil.NewTimeFrame(createStart, createEnd)
+		ts.cfg.EKSConfig.Sync()
+	}()
+
+	if err := ts.createRole(); err != nil {
+		return err
+	}
+	if err := ts.createAccessEntry(); err != nil {
+		return err
+	}
+	if err := ts.createPrincipalEntries(); err != nil {
+		return err
+	}
+	if err := ts.writeKubeConfig(); err != nil {
+		return err
+	}
+	if err := ts.checkAccess(); err != nil {
+		return err
+	}
+	if err := ts.checkListNodes(); err != nil {
+		return err
+	}
+	ts.cfg.EKSConfig.Sync()
+	return nil
+}
+
+func (ts *tester) Delete() error {
+	if !ts.cfg.EKSConfig.IsEnabledAddOnAccessEntries() {
+		ts.cfg.Logger.Info("skipping tester.Delete", zap.String("tester", pkgName))
+		return nil
+	}
+	if !ts.cfg.EKSConfig.AddOnAccessEntries.Created {
+		ts.cfg.Logger.Info("skipping tester.Delete", zap.String("tester", pkgName))
+		return nil
+	}
+
+	ts.cfg.Logger.Info("starting tester.Delete", zap.String("tester", pkgName))
+	deleteStart := time.Now()
+	defer func() {
+		deleteEnd := time.Now()
+		ts.cfg.EKSConfig.AddOnAccessEntries.TimeFrameDelete = timeutil.NewTimeFrame(deleteStart, deleteEnd)
+		ts.cfg.EKSConfig.Sync()
+	}()
+
+	var errs []string
+
+	if err := ts.deleteAccessEntry(); err != nil {
+		errs = append(errs, fmt.Sprintf("failed to delete access entry (%v)", err))
+	}
+	for principalARN := range ts.cfg.EKSConfig.AddOnAccessEntries.ExtraPrincipals {
+		if err := DeleteEntry(ts.cfg.Logger, ts.cfg.EKSAPI, ts.cfg.EKSConfig.Name, principalARN); err != nil {
+			errs = append(errs, fmt.Sprintf("failed to delete access entry for %q (%v)", principalARN, err))
+		}
+	}
+	if err := ts.deleteRole(); err != nil {
+		errs = append(errs, fmt.Sprintf("failed to delete role (%v)", err))
+	}
+	if err := os.RemoveAll(ts.cfg.EKSConfig.AddOnAccessEntries.KubeConfigPath); err != nil {
+		errs = append(errs, fmt.Sprintf("failed to delete KUBECONFIG (%v)", err))
+	}
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, ", "))
+	}
+
+	ts.cfg.EKSConfig.AddOnAccessEntries.Created = false
+	ts.cfg.EKSConfig.Sync()
+	return nil
+}
+
+const roleAssumePolicyTempl = `{
+  "Version": "2012-10-17",
+  "Statement": [
+    {
+      "Effect": "Allow",
+      "Principal": {
+        "AWS": "arn:%s:iam::%s:root"
+      },
+      "Action": "sts:AssumeRole"
+    }
+  ]
+}`
+
+func (ts *tester) createRole() error {
+	if ts.cfg.EKSConfig.Status.AWSAccountID == "" {
+		return errors.New("empty Status.AWSAccountID")
+	}
+	roleName := ts.cfg.EKSConfig.AddOnAccessEntries.RoleName
+	ts.cfg.Logger.Info("creating test role", zap.String("role-name", roleName))
+
+	tags := []*iam.Tag{{Key: aws.String("Kind"), Value: aws.String("aws-k8s-tester")}}
+	for k, v := range ts.cfg.EKSConfig.Tags {
+		tags = append(tags, &iam.Tag{Key: aws.String(k), Value: aws.String(v)})
+	}
+	input := &iam.CreateRoleInput{
+		RoleName:                 aws.String(roleName),
+		Path:                     aws.String(ts.cfg.EKSConfig.IAMPath),
+		AssumeRolePolicyDocument: aws.String(fmt.Sprintf(roleAssumePolicyTempl, ts.cfg.EKSConfig.Partition, ts.cfg.EKSConfig.Status.AWSAccountID)),
+		Description:              aws.String("aws-k8s-tester access entries test role"),
+		Tags:                     tags,
+	}
+	if ts.cfg.EKSConfig.IAMPermissionsBoundaryARN != "" {
+		input.PermissionsBoundary = aws.String(ts.cfg.EKSConfig.IAMPermissionsBoundaryARN)
+	}
+	out, err := ts.cfg.IAMAPI.CreateRole(input)
+	if err != nil {
+		aerr, ok := err.(awserr.Error)
+		if !ok || aerr.Code() != iam.ErrCodeEntityAlreadyExistsException {
+			return fmt.Errorf("failed to create role %q (%v)", roleName, err)
+		}
+		gout, gerr := ts.cfg.IAMAPI.GetRole(&iam.GetRoleInput{RoleName: aws.String(roleName)})
+		if gerr != nil {
+			return fmt.Errorf("failed to get role %q (%v)", roleName, gerr)
+		}
+		ts.cfg.EKSConfig.AddOnAccessEntries.RoleARN = aws.StringValue(gout.Role.Arn)
+	} else {
+		ts.cfg.EKSConfig.AddOnAccessEntries.RoleARN = aws.StringValue(out.Role.Arn)
+	}
+
+	ts.cfg.Logger.Info("created test role", zap.String("role-arn", ts.cfg.EKSConfig.AddOnAccessEntries.RoleARN))
+	ts.cfg.EKSConfig.Sync()
+	return nil
+}
+
+func (ts *tester) deleteRole() error {
+	roleName := ts.cfg.EKSConfig.AddOnAccessEntries.RoleName
+	ts.cfg.Logger.Info("deleting test role", zap.String("role-name", roleName))
+	_, err := ts.cfg.IAMAPI.DeleteRole(&iam.DeleteRoleInput{RoleName: aws.String(roleName)})
+	if err != nil {
+		if aerr, ok := err.(awserr.Error); ok && aerr.Code() == iam.ErrCodeNoSuchEntityException {
+			ts.cfg.Logger.Info("test role already deleted")
+			return nil
+		}
+		return err
+	}
+	ts.cfg.Logger.Info("deleted test role")
+	return nil
+}
+
+func (ts *tester) createAccessEntry() error {
+	return CreateEntry(ts.cfg.Logger, ts.cfg.EKSAPI, ts.cfg.EKSConfig.Name, Entry{
+		PrincipalARN: ts.cfg.EKSConfig.AddOnAccessEntries.RoleARN,
+		Type:         EntryTypeStandard,
+		PolicyARNs:   []string{ts.cfg.EKSConfig.AddOnAccessEntries.AccessPolicyARN},
+	})
+}
+
+func (ts *tester) deleteAccessEntry() error {
+	roleARN := ts.cfg.EKSConfig.AddOnAccessEntries.RoleARN
+	if roleARN == "" {
+		return nil
+	}
+	return DeleteEntry(ts.cfg.Logger, ts.cfg.EKSAPI, ts.cfg.EKSConfig.Name, roleARN)
+}
+
+// createPrincipalEntries creates the access entries of the tester caller
+// identity and the extra principals.
+func (ts *tester) createPrincipalEntries() error {
+	if ts.cfg.EKSConfig.AddOnAccessEntries.CallerIdentity {
+		principalARN, err := PrincipalARN(ts.cfg.EKSConfig.Status.AWSIAMRoleARN)
+		if err != nil {
+			return err
+		}
+		if err = CreateEntry(ts.cfg.Logger, ts.cfg.EKSAPI, ts.cfg.EKSConfig.Name, Entry{
+			PrincipalARN: principalARN,
+			Type:         EntryTypeStandard,
+			PolicyARNs:   []string{ts.cfg.EKSConfig.AddOnAccessEntries.CallerIdentityAccessPolicyARN},
+		}); err != nil {
+			return err
+		}
+	}
+	for principalARN, policyARN := range ts.cfg.EKSConfig.AddOnAccessEntries.ExtraPrincipals {
+		if err := CreateEntry(ts.cfg.Logger, ts.cfg.EKSAPI, ts.cfg.EKSConfig.Name, Entry{
+			PrincipalARN: principalARN,
+			Type:         EntryTypeStandard,
+			PolicyARNs:   []string{policyARN},
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkListNodes validates that every principal with an access entry
+// can list nodes, including the node roles, and records the principals
+// in "AddOnAccessEntries.Principals".
+func (ts *tester) checkListNodes() error {
+	principals := []string{ts.cfg.EKSConfig.AddOnAccessEntries.RoleARN}
+	if ts.cfg.EKSConfig.AddOnAccessEntries.CallerIdentity {
+		principalARN, err := PrincipalARN(ts.cfg.EKSConfig.Status.AWSIAMRoleARN)
+		if err != nil {
+			return err
+		}
+		principals = append(principals, principalARN)
+	}
+	extras := make([]string, 0, len(ts.cfg.EKSConfig.AddOnAccessEntries.ExtraPrincipals))
+	for principalARN := range ts.cfg.EKSConfig.AddOnAccessEntries.ExtraPrincipals {
+		extras = append(extras, principalARN)
+	}
+	sort.Strings(extras)
+	principals = append(principals, extras...)
+
+	// node roles are registered by the node groups, or by EKS
+	// for managed node groups and EKS Auto Mode
+	var nodeRoles []string
+	if ts.cfg.EKSConfig.IsEnabledAddOnNodeGroups() && ts.cfg.EKSConfig.AddOnNodeGroups.Role.ARN != "" {
+		nodeRoles = append(nodeRoles, ts.cfg.EKSConfig.AddOnNodeGroups.Role.ARN)
+	}
+	if ts.cfg.EKSConfig.IsEnabledAddOnManagedNodeGroups() && ts.cfg.EKSConfig.AddOnManagedNodeGroups.Role.ARN != "" {
+		nodeRoles = append(nodeRoles, ts.cfg.EKSConfig.AddOnManagedNodeGroups.Role.ARN)
+	}
+	if ts.cfg.EKSConfig.IsEnabledAutoMode() {
+		nodeRoles = append(nodeRoles, ts.cfg.EKSConfig.AutoModeNodeRoleARN())
+	}
+	nodeName := ""
+	if len(nodeRoles) > 0 {
+		nodes, err := ts.cfg.K8SClient.ListNodes(1000, 5*time.Second)
+		if err != nil {
+			return fmt.Errorf("failed to list nodes (%v)", err)
+		}
+		if len(nodes) == 0 {
+			return fmt.Errorf("no node to check node roles %q", nodeRoles)
+		}
+		nodeName = nodes[0].Name
+	}
+
+	principals = append(principals, nodeRoles...)
+	cli := ts.cfg.K8SClient.KubernetesClientSet()
+	var errs []string
+	for _, principalARN := range principals {
+		if err := CheckListNodes(ts.cfg.Logger, ts.cfg.EKSAPI, cli, ts.cfg.EKSConfig.Name, principalARN, nodeName); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	ts.cfg.EKSConfig.AddOnAccessEntries.Principals = principals
+	ts.cfg.EKSConfig.Sync()
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, ", "))
+	}
+	return nil
+}
+
+// https://docs.aws.amazon.com/cli/latest/reference/eks/update-kubeconfig.html
+func (ts *tester) writeKubeConfig() error {
+	args := []string{
+		ts.cfg.EKSConfig.AWSCLIPath,
+		"eks",
+		fmt.Sprintf("--region=%s", ts.cfg.EKSConfig.Region),
+		"update-kubeconfig",
+		fmt.Sprintf("--name=%s", ts.cfg.EKSConfig.Name),
+		fmt.Sprintf("--kubeconfig=%s", ts.cfg.EKSConfig.AddOnAccessEntries.KubeConfigPath),
+		fmt.Sprintf("--role-arn=%s", ts.cfg.EKSConfig.AddOnAccessEntries.RoleARN),
+	}
+	if ts.cfg.EKSConfig.ResolverURL != "" {
+		args = append(args, fmt.Sprintf("--endpoint=%s", ts.cfg.EKSConfig.ResolverURL))
+	}
+	cmd := strings.Join(args, " ")
+	ts.cfg.Logger.Info("writing KUBECONFIG for test role", zap.String("cmd", cmd))
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	output, err := exec.New().CommandContext(ctx, args[0], args[1:]...).CombinedOutput()
+	cancel()
+	fmt.Fprintf(ts.cfg.LogWriter, "\n'%s' output:\n\n%s\n\n", cmd, string(output))
+	if err != nil {
+		return fmt.Errorf("'aws eks update-kubeconfig' failed (output %q, error %v)", string(output), err)
+	}
+	ts.cfg.Logger.Info("wrote KUBECONFIG for test role", zap.String("kubeconfig-path", ts.cfg.EKSConfig.AddOnAccessEntries.KubeConfigPath))
+	return nil
+}
+
+// checkAccess asserts the test role can list namespaces,
+// retrying while the IAM role and access entry propagate.
+func (ts *tester) checkAccess() error {
+	args := []string{
+		ts.cfg.EKSConfig.KubectlPath,
+		"--kubeconfig=" + ts.cfg.EKSConfig.AddOnAccessEntries.KubeConfigPath,
+		"get",
+		"namespaces",
+	}
+	cmd := strings.Join(args, " ")
+
+	var output []byte
+	var err error
+	waitDur := 5 * time.Minute
+	retryStart := time.Now()
+	for time.Since(retryStart) < waitDur {
+		select {
+		case <-ts.cfg.Stopc:
+			return errors.New("check access aborted")
+		case <-time.After(10 * time.Second):
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		output, err = exec.New().CommandContext(ctx, args[0], args[1:]...).CombinedOutput()
+		cancel()
+		fmt.Fprintf(ts.cfg.LogWriter, "\n'%s' output:\n\n%s\n\n", cmd, string(output))
+		if err == nil {
+			break
+		}
+		ts.cfg.Logger.Warn("kubectl access failed with test role; retrying", zap.Error(err))
+	}
+	if err != nil {
+		return fmt.Errorf("'%s' failed with test role (output %q, error %v)", cmd, string(output), err)
+	}
+	ts.cfg.Logger.Info("kubectl access succeeded with test role")
+
+	// view policy must not grant write access
+	if strings.HasSuffix(ts.cfg.EKSConfig.AddOnAccessEntries.AccessPolicyARN, "/AmazonEKSViewPolicy") {
+		args = []string{
+			ts.cfg.EKSConfig.KubectlPath,
+			"--kubeconfig=" + ts.cfg.EKSConfig.AddOnAccessEntries.KubeConfigPath,
+			"auth",
+			"can-i",
+			"create",
+			"namespaces",
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		// exits non-zero for "no"
+		output, _ = exec.New().CommandContext(ctx, args[0], args[1:]...).CombinedOutput()
+		cancel()
+		out := strings.TrimSpace(string(output))
+		fmt.Fprintf(ts.cfg.LogWriter, "\n'%s' output:\n\n%s\n\n", strings.Join(args, " "), out)
+		if out != "no" {
+			return fmt.Errorf("unexpected write access with %q (output %q)", ts.cfg.EKSConfig.AddOnAccessEntries.AccessPolicyARN, out)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,217 @@
+package accessentries
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/arn"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	aws_eks "github.com/aws/aws-sdk-go/service/eks"
+	"github.com/aws/aws-sdk-go/service/eks/eksiface"
+	"go.uber.org/zap"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// Access entry types.
+// ref. https://docs.aws.amazon.com/eks/latest/APIReference/API_CreateAccessEntry.html
+const (
+	EntryTypeStandard   = "STANDARD"
+	EntryTypeEC2Linux   = "EC2_LINUX"
+	EntryTypeEC2Windows = "EC2_WINDOWS"
+)
+
+// Entry is the EKS access entry of an IAM principal.
+type Entry struct {
+	// PrincipalARN is the IAM role or user ARN.
+	PrincipalARN string
+	// Type is the access entry type (e.g. "STANDARD", "EC2_LINUX").
+	Type string
+	// PolicyARNs are the access policies to associate with the cluster scope.
+	// Must be empty for the EC2 types.
+	PolicyARNs []string
+}
+
+// CreateEntry creates the access entry and associates its access policies.
+// Existing access entries are reused (e.g. the cluster creator entry).
+func CreateEntry(lg *zap.Logger, eksAPI eksiface.EKSAPI, clusterName string, e Entry) error {
+	if e.PrincipalARN == "" {
+		return errors.New("empty principal ARN")
+	}
+	if e.Type != EntryTypeStandard && len(e.PolicyARNs) > 0 {
+		return fmt.Errorf("access entry type %q does not support access policies %q", e.Type, e.PolicyARNs)
+	}
+	lg.Info("creating access entry",
+		zap.String("principal-arn", e.PrincipalARN),
+		zap.String("type", e.Type),
+	)
+	_, err := eksAPI.CreateAccessEntry(&aws_eks.CreateAccessEntryInput{
+		ClusterName:  aws.String(clusterName),
+		PrincipalArn: aws.String(e.PrincipalARN),
+		Type:         aws.String(e.Type),
+	})
+	if err != nil {
+		aerr, ok := err.(awserr.Error)
+		if !ok || aerr.Code() != aws_eks.ErrCodeResourceInUseException {
+			return fmt.Errorf("failed to create access entry for %q (%v)", e.PrincipalARN, err)
+		}
+		lg.Info("access entry already exists", zap.String("principal-arn", e.PrincipalARN))
+	}
+
+	for _, policyARN := range e.PolicyARNs {
+		lg.Info("associating access policy",
+			zap.String("principal-arn", e.PrincipalARN),
+			zap.String("policy-arn", policyARN),
+		)
+		_, err = eksAPI.AssociateAccessPolicy(&aws_eks.AssociateAccessPolicyInput{
+			ClusterName:  aws.String(clusterName),
+			PrincipalArn: aws.String(e.PrincipalARN),
+			PolicyArn:    aws.String(policyARN),
+			AccessScope: &aws_eks.AccessScope{
+				Type: aws.String(aws_eks.AccessScopeTypeCluster),
+			},
+		})
+		if err != nil {
+			return fmt.Errorf("failed to associate access policy %q for %q (%v)", policyARN, e.PrincipalARN, err)
+		}
+	}
+	lg.Info("created access entry", zap.String("principal-arn", e.PrincipalARN))
+	return nil
+}
+
+// DeleteEntry deletes the access entry of the principal,
+// which also disassociates its access policies.
+func DeleteEntry(lg *zap.Logger, eksAPI eksiface.EKSAPI, clusterName string, principalARN string) error {
+	lg.Info("deleting access entry", zap.String("principal-arn", principalARN))
+	_, err := eksAPI.DeleteAccessEntry(&aws_eks.DeleteAccessEntryInput{
+		ClusterName:  aws.String(clusterName),
+		PrincipalArn: aws.String(principalARN),
+	})
+	if err != nil {
+		if aerr, ok := err.(awserr.Error); ok && aerr.Code() == aws_eks.ErrCodeResourceNotFoundException {
+			lg.Info("access entry already deleted", zap.String("principal-arn", principalARN))
+			return nil
+		}
+		return err
+	}
+	lg.Info("deleted access entry", zap.String("principal-arn", principalARN))
+	return nil
+}
+
+// SessionName is the IAM role session name to check the Kubernetes username
+// of the access entries (e.g. "arn:aws:sts::123:assumed-role/role/{{SessionName}}").
+const SessionName = "aws-k8s-tester"
+
+// CheckListNodes checks that the principal is authorized to list nodes,
+// with a SubjectAccessReview of the Kubernetes username and groups mapped by
+// its access entry, which is authorized by both RBAC and the access policies.
+// The node name is required for the EC2 access entry types, whose username is
+// per node (e.g. "system:node:{{EC2PrivateDNSName}}").
+func CheckListNodes(lg *zap.Logger, eksAPI eksiface.EKSAPI, cli kubernetes.Interface, clusterName string, principalARN string, nodeName string) error {
+	out, err := eksAPI.DescribeAccessEntry(&aws_eks.DescribeAccessEntryInput{
+		ClusterName:  aws.String(clusterName),
+		PrincipalArn: aws.String(principalARN),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to describe access entry for %q (%v)", principalARN, err)
+	}
+	entryType := aws.StringValue(out.AccessEntry.Type)
+	groups := aws.StringValueSlice(out.AccessEntry.KubernetesGroups)
+	if entryType == EntryTypeEC2Linux || entryType == EntryTypeEC2Windows {
+		if nodeName == "" {
+			return fmt.Errorf("empty node name for access entry type %q of %q", entryType, principalARN)
+		}
+		groups = appendIfMissing(groups, "system:nodes")
+	}
+	username, err := expandUsername(aws.StringValue(out.AccessEntry.Username), principalARN, nodeName)
+	if err != nil {
+		return err
+	}
+	groups = appendIfMissing(groups, "system:authenticated")
+
+	lg.Info("checking list nodes access",
+		zap.String("principal-arn", principalARN),
+		zap.String("type", entryType),
+		zap.String("username", username),
+		zap.Strings("groups", groups),
+	)
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	sar, err := cli.AuthorizationV1().SubjectAccessReviews().Create(ctx, &authorizationv1.SubjectAccessReview{
+		Spec: authorizationv1.SubjectAccessReviewSpec{
+			User:   username,
+			Groups: groups,
+			Extra: map[string]authorizationv1.ExtraValue{
+				"arn": {principalARN},
+			},
+			ResourceAttributes: &authorizationv1.ResourceAttributes{
+				Verb:     "list",
+				Resource: "nodes",
+			},
+		},
+	}, metav1.CreateOptions{})
+	cancel()
+	if err != nil {
+		return fmt.Errorf("failed to review list nodes access for %q (%v)", principalARN, err)
+	}
+	if !sar.Status.Allowed {
+		return fmt.Errorf("%q (username %q) cannot list nodes (reason %q, error %q)", principalARN, username, sar.Status.Reason, sar.Status.EvaluationError)
+	}
+	lg.Info("principal can list nodes", zap.String("principal-arn", principalARN), zap.String("reason", sar.Status.Reason))
+	return nil
+}
+
+// expandUsername expands the template variables of the access entry username.
+// ref. https://docs.aws.amazon.com/eks/latest/userguide/creating-access-entries.html
+func expandUsername(username string, principalARN string, nodeName string) (string, error) {
+	if username == "" {
+		return "", fmt.Errorf("empty username in access entry for %q", principalARN)
+	}
+	parsed, err := arn.Parse(principalARN)
+	if err != nil {
+		return "", fmt.Errorf("invalid principal ARN %q (%v)", principalARN, err)
+	}
+	return strings.NewReplacer(
+		"{{EC2PrivateDNSName}}", nodeName,
+		"{{SessionName}}", SessionName,
+		"{{AccountID}}", parsed.AccountID,
+	).Replace(username), nil
+}
+
+// PrincipalARN returns the IAM principal ARN of the caller identity ARN,
+// converting the assumed role session ARN to the role ARN
+// (e.g. "arn:aws:sts::123:assumed-role/my-role/session" to
+// "arn:aws:iam::123:role/my-role"). The role path is not
+// part of the session ARN, thus not included.
+func PrincipalARN(callerARN string) (string, error) {
+	parsed, err := arn.Parse(callerARN)
+	if err != nil {
+		return "", fmt.Errorf("invalid caller ARN %q (%v)", callerARN, err)
+	}
+	if parsed.Service != "sts" {
+		return callerARN, nil
+	}
+	ss := strings.Split(parsed.Resource, "/")
+	if len(ss) < 2 || ss[0] != "assumed-role" {
+		return "", fmt.Errorf("unexpected caller ARN %q (expected assumed role)", callerARN)
+	}
+	return arn.ARN{
+		Partition: parsed.Partition,
+		Service:   "iam",
+		AccountID: parsed.AccountID,
+		Resource:  "role/" + ss[1],
+	}.String(), nil
+}
+
+func appendIfMissing(ss []string, s string) []string {
+	for _, v := range ss {
+		if v == s {
+			return ss
+		}
+	}
+	return append(ss, s)
+}
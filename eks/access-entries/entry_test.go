@@ -0,0 +1,48 @@
+package accessentries
+
+import "testing"
+
+func TestPrincipalARN(t *testing.T) {
+	tt := []struct {
+		callerARN    string
+		principalARN string
+		err          bool
+	}{
+		{"arn:aws:sts::123456789012:assumed-role/my-role/my-session", "arn:aws:iam::123456789012:role/my-role", false},
+		{"arn:aws-cn:sts::123456789012:assumed-role/my-role/my-session", "arn:aws-cn:iam::123456789012:role/my-role", false},
+		{"arn:aws:iam::123456789012:user/my-user", "arn:aws:iam::123456789012:user/my-user", false},
+		{"arn:aws:sts::123456789012:federated-user/my-user", "", true},
+		{"invalid", "", true},
+	}
+	for i, tv := range tt {
+		principalARN, err := PrincipalARN(tv.callerARN)
+		if (err != nil) != tv.err {
+			t.Fatalf("#%d: unexpected error %v", i, err)
+		}
+		if principalARN != tv.principalARN {
+			t.Fatalf("#%d: expected %q, got %q", i, tv.principalARN, principalARN)
+		}
+	}
+}
+
+func TestExpandUsername(t *testing.T) {
+	tt := []struct {
+		username     string
+		principalARN string
+		nodeName     string
+		expected     string
+	}{
+		{"system:node:{{EC2PrivateDNSName}}", "arn:aws:iam::123456789012:role/node-role", "ip-192-168-1-1.ec2.internal", "system:node:ip-192-168-1-1.ec2.internal"},
+		{"arn:aws:sts::{{AccountID}}:assumed-role/my-role/{{SessionName}}", "arn:aws:iam::123456789012:role/my-role", "", "arn:aws:sts::123456789012:assumed-role/my-role/aws-k8s-tester"},
+		{"arn:aws:iam::123456789012:user/my-user", "arn:aws:iam::123456789012:user/my-user", "", "arn:aws:iam::123456789012:user/my-user"},
+	}
+	for i, tv := range tt {
+		username, err := expandUsername(tv.username, tv.principalARN, tv.nodeName)
+		if err != nil {
+			t.Fatalf("#%d: unexpected error %v", i, err)
+		}
+		if username != tv.expected {
+			t.Fatalf("#%d: expected %q, got %q", i, tv.expected, username)
+		}
+	}
+}
@@ -244,6 +244,7 @@ func (ts *tester) createPolicy() error {
 		ts.cfg.EKSConfig.S3.BucketName,
 		ts.cfg.EKSConfig.AddOnAppMesh.PolicyCFNStackYAMLS3Key,
 		ts.cfg.EKSConfig.AddOnAppMesh.PolicyCFNStackYAMLPath,
+		aws_s3.WithTags(ts.cfg.EKSConfig.Tags),
 	); err != nil {
 		return err
 	}
@@ -258,12 +259,12 @@ func (ts *tester) createPolicy() error {
 		Capabilities: aws.StringSlice([]string{"CAPABILITY_NAMED_IAM"}),
 		OnFailure:    aws.String(cloudformation.OnFailureDelete),
 		TemplateBody: aws.String(templatePolicy),
-		Tags: cfn.NewTags(map[string]string{
+		Tags: cfn.NewTags(ts.cfg.EKSConfig.MergeTags(map[string]string{
 			"Kind":                   "aws-k8s-tester",
 			"Name":                   ts.cfg.EKSConfig.Name,
 			"aws-k8s-tester-version": version.ReleaseVersion,
 			"User":                   user.Get(),
-		}),
+		})),
 		Parameters: []*cloudformation.Parameter{
 			{
 				ParameterKey:   aws.String("PolicyName"),
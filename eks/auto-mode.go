@@ -0,0 +1,82 @@
+package eks
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-k8s-tester/eksconfig"
+	"go.uber.org/zap"
+)
+
+// checkAutoModeCapacity checks that the pods of the add-ons were scheduled
+// onto the nodes auto-provisioned by EKS Auto Mode, and records the nodes
+// and the number of scheduled pods in "AutoMode". The pods whose node has
+// been consolidated since are skipped.
+func (ts *Tester) checkAutoModeCapacity() error {
+	if ts.k8sClient == nil {
+		return errors.New("nil k8s client")
+	}
+	nodes, err := ts.k8sClient.ListNodes(1000, 5*time.Second)
+	if err != nil {
+		return fmt.Errorf("failed to list nodes (%v)", err)
+	}
+	autoNodes, otherNodes := make(map[string]struct{}), make(map[string]struct{})
+	for _, node := range nodes {
+		if node.Labels[eksconfig.AutoModeComputeTypeLabel] == eksconfig.AutoModeComputeType {
+			autoNodes[node.Name] = struct{}{}
+		} else {
+			otherNodes[node.Name] = struct{}{}
+		}
+	}
+
+	scheduled, skipped := 0, 0
+	var errs []string
+	namespaces := ts.cfg.AutoModeNamespaces()
+	for _, ns := range namespaces {
+		pods, err := ts.k8sClient.ListPods(ns, 1000, 5*time.Second)
+		if err != nil {
+			return fmt.Errorf("failed to list pods in %q (%v)", ns, err)
+		}
+		for _, pod := range pods {
+			nodeName := pod.Spec.NodeName
+			if nodeName == "" {
+				continue
+			}
+			if _, ok := autoNodes[nodeName]; ok {
+				scheduled++
+				continue
+			}
+			if _, ok := otherNodes[nodeName]; ok {
+				errs = append(errs, fmt.Sprintf("%s/%s on %q without %s=%s", ns, pod.Name, nodeName, eksconfig.AutoModeComputeTypeLabel, eksconfig.AutoModeComputeType))
+				continue
+			}
+			skipped++
+		}
+	}
+
+	names := make([]string, 0, len(autoNodes))
+	for name := range autoNodes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	ts.cfg.AutoMode.Nodes = names
+	ts.cfg.AutoMode.ScheduledPods = scheduled
+	ts.cfg.Sync()
+	ts.lg.Info("checked auto mode capacity",
+		zap.Strings("namespaces", namespaces),
+		zap.Strings("nodes", names),
+		zap.Int("scheduled-pods", scheduled),
+		zap.Int("skipped-pods", skipped),
+	)
+
+	if len(errs) > 0 {
+		return fmt.Errorf("pods not scheduled onto auto-provisioned nodes (%s)", strings.Join(errs, ", "))
+	}
+	if len(namespaces) > 0 && scheduled == 0 {
+		return fmt.Errorf("no pod scheduled onto auto-provisioned nodes in %q", namespaces)
+	}
+	return nil
+}
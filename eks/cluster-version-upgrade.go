@@ -0,0 +1,64 @@
+package eks
+
+import (
+	"fmt"
+	"time"
+
+	eks_tester "github.com/aws/aws-k8s-tester/eks/tester"
+	"github.com/aws/aws-k8s-tester/pkg/timeutil"
+	"go.uber.org/zap"
+)
+
+// rerunAddOns deletes and re-creates the add-ons in
+// "AddOnClusterVersionUpgrade.RerunAddOns" after the cluster version
+// upgrade, to confirm that their workloads still run with the upgraded
+// control plane. The add-ons are re-run in the configured order.
+func (ts *Tester) rerunAddOns() (err error) {
+	if !ts.cfg.AddOnClusterVersionUpgrade.Created {
+		return fmt.Errorf("cluster version upgrade not run before re-running add-ons %q", ts.cfg.AddOnClusterVersionUpgrade.RerunAddOns)
+	}
+
+	byName := make(map[string]eks_tester.Tester, len(ts.testers))
+	for _, cur := range ts.testers {
+		byName[testerAddOnName(cur.Name())] = cur
+	}
+
+	rerunStart := time.Now()
+	defer func() {
+		ts.cfg.AddOnClusterVersionUpgrade.TimeFrameRerun = timeutil.NewTimeFrame(rerunStart, time.Now())
+		ts.cfg.Sync()
+	}()
+	for _, name := range ts.cfg.AddOnClusterVersionUpgrade.RerunAddOns {
+		cur, ok := byName[name]
+		if !ok {
+			return fmt.Errorf("tester not found for add-on %q", name)
+		}
+
+		fmt.Fprint(ts.logWriter, ts.color("\n\n[yellow]*********************************\n"))
+		fmt.Fprintf(ts.logWriter, ts.color("[light_blue]rerunAddOns.Delete [cyan]%q [default](%q, %q)\n"), cur.Name(), ts.cfg.ConfigPath, ts.cfg.KubectlCommand())
+		if err = cur.Delete(); err != nil {
+			return fmt.Errorf("failed to delete add-on %q before re-run (%v)", name, err)
+		}
+		if ts.cfg.IsCreatedAddOn(name) {
+			return fmt.Errorf("add-on %q still marked created after delete; cannot re-run", name)
+		}
+
+		fmt.Fprint(ts.logWriter, ts.color("\n\n[yellow]*********************************\n"))
+		fmt.Fprintf(ts.logWriter, ts.color("[light_green]rerunAddOns.Create [cyan]%q [default](%q, %q)\n"), cur.Name(), ts.cfg.ConfigPath, ts.cfg.KubectlCommand())
+		if err = catchInterrupt(
+			ts.lg,
+			ts.stopCreationCh,
+			ts.stopCreationChOnce,
+			ts.osSig,
+			cur.Create,
+			cur.Name(),
+		); err != nil {
+			return fmt.Errorf("add-on %q failed after cluster version upgrade to %q (%v)", name, ts.cfg.AddOnClusterVersionUpgrade.Version, err)
+		}
+		ts.lg.Info("re-ran add-on after cluster version upgrade",
+			zap.String("add-on", name),
+			zap.String("version", ts.cfg.AddOnClusterVersionUpgrade.Version),
+		)
+	}
+	return nil
+}
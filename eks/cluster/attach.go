@@ -0,0 +1,140 @@
+package cluster
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-k8s-tester/eks/cluster/wait"
+	"github.com/aws/aws-k8s-tester/eksconfig"
+	aws_v2 "github.com/aws/aws-sdk-go-v2/aws"
+	aws_eks "github.com/aws/aws-sdk-go/service/eks"
+	"go.uber.org/zap"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// attach adopts the existing cluster, instead of creating the role,
+// VPC, and cluster. The endpoint, CA, version, role, and VPC are discovered
+// from the EKS API, and the KUBECONFIG is written unless provided.
+func (ts *tester) attach() (err error) {
+	ts.cfg.Logger.Info("attaching to existing cluster",
+		zap.String("name", ts.cfg.EKSConfig.Name),
+		zap.String("region", ts.cfg.EKSConfig.Region),
+		zap.String("kubeconfig-path", ts.cfg.EKSConfig.Attach.KubeConfigPath),
+	)
+
+	kubeconfigProvided := ts.cfg.EKSConfig.Attach.KubeConfigPath != ""
+	out, err := ts.cfg.EKSAPI.DescribeCluster(&aws_eks.DescribeClusterInput{
+		Name: aws_v2.String(ts.cfg.EKSConfig.Name),
+	})
+	switch {
+	case err == nil && out.Cluster != nil:
+		if st := aws_v2.ToString(out.Cluster.Status); st != aws_eks.ClusterStatusActive {
+			return fmt.Errorf("cannot attach to cluster %q in status %q", ts.cfg.EKSConfig.Name, st)
+		}
+		ts.updateClusterStatusV1(wait.ClusterStatus{Cluster: out.Cluster}, aws_eks.ClusterStatusActive)
+		if err = applyAttachedCluster(ts.cfg.EKSConfig, out.Cluster); err != nil {
+			return err
+		}
+	case kubeconfigProvided:
+		// e.g. non-EKS cluster, or no permission to describe
+		ts.cfg.Logger.Warn("failed to describe cluster; discovering from KUBECONFIG", zap.Error(err))
+		if err = ts.discoverFromKubeConfig(); err != nil {
+			return err
+		}
+	default:
+		if err == nil {
+			err = errors.New("empty cluster")
+		}
+		return fmt.Errorf("failed to describe cluster %q to attach (%v)", ts.cfg.EKSConfig.Name, err)
+	}
+	ts.cfg.EKSConfig.Sync()
+
+	// control plane logging is only configurable for EKS clusters
+	eksCluster := ts.cfg.EKSConfig.Attach.ClusterARN != ""
+	if eksCluster {
+		if err = ts.updateControlPlaneLogging(); err != nil {
+			return err
+		}
+	}
+
+	ts.k8sClient, err = ts.createClient()
+	if err != nil {
+		return err
+	}
+	if err = ts.CheckHealth(); err != nil {
+		return err
+	}
+	if eksCluster {
+		if err = ts.verifyControlPlaneLogging(); err != nil {
+			return err
+		}
+	}
+
+	// the server version is the source of truth (e.g. non-EKS clusters)
+	if sv := ts.cfg.EKSConfig.Status.ServerVersionInfo; sv.VersionValue > 0 {
+		ts.cfg.EKSConfig.Version = fmt.Sprintf("%.2f", sv.VersionValue)
+		ts.cfg.EKSConfig.VersionValue = sv.VersionValue
+	}
+	ts.cfg.Logger.Info("attached to existing cluster",
+		zap.String("cluster-arn", ts.cfg.EKSConfig.Attach.ClusterARN),
+		zap.String("endpoint", ts.cfg.EKSConfig.Status.ClusterAPIServerEndpoint),
+		zap.String("version", ts.cfg.EKSConfig.Version),
+	)
+	ts.cfg.EKSConfig.Sync()
+	return nil
+}
+
+// applyAttachedCluster populates the configuration
+// from the attached cluster, to be used by the add-ons.
+func applyAttachedCluster(cfg *eksconfig.Config, c *aws_eks.Cluster) error {
+	cfg.Attach.ClusterARN = aws_v2.ToString(c.Arn)
+	if v := aws_v2.ToString(c.Version); v != "" {
+		fv, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return fmt.Errorf("failed to parse cluster version %q (%v)", v, err)
+		}
+		cfg.Version, cfg.VersionValue = v, fv
+	}
+	if arn := aws_v2.ToString(c.RoleArn); arn != "" {
+		cfg.Role.ARN = arn
+		cfg.Role.Name = arn[strings.LastIndex(arn, "/")+1:]
+	}
+	if vc := c.ResourcesVpcConfig; vc != nil {
+		cfg.VPC.ID = aws_v2.ToString(vc.VpcId)
+		cfg.VPC.SecurityGroupID = aws_v2.ToString(vc.ClusterSecurityGroupId)
+		// node group add-ons are launched in the cluster subnets, unless configured
+		if len(cfg.VPC.PublicSubnetIDs) == 0 {
+			cfg.VPC.PublicSubnetIDs = aws_v2.ToStringSlice(vc.SubnetIds)
+		}
+		cfg.EndpointPublicAccess = aws_v2.ToBool(vc.EndpointPublicAccess)
+		cfg.EndpointPrivateAccess = aws_v2.ToBool(vc.EndpointPrivateAccess)
+		cfg.EndpointPublicAccessCIDRs = aws_v2.ToStringSlice(vc.PublicAccessCidrs)
+	}
+	if c.AccessConfig != nil && c.AccessConfig.AuthenticationMode != nil {
+		cfg.AuthenticationMode = aws_v2.ToString(c.AccessConfig.AuthenticationMode)
+	}
+	return nil
+}
+
+// discoverFromKubeConfig reads the endpoint and CA of the current context.
+func (ts *tester) discoverFromKubeConfig() error {
+	kcfg, err := clientcmd.LoadFromFile(ts.cfg.EKSConfig.KubeConfigPath)
+	if err != nil {
+		return fmt.Errorf("failed to load KUBECONFIG %q (%v)", ts.cfg.EKSConfig.KubeConfigPath, err)
+	}
+	kctx, ok := kcfg.Contexts[kcfg.CurrentContext]
+	if !ok {
+		return fmt.Errorf("KUBECONFIG %q has no current context %q", ts.cfg.EKSConfig.KubeConfigPath, kcfg.CurrentContext)
+	}
+	c, ok := kcfg.Clusters[kctx.Cluster]
+	if !ok {
+		return fmt.Errorf("KUBECONFIG %q has no cluster %q", ts.cfg.EKSConfig.KubeConfigPath, kctx.Cluster)
+	}
+	ts.cfg.EKSConfig.Status.ClusterAPIServerEndpoint = c.Server
+	ts.cfg.EKSConfig.Status.ClusterCA = base64.StdEncoding.EncodeToString(c.CertificateAuthorityData)
+	ts.cfg.EKSConfig.Status.ClusterCADecoded = string(c.CertificateAuthorityData)
+	return nil
+}
@@ -0,0 +1,68 @@
+package cluster
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-k8s-tester/eksconfig"
+	aws_v2 "github.com/aws/aws-sdk-go-v2/aws"
+	aws_eks "github.com/aws/aws-sdk-go/service/eks"
+)
+
+func TestApplyAttachedCluster(t *testing.T) {
+	cfg := eksconfig.NewDefault()
+	cfg.Attach = &eksconfig.Attach{Enable: true}
+	c := &aws_eks.Cluster{
+		Arn:     aws_v2.String("arn:aws:eks:us-west-2:123:cluster/existing"),
+		Version: aws_v2.String("1.29"),
+		RoleArn: aws_v2.String("arn:aws:iam::123:role/existing-role"),
+		ResourcesVpcConfig: &aws_eks.VpcConfigResponse{
+			VpcId:                  aws_v2.String("vpc-1"),
+			ClusterSecurityGroupId: aws_v2.String("sg-1"),
+			SubnetIds:              aws_v2.StringSlice([]string{"subnet-1", "subnet-2"}),
+			EndpointPublicAccess:   aws_v2.Bool(false),
+			EndpointPrivateAccess:  aws_v2.Bool(true),
+		},
+		AccessConfig: &aws_eks.AccessConfigResponse{
+			AuthenticationMode: aws_v2.String(aws_eks.AuthenticationModeApiAndConfigMap),
+		},
+	}
+	if err := applyAttachedCluster(cfg, c); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Attach.ClusterARN != "arn:aws:eks:us-west-2:123:cluster/existing" {
+		t.Fatalf("unexpected Attach.ClusterARN %q", cfg.Attach.ClusterARN)
+	}
+	if cfg.Version != "1.29" || cfg.VersionValue != 1.29 {
+		t.Fatalf("unexpected version %q (%v)", cfg.Version, cfg.VersionValue)
+	}
+	if cfg.Role.ARN != "arn:aws:iam::123:role/existing-role" || cfg.Role.Name != "existing-role" {
+		t.Fatalf("unexpected role %q (%q)", cfg.Role.ARN, cfg.Role.Name)
+	}
+	if cfg.VPC.ID != "vpc-1" || cfg.VPC.SecurityGroupID != "sg-1" {
+		t.Fatalf("unexpected VPC %q (security group %q)", cfg.VPC.ID, cfg.VPC.SecurityGroupID)
+	}
+	if !reflect.DeepEqual(cfg.VPC.PublicSubnetIDs, []string{"subnet-1", "subnet-2"}) {
+		t.Fatalf("unexpected VPC.PublicSubnetIDs %q", cfg.VPC.PublicSubnetIDs)
+	}
+	if !cfg.IsPrivateEndpointOnly() {
+		t.Fatal("expected private-only endpoint")
+	}
+	if cfg.AuthenticationMode != aws_eks.AuthenticationModeApiAndConfigMap {
+		t.Fatalf("unexpected AuthenticationMode %q", cfg.AuthenticationMode)
+	}
+
+	// configured subnets are kept
+	cfg.VPC.PublicSubnetIDs = []string{"subnet-3"}
+	if err := applyAttachedCluster(cfg, c); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(cfg.VPC.PublicSubnetIDs, []string{"subnet-3"}) {
+		t.Fatalf("unexpected VPC.PublicSubnetIDs %q", cfg.VPC.PublicSubnetIDs)
+	}
+
+	c.Version = aws_v2.String("invalid")
+	if err := applyAttachedCluster(cfg, c); err == nil {
+		t.Fatal("expected error for invalid version")
+	}
+}
@@ -0,0 +1,35 @@
+package cluster
+
+import (
+	"fmt"
+
+	aws_v2 "github.com/aws/aws-sdk-go-v2/aws"
+	aws_eks_v2 "github.com/aws/aws-sdk-go-v2/service/eks"
+	aws_eks_v2_types "github.com/aws/aws-sdk-go-v2/service/eks/types"
+)
+
+// addAutoModeConfig enables the compute, block storage, and load balancing
+// capabilities in the create cluster request, which must all be enabled
+// together. The self-managed networking add-ons are not installed, since
+// EKS Auto Mode manages the pod networking.
+// ref. https://docs.aws.amazon.com/eks/latest/APIReference/API_CreateCluster.html
+func addAutoModeConfig(input *aws_eks_v2.CreateClusterInput, nodePools []string, nodeRoleARN string) error {
+	computeConfig := &aws_eks_v2_types.ComputeConfigRequest{Enabled: aws_v2.Bool(true)}
+	if len(nodePools) > 0 {
+		if nodeRoleARN == "" {
+			return fmt.Errorf("empty node role ARN for node pools %q", nodePools)
+		}
+		computeConfig.NodePools = nodePools
+		computeConfig.NodeRoleArn = aws_v2.String(nodeRoleARN)
+	}
+	input.ComputeConfig = computeConfig
+	input.StorageConfig = &aws_eks_v2_types.StorageConfigRequest{
+		BlockStorage: &aws_eks_v2_types.BlockStorage{Enabled: aws_v2.Bool(true)},
+	}
+	if input.KubernetesNetworkConfig == nil {
+		input.KubernetesNetworkConfig = &aws_eks_v2_types.KubernetesNetworkConfigRequest{}
+	}
+	input.KubernetesNetworkConfig.ElasticLoadBalancing = &aws_eks_v2_types.ElasticLoadBalancing{Enabled: aws_v2.Bool(true)}
+	input.BootstrapSelfManagedAddons = aws_v2.Bool(false)
+	return nil
+}
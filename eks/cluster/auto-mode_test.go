@@ -0,0 +1,56 @@
+package cluster
+
+import (
+	"reflect"
+	"testing"
+
+	aws_v2 "github.com/aws/aws-sdk-go-v2/aws"
+	aws_eks_v2 "github.com/aws/aws-sdk-go-v2/service/eks"
+	aws_eks_v2_types "github.com/aws/aws-sdk-go-v2/service/eks/types"
+)
+
+func TestAddAutoModeConfig(t *testing.T) {
+	input := &aws_eks_v2.CreateClusterInput{
+		Name: aws_v2.String("test"),
+		KubernetesNetworkConfig: &aws_eks_v2_types.KubernetesNetworkConfigRequest{
+			IpFamily: aws_eks_v2_types.IpFamilyIpv6,
+		},
+	}
+	if err := addAutoModeConfig(input, []string{"general-purpose", "system"}, "arn:aws:iam::123:role/test-role"); err != nil {
+		t.Fatal(err)
+	}
+	expected := &aws_eks_v2.CreateClusterInput{
+		Name: aws_v2.String("test"),
+		ComputeConfig: &aws_eks_v2_types.ComputeConfigRequest{
+			Enabled:     aws_v2.Bool(true),
+			NodePools:   []string{"general-purpose", "system"},
+			NodeRoleArn: aws_v2.String("arn:aws:iam::123:role/test-role"),
+		},
+		StorageConfig: &aws_eks_v2_types.StorageConfigRequest{
+			BlockStorage: &aws_eks_v2_types.BlockStorage{Enabled: aws_v2.Bool(true)},
+		},
+		KubernetesNetworkConfig: &aws_eks_v2_types.KubernetesNetworkConfigRequest{
+			IpFamily:             aws_eks_v2_types.IpFamilyIpv6,
+			ElasticLoadBalancing: &aws_eks_v2_types.ElasticLoadBalancing{Enabled: aws_v2.Bool(true)},
+		},
+		BootstrapSelfManagedAddons: aws_v2.Bool(false),
+	}
+	if !reflect.DeepEqual(input, expected) {
+		t.Fatalf("unexpected request %+v", input)
+	}
+
+	input = &aws_eks_v2.CreateClusterInput{Name: aws_v2.String("test")}
+	if err := addAutoModeConfig(input, nil, ""); err != nil {
+		t.Fatal(err)
+	}
+	if input.ComputeConfig.NodePools != nil || input.ComputeConfig.NodeRoleArn != nil {
+		t.Fatalf("unexpected compute config %+v", input.ComputeConfig)
+	}
+	if input.KubernetesNetworkConfig == nil || !aws_v2.ToBool(input.KubernetesNetworkConfig.ElasticLoadBalancing.Enabled) {
+		t.Fatalf("expected load balancing enabled, got %+v", input.KubernetesNetworkConfig)
+	}
+
+	if err := addAutoModeConfig(input, []string{"system"}, ""); err == nil {
+		t.Fatal("expected error for empty node role ARN")
+	}
+}
@@ -0,0 +1,435 @@
+package cluster
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	osexec "os/exec"
+	"strings"
+	"time"
+
+	aws_ec2 "github.com/aws/aws-k8s-tester/pkg/aws/ec2"
+	aws_v2 "github.com/aws/aws-sdk-go-v2/aws"
+	aws_ec2_v2 "github.com/aws/aws-sdk-go-v2/service/ec2"
+	aws_ec2_v2_types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	smithy "github.com/aws/smithy-go"
+	"go.uber.org/zap"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// Private-only endpoint clusters are not reachable from the tester host.
+// A bastion host is created in a public subnet, and all kubectl and client-go
+// requests are proxied through "ssh -D" (SOCKS5) to the bastion host.
+// Node logs are still fetched via SSH to nodes, which does not need the endpoint.
+// ref. https://docs.aws.amazon.com/eks/latest/userguide/cluster-endpoint.html#private-access
+
+// privateEndpointOnly returns true if the cluster endpoint is only reachable
+// within the VPC. Prefers the endpoint access reported by EKS API.
+func (ts *tester) privateEndpointOnly() bool {
+	if st := ts.cfg.EKSConfig.Status; st != nil && (st.ClusterEndpointPublicAccess || st.ClusterEndpointPrivateAccess) {
+		return st.ClusterEndpointPrivateAccess && !st.ClusterEndpointPublicAccess
+	}
+	return ts.cfg.EKSConfig.IsPrivateEndpointOnly()
+}
+
+// AWS::EC2::Instance
+func (ts *tester) createBastion() error {
+	if ts.cfg.EKSConfig.Bastion == nil {
+		return errors.New("empty Bastion for private-only endpoint")
+	}
+	if ts.cfg.EKSConfig.Bastion.InstanceID != "" && ts.cfg.EKSConfig.Bastion.PublicIP != "" {
+		ts.cfg.Logger.Info("bastion host already created", zap.String("instance-id", ts.cfg.EKSConfig.Bastion.InstanceID))
+		return nil
+	}
+	if len(ts.cfg.EKSConfig.VPC.PublicSubnetIDs) == 0 {
+		return errors.New("empty VPC.PublicSubnetIDs for bastion host")
+	}
+
+	if err := ts.createBastionSecurityGroup(); err != nil {
+		return err
+	}
+
+	ts.cfg.Logger.Info("creating bastion host",
+		zap.String("instance-type", ts.cfg.EKSConfig.Bastion.InstanceType),
+		zap.String("image-id-ssm-parameter", ts.cfg.EKSConfig.Bastion.ImageIDSSMParameter),
+		zap.String("subnet-id", ts.cfg.EKSConfig.VPC.PublicSubnetIDs[0]),
+	)
+	out, err := ts.cfg.EC2APIV2.RunInstances(
+		context.Background(),
+		&aws_ec2_v2.RunInstancesInput{
+			// resolve the AMI from SSM parameter store
+			// ref. https://docs.aws.amazon.com/systems-manager/latest/userguide/parameter-store-ec2-aliases.html
+			ImageId:      aws_v2.String("resolve:ssm:" + ts.cfg.EKSConfig.Bastion.ImageIDSSMParameter),
+			InstanceType: aws_ec2_v2_types.InstanceType(ts.cfg.EKSConfig.Bastion.InstanceType),
+			KeyName:      aws_v2.String(ts.cfg.EKSConfig.RemoteAccessKeyName),
+			MinCount:     aws_v2.Int32(1),
+			MaxCount:     aws_v2.Int32(1),
+			NetworkInterfaces: []aws_ec2_v2_types.InstanceNetworkInterfaceSpecification{
+				{
+					DeviceIndex:              aws_v2.Int32(0),
+					SubnetId:                 aws_v2.String(ts.cfg.EKSConfig.VPC.PublicSubnetIDs[0]),
+					Groups:                   []string{ts.cfg.EKSConfig.Bastion.SecurityGroupID},
+					AssociatePublicIpAddress: aws_v2.Bool(true),
+					DeleteOnTermination:      aws_v2.Bool(true),
+				},
+			},
+			TagSpecifications: []aws_ec2_v2_types.TagSpecification{
+				{
+					ResourceType: aws_ec2_v2_types.ResourceTypeInstance,
+					Tags: aws_ec2.AppendTags([]aws_ec2_v2_types.Tag{
+						{
+							Key:   aws_v2.String("Name"),
+							Value: aws_v2.String(ts.cfg.EKSConfig.Name + "-bastion"),
+						},
+						{
+							Key:   aws_v2.String("Kind"),
+							Value: aws_v2.String("aws-k8s-tester"),
+						},
+					}, ts.cfg.EKSConfig.Tags),
+				},
+			},
+		},
+	)
+	if err != nil {
+		ts.cfg.Logger.Warn("failed to create bastion host", zap.Error(err))
+		return err
+	}
+	if len(out.Instances) != 1 {
+		return fmt.Errorf("unexpected bastion host instances %d", len(out.Instances))
+	}
+	ts.cfg.EKSConfig.Bastion.InstanceID = aws_v2.ToString(out.Instances[0].InstanceId)
+	ts.cfg.EKSConfig.Sync()
+
+	ts.cfg.Logger.Info("waiting for bastion host", zap.String("instance-id", ts.cfg.EKSConfig.Bastion.InstanceID))
+	retryStart, waitDur := time.Now(), 10*time.Minute
+	for time.Since(retryStart) < waitDur {
+		select {
+		case <-ts.cfg.Stopc:
+			return errors.New("bastion host creation aborted")
+		case <-time.After(10 * time.Second):
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		dout, err := ts.cfg.EC2APIV2.DescribeInstances(
+			ctx,
+			&aws_ec2_v2.DescribeInstancesInput{
+				InstanceIds: []string{ts.cfg.EKSConfig.Bastion.InstanceID},
+			},
+		)
+		cancel()
+		if err != nil {
+			ts.cfg.Logger.Warn("failed to describe bastion host", zap.Error(err))
+			continue
+		}
+		if len(dout.Reservations) != 1 || len(dout.Reservations[0].Instances) != 1 {
+			ts.cfg.Logger.Warn("bastion host not found yet")
+			continue
+		}
+		iv := dout.Reservations[0].Instances[0]
+		state := ""
+		if iv.State != nil {
+			state = string(iv.State.Name)
+		}
+		ts.cfg.Logger.Info("polled bastion host", zap.String("state", state))
+		if state != string(aws_ec2_v2_types.InstanceStateNameRunning) || aws_v2.ToString(iv.PublicIpAddress) == "" {
+			continue
+		}
+
+		ts.cfg.EKSConfig.Bastion.PublicIP = aws_v2.ToString(iv.PublicIpAddress)
+		ts.cfg.EKSConfig.Sync()
+		ts.cfg.Logger.Info("created bastion host",
+			zap.String("instance-id", ts.cfg.EKSConfig.Bastion.InstanceID),
+			zap.String("public-ip", ts.cfg.EKSConfig.Bastion.PublicIP),
+		)
+		return nil
+	}
+	return fmt.Errorf("bastion host %q not running", ts.cfg.EKSConfig.Bastion.InstanceID)
+}
+
+// AWS::EC2::SecurityGroup
+func (ts *tester) createBastionSecurityGroup() error {
+	if ts.cfg.EKSConfig.Bastion.SecurityGroupID != "" {
+		return nil
+	}
+
+	ts.cfg.Logger.Info("creating bastion host security group")
+	sout, err := ts.cfg.EC2APIV2.CreateSecurityGroup(
+		context.Background(),
+		&aws_ec2_v2.CreateSecurityGroupInput{
+			GroupName:   aws_v2.String(ts.cfg.EKSConfig.Name + "-bastion-security-group"),
+			Description: aws_v2.String("SSH access to bastion host for private EKS cluster endpoint"),
+			VpcId:       aws_v2.String(ts.cfg.EKSConfig.VPC.ID),
+			TagSpecifications: []aws_ec2_v2_types.TagSpecification{
+				{
+					ResourceType: aws_ec2_v2_types.ResourceTypeSecurityGroup,
+					Tags: aws_ec2.AppendTags([]aws_ec2_v2_types.Tag{
+						{
+							Key:   aws_v2.String("Name"),
+							Value: aws_v2.String(ts.cfg.EKSConfig.Name + "-bastion-security-group"),
+						},
+					}, ts.cfg.EKSConfig.Tags),
+				},
+			},
+		},
+	)
+	if err != nil {
+		ts.cfg.Logger.Warn("failed to create bastion host security group", zap.Error(err))
+		return err
+	}
+	ts.cfg.EKSConfig.Bastion.SecurityGroupID = aws_v2.ToString(sout.GroupId)
+	ts.cfg.EKSConfig.Sync()
+
+	ts.cfg.Logger.Info("authorizing SSH ingress to bastion host", zap.String("sg-id", ts.cfg.EKSConfig.Bastion.SecurityGroupID))
+	_, err = ts.cfg.EC2APIV2.AuthorizeSecurityGroupIngress(
+		context.Background(),
+		&aws_ec2_v2.AuthorizeSecurityGroupIngressInput{
+			GroupId: aws_v2.String(ts.cfg.EKSConfig.Bastion.SecurityGroupID),
+			IpPermissions: []aws_ec2_v2_types.IpPermission{
+				{
+					IpProtocol: aws_v2.String("tcp"),
+					IpRanges: []aws_ec2_v2_types.IpRange{
+						{
+							CidrIp: aws_v2.String("0.0.0.0/0"),
+						},
+					},
+					FromPort: aws_v2.Int32(22),
+					ToPort:   aws_v2.Int32(22),
+				},
+			},
+		},
+	)
+	if err != nil {
+		ts.cfg.Logger.Warn("failed to authorize SSH ingress to bastion host", zap.Error(err))
+		return err
+	}
+
+	ts.cfg.Logger.Info("authorizing HTTPS ingress from bastion host to control plane", zap.String("sg-id", ts.cfg.EKSConfig.VPC.SecurityGroupID))
+	_, err = ts.cfg.EC2APIV2.AuthorizeSecurityGroupIngress(
+		context.Background(),
+		&aws_ec2_v2.AuthorizeSecurityGroupIngressInput{
+			GroupId:       aws_v2.String(ts.cfg.EKSConfig.VPC.SecurityGroupID),
+			IpPermissions: ts.bastionToControlPlanePermissions(),
+		},
+	)
+	if err != nil {
+		ts.cfg.Logger.Warn("failed to authorize HTTPS ingress from bastion host", zap.Error(err))
+		return err
+	}
+
+	ts.cfg.Logger.Info("created bastion host security group", zap.String("sg-id", ts.cfg.EKSConfig.Bastion.SecurityGroupID))
+	return nil
+}
+
+func (ts *tester) bastionToControlPlanePermissions() []aws_ec2_v2_types.IpPermission {
+	return []aws_ec2_v2_types.IpPermission{
+		{
+			IpProtocol: aws_v2.String("tcp"),
+			UserIdGroupPairs: []aws_ec2_v2_types.UserIdGroupPair{
+				{
+					GroupId: aws_v2.String(ts.cfg.EKSConfig.Bastion.SecurityGroupID),
+					VpcId:   aws_v2.String(ts.cfg.EKSConfig.VPC.ID),
+				},
+			},
+			FromPort: aws_v2.Int32(443),
+			ToPort:   aws_v2.Int32(443),
+		},
+	}
+}
+
+func (ts *tester) deleteBastion() error {
+	if ts.cfg.EKSConfig.Bastion == nil {
+		return nil
+	}
+	ts.stopProxy()
+
+	if id := ts.cfg.EKSConfig.Bastion.InstanceID; id != "" {
+		if _, ok := ts.cfg.EKSConfig.Status.DeletedResources[id]; !ok {
+			ts.cfg.Logger.Info("deleting bastion host", zap.String("instance-id", id))
+			_, err := ts.cfg.EC2APIV2.TerminateInstances(
+				context.Background(),
+				&aws_ec2_v2.TerminateInstancesInput{
+					InstanceIds: []string{id},
+				},
+			)
+			if err != nil && !isNotFound(err) {
+				ts.cfg.Logger.Warn("failed to delete bastion host", zap.Error(err))
+				return err
+			}
+			if err == nil {
+				if err = ts.waitBastionTerminated(id); err != nil {
+					return err
+				}
+			}
+			ts.cfg.EKSConfig.Status.DeletedResources[id] = "Bastion.InstanceID"
+			ts.cfg.EKSConfig.Sync()
+			ts.cfg.Logger.Info("deleted bastion host", zap.String("instance-id", id))
+		}
+	}
+
+	if id := ts.cfg.EKSConfig.Bastion.SecurityGroupID; id != "" {
+		if _, ok := ts.cfg.EKSConfig.Status.DeletedResources[id]; !ok {
+			// otherwise, "DependencyViolation" from the control plane security group
+			ts.cfg.Logger.Info("revoking HTTPS ingress from bastion host", zap.String("sg-id", ts.cfg.EKSConfig.VPC.SecurityGroupID))
+			_, err := ts.cfg.EC2APIV2.RevokeSecurityGroupIngress(
+				context.Background(),
+				&aws_ec2_v2.RevokeSecurityGroupIngressInput{
+					GroupId:       aws_v2.String(ts.cfg.EKSConfig.VPC.SecurityGroupID),
+					IpPermissions: ts.bastionToControlPlanePermissions(),
+				},
+			)
+			if err != nil {
+				ts.cfg.Logger.Warn("failed to revoke HTTPS ingress from bastion host", zap.Error(err))
+			}
+
+			ts.cfg.Logger.Info("deleting bastion host security group", zap.String("sg-id", id))
+			_, err = ts.cfg.EC2APIV2.DeleteSecurityGroup(
+				context.Background(),
+				&aws_ec2_v2.DeleteSecurityGroupInput{
+					GroupId: aws_v2.String(id),
+				},
+			)
+			if err != nil && !isNotFound(err) {
+				ts.cfg.Logger.Warn("failed to delete bastion host security group", zap.Error(err))
+				return err
+			}
+			ts.cfg.EKSConfig.Status.DeletedResources[id] = "Bastion.SecurityGroupID"
+			ts.cfg.EKSConfig.Sync()
+			ts.cfg.Logger.Info("deleted bastion host security group", zap.String("sg-id", id))
+		}
+	}
+	return nil
+}
+
+func (ts *tester) waitBastionTerminated(id string) error {
+	retryStart, waitDur := time.Now(), 10*time.Minute
+	for time.Since(retryStart) < waitDur {
+		select {
+		case <-ts.cfg.Stopc:
+			return errors.New("bastion host deletion aborted")
+		case <-time.After(10 * time.Second):
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		dout, err := ts.cfg.EC2APIV2.DescribeInstances(
+			ctx,
+			&aws_ec2_v2.DescribeInstancesInput{
+				InstanceIds: []string{id},
+			},
+		)
+		cancel()
+		if err != nil {
+			if isNotFound(err) {
+				return nil
+			}
+			ts.cfg.Logger.Warn("failed to describe bastion host", zap.Error(err))
+			continue
+		}
+		if len(dout.Reservations) == 0 || len(dout.Reservations[0].Instances) == 0 {
+			return nil
+		}
+		iv := dout.Reservations[0].Instances[0]
+		if iv.State != nil && iv.State.Name == aws_ec2_v2_types.InstanceStateNameTerminated {
+			return nil
+		}
+		ts.cfg.Logger.Info("bastion host not terminated yet", zap.String("instance-id", id))
+	}
+	return fmt.Errorf("bastion host %q not terminated", id)
+}
+
+func isNotFound(err error) bool {
+	var apiErr smithy.APIError
+	return errors.As(err, &apiErr) && strings.Contains(apiErr.ErrorCode(), "NotFound")
+}
+
+// startProxy starts the local SOCKS5 proxy via "ssh -D" to the bastion host,
+// and waits until the local port accepts connections.
+func (ts *tester) startProxy() error {
+	if ts.proxyCmd != nil {
+		return nil
+	}
+	addr := fmt.Sprintf("127.0.0.1:%d", ts.cfg.EKSConfig.Bastion.LocalProxyPort)
+	args := []string{
+		"-i", ts.cfg.EKSConfig.RemoteAccessPrivateKeyPath,
+		"-o", "StrictHostKeyChecking=no",
+		"-o", "UserKnownHostsFile=/dev/null",
+		"-o", "ServerAliveInterval=30",
+		"-o", "ExitOnForwardFailure=yes",
+		"-N",
+		"-D", addr,
+		"ec2-user@" + ts.cfg.EKSConfig.Bastion.PublicIP,
+	}
+
+	// sshd takes a while after the instance is running
+	retryStart, waitDur := time.Now(), 5*time.Minute
+	for time.Since(retryStart) < waitDur {
+		ts.cfg.Logger.Info("starting SOCKS5 proxy to bastion host", zap.String("command", "ssh "+strings.Join(args, " ")))
+		cmd := osexec.Command("ssh", args...)
+		cmd.Stdout, cmd.Stderr = ts.cfg.LogWriter, ts.cfg.LogWriter
+		if err := cmd.Start(); err != nil {
+			return fmt.Errorf("failed to start SOCKS5 proxy (%v)", err)
+		}
+		exitc := make(chan error, 1)
+		go func() {
+			exitc <- cmd.Wait()
+		}()
+
+	wait:
+		for i := 0; i < 10; i++ {
+			select {
+			case <-ts.cfg.Stopc:
+				_ = cmd.Process.Kill()
+				return errors.New("SOCKS5 proxy start aborted")
+			case err := <-exitc:
+				ts.cfg.Logger.Warn("SOCKS5 proxy exited; retrying", zap.Error(err))
+				break wait
+			case <-time.After(3 * time.Second):
+			}
+			conn, err := net.DialTimeout("tcp", addr, 3*time.Second)
+			if err != nil {
+				continue
+			}
+			conn.Close()
+
+			ts.proxyCmd = cmd
+			ts.cfg.EKSConfig.Bastion.ProxyURL = "socks5://" + addr
+			ts.cfg.EKSConfig.Sync()
+			ts.cfg.Logger.Info("started SOCKS5 proxy to bastion host", zap.String("proxy-url", ts.cfg.EKSConfig.Bastion.ProxyURL))
+			return nil
+		}
+		_ = cmd.Process.Kill()
+		time.Sleep(5 * time.Second)
+	}
+	return fmt.Errorf("failed to start SOCKS5 proxy to bastion host %q", ts.cfg.EKSConfig.Bastion.PublicIP)
+}
+
+func (ts *tester) stopProxy() {
+	if ts.proxyCmd == nil {
+		return
+	}
+	ts.cfg.Logger.Info("stopping SOCKS5 proxy to bastion host")
+	if err := ts.proxyCmd.Process.Kill(); err != nil {
+		ts.cfg.Logger.Warn("failed to stop SOCKS5 proxy", zap.Error(err))
+	}
+	ts.proxyCmd = nil
+}
+
+// setKubeConfigProxyURL sets "proxy-url" for all clusters in KUBECONFIG,
+// which is honored by both kubectl and client-go.
+func (ts *tester) setKubeConfigProxyURL() error {
+	kcfg, err := clientcmd.LoadFromFile(ts.cfg.EKSConfig.KubeConfigPath)
+	if err != nil {
+		return fmt.Errorf("failed to load KUBECONFIG %q (%v)", ts.cfg.EKSConfig.KubeConfigPath, err)
+	}
+	for _, c := range kcfg.Clusters {
+		c.ProxyURL = ts.cfg.EKSConfig.Bastion.ProxyURL
+	}
+	if err = clientcmd.WriteToFile(*kcfg, ts.cfg.EKSConfig.KubeConfigPath); err != nil {
+		return fmt.Errorf("failed to write KUBECONFIG %q (%v)", ts.cfg.EKSConfig.KubeConfigPath, err)
+	}
+	ts.cfg.Logger.Info("set proxy-url in KUBECONFIG",
+		zap.String("kubeconfig-path", ts.cfg.EKSConfig.KubeConfigPath),
+		zap.String("proxy-url", ts.cfg.EKSConfig.Bastion.ProxyURL),
+	)
+	return nil
+}
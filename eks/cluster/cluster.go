@@ -13,6 +13,7 @@ import (
 	"io/ioutil"
 	"net/http"
 	"net/url"
+	osexec "os/exec"
 	"path"
 	"path/filepath"
 	"reflect"
@@ -34,7 +35,7 @@ import (
 	aws_iam_v2 "github.com/aws/aws-sdk-go-v2/service/iam"
 	aws_kms_v2 "github.com/aws/aws-sdk-go-v2/service/kms"
 	aws_s3_v2 "github.com/aws/aws-sdk-go-v2/service/s3"
-	"github.com/aws/aws-sdk-go/service/cloudformation/cloudformationiface"
+	"github.com/aws/aws-sdk-go/service/cloudwatchlogs/cloudwatchlogsiface"
 	"github.com/aws/aws-sdk-go/service/eks"
 	"github.com/aws/aws-sdk-go/service/eks/eksiface"
 	"github.com/aws/aws-sdk-go/service/s3/s3iface"
@@ -63,9 +64,9 @@ type Config struct {
 	EKSAPI   eksiface.EKSAPI
 	EKSAPIV2 *aws_eks_v2.Client
 
-	ELBV2APIV2 *aws_elbv2_v2.Client
+	LogsAPI cloudwatchlogsiface.CloudWatchLogsAPI
 
-	CFNAPI cloudformationiface.CloudFormationAPI
+	ELBV2APIV2 *aws_elbv2_v2.Client
 }
 
 type Tester interface {
@@ -98,24 +99,41 @@ type tester struct {
 	cfg       Config
 	k8sClient k8s_client.EKS
 
+	// proxyCmd is the "ssh -D" process to the bastion host,
+	// only for private-only endpoint clusters.
+	proxyCmd *osexec.Cmd
+
 	checkHealthMu *sync.Mutex
 }
 
 func (ts *tester) Create() (err error) {
 	ts.cfg.Logger.Info("starting tester.Create", zap.String("tester", pkgName))
 
-	if err = ts.createEncryption(); err != nil {
-		return err
+	if ts.cfg.EKSConfig.IsEnabledAttach() {
+		return ts.attach()
 	}
+
 	if err = ts.createRole(); err != nil {
 		return err
 	}
+	// existing CMK key policy is validated against the cluster role
+	if err = ts.createEncryption(); err != nil {
+		return err
+	}
 	if err = ts.createVPC(); err != nil {
 		return err
 	}
 	if err = ts.createEKS(); err != nil {
 		return err
 	}
+	if err = ts.updateControlPlaneLogging(); err != nil {
+		return err
+	}
+	if ts.privateEndpointOnly() {
+		if err = ts.createBastion(); err != nil {
+			return err
+		}
+	}
 
 	ts.k8sClient, err = ts.createClient()
 	if err != nil {
@@ -124,6 +142,9 @@ func (ts *tester) Create() (err error) {
 	if err = ts.CheckHealth(); err != nil {
 		return err
 	}
+	if err = ts.verifyControlPlaneLogging(); err != nil {
+		return err
+	}
 
 	ts.cfg.EKSConfig.Sync()
 	return nil
@@ -201,6 +222,12 @@ func (ts *tester) checkHealth(caller string) (err error) {
 func (ts *tester) Delete() error {
 	ts.cfg.Logger.Info("starting tester.Delete", zap.String("tester", pkgName))
 
+	if ts.cfg.EKSConfig.IsEnabledAttach() {
+		// the attached cluster is owned by other tools
+		ts.cfg.Logger.Info("skipping cluster delete for attached cluster", zap.String("cluster-arn", ts.cfg.EKSConfig.Attach.ClusterARN))
+		return nil
+	}
+
 	var errs []string
 
 	if err := ts.deleteEKS(); err != nil {
@@ -211,13 +238,19 @@ func (ts *tester) Delete() error {
 		// otherwise, it's not safe to delete non-EKS resources
 		// (e.g. delete CMK can fail other dependent components
 		// under the same account)
-		if err := ts.deleteEncryption(); err != nil {
+		// delete in the reverse order of creation, so that the VPC is
+		// deleted (and its leaked ENIs swept) while the cluster role,
+		// which owns the cross-account ENIs, still exists
+		if err := ts.deleteBastion(); err != nil {
 			errs = append(errs, err.Error())
 		}
-		if err := ts.deleteRole(); err != nil {
+		if err := ts.deleteVPC(); err != nil {
 			errs = append(errs, err.Error())
 		}
-		if err := ts.deleteVPC(); err != nil {
+		if err := ts.deleteEncryption(); err != nil {
+			errs = append(errs, err.Error())
+		}
+		if err := ts.deleteRole(); err != nil {
 			errs = append(errs, err.Error())
 		}
 	}
@@ -250,6 +283,13 @@ func (ts *tester) updateClusterStatusV1(v1 wait.ClusterStatus, desired string) {
 		if v1.Cluster.Endpoint != nil {
 			ts.cfg.EKSConfig.Status.ClusterAPIServerEndpoint = aws_v2.ToString(v1.Cluster.Endpoint)
 		}
+		if v1.Cluster.ResourcesVpcConfig != nil {
+			ts.cfg.EKSConfig.Status.ClusterEndpointPublicAccess = aws_v2.ToBool(v1.Cluster.ResourcesVpcConfig.EndpointPublicAccess)
+			ts.cfg.EKSConfig.Status.ClusterEndpointPrivateAccess = aws_v2.ToBool(v1.Cluster.ResourcesVpcConfig.EndpointPrivateAccess)
+		}
+		if v1.Cluster.Id != nil && ts.cfg.EKSConfig.IsEnabledOutpost() {
+			ts.cfg.EKSConfig.Outpost.ClusterID = aws_v2.ToString(v1.Cluster.Id)
+		}
 
 		if v1.Cluster.Identity != nil &&
 			v1.Cluster.Identity.Oidc != nil &&
@@ -332,6 +372,8 @@ func (ts *tester) updateClusterStatusV1(v1 wait.ClusterStatus, desired string) {
 	} else {
 
 		ts.cfg.EKSConfig.Status.ClusterAPIServerEndpoint = ""
+		ts.cfg.EKSConfig.Status.ClusterEndpointPublicAccess = false
+		ts.cfg.EKSConfig.Status.ClusterEndpointPrivateAccess = false
 		ts.cfg.EKSConfig.Status.ClusterOIDCIssuerURL = ""
 		ts.cfg.EKSConfig.Status.ClusterOIDCIssuerHostPath = ""
 		ts.cfg.EKSConfig.Status.ClusterOIDCIssuerARN = ""
@@ -364,6 +406,10 @@ func (ts *tester) updateClusterStatusV2(v2 wait_v2.ClusterStatus, desired string
 		if v2.Cluster.Endpoint != nil {
 			ts.cfg.EKSConfig.Status.ClusterAPIServerEndpoint = aws_v2.ToString(v2.Cluster.Endpoint)
 		}
+		if v2.Cluster.ResourcesVpcConfig != nil {
+			ts.cfg.EKSConfig.Status.ClusterEndpointPublicAccess = v2.Cluster.ResourcesVpcConfig.EndpointPublicAccess
+			ts.cfg.EKSConfig.Status.ClusterEndpointPrivateAccess = v2.Cluster.ResourcesVpcConfig.EndpointPrivateAccess
+		}
 
 		if v2.Cluster.Identity != nil &&
 			v2.Cluster.Identity.Oidc != nil &&
@@ -446,6 +492,8 @@ func (ts *tester) updateClusterStatusV2(v2 wait_v2.ClusterStatus, desired string
 	} else {
 
 		ts.cfg.EKSConfig.Status.ClusterAPIServerEndpoint = ""
+		ts.cfg.EKSConfig.Status.ClusterEndpointPublicAccess = false
+		ts.cfg.EKSConfig.Status.ClusterEndpointPrivateAccess = false
 		ts.cfg.EKSConfig.Status.ClusterOIDCIssuerURL = ""
 		ts.cfg.EKSConfig.Status.ClusterOIDCIssuerHostPath = ""
 		ts.cfg.EKSConfig.Status.ClusterOIDCIssuerARN = ""
@@ -501,14 +549,16 @@ func (ts *tester) createClient() (cli k8s_client.EKS, err error) {
 	fmt.Printf(ts.cfg.EKSConfig.Colorize("[light_green]createClient [default](%q)\n"), ts.cfg.EKSConfig.ConfigPath)
 	ts.cfg.EKSConfig.AuthenticationAPIVersion ="client.authentication.k8s.io/v1alpha1"
 
-	if ts.cfg.EKSConfig.AWSIAMAuthenticatorPath != "" && ts.cfg.EKSConfig.AWSIAMAuthenticatorDownloadURL != "" {
+	if ts.cfg.EKSConfig.IsEnabledAttach() && ts.cfg.EKSConfig.Attach.KubeConfigPath != "" {
+		ts.cfg.Logger.Info("using existing KUBECONFIG of attached cluster", zap.String("kubeconfig-path", ts.cfg.EKSConfig.KubeConfigPath))
+	} else if ts.cfg.EKSConfig.AWSIAMAuthenticatorPath != "" && ts.cfg.EKSConfig.AWSIAMAuthenticatorDownloadURL != "" {
 		tpl := template.Must(template.New("tmplKUBECONFIG").Parse(tmplKUBECONFIG))
 		buf := bytes.NewBuffer(nil)
 		if err = tpl.Execute(buf, kubeconfig{
 			ClusterAPIServerEndpoint: ts.cfg.EKSConfig.Status.ClusterAPIServerEndpoint,
 			ClusterCA:                ts.cfg.EKSConfig.Status.ClusterCA,
 			AWSIAMAuthenticatorPath:  ts.cfg.EKSConfig.AWSIAMAuthenticatorPath,
-			ClusterName:              ts.cfg.EKSConfig.Name,
+			ClusterName:              ts.cfg.EKSConfig.TokenClusterID(),
 			AuthenticationAPIVersion: ts.cfg.EKSConfig.AuthenticationAPIVersion,
 		}); err != nil {
 			return nil, err
@@ -523,6 +573,7 @@ func (ts *tester) createClient() (cli k8s_client.EKS, err error) {
 			ts.cfg.EKSConfig.S3.BucketName,
 			path.Join(ts.cfg.EKSConfig.Name, "kubeconfig.yaml"),
 			ts.cfg.EKSConfig.KubeConfigPath,
+			aws_s3.WithTags(ts.cfg.EKSConfig.Tags),
 		); err != nil {
 			return nil, err
 		}
@@ -572,6 +623,7 @@ func (ts *tester) createClient() (cli k8s_client.EKS, err error) {
 				ts.cfg.EKSConfig.S3.BucketName,
 				path.Join(ts.cfg.EKSConfig.Name, "kubeconfig.yaml"),
 				ts.cfg.EKSConfig.KubeConfigPath,
+				aws_s3.WithTags(ts.cfg.EKSConfig.Tags),
 			); err != nil {
 				return nil, err
 			}
@@ -586,11 +638,27 @@ func (ts *tester) createClient() (cli k8s_client.EKS, err error) {
 		fmt.Fprintf(ts.cfg.LogWriter, "\n\n'%s' output:\n\n%s\n\n", cmd, strings.TrimSpace(string(output)))
 	}
 
+	proxyURL := ""
+	// attached clusters must be reachable from the tester host
+	if ts.privateEndpointOnly() && !ts.cfg.EKSConfig.IsEnabledAttach() {
+		if ts.cfg.EKSConfig.Bastion == nil || ts.cfg.EKSConfig.Bastion.PublicIP == "" {
+			return nil, errors.New("private-only endpoint but no bastion host found")
+		}
+		if err = ts.startProxy(); err != nil {
+			return nil, err
+		}
+		if err = ts.setKubeConfigProxyURL(); err != nil {
+			return nil, err
+		}
+		proxyURL = ts.cfg.EKSConfig.Bastion.ProxyURL
+	}
+
 	ts.cfg.Logger.Info("creating k8s client")
 	kcfg := &k8s_client.EKSConfig{
 		Logger:                             ts.cfg.Logger,
 		Region:                             ts.cfg.EKSConfig.Region,
 		ClusterName:                        ts.cfg.EKSConfig.Name,
+		ClusterID:                          ts.cfg.EKSConfig.TokenClusterID(),
 		KubeConfigPath:                     ts.cfg.EKSConfig.KubeConfigPath,
 		KubectlPath:                        ts.cfg.EKSConfig.KubectlPath,
 		ServerVersion:                      ts.cfg.EKSConfig.Version,
@@ -603,6 +671,7 @@ func (ts *tester) createClient() (cli k8s_client.EKS, err error) {
 		ClientQPS:                          ts.cfg.EKSConfig.ClientQPS,
 		ClientBurst:                        ts.cfg.EKSConfig.ClientBurst,
 		ClientTimeout:                      ts.cfg.EKSConfig.ClientTimeout,
+		ProxyURL:                           proxyURL,
 	}
 	if ts.cfg.EKSConfig.IsEnabledAddOnClusterVersionUpgrade() {
 		kcfg.UpgradeServerVersion = ts.cfg.EKSConfig.AddOnClusterVersionUpgrade.Version
@@ -9,6 +9,8 @@ import (
 	"github.com/aws/aws-k8s-tester/eks/cluster/wait"
 	wait_v2 "github.com/aws/aws-k8s-tester/eks/cluster/wait-v2"
 	"github.com/aws/aws-k8s-tester/eksconfig"
+	"github.com/aws/aws-k8s-tester/pkg/ctxutil"
+	"github.com/aws/aws-k8s-tester/pkg/metrics"
 	"github.com/aws/aws-k8s-tester/pkg/timeutil"
 	"github.com/aws/aws-k8s-tester/pkg/user"
 	"github.com/aws/aws-k8s-tester/version"
@@ -25,6 +27,12 @@ import (
 const (
 	ClusterCreateTimeout = time.Hour
 	ClusterDeleteTimeout = time.Hour
+
+	// OutpostClusterCreateTimeout is the create timeout of the local clusters,
+	// whose control plane instances are launched and bootstrapped on the Outpost.
+	OutpostClusterCreateTimeout = 3 * time.Hour
+	// OutpostClusterDeleteTimeout is the delete timeout of the local clusters.
+	OutpostClusterDeleteTimeout = 2 * time.Hour
 )
 
 func (ts *tester) createEKS() (err error) {
@@ -55,13 +63,20 @@ func (ts *tester) createEKS() (err error) {
 		ts.cfg.EKSConfig.Status.TimeFrameCreate = timeutil.NewTimeFrame(createStart, createEnd)
 		ts.cfg.EKSConfig.Sync()
 	}()
-	initialWait := 9 * time.Minute
+	initialWait, createTimeout := 9*time.Minute, ClusterCreateTimeout
+
+	securityGroups := append([]string{ts.cfg.EKSConfig.VPC.SecurityGroupID}, ts.cfg.EKSConfig.VPC.AdditionalSecurityGroupIDs...)
 
 	subnets := make([]string, len(ts.cfg.EKSConfig.VPC.PublicSubnetIDs))
 	copy(subnets, ts.cfg.EKSConfig.VPC.PublicSubnetIDs)
 	if len(ts.cfg.EKSConfig.VPC.PrivateSubnetIDs) > 0 {
 		subnets = append(subnets, ts.cfg.EKSConfig.VPC.PrivateSubnetIDs...)
 	}
+	if ts.cfg.EKSConfig.IsEnabledOutpost() {
+		// control plane instances are launched in the Outpost subnets
+		subnets = append([]string{}, ts.cfg.EKSConfig.Outpost.SubnetIDs...)
+		createTimeout = OutpostClusterCreateTimeout
+	}
 
 	ts.cfg.Logger.Info("creating a cluster using EKS API",
 		zap.String("name", ts.cfg.EKSConfig.Name),
@@ -70,16 +85,23 @@ func (ts *tester) createEKS() (err error) {
 		zap.String("signing-name", ts.cfg.EKSConfig.SigningName),
 		zap.String("request-header-key", ts.cfg.EKSConfig.RequestHeaderKey),
 		zap.String("request-header-value", ts.cfg.EKSConfig.RequestHeaderValue),
+		zap.Bool("endpoint-public-access", ts.cfg.EKSConfig.EndpointPublicAccess),
+		zap.Strings("endpoint-public-access-cidrs", ts.cfg.EKSConfig.EndpointPublicAccessCIDRs),
+		zap.Bool("endpoint-private-access", ts.cfg.EKSConfig.EndpointPrivateAccess),
 	)
 
-	if ts.useV2SDK {
+	// "aws-sdk-go" has no "ComputeConfig", so Auto Mode clusters
+	// are always created with the v2 SDK
+	if ts.useV2SDK || ts.cfg.EKSConfig.IsEnabledAutoMode() {
 		createInput := &aws_eks_v2.CreateClusterInput{
 			Name:    aws_v2.String(ts.cfg.EKSConfig.Name),
 			Version: aws_v2.String(ts.cfg.EKSConfig.Version),
 			RoleArn: aws_v2.String(ts.cfg.EKSConfig.Role.ARN),
 			ResourcesVpcConfig: &aws_eks_v2_types.VpcConfigRequest{
-				SubnetIds:        subnets,
-				SecurityGroupIds: []string{ts.cfg.EKSConfig.VPC.SecurityGroupID},
+				SubnetIds:             subnets,
+				SecurityGroupIds:      securityGroups,
+				EndpointPublicAccess:  aws_v2.Bool(ts.cfg.EKSConfig.EndpointPublicAccess),
+				EndpointPrivateAccess: aws_v2.Bool(ts.cfg.EKSConfig.EndpointPrivateAccess),
 			},
 			Tags: map[string]string{
 				"Kind":                   "aws-k8s-tester",
@@ -94,6 +116,9 @@ func (ts *tester) createEKS() (err error) {
 				zap.String("value", v),
 			)
 		}
+		if len(ts.cfg.EKSConfig.EndpointPublicAccessCIDRs) > 0 {
+			createInput.ResourcesVpcConfig.PublicAccessCidrs = ts.cfg.EKSConfig.EndpointPublicAccessCIDRs
+		}
 		if ts.cfg.EKSConfig.Encryption.CMKARN != "" {
 			ts.cfg.Logger.Info("added encryption to EKS API request",
 				zap.String("cmk-arn", ts.cfg.EKSConfig.Encryption.CMKARN),
@@ -107,6 +132,50 @@ func (ts *tester) createEKS() (err error) {
 				},
 			}
 		}
+		if ts.isIPv6() {
+			ts.cfg.Logger.Info("added IPv6 family to EKS API request", zap.String("ip-family", ts.cfg.EKSConfig.IPFamily))
+			createInput.KubernetesNetworkConfig = &aws_eks_v2_types.KubernetesNetworkConfigRequest{
+				IpFamily: aws_eks_v2_types.IpFamilyIpv6,
+			}
+		}
+		if ts.cfg.EKSConfig.AuthenticationMode != "" {
+			ts.cfg.Logger.Info("added authentication mode to EKS API request", zap.String("authentication-mode", ts.cfg.EKSConfig.AuthenticationMode))
+			createInput.AccessConfig = &aws_eks_v2_types.CreateAccessConfigRequest{
+				AuthenticationMode: aws_eks_v2_types.AuthenticationMode(ts.cfg.EKSConfig.AuthenticationMode),
+				// keep the cluster creator as admin, as with "aws-auth" ConfigMap
+				BootstrapClusterCreatorAdminPermissions: aws_v2.Bool(true),
+			}
+		}
+		if ts.cfg.EKSConfig.IsEnabledAutoMode() {
+			ts.cfg.Logger.Info("added auto mode to EKS API request",
+				zap.Strings("node-pools", ts.cfg.EKSConfig.AutoMode.NodePools),
+				zap.String("node-role-arn", ts.cfg.EKSConfig.AutoModeNodeRoleARN()),
+			)
+			if err = addAutoModeConfig(createInput, ts.cfg.EKSConfig.AutoMode.NodePools, ts.cfg.EKSConfig.AutoModeNodeRoleARN()); err != nil {
+				return err
+			}
+		}
+		if ts.cfg.EKSConfig.IsEnabledOutpost() {
+			ts.cfg.Logger.Info("added outpost config to EKS API request",
+				zap.String("outpost-arn", ts.cfg.EKSConfig.Outpost.OutpostARN),
+				zap.String("control-plane-instance-type", ts.cfg.EKSConfig.Outpost.ControlPlaneInstanceType),
+			)
+			createInput.OutpostConfig = &aws_eks_v2_types.OutpostConfigRequest{
+				OutpostArns:              []string{ts.cfg.EKSConfig.Outpost.OutpostARN},
+				ControlPlaneInstanceType: aws_v2.String(ts.cfg.EKSConfig.Outpost.ControlPlaneInstanceType),
+			}
+			if ts.cfg.EKSConfig.Outpost.ControlPlanePlacementGroupName != "" {
+				createInput.OutpostConfig.ControlPlanePlacement = &aws_eks_v2_types.ControlPlanePlacementRequest{
+					GroupName: aws_v2.String(ts.cfg.EKSConfig.Outpost.ControlPlanePlacementGroupName),
+				}
+			}
+		}
+		if ts.cfg.EKSConfig.IsEnabledControlPlaneLogging() {
+			ts.cfg.Logger.Info("added control plane logging to EKS API request",
+				zap.Strings("log-types", ts.cfg.EKSConfig.ControlPlaneLogging.LogTypes),
+			)
+			createInput.Logging = ts.loggingRequestV2()
+		}
 		opts := make([]func(*aws_eks_v2.Options), 0)
 		if ts.cfg.EKSConfig.RequestHeaderKey != "" && ts.cfg.EKSConfig.RequestHeaderValue != "" {
 			ts.cfg.Logger.Info("set request header for EKS create request",
@@ -131,8 +200,10 @@ func (ts *tester) createEKS() (err error) {
 			Version: aws_v2.String(ts.cfg.EKSConfig.Version),
 			RoleArn: aws_v2.String(ts.cfg.EKSConfig.Role.ARN),
 			ResourcesVpcConfig: &aws_eks.VpcConfigRequest{
-				SubnetIds:        aws_v2.StringSlice(subnets),
-				SecurityGroupIds: aws_v2.StringSlice([]string{ts.cfg.EKSConfig.VPC.SecurityGroupID}),
+				SubnetIds:             aws_v2.StringSlice(subnets),
+				SecurityGroupIds:      aws_v2.StringSlice(securityGroups),
+				EndpointPublicAccess:  aws_v2.Bool(ts.cfg.EKSConfig.EndpointPublicAccess),
+				EndpointPrivateAccess: aws_v2.Bool(ts.cfg.EKSConfig.EndpointPrivateAccess),
 			},
 			Tags: map[string]*string{
 				"Kind":                   aws_v2.String("aws-k8s-tester"),
@@ -147,6 +218,9 @@ func (ts *tester) createEKS() (err error) {
 				zap.String("value", v),
 			)
 		}
+		if len(ts.cfg.EKSConfig.EndpointPublicAccessCIDRs) > 0 {
+			createInput.ResourcesVpcConfig.PublicAccessCidrs = aws_v2.StringSlice(ts.cfg.EKSConfig.EndpointPublicAccessCIDRs)
+		}
 		if ts.cfg.EKSConfig.Encryption.CMKARN != "" {
 			ts.cfg.Logger.Info("added encryption to EKS API request",
 				zap.String("cmk-arn", ts.cfg.EKSConfig.Encryption.CMKARN),
@@ -160,6 +234,41 @@ func (ts *tester) createEKS() (err error) {
 				},
 			}
 		}
+		if ts.cfg.EKSConfig.IsEnabledControlPlaneLogging() {
+			ts.cfg.Logger.Info("added control plane logging to EKS API request",
+				zap.Strings("log-types", ts.cfg.EKSConfig.ControlPlaneLogging.LogTypes),
+			)
+			createInput.Logging = ts.loggingRequest()
+		}
+		if ts.isIPv6() {
+			ts.cfg.Logger.Info("added IPv6 family to EKS API request", zap.String("ip-family", ts.cfg.EKSConfig.IPFamily))
+			createInput.KubernetesNetworkConfig = &aws_eks.KubernetesNetworkConfigRequest{
+				IpFamily: aws_v2.String(aws_eks.IpFamilyIpv6),
+			}
+		}
+		if ts.cfg.EKSConfig.AuthenticationMode != "" {
+			ts.cfg.Logger.Info("added authentication mode to EKS API request", zap.String("authentication-mode", ts.cfg.EKSConfig.AuthenticationMode))
+			createInput.AccessConfig = &aws_eks.CreateAccessConfigRequest{
+				AuthenticationMode: aws_v2.String(ts.cfg.EKSConfig.AuthenticationMode),
+				// keep the cluster creator as admin, as with "aws-auth" ConfigMap
+				BootstrapClusterCreatorAdminPermissions: aws_v2.Bool(true),
+			}
+		}
+		if ts.cfg.EKSConfig.IsEnabledOutpost() {
+			ts.cfg.Logger.Info("added outpost config to EKS API request",
+				zap.String("outpost-arn", ts.cfg.EKSConfig.Outpost.OutpostARN),
+				zap.String("control-plane-instance-type", ts.cfg.EKSConfig.Outpost.ControlPlaneInstanceType),
+			)
+			createInput.OutpostConfig = &aws_eks.OutpostConfigRequest{
+				OutpostArns:              aws_v2.StringSlice([]string{ts.cfg.EKSConfig.Outpost.OutpostARN}),
+				ControlPlaneInstanceType: aws_v2.String(ts.cfg.EKSConfig.Outpost.ControlPlaneInstanceType),
+			}
+			if ts.cfg.EKSConfig.Outpost.ControlPlanePlacementGroupName != "" {
+				createInput.OutpostConfig.ControlPlanePlacement = &aws_eks.ControlPlanePlacementRequest{
+					GroupName: aws_v2.String(ts.cfg.EKSConfig.Outpost.ControlPlanePlacementGroupName),
+				}
+			}
+		}
 		req, _ := ts.cfg.EKSAPI.CreateClusterRequest(createInput)
 		if ts.cfg.EKSConfig.RequestHeaderKey != "" && ts.cfg.EKSConfig.RequestHeaderValue != "" {
 			req.HTTPRequest.Header[ts.cfg.EKSConfig.RequestHeaderKey] = []string{ts.cfg.EKSConfig.RequestHeaderValue}
@@ -175,7 +284,7 @@ func (ts *tester) createEKS() (err error) {
 	}
 
 	ts.cfg.Logger.Info("sent create cluster request")
-	ctx, cancel := context.WithTimeout(context.Background(), ClusterCreateTimeout)
+	ctx, cancel := context.WithTimeout(context.Background(), createTimeout)
 	if ts.useV2SDK {
 		ch := wait_v2.Poll(
 			ctx,
@@ -193,9 +302,10 @@ func (ts *tester) createEKS() (err error) {
 			err = sv.Error
 		}
 	} else {
-		ch := wait.Poll(
-			ctx,
-			ts.cfg.Stopc,
+		apiLatency := metrics.NewLatencyRecorder()
+		pollCtx, pollCancel := ctxutil.WithStopc(ctx, ts.cfg.Stopc)
+		ch := wait.PollContext(
+			pollCtx,
 			ts.cfg.Logger,
 			ts.cfg.LogWriter,
 			ts.cfg.EKSAPI,
@@ -203,11 +313,15 @@ func (ts *tester) createEKS() (err error) {
 			aws_eks.ClusterStatusActive,
 			initialWait,
 			30*time.Second,
+			wait.WithAPILatency(observeAPILatency(apiLatency)),
+			wait.WithAutoInitialWait(""),
 		)
 		for sv := range ch {
 			ts.updateClusterStatusV1(sv, aws_eks.ClusterStatusActive)
 			err = sv.Error
 		}
+		pollCancel()
+		ts.recordAPILatency(apiLatency)
 	}
 	cancel()
 
@@ -300,7 +414,11 @@ func (ts *tester) deleteEKS() error {
 	ts.cfg.EKSConfig.Status.Up = false
 	ts.cfg.EKSConfig.Sync()
 
-	ctx, cancel := context.WithTimeout(context.Background(), ClusterDeleteTimeout)
+	deleteTimeout := ClusterDeleteTimeout
+	if ts.cfg.EKSConfig.IsEnabledOutpost() {
+		deleteTimeout = OutpostClusterDeleteTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), deleteTimeout)
 	if ts.useV2SDK {
 		csCh := wait_v2.Poll(
 			ctx,
@@ -317,9 +435,9 @@ func (ts *tester) deleteEKS() error {
 			ts.updateClusterStatusV2(v, eksconfig.ClusterStatusDELETEDORNOTEXIST)
 		}
 	} else {
-		csCh := wait.Poll(
-			ctx,
-			make(chan struct{}), // do not exit on stop
+		apiLatency := metrics.NewLatencyRecorder()
+		csCh := wait.PollContext(
+			ctx, // do not exit on stop
 			ts.cfg.Logger,
 			ts.cfg.LogWriter,
 			ts.cfg.EKSAPI,
@@ -327,10 +445,13 @@ func (ts *tester) deleteEKS() error {
 			eksconfig.ClusterStatusDELETEDORNOTEXIST,
 			5*time.Minute,
 			20*time.Second,
+			wait.WithAPILatency(observeAPILatency(apiLatency)),
+			wait.WithAutoInitialWait(""),
 		)
 		for v := range csCh {
 			ts.updateClusterStatusV1(v, eksconfig.ClusterStatusDELETEDORNOTEXIST)
 		}
+		ts.recordAPILatency(apiLatency)
 	}
 	cancel()
 
@@ -394,3 +515,25 @@ func (ts *tester) describeCluster() {
 		zap.String("status", ts.cfg.EKSConfig.Status.ClusterStatusCurrent),
 	)
 }
+
+// observeAPILatency returns the "wait.WithAPILatency" function
+// that records every waiter describe call into the recorder.
+func observeAPILatency(rec *metrics.LatencyRecorder) func(api string, took time.Duration, err error) {
+	return func(api string, took time.Duration, err error) {
+		rec.Observe(took, err)
+	}
+}
+
+// recordAPILatency merges the waiter API latency into the cluster status.
+func (ts *tester) recordAPILatency(rec *metrics.LatencyRecorder) {
+	rs := rec.Summary(time.Now().UTC().Format(time.RFC3339Nano))
+	if err := ts.cfg.EKSConfig.RecordClusterAPILatency(rs); err != nil {
+		ts.cfg.Logger.Warn("failed to record cluster API latency", zap.Error(err))
+		return
+	}
+	ts.cfg.Logger.Info("recorded cluster API latency",
+		zap.Float64("success-total", rs.SuccessTotal),
+		zap.Float64("failure-total", rs.FailureTotal),
+		zap.Duration("latency-p99", rs.LantencyP99),
+	)
+}
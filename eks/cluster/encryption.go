@@ -2,8 +2,10 @@ package cluster
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"path"
 	"strings"
 
 	"github.com/aws/aws-k8s-tester/pkg/user"
@@ -20,8 +22,12 @@ func (ts *tester) createEncryption() error {
 	fmt.Printf(ts.cfg.EKSConfig.Colorize("[light_green]createEncryption [default](%q)\n"), ts.cfg.EKSConfig.ConfigPath)
 
 	if !ts.cfg.EKSConfig.Encryption.CMKCreate {
-		ts.cfg.Logger.Info("Encryption.CMKCreate false; no need to create a new one")
-		return nil
+		if ts.cfg.EKSConfig.Encryption.CMKARN == "" {
+			ts.cfg.Logger.Info("Encryption.CMKCreate false; no need to create a new one")
+			return nil
+		}
+		ts.cfg.Logger.Info("Encryption.CMKCreate false; validating existing CMK", zap.String("cmk-arn", ts.cfg.EKSConfig.Encryption.CMKARN))
+		return ts.validateExistingCMK()
 	}
 
 	if ts.cfg.EKSConfig.Encryption.CMKARN != "" {
@@ -151,3 +157,167 @@ func getIDFromKeyARN(arn string) string {
 	}
 	return arn
 }
+
+// requiredCMKActions is the list of KMS actions that the cluster role
+// must be allowed for envelope encryption of Kubernetes secrets.
+// ref. https://docs.aws.amazon.com/eks/latest/userguide/enable-kms.html
+var requiredCMKActions = []string{
+	"kms:Encrypt",
+	"kms:Decrypt",
+	"kms:DescribeKey",
+	"kms:CreateGrant",
+}
+
+// validateCMKARN returns an error if the existing CMK is not referenced
+// by its key ARN or alias ARN, which are the formats accepted by
+// the EKS encryption config.
+// e.g. "arn:aws:kms:us-west-2:123:key/330e3b1a-61c4-4be6-93e0-244180c9f169"
+// e.g. "arn:aws:kms:us-west-2:123:alias/my-key"
+func validateCMKARN(arn string) error {
+	if strings.HasPrefix(arn, "arn:") && strings.Contains(arn, ":kms:") &&
+		(strings.Contains(arn, ":key/") || strings.Contains(arn, ":alias/")) {
+		return nil
+	}
+	return fmt.Errorf("Encryption.CMKCreate false; expect KMS key or alias ARN for Encryption.CMKARN but got %q", arn)
+}
+
+// validateExistingCMK checks that the existing CMK is an enabled symmetric key
+// and that its key policy permits the cluster role, either directly or
+// by delegating to the account's IAM policies.
+func (ts *tester) validateExistingCMK() error {
+	keyARN := ts.cfg.EKSConfig.Encryption.CMKARN
+	roleARN := ts.cfg.EKSConfig.Role.ARN
+	if err := validateCMKARN(keyARN); err != nil {
+		return err
+	}
+
+	dresp, err := ts.cfg.KMSAPIV2.DescribeKey(
+		context.Background(),
+		&aws_kms_v2.DescribeKeyInput{
+			KeyId: aws_v2.String(keyARN),
+		})
+	if err != nil {
+		ts.cfg.Logger.Warn("failed to describe CMK ARN", zap.Error(err))
+		return err
+	}
+	if dresp.KeyMetadata == nil {
+		return fmt.Errorf("CMK %q not found", keyARN)
+	}
+	if st := dresp.KeyMetadata.KeyState; st != aws_kms_v2_types.KeyStateEnabled {
+		return fmt.Errorf("CMK %q is in state %q (expected %q)", keyARN, st, aws_kms_v2_types.KeyStateEnabled)
+	}
+	if ku := dresp.KeyMetadata.KeyUsage; ku != aws_kms_v2_types.KeyUsageTypeEncryptDecrypt {
+		return fmt.Errorf("CMK %q has key usage %q (expected %q)", keyARN, ku, aws_kms_v2_types.KeyUsageTypeEncryptDecrypt)
+	}
+	if ks := dresp.KeyMetadata.CustomerMasterKeySpec; ks != aws_kms_v2_types.CustomerMasterKeySpecSymmetricDefault {
+		return fmt.Errorf("CMK %q has key spec %q (expected %q)", keyARN, ks, aws_kms_v2_types.CustomerMasterKeySpecSymmetricDefault)
+	}
+
+	presp, err := ts.cfg.KMSAPIV2.GetKeyPolicy(
+		context.Background(),
+		&aws_kms_v2.GetKeyPolicyInput{
+			KeyId:      aws_v2.String(keyARN),
+			PolicyName: aws_v2.String("default"),
+		})
+	if err != nil {
+		ts.cfg.Logger.Warn("failed to get CMK key policy", zap.Error(err))
+		return err
+	}
+	if err = checkKeyPolicy(aws_v2.ToString(presp.Policy), roleARN); err != nil {
+		ts.cfg.Logger.Warn("CMK key policy does not permit cluster role",
+			zap.String("cmk-arn", keyARN),
+			zap.String("role-arn", roleARN),
+			zap.Error(err),
+		)
+		return err
+	}
+
+	ts.cfg.Logger.Info("validated existing CMK",
+		zap.String("cmk-arn", keyARN),
+		zap.String("cmk-id", getIDFromKeyARN(keyARN)),
+		zap.String("role-arn", roleARN),
+	)
+	return nil
+}
+
+type keyPolicyDocument struct {
+	Statement []keyPolicyStatement `json:"Statement"`
+}
+
+type keyPolicyStatement struct {
+	Effect    string          `json:"Effect"`
+	Principal json.RawMessage `json:"Principal"`
+	Action    json.RawMessage `json:"Action"`
+}
+
+// checkKeyPolicy returns an error if none of the "Allow" statements
+// in the key policy grants the required actions to the role.
+// The account root principal delegates the access to IAM policies,
+// thus is considered as permitted.
+// ref. https://docs.aws.amazon.com/kms/latest/developerguide/key-policy-default.html
+func checkKeyPolicy(policy string, roleARN string) error {
+	var doc keyPolicyDocument
+	if err := json.Unmarshal([]byte(policy), &doc); err != nil {
+		return fmt.Errorf("failed to parse key policy %v", err)
+	}
+	principals := []string{"*", roleARN}
+	if ss := strings.Split(roleARN, ":"); len(ss) > 4 && ss[4] != "" {
+		principals = append(principals, ss[4], fmt.Sprintf("arn:%s:iam::%s:root", ss[1], ss[4]))
+	}
+
+	allowed := make(map[string]bool)
+	for _, st := range doc.Statement {
+		if st.Effect != "Allow" || !matchPrincipal(st.Principal, principals) {
+			continue
+		}
+		for _, pattern := range stringOrSlice(st.Action) {
+			for _, act := range requiredCMKActions {
+				if ok, _ := path.Match(strings.ToLower(pattern), strings.ToLower(act)); ok {
+					allowed[act] = true
+				}
+			}
+		}
+	}
+	var missing []string
+	for _, act := range requiredCMKActions {
+		if !allowed[act] {
+			missing = append(missing, act)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("key policy does not allow %v for role %q", missing, roleARN)
+	}
+	return nil
+}
+
+func matchPrincipal(raw json.RawMessage, principals []string) bool {
+	var vs []string
+	var m map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &m); err == nil {
+		vs = stringOrSlice(m["AWS"])
+	} else {
+		vs = stringOrSlice(raw)
+	}
+	for _, v := range vs {
+		for _, p := range principals {
+			if v == p {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// stringOrSlice parses the IAM policy element that is
+// either a single string or a list of strings.
+func stringOrSlice(raw json.RawMessage) []string {
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return []string{s}
+	}
+	var ss []string
+	if err := json.Unmarshal(raw, &ss); err == nil {
+		return ss
+	}
+	return nil
+}
@@ -0,0 +1,23 @@
+package cluster
+
+import "testing"
+
+func Test_validateCMKARN(t *testing.T) {
+	tt := []struct {
+		arn    string
+		expErr bool
+	}{
+		{"arn:aws:kms:us-west-2:123:key/330e3b1a-61c4-4be6-93e0-244180c9f169", false},
+		{"arn:aws:kms:us-west-2:123:alias/my-key", false},
+		{"arn:aws-cn:kms:cn-north-1:123:alias/my-key", false},
+		{"alias/my-key", true},
+		{"330e3b1a-61c4-4be6-93e0-244180c9f169", true},
+		{"arn:aws:iam::123:role/my-role", true},
+		{"", true},
+	}
+	for i, tv := range tt {
+		if err := validateCMKARN(tv.arn); (err != nil) != tv.expErr {
+			t.Fatalf("#%d: %q expected error %v, got %v", i, tv.arn, tv.expErr, err)
+		}
+	}
+}
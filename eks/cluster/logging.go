@@ -0,0 +1,262 @@
+package cluster
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-k8s-tester/eks/cluster/wait"
+	"github.com/aws/aws-k8s-tester/pkg/ctxutil"
+	aws_v2 "github.com/aws/aws-sdk-go-v2/aws"
+	aws_eks_v2_types "github.com/aws/aws-sdk-go-v2/service/eks/types"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/cloudwatchlogs"
+	aws_eks "github.com/aws/aws-sdk-go/service/eks"
+	"go.uber.org/zap"
+)
+
+// logStreamPrefixes maps the control plane log type
+// to the name prefix of its log streams.
+// ref. https://docs.aws.amazon.com/eks/latest/userguide/control-plane-logs.html
+var logStreamPrefixes = map[string]string{
+	aws_eks.LogTypeApi:               "kube-apiserver-",
+	aws_eks.LogTypeAudit:             "kube-apiserver-audit-",
+	aws_eks.LogTypeAuthenticator:     "authenticator-",
+	aws_eks.LogTypeControllerManager: "kube-controller-manager-",
+	aws_eks.LogTypeScheduler:         "kube-scheduler-",
+}
+
+// isLogStreamOfType returns true if the log stream belongs to the log type.
+// The "api" prefix also matches the "audit" log streams.
+func isLogStreamOfType(logType string, streamName string) bool {
+	prefix, ok := logStreamPrefixes[logType]
+	if !ok || !strings.HasPrefix(streamName, prefix) {
+		return false
+	}
+	if logType == aws_eks.LogTypeApi {
+		return !strings.HasPrefix(streamName, logStreamPrefixes[aws_eks.LogTypeAudit])
+	}
+	return true
+}
+
+// disabledLogTypes returns the log types, not yet enabled in the cluster.
+func disabledLogTypes(logging *aws_eks.Logging, logTypes []string) (disabled []string) {
+	enabled := make(map[string]struct{})
+	if logging != nil {
+		for _, setup := range logging.ClusterLogging {
+			if !aws_v2.ToBool(setup.Enabled) {
+				continue
+			}
+			for _, tp := range setup.Types {
+				enabled[aws_v2.ToString(tp)] = struct{}{}
+			}
+		}
+	}
+	for _, tp := range logTypes {
+		if _, ok := enabled[tp]; !ok {
+			disabled = append(disabled, tp)
+		}
+	}
+	sort.Strings(disabled)
+	return disabled
+}
+
+func (ts *tester) loggingRequest() *aws_eks.Logging {
+	return &aws_eks.Logging{
+		ClusterLogging: []*aws_eks.LogSetup{
+			{
+				Enabled: aws_v2.Bool(true),
+				Types:   aws_v2.StringSlice(ts.cfg.EKSConfig.ControlPlaneLogging.LogTypes),
+			},
+		},
+	}
+}
+
+func (ts *tester) loggingRequestV2() *aws_eks_v2_types.Logging {
+	types := make([]aws_eks_v2_types.LogType, 0, len(ts.cfg.EKSConfig.ControlPlaneLogging.LogTypes))
+	for _, tp := range ts.cfg.EKSConfig.ControlPlaneLogging.LogTypes {
+		types = append(types, aws_eks_v2_types.LogType(tp))
+	}
+	return &aws_eks_v2_types.Logging{
+		ClusterLogging: []aws_eks_v2_types.LogSetup{
+			{
+				Enabled: aws_v2.Bool(true),
+				Types:   types,
+			},
+		},
+	}
+}
+
+// updateControlPlaneLogging enables the control plane log types
+// not yet enabled in the existing cluster (e.g. attached, or created
+// by the previous run without logging), and waits for the update.
+func (ts *tester) updateControlPlaneLogging() error {
+	if !ts.cfg.EKSConfig.IsEnabledControlPlaneLogging() {
+		return nil
+	}
+	out, err := ts.cfg.EKSAPI.DescribeCluster(&aws_eks.DescribeClusterInput{
+		Name: aws_v2.String(ts.cfg.EKSConfig.Name),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to describe cluster for control plane logging (%v)", err)
+	}
+	disabled := disabledLogTypes(out.Cluster.Logging, ts.cfg.EKSConfig.ControlPlaneLogging.LogTypes)
+	if len(disabled) == 0 {
+		ts.cfg.Logger.Info("control plane logging already enabled", zap.Strings("log-types", ts.cfg.EKSConfig.ControlPlaneLogging.LogTypes))
+		return nil
+	}
+
+	ts.cfg.Logger.Info("enabling control plane logging", zap.Strings("log-types", disabled))
+	updateOut, err := ts.cfg.EKSAPI.UpdateClusterConfig(&aws_eks.UpdateClusterConfigInput{
+		Name:    aws_v2.String(ts.cfg.EKSConfig.Name),
+		Logging: ts.loggingRequest(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to enable control plane logging (%v)", err)
+	}
+	reqID := ""
+	if updateOut.Update != nil {
+		reqID = aws_v2.ToString(updateOut.Update.Id)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Minute)
+	ctx, stopCancel := ctxutil.WithStopc(ctx, ts.cfg.Stopc)
+	_, err = wait.WaitForUpdateStatus(
+		ctx,
+		ts.cfg.Logger,
+		ts.cfg.LogWriter,
+		ts.cfg.EKSAPI,
+		ts.cfg.EKSConfig.Name,
+		reqID,
+		aws_eks.UpdateStatusSuccessful,
+		30*time.Second,
+		20*time.Second,
+	)
+	stopCancel()
+	cancel()
+	if err != nil {
+		return fmt.Errorf("failed to wait for control plane logging update %q (%v)", reqID, err)
+	}
+	ts.cfg.Logger.Info("enabled control plane logging", zap.String("request-id", reqID))
+	return nil
+}
+
+// verifyControlPlaneLogging waits for the log streams of each verified
+// log type to receive events, and fails if logging silently does not work
+// (e.g. missing log group permissions, or log types not applied).
+func (ts *tester) verifyControlPlaneLogging() error {
+	if !ts.cfg.EKSConfig.IsEnabledControlPlaneLogging() {
+		return nil
+	}
+	cur := ts.cfg.EKSConfig.ControlPlaneLogging
+	if len(cur.VerifyLogTypes) == 0 {
+		ts.cfg.Logger.Info("skipping control plane logging verification")
+		return nil
+	}
+	// always re-verified, since logging may be disabled after the previous run
+	cur.VerifiedLogStreams = make(map[string]string)
+	ts.cfg.Logger.Info("verifying control plane logging",
+		zap.String("log-group-name", cur.LogGroupName),
+		zap.Strings("verify-log-types", cur.VerifyLogTypes),
+		zap.Duration("verify-timeout", cur.VerifyTimeout),
+	)
+
+	retryStart := time.Now()
+	for {
+		pending := make([]string, 0, len(cur.VerifyLogTypes))
+		for _, tp := range cur.VerifyLogTypes {
+			if _, ok := cur.VerifiedLogStreams[tp]; ok {
+				continue
+			}
+			stream, err := ts.findLogStreamWithEvents(tp)
+			if err != nil {
+				ts.cfg.Logger.Warn("failed to find log events", zap.String("log-type", tp), zap.Error(err))
+			}
+			if stream == "" {
+				pending = append(pending, tp)
+				continue
+			}
+			ts.cfg.Logger.Info("verified control plane log events", zap.String("log-type", tp), zap.String("log-stream-name", stream))
+			cur.VerifiedLogStreams[tp] = stream
+		}
+		ts.cfg.EKSConfig.Sync()
+		if len(pending) == 0 {
+			break
+		}
+		if time.Since(retryStart) > cur.VerifyTimeout {
+			return fmt.Errorf("control plane log types %q in log group %q received no events after %v", pending, cur.LogGroupName, cur.VerifyTimeout)
+		}
+		ts.cfg.Logger.Info("waiting for control plane log events", zap.Strings("log-types", pending))
+		select {
+		case <-ts.cfg.Stopc:
+			return errors.New("control plane logging verification aborted")
+		case <-time.After(30 * time.Second):
+		}
+	}
+
+	ts.cfg.Logger.Info("verified control plane logging", zap.Any("log-streams", cur.VerifiedLogStreams))
+	return nil
+}
+
+// findLogStreamWithEvents returns the name of the log stream of the log type
+// that has received events, or empty if none yet.
+func (ts *tester) findLogStreamWithEvents(logType string) (string, error) {
+	groupName := ts.cfg.EKSConfig.ControlPlaneLogging.LogGroupName
+	streams := make([]string, 0)
+	err := ts.cfg.LogsAPI.DescribeLogStreamsPages(
+		&cloudwatchlogs.DescribeLogStreamsInput{
+			LogGroupName:        aws_v2.String(groupName),
+			LogStreamNamePrefix: aws_v2.String(logStreamPrefixes[logType]),
+		},
+		func(out *cloudwatchlogs.DescribeLogStreamsOutput, lastPage bool) bool {
+			for _, s := range out.LogStreams {
+				if name := aws_v2.ToString(s.LogStreamName); isLogStreamOfType(logType, name) {
+					streams = append(streams, name)
+				}
+			}
+			return true
+		},
+	)
+	if err != nil {
+		if aerr, ok := err.(awserr.Error); ok && aerr.Code() == cloudwatchlogs.ErrCodeResourceNotFoundException {
+			// log group is created on the first log delivery
+			return "", nil
+		}
+		return "", err
+	}
+
+	// "FilterLogEvents" accepts up to 100 log stream names
+	for len(streams) > 0 {
+		n := len(streams)
+		if n > 100 {
+			n = 100
+		}
+		found := ""
+		// a page may be empty while the search continues with the next token
+		err = ts.cfg.LogsAPI.FilterLogEventsPages(
+			&cloudwatchlogs.FilterLogEventsInput{
+				LogGroupName:   aws_v2.String(groupName),
+				LogStreamNames: aws_v2.StringSlice(streams[:n]),
+				Limit:          aws_v2.Int64(1),
+			},
+			func(out *cloudwatchlogs.FilterLogEventsOutput, lastPage bool) bool {
+				if len(out.Events) > 0 {
+					found = aws_v2.ToString(out.Events[0].LogStreamName)
+					return false
+				}
+				return true
+			},
+		)
+		if err != nil {
+			return "", err
+		}
+		if found != "" {
+			return found, nil
+		}
+		streams = streams[n:]
+	}
+	return "", nil
+}
@@ -0,0 +1,51 @@
+package cluster
+
+import (
+	"reflect"
+	"testing"
+
+	aws_v2 "github.com/aws/aws-sdk-go-v2/aws"
+	aws_eks "github.com/aws/aws-sdk-go/service/eks"
+)
+
+func TestIsLogStreamOfType(t *testing.T) {
+	tt := []struct {
+		logType string
+		stream  string
+		exp     bool
+	}{
+		{"api", "kube-apiserver-0123abcd", true},
+		{"api", "kube-apiserver-audit-0123abcd", false},
+		{"audit", "kube-apiserver-audit-0123abcd", true},
+		{"audit", "kube-apiserver-0123abcd", false},
+		{"authenticator", "authenticator-0123abcd", true},
+		{"controllerManager", "kube-controller-manager-0123abcd", true},
+		{"scheduler", "kube-scheduler-0123abcd", true},
+		{"scheduler", "kube-controller-manager-0123abcd", false},
+		{"unknown", "kube-apiserver-0123abcd", false},
+	}
+	for i, tv := range tt {
+		if v := isLogStreamOfType(tv.logType, tv.stream); v != tv.exp {
+			t.Fatalf("#%d: expected %v for %q in %q, got %v", i, tv.exp, tv.logType, tv.stream, v)
+		}
+	}
+}
+
+func TestDisabledLogTypes(t *testing.T) {
+	logging := &aws_eks.Logging{
+		ClusterLogging: []*aws_eks.LogSetup{
+			{Enabled: aws_v2.Bool(true), Types: aws_v2.StringSlice([]string{"api", "scheduler"})},
+			{Enabled: aws_v2.Bool(false), Types: aws_v2.StringSlice([]string{"audit", "authenticator"})},
+		},
+	}
+	disabled := disabledLogTypes(logging, []string{"scheduler", "audit", "api", "authenticator"})
+	if !reflect.DeepEqual(disabled, []string{"audit", "authenticator"}) {
+		t.Fatalf("unexpected disabled log types %q", disabled)
+	}
+	if disabled = disabledLogTypes(nil, []string{"api"}); !reflect.DeepEqual(disabled, []string{"api"}) {
+		t.Fatalf("unexpected disabled log types %q", disabled)
+	}
+	if disabled = disabledLogTypes(logging, []string{"api"}); len(disabled) != 0 {
+		t.Fatalf("unexpected disabled log types %q", disabled)
+	}
+}
@@ -0,0 +1,224 @@
+package cluster
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	aws_ec2 "github.com/aws/aws-k8s-tester/pkg/aws/ec2"
+	aws_v2 "github.com/aws/aws-sdk-go-v2/aws"
+	aws_ec2_v2 "github.com/aws/aws-sdk-go-v2/service/ec2"
+	aws_ec2_v2_types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"go.uber.org/zap"
+)
+
+// createPodSubnets associates the secondary CIDR with the VPC, and creates
+// the pod subnets for the VPC CNI custom networking, in the same availability
+// zones as the public subnets. The pod subnets share the public route table,
+// since the pod traffic out of the VPC is SNAT-ed to the node primary IP.
+func (ts *tester) createPodSubnets() error {
+	if !ts.cfg.EKSConfig.IsEnabledCNICustomNetworking() {
+		return nil
+	}
+	cur := ts.cfg.EKSConfig.CNICustomNetworking
+	if len(cur.PodSubnetIDs) > 0 {
+		ts.cfg.Logger.Info("pod subnets already created; skipping", zap.Strings("pod-subnet-ids", cur.PodSubnetIDs))
+		return nil
+	}
+
+	// AWS::EC2::VPCCidrBlock
+	ts.cfg.Logger.Info("associating secondary VPC CIDR block", zap.String("cidr-block", cur.SecondaryCIDR))
+	aout, err := ts.cfg.EC2APIV2.AssociateVpcCidrBlock(
+		context.Background(),
+		&aws_ec2_v2.AssociateVpcCidrBlockInput{
+			VpcId:     aws_v2.String(ts.cfg.EKSConfig.VPC.ID),
+			CidrBlock: aws_v2.String(cur.SecondaryCIDR),
+		},
+	)
+	if err != nil {
+		ts.cfg.Logger.Warn("failed to associate secondary VPC CIDR block", zap.Error(err))
+		return err
+	}
+	cur.CIDRAssociationID = aws_v2.ToString(aout.CidrBlockAssociation.AssociationId)
+	ts.cfg.EKSConfig.Sync()
+	if err = ts.waitSecondaryCIDRAssociated(2 * time.Minute); err != nil {
+		return err
+	}
+
+	// AWS::EC2::Subnet
+	cur.PodSubnetIDs = make([]string, 0, len(cur.PodSubnetCIDRs))
+	cur.PodSubnetRouteTableAssociationIDs = make([]string, 0, len(cur.PodSubnetCIDRs))
+	for idx, cidr := range cur.PodSubnetCIDRs {
+		az := ts.cfg.EKSConfig.AvailabilityZoneNames[idx]
+		sout, err := ts.cfg.EC2APIV2.CreateSubnet(
+			context.Background(),
+			&aws_ec2_v2.CreateSubnetInput{
+				VpcId:            aws_v2.String(ts.cfg.EKSConfig.VPC.ID),
+				AvailabilityZone: aws_v2.String(az),
+				CidrBlock:        aws_v2.String(cidr),
+				TagSpecifications: []aws_ec2_v2_types.TagSpecification{
+					{
+						ResourceType: aws_ec2_v2_types.ResourceTypeSubnet,
+						Tags: aws_ec2.AppendTags([]aws_ec2_v2_types.Tag{
+							{
+								Key:   aws_v2.String("Name"),
+								Value: aws_v2.String(fmt.Sprintf("%s-pod-subnet-%d", ts.cfg.EKSConfig.Name, idx+1)),
+							},
+							{
+								Key:   aws_v2.String("Network"),
+								Value: aws_v2.String("Pod"),
+							},
+						}, ts.cfg.EKSConfig.Tags),
+					},
+				},
+			},
+		)
+		if err != nil {
+			ts.cfg.Logger.Warn("failed to create pod subnet", zap.String("availability-zone", az), zap.Error(err))
+			return err
+		}
+		subnetID := aws_v2.ToString(sout.Subnet.SubnetId)
+		cur.PodSubnetIDs = append(cur.PodSubnetIDs, subnetID)
+		ts.cfg.EKSConfig.Sync()
+		ts.cfg.Logger.Info("created a pod subnet", zap.String("availability-zone", az), zap.String("subnet-id", subnetID))
+
+		// AWS::EC2::SubnetRouteTableAssociation
+		rout, err := ts.cfg.EC2APIV2.AssociateRouteTable(
+			context.Background(),
+			&aws_ec2_v2.AssociateRouteTableInput{
+				SubnetId:     aws_v2.String(subnetID),
+				RouteTableId: aws_v2.String(ts.cfg.EKSConfig.VPC.PublicRouteTableID),
+			},
+		)
+		if err != nil {
+			ts.cfg.Logger.Warn("failed to associate route table", zap.Error(err))
+			return err
+		}
+		cur.PodSubnetRouteTableAssociationIDs = append(cur.PodSubnetRouteTableAssociationIDs, aws_v2.ToString(rout.AssociationId))
+		ts.cfg.EKSConfig.Sync()
+	}
+
+	ts.cfg.Logger.Info("created pod subnets",
+		zap.String("secondary-cidr", cur.SecondaryCIDR),
+		zap.Strings("pod-subnet-ids", cur.PodSubnetIDs),
+	)
+	return nil
+}
+
+// waitSecondaryCIDRAssociated waits for the secondary CIDR association,
+// since the subnets cannot be created in the CIDR while "associating".
+func (ts *tester) waitSecondaryCIDRAssociated(timeout time.Duration) error {
+	associationID := ts.cfg.EKSConfig.CNICustomNetworking.CIDRAssociationID
+	retryStart := time.Now()
+	for time.Since(retryStart) < timeout {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		out, err := ts.cfg.EC2APIV2.DescribeVpcs(
+			ctx,
+			&aws_ec2_v2.DescribeVpcsInput{
+				VpcIds: []string{ts.cfg.EKSConfig.VPC.ID},
+			},
+		)
+		cancel()
+		if err != nil {
+			ts.cfg.Logger.Warn("failed to describe VPC", zap.Error(err))
+		} else if len(out.Vpcs) == 1 {
+			for _, av := range out.Vpcs[0].CidrBlockAssociationSet {
+				if aws_v2.ToString(av.AssociationId) != associationID || av.CidrBlockState == nil {
+					continue
+				}
+				state := av.CidrBlockState.State
+				ts.cfg.Logger.Info("polling secondary VPC CIDR block", zap.String("association-id", associationID), zap.String("state", string(state)))
+				switch state {
+				case aws_ec2_v2_types.VpcCidrBlockStateCodeAssociated:
+					return nil
+				case aws_ec2_v2_types.VpcCidrBlockStateCodeFailed, aws_ec2_v2_types.VpcCidrBlockStateCodeFailing:
+					return fmt.Errorf("secondary VPC CIDR block association %q %s (%s)", associationID, state, aws_v2.ToString(av.CidrBlockState.StatusMessage))
+				}
+			}
+		}
+
+		select {
+		case <-time.After(5 * time.Second):
+		case <-ts.cfg.Stopc:
+			return errors.New("stopped")
+		}
+	}
+	return fmt.Errorf("secondary VPC CIDR block association %q not associated after %v", associationID, timeout)
+}
+
+// deletePodSubnets deletes the pod subnets and disassociates the secondary CIDR.
+// Must be run after the ENIs in the pod subnets are deleted,
+// and before the public route table deletion.
+func (ts *tester) deletePodSubnets() (err error) {
+	if ts.cfg.EKSConfig.CNICustomNetworking == nil {
+		return nil
+	}
+	cur := ts.cfg.EKSConfig.CNICustomNetworking
+	ts.cfg.Logger.Info("deleting pod subnets", zap.Strings("pod-subnet-ids", cur.PodSubnetIDs))
+	if ts.cfg.EKSConfig.VPC.ID == "" {
+		return nil
+	}
+
+	var errs []string
+	for _, id := range cur.PodSubnetRouteTableAssociationIDs {
+		if _, ok := ts.cfg.EKSConfig.Status.DeletedResources[id]; ok {
+			continue
+		}
+		_, err = ts.cfg.EC2APIV2.DisassociateRouteTable(
+			context.Background(),
+			&aws_ec2_v2.DisassociateRouteTableInput{
+				AssociationId: aws_v2.String(id),
+			},
+		)
+		if err != nil && !isNotFound(err) {
+			ts.cfg.Logger.Warn("failed to disassociate route table", zap.Error(err))
+			errs = append(errs, err.Error())
+			continue
+		}
+		ts.cfg.EKSConfig.Status.DeletedResources[id] = "CNICustomNetworking.PodSubnetRouteTableAssociationID"
+		ts.cfg.EKSConfig.Sync()
+	}
+	for _, subnet := range cur.PodSubnetIDs {
+		if _, ok := ts.cfg.EKSConfig.Status.DeletedResources[subnet]; ok {
+			continue
+		}
+		_, err = ts.cfg.EC2APIV2.DeleteSubnet(
+			context.Background(),
+			&aws_ec2_v2.DeleteSubnetInput{
+				SubnetId: aws_v2.String(subnet),
+			},
+		)
+		if err != nil && !isNotFound(err) {
+			ts.cfg.Logger.Warn("failed to delete pod subnet", zap.Error(err))
+			errs = append(errs, err.Error())
+			continue
+		}
+		ts.cfg.EKSConfig.Status.DeletedResources[subnet] = "CNICustomNetworking.PodSubnetID"
+		ts.cfg.EKSConfig.Sync()
+	}
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, ","))
+	}
+
+	if id := cur.CIDRAssociationID; id != "" {
+		if _, ok := ts.cfg.EKSConfig.Status.DeletedResources[id]; !ok {
+			_, err = ts.cfg.EC2APIV2.DisassociateVpcCidrBlock(
+				context.Background(),
+				&aws_ec2_v2.DisassociateVpcCidrBlockInput{
+					AssociationId: aws_v2.String(id),
+				},
+			)
+			if err != nil && !isNotFound(err) {
+				ts.cfg.Logger.Warn("failed to disassociate secondary VPC CIDR block", zap.Error(err))
+				return err
+			}
+			ts.cfg.EKSConfig.Status.DeletedResources[id] = "CNICustomNetworking.CIDRAssociationID"
+			ts.cfg.EKSConfig.Sync()
+		}
+	}
+
+	ts.cfg.Logger.Info("deleted pod subnets")
+	return nil
+}
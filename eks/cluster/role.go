@@ -8,6 +8,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/aws/aws-k8s-tester/eksconfig"
 	aws_iam "github.com/aws/aws-k8s-tester/pkg/aws/iam"
 	aws_v2 "github.com/aws/aws-sdk-go-v2/aws"
 	aws_iam_v2 "github.com/aws/aws-sdk-go-v2/service/iam"
@@ -23,16 +24,23 @@ func (ts *tester) createRole() error {
 
 	if !ts.cfg.EKSConfig.Role.Create {
 		ts.cfg.Logger.Info("Role.Create false; skipping creation")
+		policyARNs := []string{
+			// Prior to April 16, 2020, AmazonEKSServicePolicy was also required and the suggested name was eksServiceRole. With the AWSServiceRoleForAmazonEKS service-linked role, that policy is no longer required for clusters created on or after April 16, 2020.
+			// ref. https://docs.aws.amazon.com/eks/latest/userguide/service_IAM_role.html
+			"arn:aws:iam::aws:policy/AmazonEKSClusterPolicy",
+		}
+		if ts.cfg.EKSConfig.IsEnabledAutoMode() {
+			policyARNs = eksconfig.AutoModeManagedPolicyARNs
+		}
+		if ts.cfg.EKSConfig.IsEnabledSecurityGroupsForPods() {
+			policyARNs = append(policyARNs, eksconfig.SecurityGroupsForPodsPolicyARN)
+		}
 		return aws_iam.ValidateV2(
 			ts.cfg.Logger,
 			ts.cfg.IAMAPIV2,
 			ts.cfg.EKSConfig.Role.Name,
 			[]string{"eks.amazonaws.com"},
-			[]string{
-				// Prior to April 16, 2020, AmazonEKSServicePolicy was also required and the suggested name was eksServiceRole. With the AWSServiceRoleForAmazonEKS service-linked role, that policy is no longer required for clusters created on or after April 16, 2020.
-				// ref. https://docs.aws.amazon.com/eks/latest/userguide/service_IAM_role.html
-				"arn:aws:iam::aws:policy/AmazonEKSClusterPolicy",
-			},
+			policyARNs,
 		)
 	}
 	if ts.cfg.EKSConfig.Role.ARN != "" {
@@ -95,14 +103,15 @@ func (ts *tester) deleteRole() error {
 
 func (ts *tester) _createRole() error {
 	ts.cfg.Logger.Info("creating role", zap.String("name", ts.cfg.EKSConfig.Role.Name))
-	out, err := ts.cfg.IAMAPIV2.CreateRole(
-		context.Background(),
-		&aws_iam_v2.CreateRoleInput{
-			RoleName:                 aws_v2.String(ts.cfg.EKSConfig.Role.Name),
-			Path:                     aws_v2.String("/"),
-			AssumeRolePolicyDocument: aws_v2.String(createAssumeRolePolicyDocument(ts.cfg.EKSConfig.Role.ServicePrincipals)),
-		},
-	)
+	input := &aws_iam_v2.CreateRoleInput{
+		RoleName:                 aws_v2.String(ts.cfg.EKSConfig.Role.Name),
+		Path:                     aws_v2.String(ts.cfg.EKSConfig.IAMPath),
+		AssumeRolePolicyDocument: aws_v2.String(createAssumeRolePolicyDocument(ts.cfg.EKSConfig.Role.ServicePrincipals, ts.cfg.EKSConfig.IsEnabledAutoMode())),
+	}
+	if ts.cfg.EKSConfig.IAMPermissionsBoundaryARN != "" {
+		input.PermissionsBoundary = aws_v2.String(ts.cfg.EKSConfig.IAMPermissionsBoundaryARN)
+	}
+	out, err := ts.cfg.IAMAPIV2.CreateRole(context.Background(), input)
 	if err != nil {
 		return err
 	}
@@ -269,10 +278,10 @@ func (ts *tester) detachPolicy() error {
 	return nil
 }
 
-func createAssumeRolePolicyDocument(sps []string) string {
+func createAssumeRolePolicyDocument(sps []string, autoMode bool) string {
 	p := aws_iam.PolicyDocument{
 		Version:   "2012-10-17",
-		Statement: createStatementEntriesForAssumeRole(sps),
+		Statement: createStatementEntriesForAssumeRole(sps, autoMode),
 	}
 	b, err := json.Marshal(p)
 	if err != nil {
@@ -293,16 +302,23 @@ func createRolePolicyDocument(partition string, bucketName string) string {
 	return string(b)
 }
 
-func createStatementEntriesForAssumeRole(sps []string) []aws_iam.StatementEntry {
+// EKS Auto Mode requires "sts:TagSession" to tag the EC2 instances
+// with the cluster role session.
+// ref. https://docs.aws.amazon.com/eks/latest/userguide/auto-cluster-iam-role.html
+func createStatementEntriesForAssumeRole(sps []string, autoMode bool) []aws_iam.StatementEntry {
+	actions := []string{
+		"sts:AssumeRole",
+	}
+	if autoMode {
+		actions = append(actions, "sts:TagSession")
+	}
 	return []aws_iam.StatementEntry{
 		{
 			Effect: "Allow",
 			Principal: &aws_iam.PrincipalEntry{
 				Service: sps,
 			},
-			Action: []string{
-				"sts:AssumeRole",
-			},
+			Action: actions,
 		},
 	}
 }
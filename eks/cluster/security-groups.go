@@ -7,6 +7,7 @@ import (
 	"strings"
 	"time"
 
+	aws_ec2 "github.com/aws/aws-k8s-tester/pkg/aws/ec2"
 	aws_v2 "github.com/aws/aws-sdk-go-v2/aws"
 	aws_ec2_v2 "github.com/aws/aws-sdk-go-v2/service/ec2"
 	aws_ec2_v2_types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
@@ -24,6 +25,17 @@ func (ts *tester) createSecurityGroups() error {
 			GroupName:   aws_v2.String(fmt.Sprintf("%s-security-group", ts.cfg.EKSConfig.Name)),
 			Description: aws_v2.String("Communication between EKS Kubernetes control plane and worker nodes"),
 			VpcId:       aws_v2.String(ts.cfg.EKSConfig.VPC.ID),
+			TagSpecifications: []aws_ec2_v2_types.TagSpecification{
+				{
+					ResourceType: aws_ec2_v2_types.ResourceTypeSecurityGroup,
+					Tags: aws_ec2.AppendTags([]aws_ec2_v2_types.Tag{
+						{
+							Key:   aws_v2.String("Name"),
+							Value: aws_v2.String(fmt.Sprintf("%s-security-group", ts.cfg.EKSConfig.Name)),
+						},
+					}, ts.cfg.EKSConfig.Tags),
+				},
+			},
 		},
 	)
 	if err != nil {
@@ -12,7 +12,9 @@ import (
 	"github.com/aws/aws-k8s-tester/eks/cluster/wait"
 	eks_tester "github.com/aws/aws-k8s-tester/eks/tester"
 	"github.com/aws/aws-k8s-tester/eksconfig"
+	"github.com/aws/aws-k8s-tester/pkg/ctxutil"
 	k8s_client "github.com/aws/aws-k8s-tester/pkg/k8s-client"
+	"github.com/aws/aws-k8s-tester/pkg/metrics"
 	"github.com/aws/aws-k8s-tester/pkg/spinner"
 	"github.com/aws/aws-k8s-tester/pkg/timeutil"
 	"github.com/aws/aws-sdk-go/aws"
@@ -106,9 +108,10 @@ func (ts *tester) Create() (err error) {
 
 	// enough time for upgrade fail/rollback
 	ctx, cancel := context.WithTimeout(context.Background(), time.Hour+30*time.Minute)
-	ch := wait.PollUpdate(
+	ctx, stopCancel := ctxutil.WithStopc(ctx, ts.cfg.Stopc)
+	apiLatency := metrics.NewLatencyRecorder()
+	_, err = wait.WaitForUpdateStatus(
 		ctx,
-		ts.cfg.Stopc,
 		ts.cfg.Logger,
 		ts.cfg.LogWriter,
 		ts.cfg.EKSAPI,
@@ -117,11 +120,16 @@ func (ts *tester) Create() (err error) {
 		eks.UpdateStatusSuccessful,
 		initialWait,
 		30*time.Second,
+		wait.WithAPILatency(func(api string, took time.Duration, err error) {
+			apiLatency.Observe(took, err)
+		}),
+		wait.WithAutoInitialWait(ts.cfg.EKSConfig.AddOnClusterVersionUpgrade.Version),
 	)
-	for sv := range ch {
-		err = sv.Error
-	}
+	stopCancel()
 	cancel()
+	if rerr := ts.cfg.EKSConfig.RecordClusterAPILatency(apiLatency.Summary(time.Now().UTC().Format(time.RFC3339Nano))); rerr != nil {
+		ts.cfg.Logger.Warn("failed to record cluster API latency", zap.Error(rerr))
+	}
 	if err != nil {
 		return fmt.Errorf("Cluster %q update failed %v", ts.cfg.EKSConfig.Name, err)
 	}
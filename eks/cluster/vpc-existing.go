@@ -0,0 +1,121 @@
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	aws_v2 "github.com/aws/aws-sdk-go-v2/aws"
+	aws_ec2_v2 "github.com/aws/aws-sdk-go-v2/service/ec2"
+	aws_ec2_v2_types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"go.uber.org/zap"
+)
+
+// minSubnetFreeIPs is the minimum number of free IP addresses
+// that EKS requires in each subnet for the cross-account ENIs.
+// ref. https://docs.aws.amazon.com/eks/latest/userguide/network_reqs.html
+const minSubnetFreeIPs = 6
+
+// validateExistingVPC checks that the existing VPC, subnets, and security groups
+// satisfy EKS requirements, before creating a cluster in them.
+// ref. https://docs.aws.amazon.com/eks/latest/userguide/network_reqs.html
+func (ts *tester) validateExistingVPC() error {
+	ts.cfg.Logger.Info("validating existing VPC",
+		zap.String("vpc-id", ts.cfg.EKSConfig.VPC.ID),
+		zap.Strings("public-subnet-ids", ts.cfg.EKSConfig.VPC.PublicSubnetIDs),
+		zap.Strings("private-subnet-ids", ts.cfg.EKSConfig.VPC.PrivateSubnetIDs),
+		zap.String("security-group-id", ts.cfg.EKSConfig.VPC.SecurityGroupID),
+		zap.Strings("additional-security-group-ids", ts.cfg.EKSConfig.VPC.AdditionalSecurityGroupIDs),
+	)
+
+	// nodes must be able to resolve the cluster endpoint
+	for _, attr := range []aws_ec2_v2_types.VpcAttributeName{
+		aws_ec2_v2_types.VpcAttributeNameEnableDnsSupport,
+		aws_ec2_v2_types.VpcAttributeNameEnableDnsHostnames,
+	} {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		out, err := ts.cfg.EC2APIV2.DescribeVpcAttribute(
+			ctx,
+			&aws_ec2_v2.DescribeVpcAttributeInput{
+				VpcId:     aws_v2.String(ts.cfg.EKSConfig.VPC.ID),
+				Attribute: attr,
+			},
+		)
+		cancel()
+		if err != nil {
+			ts.cfg.Logger.Warn("failed to describe VPC attribute", zap.String("attribute", string(attr)), zap.Error(err))
+			return err
+		}
+		var v *aws_ec2_v2_types.AttributeBooleanValue
+		switch attr {
+		case aws_ec2_v2_types.VpcAttributeNameEnableDnsSupport:
+			v = out.EnableDnsSupport
+		case aws_ec2_v2_types.VpcAttributeNameEnableDnsHostnames:
+			v = out.EnableDnsHostnames
+		}
+		if v == nil || !aws_v2.ToBool(v.Value) {
+			return fmt.Errorf("VPC %q has %q disabled", ts.cfg.EKSConfig.VPC.ID, attr)
+		}
+	}
+
+	subnetIDs := append(append([]string{}, ts.cfg.EKSConfig.VPC.PublicSubnetIDs...), ts.cfg.EKSConfig.VPC.PrivateSubnetIDs...)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	sresp, err := ts.cfg.EC2APIV2.DescribeSubnets(
+		ctx,
+		&aws_ec2_v2.DescribeSubnetsInput{
+			SubnetIds: subnetIDs,
+		})
+	cancel()
+	if err != nil {
+		ts.cfg.Logger.Warn("failed to describe subnets", zap.Error(err))
+		return err
+	}
+	if len(sresp.Subnets) != len(subnetIDs) {
+		return fmt.Errorf("expected %d subnets, found %d", len(subnetIDs), len(sresp.Subnets))
+	}
+	azs := make(map[string]struct{})
+	for _, sv := range sresp.Subnets {
+		id, az := aws_v2.ToString(sv.SubnetId), aws_v2.ToString(sv.AvailabilityZone)
+		freeIPs := aws_v2.ToInt32(sv.AvailableIpAddressCount)
+		ts.cfg.Logger.Info("found subnet",
+			zap.String("id", id),
+			zap.String("availability-zone", az),
+			zap.Int32("available-ip-address-count", freeIPs),
+		)
+		if vpcID := aws_v2.ToString(sv.VpcId); vpcID != ts.cfg.EKSConfig.VPC.ID {
+			return fmt.Errorf("subnet %q is in VPC %q (expected %q)", id, vpcID, ts.cfg.EKSConfig.VPC.ID)
+		}
+		if freeIPs < minSubnetFreeIPs {
+			return fmt.Errorf("subnet %q has %d free IP addresses (expected at least %d)", id, freeIPs, minSubnetFreeIPs)
+		}
+		if ts.isIPv6() && len(sv.Ipv6CidrBlockAssociationSet) == 0 {
+			return fmt.Errorf("subnet %q has no IPv6 CIDR block for IPFamily %q", id, ts.cfg.EKSConfig.IPFamily)
+		}
+		azs[az] = struct{}{}
+	}
+	if len(azs) < 2 {
+		return fmt.Errorf("subnets %v must be in at least 2 availability zones (found %d)", subnetIDs, len(azs))
+	}
+
+	sgIDs := append([]string{ts.cfg.EKSConfig.VPC.SecurityGroupID}, ts.cfg.EKSConfig.VPC.AdditionalSecurityGroupIDs...)
+	ctx, cancel = context.WithTimeout(context.Background(), time.Minute)
+	gresp, err := ts.cfg.EC2APIV2.DescribeSecurityGroups(
+		ctx,
+		&aws_ec2_v2.DescribeSecurityGroupsInput{
+			GroupIds: sgIDs,
+		})
+	cancel()
+	if err != nil {
+		ts.cfg.Logger.Warn("failed to describe security groups", zap.Error(err))
+		return err
+	}
+	for _, sg := range gresp.SecurityGroups {
+		id := aws_v2.ToString(sg.GroupId)
+		if vpcID := aws_v2.ToString(sg.VpcId); vpcID != ts.cfg.EKSConfig.VPC.ID {
+			return fmt.Errorf("security group %q is in VPC %q (expected %q)", id, vpcID, ts.cfg.EKSConfig.VPC.ID)
+		}
+	}
+
+	ts.cfg.Logger.Info("validated existing VPC", zap.String("vpc-id", ts.cfg.EKSConfig.VPC.ID), zap.Int("availability-zones", len(azs)))
+	return nil
+}
@@ -0,0 +1,221 @@
+package cluster
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-k8s-tester/eksconfig"
+	aws_v2 "github.com/aws/aws-sdk-go-v2/aws"
+	aws_ec2_v2 "github.com/aws/aws-sdk-go-v2/service/ec2"
+	aws_ec2_v2_types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	smithy "github.com/aws/smithy-go"
+	"go.uber.org/zap"
+)
+
+// IPv6 clusters need IPv6 CIDR blocks in the VPC and subnets,
+// "::/0" routes via internet gateway for public subnets, and
+// via egress-only internet gateway for private subnets.
+// ref. https://docs.aws.amazon.com/eks/latest/userguide/cni-ipv6.html
+
+func (ts *tester) isIPv6() bool {
+	return ts.cfg.EKSConfig.IPFamily == eksconfig.IPFamilyIPv6
+}
+
+// waitVPCIPv6CIDR waits until the Amazon provided IPv6 CIDR block
+// is associated with the VPC.
+func (ts *tester) waitVPCIPv6CIDR() error {
+	ts.cfg.Logger.Info("waiting for VPC IPv6 CIDR block", zap.String("vpc-id", ts.cfg.EKSConfig.VPC.ID))
+
+	retryStart, waitDur := time.Now(), 3*time.Minute
+	for time.Since(retryStart) < waitDur {
+		select {
+		case <-ts.cfg.Stopc:
+			return errors.New("VPC IPv6 CIDR block wait aborted")
+		case <-time.After(5 * time.Second):
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		out, err := ts.cfg.EC2APIV2.DescribeVpcs(
+			ctx,
+			&aws_ec2_v2.DescribeVpcsInput{
+				VpcIds: []string{ts.cfg.EKSConfig.VPC.ID},
+			},
+		)
+		cancel()
+		if err != nil {
+			ts.cfg.Logger.Warn("failed to describe VPC", zap.Error(err))
+			continue
+		}
+		if len(out.Vpcs) != 1 {
+			ts.cfg.Logger.Warn("unexpected VPCs", zap.Int("vpcs", len(out.Vpcs)))
+			continue
+		}
+		for _, av := range out.Vpcs[0].Ipv6CidrBlockAssociationSet {
+			if av.Ipv6CidrBlockState == nil || av.Ipv6CidrBlockState.State != aws_ec2_v2_types.VpcCidrBlockStateCodeAssociated {
+				continue
+			}
+			ts.cfg.EKSConfig.VPC.IPv6CIDR = aws_v2.ToString(av.Ipv6CidrBlock)
+			ts.cfg.EKSConfig.Sync()
+			ts.cfg.Logger.Info("found VPC IPv6 CIDR block", zap.String("ipv6-cidr", ts.cfg.EKSConfig.VPC.IPv6CIDR))
+			return nil
+		}
+		ts.cfg.Logger.Info("VPC IPv6 CIDR block not associated yet")
+	}
+	return fmt.Errorf("VPC %q IPv6 CIDR block not associated", ts.cfg.EKSConfig.VPC.ID)
+}
+
+// ipv6SubnetCIDR returns the idx-th /64 block within the VPC IPv6 CIDR block.
+func ipv6SubnetCIDR(vpcCIDR string, idx int) (string, error) {
+	_, ipnet, err := net.ParseCIDR(vpcCIDR)
+	if err != nil {
+		return "", err
+	}
+	ones, bits := ipnet.Mask.Size()
+	if bits != 128 || ones > 64 {
+		return "", fmt.Errorf("unexpected IPv6 CIDR block %q", vpcCIDR)
+	}
+	if idx < 0 || uint64(idx) >= uint64(1)<<uint(64-ones) {
+		return "", fmt.Errorf("subnet index %d out of range for %q", idx, vpcCIDR)
+	}
+	ip := make(net.IP, net.IPv6len)
+	copy(ip, ipnet.IP.To16())
+	binary.BigEndian.PutUint64(ip[:8], binary.BigEndian.Uint64(ip[:8])+uint64(idx))
+	return fmt.Sprintf("%s/64", ip.String()), nil
+}
+
+// AWS::EC2::SubnetCidrBlock
+func (ts *tester) associateSubnetIPv6CIDR(subnetID string, idx int) error {
+	cidr, err := ipv6SubnetCIDR(ts.cfg.EKSConfig.VPC.IPv6CIDR, idx)
+	if err != nil {
+		return err
+	}
+	ts.cfg.Logger.Info("associating subnet IPv6 CIDR block", zap.String("subnet-id", subnetID), zap.String("ipv6-cidr", cidr))
+	_, err = ts.cfg.EC2APIV2.AssociateSubnetCidrBlock(
+		context.Background(),
+		&aws_ec2_v2.AssociateSubnetCidrBlockInput{
+			SubnetId:      aws_v2.String(subnetID),
+			Ipv6CidrBlock: aws_v2.String(cidr),
+		},
+	)
+	if err != nil {
+		ts.cfg.Logger.Warn("failed to associate subnet IPv6 CIDR block", zap.Error(err))
+		return err
+	}
+
+	_, err = ts.cfg.EC2APIV2.ModifySubnetAttribute(
+		context.Background(),
+		&aws_ec2_v2.ModifySubnetAttributeInput{
+			SubnetId:                    aws_v2.String(subnetID),
+			AssignIpv6AddressOnCreation: &aws_ec2_v2_types.AttributeBooleanValue{Value: aws_v2.Bool(true)},
+		},
+	)
+	if err != nil {
+		ts.cfg.Logger.Warn("failed to modify subnet attribute", zap.Error(err))
+		return err
+	}
+	ts.cfg.Logger.Info("modified the subnet with AssignIpv6AddressOnCreation", zap.String("subnet-id", subnetID))
+	return nil
+}
+
+// AWS::EC2::EgressOnlyInternetGateway
+func (ts *tester) createEgressOnlyInternetGateway() error {
+	ts.cfg.Logger.Info("creating egress-only internet gateway")
+	out, err := ts.cfg.EC2APIV2.CreateEgressOnlyInternetGateway(
+		context.Background(),
+		&aws_ec2_v2.CreateEgressOnlyInternetGatewayInput{
+			VpcId: aws_v2.String(ts.cfg.EKSConfig.VPC.ID),
+		},
+	)
+	if err != nil {
+		ts.cfg.Logger.Warn("failed to create egress-only internet gateway", zap.Error(err))
+		return err
+	}
+
+	ts.cfg.EKSConfig.VPC.EgressOnlyInternetGatewayID = aws_v2.ToString(out.EgressOnlyInternetGateway.EgressOnlyInternetGatewayId)
+	ts.cfg.EKSConfig.Sync()
+	ts.cfg.Logger.Info("created egress-only internet gateway", zap.String("egress-only-internet-gateway-id", ts.cfg.EKSConfig.VPC.EgressOnlyInternetGatewayID))
+	return nil
+}
+
+func (ts *tester) deleteEgressOnlyInternetGateway() (err error) {
+	ts.cfg.Logger.Info("deleting egress-only internet gateway")
+	if ts.cfg.EKSConfig.VPC.ID == "" || ts.cfg.EKSConfig.VPC.EgressOnlyInternetGatewayID == "" {
+		return nil
+	}
+	if _, ok := ts.cfg.EKSConfig.Status.DeletedResources[ts.cfg.EKSConfig.VPC.EgressOnlyInternetGatewayID]; ok {
+		return nil
+	}
+
+	_, err = ts.cfg.EC2APIV2.DeleteEgressOnlyInternetGateway(
+		context.Background(),
+		&aws_ec2_v2.DeleteEgressOnlyInternetGatewayInput{
+			EgressOnlyInternetGatewayId: aws_v2.String(ts.cfg.EKSConfig.VPC.EgressOnlyInternetGatewayID),
+		},
+	)
+	if err != nil {
+		ts.cfg.Logger.Warn("failed to delete egress-only internet gateway", zap.Error(err))
+		var apiErr smithy.APIError
+		if errors.As(err, &apiErr) {
+			if strings.Contains(apiErr.ErrorCode(), "NotFound") {
+				ts.cfg.EKSConfig.Status.DeletedResources[ts.cfg.EKSConfig.VPC.EgressOnlyInternetGatewayID] = "VPC.EgressOnlyInternetGatewayID"
+				ts.cfg.EKSConfig.Sync()
+				return nil
+			}
+		}
+		return err
+	}
+
+	ts.cfg.EKSConfig.Status.DeletedResources[ts.cfg.EKSConfig.VPC.EgressOnlyInternetGatewayID] = "VPC.EgressOnlyInternetGatewayID"
+	ts.cfg.EKSConfig.Sync()
+	ts.cfg.Logger.Info("deleted egress-only internet gateway")
+	return nil
+}
+
+// AWS::EC2::Route
+func (ts *tester) createPublicIPv6Route() error {
+	ts.cfg.Logger.Info("creating public IPv6 route")
+
+	_, err := ts.cfg.EC2APIV2.CreateRoute(
+		context.Background(),
+		&aws_ec2_v2.CreateRouteInput{
+			RouteTableId:             aws_v2.String(ts.cfg.EKSConfig.VPC.PublicRouteTableID),
+			GatewayId:                aws_v2.String(ts.cfg.EKSConfig.VPC.InternetGatewayID),
+			DestinationIpv6CidrBlock: aws_v2.String("::/0"),
+		},
+	)
+	if err != nil {
+		ts.cfg.Logger.Warn("failed to create public IPv6 route", zap.Error(err))
+		return err
+	}
+
+	ts.cfg.Logger.Info("created public IPv6 route")
+	return nil
+}
+
+// AWS::EC2::Route
+func (ts *tester) createPrivateIPv6Routes() error {
+	ts.cfg.Logger.Info("creating private IPv6 routes using egress-only internet gateway")
+
+	for _, route := range ts.cfg.EKSConfig.VPC.PrivateRouteTableIDs {
+		_, err := ts.cfg.EC2APIV2.CreateRoute(
+			context.Background(),
+			&aws_ec2_v2.CreateRouteInput{
+				RouteTableId:                aws_v2.String(route),
+				EgressOnlyInternetGatewayId: aws_v2.String(ts.cfg.EKSConfig.VPC.EgressOnlyInternetGatewayID),
+				DestinationIpv6CidrBlock:    aws_v2.String("::/0"),
+			},
+		)
+		if err != nil {
+			ts.cfg.Logger.Warn("failed to create private IPv6 route", zap.Error(err))
+			return err
+		}
+	}
+
+	ts.cfg.Logger.Info("created private IPv6 routes")
+	return nil
+}
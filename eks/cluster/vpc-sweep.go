@@ -0,0 +1,129 @@
+package cluster
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	aws_v2 "github.com/aws/aws-sdk-go-v2/aws"
+	aws_elbv2_v2 "github.com/aws/aws-sdk-go-v2/service/elasticloadbalancingv2"
+	smithy "github.com/aws/smithy-go"
+	"go.uber.org/zap"
+)
+
+// VPCDeleteRetries is the number of VPC delete retries, each after
+// sweeping the leaked resources that block the VPC deletion.
+const VPCDeleteRetries = 3
+
+// isDependencyViolation returns true if the error is caused by the
+// resources still in use (e.g. leaked ENIs of the load balancers
+// created by the Kubernetes "Service" type "LoadBalancer").
+func isDependencyViolation(err error) bool {
+	var apiErr smithy.APIError
+	return errors.As(err, &apiErr) && apiErr.ErrorCode() == "DependencyViolation"
+}
+
+// deleteVPCWithSweep deletes the VPC, and when the deletion fails with
+// dependency violations, sweeps the orphaned load balancers, ENIs,
+// security groups, subnets, and gateways in the VPC, and retries.
+func (ts *tester) deleteVPCWithSweep() (err error) {
+	for i := 0; ; i++ {
+		err = ts._deleteVPC()
+		if err == nil || !isDependencyViolation(err) {
+			return err
+		}
+		if i == VPCDeleteRetries {
+			break
+		}
+		ts.cfg.Logger.Warn("VPC delete blocked by dependencies; sweeping leaked resources",
+			zap.String("vpc-id", ts.cfg.EKSConfig.VPC.ID),
+			zap.Int("retry", i+1),
+			zap.Error(err),
+		)
+		if err = ts.sweepVPC(); err != nil {
+			return err
+		}
+	}
+	return fmt.Errorf("failed to delete VPC %q after %d sweeps (%v)", ts.cfg.EKSConfig.VPC.ID, VPCDeleteRetries, err)
+}
+
+// sweepVPC deletes the leaked resources in the VPC in dependency order.
+// The load balancers must be gone before their ENIs and security groups
+// can be deleted, and the ENIs before the subnets.
+func (ts *tester) sweepVPC() error {
+	if err := ts.deleteELBv2(); err != nil {
+		ts.cfg.Logger.Warn("failed to sweep ELBv2", zap.Error(err))
+	}
+	if err := ts.waitELBv2Deleted(5 * time.Minute); err != nil {
+		return err
+	}
+	if err := ts.deleteENIs(); err != nil {
+		ts.cfg.Logger.Warn("failed to sweep ENIs", zap.Error(err))
+	}
+	if err := ts.deleteOtherSecurityGroups(); err != nil {
+		ts.cfg.Logger.Warn("failed to sweep security groups", zap.Error(err))
+	}
+	if err := ts.deleteSecurityGroups(); err != nil {
+		ts.cfg.Logger.Warn("failed to sweep cluster security group", zap.Error(err))
+	}
+	if err := ts.deletePrivateSubnets(); err != nil {
+		ts.cfg.Logger.Warn("failed to sweep private subnets", zap.Error(err))
+	}
+	if err := ts.deletePodSubnets(); err != nil {
+		ts.cfg.Logger.Warn("failed to sweep pod subnets", zap.Error(err))
+	}
+	if err := ts.deletePublicSubnets(); err != nil {
+		ts.cfg.Logger.Warn("failed to sweep public subnets", zap.Error(err))
+	}
+	if err := ts.deleteVPCGatewayAttachment(); err != nil {
+		ts.cfg.Logger.Warn("failed to sweep VPC gateway attachment", zap.Error(err))
+	}
+	if err := ts.deleteInternetGateway(); err != nil {
+		ts.cfg.Logger.Warn("failed to sweep internet gateway", zap.Error(err))
+	}
+	if err := ts.deleteEgressOnlyInternetGateway(); err != nil {
+		ts.cfg.Logger.Warn("failed to sweep egress-only internet gateway", zap.Error(err))
+	}
+
+	select {
+	case <-time.After(10 * time.Second):
+	case <-ts.cfg.Stopc:
+		return errors.New("stopped")
+	}
+	return nil
+}
+
+// waitELBv2Deleted waits until no load balancer remains in the VPC,
+// since "DeleteLoadBalancer" returns before its ENIs are released.
+func (ts *tester) waitELBv2Deleted(timeout time.Duration) error {
+	retryStart := time.Now()
+	for time.Since(retryStart) < timeout {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		out, err := ts.cfg.ELBV2APIV2.DescribeLoadBalancers(ctx, &aws_elbv2_v2.DescribeLoadBalancersInput{})
+		cancel()
+		if err != nil {
+			ts.cfg.Logger.Warn("failed to describe ELBv2", zap.Error(err))
+		} else {
+			remaining := 0
+			for _, ev := range out.LoadBalancers {
+				if aws_v2.ToString(ev.VpcId) == ts.cfg.EKSConfig.VPC.ID {
+					remaining++
+				}
+			}
+			if remaining == 0 {
+				ts.cfg.Logger.Info("no ELBv2 left in the VPC", zap.String("vpc-id", ts.cfg.EKSConfig.VPC.ID))
+				return nil
+			}
+			ts.cfg.Logger.Info("waiting for ELBv2 deletion", zap.String("vpc-id", ts.cfg.EKSConfig.VPC.ID), zap.Int("remaining", remaining))
+		}
+
+		select {
+		case <-time.After(15 * time.Second):
+		case <-ts.cfg.Stopc:
+			return errors.New("stopped")
+		}
+	}
+	ts.cfg.Logger.Warn("ELBv2 still exist in the VPC; continuing sweep", zap.Duration("timeout", timeout))
+	return nil
+}
@@ -0,0 +1,27 @@
+package cluster
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	smithy "github.com/aws/smithy-go"
+)
+
+func TestIsDependencyViolation(t *testing.T) {
+	tt := []struct {
+		err error
+		exp bool
+	}{
+		{&smithy.GenericAPIError{Code: "DependencyViolation", Message: "The vpc 'vpc-0127f6d18bd98836a' has dependencies and cannot be deleted"}, true},
+		{fmt.Errorf("wrapped (%w)", &smithy.GenericAPIError{Code: "DependencyViolation"}), true},
+		{&smithy.GenericAPIError{Code: "InvalidVpcID.NotFound"}, false},
+		{errors.New("DependencyViolation"), false},
+		{nil, false},
+	}
+	for i, tv := range tt {
+		if v := isDependencyViolation(tv.err); v != tv.exp {
+			t.Fatalf("#%d: expected %v, got %v (%v)", i, tv.exp, v, tv.err)
+		}
+	}
+}
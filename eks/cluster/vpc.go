@@ -7,6 +7,7 @@ import (
 	"strings"
 	"time"
 
+	aws_ec2 "github.com/aws/aws-k8s-tester/pkg/aws/ec2"
 	aws_v2 "github.com/aws/aws-sdk-go-v2/aws"
 	aws_ec2_v2 "github.com/aws/aws-sdk-go-v2/service/ec2"
 	aws_ec2_v2_types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
@@ -46,84 +47,22 @@ func (ts *tester) createVPC() error {
 			}
 		}
 
-		ts.cfg.Logger.Info("querying subnet IDs for given VPC",
-			zap.String("vpc-id", ts.cfg.EKSConfig.VPC.ID),
-		)
-		ctx, cancel = context.WithTimeout(context.Background(), time.Minute)
-		sresp, err := ts.cfg.EC2APIV2.DescribeSubnets(
-			ctx,
-			&aws_ec2_v2.DescribeSubnetsInput{
-				Filters: []aws_ec2_v2_types.Filter{
-					{
-						Name:   aws_v2.String("vpc-id"),
-						Values: []string{ts.cfg.EKSConfig.VPC.ID},
-					},
-				},
-			})
-		cancel()
-		if err != nil {
-			ts.cfg.Logger.Warn("failed to subnets", zap.Error(err))
-			return err
-		}
-
-		ts.cfg.EKSConfig.VPC.PublicSubnetIDs = make([]string, 0, len(sresp.Subnets))
-		ts.cfg.EKSConfig.VPC.PrivateSubnetIDs = make([]string, 0, len(sresp.Subnets))
-		for _, sv := range sresp.Subnets {
-			id := aws_v2.ToString(sv.SubnetId)
-			networkTagValue := ""
-			for _, tg := range sv.Tags {
-				switch aws_v2.ToString(tg.Key) {
-				case "Network":
-					networkTagValue = aws_v2.ToString(tg.Value)
-				}
-				if networkTagValue != "" {
-					break
-				}
+		// subnets and security group may be given for an existing VPC
+		byo := !ts.cfg.EKSConfig.VPC.Create
+		if !byo || len(ts.cfg.EKSConfig.VPC.PublicSubnetIDs) == 0 {
+			if err := ts.discoverSubnets(); err != nil {
+				return err
 			}
-			ts.cfg.Logger.Info("found subnet",
-				zap.String("id", id),
-				zap.String("availability-zone", aws_v2.ToString(sv.AvailabilityZone)),
-				zap.String("network-tag", networkTagValue),
-			)
-			switch networkTagValue {
-			case "Public":
-				ts.cfg.EKSConfig.VPC.PublicSubnetIDs = append(ts.cfg.EKSConfig.VPC.PublicSubnetIDs, id)
-			case "Private":
-				ts.cfg.EKSConfig.VPC.PrivateSubnetIDs = append(ts.cfg.EKSConfig.VPC.PrivateSubnetIDs, id)
-			default:
-				return fmt.Errorf("'Network' tag not found in subnet %q", id)
-			}
-		}
-		if len(ts.cfg.EKSConfig.VPC.PublicSubnetIDs) == 0 {
-			return fmt.Errorf("no subnet found for VPC ID %q", ts.cfg.EKSConfig.VPC.ID)
 		}
-
-		ts.cfg.Logger.Info("querying security IDs", zap.String("vpc-id", ts.cfg.EKSConfig.VPC.ID))
-		ctx, cancel = context.WithTimeout(context.Background(), time.Minute)
-		gresp, err := ts.cfg.EC2APIV2.DescribeSecurityGroups(
-			ctx,
-			&aws_ec2_v2.DescribeSecurityGroupsInput{
-				Filters: []aws_ec2_v2_types.Filter{
-					{
-						Name:   aws_v2.String("vpc-id"),
-						Values: []string{ts.cfg.EKSConfig.VPC.ID},
-					},
-				},
-			})
-		cancel()
-		if err != nil {
-			ts.cfg.Logger.Warn("failed to security groups", zap.Error(err))
-			return err
-		}
-		for _, sg := range gresp.SecurityGroups {
-			id, name := aws_v2.ToString(sg.GroupId), aws_v2.ToString(sg.GroupName)
-			ts.cfg.Logger.Info("found security group", zap.String("id", id), zap.String("name", name))
-			if name != "default" {
-				ts.cfg.EKSConfig.VPC.SecurityGroupID = id
+		if !byo || ts.cfg.EKSConfig.VPC.SecurityGroupID == "" {
+			if err := ts.discoverSecurityGroup(); err != nil {
+				return err
 			}
 		}
-		if ts.cfg.EKSConfig.VPC.SecurityGroupID == "" {
-			return fmt.Errorf("no security group found for VPC ID %q", ts.cfg.EKSConfig.VPC.ID)
+		if byo {
+			if err := ts.validateExistingVPC(); err != nil {
+				return err
+			}
 		}
 
 		ts.cfg.EKSConfig.Sync()
@@ -143,6 +82,11 @@ func (ts *tester) createVPC() error {
 	if err := ts._createVPC(); err != nil { // AWS::EC2::VPC
 		return err
 	}
+	if ts.isIPv6() {
+		if err := ts.waitVPCIPv6CIDR(); err != nil {
+			return err
+		}
+	}
 	if err := ts.modifyVPC(); err != nil {
 		return err
 	}
@@ -159,6 +103,11 @@ func (ts *tester) createVPC() error {
 	if err := ts.createVPCGatewayAttachment(); err != nil { // AWS::EC2::VPCGatewayAttachment
 		return err
 	}
+	if ts.isIPv6() {
+		if err := ts.createEgressOnlyInternetGateway(); err != nil { // AWS::EC2::EgressOnlyInternetGateway
+			return err
+		}
+	}
 
 	if err := ts.createPublicSubnets(); err != nil { // AWS::EC2::Subnet
 		return err
@@ -169,6 +118,11 @@ func (ts *tester) createVPC() error {
 	if err := ts.createPublicRoute(); err != nil { // AWS::EC2::Route
 		return err
 	}
+	if ts.isIPv6() {
+		if err := ts.createPublicIPv6Route(); err != nil { // AWS::EC2::Route
+			return err
+		}
+	}
 	if err := ts.createPublicSubnetRouteTableAssociation(); err != nil { // AWS::EC2::SubnetRouteTableAssociation
 		return err
 	}
@@ -189,9 +143,17 @@ func (ts *tester) createVPC() error {
 	if err := ts.createPrivateRoutes(); err != nil { // AWS::EC2::Route
 		return err
 	}
+	if ts.isIPv6() {
+		if err := ts.createPrivateIPv6Routes(); err != nil { // AWS::EC2::Route
+			return err
+		}
+	}
 	if err := ts.createPrivateSubnetRouteTableAssociation(); err != nil { // AWS::EC2::SubnetRouteTableAssociation
 		return err
 	}
+	if err := ts.createPodSubnets(); err != nil { // AWS::EC2::VPCCidrBlock, AWS::EC2::Subnet, AWS::EC2::SubnetRouteTableAssociation
+		return err
+	}
 
 	if err := ts.createDHCPOptions(); err != nil { // AWS::EC2::DHCPOptions, AWS::EC2::VPCDHCPOptionsAssociation
 		return err
@@ -200,6 +162,7 @@ func (ts *tester) createVPC() error {
 	ts.cfg.Logger.Info("created a VPC",
 		zap.String("vpc-id", ts.cfg.EKSConfig.VPC.ID),
 		zap.Strings("vpc-cidr-blocks", ts.cfg.EKSConfig.VPC.CIDRs),
+		zap.String("vpc-ipv6-cidr-block", ts.cfg.EKSConfig.VPC.IPv6CIDR),
 		zap.Strings("public-subnet-ids", ts.cfg.EKSConfig.VPC.PublicSubnetIDs),
 		zap.Strings("private-subnet-ids", ts.cfg.EKSConfig.VPC.PrivateSubnetIDs),
 		zap.String("control-plane-security-group-id", ts.cfg.EKSConfig.VPC.SecurityGroupID),
@@ -209,6 +172,95 @@ func (ts *tester) createVPC() error {
 	return nil
 }
 
+// discoverSubnets finds public and private subnets in the existing VPC,
+// using the "Network" tag.
+func (ts *tester) discoverSubnets() error {
+	ts.cfg.Logger.Info("querying subnet IDs for given VPC",
+		zap.String("vpc-id", ts.cfg.EKSConfig.VPC.ID),
+	)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	sresp, err := ts.cfg.EC2APIV2.DescribeSubnets(
+		ctx,
+		&aws_ec2_v2.DescribeSubnetsInput{
+			Filters: []aws_ec2_v2_types.Filter{
+				{
+					Name:   aws_v2.String("vpc-id"),
+					Values: []string{ts.cfg.EKSConfig.VPC.ID},
+				},
+			},
+		})
+	cancel()
+	if err != nil {
+		ts.cfg.Logger.Warn("failed to subnets", zap.Error(err))
+		return err
+	}
+
+	ts.cfg.EKSConfig.VPC.PublicSubnetIDs = make([]string, 0, len(sresp.Subnets))
+	ts.cfg.EKSConfig.VPC.PrivateSubnetIDs = make([]string, 0, len(sresp.Subnets))
+	for _, sv := range sresp.Subnets {
+		id := aws_v2.ToString(sv.SubnetId)
+		networkTagValue := ""
+		for _, tg := range sv.Tags {
+			switch aws_v2.ToString(tg.Key) {
+			case "Network":
+				networkTagValue = aws_v2.ToString(tg.Value)
+			}
+			if networkTagValue != "" {
+				break
+			}
+		}
+		ts.cfg.Logger.Info("found subnet",
+			zap.String("id", id),
+			zap.String("availability-zone", aws_v2.ToString(sv.AvailabilityZone)),
+			zap.String("network-tag", networkTagValue),
+		)
+		switch networkTagValue {
+		case "Public":
+			ts.cfg.EKSConfig.VPC.PublicSubnetIDs = append(ts.cfg.EKSConfig.VPC.PublicSubnetIDs, id)
+		case "Private":
+			ts.cfg.EKSConfig.VPC.PrivateSubnetIDs = append(ts.cfg.EKSConfig.VPC.PrivateSubnetIDs, id)
+		default:
+			return fmt.Errorf("'Network' tag not found in subnet %q", id)
+		}
+	}
+	if len(ts.cfg.EKSConfig.VPC.PublicSubnetIDs) == 0 {
+		return fmt.Errorf("no subnet found for VPC ID %q", ts.cfg.EKSConfig.VPC.ID)
+	}
+	return nil
+}
+
+// discoverSecurityGroup finds the non-default security group in the existing VPC.
+func (ts *tester) discoverSecurityGroup() error {
+	ts.cfg.Logger.Info("querying security IDs", zap.String("vpc-id", ts.cfg.EKSConfig.VPC.ID))
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	gresp, err := ts.cfg.EC2APIV2.DescribeSecurityGroups(
+		ctx,
+		&aws_ec2_v2.DescribeSecurityGroupsInput{
+			Filters: []aws_ec2_v2_types.Filter{
+				{
+					Name:   aws_v2.String("vpc-id"),
+					Values: []string{ts.cfg.EKSConfig.VPC.ID},
+				},
+			},
+		})
+	cancel()
+	if err != nil {
+		ts.cfg.Logger.Warn("failed to security groups", zap.Error(err))
+		return err
+	}
+	for _, sg := range gresp.SecurityGroups {
+		id, name := aws_v2.ToString(sg.GroupId), aws_v2.ToString(sg.GroupName)
+		ts.cfg.Logger.Info("found security group", zap.String("id", id), zap.String("name", name))
+		if name != "default" {
+			ts.cfg.EKSConfig.VPC.SecurityGroupID = id
+		}
+	}
+	if ts.cfg.EKSConfig.VPC.SecurityGroupID == "" {
+		return fmt.Errorf("no security group found for VPC ID %q", ts.cfg.EKSConfig.VPC.ID)
+	}
+	return nil
+}
+
 // e.g. DependencyViolation: The vpc 'vpc-0127f6d18bd98836a' has dependencies and cannot be deleted
 func (ts *tester) deleteVPC() error {
 	fmt.Print(ts.cfg.EKSConfig.Colorize("\n\n[yellow]*********************************\n"))
@@ -302,6 +354,10 @@ func (ts *tester) deleteVPC() error {
 		time.Sleep(10 * time.Second)
 	}
 
+	if err := ts.deletePodSubnets(); err != nil {
+		ts.cfg.Logger.Warn("failed to delete pod subnets", zap.Error(err))
+		errs = append(errs, err.Error())
+	}
 	if err := ts.deletePublicSubnetRouteTableAssociation(); err != nil {
 		ts.cfg.Logger.Warn("failed to delete public subnet route table association", zap.Error(err))
 		errs = append(errs, err.Error())
@@ -333,6 +389,10 @@ func (ts *tester) deleteVPC() error {
 		ts.cfg.Logger.Warn("failed to delete internet gateway", zap.Error(err))
 		errs = append(errs, err.Error())
 	}
+	if err := ts.deleteEgressOnlyInternetGateway(); err != nil {
+		ts.cfg.Logger.Warn("failed to delete egress-only internet gateway", zap.Error(err))
+		errs = append(errs, err.Error())
+	}
 
 	select {
 	case <-time.After(10 * time.Second):
@@ -371,7 +431,8 @@ func (ts *tester) deleteVPC() error {
 		errs = append(errs, err.Error())
 	}
 
-	if err := ts._deleteVPC(); err != nil {
+	// leaked resources (e.g. NLB of "Service" type "LoadBalancer") are swept on "DependencyViolation"
+	if err := ts.deleteVPCWithSweep(); err != nil {
 		ts.cfg.Logger.Warn("failed to delete VPC", zap.Error(err))
 		errs = append(errs, err.Error())
 	}
@@ -388,16 +449,17 @@ func (ts *tester) _createVPC() error {
 	vpcOut, err := ts.cfg.EC2APIV2.CreateVpc(
 		context.Background(),
 		&aws_ec2_v2.CreateVpcInput{
-			CidrBlock: aws_v2.String(ts.cfg.EKSConfig.VPC.CIDRs[0]),
+			CidrBlock:                   aws_v2.String(ts.cfg.EKSConfig.VPC.CIDRs[0]),
+			AmazonProvidedIpv6CidrBlock: aws_v2.Bool(ts.isIPv6()),
 			TagSpecifications: []aws_ec2_v2_types.TagSpecification{
 				{
 					ResourceType: aws_ec2_v2_types.ResourceTypeVpc,
-					Tags: []aws_ec2_v2_types.Tag{
+					Tags: aws_ec2.AppendTags([]aws_ec2_v2_types.Tag{
 						{
 							Key:   aws_v2.String("Name"),
 							Value: aws_v2.String(fmt.Sprintf("%s-vpc", ts.cfg.EKSConfig.Name)),
 						},
-					},
+					}, ts.cfg.EKSConfig.Tags),
 				},
 			},
 		},
@@ -624,7 +686,7 @@ func (ts *tester) createPublicSubnets() error {
 				TagSpecifications: []aws_ec2_v2_types.TagSpecification{
 					{
 						ResourceType: aws_ec2_v2_types.ResourceTypeSubnet,
-						Tags: []aws_ec2_v2_types.Tag{
+						Tags: aws_ec2.AppendTags([]aws_ec2_v2_types.Tag{
 							{
 								Key:   aws_v2.String("Name"),
 								Value: aws_v2.String(fmt.Sprintf("%s-public-subnet-%d", ts.cfg.EKSConfig.Name, idx+1)),
@@ -648,7 +710,7 @@ func (ts *tester) createPublicSubnets() error {
 								Key:   aws_v2.String(fmt.Sprintf("kubernetes.io/cluster/%s", ts.cfg.EKSConfig.Name)),
 								Value: aws_v2.String("owned"),
 							},
-						},
+						}, ts.cfg.EKSConfig.Tags),
 					},
 				},
 			},
@@ -673,6 +735,12 @@ func (ts *tester) createPublicSubnets() error {
 			return err
 		}
 		ts.cfg.Logger.Info("modified the public subnet with MapPublicIpOnLaunch", zap.String("availability-zone", ts.cfg.EKSConfig.AvailabilityZoneNames[idx]), zap.String("subnet-id", subnetID))
+
+		if ts.isIPv6() {
+			if err = ts.associateSubnetIPv6CIDR(subnetID, idx); err != nil {
+				return err
+			}
+		}
 	}
 	ts.cfg.EKSConfig.Sync()
 	ts.cfg.Logger.Info("created public subnets", zap.Strings("availability-zones", ts.cfg.EKSConfig.AvailabilityZoneNames))
@@ -734,7 +802,7 @@ func (ts *tester) createPublicRouteTable() error {
 			TagSpecifications: []aws_ec2_v2_types.TagSpecification{
 				{
 					ResourceType: aws_ec2_v2_types.ResourceTypeRouteTable,
-					Tags: []aws_ec2_v2_types.Tag{
+					Tags: aws_ec2.AppendTags([]aws_ec2_v2_types.Tag{
 						{
 							Key:   aws_v2.String("Name"),
 							Value: aws_v2.String(fmt.Sprintf("%s-public-route-table", ts.cfg.EKSConfig.Name)),
@@ -743,7 +811,7 @@ func (ts *tester) createPublicRouteTable() error {
 							Key:   aws_v2.String("Network"),
 							Value: aws_v2.String("Public"),
 						},
-					},
+					}, ts.cfg.EKSConfig.Tags),
 				},
 			},
 		},
@@ -897,12 +965,12 @@ func (ts *tester) createPublicEIPs() error {
 			tags = []aws_ec2_v2_types.TagSpecification{
 				{
 					ResourceType: aws_ec2_v2_types.ResourceTypeElasticIp,
-					Tags: []aws_ec2_v2_types.Tag{
+					Tags: aws_ec2.AppendTags([]aws_ec2_v2_types.Tag{
 						{
 							Key:   aws_v2.String("Name"),
 							Value: aws_v2.String(fmt.Sprintf("%s-eip-%d", ts.cfg.EKSConfig.Name, idx+1)),
 						},
-					},
+					}, ts.cfg.EKSConfig.Tags),
 				},
 			}
 		}
@@ -985,12 +1053,12 @@ func (ts *tester) createPublicNATGateways() error {
 				TagSpecifications: []aws_ec2_v2_types.TagSpecification{
 					{
 						ResourceType: aws_ec2_v2_types.ResourceTypeNatgateway,
-						Tags: []aws_ec2_v2_types.Tag{
+						Tags: aws_ec2.AppendTags([]aws_ec2_v2_types.Tag{
 							{
 								Key:   aws_v2.String("Name"),
 								Value: aws_v2.String(fmt.Sprintf("%s-nat-gateway-%d", ts.cfg.EKSConfig.Name, idx+1)),
 							},
-						},
+						}, ts.cfg.EKSConfig.Tags),
 					},
 				},
 			},
@@ -1167,7 +1235,7 @@ func (ts *tester) createPrivateSubnets() error {
 				TagSpecifications: []aws_ec2_v2_types.TagSpecification{
 					{
 						ResourceType: aws_ec2_v2_types.ResourceTypeSubnet,
-						Tags: []aws_ec2_v2_types.Tag{
+						Tags: aws_ec2.AppendTags([]aws_ec2_v2_types.Tag{
 							{
 								Key:   aws_v2.String("Name"),
 								Value: aws_v2.String(fmt.Sprintf("%s-private-subnet-%d", ts.cfg.EKSConfig.Name, idx+1)),
@@ -1181,7 +1249,7 @@ func (ts *tester) createPrivateSubnets() error {
 								Key:   aws_v2.String(fmt.Sprintf("kubernetes.io/cluster/%s", ts.cfg.EKSConfig.Name)),
 								Value: aws_v2.String("owned"),
 							},
-						},
+						}, ts.cfg.EKSConfig.Tags),
 					},
 				},
 			},
@@ -1206,6 +1274,13 @@ func (ts *tester) createPrivateSubnets() error {
 			return err
 		}
 		ts.cfg.Logger.Info("modified the private subnet with MapPublicIpOnLaunch", zap.String("availability-zone", ts.cfg.EKSConfig.AvailabilityZoneNames[idx]), zap.String("subnet-id", subnetID))
+
+		if ts.isIPv6() {
+			// public subnets take the first /64 blocks
+			if err = ts.associateSubnetIPv6CIDR(subnetID, len(ts.cfg.EKSConfig.VPC.PublicSubnetIDs)+idx); err != nil {
+				return err
+			}
+		}
 	}
 	ts.cfg.EKSConfig.Sync()
 
@@ -1268,7 +1343,7 @@ func (ts *tester) createPrivateRouteTables() error {
 				TagSpecifications: []aws_ec2_v2_types.TagSpecification{
 					{
 						ResourceType: aws_ec2_v2_types.ResourceTypeRouteTable,
-						Tags: []aws_ec2_v2_types.Tag{
+						Tags: aws_ec2.AppendTags([]aws_ec2_v2_types.Tag{
 							{
 								Key:   aws_v2.String("Name"),
 								Value: aws_v2.String(fmt.Sprintf("%s-private-route-table-%d", ts.cfg.EKSConfig.Name, idx+1)),
@@ -1277,7 +1352,7 @@ func (ts *tester) createPrivateRouteTables() error {
 								Key:   aws_v2.String("Network"),
 								Value: aws_v2.String("private"),
 							},
-						},
+						}, ts.cfg.EKSConfig.Tags),
 					},
 				},
 			},
@@ -0,0 +1,117 @@
+package wait_v2
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"time"
+
+	aws_v2 "github.com/aws/aws-sdk-go-v2/aws"
+	aws_eks_v2_types "github.com/aws/aws-sdk-go-v2/service/eks/types"
+	"go.uber.org/zap"
+)
+
+// PollEndpointReady periodically fetches the cluster status
+// until the cluster is "ACTIVE" with non-empty API server endpoint,
+// certificate authority data, and OIDC issuer URL.
+// If "WithEndpointDial" is set, it also requires a successful TLS dial
+// to the API server endpoint with the cluster CA.
+func PollEndpointReady(
+	ctx context.Context,
+	stopc chan struct{},
+	lg *zap.Logger,
+	logWriter io.Writer,
+	eksAPIV2 EKSAPI,
+	clusterName string,
+	initialWait time.Duration,
+	pollInterval time.Duration,
+	opts ...OpOption) <-chan ClusterStatus {
+
+	ret := Op{}
+	ret.applyOpts(opts)
+
+	readyFunc := func(cluster *aws_eks_v2_types.Cluster) error {
+		return checkEndpointReady(cluster, ret.dialEndpoint, ret.dialTimeout)
+	}
+	opts = append(opts, func(op *Op) { op.readyFunc = readyFunc })
+
+	return Poll(
+		ctx,
+		stopc,
+		lg,
+		logWriter,
+		eksAPIV2,
+		clusterName,
+		fmt.Sprint(aws_eks_v2_types.ClusterStatusActive),
+		initialWait,
+		pollInterval,
+		opts...,
+	)
+}
+
+func checkEndpointReady(cluster *aws_eks_v2_types.Cluster, dial bool, dialTimeout time.Duration) error {
+	endpoint := aws_v2.ToString(cluster.Endpoint)
+	if endpoint == "" {
+		return errors.New("empty cluster endpoint")
+	}
+	caData := ""
+	if cluster.CertificateAuthority != nil {
+		caData = aws_v2.ToString(cluster.CertificateAuthority.Data)
+	}
+	if caData == "" {
+		return errors.New("empty cluster certificate authority data")
+	}
+	issuer := ""
+	if cluster.Identity != nil && cluster.Identity.Oidc != nil {
+		issuer = aws_v2.ToString(cluster.Identity.Oidc.Issuer)
+	}
+	if issuer == "" {
+		return errors.New("empty cluster OIDC issuer")
+	}
+	if !dial {
+		return nil
+	}
+	return dialEndpoint(endpoint, caData, dialTimeout)
+}
+
+// dialEndpoint opens a TLS connection to the cluster endpoint,
+// verifying the server certificate with the base64-encoded cluster CA.
+func dialEndpoint(endpoint string, caData string, timeout time.Duration) error {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return fmt.Errorf("failed to parse endpoint %q (%v)", endpoint, err)
+	}
+	host := u.Host
+	if u.Port() == "" {
+		host = net.JoinHostPort(u.Hostname(), "443")
+	}
+
+	ca, err := base64.StdEncoding.DecodeString(caData)
+	if err != nil {
+		return fmt.Errorf("failed to decode cluster CA (%v)", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(ca) {
+		return errors.New("failed to parse cluster CA")
+	}
+
+	if timeout == 0 {
+		timeout = 10 * time.Second
+	}
+	conn, err := tls.DialWithDialer(
+		&net.Dialer{Timeout: timeout},
+		"tcp",
+		host,
+		&tls.Config{RootCAs: pool, ServerName: u.Hostname()},
+	)
+	if err != nil {
+		return fmt.Errorf("failed to dial endpoint %q (%v)", host, err)
+	}
+	return conn.Close()
+}
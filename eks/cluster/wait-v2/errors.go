@@ -0,0 +1,33 @@
+package wait_v2
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrWaitStopped is returned when the wait is stopped via stop channel.
+	ErrWaitStopped = errors.New("wait stopped")
+	// ErrClusterFailed is matched by "ClusterFailedError" via "errors.Is".
+	ErrClusterFailed = errors.New("cluster failed")
+	// ErrUpdateCancelled is returned when the cluster update is cancelled.
+	ErrUpdateCancelled = errors.New("cluster update cancelled")
+	// ErrUpdateFailed is returned when the cluster update failed.
+	ErrUpdateFailed = errors.New("cluster update failed")
+)
+
+// ClusterFailedError is returned when the cluster reaches
+// an unexpected terminal status (e.g. "FAILED").
+type ClusterFailedError struct {
+	ClusterName string
+	Status      string
+}
+
+func (e *ClusterFailedError) Error() string {
+	return fmt.Sprintf("unexpected cluster status %q (cluster %q)", e.Status, e.ClusterName)
+}
+
+// Is returns true if the target is "ErrClusterFailed".
+func (e *ClusterFailedError) Is(target error) bool {
+	return target == ErrClusterFailed
+}
@@ -38,6 +38,13 @@ func IsDeleted(err error) bool {
 	return strings.Contains(err.Error(), "No cluster found for name")
 }
 
+// EKSAPI is the subset of the aws-sdk-go-v2 EKS client used by the pollers.
+// "*aws_eks_v2.Client" satisfies this interface.
+type EKSAPI interface {
+	DescribeCluster(ctx context.Context, params *aws_eks_v2.DescribeClusterInput, optFns ...func(*aws_eks_v2.Options)) (*aws_eks_v2.DescribeClusterOutput, error)
+	DescribeUpdate(ctx context.Context, params *aws_eks_v2.DescribeUpdateInput, optFns ...func(*aws_eks_v2.Options)) (*aws_eks_v2.DescribeUpdateOutput, error)
+}
+
 // ClusterStatus represents the EKS cluster status.
 type ClusterStatus struct {
 	Cluster *aws_eks_v2_types.Cluster
@@ -46,12 +53,14 @@ type ClusterStatus struct {
 
 // Poll periodically fetches the cluster status
 // until the cluster becomes the desired state.
+// On timeout or stop, the last status carries the context/stop error
+// with the most recently observed cluster (if any) for diagnostics.
 func Poll(
 	ctx context.Context,
 	stopc chan struct{},
 	lg *zap.Logger,
 	logWriter io.Writer,
-	eksAPIV2 *aws_eks_v2.Client,
+	eksAPIV2 EKSAPI,
 	clusterName string,
 	desiredClusterStatus string,
 	initialWait time.Duration,
@@ -79,18 +88,22 @@ func Poll(
 		// wait from second interation
 		waitDur := time.Duration(0)
 
+		// retain the last observed cluster so that
+		// timeout or stop still returns diagnostic context
+		var lastCluster *aws_eks_v2_types.Cluster
+
 		first := true
 		for ctx.Err() == nil {
 			select {
 			case <-ctx.Done():
 				lg.Warn("wait aborted, ctx done", zap.Error(ctx.Err()))
-				ch <- ClusterStatus{Cluster: nil, Error: ctx.Err()}
+				ch <- ClusterStatus{Cluster: lastCluster, Error: ctx.Err()}
 				close(ch)
 				return
 
 			case <-stopc:
 				lg.Warn("wait stopped, stopc closed", zap.Error(ctx.Err()))
-				ch <- ClusterStatus{Cluster: nil, Error: errors.New("wait stopped")}
+				ch <- ClusterStatus{Cluster: lastCluster, Error: ErrWaitStopped}
 				close(ch)
 				return
 
@@ -134,21 +147,43 @@ func Poll(
 			}
 
 			cluster := output.Cluster
+			lastCluster = cluster
 			currentStatus := fmt.Sprint(cluster.Status)
+			currentVersion := aws_v2.ToString(cluster.Version)
 			lg.Info("poll",
 				zap.String("cluster-name", clusterName),
 				zap.String("status", currentStatus),
+				zap.String("version", currentVersion),
 				zap.String("started", humanize.RelTime(now, time.Now(), "ago", "from now")),
 				zap.String("ctx-time-left", ctxutil.TimeLeftTillDeadline(ctx)),
 			)
 			switch currentStatus {
 			case desiredClusterStatus:
+				if ret.desiredVersion != "" && currentVersion != ret.desiredVersion {
+					lg.Info("desired cluster status but not desired version; retrying",
+						zap.String("status", currentStatus),
+						zap.String("version", currentVersion),
+						zap.String("desired-version", ret.desiredVersion),
+					)
+					ch <- ClusterStatus{Cluster: cluster, Error: nil}
+					break
+				}
+				if ret.readyFunc != nil {
+					if err := ret.readyFunc(cluster); err != nil {
+						lg.Info("desired cluster status but not ready; retrying",
+							zap.String("status", currentStatus),
+							zap.Error(err),
+						)
+						ch <- ClusterStatus{Cluster: cluster, Error: nil}
+						break
+					}
+				}
 				ch <- ClusterStatus{Cluster: cluster, Error: nil}
-				lg.Info("desired cluster status; done", zap.String("status", currentStatus))
+				lg.Info("desired cluster status; done", zap.String("status", currentStatus), zap.String("version", currentVersion))
 				close(ch)
 				return
 			case fmt.Sprint(aws_eks_v2_types.ClusterStatusFailed):
-				ch <- ClusterStatus{Cluster: cluster, Error: fmt.Errorf("unexpected cluster status %q", aws_eks_v2_types.ClusterStatusFailed)}
+				ch <- ClusterStatus{Cluster: cluster, Error: &ClusterFailedError{ClusterName: clusterName, Status: currentStatus}}
 				lg.Warn("cluster status failed", zap.String("status", currentStatus), zap.String("desired-status", desiredClusterStatus))
 				close(ch)
 				return
@@ -161,22 +196,26 @@ func Poll(
 			}
 
 			if first {
-				lg.Info("sleeping", zap.Duration("initial-wait", initialWait))
+				firstWait := initialWait
+				if ret.initialWaitFunc != nil {
+					firstWait = ret.initialWaitFunc(currentStatus)
+				}
+				lg.Info("sleeping", zap.Duration("initial-wait", firstWait))
 				sp.Restart()
 				select {
 				case <-ctx.Done():
 					sp.Stop()
 					lg.Warn("wait aborted, ctx done", zap.Error(ctx.Err()))
-					ch <- ClusterStatus{Cluster: nil, Error: ctx.Err()}
+					ch <- ClusterStatus{Cluster: lastCluster, Error: ctx.Err()}
 					close(ch)
 					return
 				case <-stopc:
 					sp.Stop()
 					lg.Warn("wait stopped, stopc closed", zap.Error(ctx.Err()))
-					ch <- ClusterStatus{Cluster: nil, Error: errors.New("wait stopped")}
+					ch <- ClusterStatus{Cluster: lastCluster, Error: ErrWaitStopped}
 					close(ch)
 					return
-				case <-time.After(initialWait):
+				case <-time.After(firstWait):
 					sp.Stop()
 				}
 				first = false
@@ -184,7 +223,7 @@ func Poll(
 		}
 
 		lg.Warn("wait aborted, ctx done", zap.Error(ctx.Err()))
-		ch <- ClusterStatus{Cluster: nil, Error: ctx.Err()}
+		ch <- ClusterStatus{Cluster: lastCluster, Error: ctx.Err()}
 		close(ch)
 	}()
 	return ch
@@ -222,7 +261,7 @@ func PollUpdate(
 	stopc chan struct{},
 	lg *zap.Logger,
 	logWriter io.Writer,
-	eksAPIV2 *aws_eks_v2.Client,
+	eksAPIV2 EKSAPI,
 	clusterName string,
 	requestID string,
 	desiredUpdateStatus string,
@@ -262,7 +301,7 @@ func PollUpdate(
 
 			case <-stopc:
 				lg.Warn("wait stopped, stopc closed", zap.Error(ctx.Err()))
-				ch <- UpdateStatus{Update: nil, Error: errors.New("wait stopped")}
+				ch <- UpdateStatus{Update: nil, Error: ErrWaitStopped}
 				close(ch)
 				return
 
@@ -296,7 +335,7 @@ func PollUpdate(
 
 			if output.Update == nil {
 				lg.Warn("expected non-nil cluster update; retrying")
-				ch <- UpdateStatus{Update: nil, Error: fmt.Errorf("unexpected empty response %+v", output)}
+				ch <- UpdateStatus{Update: nil, Error: fmt.Errorf("unexpected empty response %+v", *output)}
 				continue
 			}
 
@@ -317,12 +356,12 @@ func PollUpdate(
 				close(ch)
 				return
 			case fmt.Sprint(aws_eks_v2_types.UpdateStatusCancelled):
-				ch <- UpdateStatus{Update: update, Error: fmt.Errorf("unexpected cluster update status %q", aws_eks_v2_types.UpdateStatusCancelled)}
+				ch <- UpdateStatus{Update: update, Error: fmt.Errorf("%w (unexpected cluster update status %q)", ErrUpdateCancelled, currentStatus)}
 				lg.Warn("cluster update status cancelled", zap.String("status", currentStatus), zap.String("desired-status", desiredUpdateStatus))
 				close(ch)
 				return
 			case fmt.Sprint(aws_eks_v2_types.UpdateStatusFailed):
-				ch <- UpdateStatus{Update: update, Error: fmt.Errorf("unexpected cluster update status %q", aws_eks_v2_types.UpdateStatusFailed)}
+				ch <- UpdateStatus{Update: update, Error: fmt.Errorf("%w (unexpected cluster update status %q)", ErrUpdateFailed, currentStatus)}
 				lg.Warn("cluster update status failed", zap.String("status", currentStatus), zap.String("desired-status", desiredUpdateStatus))
 				close(ch)
 				return
@@ -335,7 +374,11 @@ func PollUpdate(
 			}
 
 			if first {
-				lg.Info("sleeping", zap.Duration("initial-wait", initialWait))
+				firstWait := initialWait
+				if ret.initialWaitFunc != nil {
+					firstWait = ret.initialWaitFunc(currentStatus)
+				}
+				lg.Info("sleeping", zap.Duration("initial-wait", firstWait))
 				select {
 				case <-ctx.Done():
 					lg.Warn("wait aborted, ctx done", zap.Error(ctx.Err()))
@@ -345,11 +388,11 @@ func PollUpdate(
 
 				case <-stopc:
 					lg.Warn("wait stopped, stopc closed", zap.Error(ctx.Err()))
-					ch <- UpdateStatus{Update: nil, Error: errors.New("wait stopped")}
+					ch <- UpdateStatus{Update: nil, Error: ErrWaitStopped}
 					close(ch)
 					return
 
-				case <-time.After(initialWait):
+				case <-time.After(firstWait):
 				}
 				first = false
 			}
@@ -364,7 +407,14 @@ func PollUpdate(
 
 // Op represents a MNG operation.
 type Op struct {
-	queryFunc func()
+	queryFunc      func()
+	desiredVersion string
+
+	initialWaitFunc func(firstStatus string) time.Duration
+
+	readyFunc    func(*aws_eks_v2_types.Cluster) error
+	dialEndpoint bool
+	dialTimeout  time.Duration
 }
 
 // OpOption configures archiver operations.
@@ -375,6 +425,29 @@ func WithQueryFunc(f func()) OpOption {
 	return func(op *Op) { op.queryFunc = f }
 }
 
+// WithDesiredVersion configures the cluster poller to wait until
+// the cluster reports the desired Kubernetes version (e.g. "1.29")
+// in addition to the desired status. Only used for "Poll".
+func WithDesiredVersion(ver string) OpOption {
+	return func(op *Op) { op.desiredVersion = ver }
+}
+
+// WithInitialWaitFunc configures the function to compute the initial wait
+// from the status observed on the first successful describe call.
+// If not set, the fixed "initialWait" argument is used.
+func WithInitialWaitFunc(f func(firstStatus string) time.Duration) OpOption {
+	return func(op *Op) { op.initialWaitFunc = f }
+}
+
+// WithEndpointDial configures "PollEndpointReady" to confirm
+// the cluster endpoint is reachable with a TLS dial using the cluster CA.
+func WithEndpointDial(timeout time.Duration) OpOption {
+	return func(op *Op) {
+		op.dialEndpoint = true
+		op.dialTimeout = timeout
+	}
+}
+
 func (op *Op) applyOpts(opts []OpOption) {
 	for _, opt := range opts {
 		opt(op)
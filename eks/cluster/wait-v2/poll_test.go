@@ -0,0 +1,268 @@
+package wait_v2
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-k8s-tester/eksconfig"
+	aws_v2 "github.com/aws/aws-sdk-go-v2/aws"
+	aws_eks_v2 "github.com/aws/aws-sdk-go-v2/service/eks"
+	aws_eks_v2_types "github.com/aws/aws-sdk-go-v2/service/eks/types"
+	smithy "github.com/aws/smithy-go"
+	"go.uber.org/zap"
+)
+
+// fakeResult is the status or the error returned by a fake describe call.
+type fakeResult struct {
+	status string
+	// version is the cluster version, "1.29" if empty.
+	version string
+	err     error
+}
+
+// fakeEKSAPI returns the results in order,
+// and repeats the last result once exhausted.
+type fakeEKSAPI struct {
+	clusters []fakeResult
+	updates  []fakeResult
+
+	clusterCalls int
+	updateCalls  int
+}
+
+func next(results []fakeResult, calls int) fakeResult {
+	idx := calls - 1
+	if idx >= len(results) {
+		idx = len(results) - 1
+	}
+	return results[idx]
+}
+
+func (f *fakeEKSAPI) DescribeCluster(ctx context.Context, params *aws_eks_v2.DescribeClusterInput, optFns ...func(*aws_eks_v2.Options)) (*aws_eks_v2.DescribeClusterOutput, error) {
+	f.clusterCalls++
+	rv := next(f.clusters, f.clusterCalls)
+	if rv.err != nil {
+		return nil, rv.err
+	}
+	version := rv.version
+	if version == "" {
+		version = "1.29"
+	}
+	return &aws_eks_v2.DescribeClusterOutput{
+		Cluster: &aws_eks_v2_types.Cluster{
+			Name:    params.Name,
+			Status:  aws_eks_v2_types.ClusterStatus(rv.status),
+			Version: aws_v2.String(version),
+		},
+	}, nil
+}
+
+func (f *fakeEKSAPI) DescribeUpdate(ctx context.Context, params *aws_eks_v2.DescribeUpdateInput, optFns ...func(*aws_eks_v2.Options)) (*aws_eks_v2.DescribeUpdateOutput, error) {
+	f.updateCalls++
+	rv := next(f.updates, f.updateCalls)
+	if rv.err != nil {
+		return nil, rv.err
+	}
+	return &aws_eks_v2.DescribeUpdateOutput{
+		Update: &aws_eks_v2_types.Update{
+			Id:     params.UpdateId,
+			Status: aws_eks_v2_types.UpdateStatus(rv.status),
+		},
+	}, nil
+}
+
+func notFoundErr(msg string) error {
+	return &smithy.GenericAPIError{Code: "ResourceNotFoundException", Message: msg}
+}
+
+func TestPoll(t *testing.T) {
+	active := string(aws_eks_v2_types.ClusterStatusActive)
+	creating := string(aws_eks_v2_types.ClusterStatusCreating)
+	tt := []struct {
+		name         string
+		results      []fakeResult
+		desired      string
+		opts         []OpOption
+		timeout      time.Duration
+		pollInterval time.Duration
+		stopAfter    int
+
+		expCalls  int
+		expStatus string
+		expErr    func(error) bool
+	}{
+		{
+			name:      "already active",
+			results:   []fakeResult{{status: active}},
+			desired:   active,
+			expCalls:  1,
+			expStatus: active,
+		},
+		{
+			name:      "creating to active",
+			results:   []fakeResult{{status: creating}, {status: creating}, {status: active}},
+			desired:   active,
+			expCalls:  3,
+			expStatus: active,
+		},
+		{
+			name:      "failed",
+			results:   []fakeResult{{status: creating}, {status: string(aws_eks_v2_types.ClusterStatusFailed)}},
+			desired:   active,
+			expCalls:  2,
+			expStatus: string(aws_eks_v2_types.ClusterStatusFailed),
+			expErr:    func(err error) bool { return errors.Is(err, ErrClusterFailed) },
+		},
+		{
+			name:     "deleted as desired",
+			results:  []fakeResult{{status: string(aws_eks_v2_types.ClusterStatusDeleting)}, {err: notFoundErr("No cluster found for name: my-cluster.")}},
+			desired:  eksconfig.ClusterStatusDELETEDORNOTEXIST,
+			expCalls: 2,
+		},
+		{
+			name:     "deleted while waiting for active",
+			results:  []fakeResult{{err: notFoundErr("No cluster found for name: my-cluster.")}},
+			desired:  active,
+			expCalls: 1,
+			expErr:   IsDeleted,
+		},
+		{
+			name:      "retry describe errors",
+			results:   []fakeResult{{err: errors.New("connection reset")}, {status: active}},
+			desired:   active,
+			expCalls:  2,
+			expStatus: active,
+		},
+		{
+			name:      "desired version",
+			results:   []fakeResult{{status: active, version: "1.28"}, {status: active, version: "1.29"}},
+			desired:   active,
+			opts:      []OpOption{WithDesiredVersion("1.29")},
+			expCalls:  2,
+			expStatus: active,
+		},
+		{
+			name:         "timeout with last observed cluster",
+			results:      []fakeResult{{status: creating}},
+			desired:      active,
+			timeout:      50 * time.Millisecond,
+			pollInterval: time.Hour,
+			expCalls:     1,
+			expStatus:    creating,
+			expErr:       func(err error) bool { return errors.Is(err, context.DeadlineExceeded) },
+		},
+		{
+			name:         "stopped with last observed cluster",
+			results:      []fakeResult{{status: creating}},
+			desired:      active,
+			pollInterval: time.Hour,
+			stopAfter:    1,
+			expCalls:     1,
+			expStatus:    creating,
+			expErr:       func(err error) bool { return errors.Is(err, ErrWaitStopped) },
+		},
+	}
+	for _, tv := range tt {
+		t.Run(tv.name, func(t *testing.T) {
+			timeout, pollInterval := tv.timeout, tv.pollInterval
+			if timeout == 0 {
+				timeout = 10 * time.Second
+			}
+			if pollInterval == 0 {
+				pollInterval = time.Millisecond
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), timeout)
+			defer cancel()
+			stopc := make(chan struct{})
+
+			api := &fakeEKSAPI{clusters: tv.results}
+			var statuses []ClusterStatus
+			for sv := range Poll(ctx, stopc, zap.NewExample(), ioutil.Discard, api, "my-cluster", tv.desired, 0, pollInterval, tv.opts...) {
+				statuses = append(statuses, sv)
+				if len(statuses) == tv.stopAfter {
+					close(stopc)
+				}
+			}
+			if len(statuses) == 0 {
+				t.Fatal("no status received")
+			}
+			last := statuses[len(statuses)-1]
+
+			if tv.expErr == nil && last.Error != nil {
+				t.Fatalf("unexpected error %v", last.Error)
+			}
+			if tv.expErr != nil && !tv.expErr(last.Error) {
+				t.Fatalf("unexpected error %v", last.Error)
+			}
+			status := ""
+			if last.Cluster != nil {
+				status = string(last.Cluster.Status)
+			}
+			if status != tv.expStatus {
+				t.Fatalf("expected last status %q, got %q", tv.expStatus, status)
+			}
+			if api.clusterCalls != tv.expCalls {
+				t.Fatalf("expected DescribeCluster calls %d, got %d", tv.expCalls, api.clusterCalls)
+			}
+		})
+	}
+}
+
+func TestPollUpdate(t *testing.T) {
+	successful := string(aws_eks_v2_types.UpdateStatusSuccessful)
+	inProgress := string(aws_eks_v2_types.UpdateStatusInProgress)
+	tt := []struct {
+		name    string
+		results []fakeResult
+
+		expCalls  int
+		expStatus string
+		expErr    error
+	}{
+		{
+			name:      "in progress to successful",
+			results:   []fakeResult{{status: inProgress}, {status: successful}},
+			expCalls:  2,
+			expStatus: successful,
+		},
+		{
+			name:      "cancelled",
+			results:   []fakeResult{{status: inProgress}, {status: string(aws_eks_v2_types.UpdateStatusCancelled)}},
+			expCalls:  2,
+			expStatus: string(aws_eks_v2_types.UpdateStatusCancelled),
+			expErr:    ErrUpdateCancelled,
+		},
+		{
+			name:      "failed",
+			results:   []fakeResult{{status: string(aws_eks_v2_types.UpdateStatusFailed)}},
+			expCalls:  1,
+			expStatus: string(aws_eks_v2_types.UpdateStatusFailed),
+			expErr:    ErrUpdateFailed,
+		},
+	}
+	for _, tv := range tt {
+		t.Run(tv.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+
+			api := &fakeEKSAPI{updates: tv.results}
+			var last UpdateStatus
+			for sv := range PollUpdate(ctx, make(chan struct{}), zap.NewExample(), ioutil.Discard, api, "my-cluster", "my-update", successful, 0, time.Millisecond) {
+				last = sv
+			}
+
+			if !errors.Is(last.Error, tv.expErr) {
+				t.Fatalf("expected error %v, got %v", tv.expErr, last.Error)
+			}
+			if last.Update == nil || string(last.Update.Status) != tv.expStatus {
+				t.Fatalf("expected last status %q, got %+v", tv.expStatus, last.Update)
+			}
+			if api.updateCalls != tv.expCalls {
+				t.Fatalf("expected DescribeUpdate calls %d, got %d", tv.expCalls, api.updateCalls)
+			}
+		})
+	}
+}
@@ -0,0 +1,220 @@
+package wait
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-k8s-tester/eksconfig"
+	"github.com/aws/aws-k8s-tester/pkg/ctxutil"
+	pkg_wait "github.com/aws/aws-k8s-tester/pkg/wait"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	aws_eks "github.com/aws/aws-sdk-go/service/eks"
+	"github.com/aws/aws-sdk-go/service/eks/eksiface"
+	"github.com/dustin/go-humanize"
+	"go.uber.org/zap"
+)
+
+// ErrAddonFailed is matched by "AddonFailedError" via "errors.Is".
+var ErrAddonFailed = errors.New("addon failed")
+
+// AddonFailedError is returned when the add-on reaches
+// an unexpected terminal status (e.g. "CREATE_FAILED", "DEGRADED").
+type AddonFailedError struct {
+	ClusterName  string
+	AddonName    string
+	Status       string
+	HealthIssues []HealthIssue
+}
+
+func (e *AddonFailedError) Error() string {
+	if len(e.HealthIssues) == 0 {
+		return fmt.Sprintf("unexpected addon status %q (cluster %q, addon %q)", e.Status, e.ClusterName, e.AddonName)
+	}
+	return fmt.Sprintf("unexpected addon status %q (cluster %q, addon %q, health issues %s)", e.Status, e.ClusterName, e.AddonName, formatHealthIssues(e.HealthIssues))
+}
+
+// Is returns true if the target is "ErrAddonFailed".
+func (e *AddonFailedError) Is(target error) bool {
+	return target == ErrAddonFailed
+}
+
+// IsAddonDeleted returns true if error from EKS API indicates that
+// the EKS add-on does not exist.
+func IsAddonDeleted(err error) bool {
+	if err == nil {
+		return false
+	}
+	awsErr, ok := err.(awserr.Error)
+	if ok && awsErr.Code() == "ResourceNotFoundException" &&
+		strings.HasPrefix(awsErr.Message(), "No addon") {
+		return true
+	}
+	// ResourceNotFoundException: No addon: vpc-cni found in cluster: aws-k8s-tester-155468BC717E03B003
+	return strings.Contains(err.Error(), "No addon")
+}
+
+// AddonStatus represents the EKS add-on status.
+type AddonStatus struct {
+	Addon *aws_eks.Addon
+	Error error
+	// HealthIssues are the add-on health issues reported by EKS, if any.
+	HealthIssues []HealthIssue
+}
+
+// PollAddon periodically fetches the add-on status until the add-on
+// becomes the desired state (e.g. "ACTIVE", or "eksconfig.ClusterStatusDELETEDORNOTEXIST"
+// to wait for deletion). "CREATE_FAILED", "UPDATE_FAILED", "DELETE_FAILED",
+// and "DEGRADED" (unless desired) are terminal failures.
+// Supported options are "WithFailureStatuses", "WithDescribeTimeout",
+// "WithInitialWaitFunc", and "WithLogFields".
+func PollAddon(
+	ctx context.Context,
+	stopc chan struct{},
+	lg *zap.Logger,
+	logWriter io.Writer,
+	eksAPI eksiface.EKSAPI,
+	clusterName string,
+	addonName string,
+	desiredAddonStatus string,
+	initialWait time.Duration,
+	pollInterval time.Duration,
+	opts ...OpOption) <-chan AddonStatus {
+
+	ret := Op{}
+	ret.applyOpts(opts)
+	if len(ret.logFields) > 0 {
+		lg = lg.With(ret.logFields...)
+	}
+
+	now := time.Now()
+
+	lg.Info("polling addon",
+		zap.String("cluster-name", clusterName),
+		zap.String("addon-name", addonName),
+		zap.String("desired-status", desiredAddonStatus),
+		zap.String("initial-wait", initialWait.String()),
+		zap.String("poll-interval", pollInterval.String()),
+		zap.String("ctx-time-left", ctxutil.TimeLeftTillDeadline(ctx)),
+	)
+
+	var lastAddon *aws_eks.Addon
+
+	return pkg_wait.Poller[AddonStatus]{
+		Describe: func(ctx context.Context) (AddonStatus, error) {
+			attemptCtx, attemptCancel := ret.attemptContext(ctx)
+			callStart := time.Now()
+			output, err := eksAPI.DescribeAddonWithContext(attemptCtx, &aws_eks.DescribeAddonInput{
+				ClusterName: aws.String(clusterName),
+				AddonName:   aws.String(addonName),
+			})
+			ret.observeAPILatency("DescribeAddon", callStart, err)
+			attemptCancel()
+			if err != nil {
+				return AddonStatus{}, err
+			}
+			if output.Addon == nil {
+				return AddonStatus{}, fmt.Errorf("%w %+v", errEmptyResponse, output.GoString())
+			}
+			return AddonStatus{Addon: output.Addon}, nil
+		},
+		Classify: func(sv AddonStatus, err error) (AddonStatus, bool) {
+			if err != nil {
+				if IsAddonDeleted(err) {
+					if desiredAddonStatus == eksconfig.ClusterStatusDELETEDORNOTEXIST {
+						lg.Info("addon is already deleted as desired; exiting", zap.Error(err))
+						return AddonStatus{Addon: nil, Error: nil}, true
+					}
+					lg.Warn("addon does not exist; aborting", zap.Error(err))
+					return AddonStatus{Addon: nil, Error: err}, true
+				}
+				lg.Warn("describe addon failed; retrying", zap.Error(err))
+				return AddonStatus{Addon: nil, Error: err}, false
+			}
+
+			addon := sv.Addon
+			lastAddon = addon
+			currentStatus := aws.StringValue(addon.Status)
+			healthIssues := addonHealthIssues(addon)
+			lg.Info("poll",
+				zap.String("cluster-name", clusterName),
+				zap.String("addon-name", addonName),
+				zap.String("status", currentStatus),
+				zap.String("addon-version", aws.StringValue(addon.AddonVersion)),
+				zap.String("health-issues", formatHealthIssues(healthIssues)),
+				zap.String("started", humanize.RelTime(now, time.Now(), "ago", "from now")),
+				zap.String("ctx-time-left", ctxutil.TimeLeftTillDeadline(ctx)),
+			)
+			switch {
+			case currentStatus == desiredAddonStatus:
+				lg.Info("desired addon status; done", zap.String("status", currentStatus))
+				return AddonStatus{Addon: addon, Error: nil, HealthIssues: healthIssues}, true
+			case ret.isAddonFailureStatus(currentStatus):
+				failErr := &AddonFailedError{
+					ClusterName:  clusterName,
+					AddonName:    addonName,
+					Status:       currentStatus,
+					HealthIssues: healthIssues,
+				}
+				lg.Warn("addon status failed",
+					zap.String("status", currentStatus),
+					zap.String("desired-status", desiredAddonStatus),
+					zap.String("health-issues", formatHealthIssues(healthIssues)),
+				)
+				return AddonStatus{Addon: addon, Error: failErr, HealthIssues: healthIssues}, true
+			}
+			return AddonStatus{Addon: addon, Error: nil, HealthIssues: healthIssues}, false
+		},
+		Abort: func(err error) AddonStatus {
+			return AddonStatus{Addon: lastAddon, Error: err}
+		},
+		InitialWait: func(sv AddonStatus) time.Duration {
+			if ret.initialWaitFunc != nil {
+				return ret.initialWaitFunc(aws.StringValue(sv.Addon.Status))
+			}
+			return initialWait
+		},
+		PollInterval:   pollInterval,
+		LogWriter:      logWriter,
+		SpinnerMessage: "Waiting for addon status " + desiredAddonStatus,
+	}.Poll(ctx, stopc, lg)
+}
+
+// isAddonFailureStatus returns true if the add-on status is terminal failure.
+func (op *Op) isAddonFailureStatus(status string) bool {
+	switch status {
+	case aws_eks.AddonStatusCreateFailed,
+		aws_eks.AddonStatusUpdateFailed,
+		aws_eks.AddonStatusDeleteFailed,
+		aws_eks.AddonStatusDegraded:
+		return true
+	}
+	for _, v := range op.failureStatuses {
+		if status == v {
+			return true
+		}
+	}
+	return false
+}
+
+// addonHealthIssues returns the health issues of the add-on.
+func addonHealthIssues(addon *aws_eks.Addon) (issues []HealthIssue) {
+	if addon == nil || addon.Health == nil {
+		return nil
+	}
+	for _, v := range addon.Health.Issues {
+		if v == nil {
+			continue
+		}
+		issues = append(issues, HealthIssue{
+			Code:        aws.StringValue(v.Code),
+			Message:     aws.StringValue(v.Message),
+			ResourceIDs: aws.StringValueSlice(v.ResourceIds),
+		})
+	}
+	return issues
+}
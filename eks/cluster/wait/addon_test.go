@@ -0,0 +1,162 @@
+package wait
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-k8s-tester/eksconfig"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	aws_eks "github.com/aws/aws-sdk-go/service/eks"
+	"go.uber.org/zap"
+)
+
+func (f *fakeEKSAPI) DescribeAddonWithContext(ctx aws.Context, input *aws_eks.DescribeAddonInput, opts ...request.Option) (*aws_eks.DescribeAddonOutput, error) {
+	rv := f.next("addon/"+aws.StringValue(input.AddonName), f.addons)
+	if rv.err != nil {
+		return nil, rv.err
+	}
+	addon := &aws_eks.Addon{
+		ClusterName:  input.ClusterName,
+		AddonName:    input.AddonName,
+		AddonVersion: aws.String("v1.15.1-eksbuild.1"),
+		Status:       aws.String(rv.status),
+	}
+	if f.addon != nil {
+		f.addon(addon)
+	}
+	return &aws_eks.DescribeAddonOutput{Addon: addon}, nil
+}
+
+func addonNotFoundErr() error {
+	return awserr.New("ResourceNotFoundException", "No addon: vpc-cni found in cluster: my-cluster", nil)
+}
+
+func TestPollAddon(t *testing.T) {
+	tt := []struct {
+		name         string
+		results      []fakeResult
+		health       *aws_eks.AddonHealth
+		desired      string
+		timeout      time.Duration
+		pollInterval time.Duration
+		stopAfter    int
+
+		expCalls  int
+		expStatus string
+		expErr    func(error) bool
+	}{
+		{
+			name:      "creating to active",
+			results:   []fakeResult{{status: aws_eks.AddonStatusCreating}, {err: throttlingErr()}, {status: aws_eks.AddonStatusActive}},
+			desired:   aws_eks.AddonStatusActive,
+			expCalls:  3,
+			expStatus: aws_eks.AddonStatusActive,
+		},
+		{
+			name:    "create failed with health issues",
+			results: []fakeResult{{status: aws_eks.AddonStatusCreating}, {status: aws_eks.AddonStatusCreateFailed}},
+			health: &aws_eks.AddonHealth{Issues: []*aws_eks.AddonIssue{
+				{Code: aws.String("InsufficientNumberOfReplicas"), Message: aws.String("not enough replicas"), ResourceIds: aws.StringSlice([]string{"aws-node"})},
+			}},
+			desired:   aws_eks.AddonStatusActive,
+			expCalls:  2,
+			expStatus: aws_eks.AddonStatusCreateFailed,
+			expErr: func(err error) bool {
+				var failErr *AddonFailedError
+				return errors.Is(err, ErrAddonFailed) && errors.As(err, &failErr) &&
+					len(failErr.HealthIssues) == 1 && failErr.HealthIssues[0].Code == "InsufficientNumberOfReplicas"
+			},
+		},
+		{
+			name:      "degraded",
+			results:   []fakeResult{{status: aws_eks.AddonStatusDegraded}},
+			desired:   aws_eks.AddonStatusActive,
+			expCalls:  1,
+			expStatus: aws_eks.AddonStatusDegraded,
+			expErr:    func(err error) bool { return errors.Is(err, ErrAddonFailed) },
+		},
+		{
+			name:     "deleted as desired",
+			results:  []fakeResult{{status: aws_eks.AddonStatusDeleting}, {err: addonNotFoundErr()}},
+			desired:  eksconfig.ClusterStatusDELETEDORNOTEXIST,
+			expCalls: 2,
+		},
+		{
+			name:     "deleted while waiting for active",
+			results:  []fakeResult{{err: addonNotFoundErr()}},
+			desired:  aws_eks.AddonStatusActive,
+			expCalls: 1,
+			expErr:   IsAddonDeleted,
+		},
+		{
+			name:         "timeout with last observed addon",
+			results:      []fakeResult{{status: aws_eks.AddonStatusCreating}},
+			desired:      aws_eks.AddonStatusActive,
+			timeout:      50 * time.Millisecond,
+			pollInterval: time.Hour,
+			expCalls:     1,
+			expStatus:    aws_eks.AddonStatusCreating,
+			expErr:       func(err error) bool { return errors.Is(err, context.DeadlineExceeded) },
+		},
+		{
+			name:         "stopped with last observed addon",
+			results:      []fakeResult{{status: aws_eks.AddonStatusCreating}},
+			desired:      aws_eks.AddonStatusActive,
+			pollInterval: time.Hour,
+			stopAfter:    1,
+			expCalls:     1,
+			expStatus:    aws_eks.AddonStatusCreating,
+			expErr:       func(err error) bool { return errors.Is(err, ErrWaitStopped) },
+		},
+	}
+	for _, tv := range tt {
+		t.Run(tv.name, func(t *testing.T) {
+			timeout, pollInterval := tv.timeout, tv.pollInterval
+			if timeout == 0 {
+				timeout = 10 * time.Second
+			}
+			if pollInterval == 0 {
+				pollInterval = time.Millisecond
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), timeout)
+			defer cancel()
+
+			api := &fakeEKSAPI{addons: tv.results}
+			if tv.health != nil {
+				api.addon = func(addon *aws_eks.Addon) { addon.Health = tv.health }
+			}
+			stopc := make(chan struct{})
+			var last AddonStatus
+			received := 0
+			for sv := range PollAddon(ctx, stopc, zap.NewExample(), ioutil.Discard, api, "my-cluster", "vpc-cni", tv.desired, 0, pollInterval) {
+				last = sv
+				received++
+				if received == tv.stopAfter {
+					close(stopc)
+				}
+			}
+
+			if tv.expErr == nil && last.Error != nil {
+				t.Fatalf("unexpected error %v", last.Error)
+			}
+			if tv.expErr != nil && !tv.expErr(last.Error) {
+				t.Fatalf("unexpected error %v", last.Error)
+			}
+			status := ""
+			if last.Addon != nil {
+				status = aws.StringValue(last.Addon.Status)
+			}
+			if status != tv.expStatus {
+				t.Fatalf("expected last status %q, got %q", tv.expStatus, status)
+			}
+			if calls := api.callCount("addon/vpc-cni"); calls != tv.expCalls {
+				t.Fatalf("expected DescribeAddon calls %d, got %d", tv.expCalls, calls)
+			}
+		})
+	}
+}
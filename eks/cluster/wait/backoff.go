@@ -0,0 +1,44 @@
+package wait
+
+import (
+	"math/rand"
+	"time"
+)
+
+// Backoff configures the exponential backoff between cluster polls.
+type Backoff struct {
+	// Factor is the multiplier applied to the poll interval after
+	// every poll (e.g. 2.0). Values less than or equal to 1 disable growth.
+	Factor float64
+	// Jitter is the maximum random fraction of the interval
+	// to add to each wait (e.g. 0.1 for up to 10%).
+	Jitter float64
+	// MaxInterval caps the poll interval before jitter.
+	// Zero means no cap.
+	MaxInterval time.Duration
+	// ResetOnStatusChange resets the interval to the initial
+	// poll interval whenever the observed status changes.
+	ResetOnStatusChange bool
+}
+
+// next returns the next poll interval before jitter.
+func (b *Backoff) next(cur time.Duration, pollInterval time.Duration, statusChanged bool) time.Duration {
+	if b.ResetOnStatusChange && statusChanged {
+		return pollInterval
+	}
+	if b.Factor > 1 {
+		cur = time.Duration(float64(cur) * b.Factor)
+	}
+	if b.MaxInterval > 0 && cur > b.MaxInterval {
+		cur = b.MaxInterval
+	}
+	return cur
+}
+
+// jitter returns the interval with random jitter added.
+func (b *Backoff) jitter(interval time.Duration) time.Duration {
+	if b.Jitter <= 0 {
+		return interval
+	}
+	return interval + time.Duration(rand.Float64()*b.Jitter*float64(interval))
+}
@@ -0,0 +1,89 @@
+package wait
+
+import (
+	"testing"
+	"time"
+
+	aws_eks "github.com/aws/aws-sdk-go/service/eks"
+)
+
+func TestBackoffNext(t *testing.T) {
+	tt := []struct {
+		name          string
+		backoff       Backoff
+		cur           time.Duration
+		statusChanged bool
+		exp           time.Duration
+	}{
+		{
+			name:    "grow by factor",
+			backoff: Backoff{Factor: 2},
+			cur:     10 * time.Second,
+			exp:     20 * time.Second,
+		},
+		{
+			name:    "no growth with factor 1",
+			backoff: Backoff{Factor: 1},
+			cur:     10 * time.Second,
+			exp:     10 * time.Second,
+		},
+		{
+			name:    "capped by max interval",
+			backoff: Backoff{Factor: 2, MaxInterval: 15 * time.Second},
+			cur:     10 * time.Second,
+			exp:     15 * time.Second,
+		},
+		{
+			name:          "status change without reset",
+			backoff:       Backoff{Factor: 2},
+			cur:           10 * time.Second,
+			statusChanged: true,
+			exp:           20 * time.Second,
+		},
+		{
+			name:          "reset on status change",
+			backoff:       Backoff{Factor: 2, ResetOnStatusChange: true},
+			cur:           40 * time.Second,
+			statusChanged: true,
+			exp:           5 * time.Second,
+		},
+	}
+	for _, tv := range tt {
+		t.Run(tv.name, func(t *testing.T) {
+			if got := tv.backoff.next(tv.cur, 5*time.Second, tv.statusChanged); got != tv.exp {
+				t.Fatalf("expected %v, got %v", tv.exp, got)
+			}
+		})
+	}
+}
+
+func TestBackoffJitter(t *testing.T) {
+	b := Backoff{}
+	if got := b.jitter(time.Second); got != time.Second {
+		t.Fatalf("expected no jitter, got %v", got)
+	}
+	b = Backoff{Jitter: 0.1}
+	for i := 0; i < 100; i++ {
+		if got := b.jitter(time.Second); got < time.Second || got > 1100*time.Millisecond {
+			t.Fatalf("jitter %v out of range", got)
+		}
+	}
+}
+
+func TestPollBackoff(t *testing.T) {
+	api := &fakeEKSAPI{clusters: map[string][]fakeResult{"my-cluster": {
+		{status: aws_eks.ClusterStatusCreating},
+		{status: aws_eks.ClusterStatusCreating},
+		{status: aws_eks.ClusterStatusCreating},
+		{status: aws_eks.ClusterStatusActive},
+	}}}
+	statuses := pollCluster(t, api, aws_eks.ClusterStatusActive, 10*time.Second, time.Millisecond, 0,
+		WithBackoff(Backoff{Factor: 2, Jitter: 0.5, MaxInterval: 4 * time.Millisecond}),
+	)
+	if last := statuses[len(statuses)-1]; last.Error != nil {
+		t.Fatal(last.Error)
+	}
+	if calls := api.callCount("my-cluster"); calls != 4 {
+		t.Fatalf("expected DescribeCluster calls 4, got %d", calls)
+	}
+}
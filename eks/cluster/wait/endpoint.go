@@ -0,0 +1,178 @@
+package wait
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	aws_eks "github.com/aws/aws-sdk-go/service/eks"
+	"github.com/aws/aws-sdk-go/service/eks/eksiface"
+	"go.uber.org/zap"
+)
+
+// PollEndpointReady periodically fetches the cluster status
+// until the cluster is "ACTIVE" with non-empty API server endpoint,
+// certificate authority data, and OIDC issuer URL.
+// If "WithEndpointDial" is set, it also requires a successful TLS dial
+// to the API server endpoint with the cluster CA.
+func PollEndpointReady(
+	ctx context.Context,
+	stopc chan struct{},
+	lg *zap.Logger,
+	logWriter io.Writer,
+	eksAPI eksiface.EKSAPI,
+	clusterName string,
+	initialWait time.Duration,
+	pollInterval time.Duration,
+	opts ...OpOption) <-chan ClusterStatus {
+
+	ret := Op{}
+	ret.applyOpts(opts)
+
+	readyFunc := func(cluster *aws_eks.Cluster) error {
+		return checkEndpointReady(cluster, ret.dialEndpoint, ret.dialTimeout)
+	}
+	opts = append(opts, func(op *Op) { op.readyFunc = readyFunc })
+
+	return Poll(
+		ctx,
+		stopc,
+		lg,
+		logWriter,
+		eksAPI,
+		clusterName,
+		aws_eks.ClusterStatusActive,
+		initialWait,
+		pollInterval,
+		opts...,
+	)
+}
+
+func checkEndpointReady(cluster *aws_eks.Cluster, dial bool, dialTimeout time.Duration) error {
+	endpoint := aws.StringValue(cluster.Endpoint)
+	if endpoint == "" {
+		return errors.New("empty cluster endpoint")
+	}
+	caData := ""
+	if cluster.CertificateAuthority != nil {
+		caData = aws.StringValue(cluster.CertificateAuthority.Data)
+	}
+	if caData == "" {
+		return errors.New("empty cluster certificate authority data")
+	}
+	issuer := ""
+	if cluster.Identity != nil && cluster.Identity.Oidc != nil {
+		issuer = aws.StringValue(cluster.Identity.Oidc.Issuer)
+	}
+	if issuer == "" {
+		return errors.New("empty cluster OIDC issuer")
+	}
+	if !dial {
+		return nil
+	}
+	return dialEndpoint(endpoint, caData, dialTimeout)
+}
+
+// dialEndpoint opens a TLS connection to the cluster endpoint,
+// verifying the server certificate with the base64-encoded cluster CA.
+func dialEndpoint(endpoint string, caData string, timeout time.Duration) error {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return fmt.Errorf("failed to parse endpoint %q (%v)", endpoint, err)
+	}
+	host := u.Host
+	if u.Port() == "" {
+		host = net.JoinHostPort(u.Hostname(), "443")
+	}
+
+	pool, err := certPool(caData)
+	if err != nil {
+		return err
+	}
+
+	if timeout == 0 {
+		timeout = 10 * time.Second
+	}
+	conn, err := tls.DialWithDialer(
+		&net.Dialer{Timeout: timeout},
+		"tcp",
+		host,
+		&tls.Config{RootCAs: pool, ServerName: u.Hostname()},
+	)
+	if err != nil {
+		return fmt.Errorf("failed to dial endpoint %q (%v)", host, err)
+	}
+	return conn.Close()
+}
+
+// probeAPIServer sends GET requests to the "/healthz" and "/version"
+// paths of the cluster endpoint, verifying the server certificate with
+// the base64-encoded cluster CA. It returns nil on the first 200 response.
+// Both paths are readable without credentials ("system:public-info-viewer").
+func probeAPIServer(cluster *aws_eks.Cluster, timeout time.Duration) error {
+	endpoint := strings.TrimSuffix(aws.StringValue(cluster.Endpoint), "/")
+	if endpoint == "" {
+		return errors.New("empty cluster endpoint")
+	}
+	caData := ""
+	if cluster.CertificateAuthority != nil {
+		caData = aws.StringValue(cluster.CertificateAuthority.Data)
+	}
+	pool, err := certPool(caData)
+	if err != nil {
+		return err
+	}
+
+	if timeout == 0 {
+		timeout = 10 * time.Second
+	}
+	cli := &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{RootCAs: pool},
+		},
+	}
+	defer cli.CloseIdleConnections()
+
+	var errs []string
+	for _, path := range []string{"/healthz", "/version"} {
+		resp, err := cli.Get(endpoint + path)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", path, err))
+			continue
+		}
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+		if resp.StatusCode == http.StatusOK {
+			return nil
+		}
+		errs = append(errs, fmt.Sprintf("%s: unexpected status %q", path, resp.Status))
+	}
+	return fmt.Errorf("API server %q not serving (%s)", endpoint, strings.Join(errs, ", "))
+}
+
+// certPool returns the cert pool with the base64-encoded cluster CA.
+func certPool(caData string) (*x509.CertPool, error) {
+	if caData == "" {
+		return nil, errors.New("empty cluster certificate authority data")
+	}
+	ca, err := base64.StdEncoding.DecodeString(caData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode cluster CA (%v)", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(ca) {
+		return nil, errors.New("failed to parse cluster CA")
+	}
+	return pool, nil
+}
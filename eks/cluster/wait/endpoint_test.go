@@ -0,0 +1,171 @@
+package wait
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	aws_eks "github.com/aws/aws-sdk-go/service/eks"
+	"go.uber.org/zap"
+)
+
+// newAPIServer returns the TLS server that serves "/healthz" and "/version"
+// from the "healthyAfter"-th "/healthz" request, and the base64-encoded CA.
+func newAPIServer(t *testing.T, healthyAfter int32) (*httptest.Server, string) {
+	var healthz int32
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.URL.Path == "/healthz" {
+			atomic.AddInt32(&healthz, 1)
+		}
+		if atomic.LoadInt32(&healthz) < healthyAfter {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte("ok"))
+	}))
+	t.Cleanup(srv.Close)
+	ca := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+	return srv, base64.StdEncoding.EncodeToString(ca)
+}
+
+func withEndpoint(endpoint string, caData string, issuer string) func(*aws_eks.Cluster) {
+	return func(cluster *aws_eks.Cluster) {
+		cluster.Endpoint = aws.String(endpoint)
+		cluster.CertificateAuthority = &aws_eks.Certificate{Data: aws.String(caData)}
+		cluster.Identity = &aws_eks.Identity{Oidc: &aws_eks.OIDC{Issuer: aws.String(issuer)}}
+	}
+}
+
+func TestPollAPIServerProbe(t *testing.T) {
+	srv, caData := newAPIServer(t, 3)
+	api := &fakeEKSAPI{
+		clusters: map[string][]fakeResult{"my-cluster": {{status: aws_eks.ClusterStatusCreating}, {status: aws_eks.ClusterStatusActive}}},
+		cluster:  withEndpoint(srv.URL, caData, "https://oidc.eks.us-west-2.amazonaws.com/id/EXAMPLE"),
+	}
+	statuses := pollCluster(t, api, aws_eks.ClusterStatusActive, 10*time.Second, time.Millisecond, 0, WithAPIServerProbe(time.Second))
+	if last := statuses[len(statuses)-1]; last.Error != nil {
+		t.Fatal(last.Error)
+	}
+	// ACTIVE on the second call, but the API server serves from the third probe
+	if calls := api.callCount("my-cluster"); calls != 4 {
+		t.Fatalf("expected DescribeCluster calls 4, got %d", calls)
+	}
+
+	// never serving, until timeout
+	srv, caData = newAPIServer(t, 1000)
+	api = &fakeEKSAPI{
+		clusters: map[string][]fakeResult{"my-cluster": {{status: aws_eks.ClusterStatusActive}}},
+		cluster:  withEndpoint(srv.URL, caData, ""),
+	}
+	statuses = pollCluster(t, api, aws_eks.ClusterStatusActive, 100*time.Millisecond, time.Millisecond, 0, WithAPIServerProbe(time.Second))
+	last := statuses[len(statuses)-1]
+	if !errors.Is(last.Error, context.DeadlineExceeded) {
+		t.Fatalf("expected context.DeadlineExceeded, got %v", last.Error)
+	}
+	if last.Cluster == nil || aws.StringValue(last.Cluster.Status) != aws_eks.ClusterStatusActive {
+		t.Fatalf("expected last observed ACTIVE cluster, got %+v", last.Cluster)
+	}
+
+	// probe only applies to "ACTIVE"
+	api = &fakeEKSAPI{
+		clusters: map[string][]fakeResult{"my-cluster": {{status: aws_eks.ClusterStatusUpdating}}},
+		cluster:  withEndpoint(srv.URL, caData, ""),
+	}
+	statuses = pollCluster(t, api, aws_eks.ClusterStatusUpdating, 10*time.Second, time.Millisecond, 0, WithAPIServerProbe(time.Second))
+	if last := statuses[len(statuses)-1]; last.Error != nil {
+		t.Fatal(last.Error)
+	}
+}
+
+func TestProbeAPIServer(t *testing.T) {
+	srv, caData := newAPIServer(t, 0)
+	tt := []struct {
+		name    string
+		cluster *aws_eks.Cluster
+		expErr  bool
+	}{
+		{
+			name:    "serving",
+			cluster: &aws_eks.Cluster{Endpoint: aws.String(srv.URL + "/"), CertificateAuthority: &aws_eks.Certificate{Data: aws.String(caData)}},
+		},
+		{
+			name:    "empty endpoint",
+			cluster: &aws_eks.Cluster{CertificateAuthority: &aws_eks.Certificate{Data: aws.String(caData)}},
+			expErr:  true,
+		},
+		{
+			name:    "empty CA",
+			cluster: &aws_eks.Cluster{Endpoint: aws.String(srv.URL)},
+			expErr:  true,
+		},
+		{
+			name:    "invalid CA",
+			cluster: &aws_eks.Cluster{Endpoint: aws.String(srv.URL), CertificateAuthority: &aws_eks.Certificate{Data: aws.String(base64.StdEncoding.EncodeToString([]byte("invalid")))}},
+			expErr:  true,
+		},
+	}
+	for _, tv := range tt {
+		t.Run(tv.name, func(t *testing.T) {
+			err := probeAPIServer(tv.cluster, time.Second)
+			if tv.expErr != (err != nil) {
+				t.Fatalf("expected error %v, got %v", tv.expErr, err)
+			}
+		})
+	}
+}
+
+func TestPollEndpointReady(t *testing.T) {
+	srv, caData := newAPIServer(t, 0)
+	issuer := "https://oidc.eks.us-west-2.amazonaws.com/id/EXAMPLE"
+
+	var calls int32
+	api := &fakeEKSAPI{
+		clusters: map[string][]fakeResult{"my-cluster": {{status: aws_eks.ClusterStatusActive}}},
+		cluster: func(cluster *aws_eks.Cluster) {
+			// endpoint, CA, and OIDC issuer are populated one at a time
+			switch atomic.AddInt32(&calls, 1) {
+			case 1:
+			case 2:
+				withEndpoint(srv.URL, "", "")(cluster)
+			case 3:
+				withEndpoint(srv.URL, caData, "")(cluster)
+			default:
+				withEndpoint(srv.URL, caData, issuer)(cluster)
+			}
+		},
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	var last ClusterStatus
+	for sv := range PollEndpointReady(ctx, nil, zap.NewExample(), ioutil.Discard, api, "my-cluster", 0, time.Millisecond, WithEndpointDial(time.Second)) {
+		last = sv
+	}
+	if last.Error != nil {
+		t.Fatal(last.Error)
+	}
+	if n := api.callCount("my-cluster"); n != 4 {
+		t.Fatalf("expected DescribeCluster calls 4, got %d", n)
+	}
+
+	if err := checkEndpointReady(&aws_eks.Cluster{}, false, 0); err == nil {
+		t.Fatal("expected error for empty endpoint")
+	}
+	// closed server fails the dial
+	srv.Close()
+	cluster := &aws_eks.Cluster{}
+	withEndpoint(srv.URL, caData, issuer)(cluster)
+	if err := checkEndpointReady(cluster, false, 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := checkEndpointReady(cluster, true, time.Second); err == nil {
+		t.Fatal("expected dial error for closed server")
+	}
+}
@@ -0,0 +1,179 @@
+package wait
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	pkg_wait "github.com/aws/aws-k8s-tester/pkg/wait"
+	"github.com/aws/aws-sdk-go/aws"
+	aws_eks "github.com/aws/aws-sdk-go/service/eks"
+)
+
+var (
+	// ErrWaitStopped is returned when the wait is stopped via stop channel.
+	ErrWaitStopped = pkg_wait.ErrWaitStopped
+	// ErrClusterFailed is matched by "ClusterFailedError" via "errors.Is".
+	ErrClusterFailed = errors.New("cluster failed")
+	// ErrClusterNotFound is returned, wrapping the EKS API error, when the cluster
+	// does not exist while waiting for any status other than deletion.
+	ErrClusterNotFound = errors.New("cluster not found")
+	// ErrUpdateCancelled is returned when the cluster update is cancelled.
+	ErrUpdateCancelled = errors.New("cluster update cancelled")
+	// ErrUpdateFailed is returned when the cluster update failed.
+	ErrUpdateFailed = errors.New("cluster update failed")
+
+	errEmptyResponse = errors.New("unexpected empty response")
+)
+
+// ClusterFailedError is returned when the cluster reaches
+// an unexpected terminal status (e.g. "FAILED").
+type ClusterFailedError struct {
+	ClusterName string
+	Status      string
+	// HealthIssues are the cluster health issues reported by EKS, if any.
+	HealthIssues []HealthIssue
+}
+
+func (e *ClusterFailedError) Error() string {
+	if len(e.HealthIssues) == 0 {
+		return fmt.Sprintf("unexpected cluster status %q (cluster %q)", e.Status, e.ClusterName)
+	}
+	return fmt.Sprintf("unexpected cluster status %q (cluster %q, health issues %s)", e.Status, e.ClusterName, formatHealthIssues(e.HealthIssues))
+}
+
+// Is returns true if the target is "ErrClusterFailed".
+func (e *ClusterFailedError) Is(target error) bool {
+	return target == ErrClusterFailed
+}
+
+// newClusterNotFoundError wraps the EKS API error with "ErrClusterNotFound",
+// so that callers can match either via "errors.Is" or "errors.As".
+func newClusterNotFoundError(clusterName string, err error) error {
+	return fmt.Errorf("%w %q (%w)", ErrClusterNotFound, clusterName, err)
+}
+
+// HealthIssue represents an EKS health issue.
+type HealthIssue struct {
+	Code        string   `json:"code"`
+	Message     string   `json:"message"`
+	ResourceIDs []string `json:"resource-ids"`
+}
+
+func (hi HealthIssue) String() string {
+	if len(hi.ResourceIDs) == 0 {
+		return fmt.Sprintf("%s: %s", hi.Code, hi.Message)
+	}
+	return fmt.Sprintf("%s: %s (resources %s)", hi.Code, hi.Message, strings.Join(hi.ResourceIDs, ", "))
+}
+
+// clusterHealthIssues returns the health issues of the cluster.
+func clusterHealthIssues(cluster *aws_eks.Cluster) (issues []HealthIssue) {
+	if cluster == nil || cluster.Health == nil {
+		return nil
+	}
+	for _, v := range cluster.Health.Issues {
+		if v == nil {
+			continue
+		}
+		issues = append(issues, HealthIssue{
+			Code:        aws.StringValue(v.Code),
+			Message:     aws.StringValue(v.Message),
+			ResourceIDs: aws.StringValueSlice(v.ResourceIds),
+		})
+	}
+	return issues
+}
+
+func formatHealthIssues(issues []HealthIssue) string {
+	ss := make([]string, 0, len(issues))
+	for _, v := range issues {
+		ss = append(ss, "["+v.String()+"]")
+	}
+	return strings.Join(ss, ", ")
+}
+
+// UpdateError represents an EKS cluster update error detail.
+type UpdateError struct {
+	Code        string   `json:"code"`
+	Message     string   `json:"message"`
+	ResourceIDs []string `json:"resource-ids"`
+}
+
+func (ue UpdateError) String() string {
+	if len(ue.ResourceIDs) == 0 {
+		return fmt.Sprintf("%s: %s", ue.Code, ue.Message)
+	}
+	return fmt.Sprintf("%s: %s (resources %s)", ue.Code, ue.Message, strings.Join(ue.ResourceIDs, ", "))
+}
+
+// UpdateFailedError is returned when the cluster update is
+// cancelled or failed, with the error details reported by EKS.
+// It matches "ErrUpdateCancelled" or "ErrUpdateFailed" via "errors.Is",
+// depending on the status.
+type UpdateFailedError struct {
+	ClusterName string
+	UpdateID    string
+	Status      string
+	Errors      []UpdateError
+	// Params are the update parameters (e.g. "Version": "1.29").
+	Params map[string]string
+}
+
+func (e *UpdateFailedError) Error() string {
+	sentinel := ErrUpdateFailed
+	if e.Status == aws_eks.UpdateStatusCancelled {
+		sentinel = ErrUpdateCancelled
+	}
+	if len(e.Errors) == 0 {
+		return fmt.Sprintf("%v (unexpected cluster update status %q)", sentinel, e.Status)
+	}
+	return fmt.Sprintf("%v (unexpected cluster update status %q, errors %s)", sentinel, e.Status, formatUpdateErrors(e.Errors))
+}
+
+// Is returns true if the target is "ErrUpdateCancelled" for cancelled updates,
+// or "ErrUpdateFailed" for failed updates.
+func (e *UpdateFailedError) Is(target error) bool {
+	if e.Status == aws_eks.UpdateStatusCancelled {
+		return target == ErrUpdateCancelled
+	}
+	return target == ErrUpdateFailed
+}
+
+// newUpdateFailedError extracts the error details and parameters of the update.
+func newUpdateFailedError(clusterName string, update *aws_eks.Update) *UpdateFailedError {
+	e := &UpdateFailedError{
+		ClusterName: clusterName,
+		UpdateID:    aws.StringValue(update.Id),
+		Status:      aws.StringValue(update.Status),
+	}
+	for _, v := range update.Errors {
+		if v == nil {
+			continue
+		}
+		e.Errors = append(e.Errors, UpdateError{
+			Code:        aws.StringValue(v.ErrorCode),
+			Message:     aws.StringValue(v.ErrorMessage),
+			ResourceIDs: aws.StringValueSlice(v.ResourceIds),
+		})
+	}
+	for _, v := range update.Params {
+		if v == nil {
+			continue
+		}
+		if e.Params == nil {
+			e.Params = make(map[string]string)
+		}
+		e.Params[aws.StringValue(v.Type)] = aws.StringValue(v.Value)
+	}
+	return e
+}
+
+// formatUpdateErrors returns the human-readable cluster update errors.
+func formatUpdateErrors(errs []UpdateError) string {
+	ss := make([]string, 0, len(errs))
+	for _, v := range errs {
+		ss = append(ss, "["+v.String()+"]")
+	}
+	return strings.Join(ss, ", ")
+}
@@ -0,0 +1,46 @@
+package wait
+
+import "time"
+
+// PollEventKind is the kind of "PollEvent".
+type PollEventKind string
+
+const (
+	// PollEventPolled is sent on every successful describe call.
+	PollEventPolled PollEventKind = "polled"
+	// PollEventStatusChanged is sent when the observed status changes,
+	// including the very first observed status.
+	PollEventStatusChanged PollEventKind = "status-changed"
+	// PollEventRetrying is sent when the describe call fails and is retried.
+	PollEventRetrying PollEventKind = "retrying"
+	// PollEventAborted is sent when the poller exits with an error.
+	PollEventAborted PollEventKind = "aborted"
+	// PollEventDone is sent when the desired status is reached.
+	PollEventDone PollEventKind = "done"
+)
+
+// PollEvent represents a poller state transition, for progress UIs.
+type PollEvent struct {
+	Kind PollEventKind
+	// Status is the most recently observed status (empty if none yet).
+	Status string
+	// Elapsed is the time since the poller started.
+	Elapsed time.Duration
+	// PollCount is the number of describe calls made so far.
+	PollCount int
+	Error     error
+}
+
+// sendEvent calls the event sink, if any.
+func (op *Op) sendEvent(kind PollEventKind, status string, start time.Time, pollCount int, err error) {
+	if op.eventSink == nil {
+		return
+	}
+	op.eventSink(PollEvent{
+		Kind:      kind,
+		Status:    status,
+		Elapsed:   time.Since(start),
+		PollCount: pollCount,
+		Error:     err,
+	})
+}
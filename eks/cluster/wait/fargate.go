@@ -0,0 +1,159 @@
+package wait
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-k8s-tester/eksconfig"
+	"github.com/aws/aws-k8s-tester/pkg/ctxutil"
+	pkg_wait "github.com/aws/aws-k8s-tester/pkg/wait"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	aws_eks "github.com/aws/aws-sdk-go/service/eks"
+	"github.com/aws/aws-sdk-go/service/eks/eksiface"
+	"github.com/dustin/go-humanize"
+	"go.uber.org/zap"
+)
+
+// IsFargateProfileDeleted returns true if error from EKS API indicates that
+// the EKS Fargate profile has already been deleted.
+func IsFargateProfileDeleted(err error) bool {
+	if err == nil {
+		return false
+	}
+	awsErr, ok := err.(awserr.Error)
+	if ok && awsErr.Code() == "ResourceNotFoundException" {
+		return true
+	}
+	return strings.Contains(err.Error(), " not found ")
+}
+
+// FargateProfileStatus represents the EKS Fargate profile status.
+type FargateProfileStatus struct {
+	FargateProfile *aws_eks.FargateProfile
+	Error          error
+}
+
+// PollFargateProfile periodically fetches the Fargate profile status
+// until the profile becomes the desired state. Use
+// "eksconfig.ClusterStatusDELETEDORNOTEXIST" to wait for deletion,
+// in which case "DELETING" followed by not-found means success.
+// "CREATE_FAILED" and "DELETE_FAILED" are terminal failures.
+// Supported options are "WithFailureStatuses", "WithDescribeTimeout",
+// "WithInitialWaitFunc", and "WithLogFields".
+func PollFargateProfile(
+	ctx context.Context,
+	stopc chan struct{},
+	lg *zap.Logger,
+	logWriter io.Writer,
+	eksAPI eksiface.EKSAPI,
+	clusterName string,
+	profileName string,
+	desiredStatus string,
+	initialWait time.Duration,
+	pollInterval time.Duration,
+	opts ...OpOption) <-chan FargateProfileStatus {
+
+	ret := Op{}
+	ret.applyOpts(opts)
+	if len(ret.logFields) > 0 {
+		lg = lg.With(ret.logFields...)
+	}
+
+	now := time.Now()
+
+	lg.Info("polling fargate profile",
+		zap.String("cluster-name", clusterName),
+		zap.String("profile-name", profileName),
+		zap.String("desired-status", desiredStatus),
+		zap.String("initial-wait", initialWait.String()),
+		zap.String("poll-interval", pollInterval.String()),
+		zap.String("ctx-time-left", ctxutil.TimeLeftTillDeadline(ctx)),
+	)
+
+	var lastProfile *aws_eks.FargateProfile
+
+	return pkg_wait.Poller[FargateProfileStatus]{
+		Describe: func(ctx context.Context) (FargateProfileStatus, error) {
+			attemptCtx, attemptCancel := ret.attemptContext(ctx)
+			callStart := time.Now()
+			output, err := eksAPI.DescribeFargateProfileWithContext(attemptCtx, &aws_eks.DescribeFargateProfileInput{
+				ClusterName:        aws.String(clusterName),
+				FargateProfileName: aws.String(profileName),
+			})
+			ret.observeAPILatency("DescribeFargateProfile", callStart, err)
+			attemptCancel()
+			if err != nil {
+				return FargateProfileStatus{}, err
+			}
+			if output.FargateProfile == nil {
+				return FargateProfileStatus{}, fmt.Errorf("%w %+v", errEmptyResponse, output.GoString())
+			}
+			return FargateProfileStatus{FargateProfile: output.FargateProfile}, nil
+		},
+		Classify: func(sv FargateProfileStatus, err error) (FargateProfileStatus, bool) {
+			if err != nil {
+				if IsFargateProfileDeleted(err) {
+					if desiredStatus == eksconfig.ClusterStatusDELETEDORNOTEXIST {
+						lg.Info("fargate profile is already deleted as desired; exiting", zap.Error(err))
+						return FargateProfileStatus{FargateProfile: nil, Error: nil}, true
+					}
+					lg.Warn("fargate profile does not exist", zap.Error(err))
+					return FargateProfileStatus{FargateProfile: nil, Error: err}, true
+				}
+				lg.Warn("describe fargate profile failed; retrying", zap.Error(err))
+				return FargateProfileStatus{FargateProfile: nil, Error: err}, false
+			}
+
+			profile := sv.FargateProfile
+			lastProfile = profile
+			currentStatus := aws.StringValue(profile.Status)
+			lg.Info("poll",
+				zap.String("cluster-name", clusterName),
+				zap.String("profile-name", profileName),
+				zap.String("status", currentStatus),
+				zap.String("started", humanize.RelTime(now, time.Now(), "ago", "from now")),
+				zap.String("ctx-time-left", ctxutil.TimeLeftTillDeadline(ctx)),
+			)
+			switch {
+			case currentStatus == desiredStatus:
+				lg.Info("desired fargate profile status; done", zap.String("status", currentStatus))
+				return FargateProfileStatus{FargateProfile: profile, Error: nil}, true
+			case ret.isFargateProfileFailureStatus(currentStatus):
+				lg.Warn("unexpected fargate profile status; failed", zap.String("status", currentStatus))
+				return FargateProfileStatus{FargateProfile: profile, Error: fmt.Errorf("unexpected fargate status %q", currentStatus)}, true
+			}
+			return FargateProfileStatus{FargateProfile: profile, Error: nil}, false
+		},
+		Abort: func(err error) FargateProfileStatus {
+			return FargateProfileStatus{FargateProfile: lastProfile, Error: err}
+		},
+		InitialWait: func(sv FargateProfileStatus) time.Duration {
+			if ret.initialWaitFunc != nil {
+				return ret.initialWaitFunc(aws.StringValue(sv.FargateProfile.Status))
+			}
+			return initialWait
+		},
+		PollInterval:   pollInterval,
+		LogWriter:      logWriter,
+		SpinnerMessage: "Waiting for Fargate profile status " + desiredStatus,
+	}.Poll(ctx, stopc, lg)
+}
+
+// isFargateProfileFailureStatus returns true if the Fargate profile status is terminal failure.
+func (op *Op) isFargateProfileFailureStatus(status string) bool {
+	switch status {
+	case aws_eks.FargateProfileStatusCreateFailed,
+		aws_eks.FargateProfileStatusDeleteFailed:
+		return true
+	}
+	for _, v := range op.failureStatuses {
+		if status == v {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,161 @@
+package wait
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-k8s-tester/eksconfig"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	aws_eks "github.com/aws/aws-sdk-go/service/eks"
+	"go.uber.org/zap"
+)
+
+func (f *fakeEKSAPI) DescribeFargateProfileWithContext(ctx aws.Context, input *aws_eks.DescribeFargateProfileInput, opts ...request.Option) (*aws_eks.DescribeFargateProfileOutput, error) {
+	rv := f.next("fargate/"+aws.StringValue(input.FargateProfileName), f.profiles)
+	if rv.err != nil {
+		return nil, rv.err
+	}
+	return &aws_eks.DescribeFargateProfileOutput{FargateProfile: &aws_eks.FargateProfile{
+		ClusterName:        input.ClusterName,
+		FargateProfileName: input.FargateProfileName,
+		Status:             aws.String(rv.status),
+	}}, nil
+}
+
+func fargateProfileNotFoundErr() error {
+	return awserr.New("ResourceNotFoundException", "Fargate Profile my-profile not found for cluster my-cluster", nil)
+}
+
+func TestPollFargateProfile(t *testing.T) {
+	tt := []struct {
+		name         string
+		results      []fakeResult
+		desired      string
+		opts         []OpOption
+		timeout      time.Duration
+		pollInterval time.Duration
+		stopAfter    int
+
+		expCalls  int
+		expStatus string
+		expErr    func(error) bool
+	}{
+		{
+			name:      "creating to active",
+			results:   []fakeResult{{status: aws_eks.FargateProfileStatusCreating}, {err: throttlingErr()}, {status: aws_eks.FargateProfileStatusActive}},
+			desired:   aws_eks.FargateProfileStatusActive,
+			expCalls:  3,
+			expStatus: aws_eks.FargateProfileStatusActive,
+		},
+		{
+			name:      "create failed",
+			results:   []fakeResult{{status: aws_eks.FargateProfileStatusCreating}, {status: aws_eks.FargateProfileStatusCreateFailed}},
+			desired:   aws_eks.FargateProfileStatusActive,
+			expCalls:  2,
+			expStatus: aws_eks.FargateProfileStatusCreateFailed,
+			expErr: func(err error) bool {
+				return err != nil && strings.Contains(err.Error(), aws_eks.FargateProfileStatusCreateFailed)
+			},
+		},
+		{
+			name:      "failure status option",
+			results:   []fakeResult{{status: aws_eks.FargateProfileStatusDeleting}},
+			desired:   aws_eks.FargateProfileStatusActive,
+			opts:      []OpOption{WithFailureStatuses(aws_eks.FargateProfileStatusDeleting)},
+			expCalls:  1,
+			expStatus: aws_eks.FargateProfileStatusDeleting,
+			expErr:    func(err error) bool { return err != nil },
+		},
+		{
+			name:     "deleting to not found as desired",
+			results:  []fakeResult{{status: aws_eks.FargateProfileStatusDeleting}, {status: aws_eks.FargateProfileStatusDeleting}, {err: fargateProfileNotFoundErr()}},
+			desired:  eksconfig.ClusterStatusDELETEDORNOTEXIST,
+			expCalls: 3,
+		},
+		{
+			name:      "delete failed",
+			results:   []fakeResult{{status: aws_eks.FargateProfileStatusDeleting}, {status: aws_eks.FargateProfileStatusDeleteFailed}},
+			desired:   eksconfig.ClusterStatusDELETEDORNOTEXIST,
+			expCalls:  2,
+			expStatus: aws_eks.FargateProfileStatusDeleteFailed,
+			expErr: func(err error) bool {
+				return err != nil && strings.Contains(err.Error(), aws_eks.FargateProfileStatusDeleteFailed)
+			},
+		},
+		{
+			name:     "not found while waiting for active",
+			results:  []fakeResult{{err: fargateProfileNotFoundErr()}},
+			desired:  aws_eks.FargateProfileStatusActive,
+			expCalls: 1,
+			expErr:   IsFargateProfileDeleted,
+		},
+		{
+			name:         "timeout with last observed profile",
+			results:      []fakeResult{{status: aws_eks.FargateProfileStatusCreating}},
+			desired:      aws_eks.FargateProfileStatusActive,
+			timeout:      50 * time.Millisecond,
+			pollInterval: time.Hour,
+			expCalls:     1,
+			expStatus:    aws_eks.FargateProfileStatusCreating,
+			expErr:       func(err error) bool { return errors.Is(err, context.DeadlineExceeded) },
+		},
+		{
+			name:         "stopped with last observed profile",
+			results:      []fakeResult{{status: aws_eks.FargateProfileStatusCreating}},
+			desired:      aws_eks.FargateProfileStatusActive,
+			pollInterval: time.Hour,
+			stopAfter:    1,
+			expCalls:     1,
+			expStatus:    aws_eks.FargateProfileStatusCreating,
+			expErr:       func(err error) bool { return errors.Is(err, ErrWaitStopped) },
+		},
+	}
+	for _, tv := range tt {
+		t.Run(tv.name, func(t *testing.T) {
+			timeout, pollInterval := tv.timeout, tv.pollInterval
+			if timeout == 0 {
+				timeout = 10 * time.Second
+			}
+			if pollInterval == 0 {
+				pollInterval = time.Millisecond
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), timeout)
+			defer cancel()
+
+			api := &fakeEKSAPI{profiles: tv.results}
+			stopc := make(chan struct{})
+			var last FargateProfileStatus
+			received := 0
+			for sv := range PollFargateProfile(ctx, stopc, zap.NewExample(), ioutil.Discard, api, "my-cluster", "my-profile", tv.desired, 0, pollInterval, tv.opts...) {
+				last = sv
+				received++
+				if received == tv.stopAfter {
+					close(stopc)
+				}
+			}
+
+			if tv.expErr == nil && last.Error != nil {
+				t.Fatalf("unexpected error %v", last.Error)
+			}
+			if tv.expErr != nil && !tv.expErr(last.Error) {
+				t.Fatalf("unexpected error %v", last.Error)
+			}
+			status := ""
+			if last.FargateProfile != nil {
+				status = aws.StringValue(last.FargateProfile.Status)
+			}
+			if status != tv.expStatus {
+				t.Fatalf("expected last status %q, got %q", tv.expStatus, status)
+			}
+			if calls := api.callCount("fargate/my-profile"); calls != tv.expCalls {
+				t.Fatalf("expected DescribeFargateProfile calls %d, got %d", tv.expCalls, calls)
+			}
+		})
+	}
+}
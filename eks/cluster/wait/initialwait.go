@@ -0,0 +1,51 @@
+package wait
+
+import (
+	"time"
+
+	aws_eks "github.com/aws/aws-sdk-go/service/eks"
+)
+
+// AutoInitialWait returns the initial wait heuristically computed from
+// the cluster or cluster update status observed on the first describe
+// call, and the Kubernetes version the cluster is being upgraded to
+// (empty if not a version upgrade):
+//
+//	CREATING                         8 minutes
+//	DELETING                         3 minutes
+//	UPDATING, InProgress             2 minutes
+//	UPDATING, InProgress (upgrade)  10 minutes
+//
+// Control plane version upgrades take ~30 minutes, while other updates
+// (e.g. logging, endpoint access) take a few minutes. The worker node
+// count is not an input, since the control plane transitions do not
+// depend on it, and node groups are waited for separately.
+// It returns zero for any other status, so that polling starts right away.
+func AutoInitialWait(firstStatus string, upgradeVersion string) time.Duration {
+	switch firstStatus {
+	case aws_eks.ClusterStatusCreating:
+		return 8 * time.Minute
+	case aws_eks.ClusterStatusDeleting:
+		return 3 * time.Minute
+	case aws_eks.ClusterStatusUpdating, aws_eks.UpdateStatusInProgress:
+		if upgradeVersion != "" {
+			return 10 * time.Minute
+		}
+		return 2 * time.Minute
+	}
+	return 0
+}
+
+// WithAutoInitialWait configures "Poll" and "PollUpdate" to compute
+// the initial wait with "AutoInitialWait", instead of the fixed "initialWait"
+// argument. Set "upgradeVersion" to the target Kubernetes version
+// (e.g. "eksconfig.AddOnClusterVersionUpgrade.Version") when waiting
+// for a version upgrade, or empty otherwise.
+// The last of "WithAutoInitialWait" and "WithInitialWaitFunc" wins.
+func WithAutoInitialWait(upgradeVersion string) OpOption {
+	return func(op *Op) {
+		op.initialWaitFunc = func(firstStatus string) time.Duration {
+			return AutoInitialWait(firstStatus, upgradeVersion)
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package wait
+
+import (
+	"testing"
+	"time"
+
+	aws_eks "github.com/aws/aws-sdk-go/service/eks"
+)
+
+func TestAutoInitialWait(t *testing.T) {
+	tt := []struct {
+		name           string
+		firstStatus    string
+		upgradeVersion string
+
+		expWait time.Duration
+	}{
+		{name: "creating", firstStatus: aws_eks.ClusterStatusCreating, expWait: 8 * time.Minute},
+		{name: "creating ignores version", firstStatus: aws_eks.ClusterStatusCreating, upgradeVersion: "1.30", expWait: 8 * time.Minute},
+		{name: "deleting", firstStatus: aws_eks.ClusterStatusDeleting, expWait: 3 * time.Minute},
+		{name: "updating", firstStatus: aws_eks.ClusterStatusUpdating, expWait: 2 * time.Minute},
+		{name: "updating version", firstStatus: aws_eks.ClusterStatusUpdating, upgradeVersion: "1.30", expWait: 10 * time.Minute},
+		{name: "update in progress", firstStatus: aws_eks.UpdateStatusInProgress, expWait: 2 * time.Minute},
+		{name: "version update in progress", firstStatus: aws_eks.UpdateStatusInProgress, upgradeVersion: "1.30", expWait: 10 * time.Minute},
+		{name: "active", firstStatus: aws_eks.ClusterStatusActive, upgradeVersion: "1.30"},
+		{name: "failed", firstStatus: aws_eks.ClusterStatusFailed},
+		{name: "empty status"},
+	}
+	for _, tv := range tt {
+		t.Run(tv.name, func(t *testing.T) {
+			if d := AutoInitialWait(tv.firstStatus, tv.upgradeVersion); d != tv.expWait {
+				t.Fatalf("expected initial wait %v, got %v", tv.expWait, d)
+			}
+		})
+	}
+}
@@ -0,0 +1,239 @@
+package wait
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-k8s-tester/eksconfig"
+	"github.com/aws/aws-k8s-tester/pkg/ctxutil"
+	pkg_wait "github.com/aws/aws-k8s-tester/pkg/wait"
+	"github.com/aws/aws-sdk-go/aws"
+	aws_eks "github.com/aws/aws-sdk-go/service/eks"
+	"github.com/aws/aws-sdk-go/service/eks/eksiface"
+	"go.uber.org/zap"
+)
+
+// MultiClusterStatus represents the EKS cluster status
+// keyed by the cluster name, for "PollMany".
+type MultiClusterStatus struct {
+	ClusterName string
+	ClusterStatus
+	// Done is true if this is the final status of the cluster.
+	Done bool
+}
+
+// PollMany periodically fetches the status of multiple clusters
+// from a single goroutine until every cluster becomes the desired state
+// or fails, with at most "concurrency" describe calls in flight.
+// Each cluster emits its final status with "Done" set, and the channel
+// is closed once all clusters are done. On timeout or stop, every
+// pending cluster emits the context/stop error. Like "Poll", the poll
+// interval is doubled (up to 16x) while describe calls are throttled.
+// Supported options are "WithFailureStatuses", "WithThrottledFunc",
+// "WithDescribeTimeout", and "WithLogFields".
+func PollMany(
+	ctx context.Context,
+	stopc chan struct{},
+	lg *zap.Logger,
+	eksAPI eksiface.EKSAPI,
+	clusterNames []string,
+	desiredClusterStatus string,
+	initialWait time.Duration,
+	pollInterval time.Duration,
+	concurrency int,
+	opts ...OpOption) <-chan MultiClusterStatus {
+
+	ret := Op{}
+	ret.applyOpts(opts)
+	if len(ret.logFields) > 0 {
+		lg = lg.With(ret.logFields...)
+	}
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	pending := make(map[string]struct{}, len(clusterNames))
+	for _, name := range clusterNames {
+		pending[name] = struct{}{}
+	}
+
+	lg.Info("polling clusters",
+		zap.Int("clusters", len(pending)),
+		zap.String("desired-status", desiredClusterStatus),
+		zap.Int("concurrency", concurrency),
+		zap.String("initial-wait", initialWait.String()),
+		zap.String("poll-interval", pollInterval.String()),
+		zap.String("ctx-time-left", ctxutil.TimeLeftTillDeadline(ctx)),
+	)
+
+	ch := make(chan MultiClusterStatus, len(pending))
+	abort := func(err error) {
+		for _, name := range sortedNames(pending) {
+			ch <- MultiClusterStatus{ClusterName: name, ClusterStatus: ClusterStatus{Error: err}, Done: true}
+		}
+		close(ch)
+	}
+	go func() {
+		if len(pending) == 0 {
+			close(ch)
+			return
+		}
+
+		// very first poll should be no-wait
+		// in case clusters have already reached desired status
+		waitDur := time.Duration(0)
+
+		// poll interval raised by throttling, and
+		// the number of throttled describe calls
+		backoff, throttled := pollInterval, 0
+
+		first := true
+		for ctx.Err() == nil {
+			select {
+			case <-ctx.Done():
+				lg.Warn("wait aborted, ctx done", zap.Error(ctx.Err()))
+				abort(pkg_wait.ContextErr(ctx))
+				return
+			case <-stopc:
+				lg.Warn("wait stopped, stopc closed", zap.Error(ctx.Err()))
+				abort(ErrWaitStopped)
+				return
+			case <-time.After(waitDur):
+			}
+
+			roundThrottled := 0
+			for _, sv := range ret.describeMany(ctx, eksAPI, sortedNames(pending), concurrency) {
+				if isThrottled(sv.Error) {
+					roundThrottled++
+					throttled++
+					if ret.throttledFunc != nil {
+						ret.throttledFunc(throttled)
+					}
+				}
+				sv = ret.classifyMany(desiredClusterStatus, sv)
+				if sv.Done {
+					delete(pending, sv.ClusterName)
+				}
+				ch <- sv
+			}
+			lg.Info("poll",
+				zap.Int("pending", len(pending)),
+				zap.String("ctx-time-left", ctxutil.TimeLeftTillDeadline(ctx)),
+			)
+			if len(pending) == 0 {
+				lg.Info("all clusters done")
+				close(ch)
+				return
+			}
+
+			if roundThrottled > 0 {
+				if backoff < maxThrottleBackoff*pollInterval {
+					backoff *= 2
+				}
+				lg.Warn("describe clusters throttled; backing off",
+					zap.Int("throttled", roundThrottled),
+					zap.Duration("wait", backoff),
+				)
+			} else {
+				backoff = pollInterval
+			}
+			waitDur = backoff
+			if first {
+				waitDur, first = initialWait, false
+			}
+		}
+
+		lg.Warn("wait aborted, ctx done", zap.Error(ctx.Err()))
+		abort(pkg_wait.ContextErr(ctx))
+	}()
+	return ch
+}
+
+// describeMany describes the clusters with at most "concurrency"
+// calls in flight, and returns the results in the same order.
+// The API latencies are reported once all calls are done,
+// so that "WithAPILatency" is never called concurrently.
+func (op *Op) describeMany(ctx context.Context, eksAPI eksiface.EKSAPI, names []string, concurrency int) []MultiClusterStatus {
+	rs := make([]MultiClusterStatus, len(names))
+	took, errs := make([]time.Duration, len(names)), make([]error, len(names))
+	sema := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i, name := range names {
+		wg.Add(1)
+		sema <- struct{}{}
+		go func(i int, name string) {
+			defer func() {
+				<-sema
+				wg.Done()
+			}()
+			rs[i].ClusterName = name
+			attemptCtx, attemptCancel := op.attemptContext(ctx)
+			callStart := time.Now()
+			output, err := eksAPI.DescribeClusterWithContext(attemptCtx, &aws_eks.DescribeClusterInput{
+				Name: aws.String(name),
+			})
+			took[i], errs[i] = time.Since(callStart), err
+			attemptCancel()
+			if err != nil {
+				rs[i].Error = err
+				return
+			}
+			if output.Cluster == nil {
+				rs[i].Error = fmt.Errorf("%w %+v", errEmptyResponse, output.GoString())
+				return
+			}
+			rs[i].Cluster = output.Cluster
+		}(i, name)
+	}
+	wg.Wait()
+	if op.apiLatencyFunc != nil {
+		for i := range names {
+			op.apiLatencyFunc("DescribeCluster", took[i], errs[i])
+		}
+	}
+	return rs
+}
+
+// classifyMany sets "Done" and the terminal error, if any.
+func (op *Op) classifyMany(desiredClusterStatus string, sv MultiClusterStatus) MultiClusterStatus {
+	if sv.Error != nil {
+		if IsDeleted(sv.Error) {
+			sv.Done = true
+			if desiredClusterStatus == eksconfig.ClusterStatusDELETEDORNOTEXIST {
+				sv.Error = nil
+			} else {
+				sv.Error = newClusterNotFoundError(sv.ClusterName, sv.Error)
+			}
+		}
+		return sv
+	}
+	if sv.Cluster == nil {
+		return sv
+	}
+	sv.HealthIssues = clusterHealthIssues(sv.Cluster)
+	status := aws.StringValue(sv.Cluster.Status)
+	switch {
+	case status == desiredClusterStatus:
+		sv.Done = true
+	case op.isFailureStatus(status):
+		sv.Done = true
+		sv.Error = &ClusterFailedError{
+			ClusterName:  sv.ClusterName,
+			Status:       status,
+			HealthIssues: sv.HealthIssues,
+		}
+	}
+	return sv
+}
+
+func sortedNames(m map[string]struct{}) []string {
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
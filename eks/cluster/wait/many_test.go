@@ -0,0 +1,172 @@
+package wait
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-k8s-tester/eksconfig"
+	"github.com/aws/aws-sdk-go/aws"
+	aws_eks "github.com/aws/aws-sdk-go/service/eks"
+	"go.uber.org/zap"
+)
+
+// pollMany polls the clusters until the channel is closed, and returns
+// the final status of each cluster. The stop channel is closed after
+// receiving "stopAfter" statuses, if non-zero.
+func pollMany(t *testing.T, api *fakeEKSAPI, names []string, desired string, timeout time.Duration, pollInterval time.Duration, concurrency int, stopAfter int) map[string]MultiClusterStatus {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	stopc := make(chan struct{})
+	final, received := make(map[string]MultiClusterStatus), 0
+	for sv := range PollMany(ctx, stopc, zap.NewExample(), api, names, desired, pollInterval, pollInterval, concurrency) {
+		received++
+		if received == stopAfter {
+			close(stopc)
+		}
+		if _, ok := final[sv.ClusterName]; ok {
+			t.Fatalf("unexpected status %+v after final status", sv)
+		}
+		if sv.Done {
+			final[sv.ClusterName] = sv
+		}
+	}
+	if len(final) != len(names) {
+		t.Fatalf("expected %d final statuses, got %+v", len(names), final)
+	}
+	return final
+}
+
+func TestPollMany(t *testing.T) {
+	api := &fakeEKSAPI{clusters: map[string][]fakeResult{
+		"active":    {{status: aws_eks.ClusterStatusActive}},
+		"creating":  {{status: aws_eks.ClusterStatusCreating}, {status: aws_eks.ClusterStatusCreating}, {status: aws_eks.ClusterStatusActive}},
+		"throttled": {{err: throttlingErr()}, {err: throttlingErr()}, {status: aws_eks.ClusterStatusActive}},
+		"failed":    {{status: aws_eks.ClusterStatusCreating}, {status: aws_eks.ClusterStatusFailed}},
+		"deleted":   {{err: clusterNotFoundErr("deleted")}},
+	}}
+	names := []string{"active", "creating", "throttled", "failed", "deleted"}
+	final := pollMany(t, api, names, aws_eks.ClusterStatusActive, 10*time.Second, time.Millisecond, 2, 0)
+
+	for _, name := range []string{"active", "creating", "throttled"} {
+		sv := final[name]
+		if sv.Error != nil || aws.StringValue(sv.Cluster.Status) != aws_eks.ClusterStatusActive {
+			t.Fatalf("%q: unexpected final status %+v", name, sv)
+		}
+	}
+	if sv := final["failed"]; !errors.Is(sv.Error, ErrClusterFailed) {
+		t.Fatalf("expected ErrClusterFailed, got %v", sv.Error)
+	}
+	if sv := final["deleted"]; !errors.Is(sv.Error, ErrClusterNotFound) {
+		t.Fatalf("expected ErrClusterNotFound, got %v", sv.Error)
+	}
+
+	for name, exp := range map[string]int{"active": 1, "creating": 3, "throttled": 3, "failed": 2, "deleted": 1} {
+		if calls := api.callCount(name); calls != exp {
+			t.Fatalf("%q: expected DescribeCluster calls %d, got %d", name, exp, calls)
+		}
+	}
+	if api.maxInflight > 2 {
+		t.Fatalf("expected at most 2 describe calls in flight, got %d", api.maxInflight)
+	}
+}
+
+func TestPollManyDeleted(t *testing.T) {
+	api := &fakeEKSAPI{clusters: map[string][]fakeResult{
+		"a": {{status: aws_eks.ClusterStatusDeleting}, {err: clusterNotFoundErr("a")}},
+		"b": {{err: clusterNotFoundErr("b")}},
+	}}
+	final := pollMany(t, api, []string{"a", "b"}, eksconfig.ClusterStatusDELETEDORNOTEXIST, 10*time.Second, time.Millisecond, 1, 0)
+	for name, sv := range final {
+		if sv.Error != nil {
+			t.Fatalf("%q: unexpected error %v", name, sv.Error)
+		}
+	}
+	if api.maxInflight > 1 {
+		t.Fatalf("expected at most 1 describe call in flight, got %d", api.maxInflight)
+	}
+}
+
+func TestPollManyAbort(t *testing.T) {
+	results := map[string][]fakeResult{
+		"active":   {{status: aws_eks.ClusterStatusActive}},
+		"creating": {{status: aws_eks.ClusterStatusCreating}},
+	}
+	names := []string{"active", "creating"}
+
+	final := pollMany(t, &fakeEKSAPI{clusters: results}, names, aws_eks.ClusterStatusActive, 50*time.Millisecond, time.Hour, 2, 0)
+	if sv := final["active"]; sv.Error != nil {
+		t.Fatalf("unexpected error %v", sv.Error)
+	}
+	if sv := final["creating"]; !errors.Is(sv.Error, context.DeadlineExceeded) {
+		t.Fatalf("expected context.DeadlineExceeded, got %v", sv.Error)
+	}
+
+	final = pollMany(t, &fakeEKSAPI{clusters: results}, names, aws_eks.ClusterStatusActive, 10*time.Second, time.Hour, 2, 1)
+	if sv := final["active"]; sv.Error != nil {
+		t.Fatalf("unexpected error %v", sv.Error)
+	}
+	if sv := final["creating"]; !errors.Is(sv.Error, ErrWaitStopped) {
+		t.Fatalf("expected ErrWaitStopped, got %v", sv.Error)
+	}
+}
+
+func TestPollManyEmpty(t *testing.T) {
+	for sv := range PollMany(context.Background(), nil, zap.NewExample(), &fakeEKSAPI{}, nil, aws_eks.ClusterStatusActive, 0, time.Millisecond, 1) {
+		t.Fatalf("unexpected status %+v", sv)
+	}
+}
+
+func TestPollManyThrottled(t *testing.T) {
+	api := &fakeEKSAPI{clusters: map[string][]fakeResult{
+		"a": {{err: throttlingErr()}, {err: throttlingErr()}, {err: throttlingErr()}, {status: aws_eks.ClusterStatusActive}},
+		"b": {{status: aws_eks.ClusterStatusActive}},
+	}}
+	var throttled []int
+	start := time.Now()
+	for sv := range PollMany(context.Background(), nil, zap.NewExample(), api, []string{"a", "b"}, aws_eks.ClusterStatusActive, 0, time.Millisecond, 2,
+		WithThrottledFunc(func(n int) { throttled = append(throttled, n) }),
+	) {
+		if sv.Done && sv.Error != nil {
+			t.Fatalf("%q: unexpected error %v", sv.ClusterName, sv.Error)
+		}
+	}
+	if !reflect.DeepEqual(throttled, []int{1, 2, 3}) {
+		t.Fatalf("expected cumulative throttled counts [1 2 3], got %v", throttled)
+	}
+	// zero initial wait after the first round, then the poll interval
+	// is doubled on every throttled round (2ms, 4ms, 8ms)
+	if took := time.Since(start); took < (4+8)*time.Millisecond {
+		t.Fatalf("expected throttling back-off, took %v", took)
+	}
+}
+
+func TestPollManyAPILatency(t *testing.T) {
+	api := &fakeEKSAPI{clusters: map[string][]fakeResult{
+		"a": {{status: aws_eks.ClusterStatusCreating}, {status: aws_eks.ClusterStatusActive}},
+		"b": {{err: throttlingErr()}, {status: aws_eks.ClusterStatusActive}},
+		"c": {{status: aws_eks.ClusterStatusActive}},
+	}}
+	// not synchronized, for "go test -race" to catch concurrent calls
+	var observed, failed int
+	for range PollMany(context.Background(), nil, zap.NewExample(), api, []string{"a", "b", "c"}, aws_eks.ClusterStatusActive, 0, time.Millisecond, 3,
+		WithAPILatency(func(name string, took time.Duration, err error) {
+			if name != "DescribeCluster" || took <= 0 {
+				t.Errorf("unexpected API latency %q %v", name, took)
+			}
+			observed++
+			if err != nil {
+				failed++
+			}
+		}),
+	) {
+	}
+	if exp := api.callCount("a") + api.callCount("b") + api.callCount("c"); observed != exp {
+		t.Fatalf("expected API latency of %d describe calls, got %d", exp, observed)
+	}
+	if failed != 1 {
+		t.Fatalf("expected 1 failed describe call, got %d", failed)
+	}
+}
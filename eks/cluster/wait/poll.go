@@ -11,7 +11,7 @@ import (
 
 	"github.com/aws/aws-k8s-tester/eksconfig"
 	"github.com/aws/aws-k8s-tester/pkg/ctxutil"
-	"github.com/aws/aws-k8s-tester/pkg/spinner"
+	pkg_wait "github.com/aws/aws-k8s-tester/pkg/wait"
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/service/eks"
@@ -27,8 +27,11 @@ func IsDeleted(err error) bool {
 	if err == nil {
 		return false
 	}
-	awsErr, ok := err.(awserr.Error)
-	if ok && awsErr.Code() == "ResourceNotFoundException" &&
+	if errors.Is(err, ErrClusterNotFound) {
+		return true
+	}
+	var awsErr awserr.Error
+	if errors.As(err, &awsErr) && awsErr.Code() == "ResourceNotFoundException" &&
 		strings.HasPrefix(awsErr.Message(), "No cluster found for") {
 		return true
 	}
@@ -36,14 +39,47 @@ func IsDeleted(err error) bool {
 	return strings.Contains(err.Error(), "No cluster found for name: ")
 }
 
+// isThrottled returns true if error from EKS API indicates that
+// the request was throttled.
+func isThrottled(err error) bool {
+	if err == nil {
+		return false
+	}
+	var awsErr awserr.Error
+	if errors.As(err, &awsErr) {
+		switch awsErr.Code() {
+		case "ThrottlingException", "Throttling", "TooManyRequestsException", "RequestLimitExceeded":
+			return true
+		}
+	}
+	// ThrottlingException: Rate exceeded
+	return strings.Contains(err.Error(), "ThrottlingException") || strings.Contains(err.Error(), "Rate exceeded")
+}
+
+// maxThrottleBackoff caps the poll interval growth on throttling errors,
+// as a multiple of the poll interval.
+const maxThrottleBackoff = 16
+
 // ClusterStatus represents the EKS cluster status.
 type ClusterStatus struct {
 	Cluster *aws_eks.Cluster
 	Error   error
+	// Progress describes the cluster status and health issues
+	// when they change while waiting for deletion.
+	// Only set with "WithDeletionProgress".
+	Progress string
+	// HealthIssues are the cluster health issues reported by EKS
+	// in the most recent describe call, if any.
+	HealthIssues []HealthIssue
+	// Elapsed is the time from the start of the poll to the desired status
+	// (e.g. time-to-ACTIVE). Only set on the final successful status.
+	Elapsed time.Duration
 }
 
 // Poll periodically fetches the cluster status
 // until the cluster becomes the desired state.
+// On timeout or stop, the last status carries the context/stop error
+// with the most recently observed cluster (if any) for diagnostics.
 func Poll(
 	ctx context.Context,
 	stopc chan struct{},
@@ -58,9 +94,11 @@ func Poll(
 
 	ret := Op{}
 	ret.applyOpts(opts)
+	if len(ret.logFields) > 0 {
+		lg = lg.With(ret.logFields...)
+	}
 
 	now := time.Now()
-	sp := spinner.New(logWriter, "Waiting for cluster status "+desiredClusterStatus)
 
 	lg.Info("polling cluster",
 		zap.String("cluster-name", clusterName),
@@ -70,120 +108,241 @@ func Poll(
 		zap.String("ctx-time-left", ctxutil.TimeLeftTillDeadline(ctx)),
 	)
 
-	ch := make(chan ClusterStatus, 10)
-	go func() {
-		// very first poll should be no-wait
-		// in case stack has already reached desired status
-		// wait from second interation
-		waitDur := time.Duration(0)
-
-		first := true
-		for ctx.Err() == nil {
-			select {
-			case <-ctx.Done():
-				lg.Warn("wait aborted, ctx done", zap.Error(ctx.Err()))
-				ch <- ClusterStatus{Cluster: nil, Error: ctx.Err()}
-				close(ch)
-				return
-
-			case <-stopc:
-				lg.Warn("wait stopped, stopc closed", zap.Error(ctx.Err()))
-				ch <- ClusterStatus{Cluster: nil, Error: errors.New("wait stopped")}
-				close(ch)
-				return
-
-			case <-time.After(waitDur):
-				// very first poll should be no-wait
-				// in case stack has already reached desired status
-				// wait from second interation
-				if waitDur == time.Duration(0) {
-					waitDur = pollInterval
+	// wait before the next poll, raised by throttling or "WithBackoff"
+	waitDur := pollInterval
+
+	// start of the consecutive describe errors, zero on success
+	var errStart time.Time
+
+	// retain the last observed cluster so that
+	// timeout or stop still returns diagnostic context
+	var lastCluster *aws_eks.Cluster
+
+	// last reported deletion progress, only with "WithDeletionProgress"
+	lastProgress := ""
+
+	// for "WithEventSink"
+	pollCount, lastStatus := 0, ""
+
+	// poll interval before jitter, only with "WithBackoff"
+	interval := pollInterval
+
+	// number of throttled describe calls, and whether
+	// the poll interval has been raised due to throttling
+	throttled, throttleBackoff := 0, false
+
+	// for "WithDedupe", whether to send the last non-terminal status
+	// and when a status was last sent
+	emit, lastEmit := true, time.Time{}
+
+	describe := func(ctx context.Context) (ClusterStatus, error) {
+		pollCount++
+		attemptCtx, attemptCancel := ret.attemptContext(ctx)
+		callStart := time.Now()
+		output, err := eksAPI.DescribeClusterWithContext(attemptCtx, &aws_eks.DescribeClusterInput{
+			Name: aws.String(clusterName),
+		})
+		ret.observeAPILatency("DescribeCluster", callStart, err)
+		attemptCancel()
+		if err != nil {
+			return ClusterStatus{}, err
+		}
+		if output.Cluster == nil {
+			return ClusterStatus{}, fmt.Errorf("%w %+v", errEmptyResponse, output.GoString())
+		}
+		return ClusterStatus{Cluster: output.Cluster}, nil
+	}
+
+	classify := func(sv ClusterStatus, err error) (ClusterStatus, bool) {
+		if err != nil {
+			if IsDeleted(err) {
+				if desiredClusterStatus == eksconfig.ClusterStatusDELETEDORNOTEXIST {
+					lg.Info("cluster is already deleted as desired; exiting", zap.Error(err))
+					ret.sendEvent(PollEventDone, desiredClusterStatus, now, pollCount, nil)
+					elapsed := time.Since(now)
+					ret.emitMetric(desiredClusterStatus, elapsed)
+					return ClusterStatus{Cluster: nil, Error: nil, Elapsed: elapsed}, true
 				}
+				lg.Warn("cluster does not exist; aborting", zap.Error(err))
+				err = newClusterNotFoundError(clusterName, err)
+				ret.sendEvent(PollEventAborted, lastStatus, now, pollCount, err)
+				return ClusterStatus{Cluster: nil, Error: err}, true
 			}
-
-			output, err := eksAPI.DescribeCluster(&aws_eks.DescribeClusterInput{
-				Name: aws.String(clusterName),
-			})
-			if err != nil {
-				if IsDeleted(err) {
-					if desiredClusterStatus == eksconfig.ClusterStatusDELETEDORNOTEXIST {
-						lg.Info("cluster is already deleted as desired; exiting", zap.Error(err))
-						ch <- ClusterStatus{Cluster: nil, Error: nil}
-						close(ch)
-						return
-					}
-					lg.Warn("cluster does not exist; aborting", zap.Error(err))
-					ch <- ClusterStatus{Cluster: nil, Error: err}
-					close(ch)
-					return
+			if isThrottled(err) {
+				throttled++
+				if ret.throttledFunc != nil {
+					ret.throttledFunc(throttled)
+				}
+				if waitDur < maxThrottleBackoff*pollInterval {
+					waitDur *= 2
 				}
-				lg.Warn("describe cluster failed; retrying", zap.Error(err))
-				ch <- ClusterStatus{Cluster: nil, Error: err}
-				continue
+				throttleBackoff = true
+				lg.Warn("describe cluster throttled; backing off",
+					zap.Int("throttled", throttled),
+					zap.Duration("wait", waitDur),
+					zap.Error(err),
+				)
 			}
-
-			if output.Cluster == nil {
-				lg.Warn("expected non-nil cluster; retrying")
-				ch <- ClusterStatus{Cluster: nil, Error: fmt.Errorf("unexpected empty response %+v", output.GoString())}
-				continue
+			if ret.retryBudgetExhausted(&errStart) {
+				lg.Warn("describe cluster failed; retry budget exhausted", zap.Duration("retry-budget", ret.retryBudget), zap.Error(err))
+				ret.sendEvent(PollEventAborted, lastStatus, now, pollCount, err)
+				return ClusterStatus{Cluster: lastCluster, Error: err}, true
 			}
-
-			cluster := output.Cluster
-			currentStatus := aws.StringValue(cluster.Status)
-			lg.Info("poll",
+			lg.Warn("describe cluster failed; retrying", zap.Error(err))
+			ret.sendEvent(PollEventRetrying, lastStatus, now, pollCount, err)
+			return ClusterStatus{Cluster: nil, Error: err}, false
+		}
+		errStart = time.Time{}
+
+		cluster := sv.Cluster
+		lastCluster = cluster
+		currentStatus := aws.StringValue(cluster.Status)
+		currentVersion := aws.StringValue(cluster.Version)
+		healthIssues := clusterHealthIssues(cluster)
+		statusChanged := currentStatus != lastStatus
+		emit = !ret.dedupe || statusChanged || (ret.keepalive > 0 && time.Since(lastEmit) >= ret.keepalive)
+		pollLog := lg.Info
+		if !emit {
+			pollLog = lg.Debug
+		}
+		pollLog("poll",
+			zap.String("cluster-name", clusterName),
+			zap.String("status", currentStatus),
+			zap.String("version", currentVersion),
+			zap.String("started", humanize.RelTime(now, time.Now(), "ago", "from now")),
+			zap.String("ctx-time-left", ctxutil.TimeLeftTillDeadline(ctx)),
+		)
+		if len(healthIssues) > 0 {
+			lg.Warn("cluster has health issues",
 				zap.String("cluster-name", clusterName),
 				zap.String("status", currentStatus),
-				zap.String("started", humanize.RelTime(now, time.Now(), "ago", "from now")),
-				zap.String("ctx-time-left", ctxutil.TimeLeftTillDeadline(ctx)),
+				zap.String("health-issues", formatHealthIssues(healthIssues)),
 			)
-			switch currentStatus {
-			case desiredClusterStatus:
-				ch <- ClusterStatus{Cluster: cluster, Error: nil}
-				lg.Info("desired cluster status; done", zap.String("status", currentStatus))
-				close(ch)
-				return
-			case aws_eks.ClusterStatusFailed:
-				ch <- ClusterStatus{Cluster: cluster, Error: fmt.Errorf("unexpected cluster status %q", aws_eks.ClusterStatusFailed)}
-				lg.Warn("cluster status failed", zap.String("status", currentStatus), zap.String("desired-status", desiredClusterStatus))
-				close(ch)
-				return
-			default:
-				ch <- ClusterStatus{Cluster: cluster, Error: nil}
-			}
+		}
+		if ret.onPoll != nil {
+			ret.onPoll(cluster)
+		}
+		ret.sendEvent(PollEventPolled, currentStatus, now, pollCount, nil)
+		if statusChanged {
+			ret.sendEvent(PollEventStatusChanged, currentStatus, now, pollCount, nil)
+			lastStatus = currentStatus
+		}
+		if throttleBackoff {
+			waitDur, throttleBackoff = pollInterval, false
+		}
+		if ret.backoff != nil {
+			interval = ret.backoff.next(interval, pollInterval, statusChanged)
+			waitDur = ret.backoff.jitter(interval)
+		}
 
-			if ret.queryFunc != nil {
-				ret.queryFunc()
+		switch {
+		case currentStatus == desiredClusterStatus:
+			if ret.desiredVersion != "" && currentVersion != ret.desiredVersion {
+				lg.Info("desired cluster status but not desired version; retrying",
+					zap.String("status", currentStatus),
+					zap.String("version", currentVersion),
+					zap.String("desired-version", ret.desiredVersion),
+				)
+				break
 			}
-
-			if first {
-				lg.Info("sleeping", zap.Duration("initial-wait", initialWait))
-				sp.Restart()
-				select {
-				case <-ctx.Done():
-					sp.Stop()
-					lg.Warn("wait aborted, ctx done", zap.Error(ctx.Err()))
-					ch <- ClusterStatus{Cluster: nil, Error: ctx.Err()}
-					close(ch)
-					return
-				case <-stopc:
-					sp.Stop()
-					lg.Warn("wait stopped, stopc closed", zap.Error(ctx.Err()))
-					ch <- ClusterStatus{Cluster: nil, Error: errors.New("wait stopped")}
-					close(ch)
-					return
-				case <-time.After(initialWait):
-					sp.Stop()
+			if ret.readyFunc != nil {
+				if err := ret.readyFunc(cluster); err != nil {
+					lg.Info("desired cluster status but not ready; retrying",
+						zap.String("status", currentStatus),
+						zap.Error(err),
+					)
+					break
+				}
+			}
+			if ret.probeAPIServer && desiredClusterStatus == aws_eks.ClusterStatusActive {
+				if err := probeAPIServer(cluster, ret.probeTimeout); err != nil {
+					lg.Info("desired cluster status but API server not reachable; retrying",
+						zap.String("status", currentStatus),
+						zap.Error(err),
+					)
+					break
 				}
-				first = false
 			}
+			elapsed := time.Since(now)
+			ret.emitMetric(desiredClusterStatus, elapsed)
+			lg.Info("desired cluster status; done",
+				zap.String("status", currentStatus),
+				zap.String("version", currentVersion),
+				zap.Duration("elapsed", elapsed),
+			)
+			ret.sendEvent(PollEventDone, currentStatus, now, pollCount, nil)
+			return ClusterStatus{Cluster: cluster, Error: nil, HealthIssues: healthIssues, Elapsed: elapsed}, true
+
+		case ret.isFailureStatus(currentStatus):
+			failErr := &ClusterFailedError{
+				ClusterName:  clusterName,
+				Status:       currentStatus,
+				HealthIssues: healthIssues,
+			}
+			lg.Warn("cluster status failed",
+				zap.String("status", currentStatus),
+				zap.String("desired-status", desiredClusterStatus),
+				zap.String("health-issues", formatHealthIssues(failErr.HealthIssues)),
+			)
+			ret.sendEvent(PollEventAborted, currentStatus, now, pollCount, failErr)
+			return ClusterStatus{Cluster: cluster, Error: failErr, HealthIssues: healthIssues}, true
 		}
 
-		lg.Warn("wait aborted, ctx done", zap.Error(ctx.Err()))
-		ch <- ClusterStatus{Cluster: nil, Error: ctx.Err()}
-		close(ch)
-		return
-	}()
-	return ch
+		progress := ""
+		if ret.deletionProgress && desiredClusterStatus == eksconfig.ClusterStatusDELETEDORNOTEXIST {
+			if p := deletionProgress(cluster); p != lastProgress {
+				lg.Info("cluster deletion progress",
+					zap.String("cluster-name", clusterName),
+					zap.String("progress", p),
+					zap.String("previous-progress", lastProgress),
+				)
+				progress, lastProgress = p, p
+				emit = true
+			}
+		}
+		if emit {
+			lastEmit = time.Now()
+		}
+		if ret.queryFunc != nil {
+			ret.queryFunc()
+		}
+		return ClusterStatus{Cluster: cluster, Error: nil, HealthIssues: healthIssues, Progress: progress}, false
+	}
+
+	return pkg_wait.Poller[ClusterStatus]{
+		Describe: describe,
+		Classify: classify,
+		Abort: func(err error) ClusterStatus {
+			ret.sendEvent(PollEventAborted, lastStatus, now, pollCount, err)
+			return ClusterStatus{Cluster: lastCluster, Error: err}
+		},
+		InitialWait: func(sv ClusterStatus) time.Duration {
+			if ret.initialWaitFunc != nil {
+				return ret.initialWaitFunc(aws.StringValue(sv.Cluster.Status))
+			}
+			return initialWait
+		},
+		PollInterval: pollInterval,
+		NextWait:     func(ClusterStatus) time.Duration { return waitDur },
+		Emit: func(sv ClusterStatus) bool {
+			// describe errors are always sent
+			return emit || sv.Error != nil
+		},
+		LogWriter:      logWriter,
+		SpinnerMessage: "Waiting for cluster status " + desiredClusterStatus,
+	}.Poll(ctx, stopc, lg)
+}
+
+// deletionProgress returns the cluster status with its health issues,
+// which often point to the dependency blocking the deletion
+// (e.g. ENI or security group).
+func deletionProgress(cluster *aws_eks.Cluster) string {
+	status := aws.StringValue(cluster.Status)
+	issues := clusterHealthIssues(cluster)
+	if len(issues) == 0 {
+		return status
+	}
+	return fmt.Sprintf("%s (health issues %s)", status, formatHealthIssues(issues))
 }
 
 // updateNotExists returns true if error from EKS API indicates that
@@ -225,6 +384,9 @@ func PollUpdate(
 
 	ret := Op{}
 	ret.applyOpts(opts)
+	if len(ret.logFields) > 0 {
+		lg = lg.With(ret.logFields...)
+	}
 
 	lg.Info("polling cluster update",
 		zap.String("cluster-name", clusterName),
@@ -237,126 +399,141 @@ func PollUpdate(
 
 	now := time.Now()
 
-	ch := make(chan UpdateStatus, 10)
-	go func() {
-		// very first poll should be no-wait
-		// in case stack has already reached desired status
-		// wait from second interation
-		waitDur := time.Duration(0)
-
-		first := true
-		for ctx.Err() == nil {
-			select {
-			case <-ctx.Done():
-				lg.Warn("wait aborted, ctx done", zap.Error(ctx.Err()))
-				ch <- UpdateStatus{Update: nil, Error: ctx.Err()}
-				close(ch)
-				return
-
-			case <-stopc:
-				lg.Warn("wait stopped, stopc closed", zap.Error(ctx.Err()))
-				ch <- UpdateStatus{Update: nil, Error: errors.New("wait stopped")}
-				close(ch)
-				return
-
-			case <-time.After(waitDur):
-				// very first poll should be no-wait
-				// in case stack has already reached desired status
-				// wait from second interation
-				if waitDur == time.Duration(0) {
-					waitDur = pollInterval
-				}
-			}
+	// start of the consecutive describe errors, zero on success
+	var errStart time.Time
 
-			output, err := eksAPI.DescribeUpdate(&eks.DescribeUpdateInput{
+	return pkg_wait.Poller[UpdateStatus]{
+		Describe: func(ctx context.Context) (UpdateStatus, error) {
+			attemptCtx, attemptCancel := ret.attemptContext(ctx)
+			callStart := time.Now()
+			output, err := eksAPI.DescribeUpdateWithContext(attemptCtx, &eks.DescribeUpdateInput{
 				Name:     aws.String(clusterName),
 				UpdateId: aws.String(requestID),
 			})
+			ret.observeAPILatency("DescribeUpdate", callStart, err)
+			attemptCancel()
+			if err != nil {
+				return UpdateStatus{}, err
+			}
+			if output.Update == nil {
+				return UpdateStatus{}, fmt.Errorf("%w %+v", errEmptyResponse, output.GoString())
+			}
+			return UpdateStatus{Update: output.Update}, nil
+		},
+		Classify: func(sv UpdateStatus, err error) (UpdateStatus, bool) {
 			if err != nil {
 				if updateNotExists(err) {
-					lg.Warn("cluster update does not exist; aborting", zap.Error(ctx.Err()))
-					ch <- UpdateStatus{Update: nil, Error: err}
-					close(ch)
-					return
+					lg.Warn("cluster update does not exist; aborting", zap.Error(err))
+					return UpdateStatus{Update: nil, Error: err}, true
+				}
+				if ret.retryBudgetExhausted(&errStart) {
+					lg.Warn("describe cluster update failed; retry budget exhausted", zap.Duration("retry-budget", ret.retryBudget), zap.Error(err))
+					return UpdateStatus{Update: nil, Error: err}, true
 				}
-
 				lg.Warn("describe cluster update failed; retrying", zap.Error(err))
-				ch <- UpdateStatus{Update: nil, Error: err}
-				continue
-			}
-
-			if output.Update == nil {
-				lg.Warn("expected non-nil cluster update; retrying")
-				ch <- UpdateStatus{Update: nil, Error: fmt.Errorf("unexpected empty response %+v", output.GoString())}
-				continue
+				return UpdateStatus{Update: nil, Error: err}, false
 			}
+			errStart = time.Time{}
 
-			update := output.Update
+			update := sv.Update
 			currentStatus := aws.StringValue(update.Status)
-			updateType := aws.StringValue(update.Type)
 			lg.Info("poll",
 				zap.String("cluster-name", clusterName),
 				zap.String("status", currentStatus),
-				zap.String("update-type", updateType),
+				zap.String("update-type", aws.StringValue(update.Type)),
 				zap.String("started", humanize.RelTime(now, time.Now(), "ago", "from now")),
 				zap.String("ctx-time-left", ctxutil.TimeLeftTillDeadline(ctx)),
 			)
+			if ret.onPollUpdate != nil {
+				ret.onPollUpdate(update)
+			}
 			switch currentStatus {
 			case desiredUpdateStatus:
-				ch <- UpdateStatus{Update: update, Error: nil}
 				lg.Info("desired cluster update status; done", zap.String("status", currentStatus))
-				close(ch)
-				return
-			case eks.UpdateStatusCancelled:
-				ch <- UpdateStatus{Update: update, Error: fmt.Errorf("unexpected cluster update status %q", eks.UpdateStatusCancelled)}
-				lg.Warn("cluster update status cancelled", zap.String("status", currentStatus), zap.String("desired-status", desiredUpdateStatus))
-				close(ch)
-				return
-			case eks.UpdateStatusFailed:
-				ch <- UpdateStatus{Update: update, Error: fmt.Errorf("unexpected cluster update status %q", eks.UpdateStatusFailed)}
-				lg.Warn("cluster update status failed", zap.String("status", currentStatus), zap.String("desired-status", desiredUpdateStatus))
-				close(ch)
-				return
-			default:
-				ch <- UpdateStatus{Update: update, Error: nil}
+				return UpdateStatus{Update: update, Error: nil}, true
+			case eks.UpdateStatusCancelled, eks.UpdateStatusFailed:
+				if currentStatus == eks.UpdateStatusCancelled && ret.supersedingUpdateFunc != nil {
+					newID, err := ret.supersedingUpdateFunc()
+					if err == nil && newID != "" && newID != requestID {
+						lg.Info("cluster update cancelled; following superseding update",
+							zap.String("request-id", requestID),
+							zap.String("superseding-request-id", newID),
+						)
+						requestID = newID
+						break
+					}
+					lg.Warn("cluster update cancelled; no superseding update",
+						zap.String("request-id", requestID),
+						zap.String("superseding-request-id", newID),
+						zap.Error(err),
+					)
+				}
+				updateErr := newUpdateFailedError(clusterName, update)
+				lg.Warn("cluster update status failed",
+					zap.String("status", currentStatus),
+					zap.String("desired-status", desiredUpdateStatus),
+					zap.String("update-errors", formatUpdateErrors(updateErr.Errors)),
+					zap.Any("update-params", updateErr.Params),
+				)
+				return UpdateStatus{Update: update, Error: updateErr}, true
 			}
-
 			if ret.queryFunc != nil {
 				ret.queryFunc()
 			}
-
-			if first {
-				lg.Info("sleeping", zap.Duration("initial-wait", initialWait))
-				select {
-				case <-ctx.Done():
-					lg.Warn("wait aborted, ctx done", zap.Error(ctx.Err()))
-					ch <- UpdateStatus{Update: nil, Error: ctx.Err()}
-					close(ch)
-					return
-
-				case <-stopc:
-					lg.Warn("wait stopped, stopc closed", zap.Error(ctx.Err()))
-					ch <- UpdateStatus{Update: nil, Error: errors.New("wait stopped")}
-					close(ch)
-					return
-
-				case <-time.After(initialWait):
-				}
-				first = false
+			return UpdateStatus{Update: update, Error: nil}, false
+		},
+		Abort: func(err error) UpdateStatus {
+			return UpdateStatus{Update: nil, Error: err}
+		},
+		InitialWait: func(sv UpdateStatus) time.Duration {
+			if ret.initialWaitFunc != nil {
+				return ret.initialWaitFunc(aws.StringValue(sv.Update.Status))
 			}
-		}
-
-		lg.Warn("wait aborted, ctx done", zap.Error(ctx.Err()))
-		ch <- UpdateStatus{Update: nil, Error: ctx.Err()}
-		close(ch)
-		return
-	}()
-	return ch
+			return initialWait
+		},
+		PollInterval: pollInterval,
+		Emit: func(sv UpdateStatus) bool {
+			// the cancelled update is only sent as non-terminal status
+			// when following the superseding update, which is not
+			// an update status the caller waits for
+			return sv.Update == nil || aws.StringValue(sv.Update.Status) != eks.UpdateStatusCancelled
+		},
+	}.Poll(ctx, stopc, lg)
 }
 
 // Op represents a MNG operation.
 type Op struct {
-	queryFunc func()
+	queryFunc      func()
+	onPoll         func(*aws_eks.Cluster)
+	onPollUpdate   func(*aws_eks.Update)
+	desiredVersion string
+
+	initialWaitFunc  func(firstStatus string) time.Duration
+	backoff          *Backoff
+	failureStatuses  []string
+	throttledFunc    func(throttled int)
+	describeTimeout  time.Duration
+	retryBudget      time.Duration
+	deletionProgress bool
+
+	logFields []zap.Field
+	eventSink func(PollEvent)
+
+	metricEmitter func(name string, value float64, unit string)
+
+	supersedingUpdateFunc func() (string, error)
+
+	readyFunc    func(*aws_eks.Cluster) error
+	dialEndpoint bool
+	dialTimeout  time.Duration
+
+	probeAPIServer bool
+	probeTimeout   time.Duration
+
+	apiLatencyFunc func(api string, took time.Duration, err error)
+
+	dedupe    bool
+	keepalive time.Duration
 }
 
 // OpOption configures archiver operations.
@@ -367,6 +544,203 @@ func WithQueryFunc(f func()) OpOption {
 	return func(op *Op) { op.queryFunc = f }
 }
 
+// WithOnPoll configures the function to be called with the cluster
+// on every successful describe call, including the final one.
+// Only used for "Poll".
+func WithOnPoll(f func(*aws_eks.Cluster)) OpOption {
+	return func(op *Op) { op.onPoll = f }
+}
+
+// WithOnPollUpdate configures the function to be called with the cluster
+// update on every successful describe call, including the final one.
+// Only used for "PollUpdate".
+func WithOnPollUpdate(f func(*aws_eks.Update)) OpOption {
+	return func(op *Op) { op.onPollUpdate = f }
+}
+
+// WithLogFields configures the fields (e.g. test ID) to be added
+// to every log line from the poller, to correlate concurrent runs.
+func WithLogFields(fields ...zap.Field) OpOption {
+	return func(op *Op) { op.logFields = append(op.logFields, fields...) }
+}
+
+// WithEventSink configures the function to be called on every poller
+// state transition (see "PollEvent"), in addition to the status channel.
+// The sink is called synchronously within the poll goroutine, so it
+// must not block. Only used for "Poll".
+func WithEventSink(f func(PollEvent)) OpOption {
+	return func(op *Op) { op.eventSink = f }
+}
+
+// WithMetricEmitter configures the function to be called once on success
+// with the time to the desired status, in seconds, suitable for CloudWatch
+// (e.g. name "ClusterTimeToACTIVE", unit "Seconds"). Only used for "Poll".
+func WithMetricEmitter(f func(name string, value float64, unit string)) OpOption {
+	return func(op *Op) { op.metricEmitter = f }
+}
+
+// WithFollowSupersedingUpdate configures "PollUpdate" to ask for the
+// replacement update ID when the watched update is cancelled, and to
+// continue polling that update instead of failing. The cancelled
+// update is not sent. If the function
+// returns an error or no new ID, the cancelled update is returned as error.
+func WithFollowSupersedingUpdate(f func() (string, error)) OpOption {
+	return func(op *Op) { op.supersedingUpdateFunc = f }
+}
+
+// WithDesiredVersion configures the cluster poller to wait until
+// the cluster reports the desired Kubernetes version (e.g. "1.29")
+// in addition to the desired status. Only used for "Poll".
+func WithDesiredVersion(ver string) OpOption {
+	return func(op *Op) { op.desiredVersion = ver }
+}
+
+// WithInitialWaitFunc configures the function to compute the initial wait
+// from the status observed on the first successful describe call.
+// If not set, the fixed "initialWait" argument is used.
+func WithInitialWaitFunc(f func(firstStatus string) time.Duration) OpOption {
+	return func(op *Op) { op.initialWaitFunc = f }
+}
+
+// WithBackoff configures "Poll" to grow the poll interval exponentially
+// with jitter after every successful describe call, starting from the
+// "pollInterval" argument, so that long-running operations make fewer API calls.
+func WithBackoff(b Backoff) OpOption {
+	return func(op *Op) { op.backoff = &b }
+}
+
+// WithFailureStatuses configures additional cluster statuses
+// (e.g. "DELETING" when waiting for "ACTIVE") to be treated as
+// immediate failures, in addition to "FAILED". Only used for "Poll".
+func WithFailureStatuses(statuses ...string) OpOption {
+	return func(op *Op) { op.failureStatuses = append(op.failureStatuses, statuses...) }
+}
+
+// WithThrottledFunc configures the function to be called with
+// the cumulative number of throttled describe calls, on every
+// throttling error. "Poll" and "PollMany" double the poll interval
+// on throttling (up to 16x), and reset it once describe calls
+// are no longer throttled.
+func WithThrottledFunc(f func(throttled int)) OpOption {
+	return func(op *Op) { op.throttledFunc = f }
+}
+
+// WithDescribeTimeout configures the timeout for each describe call,
+// so that a hung API call is retried rather than blocking the poller.
+// Zero means no per-call timeout (default).
+func WithDescribeTimeout(d time.Duration) OpOption {
+	return func(op *Op) { op.describeTimeout = d }
+}
+
+// WithRetryBudget configures the maximum cumulative duration of
+// consecutive describe errors before the poller gives up with
+// the last error, regardless of the context deadline.
+// The budget is reset on every successful describe call.
+// Zero means unlimited (default).
+func WithRetryBudget(d time.Duration) OpOption {
+	return func(op *Op) { op.retryBudget = d }
+}
+
+// WithDeletionProgress configures "Poll" to log and emit the cluster status
+// and health issues whenever they change while waiting for
+// "ClusterStatusDELETEDORNOTEXIST" (see "ClusterStatus.Progress").
+// Cluster not found still means the deletion is complete.
+func WithDeletionProgress() OpOption {
+	return func(op *Op) { op.deletionProgress = true }
+}
+
+// WithEndpointDial configures "PollEndpointReady" to confirm
+// the cluster endpoint is reachable with a TLS dial using the cluster CA.
+func WithEndpointDial(timeout time.Duration) OpOption {
+	return func(op *Op) {
+		op.dialEndpoint = true
+		op.dialTimeout = timeout
+	}
+}
+
+// WithAPIServerProbe configures "Poll" to report done for "ACTIVE"
+// only after the API server responds to "/healthz" or "/version"
+// over HTTPS with the cluster CA, since "ACTIVE" does not guarantee
+// the endpoint is resolvable or serving. The timeout applies per request.
+func WithAPIServerProbe(timeout time.Duration) OpOption {
+	return func(op *Op) {
+		op.probeAPIServer = true
+		op.probeTimeout = timeout
+	}
+}
+
+// WithAPILatency configures the function to be called with the latency
+// of every EKS describe call made by the poller (e.g. "DescribeCluster",
+// "DescribeUpdate"), including failed calls, to track control-plane
+// API latency across runs (see "metrics.LatencyRecorder").
+// The function is called within the poll goroutine, and never concurrently
+// ("PollMany" reports the latencies once the concurrent calls are done),
+// so it must not block.
+func WithAPILatency(f func(api string, took time.Duration, err error)) OpOption {
+	return func(op *Op) { op.apiLatencyFunc = f }
+}
+
+// WithDedupe configures "Poll" to send the cluster status only on
+// status transitions (and deletion progress changes), plus a keepalive
+// of the unchanged status every "keepalive", to reduce channel traffic
+// and log noise during long operations. Describe errors and the final
+// status are always sent. Zero keepalive sends transitions only.
+func WithDedupe(keepalive time.Duration) OpOption {
+	return func(op *Op) {
+		op.dedupe = true
+		op.keepalive = keepalive
+	}
+}
+
+// isFailureStatus returns true if the cluster status is terminal failure.
+func (op *Op) isFailureStatus(status string) bool {
+	if status == aws_eks.ClusterStatusFailed {
+		return true
+	}
+	for _, v := range op.failureStatuses {
+		if status == v {
+			return true
+		}
+	}
+	return false
+}
+
+// attemptContext returns the context for a single describe call.
+func (op *Op) attemptContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if op.describeTimeout > 0 {
+		return context.WithTimeout(ctx, op.describeTimeout)
+	}
+	return context.WithCancel(ctx)
+}
+
+// emitMetric emits the time to the desired status, if configured.
+func (op *Op) emitMetric(desiredStatus string, elapsed time.Duration) {
+	if op.metricEmitter == nil {
+		return
+	}
+	if desiredStatus == eksconfig.ClusterStatusDELETEDORNOTEXIST {
+		desiredStatus = "DELETED"
+	}
+	op.metricEmitter("ClusterTimeTo"+desiredStatus, elapsed.Seconds(), "Seconds")
+}
+
+// observeAPILatency reports the latency of the API call since "start",
+// only with "WithAPILatency".
+func (op *Op) observeAPILatency(api string, start time.Time, err error) {
+	if op.apiLatencyFunc != nil {
+		op.apiLatencyFunc(api, time.Since(start), err)
+	}
+}
+
+// retryBudgetExhausted records the start of consecutive errors,
+// and returns true if the retry budget has been exhausted.
+func (op *Op) retryBudgetExhausted(errStart *time.Time) bool {
+	if errStart.IsZero() {
+		*errStart = time.Now()
+	}
+	return op.retryBudget > 0 && time.Since(*errStart) >= op.retryBudget
+}
+
 func (op *Op) applyOpts(opts []OpOption) {
 	for _, opt := range opts {
 		opt(op)
@@ -0,0 +1,967 @@
+package wait
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-k8s-tester/eksconfig"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	aws_eks "github.com/aws/aws-sdk-go/service/eks"
+	"github.com/aws/aws-sdk-go/service/eks/eksiface"
+	"go.uber.org/zap"
+)
+
+// fakeResult is the status or the error returned by a fake describe call.
+type fakeResult struct {
+	status string
+	// version is the cluster version, "1.29" if empty.
+	version string
+	err     error
+	// block blocks the describe call until its context is done.
+	block bool
+}
+
+// fakeEKSAPI returns the results in order for each resource,
+// and repeats the last result once exhausted.
+type fakeEKSAPI struct {
+	eksiface.EKSAPI
+
+	// clusters are the describe results keyed by the cluster name.
+	clusters map[string][]fakeResult
+	// cluster is called to fill the described cluster (e.g. endpoint).
+	cluster func(*aws_eks.Cluster)
+	// updates are the describe update results keyed by the update ID.
+	updates map[string][]fakeResult
+	// update is called to fill the described update (e.g. errors).
+	update func(*aws_eks.Update)
+	// addons are the describe add-on results.
+	addons []fakeResult
+	// addon is called to fill the described add-on (e.g. health).
+	addon func(*aws_eks.Addon)
+	// profiles are the describe Fargate profile results.
+	profiles []fakeResult
+
+	mu          sync.Mutex
+	calls       map[string]int
+	inflight    int
+	maxInflight int
+}
+
+func (f *fakeEKSAPI) next(key string, results []fakeResult) fakeResult {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.calls == nil {
+		f.calls = make(map[string]int)
+	}
+	f.calls[key]++
+	idx := f.calls[key] - 1
+	if idx >= len(results) {
+		idx = len(results) - 1
+	}
+	return results[idx]
+}
+
+func (f *fakeEKSAPI) callCount(key string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.calls[key]
+}
+
+func (f *fakeEKSAPI) DescribeClusterWithContext(ctx aws.Context, input *aws_eks.DescribeClusterInput, opts ...request.Option) (*aws_eks.DescribeClusterOutput, error) {
+	f.mu.Lock()
+	f.inflight++
+	if f.inflight > f.maxInflight {
+		f.maxInflight = f.inflight
+	}
+	f.mu.Unlock()
+	defer func() {
+		f.mu.Lock()
+		f.inflight--
+		f.mu.Unlock()
+	}()
+	// for concurrent describe calls to overlap
+	time.Sleep(time.Millisecond)
+
+	name := aws.StringValue(input.Name)
+	rv := f.next(name, f.clusters[name])
+	if rv.block {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	if rv.err != nil {
+		return nil, rv.err
+	}
+	version := rv.version
+	if version == "" {
+		version = "1.29"
+	}
+	cluster := &aws_eks.Cluster{
+		Name:    input.Name,
+		Status:  aws.String(rv.status),
+		Version: aws.String(version),
+	}
+	if f.cluster != nil {
+		f.cluster(cluster)
+	}
+	return &aws_eks.DescribeClusterOutput{Cluster: cluster}, nil
+}
+
+func (f *fakeEKSAPI) DescribeUpdateWithContext(ctx aws.Context, input *aws_eks.DescribeUpdateInput, opts ...request.Option) (*aws_eks.DescribeUpdateOutput, error) {
+	id := aws.StringValue(input.UpdateId)
+	rv := f.next("update/"+id, f.updates[id])
+	if rv.block {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	if rv.err != nil {
+		return nil, rv.err
+	}
+	update := &aws_eks.Update{
+		Id:     input.UpdateId,
+		Status: aws.String(rv.status),
+	}
+	if f.update != nil {
+		f.update(update)
+	}
+	return &aws_eks.DescribeUpdateOutput{Update: update}, nil
+}
+
+func clusterNotFoundErr(name string) error {
+	return awserr.New("ResourceNotFoundException", "No cluster found for name: "+name+".", nil)
+}
+
+func throttlingErr() error {
+	return awserr.New("ThrottlingException", "Rate exceeded", nil)
+}
+
+// pollCluster polls "my-cluster" until the channel is closed,
+// and returns every received status. The stop channel is closed
+// after receiving "stopAfter" statuses, if non-zero.
+func pollCluster(t *testing.T, api eksiface.EKSAPI, desired string, timeout time.Duration, pollInterval time.Duration, stopAfter int, opts ...OpOption) (statuses []ClusterStatus) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	stopc := make(chan struct{})
+	for sv := range Poll(ctx, stopc, zap.NewExample(), ioutil.Discard, api, "my-cluster", desired, 0, pollInterval, opts...) {
+		statuses = append(statuses, sv)
+		if len(statuses) == stopAfter {
+			close(stopc)
+		}
+	}
+	if len(statuses) == 0 {
+		t.Fatal("no status received")
+	}
+	return statuses
+}
+
+func TestPoll(t *testing.T) {
+	tt := []struct {
+		name         string
+		results      []fakeResult
+		desired      string
+		opts         []OpOption
+		timeout      time.Duration
+		pollInterval time.Duration
+		stopAfter    int
+
+		expCalls   int
+		expStatus  string
+		expVersion string
+		expErr     func(error) bool
+	}{
+		{
+			name:      "already active",
+			results:   []fakeResult{{status: aws_eks.ClusterStatusActive}},
+			desired:   aws_eks.ClusterStatusActive,
+			expCalls:  1,
+			expStatus: aws_eks.ClusterStatusActive,
+		},
+		{
+			name:      "creating to active",
+			results:   []fakeResult{{status: aws_eks.ClusterStatusCreating}, {status: aws_eks.ClusterStatusCreating}, {status: aws_eks.ClusterStatusActive}},
+			desired:   aws_eks.ClusterStatusActive,
+			expCalls:  3,
+			expStatus: aws_eks.ClusterStatusActive,
+		},
+		{
+			name:      "failed",
+			results:   []fakeResult{{status: aws_eks.ClusterStatusCreating}, {status: aws_eks.ClusterStatusFailed}},
+			desired:   aws_eks.ClusterStatusActive,
+			expCalls:  2,
+			expStatus: aws_eks.ClusterStatusFailed,
+			expErr:    func(err error) bool { return errors.Is(err, ErrClusterFailed) },
+		},
+		{
+			name:      "failure status option",
+			results:   []fakeResult{{status: aws_eks.ClusterStatusDeleting}},
+			desired:   aws_eks.ClusterStatusActive,
+			opts:      []OpOption{WithFailureStatuses(aws_eks.ClusterStatusDeleting)},
+			expCalls:  1,
+			expStatus: aws_eks.ClusterStatusDeleting,
+			expErr:    func(err error) bool { return errors.Is(err, ErrClusterFailed) },
+		},
+		{
+			name:     "deleted as desired",
+			results:  []fakeResult{{status: aws_eks.ClusterStatusDeleting}, {err: clusterNotFoundErr("my-cluster")}},
+			desired:  eksconfig.ClusterStatusDELETEDORNOTEXIST,
+			expCalls: 2,
+		},
+		{
+			name:     "deleted while waiting for active",
+			results:  []fakeResult{{err: clusterNotFoundErr("my-cluster")}},
+			desired:  aws_eks.ClusterStatusActive,
+			expCalls: 1,
+			expErr: func(err error) bool {
+				return errors.Is(err, ErrClusterNotFound) && IsDeleted(err)
+			},
+		},
+		{
+			name:      "retry describe errors",
+			results:   []fakeResult{{err: errors.New("connection reset")}, {status: aws_eks.ClusterStatusActive}},
+			desired:   aws_eks.ClusterStatusActive,
+			expCalls:  2,
+			expStatus: aws_eks.ClusterStatusActive,
+		},
+		{
+			name:         "timeout with last observed cluster",
+			results:      []fakeResult{{status: aws_eks.ClusterStatusCreating}},
+			desired:      aws_eks.ClusterStatusActive,
+			timeout:      50 * time.Millisecond,
+			pollInterval: time.Hour,
+			expCalls:     1,
+			expStatus:    aws_eks.ClusterStatusCreating,
+			expErr:       func(err error) bool { return errors.Is(err, context.DeadlineExceeded) },
+		},
+		{
+			name:         "stopped with last observed cluster",
+			results:      []fakeResult{{status: aws_eks.ClusterStatusCreating}},
+			desired:      aws_eks.ClusterStatusActive,
+			pollInterval: time.Hour,
+			stopAfter:    1,
+			expCalls:     1,
+			expStatus:    aws_eks.ClusterStatusCreating,
+			expErr:       func(err error) bool { return errors.Is(err, ErrWaitStopped) },
+		},
+		{
+			name: "active at desired version",
+			results: []fakeResult{
+				{status: aws_eks.ClusterStatusUpdating, version: "1.28"},
+				{status: aws_eks.ClusterStatusActive, version: "1.28"},
+				{status: aws_eks.ClusterStatusActive, version: "1.29"},
+			},
+			desired:    aws_eks.ClusterStatusActive,
+			opts:       []OpOption{WithDesiredVersion("1.29")},
+			expCalls:   3,
+			expStatus:  aws_eks.ClusterStatusActive,
+			expVersion: "1.29",
+		},
+		{
+			name:         "active at old version does not complete",
+			results:      []fakeResult{{status: aws_eks.ClusterStatusActive, version: "1.28"}},
+			desired:      aws_eks.ClusterStatusActive,
+			opts:         []OpOption{WithDesiredVersion("1.29")},
+			timeout:      50 * time.Millisecond,
+			pollInterval: time.Hour,
+			expCalls:     1,
+			expStatus:    aws_eks.ClusterStatusActive,
+			expVersion:   "1.28",
+			expErr:       func(err error) bool { return errors.Is(err, context.DeadlineExceeded) },
+		},
+	}
+	for _, tv := range tt {
+		t.Run(tv.name, func(t *testing.T) {
+			timeout, pollInterval := tv.timeout, tv.pollInterval
+			if timeout == 0 {
+				timeout = 10 * time.Second
+			}
+			if pollInterval == 0 {
+				pollInterval = time.Millisecond
+			}
+			api := &fakeEKSAPI{clusters: map[string][]fakeResult{"my-cluster": tv.results}}
+			statuses := pollCluster(t, api, tv.desired, timeout, pollInterval, tv.stopAfter, tv.opts...)
+			last := statuses[len(statuses)-1]
+
+			if tv.expErr == nil && last.Error != nil {
+				t.Fatalf("unexpected error %v", last.Error)
+			}
+			if tv.expErr != nil && !tv.expErr(last.Error) {
+				t.Fatalf("unexpected error %v", last.Error)
+			}
+			status, version := "", ""
+			if last.Cluster != nil {
+				status, version = aws.StringValue(last.Cluster.Status), aws.StringValue(last.Cluster.Version)
+			}
+			if status != tv.expStatus {
+				t.Fatalf("expected last status %q, got %q", tv.expStatus, status)
+			}
+			if tv.expVersion != "" && version != tv.expVersion {
+				t.Fatalf("expected last version %q, got %q", tv.expVersion, version)
+			}
+			if calls := api.callCount("my-cluster"); calls != tv.expCalls {
+				t.Fatalf("expected DescribeCluster calls %d, got %d", tv.expCalls, calls)
+			}
+		})
+	}
+}
+
+func TestPollInitialWaitFunc(t *testing.T) {
+	tt := []struct {
+		name        string
+		initialWait time.Duration
+
+		expCalls  int
+		expStatus string
+		expErr    error
+	}{
+		{
+			name:        "short initial wait",
+			initialWait: time.Millisecond,
+			expCalls:    2,
+			expStatus:   aws_eks.ClusterStatusActive,
+		},
+		{
+			name:        "initial wait longer than timeout",
+			initialWait: time.Hour,
+			expCalls:    1,
+			expStatus:   aws_eks.ClusterStatusCreating,
+			expErr:      context.DeadlineExceeded,
+		},
+	}
+	for _, tv := range tt {
+		t.Run(tv.name, func(t *testing.T) {
+			api := &fakeEKSAPI{clusters: map[string][]fakeResult{"my-cluster": {
+				{status: aws_eks.ClusterStatusCreating},
+				{status: aws_eks.ClusterStatusActive},
+			}}}
+			var firstStatuses []string
+			statuses := pollCluster(t, api, aws_eks.ClusterStatusActive, 50*time.Millisecond, time.Millisecond, 0,
+				WithInitialWaitFunc(func(firstStatus string) time.Duration {
+					firstStatuses = append(firstStatuses, firstStatus)
+					return tv.initialWait
+				}),
+			)
+			last := statuses[len(statuses)-1]
+
+			if len(firstStatuses) != 1 || firstStatuses[0] != aws_eks.ClusterStatusCreating {
+				t.Fatalf("expected initial wait func called once with %q, got %q", aws_eks.ClusterStatusCreating, firstStatuses)
+			}
+			if !errors.Is(last.Error, tv.expErr) {
+				t.Fatalf("expected error %v, got %v", tv.expErr, last.Error)
+			}
+			if status := aws.StringValue(last.Cluster.Status); status != tv.expStatus {
+				t.Fatalf("expected last status %q, got %q", tv.expStatus, status)
+			}
+			if calls := api.callCount("my-cluster"); calls != tv.expCalls {
+				t.Fatalf("expected DescribeCluster calls %d, got %d", tv.expCalls, calls)
+			}
+		})
+	}
+}
+
+func TestPollRetryBudget(t *testing.T) {
+	connErr := errors.New("connection reset")
+	tt := []struct {
+		name         string
+		results      []fakeResult
+		retryBudget  time.Duration
+		pollInterval time.Duration
+
+		expCalls  int
+		expStatus string
+		expErr    error
+	}{
+		{
+			name:         "unlimited by default",
+			results:      []fakeResult{{err: connErr}, {err: connErr}, {err: connErr}, {status: aws_eks.ClusterStatusActive}},
+			pollInterval: time.Millisecond,
+			expCalls:     4,
+			expStatus:    aws_eks.ClusterStatusActive,
+		},
+		{
+			name:         "exhausted with last observed cluster",
+			results:      []fakeResult{{status: aws_eks.ClusterStatusCreating}, {err: connErr}},
+			retryBudget:  20 * time.Millisecond,
+			pollInterval: 5 * time.Millisecond,
+			expStatus:    aws_eks.ClusterStatusCreating,
+			expErr:       connErr,
+		},
+		{
+			// each run of errors is shorter than the budget,
+			// but not the errors in total
+			name: "reset after success",
+			results: []fakeResult{
+				{err: connErr},
+				{err: connErr},
+				{status: aws_eks.ClusterStatusCreating},
+				{err: connErr},
+				{err: connErr},
+				{status: aws_eks.ClusterStatusActive},
+			},
+			retryBudget:  50 * time.Millisecond,
+			pollInterval: 20 * time.Millisecond,
+			expCalls:     6,
+			expStatus:    aws_eks.ClusterStatusActive,
+		},
+	}
+	for _, tv := range tt {
+		t.Run(tv.name, func(t *testing.T) {
+			api := &fakeEKSAPI{clusters: map[string][]fakeResult{"my-cluster": tv.results}}
+			statuses := pollCluster(t, api, aws_eks.ClusterStatusActive, 10*time.Second, tv.pollInterval, 0,
+				WithRetryBudget(tv.retryBudget),
+			)
+			last := statuses[len(statuses)-1]
+
+			if last.Error != tv.expErr {
+				t.Fatalf("expected error %v, got %v", tv.expErr, last.Error)
+			}
+			if status := aws.StringValue(last.Cluster.Status); status != tv.expStatus {
+				t.Fatalf("expected last status %q, got %q", tv.expStatus, status)
+			}
+			// the number of calls before the budget is exhausted depends on timing
+			if calls := api.callCount("my-cluster"); tv.expCalls > 0 && calls != tv.expCalls {
+				t.Fatalf("expected DescribeCluster calls %d, got %d", tv.expCalls, calls)
+			}
+		})
+	}
+}
+
+func TestPollClusterFailedHealthIssues(t *testing.T) {
+	api := &fakeEKSAPI{
+		clusters: map[string][]fakeResult{"my-cluster": {{status: aws_eks.ClusterStatusFailed}}},
+		cluster: func(cluster *aws_eks.Cluster) {
+			cluster.Health = &aws_eks.ClusterHealth{Issues: []*aws_eks.ClusterIssue{
+				{Code: aws.String("Ec2SubnetNotFound"), Message: aws.String("subnet not found"), ResourceIds: aws.StringSlice([]string{"subnet-1"})},
+			}}
+		},
+	}
+	statuses := pollCluster(t, api, aws_eks.ClusterStatusActive, 10*time.Second, time.Millisecond, 0)
+	last := statuses[len(statuses)-1]
+
+	var failErr *ClusterFailedError
+	if !errors.As(last.Error, &failErr) {
+		t.Fatalf("expected ClusterFailedError, got %v", last.Error)
+	}
+	if len(failErr.HealthIssues) != 1 || failErr.HealthIssues[0].Code != "Ec2SubnetNotFound" {
+		t.Fatalf("unexpected health issues %+v", failErr.HealthIssues)
+	}
+}
+
+func TestPollDeletionProgress(t *testing.T) {
+	eniIssue := &aws_eks.ClusterIssue{
+		Code:        aws.String("Ec2SecurityGroupNotFound"),
+		Message:     aws.String("security group in use"),
+		ResourceIds: aws.StringSlice([]string{"eni-1"}),
+	}
+	deleting := 0
+	api := &fakeEKSAPI{
+		clusters: map[string][]fakeResult{"my-cluster": {
+			{status: aws_eks.ClusterStatusDeleting},
+			{status: aws_eks.ClusterStatusDeleting},
+			{status: aws_eks.ClusterStatusDeleting},
+			{status: aws_eks.ClusterStatusDeleting},
+			{err: clusterNotFoundErr("my-cluster")},
+		}},
+		// health issue from the second to the third describe call
+		cluster: func(cluster *aws_eks.Cluster) {
+			deleting++
+			if deleting == 2 || deleting == 3 {
+				cluster.Health = &aws_eks.ClusterHealth{Issues: []*aws_eks.ClusterIssue{eniIssue}}
+			}
+		},
+	}
+	statuses := pollCluster(t, api, eksconfig.ClusterStatusDELETEDORNOTEXIST, 10*time.Second, time.Millisecond, 0,
+		WithDeletionProgress(),
+	)
+	if last := statuses[len(statuses)-1]; last.Error != nil || last.Cluster != nil {
+		t.Fatalf("expected deleted cluster, got %+v", last)
+	}
+
+	var progress []string
+	for _, sv := range statuses {
+		if sv.Progress != "" {
+			progress = append(progress, sv.Progress)
+		}
+	}
+	expProgress := []string{
+		"DELETING",
+		"DELETING (health issues [Ec2SecurityGroupNotFound: security group in use (resources eni-1)])",
+		"DELETING",
+	}
+	if !reflect.DeepEqual(progress, expProgress) {
+		t.Fatalf("expected progress %q, got %q", expProgress, progress)
+	}
+}
+
+func TestPollEventSink(t *testing.T) {
+	tt := []struct {
+		name    string
+		results []fakeResult
+
+		// expEvents are "kind status poll-count" of every event.
+		expEvents []string
+	}{
+		{
+			name: "creating to active",
+			results: []fakeResult{
+				{status: aws_eks.ClusterStatusCreating},
+				{status: aws_eks.ClusterStatusCreating},
+				{err: errors.New("connection reset")},
+				{status: aws_eks.ClusterStatusActive},
+			},
+			expEvents: []string{
+				"polled CREATING 1",
+				"status-changed CREATING 1",
+				"polled CREATING 2",
+				"retrying CREATING 3",
+				"polled ACTIVE 4",
+				"status-changed ACTIVE 4",
+				"done ACTIVE 4",
+			},
+		},
+		{
+			name:    "failed",
+			results: []fakeResult{{status: aws_eks.ClusterStatusCreating}, {status: aws_eks.ClusterStatusFailed}},
+			expEvents: []string{
+				"polled CREATING 1",
+				"status-changed CREATING 1",
+				"polled FAILED 2",
+				"status-changed FAILED 2",
+				"aborted FAILED 2",
+			},
+		},
+	}
+	for _, tv := range tt {
+		t.Run(tv.name, func(t *testing.T) {
+			api := &fakeEKSAPI{clusters: map[string][]fakeResult{"my-cluster": tv.results}}
+			var events []string
+			pollCluster(t, api, aws_eks.ClusterStatusActive, 10*time.Second, time.Millisecond, 0,
+				WithEventSink(func(ev PollEvent) {
+					if (ev.Kind == PollEventRetrying || ev.Kind == PollEventAborted) != (ev.Error != nil) {
+						t.Errorf("unexpected error %v for event %q", ev.Error, ev.Kind)
+					}
+					events = append(events, fmt.Sprintf("%s %s %d", ev.Kind, ev.Status, ev.PollCount))
+				}),
+			)
+			if !reflect.DeepEqual(events, tv.expEvents) {
+				t.Fatalf("expected events %q, got %q", tv.expEvents, events)
+			}
+		})
+	}
+}
+
+func TestPollMetricEmitter(t *testing.T) {
+	tt := []struct {
+		name    string
+		results []fakeResult
+		desired string
+
+		// expMetric is the emitted metric name, empty if none.
+		expMetric string
+	}{
+		{
+			name:      "time to active",
+			results:   []fakeResult{{status: aws_eks.ClusterStatusCreating}, {status: aws_eks.ClusterStatusActive}},
+			desired:   aws_eks.ClusterStatusActive,
+			expMetric: "ClusterTimeToACTIVE",
+		},
+		{
+			name:      "time to deleted",
+			results:   []fakeResult{{status: aws_eks.ClusterStatusDeleting}, {err: clusterNotFoundErr("my-cluster")}},
+			desired:   eksconfig.ClusterStatusDELETEDORNOTEXIST,
+			expMetric: "ClusterTimeToDELETED",
+		},
+		{
+			name:    "no metric on failure",
+			results: []fakeResult{{status: aws_eks.ClusterStatusCreating}, {status: aws_eks.ClusterStatusFailed}},
+			desired: aws_eks.ClusterStatusActive,
+		},
+	}
+	for _, tv := range tt {
+		t.Run(tv.name, func(t *testing.T) {
+			api := &fakeEKSAPI{clusters: map[string][]fakeResult{"my-cluster": tv.results}}
+			var metrics []string
+			var value float64
+			statuses := pollCluster(t, api, tv.desired, 10*time.Second, time.Millisecond, 0,
+				WithMetricEmitter(func(name string, v float64, unit string) {
+					metrics = append(metrics, name+" "+unit)
+					value = v
+				}),
+			)
+			last := statuses[len(statuses)-1]
+
+			if tv.expMetric == "" {
+				if len(metrics) != 0 || last.Elapsed != 0 {
+					t.Fatalf("unexpected metrics %q (elapsed %v)", metrics, last.Elapsed)
+				}
+				return
+			}
+			if !reflect.DeepEqual(metrics, []string{tv.expMetric + " Seconds"}) {
+				t.Fatalf("expected metric %q once, got %q", tv.expMetric, metrics)
+			}
+			if last.Elapsed <= 0 || value != last.Elapsed.Seconds() {
+				t.Fatalf("expected metric value %v, got %v", last.Elapsed.Seconds(), value)
+			}
+		})
+	}
+}
+
+// pollUpdate polls the cluster update "first" until the channel is closed,
+// and returns every received status.
+func pollUpdate(t *testing.T, api eksiface.EKSAPI, opts ...OpOption) (statuses []UpdateStatus) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	for sv := range PollUpdate(ctx, make(chan struct{}), zap.NewExample(), ioutil.Discard, api, "my-cluster", "first", aws_eks.UpdateStatusSuccessful, 0, time.Millisecond, opts...) {
+		statuses = append(statuses, sv)
+	}
+	if len(statuses) == 0 {
+		t.Fatal("no status received")
+	}
+	return statuses
+}
+
+func TestPollUpdate(t *testing.T) {
+	cancelled := []fakeResult{{status: aws_eks.UpdateStatusInProgress}, {status: aws_eks.UpdateStatusCancelled}}
+	successful := []fakeResult{{status: aws_eks.UpdateStatusInProgress}, {status: aws_eks.UpdateStatusSuccessful}}
+	tt := []struct {
+		name    string
+		updates map[string][]fakeResult
+		opts    []OpOption
+
+		expID     string
+		expStatus string
+		expErr    error
+		// expCalls are the DescribeUpdate calls keyed by the update ID.
+		expCalls map[string]int
+	}{
+		{
+			name:      "successful",
+			updates:   map[string][]fakeResult{"first": successful},
+			expID:     "first",
+			expStatus: aws_eks.UpdateStatusSuccessful,
+			expCalls:  map[string]int{"first": 2},
+		},
+		{
+			name:      "failed",
+			updates:   map[string][]fakeResult{"first": {{status: aws_eks.UpdateStatusFailed}}},
+			expID:     "first",
+			expStatus: aws_eks.UpdateStatusFailed,
+			expErr:    ErrUpdateFailed,
+			expCalls:  map[string]int{"first": 1},
+		},
+		{
+			name:      "cancelled",
+			updates:   map[string][]fakeResult{"first": cancelled},
+			expID:     "first",
+			expStatus: aws_eks.UpdateStatusCancelled,
+			expErr:    ErrUpdateCancelled,
+			expCalls:  map[string]int{"first": 2},
+		},
+		{
+			name:      "superseded by newer update",
+			updates:   map[string][]fakeResult{"first": cancelled, "second": successful},
+			opts:      []OpOption{WithFollowSupersedingUpdate(func() (string, error) { return "second", nil })},
+			expID:     "second",
+			expStatus: aws_eks.UpdateStatusSuccessful,
+			expCalls:  map[string]int{"first": 2, "second": 2},
+		},
+		{
+			name:      "cancelled without superseding update",
+			updates:   map[string][]fakeResult{"first": cancelled},
+			opts:      []OpOption{WithFollowSupersedingUpdate(func() (string, error) { return "", nil })},
+			expID:     "first",
+			expStatus: aws_eks.UpdateStatusCancelled,
+			expErr:    ErrUpdateCancelled,
+			expCalls:  map[string]int{"first": 2},
+		},
+		{
+			name:      "superseding update lookup failed",
+			updates:   map[string][]fakeResult{"first": cancelled},
+			opts:      []OpOption{WithFollowSupersedingUpdate(func() (string, error) { return "", errors.New("list updates failed") })},
+			expID:     "first",
+			expStatus: aws_eks.UpdateStatusCancelled,
+			expErr:    ErrUpdateCancelled,
+			expCalls:  map[string]int{"first": 2},
+		},
+	}
+	for _, tv := range tt {
+		t.Run(tv.name, func(t *testing.T) {
+			api := &fakeEKSAPI{updates: tv.updates}
+			statuses := pollUpdate(t, api, tv.opts...)
+			last := statuses[len(statuses)-1]
+
+			if !errors.Is(last.Error, tv.expErr) {
+				t.Fatalf("expected error %v, got %v", tv.expErr, last.Error)
+			}
+			if id, status := aws.StringValue(last.Update.Id), aws.StringValue(last.Update.Status); id != tv.expID || status != tv.expStatus {
+				t.Fatalf("expected last update %q %q, got %q %q", tv.expID, tv.expStatus, id, status)
+			}
+			for id := range tv.updates {
+				if calls := api.callCount("update/" + id); calls != tv.expCalls[id] {
+					t.Fatalf("expected DescribeUpdate calls %d for %q, got %d", tv.expCalls[id], id, calls)
+				}
+			}
+		})
+	}
+}
+
+func TestIsThrottled(t *testing.T) {
+	tt := []struct {
+		err error
+		exp bool
+	}{
+		{nil, false},
+		{throttlingErr(), true},
+		{awserr.New("TooManyRequestsException", "too many requests", nil), true},
+		{awserr.New("RequestLimitExceeded", "request limit exceeded", nil), true},
+		{fmt.Errorf("describe cluster: %w", awserr.New("Throttling", "slow down", nil)), true},
+		{errors.New("ThrottlingException: Rate exceeded"), true},
+		{clusterNotFoundErr("my-cluster"), false},
+		{errors.New("connection reset"), false},
+	}
+	for i, tv := range tt {
+		if got := isThrottled(tv.err); got != tv.exp {
+			t.Fatalf("#%d: expected %v for %v, got %v", i, tv.exp, tv.err, got)
+		}
+	}
+}
+
+func TestPollThrottled(t *testing.T) {
+	api := &fakeEKSAPI{clusters: map[string][]fakeResult{"my-cluster": {
+		{err: throttlingErr()},
+		{err: throttlingErr()},
+		{status: aws_eks.ClusterStatusCreating},
+		{err: throttlingErr()},
+		{status: aws_eks.ClusterStatusActive},
+	}}}
+	var throttled []int
+	statuses := pollCluster(t, api, aws_eks.ClusterStatusActive, 10*time.Second, time.Millisecond, 0,
+		WithThrottledFunc(func(n int) { throttled = append(throttled, n) }),
+	)
+	if last := statuses[len(statuses)-1]; last.Error != nil {
+		t.Fatal(last.Error)
+	}
+	if !reflect.DeepEqual(throttled, []int{1, 2, 3}) {
+		t.Fatalf("expected cumulative throttled counts [1 2 3], got %v", throttled)
+	}
+	// throttling errors are retried and sent to the caller
+	errs := 0
+	for _, sv := range statuses {
+		if isThrottled(sv.Error) {
+			errs++
+		}
+	}
+	if errs != 3 {
+		t.Fatalf("expected 3 throttled statuses, got %d", errs)
+	}
+
+	// throttled until the retry budget is exhausted
+	api = &fakeEKSAPI{clusters: map[string][]fakeResult{"my-cluster": {{err: throttlingErr()}}}}
+	statuses = pollCluster(t, api, aws_eks.ClusterStatusActive, 10*time.Second, time.Millisecond, 0,
+		WithRetryBudget(20*time.Millisecond),
+	)
+	if last := statuses[len(statuses)-1]; !isThrottled(last.Error) {
+		t.Fatalf("expected throttling error, got %v", last.Error)
+	}
+}
+
+func TestPollUpdateSupersededStatuses(t *testing.T) {
+	api := &fakeEKSAPI{updates: map[string][]fakeResult{
+		"first":  {{status: aws_eks.UpdateStatusInProgress}, {status: aws_eks.UpdateStatusCancelled}},
+		"second": {{status: aws_eks.UpdateStatusInProgress}, {status: aws_eks.UpdateStatusSuccessful}},
+	}}
+	statuses := pollUpdate(t, api, WithFollowSupersedingUpdate(func() (string, error) { return "second", nil }))
+
+	// the cancelled update is followed, not sent
+	var seq []string
+	for _, sv := range statuses {
+		if sv.Error != nil {
+			t.Fatalf("unexpected error %v", sv.Error)
+		}
+		seq = append(seq, aws.StringValue(sv.Update.Id)+"/"+aws.StringValue(sv.Update.Status))
+	}
+	expSeq := []string{
+		"first/" + aws_eks.UpdateStatusInProgress,
+		"second/" + aws_eks.UpdateStatusInProgress,
+		"second/" + aws_eks.UpdateStatusSuccessful,
+	}
+	if !reflect.DeepEqual(seq, expSeq) {
+		t.Fatalf("expected statuses %q, got %q", expSeq, seq)
+	}
+}
+
+func TestPollUpdateFailedError(t *testing.T) {
+	api := &fakeEKSAPI{
+		updates: map[string][]fakeResult{"first": {{status: aws_eks.UpdateStatusInProgress}, {status: aws_eks.UpdateStatusFailed}}},
+		update: func(update *aws_eks.Update) {
+			update.Params = []*aws_eks.UpdateParam{
+				{Type: aws.String(aws_eks.UpdateParamTypeVersion), Value: aws.String("1.29")},
+			}
+			if aws.StringValue(update.Status) == aws_eks.UpdateStatusFailed {
+				update.Errors = []*aws_eks.ErrorDetail{
+					{ErrorCode: aws.String(aws_eks.ErrorCodeSubnetNotFound), ErrorMessage: aws.String("subnet not found"), ResourceIds: aws.StringSlice([]string{"subnet-1"})},
+				}
+			}
+		},
+	}
+	statuses := pollUpdate(t, api)
+	last := statuses[len(statuses)-1]
+
+	var updateErr *UpdateFailedError
+	if !errors.As(last.Error, &updateErr) {
+		t.Fatalf("expected UpdateFailedError, got %v", last.Error)
+	}
+	if !errors.Is(last.Error, ErrUpdateFailed) || errors.Is(last.Error, ErrUpdateCancelled) {
+		t.Fatalf("expected ErrUpdateFailed only, got %v", last.Error)
+	}
+	expErrors := []UpdateError{{Code: aws_eks.ErrorCodeSubnetNotFound, Message: "subnet not found", ResourceIDs: []string{"subnet-1"}}}
+	if updateErr.ClusterName != "my-cluster" || updateErr.UpdateID != "first" || updateErr.Status != aws_eks.UpdateStatusFailed {
+		t.Fatalf("unexpected update error %+v", updateErr)
+	}
+	if !reflect.DeepEqual(updateErr.Errors, expErrors) {
+		t.Fatalf("expected errors %+v, got %+v", expErrors, updateErr.Errors)
+	}
+	if expParams := map[string]string{aws_eks.UpdateParamTypeVersion: "1.29"}; !reflect.DeepEqual(updateErr.Params, expParams) {
+		t.Fatalf("expected params %v, got %v", expParams, updateErr.Params)
+	}
+}
+
+func TestUpdateFailedErrorString(t *testing.T) {
+	tt := []struct {
+		err *UpdateFailedError
+		exp string
+	}{
+		{
+			&UpdateFailedError{Status: aws_eks.UpdateStatusCancelled},
+			`cluster update cancelled (unexpected cluster update status "Cancelled")`,
+		},
+		{
+			&UpdateFailedError{Status: aws_eks.UpdateStatusFailed, Errors: []UpdateError{{Code: "SubnetNotFound", Message: "subnet not found"}}},
+			`cluster update failed (unexpected cluster update status "Failed", errors [SubnetNotFound: subnet not found])`,
+		},
+	}
+	for i, tv := range tt {
+		if got := tv.err.Error(); got != tv.exp {
+			t.Fatalf("#%d: expected %q, got %q", i, tv.exp, got)
+		}
+	}
+}
+
+func TestPollDescribeTimeout(t *testing.T) {
+	api := &fakeEKSAPI{
+		clusters: map[string][]fakeResult{"my-cluster": {{block: true}, {status: aws_eks.ClusterStatusActive}}},
+		updates:  map[string][]fakeResult{"first": {{block: true}, {status: aws_eks.UpdateStatusSuccessful}}},
+	}
+	statuses := pollCluster(t, api, aws_eks.ClusterStatusActive, 10*time.Second, time.Millisecond, 0,
+		WithDescribeTimeout(10*time.Millisecond),
+	)
+	if len(statuses) != 2 || !errors.Is(statuses[0].Error, context.DeadlineExceeded) {
+		t.Fatalf("expected timed out describe call to be retried, got %+v", statuses)
+	}
+	if last := statuses[len(statuses)-1]; last.Error != nil || aws.StringValue(last.Cluster.Status) != aws_eks.ClusterStatusActive {
+		t.Fatalf("unexpected last status %+v", last)
+	}
+
+	updates := pollUpdate(t, api, WithDescribeTimeout(10*time.Millisecond))
+	if len(updates) != 2 || !errors.Is(updates[0].Error, context.DeadlineExceeded) {
+		t.Fatalf("expected timed out describe call to be retried, got %+v", updates)
+	}
+	if last := updates[len(updates)-1]; last.Error != nil || aws.StringValue(last.Update.Status) != aws_eks.UpdateStatusSuccessful {
+		t.Fatalf("unexpected last status %+v", last)
+	}
+}
+
+func TestPollOnPoll(t *testing.T) {
+	connErr := errors.New("connection reset")
+	api := &fakeEKSAPI{
+		clusters: map[string][]fakeResult{"my-cluster": {
+			{status: aws_eks.ClusterStatusCreating},
+			{err: connErr},
+			{status: aws_eks.ClusterStatusCreating},
+			{status: aws_eks.ClusterStatusActive},
+		}},
+		updates: map[string][]fakeResult{"first": {
+			{status: aws_eks.UpdateStatusInProgress},
+			{err: connErr},
+			{status: aws_eks.UpdateStatusSuccessful},
+		}},
+	}
+
+	// called once per successful describe call, including the final one
+	var polled []string
+	pollCluster(t, api, aws_eks.ClusterStatusActive, 10*time.Second, time.Millisecond, 0,
+		WithOnPoll(func(cluster *aws_eks.Cluster) { polled = append(polled, aws.StringValue(cluster.Status)) }),
+	)
+	expPolled := []string{aws_eks.ClusterStatusCreating, aws_eks.ClusterStatusCreating, aws_eks.ClusterStatusActive}
+	if !reflect.DeepEqual(polled, expPolled) {
+		t.Fatalf("expected polled clusters %q, got %q", expPolled, polled)
+	}
+
+	polled = nil
+	pollUpdate(t, api,
+		WithOnPollUpdate(func(update *aws_eks.Update) { polled = append(polled, aws.StringValue(update.Status)) }),
+	)
+	expPolled = []string{aws_eks.UpdateStatusInProgress, aws_eks.UpdateStatusSuccessful}
+	if !reflect.DeepEqual(polled, expPolled) {
+		t.Fatalf("expected polled updates %q, got %q", expPolled, polled)
+	}
+}
+
+func TestPollDedupe(t *testing.T) {
+	results := []fakeResult{
+		{status: aws_eks.ClusterStatusCreating},
+		{status: aws_eks.ClusterStatusCreating},
+		{status: aws_eks.ClusterStatusCreating},
+		{status: aws_eks.ClusterStatusUpdating},
+		{status: aws_eks.ClusterStatusUpdating},
+		{err: errors.New("connection reset")},
+		{status: aws_eks.ClusterStatusUpdating},
+		{status: aws_eks.ClusterStatusActive},
+	}
+	tt := []struct {
+		name string
+		opts []OpOption
+
+		expStatuses []string
+	}{
+		{
+			name:        "every status without dedupe",
+			expStatuses: []string{"CREATING", "CREATING", "CREATING", "UPDATING", "UPDATING", "error", "UPDATING", "ACTIVE"},
+		},
+		{
+			name:        "transitions only",
+			opts:        []OpOption{WithDedupe(0)},
+			expStatuses: []string{"CREATING", "UPDATING", "error", "ACTIVE"},
+		},
+		{
+			name:        "keepalive",
+			opts:        []OpOption{WithDedupe(time.Nanosecond)},
+			expStatuses: []string{"CREATING", "CREATING", "CREATING", "UPDATING", "UPDATING", "error", "UPDATING", "ACTIVE"},
+		},
+	}
+	for _, tv := range tt {
+		t.Run(tv.name, func(t *testing.T) {
+			api := &fakeEKSAPI{clusters: map[string][]fakeResult{"my-cluster": results}}
+			var statuses []string
+			for _, sv := range pollCluster(t, api, aws_eks.ClusterStatusActive, 10*time.Second, time.Millisecond, 0, tv.opts...) {
+				if sv.Error != nil {
+					statuses = append(statuses, "error")
+					continue
+				}
+				statuses = append(statuses, aws.StringValue(sv.Cluster.Status))
+			}
+			if !reflect.DeepEqual(statuses, tv.expStatuses) {
+				t.Fatalf("expected statuses %q, got %q", tv.expStatuses, statuses)
+			}
+			if calls := api.callCount("my-cluster"); calls != len(results) {
+				t.Fatalf("expected DescribeCluster calls %d, got %d", len(results), calls)
+			}
+		})
+	}
+}
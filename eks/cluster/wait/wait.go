@@ -0,0 +1,174 @@
+package wait
+
+import (
+	"context"
+	"io"
+	"time"
+
+	aws_eks "github.com/aws/aws-sdk-go/service/eks"
+	"github.com/aws/aws-sdk-go/service/eks/eksiface"
+	"go.uber.org/zap"
+)
+
+// Wait calls "Poll" and blocks until the cluster becomes the desired state.
+// It returns the final cluster on success, or the terminal error with
+// the last observed cluster (if any) otherwise. Transient describe errors
+// that "Poll" retries are not returned. The channel is always drained
+// so that the poll goroutine can exit.
+func Wait(
+	ctx context.Context,
+	stopc chan struct{},
+	lg *zap.Logger,
+	logWriter io.Writer,
+	eksAPI eksiface.EKSAPI,
+	clusterName string,
+	desiredClusterStatus string,
+	initialWait time.Duration,
+	pollInterval time.Duration,
+	opts ...OpOption) (*aws_eks.Cluster, error) {
+
+	var last ClusterStatus
+	for v := range Poll(
+		ctx,
+		stopc,
+		lg,
+		logWriter,
+		eksAPI,
+		clusterName,
+		desiredClusterStatus,
+		initialWait,
+		pollInterval,
+		opts...,
+	) {
+		last = v
+	}
+	return last.Cluster, last.Error
+}
+
+// WaitUpdate calls "PollUpdate" and blocks until the cluster update
+// becomes the desired state. It returns the final update on success,
+// or the terminal error otherwise. The channel is always drained
+// so that the poll goroutine can exit.
+func WaitUpdate(
+	ctx context.Context,
+	stopc chan struct{},
+	lg *zap.Logger,
+	logWriter io.Writer,
+	eksAPI eksiface.EKSAPI,
+	clusterName string,
+	requestID string,
+	desiredUpdateStatus string,
+	initialWait time.Duration,
+	pollInterval time.Duration,
+	opts ...OpOption) (*aws_eks.Update, error) {
+
+	var last UpdateStatus
+	for v := range PollUpdate(
+		ctx,
+		stopc,
+		lg,
+		logWriter,
+		eksAPI,
+		clusterName,
+		requestID,
+		desiredUpdateStatus,
+		initialWait,
+		pollInterval,
+		opts...,
+	) {
+		last = v
+	}
+	return last.Update, last.Error
+}
+
+// PollContext is the same as "Poll", but is cancelled only via the context.
+// Use "ctxutil.WithStopc" to cancel on a stop channel, in which case
+// "ErrWaitStopped" is returned.
+func PollContext(
+	ctx context.Context,
+	lg *zap.Logger,
+	logWriter io.Writer,
+	eksAPI eksiface.EKSAPI,
+	clusterName string,
+	desiredClusterStatus string,
+	initialWait time.Duration,
+	pollInterval time.Duration,
+	opts ...OpOption) <-chan ClusterStatus {
+	return Poll(ctx, nil, lg, logWriter, eksAPI, clusterName, desiredClusterStatus, initialWait, pollInterval, opts...)
+}
+
+// PollUpdateContext is the same as "PollUpdate", but is cancelled only via the context.
+func PollUpdateContext(
+	ctx context.Context,
+	lg *zap.Logger,
+	logWriter io.Writer,
+	eksAPI eksiface.EKSAPI,
+	clusterName string,
+	requestID string,
+	desiredUpdateStatus string,
+	initialWait time.Duration,
+	pollInterval time.Duration,
+	opts ...OpOption) <-chan UpdateStatus {
+	return PollUpdate(ctx, nil, lg, logWriter, eksAPI, clusterName, requestID, desiredUpdateStatus, initialWait, pollInterval, opts...)
+}
+
+// WaitContext is the same as "Wait", but is cancelled only via the context.
+func WaitContext(
+	ctx context.Context,
+	lg *zap.Logger,
+	logWriter io.Writer,
+	eksAPI eksiface.EKSAPI,
+	clusterName string,
+	desiredClusterStatus string,
+	initialWait time.Duration,
+	pollInterval time.Duration,
+	opts ...OpOption) (*aws_eks.Cluster, error) {
+	return Wait(ctx, nil, lg, logWriter, eksAPI, clusterName, desiredClusterStatus, initialWait, pollInterval, opts...)
+}
+
+// WaitUpdateContext is the same as "WaitUpdate", but is cancelled only via the context.
+func WaitUpdateContext(
+	ctx context.Context,
+	lg *zap.Logger,
+	logWriter io.Writer,
+	eksAPI eksiface.EKSAPI,
+	clusterName string,
+	requestID string,
+	desiredUpdateStatus string,
+	initialWait time.Duration,
+	pollInterval time.Duration,
+	opts ...OpOption) (*aws_eks.Update, error) {
+	return WaitUpdate(ctx, nil, lg, logWriter, eksAPI, clusterName, requestID, desiredUpdateStatus, initialWait, pollInterval, opts...)
+}
+
+// WaitForClusterStatus blocks until the cluster becomes the desired state,
+// and returns the final cluster. It is the same as "WaitContext", so that
+// testers can wait for a cluster status in one call.
+func WaitForClusterStatus(
+	ctx context.Context,
+	lg *zap.Logger,
+	logWriter io.Writer,
+	eksAPI eksiface.EKSAPI,
+	clusterName string,
+	desiredClusterStatus string,
+	initialWait time.Duration,
+	pollInterval time.Duration,
+	opts ...OpOption) (*aws_eks.Cluster, error) {
+	return WaitContext(ctx, lg, logWriter, eksAPI, clusterName, desiredClusterStatus, initialWait, pollInterval, opts...)
+}
+
+// WaitForUpdateStatus blocks until the cluster update becomes the desired
+// state, and returns the final update. It is the same as "WaitUpdateContext".
+func WaitForUpdateStatus(
+	ctx context.Context,
+	lg *zap.Logger,
+	logWriter io.Writer,
+	eksAPI eksiface.EKSAPI,
+	clusterName string,
+	requestID string,
+	desiredUpdateStatus string,
+	initialWait time.Duration,
+	pollInterval time.Duration,
+	opts ...OpOption) (*aws_eks.Update, error) {
+	return WaitUpdateContext(ctx, lg, logWriter, eksAPI, clusterName, requestID, desiredUpdateStatus, initialWait, pollInterval, opts...)
+}
@@ -0,0 +1,170 @@
+package wait
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-k8s-tester/pkg/ctxutil"
+	"github.com/aws/aws-sdk-go/aws"
+	aws_eks "github.com/aws/aws-sdk-go/service/eks"
+	"go.uber.org/zap"
+)
+
+func TestWait(t *testing.T) {
+	tt := []struct {
+		name    string
+		results []fakeResult
+		// stop closes the stop channel on the first describe call.
+		stop bool
+
+		expStatus string
+		expErr    error
+	}{
+		{
+			name:      "active after describe error",
+			results:   []fakeResult{{status: aws_eks.ClusterStatusCreating}, {err: errors.New("connection reset")}, {status: aws_eks.ClusterStatusActive}},
+			expStatus: aws_eks.ClusterStatusActive,
+		},
+		{
+			name:      "failed",
+			results:   []fakeResult{{status: aws_eks.ClusterStatusCreating}, {status: aws_eks.ClusterStatusFailed}},
+			expStatus: aws_eks.ClusterStatusFailed,
+			expErr:    ErrClusterFailed,
+		},
+		{
+			name:      "stopped with last observed cluster",
+			results:   []fakeResult{{status: aws_eks.ClusterStatusCreating}},
+			stop:      true,
+			expStatus: aws_eks.ClusterStatusCreating,
+			expErr:    ErrWaitStopped,
+		},
+	}
+	for _, tv := range tt {
+		for _, withContext := range []bool{false, true} {
+			name := tv.name + "/Wait"
+			if withContext {
+				name = tv.name + "/WaitContext"
+			}
+			t.Run(name, func(t *testing.T) {
+				ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+				defer cancel()
+				stopc, once := make(chan struct{}), new(sync.Once)
+				api := &fakeEKSAPI{clusters: map[string][]fakeResult{"my-cluster": tv.results}}
+				if tv.stop {
+					api.cluster = func(*aws_eks.Cluster) { once.Do(func() { close(stopc) }) }
+				}
+				var (
+					cluster *aws_eks.Cluster
+					err     error
+				)
+				if withContext {
+					ctx, cancel = ctxutil.WithStopc(ctx, stopc)
+					defer cancel()
+					cluster, err = WaitContext(ctx, zap.NewExample(), ioutil.Discard, api, "my-cluster", aws_eks.ClusterStatusActive, 0, time.Millisecond)
+				} else {
+					cluster, err = Wait(ctx, stopc, zap.NewExample(), ioutil.Discard, api, "my-cluster", aws_eks.ClusterStatusActive, 0, time.Millisecond)
+				}
+				if !errors.Is(err, tv.expErr) {
+					t.Fatalf("expected error %v, got %v", tv.expErr, err)
+				}
+				status := ""
+				if cluster != nil {
+					status = aws.StringValue(cluster.Status)
+				}
+				if status != tv.expStatus {
+					t.Fatalf("expected status %q, got %q", tv.expStatus, status)
+				}
+			})
+		}
+	}
+}
+
+func TestWaitUpdate(t *testing.T) {
+	tt := []struct {
+		name    string
+		results []fakeResult
+		// stop closes the stop channel on the first describe call.
+		stop bool
+
+		expStatus string
+		expErr    error
+	}{
+		{
+			name:      "successful after describe error",
+			results:   []fakeResult{{status: aws_eks.UpdateStatusInProgress}, {err: errors.New("connection reset")}, {status: aws_eks.UpdateStatusSuccessful}},
+			expStatus: aws_eks.UpdateStatusSuccessful,
+		},
+		{
+			name:      "failed",
+			results:   []fakeResult{{status: aws_eks.UpdateStatusInProgress}, {status: aws_eks.UpdateStatusFailed}},
+			expStatus: aws_eks.UpdateStatusFailed,
+			expErr:    ErrUpdateFailed,
+		},
+		{
+			name:    "stopped",
+			results: []fakeResult{{status: aws_eks.UpdateStatusInProgress}},
+			stop:    true,
+			expErr:  ErrWaitStopped,
+		},
+	}
+	for _, tv := range tt {
+		for _, withContext := range []bool{false, true} {
+			name := tv.name + "/WaitUpdate"
+			if withContext {
+				name = tv.name + "/WaitUpdateContext"
+			}
+			t.Run(name, func(t *testing.T) {
+				ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+				defer cancel()
+				stopc, once := make(chan struct{}), new(sync.Once)
+				api := &fakeEKSAPI{updates: map[string][]fakeResult{"my-update": tv.results}}
+				if tv.stop {
+					api.update = func(*aws_eks.Update) { once.Do(func() { close(stopc) }) }
+				}
+				var (
+					update *aws_eks.Update
+					err    error
+				)
+				if withContext {
+					ctx, cancel = ctxutil.WithStopc(ctx, stopc)
+					defer cancel()
+					update, err = WaitUpdateContext(ctx, zap.NewExample(), ioutil.Discard, api, "my-cluster", "my-update", aws_eks.UpdateStatusSuccessful, 0, time.Millisecond)
+				} else {
+					update, err = WaitUpdate(ctx, stopc, zap.NewExample(), ioutil.Discard, api, "my-cluster", "my-update", aws_eks.UpdateStatusSuccessful, 0, time.Millisecond)
+				}
+				if !errors.Is(err, tv.expErr) {
+					t.Fatalf("expected error %v, got %v", tv.expErr, err)
+				}
+				status := ""
+				if update != nil {
+					status = aws.StringValue(update.Status)
+				}
+				if status != tv.expStatus {
+					t.Fatalf("expected status %q, got %q", tv.expStatus, status)
+				}
+			})
+		}
+	}
+}
+
+func TestWaitForStatus(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	api := &fakeEKSAPI{
+		clusters: map[string][]fakeResult{"my-cluster": {{status: aws_eks.ClusterStatusCreating}, {status: aws_eks.ClusterStatusActive}}},
+		updates:  map[string][]fakeResult{"my-update": {{status: aws_eks.UpdateStatusInProgress}, {status: aws_eks.UpdateStatusSuccessful}}},
+	}
+
+	cluster, err := WaitForClusterStatus(ctx, zap.NewExample(), ioutil.Discard, api, "my-cluster", aws_eks.ClusterStatusActive, 0, time.Millisecond)
+	if err != nil || aws.StringValue(cluster.Status) != aws_eks.ClusterStatusActive {
+		t.Fatalf("unexpected cluster %+v, error %v", cluster, err)
+	}
+	update, err := WaitForUpdateStatus(ctx, zap.NewExample(), ioutil.Discard, api, "my-cluster", "my-update", aws_eks.UpdateStatusSuccessful, 0, time.Millisecond)
+	if err != nil || aws.StringValue(update.Status) != aws_eks.UpdateStatusSuccessful {
+		t.Fatalf("unexpected update %+v, error %v", update, err)
+	}
+}
@@ -0,0 +1,237 @@
+package eks
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/aws/aws-k8s-tester/pkg/fileutil"
+	k8s_client "github.com/aws/aws-k8s-tester/pkg/k8s-client"
+	"github.com/aws/aws-sdk-go/aws"
+	aws_eks "github.com/aws/aws-sdk-go/service/eks"
+	"go.uber.org/zap"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/exec"
+)
+
+// eniConfigLabelDef is the node label whose value is the "ENIConfig" name,
+// so that each node uses the pod subnet of its availability zone.
+const eniConfigLabelDef = "topology.kubernetes.io/zone"
+
+const eniConfigTemplate = `{{ range $az, $subnet := .Subnets }}---
+apiVersion: crd.k8s.amazonaws.com/v1alpha1
+kind: ENIConfig
+metadata:
+  name: {{ $az }}
+spec:
+  subnet: {{ $subnet }}
+  securityGroups:
+{{ range $.SecurityGroupIDs }}  - {{ . }}
+{{ end }}{{ end }}`
+
+// configureCNICustomNetworking creates the "ENIConfig" of each availability
+// zone, and enables the custom networking on the VPC CNI "aws-node" DaemonSet.
+// Must be run before the node groups are created, since the existing nodes
+// keep allocating the pod IPs from the node subnets.
+func (ts *Tester) configureCNICustomNetworking() error {
+	if ts.k8sClient == nil {
+		return errors.New("nil k8s client")
+	}
+	cur := ts.cfg.CNICustomNetworking
+	if len(cur.PodSubnetIDs) != len(cur.PodSubnetCIDRs) {
+		return fmt.Errorf("expected %d pod subnets, got %q", len(cur.PodSubnetCIDRs), cur.PodSubnetIDs)
+	}
+
+	out, err := ts.eksAPIForCluster.DescribeCluster(&aws_eks.DescribeClusterInput{
+		Name: aws.String(ts.cfg.Name),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to describe cluster %q (%v)", ts.cfg.Name, err)
+	}
+	// pod ENIs use the same security groups as the managed node group nodes
+	sgIDs := make([]string, 0, 2)
+	if vc := out.Cluster.ResourcesVpcConfig; vc != nil && aws.StringValue(vc.ClusterSecurityGroupId) != "" {
+		sgIDs = append(sgIDs, aws.StringValue(vc.ClusterSecurityGroupId))
+	}
+	sgIDs = append(sgIDs, ts.cfg.VPC.SecurityGroupID)
+
+	cur.ENIConfigs = make(map[string]string, len(cur.PodSubnetIDs))
+	for idx, subnetID := range cur.PodSubnetIDs {
+		cur.ENIConfigs[ts.cfg.AvailabilityZoneNames[idx]] = subnetID
+	}
+	tpl := template.Must(template.New("eniConfigTemplate").Parse(eniConfigTemplate))
+	buf := bytes.NewBuffer(nil)
+	if err = tpl.Execute(buf, struct {
+		Subnets          map[string]string
+		SecurityGroupIDs []string
+	}{
+		Subnets:          cur.ENIConfigs,
+		SecurityGroupIDs: sgIDs,
+	}); err != nil {
+		return err
+	}
+	fpath, err := fileutil.WriteTempFile(buf.Bytes())
+	if err != nil {
+		return err
+	}
+	applyArgs := []string{
+		ts.cfg.KubectlPath,
+		"--kubeconfig=" + ts.cfg.KubeConfigPath,
+		"apply",
+		"--filename=" + fpath,
+	}
+	applyCmd := strings.Join(applyArgs, " ")
+
+	// "ENIConfig" CRD is installed with the VPC CNI
+	applied := false
+	retryStart, waitDur := time.Now(), 5*time.Minute
+	for time.Since(retryStart) < waitDur {
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+		output, err := exec.New().CommandContext(ctx, applyArgs[0], applyArgs[1:]...).CombinedOutput()
+		cancel()
+		fmt.Fprintf(ts.logWriter, "\n\n'%s' output:\n\n%s\n\n", applyCmd, strings.TrimSpace(string(output)))
+		if err == nil {
+			applied = true
+			break
+		}
+		ts.lg.Warn("failed to apply ENIConfig", zap.Error(err))
+		select {
+		case <-ts.stopCreationCh:
+			return errors.New("ENIConfig creation aborted")
+		case <-time.After(10 * time.Second):
+		}
+	}
+	if !applied {
+		return errors.New("failed to apply ENIConfig")
+	}
+	ts.cfg.Sync()
+	ts.lg.Info("created ENIConfig", zap.Any("eni-configs", cur.ENIConfigs), zap.Strings("security-group-ids", sgIDs))
+
+	patch := []byte(fmt.Sprintf(
+		`{"spec":{"template":{"spec":{"containers":[{"name":"aws-node","env":[{"name":"AWS_VPC_K8S_CNI_CUSTOM_NETWORK_CFG","value":"true"},{"name":"ENI_CONFIG_LABEL_DEF","value":%q}]}]}}}}`,
+		eniConfigLabelDef,
+	))
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	_, err = ts.k8sClient.KubernetesClientSet().
+		AppsV1().
+		DaemonSets("kube-system").
+		Patch(ctx, "aws-node", types.StrategicMergePatchType, patch, metav1.PatchOptions{})
+	cancel()
+	if err != nil {
+		return fmt.Errorf("failed to enable custom networking on aws-node DaemonSet (%v)", err)
+	}
+	ts.lg.Info("enabled custom networking on aws-node DaemonSet", zap.String("eni-config-label-def", eniConfigLabelDef))
+	return nil
+}
+
+// checkCNICustomNetworking runs the test pods, and checks that every pod
+// received an IP from the secondary CIDR. The namespace is deleted afterwards.
+func (ts *Tester) checkCNICustomNetworking() (err error) {
+	if ts.k8sClient == nil {
+		return errors.New("nil k8s client")
+	}
+	cur := ts.cfg.CNICustomNetworking
+	cli := ts.k8sClient.KubernetesClientSet()
+	ns := cur.Namespace
+	if err = k8s_client.CreateNamespace(ts.lg, cli, ns); err != nil {
+		return err
+	}
+	defer func() {
+		if derr := k8s_client.DeleteNamespaceAndWait(
+			ts.lg,
+			cli,
+			ns,
+			k8s_client.DefaultNamespaceDeletionInterval,
+			k8s_client.DefaultNamespaceDeletionTimeout,
+			k8s_client.WithForceDelete(true),
+		); derr != nil && err == nil {
+			err = fmt.Errorf("failed to delete namespace %q (%v)", ns, derr)
+		}
+	}()
+
+	for i := 0; i < cur.Pods; i++ {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		_, err = cli.CoreV1().Pods(ns).Create(ctx, &v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      fmt.Sprintf("cni-custom-networking-%d", i),
+				Namespace: ns,
+			},
+			Spec: v1.PodSpec{
+				RestartPolicy: v1.RestartPolicyNever,
+				NodeSelector:  map[string]string{"NodeType": "regular"},
+				Containers: []v1.Container{
+					{
+						Name:    "sleep",
+						Image:   "busybox",
+						Command: []string{"sleep", "3600"},
+					},
+				},
+			},
+		}, metav1.CreateOptions{})
+		cancel()
+		if err != nil {
+			return fmt.Errorf("failed to create pod (%v)", err)
+		}
+	}
+
+	cur.PodIPs = make(map[string]string, cur.Pods)
+	retryStart, waitDur := time.Now(), 5*time.Minute
+	for len(cur.PodIPs) < cur.Pods {
+		if time.Since(retryStart) > waitDur {
+			return fmt.Errorf("%d of %d pods received IPs after %v", len(cur.PodIPs), cur.Pods, waitDur)
+		}
+		select {
+		case <-ts.stopCreationCh:
+			return errors.New("CNI custom networking check aborted")
+		case <-time.After(10 * time.Second):
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		pods, err := cli.CoreV1().Pods(ns).List(ctx, metav1.ListOptions{})
+		cancel()
+		if err != nil {
+			ts.lg.Warn("failed to list pods", zap.Error(err))
+			continue
+		}
+		for _, pod := range pods.Items {
+			if pod.Status.Phase == v1.PodRunning && pod.Status.PodIP != "" {
+				cur.PodIPs[pod.Name] = pod.Status.PodIP
+			}
+		}
+		ts.lg.Info("waiting for pod IPs", zap.Int("running", len(cur.PodIPs)), zap.Int("target", cur.Pods))
+	}
+	ts.cfg.Sync()
+
+	outside, err := podIPsOutsideCIDR(cur.SecondaryCIDR, cur.PodIPs)
+	if err != nil {
+		return err
+	}
+	if len(outside) > 0 {
+		return fmt.Errorf("pods %q did not receive IPs from the secondary CIDR %q", outside, cur.SecondaryCIDR)
+	}
+	ts.lg.Info("checked CNI custom networking", zap.String("secondary-cidr", cur.SecondaryCIDR), zap.Any("pod-ips", cur.PodIPs))
+	return nil
+}
+
+// podIPsOutsideCIDR returns the pod names whose IPs are not within the CIDR,
+// with the IPs (e.g. "pod-1 (10.0.1.2)").
+func podIPsOutsideCIDR(cidr string, podIPs map[string]string) (outside []string, err error) {
+	_, ipNet, err := net.ParseCIDR(cidr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid CIDR %q (%v)", cidr, err)
+	}
+	for name, ip := range podIPs {
+		if !ipNet.Contains(net.ParseIP(ip)) {
+			outside = append(outside, fmt.Sprintf("%s (%s)", name, ip))
+		}
+	}
+	sort.Strings(outside)
+	return outside, nil
+}
@@ -0,0 +1,24 @@
+package eks
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPodIPsOutsideCIDR(t *testing.T) {
+	outside, err := podIPsOutsideCIDR("100.64.0.0/16", map[string]string{
+		"pod-0": "100.64.1.2",
+		"pod-1": "10.0.1.2",
+		"pod-2": "100.65.0.1",
+		"pod-3": "100.64.255.255",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exp := []string{"pod-1 (10.0.1.2)", "pod-2 (100.65.0.1)"}; !reflect.DeepEqual(outside, exp) {
+		t.Fatalf("expected %q, got %q", exp, outside)
+	}
+	if _, err = podIPsOutsideCIDR("100.64.0.0", nil); err == nil {
+		t.Fatal("expected error for invalid CIDR")
+	}
+}
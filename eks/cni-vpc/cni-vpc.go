@@ -580,6 +580,26 @@ func (ts *tester) updateCNIDaemonSet() (err error) {
 			Value: "1",
 		})
 	}
+	if ts.cfg.EKSConfig.IsEnabledCNICustomNetworking() {
+		// ref. https://docs.aws.amazon.com/eks/latest/userguide/cni-custom-network.html
+		envVars = append(envVars,
+			v1.EnvVar{
+				Name:  "AWS_VPC_K8S_CNI_CUSTOM_NETWORK_CFG",
+				Value: "true",
+			},
+			v1.EnvVar{
+				Name:  "ENI_CONFIG_LABEL_DEF",
+				Value: "topology.kubernetes.io/zone",
+			},
+		)
+	}
+	if ts.cfg.EKSConfig.IsEnabledSecurityGroupsForPods() {
+		// ref. https://docs.aws.amazon.com/eks/latest/userguide/security-groups-for-pods.html
+		envVars = append(envVars, v1.EnvVar{
+			Name:  "ENABLE_POD_ENI",
+			Value: "true",
+		})
+	}
 
 	dirOrCreate := v1.HostPathDirectoryOrCreate
 	podSpec := v1.PodTemplateSpec{
@@ -95,6 +95,7 @@ type loader struct {
 	donecCloseOnce *sync.Once
 
 	writeLatencies metrics.Durations
+	writesTook     time.Duration
 }
 
 func New(cfg Config) Loader {
@@ -107,7 +108,9 @@ func New(cfg Config) Loader {
 
 func (ld *loader) Start() {
 	ld.cfg.Logger.Info("starting write function", zap.String("namespace-write", ld.cfg.Namespace))
+	writesStart := time.Now()
 	ld.writeLatencies = startWrites(ld.cfg.Logger, ld.cfg.Client.KubernetesClientSet(), ld.cfg.ClientTimeout, ld.cfg.Namespace, ld.cfg.Objects, ld.cfg.ObjectSize, ld.cfg.Stopc, ld.donec)
+	ld.writesTook = time.Since(writesStart)
 	ld.cfg.Logger.Info("completed write function", zap.String("namespace-write", ld.cfg.Namespace))
 }
 
@@ -123,7 +126,7 @@ func (ld *loader) Stop() {
 // ref. https://pkg.go.dev/github.com/prometheus/client_golang@v1.6.0/prometheus/promhttp?tab=doc#Handler
 func (ts *loader) CollectMetrics() (writeLatencies metrics.Durations, writesSummary metrics.RequestsSummary, err error) {
 	curTS := time.Now().UTC().Format(time.RFC3339Nano)
-	writesSummary = metrics.RequestsSummary{TestID: curTS}
+	writesSummary = metrics.RequestsSummary{TestID: curTS, TotalDuration: ts.writesTook}
 
 	// https://pkg.go.dev/github.com/prometheus/client_golang/prometheus?tab=doc#Gatherer
 	mfs, err := prometheus.DefaultGatherer.Gather()
@@ -143,7 +146,7 @@ func (ts *loader) CollectMetrics() (writeLatencies metrics.Durations, writesSumm
 			gg := mf.Metric[0].GetGauge()
 			writesSummary.FailureTotal = gg.GetValue()
 		case "configmaps_client_write_request_latency_milliseconds":
-			writesSummary.LatencyHistogram, err = metrics.ParseHistogram("milliseconds", mf.Metric[0].GetHistogram())
+			writesSummary.LatencyHistogram, err = metrics.ParseHistogram(metrics.ScaleMilliseconds, mf.Metric[0].GetHistogram())
 			if err != nil {
 				return nil, metrics.RequestsSummary{}, err
 			}
@@ -156,6 +159,7 @@ func (ts *loader) CollectMetrics() (writeLatencies metrics.Durations, writesSumm
 	ts.cfg.Logger.Info("sorted write latency results", zap.Int("total-data-points", ts.writeLatencies.Len()), zap.String("took", time.Since(now).String()))
 	writesSummary.LantencyP50 = ts.writeLatencies.PickLantencyP50()
 	writesSummary.LantencyP90 = ts.writeLatencies.PickLantencyP90()
+	writesSummary.LantencyP95 = ts.writeLatencies.PickLantencyP95()
 	writesSummary.LantencyP99 = ts.writeLatencies.PickLantencyP99()
 	writesSummary.LantencyP999 = ts.writeLatencies.PickLantencyP999()
 	writesSummary.LantencyP9999 = ts.writeLatencies.PickLantencyP9999()
@@ -204,6 +204,7 @@ func (ts *tester) checkResults(curWriteLatencies metrics.Durations) (err error)
 			ts.cfg.EKSConfig.S3.BucketName,
 			ts.cfg.EKSConfig.AddOnConfigmapsLocal.RequestsSummaryWritesCompareJSONS3Key,
 			ts.cfg.EKSConfig.AddOnConfigmapsLocal.RequestsSummaryWritesCompareJSONPath,
+			aws_s3.WithTags(ts.cfg.EKSConfig.Tags),
 		); err != nil {
 			return err
 		}
@@ -217,6 +218,7 @@ func (ts *tester) checkResults(curWriteLatencies metrics.Durations) (err error)
 			ts.cfg.EKSConfig.S3.BucketName,
 			ts.cfg.EKSConfig.AddOnConfigmapsLocal.RequestsSummaryWritesCompareTableS3Key,
 			ts.cfg.EKSConfig.AddOnConfigmapsLocal.RequestsSummaryWritesCompareTablePath,
+			aws_s3.WithTags(ts.cfg.EKSConfig.Tags),
 		); err != nil {
 			return err
 		}
@@ -259,6 +261,7 @@ func (ts *tester) checkResults(curWriteLatencies metrics.Durations) (err error)
 			ts.cfg.EKSConfig.S3.BucketName,
 			ts.cfg.EKSConfig.AddOnConfigmapsLocal.RequestsRawWritesCompareAllJSONS3Key,
 			ts.cfg.EKSConfig.AddOnConfigmapsLocal.RequestsRawWritesCompareAllJSONPath,
+			aws_s3.WithTags(ts.cfg.EKSConfig.Tags),
 		); err != nil {
 			return err
 		}
@@ -271,6 +274,7 @@ func (ts *tester) checkResults(curWriteLatencies metrics.Durations) (err error)
 			ts.cfg.EKSConfig.S3.BucketName,
 			ts.cfg.EKSConfig.AddOnConfigmapsLocal.RequestsRawWritesCompareAllCSVS3Key,
 			ts.cfg.EKSConfig.AddOnConfigmapsLocal.RequestsRawWritesCompareAllCSVPath,
+			aws_s3.WithTags(ts.cfg.EKSConfig.Tags),
 		); err != nil {
 			return err
 		}
@@ -284,6 +288,7 @@ func (ts *tester) checkResults(curWriteLatencies metrics.Durations) (err error)
 		ts.cfg.EKSConfig.S3.BucketName,
 		path.Join(ts.cfg.EKSConfig.AddOnConfigmapsLocal.RequestsRawWritesCompareS3Dir, curTS),
 		ts.cfg.EKSConfig.AddOnConfigmapsLocal.RequestsRawWritesJSONPath,
+		aws_s3.WithTags(ts.cfg.EKSConfig.Tags),
 	); err != nil {
 		return err
 	}
@@ -293,6 +298,7 @@ func (ts *tester) checkResults(curWriteLatencies metrics.Durations) (err error)
 		ts.cfg.EKSConfig.S3.BucketName,
 		path.Join(ts.cfg.EKSConfig.AddOnConfigmapsLocal.RequestsSummaryWritesCompareS3Dir, curTS),
 		ts.cfg.EKSConfig.AddOnConfigmapsLocal.RequestsSummaryWritesJSONPath,
+		aws_s3.WithTags(ts.cfg.EKSConfig.Tags),
 	); err != nil {
 		return err
 	}
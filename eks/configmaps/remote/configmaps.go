@@ -816,6 +816,7 @@ func (ts *tester) checkResults() (err error) {
 			zap.String("s3-dir", path.Dir(ts.cfg.EKSConfig.AddOnConfigmapsRemote.RequestsSummaryWritesJSONS3Key)),
 		)
 		cnt := 0
+		var writesWorkerSummaries []metrics.RequestsSummary
 		err = filepath.Walk(writesDirSummary, func(fpath string, info os.FileInfo, werr error) error {
 			if werr != nil {
 				return werr
@@ -830,23 +831,21 @@ func (ts *tester) checkResults() (err error) {
 				if err != nil {
 					return fmt.Errorf("failed to open %q (%v)", fpath, err)
 				}
-				var r metrics.RequestsSummary
-				if err = json.Unmarshal(b, &r); err != nil {
-					return fmt.Errorf("failed to unmarshal %q (%s, %v)", fpath, string(b), err)
-				}
-				writesSummary.SuccessTotal += r.SuccessTotal
-				writesSummary.FailureTotal += r.FailureTotal
-				if writesSummary.LatencyHistogram == nil || len(writesSummary.LatencyHistogram) == 0 {
-					writesSummary.LatencyHistogram = r.LatencyHistogram
-				} else {
-					writesSummary.LatencyHistogram, err = metrics.MergeHistograms(writesSummary.LatencyHistogram, r.LatencyHistogram)
-					if err != nil {
-						return fmt.Errorf("failed to merge histograms (%v)", err)
-					}
+				r, err := metrics.ParseRequestsSummary(b)
+				if err != nil {
+					return fmt.Errorf("failed to parse %q (%s, %v)", fpath, string(b), err)
 				}
+				writesWorkerSummaries = append(writesWorkerSummaries, r)
 			}
 			return nil
 		})
+		if err == nil && cnt > 0 {
+			// merge all worker histograms at once, with the buckets aligned
+			writesSummary, err = metrics.CombineRequestsSummaries(append([]metrics.RequestsSummary{writesSummary}, writesWorkerSummaries...)...)
+			if err != nil {
+				err = fmt.Errorf("failed to combine requests summaries (%v)", err)
+			}
+		}
 		if err != nil || cnt == 0 {
 			ts.cfg.Logger.Warn("failed to read writes results", zap.Int("file-count", cnt), zap.Error(err))
 			os.RemoveAll(writesDirSummary)
@@ -860,6 +859,7 @@ func (ts *tester) checkResults() (err error) {
 	ts.cfg.Logger.Info("sorted write latencies", zap.String("took", time.Since(sortStart).String()))
 	writesSummary.LantencyP50 = curWriteLatencies.PickLantencyP50()
 	writesSummary.LantencyP90 = curWriteLatencies.PickLantencyP90()
+	writesSummary.LantencyP95 = curWriteLatencies.PickLantencyP95()
 	writesSummary.LantencyP99 = curWriteLatencies.PickLantencyP99()
 	writesSummary.LantencyP999 = curWriteLatencies.PickLantencyP999()
 	writesSummary.LantencyP9999 = curWriteLatencies.PickLantencyP9999()
@@ -881,6 +881,7 @@ func (ts *tester) checkResults() (err error) {
 		ts.cfg.EKSConfig.S3.BucketName,
 		ts.cfg.EKSConfig.AddOnConfigmapsRemote.RequestsRawWritesJSONS3Key,
 		ts.cfg.EKSConfig.AddOnConfigmapsRemote.RequestsRawWritesJSONPath,
+		aws_s3.WithTags(ts.cfg.EKSConfig.Tags),
 	); err != nil {
 		return err
 	}
@@ -894,6 +895,7 @@ func (ts *tester) checkResults() (err error) {
 		ts.cfg.EKSConfig.S3.BucketName,
 		ts.cfg.EKSConfig.AddOnConfigmapsRemote.RequestsSummaryWritesJSONS3Key,
 		ts.cfg.EKSConfig.AddOnConfigmapsRemote.RequestsSummaryWritesJSONPath,
+		aws_s3.WithTags(ts.cfg.EKSConfig.Tags),
 	); err != nil {
 		return err
 	}
@@ -907,6 +909,7 @@ func (ts *tester) checkResults() (err error) {
 		ts.cfg.EKSConfig.S3.BucketName,
 		ts.cfg.EKSConfig.AddOnConfigmapsRemote.RequestsSummaryWritesTableS3Key,
 		ts.cfg.EKSConfig.AddOnConfigmapsRemote.RequestsSummaryWritesTablePath,
+		aws_s3.WithTags(ts.cfg.EKSConfig.Tags),
 	); err != nil {
 		return err
 	}
@@ -947,6 +950,7 @@ func (ts *tester) checkResults() (err error) {
 			ts.cfg.EKSConfig.S3.BucketName,
 			ts.cfg.EKSConfig.AddOnConfigmapsRemote.RequestsSummaryWritesCompareJSONS3Key,
 			ts.cfg.EKSConfig.AddOnConfigmapsRemote.RequestsSummaryWritesCompareJSONPath,
+			aws_s3.WithTags(ts.cfg.EKSConfig.Tags),
 		); err != nil {
 			return err
 		}
@@ -960,6 +964,7 @@ func (ts *tester) checkResults() (err error) {
 			ts.cfg.EKSConfig.S3.BucketName,
 			ts.cfg.EKSConfig.AddOnConfigmapsRemote.RequestsSummaryWritesCompareTableS3Key,
 			ts.cfg.EKSConfig.AddOnConfigmapsRemote.RequestsSummaryWritesCompareTablePath,
+			aws_s3.WithTags(ts.cfg.EKSConfig.Tags),
 		); err != nil {
 			return err
 		}
@@ -1002,6 +1007,7 @@ func (ts *tester) checkResults() (err error) {
 			ts.cfg.EKSConfig.S3.BucketName,
 			ts.cfg.EKSConfig.AddOnConfigmapsRemote.RequestsRawWritesCompareAllJSONS3Key,
 			ts.cfg.EKSConfig.AddOnConfigmapsRemote.RequestsRawWritesCompareAllJSONPath,
+			aws_s3.WithTags(ts.cfg.EKSConfig.Tags),
 		); err != nil {
 			return err
 		}
@@ -1014,6 +1020,7 @@ func (ts *tester) checkResults() (err error) {
 			ts.cfg.EKSConfig.S3.BucketName,
 			ts.cfg.EKSConfig.AddOnConfigmapsRemote.RequestsRawWritesCompareAllCSVS3Key,
 			ts.cfg.EKSConfig.AddOnConfigmapsRemote.RequestsRawWritesCompareAllCSVPath,
+			aws_s3.WithTags(ts.cfg.EKSConfig.Tags),
 		); err != nil {
 			return err
 		}
@@ -1027,6 +1034,7 @@ func (ts *tester) checkResults() (err error) {
 		ts.cfg.EKSConfig.S3.BucketName,
 		path.Join(ts.cfg.EKSConfig.AddOnConfigmapsRemote.RequestsRawWritesCompareS3Dir, curTS),
 		ts.cfg.EKSConfig.AddOnConfigmapsRemote.RequestsRawWritesJSONPath,
+		aws_s3.WithTags(ts.cfg.EKSConfig.Tags),
 	); err != nil {
 		return err
 	}
@@ -1036,6 +1044,7 @@ func (ts *tester) checkResults() (err error) {
 		ts.cfg.EKSConfig.S3.BucketName,
 		path.Join(ts.cfg.EKSConfig.AddOnConfigmapsRemote.RequestsSummaryWritesCompareS3Dir, curTS),
 		ts.cfg.EKSConfig.AddOnConfigmapsRemote.RequestsSummaryWritesJSONPath,
+		aws_s3.WithTags(ts.cfg.EKSConfig.Tags),
 	); err != nil {
 		return err
 	}
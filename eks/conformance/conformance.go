@@ -517,6 +517,7 @@ func (ts *tester) checkResults() (err error) {
 		ts.cfg.EKSConfig.S3.BucketName,
 		ts.cfg.EKSConfig.AddOnConformance.SonobuoyResultTarGzS3Key,
 		ts.cfg.EKSConfig.AddOnConformance.SonobuoyResultTarGzPath,
+		aws_s3.WithTags(ts.cfg.EKSConfig.Tags),
 	); err != nil {
 		return err
 	}
@@ -526,6 +527,7 @@ func (ts *tester) checkResults() (err error) {
 		ts.cfg.EKSConfig.S3.BucketName,
 		ts.cfg.EKSConfig.AddOnConformance.SonobuoyResultE2eLogS3Key,
 		ts.cfg.EKSConfig.AddOnConformance.SonobuoyResultE2eLogPath,
+		aws_s3.WithTags(ts.cfg.EKSConfig.Tags),
 	); err != nil {
 		return err
 	}
@@ -535,6 +537,7 @@ func (ts *tester) checkResults() (err error) {
 		ts.cfg.EKSConfig.S3.BucketName,
 		ts.cfg.EKSConfig.AddOnConformance.SonobuoyResultJunitXMLS3Key,
 		ts.cfg.EKSConfig.AddOnConformance.SonobuoyResultJunitXMLPath,
+		aws_s3.WithTags(ts.cfg.EKSConfig.Tags),
 	); err != nil {
 		return err
 	}
@@ -183,6 +183,7 @@ func (ts *tester) checkResults(curWriteLatencies metrics.Durations) (err error)
 			ts.cfg.EKSConfig.S3.BucketName,
 			ts.cfg.EKSConfig.AddOnCSRsLocal.RequestsSummaryWritesCompareJSONS3Key,
 			ts.cfg.EKSConfig.AddOnCSRsLocal.RequestsSummaryWritesCompareJSONPath,
+			aws_s3.WithTags(ts.cfg.EKSConfig.Tags),
 		); err != nil {
 			return err
 		}
@@ -196,6 +197,7 @@ func (ts *tester) checkResults(curWriteLatencies metrics.Durations) (err error)
 			ts.cfg.EKSConfig.S3.BucketName,
 			ts.cfg.EKSConfig.AddOnCSRsLocal.RequestsSummaryWritesCompareTableS3Key,
 			ts.cfg.EKSConfig.AddOnCSRsLocal.RequestsSummaryWritesCompareTablePath,
+			aws_s3.WithTags(ts.cfg.EKSConfig.Tags),
 		); err != nil {
 			return err
 		}
@@ -238,6 +240,7 @@ func (ts *tester) checkResults(curWriteLatencies metrics.Durations) (err error)
 			ts.cfg.EKSConfig.S3.BucketName,
 			ts.cfg.EKSConfig.AddOnCSRsLocal.RequestsRawWritesCompareAllJSONS3Key,
 			ts.cfg.EKSConfig.AddOnCSRsLocal.RequestsRawWritesCompareAllJSONPath,
+			aws_s3.WithTags(ts.cfg.EKSConfig.Tags),
 		); err != nil {
 			return err
 		}
@@ -250,6 +253,7 @@ func (ts *tester) checkResults(curWriteLatencies metrics.Durations) (err error)
 			ts.cfg.EKSConfig.S3.BucketName,
 			ts.cfg.EKSConfig.AddOnCSRsLocal.RequestsRawWritesCompareAllCSVS3Key,
 			ts.cfg.EKSConfig.AddOnCSRsLocal.RequestsRawWritesCompareAllCSVPath,
+			aws_s3.WithTags(ts.cfg.EKSConfig.Tags),
 		); err != nil {
 			return err
 		}
@@ -263,6 +267,7 @@ func (ts *tester) checkResults(curWriteLatencies metrics.Durations) (err error)
 		ts.cfg.EKSConfig.S3.BucketName,
 		path.Join(ts.cfg.EKSConfig.AddOnCSRsLocal.RequestsRawWritesCompareS3Dir, curTS),
 		ts.cfg.EKSConfig.AddOnCSRsLocal.RequestsRawWritesJSONPath,
+		aws_s3.WithTags(ts.cfg.EKSConfig.Tags),
 	); err != nil {
 		return err
 	}
@@ -272,6 +277,7 @@ func (ts *tester) checkResults(curWriteLatencies metrics.Durations) (err error)
 		ts.cfg.EKSConfig.S3.BucketName,
 		path.Join(ts.cfg.EKSConfig.AddOnCSRsLocal.RequestsSummaryWritesCompareS3Dir, curTS),
 		ts.cfg.EKSConfig.AddOnCSRsLocal.RequestsSummaryWritesJSONPath,
+		aws_s3.WithTags(ts.cfg.EKSConfig.Tags),
 	); err != nil {
 		return err
 	}
@@ -10,6 +10,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/aws/aws-k8s-tester/eks/gpu"
 	eks_tester "github.com/aws/aws-k8s-tester/eks/tester"
 	"github.com/aws/aws-k8s-tester/eksconfig"
 	k8s_client "github.com/aws/aws-k8s-tester/pkg/k8s-client"
@@ -75,6 +76,14 @@ func (ts *tester) Create() (err error) {
 	); err != nil {
 		return err
 	}
+	// the GPU AMI ships the NVIDIA driver, but the pod is unschedulable
+	// until the device plugin advertises "nvidia.com/gpu" on the nodes
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	err = gpu.WaitForGPUAllocatable(ctx, ts.cfg.Logger, ts.cfg.Stopc, ts.cfg.K8SClient, ts.cfg.EKSConfig.GPUNodeGroups())
+	cancel()
+	if err != nil {
+		return fmt.Errorf("GPUs not allocatable (%v)", err)
+	}
 	if err = ts.createPod(); err != nil {
 		return err
 	}
@@ -105,11 +114,18 @@ func (ts *tester) createPod() (err error) {
 					Image: "k8s.gcr.io/cuda-vector-add:v0.1",
 					Resources: v1.ResourceRequirements{
 						Limits: map[v1.ResourceName]resource.Quantity{
-							v1.ResourceName("nvidia.com/gpu"): resource.MustParse("1"),
+							gpu.ResourceNvidiaGPU: resource.MustParse("1"),
 						},
 					},
 				},
 			},
+			Tolerations: []v1.Toleration{
+				{
+					Key:      string(gpu.ResourceNvidiaGPU),
+					Operator: v1.TolerationOpExists,
+					Effect:   v1.TaintEffectNoSchedule,
+				},
+			},
 		},
 	}
 
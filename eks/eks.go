@@ -23,6 +23,7 @@ import (
 	"time"
 
 	"github.com/aws/aws-k8s-tester/ec2config"
+	access_entries "github.com/aws/aws-k8s-tester/eks/access-entries"
 	alb_2048 "github.com/aws/aws-k8s-tester/eks/alb-2048"
 	ami_soft_lockup_issue_454 "github.com/aws/aws-k8s-tester/eks/amazon-eks-ami-issue-454"
 	app_mesh "github.com/aws/aws-k8s-tester/eks/app-mesh"
@@ -45,6 +46,7 @@ import (
 	"github.com/aws/aws-k8s-tester/eks/fargate"
 	"github.com/aws/aws-k8s-tester/eks/fluentd"
 	"github.com/aws/aws-k8s-tester/eks/gpu"
+	"github.com/aws/aws-k8s-tester/eks/ipv6"
 	"github.com/aws/aws-k8s-tester/eks/irsa"
 	irsa_fargate "github.com/aws/aws-k8s-tester/eks/irsa-fargate"
 	jobs_echo "github.com/aws/aws-k8s-tester/eks/jobs-echo"
@@ -52,22 +54,28 @@ import (
 	jupyter_hub "github.com/aws/aws-k8s-tester/eks/jupyter-hub"
 	"github.com/aws/aws-k8s-tester/eks/kubeflow"
 	kubernetes_dashboard "github.com/aws/aws-k8s-tester/eks/kubernetes-dashboard"
+	managed_addons "github.com/aws/aws-k8s-tester/eks/managed-addons"
 	metrics_server "github.com/aws/aws-k8s-tester/eks/metrics-server"
 	"github.com/aws/aws-k8s-tester/eks/mng"
+	mng_rolling_upgrade "github.com/aws/aws-k8s-tester/eks/mng-rolling-upgrade"
+	mng_scale "github.com/aws/aws-k8s-tester/eks/mng-scale"
 	"github.com/aws/aws-k8s-tester/eks/neuron"
 	"github.com/aws/aws-k8s-tester/eks/ng"
 	nlb_guestbook "github.com/aws/aws-k8s-tester/eks/nlb-guestbook"
 	nlb_hello_world "github.com/aws/aws-k8s-tester/eks/nlb-hello-world"
+	"github.com/aws/aws-k8s-tester/eks/oidc"
 	"github.com/aws/aws-k8s-tester/eks/overprovisioning"
 	php_apache "github.com/aws/aws-k8s-tester/eks/php-apache"
 	prometheus_grafana "github.com/aws/aws-k8s-tester/eks/prometheus-grafana"
 	secrets_local "github.com/aws/aws-k8s-tester/eks/secrets/local"
 	secrets_remote "github.com/aws/aws-k8s-tester/eks/secrets/remote"
+	spot_interruption "github.com/aws/aws-k8s-tester/eks/spot-interruption"
 	stresser_local "github.com/aws/aws-k8s-tester/eks/stresser/local"
 	stresser_remote "github.com/aws/aws-k8s-tester/eks/stresser/remote"
 	stresser_remote_v2 "github.com/aws/aws-k8s-tester/eks/stresser2"
 	eks_tester "github.com/aws/aws-k8s-tester/eks/tester"
 	"github.com/aws/aws-k8s-tester/eks/trainium"
+	windows_smoke "github.com/aws/aws-k8s-tester/eks/windows-smoke"
 	"github.com/aws/aws-k8s-tester/eks/wordpress"
 	"github.com/aws/aws-k8s-tester/eksconfig"
 	pkg_aws "github.com/aws/aws-k8s-tester/pkg/aws"
@@ -100,6 +108,8 @@ import (
 	"github.com/aws/aws-sdk-go/service/cloudformation/cloudformationiface"
 	"github.com/aws/aws-sdk-go/service/cloudwatch"
 	"github.com/aws/aws-sdk-go/service/cloudwatch/cloudwatchiface"
+	"github.com/aws/aws-sdk-go/service/cloudwatchlogs"
+	"github.com/aws/aws-sdk-go/service/cloudwatchlogs/cloudwatchlogsiface"
 	"github.com/aws/aws-sdk-go/service/ec2"
 	"github.com/aws/aws-sdk-go/service/ec2/ec2iface"
 	"github.com/aws/aws-sdk-go/service/ecr"
@@ -108,12 +118,18 @@ import (
 	"github.com/aws/aws-sdk-go/service/eks/eksiface"
 	"github.com/aws/aws-sdk-go/service/elbv2"
 	"github.com/aws/aws-sdk-go/service/elbv2/elbv2iface"
+	"github.com/aws/aws-sdk-go/service/fis"
+	"github.com/aws/aws-sdk-go/service/fis/fisiface"
 	"github.com/aws/aws-sdk-go/service/iam"
 	"github.com/aws/aws-sdk-go/service/iam/iamiface"
 	"github.com/aws/aws-sdk-go/service/kms"
 	"github.com/aws/aws-sdk-go/service/kms/kmsiface"
+	"github.com/aws/aws-sdk-go/service/lambda"
+	"github.com/aws/aws-sdk-go/service/lambda/lambdaiface"
 	"github.com/aws/aws-sdk-go/service/s3"
 	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+	"github.com/aws/aws-sdk-go/service/servicequotas"
+	"github.com/aws/aws-sdk-go/service/servicequotas/servicequotasiface"
 	"github.com/aws/aws-sdk-go/service/ssm"
 	"github.com/aws/aws-sdk-go/service/ssm/ssmiface"
 	"github.com/aws/aws-sdk-go/service/sts"
@@ -158,9 +174,15 @@ type Tester struct {
 	kmsAPI   kmsiface.KMSAPI
 	kmsAPIV2 *aws_kms_v2.Client
 
+	lambdaAPI lambdaiface.LambdaAPI
+
+	fisAPI fisiface.FISAPI
+
 	ssmAPI   ssmiface.SSMAPI
 	ssmAPIV2 *aws_ssm_v2.Client
 
+	sqAPI servicequotasiface.ServiceQuotasAPI
+
 	cfnAPI   cloudformationiface.CloudFormationAPI
 	cfnAPIV2 *aws_cfn_v2.Client
 
@@ -173,6 +195,8 @@ type Tester struct {
 	cwAPI   cloudwatchiface.CloudWatchAPI
 	cwAPIV2 *aws_cw_v2.Client
 
+	logsAPI cloudwatchlogsiface.CloudWatchLogsAPI
+
 	asgAPI   autoscalingiface.AutoScalingAPI
 	asgAPIV2 *aws_asg_v2.Client
 
@@ -191,6 +215,7 @@ type Tester struct {
 	s3Uploaded bool
 
 	clusterTester cluster.Tester
+	oidcProvider  *oidc.Provider
 	k8sClient     k8s_client.EKS
 
 	// only create/install, no need delete
@@ -385,9 +410,15 @@ func New(cfg *eksconfig.Config) (ts *Tester, err error) {
 	ts.kmsAPI = kms.New(ts.awsSession)
 	ts.kmsAPIV2 = aws_kms_v2.NewFromConfig(awsCfgV2)
 
+	ts.lambdaAPI = lambda.New(ts.awsSession)
+
+	ts.fisAPI = fis.New(ts.awsSession)
+
 	ts.ssmAPI = ssm.New(ts.awsSession)
 	ts.ssmAPIV2 = aws_ssm_v2.NewFromConfig(awsCfgV2)
 
+	ts.sqAPI = servicequotas.New(ts.awsSession)
+
 	ts.cfnAPI = cloudformation.New(ts.awsSession)
 	ts.cfnAPIV2 = aws_cfn_v2.NewFromConfig(awsCfgV2)
 
@@ -506,6 +537,8 @@ func New(cfg *eksconfig.Config) (ts *Tester, err error) {
 	ts.cwAPI = cloudwatch.New(ts.awsSession)
 	ts.cwAPIV2 = aws_cw_v2.NewFromConfig(awsCfgV2)
 
+	ts.logsAPI = cloudwatchlogs.New(ts.awsSession)
+
 	ts.asgAPI = autoscaling.New(ts.awsSession)
 	ts.asgAPIV2 = aws_asg_v2.NewFromConfig(awsCfgV2)
 
@@ -692,11 +725,16 @@ func (ts *Tester) createTesters() (err error) {
 		S3APIV2:    ts.s3APIV2,
 		IAMAPIV2:   ts.iamAPIV2,
 		KMSAPIV2:   ts.kmsAPIV2,
-		CFNAPI:     ts.cfnAPI,
 		EC2APIV2:   ts.ec2APIV2,
 		EKSAPI:     ts.eksAPIForCluster,
 		EKSAPIV2:   ts.eksAPIForClusterV2,
 		ELBV2APIV2: ts.elbv2APIV2,
+		LogsAPI:    ts.logsAPI,
+	})
+	ts.oidcProvider = oidc.New(oidc.Config{
+		Logger:    ts.lg,
+		EKSConfig: ts.cfg,
+		IAMAPI:    ts.iamAPI,
 	})
 
 	ts.cniTester = cni_vpc.New(cni_vpc.Config{
@@ -719,6 +757,7 @@ func (ts *Tester) createTesters() (err error) {
 		SSMAPIV2: ts.ssmAPIV2,
 		EC2APIV2: ts.ec2APIV2,
 		ASGAPIV2: ts.asgAPIV2,
+		EKSAPI:   ts.eksAPIForCluster,
 	})
 	ts.mngTester = mng.New(mng.Config{
 		Logger:    ts.lg,
@@ -732,6 +771,7 @@ func (ts *Tester) createTesters() (err error) {
 		ASGAPIV2: ts.asgAPIV2,
 		EKSAPI:   ts.eksAPIForMNG,
 		EKSAPIV2: ts.eksAPIForMNGV2,
+		SSMAPI:   ts.ssmAPI,
 
 		CFNAPI: ts.cfnAPI,
 	})
@@ -972,7 +1012,6 @@ func (ts *Tester) createTesters() (err error) {
 			K8SClient: ts.k8sClient,
 			S3API:     ts.s3API,
 			CFNAPI:    ts.cfnAPI,
-			IAMAPI:    ts.iamAPI,
 			ECRAPI:    ecr.New(ts.awsSession, aws.NewConfig().WithRegion(ts.cfg.GetAddOnIRSARepositoryRegion())),
 		}),
 		irsa_fargate.New(irsa_fargate.Config{
@@ -982,7 +1021,6 @@ func (ts *Tester) createTesters() (err error) {
 			EKSConfig: ts.cfg,
 			K8SClient: ts.k8sClient,
 			S3API:     ts.s3API,
-			IAMAPI:    ts.iamAPI,
 			CFNAPI:    ts.cfnAPI,
 			EKSAPI:    ts.eksAPIForCluster,
 			ECRAPI:    ecr.New(ts.awsSession, aws.NewConfig().WithRegion(ts.cfg.GetAddOnIRSAFargateRepositoryRegion())),
@@ -1017,6 +1055,64 @@ func (ts *Tester) createTesters() (err error) {
 			EKSConfig: ts.cfg,
 			K8SClient: ts.k8sClient,
 		}),
+		windows_smoke.New(windows_smoke.Config{
+			Logger:    ts.lg,
+			LogWriter: ts.logWriter,
+			Stopc:     ts.stopCreationCh,
+			EKSConfig: ts.cfg,
+			K8SClient: ts.k8sClient,
+		}),
+		ipv6.New(ipv6.Config{
+			Logger:    ts.lg,
+			LogWriter: ts.logWriter,
+			Stopc:     ts.stopCreationCh,
+			EKSConfig: ts.cfg,
+			K8SClient: ts.k8sClient,
+			ELB2API:   ts.elbv2API,
+		}),
+		access_entries.New(access_entries.Config{
+			Logger:    ts.lg,
+			LogWriter: ts.logWriter,
+			Stopc:     ts.stopCreationCh,
+			EKSConfig: ts.cfg,
+			K8SClient: ts.k8sClient,
+			EKSAPI:    ts.eksAPIForCluster,
+			IAMAPI:    ts.iamAPI,
+		}),
+		managed_addons.New(managed_addons.Config{
+			Logger:    ts.lg,
+			LogWriter: ts.logWriter,
+			Stopc:     ts.stopCreationCh,
+			EKSConfig: ts.cfg,
+			K8SClient: ts.k8sClient,
+			EKSAPI:    ts.eksAPIForCluster,
+		}),
+		spot_interruption.New(spot_interruption.Config{
+			Logger:    ts.lg,
+			LogWriter: ts.logWriter,
+			Stopc:     ts.stopCreationCh,
+			EKSConfig: ts.cfg,
+			K8SClient: ts.k8sClient,
+			IAMAPI:    ts.iamAPI,
+			FISAPI:    ts.fisAPI,
+			ASGAPI:    ts.asgAPI,
+		}),
+		mng_scale.New(mng_scale.Config{
+			Logger:    ts.lg,
+			LogWriter: ts.logWriter,
+			Stopc:     ts.stopCreationCh,
+			EKSConfig: ts.cfg,
+			K8SClient: ts.k8sClient,
+			EKSAPI:    ts.eksAPIForMNG,
+		}),
+		mng_rolling_upgrade.New(mng_rolling_upgrade.Config{
+			Logger:    ts.lg,
+			LogWriter: ts.logWriter,
+			Stopc:     ts.stopCreationCh,
+			EKSConfig: ts.cfg,
+			K8SClient: ts.k8sClient,
+			EKSAPI:    ts.eksAPIForMNG,
+		}),
 		cluster_loader_local.New(cluster_loader_local.Config{
 			Logger:    ts.lg,
 			LogWriter: ts.logWriter,
@@ -1078,6 +1174,9 @@ func (ts *Tester) createTesters() (err error) {
 			K8SClient: ts.k8sClient,
 		}),
 	}
+	if err = ts.orderTesters(); err != nil {
+		return err
+	}
 	if serr := ts.cfg.Sync(); serr != nil {
 		fmt.Fprintf(ts.logWriter, ts.color("[light_magenta]cfg.Sync failed [default]%v\n"), serr)
 	}
@@ -1085,6 +1184,55 @@ func (ts *Tester) createTesters() (err error) {
 	return nil
 }
 
+// orderTesters reorders the add-on testers so that the add-ons are created
+// after the add-ons that they require (see "eksconfig.AddOnDependencies").
+// The add-on name is derived from the tester package path (see "testerAddOnName").
+// Testers that are not part of any add-on dependency (e.g. node groups)
+// keep their positions, and the add-on testers are reordered among their slots.
+func (ts *Tester) orderTesters() error {
+	related := make(map[string]bool)
+	for name, deps := range eksconfig.AddOnDependencies {
+		related[name] = true
+		for _, dep := range deps {
+			related[dep] = true
+		}
+	}
+	cnt := make(map[string]int, len(ts.testers))
+	for _, cur := range ts.testers {
+		cnt["add-on-"+testerAddOnName(cur.Name())]++
+	}
+
+	var slots []int
+	var names []string
+	byName := make(map[string]eks_tester.Tester)
+	for idx, cur := range ts.testers {
+		name := "add-on-" + testerAddOnName(cur.Name())
+		if !related[name] || cnt[name] != 1 {
+			continue
+		}
+		slots = append(slots, idx)
+		names = append(names, name)
+		byName[name] = cur
+	}
+	ordered, err := eksconfig.OrderAddOns(names)
+	if err != nil {
+		return err
+	}
+	for i, name := range ordered {
+		ts.testers[slots[i]] = byName[name]
+	}
+	return nil
+}
+
+// testerAddOnName returns the add-on name of the tester, from its package path
+// (e.g. "jobs-pi" for ".../eks/jobs-pi", "csrs-local" for ".../eks/csrs/local").
+func testerAddOnName(testerName string) string {
+	if idx := strings.LastIndex(testerName, "/eks/"); idx >= 0 {
+		testerName = testerName[idx+len("/eks/"):]
+	}
+	return strings.ReplaceAll(testerName, "/", "-")
+}
+
 // Up should provision a new cluster for testing.
 // ref. https://pkg.go.dev/k8s.io/test-infra/kubetest2/pkg/types?tab=doc#Deployer
 // ref. https://pkg.go.dev/k8s.io/test-infra/kubetest2/pkg/types?tab=doc#Options
@@ -1214,6 +1362,29 @@ func (ts *Tester) Up() (err error) {
 	)
 	defer ts.cfg.Sync()
 
+	fmt.Fprint(ts.logWriter, ts.color("\n\n[yellow]*********************************\n"))
+	fmt.Fprintf(ts.logWriter, ts.color("[light_green]validateQuotas [default](%q)\n"), ts.cfg.ConfigPath)
+	if err := ts.validateQuotas(); err != nil {
+		return err
+	}
+
+	// serve the metrics while the tester runs, including cluster creation
+	if ts.cfg.IsEnabledPrometheusEndpoint() {
+		srv, err := ts.startPrometheusEndpoint()
+		if err != nil {
+			return err
+		}
+		defer srv.Stop()
+	}
+	// push the metrics while the tester runs, and the final ones on return
+	if ts.cfg.IsEnabledOTLPExporter() {
+		e, err := ts.startOTLPExporter()
+		if err != nil {
+			return err
+		}
+		defer e.Stop()
+	}
+
 	fmt.Fprint(ts.logWriter, ts.color("\n\n[yellow]*********************************\n"))
 	fmt.Fprintf(ts.logWriter, ts.color("[light_green]createS3 [default](%q)\n"), ts.cfg.ConfigPath)
 	if err := catchInterrupt(
@@ -1240,6 +1411,14 @@ func (ts *Tester) Up() (err error) {
 		return err
 	}
 
+	if ts.cfg.IsEnabledEndpointPublicAccessCheck() && ts.cfg.EndpointPublicAccessCheck.AddRunnerCIDR {
+		fmt.Fprint(ts.logWriter, ts.color("\n\n[yellow]*********************************\n"))
+		fmt.Fprintf(ts.logWriter, ts.color("[light_green]addRunnerCIDR [default](%q)\n"), ts.cfg.ConfigPath)
+		if err := ts.addRunnerCIDR(); err != nil {
+			return err
+		}
+	}
+
 	fmt.Fprint(ts.logWriter, ts.color("\n\n[yellow]*********************************\n"))
 	fmt.Fprintf(ts.logWriter, ts.color("[light_green]createCluster [default](%q, %q)\n"), ts.cfg.ConfigPath, ts.cfg.KubectlCommand())
 	if err := catchInterrupt(
@@ -1257,6 +1436,14 @@ func (ts *Tester) Up() (err error) {
 		return err
 	}
 
+	if ts.cfg.IsEnabledOIDCProvider() {
+		fmt.Fprint(ts.logWriter, ts.color("\n\n[yellow]*********************************\n"))
+		fmt.Fprintf(ts.logWriter, ts.color("[light_green]oidcProvider.Create [default](%q)\n"), ts.cfg.ConfigPath)
+		if err := ts.oidcProvider.Create(); err != nil {
+			return err
+		}
+	}
+
 	if ts.cfg.KubeControllerManagerQPS != "" &&
 		ts.cfg.KubeControllerManagerBurst != "" &&
 		ts.cfg.KubeSchedulerQPS != "" &&
@@ -1332,6 +1519,22 @@ func (ts *Tester) Up() (err error) {
 		}
 	}
 
+	if ts.cfg.IsEnabledCNICustomNetworking() {
+		fmt.Fprint(ts.logWriter, ts.color("\n\n[yellow]*********************************\n"))
+		fmt.Fprintf(ts.logWriter, ts.color("[light_green]configureCNICustomNetworking [default](%q)\n"), ts.cfg.ConfigPath)
+		if err := ts.configureCNICustomNetworking(); err != nil {
+			return err
+		}
+	}
+
+	if ts.cfg.IsEnabledSecurityGroupsForPods() {
+		fmt.Fprint(ts.logWriter, ts.color("\n\n[yellow]*********************************\n"))
+		fmt.Fprintf(ts.logWriter, ts.color("[light_green]enablePodENI [default](%q)\n"), ts.cfg.ConfigPath)
+		if err := ts.enablePodENI(); err != nil {
+			return err
+		}
+	}
+
 	if ts.cfg.IsEnabledAddOnNodeGroups() {
 		if ts.ngTester == nil {
 			return errors.New("ts.ngTester == nil when AddOnNodeGroups.Enable == true")
@@ -1371,6 +1574,54 @@ func (ts *Tester) Up() (err error) {
 		}
 	}
 
+	if ts.cfg.IsEnabledVersionSkew() {
+		fmt.Fprint(ts.logWriter, ts.color("\n\n[yellow]*********************************\n"))
+		fmt.Fprintf(ts.logWriter, ts.color("[light_green]checkVersionSkew [default](%q, %q)\n"), ts.cfg.ConfigPath, "create")
+		if err := ts.checkVersionSkew("create"); err != nil {
+			return err
+		}
+	}
+
+	if len(ts.cfg.Tags) > 0 {
+		fmt.Fprint(ts.logWriter, ts.color("\n\n[yellow]*********************************\n"))
+		fmt.Fprintf(ts.logWriter, ts.color("[light_green]checkTags [default](%q)\n"), ts.cfg.ConfigPath)
+		if err := ts.checkTags(); err != nil {
+			return err
+		}
+	}
+
+	if ts.cfg.IsEnabledCNICustomNetworking() {
+		fmt.Fprint(ts.logWriter, ts.color("\n\n[yellow]*********************************\n"))
+		fmt.Fprintf(ts.logWriter, ts.color("[light_green]checkCNICustomNetworking [default](%q)\n"), ts.cfg.ConfigPath)
+		if err := ts.checkCNICustomNetworking(); err != nil {
+			return err
+		}
+	}
+
+	if ts.cfg.IsEnabledSecurityGroupsForPods() {
+		fmt.Fprint(ts.logWriter, ts.color("\n\n[yellow]*********************************\n"))
+		fmt.Fprintf(ts.logWriter, ts.color("[light_green]checkSecurityGroupsForPods [default](%q)\n"), ts.cfg.ConfigPath)
+		if err := ts.checkSecurityGroupsForPods(); err != nil {
+			return err
+		}
+	}
+
+	if ts.cfg.IsEnabledEndpointPublicAccessCheck() {
+		fmt.Fprint(ts.logWriter, ts.color("\n\n[yellow]*********************************\n"))
+		fmt.Fprintf(ts.logWriter, ts.color("[light_green]checkEndpointPublicAccess [default](%q)\n"), ts.cfg.ConfigPath)
+		if err := ts.checkEndpointPublicAccess(); err != nil {
+			return err
+		}
+	}
+
+	if ts.cfg.IsEnabledKMSRotation() {
+		fmt.Fprint(ts.logWriter, ts.color("\n\n[yellow]*********************************\n"))
+		fmt.Fprintf(ts.logWriter, ts.color("[light_green]runKMSRotation [default](%q)\n"), ts.cfg.ConfigPath)
+		if err := ts.runKMSRotation(); err != nil {
+			return err
+		}
+	}
+
 	needGPU := false
 	if ts.cfg.IsEnabledAddOnNodeGroups() {
 	gpuFound1:
@@ -1394,30 +1645,30 @@ func (ts *Tester) Up() (err error) {
 	}
 	if needGPU {
 		fmt.Fprint(ts.logWriter, ts.color("\n\n[yellow]*********************************\n"))
-		fmt.Fprintf(ts.logWriter, ts.color("[light_green]gpuTester.DeployMPIOperator [default](%q, %q)\n"), ts.cfg.ConfigPath, ts.cfg.KubectlCommand())
+		fmt.Fprintf(ts.logWriter, ts.color("[light_green]gpuTester.InstallNvidiaDriver [default](%q, %q)\n"), ts.cfg.ConfigPath, ts.cfg.KubectlCommand())
 		if err := catchInterrupt(
 			ts.lg,
 			ts.stopCreationCh,
 			ts.stopCreationChOnce,
 			ts.osSig,
-			ts.gpuTester.DeployMPIOperator,
+			ts.gpuTester.InstallNvidiaDriver,
 			ts.gpuTester.Name(),
 		); err != nil {
-			ts.lg.Warn("failed to deploy MPI", zap.Error(err))
+			ts.lg.Warn("failed to install nvidia driver", zap.Error(err))
 			return err
 		}
 
 		fmt.Fprint(ts.logWriter, ts.color("\n\n[yellow]*********************************\n"))
-		fmt.Fprintf(ts.logWriter, ts.color("[light_green]gpuTester.InstallNvidiaDriver [default](%q, %q)\n"), ts.cfg.ConfigPath, ts.cfg.KubectlCommand())
+		fmt.Fprintf(ts.logWriter, ts.color("[light_green]gpuTester.DeployMPIOperator [default](%q, %q)\n"), ts.cfg.ConfigPath, ts.cfg.KubectlCommand())
 		if err := catchInterrupt(
 			ts.lg,
 			ts.stopCreationCh,
 			ts.stopCreationChOnce,
 			ts.osSig,
-			ts.gpuTester.InstallNvidiaDriver,
+			ts.gpuTester.DeployMPIOperator,
 			ts.gpuTester.Name(),
 		); err != nil {
-			ts.lg.Warn("failed to install nvidia driver", zap.Error(err))
+			ts.lg.Warn("failed to deploy MPI", zap.Error(err))
 			return err
 		}
 
@@ -1526,9 +1777,16 @@ func (ts *Tester) Up() (err error) {
 		ts.lg.Warn("failed to upload artifacts to S3", zap.Error(serr))
 	}
 
+	if ts.cfg.IsEnabledLiveReload() {
+		liveReloadDonec := make(chan struct{})
+		defer close(liveReloadDonec)
+		go ts.startLiveReload(liveReloadDonec)
+	}
+
 	for idx, cur := range ts.testers {
 		fmt.Fprint(ts.logWriter, ts.color("\n\n[yellow]*********************************\n"))
 		fmt.Fprintf(ts.logWriter, ts.color("[light_green]testers[%02d].Create [cyan]%q [default](%q, %q)\n"), idx, cur.Name(), ts.cfg.ConfigPath, ts.cfg.KubectlCommand())
+		createdAddOns := ts.cfg.CreatedAddOns()
 		err := catchInterrupt(
 			ts.lg,
 			ts.stopCreationCh,
@@ -1537,6 +1795,12 @@ func (ts *Tester) Up() (err error) {
 			cur.Create,
 			cur.Name(),
 		)
+		if err != nil {
+			if failed := ts.cfg.SetAddOnsCreateFailed(createdAddOns); len(failed) > 0 {
+				ts.lg.Warn("add-on creation failed", zap.String("tester", cur.Name()), zap.Strings("add-ons", failed))
+				ts.cfg.Sync()
+			}
+		}
 
 		if idx%10 == 0 {
 			fmt.Fprint(ts.logWriter, ts.color("\n\n[yellow]*********************************\n"))
@@ -1568,6 +1832,50 @@ func (ts *Tester) Up() (err error) {
 		}
 	}
 
+	if ts.cfg.IsEnabledAddOnClusterVersionUpgrade() && len(ts.cfg.AddOnClusterVersionUpgrade.RerunAddOns) > 0 {
+		fmt.Fprint(ts.logWriter, ts.color("\n\n[yellow]*********************************\n"))
+		fmt.Fprintf(ts.logWriter, ts.color("[light_green]rerunAddOns [default](%q, %q)\n"), ts.cfg.ConfigPath, ts.cfg.AddOnClusterVersionUpgrade.RerunAddOns)
+		if err := ts.rerunAddOns(); err != nil {
+			return err
+		}
+	}
+
+	if ts.cfg.IsEnabledAutoMode() {
+		fmt.Fprint(ts.logWriter, ts.color("\n\n[yellow]*********************************\n"))
+		fmt.Fprintf(ts.logWriter, ts.color("[light_green]checkAutoModeCapacity [default](%q)\n"), ts.cfg.ConfigPath)
+		if err := ts.checkAutoModeCapacity(); err != nil {
+			return err
+		}
+	}
+
+	fmt.Fprint(ts.logWriter, ts.color("\n\n[yellow]*********************************\n"))
+	fmt.Fprintf(ts.logWriter, ts.color("[light_green]uploadSummaryCSVs [default](%q)\n"), ts.cfg.ConfigPath)
+	if err := ts.uploadSummaryCSVs(); err != nil {
+		ts.lg.Warn("failed to upload summary CSVs", zap.Error(err))
+	}
+
+	if ts.cfg.IsEnabledCWSummaries() {
+		fmt.Fprint(ts.logWriter, ts.color("\n\n[yellow]*********************************\n"))
+		fmt.Fprintf(ts.logWriter, ts.color("[light_green]publishSummaries [default](%q)\n"), ts.cfg.ConfigPath)
+		if err := ts.publishSummaries(); err != nil {
+			ts.lg.Warn("failed to publish summaries to CloudWatch", zap.Error(err))
+		}
+	}
+
+	fmt.Fprint(ts.logWriter, ts.color("\n\n[yellow]*********************************\n"))
+	fmt.Fprintf(ts.logWriter, ts.color("[light_green]checkSLOs [default](%q)\n"), ts.cfg.ConfigPath)
+	if err := ts.checkSLOs(); err != nil {
+		return err
+	}
+
+	if ts.cfg.IsEnabledRegression() {
+		fmt.Fprint(ts.logWriter, ts.color("\n\n[yellow]*********************************\n"))
+		fmt.Fprintf(ts.logWriter, ts.color("[light_green]checkRegressions [default](%q)\n"), ts.cfg.ConfigPath)
+		if err := ts.checkRegressions(); err != nil {
+			return err
+		}
+	}
+
 	if ts.cfg.IsEnabledAddOnNodeGroups() && ts.cfg.AddOnNodeGroups.Created && ts.cfg.AddOnNodeGroups.FetchLogs {
 		if ts.ngTester == nil {
 			return errors.New("ts.ngTester == nil when AddOnNodeGroups.Enable == true")
@@ -1702,6 +2010,14 @@ func (ts *Tester) Up() (err error) {
 		}
 	}
 
+	if ts.cfg.IsEnabledVersionSkew() && (ts.cfg.IsEnabledAddOnClusterVersionUpgrade() || logFetchAgain) {
+		fmt.Fprint(ts.logWriter, ts.color("\n\n[yellow]*********************************\n"))
+		fmt.Fprintf(ts.logWriter, ts.color("[light_green]checkVersionSkew [default](%q, %q)\n"), ts.cfg.ConfigPath, "upgrade")
+		if err := ts.checkVersionSkew("upgrade"); err != nil {
+			return err
+		}
+	}
+
 	if logFetchAgain && ts.cfg.IsEnabledAddOnManagedNodeGroups() && ts.cfg.AddOnManagedNodeGroups.Created && ts.cfg.AddOnManagedNodeGroups.FetchLogs {
 		if ts.mngTester == nil {
 			return errors.New("ts.mngTester == nil when AddOnManagedNodeGroups.Enable == true")
@@ -1772,6 +2088,49 @@ func (ts *Tester) Down() error {
 	return ts.down()
 }
 
+// deleteTesters deletes the add-on testers in the reverse order of creation,
+// and returns the delete errors. The add-ons retained by their cleanup
+// policies (see "eksconfig.Config.IsRetainedAddOn") are not deleted.
+func (ts *Tester) deleteTesters() (errs []string) {
+	testersN := len(ts.testers)
+	for idx := range ts.testers {
+		idx = testersN - idx - 1
+		cur := ts.testers[idx]
+		fmt.Fprint(ts.logWriter, ts.color("\n\n[yellow]*********************************\n"))
+		if name := "add-on-" + testerAddOnName(cur.Name()); ts.cfg.IsRetainedAddOn(name) {
+			fmt.Fprintf(ts.logWriter, ts.color("[light_yellow]SKIP [light_blue]testers[%02d].Delete [cyan]%q [default](cleanup policy, %q)\n"), idx, cur.Name(), ts.cfg.ConfigPath)
+			ts.lg.Warn("retaining add-on resources for cleanup policy", zap.String("add-on", name))
+			continue
+		}
+		fmt.Fprintf(ts.logWriter, ts.color("[light_blue]testers[%02d].Delete [cyan]%q [default](%q, %q)\n"), idx, cur.Name(), ts.cfg.ConfigPath, ts.cfg.KubectlCommand())
+		if err := cur.Delete(); err != nil {
+			ts.lg.Warn("failed tester.Delete", zap.Error(err))
+			errs = append(errs, err.Error())
+		}
+	}
+	return errs
+}
+
+// skipDeleteClusterReason returns why the key pair, node groups, and cluster
+// must not be deleted, or an empty string to delete them. The add-ons retained
+// by their cleanup policies are recorded in "Status.RetainedAddOns", since
+// deleting the cluster would delete their resources with it.
+func (ts *Tester) skipDeleteClusterReason() string {
+	if ts.cfg.SkipDeleteClusterAndNodes {
+		return "SkipDeleteClusterAndNodes 'true'"
+	}
+	ts.cfg.Status.RetainedAddOns = ts.cfg.RetainedAddOns()
+	if len(ts.cfg.Status.RetainedAddOns) == 0 {
+		return ""
+	}
+	ts.lg.Warn("retaining cluster for retained add-ons; set their cleanup policy to 'delete' and re-run delete to clean up",
+		zap.Strings("add-ons", ts.cfg.Status.RetainedAddOns),
+		zap.String("cluster-arn", ts.cfg.Status.ClusterARN),
+		zap.String("vpc-id", ts.cfg.VPC.ID),
+	)
+	return fmt.Sprintf("retained add-ons %q", ts.cfg.Status.RetainedAddOns)
+}
+
 func (ts *Tester) down() (err error) {
 	fmt.Fprint(ts.logWriter, ts.color("\n\n[yellow]*********************************\n"))
 	fmt.Fprintf(ts.logWriter, ts.color("[light_blue]DOWN START [default](%q, %q)\n"), ts.cfg.ConfigPath, ts.cfg.KubectlCommand())
@@ -1815,9 +2174,10 @@ func (ts *Tester) down() (err error) {
 
 	var errs []string
 
-	if ts.cfg.SkipDeleteClusterAndNodes {
+	skipReason := ts.skipDeleteClusterReason()
+	if skipReason != "" {
 		fmt.Fprint(ts.logWriter, ts.color("\n\n[yellow]*********************************\n"))
-		fmt.Fprintf(ts.logWriter, ts.color("[light_yellow]SKIP [light_blue]deleteKeyPair [default](SkipDeleteClusterAndNodes 'true', %q)\n"), ts.cfg.ConfigPath)
+		fmt.Fprintf(ts.logWriter, ts.color("[light_yellow]SKIP [light_blue]deleteKeyPair [default](%s, %q)\n"), skipReason, ts.cfg.ConfigPath)
 	} else {
 		fmt.Fprint(ts.logWriter, ts.color("\n\n[yellow]*********************************\n"))
 		fmt.Fprintf(ts.logWriter, ts.color("[light_blue]deleteKeyPair [default](%q)\n"), ts.cfg.ConfigPath)
@@ -1827,21 +2187,11 @@ func (ts *Tester) down() (err error) {
 		}
 	}
 
-	testersN := len(ts.testers)
-	for idx := range ts.testers {
-		idx = testersN - idx - 1
-		cur := ts.testers[idx]
-		fmt.Fprint(ts.logWriter, ts.color("\n\n[yellow]*********************************\n"))
-		fmt.Fprintf(ts.logWriter, ts.color("[light_blue]testers[%02d].Delete [cyan]%q [default](%q, %q)\n"), idx, cur.Name(), ts.cfg.ConfigPath, ts.cfg.KubectlCommand())
-		if err := cur.Delete(); err != nil {
-			ts.lg.Warn("failed tester.Delete", zap.Error(err))
-			errs = append(errs, err.Error())
-		}
-	}
+	errs = append(errs, ts.deleteTesters()...)
 
-	if ts.cfg.SkipDeleteClusterAndNodes {
+	if skipReason != "" {
 		fmt.Fprint(ts.logWriter, ts.color("\n\n[yellow]*********************************\n"))
-		fmt.Fprintf(ts.logWriter, ts.color("[light_yellow]SKIP [light_blue]cluster/nodes.Delete [default](SkipDeleteClusterAndNodes 'true', %q)\n"), ts.cfg.ConfigPath)
+		fmt.Fprintf(ts.logWriter, ts.color("[light_yellow]SKIP [light_blue]cluster/nodes.Delete [default](%s, %q)\n"), skipReason, ts.cfg.ConfigPath)
 	} else {
 		// NOTE(jaypipes): Wait for a bit here because we asked Kubernetes to
 		// delete the NLB hello world and ALB2048 Deployment/Service above, and
@@ -1858,7 +2208,7 @@ func (ts *Tester) down() (err error) {
 		// https://github.com/aws/aws-k8s-tester/issues/70
 		// https://github.com/kubernetes/kubernetes/issues/53451
 		// https://github.com/kubernetes/enhancements/blob/master/keps/sig-network/20190423-service-lb-finalizer.md
-		if (ts.cfg.IsEnabledAddOnNodeGroups() || ts.cfg.IsEnabledAddOnManagedNodeGroups()) &&
+		if (ts.cfg.IsEnabledAddOnNodeGroups() || ts.cfg.IsEnabledAddOnManagedNodeGroups() || ts.cfg.IsEnabledAutoMode()) &&
 			((ts.cfg.IsEnabledAddOnALB2048() && ts.cfg.AddOnALB2048.Created) ||
 				(ts.cfg.IsEnabledAddOnNLBHelloWorld() && ts.cfg.AddOnNLBHelloWorld.Created)) {
 			waitDur := 2 * time.Minute
@@ -1866,7 +2216,9 @@ func (ts *Tester) down() (err error) {
 			time.Sleep(waitDur)
 		}
 
-		// following need to be run in order to resolve delete dependency
+		// following need to be run in order to resolve delete dependency,
+		// the reverse of creation: add-ons (above), managed node groups,
+		// node groups, OIDC provider, and cluster, which deletes the VPC last
 		// e.g. cluster must be deleted before VPC delete
 		if ts.cfg.IsEnabledAddOnManagedNodeGroups() && ts.mngTester != nil {
 			fmt.Fprint(ts.logWriter, ts.color("\n\n[yellow]*********************************\n"))
@@ -1894,6 +2246,15 @@ func (ts *Tester) down() (err error) {
 			time.Sleep(waitDur)
 		}
 
+		if ts.cfg.IsEnabledOIDCProvider() && ts.oidcProvider != nil {
+			fmt.Fprint(ts.logWriter, ts.color("\n\n[yellow]*********************************\n"))
+			fmt.Fprintf(ts.logWriter, ts.color("[light_blue]oidcProvider.Delete [default](%q)\n"), ts.cfg.ConfigPath)
+			if err := ts.oidcProvider.Delete(); err != nil {
+				ts.lg.Warn("failed oidcProvider.Delete", zap.Error(err))
+				errs = append(errs, err.Error())
+			}
+		}
+
 		fmt.Fprint(ts.logWriter, ts.color("\n\n[yellow]*********************************\n"))
 		fmt.Fprintf(ts.logWriter, ts.color("[light_blue]clusterTester.Delete [default](%q)\n"), ts.cfg.ConfigPath)
 		if err := ts.clusterTester.Delete(); err != nil {
@@ -0,0 +1,210 @@
+package eks
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/aws/aws-k8s-tester/eks/cluster"
+	eks_tester "github.com/aws/aws-k8s-tester/eks/tester"
+	"github.com/aws/aws-k8s-tester/eksconfig"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"go.uber.org/zap"
+)
+
+func TestCreateTestersOrder(t *testing.T) {
+	dir := t.TempDir()
+	cfg := eksconfig.NewDefault()
+	cfg.ConfigPath = filepath.Join(dir, "eks.yaml")
+	cfg.KubectlCommandsOutputPath = filepath.Join(dir, "eks.kubectl.sh")
+	cfg.RemoteAccessCommandsOutputPath = filepath.Join(dir, "eks.ssh.sh")
+
+	ts := &Tester{
+		color:              cfg.Colorize,
+		stopCreationCh:     make(chan struct{}),
+		stopCreationChOnce: new(sync.Once),
+		lg:                 zap.NewNop(),
+		logWriter:          ioutil.Discard,
+		cfg:                cfg,
+		awsSession:         session.Must(session.NewSession(aws.NewConfig().WithRegion(cfg.Region))),
+	}
+	if err := ts.createTesters(); err != nil {
+		t.Fatal(err)
+	}
+
+	idx := make(map[string]int, len(ts.testers))
+	for i, cur := range ts.testers {
+		name := "add-on-" + testerAddOnName(cur.Name())
+		if _, ok := idx[name]; ok {
+			t.Fatalf("tester %q listed twice", name)
+		}
+		idx[name] = i
+	}
+	for name, deps := range eksconfig.AddOnDependencies {
+		for _, dep := range deps {
+			if idx[dep] > idx[name] {
+				t.Fatalf("%q created before %q", name, dep)
+			}
+		}
+	}
+}
+
+type fakeTester struct{ name string }
+
+func (ft *fakeTester) Name() string  { return ft.name }
+func (ft *fakeTester) Create() error { return nil }
+func (ft *fakeTester) Delete() error { return nil }
+
+func TestOrderTesters(t *testing.T) {
+	testers := []eks_tester.Tester{
+		&fakeTester{"github.com/aws/aws-k8s-tester/eks/fluentd"},
+		&fakeTester{"github.com/aws/aws-k8s-tester/eks/kubernetes-dashboard"},
+		&fakeTester{"github.com/aws/aws-k8s-tester/eks/unknown"},
+		&fakeTester{"github.com/aws/aws-k8s-tester/eks/unknown"},
+		&fakeTester{"github.com/aws/aws-k8s-tester/eks/wordpress"},
+		&fakeTester{"github.com/aws/aws-k8s-tester/eks/metrics-server"},
+		&fakeTester{"github.com/aws/aws-k8s-tester/eks/csrs/local"},
+		&fakeTester{"github.com/aws/aws-k8s-tester/eks/csi-ebs"},
+	}
+	ts := &Tester{testers: append([]eks_tester.Tester(nil), testers...)}
+	if err := ts.orderTesters(); err != nil {
+		t.Fatal(err)
+	}
+
+	// testers without add-on dependencies keep their positions
+	expected := []eks_tester.Tester{
+		testers[0],
+		testers[5],
+		testers[2],
+		testers[3],
+		testers[1],
+		testers[7],
+		testers[6],
+		testers[4],
+	}
+	if !reflect.DeepEqual(ts.testers, expected) {
+		names := make([]string, 0, len(ts.testers))
+		for _, cur := range ts.testers {
+			names = append(names, cur.Name())
+		}
+		t.Fatalf("unexpected order %q", names)
+	}
+}
+
+func Test_testerAddOnName(t *testing.T) {
+	tt := []struct {
+		testerName string
+		name       string
+	}{
+		{"github.com/aws/aws-k8s-tester/eks/jobs-pi", "jobs-pi"},
+		{"github.com/aws/aws-k8s-tester/eks/csrs/local", "csrs-local"},
+		{"github.com/aws/aws-k8s-tester/eks/cluster/version-upgrade", "cluster-version-upgrade"},
+		{"jobs-echo", "jobs-echo"},
+	}
+	for i, tv := range tt {
+		if name := testerAddOnName(tv.testerName); name != tv.name {
+			t.Fatalf("#%d: %q expected %q, got %q", i, tv.testerName, tv.name, name)
+		}
+	}
+}
+
+type deleteTester struct {
+	name    string
+	deleted *[]string
+}
+
+func (dt *deleteTester) Name() string  { return dt.name }
+func (dt *deleteTester) Create() error { return nil }
+func (dt *deleteTester) Delete() error {
+	*dt.deleted = append(*dt.deleted, dt.name)
+	return nil
+}
+
+// failed Up with one retained add-on still reverts the other add-ons
+func TestDeleteTestersRetainedAddOn(t *testing.T) {
+	cfg := eksconfig.NewDefault()
+	cfg.AddOnCSIEBS.Enable = true
+	cfg.AddOnCSIEBS.Created = true
+	cfg.AddOnCSIEBS.CleanupPolicy = eksconfig.CleanupPolicyDelete
+	cfg.AddOnWordpress.Enable = true
+	cfg.AddOnWordpress.Created = true
+	cfg.AddOnWordpress.CreateFailed = true
+	cfg.AddOnWordpress.CleanupPolicy = eksconfig.CleanupPolicyRetainOnFailure
+	cfg.AddOnJobsPi.Enable = true
+	cfg.AddOnJobsPi.Created = true
+	cfg.AddOnJobsPi.CleanupPolicy = eksconfig.CleanupPolicyRetainOnFailure
+
+	var deleted []string
+	ts := &Tester{
+		color:     cfg.Colorize,
+		lg:        zap.NewNop(),
+		logWriter: ioutil.Discard,
+		cfg:       cfg,
+		testers: []eks_tester.Tester{
+			&deleteTester{name: "github.com/aws/aws-k8s-tester/eks/csi-ebs", deleted: &deleted},
+			&deleteTester{name: "github.com/aws/aws-k8s-tester/eks/wordpress", deleted: &deleted},
+			&deleteTester{name: "github.com/aws/aws-k8s-tester/eks/jobs-pi", deleted: &deleted},
+		},
+	}
+	if errs := ts.deleteTesters(); len(errs) > 0 {
+		t.Fatal(errs)
+	}
+	expected := []string{
+		"github.com/aws/aws-k8s-tester/eks/jobs-pi",
+		"github.com/aws/aws-k8s-tester/eks/csi-ebs",
+	}
+	if !reflect.DeepEqual(deleted, expected) {
+		t.Fatalf("expected deleted %q, got %q", expected, deleted)
+	}
+}
+
+type deleteClusterTester struct {
+	cluster.Tester
+	deleted *[]string
+}
+
+func (dt *deleteClusterTester) Delete() error {
+	*dt.deleted = append(*dt.deleted, "cluster")
+	return nil
+}
+
+// failed Up with one retained add-on keeps the cluster it runs on
+func TestDownRetainedAddOn(t *testing.T) {
+	cfg := eksconfig.NewDefault()
+	cfg.ConfigPath = filepath.Join(t.TempDir(), "eks.yaml")
+	cfg.AddOnCSIEBS.Enable = true
+	cfg.AddOnCSIEBS.Created = true
+	cfg.AddOnCSIEBS.CleanupPolicy = eksconfig.CleanupPolicyDelete
+	cfg.AddOnWordpress.Enable = true
+	cfg.AddOnWordpress.Created = true
+	cfg.AddOnWordpress.CreateFailed = true
+	cfg.AddOnWordpress.CleanupPolicy = eksconfig.CleanupPolicyRetainOnFailure
+
+	var deleted []string
+	ts := &Tester{
+		color:      cfg.Colorize,
+		lg:         zap.NewNop(),
+		logWriter:  ioutil.Discard,
+		cfg:        cfg,
+		s3Uploaded: true,
+		testers: []eks_tester.Tester{
+			&deleteTester{name: "github.com/aws/aws-k8s-tester/eks/csi-ebs", deleted: &deleted},
+			&deleteTester{name: "github.com/aws/aws-k8s-tester/eks/wordpress", deleted: &deleted},
+		},
+		clusterTester: &deleteClusterTester{deleted: &deleted},
+	}
+	if err := ts.down(); err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"github.com/aws/aws-k8s-tester/eks/csi-ebs"}
+	if !reflect.DeepEqual(deleted, expected) {
+		t.Fatalf("expected deleted %q, got %q", expected, deleted)
+	}
+	if !reflect.DeepEqual(cfg.Status.RetainedAddOns, []string{"AddOnWordpress"}) {
+		t.Fatalf("unexpected Status.RetainedAddOns %q", cfg.Status.RetainedAddOns)
+	}
+
+}
@@ -0,0 +1,354 @@
+package eks
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-k8s-tester/pkg/httputil"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	aws_eks "github.com/aws/aws-sdk-go/service/eks"
+	"github.com/aws/aws-sdk-go/service/iam"
+	"github.com/aws/aws-sdk-go/service/lambda"
+	"go.uber.org/zap"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// endpointProbeCode is the probe Lambda function, which reports its own
+// public IP and whether the endpoint returned any HTTP response.
+// Any HTTP status (e.g. 401 or 403) means the endpoint is reachable.
+const endpointProbeCode = `import ssl
+import urllib.error
+import urllib.request
+
+
+def handler(event, context):
+    out = {}
+    try:
+        out['source-ip'] = urllib.request.urlopen(event['runner-ip-url'], timeout=10).read().decode().strip()
+    except Exception as e:
+        out['source-ip-error'] = str(e)
+    ctx = ssl.create_default_context()
+    ctx.check_hostname = False
+    ctx.verify_mode = ssl.CERT_NONE
+    try:
+        out['status'] = urllib.request.urlopen(event['endpoint'] + '/version', timeout=10, context=ctx).status
+    except urllib.error.HTTPError as e:
+        out['status'] = e.code
+    except Exception as e:
+        out['error'] = str(e)
+    return out
+`
+
+const endpointProbeRoleAssumePolicy = `{
+  "Version": "2012-10-17",
+  "Statement": [
+    {
+      "Effect": "Allow",
+      "Principal": {
+        "Service": "lambda.amazonaws.com"
+      },
+      "Action": "sts:AssumeRole"
+    }
+  ]
+}`
+
+type endpointProbeEvent struct {
+	Endpoint    string `json:"endpoint"`
+	RunnerIPURL string `json:"runner-ip-url"`
+}
+
+type endpointProbeResult struct {
+	SourceIP      string `json:"source-ip"`
+	SourceIPError string `json:"source-ip-error"`
+	Status        int    `json:"status"`
+	Error         string `json:"error"`
+}
+
+// addRunnerCIDR adds the public IP of the tester to the allowed
+// public endpoint CIDRs. Must be run before the cluster is created.
+func (ts *Tester) addRunnerCIDR() error {
+	cur := ts.cfg.EndpointPublicAccessCheck
+	if cur.RunnerCIDR == "" {
+		data, err := httputil.Read(ts.lg, nil, cur.RunnerIPURL)
+		if err != nil {
+			return fmt.Errorf("failed to get runner IP from %q (%v)", cur.RunnerIPURL, err)
+		}
+		cur.RunnerCIDR, err = runnerCIDR(string(data))
+		if err != nil {
+			return err
+		}
+	}
+	for _, v := range ts.cfg.EndpointPublicAccessCIDRs {
+		if v == cur.RunnerCIDR {
+			ts.lg.Info("runner CIDR already allowed", zap.String("runner-cidr", cur.RunnerCIDR))
+			return nil
+		}
+	}
+	ts.cfg.EndpointPublicAccessCIDRs = append(ts.cfg.EndpointPublicAccessCIDRs, cur.RunnerCIDR)
+	sort.Strings(ts.cfg.EndpointPublicAccessCIDRs)
+	ts.cfg.Sync()
+	ts.lg.Info("added runner CIDR",
+		zap.String("runner-cidr", cur.RunnerCIDR),
+		zap.Strings("endpoint-public-access-cidrs", ts.cfg.EndpointPublicAccessCIDRs),
+	)
+	return nil
+}
+
+// runnerCIDR returns the "/32" CIDR of the IPv4 address in the body.
+func runnerCIDR(body string) (string, error) {
+	s := strings.TrimSpace(body)
+	ip := net.ParseIP(s)
+	if ip == nil {
+		return "", fmt.Errorf("invalid runner IP %q", s)
+	}
+	if ip.To4() == nil {
+		return "", fmt.Errorf("runner IP %q is not IPv4", s)
+	}
+	return ip.To4().String() + "/32", nil
+}
+
+// checkEndpointPublicAccess checks that the cluster allows the configured
+// CIDRs, the tester reaches the endpoint, and the probe Lambda function
+// outside of the allowed CIDRs is rejected.
+func (ts *Tester) checkEndpointPublicAccess() (err error) {
+	if ts.k8sClient == nil {
+		return errors.New("nil k8s client")
+	}
+	cur := ts.cfg.EndpointPublicAccessCheck
+
+	out, err := ts.eksAPIForCluster.DescribeCluster(&aws_eks.DescribeClusterInput{
+		Name: aws.String(ts.cfg.Name),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to describe cluster %q (%v)", ts.cfg.Name, err)
+	}
+	var allowed []string
+	if vc := out.Cluster.ResourcesVpcConfig; vc != nil {
+		allowed = aws.StringValueSlice(vc.PublicAccessCidrs)
+	}
+	sort.Strings(allowed)
+	expected := append([]string(nil), ts.cfg.EndpointPublicAccessCIDRs...)
+	sort.Strings(expected)
+	if strings.Join(allowed, ",") != strings.Join(expected, ",") {
+		return fmt.Errorf("expected public access CIDRs %q, got %q", expected, allowed)
+	}
+	ts.lg.Info("checked public access CIDRs", zap.Strings("public-access-cidrs", allowed))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	_, err = ts.k8sClient.KubernetesClientSet().CoreV1().Namespaces().List(ctx, metav1.ListOptions{Limit: 1})
+	cancel()
+	if err != nil {
+		return fmt.Errorf("failed to reach endpoint from runner (%v)", err)
+	}
+	ts.lg.Info("reached endpoint from runner", zap.String("runner-cidr", cur.RunnerCIDR))
+
+	defer func() {
+		if derr := ts.deleteEndpointProbe(); derr != nil && err == nil {
+			err = derr
+		}
+	}()
+	if err = ts.createEndpointProbe(); err != nil {
+		return err
+	}
+	res, err := ts.invokeEndpointProbe()
+	if err != nil {
+		return err
+	}
+	cur.ProbeSourceIP, cur.ProbeError = res.SourceIP, res.Error
+	ts.cfg.Sync()
+	if err = evaluateEndpointProbe(res, ts.cfg.EndpointPublicAccessCIDRs); err != nil {
+		return err
+	}
+	ts.lg.Info("endpoint rejected probe",
+		zap.String("probe-source-ip", res.SourceIP),
+		zap.String("probe-error", res.Error),
+	)
+	return nil
+}
+
+// evaluateEndpointProbe returns an error if the probe result does not
+// prove the endpoint access control, that is, the probe must run outside
+// of the allowed CIDRs and fail to reach the endpoint.
+func evaluateEndpointProbe(res endpointProbeResult, allowed []string) error {
+	ip := net.ParseIP(res.SourceIP)
+	if ip == nil {
+		return fmt.Errorf("probe failed to get its source IP %q (%s)", res.SourceIP, res.SourceIPError)
+	}
+	for _, v := range allowed {
+		_, ipNet, err := net.ParseCIDR(v)
+		if err != nil {
+			return fmt.Errorf("invalid CIDR %q (%v)", v, err)
+		}
+		if ipNet.Contains(ip) {
+			return fmt.Errorf("probe source IP %q within allowed CIDR %q", res.SourceIP, v)
+		}
+	}
+	if res.Status != 0 {
+		return fmt.Errorf("probe from %q reached endpoint with status %d", res.SourceIP, res.Status)
+	}
+	if res.Error == "" {
+		return fmt.Errorf("probe from %q returned neither status nor error", res.SourceIP)
+	}
+	return nil
+}
+
+func (ts *Tester) createEndpointProbe() error {
+	cur := ts.cfg.EndpointPublicAccessCheck
+
+	ts.lg.Info("creating probe role", zap.String("role-name", cur.ProbeRoleName))
+	tags := []*iam.Tag{}
+	for k, v := range ts.cfg.MergeTags(map[string]string{"Kind": "aws-k8s-tester", "Name": ts.cfg.Name}) {
+		tags = append(tags, &iam.Tag{Key: aws.String(k), Value: aws.String(v)})
+	}
+	input := &iam.CreateRoleInput{
+		RoleName:                 aws.String(cur.ProbeRoleName),
+		Path:                     aws.String(ts.cfg.IAMPath),
+		AssumeRolePolicyDocument: aws.String(endpointProbeRoleAssumePolicy),
+		Tags:                     tags,
+	}
+	if ts.cfg.IAMPermissionsBoundaryARN != "" {
+		input.PermissionsBoundary = aws.String(ts.cfg.IAMPermissionsBoundaryARN)
+	}
+	rout, err := ts.iamAPI.CreateRole(input)
+	if err != nil {
+		return fmt.Errorf("failed to create probe role %q (%v)", cur.ProbeRoleName, err)
+	}
+	roleARN := aws.StringValue(rout.Role.Arn)
+	if _, err = ts.iamAPI.AttachRolePolicy(&iam.AttachRolePolicyInput{
+		RoleName:  aws.String(cur.ProbeRoleName),
+		PolicyArn: aws.String(endpointProbePolicyARN(ts.cfg.Partition)),
+	}); err != nil {
+		return fmt.Errorf("failed to attach probe role policy (%v)", err)
+	}
+
+	buf := bytes.NewBuffer(nil)
+	zw := zip.NewWriter(buf)
+	f, err := zw.Create("index.py")
+	if err != nil {
+		return err
+	}
+	if _, err = f.Write([]byte(endpointProbeCode)); err != nil {
+		return err
+	}
+	if err = zw.Close(); err != nil {
+		return err
+	}
+
+	ts.lg.Info("creating probe function", zap.String("function-name", cur.ProbeFunctionName), zap.String("role-arn", roleARN))
+	// newly created role takes time to be assumable by Lambda
+	created := false
+	retryStart, waitDur := time.Now(), 3*time.Minute
+	for time.Since(retryStart) < waitDur {
+		_, err = ts.lambdaAPI.CreateFunction(&lambda.CreateFunctionInput{
+			FunctionName: aws.String(cur.ProbeFunctionName),
+			Runtime:      aws.String(cur.ProbeRuntime),
+			Role:         aws.String(roleARN),
+			Handler:      aws.String("index.handler"),
+			Code:         &lambda.FunctionCode{ZipFile: buf.Bytes()},
+			Timeout:      aws.Int64(30),
+			Tags:         aws.StringMap(ts.cfg.MergeTags(map[string]string{"Kind": "aws-k8s-tester", "Name": ts.cfg.Name})),
+		})
+		if err == nil {
+			created = true
+			break
+		}
+		if aerr, ok := err.(awserr.Error); !ok || aerr.Code() != lambda.ErrCodeInvalidParameterValueException {
+			return fmt.Errorf("failed to create probe function %q (%v)", cur.ProbeFunctionName, err)
+		}
+		ts.lg.Warn("probe role not ready yet", zap.Error(err))
+		select {
+		case <-ts.stopCreationCh:
+			return errors.New("probe function creation aborted")
+		case <-time.After(10 * time.Second):
+		}
+	}
+	if !created {
+		return fmt.Errorf("failed to create probe function %q (%v)", cur.ProbeFunctionName, err)
+	}
+	if err = ts.lambdaAPI.WaitUntilFunctionActiveV2(&lambda.GetFunctionInput{
+		FunctionName: aws.String(cur.ProbeFunctionName),
+	}); err != nil {
+		return fmt.Errorf("failed to wait for probe function %q (%v)", cur.ProbeFunctionName, err)
+	}
+	ts.lg.Info("created probe function", zap.String("function-name", cur.ProbeFunctionName))
+	return nil
+}
+
+func (ts *Tester) invokeEndpointProbe() (res endpointProbeResult, err error) {
+	cur := ts.cfg.EndpointPublicAccessCheck
+	payload, err := json.Marshal(endpointProbeEvent{
+		Endpoint:    ts.cfg.Status.ClusterAPIServerEndpoint,
+		RunnerIPURL: cur.RunnerIPURL,
+	})
+	if err != nil {
+		return res, err
+	}
+	ts.lg.Info("invoking probe function", zap.String("function-name", cur.ProbeFunctionName))
+	out, err := ts.lambdaAPI.Invoke(&lambda.InvokeInput{
+		FunctionName: aws.String(cur.ProbeFunctionName),
+		Payload:      payload,
+	})
+	if err != nil {
+		return res, fmt.Errorf("failed to invoke probe function %q (%v)", cur.ProbeFunctionName, err)
+	}
+	if out.FunctionError != nil {
+		return res, fmt.Errorf("probe function %q failed %q (%s)", cur.ProbeFunctionName, aws.StringValue(out.FunctionError), string(out.Payload))
+	}
+	if err = json.Unmarshal(out.Payload, &res); err != nil {
+		return res, fmt.Errorf("failed to parse probe result %q (%v)", string(out.Payload), err)
+	}
+	return res, nil
+}
+
+func (ts *Tester) deleteEndpointProbe() error {
+	cur := ts.cfg.EndpointPublicAccessCheck
+	var errs []string
+
+	ts.lg.Info("deleting probe function", zap.String("function-name", cur.ProbeFunctionName))
+	_, err := ts.lambdaAPI.DeleteFunction(&lambda.DeleteFunctionInput{
+		FunctionName: aws.String(cur.ProbeFunctionName),
+	})
+	if err != nil {
+		if aerr, ok := err.(awserr.Error); !ok || aerr.Code() != lambda.ErrCodeResourceNotFoundException {
+			errs = append(errs, fmt.Sprintf("failed to delete probe function %q (%v)", cur.ProbeFunctionName, err))
+		}
+	}
+
+	ts.lg.Info("deleting probe role", zap.String("role-name", cur.ProbeRoleName))
+	_, err = ts.iamAPI.DetachRolePolicy(&iam.DetachRolePolicyInput{
+		RoleName:  aws.String(cur.ProbeRoleName),
+		PolicyArn: aws.String(endpointProbePolicyARN(ts.cfg.Partition)),
+	})
+	if err != nil {
+		if aerr, ok := err.(awserr.Error); !ok || aerr.Code() != iam.ErrCodeNoSuchEntityException {
+			errs = append(errs, fmt.Sprintf("failed to detach probe role policy (%v)", err))
+		}
+	}
+	_, err = ts.iamAPI.DeleteRole(&iam.DeleteRoleInput{
+		RoleName: aws.String(cur.ProbeRoleName),
+	})
+	if err != nil {
+		if aerr, ok := err.(awserr.Error); !ok || aerr.Code() != iam.ErrCodeNoSuchEntityException {
+			errs = append(errs, fmt.Sprintf("failed to delete probe role %q (%v)", cur.ProbeRoleName, err))
+		}
+	}
+
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, ", "))
+	}
+	ts.lg.Info("deleted probe function and role")
+	return nil
+}
+
+func endpointProbePolicyARN(partition string) string {
+	return "arn:" + partition + ":iam::aws:policy/service-role/AWSLambdaBasicExecutionRole"
+}
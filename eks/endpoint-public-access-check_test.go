@@ -0,0 +1,48 @@
+package eks
+
+import "testing"
+
+func TestRunnerCIDR(t *testing.T) {
+	tt := []struct {
+		body string
+		cidr string
+		err  bool
+	}{
+		{"203.0.113.7\n", "203.0.113.7/32", false},
+		{" 198.51.100.1 ", "198.51.100.1/32", false},
+		{"2001:db8::1", "", true},
+		{"<html>", "", true},
+		{"", "", true},
+	}
+	for i, tv := range tt {
+		cidr, err := runnerCIDR(tv.body)
+		if (err != nil) != tv.err {
+			t.Fatalf("#%d: expected error %v, got %v", i, tv.err, err)
+		}
+		if cidr != tv.cidr {
+			t.Fatalf("#%d: expected %q, got %q", i, tv.cidr, cidr)
+		}
+	}
+}
+
+func TestEvaluateEndpointProbe(t *testing.T) {
+	allowed := []string{"203.0.113.0/24", "198.51.100.7/32"}
+	tt := []struct {
+		res endpointProbeResult
+		err bool
+	}{
+		{endpointProbeResult{SourceIP: "192.0.2.10", Error: "timed out"}, false},
+		{endpointProbeResult{SourceIP: "192.0.2.10", Status: 401}, true},
+		{endpointProbeResult{SourceIP: "192.0.2.10", Status: 200}, true},
+		{endpointProbeResult{SourceIP: "203.0.113.9", Error: "timed out"}, true},
+		{endpointProbeResult{SourceIP: "198.51.100.7", Error: "timed out"}, true},
+		{endpointProbeResult{SourceIPError: "timed out", Error: "timed out"}, true},
+		{endpointProbeResult{SourceIP: "192.0.2.10"}, true},
+	}
+	for i, tv := range tt {
+		err := evaluateEndpointProbe(tv.res, allowed)
+		if (err != nil) != tv.err {
+			t.Fatalf("#%d: expected error %v, got %v", i, tv.err, err)
+		}
+	}
+}
@@ -202,6 +202,16 @@ Parameters:
     Type: String
     Description: The name of the Fargate role
 
+  FargateRolePath:
+    Type: String
+    Default: /
+    Description: The path of the Fargate role
+
+  FargateRolePermissionsBoundaryARN:
+    Type: String
+    Default: ''
+    Description: The permissions boundary policy ARN of the Fargate role (empty for none)
+
   FargateRoleServicePrincipals:
     Type: CommaDelimitedList
     Default: 'eks.amazonaws.com,eks-fargate-pods.amazonaws.com'
@@ -212,6 +222,10 @@ Parameters:
     Default: 'arn:aws:iam::aws:policy/AmazonEKSFargatePodExecutionRolePolicy'
     Description: EKS Fargate policy ARNs
 
+Conditions:
+
+  HasFargateRolePermissionsBoundaryARN: !Not [!Equals [!Ref FargateRolePermissionsBoundaryARN, '']]
+
 Resources:
 
   FargateRole:
@@ -227,7 +241,8 @@ Resources:
           Action:
           - sts:AssumeRole
       ManagedPolicyArns: !Ref FargateRoleManagedPolicyARNs
-      Path: /
+      Path: !Ref FargateRolePath
+      PermissionsBoundary: !If [HasFargateRolePermissionsBoundaryARN, !Ref FargateRolePermissionsBoundaryARN, !Ref 'AWS::NoValue']
 
 Outputs:
 
@@ -272,6 +287,7 @@ func (ts *tester) createRole() error {
 		ts.cfg.EKSConfig.S3.BucketName,
 		ts.cfg.EKSConfig.AddOnFargate.RoleCFNStackYAMLS3Key,
 		ts.cfg.EKSConfig.AddOnFargate.RoleCFNStackYAMLPath,
+		aws_s3.WithTags(ts.cfg.EKSConfig.Tags),
 	); err != nil {
 		return err
 	}
@@ -284,17 +300,25 @@ func (ts *tester) createRole() error {
 		Capabilities: aws.StringSlice([]string{"CAPABILITY_NAMED_IAM"}),
 		OnFailure:    aws.String(cloudformation.OnFailureDelete),
 		TemplateBody: aws.String(TemplateRole),
-		Tags: cfn.NewTags(map[string]string{
+		Tags: cfn.NewTags(ts.cfg.EKSConfig.MergeTags(map[string]string{
 			"Kind":                   "aws-k8s-tester",
 			"Name":                   ts.cfg.EKSConfig.Name,
 			"aws-k8s-tester-version": version.ReleaseVersion,
 			"User":                   user.Get(),
-		}),
+		})),
 		Parameters: []*cloudformation.Parameter{
 			{
 				ParameterKey:   aws.String("FargateRoleName"),
 				ParameterValue: aws.String(ts.cfg.EKSConfig.AddOnFargate.RoleName),
 			},
+			{
+				ParameterKey:   aws.String("FargateRolePath"),
+				ParameterValue: aws.String(ts.cfg.EKSConfig.IAMPath),
+			},
+			{
+				ParameterKey:   aws.String("FargateRolePermissionsBoundaryARN"),
+				ParameterValue: aws.String(ts.cfg.EKSConfig.IAMPermissionsBoundaryARN),
+			},
 		},
 	}
 	if len(ts.cfg.EKSConfig.AddOnFargate.RoleServicePrincipals) > 0 {
@@ -0,0 +1,138 @@
+// Package fleet implements the multi-cluster orchestration, which creates
+// and deletes the clusters of "eksconfig.Fleet" concurrently with bounded
+// parallelism, and aggregates the results.
+package fleet
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-k8s-tester/eks"
+	"github.com/aws/aws-k8s-tester/eksconfig"
+	"github.com/aws/aws-k8s-tester/pkg/fileutil"
+	"go.uber.org/zap"
+)
+
+// Up creates the clusters of the fleet, and runs their add-ons.
+// "update" (e.g. "UpdateFromEnvs") is applied to each cluster configuration
+// (see "eksconfig.Fleet.Config"). The results are written to "ResultsPath",
+// and the returned error lists the failed clusters, if any.
+func Up(lg *zap.Logger, f *eksconfig.Fleet, update func(*eksconfig.Config) error) ([]eksconfig.FleetResult, error) {
+	load := func(i int) (*eksconfig.Config, error) {
+		cfg, err := f.Config(i, update)
+		if err != nil {
+			return nil, err
+		}
+		if err = cfg.ValidateAndSetDefaults(); err != nil {
+			return cfg, fmt.Errorf("failed to validate configuration (%v)", err)
+		}
+		return cfg, nil
+	}
+	up := func(cfg *eksconfig.Config) error {
+		ts, err := eks.New(cfg)
+		if err != nil {
+			return err
+		}
+		return ts.Up()
+	}
+	return finish(lg, f, "up", Run(lg, f, load, up))
+}
+
+// Down deletes the clusters of the fleet from their states.
+// Clusters without states (e.g. never created) are skipped.
+func Down(lg *zap.Logger, f *eksconfig.Fleet) ([]eksconfig.FleetResult, error) {
+	load := func(i int) (*eksconfig.Config, error) {
+		p := f.StatePath(i)
+		if !fileutil.Exist(p) {
+			return nil, nil
+		}
+		return eksconfig.Load(p)
+	}
+	down := func(cfg *eksconfig.Config) error {
+		ts, err := eks.New(cfg)
+		if err != nil {
+			return err
+		}
+		return ts.Down()
+	}
+	return finish(lg, f, "down", Run(lg, f, load, down))
+}
+
+// Run loads the configuration of each cluster and runs "op", with at most
+// "Parallelism" clusters at a time. A failed cluster does not stop the others.
+// If "load" returns a nil configuration without error, the cluster is skipped.
+// The results are in the manifest order.
+func Run(
+	lg *zap.Logger,
+	f *eksconfig.Fleet,
+	load func(i int) (*eksconfig.Config, error),
+	op func(cfg *eksconfig.Config) error,
+) []eksconfig.FleetResult {
+	rs := make([]eksconfig.FleetResult, len(f.Clusters))
+	n := f.Parallelism
+	if n <= 0 {
+		n = 1
+	}
+	sema := make(chan struct{}, n)
+	var wg sync.WaitGroup
+	for i := range f.Clusters {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			sema <- struct{}{}
+			defer func() { <-sema }()
+
+			cur := f.Clusters[i]
+			rs[i] = eksconfig.FleetResult{Name: cur.Name, Region: cur.Region, ConfigPath: f.StatePath(i)}
+			start := time.Now()
+			cfg, err := load(i)
+			if err == nil && cfg == nil {
+				lg.Info("skipped cluster", zap.String("name", cur.Name))
+				rs[i].Success = true
+				return
+			}
+			if err == nil {
+				rs[i].Region = cfg.Region
+				lg.Info("starting cluster", zap.String("name", cur.Name), zap.String("region", cfg.Region))
+				err = op(cfg)
+			}
+			rs[i].Took = time.Since(start)
+			if err != nil {
+				rs[i].Error = err.Error()
+				lg.Warn("cluster failed", zap.String("name", cur.Name), zap.Duration("took", rs[i].Took), zap.Error(err))
+				return
+			}
+			rs[i].Success = true
+			lg.Info("cluster succeeded", zap.String("name", cur.Name), zap.Duration("took", rs[i].Took))
+		}(i)
+	}
+	wg.Wait()
+	return rs
+}
+
+func finish(lg *zap.Logger, f *eksconfig.Fleet, stage string, rs []eksconfig.FleetResult) ([]eksconfig.FleetResult, error) {
+	if err := f.WriteResults(rs); err != nil {
+		lg.Warn("failed to write fleet results", zap.Error(err))
+	}
+	var failed []string
+	for _, r := range rs {
+		if !r.Success {
+			failed = append(failed, r.Name)
+		}
+	}
+	lg.Info("fleet finished",
+		zap.String("fleet", f.Name),
+		zap.String("stage", stage),
+		zap.Int("clusters", len(rs)),
+		zap.Int("failed", len(failed)),
+		zap.String("results-path", f.ResultsPath),
+	)
+	if len(failed) > 0 {
+		sort.Strings(failed)
+		return rs, fmt.Errorf("fleet %q %s failed for %d cluster(s) (%s)", f.Name, stage, len(failed), strings.Join(failed, ", "))
+	}
+	return rs, nil
+}
@@ -0,0 +1,79 @@
+package fleet
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-k8s-tester/eksconfig"
+	"go.uber.org/zap"
+)
+
+func TestRun(t *testing.T) {
+	f := &eksconfig.Fleet{
+		Name:        "test",
+		Parallelism: 2,
+		Clusters: []eksconfig.FleetCluster{
+			{Name: "a", Region: "us-west-2"},
+			{Name: "b", Region: "us-east-1"},
+			{Name: "c"},
+			{Name: "d"},
+			{Name: "e"},
+		},
+	}
+	load := func(i int) (*eksconfig.Config, error) {
+		switch f.Clusters[i].Name {
+		case "c":
+			return nil, errors.New("invalid spec")
+		case "e":
+			return nil, nil
+		}
+		return &eksconfig.Config{Name: f.Clusters[i].Name, Region: "ap-northeast-2"}, nil
+	}
+
+	var mu sync.Mutex
+	running, maxRunning := 0, 0
+	op := func(cfg *eksconfig.Config) error {
+		mu.Lock()
+		running++
+		if running > maxRunning {
+			maxRunning = running
+		}
+		mu.Unlock()
+		time.Sleep(100 * time.Millisecond)
+		mu.Lock()
+		running--
+		mu.Unlock()
+		if cfg.Name == "d" {
+			return errors.New("create failed")
+		}
+		return nil
+	}
+
+	rs := Run(zap.NewExample(), f, load, op)
+	if maxRunning != 2 {
+		t.Fatalf("expected 2 clusters running concurrently, got %d", maxRunning)
+	}
+	if len(rs) != 5 {
+		t.Fatalf("unexpected results %+v", rs)
+	}
+	for i, exp := range []struct {
+		name    string
+		success bool
+		err     string
+	}{
+		{"a", true, ""},
+		{"b", true, ""},
+		{"c", false, "invalid spec"},
+		{"d", false, "create failed"},
+		{"e", true, ""},
+	} {
+		if rs[i].Name != exp.name || rs[i].Success != exp.success || rs[i].Error != exp.err {
+			t.Fatalf("#%d: unexpected result %+v", i, rs[i])
+		}
+	}
+	if rs[0].Region != "ap-northeast-2" {
+		t.Fatalf("expected region from configuration, got %q", rs[0].Region)
+	}
+}
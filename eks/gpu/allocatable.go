@@ -0,0 +1,94 @@
+package gpu
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-k8s-tester/eksconfig"
+	k8s_client "github.com/aws/aws-k8s-tester/pkg/k8s-client"
+	"go.uber.org/zap"
+	v1 "k8s.io/api/core/v1"
+)
+
+// ResourceNvidiaGPU is the extended resource name advertised by the NVIDIA device plugin.
+const ResourceNvidiaGPU v1.ResourceName = "nvidia.com/gpu"
+
+// CheckGPUAllocatable returns an error if any GPU node group has fewer Ready
+// nodes than its minimum size with "nvidia.com/gpu" allocatable, or if any node
+// advertises a different number of GPUs than "GPUsPerNode".
+func CheckGPUAllocatable(nodes []v1.Node, ngs []eksconfig.GPUNodeGroup) error {
+	var errs []string
+	for _, ng := range ngs {
+		found := 0
+		for _, node := range nodes {
+			if node.Labels["NGName"] != ng.Name || !isNodeReady(node) {
+				continue
+			}
+			qv, ok := node.Status.Allocatable[ResourceNvidiaGPU]
+			gpus := int(qv.Value())
+			if !ok || gpus == 0 {
+				continue
+			}
+			if ng.GPUsPerNode > 0 && gpus != ng.GPUsPerNode {
+				errs = append(errs, fmt.Sprintf("node %q in %q has %d GPUs (expected %d)", node.Name, ng.Name, gpus, ng.GPUsPerNode))
+				continue
+			}
+			found++
+		}
+		if found < ng.MinNodes {
+			errs = append(errs, fmt.Sprintf("%q has %d nodes with GPU allocatable (expected >= %d)", ng.Name, found, ng.MinNodes))
+		}
+	}
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, ", "))
+	}
+	return nil
+}
+
+func isNodeReady(node v1.Node) bool {
+	for _, cond := range node.Status.Conditions {
+		if cond.Type == v1.NodeReady {
+			return cond.Status == v1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// WaitForGPUAllocatable waits until "CheckGPUAllocatable" succeeds,
+// after the NVIDIA device plugin registers the GPUs with kubelet.
+func WaitForGPUAllocatable(
+	ctx context.Context,
+	lg *zap.Logger,
+	stopc chan struct{},
+	cli k8s_client.EKS,
+	ngs []eksconfig.GPUNodeGroup,
+) (err error) {
+	lg.Info("waiting for GPU allocatable", zap.Int("node-groups", len(ngs)))
+	ticker := time.NewTicker(15 * time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stopc:
+			return errors.New("GPU allocatable wait aborted")
+		case <-ctx.Done():
+			return fmt.Errorf("GPU allocatable wait timed out (%v, last error %v)", ctx.Err(), err)
+		case <-ticker.C:
+		}
+
+		var nodes []v1.Node
+		nodes, err = k8s_client.ListNodes(cli.KubernetesClientSet())
+		if err != nil {
+			lg.Warn("failed to list nodes", zap.Error(err))
+			continue
+		}
+		if err = CheckGPUAllocatable(nodes, ngs); err != nil {
+			lg.Info("GPU allocatable not ready", zap.String("reason", err.Error()))
+			continue
+		}
+		lg.Info("GPU allocatable ready", zap.Int("nodes", len(nodes)))
+		return nil
+	}
+}
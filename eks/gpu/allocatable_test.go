@@ -0,0 +1,83 @@
+package gpu
+
+import (
+	"testing"
+
+	"github.com/aws/aws-k8s-tester/eksconfig"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestCheckGPUAllocatable(t *testing.T) {
+	node := func(name string, ngName string, ready bool, gpus string) v1.Node {
+		status := v1.ConditionFalse
+		if ready {
+			status = v1.ConditionTrue
+		}
+		n := v1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{"NGName": ngName}},
+			Status: v1.NodeStatus{
+				Conditions:  []v1.NodeCondition{{Type: v1.NodeReady, Status: status}},
+				Allocatable: v1.ResourceList{},
+			},
+		}
+		if gpus != "" {
+			n.Status.Allocatable[ResourceNvidiaGPU] = resource.MustParse(gpus)
+		}
+		return n
+	}
+	ngs := []eksconfig.GPUNodeGroup{
+		{Name: "mng-gpu", GPUsPerNode: 4, MinNodes: 2},
+		{Name: "ng-gpu", GPUsPerNode: 0, MinNodes: 1},
+	}
+
+	tt := []struct {
+		nodes []v1.Node
+		err   bool
+	}{
+		{
+			nodes: []v1.Node{
+				node("a", "mng-gpu", true, "4"),
+				node("b", "mng-gpu", true, "4"),
+				node("c", "ng-gpu", true, "1"),
+				node("d", "mng-cpu", true, ""),
+			},
+			err: false,
+		},
+		{
+			// device plugin not yet registered
+			nodes: []v1.Node{
+				node("a", "mng-gpu", true, "4"),
+				node("b", "mng-gpu", true, ""),
+				node("c", "ng-gpu", true, "1"),
+			},
+			err: true,
+		},
+		{
+			// not ready
+			nodes: []v1.Node{
+				node("a", "mng-gpu", true, "4"),
+				node("b", "mng-gpu", false, "4"),
+				node("c", "ng-gpu", true, "8"),
+			},
+			err: true,
+		},
+		{
+			// unexpected GPU count
+			nodes: []v1.Node{
+				node("a", "mng-gpu", true, "4"),
+				node("b", "mng-gpu", true, "1"),
+				node("c", "mng-gpu", true, "4"),
+				node("d", "ng-gpu", true, "1"),
+			},
+			err: true,
+		},
+	}
+	for i, tv := range tt {
+		err := CheckGPUAllocatable(tv.nodes, ngs)
+		if (err != nil) != tv.err {
+			t.Fatalf("#%d: unexpected error %v", i, err)
+		}
+	}
+}
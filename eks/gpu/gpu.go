@@ -12,14 +12,10 @@ import (
 	"strings"
 	"time"
 
-	"github.com/aws/aws-k8s-tester/ec2config"
 	"github.com/aws/aws-k8s-tester/eksconfig"
 	"github.com/aws/aws-k8s-tester/pkg/fileutil"
 	k8s_client "github.com/aws/aws-k8s-tester/pkg/k8s-client"
-	"github.com/aws/aws-sdk-go/service/eks"
 	"go.uber.org/zap"
-	v1 "k8s.io/api/core/v1"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/utils/exec"
 )
 
@@ -39,7 +35,8 @@ type Tester interface {
 	DeployMPIOperator() error
 	// InstallNvidiaDriver installs the Nvidia device plugin for Kubernetes.
 	// After GPU worker nodes join the cluster, one must apply the Nvidia
-	// device plugin for Kubernetes as a DaemonSet.
+	// device plugin for Kubernetes as a DaemonSet. It waits until every GPU
+	// node advertises "nvidia.com/gpu" allocatable, see "WaitForGPUAllocatable".
 	// ref. https://docs.aws.amazon.com/eks/latest/userguide/create-managed-node-group.html
 	// ref. https://docs.aws.amazon.com/eks/latest/userguide/gpu-ami.html
 	// ref. https://github.com/NVIDIA/k8s-device-plugin
@@ -152,7 +149,7 @@ apiVersion: apps/v1
 kind: DaemonSet
 metadata:
   name: nvidia-device-plugin-daemonset
-  namespace: mpi-operator
+  namespace: kube-system
 spec:
   selector:
     matchLabels:
@@ -215,7 +212,7 @@ func (ts *tester) InstallNvidiaDriver() (err error) {
 	applyArgs := []string{
 		ts.cfg.EKSConfig.KubectlPath,
 		"--kubeconfig=" + ts.cfg.EKSConfig.KubeConfigPath,
-		"--namespace=mpi-operator",
+		"--namespace=kube-system",
 		"apply",
 		"-f",
 		fpath,
@@ -251,171 +248,11 @@ func (ts *tester) InstallNvidiaDriver() (err error) {
 		return errors.New("failed to install nvidia GPU driver")
 	}
 
-	if ts.cfg.EKSConfig.IsEnabledAddOnNodeGroups() {
-		cnt := 0
-		for _, cur := range ts.cfg.EKSConfig.AddOnNodeGroups.ASGs {
-			if cur.AMIType == ec2config.AMITypeAL2X8664GPU {
-				cnt++
-			}
-		}
-
-		waitDur := 5 * time.Minute
-		var items []v1.Node
-		retryStart := time.Now()
-
-		readyNGs := make(map[string]struct{})
-		for time.Since(retryStart) < waitDur {
-			if len(readyNGs) == cnt {
-				break
-			}
-			for ngName, cur := range ts.cfg.EKSConfig.AddOnNodeGroups.ASGs {
-				if cur.AMIType != ec2config.AMITypeAL2X8664GPU {
-					ts.cfg.Logger.Warn("skipping non-GPU AMI", zap.String("ng-name", ngName))
-					continue
-				}
-				if _, ok := readyNGs[ngName]; ok {
-					ts.cfg.Logger.Info("skipping already ready mng", zap.String("ng-name", ngName))
-					continue
-				}
-				ts.cfg.Logger.Info("listing GPU nodes via client-go", zap.String("ng-name", ngName))
-				ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
-				nodes, err := ts.cfg.K8SClient.KubernetesClientSet().CoreV1().Nodes().List(
-					ctx,
-					metav1.ListOptions{
-						// TODO: filter by GPU?
-						// FieldSelector: fields.OneTermEqualSelector("metadata.name", "GPU").String(),
-					},
-				)
-				cancel()
-				if err != nil {
-					ts.cfg.Logger.Warn("get nodes failed", zap.Error(err))
-					time.Sleep(5 * time.Second)
-					continue
-				}
-				items = nodes.Items
-				ts.cfg.Logger.Info("listed GPU nodes via client-go", zap.String("ng-name", ngName), zap.Int("nodes", len(items)))
-
-				foundReady := int32(0)
-				for _, node := range items {
-					labels := node.GetLabels()
-					if labels["NGName"] != ngName {
-						continue
-					}
-					nodeName := node.GetName()
-
-					ts.cfg.Logger.Info("checking node-info conditions",
-						zap.String("node-name", nodeName),
-						zap.String("labels", fmt.Sprintf("%+v", labels)),
-						zap.String("allocatable", fmt.Sprintf("%+v", node.Status.Allocatable)),
-					)
-					for _, cond := range node.Status.Conditions {
-						if cond.Type != v1.NodeReady {
-							continue
-						}
-						ts.cfg.Logger.Info("node info",
-							zap.String("node-name", nodeName),
-							zap.String("type", fmt.Sprintf("%s", cond.Type)),
-							zap.String("status", fmt.Sprintf("%s", cond.Status)),
-						)
-						if cond.Status == v1.ConditionTrue {
-							foundReady++
-						}
-					}
-				}
-				ts.cfg.Logger.Info("nodes",
-					zap.Int32("current-ready-nodes", foundReady),
-					zap.Int32("min-ready-nodes", cur.ASGMinSize),
-					zap.Int32("desired-ready-nodes", cur.ASGDesiredCapacity),
-				)
-				time.Sleep(5 * time.Second)
-
-				if foundReady >= cur.ASGMinSize {
-					readyNGs[ngName] = struct{}{}
-					break
-				}
-			}
-		}
-	}
-
-	if ts.cfg.EKSConfig.IsEnabledAddOnManagedNodeGroups() {
-		cnt := 0
-		for _, cur := range ts.cfg.EKSConfig.AddOnManagedNodeGroups.MNGs {
-			if cur.AMIType == eks.AMITypesAl2X8664Gpu {
-				cnt++
-			}
-		}
-
-		waitDur := 5 * time.Minute
-		var items []v1.Node
-		retryStart := time.Now()
-
-		readyMNGs := make(map[string]struct{})
-		for time.Since(retryStart) < waitDur {
-			if len(readyMNGs) == cnt {
-				break
-			}
-			for mngName, cur := range ts.cfg.EKSConfig.AddOnManagedNodeGroups.MNGs {
-				if cur.AMIType != eks.AMITypesAl2X8664Gpu {
-					ts.cfg.Logger.Warn("skipping non-GPU AMI", zap.String("mng-name", mngName))
-					continue
-				}
-				if _, ok := readyMNGs[mngName]; ok {
-					ts.cfg.Logger.Info("skipping already ready mng", zap.String("mng-name", mngName))
-					continue
-				}
-				ts.cfg.Logger.Info("listing GPU nodes via client-go", zap.String("mng-name", mngName))
-				ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
-				nodes, err := ts.cfg.K8SClient.KubernetesClientSet().CoreV1().Nodes().List(
-					ctx,
-					metav1.ListOptions{
-						// TODO: filter by GPU?
-						// FieldSelector: fields.OneTermEqualSelector("metadata.name", "GPU").String(),
-					},
-				)
-				cancel()
-				if err != nil {
-					ts.cfg.Logger.Warn("get nodes failed", zap.Error(err))
-					time.Sleep(5 * time.Second)
-					continue
-				}
-				items = nodes.Items
-				ts.cfg.Logger.Info("listed GPU nodes via client-go", zap.String("mng-name", mngName), zap.Int("nodes", len(items)))
-
-				foundReady := 0
-				for _, node := range items {
-					labels := node.GetLabels()
-					if labels["NGName"] != mngName {
-						continue
-					}
-					nodeName := node.GetName()
-					ts.cfg.Logger.Info("checking node-info conditions", zap.String("node-name", nodeName), zap.String("labels", fmt.Sprintf("%+v", labels)))
-					for _, cond := range node.Status.Conditions {
-						if cond.Type != v1.NodeReady {
-							continue
-						}
-						ts.cfg.Logger.Info("node info",
-							zap.String("node-name", nodeName),
-							zap.String("type", fmt.Sprintf("%s", cond.Type)),
-							zap.String("status", fmt.Sprintf("%s", cond.Status)),
-						)
-						if cond.Status == v1.ConditionTrue {
-							foundReady++
-						}
-					}
-				}
-				ts.cfg.Logger.Info("nodes",
-					zap.Int("current-ready-nodes", foundReady),
-					zap.Int("min-ready-nodes", cur.ASGMinSize),
-					zap.Int("desired-ready-nodes", cur.ASGDesiredCapacity),
-				)
-				time.Sleep(5 * time.Second)
-
-				if foundReady >= cur.ASGMinSize {
-					readyMNGs[mngName] = struct{}{}
-					break
-				}
-			}
-		}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	err = WaitForGPUAllocatable(ctx, ts.cfg.Logger, ts.cfg.Stopc, ts.cfg.K8SClient, ts.cfg.EKSConfig.GPUNodeGroups())
+	cancel()
+	if err != nil {
+		return fmt.Errorf("nvidia GPU driver installed but GPUs not allocatable (%v)", err)
 	}
 
 	ts.cfg.EKSConfig.Sync()
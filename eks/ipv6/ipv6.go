@@ -0,0 +1,506 @@
+// Package ipv6 implements tester for IPv6 clusters, which asserts
+// Pods get IPv6 addresses and a dual-stack load balancer serves traffic.
+package ipv6
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	eks_tester "github.com/aws/aws-k8s-tester/eks/tester"
+	"github.com/aws/aws-k8s-tester/eksconfig"
+	"github.com/aws/aws-k8s-tester/pkg/aws/elb"
+	"github.com/aws/aws-k8s-tester/pkg/httputil"
+	k8s_client "github.com/aws/aws-k8s-tester/pkg/k8s-client"
+	"github.com/aws/aws-k8s-tester/pkg/timeutil"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/elbv2"
+	"github.com/aws/aws-sdk-go/service/elbv2/elbv2iface"
+	"go.uber.org/zap"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// Config defines IPv6 tester configuration.
+type Config struct {
+	Logger    *zap.Logger
+	LogWriter io.Writer
+	Stopc     chan struct{}
+	EKSConfig *eksconfig.Config
+	K8SClient k8s_client.EKS
+	ELB2API   elbv2iface.ELBV2API
+}
+
+var pkgName = reflect.TypeOf(tester{}).PkgPath()
+
+func (ts *tester) Name() string { return pkgName }
+
+// New creates a new IPv6 tester.
+func New(cfg Config) eks_tester.Tester {
+	cfg.Logger.Info("creating tester", zap.String("tester", pkgName))
+	return &tester{cfg: cfg}
+}
+
+type tester struct {
+	cfg Config
+}
+
+const (
+	ipv6DeploymentName = "ipv6-deployment"
+	ipv6AppName        = "ipv6"
+	ipv6AppImageName   = "dockercloud/hello-world"
+	ipv6ServiceName    = "ipv6-service"
+)
+
+func (ts *tester) Create() error {
+	if !ts.cfg.EKSConfig.IsEnabledAddOnIPv6() {
+		ts.cfg.Logger.Info("skipping tester.Create", zap.String("tester", pkgName))
+		return nil
+	}
+	if ts.cfg.EKSConfig.AddOnIPv6.Created {
+		ts.cfg.Logger.Info("skipping tester.Create", zap.String("tester", pkgName))
+		return nil
+	}
+
+	ts.cfg.Logger.Info("starting tester.Create", zap.String("tester", pkgName))
+	ts.cfg.EKSConfig.AddOnIPv6.Created = true
+	ts.cfg.EKSConfig.Sync()
+	createStart := time.Now()
+	defer func() {
+		createEnd := time.Now()
+		ts.cfg.EKSConfig.AddOnIPv6.TimeFrameCreate = timeutil.NewTimeFrame(createStart, createEnd)
+		ts.cfg.EKSConfig.Sync()
+	}()
+
+	if err := k8s_client.CreateNamespace(
+		ts.cfg.Logger,
+		ts.cfg.K8SClient.KubernetesClientSet(),
+		ts.cfg.EKSConfig.AddOnIPv6.Namespace,
+	); err != nil {
+		return err
+	}
+	if err := ts.createDeployment(); err != nil {
+		return err
+	}
+	if err := ts.waitDeployment(); err != nil {
+		return err
+	}
+	if err := ts.checkPodIPs(); err != nil {
+		return err
+	}
+	if err := ts.createService(); err != nil {
+		return err
+	}
+	ts.cfg.EKSConfig.Sync()
+	return nil
+}
+
+func (ts *tester) Delete() error {
+	if !ts.cfg.EKSConfig.IsEnabledAddOnIPv6() {
+		ts.cfg.Logger.Info("skipping tester.Delete", zap.String("tester", pkgName))
+		return nil
+	}
+	if !ts.cfg.EKSConfig.AddOnIPv6.Created {
+		ts.cfg.Logger.Info("skipping tester.Delete", zap.String("tester", pkgName))
+		return nil
+	}
+
+	ts.cfg.Logger.Info("starting tester.Delete", zap.String("tester", pkgName))
+	deleteStart := time.Now()
+	defer func() {
+		deleteEnd := time.Now()
+		ts.cfg.EKSConfig.AddOnIPv6.TimeFrameDelete = timeutil.NewTimeFrame(deleteStart, deleteEnd)
+		ts.cfg.EKSConfig.Sync()
+	}()
+
+	var errs []string
+
+	if err := ts.deleteService(); err != nil {
+		errs = append(errs, fmt.Sprintf("failed to delete IPv6 Service (%v)", err))
+	}
+	ts.cfg.Logger.Info("wait for a minute after deleting Service")
+	time.Sleep(time.Minute)
+
+	if err := ts.deleteDeployment(); err != nil {
+		errs = append(errs, fmt.Sprintf("failed to delete IPv6 Deployment (%v)", err))
+	}
+
+	// load balancers created by the AWS Load Balancer Controller
+	if err := elb.DeleteELBv2(
+		ts.cfg.Logger,
+		ts.cfg.ELB2API,
+		ts.cfg.EKSConfig.AddOnIPv6.ELBARN,
+		ts.cfg.EKSConfig.VPC.ID,
+		map[string]string{
+			"elbv2.k8s.aws/cluster": ts.cfg.EKSConfig.Name,
+			"service.k8s.aws/stack": ts.cfg.EKSConfig.AddOnIPv6.Namespace + "/" + ipv6ServiceName,
+		},
+	); err != nil {
+		errs = append(errs, fmt.Sprintf("failed to delete IPv6 load balancer (%v)", err))
+	}
+
+	if err := k8s_client.DeleteNamespaceAndWait(
+		ts.cfg.Logger,
+		ts.cfg.K8SClient.KubernetesClientSet(),
+		ts.cfg.EKSConfig.AddOnIPv6.Namespace,
+		k8s_client.DefaultNamespaceDeletionInterval,
+		k8s_client.DefaultNamespaceDeletionTimeout,
+		k8s_client.WithForceDelete(true),
+	); err != nil {
+		errs = append(errs, fmt.Sprintf("failed to delete IPv6 namespace (%v)", err))
+	}
+
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, ", "))
+	}
+
+	ts.cfg.EKSConfig.AddOnIPv6.Created = false
+	ts.cfg.EKSConfig.Sync()
+	return nil
+}
+
+func (ts *tester) createDeployment() error {
+	ts.cfg.Logger.Info("creating IPv6 Deployment")
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	_, err := ts.cfg.K8SClient.KubernetesClientSet().
+		AppsV1().
+		Deployments(ts.cfg.EKSConfig.AddOnIPv6.Namespace).
+		Create(
+			ctx,
+			&appsv1.Deployment{
+				TypeMeta: metav1.TypeMeta{
+					APIVersion: "apps/v1",
+					Kind:       "Deployment",
+				},
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      ipv6DeploymentName,
+					Namespace: ts.cfg.EKSConfig.AddOnIPv6.Namespace,
+					Labels: map[string]string{
+						"app.kubernetes.io/name": ipv6AppName,
+					},
+				},
+				Spec: appsv1.DeploymentSpec{
+					Replicas: aws.Int32(ts.cfg.EKSConfig.AddOnIPv6.DeploymentReplicas),
+					Selector: &metav1.LabelSelector{
+						MatchLabels: map[string]string{
+							"app.kubernetes.io/name": ipv6AppName,
+						},
+					},
+					Template: v1.PodTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{
+							Labels: map[string]string{
+								"app.kubernetes.io/name": ipv6AppName,
+							},
+						},
+						Spec: v1.PodSpec{
+							RestartPolicy: v1.RestartPolicyAlways,
+							Containers: []v1.Container{
+								{
+									Name:            ipv6AppName,
+									Image:           ipv6AppImageName,
+									ImagePullPolicy: v1.PullAlways,
+									Ports: []v1.ContainerPort{
+										{
+											Protocol:      v1.ProtocolTCP,
+											ContainerPort: 80,
+										},
+									},
+								},
+							},
+							// do not deploy in fake nodes, obviously
+							NodeSelector: map[string]string{
+								"NodeType": "regular",
+							},
+						},
+					},
+				},
+			},
+			metav1.CreateOptions{},
+		)
+	cancel()
+	if err != nil {
+		return fmt.Errorf("failed to create IPv6 Deployment (%v)", err)
+	}
+
+	ts.cfg.Logger.Info("created IPv6 Deployment")
+	ts.cfg.EKSConfig.Sync()
+	return nil
+}
+
+func (ts *tester) deleteDeployment() error {
+	ts.cfg.Logger.Info("deleting IPv6 Deployment")
+	foreground := metav1.DeletePropagationForeground
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	err := ts.cfg.K8SClient.KubernetesClientSet().
+		AppsV1().
+		Deployments(ts.cfg.EKSConfig.AddOnIPv6.Namespace).
+		Delete(
+			ctx,
+			ipv6DeploymentName,
+			metav1.DeleteOptions{
+				GracePeriodSeconds: aws.Int64(0),
+				PropagationPolicy:  &foreground,
+			},
+		)
+	cancel()
+	if err != nil && !apierrs.IsNotFound(err) && !strings.Contains(err.Error(), "not found") {
+		ts.cfg.Logger.Warn("failed to delete", zap.Error(err))
+		return fmt.Errorf("failed to delete IPv6 Deployment (%v)", err)
+	}
+
+	ts.cfg.Logger.Info("deleted IPv6 Deployment")
+	ts.cfg.EKSConfig.Sync()
+	return nil
+}
+
+func (ts *tester) waitDeployment() (err error) {
+	timeout := 7*time.Minute + time.Duration(ts.cfg.EKSConfig.AddOnIPv6.DeploymentReplicas)*time.Minute
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	_, err = k8s_client.WaitForDeploymentCompletes(
+		ctx,
+		ts.cfg.Logger,
+		ts.cfg.LogWriter,
+		ts.cfg.Stopc,
+		ts.cfg.K8SClient,
+		time.Minute,
+		20*time.Second,
+		ts.cfg.EKSConfig.AddOnIPv6.Namespace,
+		ipv6DeploymentName,
+		ts.cfg.EKSConfig.AddOnIPv6.DeploymentReplicas,
+	)
+	cancel()
+	return err
+}
+
+// checkPodIPs returns an error if any Pod in the Deployment
+// is assigned a non-IPv6 address.
+func (ts *tester) checkPodIPs() error {
+	ts.cfg.Logger.Info("checking IPv6 Pod IPs")
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	pods, err := ts.cfg.K8SClient.KubernetesClientSet().
+		CoreV1().
+		Pods(ts.cfg.EKSConfig.AddOnIPv6.Namespace).
+		List(ctx, metav1.ListOptions{LabelSelector: "app.kubernetes.io/name=" + ipv6AppName})
+	cancel()
+	if err != nil {
+		return fmt.Errorf("failed to list IPv6 Pods (%v)", err)
+	}
+	if len(pods.Items) == 0 {
+		return errors.New("no IPv6 Pod found")
+	}
+
+	ips := make([]string, 0, len(pods.Items))
+	for _, pod := range pods.Items {
+		if !isIPv6(pod.Status.PodIP) {
+			return fmt.Errorf("Pod %q has non-IPv6 address %q", pod.Name, pod.Status.PodIP)
+		}
+		ips = append(ips, pod.Status.PodIP)
+	}
+	sort.Strings(ips)
+	ts.cfg.EKSConfig.AddOnIPv6.PodIPs = ips
+	ts.cfg.EKSConfig.Sync()
+
+	ts.cfg.Logger.Info("checked IPv6 Pod IPs", zap.Strings("pod-ips", ips))
+	return nil
+}
+
+func isIPv6(s string) bool {
+	ip := net.ParseIP(s)
+	return ip != nil && ip.To4() == nil
+}
+
+func (ts *tester) createService() error {
+	ts.cfg.Logger.Info("creating IPv6 Service", zap.Any("annotations", ts.cfg.EKSConfig.AddOnIPv6.ServiceAnnotations))
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	_, err := ts.cfg.K8SClient.KubernetesClientSet().
+		CoreV1().
+		Services(ts.cfg.EKSConfig.AddOnIPv6.Namespace).
+		Create(
+			ctx,
+			&v1.Service{
+				TypeMeta: metav1.TypeMeta{
+					APIVersion: "v1",
+					Kind:       "Service",
+				},
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        ipv6ServiceName,
+					Namespace:   ts.cfg.EKSConfig.AddOnIPv6.Namespace,
+					Annotations: ts.cfg.EKSConfig.AddOnIPv6.ServiceAnnotations,
+				},
+				Spec: v1.ServiceSpec{
+					Selector: map[string]string{
+						"app.kubernetes.io/name": ipv6AppName,
+					},
+					Type: v1.ServiceTypeLoadBalancer,
+					Ports: []v1.ServicePort{
+						{
+							Protocol:   v1.ProtocolTCP,
+							Port:       80,
+							TargetPort: intstr.FromInt(80),
+						},
+					},
+				},
+			},
+			metav1.CreateOptions{},
+		)
+	cancel()
+	if err != nil {
+		return fmt.Errorf("failed to create IPv6 Service (%v)", err)
+	}
+	ts.cfg.Logger.Info("created IPv6 Service")
+
+	waitDur := 5 * time.Minute
+	hostName := ""
+	retryStart := time.Now()
+	for time.Since(retryStart) < waitDur {
+		select {
+		case <-ts.cfg.Stopc:
+			return errors.New("IPv6 Service creation aborted")
+		case <-time.After(10 * time.Second):
+		}
+
+		ctx, cancel = context.WithTimeout(context.Background(), time.Minute)
+		so, err := ts.cfg.K8SClient.KubernetesClientSet().
+			CoreV1().
+			Services(ts.cfg.EKSConfig.AddOnIPv6.Namespace).
+			Get(ctx, ipv6ServiceName, metav1.GetOptions{})
+		cancel()
+		if err != nil {
+			ts.cfg.Logger.Warn("failed to get IPv6 Service; retrying", zap.Error(err))
+			continue
+		}
+		if !isIPv6(so.Spec.ClusterIP) {
+			return fmt.Errorf("IPv6 Service has non-IPv6 cluster IP %q", so.Spec.ClusterIP)
+		}
+		for _, ing := range so.Status.LoadBalancer.Ingress {
+			hostName = ing.Hostname
+			break
+		}
+		if hostName != "" {
+			ts.cfg.Logger.Info("found IPv6 Service load balancer host name", zap.String("host-name", hostName))
+			break
+		}
+		ts.cfg.Logger.Info("IPv6 Service load balancer not ready yet")
+	}
+	if hostName == "" {
+		return errors.New("failed to find IPv6 Service load balancer host name")
+	}
+
+	if err = ts.checkDualStack(hostName); err != nil {
+		return err
+	}
+	ts.cfg.EKSConfig.AddOnIPv6.URL = "http://" + hostName
+	ts.cfg.EKSConfig.Sync()
+
+	fmt.Fprintf(ts.cfg.LogWriter, "\nIPv6 ELB ARN: %s\n", ts.cfg.EKSConfig.AddOnIPv6.ELBARN)
+	fmt.Fprintf(ts.cfg.LogWriter, "IPv6 ELB Name: %s\n", ts.cfg.EKSConfig.AddOnIPv6.ELBName)
+	fmt.Fprintf(ts.cfg.LogWriter, "IPv6 URL: %s\n\n", ts.cfg.EKSConfig.AddOnIPv6.URL)
+
+	htmlChecked := false
+	retryStart = time.Now()
+	for time.Since(retryStart) < waitDur {
+		select {
+		case <-ts.cfg.Stopc:
+			return errors.New("IPv6 Service creation aborted")
+		case <-time.After(10 * time.Second):
+		}
+
+		out, err := httputil.ReadInsecure(ts.cfg.Logger, ioutil.Discard, ts.cfg.EKSConfig.AddOnIPv6.URL)
+		if err != nil {
+			ts.cfg.Logger.Warn("failed to read IPv6 Service; retrying", zap.Error(err))
+			continue
+		}
+		httpOutput := string(out)
+		fmt.Fprintf(ts.cfg.LogWriter, "\nIPv6 Service output:\n%s\n", httpOutput)
+
+		if strings.Contains(httpOutput, `<h1>Hello world!</h1>`) {
+			ts.cfg.Logger.Info("read IPv6 Service", zap.String("host-name", hostName))
+			htmlChecked = true
+			break
+		}
+		ts.cfg.Logger.Warn("unexpected IPv6 Service output; retrying")
+	}
+	if !htmlChecked {
+		return fmt.Errorf("IPv6 Service %q did not return expected HTML output", ts.cfg.EKSConfig.AddOnIPv6.URL)
+	}
+
+	ts.cfg.EKSConfig.Sync()
+	return nil
+}
+
+// checkDualStack finds the load balancer by its DNS name,
+// and returns an error if it is not dual-stack.
+func (ts *tester) checkDualStack(hostName string) (err error) {
+	var lb *elbv2.LoadBalancer
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	err = ts.cfg.ELB2API.DescribeLoadBalancersPagesWithContext(
+		ctx,
+		&elbv2.DescribeLoadBalancersInput{},
+		func(output *elbv2.DescribeLoadBalancersOutput, _ bool) bool {
+			for _, ev := range output.LoadBalancers {
+				if aws.StringValue(ev.DNSName) == hostName {
+					lb = ev
+					return false
+				}
+			}
+			return true
+		},
+	)
+	cancel()
+	if err != nil {
+		return fmt.Errorf("failed to describe load balancers (%v)", err)
+	}
+	if lb == nil {
+		return fmt.Errorf("load balancer %q not found", hostName)
+	}
+
+	ts.cfg.EKSConfig.AddOnIPv6.ELBARN = aws.StringValue(lb.LoadBalancerArn)
+	ts.cfg.EKSConfig.AddOnIPv6.ELBName = aws.StringValue(lb.LoadBalancerName)
+	ts.cfg.EKSConfig.Sync()
+
+	ipType := aws.StringValue(lb.IpAddressType)
+	if ipType != elbv2.IpAddressTypeDualstack {
+		return fmt.Errorf("load balancer %q has IP address type %q (expected %q)", ts.cfg.EKSConfig.AddOnIPv6.ELBName, ipType, elbv2.IpAddressTypeDualstack)
+	}
+	ts.cfg.Logger.Info("found dual-stack load balancer",
+		zap.String("elb-arn", ts.cfg.EKSConfig.AddOnIPv6.ELBARN),
+		zap.String("ip-address-type", ipType),
+	)
+	return nil
+}
+
+func (ts *tester) deleteService() error {
+	ts.cfg.Logger.Info("deleting IPv6 Service")
+	foreground := metav1.DeletePropagationForeground
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	err := ts.cfg.K8SClient.KubernetesClientSet().
+		CoreV1().
+		Services(ts.cfg.EKSConfig.AddOnIPv6.Namespace).
+		Delete(
+			ctx,
+			ipv6ServiceName,
+			metav1.DeleteOptions{
+				GracePeriodSeconds: aws.Int64(0),
+				PropagationPolicy:  &foreground,
+			},
+		)
+	cancel()
+	if err != nil && !apierrs.IsNotFound(err) && !strings.Contains(err.Error(), "not found") {
+		ts.cfg.Logger.Warn("failed to delete", zap.Error(err))
+		return fmt.Errorf("failed to delete IPv6 Service (%v)", err)
+	}
+
+	ts.cfg.Logger.Info("deleted IPv6 Service", zap.Error(err))
+	ts.cfg.EKSConfig.Sync()
+	return nil
+}
@@ -26,14 +26,11 @@ import (
 	"github.com/aws/aws-k8s-tester/pkg/user"
 	"github.com/aws/aws-k8s-tester/version"
 	"github.com/aws/aws-sdk-go/aws"
-	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/service/cloudformation"
 	"github.com/aws/aws-sdk-go/service/cloudformation/cloudformationiface"
 	"github.com/aws/aws-sdk-go/service/ecr/ecriface"
 	"github.com/aws/aws-sdk-go/service/eks"
 	"github.com/aws/aws-sdk-go/service/eks/eksiface"
-	"github.com/aws/aws-sdk-go/service/iam"
-	"github.com/aws/aws-sdk-go/service/iam/iamiface"
 	"github.com/aws/aws-sdk-go/service/s3/s3iface"
 	"go.uber.org/zap"
 	v1 "k8s.io/api/core/v1"
@@ -53,7 +50,6 @@ type Config struct {
 	S3API     s3iface.S3API
 	CFNAPI    cloudformationiface.CloudFormationAPI
 	EKSAPI    eksiface.EKSAPI
-	IAMAPI    iamiface.IAMAPI
 	ECRAPI    ecriface.ECRAPI
 }
 
@@ -115,8 +111,8 @@ func (ts *tester) Create() (err error) {
 	if err = ts.createS3Object(); err != nil {
 		return err
 	}
-	if err = ts.createOIDCProvider(); err != nil {
-		return err
+	if ts.cfg.EKSConfig.Status.ClusterOIDCIssuerARN == "" {
+		return errors.New("empty EKSConfig.Status.ClusterOIDCIssuerARN (OIDCProvider not created)")
 	}
 	if err = ts.createRole(); err != nil {
 		return err
@@ -193,12 +189,6 @@ func (ts *tester) Delete() error {
 	ts.cfg.Logger.Info("wait after deleting IAM Role")
 	time.Sleep(20 * time.Second)
 
-	if err := ts.deleteOIDCProvider(); err != nil {
-		errs = append(errs, fmt.Sprintf("failed to delete IAM Open ID Connect provider (%v)", err))
-	}
-	ts.cfg.Logger.Info("wait for a minute after deleting OIDC provider")
-	time.Sleep(time.Minute)
-
 	if err := k8s_client.DeleteNamespaceAndWait(
 		ts.cfg.Logger,
 		ts.cfg.K8SClient.KubernetesClientSet(),
@@ -231,78 +221,10 @@ func (ts *tester) createS3Object() (err error) {
 		ts.cfg.EKSConfig.S3.BucketName,
 		ts.cfg.EKSConfig.AddOnIRSAFargate.S3Key,
 		strings.NewReader(ts.testBody),
+		aws_s3.WithTags(ts.cfg.EKSConfig.Tags),
 	)
 }
 
-func (ts *tester) createOIDCProvider() error {
-	if ts.cfg.EKSConfig.Name == "" {
-		return errors.New("EKSConfig.Name is empty")
-	}
-	if ts.cfg.EKSConfig.Status.ClusterOIDCIssuerURL == "" {
-		return errors.New("EKSConfig.Status.ClusterOIDCIssuerURL is empty")
-	}
-
-	ts.cfg.Logger.Info("checking existing IAM Open ID Connect provider",
-		zap.String("provider-arn", ts.cfg.EKSConfig.Status.ClusterOIDCIssuerARN),
-	)
-	needCreate := false
-	_, err := ts.cfg.IAMAPI.GetOpenIDConnectProvider(&iam.GetOpenIDConnectProviderInput{
-		OpenIDConnectProviderArn: aws.String(ts.cfg.EKSConfig.Status.ClusterOIDCIssuerARN),
-	})
-	if err != nil {
-		arr := err.(awserr.Error)
-		if arr.Code() == iam.ErrCodeNoSuchEntityException {
-			needCreate = true
-		} else {
-			ts.cfg.Logger.Warn("failed to get IAM Open ID Connect provider", zap.Error(err))
-		}
-	} else {
-		ts.cfg.Logger.Info("IAM Open ID Connect provider already exists",
-			zap.String("provider-arn", ts.cfg.EKSConfig.Status.ClusterOIDCIssuerARN),
-		)
-	}
-
-	if needCreate {
-		ts.cfg.Logger.Info("creating IAM Open ID Connect provider")
-		output, err := ts.cfg.IAMAPI.CreateOpenIDConnectProvider(&iam.CreateOpenIDConnectProviderInput{ // no name or tags, keyed to URL
-			Url:            aws.String(ts.cfg.EKSConfig.Status.ClusterOIDCIssuerURL),
-			ThumbprintList: aws.StringSlice([]string{ts.cfg.EKSConfig.Status.ClusterOIDCIssuerCAThumbprint}),
-			ClientIDList:   aws.StringSlice([]string{"sts.amazonaws.com"}),
-		})
-		if err != nil {
-			return err
-		}
-		ts.cfg.EKSConfig.Status.ClusterOIDCIssuerARN = aws.StringValue(output.OpenIDConnectProviderArn)
-		ts.cfg.EKSConfig.Sync()
-		ts.cfg.Logger.Info("created IAM Open ID Connect provider", zap.String("provider-arn", ts.cfg.EKSConfig.Status.ClusterOIDCIssuerARN))
-	}
-	ts.cfg.EKSConfig.Sync()
-	return nil
-}
-
-func (ts *tester) deleteOIDCProvider() error {
-	ts.cfg.Logger.Info("deleting IAM Open ID Connect provider",
-		zap.String("provider-arn", ts.cfg.EKSConfig.Status.ClusterOIDCIssuerARN),
-	)
-	_, err := ts.cfg.IAMAPI.DeleteOpenIDConnectProvider(&iam.DeleteOpenIDConnectProviderInput{
-		OpenIDConnectProviderArn: aws.String(ts.cfg.EKSConfig.Status.ClusterOIDCIssuerARN),
-	})
-	if err != nil {
-		arr := err.(awserr.Error)
-		if arr.Code() == iam.ErrCodeNoSuchEntityException {
-			ts.cfg.Logger.Warn("IAM Open ID Connect provider already deleted", zap.Error(err))
-		} else {
-			ts.cfg.Logger.Warn("failed to delete IAM Open ID Connect provider", zap.Error(err))
-		}
-	} else {
-		ts.cfg.Logger.Info("deleted IAM Open ID Connect provider",
-			zap.String("provider-arn", ts.cfg.EKSConfig.Status.ClusterOIDCIssuerARN),
-		)
-	}
-	ts.cfg.EKSConfig.Sync()
-	return nil
-}
-
 // TemplateRole is the CloudFormation template for EKS IRSA Fargate role.
 const TemplateRole = `
 ---
@@ -319,6 +241,16 @@ Parameters:
     Type: String
     Description: EKS IRSA Fargate Provider ARN
 
+  RolePath:
+    Type: String
+    Default: /
+    Description: The path of the IRSA Fargate role
+
+  RolePermissionsBoundaryARN:
+    Type: String
+    Default: ''
+    Description: The permissions boundary policy ARN of the IRSA Fargate role (empty for none)
+
   Namespace:
     Type: String
     Description: The namespace for the IRSA Fargate role
@@ -337,6 +269,10 @@ Parameters:
     Default: 'arn:aws:iam::aws:policy/AmazonEKSFargatePodExecutionRolePolicy'
     Description: EKS IRSA Fargate policy ARNs
 
+Conditions:
+
+  HasRolePermissionsBoundaryARN: !Not [!Equals [!Ref RolePermissionsBoundaryARN, '']]
+
 Resources:
 
   Role:
@@ -360,7 +296,8 @@ Resources:
           Action:
           - sts:AssumeRole
       ManagedPolicyArns: !Ref RoleManagedPolicyARNs
-      Path: /
+      Path: !Ref RolePath
+      PermissionsBoundary: !If [HasRolePermissionsBoundaryARN, !Ref RolePermissionsBoundaryARN, !Ref 'AWS::NoValue']
       Policies:
       - PolicyName: !Join ['-', [!Ref RoleName, 's3-policy']]
         PolicyDocument:
@@ -418,6 +355,7 @@ func (ts *tester) createRole() error {
 		ts.cfg.EKSConfig.S3.BucketName,
 		ts.cfg.EKSConfig.AddOnIRSAFargate.RoleCFNStackYAMLS3Key,
 		ts.cfg.EKSConfig.AddOnIRSAFargate.RoleCFNStackYAMLPath,
+		aws_s3.WithTags(ts.cfg.EKSConfig.Tags),
 	); err != nil {
 		return err
 	}
@@ -430,17 +368,25 @@ func (ts *tester) createRole() error {
 		Capabilities: aws.StringSlice([]string{"CAPABILITY_NAMED_IAM"}),
 		OnFailure:    aws.String(cloudformation.OnFailureDelete),
 		TemplateBody: aws.String(buf.String()),
-		Tags: cfn.NewTags(map[string]string{
+		Tags: cfn.NewTags(ts.cfg.EKSConfig.MergeTags(map[string]string{
 			"Kind":                   "aws-k8s-tester",
 			"Name":                   ts.cfg.EKSConfig.Name,
 			"aws-k8s-tester-version": version.ReleaseVersion,
 			"User":                   user.Get(),
-		}),
+		})),
 		Parameters: []*cloudformation.Parameter{
 			{
 				ParameterKey:   aws.String("RoleName"),
 				ParameterValue: aws.String(ts.cfg.EKSConfig.AddOnIRSAFargate.RoleName),
 			},
+			{
+				ParameterKey:   aws.String("RolePath"),
+				ParameterValue: aws.String(ts.cfg.EKSConfig.IAMPath),
+			},
+			{
+				ParameterKey:   aws.String("RolePermissionsBoundaryARN"),
+				ParameterValue: aws.String(ts.cfg.EKSConfig.IAMPermissionsBoundaryARN),
+			},
 			{
 				ParameterKey:   aws.String("IssuerARN"),
 				ParameterValue: aws.String(ts.cfg.EKSConfig.Status.ClusterOIDCIssuerARN),
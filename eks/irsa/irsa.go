@@ -25,12 +25,9 @@ import (
 	"github.com/aws/aws-k8s-tester/pkg/user"
 	"github.com/aws/aws-k8s-tester/version"
 	"github.com/aws/aws-sdk-go/aws"
-	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/service/cloudformation"
 	"github.com/aws/aws-sdk-go/service/cloudformation/cloudformationiface"
 	"github.com/aws/aws-sdk-go/service/ecr/ecriface"
-	"github.com/aws/aws-sdk-go/service/iam"
-	"github.com/aws/aws-sdk-go/service/iam/iamiface"
 	"github.com/aws/aws-sdk-go/service/s3/s3iface"
 	"go.uber.org/zap"
 	apps_v1 "k8s.io/api/apps/v1"
@@ -50,7 +47,6 @@ type Config struct {
 	K8SClient k8s_client.EKS
 	S3API     s3iface.S3API
 	CFNAPI    cloudformationiface.CloudFormationAPI
-	IAMAPI    iamiface.IAMAPI
 	ECRAPI    ecriface.ECRAPI
 }
 
@@ -113,8 +109,8 @@ func (ts *tester) Create() (err error) {
 	if err = ts.createS3Object(); err != nil {
 		return err
 	}
-	if err = ts.createOIDCProvider(); err != nil {
-		return err
+	if ts.cfg.EKSConfig.Status.ClusterOIDCIssuerARN == "" {
+		return errors.New("empty EKSConfig.Status.ClusterOIDCIssuerARN (OIDCProvider not created)")
 	}
 	if err = ts.createRole(); err != nil {
 		return err
@@ -187,12 +183,6 @@ func (ts *tester) Delete() error {
 	ts.cfg.Logger.Info("wait after deleting IAM Role")
 	time.Sleep(20 * time.Second)
 
-	if err := ts.deleteOIDCProvider(); err != nil {
-		errs = append(errs, fmt.Sprintf("failed to delete IAM Open ID Connect provider (%v)", err))
-	}
-	ts.cfg.Logger.Info("wait for a minute after deleting OIDC provider")
-	time.Sleep(time.Minute)
-
 	if err := k8s_client.DeleteNamespaceAndWait(
 		ts.cfg.Logger,
 		ts.cfg.K8SClient.KubernetesClientSet(),
@@ -225,78 +215,10 @@ func (ts *tester) createS3Object() (err error) {
 		ts.cfg.EKSConfig.S3.BucketName,
 		ts.cfg.EKSConfig.AddOnIRSA.S3Key,
 		strings.NewReader(ts.testBody),
+		aws_s3.WithTags(ts.cfg.EKSConfig.Tags),
 	)
 }
 
-func (ts *tester) createOIDCProvider() error {
-	if ts.cfg.EKSConfig.Name == "" {
-		return errors.New("EKSConfig.Name is empty")
-	}
-	if ts.cfg.EKSConfig.Status.ClusterOIDCIssuerURL == "" {
-		return errors.New("EKSConfig.Status.ClusterOIDCIssuerURL is empty")
-	}
-
-	ts.cfg.Logger.Info("checking existing IAM Open ID Connect provider",
-		zap.String("provider-arn", ts.cfg.EKSConfig.Status.ClusterOIDCIssuerARN),
-	)
-	needCreate := false
-	_, err := ts.cfg.IAMAPI.GetOpenIDConnectProvider(&iam.GetOpenIDConnectProviderInput{
-		OpenIDConnectProviderArn: aws.String(ts.cfg.EKSConfig.Status.ClusterOIDCIssuerARN),
-	})
-	if err != nil {
-		arr := err.(awserr.Error)
-		if arr.Code() == iam.ErrCodeNoSuchEntityException {
-			needCreate = true
-		} else {
-			ts.cfg.Logger.Warn("failed to get IAM Open ID Connect provider", zap.Error(err))
-		}
-	} else {
-		ts.cfg.Logger.Info("IAM Open ID Connect provider already exists",
-			zap.String("provider-arn", ts.cfg.EKSConfig.Status.ClusterOIDCIssuerARN),
-		)
-	}
-
-	if needCreate {
-		ts.cfg.Logger.Info("creating IAM Open ID Connect provider")
-		output, err := ts.cfg.IAMAPI.CreateOpenIDConnectProvider(&iam.CreateOpenIDConnectProviderInput{ // no name or tags, keyed to URL
-			Url:            aws.String(ts.cfg.EKSConfig.Status.ClusterOIDCIssuerURL),
-			ThumbprintList: aws.StringSlice([]string{ts.cfg.EKSConfig.Status.ClusterOIDCIssuerCAThumbprint}),
-			ClientIDList:   aws.StringSlice([]string{"sts.amazonaws.com"}),
-		})
-		if err != nil {
-			return err
-		}
-		ts.cfg.EKSConfig.Status.ClusterOIDCIssuerARN = aws.StringValue(output.OpenIDConnectProviderArn)
-		ts.cfg.EKSConfig.Sync()
-		ts.cfg.Logger.Info("created IAM Open ID Connect provider", zap.String("provider-arn", ts.cfg.EKSConfig.Status.ClusterOIDCIssuerARN))
-	}
-	ts.cfg.EKSConfig.Sync()
-	return nil
-}
-
-func (ts *tester) deleteOIDCProvider() error {
-	ts.cfg.Logger.Info("deleting IAM Open ID Connect provider",
-		zap.String("provider-arn", ts.cfg.EKSConfig.Status.ClusterOIDCIssuerARN),
-	)
-	_, err := ts.cfg.IAMAPI.DeleteOpenIDConnectProvider(&iam.DeleteOpenIDConnectProviderInput{
-		OpenIDConnectProviderArn: aws.String(ts.cfg.EKSConfig.Status.ClusterOIDCIssuerARN),
-	})
-	if err != nil {
-		arr := err.(awserr.Error)
-		if arr.Code() == iam.ErrCodeNoSuchEntityException {
-			ts.cfg.Logger.Warn("IAM Open ID Connect provider already deleted", zap.Error(err))
-		} else {
-			ts.cfg.Logger.Warn("failed to delete IAM Open ID Connect provider", zap.Error(err))
-		}
-	} else {
-		ts.cfg.Logger.Info("deleted IAM Open ID Connect provider",
-			zap.String("provider-arn", ts.cfg.EKSConfig.Status.ClusterOIDCIssuerARN),
-		)
-	}
-	ts.cfg.EKSConfig.Sync()
-	return nil
-}
-
 // TemplateRole is the CloudFormation template for EKS IRSA role.
 const TemplateRole = `
 ---
@@ -313,6 +235,16 @@ Parameters:
     Type: String
     Description: EKS IRSA Provider ARN
 
+  RolePath:
+    Type: String
+    Default: /
+    Description: The path of the IRSA role
+
+  RolePermissionsBoundaryARN:
+    Type: String
+    Default: ''
+    Description: The permissions boundary policy ARN of the IRSA role (empty for none)
+
   Namespace:
     Type: String
     Description: The namespace for the IRSA role
@@ -321,12 +253,18 @@ Parameters:
     Type: String
     Description: The ServiceAccount name for the IRSA role
 
+Conditions:
+
+  HasRolePermissionsBoundaryARN: !Not [!Equals [!Ref RolePermissionsBoundaryARN, '']]
+
 Resources:
 
   IRSARole:
     Type: AWS::IAM::Role
     Properties:
       RoleName: !Ref RoleName
+      Path: !Ref RolePath
+      PermissionsBoundary: !If [HasRolePermissionsBoundaryARN, !Ref RolePermissionsBoundaryARN, !Ref 'AWS::NoValue']
       AssumeRolePolicyDocument:
         Version: '2012-10-17'
         Statement:
@@ -395,6 +333,7 @@ func (ts *tester) createRole() error {
 		ts.cfg.EKSConfig.S3.BucketName,
 		ts.cfg.EKSConfig.AddOnIRSA.RoleCFNStackYAMLS3Key,
 		ts.cfg.EKSConfig.AddOnIRSA.RoleCFNStackYAMLPath,
+		aws_s3.WithTags(ts.cfg.EKSConfig.Tags),
 	); err != nil {
 		return err
 	}
@@ -407,17 +346,25 @@ func (ts *tester) createRole() error {
 		Capabilities: aws.StringSlice([]string{"CAPABILITY_NAMED_IAM"}),
 		OnFailure:    aws.String(cloudformation.OnFailureDelete),
 		TemplateBody: aws.String(buf.String()),
-		Tags: cfn.NewTags(map[string]string{
+		Tags: cfn.NewTags(ts.cfg.EKSConfig.MergeTags(map[string]string{
 			"Kind":                   "aws-k8s-tester",
 			"Name":                   ts.cfg.EKSConfig.Name,
 			"aws-k8s-tester-version": version.ReleaseVersion,
 			"User":                   user.Get(),
-		}),
+		})),
 		Parameters: []*cloudformation.Parameter{
 			{
 				ParameterKey:   aws.String("RoleName"),
 				ParameterValue: aws.String(ts.cfg.EKSConfig.AddOnIRSA.RoleName),
 			},
+			{
+				ParameterKey:   aws.String("RolePath"),
+				ParameterValue: aws.String(ts.cfg.EKSConfig.IAMPath),
+			},
+			{
+				ParameterKey:   aws.String("RolePermissionsBoundaryARN"),
+				ParameterValue: aws.String(ts.cfg.EKSConfig.IAMPermissionsBoundaryARN),
+			},
 			{
 				ParameterKey:   aws.String("IssuerARN"),
 				ParameterValue: aws.String(ts.cfg.EKSConfig.Status.ClusterOIDCIssuerARN),
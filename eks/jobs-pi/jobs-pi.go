@@ -153,7 +153,8 @@ func (ts *tester) Delete() (err error) {
 }
 
 const (
-	jobName        = "job-pi"
+	jobName = "job-pi"
+	// "perl" official image is multi-arch, so that the Job runs on both amd64 and arm64 nodes
 	jobPiImageName = "perl"
 )
 
@@ -11,6 +11,7 @@ import (
 	"strings"
 	"time"
 
+	aws_s3 "github.com/aws/aws-k8s-tester/pkg/aws/s3"
 	"github.com/aws/aws-k8s-tester/pkg/fileutil"
 	"github.com/aws/aws-k8s-tester/pkg/user"
 	aws_v2 "github.com/aws/aws-sdk-go-v2/aws"
@@ -96,6 +97,7 @@ func (ts *Tester) createKeyPair() (err error) {
 					"Kind": "aws-k8s-tester",
 					"User": user.Get(),
 				},
+				Tagging: aws_s3.NewTagging(ts.cfg.Tags),
 			})
 		if err == nil {
 			ts.lg.Info("uploaded EC2 private key",
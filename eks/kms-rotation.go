@@ -0,0 +1,257 @@
+package eks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-k8s-tester/eks/cluster/wait"
+	"github.com/aws/aws-k8s-tester/pkg/ctxutil"
+	k8s_client "github.com/aws/aws-k8s-tester/pkg/k8s-client"
+	"github.com/aws/aws-sdk-go/aws"
+	aws_eks "github.com/aws/aws-sdk-go/service/eks"
+	"github.com/aws/aws-sdk-go/service/kms"
+	"go.uber.org/zap"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+)
+
+// kmsEncryptionTimestampAnnotation is the annotation updated on every secret
+// to rewrite it, so that the secret is re-encrypted with the rotated key.
+const kmsEncryptionTimestampAnnotation = "kms-encryption-timestamp"
+
+// runKMSRotation writes the test secrets, rotates the cluster envelope
+// encryption key (or associates one if the cluster has none), re-encrypts
+// all secrets, and checks that the test secrets remain readable and
+// new secrets encrypt successfully. The namespace is deleted afterwards.
+func (ts *Tester) runKMSRotation() (err error) {
+	if ts.k8sClient == nil {
+		return errors.New("nil k8s client")
+	}
+	cur := ts.cfg.KMSRotation
+	out, err := ts.eksAPIForCluster.DescribeCluster(&aws_eks.DescribeClusterInput{
+		Name: aws.String(ts.cfg.Name),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to describe cluster %q (%v)", ts.cfg.Name, err)
+	}
+	cur.KeyARN = clusterKeyARN(out.Cluster)
+	if cur.KeyARN == "" && ts.cfg.Encryption.CMKARN == "" {
+		return fmt.Errorf("cluster %q has no envelope encryption and no Encryption.CMKARN to associate", ts.cfg.Name)
+	}
+	ts.cfg.Sync()
+
+	cli := ts.k8sClient.KubernetesClientSet()
+	ns := cur.Namespace
+	if err = k8s_client.CreateNamespace(ts.lg, cli, ns); err != nil {
+		return err
+	}
+	defer func() {
+		if derr := k8s_client.DeleteNamespaceAndWait(
+			ts.lg,
+			cli,
+			ns,
+			k8s_client.DefaultNamespaceDeletionInterval,
+			k8s_client.DefaultNamespaceDeletionTimeout,
+			k8s_client.WithForceDelete(true),
+		); derr != nil && err == nil {
+			err = fmt.Errorf("failed to delete namespace %q (%v)", ns, derr)
+		}
+	}()
+
+	before, err := ts.writeKMSRotationSecrets(cli, "before")
+	if err != nil {
+		return err
+	}
+
+	if cur.KeyARN == "" {
+		if err = ts.associateEncryptionKey(ts.cfg.Encryption.CMKARN); err != nil {
+			return err
+		}
+		cur.KeyARN = ts.cfg.Encryption.CMKARN
+		cur.KeyAssociated = true
+	} else if err = ts.rotateEncryptionKey(cur.KeyARN); err != nil {
+		return err
+	}
+	ts.cfg.Sync()
+
+	cur.ReencryptedSecrets, err = ts.reencryptSecrets(cli)
+	ts.cfg.Sync()
+	if err != nil {
+		return err
+	}
+
+	if err = ts.readKMSRotationSecrets(cli, before); err != nil {
+		return err
+	}
+	after, err := ts.writeKMSRotationSecrets(cli, "after")
+	if err != nil {
+		return err
+	}
+	if err = ts.readKMSRotationSecrets(cli, after); err != nil {
+		return err
+	}
+
+	ts.lg.Info("checked KMS key rotation",
+		zap.String("key-arn", cur.KeyARN),
+		zap.Bool("key-associated", cur.KeyAssociated),
+		zap.Int("reencrypted-secrets", cur.ReencryptedSecrets),
+	)
+	return nil
+}
+
+// clusterKeyARN returns the KMS key ARN of the cluster secrets encryption,
+// or empty if the cluster has no envelope encryption.
+func clusterKeyARN(cl *aws_eks.Cluster) string {
+	if cl == nil {
+		return ""
+	}
+	for _, ec := range cl.EncryptionConfig {
+		if ec.Provider == nil {
+			continue
+		}
+		for _, r := range ec.Resources {
+			if aws.StringValue(r) == "secrets" {
+				return aws.StringValue(ec.Provider.KeyArn)
+			}
+		}
+	}
+	return ""
+}
+
+// associateEncryptionKey enables the envelope encryption of the existing
+// cluster, and waits for the update. EKS re-encrypts the existing secrets.
+func (ts *Tester) associateEncryptionKey(keyARN string) error {
+	ts.lg.Info("associating encryption key", zap.String("key-arn", keyARN))
+	out, err := ts.eksAPIForCluster.AssociateEncryptionConfig(&aws_eks.AssociateEncryptionConfigInput{
+		ClusterName: aws.String(ts.cfg.Name),
+		EncryptionConfig: []*aws_eks.EncryptionConfig{
+			{
+				Resources: aws.StringSlice([]string{"secrets"}),
+				Provider:  &aws_eks.Provider{KeyArn: aws.String(keyARN)},
+			},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to associate encryption key %q (%v)", keyARN, err)
+	}
+	reqID := ""
+	if out.Update != nil {
+		reqID = aws.StringValue(out.Update.Id)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Hour)
+	ctx, stopCancel := ctxutil.WithStopc(ctx, ts.stopCreationCh)
+	_, err = wait.WaitForUpdateStatus(
+		ctx,
+		ts.lg,
+		ts.logWriter,
+		ts.eksAPIForCluster,
+		ts.cfg.Name,
+		reqID,
+		aws_eks.UpdateStatusSuccessful,
+		time.Minute,
+		30*time.Second,
+	)
+	stopCancel()
+	cancel()
+	if err != nil {
+		return fmt.Errorf("failed to wait for encryption key association %q (%v)", reqID, err)
+	}
+	ts.lg.Info("associated encryption key", zap.String("key-arn", keyARN), zap.String("request-id", reqID))
+	return nil
+}
+
+// rotateEncryptionKey enables the automatic rotation of the key material.
+// The associated key cannot be changed, and the previous key material
+// remains available to decrypt the data keys of the existing secrets.
+func (ts *Tester) rotateEncryptionKey(keyARN string) error {
+	ts.lg.Info("enabling encryption key rotation", zap.String("key-arn", keyARN))
+	if _, err := ts.kmsAPI.EnableKeyRotation(&kms.EnableKeyRotationInput{
+		KeyId: aws.String(keyARN),
+	}); err != nil {
+		return fmt.Errorf("failed to enable key rotation %q (%v)", keyARN, err)
+	}
+	out, err := ts.kmsAPI.GetKeyRotationStatus(&kms.GetKeyRotationStatusInput{
+		KeyId: aws.String(keyARN),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to get key rotation status %q (%v)", keyARN, err)
+	}
+	if !aws.BoolValue(out.KeyRotationEnabled) {
+		return fmt.Errorf("key rotation not enabled for %q", keyARN)
+	}
+	ts.lg.Info("enabled encryption key rotation", zap.String("key-arn", keyARN))
+	return nil
+}
+
+// reencryptSecrets rewrites all secrets in all namespaces,
+// so that they are encrypted with the new data keys.
+// ref. https://docs.aws.amazon.com/eks/latest/userguide/enable-kms.html
+func (ts *Tester) reencryptSecrets(cli kubernetes.Interface) (int, error) {
+	secrets, err := ts.k8sClient.ListSecrets(metav1.NamespaceAll, 1000, 5*time.Second)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list secrets (%v)", err)
+	}
+	patch := []byte(fmt.Sprintf(`{"metadata":{"annotations":{%q:%q}}}`, kmsEncryptionTimestampAnnotation, time.Now().UTC().Format(time.RFC3339)))
+	ts.lg.Info("re-encrypting secrets", zap.Int("secrets", len(secrets)))
+	n := 0
+	for _, s := range secrets {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		_, err = cli.CoreV1().Secrets(s.Namespace).Patch(ctx, s.Name, types.MergePatchType, patch, metav1.PatchOptions{})
+		cancel()
+		if err != nil {
+			return n, fmt.Errorf("failed to re-encrypt secret %s/%s (%v)", s.Namespace, s.Name, err)
+		}
+		n++
+	}
+	ts.lg.Info("re-encrypted secrets", zap.Int("secrets", n))
+	return n, nil
+}
+
+// writeKMSRotationSecrets creates the test secrets,
+// and returns the expected data by the secret name.
+func (ts *Tester) writeKMSRotationSecrets(cli kubernetes.Interface, prefix string) (map[string]string, error) {
+	written := make(map[string]string, ts.cfg.KMSRotation.Objects)
+	for i := 0; i < ts.cfg.KMSRotation.Objects; i++ {
+		name := fmt.Sprintf("%s-%d", prefix, i)
+		value := fmt.Sprintf("%s-%s-%d", ts.cfg.Name, prefix, time.Now().UnixNano())
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		_, err := cli.CoreV1().Secrets(ts.cfg.KMSRotation.Namespace).Create(ctx, &v1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: ts.cfg.KMSRotation.Namespace,
+			},
+			Type: v1.SecretTypeOpaque,
+			Data: map[string][]byte{"value": []byte(value)},
+		}, metav1.CreateOptions{})
+		cancel()
+		if err != nil {
+			return nil, fmt.Errorf("failed to create secret %q (%v)", name, err)
+		}
+		written[name] = value
+	}
+	ts.lg.Info("created secrets", zap.String("prefix", prefix), zap.Int("secrets", len(written)))
+	return written, nil
+}
+
+// readKMSRotationSecrets reads back the test secrets, which fails
+// if a secret cannot be decrypted.
+func (ts *Tester) readKMSRotationSecrets(cli kubernetes.Interface, expected map[string]string) error {
+	for name, value := range expected {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		s, err := cli.CoreV1().Secrets(ts.cfg.KMSRotation.Namespace).Get(ctx, name, metav1.GetOptions{})
+		cancel()
+		if err != nil {
+			return fmt.Errorf("failed to read secret %q (%v)", name, err)
+		}
+		if v := string(s.Data["value"]); v != value {
+			return fmt.Errorf("unexpected secret %q value %q (expected %q)", name, v, value)
+		}
+	}
+	ts.lg.Info("read secrets", zap.Int("secrets", len(expected)))
+	return nil
+}
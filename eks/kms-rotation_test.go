@@ -0,0 +1,28 @@
+package eks
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	aws_eks "github.com/aws/aws-sdk-go/service/eks"
+)
+
+func TestClusterKeyARN(t *testing.T) {
+	tt := []struct {
+		cl  *aws_eks.Cluster
+		exp string
+	}{
+		{nil, ""},
+		{&aws_eks.Cluster{}, ""},
+		{&aws_eks.Cluster{EncryptionConfig: []*aws_eks.EncryptionConfig{{Resources: aws.StringSlice([]string{"secrets"})}}}, ""},
+		{&aws_eks.Cluster{EncryptionConfig: []*aws_eks.EncryptionConfig{{
+			Resources: aws.StringSlice([]string{"secrets"}),
+			Provider:  &aws_eks.Provider{KeyArn: aws.String("arn:aws:kms:us-west-2:123:key/abc")},
+		}}}, "arn:aws:kms:us-west-2:123:key/abc"},
+	}
+	for i, tv := range tt {
+		if v := clusterKeyARN(tv.cl); v != tv.exp {
+			t.Fatalf("#%d: expected %q, got %q", i, tv.exp, v)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package eks
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"time"
+
+	aws_s3 "github.com/aws/aws-k8s-tester/pkg/aws/s3"
+	"github.com/aws/aws-k8s-tester/pkg/fileutil"
+	"go.uber.org/zap"
+)
+
+// startLiveReload periodically reads the live-reload file (or S3 object),
+// and applies the updated add-on parameters to the configuration,
+// which the running testers (e.g. stresser) pick up without restart.
+func (ts *Tester) startLiveReload(donec chan struct{}) {
+	ts.lg.Info("starting live-reload",
+		zap.String("path", ts.cfg.LiveReload.Path),
+		zap.String("s3-key", ts.cfg.LiveReload.S3Key),
+		zap.Duration("interval", ts.cfg.LiveReload.Interval),
+	)
+	ticker := time.NewTicker(ts.cfg.LiveReload.Interval)
+	defer ticker.Stop()
+
+	var prev []byte
+	for {
+		select {
+		case <-ts.stopCreationCh:
+			ts.lg.Info("live-reload stopped")
+			return
+		case <-donec:
+			ts.lg.Info("live-reload done")
+			return
+		case <-ticker.C:
+		}
+
+		d, err := ts.readLiveReload()
+		if err != nil {
+			ts.lg.Warn("failed to read live-reload", zap.Error(err))
+			continue
+		}
+		if len(d) == 0 || bytes.Equal(d, prev) {
+			continue
+		}
+		prev = d
+
+		changes, err := ts.cfg.ApplyLiveReload(d)
+		if err != nil {
+			ts.lg.Warn("failed to apply live-reload", zap.Error(err))
+			continue
+		}
+		if len(changes) == 0 {
+			continue
+		}
+		ts.lg.Info("applied live-reload", zap.Strings("changes", changes))
+		if err = ts.cfg.Sync(); err != nil {
+			ts.lg.Warn("failed to sync config after live-reload", zap.Error(err))
+		}
+	}
+}
+
+func (ts *Tester) readLiveReload() ([]byte, error) {
+	if ts.cfg.LiveReload.S3Key == "" {
+		if !fileutil.Exist(ts.cfg.LiveReload.Path) {
+			return nil, nil
+		}
+		return ioutil.ReadFile(ts.cfg.LiveReload.Path)
+	}
+
+	p, err := aws_s3.DownloadToTempFile(ts.lg, ts.s3API, ts.cfg.S3.BucketName, ts.cfg.LiveReload.S3Key, aws_s3.WithTimeout(time.Minute))
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(p)
+	return ioutil.ReadFile(p)
+}
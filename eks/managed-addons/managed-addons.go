@@ -0,0 +1,414 @@
+// Package managedaddons implements tester for EKS managed add-ons, which
+// installs, upgrades, and removes the EKS managed add-ons (e.g. "vpc-cni",
+// "coredns", "kube-proxy", "aws-ebs-csi-driver") via EKS API, so that
+// the add-on version bumps can be regression-tested.
+// ref. https://docs.aws.amazon.com/eks/latest/userguide/eks-add-ons.html
+package managedaddons
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-k8s-tester/eks/cluster/wait"
+	eks_tester "github.com/aws/aws-k8s-tester/eks/tester"
+	"github.com/aws/aws-k8s-tester/eksconfig"
+	k8s_client "github.com/aws/aws-k8s-tester/pkg/k8s-client"
+	"github.com/aws/aws-k8s-tester/pkg/timeutil"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	aws_eks "github.com/aws/aws-sdk-go/service/eks"
+	"github.com/aws/aws-sdk-go/service/eks/eksiface"
+	"go.uber.org/zap"
+)
+
+// Config defines EKS managed add-ons tester configuration.
+type Config struct {
+	Logger    *zap.Logger
+	LogWriter io.Writer
+	Stopc     chan struct{}
+	EKSConfig *eksconfig.Config
+	K8SClient k8s_client.EKS
+	EKSAPI    eksiface.EKSAPI
+}
+
+var pkgName = reflect.TypeOf(tester{}).PkgPath()
+
+func (ts *tester) Name() string { return pkgName }
+
+// New creates a new EKS managed add-ons tester.
+func New(cfg Config) eks_tester.Tester {
+	cfg.Logger.Info("creating tester", zap.String("tester", pkgName))
+	return &tester{cfg: cfg}
+}
+
+type tester struct {
+	cfg Config
+}
+
+func (ts *tester) Create() error {
+	if !ts.cfg.EKSConfig.IsEnabledAddOnManagedAddons() {
+		ts.cfg.Logger.Info("skipping tester.Create", zap.String("tester", pkgName))
+		return nil
+	}
+	if ts.cfg.EKSConfig.AddOnManagedAddons.Created {
+		ts.cfg.Logger.Info("skipping tester.Create", zap.String("tester", pkgName))
+		return nil
+	}
+
+	ts.cfg.Logger.Info("starting tester.Create", zap.String("tester", pkgName))
+	ts.cfg.EKSConfig.AddOnManagedAddons.Created = true
+	ts.cfg.EKSConfig.Sync()
+	createStart := time.Now()
+	defer func() {
+		createEnd := time.Now()
+		ts.cfg.EKSConfig.AddOnManagedAddons.TimeFrameCreate = timeutil.NewTimeFrame(createStart, createEnd)
+		ts.cfg.EKSConfig.Sync()
+	}()
+
+	for _, name := range ts.addonNames() {
+		if err := ts.createAddon(name); err != nil {
+			return err
+		}
+	}
+	for _, name := range ts.addonNames() {
+		if ts.cfg.EKSConfig.AddOnManagedAddons.Addons[name].UpgradeVersion == "" {
+			continue
+		}
+		if err := ts.upgradeAddon(name); err != nil {
+			return err
+		}
+	}
+	ts.cfg.EKSConfig.Sync()
+	return nil
+}
+
+func (ts *tester) Delete() error {
+	if !ts.cfg.EKSConfig.IsEnabledAddOnManagedAddons() {
+		ts.cfg.Logger.Info("skipping tester.Delete", zap.String("tester", pkgName))
+		return nil
+	}
+	if !ts.cfg.EKSConfig.AddOnManagedAddons.Created {
+		ts.cfg.Logger.Info("skipping tester.Delete", zap.String("tester", pkgName))
+		return nil
+	}
+
+	ts.cfg.Logger.Info("starting tester.Delete", zap.String("tester", pkgName))
+	deleteStart := time.Now()
+	defer func() {
+		deleteEnd := time.Now()
+		ts.cfg.EKSConfig.AddOnManagedAddons.TimeFrameDelete = timeutil.NewTimeFrame(deleteStart, deleteEnd)
+		ts.cfg.EKSConfig.Sync()
+	}()
+
+	var errs []string
+
+	// reverse order of creation
+	names := ts.addonNames()
+	for i := len(names) - 1; i >= 0; i-- {
+		if err := ts.deleteAddon(names[i]); err != nil {
+			errs = append(errs, fmt.Sprintf("failed to delete addon %q (%v)", names[i], err))
+		}
+	}
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, ", "))
+	}
+
+	ts.cfg.EKSConfig.AddOnManagedAddons.Created = false
+	ts.cfg.EKSConfig.Sync()
+	return nil
+}
+
+func (ts *tester) addonNames() (names []string) {
+	for name := range ts.cfg.EKSConfig.AddOnManagedAddons.Addons {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (ts *tester) createAddon(name string) error {
+	cur := ts.cfg.EKSConfig.AddOnManagedAddons.Addons[name]
+	version, err := ts.resolveVersion(name, cur.Version)
+	if err != nil {
+		return err
+	}
+
+	ts.cfg.Logger.Info("creating addon",
+		zap.String("addon-name", name),
+		zap.String("addon-version", version),
+		zap.String("resolve-conflicts", cur.ResolveConflicts),
+	)
+	input := &aws_eks.CreateAddonInput{
+		ClusterName:      aws.String(ts.cfg.EKSConfig.Name),
+		AddonName:        aws.String(name),
+		ResolveConflicts: aws.String(cur.ResolveConflicts),
+	}
+	if len(ts.cfg.EKSConfig.Tags) > 0 {
+		input.Tags = aws.StringMap(ts.cfg.EKSConfig.Tags)
+	}
+	if version != "" {
+		input.AddonVersion = aws.String(version)
+	}
+	if cur.ServiceAccountRoleARN != "" {
+		input.ServiceAccountRoleArn = aws.String(cur.ServiceAccountRoleARN)
+	}
+	if cur.ConfigurationValues != "" {
+		input.ConfigurationValues = aws.String(cur.ConfigurationValues)
+	}
+	_, err = ts.cfg.EKSAPI.CreateAddon(input)
+	if err != nil {
+		awsErr, ok := err.(awserr.Error)
+		if !ok || awsErr.Code() != aws_eks.ErrCodeResourceInUseException {
+			return fmt.Errorf("failed to create addon %q (%v)", name, err)
+		}
+		// e.g. installed with the cluster, keep on delete
+		ts.cfg.Logger.Warn("addon already exists", zap.String("addon-name", name), zap.Error(err))
+		cur.Existing = true
+		ts.cfg.EKSConfig.AddOnManagedAddons.Addons[name] = cur
+		ts.cfg.EKSConfig.Sync()
+	}
+
+	addon, err := ts.waitAddon(name, aws_eks.AddonStatusActive, 30*time.Second)
+	if err != nil {
+		return err
+	}
+	if version != "" && aws.StringValue(addon.AddonVersion) != version {
+		if !cur.Existing {
+			return fmt.Errorf("addon %q version %q, expected %q", name, aws.StringValue(addon.AddonVersion), version)
+		}
+		return ts.updateAddon(name, version, cur.ResolveConflicts)
+	}
+	ts.cfg.Logger.Info("created addon", zap.String("addon-name", name), zap.String("addon-version", aws.StringValue(addon.AddonVersion)))
+	return nil
+}
+
+func (ts *tester) upgradeAddon(name string) error {
+	cur := ts.cfg.EKSConfig.AddOnManagedAddons.Addons[name]
+	version, err := ts.resolveVersion(name, cur.UpgradeVersion)
+	if err != nil {
+		return err
+	}
+	if version == cur.InstalledVersion {
+		ts.cfg.Logger.Info("addon already upgraded; skipping", zap.String("addon-name", name), zap.String("addon-version", version))
+		return nil
+	}
+	return ts.updateAddon(name, version, cur.ResolveConflicts)
+}
+
+// updateAddon updates the add-on version, and waits for the add-on
+// to become active with the version.
+func (ts *tester) updateAddon(name string, version string, resolveConflicts string) error {
+	cur := ts.cfg.EKSConfig.AddOnManagedAddons.Addons[name]
+	ts.cfg.Logger.Info("updating addon",
+		zap.String("addon-name", name),
+		zap.String("from-addon-version", cur.InstalledVersion),
+		zap.String("to-addon-version", version),
+		zap.String("resolve-conflicts", resolveConflicts),
+	)
+	input := &aws_eks.UpdateAddonInput{
+		ClusterName:      aws.String(ts.cfg.EKSConfig.Name),
+		AddonName:        aws.String(name),
+		AddonVersion:     aws.String(version),
+		ResolveConflicts: aws.String(resolveConflicts),
+	}
+	if cur.ServiceAccountRoleARN != "" {
+		input.ServiceAccountRoleArn = aws.String(cur.ServiceAccountRoleARN)
+	}
+	if cur.ConfigurationValues != "" {
+		input.ConfigurationValues = aws.String(cur.ConfigurationValues)
+	}
+	if _, err := ts.cfg.EKSAPI.UpdateAddon(input); err != nil {
+		return fmt.Errorf("failed to update addon %q to %q (%v)", name, version, err)
+	}
+
+	// wait for "UPDATING" before polling for "ACTIVE"
+	addon, err := ts.waitAddon(name, aws_eks.AddonStatusActive, time.Minute)
+	if err != nil {
+		return err
+	}
+	if aws.StringValue(addon.AddonVersion) != version {
+		return fmt.Errorf("addon %q version %q after update, expected %q", name, aws.StringValue(addon.AddonVersion), version)
+	}
+	ts.cfg.Logger.Info("updated addon", zap.String("addon-name", name), zap.String("addon-version", version))
+	return nil
+}
+
+func (ts *tester) deleteAddon(name string) error {
+	cur := ts.cfg.EKSConfig.AddOnManagedAddons.Addons[name]
+	if cur.Existing {
+		ts.cfg.Logger.Info("addon existed before creation; skipping deletion", zap.String("addon-name", name))
+		return nil
+	}
+	ts.cfg.Logger.Info("deleting addon", zap.String("addon-name", name), zap.Bool("preserve", cur.Preserve))
+	_, err := ts.cfg.EKSAPI.DeleteAddon(&aws_eks.DeleteAddonInput{
+		ClusterName: aws.String(ts.cfg.EKSConfig.Name),
+		AddonName:   aws.String(name),
+		Preserve:    aws.Bool(cur.Preserve),
+	})
+	if err != nil && !wait.IsAddonDeleted(err) {
+		return err
+	}
+	if _, err = ts.waitAddon(name, eksconfig.ClusterStatusDELETEDORNOTEXIST, 30*time.Second); err != nil {
+		return err
+	}
+	ts.cfg.Logger.Info("deleted addon", zap.String("addon-name", name))
+	return nil
+}
+
+// waitAddon polls the add-on until the desired status, and records
+// the add-on status and version.
+func (ts *tester) waitAddon(name string, desiredStatus string, initialWait time.Duration) (addon *aws_eks.Addon, err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Minute)
+	ch := wait.PollAddon(
+		ctx,
+		ts.cfg.Stopc,
+		ts.cfg.Logger,
+		ts.cfg.LogWriter,
+		ts.cfg.EKSAPI,
+		ts.cfg.EKSConfig.Name,
+		name,
+		desiredStatus,
+		initialWait,
+		10*time.Second,
+	)
+	for sv := range ch {
+		addon, err = sv.Addon, sv.Error
+	}
+	cancel()
+
+	cur := ts.cfg.EKSConfig.AddOnManagedAddons.Addons[name]
+	if addon != nil {
+		cur.Status = aws.StringValue(addon.Status)
+		cur.InstalledVersion = aws.StringValue(addon.AddonVersion)
+	} else if err == nil {
+		cur.Status = desiredStatus
+		cur.InstalledVersion = ""
+	}
+	ts.cfg.EKSConfig.AddOnManagedAddons.Addons[name] = cur
+	ts.cfg.EKSConfig.Sync()
+	if err != nil {
+		return addon, fmt.Errorf("failed to wait for addon %q %q (%v)", name, desiredStatus, err)
+	}
+	return addon, nil
+}
+
+// resolveVersion resolves "default" and "latest" to the add-on version
+// for the cluster version, and checks that the explicit version is
+// compatible with the cluster version. Empty version is returned as is.
+func (ts *tester) resolveVersion(name string, version string) (string, error) {
+	if version == "" {
+		return "", nil
+	}
+	var infos []*aws_eks.AddonInfo
+	err := ts.cfg.EKSAPI.DescribeAddonVersionsPages(&aws_eks.DescribeAddonVersionsInput{
+		AddonName:         aws.String(name),
+		KubernetesVersion: aws.String(ts.cfg.EKSConfig.Version),
+	}, func(out *aws_eks.DescribeAddonVersionsOutput, lastPage bool) bool {
+		infos = append(infos, out.Addons...)
+		return true
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to describe addon %q versions (%v)", name, err)
+	}
+	resolved, err := resolveAddonVersion(infos, ts.cfg.EKSConfig.Version, version)
+	if err != nil {
+		return "", fmt.Errorf("addon %q %v", name, err)
+	}
+	ts.cfg.Logger.Info("resolved addon version",
+		zap.String("addon-name", name),
+		zap.String("version", version),
+		zap.String("resolved-version", resolved),
+	)
+	return resolved, nil
+}
+
+// resolveAddonVersion returns the add-on version compatible with
+// the cluster version, "default" for the default version, "latest"
+// for the highest version, or the version itself if compatible.
+func resolveAddonVersion(infos []*aws_eks.AddonInfo, clusterVersion string, version string) (string, error) {
+	var compatible []string
+	defaultVersion := ""
+	for _, info := range infos {
+		if info == nil {
+			continue
+		}
+		for _, av := range info.AddonVersions {
+			if av == nil {
+				continue
+			}
+			for _, c := range av.Compatibilities {
+				if c == nil || aws.StringValue(c.ClusterVersion) != clusterVersion {
+					continue
+				}
+				compatible = append(compatible, aws.StringValue(av.AddonVersion))
+				if aws.BoolValue(c.DefaultVersion) {
+					defaultVersion = aws.StringValue(av.AddonVersion)
+				}
+				break
+			}
+		}
+	}
+	if len(compatible) == 0 {
+		return "", fmt.Errorf("no version compatible with cluster version %q", clusterVersion)
+	}
+	sort.Slice(compatible, func(i, j int) bool {
+		return compareAddonVersions(compatible[i], compatible[j]) < 0
+	})
+
+	switch version {
+	case eksconfig.ManagedAddonVersionDefault:
+		if defaultVersion == "" {
+			return "", fmt.Errorf("no default version for cluster version %q", clusterVersion)
+		}
+		return defaultVersion, nil
+	case eksconfig.ManagedAddonVersionLatest:
+		return compatible[len(compatible)-1], nil
+	}
+	for _, v := range compatible {
+		if v == version {
+			return v, nil
+		}
+	}
+	return "", fmt.Errorf("version %q not compatible with cluster version %q (compatible versions %q)", version, clusterVersion, compatible)
+}
+
+// compareAddonVersions compares the add-on versions
+// (e.g. "v1.18.3-eksbuild.2"), by the numeric components.
+func compareAddonVersions(a string, b string) int {
+	as, bs := addonVersionNumbers(a), addonVersionNumbers(b)
+	for i := 0; i < len(as) && i < len(bs); i++ {
+		switch {
+		case as[i] < bs[i]:
+			return -1
+		case as[i] > bs[i]:
+			return 1
+		}
+	}
+	switch {
+	case len(as) < len(bs):
+		return -1
+	case len(as) > len(bs):
+		return 1
+	}
+	return strings.Compare(a, b)
+}
+
+// addonVersionNumbers returns the numeric components of the add-on version
+// (e.g. "v1.18.3-eksbuild.2" returns [1 18 3 2]).
+func addonVersionNumbers(v string) (ns []int) {
+	for _, f := range strings.FieldsFunc(v, func(r rune) bool { return r < '0' || r > '9' }) {
+		n, err := strconv.Atoi(f)
+		if err != nil {
+			continue
+		}
+		ns = append(ns, n)
+	}
+	return ns
+}
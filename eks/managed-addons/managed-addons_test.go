@@ -0,0 +1,84 @@
+package managedaddons
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	aws_eks "github.com/aws/aws-sdk-go/service/eks"
+)
+
+func TestResolveAddonVersion(t *testing.T) {
+	infos := []*aws_eks.AddonInfo{
+		{
+			AddonName: aws.String("vpc-cni"),
+			AddonVersions: []*aws_eks.AddonVersionInfo{
+				{
+					AddonVersion: aws.String("v1.18.10-eksbuild.1"),
+					Compatibilities: []*aws_eks.Compatibility{
+						{ClusterVersion: aws.String("1.30")},
+					},
+				},
+				{
+					AddonVersion: aws.String("v1.18.9-eksbuild.3"),
+					Compatibilities: []*aws_eks.Compatibility{
+						{ClusterVersion: aws.String("1.30")},
+						{ClusterVersion: aws.String("1.29")},
+					},
+				},
+				{
+					AddonVersion: aws.String("v1.18.9-eksbuild.2"),
+					Compatibilities: []*aws_eks.Compatibility{
+						{ClusterVersion: aws.String("1.30"), DefaultVersion: aws.Bool(true)},
+						{ClusterVersion: aws.String("1.29"), DefaultVersion: aws.Bool(true)},
+					},
+				},
+				{
+					AddonVersion: aws.String("v1.19.0-eksbuild.1"),
+					Compatibilities: []*aws_eks.Compatibility{
+						{ClusterVersion: aws.String("1.31")},
+					},
+				},
+			},
+		},
+	}
+	tt := []struct {
+		clusterVersion string
+		version        string
+		resolved       string
+		err            bool
+	}{
+		{"1.30", "default", "v1.18.9-eksbuild.2", false},
+		{"1.30", "latest", "v1.18.10-eksbuild.1", false},
+		{"1.29", "latest", "v1.18.9-eksbuild.3", false},
+		{"1.30", "v1.18.9-eksbuild.3", "v1.18.9-eksbuild.3", false},
+		{"1.31", "default", "", true},
+		{"1.30", "v1.19.0-eksbuild.1", "", true},
+		{"1.28", "latest", "", true},
+	}
+	for i, tv := range tt {
+		resolved, err := resolveAddonVersion(infos, tv.clusterVersion, tv.version)
+		if (err != nil) != tv.err {
+			t.Fatalf("#%d: expected error %v, got %v", i, tv.err, err)
+		}
+		if resolved != tv.resolved {
+			t.Fatalf("#%d: expected %q, got %q", i, tv.resolved, resolved)
+		}
+	}
+}
+
+func TestCompareAddonVersions(t *testing.T) {
+	tt := []struct {
+		a, b string
+		exp  int
+	}{
+		{"v1.18.9-eksbuild.2", "v1.18.10-eksbuild.1", -1},
+		{"v1.18.9-eksbuild.3", "v1.18.9-eksbuild.2", 1},
+		{"v1.11.1-eksbuild.4", "v1.11.1-eksbuild.4", 0},
+		{"v1.11.1", "v1.11.1-eksbuild.1", -1},
+	}
+	for i, tv := range tt {
+		if v := compareAddonVersions(tv.a, tv.b); v != tv.exp {
+			t.Fatalf("#%d: expected %d, got %d", i, tv.exp, v)
+		}
+	}
+}
@@ -0,0 +1,623 @@
+// Package mngrollingupgrade implements tester for managed node group rolling
+// AMI upgrade, which rolls the managed node group to a new AMI release with
+// a PodDisruptionBudget-protected workload running, and validates that the
+// rolling replacement respects "maxUnavailable" and the PodDisruptionBudget.
+// ref. https://docs.aws.amazon.com/eks/latest/userguide/managed-node-update-behavior.html
+package mngrollingupgrade
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-k8s-tester/eks/mng/wait"
+	eks_tester "github.com/aws/aws-k8s-tester/eks/tester"
+	"github.com/aws/aws-k8s-tester/eksconfig"
+	k8s_client "github.com/aws/aws-k8s-tester/pkg/k8s-client"
+	"github.com/aws/aws-k8s-tester/pkg/metrics"
+	"github.com/aws/aws-k8s-tester/pkg/timeutil"
+	"github.com/aws/aws-sdk-go/aws"
+	aws_eks "github.com/aws/aws-sdk-go/service/eks"
+	"github.com/aws/aws-sdk-go/service/eks/eksiface"
+	"go.uber.org/zap"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// Config defines managed node group rolling upgrade tester configuration.
+type Config struct {
+	Logger    *zap.Logger
+	LogWriter io.Writer
+	Stopc     chan struct{}
+	EKSConfig *eksconfig.Config
+	K8SClient k8s_client.EKS
+	EKSAPI    eksiface.EKSAPI
+}
+
+var pkgName = reflect.TypeOf(tester{}).PkgPath()
+
+func (ts *tester) Name() string { return pkgName }
+
+// New creates a new managed node group rolling upgrade tester.
+func New(cfg Config) eks_tester.Tester {
+	cfg.Logger.Info("creating tester", zap.String("tester", pkgName))
+	return &tester{cfg: cfg}
+}
+
+type tester struct {
+	cfg Config
+
+	// nodesBefore is the set of node names in the managed node group
+	// before the upgrade, to be replaced.
+	nodesBefore map[string]struct{}
+	// removed is the set of old node names removed since the upgrade request.
+	removed map[string]struct{}
+	rec     *metrics.LatencyRecorder
+	start   time.Time
+}
+
+const (
+	rollingUpgradeDeploymentName = "mng-rolling-upgrade-deployment"
+	rollingUpgradePDBName        = "mng-rolling-upgrade-pdb"
+	rollingUpgradeAppName        = "mng-rolling-upgrade"
+	rollingUpgradeAppImageName   = "busybox"
+)
+
+// latencyBoundsMs is the histogram upper bounds in milliseconds,
+// since each node takes minutes to drain and terminate.
+var latencyBoundsMs = []float64{
+	60 * 1000,
+	120 * 1000,
+	180 * 1000,
+	300 * 1000,
+	450 * 1000,
+	600 * 1000,
+	900 * 1000,
+	1200 * 1000,
+	1800 * 1000,
+	2700 * 1000,
+	3600 * 1000,
+}
+
+func (ts *tester) Create() (err error) {
+	if !ts.cfg.EKSConfig.IsEnabledAddOnMNGRollingUpgrade() {
+		ts.cfg.Logger.Info("skipping tester.Create", zap.String("tester", pkgName))
+		return nil
+	}
+	if ts.cfg.EKSConfig.AddOnMNGRollingUpgrade.Created {
+		ts.cfg.Logger.Info("skipping tester.Create", zap.String("tester", pkgName))
+		return nil
+	}
+
+	ts.cfg.Logger.Info("starting tester.Create", zap.String("tester", pkgName))
+	ts.cfg.EKSConfig.AddOnMNGRollingUpgrade.Created = true
+	ts.cfg.EKSConfig.Sync()
+	createStart := time.Now()
+	defer func() {
+		createEnd := time.Now()
+		ts.cfg.EKSConfig.AddOnMNGRollingUpgrade.TimeFrameCreate = timeutil.NewTimeFrame(createStart, createEnd)
+		ts.cfg.EKSConfig.Sync()
+	}()
+
+	if err = k8s_client.CreateNamespace(
+		ts.cfg.Logger,
+		ts.cfg.K8SClient.KubernetesClientSet(),
+		ts.cfg.EKSConfig.AddOnMNGRollingUpgrade.Namespace,
+	); err != nil {
+		return err
+	}
+	if err = ts.createDeployment(); err != nil {
+		return err
+	}
+	if err = ts.createPDB(); err != nil {
+		return err
+	}
+	if err = ts.waitDeployment(); err != nil {
+		return err
+	}
+
+	if ts.cfg.EKSConfig.AddOnMNGRollingUpgrade.ReleaseVersionFrom, err = ts.releaseVersion(); err != nil {
+		return err
+	}
+	if err = ts.updateConfig(); err != nil {
+		return err
+	}
+
+	err = ts.upgrade()
+	rs := ts.rec.Summary(fmt.Sprintf("%s-rolling-upgrade-%s", ts.cfg.EKSConfig.AddOnMNGRollingUpgrade.MNGName, time.Now().UTC().Format(time.RFC3339Nano)))
+	ts.cfg.EKSConfig.AddOnMNGRollingUpgrade.RequestsSummaryReplace = rs
+	if werr := ts.writeSummary(
+		rs,
+		ts.cfg.EKSConfig.AddOnMNGRollingUpgrade.RequestsSummaryReplaceJSONPath,
+		ts.cfg.EKSConfig.AddOnMNGRollingUpgrade.RequestsSummaryReplaceTablePath,
+	); werr != nil {
+		ts.cfg.Logger.Warn("failed to write rolling upgrade summary", zap.Error(werr))
+	}
+	ts.cfg.EKSConfig.Sync()
+	if err != nil {
+		return err
+	}
+
+	if ts.cfg.EKSConfig.AddOnMNGRollingUpgrade.ReleaseVersionTo, err = ts.releaseVersion(); err != nil {
+		return err
+	}
+	ts.cfg.EKSConfig.Sync()
+	return ts.validate()
+}
+
+func (ts *tester) Delete() error {
+	if !ts.cfg.EKSConfig.IsEnabledAddOnMNGRollingUpgrade() {
+		ts.cfg.Logger.Info("skipping tester.Delete", zap.String("tester", pkgName))
+		return nil
+	}
+	if !ts.cfg.EKSConfig.AddOnMNGRollingUpgrade.Created {
+		ts.cfg.Logger.Info("skipping tester.Delete", zap.String("tester", pkgName))
+		return nil
+	}
+
+	ts.cfg.Logger.Info("starting tester.Delete", zap.String("tester", pkgName))
+	deleteStart := time.Now()
+	defer func() {
+		deleteEnd := time.Now()
+		ts.cfg.EKSConfig.AddOnMNGRollingUpgrade.TimeFrameDelete = timeutil.NewTimeFrame(deleteStart, deleteEnd)
+		ts.cfg.EKSConfig.Sync()
+	}()
+
+	// the PodDisruptionBudget is deleted with its namespace
+	if err := k8s_client.DeleteNamespaceAndWait(
+		ts.cfg.Logger,
+		ts.cfg.K8SClient.KubernetesClientSet(),
+		ts.cfg.EKSConfig.AddOnMNGRollingUpgrade.Namespace,
+		k8s_client.DefaultNamespaceDeletionInterval,
+		k8s_client.DefaultNamespaceDeletionTimeout,
+		k8s_client.WithForceDelete(true),
+	); err != nil {
+		return fmt.Errorf("failed to delete MNG rolling upgrade namespace (%v)", err)
+	}
+
+	ts.cfg.EKSConfig.AddOnMNGRollingUpgrade.Created = false
+	ts.cfg.EKSConfig.Sync()
+	return nil
+}
+
+func (ts *tester) appLabels() map[string]string {
+	return map[string]string{
+		"app.kubernetes.io/name": rollingUpgradeAppName,
+	}
+}
+
+func (ts *tester) createDeployment() error {
+	ts.cfg.Logger.Info("creating MNG rolling upgrade Deployment")
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	_, err := ts.cfg.K8SClient.KubernetesClientSet().
+		AppsV1().
+		Deployments(ts.cfg.EKSConfig.AddOnMNGRollingUpgrade.Namespace).
+		Create(
+			ctx,
+			&appsv1.Deployment{
+				TypeMeta: metav1.TypeMeta{
+					APIVersion: "apps/v1",
+					Kind:       "Deployment",
+				},
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      rollingUpgradeDeploymentName,
+					Namespace: ts.cfg.EKSConfig.AddOnMNGRollingUpgrade.Namespace,
+					Labels:    ts.appLabels(),
+				},
+				Spec: appsv1.DeploymentSpec{
+					Replicas: aws.Int32(ts.cfg.EKSConfig.AddOnMNGRollingUpgrade.DeploymentReplicas),
+					Selector: &metav1.LabelSelector{
+						MatchLabels: ts.appLabels(),
+					},
+					Template: v1.PodTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{
+							Labels: ts.appLabels(),
+						},
+						Spec: v1.PodSpec{
+							RestartPolicy: v1.RestartPolicyAlways,
+							Containers: []v1.Container{
+								{
+									Name:            rollingUpgradeAppName,
+									Image:           rollingUpgradeAppImageName,
+									ImagePullPolicy: v1.PullIfNotPresent,
+									Command: []string{
+										"/bin/sh",
+										"-c",
+										"while true; do sleep 3600; done",
+									},
+								},
+							},
+							NodeSelector: map[string]string{
+								"NGName": ts.cfg.EKSConfig.AddOnMNGRollingUpgrade.MNGName,
+							},
+							// the managed node group taints the old nodes on upgrade
+							Tolerations: []v1.Toleration{
+								{Operator: v1.TolerationOpExists},
+							},
+						},
+					},
+				},
+			},
+			metav1.CreateOptions{},
+		)
+	cancel()
+	if err != nil {
+		return fmt.Errorf("failed to create MNG rolling upgrade Deployment (%v)", err)
+	}
+
+	ts.cfg.Logger.Info("created MNG rolling upgrade Deployment")
+	ts.cfg.EKSConfig.Sync()
+	return nil
+}
+
+func (ts *tester) createPDB() error {
+	maxUnavailable := intstr.FromInt(int(ts.cfg.EKSConfig.AddOnMNGRollingUpgrade.PDBMaxUnavailable))
+	ts.cfg.Logger.Info("creating MNG rolling upgrade PodDisruptionBudget", zap.String("max-unavailable", maxUnavailable.String()))
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	_, err := ts.cfg.K8SClient.KubernetesClientSet().
+		PolicyV1().
+		PodDisruptionBudgets(ts.cfg.EKSConfig.AddOnMNGRollingUpgrade.Namespace).
+		Create(
+			ctx,
+			&policyv1.PodDisruptionBudget{
+				TypeMeta: metav1.TypeMeta{
+					APIVersion: "policy/v1",
+					Kind:       "PodDisruptionBudget",
+				},
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      rollingUpgradePDBName,
+					Namespace: ts.cfg.EKSConfig.AddOnMNGRollingUpgrade.Namespace,
+				},
+				Spec: policyv1.PodDisruptionBudgetSpec{
+					MaxUnavailable: &maxUnavailable,
+					Selector: &metav1.LabelSelector{
+						MatchLabels: ts.appLabels(),
+					},
+				},
+			},
+			metav1.CreateOptions{},
+		)
+	cancel()
+	if err != nil {
+		return fmt.Errorf("failed to create MNG rolling upgrade PodDisruptionBudget (%v)", err)
+	}
+
+	ts.cfg.Logger.Info("created MNG rolling upgrade PodDisruptionBudget")
+	return nil
+}
+
+func (ts *tester) waitDeployment() (err error) {
+	timeout := 5*time.Minute + time.Duration(ts.cfg.EKSConfig.AddOnMNGRollingUpgrade.DeploymentReplicas)*time.Minute
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	_, err = k8s_client.WaitForDeploymentCompletes(
+		ctx,
+		ts.cfg.Logger,
+		ts.cfg.LogWriter,
+		ts.cfg.Stopc,
+		ts.cfg.K8SClient,
+		30*time.Second,
+		20*time.Second,
+		ts.cfg.EKSConfig.AddOnMNGRollingUpgrade.Namespace,
+		rollingUpgradeDeploymentName,
+		ts.cfg.EKSConfig.AddOnMNGRollingUpgrade.DeploymentReplicas,
+	)
+	cancel()
+	return err
+}
+
+func (ts *tester) listPods() ([]v1.Pod, error) {
+	return k8s_client.ListPodsWithOptions(
+		ts.cfg.K8SClient.KubernetesClientSet(),
+		ts.cfg.EKSConfig.AddOnMNGRollingUpgrade.Namespace,
+		metav1.ListOptions{LabelSelector: "app.kubernetes.io/name=" + rollingUpgradeAppName},
+	)
+}
+
+func (ts *tester) listNodes() ([]v1.Node, error) {
+	return k8s_client.ListNodesWithOptions(
+		ts.cfg.K8SClient.KubernetesClientSet(),
+		metav1.ListOptions{LabelSelector: "NGName=" + ts.cfg.EKSConfig.AddOnMNGRollingUpgrade.MNGName},
+	)
+}
+
+func (ts *tester) releaseVersion() (string, error) {
+	out, err := ts.cfg.EKSAPI.DescribeNodegroup(&aws_eks.DescribeNodegroupInput{
+		ClusterName:   aws.String(ts.cfg.EKSConfig.Name),
+		NodegroupName: aws.String(ts.cfg.EKSConfig.AddOnMNGRollingUpgrade.MNGName),
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to describe MNGs[%q] (%v)", ts.cfg.EKSConfig.AddOnMNGRollingUpgrade.MNGName, err)
+	}
+	if out.Nodegroup == nil {
+		return "", fmt.Errorf("MNGs[%q] not found", ts.cfg.EKSConfig.AddOnMNGRollingUpgrade.MNGName)
+	}
+	return aws.StringValue(out.Nodegroup.ReleaseVersion), nil
+}
+
+// waitUpdate waits for the managed node group update to succeed,
+// calling "queryFunc" (if not nil) on every poll.
+func (ts *tester) waitUpdate(reqID string, pollInterval time.Duration, queryFunc func()) (err error) {
+	opts := []wait.OpOption{}
+	if queryFunc != nil {
+		opts = append(opts, wait.WithQueryFunc(queryFunc))
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), ts.cfg.EKSConfig.AddOnMNGRollingUpgrade.Timeout)
+	updateCh := wait.PollUpdate(
+		ctx,
+		ts.cfg.Stopc,
+		ts.cfg.Logger,
+		ts.cfg.LogWriter,
+		ts.cfg.EKSAPI,
+		ts.cfg.EKSConfig.Name,
+		ts.cfg.EKSConfig.AddOnMNGRollingUpgrade.MNGName,
+		reqID,
+		aws_eks.UpdateStatusSuccessful,
+		pollInterval,
+		pollInterval,
+		opts...,
+	)
+	for v := range updateCh {
+		err = v.Error
+		if v.Update != nil && len(v.Update.Errors) > 0 {
+			ts.cfg.Logger.Warn("MNG update errors", zap.String("errors", fmt.Sprintf("%+v", v.Update.Errors)))
+		}
+	}
+	cancel()
+	return err
+}
+
+// updateConfig sets the "maxUnavailable" of the managed node group.
+// ref. https://docs.aws.amazon.com/eks/latest/APIReference/API_NodegroupUpdateConfig.html
+func (ts *tester) updateConfig() error {
+	mngName := ts.cfg.EKSConfig.AddOnMNGRollingUpgrade.MNGName
+	ts.cfg.Logger.Info("updating MNG update config",
+		zap.String("mng-name", mngName),
+		zap.Int("max-unavailable", ts.cfg.EKSConfig.AddOnMNGRollingUpgrade.MaxUnavailable),
+	)
+	out, err := ts.cfg.EKSAPI.UpdateNodegroupConfig(&aws_eks.UpdateNodegroupConfigInput{
+		ClusterName:   aws.String(ts.cfg.EKSConfig.Name),
+		NodegroupName: aws.String(mngName),
+		UpdateConfig: &aws_eks.NodegroupUpdateConfig{
+			MaxUnavailable: aws.Int64(int64(ts.cfg.EKSConfig.AddOnMNGRollingUpgrade.MaxUnavailable)),
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("MNGs[%q] update config failed (%v)", mngName, err)
+	}
+	if out.Update == nil {
+		return fmt.Errorf("MNGs[%q] update config returned empty update", mngName)
+	}
+	if err = ts.waitUpdate(aws.StringValue(out.Update.Id), 10*time.Second, nil); err != nil {
+		return fmt.Errorf("MNGs[%q] update config failed (%v)", mngName, err)
+	}
+	ts.cfg.Logger.Info("updated MNG update config", zap.String("mng-name", mngName))
+	return nil
+}
+
+// upgrade triggers "UpdateNodegroupVersion" and observes the rolling
+// replacement until every old node is removed.
+func (ts *tester) upgrade() (err error) {
+	mngName := ts.cfg.EKSConfig.AddOnMNGRollingUpgrade.MNGName
+	fmt.Fprint(ts.cfg.LogWriter, ts.cfg.EKSConfig.Colorize("\n\n[yellow]*********************************\n"))
+	fmt.Fprintf(ts.cfg.LogWriter, ts.cfg.EKSConfig.Colorize("[light_green]MNGs[%q] rolling upgrade from %q\n"), mngName, ts.cfg.EKSConfig.AddOnMNGRollingUpgrade.ReleaseVersionFrom)
+
+	ts.rec = metrics.NewLatencyRecorder(latencyBoundsMs...)
+	nodes, err := ts.listNodes()
+	if err != nil {
+		return err
+	}
+	ts.nodesBefore = make(map[string]struct{}, len(nodes))
+	for _, node := range nodes {
+		ts.nodesBefore[node.Name] = struct{}{}
+	}
+	ts.removed = make(map[string]struct{})
+	ts.cfg.EKSConfig.AddOnMNGRollingUpgrade.ObservedMaxUnavailable = 0
+	ts.cfg.EKSConfig.AddOnMNGRollingUpgrade.ObservedMinReadyPods = ts.cfg.EKSConfig.AddOnMNGRollingUpgrade.DeploymentReplicas
+
+	// ref. https://docs.aws.amazon.com/cli/latest/reference/eks/update-nodegroup-version.html
+	updateInput := &aws_eks.UpdateNodegroupVersionInput{
+		ClusterName:   aws.String(ts.cfg.EKSConfig.Name),
+		NodegroupName: aws.String(mngName),
+		// fail on "PodEvictionFailure" rather than violating the PodDisruptionBudget
+		Force: aws.Bool(false),
+	}
+	if ts.cfg.EKSConfig.AddOnMNGRollingUpgrade.ReleaseVersion != "" {
+		updateInput.ReleaseVersion = aws.String(ts.cfg.EKSConfig.AddOnMNGRollingUpgrade.ReleaseVersion)
+	}
+	ts.start = time.Now()
+	updateOut, err := ts.cfg.EKSAPI.UpdateNodegroupVersion(updateInput)
+	if err != nil {
+		ts.cfg.Logger.Warn("MNG rolling upgrade request failed", zap.String("mng-name", mngName), zap.Error(err))
+		return err
+	}
+	reqID := ""
+	if updateOut.Update != nil {
+		reqID = aws.StringValue(updateOut.Update.Id)
+	}
+	ts.cfg.Logger.Info("sent MNG rolling upgrade request; polling",
+		zap.String("mng-name", mngName),
+		zap.String("request-id", reqID),
+		zap.Int("nodes", len(ts.nodesBefore)),
+	)
+
+	err = ts.waitUpdate(reqID, 10*time.Second, ts.observe)
+	if err == nil {
+		// the node objects may outlive the terminated instances
+		err = ts.waitRemoved()
+	}
+	for i := len(ts.removed); i < len(ts.nodesBefore); i++ {
+		ts.rec.Observe(ts.cfg.EKSConfig.AddOnMNGRollingUpgrade.Timeout, errors.New("node not replaced"))
+	}
+	if err != nil {
+		return fmt.Errorf("MNGs[%q] rolling upgrade failed with %d/%d nodes replaced (%v)", mngName, len(ts.removed), len(ts.nodesBefore), err)
+	}
+	return nil
+}
+
+// observe records the removed old nodes, and the number of unavailable
+// old nodes and Ready test pods at the moment.
+func (ts *tester) observe() {
+	nodes, err := ts.listNodes()
+	if err != nil {
+		ts.cfg.Logger.Warn("failed to list nodes", zap.Error(err))
+		return
+	}
+	took := time.Since(ts.start)
+	for _, name := range removedNodes(nodes, ts.nodesBefore, ts.removed) {
+		ts.removed[name] = struct{}{}
+		ts.rec.Observe(took, nil)
+		ts.cfg.Logger.Info("old node replaced", zap.String("node-name", name), zap.String("took", took.String()))
+	}
+	unavailable := unavailableNodes(nodes, ts.nodesBefore)
+	if len(unavailable) > ts.cfg.EKSConfig.AddOnMNGRollingUpgrade.ObservedMaxUnavailable {
+		ts.cfg.EKSConfig.AddOnMNGRollingUpgrade.ObservedMaxUnavailable = len(unavailable)
+	}
+
+	pods, err := ts.listPods()
+	if err != nil {
+		ts.cfg.Logger.Warn("failed to list pods", zap.Error(err))
+		return
+	}
+	ready := countReadyPods(pods)
+	if ready < ts.cfg.EKSConfig.AddOnMNGRollingUpgrade.ObservedMinReadyPods {
+		ts.cfg.EKSConfig.AddOnMNGRollingUpgrade.ObservedMinReadyPods = ready
+	}
+	ts.cfg.Logger.Info("rolling upgrade",
+		zap.Int("replaced", len(ts.removed)),
+		zap.Int("want", len(ts.nodesBefore)),
+		zap.Strings("unavailable", unavailable),
+		zap.Int32("ready-pods", ready),
+		zap.Duration("elapsed", took),
+	)
+}
+
+// waitRemoved polls every 10 seconds until all old nodes are removed,
+// or until "AddOnMNGRollingUpgrade.Timeout" elapses since the request.
+func (ts *tester) waitRemoved() error {
+	timeout := ts.cfg.EKSConfig.AddOnMNGRollingUpgrade.Timeout
+	ticker := time.NewTicker(10 * time.Second)
+	defer ticker.Stop()
+	for len(ts.removed) < len(ts.nodesBefore) {
+		if time.Since(ts.start) > timeout {
+			return fmt.Errorf("old nodes not removed after %v", timeout)
+		}
+		select {
+		case <-ts.cfg.Stopc:
+			return errors.New("rolling upgrade aborted")
+		case <-ticker.C:
+		}
+		ts.observe()
+	}
+	return nil
+}
+
+// validate returns an error if the upgrade did not roll to a new AMI
+// release, or did not respect "MaxUnavailable" or the PodDisruptionBudget.
+func (ts *tester) validate() error {
+	cfg := ts.cfg.EKSConfig.AddOnMNGRollingUpgrade
+	var errs []string
+	if cfg.ReleaseVersionTo == cfg.ReleaseVersionFrom {
+		errs = append(errs, fmt.Sprintf("release version %q not changed", cfg.ReleaseVersionTo))
+	}
+	if cfg.ReleaseVersion != "" && cfg.ReleaseVersionTo != cfg.ReleaseVersion {
+		errs = append(errs, fmt.Sprintf("release version %q (expected %q)", cfg.ReleaseVersionTo, cfg.ReleaseVersion))
+	}
+	if cfg.ObservedMaxUnavailable > cfg.MaxUnavailable {
+		errs = append(errs, fmt.Sprintf("observed %d nodes unavailable at once (expected <= %d)", cfg.ObservedMaxUnavailable, cfg.MaxUnavailable))
+	}
+	if minReady := cfg.DeploymentReplicas - cfg.PDBMaxUnavailable; cfg.ObservedMinReadyPods < minReady {
+		errs = append(errs, fmt.Sprintf("observed %d Ready pods (expected >= %d by PodDisruptionBudget)", cfg.ObservedMinReadyPods, minReady))
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("MNGs[%q] rolling upgrade validation failed [%s]", cfg.MNGName, strings.Join(errs, ", "))
+	}
+
+	ts.cfg.Logger.Info("validated MNG rolling upgrade",
+		zap.String("mng-name", cfg.MNGName),
+		zap.String("release-version-from", cfg.ReleaseVersionFrom),
+		zap.String("release-version-to", cfg.ReleaseVersionTo),
+		zap.Int("observed-max-unavailable", cfg.ObservedMaxUnavailable),
+		zap.Int32("observed-min-ready-pods", cfg.ObservedMinReadyPods),
+	)
+	return nil
+}
+
+func (ts *tester) writeSummary(rs metrics.RequestsSummary, jsonPath string, tablePath string) error {
+	if err := ioutil.WriteFile(jsonPath, []byte(rs.JSON()), 0600); err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(tablePath, []byte(rs.Table()), 0600); err != nil {
+		return err
+	}
+	fmt.Fprintf(ts.cfg.LogWriter, "\n\nRequestsSummary %q:\n%s\n", rs.TestID, rs.Table())
+	return nil
+}
+
+// removedNodes returns the names of the nodes in "before" that are
+// neither in "nodes" nor in "seen".
+func removedNodes(nodes []v1.Node, before map[string]struct{}, seen map[string]struct{}) (removed []string) {
+	cur := make(map[string]struct{}, len(nodes))
+	for _, node := range nodes {
+		cur[node.Name] = struct{}{}
+	}
+	for name := range before {
+		if _, ok := cur[name]; ok {
+			continue
+		}
+		if _, ok := seen[name]; ok {
+			continue
+		}
+		removed = append(removed, name)
+	}
+	return removed
+}
+
+// unavailableNodes returns the names of the nodes in "before" that are
+// cordoned but still Ready. The managed node group cordons each node after
+// evicting its pods and before terminating it, so the cordoned Ready nodes
+// are the ones being replaced at once, excluding the terminated nodes
+// whose objects are yet to be deleted.
+func unavailableNodes(nodes []v1.Node, before map[string]struct{}) (unavailable []string) {
+	for _, node := range nodes {
+		if _, ok := before[node.Name]; !ok {
+			continue
+		}
+		if node.Spec.Unschedulable && isNodeReady(node) {
+			unavailable = append(unavailable, node.Name)
+		}
+	}
+	return unavailable
+}
+
+func isNodeReady(node v1.Node) bool {
+	for _, cond := range node.Status.Conditions {
+		if cond.Type == v1.NodeReady {
+			return cond.Status == v1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// countReadyPods returns the number of Ready pods not being deleted.
+func countReadyPods(pods []v1.Pod) (ready int32) {
+	for _, pod := range pods {
+		if pod.DeletionTimestamp != nil {
+			continue
+		}
+		for _, cond := range pod.Status.Conditions {
+			if cond.Type == v1.PodReady && cond.Status == v1.ConditionTrue {
+				ready++
+				break
+			}
+		}
+	}
+	return ready
+}
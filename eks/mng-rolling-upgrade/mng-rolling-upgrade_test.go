@@ -0,0 +1,85 @@
+package mngrollingupgrade
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestUnavailableNodes(t *testing.T) {
+	node := func(name string, cordoned bool, ready bool) v1.Node {
+		status := v1.ConditionFalse
+		if ready {
+			status = v1.ConditionTrue
+		}
+		return v1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec:       v1.NodeSpec{Unschedulable: cordoned},
+			Status: v1.NodeStatus{
+				Conditions: []v1.NodeCondition{{Type: v1.NodeReady, Status: status}},
+			},
+		}
+	}
+	nodes := []v1.Node{
+		node("old-serving", false, true),
+		node("old-cordoned", true, true),
+		node("old-terminated", true, false),
+		node("new-cordoned", true, true),
+	}
+	before := map[string]struct{}{"old-serving": {}, "old-cordoned": {}, "old-terminated": {}, "old-removed": {}}
+
+	unavailable := unavailableNodes(nodes, before)
+	expected := []string{"old-cordoned"}
+	if !reflect.DeepEqual(unavailable, expected) {
+		t.Fatalf("expected %v, got %v", expected, unavailable)
+	}
+}
+
+func TestRemovedNodes(t *testing.T) {
+	nodes := []v1.Node{
+		{ObjectMeta: metav1.ObjectMeta{Name: "a"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "new"}},
+	}
+	before := map[string]struct{}{"a": {}, "b": {}, "c": {}}
+	seen := map[string]struct{}{"c": {}}
+
+	removed := removedNodes(nodes, before, seen)
+	sort.Strings(removed)
+	if !reflect.DeepEqual(removed, []string{"b"}) {
+		t.Fatalf("unexpected removed nodes %v", removed)
+	}
+}
+
+func TestCountReadyPods(t *testing.T) {
+	now := metav1.NewTime(time.Now())
+	pod := func(ready bool, deleting bool) v1.Pod {
+		status := v1.ConditionFalse
+		if ready {
+			status = v1.ConditionTrue
+		}
+		p := v1.Pod{
+			Status: v1.PodStatus{
+				Conditions: []v1.PodCondition{{Type: v1.PodReady, Status: status}},
+			},
+		}
+		if deleting {
+			p.DeletionTimestamp = &now
+		}
+		return p
+	}
+	pods := []v1.Pod{
+		pod(true, false),
+		pod(true, false),
+		// evicted from the draining node
+		pod(true, true),
+		// rescheduled to the new node, not yet ready
+		pod(false, false),
+	}
+	if ready := countReadyPods(pods); ready != 2 {
+		t.Fatalf("expected 2 Ready pods, got %d", ready)
+	}
+}
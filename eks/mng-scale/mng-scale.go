@@ -0,0 +1,354 @@
+// Package mngscale implements tester for managed node group scale out/in,
+// which scales the managed node group from its minimum to maximum size and
+// back, and records the time-to-Ready of new nodes and the time-to-drain
+// of removed nodes.
+// ref. https://docs.aws.amazon.com/cli/latest/reference/eks/update-nodegroup-config.html
+package mngscale
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"reflect"
+	"time"
+
+	eks_tester "github.com/aws/aws-k8s-tester/eks/tester"
+	"github.com/aws/aws-k8s-tester/eksconfig"
+	k8s_client "github.com/aws/aws-k8s-tester/pkg/k8s-client"
+	"github.com/aws/aws-k8s-tester/pkg/metrics"
+	"github.com/aws/aws-k8s-tester/pkg/timeutil"
+	"github.com/aws/aws-sdk-go/aws"
+	aws_eks "github.com/aws/aws-sdk-go/service/eks"
+	"github.com/aws/aws-sdk-go/service/eks/eksiface"
+	"go.uber.org/zap"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Config defines managed node group scale tester configuration.
+type Config struct {
+	Logger    *zap.Logger
+	LogWriter io.Writer
+	Stopc     chan struct{}
+	EKSConfig *eksconfig.Config
+	K8SClient k8s_client.EKS
+	EKSAPI    eksiface.EKSAPI
+}
+
+var pkgName = reflect.TypeOf(tester{}).PkgPath()
+
+func (ts *tester) Name() string { return pkgName }
+
+// New creates a new managed node group scale tester.
+func New(cfg Config) eks_tester.Tester {
+	cfg.Logger.Info("creating tester", zap.String("tester", pkgName))
+	return &tester{cfg: cfg}
+}
+
+type tester struct {
+	cfg Config
+}
+
+// latencyBoundsMs is the histogram upper bounds in milliseconds,
+// since nodes take minutes to join or leave the cluster.
+var latencyBoundsMs = []float64{
+	30 * 1000,
+	60 * 1000,
+	90 * 1000,
+	120 * 1000,
+	180 * 1000,
+	240 * 1000,
+	300 * 1000,
+	450 * 1000,
+	600 * 1000,
+	900 * 1000,
+}
+
+func (ts *tester) Create() (err error) {
+	if !ts.cfg.EKSConfig.IsEnabledAddOnMNGScale() {
+		ts.cfg.Logger.Info("skipping tester.Create", zap.String("tester", pkgName))
+		return nil
+	}
+	if ts.cfg.EKSConfig.AddOnMNGScale.Created {
+		ts.cfg.Logger.Info("skipping tester.Create", zap.String("tester", pkgName))
+		return nil
+	}
+
+	ts.cfg.Logger.Info("starting tester.Create", zap.String("tester", pkgName))
+	ts.cfg.EKSConfig.AddOnMNGScale.Created = true
+	ts.cfg.EKSConfig.Sync()
+	createStart := time.Now()
+	defer func() {
+		createEnd := time.Now()
+		ts.cfg.EKSConfig.AddOnMNGScale.TimeFrameCreate = timeutil.NewTimeFrame(createStart, createEnd)
+		ts.cfg.EKSConfig.Sync()
+	}()
+
+	mngName := ts.cfg.EKSConfig.AddOnMNGScale.MNGName
+	cur, ok := ts.cfg.EKSConfig.AddOnManagedNodeGroups.MNGs[mngName]
+	if !ok {
+		return fmt.Errorf("MNGs[%q] not found", mngName)
+	}
+
+	ts.cfg.EKSConfig.AddOnMNGScale.RequestsSummaryScaleOut, err = ts.scaleOut(cur)
+	if werr := ts.writeSummary(
+		ts.cfg.EKSConfig.AddOnMNGScale.RequestsSummaryScaleOut,
+		ts.cfg.EKSConfig.AddOnMNGScale.RequestsSummaryScaleOutJSONPath,
+		ts.cfg.EKSConfig.AddOnMNGScale.RequestsSummaryScaleOutTablePath,
+	); werr != nil {
+		ts.cfg.Logger.Warn("failed to write scale-out summary", zap.Error(werr))
+	}
+	ts.cfg.EKSConfig.Sync()
+	if err != nil {
+		return err
+	}
+
+	ts.cfg.EKSConfig.AddOnMNGScale.RequestsSummaryScaleIn, err = ts.scaleIn(cur)
+	if werr := ts.writeSummary(
+		ts.cfg.EKSConfig.AddOnMNGScale.RequestsSummaryScaleIn,
+		ts.cfg.EKSConfig.AddOnMNGScale.RequestsSummaryScaleInJSONPath,
+		ts.cfg.EKSConfig.AddOnMNGScale.RequestsSummaryScaleInTablePath,
+	); werr != nil {
+		ts.cfg.Logger.Warn("failed to write scale-in summary", zap.Error(werr))
+	}
+	ts.cfg.EKSConfig.Sync()
+	return err
+}
+
+func (ts *tester) Delete() error {
+	if !ts.cfg.EKSConfig.IsEnabledAddOnMNGScale() {
+		ts.cfg.Logger.Info("skipping tester.Delete", zap.String("tester", pkgName))
+		return nil
+	}
+	if !ts.cfg.EKSConfig.AddOnMNGScale.Created {
+		ts.cfg.Logger.Info("skipping tester.Delete", zap.String("tester", pkgName))
+		return nil
+	}
+
+	ts.cfg.Logger.Info("starting tester.Delete", zap.String("tester", pkgName))
+	deleteStart := time.Now()
+	defer func() {
+		deleteEnd := time.Now()
+		ts.cfg.EKSConfig.AddOnMNGScale.TimeFrameDelete = timeutil.NewTimeFrame(deleteStart, deleteEnd)
+		ts.cfg.EKSConfig.Sync()
+	}()
+
+	// nothing to clean up, the managed node group is deleted with its add-on
+	ts.cfg.EKSConfig.AddOnMNGScale.Created = false
+	ts.cfg.EKSConfig.Sync()
+	return nil
+}
+
+func (ts *tester) updateScalingConfig(cur eksconfig.MNG, desired int) error {
+	ts.cfg.Logger.Info("updating MNG scaling config",
+		zap.String("mng-name", cur.Name),
+		zap.Int("asg-min-size", cur.ASGMinSize),
+		zap.Int("asg-max-size", cur.ASGMaxSize),
+		zap.Int("target-desired-size", desired),
+	)
+	out, err := ts.cfg.EKSAPI.UpdateNodegroupConfig(&aws_eks.UpdateNodegroupConfigInput{
+		ClusterName:   aws.String(ts.cfg.EKSConfig.Name),
+		NodegroupName: aws.String(cur.Name),
+		ScalingConfig: &aws_eks.NodegroupScalingConfig{
+			MinSize:     aws.Int64(int64(cur.ASGMinSize)),
+			MaxSize:     aws.Int64(int64(cur.ASGMaxSize)),
+			DesiredSize: aws.Int64(int64(desired)),
+		},
+	})
+	if err != nil {
+		ts.cfg.Logger.Warn("MNG scaling config update failed", zap.String("mng-name", cur.Name), zap.Error(err))
+		return err
+	}
+	if out.Update != nil {
+		ts.cfg.Logger.Info("sent MNG scaling config update", zap.String("update-id", aws.StringValue(out.Update.Id)))
+	}
+	return nil
+}
+
+func (ts *tester) listNodes() ([]v1.Node, error) {
+	return k8s_client.ListNodesWithOptions(
+		ts.cfg.K8SClient.KubernetesClientSet(),
+		metav1.ListOptions{LabelSelector: "NGName=" + ts.cfg.EKSConfig.AddOnMNGScale.MNGName},
+	)
+}
+
+// scaleOut scales the managed node group to "ASGMaxSize",
+// and records the time-to-Ready of each new node since the request.
+func (ts *tester) scaleOut(cur eksconfig.MNG) (rs metrics.RequestsSummary, err error) {
+	fmt.Fprint(ts.cfg.LogWriter, ts.cfg.EKSConfig.Colorize("\n\n[yellow]*********************************\n"))
+	fmt.Fprintf(ts.cfg.LogWriter, ts.cfg.EKSConfig.Colorize("[light_green]MNGs[%q] scale out to %d\n"), cur.Name, cur.ASGMaxSize)
+
+	nodes, err := ts.listNodes()
+	if err != nil {
+		return rs, err
+	}
+	before := make(map[string]struct{}, len(nodes))
+	for _, node := range nodes {
+		before[node.Name] = struct{}{}
+	}
+	want := cur.ASGMaxSize - len(before)
+	if want <= 0 {
+		return rs, fmt.Errorf("MNGs[%q] already has %d nodes (ASGMaxSize %d)", cur.Name, len(before), cur.ASGMaxSize)
+	}
+
+	rec := metrics.NewLatencyRecorder(latencyBoundsMs...)
+	start := time.Now()
+	if err = ts.updateScalingConfig(cur, cur.ASGMaxSize); err != nil {
+		return rs, err
+	}
+
+	ready := make(map[string]struct{})
+	err = ts.poll("scale-out", func() (bool, error) {
+		nodes, err := ts.listNodes()
+		if err != nil {
+			return false, err
+		}
+		for name, took := range newReadyNodes(nodes, before, ready, start) {
+			ready[name] = struct{}{}
+			rec.Observe(took, nil)
+			ts.cfg.Logger.Info("new node ready", zap.String("node-name", name), zap.String("took", took.String()))
+		}
+		ts.cfg.Logger.Info("scaling out", zap.Int("ready", len(ready)), zap.Int("want", want), zap.Duration("elapsed", time.Since(start)))
+		return len(ready) >= want, nil
+	})
+	for i := len(ready); i < want; i++ {
+		rec.Observe(ts.cfg.EKSConfig.AddOnMNGScale.Timeout, errors.New("node not ready"))
+	}
+	rs = rec.Summary(fmt.Sprintf("%s-scale-out-%d-%s", cur.Name, cur.ASGMaxSize, time.Now().UTC().Format(time.RFC3339Nano)))
+	if err != nil {
+		return rs, fmt.Errorf("MNGs[%q] scale out failed with %d/%d nodes ready (%v)", cur.Name, len(ready), want, err)
+	}
+	return rs, nil
+}
+
+// scaleIn scales the managed node group back to "ASGMinSize",
+// and records the time-to-drain of each removed node since the request.
+func (ts *tester) scaleIn(cur eksconfig.MNG) (rs metrics.RequestsSummary, err error) {
+	fmt.Fprint(ts.cfg.LogWriter, ts.cfg.EKSConfig.Colorize("\n\n[yellow]*********************************\n"))
+	fmt.Fprintf(ts.cfg.LogWriter, ts.cfg.EKSConfig.Colorize("[light_green]MNGs[%q] scale in to %d\n"), cur.Name, cur.ASGMinSize)
+
+	nodes, err := ts.listNodes()
+	if err != nil {
+		return rs, err
+	}
+	before := make(map[string]struct{}, len(nodes))
+	for _, node := range nodes {
+		before[node.Name] = struct{}{}
+	}
+	want := len(before) - cur.ASGMinSize
+	if want <= 0 {
+		return rs, fmt.Errorf("MNGs[%q] has %d nodes (ASGMinSize %d)", cur.Name, len(before), cur.ASGMinSize)
+	}
+
+	rec := metrics.NewLatencyRecorder(latencyBoundsMs...)
+	start := time.Now()
+	if err = ts.updateScalingConfig(cur, cur.ASGMinSize); err != nil {
+		return rs, err
+	}
+
+	removed := make(map[string]struct{})
+	err = ts.poll("scale-in", func() (bool, error) {
+		nodes, err := ts.listNodes()
+		if err != nil {
+			return false, err
+		}
+		took := time.Since(start)
+		for _, name := range removedNodes(nodes, before, removed) {
+			removed[name] = struct{}{}
+			rec.Observe(took, nil)
+			ts.cfg.Logger.Info("node drained and removed", zap.String("node-name", name), zap.String("took", took.String()))
+		}
+		ts.cfg.Logger.Info("scaling in", zap.Int("removed", len(removed)), zap.Int("want", want), zap.Duration("elapsed", took))
+		return len(removed) >= want, nil
+	})
+	for i := len(removed); i < want; i++ {
+		rec.Observe(ts.cfg.EKSConfig.AddOnMNGScale.Timeout, errors.New("node not removed"))
+	}
+	rs = rec.Summary(fmt.Sprintf("%s-scale-in-%d-%s", cur.Name, cur.ASGMinSize, time.Now().UTC().Format(time.RFC3339Nano)))
+	if err != nil {
+		return rs, fmt.Errorf("MNGs[%q] scale in failed with %d/%d nodes removed (%v)", cur.Name, len(removed), want, err)
+	}
+	return rs, nil
+}
+
+// poll calls "check" every 10 seconds until it returns true,
+// or until "AddOnMNGScale.Timeout" elapses.
+func (ts *tester) poll(desc string, check func() (bool, error)) error {
+	timeout := ts.cfg.EKSConfig.AddOnMNGScale.Timeout
+	start := time.Now()
+	ticker := time.NewTicker(10 * time.Second)
+	defer ticker.Stop()
+	for time.Since(start) < timeout {
+		select {
+		case <-ts.cfg.Stopc:
+			return fmt.Errorf("%s aborted", desc)
+		case <-ticker.C:
+		}
+		done, err := check()
+		if err != nil {
+			ts.cfg.Logger.Warn("failed to check nodes", zap.String("desc", desc), zap.Error(err))
+			continue
+		}
+		if done {
+			return nil
+		}
+	}
+	return fmt.Errorf("%s timed out after %v", desc, timeout)
+}
+
+func (ts *tester) writeSummary(rs metrics.RequestsSummary, jsonPath string, tablePath string) error {
+	if err := ioutil.WriteFile(jsonPath, []byte(rs.JSON()), 0600); err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(tablePath, []byte(rs.Table()), 0600); err != nil {
+		return err
+	}
+	fmt.Fprintf(ts.cfg.LogWriter, "\n\nRequestsSummary %q:\n%s\n", rs.TestID, rs.Table())
+	return nil
+}
+
+// newReadyNodes returns the Ready nodes that are neither in "before" nor
+// in "seen", with the time since "start" to their Ready condition transition.
+func newReadyNodes(nodes []v1.Node, before map[string]struct{}, seen map[string]struct{}, start time.Time) map[string]time.Duration {
+	ready := make(map[string]time.Duration)
+	for _, node := range nodes {
+		if _, ok := before[node.Name]; ok {
+			continue
+		}
+		if _, ok := seen[node.Name]; ok {
+			continue
+		}
+		for _, cond := range node.Status.Conditions {
+			if cond.Type != v1.NodeReady || cond.Status != v1.ConditionTrue {
+				continue
+			}
+			took := cond.LastTransitionTime.Time.Sub(start)
+			if took <= 0 {
+				took = time.Since(start)
+			}
+			ready[node.Name] = took
+			break
+		}
+	}
+	return ready
+}
+
+// removedNodes returns the names of the nodes in "before" that are
+// neither in "nodes" nor in "seen".
+func removedNodes(nodes []v1.Node, before map[string]struct{}, seen map[string]struct{}) (removed []string) {
+	cur := make(map[string]struct{}, len(nodes))
+	for _, node := range nodes {
+		cur[node.Name] = struct{}{}
+	}
+	for name := range before {
+		if _, ok := cur[name]; ok {
+			continue
+		}
+		if _, ok := seen[name]; ok {
+			continue
+		}
+		removed = append(removed, name)
+	}
+	return removed
+}
@@ -0,0 +1,57 @@
+package mngscale
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestNewReadyNodes(t *testing.T) {
+	start := time.Now().Add(-5 * time.Minute)
+	node := func(name string, ready bool, readyAt time.Time) v1.Node {
+		status := v1.ConditionFalse
+		if ready {
+			status = v1.ConditionTrue
+		}
+		return v1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Status: v1.NodeStatus{
+				Conditions: []v1.NodeCondition{{Type: v1.NodeReady, Status: status, LastTransitionTime: metav1.NewTime(readyAt)}},
+			},
+		}
+	}
+	nodes := []v1.Node{
+		node("old", true, start.Add(-time.Hour)),
+		node("seen", true, start.Add(time.Minute)),
+		node("new-ready", true, start.Add(2*time.Minute)),
+		node("new-not-ready", false, start.Add(time.Minute)),
+	}
+	before := map[string]struct{}{"old": {}}
+	seen := map[string]struct{}{"seen": {}}
+
+	ready := newReadyNodes(nodes, before, seen, start)
+	expected := map[string]time.Duration{"new-ready": 2 * time.Minute}
+	if !reflect.DeepEqual(ready, expected) {
+		t.Fatalf("expected %v, got %v", expected, ready)
+	}
+}
+
+func TestRemovedNodes(t *testing.T) {
+	nodes := []v1.Node{
+		{ObjectMeta: metav1.ObjectMeta{Name: "a"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "new"}},
+	}
+	before := map[string]struct{}{"a": {}, "b": {}, "c": {}, "d": {}}
+	seen := map[string]struct{}{"d": {}}
+
+	removed := removedNodes(nodes, before, seen)
+	sort.Strings(removed)
+	expected := []string{"b", "c"}
+	if !reflect.DeepEqual(removed, expected) {
+		t.Fatalf("expected %q, got %q", expected, removed)
+	}
+}
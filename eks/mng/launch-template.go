@@ -0,0 +1,463 @@
+package mng
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-k8s-tester/eksconfig"
+	aws_ec2 "github.com/aws/aws-k8s-tester/pkg/aws/ec2"
+	aws_v2 "github.com/aws/aws-sdk-go-v2/aws"
+	aws_ec2_v2 "github.com/aws/aws-sdk-go-v2/service/ec2"
+	aws_ec2_v2_types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	aws_eks "github.com/aws/aws-sdk-go/service/eks"
+	"github.com/aws/aws-sdk-go/service/ssm"
+	smithy "github.com/aws/smithy-go"
+	"go.uber.org/zap"
+)
+
+// EKS does not create the remote access security group for the node group
+// with a launch template, so the tester creates the node security group,
+// and reuses "RemoteAccessSecurityGroupID" for the ingress/egress rules.
+// ref. https://docs.aws.amazon.com/eks/latest/userguide/launch-templates.html
+
+func (ts *tester) createLaunchTemplate(mngName string) error {
+	cur, ok := ts.cfg.EKSConfig.AddOnManagedNodeGroups.MNGs[mngName]
+	if !ok {
+		return fmt.Errorf("MNGs[%q] not found; cannot create launch template", mngName)
+	}
+	lt := cur.LaunchTemplate
+	if lt.ID != "" {
+		ts.cfg.Logger.Info("launch template already created; skipping", zap.String("mng-name", mngName), zap.String("launch-template-id", lt.ID))
+		return nil
+	}
+
+	// the security group may have been created by the previous run
+	// that failed to create the launch template
+	if lt.SecurityGroupID != "" {
+		ts.cfg.Logger.Info("launch template security group already created; skipping", zap.String("mng-name", mngName), zap.String("security-group-id", lt.SecurityGroupID))
+	} else {
+		ts.cfg.Logger.Info("creating launch template security group", zap.String("mng-name", mngName))
+		sout, err := ts.cfg.EC2APIV2.CreateSecurityGroup(
+			context.Background(),
+			&aws_ec2_v2.CreateSecurityGroupInput{
+				GroupName:   aws_v2.String(cur.Name + "-launch-template-sg"),
+				Description: aws_v2.String("Security group for the managed node group launch template"),
+				VpcId:       aws_v2.String(ts.cfg.EKSConfig.VPC.ID),
+				TagSpecifications: []aws_ec2_v2_types.TagSpecification{
+					{
+						ResourceType: aws_ec2_v2_types.ResourceTypeSecurityGroup,
+						Tags: aws_ec2.AppendTags([]aws_ec2_v2_types.Tag{
+							{
+								Key:   aws_v2.String(fmt.Sprintf("kubernetes.io/cluster/%s", ts.cfg.EKSConfig.Name)),
+								Value: aws_v2.String("owned"),
+							},
+						}, ts.cfg.EKSConfig.Tags),
+					},
+				},
+			},
+		)
+		if err != nil {
+			ts.cfg.Logger.Warn("failed to create launch template security group", zap.Error(err))
+			return err
+		}
+		lt.SecurityGroupID = aws_v2.ToString(sout.GroupId)
+		cur.RemoteAccessSecurityGroupID = lt.SecurityGroupID
+		ts.cfg.EKSConfig.AddOnManagedNodeGroups.MNGs[mngName] = cur
+		ts.cfg.EKSConfig.Sync()
+		ts.cfg.Logger.Info("created launch template security group", zap.String("security-group-id", lt.SecurityGroupID))
+	}
+
+	// the cluster security group is only attached when the launch template
+	// does not specify any security group
+	sgIDs := []string{lt.SecurityGroupID}
+	dout, err := ts.cfg.EKSAPI.DescribeCluster(&aws_eks.DescribeClusterInput{
+		Name: aws_v2.String(ts.cfg.EKSConfig.Name),
+	})
+	if err != nil {
+		return err
+	}
+	if vc := dout.Cluster.ResourcesVpcConfig; vc != nil && aws_v2.ToString(vc.ClusterSecurityGroupId) != "" {
+		sgIDs = append(sgIDs, aws_v2.ToString(vc.ClusterSecurityGroupId))
+	}
+
+	if cur.IsCustomAMI() && lt.ResolvedImageID == "" {
+		lt.ResolvedImageID = lt.ImageID
+		if lt.ImageIDSSMParameter != "" {
+			lt.ResolvedImageID, err = ts.fetchImageID(lt.ImageIDSSMParameter)
+			if err != nil {
+				return fmt.Errorf("failed to fetch custom AMI ID from %q (%v)", lt.ImageIDSSMParameter, err)
+			}
+		}
+		ts.cfg.EKSConfig.AddOnManagedNodeGroups.MNGs[mngName] = cur
+		ts.cfg.EKSConfig.Sync()
+		ts.cfg.Logger.Info("resolved custom AMI", zap.String("mng-name", mngName), zap.String("image-id", lt.ResolvedImageID))
+	}
+
+	data := ts.launchTemplateData(cur, ts.userData(cur, lt.UserData))
+	data.SecurityGroupIds = sgIDs
+	ts.cfg.Logger.Info("creating launch template",
+		zap.String("mng-name", mngName),
+		zap.Strings("security-group-ids", sgIDs),
+		zap.Int("block-devices", len(lt.BlockDevices)),
+	)
+	out, err := ts.cfg.EC2APIV2.CreateLaunchTemplate(
+		context.Background(),
+		&aws_ec2_v2.CreateLaunchTemplateInput{
+			LaunchTemplateName: aws_v2.String(cur.Name + "-launch-template"),
+			LaunchTemplateData: data,
+			VersionDescription: aws_v2.String("created by aws-k8s-tester"),
+			TagSpecifications: []aws_ec2_v2_types.TagSpecification{
+				{
+					ResourceType: aws_ec2_v2_types.ResourceTypeLaunchTemplate,
+					Tags:         aws_ec2.AppendTags(nil, ts.cfg.EKSConfig.Tags),
+				},
+			},
+		},
+	)
+	if err != nil {
+		ts.cfg.Logger.Warn("failed to create launch template", zap.Error(err))
+		return err
+	}
+	lt.ID = aws_v2.ToString(out.LaunchTemplate.LaunchTemplateId)
+	lt.Name = aws_v2.ToString(out.LaunchTemplate.LaunchTemplateName)
+	lt.Version = aws_v2.ToInt64(out.LaunchTemplate.LatestVersionNumber)
+	ts.cfg.EKSConfig.AddOnManagedNodeGroups.MNGs[mngName] = cur
+	ts.cfg.EKSConfig.Sync()
+	ts.cfg.Logger.Info("created launch template",
+		zap.String("mng-name", mngName),
+		zap.String("launch-template-id", lt.ID),
+		zap.String("launch-template-name", lt.Name),
+		zap.Int64("launch-template-version", lt.Version),
+	)
+	return nil
+}
+
+// createLaunchTemplateVersion creates a new launch template version
+// from the current one, with "UpgradeUserData", for the node group
+// version upgrade to roll out.
+func (ts *tester) createLaunchTemplateVersion(mngName string) error {
+	cur, ok := ts.cfg.EKSConfig.AddOnManagedNodeGroups.MNGs[mngName]
+	if !ok {
+		return fmt.Errorf("MNGs[%q] not found; cannot create launch template version", mngName)
+	}
+	if cur.LaunchTemplate == nil || !cur.LaunchTemplate.Enable {
+		return nil
+	}
+	if cur.VersionUpgrade == nil || !cur.VersionUpgrade.Enable || cur.VersionUpgrade.Created {
+		return nil
+	}
+	lt := cur.LaunchTemplate
+	if lt.ID == "" {
+		return fmt.Errorf("MNGs[%q] launch template ID not found; cannot create launch template version", mngName)
+	}
+	userData := lt.UpgradeUserData
+	if userData == "" {
+		userData = lt.UserData
+	}
+
+	ts.cfg.Logger.Info("creating launch template version",
+		zap.String("mng-name", mngName),
+		zap.String("launch-template-id", lt.ID),
+		zap.Int64("source-version", lt.Version),
+	)
+	// only overwrite the user data, the other fields are kept from the source version
+	data := &aws_ec2_v2_types.RequestLaunchTemplateData{}
+	if v := ts.userData(cur, userData); v != "" {
+		data.UserData = aws_v2.String(base64.StdEncoding.EncodeToString([]byte(v)))
+	}
+	out, err := ts.cfg.EC2APIV2.CreateLaunchTemplateVersion(
+		context.Background(),
+		&aws_ec2_v2.CreateLaunchTemplateVersionInput{
+			LaunchTemplateId:   aws_v2.String(lt.ID),
+			SourceVersion:      aws_v2.String(strconv.FormatInt(lt.Version, 10)),
+			LaunchTemplateData: data,
+			VersionDescription: aws_v2.String("version upgrade " + cur.VersionUpgrade.Version),
+		},
+	)
+	if err != nil {
+		ts.cfg.Logger.Warn("failed to create launch template version", zap.Error(err))
+		return err
+	}
+	lt.Version = aws_v2.ToInt64(out.LaunchTemplateVersion.VersionNumber)
+	ts.cfg.EKSConfig.AddOnManagedNodeGroups.MNGs[mngName] = cur
+	ts.cfg.EKSConfig.Sync()
+	ts.cfg.Logger.Info("created launch template version",
+		zap.String("mng-name", mngName),
+		zap.String("launch-template-id", lt.ID),
+		zap.Int64("launch-template-version", lt.Version),
+	)
+	return nil
+}
+
+// must be run after deleting node group
+// otherwise, the launch template security group is still in use
+func (ts *tester) deleteLaunchTemplate(mngName string) error {
+	cur, ok := ts.cfg.EKSConfig.AddOnManagedNodeGroups.MNGs[mngName]
+	if !ok {
+		return fmt.Errorf("MNGs[%q] not found; cannot delete launch template", mngName)
+	}
+	if cur.LaunchTemplate == nil || !cur.LaunchTemplate.Enable {
+		return nil
+	}
+	lt := cur.LaunchTemplate
+
+	if _, ok := ts.cfg.EKSConfig.Status.DeletedResources[lt.ID]; lt.ID != "" && !ok {
+		ts.cfg.Logger.Info("deleting launch template", zap.String("mng-name", mngName), zap.String("launch-template-id", lt.ID))
+		_, err := ts.cfg.EC2APIV2.DeleteLaunchTemplate(
+			context.Background(),
+			&aws_ec2_v2.DeleteLaunchTemplateInput{
+				LaunchTemplateId: aws_v2.String(lt.ID),
+			},
+		)
+		if err != nil {
+			var apiErr smithy.APIError
+			if !errors.As(err, &apiErr) || !strings.Contains(apiErr.ErrorCode(), "NotFound") {
+				ts.cfg.Logger.Warn("failed to delete launch template", zap.Error(err))
+				return err
+			}
+		}
+		ts.cfg.EKSConfig.Status.DeletedResources[lt.ID] = "AddOnManagedNodeGroups.MNGs.LaunchTemplate.ID"
+		ts.cfg.EKSConfig.Sync()
+		ts.cfg.Logger.Info("deleted launch template", zap.String("launch-template-id", lt.ID))
+	}
+
+	if _, ok := ts.cfg.EKSConfig.Status.DeletedResources[lt.SecurityGroupID]; lt.SecurityGroupID != "" && !ok {
+		ts.cfg.Logger.Info("deleting launch template security group", zap.String("mng-name", mngName), zap.String("security-group-id", lt.SecurityGroupID))
+		retryStart, waitDur := time.Now(), 5*time.Minute
+		for {
+			_, err := ts.cfg.EC2APIV2.DeleteSecurityGroup(
+				context.Background(),
+				&aws_ec2_v2.DeleteSecurityGroupInput{
+					GroupId: aws_v2.String(lt.SecurityGroupID),
+				},
+			)
+			if err == nil {
+				break
+			}
+			var apiErr smithy.APIError
+			if !errors.As(err, &apiErr) {
+				return err
+			}
+			if strings.Contains(apiErr.ErrorCode(), "NotFound") {
+				break
+			}
+			// leaked ENIs may take awhile to be deleted
+			if apiErr.ErrorCode() != "DependencyViolation" || time.Since(retryStart) > waitDur {
+				return fmt.Errorf("failed to delete launch template security group %q (%v)", lt.SecurityGroupID, err)
+			}
+			ts.cfg.Logger.Warn("failed to delete launch template security group; retrying", zap.String("security-group-id", lt.SecurityGroupID), zap.Error(err))
+			select {
+			case <-ts.cfg.Stopc:
+				return errors.New("launch template security group deletion aborted")
+			case <-time.After(10 * time.Second):
+			}
+		}
+		ts.cfg.EKSConfig.Status.DeletedResources[lt.SecurityGroupID] = "AddOnManagedNodeGroups.MNGs.LaunchTemplate.SecurityGroupID"
+		ts.cfg.EKSConfig.Sync()
+		ts.cfg.Logger.Info("deleted launch template security group", zap.String("security-group-id", lt.SecurityGroupID))
+	}
+	return nil
+}
+
+func (ts *tester) launchTemplateData(cur eksconfig.MNG, userData string) *aws_ec2_v2_types.RequestLaunchTemplateData {
+	lt := cur.LaunchTemplate
+	data := &aws_ec2_v2_types.RequestLaunchTemplateData{
+		MetadataOptions: &aws_ec2_v2_types.LaunchTemplateInstanceMetadataOptionsRequest{
+			HttpEndpoint:            aws_ec2_v2_types.LaunchTemplateInstanceMetadataEndpointStateEnabled,
+			HttpTokens:              aws_ec2_v2_types.LaunchTemplateHttpTokensState(lt.MetadataHTTPTokens),
+			HttpPutResponseHopLimit: aws_v2.Int32(lt.MetadataHTTPPutResponseHopLimit),
+		},
+	}
+	if lt.ResolvedImageID != "" {
+		data.ImageId = aws_v2.String(lt.ResolvedImageID)
+	}
+	if ts.cfg.EKSConfig.RemoteAccessKeyName != "" {
+		data.KeyName = aws_v2.String(ts.cfg.EKSConfig.RemoteAccessKeyName)
+	}
+	for _, bd := range lt.BlockDevices {
+		ebs := &aws_ec2_v2_types.LaunchTemplateEbsBlockDeviceRequest{
+			DeleteOnTermination: aws_v2.Bool(true),
+			Encrypted:           aws_v2.Bool(bd.Encrypted),
+			VolumeSize:          aws_v2.Int32(bd.VolumeSize),
+			VolumeType:          aws_ec2_v2_types.VolumeType(bd.VolumeType),
+		}
+		if bd.IOPS > 0 {
+			ebs.Iops = aws_v2.Int32(bd.IOPS)
+		}
+		if bd.Throughput > 0 {
+			ebs.Throughput = aws_v2.Int32(bd.Throughput)
+		}
+		data.BlockDeviceMappings = append(data.BlockDeviceMappings, aws_ec2_v2_types.LaunchTemplateBlockDeviceMappingRequest{
+			DeviceName: aws_v2.String(bd.DeviceName),
+			Ebs:        ebs,
+		})
+	}
+	tags := make(map[string]string, len(ts.cfg.EKSConfig.Tags)+len(lt.Tags))
+	for k, v := range ts.cfg.EKSConfig.Tags {
+		tags[k] = v
+	}
+	for k, v := range lt.Tags {
+		tags[k] = v
+	}
+	if len(tags) > 0 {
+		for _, rt := range []aws_ec2_v2_types.ResourceType{aws_ec2_v2_types.ResourceTypeInstance, aws_ec2_v2_types.ResourceTypeVolume} {
+			data.TagSpecifications = append(data.TagSpecifications, aws_ec2_v2_types.LaunchTemplateTagSpecificationRequest{
+				ResourceType: rt,
+				Tags:         aws_ec2.AppendTags(nil, tags),
+			})
+		}
+	}
+	if userData != "" {
+		data.UserData = aws_v2.String(base64.StdEncoding.EncodeToString([]byte(userData)))
+	}
+	return data
+}
+
+func (ts *tester) fetchImageID(ssmParam string) (string, error) {
+	out, err := ts.cfg.SSMAPI.GetParameter(&ssm.GetParameterInput{
+		Name: aws_v2.String(ssmParam),
+	})
+	if err != nil {
+		return "", err
+	}
+	return aws_v2.ToString(out.Parameter.Value), nil
+}
+
+// userData returns the launch template user data with the shell script.
+// For the custom AMI, the bootstrap is appended since EKS does not merge
+// its own bootstrap user data.
+func (ts *tester) userData(cur eksconfig.MNG, script string) string {
+	if !cur.IsCustomAMI() {
+		return mimeUserData(script)
+	}
+	return customAMIUserData(
+		cur,
+		script,
+		ts.cfg.EKSConfig.Name,
+		ts.cfg.EKSConfig.Status.ClusterCA,
+		ts.cfg.EKSConfig.Status.ClusterAPIServerEndpoint,
+	)
+}
+
+// customAMINodeLabels returns the node labels that EKS sets for the
+// managed node group, since the custom AMI bootstrap must set them.
+// ref. https://docs.aws.amazon.com/eks/latest/userguide/launch-templates.html#launch-template-custom-ami
+func customAMINodeLabels(cur eksconfig.MNG) map[string]string {
+	capacityType := cur.CapacityType
+	if capacityType == "" {
+		capacityType = aws_eks.CapacityTypesOnDemand
+	}
+	labels := map[string]string{
+		"NodeType":                        "regular",
+		"AMIType":                         cur.AMIType,
+		"NGType":                          "managed",
+		"NGName":                          cur.Name,
+		"eks.amazonaws.com/nodegroup":     cur.Name,
+		"eks.amazonaws.com/capacityType":  capacityType,
+		eksconfig.NodeLabelNodegroupImage: cur.LaunchTemplate.ResolvedImageID,
+	}
+	for k, v := range cur.Labels {
+		labels[k] = v
+	}
+	return labels
+}
+
+// customAMIUserData returns the user data of the custom AMI, which runs
+// the shell script (if any) and then bootstraps the node for "AMIType".
+func customAMIUserData(cur eksconfig.MNG, script string, clusterName string, clusterCA string, endpoint string) string {
+	labels := customAMINodeLabels(cur)
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	switch cur.AMIType {
+	case aws_eks.AMITypesBottlerocketX8664, aws_eks.AMITypesBottlerocketArm64:
+		// admin container is required to SSH and fetch logs
+		// ref. https://github.com/bottlerocket-os/bottlerocket#admin-container
+		d := fmt.Sprintf(`[settings.kubernetes]
+cluster-name = %q
+cluster-certificate = %q
+api-server = %q
+[settings.kubernetes.node-labels]
+`, clusterName, clusterCA, endpoint)
+		for _, k := range keys {
+			d += fmt.Sprintf("%q = %q\n", k, labels[k])
+		}
+		if len(cur.Taints) > 0 {
+			d += "[settings.kubernetes.node-taints]\n"
+			for _, tv := range cur.Taints {
+				d += fmt.Sprintf("%q = %q\n", tv.Key, tv.Value+":"+taintEffects[tv.Effect])
+			}
+		}
+		d += "[settings.host-containers.admin]\nenabled = true\n"
+		return d
+	}
+
+	nodeLabels := make([]string, 0, len(keys))
+	for _, k := range keys {
+		nodeLabels = append(nodeLabels, k+"="+labels[k])
+	}
+	kubeletArgs := "--node-labels=" + strings.Join(nodeLabels, ",")
+	if len(cur.Taints) > 0 {
+		taints := make([]string, 0, len(cur.Taints))
+		for _, tv := range cur.Taints {
+			taints = append(taints, tv.Key+"="+tv.Value+":"+taintEffects[tv.Effect])
+		}
+		kubeletArgs += " --register-with-taints=" + strings.Join(taints, ",")
+	}
+	bootstrap := fmt.Sprintf(`#!/bin/bash
+set -xeu
+
+/etc/eks/bootstrap.sh %s --b64-cluster-ca %s --apiserver-endpoint %s --kubelet-extra-args '%s'
+`, clusterName, clusterCA, endpoint, kubeletArgs)
+	return mimeUserData(script, bootstrap)
+}
+
+// taintEffects maps the EKS API taint effect to the kubelet one.
+var taintEffects = map[string]string{
+	aws_eks.TaintEffectNoSchedule:       "NoSchedule",
+	aws_eks.TaintEffectNoExecute:        "NoExecute",
+	aws_eks.TaintEffectPreferNoSchedule: "PreferNoSchedule",
+}
+
+const userDataBoundary = "==AWS_K8S_TESTER_BOUNDARY=="
+
+// mimeUserData wraps the shell scripts in the MIME multi-part format,
+// which EKS merges with its bootstrap user data. The scripts are run
+// in order, and empty ones are skipped.
+// ref. https://docs.aws.amazon.com/eks/latest/userguide/launch-templates.html#launch-template-user-data
+func mimeUserData(scripts ...string) string {
+	parts := ""
+	for _, script := range scripts {
+		if strings.TrimSpace(script) == "" {
+			continue
+		}
+		if !strings.HasPrefix(script, "#!") {
+			script = "#!/bin/bash\n" + script
+		}
+		if !strings.HasSuffix(script, "\n") {
+			script += "\n"
+		}
+		parts += fmt.Sprintf(`--%s
+Content-Type: text/x-shellscript; charset="us-ascii"
+
+%s
+`, userDataBoundary, script)
+	}
+	if parts == "" {
+		return ""
+	}
+	return fmt.Sprintf(`MIME-Version: 1.0
+Content-Type: multipart/mixed; boundary="%s"
+
+%s--%s--
+`, userDataBoundary, parts, userDataBoundary)
+}
@@ -0,0 +1,86 @@
+package mng
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-k8s-tester/eksconfig"
+	aws_eks "github.com/aws/aws-sdk-go/service/eks"
+)
+
+func TestMIMEUserData(t *testing.T) {
+	if v := mimeUserData("  \n"); v != "" {
+		t.Fatalf("expected empty user data, got %q", v)
+	}
+
+	tt := []struct {
+		script string
+		part   string
+	}{
+		{"echo hello", "#!/bin/bash\necho hello\n"},
+		{"#!/bin/sh\necho hello\n", "#!/bin/sh\necho hello\n"},
+	}
+	for i, tv := range tt {
+		v := mimeUserData(tv.script)
+		if !strings.HasPrefix(v, "MIME-Version: 1.0\nContent-Type: multipart/mixed; boundary=\""+userDataBoundary+"\"\n") {
+			t.Fatalf("#%d: unexpected header %q", i, v)
+		}
+		if !strings.Contains(v, "Content-Type: text/x-shellscript; charset=\"us-ascii\"\n\n"+tv.part+"\n--"+userDataBoundary+"--\n") {
+			t.Fatalf("#%d: unexpected part %q", i, v)
+		}
+	}
+}
+
+func TestMIMEUserDataMultiPart(t *testing.T) {
+	v := mimeUserData("echo first", "", "echo second")
+	if strings.Count(v, "--"+userDataBoundary+"\n") != 2 {
+		t.Fatalf("expected 2 parts, got %q", v)
+	}
+	if strings.Index(v, "echo first") > strings.Index(v, "echo second") {
+		t.Fatalf("unexpected order of parts %q", v)
+	}
+	if !strings.HasSuffix(v, "\n--"+userDataBoundary+"--\n") {
+		t.Fatalf("unexpected trailer %q", v)
+	}
+}
+
+func TestCustomAMIUserData(t *testing.T) {
+	cur := eksconfig.MNG{
+		Name:         "mng-custom",
+		AMIType:      aws_eks.AMITypesAl2X8664,
+		CapacityType: aws_eks.CapacityTypesSpot,
+		Labels:       map[string]string{"team": "ami"},
+		Taints:       []eksconfig.MNGTaint{{Key: "dedicated", Value: "ami", Effect: aws_eks.TaintEffectNoSchedule}},
+		LaunchTemplate: &eksconfig.MNGLaunchTemplate{
+			Enable:          true,
+			ImageID:         "ami-0123456789abcdef0",
+			ResolvedImageID: "ami-0123456789abcdef0",
+		},
+	}
+
+	v := customAMIUserData(cur, "echo pre-bootstrap", "my-cluster", "Y2E=", "https://example.com")
+	if strings.Index(v, "echo pre-bootstrap") > strings.Index(v, "/etc/eks/bootstrap.sh") {
+		t.Fatalf("expected user script before bootstrap, got %q", v)
+	}
+	for _, sub := range []string{
+		"/etc/eks/bootstrap.sh my-cluster --b64-cluster-ca Y2E= --apiserver-endpoint https://example.com",
+		"--node-labels=AMIType=AL2_x86_64,NGName=mng-custom,NGType=managed,NodeType=regular,eks.amazonaws.com/capacityType=SPOT,eks.amazonaws.com/nodegroup=mng-custom,eks.amazonaws.com/nodegroup-image=ami-0123456789abcdef0,team=ami",
+		"--register-with-taints=dedicated=ami:NoSchedule",
+	} {
+		if !strings.Contains(v, sub) {
+			t.Fatalf("expected %q in %q", sub, v)
+		}
+	}
+
+	cur.AMIType = aws_eks.AMITypesBottlerocketX8664
+	v = customAMIUserData(cur, "", "my-cluster", "Y2E=", "https://example.com")
+	for _, sub := range []string{
+		"cluster-name = \"my-cluster\"\n",
+		"\"eks.amazonaws.com/nodegroup-image\" = \"ami-0123456789abcdef0\"\n",
+		"[settings.kubernetes.node-taints]\n\"dedicated\" = \"ami:NoSchedule\"\n",
+	} {
+		if !strings.Contains(v, sub) {
+			t.Fatalf("expected %q in %q", sub, v)
+		}
+	}
+}
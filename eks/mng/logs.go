@@ -95,7 +95,7 @@ func (ts *tester) fetchLogs(qps float32, burst int) error {
 		for instID, cur := range nodeGroup.Instances {
 			pfx := instID + "-"
 
-			go func(instID, logsDir, pfx string, cur ec2config.Instance) {
+			go func(instID, logsDir, pfx, amiType string, cur ec2config.Instance) {
 				select {
 				case <-ts.cfg.Stopc:
 					ts.cfg.Logger.Warn("exiting fetch logger", zap.String("prefix", pfx))
@@ -135,6 +135,12 @@ func (ts *tester) fetchLogs(qps float32, burst int) error {
 				}
 
 				data := instanceLogs{mngName: name, instanceID: instID}
+				if ec2config.IsBottlerocket(amiType) {
+					data.paths, data.errs = ts.fetchBottlerocketLogs(sh, sshOptLog, instID, logsDir, pfx)
+					rch <- data
+					return
+				}
+
 				// fetch default logs
 				for cmd, fileName := range defaultLogs {
 					if !rateLimiter.Allow() {
@@ -383,7 +389,7 @@ func (ts *tester) fetchLogs(qps float32, burst int) error {
 					}
 				}
 				rch <- data
-			}(instID, logsDir, pfx, cur)
+			}(instID, logsDir, pfx, nodeGroup.AMIType, cur)
 		}
 	}
 
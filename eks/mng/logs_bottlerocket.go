@@ -0,0 +1,72 @@
+package mng
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"time"
+
+	"github.com/aws/aws-k8s-tester/ssh"
+	"go.uber.org/zap"
+)
+
+// Bottlerocket has no shell on the host, and SSH lands in the admin
+// container, so run host commands via "sheltie" and collect the rest
+// with "logdog" that archives all host logs.
+// ref. https://github.com/bottlerocket-os/bottlerocket#logs
+// ref. https://github.com/bottlerocket-os/bottlerocket-admin-container
+var bottlerocketLogs = map[string]string{
+	// kernel logs
+	"sudo sheltie journalctl --no-pager --output=short-precise -k": "kernel.out.log",
+
+	// full journal logs (e.g. disk mounts)
+	"sudo sheltie journalctl --no-pager --output=short-precise": "journal.out.log",
+
+	// other systemd services
+	"sudo sheltie systemctl list-units -t service --no-pager --no-legend --all": "list-units-systemctl.out.log",
+}
+
+const (
+	// host root file system mounted in the admin container
+	bottlerocketLogdogPath = "/.bottlerocket/rootfs/var/log/support/bottlerocket-logs.tar.gz"
+	// copied to the admin container user home, in order to download via SCP
+	bottlerocketLogdogCopyPath = "/home/ec2-user/bottlerocket-logs.tar.gz"
+)
+
+// fetchBottlerocketLogs fetches logs from a Bottlerocket instance
+// via the admin container, instead of Amazon Linux paths.
+func (ts *tester) fetchBottlerocketLogs(sh ssh.SSH, sshOptLog ssh.OpOption, instID, logsDir, pfx string) (paths []string, errs []string) {
+	for cmd, fileName := range bottlerocketLogs {
+		out, oerr := sh.Run(cmd, sshOptLog, ssh.WithRetry(2, 3*time.Second))
+		if oerr != nil {
+			errs = append(errs, fmt.Sprintf("failed to run command %q for %q (error %v)", cmd, instID, oerr))
+			continue
+		}
+		fpath := filepath.Join(logsDir, shorten(ts.cfg.Logger, pfx+fileName))
+		if err := ioutil.WriteFile(fpath, out, 0600); err != nil {
+			errs = append(errs, fmt.Sprintf("failed to write to a file %q for %q (error %v)", fpath, instID, err))
+			continue
+		}
+		ts.cfg.Logger.Debug("wrote", zap.String("file-path", fpath))
+		paths = append(paths, fpath)
+	}
+
+	ts.cfg.Logger.Info("running logdog", zap.String("instance-id", instID))
+	logdogCmd := fmt.Sprintf("sudo sheltie logdog && sudo cp %s %s && sudo chown ec2-user %s",
+		bottlerocketLogdogPath,
+		bottlerocketLogdogCopyPath,
+		bottlerocketLogdogCopyPath,
+	)
+	if _, oerr := sh.Run(logdogCmd, sshOptLog, ssh.WithTimeout(5*time.Minute)); oerr != nil {
+		errs = append(errs, fmt.Sprintf("failed to run command %q for %q (error %v)", logdogCmd, instID, oerr))
+		return paths, errs
+	}
+	fpath := filepath.Join(logsDir, shorten(ts.cfg.Logger, pfx+filepath.Base(bottlerocketLogdogCopyPath)))
+	if _, oerr := sh.Download(bottlerocketLogdogCopyPath, fpath, sshOptLog, ssh.WithRetry(2, 3*time.Second)); oerr != nil {
+		errs = append(errs, fmt.Sprintf("failed to download %q for %q (error %v)", bottlerocketLogdogCopyPath, instID, oerr))
+		return paths, errs
+	}
+	ts.cfg.Logger.Debug("wrote", zap.String("file-path", fpath))
+	paths = append(paths, fpath)
+	return paths, errs
+}
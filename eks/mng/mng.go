@@ -13,15 +13,16 @@ import (
 	"github.com/aws/aws-k8s-tester/eks/mng/scale"
 	version_upgrade "github.com/aws/aws-k8s-tester/eks/mng/version-upgrade"
 	"github.com/aws/aws-k8s-tester/eks/mng/wait"
+	wait_v2 "github.com/aws/aws-k8s-tester/eks/mng/wait-v2"
 	"github.com/aws/aws-k8s-tester/eksconfig"
 	k8s_client "github.com/aws/aws-k8s-tester/pkg/k8s-client"
 	"github.com/aws/aws-k8s-tester/pkg/timeutil"
 	aws_asg_v2 "github.com/aws/aws-sdk-go-v2/service/autoscaling"
 	aws_ec2_v2 "github.com/aws/aws-sdk-go-v2/service/ec2"
-	aws_eks_v2 "github.com/aws/aws-sdk-go-v2/service/eks"
 	aws_iam_v2 "github.com/aws/aws-sdk-go-v2/service/iam"
 	"github.com/aws/aws-sdk-go/service/cloudformation/cloudformationiface"
 	"github.com/aws/aws-sdk-go/service/eks/eksiface"
+	"github.com/aws/aws-sdk-go/service/ssm/ssmiface"
 	"go.uber.org/zap"
 )
 
@@ -37,7 +38,8 @@ type Config struct {
 	EC2APIV2 *aws_ec2_v2.Client
 	ASGAPIV2 *aws_asg_v2.Client
 	EKSAPI   eksiface.EKSAPI
-	EKSAPIV2 *aws_eks_v2.Client
+	EKSAPIV2 wait_v2.EKSAPI
+	SSMAPI   ssmiface.SSMAPI
 
 	CFNAPI cloudformationiface.CloudFormationAPI
 }
@@ -145,6 +147,9 @@ func (ts *tester) Create() (err error) {
 		if err = ts.authorizeSecurityGroups(mngName); err != nil {
 			return err
 		}
+		if err = ts.propagateTags(mngName); err != nil {
+			return err
+		}
 	}
 
 	ts.cfg.EKSConfig.AddOnManagedNodeGroups.Created = true
@@ -178,6 +183,9 @@ func (ts *tester) UpgradeVersion() (err error) {
 		if cur.VersionUpgrade == nil || !cur.VersionUpgrade.Enable {
 			continue
 		}
+		if err = ts.createLaunchTemplateVersion(mngName); err != nil {
+			return err
+		}
 		if err = ts.versionUpgrader.Upgrade(mngName); err != nil {
 			return err
 		}
@@ -279,6 +287,16 @@ func (ts *tester) Delete() error {
 		errs = append(errs, err.Error())
 	}
 
+	for name := range ts.cfg.EKSConfig.AddOnManagedNodeGroups.MNGs {
+		if _, ok := failedMNGs[name]; ok {
+			continue
+		}
+		if err := ts.deleteLaunchTemplate(name); err != nil {
+			ts.cfg.Logger.Warn("failed to delete mng launch template", zap.String("name", name), zap.Error(err))
+			errs = append(errs, err.Error())
+		}
+	}
+
 	// must be run after deleting node group
 	// otherwise, "Cannot delete entity, must remove roles from instance profile first. (Service: AmazonIdentityManagement; Status Code: 409; Error Code: DeleteConflict; Request ID: 197f795b-1003-4386-81cc-44a926c42be7)"
 	if err := ts.deleteRole(); err != nil {
@@ -5,11 +5,13 @@ import (
 	"errors"
 	"fmt"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/aws/aws-k8s-tester/ec2config"
 	"github.com/aws/aws-k8s-tester/eks/mng/wait"
+	wait_v2 "github.com/aws/aws-k8s-tester/eks/mng/wait-v2"
 	"github.com/aws/aws-k8s-tester/pkg/timeutil"
 	"github.com/aws/aws-k8s-tester/pkg/user"
 	"github.com/aws/aws-k8s-tester/version"
@@ -67,26 +69,7 @@ func (ts *tester) deleteMNG(mngName string) error {
 		initialWait, timeout = 3*time.Minute, 20*time.Minute
 	}
 	ctx, cancel := context.WithTimeout(context.Background(), timeout)
-	ch := wait.Poll(
-		ctx,
-		ts.cfg.Stopc,
-		ts.cfg.Logger,
-		ts.cfg.LogWriter,
-		ts.cfg.EKSAPI,
-		ts.cfg.EKSConfig.Name,
-		mngName,
-		wait.ManagedNodeGroupStatusDELETEDORNOTEXIST,
-		initialWait,
-		20*time.Second,
-	)
-	for sv := range ch {
-		serr := ts.setStatus(sv)
-		if serr != nil {
-			cancel()
-			return serr
-		}
-		err = sv.Error
-	}
+	err = ts.pollMNG(ctx, mngName, wait.ManagedNodeGroupStatusDELETEDORNOTEXIST, initialWait, 20*time.Second)
 	cancel()
 	if err != nil {
 		return err
@@ -141,14 +124,62 @@ func (ts *tester) _createMNGs() (tss tupleTimes, err error) {
 				"NGName":   aws_v2.String(cur.Name),
 			},
 		}
+		for k, v := range ts.cfg.EKSConfig.Tags {
+			createInput.Tags[k] = aws_v2.String(v)
+		}
 		for k, v := range cur.Tags {
 			createInput.Tags[k] = aws_v2.String(v)
 			ts.cfg.Logger.Info("added EKS tag", zap.String("key", k), zap.String("value", v))
 		}
+		if cur.CapacityType != "" {
+			createInput.CapacityType = aws_v2.String(cur.CapacityType)
+			ts.cfg.Logger.Info("set MNG capacity type", zap.String("capacity-type", cur.CapacityType))
+		}
+		if len(cur.SubnetIDs) > 0 {
+			createInput.Subnets = aws_v2.StringSlice(cur.SubnetIDs)
+			ts.cfg.Logger.Info("set MNG subnets", zap.Strings("subnet-ids", cur.SubnetIDs))
+		}
+		for k, v := range cur.Labels {
+			createInput.Labels[k] = aws_v2.String(v)
+			ts.cfg.Logger.Info("added node label", zap.String("key", k), zap.String("value", v))
+		}
+		for _, tv := range cur.Taints {
+			createInput.Taints = append(createInput.Taints, &aws_eks.Taint{
+				Key:    aws_v2.String(tv.Key),
+				Value:  aws_v2.String(tv.Value),
+				Effect: aws_v2.String(tv.Effect),
+			})
+			ts.cfg.Logger.Info("added node taint", zap.String("key", tv.Key), zap.String("value", tv.Value), zap.String("effect", tv.Effect))
+		}
 		if cur.ReleaseVersion != "" {
 			createInput.ReleaseVersion = aws_v2.String(cur.ReleaseVersion)
 			ts.cfg.Logger.Info("added EKS release version", zap.String("version", cur.ReleaseVersion))
 		}
+		if cur.Version != "" {
+			createInput.Version = aws_v2.String(cur.Version)
+			ts.cfg.Logger.Info("added Kubernetes version", zap.String("version", cur.Version))
+		}
+		if cur.LaunchTemplate != nil && cur.LaunchTemplate.Enable {
+			if err := ts.createLaunchTemplate(mngName); err != nil {
+				return nil, fmt.Errorf("MNGs[%q] launch template create failed (%v)", cur.Name, err)
+			}
+			cur = ts.cfg.EKSConfig.AddOnManagedNodeGroups.MNGs[mngName]
+			// disk size and remote access must be set in the launch template
+			createInput.DiskSize = nil
+			createInput.RemoteAccess = nil
+			createInput.LaunchTemplate = &aws_eks.LaunchTemplateSpecification{
+				Id:      aws_v2.String(cur.LaunchTemplate.ID),
+				Version: aws_v2.String(strconv.FormatInt(cur.LaunchTemplate.Version, 10)),
+			}
+			if cur.IsCustomAMI() {
+				// the AMI is set in the launch template
+				createInput.AmiType = aws_v2.String(aws_eks.AMITypesCustom)
+			}
+			ts.cfg.Logger.Info("set MNG launch template",
+				zap.String("launch-template-id", cur.LaunchTemplate.ID),
+				zap.Int64("launch-template-version", cur.LaunchTemplate.Version),
+			)
+		}
 		timeStart := time.Now()
 		req, _ := ts.cfg.EKSAPI.CreateNodegroupRequest(&createInput)
 		if ts.cfg.EKSConfig.AddOnManagedNodeGroups.RequestHeaderKey != "" && ts.cfg.EKSConfig.AddOnManagedNodeGroups.RequestHeaderValue != "" {
@@ -203,26 +234,7 @@ func (ts *tester) waitForMNGs(now time.Time, tss tupleTimes) (err error) {
 
 		timeStart := time.Now()
 		ctx, cancel := context.WithTimeout(context.Background(), 15*time.Minute)
-		ch := wait.Poll(
-			ctx,
-			ts.cfg.Stopc,
-			ts.cfg.Logger,
-			ts.cfg.LogWriter,
-			ts.cfg.EKSAPI,
-			ts.cfg.EKSConfig.Name,
-			mngName,
-			aws_eks.NodegroupStatusActive,
-			time.Minute,
-			20*time.Second,
-		)
-		for sv := range ch {
-			serr := ts.setStatus(sv)
-			if serr != nil {
-				cancel()
-				return serr
-			}
-			err = sv.Error
-		}
+		err = ts.pollMNG(ctx, mngName, aws_eks.NodegroupStatusActive, time.Minute, 20*time.Second)
 		cancel()
 		if err != nil {
 			return err
@@ -261,7 +273,32 @@ func (ts *tester) waitForMNGs(now time.Time, tss tupleTimes) (err error) {
 	return nil
 }
 
-func (ts *tester) setStatus(sv wait.ManagedNodeGroupStatus) (err error) {
+// pollMNG polls the managed node group until the desired status
+// with aws-sdk-go-v2, updating the node group status in the configuration,
+// and returns the last poll error.
+func (ts *tester) pollMNG(ctx context.Context, mngName string, desired string, initialWait time.Duration, pollInterval time.Duration) (err error) {
+	ch := wait_v2.Poll(
+		ctx,
+		ts.cfg.Stopc,
+		ts.cfg.Logger,
+		ts.cfg.LogWriter,
+		ts.cfg.EKSAPIV2,
+		ts.cfg.EKSConfig.Name,
+		mngName,
+		desired,
+		initialWait,
+		pollInterval,
+	)
+	for sv := range ch {
+		if serr := ts.setStatus(sv); serr != nil {
+			return serr
+		}
+		err = sv.Error
+	}
+	return err
+}
+
+func (ts *tester) setStatus(sv wait_v2.ManagedNodeGroupStatus) (err error) {
 	name := sv.NodeGroupName
 	if name == "" {
 		return errors.New("EKS Managed Node Group empty name")
@@ -278,7 +315,7 @@ func (ts *tester) setStatus(sv wait.ManagedNodeGroupStatus) (err error) {
 			cur.Status = wait.ManagedNodeGroupStatusDELETEDORNOTEXIST
 		}
 	} else {
-		cur.Status = aws_v2.ToString(sv.NodeGroup.Status)
+		cur.Status = string(sv.NodeGroup.Status)
 		if sv.NodeGroup.Resources != nil && cur.RemoteAccessSecurityGroupID == "" {
 			cur.RemoteAccessSecurityGroupID = aws_v2.ToString(sv.NodeGroup.Resources.RemoteAccessSecurityGroup)
 		}
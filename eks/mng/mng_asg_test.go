@@ -0,0 +1,62 @@
+package mng
+
+import (
+	"context"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-k8s-tester/eksconfig"
+	aws_v2 "github.com/aws/aws-sdk-go-v2/aws"
+	aws_eks_v2 "github.com/aws/aws-sdk-go-v2/service/eks"
+	aws_eks_v2_types "github.com/aws/aws-sdk-go-v2/service/eks/types"
+	"go.uber.org/zap"
+)
+
+type fakeEKSAPIV2 struct {
+	statuses []aws_eks_v2_types.NodegroupStatus
+	calls    int
+}
+
+func (f *fakeEKSAPIV2) DescribeNodegroup(ctx context.Context, params *aws_eks_v2.DescribeNodegroupInput, optFns ...func(*aws_eks_v2.Options)) (*aws_eks_v2.DescribeNodegroupOutput, error) {
+	idx := f.calls
+	if idx >= len(f.statuses) {
+		idx = len(f.statuses) - 1
+	}
+	f.calls++
+	return &aws_eks_v2.DescribeNodegroupOutput{
+		Nodegroup: &aws_eks_v2_types.Nodegroup{
+			NodegroupName: params.NodegroupName,
+			Status:        f.statuses[idx],
+			Resources:     &aws_eks_v2_types.NodegroupResources{RemoteAccessSecurityGroup: aws_v2.String("sg-1")},
+		},
+	}, nil
+}
+
+func TestPollMNG(t *testing.T) {
+	cfg := eksconfig.NewDefault()
+	cfg.ConfigPath = filepath.Join(t.TempDir(), "eks.yaml")
+	cfg.AddOnManagedNodeGroups.MNGs = map[string]eksconfig.MNG{"my-mng": {Name: "my-mng"}}
+
+	ts := &tester{cfg: Config{
+		Logger:    zap.NewNop(),
+		LogWriter: ioutil.Discard,
+		Stopc:     make(chan struct{}),
+		EKSConfig: cfg,
+		EKSAPIV2: &fakeEKSAPIV2{statuses: []aws_eks_v2_types.NodegroupStatus{
+			aws_eks_v2_types.NodegroupStatusCreating,
+			aws_eks_v2_types.NodegroupStatusActive,
+		}},
+	}}
+	if err := ts.pollMNG(context.Background(), "my-mng", string(aws_eks_v2_types.NodegroupStatusActive), time.Millisecond, time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	cur := cfg.AddOnManagedNodeGroups.MNGs["my-mng"]
+	if cur.Status != string(aws_eks_v2_types.NodegroupStatusActive) {
+		t.Fatalf("unexpected status %q", cur.Status)
+	}
+	if cur.RemoteAccessSecurityGroupID != "sg-1" {
+		t.Fatalf("unexpected remote access security group %q", cur.RemoteAccessSecurityGroupID)
+	}
+}
@@ -0,0 +1,73 @@
+package mng
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	aws_ec2 "github.com/aws/aws-k8s-tester/pkg/aws/ec2"
+	aws_v2 "github.com/aws/aws-sdk-go-v2/aws"
+	aws_asg_v2 "github.com/aws/aws-sdk-go-v2/service/autoscaling"
+	aws_ec2_v2 "github.com/aws/aws-sdk-go-v2/service/ec2"
+	"go.uber.org/zap"
+)
+
+// propagateTags applies the node group tags to the ASG, its instances,
+// and the remote access security group, since EKS does not propagate
+// the managed node group tags to the resources it creates.
+// ASG tags are propagated at launch, so that replaced or scaled-out
+// instances are tagged as well.
+func (ts *tester) propagateTags(name string) error {
+	cur, ok := ts.cfg.EKSConfig.AddOnManagedNodeGroups.MNGs[name]
+	if !ok {
+		return fmt.Errorf("MNGs[%q] not found; cannot propagate tags", name)
+	}
+	tags := ts.cfg.EKSConfig.MergeTags(cur.Tags)
+	if len(tags) == 0 {
+		return nil
+	}
+	if cur.ASGName == "" {
+		return fmt.Errorf("MNG[%q] ASG name not found; cannot propagate tags", name)
+	}
+	ts.cfg.Logger.Info("propagating tags",
+		zap.String("mng-name", name),
+		zap.String("asg-name", cur.ASGName),
+		zap.Int("instances", len(cur.Instances)),
+	)
+
+	asgTags := aws_ec2.AppendASGTags(nil, tags)
+	for i := range asgTags {
+		asgTags[i].ResourceId = aws_v2.String(cur.ASGName)
+		asgTags[i].ResourceType = aws_v2.String("auto-scaling-group")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	_, err := ts.cfg.ASGAPIV2.CreateOrUpdateTags(ctx, &aws_asg_v2.CreateOrUpdateTagsInput{
+		Tags: asgTags,
+	})
+	cancel()
+	if err != nil {
+		return fmt.Errorf("failed to tag ASG %q (%v)", cur.ASGName, err)
+	}
+
+	resources := make([]string, 0, len(cur.Instances)+1)
+	for id := range cur.Instances {
+		resources = append(resources, id)
+	}
+	if cur.RemoteAccessSecurityGroupID != "" {
+		resources = append(resources, cur.RemoteAccessSecurityGroupID)
+	}
+	if len(resources) == 0 {
+		return nil
+	}
+	ctx, cancel = context.WithTimeout(context.Background(), time.Minute)
+	_, err = ts.cfg.EC2APIV2.CreateTags(ctx, &aws_ec2_v2.CreateTagsInput{
+		Resources: resources,
+		Tags:      aws_ec2.AppendTags(nil, tags),
+	})
+	cancel()
+	if err != nil {
+		return fmt.Errorf("failed to tag MNG %q resources %q (%v)", name, resources, err)
+	}
+	ts.cfg.Logger.Info("propagated tags", zap.String("mng-name", name), zap.Strings("resources", resources))
+	return nil
+}
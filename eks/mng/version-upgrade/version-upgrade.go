@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"io"
 	"reflect"
+	"strconv"
 	"time"
 
 	"github.com/aws/aws-k8s-tester/eks/mng/wait"
@@ -107,12 +108,25 @@ func (ts *tester) Upgrade(mngName string) (err error) {
 	}
 
 	// ref. https://docs.aws.amazon.com/cli/latest/reference/eks/update-nodegroup-version.html
-	var updateOut *eks.UpdateNodegroupVersionOutput
-	updateOut, err = ts.cfg.EKSAPI.UpdateNodegroupVersion(&eks.UpdateNodegroupVersionInput{
+	updateInput := &eks.UpdateNodegroupVersionInput{
 		ClusterName:   aws.String(ts.cfg.EKSConfig.Name),
 		NodegroupName: aws.String(mngName),
 		Version:       aws.String(cur.VersionUpgrade.Version),
-	})
+	}
+	if cur.LaunchTemplate != nil && cur.LaunchTemplate.Enable && cur.LaunchTemplate.ID != "" {
+		// roll out the launch template version created for the upgrade
+		updateInput.LaunchTemplate = &eks.LaunchTemplateSpecification{
+			Id:      aws.String(cur.LaunchTemplate.ID),
+			Version: aws.String(strconv.FormatInt(cur.LaunchTemplate.Version, 10)),
+		}
+		ts.cfg.Logger.Info("upgrading MNG launch template version",
+			zap.String("mng-name", mngName),
+			zap.String("launch-template-id", cur.LaunchTemplate.ID),
+			zap.Int64("launch-template-version", cur.LaunchTemplate.Version),
+		)
+	}
+	var updateOut *eks.UpdateNodegroupVersionOutput
+	updateOut, err = ts.cfg.EKSAPI.UpdateNodegroupVersion(updateInput)
 	if err != nil {
 		ts.cfg.Logger.Warn("MNG version upgrade request failed", zap.String("mng-name", mngName), zap.Error(err))
 		return err
@@ -0,0 +1,224 @@
+// Package wait_v2 implements managed node group waiter with aws-sdk-go-v2.
+package wait_v2
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	cluster_wait "github.com/aws/aws-k8s-tester/eks/cluster/wait"
+	"github.com/aws/aws-k8s-tester/eks/mng/wait"
+	"github.com/aws/aws-k8s-tester/pkg/ctxutil"
+	"github.com/aws/aws-k8s-tester/pkg/spinner"
+	aws_v2 "github.com/aws/aws-sdk-go-v2/aws"
+	aws_eks_v2 "github.com/aws/aws-sdk-go-v2/service/eks"
+	aws_eks_v2_types "github.com/aws/aws-sdk-go-v2/service/eks/types"
+	smithy "github.com/aws/smithy-go"
+	"github.com/dustin/go-humanize"
+	"go.uber.org/zap"
+)
+
+// ErrWaitStopped is returned when the wait is stopped via stop channel.
+var ErrWaitStopped = errors.New("wait stopped")
+
+// EKSAPI is the subset of the aws-sdk-go-v2 EKS client used by the poller,
+// so that the poller can be tested with a fake.
+// "*aws_eks_v2.Client" satisfies this interface.
+type EKSAPI interface {
+	DescribeNodegroup(ctx context.Context, params *aws_eks_v2.DescribeNodegroupInput, optFns ...func(*aws_eks_v2.Options)) (*aws_eks_v2.DescribeNodegroupOutput, error)
+}
+
+// ManagedNodeGroupStatus represents the managed node group status.
+type ManagedNodeGroupStatus struct {
+	NodeGroupName string
+	NodeGroup     *aws_eks_v2_types.Nodegroup
+	Error         error
+}
+
+// Poll periodically fetches the managed node group status
+// until the node group becomes the desired state.
+// Use "wait.ManagedNodeGroupStatusDELETEDORNOTEXIST" to wait for deletion.
+// Failed node groups return "*wait.NodeGroupFailedError", same as "wait.Poll".
+func Poll(
+	ctx context.Context,
+	stopc chan struct{},
+	lg *zap.Logger,
+	logWriter io.Writer,
+	eksAPIV2 EKSAPI,
+	clusterName string,
+	mngName string,
+	desiredNodeGroupStatus string,
+	initialWait time.Duration,
+	pollInterval time.Duration) <-chan ManagedNodeGroupStatus {
+
+	now := time.Now()
+	sp := spinner.New(logWriter, "Waiting for Managed Node Group status "+desiredNodeGroupStatus)
+
+	lg.Info("polling mng",
+		zap.String("cluster-name", clusterName),
+		zap.String("mng-name", mngName),
+		zap.String("desired-status", desiredNodeGroupStatus),
+		zap.String("initial-wait", initialWait.String()),
+		zap.String("poll-interval", pollInterval.String()),
+		zap.String("ctx-time-left", ctxutil.TimeLeftTillDeadline(ctx)),
+	)
+
+	ch := make(chan ManagedNodeGroupStatus, 10)
+	go func() {
+		// very first poll should be no-wait
+		// in case stack has already reached desired status
+		// wait from second interation
+		waitDur := time.Duration(0)
+
+		first := true
+		for ctx.Err() == nil {
+			select {
+			case <-ctx.Done():
+				lg.Warn("wait aborted, ctx done", zap.Error(ctx.Err()))
+				ch <- ManagedNodeGroupStatus{NodeGroupName: mngName, NodeGroup: nil, Error: ctx.Err()}
+				close(ch)
+				return
+
+			case <-stopc:
+				lg.Warn("wait stopped, stopc closed", zap.Error(ctx.Err()))
+				ch <- ManagedNodeGroupStatus{NodeGroupName: mngName, NodeGroup: nil, Error: ErrWaitStopped}
+				close(ch)
+				return
+
+			case <-time.After(waitDur):
+				// very first poll should be no-wait
+				// in case stack has already reached desired status
+				// wait from second interation
+				if waitDur == time.Duration(0) {
+					waitDur = pollInterval
+				}
+			}
+
+			output, err := eksAPIV2.DescribeNodegroup(
+				ctx,
+				&aws_eks_v2.DescribeNodegroupInput{
+					ClusterName:   aws_v2.String(clusterName),
+					NodegroupName: aws_v2.String(mngName),
+				},
+			)
+			if err != nil {
+				if IsDeleted(err) {
+					if desiredNodeGroupStatus == wait.ManagedNodeGroupStatusDELETEDORNOTEXIST {
+						lg.Info("managed node group is already deleted as desired; exiting", zap.Error(err))
+						ch <- ManagedNodeGroupStatus{NodeGroupName: mngName, NodeGroup: nil, Error: nil}
+						close(ch)
+						return
+					}
+					lg.Warn("managed node group does not exist", zap.Error(err))
+					ch <- ManagedNodeGroupStatus{NodeGroupName: mngName, NodeGroup: nil, Error: err}
+					close(ch)
+					return
+				}
+				lg.Warn("describe managed node group failed; retrying", zap.Error(err))
+				ch <- ManagedNodeGroupStatus{NodeGroupName: mngName, NodeGroup: nil, Error: err}
+				continue
+			}
+
+			if output.Nodegroup == nil {
+				lg.Warn("expected non-nil managed node group; retrying")
+				ch <- ManagedNodeGroupStatus{NodeGroupName: mngName, NodeGroup: nil, Error: fmt.Errorf("unexpected empty response %+v", *output)}
+				continue
+			}
+
+			nodeGroup := output.Nodegroup
+			currentStatus := string(nodeGroup.Status)
+			lg.Info("poll",
+				zap.String("cluster-name", clusterName),
+				zap.String("mng-name", mngName),
+				zap.String("status", currentStatus),
+				zap.String("started", humanize.RelTime(now, time.Now(), "ago", "from now")),
+				zap.String("ctx-time-left", ctxutil.TimeLeftTillDeadline(ctx)),
+			)
+			switch currentStatus {
+			case desiredNodeGroupStatus:
+				ch <- ManagedNodeGroupStatus{NodeGroupName: mngName, NodeGroup: nodeGroup, Error: nil}
+				lg.Info("desired managed node group status; done", zap.String("status", currentStatus))
+				close(ch)
+				return
+
+			case string(aws_eks_v2_types.NodegroupStatusCreateFailed),
+				string(aws_eks_v2_types.NodegroupStatusDeleteFailed),
+				string(aws_eks_v2_types.NodegroupStatusDegraded):
+				failErr := newNodeGroupFailedError(mngName, nodeGroup)
+				lg.Warn("unexpected managed node group status; failed", zap.String("status", currentStatus), zap.Error(failErr))
+				ch <- ManagedNodeGroupStatus{NodeGroupName: mngName, NodeGroup: nodeGroup, Error: failErr}
+				close(ch)
+				return
+
+			default:
+				ch <- ManagedNodeGroupStatus{NodeGroupName: mngName, NodeGroup: nodeGroup, Error: nil}
+			}
+
+			if first {
+				lg.Info("sleeping", zap.Duration("initial-wait", initialWait))
+				sp.Restart()
+				select {
+				case <-ctx.Done():
+					sp.Stop()
+					lg.Warn("wait aborted, ctx done", zap.Error(ctx.Err()))
+					ch <- ManagedNodeGroupStatus{NodeGroupName: mngName, NodeGroup: nil, Error: ctx.Err()}
+					close(ch)
+					return
+				case <-stopc:
+					sp.Stop()
+					lg.Warn("wait stopped, stopc closed", zap.Error(ctx.Err()))
+					ch <- ManagedNodeGroupStatus{NodeGroupName: mngName, NodeGroup: nil, Error: ErrWaitStopped}
+					close(ch)
+					return
+				case <-time.After(initialWait):
+					sp.Stop()
+				}
+				first = false
+			}
+		}
+
+		lg.Warn("wait aborted, ctx done", zap.Error(ctx.Err()))
+		ch <- ManagedNodeGroupStatus{NodeGroupName: mngName, NodeGroup: nil, Error: ctx.Err()}
+		close(ch)
+	}()
+	return ch
+}
+
+// IsDeleted returns true if error from EKS API indicates that
+// the EKS managed node group has already been deleted.
+func IsDeleted(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) && apiErr.ErrorCode() == "ResourceNotFoundException" {
+		return true
+	}
+
+	// ResourceNotFoundException: nodeGroup eks-2019120505-pdx-us-west-2-tqy2d-managed-node-group not found for cluster eks-2019120505-pdx-us-west-2-tqy2d\n\tstatus code: 404, request id: 330998c1-22e9-4a8b-b180-420dadade090
+	return strings.Contains(err.Error(), "No cluster found for") ||
+		strings.Contains(err.Error(), " not found for cluster ")
+}
+
+// newNodeGroupFailedError extracts the health issues of the node group.
+func newNodeGroupFailedError(mngName string, ng *aws_eks_v2_types.Nodegroup) *wait.NodeGroupFailedError {
+	e := &wait.NodeGroupFailedError{
+		NodeGroupName: mngName,
+		Status:        string(ng.Status),
+	}
+	if ng.Health == nil {
+		return e
+	}
+	for _, v := range ng.Health.Issues {
+		e.HealthIssues = append(e.HealthIssues, cluster_wait.HealthIssue{
+			Code:        string(v.Code),
+			Message:     aws_v2.ToString(v.Message),
+			ResourceIDs: v.ResourceIds,
+		})
+	}
+	return e
+}
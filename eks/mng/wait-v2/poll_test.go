@@ -0,0 +1,91 @@
+package wait_v2
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-k8s-tester/eks/mng/wait"
+	aws_v2 "github.com/aws/aws-sdk-go-v2/aws"
+	aws_eks_v2 "github.com/aws/aws-sdk-go-v2/service/eks"
+	aws_eks_v2_types "github.com/aws/aws-sdk-go-v2/service/eks/types"
+	smithy "github.com/aws/smithy-go"
+	"go.uber.org/zap"
+)
+
+type fakeEKSAPI struct {
+	statuses []aws_eks_v2_types.NodegroupStatus
+	health   *aws_eks_v2_types.NodegroupHealth
+	err      error
+	calls    int
+}
+
+func (f *fakeEKSAPI) DescribeNodegroup(ctx context.Context, params *aws_eks_v2.DescribeNodegroupInput, optFns ...func(*aws_eks_v2.Options)) (*aws_eks_v2.DescribeNodegroupOutput, error) {
+	f.calls++
+	if f.err != nil {
+		return nil, f.err
+	}
+	idx := f.calls - 1
+	if idx >= len(f.statuses) {
+		idx = len(f.statuses) - 1
+	}
+	return &aws_eks_v2.DescribeNodegroupOutput{
+		Nodegroup: &aws_eks_v2_types.Nodegroup{
+			NodegroupName: params.NodegroupName,
+			Status:        f.statuses[idx],
+			Health:        f.health,
+		},
+	}, nil
+}
+
+func poll(t *testing.T, api EKSAPI, desired string) (last ManagedNodeGroupStatus) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	for sv := range Poll(ctx, make(chan struct{}), zap.NewExample(), ioutil.Discard, api, "my-cluster", "my-mng", desired, time.Millisecond, time.Millisecond) {
+		last = sv
+	}
+	return last
+}
+
+func TestPoll(t *testing.T) {
+	api := &fakeEKSAPI{statuses: []aws_eks_v2_types.NodegroupStatus{
+		aws_eks_v2_types.NodegroupStatusCreating,
+		aws_eks_v2_types.NodegroupStatusCreating,
+		aws_eks_v2_types.NodegroupStatusActive,
+	}}
+	last := poll(t, api, string(aws_eks_v2_types.NodegroupStatusActive))
+	if last.Error != nil {
+		t.Fatal(last.Error)
+	}
+	if last.NodeGroup == nil || last.NodeGroup.Status != aws_eks_v2_types.NodegroupStatusActive {
+		t.Fatalf("unexpected last status %+v", last)
+	}
+	if api.calls != 3 {
+		t.Fatalf("unexpected DescribeNodegroup calls %d", api.calls)
+	}
+
+	api = &fakeEKSAPI{
+		statuses: []aws_eks_v2_types.NodegroupStatus{aws_eks_v2_types.NodegroupStatusDegraded},
+		health: &aws_eks_v2_types.NodegroupHealth{Issues: []aws_eks_v2_types.Issue{
+			{Code: "AsgInstanceLaunchFailures", Message: aws_v2.String("failed"), ResourceIds: []string{"asg-1"}},
+		}},
+	}
+	last = poll(t, api, string(aws_eks_v2_types.NodegroupStatusActive))
+	var failErr *wait.NodeGroupFailedError
+	if !errors.As(last.Error, &failErr) || !errors.Is(last.Error, wait.ErrNodeGroupFailed) {
+		t.Fatalf("expected NodeGroupFailedError, got %v", last.Error)
+	}
+	if !failErr.HasIssue("AsgInstanceLaunchFailures") {
+		t.Fatalf("unexpected health issues %+v", failErr.HealthIssues)
+	}
+
+	api = &fakeEKSAPI{err: &smithy.GenericAPIError{Code: "ResourceNotFoundException", Message: "nodeGroup my-mng not found for cluster my-cluster"}}
+	if last = poll(t, api, wait.ManagedNodeGroupStatusDELETEDORNOTEXIST); last.Error != nil || last.NodeGroup != nil {
+		t.Fatalf("unexpected last status %+v", last)
+	}
+	if last = poll(t, api, string(aws_eks_v2_types.NodegroupStatusActive)); !IsDeleted(last.Error) {
+		t.Fatalf("expected not found error, got %v", last.Error)
+	}
+}
@@ -0,0 +1,73 @@
+package wait
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	cluster_wait "github.com/aws/aws-k8s-tester/eks/cluster/wait"
+	"github.com/aws/aws-sdk-go/aws"
+	aws_eks "github.com/aws/aws-sdk-go/service/eks"
+)
+
+// ErrNodeGroupFailed is matched by "NodeGroupFailedError" via "errors.Is".
+var ErrNodeGroupFailed = errors.New("managed node group failed")
+
+// NodeGroupFailedError is returned when the managed node group reaches
+// an unexpected terminal status (e.g. "DEGRADED", "CREATE_FAILED"),
+// with the health issues reported by EKS. Use "errors.As" and "HasIssue"
+// to branch on the failure class (e.g. "AsgInstanceLaunchFailures").
+type NodeGroupFailedError struct {
+	NodeGroupName string
+	Status        string
+	HealthIssues  []cluster_wait.HealthIssue
+}
+
+func (e *NodeGroupFailedError) Error() string {
+	if len(e.HealthIssues) == 0 {
+		return fmt.Sprintf("unexpected mng status %q", e.Status)
+	}
+	ss := make([]string, 0, len(e.HealthIssues))
+	for _, v := range e.HealthIssues {
+		ss = append(ss, "["+v.String()+"]")
+	}
+	return fmt.Sprintf("unexpected mng status %q (health issues %s)", e.Status, strings.Join(ss, ", "))
+}
+
+// Is returns true if the target is "ErrNodeGroupFailed".
+func (e *NodeGroupFailedError) Is(target error) bool {
+	return target == ErrNodeGroupFailed
+}
+
+// HasIssue returns true if any of the health issues has the code
+// (e.g. "AsgInstanceLaunchFailures", "Ec2LaunchTemplateVersionMismatch").
+func (e *NodeGroupFailedError) HasIssue(code string) bool {
+	for _, v := range e.HealthIssues {
+		if v.Code == code {
+			return true
+		}
+	}
+	return false
+}
+
+// newNodeGroupFailedError extracts the health issues of the node group.
+func newNodeGroupFailedError(mngName string, ng *aws_eks.Nodegroup) *NodeGroupFailedError {
+	e := &NodeGroupFailedError{
+		NodeGroupName: mngName,
+		Status:        aws.StringValue(ng.Status),
+	}
+	if ng.Health == nil {
+		return e
+	}
+	for _, v := range ng.Health.Issues {
+		if v == nil {
+			continue
+		}
+		e.HealthIssues = append(e.HealthIssues, cluster_wait.HealthIssue{
+			Code:        aws.StringValue(v.Code),
+			Message:     aws.StringValue(v.Message),
+			ResourceIDs: aws.StringValueSlice(v.ResourceIds),
+		})
+	}
+	return e
+}
@@ -146,8 +146,9 @@ func Poll(
 			case aws_eks.NodegroupStatusCreateFailed,
 				aws_eks.NodegroupStatusDeleteFailed,
 				aws_eks.NodegroupStatusDegraded:
-				lg.Warn("unexpected managed node group status; failed", zap.String("status", currentStatus))
-				ch <- ManagedNodeGroupStatus{NodeGroupName: mngName, NodeGroup: nodeGroup, Error: fmt.Errorf("unexpected mng status %q", currentStatus)}
+				failErr := newNodeGroupFailedError(mngName, nodeGroup)
+				lg.Warn("unexpected managed node group status; failed", zap.String("status", currentStatus), zap.Error(failErr))
+				ch <- ManagedNodeGroupStatus{NodeGroupName: mngName, NodeGroup: nodeGroup, Error: failErr}
 				close(ch)
 				return
 
@@ -0,0 +1,134 @@
+package wait
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	aws_eks "github.com/aws/aws-sdk-go/service/eks"
+	"github.com/aws/aws-sdk-go/service/eks/eksiface"
+	"go.uber.org/zap"
+)
+
+// fakeEKSAPI returns the node group statuses in order,
+// and repeats the last status once exhausted.
+type fakeEKSAPI struct {
+	eksiface.EKSAPI
+
+	statuses []string
+	health   *aws_eks.NodegroupHealth
+	err      error
+	calls    int
+}
+
+func (f *fakeEKSAPI) DescribeNodegroup(input *aws_eks.DescribeNodegroupInput) (*aws_eks.DescribeNodegroupOutput, error) {
+	f.calls++
+	if f.err != nil {
+		return nil, f.err
+	}
+	idx := f.calls - 1
+	if idx >= len(f.statuses) {
+		idx = len(f.statuses) - 1
+	}
+	return &aws_eks.DescribeNodegroupOutput{
+		Nodegroup: &aws_eks.Nodegroup{
+			ClusterName:   input.ClusterName,
+			NodegroupName: input.NodegroupName,
+			Status:        aws.String(f.statuses[idx]),
+			Health:        f.health,
+		},
+	}, nil
+}
+
+func TestPoll(t *testing.T) {
+	launchFailures := &aws_eks.NodegroupHealth{Issues: []*aws_eks.Issue{
+		{Code: aws.String(aws_eks.NodegroupIssueCodeAsgInstanceLaunchFailures), Message: aws.String("instance launch failed"), ResourceIds: aws.StringSlice([]string{"asg-1"})},
+	}}
+	tt := []struct {
+		name     string
+		statuses []string
+		health   *aws_eks.NodegroupHealth
+		err      error
+		desired  string
+
+		expCalls  int
+		expStatus string
+		// expIssue is the expected health issue code of "NodeGroupFailedError",
+		// empty if the poll succeeds.
+		expIssue string
+	}{
+		{
+			name:      "creating to active",
+			statuses:  []string{aws_eks.NodegroupStatusCreating, aws_eks.NodegroupStatusCreating, aws_eks.NodegroupStatusActive},
+			desired:   aws_eks.NodegroupStatusActive,
+			expCalls:  3,
+			expStatus: aws_eks.NodegroupStatusActive,
+		},
+		{
+			name:      "degraded with health issues",
+			statuses:  []string{aws_eks.NodegroupStatusCreating, aws_eks.NodegroupStatusDegraded},
+			health:    launchFailures,
+			desired:   aws_eks.NodegroupStatusActive,
+			expCalls:  2,
+			expStatus: aws_eks.NodegroupStatusDegraded,
+			expIssue:  aws_eks.NodegroupIssueCodeAsgInstanceLaunchFailures,
+		},
+		{
+			name:      "create failed with health issues",
+			statuses:  []string{aws_eks.NodegroupStatusCreateFailed},
+			health:    launchFailures,
+			desired:   aws_eks.NodegroupStatusActive,
+			expCalls:  1,
+			expStatus: aws_eks.NodegroupStatusCreateFailed,
+			expIssue:  aws_eks.NodegroupIssueCodeAsgInstanceLaunchFailures,
+		},
+		{
+			name:     "deleted as desired",
+			err:      awserr.New("ResourceNotFoundException", "No node group found for name: my-mng.", nil),
+			desired:  ManagedNodeGroupStatusDELETEDORNOTEXIST,
+			expCalls: 1,
+		},
+	}
+	for _, tv := range tt {
+		t.Run(tv.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+
+			api := &fakeEKSAPI{statuses: tv.statuses, health: tv.health, err: tv.err}
+			var last ManagedNodeGroupStatus
+			for sv := range Poll(ctx, make(chan struct{}), zap.NewExample(), ioutil.Discard, api, "my-cluster", "my-mng", tv.desired, 0, time.Millisecond) {
+				last = sv
+			}
+
+			if tv.expIssue == "" && last.Error != nil {
+				t.Fatalf("unexpected error %v", last.Error)
+			}
+			if tv.expIssue != "" {
+				var failErr *NodeGroupFailedError
+				if !errors.As(last.Error, &failErr) {
+					t.Fatalf("expected NodeGroupFailedError, got %v", last.Error)
+				}
+				if !errors.Is(last.Error, ErrNodeGroupFailed) {
+					t.Fatalf("expected ErrNodeGroupFailed, got %v", last.Error)
+				}
+				if failErr.NodeGroupName != "my-mng" || failErr.Status != tv.expStatus || !failErr.HasIssue(tv.expIssue) {
+					t.Fatalf("unexpected NodeGroupFailedError %+v", failErr)
+				}
+			}
+			status := ""
+			if last.NodeGroup != nil {
+				status = aws.StringValue(last.NodeGroup.Status)
+			}
+			if status != tv.expStatus {
+				t.Fatalf("expected last status %q, got %q", tv.expStatus, status)
+			}
+			if api.calls != tv.expCalls {
+				t.Fatalf("expected DescribeNodegroup calls %d, got %d", tv.expCalls, api.calls)
+			}
+		})
+	}
+}
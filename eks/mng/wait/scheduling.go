@@ -0,0 +1,60 @@
+package wait
+
+import (
+	"fmt"
+
+	"github.com/aws/aws-k8s-tester/eksconfig"
+	"github.com/aws/aws-sdk-go/service/eks"
+	v1 "k8s.io/api/core/v1"
+)
+
+// taintEffects maps the EKS API taint effect to the Kubernetes one.
+var taintEffects = map[string]v1.TaintEffect{
+	eks.TaintEffectNoSchedule:       v1.TaintEffectNoSchedule,
+	eks.TaintEffectNoExecute:        v1.TaintEffectNoExecute,
+	eks.TaintEffectPreferNoSchedule: v1.TaintEffectPreferNoSchedule,
+}
+
+// checkNodeScheduling returns an error if the node is missing
+// the labels or taints configured for the managed node group,
+// or does not report the custom AMI in its labels.
+func checkNodeScheduling(cur eksconfig.MNG, node v1.Node) error {
+	labels := node.GetLabels()
+	for k, v := range cur.Labels {
+		if lv, ok := labels[k]; !ok || lv != v {
+			return fmt.Errorf("node %q in MNG %q missing label %s=%s (got %q)", node.GetName(), cur.Name, k, v, lv)
+		}
+	}
+	if cur.IsCustomAMI() {
+		if lv := labels[eksconfig.NodeLabelNodegroupImage]; lv != cur.LaunchTemplate.ResolvedImageID {
+			return fmt.Errorf("node %q in MNG %q unexpected label %s=%q (expected custom AMI %q)", node.GetName(), cur.Name, eksconfig.NodeLabelNodegroupImage, lv, cur.LaunchTemplate.ResolvedImageID)
+		}
+	}
+	for _, tv := range cur.Taints {
+		found := false
+		for _, nt := range node.Spec.Taints {
+			if nt.Key == tv.Key && nt.Value == tv.Value && nt.Effect == taintEffects[tv.Effect] {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("node %q in MNG %q missing taint %s=%s:%s", node.GetName(), cur.Name, tv.Key, tv.Value, taintEffects[tv.Effect])
+		}
+	}
+	return nil
+}
+
+// checkInstanceImages returns an error if any instance of the managed
+// node group with the custom AMI is launched with a different AMI.
+func checkInstanceImages(cur eksconfig.MNG) error {
+	if !cur.IsCustomAMI() {
+		return nil
+	}
+	for id, inst := range cur.Instances {
+		if inst.ImageID != cur.LaunchTemplate.ResolvedImageID {
+			return fmt.Errorf("instance %q in MNG %q launched with AMI %q (expected custom AMI %q)", id, cur.Name, inst.ImageID, cur.LaunchTemplate.ResolvedImageID)
+		}
+	}
+	return nil
+}
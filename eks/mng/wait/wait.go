@@ -138,6 +138,9 @@ func (ts *tester) waitForNodes(mngName string, retriesLeft int) error {
 		ivv.RemoteAccessUserName = cur.RemoteAccessUserName
 		cur.Instances[id] = ivv
 	}
+	if err = checkInstanceImages(cur); err != nil {
+		return err
+	}
 	for _, inst := range cur.Instances {
 		ts.cfg.EKSConfig.Status.PrivateDNSToNodeInfo[inst.PrivateDNSName] = eksconfig.NodeInfo{
 			NodeGroupName: cur.Name,
@@ -249,6 +252,10 @@ func (ts *tester) waitForNodes(mngName string, retriesLeft int) error {
 					zap.String("status-type", fmt.Sprint(cond.Type)),
 					zap.String("status", fmt.Sprint(cond.Status)),
 				)
+				// labels and taints are registered by kubelet before ready
+				if err := checkNodeScheduling(cur, node); err != nil {
+					return err
+				}
 				readies++
 				break
 			}
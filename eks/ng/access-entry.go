@@ -0,0 +1,33 @@
+package ng
+
+import (
+	"errors"
+
+	access_entries "github.com/aws/aws-k8s-tester/eks/access-entries"
+)
+
+// createAccessEntry registers the node group instance role with
+// an EKS access entry, instead of the "aws-auth" ConfigMap.
+// ref. https://docs.aws.amazon.com/eks/latest/userguide/access-entries.html
+func (ts *tester) createAccessEntry() error {
+	if ts.cfg.EKSConfig.AddOnNodeGroups.Role.ARN == "" {
+		return errors.New("empty AddOnNodeGroups.Role.ARN")
+	}
+	if ts.cfg.EKSAPI == nil {
+		return errors.New("empty EKSAPI")
+	}
+
+	// EC2 Windows access entries also grant the Linux node permissions
+	entryType := access_entries.EntryTypeEC2Linux
+	if ts.hasWindowsNode() {
+		entryType = access_entries.EntryTypeEC2Windows
+	}
+	if err := access_entries.CreateEntry(ts.cfg.Logger, ts.cfg.EKSAPI, ts.cfg.EKSConfig.Name, access_entries.Entry{
+		PrincipalARN: ts.cfg.EKSConfig.AddOnNodeGroups.Role.ARN,
+		Type:         entryType,
+	}); err != nil {
+		return err
+	}
+	ts.cfg.EKSConfig.Sync()
+	return nil
+}
@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"github.com/aws/aws-k8s-tester/ec2config"
+	aws_ec2 "github.com/aws/aws-k8s-tester/pkg/aws/ec2"
 	"github.com/aws/aws-k8s-tester/pkg/timeutil"
 	aws_v2 "github.com/aws/aws-sdk-go-v2/aws"
 	aws_asg_v2 "github.com/aws/aws-sdk-go-v2/service/autoscaling"
@@ -116,12 +117,12 @@ func (ts *tester) _createASGs() (tss tupleTimes, err error) {
 				TagSpecifications: []aws_ec2_v2_types.TagSpecification{
 					{
 						ResourceType: aws_ec2_v2_types.ResourceTypeLaunchTemplate,
-						Tags: []aws_ec2_v2_types.Tag{
+						Tags: aws_ec2.AppendTags([]aws_ec2_v2_types.Tag{
 							{
 								Key:   aws_v2.String("Name"),
 								Value: aws_v2.String(fmt.Sprintf("%s-instance-launch-template", cur.Name)),
 							},
-						},
+						}, ts.cfg.EKSConfig.Tags),
 					},
 				},
 			},
@@ -147,14 +148,14 @@ func (ts *tester) _createASGs() (tss tupleTimes, err error) {
 			AutoScalingGroupName:   aws_v2.String(asgName),
 			MaxSize:                aws_v2.Int32(cur.ASGMaxSize),
 			MinSize:                aws_v2.Int32(cur.ASGMinSize),
-			VPCZoneIdentifier:      aws_v2.String(strings.Join(ts.cfg.EKSConfig.VPC.PublicSubnetIDs, ",")),
+			VPCZoneIdentifier:      aws_v2.String(strings.Join(ts.cfg.EKSConfig.NodeGroupSubnetIDs(), ",")),
 			HealthCheckGracePeriod: aws_v2.Int32(300),
 			HealthCheckType:        aws_v2.String("EC2"),
 			LaunchTemplate: &aws_asg_v2_types.LaunchTemplateSpecification{
 				LaunchTemplateName: aws_v2.String(cur.LaunchTemplateName),
 				Version:            aws_v2.String("$Latest"),
 			},
-			Tags: []aws_asg_v2_types.Tag{
+			Tags: aws_ec2.AppendASGTags([]aws_asg_v2_types.Tag{
 				{
 					Key:               aws_v2.String("Name"),
 					Value:             aws_v2.String(cur.Name),
@@ -175,7 +176,7 @@ func (ts *tester) _createASGs() (tss tupleTimes, err error) {
 					Value:             aws_v2.String("true"),
 					PropagateAtLaunch: aws_v2.Bool(true),
 				},
-			},
+			}, ts.cfg.EKSConfig.Tags),
 		}
 		if cur.ASGDesiredCapacity > 0 {
 			asgInput.DesiredCapacity = aws_v2.Int32(cur.ASGDesiredCapacity)
@@ -384,7 +385,9 @@ func (ts *tester) generateUserData(asgName string, amiType string, kubeletExtraA
 			ts.cfg.EKSConfig.Status.ClusterAPIServerEndpoint,
 			fmt.Sprintf(`"--node-labels=NodeType=regular,AMIType=%s,NGType=custom,NGName=%s %s"`, amiType, asgName, kubeletExtraArgs))
 
-	case ec2config.AMITypeBottleRocketCPU:
+	case ec2config.AMITypeBottleRocketCPU, ec2config.AMITypeBottleRocketARM64:
+		// admin container is required to SSH and fetch logs
+		// ref. https://github.com/bottlerocket-os/bottlerocket#admin-container
 		d = fmt.Sprintf(`[settings.kubernetes]
 cluster-name = "%s"
 cluster-certificate = "%s"
@@ -393,11 +396,13 @@ api-server = "%s"
 NodeType = "regular"
 AMIType = "%s"
 NGType = "custom"
-NGName = "%s"`,
+NGName = "%s"
+[settings.host-containers.admin]
+enabled = true`,
 			ts.cfg.EKSConfig.Name,
 			ts.cfg.EKSConfig.Status.ClusterCA,
 			ts.cfg.EKSConfig.Status.ClusterAPIServerEndpoint,
-			ec2config.AMITypeBottleRocketCPU,
+			amiType,
 			asgName,
 		)
 
@@ -425,6 +430,20 @@ set -xeu
 				dnsClusterIP,
 			)
 		}
+		if ts.cfg.EKSConfig.IsEnabledOutpost() {
+			// nodes of local clusters authenticate with the cluster ID
+			// ref. https://docs.aws.amazon.com/eks/latest/userguide/eks-outposts-self-managed-nodes.html
+			ts.cfg.Logger.Info("adding extra bootstrap arguments --enable-local-outpost and --cluster-id to user data",
+				zap.String("cluster-id", ts.cfg.EKSConfig.Outpost.ClusterID),
+			)
+			if ts.cfg.EKSConfig.ResolverURL == "" {
+				d += fmt.Sprintf(` --b64-cluster-ca %s --apiserver-endpoint %s`,
+					ts.cfg.EKSConfig.Status.ClusterCA,
+					ts.cfg.EKSConfig.Status.ClusterAPIServerEndpoint,
+				)
+			}
+			d += fmt.Sprintf(` --enable-local-outpost true --cluster-id %s`, ts.cfg.EKSConfig.Outpost.ClusterID)
+		}
 		// https://aws.amazon.com/blogs/opensource/improvements-eks-worker-node-provisioning/
 		d += fmt.Sprintf(` --kubelet-extra-args '--node-labels=NodeType=regular,AMIType=%s,NGType=custom,NGName=%s`, amiType, asgName)
 		if kubeletExtraArgs != "" {
@@ -9,7 +9,6 @@ import (
 	"text/template"
 	"time"
 
-	"github.com/aws/aws-k8s-tester/ec2config"
 	"github.com/aws/aws-k8s-tester/pkg/fileutil"
 	"go.uber.org/zap"
 	"k8s.io/utils/exec"
@@ -20,8 +19,10 @@ func (ts *tester) createConfigMap() error {
 		return errors.New("empty AddOnNodeGroups.Role.ARN")
 	}
 
-	ts.cfg.Logger.Info("writing ConfigMap", zap.String("instance-role-arn", ts.cfg.EKSConfig.AddOnNodeGroups.Role.ARN))
-	body, p, err := ts.writeConfigMapAuth(ts.cfg.EKSConfig.AddOnNodeGroups.Role.ARN)
+	// aws-auth ConfigMap does not support the role path (see "IAMPath")
+	roleARN := roleARNWithoutPath(ts.cfg.EKSConfig.AddOnNodeGroups.Role.ARN)
+	ts.cfg.Logger.Info("writing ConfigMap", zap.String("instance-role-arn", roleARN))
+	body, p, err := ts.writeConfigMapAuth(roleARN)
 	if err != nil {
 		return err
 	}
@@ -116,12 +117,21 @@ func (ts *tester) writeConfigMapAuth(instanceRoleARN string) (body string, fpath
 	return body, fpath, err
 }
 
+// roleARNWithoutPath returns the role ARN without the path
+// (e.g. "arn:aws:iam::123:role/my-path/my-role" to "arn:aws:iam::123:role/my-role").
+func roleARNWithoutPath(roleARN string) string {
+	idx := strings.Index(roleARN, ":role/")
+	if idx == -1 {
+		return roleARN
+	}
+	prefix, name := roleARN[:idx+len(":role/")], roleARN[idx+len(":role/"):]
+	if ss := strings.Split(name, "/"); len(ss) > 1 {
+		name = ss[len(ss)-1]
+	}
+	return prefix + name
+}
+
 // hasWindowsNode returns true if any Windows AMI is present in the the ASG to be created
 func (ts *tester) hasWindowsNode() bool {
-	for _, cur := range ts.cfg.EKSConfig.AddOnNodeGroups.ASGs {
-		if cur.AMIType == ec2config.AMITypeWindowsServerCore2019X8664 {
-			return true
-		}
-	}
-	return false
+	return ts.cfg.EKSConfig.HasWindowsNodeGroup()
 }
@@ -0,0 +1,21 @@
+package ng
+
+import "testing"
+
+func Test_roleARNWithoutPath(t *testing.T) {
+	tt := []struct {
+		roleARN  string
+		expected string
+	}{
+		{"arn:aws:iam::123:role/my-role", "arn:aws:iam::123:role/my-role"},
+		{"arn:aws:iam::123:role/my-path/my-role", "arn:aws:iam::123:role/my-role"},
+		{"arn:aws:iam::123:role/a/b/c/my-role", "arn:aws:iam::123:role/my-role"},
+		{"arn:aws-cn:iam::123:role/my-path/my-role", "arn:aws-cn:iam::123:role/my-role"},
+		{"my-role", "my-role"},
+	}
+	for i, tv := range tt {
+		if got := roleARNWithoutPath(tv.roleARN); got != tv.expected {
+			t.Fatalf("#%d: expected %q, got %q", i, tv.expected, got)
+		}
+	}
+}
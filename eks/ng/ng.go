@@ -18,6 +18,8 @@ import (
 	aws_ec2_v2 "github.com/aws/aws-sdk-go-v2/service/ec2"
 	aws_iam_v2 "github.com/aws/aws-sdk-go-v2/service/iam"
 	aws_ssm_v2 "github.com/aws/aws-sdk-go-v2/service/ssm"
+	aws_eks "github.com/aws/aws-sdk-go/service/eks"
+	"github.com/aws/aws-sdk-go/service/eks/eksiface"
 	"go.uber.org/zap"
 )
 
@@ -33,6 +35,9 @@ type Config struct {
 	EC2APIV2 *aws_ec2_v2.Client
 	SSMAPIV2 *aws_ssm_v2.Client
 	ASGAPIV2 *aws_asg_v2.Client
+	// EKSAPI is used to register the node role with access entries.
+	// ref. "eksconfig.Config.AuthenticationMode"
+	EKSAPI eksiface.EKSAPI
 }
 
 // Tester implements EKS "Node Group" for "kubetest2" Deployer.
@@ -102,8 +107,11 @@ func (ts *tester) Create() (err error) {
 		ts.cfg.Logger.Info("node group is already created; skipping creation")
 		return nil
 	}
-	if len(ts.cfg.EKSConfig.VPC.PublicSubnetIDs) == 0 {
-		return errors.New("empty EKSConfig.VPC.PublicSubnetIDs")
+	if len(ts.cfg.EKSConfig.NodeGroupSubnetIDs()) == 0 {
+		return errors.New("empty EKSConfig.NodeGroupSubnetIDs")
+	}
+	if ts.cfg.EKSConfig.IsEnabledOutpost() && ts.cfg.EKSConfig.Outpost.ClusterID == "" {
+		return errors.New("empty EKSConfig.Outpost.ClusterID")
 	}
 
 	ts.cfg.Logger.Info("starting tester.Create", zap.String("tester", pkgName))
@@ -123,8 +131,19 @@ func (ts *tester) Create() (err error) {
 	if err = ts.createRole(); err != nil {
 		return err
 	}
-	if err = ts.createConfigMap(); err != nil {
-		return err
+	if ts.cfg.EKSConfig.AuthenticationMode == aws_eks.AuthenticationModeConfigMap {
+		if err = ts.createConfigMap(); err != nil {
+			return err
+		}
+	} else {
+		if err = ts.createAccessEntry(); err != nil {
+			return err
+		}
+	}
+	if ts.hasWindowsNode() {
+		if err = ts.enableWindowsIPAM(); err != nil {
+			return err
+		}
 	}
 	if err = ts.createASGs(); err != nil {
 		return err
@@ -99,14 +99,15 @@ func (ts *tester) deleteRole() error {
 
 func (ts *tester) _createRole() error {
 	ts.cfg.Logger.Info("creating role", zap.String("name", ts.cfg.EKSConfig.AddOnNodeGroups.Role.Name))
-	out, err := ts.cfg.IAMAPIV2.CreateRole(
-		context.Background(),
-		&aws_iam_v2.CreateRoleInput{
-			RoleName:                 aws_v2.String(ts.cfg.EKSConfig.AddOnNodeGroups.Role.Name),
-			Path:                     aws_v2.String("/"),
-			AssumeRolePolicyDocument: aws_v2.String(createAssumeRolePolicyDocument(ts.cfg.EKSConfig.AddOnNodeGroups.Role.ServicePrincipals)),
-		},
-	)
+	input := &aws_iam_v2.CreateRoleInput{
+		RoleName:                 aws_v2.String(ts.cfg.EKSConfig.AddOnNodeGroups.Role.Name),
+		Path:                     aws_v2.String(ts.cfg.EKSConfig.IAMPath),
+		AssumeRolePolicyDocument: aws_v2.String(createAssumeRolePolicyDocument(ts.cfg.EKSConfig.AddOnNodeGroups.Role.ServicePrincipals)),
+	}
+	if ts.cfg.EKSConfig.IAMPermissionsBoundaryARN != "" {
+		input.PermissionsBoundary = aws_v2.String(ts.cfg.EKSConfig.IAMPermissionsBoundaryARN)
+	}
+	out, err := ts.cfg.IAMAPIV2.CreateRole(context.Background(), input)
 	if err != nil {
 		return err
 	}
@@ -331,6 +332,9 @@ func createStatementEntriesForRolePolicyDocument(partition string, bucketName st
 			Effect:   "Allow",
 			Resource: "*",
 			Action: []string{
+				// required for VPC CNI in IPv6 clusters
+				// ref. https://docs.aws.amazon.com/eks/latest/userguide/cni-iam-role.html#cni-iam-role-create-ipv6-policy
+				"ec2:AssignIpv6Addresses",
 				"ec2:AttachVolume",
 				"ec2:AuthorizeSecurityGroupIngress",
 				"ec2:CreateSecurityGroup",
@@ -344,6 +348,7 @@ func createStatementEntriesForRolePolicyDocument(partition string, bucketName st
 				"ec2:DescribeAccountAttributes",
 				"ec2:DescribeAddresses",
 				"ec2:DescribeInstanceStatus",
+				"ec2:DescribeInstanceTypes",
 				"ec2:DescribeInstances",
 				"ec2:DescribeInternetGateways",
 				"ec2:DescribeNetworkInterfaces",
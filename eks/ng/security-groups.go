@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"strings"
 
+	aws_ec2 "github.com/aws/aws-k8s-tester/pkg/aws/ec2"
 	aws_v2 "github.com/aws/aws-sdk-go-v2/aws"
 	aws_ec2_v2 "github.com/aws/aws-sdk-go-v2/service/ec2"
 	aws_ec2_v2_types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
@@ -33,12 +34,12 @@ func (ts *tester) createSecurityGroups() error {
 			TagSpecifications: []aws_ec2_v2_types.TagSpecification{
 				{
 					ResourceType: aws_ec2_v2_types.ResourceTypeSecurityGroup,
-					Tags: []aws_ec2_v2_types.Tag{
+					Tags: aws_ec2.AppendTags([]aws_ec2_v2_types.Tag{
 						{
 							Key:   aws_v2.String(fmt.Sprintf("kubernetes.io/cluster/%s", ts.cfg.EKSConfig.Name)),
 							Value: aws_v2.String("owned"),
 						},
-					},
+					}, ts.cfg.EKSConfig.Tags),
 				},
 			},
 		},
@@ -0,0 +1,89 @@
+package ng
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+	v1 "k8s.io/api/core/v1"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	cniConfigMapName      = "amazon-vpc-cni"
+	cniConfigMapNamespace = "kube-system"
+	windowsIPAMKey        = "enable-windows-ipam"
+)
+
+// enableWindowsIPAM enables the Windows IP address management in the
+// VPC CNI, which is required to assign Pod IPs on Windows nodes.
+// It must be done before Windows nodes join the cluster.
+// ref. https://docs.aws.amazon.com/eks/latest/userguide/windows-support.html
+func (ts *tester) enableWindowsIPAM() (err error) {
+	ts.cfg.Logger.Info("enabling Windows IPAM in VPC CNI", zap.String("configmap", cniConfigMapName))
+
+	waitDur := 3 * time.Minute
+	retryStart := time.Now()
+	for time.Since(retryStart) < waitDur {
+		select {
+		case <-ts.cfg.Stopc:
+			return errors.New("enable Windows IPAM aborted")
+		case <-time.After(5 * time.Second):
+		}
+
+		if err = ts.applyWindowsIPAM(); err == nil {
+			break
+		}
+		ts.cfg.Logger.Warn("enable Windows IPAM failed; retrying", zap.Error(err))
+	}
+	if err != nil {
+		return fmt.Errorf("failed to enable Windows IPAM (%v)", err)
+	}
+
+	ts.cfg.Logger.Info("enabled Windows IPAM in VPC CNI")
+	ts.cfg.EKSConfig.Sync()
+	return nil
+}
+
+func (ts *tester) applyWindowsIPAM() error {
+	cli := ts.cfg.K8SClient.KubernetesClientSet().CoreV1().ConfigMaps(cniConfigMapNamespace)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	cm, err := cli.Get(ctx, cniConfigMapName, metav1.GetOptions{})
+	cancel()
+	if apierrs.IsNotFound(err) {
+		ctx, cancel = context.WithTimeout(context.Background(), 15*time.Second)
+		_, err = cli.Create(ctx, &v1.ConfigMap{
+			TypeMeta: metav1.TypeMeta{
+				APIVersion: "v1",
+				Kind:       "ConfigMap",
+			},
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      cniConfigMapName,
+				Namespace: cniConfigMapNamespace,
+			},
+			Data: map[string]string{windowsIPAMKey: "true"},
+		}, metav1.CreateOptions{})
+		cancel()
+		return err
+	}
+	if err != nil {
+		return err
+	}
+	if cm.Data[windowsIPAMKey] == "true" {
+		ts.cfg.Logger.Info("Windows IPAM already enabled")
+		return nil
+	}
+
+	if cm.Data == nil {
+		cm.Data = make(map[string]string)
+	}
+	cm.Data[windowsIPAMKey] = "true"
+	ctx, cancel = context.WithTimeout(context.Background(), 15*time.Second)
+	_, err = cli.Update(ctx, cm, metav1.UpdateOptions{})
+	cancel()
+	return err
+}
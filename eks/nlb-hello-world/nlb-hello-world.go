@@ -56,8 +56,13 @@ type tester struct {
 const (
 	nlbHelloWorldDeploymentName = "hello-world-deployment"
 	nlbHelloWorldAppName        = "hello-world"
-	nlbHelloWorldAppImageName   = "dockercloud/hello-world"
-	nlbHelloWorldServiceName    = "hello-world-service"
+	// "busybox" is multi-arch, so that the Deployment runs on both amd64 and arm64 nodes
+	// ("dockercloud/hello-world" is only available for amd64)
+	nlbHelloWorldAppImageName = "busybox"
+	nlbHelloWorldServiceName  = "hello-world-service"
+
+	// nlbHelloWorldAppCommand serves the hello-world HTML page on port 80.
+	nlbHelloWorldAppCommand = "mkdir -p /www && echo '<h1>Hello world!</h1>' > /www/index.html && exec httpd -f -p 80 -h /www"
 )
 
 func (ts *tester) Create() error {
@@ -219,6 +224,11 @@ func (ts *tester) createDeployment() error {
 									Name:            nlbHelloWorldAppName,
 									Image:           nlbHelloWorldAppImageName,
 									ImagePullPolicy: v1.PullAlways,
+									Command: []string{
+										"/bin/sh",
+										"-c",
+										nlbHelloWorldAppCommand,
+									},
 									Ports: []v1.ContainerPort{
 										{
 											Protocol:      v1.ProtocolTCP,
@@ -0,0 +1,342 @@
+// Package oidc implements the IAM OpenID Connect provider of the cluster,
+// and the IAM roles for service accounts (IRSA) trusted by the provider.
+// ref. https://docs.aws.amazon.com/eks/latest/userguide/iam-roles-for-service-accounts.html
+package oidc
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-k8s-tester/eksconfig"
+	"github.com/aws/aws-k8s-tester/pkg/user"
+	"github.com/aws/aws-k8s-tester/version"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/arn"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/iam"
+	"github.com/aws/aws-sdk-go/service/iam/iamiface"
+	"go.uber.org/zap"
+)
+
+// Config defines the IAM OpenID Connect provider configuration.
+type Config struct {
+	Logger    *zap.Logger
+	EKSConfig *eksconfig.Config
+	IAMAPI    iamiface.IAMAPI
+}
+
+var pkgName = reflect.TypeOf(Provider{}).PkgPath()
+
+// Provider manages the IAM OpenID Connect provider of the cluster
+// and the IRSA roles of the add-ons.
+type Provider struct {
+	cfg Config
+}
+
+// New creates a new IAM OpenID Connect provider tester.
+func New(cfg Config) *Provider {
+	cfg.Logger.Info("creating tester", zap.String("tester", pkgName))
+	return &Provider{cfg: cfg}
+}
+
+// Name returns the name of the tester.
+func (p *Provider) Name() string { return pkgName }
+
+// Create creates the IAM OpenID Connect provider of the cluster issuer,
+// if it does not exist yet. Must be run after the cluster is created,
+// with "Status.ClusterOIDCIssuerURL" and "Status.ClusterOIDCIssuerARN".
+func (p *Provider) Create() error {
+	if !p.cfg.EKSConfig.IsEnabledOIDCProvider() {
+		p.cfg.Logger.Info("skipping tester.Create", zap.String("tester", pkgName))
+		return nil
+	}
+	if p.cfg.EKSConfig.Status.ClusterOIDCIssuerURL == "" {
+		return errors.New("EKSConfig.Status.ClusterOIDCIssuerURL is empty")
+	}
+
+	p.cfg.Logger.Info("checking existing IAM Open ID Connect provider",
+		zap.String("provider-arn", p.cfg.EKSConfig.Status.ClusterOIDCIssuerARN),
+	)
+	_, err := p.cfg.IAMAPI.GetOpenIDConnectProvider(&iam.GetOpenIDConnectProviderInput{
+		OpenIDConnectProviderArn: aws.String(p.cfg.EKSConfig.Status.ClusterOIDCIssuerARN),
+	})
+	if err == nil {
+		p.cfg.Logger.Info("IAM Open ID Connect provider already exists",
+			zap.String("provider-arn", p.cfg.EKSConfig.Status.ClusterOIDCIssuerARN),
+		)
+		return nil
+	}
+	if aerr, ok := err.(awserr.Error); !ok || aerr.Code() != iam.ErrCodeNoSuchEntityException {
+		return fmt.Errorf("failed to get IAM Open ID Connect provider (%v)", err)
+	}
+
+	p.cfg.Logger.Info("creating IAM Open ID Connect provider")
+	out, err := p.cfg.IAMAPI.CreateOpenIDConnectProvider(&iam.CreateOpenIDConnectProviderInput{ // no name, keyed to URL
+		Url:            aws.String(p.cfg.EKSConfig.Status.ClusterOIDCIssuerURL),
+		ThumbprintList: aws.StringSlice([]string{p.cfg.EKSConfig.Status.ClusterOIDCIssuerCAThumbprint}),
+		ClientIDList:   aws.StringSlice([]string{"sts.amazonaws.com"}),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create IAM Open ID Connect provider (%v)", err)
+	}
+	p.cfg.EKSConfig.Status.ClusterOIDCIssuerARN = aws.StringValue(out.OpenIDConnectProviderArn)
+	p.cfg.EKSConfig.OIDCProvider.Created = true
+	p.cfg.EKSConfig.Sync()
+	p.cfg.Logger.Info("created IAM Open ID Connect provider", zap.String("provider-arn", p.cfg.EKSConfig.Status.ClusterOIDCIssuerARN))
+	return nil
+}
+
+// Delete deletes the IRSA roles, and then the IAM OpenID Connect provider
+// if created by the tester.
+func (p *Provider) Delete() error {
+	if !p.cfg.EKSConfig.IsEnabledOIDCProvider() {
+		p.cfg.Logger.Info("skipping tester.Delete", zap.String("tester", pkgName))
+		return nil
+	}
+
+	var errs []string
+	keys := make([]string, 0, len(p.cfg.EKSConfig.OIDCProvider.ServiceAccountRoles))
+	for k := range p.cfg.EKSConfig.OIDCProvider.ServiceAccountRoles {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		ss := strings.Split(k, "/")
+		if err := p.DeleteServiceAccountRole(ss[0], ss[1]); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, ", "))
+	}
+
+	if !p.cfg.EKSConfig.OIDCProvider.Created {
+		p.cfg.Logger.Info("IAM Open ID Connect provider not created by tester; skipping delete",
+			zap.String("provider-arn", p.cfg.EKSConfig.Status.ClusterOIDCIssuerARN),
+		)
+		return nil
+	}
+	p.cfg.Logger.Info("deleting IAM Open ID Connect provider",
+		zap.String("provider-arn", p.cfg.EKSConfig.Status.ClusterOIDCIssuerARN),
+	)
+	_, err := p.cfg.IAMAPI.DeleteOpenIDConnectProvider(&iam.DeleteOpenIDConnectProviderInput{
+		OpenIDConnectProviderArn: aws.String(p.cfg.EKSConfig.Status.ClusterOIDCIssuerARN),
+	})
+	if err != nil {
+		if aerr, ok := err.(awserr.Error); !ok || aerr.Code() != iam.ErrCodeNoSuchEntityException {
+			return fmt.Errorf("failed to delete IAM Open ID Connect provider (%v)", err)
+		}
+		p.cfg.Logger.Warn("IAM Open ID Connect provider already deleted", zap.Error(err))
+	} else {
+		p.cfg.Logger.Info("deleted IAM Open ID Connect provider",
+			zap.String("provider-arn", p.cfg.EKSConfig.Status.ClusterOIDCIssuerARN),
+		)
+	}
+	p.cfg.EKSConfig.OIDCProvider.Created = false
+	p.cfg.EKSConfig.Sync()
+	return nil
+}
+
+// NewServiceAccountRole creates the IAM role that can only be assumed by
+// the service account via "sts:AssumeRoleWithWebIdentity", and returns the
+// role ARN to annotate the service account with ("eks.amazonaws.com/role-arn").
+// Each policy is either a managed policy ARN (e.g. "arn:aws:iam::aws:policy/ReadOnlyAccess")
+// or an inline policy JSON document. The role is recorded in
+// "OIDCProvider.ServiceAccountRoles", and reused if it already exists.
+// The role is deleted with the provider, unless deleted by the add-on
+// with "DeleteServiceAccountRole".
+func (p *Provider) NewServiceAccountRole(namespace string, sa string, policies []string) (string, error) {
+	if !p.cfg.EKSConfig.IsEnabledOIDCProvider() {
+		return "", errors.New("OIDCProvider not enabled")
+	}
+	if p.cfg.EKSConfig.Status.ClusterOIDCIssuerARN == "" || p.cfg.EKSConfig.Status.ClusterOIDCIssuerHostPath == "" {
+		return "", errors.New("empty EKSConfig.Status.ClusterOIDCIssuerARN or ClusterOIDCIssuerHostPath")
+	}
+	key := eksconfig.ServiceAccountRoleKey(namespace, sa)
+	if roleARN, ok := p.cfg.EKSConfig.OIDCProvider.ServiceAccountRoles[key]; ok {
+		p.cfg.Logger.Info("IRSA role already created", zap.String("service-account", key), zap.String("role-arn", roleARN))
+		return roleARN, nil
+	}
+
+	doc, err := assumeRolePolicyDocument(p.cfg.EKSConfig.Status.ClusterOIDCIssuerARN, p.cfg.EKSConfig.Status.ClusterOIDCIssuerHostPath, namespace, sa)
+	if err != nil {
+		return "", err
+	}
+	roleName := serviceAccountRoleName(p.cfg.EKSConfig.Name, namespace, sa)
+	tags := make([]*iam.Tag, 0)
+	for k, v := range p.cfg.EKSConfig.MergeTags(map[string]string{
+		"Kind":                   "aws-k8s-tester",
+		"Name":                   p.cfg.EKSConfig.Name,
+		"aws-k8s-tester-version": version.ReleaseVersion,
+		"User":                   user.Get(),
+		"ServiceAccount":         key,
+	}) {
+		tags = append(tags, &iam.Tag{Key: aws.String(k), Value: aws.String(v)})
+	}
+	sort.Slice(tags, func(i, j int) bool { return aws.StringValue(tags[i].Key) < aws.StringValue(tags[j].Key) })
+
+	p.cfg.Logger.Info("creating IRSA role",
+		zap.String("service-account", key),
+		zap.String("role-name", roleName),
+	)
+	input := &iam.CreateRoleInput{
+		RoleName:                 aws.String(roleName),
+		Path:                     aws.String(p.cfg.EKSConfig.IAMPath),
+		AssumeRolePolicyDocument: aws.String(doc),
+		Tags:                     tags,
+	}
+	if p.cfg.EKSConfig.IAMPermissionsBoundaryARN != "" {
+		input.PermissionsBoundary = aws.String(p.cfg.EKSConfig.IAMPermissionsBoundaryARN)
+	}
+	out, err := p.cfg.IAMAPI.CreateRole(input)
+	if err != nil {
+		return "", fmt.Errorf("failed to create IRSA role %q for %q (%v)", roleName, key, err)
+	}
+	roleARN := aws.StringValue(out.Role.Arn)
+	if p.cfg.EKSConfig.OIDCProvider.ServiceAccountRoles == nil {
+		p.cfg.EKSConfig.OIDCProvider.ServiceAccountRoles = make(map[string]string)
+	}
+	p.cfg.EKSConfig.OIDCProvider.ServiceAccountRoles[key] = roleARN
+	p.cfg.EKSConfig.Sync()
+
+	for i, policy := range policies {
+		if strings.HasPrefix(policy, "arn:") {
+			_, err = p.cfg.IAMAPI.AttachRolePolicy(&iam.AttachRolePolicyInput{
+				RoleName:  aws.String(roleName),
+				PolicyArn: aws.String(policy),
+			})
+			if err != nil {
+				return "", fmt.Errorf("failed to attach policy %q to IRSA role %q (%v)", policy, roleName, err)
+			}
+			continue
+		}
+		policyName := fmt.Sprintf("%s-policy-%d", roleName, i)
+		_, err = p.cfg.IAMAPI.PutRolePolicy(&iam.PutRolePolicyInput{
+			RoleName:       aws.String(roleName),
+			PolicyName:     aws.String(policyName),
+			PolicyDocument: aws.String(policy),
+		})
+		if err != nil {
+			return "", fmt.Errorf("failed to put policy %q to IRSA role %q (%v)", policyName, roleName, err)
+		}
+	}
+	p.cfg.Logger.Info("created IRSA role",
+		zap.String("service-account", key),
+		zap.String("role-arn", roleARN),
+		zap.Int("policies", len(policies)),
+	)
+	return roleARN, nil
+}
+
+// DeleteServiceAccountRole deletes the IRSA role of the service account
+// created by "NewServiceAccountRole", with its policies.
+func (p *Provider) DeleteServiceAccountRole(namespace string, sa string) error {
+	if !p.cfg.EKSConfig.IsEnabledOIDCProvider() {
+		return nil
+	}
+	key := eksconfig.ServiceAccountRoleKey(namespace, sa)
+	roleARN, ok := p.cfg.EKSConfig.OIDCProvider.ServiceAccountRoles[key]
+	if !ok {
+		p.cfg.Logger.Info("IRSA role not found; skipping delete", zap.String("service-account", key))
+		return nil
+	}
+	parsed, err := arn.Parse(roleARN)
+	if err != nil {
+		return fmt.Errorf("invalid IRSA role ARN %q (%v)", roleARN, err)
+	}
+	roleName := parsed.Resource[strings.LastIndex(parsed.Resource, "/")+1:]
+
+	p.cfg.Logger.Info("deleting IRSA role", zap.String("service-account", key), zap.String("role-arn", roleARN))
+	attached, err := p.cfg.IAMAPI.ListAttachedRolePolicies(&iam.ListAttachedRolePoliciesInput{
+		RoleName: aws.String(roleName),
+	})
+	if err != nil {
+		if aerr, ok := err.(awserr.Error); ok && aerr.Code() == iam.ErrCodeNoSuchEntityException {
+			p.cfg.Logger.Warn("IRSA role already deleted", zap.String("role-arn", roleARN))
+			delete(p.cfg.EKSConfig.OIDCProvider.ServiceAccountRoles, key)
+			p.cfg.EKSConfig.Sync()
+			return nil
+		}
+		return fmt.Errorf("failed to list attached policies of IRSA role %q (%v)", roleName, err)
+	}
+	for _, v := range attached.AttachedPolicies {
+		if _, err = p.cfg.IAMAPI.DetachRolePolicy(&iam.DetachRolePolicyInput{
+			RoleName:  aws.String(roleName),
+			PolicyArn: v.PolicyArn,
+		}); err != nil {
+			return fmt.Errorf("failed to detach policy %q from IRSA role %q (%v)", aws.StringValue(v.PolicyArn), roleName, err)
+		}
+	}
+	inline, err := p.cfg.IAMAPI.ListRolePolicies(&iam.ListRolePoliciesInput{
+		RoleName: aws.String(roleName),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to list policies of IRSA role %q (%v)", roleName, err)
+	}
+	for _, v := range inline.PolicyNames {
+		if _, err = p.cfg.IAMAPI.DeleteRolePolicy(&iam.DeleteRolePolicyInput{
+			RoleName:   aws.String(roleName),
+			PolicyName: v,
+		}); err != nil {
+			return fmt.Errorf("failed to delete policy %q from IRSA role %q (%v)", aws.StringValue(v), roleName, err)
+		}
+	}
+	if _, err = p.cfg.IAMAPI.DeleteRole(&iam.DeleteRoleInput{
+		RoleName: aws.String(roleName),
+	}); err != nil {
+		return fmt.Errorf("failed to delete IRSA role %q (%v)", roleName, err)
+	}
+	delete(p.cfg.EKSConfig.OIDCProvider.ServiceAccountRoles, key)
+	p.cfg.EKSConfig.Sync()
+	p.cfg.Logger.Info("deleted IRSA role", zap.String("service-account", key), zap.String("role-arn", roleARN))
+	return nil
+}
+
+// maxRoleNameLen is the maximum length of IAM role names.
+const maxRoleNameLen = 64
+
+// serviceAccountRoleName returns the IRSA role name of the service account,
+// truncated with a hash suffix to fit in the IAM role name limit.
+func serviceAccountRoleName(clusterName string, namespace string, sa string) string {
+	name := fmt.Sprintf("%s-irsa-%s-%s", clusterName, namespace, sa)
+	if len(name) <= maxRoleNameLen {
+		return name
+	}
+	h := sha256.Sum256([]byte(name))
+	suffix := hex.EncodeToString(h[:])[:8]
+	return name[:maxRoleNameLen-len(suffix)-1] + "-" + suffix
+}
+
+// assumeRolePolicyDocument returns the trust policy of the IRSA role,
+// conditioned on the service account subject and the STS audience.
+func assumeRolePolicyDocument(providerARN string, issuerHostPath string, namespace string, sa string) (string, error) {
+	doc := map[string]interface{}{
+		"Version": "2012-10-17",
+		"Statement": []interface{}{
+			map[string]interface{}{
+				"Effect": "Allow",
+				"Principal": map[string]interface{}{
+					"Federated": providerARN,
+				},
+				"Action": "sts:AssumeRoleWithWebIdentity",
+				"Condition": map[string]interface{}{
+					"StringEquals": map[string]string{
+						issuerHostPath + ":sub": "system:serviceaccount:" + namespace + ":" + sa,
+						issuerHostPath + ":aud": "sts.amazonaws.com",
+					},
+				},
+			},
+		},
+	}
+	b, err := json.Marshal(doc)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
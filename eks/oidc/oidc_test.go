@@ -0,0 +1,53 @@
+package oidc
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestServiceAccountRoleName(t *testing.T) {
+	name := serviceAccountRoleName("eks-2020", "irsa", "irsa-service-account")
+	if name != "eks-2020-irsa-irsa-irsa-service-account" {
+		t.Fatalf("unexpected role name %q", name)
+	}
+
+	long := strings.Repeat("a", 50)
+	name1 := serviceAccountRoleName("eks-2020", long, "sa-1")
+	name2 := serviceAccountRoleName("eks-2020", long, "sa-2")
+	if len(name1) != maxRoleNameLen || len(name2) != maxRoleNameLen {
+		t.Fatalf("unexpected role name lengths %d, %d", len(name1), len(name2))
+	}
+	if name1 == name2 {
+		t.Fatalf("expected different role names, got %q", name1)
+	}
+}
+
+func TestAssumeRolePolicyDocument(t *testing.T) {
+	doc, err := assumeRolePolicyDocument(
+		"arn:aws:iam::123:oidc-provider/oidc.eks.us-west-2.amazonaws.com/id/ABC",
+		"oidc.eks.us-west-2.amazonaws.com/id/ABC",
+		"irsa",
+		"irsa-service-account",
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var v map[string]interface{}
+	if err = json.Unmarshal([]byte(doc), &v); err != nil {
+		t.Fatal(err)
+	}
+	st := v["Statement"].([]interface{})[0].(map[string]interface{})
+	if st["Action"] != "sts:AssumeRoleWithWebIdentity" {
+		t.Fatalf("unexpected action %v", st["Action"])
+	}
+	expected := map[string]interface{}{
+		"oidc.eks.us-west-2.amazonaws.com/id/ABC:sub": "system:serviceaccount:irsa:irsa-service-account",
+		"oidc.eks.us-west-2.amazonaws.com/id/ABC:aud": "sts.amazonaws.com",
+	}
+	cond := st["Condition"].(map[string]interface{})["StringEquals"]
+	if !reflect.DeepEqual(cond, expected) {
+		t.Fatalf("unexpected condition %v", cond)
+	}
+}
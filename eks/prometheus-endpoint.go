@@ -0,0 +1,60 @@
+package eks
+
+import (
+	"github.com/aws/aws-k8s-tester/pkg/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// metricsGatherer returns the add-on metrics registered with the
+// default Prometheus registry (e.g. stresser writes/reads), and
+// the EKS API latency of the cluster waiters.
+func (ts *Tester) metricsGatherer() (prometheus.Gatherer, error) {
+	reg := prometheus.NewRegistry()
+	if err := reg.Register(metrics.NewRequestsSummaryCollector(
+		"aws_k8s_tester",
+		"cluster_api",
+		metrics.ScaleMilliseconds,
+		ts.cfg.GetClusterAPILatency,
+	)); err != nil {
+		return nil, err
+	}
+	return prometheus.Gatherers{prometheus.DefaultGatherer, reg}, nil
+}
+
+// startPrometheusEndpoint serves the tester metrics (see "metricsGatherer").
+func (ts *Tester) startPrometheusEndpoint() (*metrics.Server, error) {
+	gatherer, err := ts.metricsGatherer()
+	if err != nil {
+		return nil, err
+	}
+	return metrics.StartServer(
+		ts.lg,
+		ts.cfg.PrometheusEndpoint.ListenAddress,
+		ts.cfg.PrometheusEndpoint.Path,
+		gatherer,
+	)
+}
+
+// startOTLPExporter pushes the tester metrics (see "metricsGatherer")
+// to the OpenTelemetry collector.
+func (ts *Tester) startOTLPExporter() (*metrics.OTLPExporter, error) {
+	gatherer, err := ts.metricsGatherer()
+	if err != nil {
+		return nil, err
+	}
+	e := &metrics.OTLPExporter{
+		Logger:   ts.lg,
+		Endpoint: ts.cfg.OTLPExporter.Endpoint,
+		Headers:  ts.cfg.OTLPExporter.Headers,
+		ResourceAttributes: map[string]string{
+			"service.name":     "aws-k8s-tester",
+			"k8s.cluster.name": ts.cfg.Name,
+		},
+		Interval: ts.cfg.OTLPExporter.Interval,
+		Gatherer: gatherer,
+	}
+	if err = e.Start(); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
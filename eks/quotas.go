@@ -0,0 +1,398 @@
+package eks
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/aws/aws-k8s-tester/eksconfig"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	aws_eks "github.com/aws/aws-sdk-go/service/eks"
+	"github.com/aws/aws-sdk-go/service/servicequotas"
+	"go.uber.org/zap"
+)
+
+// serviceQuota is the service quota to check before creating resources.
+// ref. https://docs.aws.amazon.com/servicequotas/latest/userguide/intro.html
+type serviceQuota struct {
+	serviceCode string
+	quotaCode   string
+	desc        string
+}
+
+var (
+	quotaEIPs             = serviceQuota{serviceCode: "ec2", quotaCode: "L-0263D0A3", desc: "EC2-VPC Elastic IPs"}
+	quotaNATGatewaysPerAZ = serviceQuota{serviceCode: "vpc", quotaCode: "L-FE5A380F", desc: "NAT gateways per Availability Zone"}
+	quotaClusters         = serviceQuota{serviceCode: "eks", quotaCode: "L-1194D53C", desc: "EKS clusters"}
+)
+
+// vCPU-based EC2 instance quotas are per instance family class.
+// ref. https://docs.aws.amazon.com/AWSEC2/latest/UserGuide/ec2-instance-quotas.html
+var (
+	onDemandVCPUQuotas = map[string]serviceQuota{
+		"standard": {serviceCode: "ec2", quotaCode: "L-1216C47A", desc: "Running On-Demand Standard (A, C, D, H, I, M, R, T, Z) instances"},
+		"g":        {serviceCode: "ec2", quotaCode: "L-DB2E81BA", desc: "Running On-Demand G and VT instances"},
+		"p":        {serviceCode: "ec2", quotaCode: "L-417A185B", desc: "Running On-Demand P instances"},
+		"f":        {serviceCode: "ec2", quotaCode: "L-74FC7D96", desc: "Running On-Demand F instances"},
+		"x":        {serviceCode: "ec2", quotaCode: "L-7295265B", desc: "Running On-Demand X instances"},
+		"inf":      {serviceCode: "ec2", quotaCode: "L-1945791B", desc: "Running On-Demand Inf instances"},
+		"trn":      {serviceCode: "ec2", quotaCode: "L-2C3B7624", desc: "Running On-Demand Trn instances"},
+		"dl":       {serviceCode: "ec2", quotaCode: "L-6E869C2A", desc: "Running On-Demand DL instances"},
+	}
+	spotVCPUQuotas = map[string]serviceQuota{
+		"standard": {serviceCode: "ec2", quotaCode: "L-34B43A08", desc: "All Standard (A, C, D, H, I, M, R, T, Z) Spot Instance Requests"},
+		"g":        {serviceCode: "ec2", quotaCode: "L-3819A6DF", desc: "All G and VT Spot Instance Requests"},
+		"p":        {serviceCode: "ec2", quotaCode: "L-7212CCBC", desc: "All P Spot Instance Requests"},
+		"f":        {serviceCode: "ec2", quotaCode: "L-88CF9481", desc: "All F Spot Instance Requests"},
+		"x":        {serviceCode: "ec2", quotaCode: "L-E3A00192", desc: "All X Spot Instance Requests"},
+		"inf":      {serviceCode: "ec2", quotaCode: "L-B5D1601B", desc: "All Inf Spot Instance Requests"},
+		"trn":      {serviceCode: "ec2", quotaCode: "L-6B0D517C", desc: "All Trn Spot Instance Requests"},
+		"dl":       {serviceCode: "ec2", quotaCode: "L-85EED4F7", desc: "All DL Spot Instance Requests"},
+	}
+)
+
+// instanceClass returns the vCPU quota class of the EC2 instance type
+// (e.g. "standard" for "c5.xlarge", "g" for "g4dn.xlarge").
+// Returns an empty string for unknown classes.
+func instanceClass(instanceType string) string {
+	family := strings.ToLower(strings.Split(instanceType, ".")[0])
+	prefix := family
+	if idx := strings.IndexFunc(family, unicode.IsDigit); idx >= 0 {
+		prefix = family[:idx]
+	}
+	switch prefix {
+	case "inf", "trn", "dl":
+		return prefix
+	case "vt":
+		return "g"
+	case "mac", "":
+		return ""
+	}
+	switch prefix[0] {
+	case 'a', 'c', 'd', 'h', 'i', 'm', 'r', 't', 'z':
+		return "standard"
+	case 'g':
+		return "g"
+	case 'p':
+		return "p"
+	case 'f':
+		return "f"
+	case 'x':
+		return "x"
+	}
+	return ""
+}
+
+// validateQuotas checks the service quotas against the resources
+// to launch (see "eksconfig.QuotaRequirements") and the current usage,
+// and fails early if the configuration cannot possibly launch, instead
+// of failing in the middle of the creation. Quotas that cannot be
+// fetched (e.g. missing permissions) are skipped with warnings.
+func (ts *Tester) validateQuotas() error {
+	if ts.cfg.SkipQuotaCheck {
+		ts.lg.Info("skipping quota check")
+		return nil
+	}
+	req := ts.cfg.QuotaRequirements()
+	ts.lg.Info("checking service quotas",
+		zap.Any("on-demand-instances", req.OnDemandInstances),
+		zap.Any("spot-instances", req.SpotInstances),
+		zap.Int("eips", req.EIPs),
+		zap.Int("nat-gateways-per-az", req.NATGatewaysPerAZ),
+		zap.Int("clusters", req.Clusters),
+	)
+
+	var errs []string
+	check := func(q serviceQuota, required int, inUse func() (int, error)) {
+		if required == 0 {
+			return
+		}
+		limit, err := ts.getServiceQuota(q)
+		if err != nil {
+			ts.lg.Warn("failed to get service quota; skipping", zap.String("quota", q.desc), zap.Error(err))
+			return
+		}
+		used, err := inUse()
+		if err != nil {
+			ts.lg.Warn("failed to get current usage; skipping", zap.String("quota", q.desc), zap.Error(err))
+			return
+		}
+		ts.lg.Info("checked service quota",
+			zap.String("quota", q.desc),
+			zap.Float64("limit", limit),
+			zap.Int("in-use", used),
+			zap.Int("required", required),
+		)
+		if float64(used+required) > limit {
+			errs = append(errs, fmt.Sprintf("%s %s %q (quota %.0f, in use %d, required %d)", q.serviceCode, q.quotaCode, q.desc, limit, used, required))
+		}
+	}
+
+	if len(req.OnDemandInstances) > 0 || len(req.SpotInstances) > 0 {
+		onDemandRequired, spotRequired, err := ts.requiredVCPUs(req)
+		if err != nil {
+			ts.lg.Warn("failed to get instance type vCPUs; skipping vCPU quotas", zap.Error(err))
+		} else {
+			var onDemandUsed, spotUsed map[string]int
+			var usedErr error
+			inUse := func(spot bool, class string) func() (int, error) {
+				return func() (int, error) {
+					if onDemandUsed == nil && usedErr == nil {
+						onDemandUsed, spotUsed, usedErr = ts.runningVCPUs()
+					}
+					if usedErr != nil {
+						return 0, usedErr
+					}
+					if spot {
+						return spotUsed[class], nil
+					}
+					return onDemandUsed[class], nil
+				}
+			}
+			for _, class := range sortedKeys(onDemandRequired) {
+				check(onDemandVCPUQuotas[class], onDemandRequired[class], inUse(false, class))
+			}
+			for _, class := range sortedKeys(spotRequired) {
+				check(spotVCPUQuotas[class], spotRequired[class], inUse(true, class))
+			}
+		}
+	}
+	check(quotaEIPs, req.EIPs, ts.usedEIPs)
+	check(quotaNATGatewaysPerAZ, req.NATGatewaysPerAZ, ts.usedNATGatewaysPerAZ)
+	check(quotaClusters, req.Clusters, ts.usedClusters)
+
+	if len(errs) > 0 {
+		return fmt.Errorf("insufficient service quotas for the configuration (request quota increases, reduce node counts, or set SkipQuotaCheck): %s", strings.Join(errs, ", "))
+	}
+	ts.lg.Info("checked service quotas")
+	return nil
+}
+
+func (ts *Tester) getServiceQuota(q serviceQuota) (float64, error) {
+	out, err := ts.sqAPI.GetServiceQuota(&servicequotas.GetServiceQuotaInput{
+		ServiceCode: aws.String(q.serviceCode),
+		QuotaCode:   aws.String(q.quotaCode),
+	})
+	if err != nil {
+		// not applied in the account, fall back to the default
+		if aerr, ok := err.(awserr.Error); ok && aerr.Code() == servicequotas.ErrCodeNoSuchResourceException {
+			dout, derr := ts.sqAPI.GetAWSDefaultServiceQuota(&servicequotas.GetAWSDefaultServiceQuotaInput{
+				ServiceCode: aws.String(q.serviceCode),
+				QuotaCode:   aws.String(q.quotaCode),
+			})
+			if derr != nil {
+				return 0, derr
+			}
+			return aws.Float64Value(dout.Quota.Value), nil
+		}
+		return 0, err
+	}
+	return aws.Float64Value(out.Quota.Value), nil
+}
+
+// requiredVCPUs returns the required vCPUs by instance class.
+func (ts *Tester) requiredVCPUs(req eksconfig.QuotaRequirements) (onDemand map[string]int, spot map[string]int, err error) {
+	types := make([]string, 0, len(req.OnDemandInstances)+len(req.SpotInstances))
+	for k := range req.OnDemandInstances {
+		types = append(types, k)
+	}
+	for k := range req.SpotInstances {
+		types = append(types, k)
+	}
+	vcpus, err := ts.describeVCPUs(types)
+	if err != nil {
+		return nil, nil, err
+	}
+	onDemand, spot = make(map[string]int), make(map[string]int)
+	for k, n := range req.OnDemandInstances {
+		if class := instanceClass(k); class != "" {
+			onDemand[class] += n * vcpus[k]
+		} else {
+			ts.lg.Warn("unknown instance class; skipping", zap.String("instance-type", k))
+		}
+	}
+	for k, n := range req.SpotInstances {
+		if class := instanceClass(k); class != "" {
+			spot[class] += n * vcpus[k]
+		} else {
+			ts.lg.Warn("unknown instance class; skipping", zap.String("instance-type", k))
+		}
+	}
+	return onDemand, spot, nil
+}
+
+// describeVCPUs returns the default number of vCPUs for each instance type.
+func (ts *Tester) describeVCPUs(types []string) (map[string]int, error) {
+	sort.Strings(types)
+	vcpus := make(map[string]int, len(types))
+	for len(types) > 0 {
+		// at most 100 instance types in a request
+		batch := types
+		if len(batch) > 100 {
+			batch = batch[:100]
+		}
+		types = types[len(batch):]
+
+		out, err := ts.ec2API.DescribeInstanceTypes(&ec2.DescribeInstanceTypesInput{
+			InstanceTypes: aws.StringSlice(batch),
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, it := range out.InstanceTypes {
+			if it.VCpuInfo == nil {
+				continue
+			}
+			vcpus[aws.StringValue(it.InstanceType)] = int(aws.Int64Value(it.VCpuInfo.DefaultVCpus))
+		}
+	}
+	return vcpus, nil
+}
+
+// runningVCPUs returns the vCPUs of pending and running instances by instance class.
+func (ts *Tester) runningVCPUs() (onDemand map[string]int, spot map[string]int, err error) {
+	counts, spotCounts := make(map[string]int), make(map[string]int)
+	err = ts.ec2API.DescribeInstancesPages(
+		&ec2.DescribeInstancesInput{
+			Filters: []*ec2.Filter{
+				{
+					Name:   aws.String("instance-state-name"),
+					Values: aws.StringSlice([]string{"pending", "running"}),
+				},
+			},
+		},
+		func(out *ec2.DescribeInstancesOutput, lastPage bool) bool {
+			for _, rv := range out.Reservations {
+				for _, iv := range rv.Instances {
+					tp := aws.StringValue(iv.InstanceType)
+					if aws.StringValue(iv.InstanceLifecycle) == ec2.InstanceLifecycleTypeSpot {
+						spotCounts[tp]++
+					} else {
+						counts[tp]++
+					}
+				}
+			}
+			return true
+		},
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	types := make([]string, 0, len(counts)+len(spotCounts))
+	for k := range counts {
+		types = append(types, k)
+	}
+	for k := range spotCounts {
+		if _, ok := counts[k]; !ok {
+			types = append(types, k)
+		}
+	}
+	if len(types) == 0 {
+		return map[string]int{}, map[string]int{}, nil
+	}
+	vcpus, err := ts.describeVCPUs(types)
+	if err != nil {
+		return nil, nil, err
+	}
+	onDemand, spot = make(map[string]int), make(map[string]int)
+	for k, n := range counts {
+		onDemand[instanceClass(k)] += n * vcpus[k]
+	}
+	for k, n := range spotCounts {
+		spot[instanceClass(k)] += n * vcpus[k]
+	}
+	return onDemand, spot, nil
+}
+
+func (ts *Tester) usedEIPs() (int, error) {
+	out, err := ts.ec2API.DescribeAddresses(&ec2.DescribeAddressesInput{
+		Filters: []*ec2.Filter{
+			{
+				Name:   aws.String("domain"),
+				Values: aws.StringSlice([]string{"vpc"}),
+			},
+		},
+	})
+	if err != nil {
+		return 0, err
+	}
+	return len(out.Addresses), nil
+}
+
+// usedNATGatewaysPerAZ returns the maximum number of NAT gateways
+// in the availability zones to create the cluster in.
+func (ts *Tester) usedNATGatewaysPerAZ() (int, error) {
+	subnetIDs := make([]string, 0)
+	err := ts.ec2API.DescribeNatGatewaysPages(
+		&ec2.DescribeNatGatewaysInput{
+			Filter: []*ec2.Filter{
+				{
+					Name:   aws.String("state"),
+					Values: aws.StringSlice([]string{"pending", "available"}),
+				},
+			},
+		},
+		func(out *ec2.DescribeNatGatewaysOutput, lastPage bool) bool {
+			for _, nv := range out.NatGateways {
+				subnetIDs = append(subnetIDs, aws.StringValue(nv.SubnetId))
+			}
+			return true
+		},
+	)
+	if err != nil {
+		return 0, err
+	}
+	if len(subnetIDs) == 0 {
+		return 0, nil
+	}
+
+	out, err := ts.ec2API.DescribeSubnets(&ec2.DescribeSubnetsInput{
+		SubnetIds: aws.StringSlice(subnetIDs),
+	})
+	if err != nil {
+		return 0, err
+	}
+	azs := make(map[string]string, len(out.Subnets))
+	for _, sv := range out.Subnets {
+		azs[aws.StringValue(sv.SubnetId)] = aws.StringValue(sv.AvailabilityZone)
+	}
+	perAZ := make(map[string]int)
+	for _, id := range subnetIDs {
+		perAZ[azs[id]]++
+	}
+	max := 0
+	for _, az := range ts.cfg.AvailabilityZoneNames {
+		if perAZ[az] > max {
+			max = perAZ[az]
+		}
+	}
+	return max, nil
+}
+
+func (ts *Tester) usedClusters() (int, error) {
+	n := 0
+	err := ts.eksAPIForCluster.ListClustersPages(
+		&aws_eks.ListClustersInput{},
+		func(out *aws_eks.ListClustersOutput, lastPage bool) bool {
+			n += len(out.Clusters)
+			return true
+		},
+	)
+	if err != nil {
+		return 0, err
+	}
+	return n, nil
+}
+
+func sortedKeys(m map[string]int) []string {
+	ks := make([]string, 0, len(m))
+	for k := range m {
+		ks = append(ks, k)
+	}
+	sort.Strings(ks)
+	return ks
+}
@@ -0,0 +1,30 @@
+package eks
+
+import "testing"
+
+func Test_instanceClass(t *testing.T) {
+	tt := []struct {
+		instanceType string
+		class        string
+	}{
+		{"c5.xlarge", "standard"},
+		{"m5d.2xlarge", "standard"},
+		{"t3a.medium", "standard"},
+		{"r6g.large", "standard"},
+		{"g4dn.xlarge", "g"},
+		{"vt1.3xlarge", "g"},
+		{"p3.8xlarge", "p"},
+		{"f1.2xlarge", "f"},
+		{"x1e.xlarge", "x"},
+		{"inf1.xlarge", "inf"},
+		{"trn1.2xlarge", "trn"},
+		{"dl1.24xlarge", "dl"},
+		{"mac1.metal", ""},
+		{"", ""},
+	}
+	for i, tv := range tt {
+		if class := instanceClass(tv.instanceType); class != tv.class {
+			t.Fatalf("#%d: %q expected %q, got %q", i, tv.instanceType, tv.class, class)
+		}
+	}
+}
@@ -0,0 +1,586 @@
+// Package reaper implements the garbage collector of the orphaned resources
+// created by the tester (e.g. left behind by crashed CI runs), which scans a
+// region for the tester-tagged resources older than the configured age and
+// deletes them.
+package reaper
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/autoscaling"
+	"github.com/aws/aws-sdk-go/service/autoscaling/autoscalingiface"
+	"github.com/aws/aws-sdk-go/service/cloudformation"
+	"github.com/aws/aws-sdk-go/service/cloudformation/cloudformationiface"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/ec2/ec2iface"
+	"github.com/aws/aws-sdk-go/service/eks"
+	"github.com/aws/aws-sdk-go/service/eks/eksiface"
+	"github.com/aws/aws-sdk-go/service/elbv2"
+	"github.com/aws/aws-sdk-go/service/elbv2/elbv2iface"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+	"go.uber.org/zap"
+)
+
+// Resource types.
+const (
+	TypeCluster  = "eks-cluster"
+	TypeCFNStack = "cfn-stack"
+	TypeASG      = "asg"
+	TypeELBv2    = "elbv2"
+	TypeENI      = "eni"
+	TypeS3Prefix = "s3-prefix"
+)
+
+const (
+	// testerTagKey and testerTagValue mark the resources created by the tester.
+	testerTagKey   = "Kind"
+	testerTagValue = "aws-k8s-tester"
+
+	// tags of the resources created on behalf of a cluster
+	// (e.g. load balancers by the cloud provider, ENIs by the VPC CNI)
+	k8sClusterTagPrefix = "kubernetes.io/cluster/"
+	cniClusterTagKey    = "cluster.k8s.amazonaws.com/name"
+	cniCreatedAtTagKey  = "node.k8s.amazonaws.com/createdAt"
+	eksClusterTagKey    = "eks:cluster-name"
+)
+
+// Config defines the reaper configuration.
+// A nil API skips the resource type (e.g. S3API nil for no S3 sweep).
+type Config struct {
+	Logger *zap.Logger
+	// Age is the minimum age of the tester-tagged resources to delete.
+	Age time.Duration
+	// DryRun is 'true' to only report the resources to delete.
+	DryRun bool
+	// S3BucketName is the tester S3 bucket, whose top-level prefixes
+	// (one per cluster name) are deleted once all objects are older than "Age".
+	// Only the buckets tagged by the tester are swept.
+	S3BucketName string
+
+	EKSAPI   eksiface.EKSAPI
+	CFNAPI   cloudformationiface.CloudFormationAPI
+	ASGAPI   autoscalingiface.AutoScalingAPI
+	ELBV2API elbv2iface.ELBV2API
+	EC2API   ec2iface.EC2API
+	S3API    s3iface.S3API
+}
+
+// Resource is an orphaned resource found by the reaper.
+type Resource struct {
+	// Type is the resource type (e.g. "eks-cluster").
+	Type string `json:"type"`
+	// ID is the resource name, ID, or ARN.
+	ID string `json:"id"`
+	// Cluster is the name of the owner cluster, if known.
+	Cluster string `json:"cluster,omitempty"`
+	// Created is the creation time, if known.
+	Created time.Time `json:"created"`
+	// Reason is why the resource is considered orphaned.
+	Reason string `json:"reason"`
+	// Deleted is true if the delete request succeeded.
+	Deleted bool `json:"deleted"`
+	// Error is the delete error message, if any.
+	Error string `json:"error,omitempty"`
+}
+
+// Reaper finds and deletes the orphaned resources.
+type Reaper struct {
+	cfg Config
+	now time.Time
+
+	// liveClusters is the set of all cluster names in the region,
+	// and reapedClusters the subset being deleted
+	liveClusters   map[string]struct{}
+	reapedClusters map[string]struct{}
+
+	resources []Resource
+}
+
+// New creates a new reaper.
+func New(cfg Config) *Reaper {
+	return &Reaper{
+		cfg:            cfg,
+		now:            time.Now(),
+		liveClusters:   make(map[string]struct{}),
+		reapedClusters: make(map[string]struct{}),
+	}
+}
+
+// Reap scans the region, and deletes the orphaned resources unless "DryRun".
+// Clusters are reaped first, so that the resources created on their behalf
+// (e.g. load balancers, ENIs) are reaped with them regardless of the age.
+// A failed delete does not stop the others (e.g. cluster with node groups
+// still deleting), and is retried by the next run.
+func (r *Reaper) Reap() ([]Resource, error) {
+	if r.cfg.EKSAPI == nil {
+		// live clusters protect their resources from the age-based reaping
+		return nil, errors.New("empty EKSAPI")
+	}
+	var errs []string
+	for _, f := range []struct {
+		tp   string
+		api  interface{}
+		reap func() error
+	}{
+		{TypeCluster, r.cfg.EKSAPI, r.reapClusters},
+		{TypeCFNStack, r.cfg.CFNAPI, r.reapStacks},
+		{TypeASG, r.cfg.ASGAPI, r.reapASGs},
+		{TypeELBv2, r.cfg.ELBV2API, r.reapELBv2},
+		{TypeENI, r.cfg.EC2API, r.reapENIs},
+		{TypeS3Prefix, r.cfg.S3API, r.reapS3Prefixes},
+	} {
+		if f.api == nil {
+			continue
+		}
+		r.cfg.Logger.Info("reaping", zap.String("type", f.tp), zap.Duration("age", r.cfg.Age), zap.Bool("dry-run", r.cfg.DryRun))
+		if err := f.reap(); err != nil {
+			r.cfg.Logger.Warn("failed to reap", zap.String("type", f.tp), zap.Error(err))
+			errs = append(errs, fmt.Sprintf("%s (%v)", f.tp, err))
+		}
+	}
+
+	failed := 0
+	for _, v := range r.resources {
+		if v.Error != "" {
+			failed++
+		}
+	}
+	r.cfg.Logger.Info("reaped",
+		zap.Int("resources", len(r.resources)),
+		zap.Int("failed", failed),
+		zap.Bool("dry-run", r.cfg.DryRun),
+	)
+	if failed > 0 {
+		errs = append(errs, fmt.Sprintf("failed to delete %d resource(s)", failed))
+	}
+	if len(errs) > 0 {
+		return r.resources, fmt.Errorf("reap failed: %s", strings.Join(errs, ", "))
+	}
+	return r.resources, nil
+}
+
+// orphaned returns the reason if the resource is orphaned:
+// either its owner cluster is being reaped, or it is tagged by the tester
+// and older than "Age" without a live owner cluster.
+func (r *Reaper) orphaned(tags map[string]string, created time.Time) (cluster string, reason string, ok bool) {
+	cluster = ownerCluster(tags)
+	if cluster != "" {
+		if _, reaped := r.reapedClusters[cluster]; reaped {
+			return cluster, fmt.Sprintf("owner cluster %q reaped", cluster), true
+		}
+		if _, live := r.liveClusters[cluster]; live {
+			return cluster, "", false
+		}
+	}
+	if !isTesterTagged(tags) || created.IsZero() {
+		return cluster, "", false
+	}
+	if age := r.now.Sub(created); age > r.cfg.Age {
+		return cluster, fmt.Sprintf("created %v ago (older than %v)", age.Truncate(time.Second), r.cfg.Age), true
+	}
+	return cluster, "", false
+}
+
+// isTesterTagged returns true if the resource is tagged by the tester.
+func isTesterTagged(tags map[string]string) bool {
+	return tags[testerTagKey] == testerTagValue
+}
+
+// ownerCluster returns the cluster name that the resource is created for,
+// or empty if unknown.
+func ownerCluster(tags map[string]string) string {
+	if v := tags[eksClusterTagKey]; v != "" {
+		return v
+	}
+	if v := tags[cniClusterTagKey]; v != "" {
+		return v
+	}
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if strings.HasPrefix(k, k8sClusterTagPrefix) {
+			return strings.TrimPrefix(k, k8sClusterTagPrefix)
+		}
+	}
+	return ""
+}
+
+// record adds the resource, and deletes it unless "DryRun".
+func (r *Reaper) record(v Resource, del func() error) {
+	r.cfg.Logger.Info("found orphaned resource",
+		zap.String("type", v.Type),
+		zap.String("id", v.ID),
+		zap.String("cluster", v.Cluster),
+		zap.String("reason", v.Reason),
+		zap.Bool("dry-run", r.cfg.DryRun),
+	)
+	if !r.cfg.DryRun {
+		if err := del(); err != nil && !isNotFound(err) {
+			r.cfg.Logger.Warn("failed to delete orphaned resource", zap.String("type", v.Type), zap.String("id", v.ID), zap.Error(err))
+			v.Error = err.Error()
+		} else {
+			r.cfg.Logger.Info("deleted orphaned resource", zap.String("type", v.Type), zap.String("id", v.ID))
+			v.Deleted = true
+		}
+	}
+	r.resources = append(r.resources, v)
+}
+
+func isNotFound(err error) bool {
+	aerr, ok := err.(awserr.Error)
+	if !ok {
+		return false
+	}
+	code := aerr.Code()
+	return code == eks.ErrCodeResourceNotFoundException || strings.Contains(code, "NotFound")
+}
+
+func (r *Reaper) reapClusters() error {
+	names := make([]string, 0)
+	if err := r.cfg.EKSAPI.ListClustersPages(&eks.ListClustersInput{},
+		func(out *eks.ListClustersOutput, lastPage bool) bool {
+			names = append(names, aws.StringValueSlice(out.Clusters)...)
+			return true
+		}); err != nil {
+		return err
+	}
+	for _, name := range names {
+		r.liveClusters[name] = struct{}{}
+	}
+
+	for _, name := range names {
+		out, err := r.cfg.EKSAPI.DescribeCluster(&eks.DescribeClusterInput{Name: aws.String(name)})
+		if err != nil {
+			r.cfg.Logger.Warn("failed to describe cluster", zap.String("name", name), zap.Error(err))
+			continue
+		}
+		tags := aws.StringValueMap(out.Cluster.Tags)
+		// cluster itself is never owned by another cluster
+		if !isTesterTagged(tags) {
+			continue
+		}
+		created := aws.TimeValue(out.Cluster.CreatedAt)
+		age := r.now.Sub(created)
+		if age <= r.cfg.Age {
+			continue
+		}
+		r.reapedClusters[name] = struct{}{}
+		delete(r.liveClusters, name)
+		r.record(Resource{
+			Type:    TypeCluster,
+			ID:      name,
+			Cluster: name,
+			Created: created,
+			Reason:  fmt.Sprintf("created %v ago (older than %v)", age.Truncate(time.Second), r.cfg.Age),
+		}, func() error { return r.deleteCluster(name) })
+	}
+	return nil
+}
+
+// deleteCluster deletes the node groups and the Fargate profiles first,
+// since the cluster cannot be deleted until they are gone.
+func (r *Reaper) deleteCluster(name string) error {
+	ngs := make([]string, 0)
+	if err := r.cfg.EKSAPI.ListNodegroupsPages(&eks.ListNodegroupsInput{ClusterName: aws.String(name)},
+		func(out *eks.ListNodegroupsOutput, lastPage bool) bool {
+			ngs = append(ngs, aws.StringValueSlice(out.Nodegroups)...)
+			return true
+		}); err != nil {
+		return err
+	}
+	for _, ng := range ngs {
+		r.cfg.Logger.Info("deleting node group", zap.String("cluster", name), zap.String("node-group", ng))
+		if _, err := r.cfg.EKSAPI.DeleteNodegroup(&eks.DeleteNodegroupInput{
+			ClusterName:   aws.String(name),
+			NodegroupName: aws.String(ng),
+		}); err != nil && !isNotFound(err) {
+			r.cfg.Logger.Warn("failed to delete node group", zap.String("node-group", ng), zap.Error(err))
+		}
+	}
+	fps := make([]string, 0)
+	if err := r.cfg.EKSAPI.ListFargateProfilesPages(&eks.ListFargateProfilesInput{ClusterName: aws.String(name)},
+		func(out *eks.ListFargateProfilesOutput, lastPage bool) bool {
+			fps = append(fps, aws.StringValueSlice(out.FargateProfileNames)...)
+			return true
+		}); err != nil {
+		return err
+	}
+	for _, fp := range fps {
+		r.cfg.Logger.Info("deleting fargate profile", zap.String("cluster", name), zap.String("fargate-profile", fp))
+		if _, err := r.cfg.EKSAPI.DeleteFargateProfile(&eks.DeleteFargateProfileInput{
+			ClusterName:        aws.String(name),
+			FargateProfileName: aws.String(fp),
+		}); err != nil && !isNotFound(err) {
+			r.cfg.Logger.Warn("failed to delete fargate profile", zap.String("fargate-profile", fp), zap.Error(err))
+		}
+	}
+	if len(ngs) > 0 || len(fps) > 0 {
+		// "ResourceInUseException" until deleted, retried by the next run
+		return fmt.Errorf("deleting %d node group(s) and %d fargate profile(s) first; cluster deleted by the next run", len(ngs), len(fps))
+	}
+	_, err := r.cfg.EKSAPI.DeleteCluster(&eks.DeleteClusterInput{Name: aws.String(name)})
+	return err
+}
+
+func (r *Reaper) reapStacks() error {
+	return r.cfg.CFNAPI.DescribeStacksPages(&cloudformation.DescribeStacksInput{},
+		func(out *cloudformation.DescribeStacksOutput, lastPage bool) bool {
+			for _, st := range out.Stacks {
+				switch aws.StringValue(st.StackStatus) {
+				case cloudformation.StackStatusDeleteComplete, cloudformation.StackStatusDeleteInProgress:
+					continue
+				}
+				tags := make(map[string]string, len(st.Tags))
+				for _, tg := range st.Tags {
+					tags[aws.StringValue(tg.Key)] = aws.StringValue(tg.Value)
+				}
+				cluster, reason, ok := r.orphaned(tags, aws.TimeValue(st.CreationTime))
+				if !ok {
+					continue
+				}
+				name := aws.StringValue(st.StackName)
+				r.record(Resource{
+					Type:    TypeCFNStack,
+					ID:      name,
+					Cluster: cluster,
+					Created: aws.TimeValue(st.CreationTime),
+					Reason:  reason,
+				}, func() error {
+					_, err := r.cfg.CFNAPI.DeleteStack(&cloudformation.DeleteStackInput{StackName: aws.String(name)})
+					return err
+				})
+			}
+			return true
+		})
+}
+
+func (r *Reaper) reapASGs() error {
+	return r.cfg.ASGAPI.DescribeAutoScalingGroupsPages(&autoscaling.DescribeAutoScalingGroupsInput{},
+		func(out *autoscaling.DescribeAutoScalingGroupsOutput, lastPage bool) bool {
+			for _, asg := range out.AutoScalingGroups {
+				if asg.Status != nil {
+					// e.g. "Delete in progress"
+					continue
+				}
+				tags := make(map[string]string, len(asg.Tags))
+				for _, tg := range asg.Tags {
+					tags[aws.StringValue(tg.Key)] = aws.StringValue(tg.Value)
+				}
+				cluster, reason, ok := r.orphaned(tags, aws.TimeValue(asg.CreatedTime))
+				if !ok {
+					continue
+				}
+				name := aws.StringValue(asg.AutoScalingGroupName)
+				r.record(Resource{
+					Type:    TypeASG,
+					ID:      name,
+					Cluster: cluster,
+					Created: aws.TimeValue(asg.CreatedTime),
+					Reason:  reason,
+				}, func() error {
+					_, err := r.cfg.ASGAPI.DeleteAutoScalingGroup(&autoscaling.DeleteAutoScalingGroupInput{
+						AutoScalingGroupName: aws.String(name),
+						ForceDelete:          aws.Bool(true),
+					})
+					return err
+				})
+			}
+			return true
+		})
+}
+
+func (r *Reaper) reapELBv2() error {
+	lbs := make([]*elbv2.LoadBalancer, 0)
+	if err := r.cfg.ELBV2API.DescribeLoadBalancersPages(&elbv2.DescribeLoadBalancersInput{},
+		func(out *elbv2.DescribeLoadBalancersOutput, lastPage bool) bool {
+			lbs = append(lbs, out.LoadBalancers...)
+			return true
+		}); err != nil {
+		return err
+	}
+
+	// "DescribeTags" accepts up to 20 resource ARNs
+	tags := make(map[string]map[string]string, len(lbs))
+	for i := 0; i < len(lbs); i += 20 {
+		end := i + 20
+		if end > len(lbs) {
+			end = len(lbs)
+		}
+		arns := make([]*string, 0, end-i)
+		for _, lb := range lbs[i:end] {
+			arns = append(arns, lb.LoadBalancerArn)
+		}
+		out, err := r.cfg.ELBV2API.DescribeTags(&elbv2.DescribeTagsInput{ResourceArns: arns})
+		if err != nil {
+			return err
+		}
+		for _, td := range out.TagDescriptions {
+			m := make(map[string]string, len(td.Tags))
+			for _, tg := range td.Tags {
+				m[aws.StringValue(tg.Key)] = aws.StringValue(tg.Value)
+			}
+			tags[aws.StringValue(td.ResourceArn)] = m
+		}
+	}
+
+	for _, lb := range lbs {
+		arn := aws.StringValue(lb.LoadBalancerArn)
+		cluster, reason, ok := r.orphaned(tags[arn], aws.TimeValue(lb.CreatedTime))
+		if !ok {
+			continue
+		}
+		r.record(Resource{
+			Type:    TypeELBv2,
+			ID:      arn,
+			Cluster: cluster,
+			Created: aws.TimeValue(lb.CreatedTime),
+			Reason:  reason,
+		}, func() error {
+			_, err := r.cfg.ELBV2API.DeleteLoadBalancer(&elbv2.DeleteLoadBalancerInput{LoadBalancerArn: aws.String(arn)})
+			return err
+		})
+	}
+	return nil
+}
+
+// reapENIs deletes the detached ENIs. The ENIs have no creation time,
+// so only the ones with the VPC CNI creation tag are reaped by the age.
+func (r *Reaper) reapENIs() error {
+	return r.cfg.EC2API.DescribeNetworkInterfacesPages(&ec2.DescribeNetworkInterfacesInput{
+		Filters: []*ec2.Filter{
+			{Name: aws.String("status"), Values: aws.StringSlice([]string{ec2.NetworkInterfaceStatusAvailable})},
+		},
+	}, func(out *ec2.DescribeNetworkInterfacesOutput, lastPage bool) bool {
+		for _, eni := range out.NetworkInterfaces {
+			tags := make(map[string]string, len(eni.TagSet))
+			for _, tg := range eni.TagSet {
+				tags[aws.StringValue(tg.Key)] = aws.StringValue(tg.Value)
+			}
+			var created time.Time
+			if v := tags[cniCreatedAtTagKey]; v != "" {
+				created, _ = time.Parse(time.RFC3339, v)
+			}
+			cluster, reason, ok := r.orphaned(tags, created)
+			if !ok {
+				continue
+			}
+			id := aws.StringValue(eni.NetworkInterfaceId)
+			r.record(Resource{
+				Type:    TypeENI,
+				ID:      id,
+				Cluster: cluster,
+				Created: created,
+				Reason:  reason,
+			}, func() error {
+				_, err := r.cfg.EC2API.DeleteNetworkInterface(&ec2.DeleteNetworkInterfaceInput{NetworkInterfaceId: aws.String(id)})
+				return err
+			})
+		}
+		return true
+	})
+}
+
+// reapS3Prefixes deletes the top-level prefixes of the tester bucket
+// whose objects are all older than "Age", unless the cluster of the
+// same name is still live.
+func (r *Reaper) reapS3Prefixes() error {
+	if r.cfg.S3BucketName == "" {
+		return nil
+	}
+	tout, err := r.cfg.S3API.GetBucketTagging(&s3.GetBucketTaggingInput{Bucket: aws.String(r.cfg.S3BucketName)})
+	if err != nil {
+		return fmt.Errorf("failed to get bucket tags %q (%v)", r.cfg.S3BucketName, err)
+	}
+	tags := make(map[string]string, len(tout.TagSet))
+	for _, tg := range tout.TagSet {
+		tags[aws.StringValue(tg.Key)] = aws.StringValue(tg.Value)
+	}
+	if !isTesterTagged(tags) {
+		return fmt.Errorf("bucket %q not tagged by the tester", r.cfg.S3BucketName)
+	}
+
+	prefixes := make([]string, 0)
+	if err = r.cfg.S3API.ListObjectsV2Pages(&s3.ListObjectsV2Input{
+		Bucket:    aws.String(r.cfg.S3BucketName),
+		Delimiter: aws.String("/"),
+	}, func(out *s3.ListObjectsV2Output, lastPage bool) bool {
+		for _, cp := range out.CommonPrefixes {
+			prefixes = append(prefixes, aws.StringValue(cp.Prefix))
+		}
+		return true
+	}); err != nil {
+		return err
+	}
+
+	for _, prefix := range prefixes {
+		cluster := strings.TrimSuffix(prefix, "/")
+		if _, live := r.liveClusters[cluster]; live {
+			continue
+		}
+		keys, latest := make([]string, 0), time.Time{}
+		if err = r.cfg.S3API.ListObjectsV2Pages(&s3.ListObjectsV2Input{
+			Bucket: aws.String(r.cfg.S3BucketName),
+			Prefix: aws.String(prefix),
+		}, func(out *s3.ListObjectsV2Output, lastPage bool) bool {
+			for _, obj := range out.Contents {
+				keys = append(keys, aws.StringValue(obj.Key))
+				if t := aws.TimeValue(obj.LastModified); t.After(latest) {
+					latest = t
+				}
+			}
+			return true
+		}); err != nil {
+			return err
+		}
+		if len(keys) == 0 {
+			continue
+		}
+		age := r.now.Sub(latest)
+		if age <= r.cfg.Age {
+			continue
+		}
+		r.record(Resource{
+			Type:    TypeS3Prefix,
+			ID:      fmt.Sprintf("s3://%s/%s", r.cfg.S3BucketName, prefix),
+			Cluster: cluster,
+			Created: latest,
+			Reason:  fmt.Sprintf("last modified %v ago (older than %v)", age.Truncate(time.Second), r.cfg.Age),
+		}, func() error { return r.deleteObjects(keys) })
+	}
+	return nil
+}
+
+// deleteObjects deletes the objects, up to 1,000 keys per request.
+func (r *Reaper) deleteObjects(keys []string) error {
+	for i := 0; i < len(keys); i += 1000 {
+		end := i + 1000
+		if end > len(keys) {
+			end = len(keys)
+		}
+		objs := make([]*s3.ObjectIdentifier, 0, end-i)
+		for _, k := range keys[i:end] {
+			objs = append(objs, &s3.ObjectIdentifier{Key: aws.String(k)})
+		}
+		out, err := r.cfg.S3API.DeleteObjects(&s3.DeleteObjectsInput{
+			Bucket: aws.String(r.cfg.S3BucketName),
+			Delete: &s3.Delete{Objects: objs, Quiet: aws.Bool(true)},
+		})
+		if err != nil {
+			return err
+		}
+		if len(out.Errors) > 0 {
+			return fmt.Errorf("failed to delete %d object(s) (e.g. %q %s)", len(out.Errors), aws.StringValue(out.Errors[0].Key), aws.StringValue(out.Errors[0].Message))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,177 @@
+package reaper
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/autoscaling"
+	"github.com/aws/aws-sdk-go/service/autoscaling/autoscalingiface"
+	"github.com/aws/aws-sdk-go/service/eks"
+	"github.com/aws/aws-sdk-go/service/eks/eksiface"
+	"go.uber.org/zap"
+)
+
+type fakeEKS struct {
+	eksiface.EKSAPI
+	clusters   map[string]*eks.Cluster
+	nodeGroups map[string][]string
+	deleted    []string
+}
+
+func (f *fakeEKS) ListClustersPages(_ *eks.ListClustersInput, fn func(*eks.ListClustersOutput, bool) bool) error {
+	names := make([]string, 0, len(f.clusters))
+	for name := range f.clusters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	fn(&eks.ListClustersOutput{Clusters: aws.StringSlice(names)}, true)
+	return nil
+}
+
+func (f *fakeEKS) DescribeCluster(in *eks.DescribeClusterInput) (*eks.DescribeClusterOutput, error) {
+	return &eks.DescribeClusterOutput{Cluster: f.clusters[aws.StringValue(in.Name)]}, nil
+}
+
+func (f *fakeEKS) ListNodegroupsPages(in *eks.ListNodegroupsInput, fn func(*eks.ListNodegroupsOutput, bool) bool) error {
+	fn(&eks.ListNodegroupsOutput{Nodegroups: aws.StringSlice(f.nodeGroups[aws.StringValue(in.ClusterName)])}, true)
+	return nil
+}
+
+func (f *fakeEKS) DeleteNodegroup(in *eks.DeleteNodegroupInput) (*eks.DeleteNodegroupOutput, error) {
+	f.deleted = append(f.deleted, "ng/"+aws.StringValue(in.NodegroupName))
+	return &eks.DeleteNodegroupOutput{}, nil
+}
+
+func (f *fakeEKS) ListFargateProfilesPages(_ *eks.ListFargateProfilesInput, fn func(*eks.ListFargateProfilesOutput, bool) bool) error {
+	fn(&eks.ListFargateProfilesOutput{}, true)
+	return nil
+}
+
+func (f *fakeEKS) DeleteCluster(in *eks.DeleteClusterInput) (*eks.DeleteClusterOutput, error) {
+	f.deleted = append(f.deleted, "cluster/"+aws.StringValue(in.Name))
+	return &eks.DeleteClusterOutput{}, nil
+}
+
+type fakeASG struct {
+	autoscalingiface.AutoScalingAPI
+	groups  []*autoscaling.Group
+	deleted []string
+}
+
+func (f *fakeASG) DescribeAutoScalingGroupsPages(_ *autoscaling.DescribeAutoScalingGroupsInput, fn func(*autoscaling.DescribeAutoScalingGroupsOutput, bool) bool) error {
+	fn(&autoscaling.DescribeAutoScalingGroupsOutput{AutoScalingGroups: f.groups}, true)
+	return nil
+}
+
+func (f *fakeASG) DeleteAutoScalingGroup(in *autoscaling.DeleteAutoScalingGroupInput) (*autoscaling.DeleteAutoScalingGroupOutput, error) {
+	f.deleted = append(f.deleted, aws.StringValue(in.AutoScalingGroupName))
+	return &autoscaling.DeleteAutoScalingGroupOutput{}, nil
+}
+
+func TestReap(t *testing.T) {
+	now := time.Now()
+	tester := map[string]*string{"Kind": aws.String("aws-k8s-tester")}
+	eksAPI := &fakeEKS{
+		clusters: map[string]*eks.Cluster{
+			"old":        {Name: aws.String("old"), CreatedAt: aws.Time(now.Add(-48 * time.Hour)), Tags: tester},
+			"old-ng":     {Name: aws.String("old-ng"), CreatedAt: aws.Time(now.Add(-48 * time.Hour)), Tags: tester},
+			"new":        {Name: aws.String("new"), CreatedAt: aws.Time(now.Add(-time.Hour)), Tags: tester},
+			"not-tester": {Name: aws.String("not-tester"), CreatedAt: aws.Time(now.Add(-48 * time.Hour))},
+		},
+		nodeGroups: map[string][]string{"old-ng": {"mng"}},
+	}
+	asgTags := func(kv ...string) []*autoscaling.TagDescription {
+		tags := make([]*autoscaling.TagDescription, 0)
+		for i := 0; i < len(kv); i += 2 {
+			tags = append(tags, &autoscaling.TagDescription{Key: aws.String(kv[i]), Value: aws.String(kv[i+1])})
+		}
+		return tags
+	}
+	asgAPI := &fakeASG{
+		groups: []*autoscaling.Group{
+			// owner cluster reaped, regardless of age
+			{AutoScalingGroupName: aws.String("asg-old"), CreatedTime: aws.Time(now.Add(-time.Minute)), Tags: asgTags("kubernetes.io/cluster/old", "owned")},
+			// owner cluster live, regardless of age
+			{AutoScalingGroupName: aws.String("asg-new"), CreatedTime: aws.Time(now.Add(-48 * time.Hour)), Tags: asgTags("Kind", "aws-k8s-tester", "eks:cluster-name", "new")},
+			// owner cluster gone, and old
+			{AutoScalingGroupName: aws.String("asg-gone"), CreatedTime: aws.Time(now.Add(-48 * time.Hour)), Tags: asgTags("Kind", "aws-k8s-tester", "eks:cluster-name", "gone")},
+			// owner cluster gone, but new
+			{AutoScalingGroupName: aws.String("asg-gone-new"), CreatedTime: aws.Time(now.Add(-time.Hour)), Tags: asgTags("Kind", "aws-k8s-tester", "eks:cluster-name", "gone")},
+			// not tagged by the tester
+			{AutoScalingGroupName: aws.String("asg-other"), CreatedTime: aws.Time(now.Add(-48 * time.Hour))},
+			// being deleted
+			{AutoScalingGroupName: aws.String("asg-deleting"), CreatedTime: aws.Time(now.Add(-48 * time.Hour)), Tags: asgTags("Kind", "aws-k8s-tester"), Status: aws.String("Delete in progress")},
+		},
+	}
+
+	r := New(Config{
+		Logger: zap.NewExample(),
+		Age:    24 * time.Hour,
+		EKSAPI: eksAPI,
+		ASGAPI: asgAPI,
+	})
+	r.now = now
+	rs, err := r.Reap()
+	if err == nil {
+		t.Fatal("expected error for cluster with node groups")
+	}
+
+	found := make([]string, 0, len(rs))
+	for _, v := range rs {
+		found = append(found, v.Type+"/"+v.ID)
+	}
+	if exp := []string{"eks-cluster/old", "eks-cluster/old-ng", "asg/asg-old", "asg/asg-gone"}; !reflect.DeepEqual(found, exp) {
+		t.Fatalf("expected %q, got %q", exp, found)
+	}
+	if rs[1].Deleted || rs[1].Error == "" {
+		t.Fatalf("expected cluster with node groups not deleted, got %+v", rs[1])
+	}
+	if exp := []string{"cluster/old", "ng/mng"}; !reflect.DeepEqual(eksAPI.deleted, exp) {
+		t.Fatalf("expected EKS deletes %q, got %q", exp, eksAPI.deleted)
+	}
+	if exp := []string{"asg-old", "asg-gone"}; !reflect.DeepEqual(asgAPI.deleted, exp) {
+		t.Fatalf("expected ASG deletes %q, got %q", exp, asgAPI.deleted)
+	}
+}
+
+func TestReapDryRun(t *testing.T) {
+	now := time.Now()
+	eksAPI := &fakeEKS{
+		clusters: map[string]*eks.Cluster{
+			"old": {Name: aws.String("old"), CreatedAt: aws.Time(now.Add(-48 * time.Hour)), Tags: map[string]*string{"Kind": aws.String("aws-k8s-tester")}},
+		},
+	}
+	r := New(Config{Logger: zap.NewExample(), Age: 24 * time.Hour, DryRun: true, EKSAPI: eksAPI})
+	r.now = now
+	rs, err := r.Reap()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rs) != 1 || rs[0].Deleted {
+		t.Fatalf("unexpected results %+v", rs)
+	}
+	if len(eksAPI.deleted) != 0 {
+		t.Fatalf("unexpected deletes in dry run %q", eksAPI.deleted)
+	}
+}
+
+func TestOwnerCluster(t *testing.T) {
+	tt := []struct {
+		tags map[string]string
+		exp  string
+	}{
+		{map[string]string{"eks:cluster-name": "a"}, "a"},
+		{map[string]string{"cluster.k8s.amazonaws.com/name": "b"}, "b"},
+		{map[string]string{"kubernetes.io/cluster/c": "owned", "Kind": "aws-k8s-tester"}, "c"},
+		{map[string]string{"Kind": "aws-k8s-tester"}, ""},
+		{nil, ""},
+	}
+	for i, tv := range tt {
+		if v := ownerCluster(tv.tags); v != tv.exp {
+			t.Fatalf("#%d: expected %q, got %q", i, tv.exp, v)
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package eks
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	aws_s3 "github.com/aws/aws-k8s-tester/pkg/aws/s3"
+	"github.com/aws/aws-k8s-tester/pkg/metrics"
+	"go.uber.org/zap"
+)
+
+// checkRegressions compares the add-on "RequestsSummary" results against
+// the baselines from S3, and returns an error on any regression only when
+// "Regression.FailOnRegression" is true. Otherwise, the regressions are
+// recorded in "Regression.Regressed" and "Regression.Reports".
+func (ts *Tester) checkRegressions() error {
+	results := ts.cfg.RequestsSummaries()
+
+	names := make([]string, 0, len(ts.cfg.Regression.BaselineS3Keys))
+	for name := range ts.cfg.Regression.BaselineS3Keys {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	thresholds := ts.cfg.RegressionThresholds()
+	reports := make(map[string]metrics.RegressionReport)
+	for _, name := range names {
+		cur, ok := results[name]
+		if !ok {
+			ts.lg.Warn("skipping regression check for disabled add-on", zap.String("name", name))
+			continue
+		}
+		if cur.SuccessTotal+cur.FailureTotal == 0 {
+			ts.lg.Warn("skipping regression check for empty result", zap.String("name", name))
+			continue
+		}
+		baseline, err := ts.downloadBaseline(ts.cfg.Regression.BaselineS3Keys[name])
+		if err != nil {
+			return fmt.Errorf("failed to download baseline for %q (%v)", name, err)
+		}
+		r := cur.CompareTo(baseline, thresholds)
+		reports[name] = r
+		fmt.Fprintf(ts.logWriter, "\n\nRegression %q:\n%s\n", name, r.Table())
+	}
+
+	regressed := ts.cfg.SetRegressionReports(reports)
+	ts.cfg.Sync()
+	if len(regressed) == 0 {
+		ts.lg.Info("no regression from baselines", zap.Int("compared", len(reports)))
+		return nil
+	}
+
+	errs := make([]string, 0, len(regressed))
+	for _, name := range regressed {
+		errs = append(errs, fmt.Sprintf("%s: %v", name, reports[name].Err()))
+	}
+	if !ts.cfg.Regression.FailOnRegression {
+		ts.lg.Warn("regressed from baselines; marked degraded", zap.Strings("regressions", errs))
+		return nil
+	}
+	return fmt.Errorf("regressed from baselines (%s)", strings.Join(errs, "; "))
+}
+
+// checkSLOs evaluates the add-on "RequestsSummary" results against
+// the add-on SLOs (e.g. "AddOnStresserLocal.SLOMaxLatencyP99"), records
+// the verdicts in the configuration, and returns an error on any violation.
+func (ts *Tester) checkSLOs() error {
+	verdicts := ts.cfg.EvaluateSLOs()
+	ts.cfg.Sync()
+	if len(verdicts) == 0 {
+		ts.lg.Info("no SLO to evaluate")
+		return nil
+	}
+
+	addOns := make([]string, 0, len(verdicts))
+	for addOn := range verdicts {
+		addOns = append(addOns, addOn)
+	}
+	sort.Strings(addOns)
+
+	errs := make([]string, 0)
+	for _, addOn := range addOns {
+		v := verdicts[addOn]
+		fmt.Fprintf(ts.logWriter, "\n\nSLO %q:\n%s\n", addOn, v.Table())
+		if err := v.Err(); err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", addOn, err))
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("failed SLO (%s)", strings.Join(errs, "; "))
+	}
+	ts.lg.Info("passed SLO", zap.Strings("add-ons", addOns))
+	return nil
+}
+
+func (ts *Tester) downloadBaseline(s3Key string) (metrics.RequestsSummary, error) {
+	p, err := aws_s3.DownloadToTempFile(ts.lg, ts.s3API, ts.cfg.Regression.BaselineS3BucketName, s3Key, aws_s3.WithTimeout(time.Minute))
+	if err != nil {
+		return metrics.RequestsSummary{}, err
+	}
+	defer os.RemoveAll(p)
+	d, err := ioutil.ReadFile(p)
+	if err != nil {
+		return metrics.RequestsSummary{}, err
+	}
+	return metrics.ParseRequestsSummary(d)
+}
+
+// publishSummaries publishes the add-on "RequestsSummary" results
+// to CloudWatch, skipping the ones without any request.
+func (ts *Tester) publishSummaries() error {
+	summaries := ts.cfg.RequestsSummaries()
+	for name, rs := range summaries {
+		if rs.SuccessTotal+rs.FailureTotal == 0 {
+			delete(summaries, name)
+		}
+	}
+	if len(summaries) == 0 {
+		ts.lg.Info("no summary to publish")
+		return nil
+	}
+	sink := &metrics.CloudWatchSink{
+		Logger:     ts.lg,
+		CWAPI:      ts.cwAPI,
+		Namespace:  ts.cfg.CWSummaries.Namespace,
+		Dimensions: ts.cfg.CWSummaries.Dimensions,
+	}
+	return sink.Publish(summaries)
+}
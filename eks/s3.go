@@ -2,8 +2,11 @@ package eks
 
 import (
 	"errors"
+	"io/ioutil"
 	"path"
 	"path/filepath"
+	"sort"
+	"strings"
 
 	aws_s3 "github.com/aws/aws-k8s-tester/pkg/aws/s3"
 	"github.com/aws/aws-k8s-tester/pkg/fileutil"
@@ -56,6 +59,7 @@ func (ts *Tester) uploadToS3() (err error) {
 			ts.cfg.S3.BucketName,
 			path.Join(ts.cfg.Name, "aws-k8s-tester-eks.config.yaml"),
 			ts.cfg.ConfigPath,
+			aws_s3.WithTags(ts.cfg.Tags),
 		); err != nil {
 			return err
 		}
@@ -75,6 +79,7 @@ func (ts *Tester) uploadToS3() (err error) {
 			ts.cfg.S3.BucketName,
 			path.Join(ts.cfg.Name, "aws-k8s-tester-eks.log"),
 			logFilePath,
+			aws_s3.WithTags(ts.cfg.Tags),
 		); err != nil {
 			return err
 		}
@@ -82,3 +87,48 @@ func (ts *Tester) uploadToS3() (err error) {
 
 	return err
 }
+
+// uploadSummaryCSVs writes the add-on "RequestsSummary" results in CSV
+// next to their JSON artifacts (e.g. "...-writes-summary.csv" for
+// "...-writes-summary.json"), and uploads them next to the JSON S3 objects,
+// so that the results can be loaded into spreadsheets and Athena.
+// It skips the results without any request or JSON artifact.
+func (ts *Tester) uploadSummaryCSVs() error {
+	summaries := ts.cfg.RequestsSummaries()
+	names := make([]string, 0, len(summaries))
+	for name := range summaries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		rs := summaries[name]
+		if rs.SuccessTotal+rs.FailureTotal == 0 {
+			continue
+		}
+		jsonPath, jsonS3Key := ts.cfg.RequestsSummaryJSONArtifact(name)
+		if jsonPath == "" {
+			ts.lg.Warn("skipping CSV for result without JSON artifact", zap.String("name", name))
+			continue
+		}
+		csvPath := strings.TrimSuffix(jsonPath, filepath.Ext(jsonPath)) + ".csv"
+		if err := ioutil.WriteFile(csvPath, []byte(rs.CSV()), 0600); err != nil {
+			return err
+		}
+		ts.lg.Info("wrote summary CSV", zap.String("name", name), zap.String("path", csvPath))
+		if ts.cfg.S3.BucketName == "" || jsonS3Key == "" {
+			continue
+		}
+		if err := aws_s3.Upload(
+			ts.lg,
+			ts.s3API,
+			ts.cfg.S3.BucketName,
+			strings.TrimSuffix(jsonS3Key, path.Ext(jsonS3Key))+".csv",
+			csvPath,
+			aws_s3.WithTags(ts.cfg.Tags),
+		); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -212,6 +212,7 @@ func (ts *tester) checkResults(curWriteLatencies metrics.Durations, curReadLaten
 			ts.cfg.EKSConfig.S3.BucketName,
 			ts.cfg.EKSConfig.AddOnSecretsLocal.RequestsSummaryWritesCompareJSONS3Key,
 			ts.cfg.EKSConfig.AddOnSecretsLocal.RequestsSummaryWritesCompareJSONPath,
+			aws_s3.WithTags(ts.cfg.EKSConfig.Tags),
 		); err != nil {
 			return err
 		}
@@ -225,6 +226,7 @@ func (ts *tester) checkResults(curWriteLatencies metrics.Durations, curReadLaten
 			ts.cfg.EKSConfig.S3.BucketName,
 			ts.cfg.EKSConfig.AddOnSecretsLocal.RequestsSummaryWritesCompareTableS3Key,
 			ts.cfg.EKSConfig.AddOnSecretsLocal.RequestsSummaryWritesCompareTablePath,
+			aws_s3.WithTags(ts.cfg.EKSConfig.Tags),
 		); err != nil {
 			return err
 		}
@@ -267,6 +269,7 @@ func (ts *tester) checkResults(curWriteLatencies metrics.Durations, curReadLaten
 			ts.cfg.EKSConfig.S3.BucketName,
 			ts.cfg.EKSConfig.AddOnSecretsLocal.RequestsRawWritesCompareAllJSONS3Key,
 			ts.cfg.EKSConfig.AddOnSecretsLocal.RequestsRawWritesCompareAllJSONPath,
+			aws_s3.WithTags(ts.cfg.EKSConfig.Tags),
 		); err != nil {
 			return err
 		}
@@ -279,6 +282,7 @@ func (ts *tester) checkResults(curWriteLatencies metrics.Durations, curReadLaten
 			ts.cfg.EKSConfig.S3.BucketName,
 			ts.cfg.EKSConfig.AddOnSecretsLocal.RequestsRawWritesCompareAllCSVS3Key,
 			ts.cfg.EKSConfig.AddOnSecretsLocal.RequestsRawWritesCompareAllCSVPath,
+			aws_s3.WithTags(ts.cfg.EKSConfig.Tags),
 		); err != nil {
 			return err
 		}
@@ -292,6 +296,7 @@ func (ts *tester) checkResults(curWriteLatencies metrics.Durations, curReadLaten
 		ts.cfg.EKSConfig.S3.BucketName,
 		path.Join(ts.cfg.EKSConfig.AddOnSecretsLocal.RequestsRawWritesCompareS3Dir, curTS),
 		ts.cfg.EKSConfig.AddOnSecretsLocal.RequestsRawWritesJSONPath,
+		aws_s3.WithTags(ts.cfg.EKSConfig.Tags),
 	); err != nil {
 		return err
 	}
@@ -301,6 +306,7 @@ func (ts *tester) checkResults(curWriteLatencies metrics.Durations, curReadLaten
 		ts.cfg.EKSConfig.S3.BucketName,
 		path.Join(ts.cfg.EKSConfig.AddOnSecretsLocal.RequestsSummaryWritesCompareS3Dir, curTS),
 		ts.cfg.EKSConfig.AddOnSecretsLocal.RequestsSummaryWritesJSONPath,
+		aws_s3.WithTags(ts.cfg.EKSConfig.Tags),
 	); err != nil {
 		return err
 	}
@@ -340,6 +346,7 @@ func (ts *tester) checkResults(curWriteLatencies metrics.Durations, curReadLaten
 			ts.cfg.EKSConfig.S3.BucketName,
 			ts.cfg.EKSConfig.AddOnSecretsLocal.RequestsSummaryReadsCompareJSONS3Key,
 			ts.cfg.EKSConfig.AddOnSecretsLocal.RequestsSummaryReadsCompareJSONPath,
+			aws_s3.WithTags(ts.cfg.EKSConfig.Tags),
 		); err != nil {
 			return err
 		}
@@ -353,6 +360,7 @@ func (ts *tester) checkResults(curWriteLatencies metrics.Durations, curReadLaten
 			ts.cfg.EKSConfig.S3.BucketName,
 			ts.cfg.EKSConfig.AddOnSecretsLocal.RequestsSummaryReadsCompareTableS3Key,
 			ts.cfg.EKSConfig.AddOnSecretsLocal.RequestsSummaryReadsCompareTablePath,
+			aws_s3.WithTags(ts.cfg.EKSConfig.Tags),
 		); err != nil {
 			return err
 		}
@@ -395,6 +403,7 @@ func (ts *tester) checkResults(curWriteLatencies metrics.Durations, curReadLaten
 			ts.cfg.EKSConfig.S3.BucketName,
 			ts.cfg.EKSConfig.AddOnSecretsLocal.RequestsRawReadsCompareAllJSONS3Key,
 			ts.cfg.EKSConfig.AddOnSecretsLocal.RequestsRawReadsCompareAllJSONPath,
+			aws_s3.WithTags(ts.cfg.EKSConfig.Tags),
 		); err != nil {
 			return err
 		}
@@ -407,6 +416,7 @@ func (ts *tester) checkResults(curWriteLatencies metrics.Durations, curReadLaten
 			ts.cfg.EKSConfig.S3.BucketName,
 			ts.cfg.EKSConfig.AddOnSecretsLocal.RequestsRawReadsCompareAllCSVS3Key,
 			ts.cfg.EKSConfig.AddOnSecretsLocal.RequestsRawReadsCompareAllCSVPath,
+			aws_s3.WithTags(ts.cfg.EKSConfig.Tags),
 		); err != nil {
 			return err
 		}
@@ -420,6 +430,7 @@ func (ts *tester) checkResults(curWriteLatencies metrics.Durations, curReadLaten
 		ts.cfg.EKSConfig.S3.BucketName,
 		path.Join(ts.cfg.EKSConfig.AddOnSecretsLocal.RequestsRawReadsCompareS3Dir, curTS),
 		ts.cfg.EKSConfig.AddOnSecretsLocal.RequestsRawReadsJSONPath,
+		aws_s3.WithTags(ts.cfg.EKSConfig.Tags),
 	); err != nil {
 		return err
 	}
@@ -429,6 +440,7 @@ func (ts *tester) checkResults(curWriteLatencies metrics.Durations, curReadLaten
 		ts.cfg.EKSConfig.S3.BucketName,
 		path.Join(ts.cfg.EKSConfig.AddOnSecretsLocal.RequestsSummaryReadsCompareS3Dir, curTS),
 		ts.cfg.EKSConfig.AddOnSecretsLocal.RequestsSummaryReadsJSONPath,
+		aws_s3.WithTags(ts.cfg.EKSConfig.Tags),
 	); err != nil {
 		return err
 	}
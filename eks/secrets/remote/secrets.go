@@ -826,6 +826,7 @@ func (ts *tester) checkResults() (err error) {
 			zap.String("s3-dir", path.Dir(ts.cfg.EKSConfig.AddOnSecretsRemote.RequestsSummaryWritesJSONS3Key)),
 		)
 		cnt := 0
+		var writesWorkerSummaries []metrics.RequestsSummary
 		err = filepath.Walk(writesDirSummary, func(fpath string, info os.FileInfo, werr error) error {
 			if werr != nil {
 				return werr
@@ -840,23 +841,21 @@ func (ts *tester) checkResults() (err error) {
 				if err != nil {
 					return fmt.Errorf("failed to open %q (%v)", fpath, err)
 				}
-				var r metrics.RequestsSummary
-				if err = json.Unmarshal(b, &r); err != nil {
-					return fmt.Errorf("failed to unmarshal %q (%s, %v)", fpath, string(b), err)
-				}
-				writesSummary.SuccessTotal += r.SuccessTotal
-				writesSummary.FailureTotal += r.FailureTotal
-				if writesSummary.LatencyHistogram == nil || len(writesSummary.LatencyHistogram) == 0 {
-					writesSummary.LatencyHistogram = r.LatencyHistogram
-				} else {
-					writesSummary.LatencyHistogram, err = metrics.MergeHistograms(writesSummary.LatencyHistogram, r.LatencyHistogram)
-					if err != nil {
-						return fmt.Errorf("failed to merge histograms (%v)", err)
-					}
+				r, err := metrics.ParseRequestsSummary(b)
+				if err != nil {
+					return fmt.Errorf("failed to parse %q (%s, %v)", fpath, string(b), err)
 				}
+				writesWorkerSummaries = append(writesWorkerSummaries, r)
 			}
 			return nil
 		})
+		if err == nil && cnt > 0 {
+			// merge all worker histograms at once, with the buckets aligned
+			writesSummary, err = metrics.CombineRequestsSummaries(append([]metrics.RequestsSummary{writesSummary}, writesWorkerSummaries...)...)
+			if err != nil {
+				err = fmt.Errorf("failed to combine requests summaries (%v)", err)
+			}
+		}
 		if err != nil || cnt == 0 {
 			ts.cfg.Logger.Warn("failed to read writes results", zap.Int("file-count", cnt), zap.Error(err))
 			os.RemoveAll(writesDirSummary)
@@ -916,6 +915,7 @@ func (ts *tester) checkResults() (err error) {
 			zap.String("s3-dir", path.Dir(ts.cfg.EKSConfig.AddOnSecretsRemote.RequestsSummaryReadsJSONS3Key)),
 		)
 		cnt := 0
+		var readsWorkerSummaries []metrics.RequestsSummary
 		err = filepath.Walk(readsDirSummary, func(fpath string, info os.FileInfo, werr error) error {
 			if werr != nil {
 				return werr
@@ -930,23 +930,21 @@ func (ts *tester) checkResults() (err error) {
 				if err != nil {
 					return fmt.Errorf("failed to open %q (%v)", fpath, err)
 				}
-				var r metrics.RequestsSummary
-				if err = json.Unmarshal(b, &r); err != nil {
-					return fmt.Errorf("failed to unmarshal %q (%s, %v)", fpath, string(b), err)
-				}
-				readsSummary.SuccessTotal += r.SuccessTotal
-				readsSummary.FailureTotal += r.FailureTotal
-				if readsSummary.LatencyHistogram == nil || len(readsSummary.LatencyHistogram) == 0 {
-					readsSummary.LatencyHistogram = r.LatencyHistogram
-				} else {
-					readsSummary.LatencyHistogram, err = metrics.MergeHistograms(readsSummary.LatencyHistogram, r.LatencyHistogram)
-					if err != nil {
-						return fmt.Errorf("failed to merge histograms (%v)", err)
-					}
+				r, err := metrics.ParseRequestsSummary(b)
+				if err != nil {
+					return fmt.Errorf("failed to parse %q (%s, %v)", fpath, string(b), err)
 				}
+				readsWorkerSummaries = append(readsWorkerSummaries, r)
 			}
 			return nil
 		})
+		if err == nil && cnt > 0 {
+			// merge all worker histograms at once, with the buckets aligned
+			readsSummary, err = metrics.CombineRequestsSummaries(append([]metrics.RequestsSummary{readsSummary}, readsWorkerSummaries...)...)
+			if err != nil {
+				err = fmt.Errorf("failed to combine requests summaries (%v)", err)
+			}
+		}
 		if err != nil || cnt == 0 {
 			ts.cfg.Logger.Warn("failed to read reads results", zap.Int("file-count", cnt), zap.Error(err))
 			os.RemoveAll(readsDirSummary)
@@ -960,6 +958,7 @@ func (ts *tester) checkResults() (err error) {
 	ts.cfg.Logger.Info("sorted write latencies", zap.String("took", time.Since(sortStart).String()))
 	writesSummary.LantencyP50 = curWriteLatencies.PickLantencyP50()
 	writesSummary.LantencyP90 = curWriteLatencies.PickLantencyP90()
+	writesSummary.LantencyP95 = curWriteLatencies.PickLantencyP95()
 	writesSummary.LantencyP99 = curWriteLatencies.PickLantencyP99()
 	writesSummary.LantencyP999 = curWriteLatencies.PickLantencyP999()
 	writesSummary.LantencyP9999 = curWriteLatencies.PickLantencyP9999()
@@ -972,6 +971,7 @@ func (ts *tester) checkResults() (err error) {
 	ts.cfg.Logger.Info("sorted read latencies", zap.String("took", time.Since(sortStart).String()))
 	readsSummary.LantencyP50 = curReadLatencies.PickLantencyP50()
 	readsSummary.LantencyP90 = curReadLatencies.PickLantencyP90()
+	readsSummary.LantencyP95 = curReadLatencies.PickLantencyP95()
 	readsSummary.LantencyP99 = curReadLatencies.PickLantencyP99()
 	readsSummary.LantencyP999 = curReadLatencies.PickLantencyP999()
 	readsSummary.LantencyP9999 = curReadLatencies.PickLantencyP9999()
@@ -993,6 +993,7 @@ func (ts *tester) checkResults() (err error) {
 		ts.cfg.EKSConfig.S3.BucketName,
 		ts.cfg.EKSConfig.AddOnSecretsRemote.RequestsRawWritesJSONS3Key,
 		ts.cfg.EKSConfig.AddOnSecretsRemote.RequestsRawWritesJSONPath,
+		aws_s3.WithTags(ts.cfg.EKSConfig.Tags),
 	); err != nil {
 		return err
 	}
@@ -1006,6 +1007,7 @@ func (ts *tester) checkResults() (err error) {
 		ts.cfg.EKSConfig.S3.BucketName,
 		ts.cfg.EKSConfig.AddOnSecretsRemote.RequestsSummaryWritesJSONS3Key,
 		ts.cfg.EKSConfig.AddOnSecretsRemote.RequestsSummaryWritesJSONPath,
+		aws_s3.WithTags(ts.cfg.EKSConfig.Tags),
 	); err != nil {
 		return err
 	}
@@ -1019,6 +1021,7 @@ func (ts *tester) checkResults() (err error) {
 		ts.cfg.EKSConfig.S3.BucketName,
 		ts.cfg.EKSConfig.AddOnSecretsRemote.RequestsSummaryWritesTableS3Key,
 		ts.cfg.EKSConfig.AddOnSecretsRemote.RequestsSummaryWritesTablePath,
+		aws_s3.WithTags(ts.cfg.EKSConfig.Tags),
 	); err != nil {
 		return err
 	}
@@ -1039,6 +1042,7 @@ func (ts *tester) checkResults() (err error) {
 		ts.cfg.EKSConfig.S3.BucketName,
 		ts.cfg.EKSConfig.AddOnSecretsRemote.RequestsRawReadsJSONS3Key,
 		ts.cfg.EKSConfig.AddOnSecretsRemote.RequestsRawReadsJSONPath,
+		aws_s3.WithTags(ts.cfg.EKSConfig.Tags),
 	); err != nil {
 		return err
 	}
@@ -1052,6 +1056,7 @@ func (ts *tester) checkResults() (err error) {
 		ts.cfg.EKSConfig.S3.BucketName,
 		ts.cfg.EKSConfig.AddOnSecretsRemote.RequestsSummaryReadsJSONS3Key,
 		ts.cfg.EKSConfig.AddOnSecretsRemote.RequestsSummaryReadsJSONPath,
+		aws_s3.WithTags(ts.cfg.EKSConfig.Tags),
 	); err != nil {
 		return err
 	}
@@ -1065,6 +1070,7 @@ func (ts *tester) checkResults() (err error) {
 		ts.cfg.EKSConfig.S3.BucketName,
 		ts.cfg.EKSConfig.AddOnSecretsRemote.RequestsSummaryReadsTableS3Key,
 		ts.cfg.EKSConfig.AddOnSecretsRemote.RequestsSummaryReadsTablePath,
+		aws_s3.WithTags(ts.cfg.EKSConfig.Tags),
 	); err != nil {
 		return err
 	}
@@ -1105,6 +1111,7 @@ func (ts *tester) checkResults() (err error) {
 			ts.cfg.EKSConfig.S3.BucketName,
 			ts.cfg.EKSConfig.AddOnSecretsRemote.RequestsSummaryWritesCompareJSONS3Key,
 			ts.cfg.EKSConfig.AddOnSecretsRemote.RequestsSummaryWritesCompareJSONPath,
+			aws_s3.WithTags(ts.cfg.EKSConfig.Tags),
 		); err != nil {
 			return err
 		}
@@ -1118,6 +1125,7 @@ func (ts *tester) checkResults() (err error) {
 			ts.cfg.EKSConfig.S3.BucketName,
 			ts.cfg.EKSConfig.AddOnSecretsRemote.RequestsSummaryWritesCompareTableS3Key,
 			ts.cfg.EKSConfig.AddOnSecretsRemote.RequestsSummaryWritesCompareTablePath,
+			aws_s3.WithTags(ts.cfg.EKSConfig.Tags),
 		); err != nil {
 			return err
 		}
@@ -1160,6 +1168,7 @@ func (ts *tester) checkResults() (err error) {
 			ts.cfg.EKSConfig.S3.BucketName,
 			ts.cfg.EKSConfig.AddOnSecretsRemote.RequestsRawWritesCompareAllJSONS3Key,
 			ts.cfg.EKSConfig.AddOnSecretsRemote.RequestsRawWritesCompareAllJSONPath,
+			aws_s3.WithTags(ts.cfg.EKSConfig.Tags),
 		); err != nil {
 			return err
 		}
@@ -1172,6 +1181,7 @@ func (ts *tester) checkResults() (err error) {
 			ts.cfg.EKSConfig.S3.BucketName,
 			ts.cfg.EKSConfig.AddOnSecretsRemote.RequestsRawWritesCompareAllCSVS3Key,
 			ts.cfg.EKSConfig.AddOnSecretsRemote.RequestsRawWritesCompareAllCSVPath,
+			aws_s3.WithTags(ts.cfg.EKSConfig.Tags),
 		); err != nil {
 			return err
 		}
@@ -1185,6 +1195,7 @@ func (ts *tester) checkResults() (err error) {
 		ts.cfg.EKSConfig.S3.BucketName,
 		path.Join(ts.cfg.EKSConfig.AddOnSecretsRemote.RequestsRawWritesCompareS3Dir, curTS),
 		ts.cfg.EKSConfig.AddOnSecretsRemote.RequestsRawWritesJSONPath,
+		aws_s3.WithTags(ts.cfg.EKSConfig.Tags),
 	); err != nil {
 		return err
 	}
@@ -1194,6 +1205,7 @@ func (ts *tester) checkResults() (err error) {
 		ts.cfg.EKSConfig.S3.BucketName,
 		path.Join(ts.cfg.EKSConfig.AddOnSecretsRemote.RequestsSummaryWritesCompareS3Dir, curTS),
 		ts.cfg.EKSConfig.AddOnSecretsRemote.RequestsSummaryWritesJSONPath,
+		aws_s3.WithTags(ts.cfg.EKSConfig.Tags),
 	); err != nil {
 		return err
 	}
@@ -1233,6 +1245,7 @@ func (ts *tester) checkResults() (err error) {
 			ts.cfg.EKSConfig.S3.BucketName,
 			ts.cfg.EKSConfig.AddOnSecretsRemote.RequestsSummaryReadsCompareJSONS3Key,
 			ts.cfg.EKSConfig.AddOnSecretsRemote.RequestsSummaryReadsCompareJSONPath,
+			aws_s3.WithTags(ts.cfg.EKSConfig.Tags),
 		); err != nil {
 			return err
 		}
@@ -1246,6 +1259,7 @@ func (ts *tester) checkResults() (err error) {
 			ts.cfg.EKSConfig.S3.BucketName,
 			ts.cfg.EKSConfig.AddOnSecretsRemote.RequestsSummaryReadsCompareTableS3Key,
 			ts.cfg.EKSConfig.AddOnSecretsRemote.RequestsSummaryReadsCompareTablePath,
+			aws_s3.WithTags(ts.cfg.EKSConfig.Tags),
 		); err != nil {
 			return err
 		}
@@ -1288,6 +1302,7 @@ func (ts *tester) checkResults() (err error) {
 			ts.cfg.EKSConfig.S3.BucketName,
 			ts.cfg.EKSConfig.AddOnSecretsRemote.RequestsRawReadsCompareAllJSONS3Key,
 			ts.cfg.EKSConfig.AddOnSecretsRemote.RequestsRawReadsCompareAllJSONPath,
+			aws_s3.WithTags(ts.cfg.EKSConfig.Tags),
 		); err != nil {
 			return err
 		}
@@ -1300,6 +1315,7 @@ func (ts *tester) checkResults() (err error) {
 			ts.cfg.EKSConfig.S3.BucketName,
 			ts.cfg.EKSConfig.AddOnSecretsRemote.RequestsRawReadsCompareAllCSVS3Key,
 			ts.cfg.EKSConfig.AddOnSecretsRemote.RequestsRawReadsCompareAllCSVPath,
+			aws_s3.WithTags(ts.cfg.EKSConfig.Tags),
 		); err != nil {
 			return err
 		}
@@ -1313,6 +1329,7 @@ func (ts *tester) checkResults() (err error) {
 		ts.cfg.EKSConfig.S3.BucketName,
 		path.Join(ts.cfg.EKSConfig.AddOnSecretsRemote.RequestsRawReadsCompareS3Dir, curTS),
 		ts.cfg.EKSConfig.AddOnSecretsRemote.RequestsRawReadsJSONPath,
+		aws_s3.WithTags(ts.cfg.EKSConfig.Tags),
 	); err != nil {
 		return err
 	}
@@ -1322,6 +1339,7 @@ func (ts *tester) checkResults() (err error) {
 		ts.cfg.EKSConfig.S3.BucketName,
 		path.Join(ts.cfg.EKSConfig.AddOnSecretsRemote.RequestsSummaryReadsCompareS3Dir, curTS),
 		ts.cfg.EKSConfig.AddOnSecretsRemote.RequestsSummaryReadsJSONPath,
+		aws_s3.WithTags(ts.cfg.EKSConfig.Tags),
 	); err != nil {
 		return err
 	}
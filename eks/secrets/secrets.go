@@ -137,7 +137,9 @@ type loader struct {
 	donecCloseOnce *sync.Once
 
 	writeLatencies metrics.Durations
+	writesTook     time.Duration
 	readLatencies  metrics.Durations
+	readsTook      time.Duration
 }
 
 func New(cfg Config) Loader {
@@ -151,7 +153,9 @@ func New(cfg Config) Loader {
 func (ld *loader) Start() {
 	ld.cfg.Logger.Info("starting write function", zap.String("namespace-write", ld.cfg.Namespace))
 	var created []string
+	writesStart := time.Now()
 	ld.writeLatencies, created = startWrites(ld.cfg.Logger, ld.cfg.Client.KubernetesClientSet(), ld.cfg.ClientTimeout, ld.cfg.Namespace, ld.cfg.NamePrefix, ld.cfg.Objects, ld.cfg.ObjectSize, ld.cfg.Stopc, ld.donec)
+	ld.writesTook = time.Since(writesStart)
 	ld.cfg.Logger.Info("completed write function", zap.String("namespace-write", ld.cfg.Namespace))
 
 	// TODO: create Pod with created secrets mounted as volume, read them, measure latency
@@ -159,7 +163,9 @@ func (ld *loader) Start() {
 	// ref. https://github.com/aws/aws-k8s-tester/blob/v1.2.1/eks/secrets/secrets.go#L404-L514
 
 	ld.cfg.Logger.Info("starting read function", zap.String("namespace-read", ld.cfg.Namespace))
+	readsStart := time.Now()
 	ld.readLatencies = startReads(ld.cfg.Logger, ld.cfg.Client.KubernetesClientSet(), ld.cfg.ClientTimeout, ld.cfg.Namespace, created, ld.cfg.Stopc, ld.donec)
+	ld.readsTook = time.Since(readsStart)
 	ld.cfg.Logger.Info("completed read function", zap.String("namespace-read", ld.cfg.Namespace))
 }
 
@@ -175,8 +181,8 @@ func (ld *loader) Stop() {
 // ref. https://pkg.go.dev/github.com/prometheus/client_golang@v1.6.0/prometheus/promhttp?tab=doc#Handler
 func (ts *loader) CollectMetrics() (writeLatencies metrics.Durations, writesSummary metrics.RequestsSummary, readLatencies metrics.Durations, readsSummary metrics.RequestsSummary, err error) {
 	curTS := time.Now().UTC().Format(time.RFC3339Nano)
-	writesSummary = metrics.RequestsSummary{TestID: curTS}
-	readsSummary = metrics.RequestsSummary{TestID: curTS}
+	writesSummary = metrics.RequestsSummary{TestID: curTS, TotalDuration: ts.writesTook}
+	readsSummary = metrics.RequestsSummary{TestID: curTS, TotalDuration: ts.readsTook}
 
 	// https://pkg.go.dev/github.com/prometheus/client_golang/prometheus?tab=doc#Gatherer
 	mfs, err := prometheus.DefaultGatherer.Gather()
@@ -196,7 +202,7 @@ func (ts *loader) CollectMetrics() (writeLatencies metrics.Durations, writesSumm
 			gg := mf.Metric[0].GetGauge()
 			writesSummary.FailureTotal = gg.GetValue()
 		case "secrets_client_write_request_latency_milliseconds":
-			writesSummary.LatencyHistogram, err = metrics.ParseHistogram("milliseconds", mf.Metric[0].GetHistogram())
+			writesSummary.LatencyHistogram, err = metrics.ParseHistogram(metrics.ScaleMilliseconds, mf.Metric[0].GetHistogram())
 			if err != nil {
 				return nil, metrics.RequestsSummary{}, nil, metrics.RequestsSummary{}, err
 			}
@@ -208,7 +214,7 @@ func (ts *loader) CollectMetrics() (writeLatencies metrics.Durations, writesSumm
 			gg := mf.Metric[0].GetGauge()
 			readsSummary.FailureTotal = gg.GetValue()
 		case "secrets_client_read_request_latency_milliseconds":
-			readsSummary.LatencyHistogram, err = metrics.ParseHistogram("milliseconds", mf.Metric[0].GetHistogram())
+			readsSummary.LatencyHistogram, err = metrics.ParseHistogram(metrics.ScaleMilliseconds, mf.Metric[0].GetHistogram())
 			if err != nil {
 				return nil, metrics.RequestsSummary{}, nil, metrics.RequestsSummary{}, err
 			}
@@ -221,6 +227,7 @@ func (ts *loader) CollectMetrics() (writeLatencies metrics.Durations, writesSumm
 	ts.cfg.Logger.Info("sorted write latency results", zap.Int("total-data-points", ts.writeLatencies.Len()), zap.String("took", time.Since(now).String()))
 	writesSummary.LantencyP50 = ts.writeLatencies.PickLantencyP50()
 	writesSummary.LantencyP90 = ts.writeLatencies.PickLantencyP90()
+	writesSummary.LantencyP95 = ts.writeLatencies.PickLantencyP95()
 	writesSummary.LantencyP99 = ts.writeLatencies.PickLantencyP99()
 	writesSummary.LantencyP999 = ts.writeLatencies.PickLantencyP999()
 	writesSummary.LantencyP9999 = ts.writeLatencies.PickLantencyP9999()
@@ -251,6 +258,7 @@ func (ts *loader) CollectMetrics() (writeLatencies metrics.Durations, writesSumm
 	ts.cfg.Logger.Info("sorted read latency results", zap.Int("total-data-points", ts.readLatencies.Len()), zap.String("took", time.Since(now).String()))
 	readsSummary.LantencyP50 = ts.readLatencies.PickLantencyP50()
 	readsSummary.LantencyP90 = ts.readLatencies.PickLantencyP90()
+	readsSummary.LantencyP95 = ts.readLatencies.PickLantencyP95()
 	readsSummary.LantencyP99 = ts.readLatencies.PickLantencyP99()
 	readsSummary.LantencyP999 = ts.readLatencies.PickLantencyP999()
 	readsSummary.LantencyP9999 = ts.readLatencies.PickLantencyP9999()
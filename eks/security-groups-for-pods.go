@@ -0,0 +1,447 @@
+package eks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+
+	aws_ec2 "github.com/aws/aws-k8s-tester/pkg/aws/ec2"
+	"github.com/aws/aws-k8s-tester/pkg/fileutil"
+	k8s_client "github.com/aws/aws-k8s-tester/pkg/k8s-client"
+	aws_v2 "github.com/aws/aws-sdk-go-v2/aws"
+	aws_ec2_v2 "github.com/aws/aws-sdk-go-v2/service/ec2"
+	aws_ec2_v2_types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	smithy "github.com/aws/smithy-go"
+	"go.uber.org/zap"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/utils/exec"
+)
+
+const (
+	// podENIAnnotation is the pod annotation set by the VPC resource
+	// controller, once the branch ENI is attached to the pod.
+	podENIAnnotation = "vpc.amazonaws.com/pod-eni"
+
+	securityGroupsForPodsServer = "server"
+	securityGroupsForPodsClient = "client"
+	securityGroupsForPodsPort   = 8080
+)
+
+const securityGroupPolicyTemplate = `{{ range $role, $sgID := .SecurityGroupIDs }}---
+apiVersion: vpcresources.k8s.aws/v1beta1
+kind: SecurityGroupPolicy
+metadata:
+  name: {{ $role }}
+  namespace: {{ $.Namespace }}
+spec:
+  podSelector:
+    matchLabels:
+      role: {{ $role }}
+  securityGroups:
+    groupIds:
+    - {{ $sgID }}
+{{ end }}`
+
+// enablePodENI enables the trunk ENI on the VPC CNI "aws-node" DaemonSet.
+// Must be run before the node groups are created, so that the trunk ENI
+// is attached to the new nodes.
+func (ts *Tester) enablePodENI() error {
+	if ts.k8sClient == nil {
+		return errors.New("nil k8s client")
+	}
+	patch := []byte(`{"spec":{"template":{"spec":{"containers":[{"name":"aws-node","env":[{"name":"ENABLE_POD_ENI","value":"true"}]}]}}}}`)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	_, err := ts.k8sClient.KubernetesClientSet().
+		AppsV1().
+		DaemonSets("kube-system").
+		Patch(ctx, "aws-node", types.StrategicMergePatchType, patch, metav1.PatchOptions{})
+	cancel()
+	if err != nil {
+		return fmt.Errorf("failed to enable pod ENI on aws-node DaemonSet (%v)", err)
+	}
+	ts.lg.Info("enabled pod ENI on aws-node DaemonSet")
+	return nil
+}
+
+// checkSecurityGroupsForPods creates the server and client security groups
+// and their "SecurityGroupPolicy", runs the server and client pods, and checks
+// that both pods received the branch ENIs, and that the client only reaches
+// the server once the server security group allows the client security group.
+// The namespace and the security groups are deleted afterwards.
+func (ts *Tester) checkSecurityGroupsForPods() (err error) {
+	if ts.k8sClient == nil {
+		return errors.New("nil k8s client")
+	}
+	cur := ts.cfg.SecurityGroupsForPods
+
+	defer func() {
+		if derr := ts.deleteSecurityGroupsForPods(); derr != nil && err == nil {
+			err = derr
+		}
+	}()
+	if cur.ServerSecurityGroupID, err = ts.createPodSecurityGroup(securityGroupsForPodsServer); err != nil {
+		return err
+	}
+	ts.cfg.Sync()
+	if cur.ClientSecurityGroupID, err = ts.createPodSecurityGroup(securityGroupsForPodsClient); err != nil {
+		return err
+	}
+	ts.cfg.Sync()
+
+	cli := ts.k8sClient.KubernetesClientSet()
+	ns := cur.Namespace
+	if err = k8s_client.CreateNamespace(ts.lg, cli, ns); err != nil {
+		return err
+	}
+	defer func() {
+		if derr := k8s_client.DeleteNamespaceAndWait(
+			ts.lg,
+			cli,
+			ns,
+			k8s_client.DefaultNamespaceDeletionInterval,
+			k8s_client.DefaultNamespaceDeletionTimeout,
+			k8s_client.WithForceDelete(true),
+		); derr != nil && err == nil {
+			err = fmt.Errorf("failed to delete namespace %q (%v)", ns, derr)
+		}
+	}()
+
+	// "SecurityGroupPolicy" must be created before the pods,
+	// since the pods are matched on admission
+	if err = ts.applySecurityGroupPolicies(); err != nil {
+		return err
+	}
+	if err = ts.createSecurityGroupsForPodsPods(cli); err != nil {
+		return err
+	}
+	serverIP, err := ts.waitSecurityGroupsForPodsPods(cli)
+	if err != nil {
+		return err
+	}
+
+	url := fmt.Sprintf("http://%s:%d/hostname", serverIP, securityGroupsForPodsPort)
+	if err = ts.execSecurityGroupsForPodsClient(url); err == nil {
+		return fmt.Errorf("client reached %q without the server security group ingress", url)
+	}
+	ts.lg.Info("client blocked by server security group", zap.String("url", url), zap.Error(err))
+
+	if err = ts.authorizePodSecurityGroup(); err != nil {
+		return err
+	}
+	retryStart, waitDur := time.Now(), 2*time.Minute
+	for {
+		if err = ts.execSecurityGroupsForPodsClient(url); err == nil {
+			break
+		}
+		if time.Since(retryStart) > waitDur {
+			return fmt.Errorf("client failed to reach %q with the server security group ingress after %v (%v)", url, waitDur, err)
+		}
+		ts.lg.Warn("client not yet allowed by server security group", zap.String("url", url), zap.Error(err))
+		select {
+		case <-ts.stopCreationCh:
+			return errors.New("security groups for pods check aborted")
+		case <-time.After(10 * time.Second):
+		}
+	}
+
+	ts.lg.Info("checked security groups for pods",
+		zap.String("server-security-group-id", cur.ServerSecurityGroupID),
+		zap.String("client-security-group-id", cur.ClientSecurityGroupID),
+		zap.Any("branch-enis", cur.BranchENIs),
+	)
+	return nil
+}
+
+// AWS::EC2::SecurityGroup
+func (ts *Tester) createPodSecurityGroup(role string) (string, error) {
+	name := fmt.Sprintf("%s-pod-%s", ts.cfg.Name, role)
+	ts.lg.Info("creating pod security group", zap.String("name", name))
+	out, err := ts.ec2APIV2.CreateSecurityGroup(
+		context.Background(),
+		&aws_ec2_v2.CreateSecurityGroupInput{
+			GroupName:   aws_v2.String(name),
+			Description: aws_v2.String(fmt.Sprintf("Security group for %s pods", role)),
+			VpcId:       aws_v2.String(ts.cfg.VPC.ID),
+			TagSpecifications: []aws_ec2_v2_types.TagSpecification{
+				{
+					ResourceType: aws_ec2_v2_types.ResourceTypeSecurityGroup,
+					Tags: aws_ec2.AppendTags([]aws_ec2_v2_types.Tag{
+						{
+							Key:   aws_v2.String("Name"),
+							Value: aws_v2.String(name),
+						},
+					}, ts.cfg.Tags),
+				},
+			},
+		},
+	)
+	if err != nil {
+		return "", fmt.Errorf("failed to create pod security group %q (%v)", name, err)
+	}
+	sgID := aws_v2.ToString(out.GroupId)
+	ts.lg.Info("created pod security group", zap.String("name", name), zap.String("security-group-id", sgID))
+	return sgID, nil
+}
+
+// authorizePodSecurityGroup allows the client security group
+// to the server port of the server security group.
+func (ts *Tester) authorizePodSecurityGroup() error {
+	cur := ts.cfg.SecurityGroupsForPods
+	ts.lg.Info("authorizing server pod security group",
+		zap.String("server-security-group-id", cur.ServerSecurityGroupID),
+		zap.String("client-security-group-id", cur.ClientSecurityGroupID),
+	)
+	_, err := ts.ec2APIV2.AuthorizeSecurityGroupIngress(
+		context.Background(),
+		&aws_ec2_v2.AuthorizeSecurityGroupIngressInput{
+			GroupId: aws_v2.String(cur.ServerSecurityGroupID),
+			IpPermissions: []aws_ec2_v2_types.IpPermission{
+				{
+					IpProtocol: aws_v2.String("tcp"),
+					FromPort:   aws_v2.Int32(securityGroupsForPodsPort),
+					ToPort:     aws_v2.Int32(securityGroupsForPodsPort),
+					UserIdGroupPairs: []aws_ec2_v2_types.UserIdGroupPair{
+						{
+							GroupId:     aws_v2.String(cur.ClientSecurityGroupID),
+							Description: aws_v2.String("allow client pods to server pods"),
+							VpcId:       aws_v2.String(ts.cfg.VPC.ID),
+						},
+					},
+				},
+			},
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("failed to authorize server pod security group %q (%v)", cur.ServerSecurityGroupID, err)
+	}
+	ts.lg.Info("authorized server pod security group")
+	return nil
+}
+
+// deleteSecurityGroupsForPods deletes the pod security groups, and retries
+// while the branch ENIs of the deleted pods are being detached.
+func (ts *Tester) deleteSecurityGroupsForPods() error {
+	cur := ts.cfg.SecurityGroupsForPods
+	// server first, since the server ingress references the client
+	for _, sgID := range []string{cur.ServerSecurityGroupID, cur.ClientSecurityGroupID} {
+		if sgID == "" {
+			continue
+		}
+		ts.lg.Info("deleting pod security group", zap.String("security-group-id", sgID))
+		retryStart, waitDur := time.Now(), 5*time.Minute
+		for {
+			_, err := ts.ec2APIV2.DeleteSecurityGroup(
+				context.Background(),
+				&aws_ec2_v2.DeleteSecurityGroupInput{
+					GroupId: aws_v2.String(sgID),
+				},
+			)
+			if err == nil {
+				break
+			}
+			var apiErr smithy.APIError
+			if errors.As(err, &apiErr) {
+				if strings.Contains(apiErr.ErrorCode(), "NotFound") {
+					break
+				}
+				if apiErr.ErrorCode() != "DependencyViolation" || time.Since(retryStart) > waitDur {
+					return fmt.Errorf("failed to delete pod security group %q (%v)", sgID, err)
+				}
+			}
+			ts.lg.Warn("failed to delete pod security group; retrying", zap.String("security-group-id", sgID), zap.Error(err))
+			time.Sleep(10 * time.Second)
+		}
+		ts.lg.Info("deleted pod security group", zap.String("security-group-id", sgID))
+	}
+	return nil
+}
+
+func (ts *Tester) applySecurityGroupPolicies() error {
+	cur := ts.cfg.SecurityGroupsForPods
+	tpl := template.Must(template.New("securityGroupPolicyTemplate").Parse(securityGroupPolicyTemplate))
+	buf := bytes.NewBuffer(nil)
+	if err := tpl.Execute(buf, struct {
+		Namespace        string
+		SecurityGroupIDs map[string]string
+	}{
+		Namespace: cur.Namespace,
+		SecurityGroupIDs: map[string]string{
+			securityGroupsForPodsServer: cur.ServerSecurityGroupID,
+			securityGroupsForPodsClient: cur.ClientSecurityGroupID,
+		},
+	}); err != nil {
+		return err
+	}
+	fpath, err := fileutil.WriteTempFile(buf.Bytes())
+	if err != nil {
+		return err
+	}
+	applyArgs := []string{
+		ts.cfg.KubectlPath,
+		"--kubeconfig=" + ts.cfg.KubeConfigPath,
+		"apply",
+		"--filename=" + fpath,
+	}
+	applyCmd := strings.Join(applyArgs, " ")
+
+	retryStart, waitDur := time.Now(), 5*time.Minute
+	for time.Since(retryStart) < waitDur {
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+		output, err := exec.New().CommandContext(ctx, applyArgs[0], applyArgs[1:]...).CombinedOutput()
+		cancel()
+		fmt.Fprintf(ts.logWriter, "\n\n'%s' output:\n\n%s\n\n", applyCmd, strings.TrimSpace(string(output)))
+		if err == nil {
+			ts.lg.Info("created SecurityGroupPolicy")
+			return nil
+		}
+		ts.lg.Warn("failed to apply SecurityGroupPolicy", zap.Error(err))
+		select {
+		case <-ts.stopCreationCh:
+			return errors.New("SecurityGroupPolicy creation aborted")
+		case <-time.After(10 * time.Second):
+		}
+	}
+	return errors.New("failed to apply SecurityGroupPolicy")
+}
+
+func (ts *Tester) createSecurityGroupsForPodsPods(cli kubernetes.Interface) error {
+	cur := ts.cfg.SecurityGroupsForPods
+	// only the trunk ENI instance types can attach the branch ENIs
+	affinity := &v1.Affinity{
+		NodeAffinity: &v1.NodeAffinity{
+			RequiredDuringSchedulingIgnoredDuringExecution: &v1.NodeSelector{
+				NodeSelectorTerms: []v1.NodeSelectorTerm{
+					{
+						MatchExpressions: []v1.NodeSelectorRequirement{
+							{
+								Key:      v1.LabelInstanceTypeStable,
+								Operator: v1.NodeSelectorOpIn,
+								Values:   cur.InstanceTypes,
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	commands := map[string][]string{
+		securityGroupsForPodsServer: {"httpd", "-f", "-p", fmt.Sprint(securityGroupsForPodsPort), "-h", "/etc"},
+		securityGroupsForPodsClient: {"sleep", "3600"},
+	}
+	for _, role := range []string{securityGroupsForPodsServer, securityGroupsForPodsClient} {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		_, err := cli.CoreV1().Pods(cur.Namespace).Create(ctx, &v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      role,
+				Namespace: cur.Namespace,
+				Labels:    map[string]string{"role": role},
+			},
+			Spec: v1.PodSpec{
+				RestartPolicy: v1.RestartPolicyNever,
+				Affinity:      affinity,
+				Containers: []v1.Container{
+					{
+						Name:    role,
+						Image:   cur.Image,
+						Command: commands[role],
+					},
+				},
+			},
+		}, metav1.CreateOptions{})
+		cancel()
+		if err != nil {
+			return fmt.Errorf("failed to create pod %q (%v)", role, err)
+		}
+	}
+	return nil
+}
+
+// waitSecurityGroupsForPodsPods waits for the server and client pods
+// to run with the branch ENIs, and returns the server pod IP.
+func (ts *Tester) waitSecurityGroupsForPodsPods(cli kubernetes.Interface) (string, error) {
+	cur := ts.cfg.SecurityGroupsForPods
+	cur.BranchENIs = make(map[string]string, 2)
+	serverIP := ""
+	retryStart, waitDur := time.Now(), 5*time.Minute
+	for len(cur.BranchENIs) < 2 || serverIP == "" {
+		if time.Since(retryStart) > waitDur {
+			return "", fmt.Errorf("%d of 2 pods received branch ENIs after %v (%v)", len(cur.BranchENIs), waitDur, cur.BranchENIs)
+		}
+		select {
+		case <-ts.stopCreationCh:
+			return "", errors.New("security groups for pods check aborted")
+		case <-time.After(10 * time.Second):
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		pods, err := cli.CoreV1().Pods(cur.Namespace).List(ctx, metav1.ListOptions{})
+		cancel()
+		if err != nil {
+			ts.lg.Warn("failed to list pods", zap.Error(err))
+			continue
+		}
+		for _, pod := range pods.Items {
+			if pod.Status.Phase != v1.PodRunning || pod.Status.PodIP == "" {
+				continue
+			}
+			eniID, err := parsePodENI(pod.Annotations[podENIAnnotation])
+			if err != nil {
+				ts.lg.Warn("pod has no branch ENI", zap.String("pod", pod.Name), zap.Error(err))
+				continue
+			}
+			cur.BranchENIs[pod.Name] = eniID
+			if pod.Name == securityGroupsForPodsServer {
+				serverIP = pod.Status.PodIP
+			}
+		}
+		ts.lg.Info("waiting for pod branch ENIs", zap.Any("branch-enis", cur.BranchENIs))
+	}
+	ts.cfg.Sync()
+	return serverIP, nil
+}
+
+func (ts *Tester) execSecurityGroupsForPodsClient(url string) error {
+	execArgs := []string{
+		ts.cfg.KubectlPath,
+		"--kubeconfig=" + ts.cfg.KubeConfigPath,
+		"--namespace=" + ts.cfg.SecurityGroupsForPods.Namespace,
+		"exec",
+		securityGroupsForPodsClient,
+		"--",
+		"wget", "-T", "5", "-q", "-O", "/dev/null", url,
+	}
+	execCmd := strings.Join(execArgs, " ")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	output, err := exec.New().CommandContext(ctx, execArgs[0], execArgs[1:]...).CombinedOutput()
+	cancel()
+	fmt.Fprintf(ts.logWriter, "\n\n'%s' output:\n\n%s\n\n", execCmd, strings.TrimSpace(string(output)))
+	return err
+}
+
+// parsePodENI returns the branch ENI ID from the "vpc.amazonaws.com/pod-eni"
+// annotation (e.g. '[{"eniId":"eni-0123","privateIp":"10.0.1.2","vlanId":1}]').
+func parsePodENI(annotation string) (string, error) {
+	if annotation == "" {
+		return "", fmt.Errorf("empty %q annotation", podENIAnnotation)
+	}
+	var enis []struct {
+		ENIID string `json:"eniId"`
+	}
+	if err := json.Unmarshal([]byte(annotation), &enis); err != nil {
+		return "", fmt.Errorf("invalid %q annotation %q (%v)", podENIAnnotation, annotation, err)
+	}
+	for _, eni := range enis {
+		if eni.ENIID != "" {
+			return eni.ENIID, nil
+		}
+	}
+	return "", fmt.Errorf("no ENI ID in %q annotation %q", podENIAnnotation, annotation)
+}
@@ -0,0 +1,26 @@
+package eks
+
+import "testing"
+
+func TestParsePodENI(t *testing.T) {
+	tt := []struct {
+		annotation string
+		eniID      string
+		err        bool
+	}{
+		{`[{"eniId":"eni-0123","ifAddress":"02:01:02:03:04:05","privateIp":"10.0.1.2","vlanId":1,"subnetCidr":"10.0.0.0/19"}]`, "eni-0123", false},
+		{`[{"privateIp":"10.0.1.2"},{"eniId":"eni-0456"}]`, "eni-0456", false},
+		{`[]`, "", true},
+		{`{"eniId":"eni-0123"}`, "", true},
+		{"", "", true},
+	}
+	for i, tv := range tt {
+		eniID, err := parsePodENI(tv.annotation)
+		if (err != nil) != tv.err {
+			t.Fatalf("#%d: expected error %v, got %v", i, tv.err, err)
+		}
+		if eniID != tv.eniID {
+			t.Fatalf("#%d: expected %q, got %q", i, tv.eniID, eniID)
+		}
+	}
+}
@@ -0,0 +1,680 @@
+// Package spotinterruption implements tester for Spot managed node groups,
+// which interrupts a Spot node and verifies that the node is drained and
+// replaced, and that the pods are rescheduled within the SLO.
+// ref. https://docs.aws.amazon.com/eks/latest/userguide/managed-node-groups.html#managed-node-group-capacity-types
+// ref. https://docs.aws.amazon.com/fis/latest/userguide/fis-tutorial-spot-interruptions.html
+package spotinterruption
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"time"
+
+	eks_tester "github.com/aws/aws-k8s-tester/eks/tester"
+	"github.com/aws/aws-k8s-tester/eksconfig"
+	k8s_client "github.com/aws/aws-k8s-tester/pkg/k8s-client"
+	"github.com/aws/aws-k8s-tester/pkg/timeutil"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/autoscaling"
+	"github.com/aws/aws-sdk-go/service/autoscaling/autoscalingiface"
+	"github.com/aws/aws-sdk-go/service/fis"
+	"github.com/aws/aws-sdk-go/service/fis/fisiface"
+	"github.com/aws/aws-sdk-go/service/iam"
+	"github.com/aws/aws-sdk-go/service/iam/iamiface"
+	"go.uber.org/zap"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Config defines Spot interruption tester configuration.
+type Config struct {
+	Logger    *zap.Logger
+	LogWriter io.Writer
+	Stopc     chan struct{}
+	EKSConfig *eksconfig.Config
+	K8SClient k8s_client.EKS
+	IAMAPI    iamiface.IAMAPI
+	FISAPI    fisiface.FISAPI
+	ASGAPI    autoscalingiface.AutoScalingAPI
+}
+
+var pkgName = reflect.TypeOf(tester{}).PkgPath()
+
+func (ts *tester) Name() string { return pkgName }
+
+// New creates a new Spot interruption tester.
+func New(cfg Config) eks_tester.Tester {
+	cfg.Logger.Info("creating tester", zap.String("tester", pkgName))
+	return &tester{cfg: cfg}
+}
+
+type tester struct {
+	cfg Config
+	// nodesBefore is the set of node names in the managed node group
+	// before the interruption, to tell the replacement node apart.
+	nodesBefore map[string]struct{}
+}
+
+const (
+	spotInterruptionDeploymentName = "spot-interruption-deployment"
+	spotInterruptionAppName        = "spot-interruption"
+	spotInterruptionAppImageName   = "busybox"
+
+	// capacityTypeLabel is the node label set by EKS for the managed node group capacity type.
+	capacityTypeLabel = "eks.amazonaws.com/capacityType"
+
+	// fisActionSpotInterruption sends the Spot interruption notice,
+	// two minutes before the instance is interrupted.
+	fisActionSpotInterruption = "aws:ec2:send-spot-instance-interruptions"
+	fisTargetSpotInstance     = "aws:ec2:spot-instance"
+)
+
+func (ts *tester) Create() error {
+	if !ts.cfg.EKSConfig.IsEnabledAddOnSpotInterruption() {
+		ts.cfg.Logger.Info("skipping tester.Create", zap.String("tester", pkgName))
+		return nil
+	}
+	if ts.cfg.EKSConfig.AddOnSpotInterruption.Created {
+		ts.cfg.Logger.Info("skipping tester.Create", zap.String("tester", pkgName))
+		return nil
+	}
+
+	ts.cfg.Logger.Info("starting tester.Create", zap.String("tester", pkgName))
+	ts.cfg.EKSConfig.AddOnSpotInterruption.Created = true
+	ts.cfg.EKSConfig.Sync()
+	createStart := time.Now()
+	defer func() {
+		createEnd := time.Now()
+		ts.cfg.EKSConfig.AddOnSpotInterruption.TimeFrameCreate = timeutil.NewTimeFrame(createStart, createEnd)
+		ts.cfg.EKSConfig.Sync()
+	}()
+
+	if err := k8s_client.CreateNamespace(
+		ts.cfg.Logger,
+		ts.cfg.K8SClient.KubernetesClientSet(),
+		ts.cfg.EKSConfig.AddOnSpotInterruption.Namespace,
+	); err != nil {
+		return err
+	}
+	if err := ts.createDeployment(); err != nil {
+		return err
+	}
+	if err := ts.waitDeployment(); err != nil {
+		return err
+	}
+	if err := ts.pickNode(); err != nil {
+		return err
+	}
+
+	interruptStart := time.Now()
+	if ts.cfg.EKSConfig.AddOnSpotInterruption.UseFIS {
+		if err := ts.createFISRole(); err != nil {
+			return err
+		}
+		if err := ts.startFISExperiment(); err != nil {
+			return err
+		}
+	} else {
+		if err := ts.terminateInstance(); err != nil {
+			return err
+		}
+	}
+	if err := ts.waitRecovery(interruptStart); err != nil {
+		return err
+	}
+
+	ts.cfg.EKSConfig.Sync()
+	return nil
+}
+
+func (ts *tester) Delete() error {
+	if !ts.cfg.EKSConfig.IsEnabledAddOnSpotInterruption() {
+		ts.cfg.Logger.Info("skipping tester.Delete", zap.String("tester", pkgName))
+		return nil
+	}
+	if !ts.cfg.EKSConfig.AddOnSpotInterruption.Created {
+		ts.cfg.Logger.Info("skipping tester.Delete", zap.String("tester", pkgName))
+		return nil
+	}
+
+	ts.cfg.Logger.Info("starting tester.Delete", zap.String("tester", pkgName))
+	deleteStart := time.Now()
+	defer func() {
+		deleteEnd := time.Now()
+		ts.cfg.EKSConfig.AddOnSpotInterruption.TimeFrameDelete = timeutil.NewTimeFrame(deleteStart, deleteEnd)
+		ts.cfg.EKSConfig.Sync()
+	}()
+
+	var errs []string
+
+	if err := ts.deleteFISExperimentTemplate(); err != nil {
+		errs = append(errs, fmt.Sprintf("failed to delete FIS experiment template (%v)", err))
+	}
+	if err := ts.deleteFISRole(); err != nil {
+		errs = append(errs, fmt.Sprintf("failed to delete FIS role (%v)", err))
+	}
+
+	if err := k8s_client.DeleteNamespaceAndWait(
+		ts.cfg.Logger,
+		ts.cfg.K8SClient.KubernetesClientSet(),
+		ts.cfg.EKSConfig.AddOnSpotInterruption.Namespace,
+		k8s_client.DefaultNamespaceDeletionInterval,
+		k8s_client.DefaultNamespaceDeletionTimeout,
+		k8s_client.WithForceDelete(true),
+	); err != nil {
+		errs = append(errs, fmt.Sprintf("failed to delete Spot interruption namespace (%v)", err))
+	}
+
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, ", "))
+	}
+
+	ts.cfg.EKSConfig.AddOnSpotInterruption.Created = false
+	ts.cfg.EKSConfig.Sync()
+	return nil
+}
+
+func (ts *tester) nodeSelector() map[string]string {
+	return map[string]string{
+		"NGName":          ts.cfg.EKSConfig.AddOnSpotInterruption.MNGName,
+		capacityTypeLabel: "SPOT",
+	}
+}
+
+func (ts *tester) createDeployment() error {
+	ts.cfg.Logger.Info("creating Spot interruption Deployment", zap.Any("node-selector", ts.nodeSelector()))
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	_, err := ts.cfg.K8SClient.KubernetesClientSet().
+		AppsV1().
+		Deployments(ts.cfg.EKSConfig.AddOnSpotInterruption.Namespace).
+		Create(
+			ctx,
+			&appsv1.Deployment{
+				TypeMeta: metav1.TypeMeta{
+					APIVersion: "apps/v1",
+					Kind:       "Deployment",
+				},
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      spotInterruptionDeploymentName,
+					Namespace: ts.cfg.EKSConfig.AddOnSpotInterruption.Namespace,
+					Labels: map[string]string{
+						"app.kubernetes.io/name": spotInterruptionAppName,
+					},
+				},
+				Spec: appsv1.DeploymentSpec{
+					Replicas: aws.Int32(ts.cfg.EKSConfig.AddOnSpotInterruption.DeploymentReplicas),
+					Selector: &metav1.LabelSelector{
+						MatchLabels: map[string]string{
+							"app.kubernetes.io/name": spotInterruptionAppName,
+						},
+					},
+					Template: v1.PodTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{
+							Labels: map[string]string{
+								"app.kubernetes.io/name": spotInterruptionAppName,
+							},
+						},
+						Spec: v1.PodSpec{
+							RestartPolicy: v1.RestartPolicyAlways,
+							Containers: []v1.Container{
+								{
+									Name:            spotInterruptionAppName,
+									Image:           spotInterruptionAppImageName,
+									ImagePullPolicy: v1.PullIfNotPresent,
+									Command: []string{
+										"/bin/sh",
+										"-c",
+										"while true; do sleep 3600; done",
+									},
+								},
+							},
+							NodeSelector: ts.nodeSelector(),
+							// Spot node groups are often tainted to keep other workloads off
+							Tolerations: []v1.Toleration{
+								{Operator: v1.TolerationOpExists},
+							},
+						},
+					},
+				},
+			},
+			metav1.CreateOptions{},
+		)
+	cancel()
+	if err != nil {
+		return fmt.Errorf("failed to create Spot interruption Deployment (%v)", err)
+	}
+
+	ts.cfg.Logger.Info("created Spot interruption Deployment")
+	ts.cfg.EKSConfig.Sync()
+	return nil
+}
+
+func (ts *tester) waitDeployment() (err error) {
+	timeout := 5*time.Minute + time.Duration(ts.cfg.EKSConfig.AddOnSpotInterruption.DeploymentReplicas)*time.Minute
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	_, err = k8s_client.WaitForDeploymentCompletes(
+		ctx,
+		ts.cfg.Logger,
+		ts.cfg.LogWriter,
+		ts.cfg.Stopc,
+		ts.cfg.K8SClient,
+		30*time.Second,
+		20*time.Second,
+		ts.cfg.EKSConfig.AddOnSpotInterruption.Namespace,
+		spotInterruptionDeploymentName,
+		ts.cfg.EKSConfig.AddOnSpotInterruption.DeploymentReplicas,
+	)
+	cancel()
+	return err
+}
+
+func (ts *tester) listPods() ([]v1.Pod, error) {
+	return k8s_client.ListPodsWithOptions(
+		ts.cfg.K8SClient.KubernetesClientSet(),
+		ts.cfg.EKSConfig.AddOnSpotInterruption.Namespace,
+		metav1.ListOptions{LabelSelector: "app.kubernetes.io/name=" + spotInterruptionAppName},
+	)
+}
+
+func (ts *tester) listNodes() ([]v1.Node, error) {
+	return k8s_client.ListNodesWithOptions(
+		ts.cfg.K8SClient.KubernetesClientSet(),
+		metav1.ListOptions{LabelSelector: "NGName=" + ts.cfg.EKSConfig.AddOnSpotInterruption.MNGName},
+	)
+}
+
+// pickNode picks the node running the most test pods, to interrupt.
+func (ts *tester) pickNode() error {
+	pods, err := ts.listPods()
+	if err != nil {
+		return fmt.Errorf("failed to list Spot interruption pods (%v)", err)
+	}
+	nodeName := busiestNode(pods)
+	if nodeName == "" {
+		return errors.New("no Spot interruption pod is scheduled")
+	}
+	nodes, err := ts.listNodes()
+	if err != nil {
+		return fmt.Errorf("failed to list nodes (%v)", err)
+	}
+	ts.nodesBefore = make(map[string]struct{}, len(nodes))
+	for _, node := range nodes {
+		ts.nodesBefore[node.Name] = struct{}{}
+	}
+	for _, node := range nodes {
+		if node.Name != nodeName {
+			continue
+		}
+		instanceID, err := instanceIDFromProviderID(node.Spec.ProviderID)
+		if err != nil {
+			return err
+		}
+		ts.cfg.EKSConfig.AddOnSpotInterruption.InterruptedNodeName = nodeName
+		ts.cfg.EKSConfig.AddOnSpotInterruption.InterruptedInstanceID = instanceID
+		ts.cfg.EKSConfig.Sync()
+		ts.cfg.Logger.Info("picked Spot node to interrupt",
+			zap.String("node-name", nodeName),
+			zap.String("instance-id", instanceID),
+		)
+		return nil
+	}
+	return fmt.Errorf("node %q not found in managed node group %q", nodeName, ts.cfg.EKSConfig.AddOnSpotInterruption.MNGName)
+}
+
+// busiestNode returns the name of the node with the most running pods.
+func busiestNode(pods []v1.Pod) (nodeName string) {
+	cnt := make(map[string]int)
+	for _, pod := range pods {
+		if pod.Spec.NodeName == "" || pod.Status.Phase != v1.PodRunning {
+			continue
+		}
+		cnt[pod.Spec.NodeName]++
+		n := cnt[pod.Spec.NodeName]
+		if n > cnt[nodeName] || (n == cnt[nodeName] && pod.Spec.NodeName < nodeName) {
+			nodeName = pod.Spec.NodeName
+		}
+	}
+	return nodeName
+}
+
+// instanceIDFromProviderID parses the EC2 instance ID from the node provider ID
+// (e.g. "aws:///us-west-2a/i-0123456789abcdef0").
+func instanceIDFromProviderID(providerID string) (string, error) {
+	if !strings.HasPrefix(providerID, "aws://") {
+		return "", fmt.Errorf("unexpected provider ID %q", providerID)
+	}
+	id := providerID[strings.LastIndex(providerID, "/")+1:]
+	if !strings.HasPrefix(id, "i-") {
+		return "", fmt.Errorf("unexpected provider ID %q", providerID)
+	}
+	return id, nil
+}
+
+// terminateInstance terminates the picked instance via its Auto Scaling group,
+// which triggers the managed node group lifecycle hook to drain the node.
+func (ts *tester) terminateInstance() error {
+	instanceID := ts.cfg.EKSConfig.AddOnSpotInterruption.InterruptedInstanceID
+	ts.cfg.Logger.Info("terminating Spot instance in ASG", zap.String("instance-id", instanceID))
+	_, err := ts.cfg.ASGAPI.TerminateInstanceInAutoScalingGroup(&autoscaling.TerminateInstanceInAutoScalingGroupInput{
+		InstanceId:                     aws.String(instanceID),
+		ShouldDecrementDesiredCapacity: aws.Bool(false),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to terminate Spot instance %q (%v)", instanceID, err)
+	}
+	ts.cfg.Logger.Info("terminated Spot instance in ASG", zap.String("instance-id", instanceID))
+	return nil
+}
+
+const fisAssumeRolePolicyDocument = `{
+  "Version": "2012-10-17",
+  "Statement": [
+    {
+      "Effect": "Allow",
+      "Principal": {
+        "Service": "fis.amazonaws.com"
+      },
+      "Action": "sts:AssumeRole"
+    }
+  ]
+}`
+
+const fisRolePolicyDocument = `{
+  "Version": "2012-10-17",
+  "Statement": [
+    {
+      "Effect": "Allow",
+      "Action": [
+        "ec2:DescribeInstances",
+        "ec2:SendSpotInstanceInterruptions"
+      ],
+      "Resource": "*"
+    }
+  ]
+}`
+
+func (ts *tester) createFISRole() error {
+	roleName := ts.cfg.EKSConfig.AddOnSpotInterruption.FISRoleName
+	ts.cfg.Logger.Info("creating FIS role", zap.String("role-name", roleName))
+	input := &iam.CreateRoleInput{
+		RoleName:                 aws.String(roleName),
+		Path:                     aws.String(ts.cfg.EKSConfig.IAMPath),
+		AssumeRolePolicyDocument: aws.String(fisAssumeRolePolicyDocument),
+	}
+	if ts.cfg.EKSConfig.IAMPermissionsBoundaryARN != "" {
+		input.PermissionsBoundary = aws.String(ts.cfg.EKSConfig.IAMPermissionsBoundaryARN)
+	}
+	out, err := ts.cfg.IAMAPI.CreateRole(input)
+	if err != nil {
+		return fmt.Errorf("failed to create FIS role %q (%v)", roleName, err)
+	}
+	ts.cfg.EKSConfig.AddOnSpotInterruption.FISRoleARN = aws.StringValue(out.Role.Arn)
+	ts.cfg.EKSConfig.Sync()
+
+	if _, err = ts.cfg.IAMAPI.PutRolePolicy(&iam.PutRolePolicyInput{
+		RoleName:       aws.String(roleName),
+		PolicyName:     aws.String(roleName + "-policy"),
+		PolicyDocument: aws.String(fisRolePolicyDocument),
+	}); err != nil {
+		return fmt.Errorf("failed to put policy to FIS role %q (%v)", roleName, err)
+	}
+
+	// IAM is eventually consistent; FIS fails to assume the role right after creation
+	ts.cfg.Logger.Info("created FIS role; waiting for IAM propagation", zap.String("role-arn", ts.cfg.EKSConfig.AddOnSpotInterruption.FISRoleARN))
+	select {
+	case <-ts.cfg.Stopc:
+		return errors.New("FIS role creation aborted")
+	case <-time.After(15 * time.Second):
+	}
+	return nil
+}
+
+func (ts *tester) deleteFISRole() error {
+	if ts.cfg.EKSConfig.AddOnSpotInterruption.FISRoleARN == "" {
+		return nil
+	}
+	roleName := ts.cfg.EKSConfig.AddOnSpotInterruption.FISRoleName
+	ts.cfg.Logger.Info("deleting FIS role", zap.String("role-name", roleName))
+	_, err := ts.cfg.IAMAPI.DeleteRolePolicy(&iam.DeleteRolePolicyInput{
+		RoleName:   aws.String(roleName),
+		PolicyName: aws.String(roleName + "-policy"),
+	})
+	if err != nil && !isNotFound(err, iam.ErrCodeNoSuchEntityException) {
+		return fmt.Errorf("failed to delete policy from FIS role %q (%v)", roleName, err)
+	}
+	_, err = ts.cfg.IAMAPI.DeleteRole(&iam.DeleteRoleInput{
+		RoleName: aws.String(roleName),
+	})
+	if err != nil && !isNotFound(err, iam.ErrCodeNoSuchEntityException) {
+		return fmt.Errorf("failed to delete FIS role %q (%v)", roleName, err)
+	}
+	ts.cfg.EKSConfig.Status.DeletedResources[roleName] = "AddOnSpotInterruption.FISRoleName"
+	ts.cfg.EKSConfig.AddOnSpotInterruption.FISRoleARN = ""
+	ts.cfg.EKSConfig.Sync()
+	ts.cfg.Logger.Info("deleted FIS role", zap.String("role-name", roleName))
+	return nil
+}
+
+func (ts *tester) instanceARN() string {
+	return fmt.Sprintf("arn:%s:ec2:%s:%s:instance/%s",
+		ts.cfg.EKSConfig.Partition,
+		ts.cfg.EKSConfig.Region,
+		ts.cfg.EKSConfig.Status.AWSAccountID,
+		ts.cfg.EKSConfig.AddOnSpotInterruption.InterruptedInstanceID,
+	)
+}
+
+func (ts *tester) startFISExperiment() error {
+	ts.cfg.Logger.Info("creating FIS experiment template", zap.String("instance-arn", ts.instanceARN()))
+	tmpl, err := ts.cfg.FISAPI.CreateExperimentTemplate(&fis.CreateExperimentTemplateInput{
+		Description: aws.String("aws-k8s-tester Spot interruption for " + ts.cfg.EKSConfig.Name),
+		RoleArn:     aws.String(ts.cfg.EKSConfig.AddOnSpotInterruption.FISRoleARN),
+		Actions: map[string]*fis.CreateExperimentTemplateActionInput{
+			"interrupt": {
+				ActionId: aws.String(fisActionSpotInterruption),
+				Parameters: map[string]*string{
+					"durationBeforeInterruption": aws.String("PT2M"),
+				},
+				Targets: map[string]*string{
+					"SpotInstances": aws.String("spot-instance"),
+				},
+			},
+		},
+		Targets: map[string]*fis.CreateExperimentTemplateTargetInput{
+			"spot-instance": {
+				ResourceType:  aws.String(fisTargetSpotInstance),
+				ResourceArns:  aws.StringSlice([]string{ts.instanceARN()}),
+				SelectionMode: aws.String("ALL"),
+			},
+		},
+		StopConditions: []*fis.CreateExperimentTemplateStopConditionInput{
+			{Source: aws.String("none")},
+		},
+		Tags: aws.StringMap(ts.cfg.EKSConfig.MergeTags(map[string]string{
+			"Kind": "aws-k8s-tester",
+			"Name": ts.cfg.EKSConfig.Name,
+		})),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create FIS experiment template (%v)", err)
+	}
+	ts.cfg.EKSConfig.AddOnSpotInterruption.FISExperimentTemplateID = aws.StringValue(tmpl.ExperimentTemplate.Id)
+	ts.cfg.EKSConfig.Sync()
+
+	ts.cfg.Logger.Info("starting FIS experiment", zap.String("experiment-template-id", ts.cfg.EKSConfig.AddOnSpotInterruption.FISExperimentTemplateID))
+	out, err := ts.cfg.FISAPI.StartExperiment(&fis.StartExperimentInput{
+		ExperimentTemplateId: tmpl.ExperimentTemplate.Id,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to start FIS experiment (%v)", err)
+	}
+	ts.cfg.EKSConfig.AddOnSpotInterruption.FISExperimentID = aws.StringValue(out.Experiment.Id)
+	ts.cfg.EKSConfig.Sync()
+
+	// the action completes once the interruption notice is sent
+	retryStart := time.Now()
+	for time.Since(retryStart) < 5*time.Minute {
+		select {
+		case <-ts.cfg.Stopc:
+			return errors.New("FIS experiment aborted")
+		case <-time.After(10 * time.Second):
+		}
+		exp, err := ts.cfg.FISAPI.GetExperiment(&fis.GetExperimentInput{
+			Id: out.Experiment.Id,
+		})
+		if err != nil {
+			ts.cfg.Logger.Warn("failed to get FIS experiment", zap.Error(err))
+			continue
+		}
+		status, reason := aws.StringValue(exp.Experiment.State.Status), aws.StringValue(exp.Experiment.State.Reason)
+		ts.cfg.Logger.Info("polled FIS experiment",
+			zap.String("experiment-id", ts.cfg.EKSConfig.AddOnSpotInterruption.FISExperimentID),
+			zap.String("status", status),
+			zap.String("reason", reason),
+		)
+		switch status {
+		case fis.ExperimentStatusCompleted:
+			return nil
+		case fis.ExperimentStatusFailed, fis.ExperimentStatusStopped:
+			return fmt.Errorf("FIS experiment %q %s (%s)", ts.cfg.EKSConfig.AddOnSpotInterruption.FISExperimentID, status, reason)
+		}
+	}
+	return fmt.Errorf("FIS experiment %q did not complete in time", ts.cfg.EKSConfig.AddOnSpotInterruption.FISExperimentID)
+}
+
+func (ts *tester) deleteFISExperimentTemplate() error {
+	if ts.cfg.EKSConfig.AddOnSpotInterruption.FISExperimentTemplateID == "" {
+		return nil
+	}
+	id := ts.cfg.EKSConfig.AddOnSpotInterruption.FISExperimentTemplateID
+	ts.cfg.Logger.Info("deleting FIS experiment template", zap.String("experiment-template-id", id))
+	_, err := ts.cfg.FISAPI.DeleteExperimentTemplate(&fis.DeleteExperimentTemplateInput{
+		Id: aws.String(id),
+	})
+	if err != nil && !isNotFound(err, fis.ErrCodeResourceNotFoundException) {
+		return err
+	}
+	ts.cfg.EKSConfig.Status.DeletedResources[id] = "AddOnSpotInterruption.FISExperimentTemplateID"
+	ts.cfg.EKSConfig.AddOnSpotInterruption.FISExperimentTemplateID = ""
+	ts.cfg.EKSConfig.Sync()
+	ts.cfg.Logger.Info("deleted FIS experiment template", zap.String("experiment-template-id", id))
+	return nil
+}
+
+func isNotFound(err error, code string) bool {
+	aerr, ok := err.(awserr.Error)
+	return ok && aerr.Code() == code
+}
+
+// waitRecovery waits until the interrupted node is drained and replaced
+// by a new Ready node, and all pods are running on the other nodes.
+func (ts *tester) waitRecovery(interruptStart time.Time) error {
+	slo := ts.cfg.EKSConfig.AddOnSpotInterruption.SLO
+	oldNode := ts.cfg.EKSConfig.AddOnSpotInterruption.InterruptedNodeName
+	ts.cfg.Logger.Info("waiting for Spot interruption recovery",
+		zap.String("interrupted-node", oldNode),
+		zap.Duration("slo", slo),
+	)
+
+	ticker := time.NewTicker(15 * time.Second)
+	defer ticker.Stop()
+	for time.Since(interruptStart) < slo {
+		select {
+		case <-ts.cfg.Stopc:
+			return errors.New("Spot interruption recovery wait aborted")
+		case <-ticker.C:
+		}
+
+		nodes, err := ts.listNodes()
+		if err != nil {
+			ts.cfg.Logger.Warn("failed to list nodes", zap.Error(err))
+			continue
+		}
+		drained, replacement := checkNodes(nodes, oldNode, ts.nodesBefore)
+		if !drained || replacement == "" {
+			ts.cfg.Logger.Info("interrupted node not yet replaced",
+				zap.Bool("drained", drained),
+				zap.String("replacement", replacement),
+				zap.Duration("elapsed", time.Since(interruptStart)),
+			)
+			continue
+		}
+
+		pods, err := ts.listPods()
+		if err != nil {
+			ts.cfg.Logger.Warn("failed to list pods", zap.Error(err))
+			continue
+		}
+		ready := countReadyPods(pods, oldNode)
+		if ready < int(ts.cfg.EKSConfig.AddOnSpotInterruption.DeploymentReplicas) {
+			ts.cfg.Logger.Info("pods not yet rescheduled",
+				zap.Int("ready", ready),
+				zap.Int32("replicas", ts.cfg.EKSConfig.AddOnSpotInterruption.DeploymentReplicas),
+				zap.Duration("elapsed", time.Since(interruptStart)),
+			)
+			continue
+		}
+
+		took := time.Since(interruptStart)
+		ts.cfg.EKSConfig.AddOnSpotInterruption.ReplacementNodeName = replacement
+		ts.cfg.EKSConfig.AddOnSpotInterruption.RecoveryDurationString = took.String()
+		ts.cfg.EKSConfig.Sync()
+		ts.cfg.Logger.Info("recovered from Spot interruption",
+			zap.String("interrupted-node", oldNode),
+			zap.String("replacement-node", replacement),
+			zap.String("took", took.String()),
+		)
+		return nil
+	}
+	return fmt.Errorf("failed to recover from Spot interruption of %q within SLO %v", oldNode, slo)
+}
+
+// checkNodes returns true if the interrupted node is gone or cordoned,
+// and the name of a Ready node that was not present before the interruption.
+func checkNodes(nodes []v1.Node, oldNode string, nodesBefore map[string]struct{}) (drained bool, replacement string) {
+	drained = true
+	for _, node := range nodes {
+		if node.Name == oldNode {
+			drained = node.Spec.Unschedulable || node.DeletionTimestamp != nil
+			continue
+		}
+		if _, ok := nodesBefore[node.Name]; ok {
+			continue
+		}
+		if replacement == "" && isNodeReady(node) && !node.Spec.Unschedulable {
+			replacement = node.Name
+		}
+	}
+	return drained, replacement
+}
+
+func isNodeReady(node v1.Node) bool {
+	for _, cond := range node.Status.Conditions {
+		if cond.Type == v1.NodeReady {
+			return cond.Status == v1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// countReadyPods counts the ready pods that are not on the interrupted node.
+func countReadyPods(pods []v1.Pod, oldNode string) (ready int) {
+	for _, pod := range pods {
+		if pod.Spec.NodeName == "" || pod.Spec.NodeName == oldNode || pod.DeletionTimestamp != nil {
+			continue
+		}
+		for _, cond := range pod.Status.Conditions {
+			if cond.Type == v1.PodReady && cond.Status == v1.ConditionTrue {
+				ready++
+				break
+			}
+		}
+	}
+	return ready
+}
@@ -0,0 +1,98 @@
+package spotinterruption
+
+import (
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestInstanceIDFromProviderID(t *testing.T) {
+	tt := []struct {
+		providerID string
+		id         string
+		err        bool
+	}{
+		{"aws:///us-west-2a/i-0123456789abcdef0", "i-0123456789abcdef0", false},
+		{"aws:///us-west-2a/", "", true},
+		{"gce://project/zone/name", "", true},
+		{"", "", true},
+	}
+	for i, tv := range tt {
+		id, err := instanceIDFromProviderID(tv.providerID)
+		if (err != nil) != tv.err {
+			t.Fatalf("#%d: unexpected error %v", i, err)
+		}
+		if id != tv.id {
+			t.Fatalf("#%d: expected %q, got %q", i, tv.id, id)
+		}
+	}
+}
+
+func TestBusiestNode(t *testing.T) {
+	pod := func(node string, phase v1.PodPhase) v1.Pod {
+		return v1.Pod{Spec: v1.PodSpec{NodeName: node}, Status: v1.PodStatus{Phase: phase}}
+	}
+	pods := []v1.Pod{
+		pod("b", v1.PodRunning),
+		pod("a", v1.PodRunning),
+		pod("c", v1.PodPending),
+		pod("c", v1.PodPending),
+		pod("b", v1.PodRunning),
+		pod("", v1.PodPending),
+	}
+	if n := busiestNode(pods); n != "b" {
+		t.Fatalf("expected %q, got %q", "b", n)
+	}
+	if n := busiestNode(pods[:2]); n != "a" {
+		t.Fatalf("expected %q, got %q", "a", n)
+	}
+	if n := busiestNode(nil); n != "" {
+		t.Fatalf("expected empty, got %q", n)
+	}
+}
+
+func TestCheckNodes(t *testing.T) {
+	node := func(name string, ready bool, unschedulable bool) v1.Node {
+		status := v1.ConditionFalse
+		if ready {
+			status = v1.ConditionTrue
+		}
+		return v1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec:       v1.NodeSpec{Unschedulable: unschedulable},
+			Status:     v1.NodeStatus{Conditions: []v1.NodeCondition{{Type: v1.NodeReady, Status: status}}},
+		}
+	}
+	before := map[string]struct{}{"old": {}, "other": {}}
+
+	drained, replacement := checkNodes([]v1.Node{node("old", true, false), node("other", true, false)}, "old", before)
+	if drained || replacement != "" {
+		t.Fatalf("unexpected drained %v, replacement %q", drained, replacement)
+	}
+	drained, replacement = checkNodes([]v1.Node{node("old", true, true), node("other", true, false), node("new", false, false)}, "old", before)
+	if !drained || replacement != "" {
+		t.Fatalf("unexpected drained %v, replacement %q", drained, replacement)
+	}
+	drained, replacement = checkNodes([]v1.Node{node("other", true, false), node("new", true, false)}, "old", before)
+	if !drained || replacement != "new" {
+		t.Fatalf("unexpected drained %v, replacement %q", drained, replacement)
+	}
+}
+
+func TestCountReadyPods(t *testing.T) {
+	pod := func(node string, ready bool) v1.Pod {
+		status := v1.ConditionFalse
+		if ready {
+			status = v1.ConditionTrue
+		}
+		return v1.Pod{
+			Spec:   v1.PodSpec{NodeName: node},
+			Status: v1.PodStatus{Conditions: []v1.PodCondition{{Type: v1.PodReady, Status: status}}},
+		}
+	}
+	pods := []v1.Pod{pod("old", true), pod("other", true), pod("new", true), pod("new", false), pod("", false)}
+	if n := countReadyPods(pods, "old"); n != 2 {
+		t.Fatalf("expected 2, got %d", n)
+	}
+}
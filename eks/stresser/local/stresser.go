@@ -103,61 +103,95 @@ func (ts *tester) Create() (err error) {
 	}
 
 	loader := stresser.New(stresser.Config{
-		Logger:                          ts.cfg.Logger,
-		LogWriter:                       ts.cfg.LogWriter,
-		Stopc:                           ts.cfg.Stopc,
-		S3API:                           ts.cfg.S3API,
-		S3BucketName:                    ts.cfg.EKSConfig.S3.BucketName,
-		Client:                          ts.cfg.K8SClient,
-		ClientTimeout:                   ts.cfg.EKSConfig.ClientTimeout,
-		Deadline:                        time.Now().Add(ts.cfg.EKSConfig.AddOnStresserLocal.Duration),
-		NamespaceWrite:                  ts.cfg.EKSConfig.AddOnStresserLocal.Namespace,
-		NamespacesRead:                  ns,
-		ObjectSize:                      ts.cfg.EKSConfig.AddOnStresserLocal.ObjectSize,
-		ListLimit:                       ts.cfg.EKSConfig.AddOnStresserLocal.ListLimit,
-		RequestsRawWritesJSONPath:       ts.cfg.EKSConfig.AddOnStresserLocal.RequestsRawWritesJSONPath,
-		RequestsRawWritesJSONS3Key:      ts.cfg.EKSConfig.AddOnStresserLocal.RequestsRawWritesJSONS3Key,
-		RequestsSummaryWritesJSONPath:   ts.cfg.EKSConfig.AddOnStresserLocal.RequestsSummaryWritesJSONPath,
-		RequestsSummaryWritesJSONS3Key:  ts.cfg.EKSConfig.AddOnStresserLocal.RequestsSummaryWritesJSONS3Key,
-		RequestsSummaryWritesTablePath:  ts.cfg.EKSConfig.AddOnStresserLocal.RequestsSummaryWritesTablePath,
-		RequestsSummaryWritesTableS3Key: ts.cfg.EKSConfig.AddOnStresserLocal.RequestsSummaryWritesTableS3Key,
-		RequestsRawReadsJSONPath:        ts.cfg.EKSConfig.AddOnStresserLocal.RequestsRawReadsJSONPath,
-		RequestsRawReadsJSONS3Key:       ts.cfg.EKSConfig.AddOnStresserLocal.RequestsRawReadsJSONS3Key,
-		RequestsSummaryReadsJSONPath:    ts.cfg.EKSConfig.AddOnStresserLocal.RequestsSummaryReadsJSONPath,
-		RequestsSummaryReadsJSONS3Key:   ts.cfg.EKSConfig.AddOnStresserLocal.RequestsSummaryReadsJSONS3Key,
-		RequestsSummaryReadsTablePath:   ts.cfg.EKSConfig.AddOnStresserLocal.RequestsSummaryReadsTablePath,
-		RequestsSummaryReadsTableS3Key:  ts.cfg.EKSConfig.AddOnStresserLocal.RequestsSummaryReadsTableS3Key,
+		Logger:                            ts.cfg.Logger,
+		LogWriter:                         ts.cfg.LogWriter,
+		Stopc:                             ts.cfg.Stopc,
+		S3API:                             ts.cfg.S3API,
+		S3BucketName:                      ts.cfg.EKSConfig.S3.BucketName,
+		Client:                            ts.cfg.K8SClient,
+		ClientTimeout:                     ts.cfg.EKSConfig.ClientTimeout,
+		Deadline:                          time.Now().Add(ts.cfg.EKSConfig.AddOnStresserLocal.Duration),
+		NamespaceWrite:                    ts.cfg.EKSConfig.AddOnStresserLocal.Namespace,
+		NamespacesRead:                    ns,
+		ObjectSize:                        ts.cfg.EKSConfig.AddOnStresserLocal.ObjectSize,
+		ListLimit:                         ts.cfg.EKSConfig.AddOnStresserLocal.ListLimit,
+		QPS:                               ts.cfg.EKSConfig.AddOnStresserLocal.QPS,
+		HistogramBackend:                  ts.cfg.EKSConfig.LatencyHistogram.Backend,
+		HDRSignificantFigures:             ts.cfg.EKSConfig.LatencyHistogram.SignificantFigures,
+		ThroughputSampleInterval:          ts.cfg.EKSConfig.AddOnStresserLocal.ThroughputSampleInterval,
+		RequestsRawWritesJSONPath:         ts.cfg.EKSConfig.AddOnStresserLocal.RequestsRawWritesJSONPath,
+		RequestsRawWritesJSONS3Key:        ts.cfg.EKSConfig.AddOnStresserLocal.RequestsRawWritesJSONS3Key,
+		RequestsSummaryWritesJSONPath:     ts.cfg.EKSConfig.AddOnStresserLocal.RequestsSummaryWritesJSONPath,
+		RequestsSummaryWritesJSONS3Key:    ts.cfg.EKSConfig.AddOnStresserLocal.RequestsSummaryWritesJSONS3Key,
+		RequestsSummaryWritesTablePath:    ts.cfg.EKSConfig.AddOnStresserLocal.RequestsSummaryWritesTablePath,
+		RequestsSummaryWritesTableS3Key:   ts.cfg.EKSConfig.AddOnStresserLocal.RequestsSummaryWritesTableS3Key,
+		RequestsRawReadsJSONPath:          ts.cfg.EKSConfig.AddOnStresserLocal.RequestsRawReadsJSONPath,
+		RequestsRawReadsJSONS3Key:         ts.cfg.EKSConfig.AddOnStresserLocal.RequestsRawReadsJSONS3Key,
+		RequestsSummaryReadsJSONPath:      ts.cfg.EKSConfig.AddOnStresserLocal.RequestsSummaryReadsJSONPath,
+		RequestsSummaryReadsJSONS3Key:     ts.cfg.EKSConfig.AddOnStresserLocal.RequestsSummaryReadsJSONS3Key,
+		RequestsSummaryReadsTablePath:     ts.cfg.EKSConfig.AddOnStresserLocal.RequestsSummaryReadsTablePath,
+		RequestsSummaryReadsTableS3Key:    ts.cfg.EKSConfig.AddOnStresserLocal.RequestsSummaryReadsTableS3Key,
+		RequestsThroughputWritesJSONPath:  ts.cfg.EKSConfig.AddOnStresserLocal.RequestsThroughputWritesJSONPath,
+		RequestsThroughputWritesJSONS3Key: ts.cfg.EKSConfig.AddOnStresserLocal.RequestsThroughputWritesJSONS3Key,
+		RequestsThroughputReadsJSONPath:   ts.cfg.EKSConfig.AddOnStresserLocal.RequestsThroughputReadsJSONPath,
+		RequestsThroughputReadsJSONS3Key:  ts.cfg.EKSConfig.AddOnStresserLocal.RequestsThroughputReadsJSONS3Key,
 	})
 	loader.Start()
 
+	// check for the load parameters updated by live-reload
+	qps, objectSize, listLimit := ts.cfg.EKSConfig.GetAddOnStresserLocalLoad()
+	loadTicker := time.NewTicker(10 * time.Second)
+	defer loadTicker.Stop()
+	durationTimer := time.NewTimer(ts.cfg.EKSConfig.AddOnStresserLocal.Duration)
+	defer durationTimer.Stop()
+
 	var curWriteLatencies metrics.Durations
 	var curReadLatencies metrics.Durations
-	select {
-	case <-ts.cfg.Stopc:
-		ts.cfg.Logger.Warn("cluster stresser aborted")
-		loader.Stop()
-		curWriteLatencies, ts.cfg.EKSConfig.AddOnStresserLocal.RequestsSummaryWrites, curReadLatencies, ts.cfg.EKSConfig.AddOnStresserLocal.RequestsSummaryReads, err = loader.CollectMetrics()
-		ts.cfg.EKSConfig.Sync()
-		if err != nil {
-			ts.cfg.Logger.Warn("failed to get metrics", zap.Error(err))
-		}
-		return err
-
-	case <-time.After(ts.cfg.EKSConfig.AddOnStresserLocal.Duration):
-		ts.cfg.Logger.Info("completing load testing", zap.Duration("duration", ts.cfg.EKSConfig.AddOnStresserLocal.Duration))
-		loader.Stop()
-		curWriteLatencies, ts.cfg.EKSConfig.AddOnStresserLocal.RequestsSummaryWrites, curReadLatencies, ts.cfg.EKSConfig.AddOnStresserLocal.RequestsSummaryReads, err = loader.CollectMetrics()
-		ts.cfg.EKSConfig.Sync()
-		if err != nil {
-			ts.cfg.Logger.Warn("failed to get metrics", zap.Error(err))
-			return err
-		}
-
+waitLoop:
+	for {
 		select {
+		case <-loadTicker.C:
+			curQPS, curObjectSize, curListLimit := ts.cfg.EKSConfig.GetAddOnStresserLocalLoad()
+			if curQPS != qps {
+				loader.SetQPS(curQPS)
+				qps = curQPS
+			}
+			if curObjectSize != objectSize {
+				loader.SetObjectSize(curObjectSize)
+				objectSize = curObjectSize
+			}
+			if curListLimit != listLimit {
+				loader.SetListLimit(curListLimit)
+				listLimit = curListLimit
+			}
+
 		case <-ts.cfg.Stopc:
 			ts.cfg.Logger.Warn("cluster stresser aborted")
-			return nil
-		case <-time.After(30 * time.Second):
+			loader.Stop()
+			curWriteLatencies, ts.cfg.EKSConfig.AddOnStresserLocal.RequestsSummaryWrites, curReadLatencies, ts.cfg.EKSConfig.AddOnStresserLocal.RequestsSummaryReads, err = loader.CollectMetrics()
+			ts.cfg.EKSConfig.Sync()
+			if err != nil {
+				ts.cfg.Logger.Warn("failed to get metrics", zap.Error(err))
+			}
+			return err
+
+		case <-durationTimer.C:
+			ts.cfg.Logger.Info("completing load testing", zap.Duration("duration", ts.cfg.EKSConfig.AddOnStresserLocal.Duration))
+			loader.Stop()
+			curWriteLatencies, ts.cfg.EKSConfig.AddOnStresserLocal.RequestsSummaryWrites, curReadLatencies, ts.cfg.EKSConfig.AddOnStresserLocal.RequestsSummaryReads, err = loader.CollectMetrics()
+			ts.cfg.EKSConfig.Sync()
+			if err != nil {
+				ts.cfg.Logger.Warn("failed to get metrics", zap.Error(err))
+				return err
+			}
+
+			select {
+			case <-ts.cfg.Stopc:
+				ts.cfg.Logger.Warn("cluster stresser aborted")
+				return nil
+			case <-time.After(30 * time.Second):
+			}
+			break waitLoop
 		}
 	}
 
@@ -253,6 +287,7 @@ func (ts *tester) checkResults(curWriteLatencies metrics.Durations, curReadLaten
 			ts.cfg.EKSConfig.S3.BucketName,
 			ts.cfg.EKSConfig.AddOnStresserLocal.RequestsSummaryWritesCompareJSONS3Key,
 			ts.cfg.EKSConfig.AddOnStresserLocal.RequestsSummaryWritesCompareJSONPath,
+			aws_s3.WithTags(ts.cfg.EKSConfig.Tags),
 		); err != nil {
 			return err
 		}
@@ -266,6 +301,7 @@ func (ts *tester) checkResults(curWriteLatencies metrics.Durations, curReadLaten
 			ts.cfg.EKSConfig.S3.BucketName,
 			ts.cfg.EKSConfig.AddOnStresserLocal.RequestsSummaryWritesCompareTableS3Key,
 			ts.cfg.EKSConfig.AddOnStresserLocal.RequestsSummaryWritesCompareTablePath,
+			aws_s3.WithTags(ts.cfg.EKSConfig.Tags),
 		); err != nil {
 			return err
 		}
@@ -308,6 +344,7 @@ func (ts *tester) checkResults(curWriteLatencies metrics.Durations, curReadLaten
 			ts.cfg.EKSConfig.S3.BucketName,
 			ts.cfg.EKSConfig.AddOnStresserLocal.RequestsRawWritesCompareAllJSONS3Key,
 			ts.cfg.EKSConfig.AddOnStresserLocal.RequestsRawWritesCompareAllJSONPath,
+			aws_s3.WithTags(ts.cfg.EKSConfig.Tags),
 		); err != nil {
 			return err
 		}
@@ -320,6 +357,7 @@ func (ts *tester) checkResults(curWriteLatencies metrics.Durations, curReadLaten
 			ts.cfg.EKSConfig.S3.BucketName,
 			ts.cfg.EKSConfig.AddOnStresserLocal.RequestsRawWritesCompareAllCSVS3Key,
 			ts.cfg.EKSConfig.AddOnStresserLocal.RequestsRawWritesCompareAllCSVPath,
+			aws_s3.WithTags(ts.cfg.EKSConfig.Tags),
 		); err != nil {
 			return err
 		}
@@ -334,6 +372,7 @@ func (ts *tester) checkResults(curWriteLatencies metrics.Durations, curReadLaten
 			ts.cfg.EKSConfig.S3.BucketName,
 			path.Join(ts.cfg.EKSConfig.AddOnStresserLocal.RequestsRawWritesCompareS3Dir, curTS),
 			ts.cfg.EKSConfig.AddOnStresserLocal.RequestsRawWritesJSONPath,
+			aws_s3.WithTags(ts.cfg.EKSConfig.Tags),
 		); err != nil {
 			return err
 		}
@@ -345,6 +384,7 @@ func (ts *tester) checkResults(curWriteLatencies metrics.Durations, curReadLaten
 			ts.cfg.EKSConfig.S3.BucketName,
 			path.Join(ts.cfg.EKSConfig.AddOnStresserLocal.RequestsSummaryWritesCompareS3Dir, curTS),
 			ts.cfg.EKSConfig.AddOnStresserLocal.RequestsSummaryWritesJSONPath,
+			aws_s3.WithTags(ts.cfg.EKSConfig.Tags),
 		); err != nil {
 			return err
 		}
@@ -385,6 +425,7 @@ func (ts *tester) checkResults(curWriteLatencies metrics.Durations, curReadLaten
 			ts.cfg.EKSConfig.S3.BucketName,
 			ts.cfg.EKSConfig.AddOnStresserLocal.RequestsSummaryReadsCompareJSONS3Key,
 			ts.cfg.EKSConfig.AddOnStresserLocal.RequestsSummaryReadsCompareJSONPath,
+			aws_s3.WithTags(ts.cfg.EKSConfig.Tags),
 		); err != nil {
 			return err
 		}
@@ -398,6 +439,7 @@ func (ts *tester) checkResults(curWriteLatencies metrics.Durations, curReadLaten
 			ts.cfg.EKSConfig.S3.BucketName,
 			ts.cfg.EKSConfig.AddOnStresserLocal.RequestsSummaryReadsCompareTableS3Key,
 			ts.cfg.EKSConfig.AddOnStresserLocal.RequestsSummaryReadsCompareTablePath,
+			aws_s3.WithTags(ts.cfg.EKSConfig.Tags),
 		); err != nil {
 			return err
 		}
@@ -440,6 +482,7 @@ func (ts *tester) checkResults(curWriteLatencies metrics.Durations, curReadLaten
 			ts.cfg.EKSConfig.S3.BucketName,
 			ts.cfg.EKSConfig.AddOnStresserLocal.RequestsRawReadsCompareAllJSONS3Key,
 			ts.cfg.EKSConfig.AddOnStresserLocal.RequestsRawReadsCompareAllJSONPath,
+			aws_s3.WithTags(ts.cfg.EKSConfig.Tags),
 		); err != nil {
 			return err
 		}
@@ -452,6 +495,7 @@ func (ts *tester) checkResults(curWriteLatencies metrics.Durations, curReadLaten
 			ts.cfg.EKSConfig.S3.BucketName,
 			ts.cfg.EKSConfig.AddOnStresserLocal.RequestsRawReadsCompareAllCSVS3Key,
 			ts.cfg.EKSConfig.AddOnStresserLocal.RequestsRawReadsCompareAllCSVPath,
+			aws_s3.WithTags(ts.cfg.EKSConfig.Tags),
 		); err != nil {
 			return err
 		}
@@ -465,6 +509,7 @@ func (ts *tester) checkResults(curWriteLatencies metrics.Durations, curReadLaten
 		ts.cfg.EKSConfig.S3.BucketName,
 		path.Join(ts.cfg.EKSConfig.AddOnStresserLocal.RequestsRawReadsCompareS3Dir, curTS),
 		ts.cfg.EKSConfig.AddOnStresserLocal.RequestsRawReadsJSONPath,
+		aws_s3.WithTags(ts.cfg.EKSConfig.Tags),
 	); err != nil {
 		return err
 	}
@@ -474,6 +519,7 @@ func (ts *tester) checkResults(curWriteLatencies metrics.Durations, curReadLaten
 		ts.cfg.EKSConfig.S3.BucketName,
 		path.Join(ts.cfg.EKSConfig.AddOnStresserLocal.RequestsSummaryReadsCompareS3Dir, curTS),
 		ts.cfg.EKSConfig.AddOnStresserLocal.RequestsSummaryReadsJSONPath,
+		aws_s3.WithTags(ts.cfg.EKSConfig.Tags),
 	); err != nil {
 		return err
 	}
@@ -690,7 +690,7 @@ func (ts *tester) createObject() (batchv1.Job, string, error) {
 	// do not specify "kubeconfig", and use in-cluster config via "pkg/k8s-client"
 	// otherwise, error "namespaces is forbidden: User "system:node:ip-192-168-84..."
 	// ref. https://github.com/kubernetes/client-go/blob/master/examples/in-cluster-client-configuration/main.go
-	testerCmd := fmt.Sprintf(`/aws-k8s-tester eks create stresser --partition=%s --region=%s --s3-bucket-name=%s --clients=%d --client-qps=%f --client-burst=%d --client-timeout=%s --object-size=%d --list-limit=%d --duration=%s --namespace-write=%s --namespaces-read=%s --requests-raw-writes-json-s3-dir=%s --requests-summary-writes-json-s3-dir=%s --requests-summary-writes-table-s3-dir=%s --requests-raw-reads-json-s3-dir=%s --requests-summary-reads-json-s3-dir=%s --requests-summary-reads-table-s3-dir=%s --writes-output-name-prefix=%s --reads-output-name-prefix=%s`,
+	testerCmd := fmt.Sprintf(`/aws-k8s-tester eks create stresser --partition=%s --region=%s --s3-bucket-name=%s --clients=%d --client-qps=%f --client-burst=%d --client-timeout=%s --object-size=%d --list-limit=%d --duration=%s --namespace-write=%s --namespaces-read=%s --requests-raw-writes-json-s3-dir=%s --requests-summary-writes-json-s3-dir=%s --requests-summary-writes-table-s3-dir=%s --requests-raw-reads-json-s3-dir=%s --requests-summary-reads-json-s3-dir=%s --requests-summary-reads-table-s3-dir=%s --writes-output-name-prefix=%s --reads-output-name-prefix=%s --histogram-backend=%s --hdr-significant-figures=%d`,
 		ts.cfg.EKSConfig.Partition,
 		ts.cfg.EKSConfig.Region,
 		ts.cfg.EKSConfig.S3.BucketName,
@@ -711,6 +711,8 @@ func (ts *tester) createObject() (batchv1.Job, string, error) {
 		path.Dir(ts.cfg.EKSConfig.AddOnStresserRemote.RequestsSummaryReadsTableS3Key),
 		ts.cfg.EKSConfig.AddOnStresserRemote.RequestsSummaryWritesOutputNamePrefix,
 		ts.cfg.EKSConfig.AddOnStresserRemote.RequestsSummaryReadsOutputNamePrefix,
+		ts.cfg.EKSConfig.LatencyHistogram.Backend,
+		ts.cfg.EKSConfig.LatencyHistogram.SignificantFigures,
 	)
 
 	dirOrCreate := v1.HostPathDirectoryOrCreate
@@ -787,6 +789,8 @@ func (ts *tester) createObject() (batchv1.Job, string, error) {
 			NodeSelector: map[string]string{
 				// do not deploy in fake nodes, obviously
 				"NodeType": "regular",
+				// the tester image is built for a single architecture
+				"kubernetes.io/arch": ts.cfg.EKSConfig.AddOnStresserRemote.RepositoryImageArch,
 			},
 		},
 	}
@@ -905,6 +909,7 @@ func (ts *tester) checkResults() (err error) {
 			zap.String("s3-dir", path.Dir(ts.cfg.EKSConfig.AddOnStresserRemote.RequestsSummaryWritesJSONS3Key)),
 		)
 		cnt := 0
+		var writesWorkerSummaries []metrics.RequestsSummary
 		err = filepath.Walk(writesDirSummary, func(fpath string, info os.FileInfo, werr error) error {
 			if werr != nil {
 				return werr
@@ -919,23 +924,21 @@ func (ts *tester) checkResults() (err error) {
 				if err != nil {
 					return fmt.Errorf("failed to open %q (%v)", fpath, err)
 				}
-				var r metrics.RequestsSummary
-				if err = json.Unmarshal(b, &r); err != nil {
-					return fmt.Errorf("failed to unmarshal %q (%s, %v)", fpath, string(b), err)
-				}
-				writesSummary.SuccessTotal += r.SuccessTotal
-				writesSummary.FailureTotal += r.FailureTotal
-				if writesSummary.LatencyHistogram == nil || len(writesSummary.LatencyHistogram) == 0 {
-					writesSummary.LatencyHistogram = r.LatencyHistogram
-				} else {
-					writesSummary.LatencyHistogram, err = metrics.MergeHistograms(writesSummary.LatencyHistogram, r.LatencyHistogram)
-					if err != nil {
-						return fmt.Errorf("failed to merge histograms (%v)", err)
-					}
+				r, err := metrics.ParseRequestsSummary(b)
+				if err != nil {
+					return fmt.Errorf("failed to parse %q (%s, %v)", fpath, string(b), err)
 				}
+				writesWorkerSummaries = append(writesWorkerSummaries, r)
 			}
 			return nil
 		})
+		if err == nil && cnt > 0 {
+			// merge all worker histograms at once, with the buckets aligned
+			writesSummary, err = metrics.CombineRequestsSummaries(append([]metrics.RequestsSummary{writesSummary}, writesWorkerSummaries...)...)
+			if err != nil {
+				err = fmt.Errorf("failed to combine requests summaries (%v)", err)
+			}
+		}
 		if err != nil || cnt == 0 {
 			ts.cfg.Logger.Warn("failed to read writes results", zap.Int("file-count", cnt), zap.Error(err))
 			os.RemoveAll(writesDirSummary)
@@ -995,6 +998,7 @@ func (ts *tester) checkResults() (err error) {
 			zap.String("s3-dir", path.Dir(ts.cfg.EKSConfig.AddOnStresserRemote.RequestsSummaryReadsJSONS3Key)),
 		)
 		cnt := 0
+		var readsWorkerSummaries []metrics.RequestsSummary
 		err = filepath.Walk(readsDirSummary, func(fpath string, info os.FileInfo, werr error) error {
 			if werr != nil {
 				return werr
@@ -1009,23 +1013,21 @@ func (ts *tester) checkResults() (err error) {
 				if err != nil {
 					return fmt.Errorf("failed to open %q (%v)", fpath, err)
 				}
-				var r metrics.RequestsSummary
-				if err = json.Unmarshal(b, &r); err != nil {
-					return fmt.Errorf("failed to unmarshal %q (%s, %v)", fpath, string(b), err)
-				}
-				readsSummary.SuccessTotal += r.SuccessTotal
-				readsSummary.FailureTotal += r.FailureTotal
-				if readsSummary.LatencyHistogram == nil || len(readsSummary.LatencyHistogram) == 0 {
-					readsSummary.LatencyHistogram = r.LatencyHistogram
-				} else {
-					readsSummary.LatencyHistogram, err = metrics.MergeHistograms(readsSummary.LatencyHistogram, r.LatencyHistogram)
-					if err != nil {
-						return fmt.Errorf("failed to merge histograms (%v)", err)
-					}
+				r, err := metrics.ParseRequestsSummary(b)
+				if err != nil {
+					return fmt.Errorf("failed to parse %q (%s, %v)", fpath, string(b), err)
 				}
+				readsWorkerSummaries = append(readsWorkerSummaries, r)
 			}
 			return nil
 		})
+		if err == nil && cnt > 0 {
+			// merge all worker histograms at once, with the buckets aligned
+			readsSummary, err = metrics.CombineRequestsSummaries(append([]metrics.RequestsSummary{readsSummary}, readsWorkerSummaries...)...)
+			if err != nil {
+				err = fmt.Errorf("failed to combine requests summaries (%v)", err)
+			}
+		}
 		if err != nil || cnt == 0 {
 			ts.cfg.Logger.Warn("failed to read reads results", zap.Int("file-count", cnt), zap.Error(err))
 			os.RemoveAll(readsDirSummary)
@@ -1039,6 +1041,7 @@ func (ts *tester) checkResults() (err error) {
 	ts.cfg.Logger.Info("sorted write latencies", zap.String("took", time.Since(sortStart).String()))
 	writesSummary.LantencyP50 = curWriteLatencies.PickLantencyP50()
 	writesSummary.LantencyP90 = curWriteLatencies.PickLantencyP90()
+	writesSummary.LantencyP95 = curWriteLatencies.PickLantencyP95()
 	writesSummary.LantencyP99 = curWriteLatencies.PickLantencyP99()
 	writesSummary.LantencyP999 = curWriteLatencies.PickLantencyP999()
 	writesSummary.LantencyP9999 = curWriteLatencies.PickLantencyP9999()
@@ -1051,6 +1054,7 @@ func (ts *tester) checkResults() (err error) {
 	ts.cfg.Logger.Info("sorted read latencies", zap.String("took", time.Since(sortStart).String()))
 	readsSummary.LantencyP50 = curReadLatencies.PickLantencyP50()
 	readsSummary.LantencyP90 = curReadLatencies.PickLantencyP90()
+	readsSummary.LantencyP95 = curReadLatencies.PickLantencyP95()
 	readsSummary.LantencyP99 = curReadLatencies.PickLantencyP99()
 	readsSummary.LantencyP999 = curReadLatencies.PickLantencyP999()
 	readsSummary.LantencyP9999 = curReadLatencies.PickLantencyP9999()
@@ -1072,6 +1076,7 @@ func (ts *tester) checkResults() (err error) {
 		ts.cfg.EKSConfig.S3.BucketName,
 		ts.cfg.EKSConfig.AddOnStresserRemote.RequestsRawWritesJSONS3Key,
 		ts.cfg.EKSConfig.AddOnStresserRemote.RequestsRawWritesJSONPath,
+		aws_s3.WithTags(ts.cfg.EKSConfig.Tags),
 	); err != nil {
 		return err
 	}
@@ -1085,6 +1090,7 @@ func (ts *tester) checkResults() (err error) {
 		ts.cfg.EKSConfig.S3.BucketName,
 		ts.cfg.EKSConfig.AddOnStresserRemote.RequestsSummaryWritesJSONS3Key,
 		ts.cfg.EKSConfig.AddOnStresserRemote.RequestsSummaryWritesJSONPath,
+		aws_s3.WithTags(ts.cfg.EKSConfig.Tags),
 	); err != nil {
 		return err
 	}
@@ -1098,6 +1104,7 @@ func (ts *tester) checkResults() (err error) {
 		ts.cfg.EKSConfig.S3.BucketName,
 		ts.cfg.EKSConfig.AddOnStresserRemote.RequestsSummaryWritesTableS3Key,
 		ts.cfg.EKSConfig.AddOnStresserRemote.RequestsSummaryWritesTablePath,
+		aws_s3.WithTags(ts.cfg.EKSConfig.Tags),
 	); err != nil {
 		return err
 	}
@@ -1118,6 +1125,7 @@ func (ts *tester) checkResults() (err error) {
 		ts.cfg.EKSConfig.S3.BucketName,
 		ts.cfg.EKSConfig.AddOnStresserRemote.RequestsRawReadsJSONS3Key,
 		ts.cfg.EKSConfig.AddOnStresserRemote.RequestsRawReadsJSONPath,
+		aws_s3.WithTags(ts.cfg.EKSConfig.Tags),
 	); err != nil {
 		return err
 	}
@@ -1131,6 +1139,7 @@ func (ts *tester) checkResults() (err error) {
 		ts.cfg.EKSConfig.S3.BucketName,
 		ts.cfg.EKSConfig.AddOnStresserRemote.RequestsSummaryReadsJSONS3Key,
 		ts.cfg.EKSConfig.AddOnStresserRemote.RequestsSummaryReadsJSONPath,
+		aws_s3.WithTags(ts.cfg.EKSConfig.Tags),
 	); err != nil {
 		return err
 	}
@@ -1144,6 +1153,7 @@ func (ts *tester) checkResults() (err error) {
 		ts.cfg.EKSConfig.S3.BucketName,
 		ts.cfg.EKSConfig.AddOnStresserRemote.RequestsSummaryReadsTableS3Key,
 		ts.cfg.EKSConfig.AddOnStresserRemote.RequestsSummaryReadsTablePath,
+		aws_s3.WithTags(ts.cfg.EKSConfig.Tags),
 	); err != nil {
 		return err
 	}
@@ -1184,6 +1194,7 @@ func (ts *tester) checkResults() (err error) {
 			ts.cfg.EKSConfig.S3.BucketName,
 			ts.cfg.EKSConfig.AddOnStresserRemote.RequestsSummaryWritesCompareJSONS3Key,
 			ts.cfg.EKSConfig.AddOnStresserRemote.RequestsSummaryWritesCompareJSONPath,
+			aws_s3.WithTags(ts.cfg.EKSConfig.Tags),
 		); err != nil {
 			return err
 		}
@@ -1197,6 +1208,7 @@ func (ts *tester) checkResults() (err error) {
 			ts.cfg.EKSConfig.S3.BucketName,
 			ts.cfg.EKSConfig.AddOnStresserRemote.RequestsSummaryWritesCompareTableS3Key,
 			ts.cfg.EKSConfig.AddOnStresserRemote.RequestsSummaryWritesCompareTablePath,
+			aws_s3.WithTags(ts.cfg.EKSConfig.Tags),
 		); err != nil {
 			return err
 		}
@@ -1239,6 +1251,7 @@ func (ts *tester) checkResults() (err error) {
 			ts.cfg.EKSConfig.S3.BucketName,
 			ts.cfg.EKSConfig.AddOnStresserRemote.RequestsRawWritesCompareAllJSONS3Key,
 			ts.cfg.EKSConfig.AddOnStresserRemote.RequestsRawWritesCompareAllJSONPath,
+			aws_s3.WithTags(ts.cfg.EKSConfig.Tags),
 		); err != nil {
 			return err
 		}
@@ -1251,6 +1264,7 @@ func (ts *tester) checkResults() (err error) {
 			ts.cfg.EKSConfig.S3.BucketName,
 			ts.cfg.EKSConfig.AddOnStresserRemote.RequestsRawWritesCompareAllCSVS3Key,
 			ts.cfg.EKSConfig.AddOnStresserRemote.RequestsRawWritesCompareAllCSVPath,
+			aws_s3.WithTags(ts.cfg.EKSConfig.Tags),
 		); err != nil {
 			return err
 		}
@@ -1265,6 +1279,7 @@ func (ts *tester) checkResults() (err error) {
 			ts.cfg.EKSConfig.S3.BucketName,
 			path.Join(ts.cfg.EKSConfig.AddOnStresserRemote.RequestsRawWritesCompareS3Dir, curTS),
 			ts.cfg.EKSConfig.AddOnStresserRemote.RequestsRawWritesJSONPath,
+			aws_s3.WithTags(ts.cfg.EKSConfig.Tags),
 		); err != nil {
 			return err
 		}
@@ -1276,6 +1291,7 @@ func (ts *tester) checkResults() (err error) {
 			ts.cfg.EKSConfig.S3.BucketName,
 			path.Join(ts.cfg.EKSConfig.AddOnStresserRemote.RequestsSummaryWritesCompareS3Dir, curTS),
 			ts.cfg.EKSConfig.AddOnStresserRemote.RequestsSummaryWritesJSONPath,
+			aws_s3.WithTags(ts.cfg.EKSConfig.Tags),
 		); err != nil {
 			return err
 		}
@@ -1316,6 +1332,7 @@ func (ts *tester) checkResults() (err error) {
 			ts.cfg.EKSConfig.S3.BucketName,
 			ts.cfg.EKSConfig.AddOnStresserRemote.RequestsSummaryReadsCompareJSONS3Key,
 			ts.cfg.EKSConfig.AddOnStresserRemote.RequestsSummaryReadsCompareJSONPath,
+			aws_s3.WithTags(ts.cfg.EKSConfig.Tags),
 		); err != nil {
 			return err
 		}
@@ -1329,6 +1346,7 @@ func (ts *tester) checkResults() (err error) {
 			ts.cfg.EKSConfig.S3.BucketName,
 			ts.cfg.EKSConfig.AddOnStresserRemote.RequestsSummaryReadsCompareTableS3Key,
 			ts.cfg.EKSConfig.AddOnStresserRemote.RequestsSummaryReadsCompareTablePath,
+			aws_s3.WithTags(ts.cfg.EKSConfig.Tags),
 		); err != nil {
 			return err
 		}
@@ -1371,6 +1389,7 @@ func (ts *tester) checkResults() (err error) {
 			ts.cfg.EKSConfig.S3.BucketName,
 			ts.cfg.EKSConfig.AddOnStresserRemote.RequestsRawReadsCompareAllJSONS3Key,
 			ts.cfg.EKSConfig.AddOnStresserRemote.RequestsRawReadsCompareAllJSONPath,
+			aws_s3.WithTags(ts.cfg.EKSConfig.Tags),
 		); err != nil {
 			return err
 		}
@@ -1383,6 +1402,7 @@ func (ts *tester) checkResults() (err error) {
 			ts.cfg.EKSConfig.S3.BucketName,
 			ts.cfg.EKSConfig.AddOnStresserRemote.RequestsRawReadsCompareAllCSVS3Key,
 			ts.cfg.EKSConfig.AddOnStresserRemote.RequestsRawReadsCompareAllCSVPath,
+			aws_s3.WithTags(ts.cfg.EKSConfig.Tags),
 		); err != nil {
 			return err
 		}
@@ -1396,6 +1416,7 @@ func (ts *tester) checkResults() (err error) {
 		ts.cfg.EKSConfig.S3.BucketName,
 		path.Join(ts.cfg.EKSConfig.AddOnStresserRemote.RequestsRawReadsCompareS3Dir, curTS),
 		ts.cfg.EKSConfig.AddOnStresserRemote.RequestsRawReadsJSONPath,
+		aws_s3.WithTags(ts.cfg.EKSConfig.Tags),
 	); err != nil {
 		return err
 	}
@@ -1405,6 +1426,7 @@ func (ts *tester) checkResults() (err error) {
 		ts.cfg.EKSConfig.S3.BucketName,
 		path.Join(ts.cfg.EKSConfig.AddOnStresserRemote.RequestsSummaryReadsCompareS3Dir, curTS),
 		ts.cfg.EKSConfig.AddOnStresserRemote.RequestsSummaryReadsJSONPath,
+		aws_s3.WithTags(ts.cfg.EKSConfig.Tags),
 	); err != nil {
 		return err
 	}
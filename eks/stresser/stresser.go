@@ -19,6 +19,7 @@ import (
 	"github.com/aws/aws-sdk-go/service/s3/s3iface"
 	"github.com/prometheus/client_golang/prometheus"
 	"go.uber.org/zap"
+	"golang.org/x/time/rate"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
@@ -107,6 +108,18 @@ type Config struct {
 
 	ObjectSize int
 	ListLimit  int64
+	// QPS is the maximum number of write and read requests per second.
+	// 0 for no limit.
+	QPS float64
+
+	// HistogramBackend is the latency histogram backend of the summaries
+	// (e.g. "fixed", "hdr"). Defaults to the fixed Prometheus histogram buckets.
+	HistogramBackend string
+	// HDRSignificantFigures is the significant figures of the "hdr" backend.
+	HDRSignificantFigures int
+	// ThroughputSampleInterval is the interval to sample the throughput.
+	// 0 to disable.
+	ThroughputSampleInterval time.Duration
 
 	RequestsRawWritesJSONPath       string
 	RequestsRawWritesJSONS3Key      string
@@ -121,6 +134,11 @@ type Config struct {
 	RequestsSummaryReadsJSONS3Key  string
 	RequestsSummaryReadsTablePath  string
 	RequestsSummaryReadsTableS3Key string
+
+	RequestsThroughputWritesJSONPath  string
+	RequestsThroughputWritesJSONS3Key string
+	RequestsThroughputReadsJSONPath   string
+	RequestsThroughputReadsJSONS3Key  string
 }
 
 // Loader defines cluster loader operations.
@@ -128,6 +146,15 @@ type Loader interface {
 	Start()
 	Stop()
 	CollectMetrics() (writeLatencies metrics.Durations, writesSummary metrics.RequestsSummary, readLatencies metrics.Durations, readsSummary metrics.RequestsSummary, err error)
+
+	// SetQPS updates the maximum number of requests per second while running.
+	// 0 for no limit.
+	SetQPS(qps float64)
+	// SetObjectSize updates the value size in bytes for write objects while running.
+	// Writes are not started if the object size was 0 on "Start".
+	SetObjectSize(n int)
+	// SetListLimit updates the maximum number of items in the list call while running.
+	SetListLimit(n int64)
 }
 
 type loader struct {
@@ -137,30 +164,110 @@ type loader struct {
 
 	writeLatencies chan metrics.Durations
 	readLatencies  chan metrics.Durations
+
+	writeThroughput *metrics.ThroughputRecorder
+	readThroughput  *metrics.ThroughputRecorder
+
+	// wall-clock duration of the load, from "Start" to "Stop"
+	start time.Time
+	took  time.Duration
+
+	limiter *rate.Limiter
+
+	mu         sync.RWMutex
+	objectSize int
+	listLimit  int64
 }
 
 func New(cfg Config) Loader {
 	return &loader{
-		cfg:            cfg,
-		donec:          make(chan struct{}),
-		donecCloseOnce: new(sync.Once),
-		writeLatencies: make(chan metrics.Durations, 1), // buffer to not block send
-		readLatencies:  make(chan metrics.Durations, 1), // buffer to not block send
+		cfg:             cfg,
+		donec:           make(chan struct{}),
+		donecCloseOnce:  new(sync.Once),
+		writeLatencies:  make(chan metrics.Durations, 1), // buffer to not block send
+		readLatencies:   make(chan metrics.Durations, 1), // buffer to not block send
+		writeThroughput: metrics.NewThroughputRecorder(cfg.ThroughputSampleInterval),
+		readThroughput:  metrics.NewThroughputRecorder(cfg.ThroughputSampleInterval),
+		limiter:         rate.NewLimiter(qpsToLimit(cfg.QPS), 1),
+		objectSize:      cfg.ObjectSize,
+		listLimit:       cfg.ListLimit,
+	}
+}
+
+func qpsToLimit(qps float64) rate.Limit {
+	if qps <= 0 {
+		return rate.Inf
+	}
+	return rate.Limit(qps)
+}
+
+func (ld *loader) SetQPS(qps float64) {
+	ld.cfg.Logger.Info("updating QPS", zap.Float64("qps", qps))
+	ld.limiter.SetLimit(qpsToLimit(qps))
+}
+
+func (ld *loader) SetObjectSize(n int) {
+	ld.cfg.Logger.Info("updating object size", zap.Int("object-size", n))
+	ld.mu.Lock()
+	ld.objectSize = n
+	ld.mu.Unlock()
+}
+
+func (ld *loader) SetListLimit(n int64) {
+	ld.cfg.Logger.Info("updating list limit", zap.Int64("list-limit", n))
+	ld.mu.Lock()
+	ld.listLimit = n
+	ld.mu.Unlock()
+}
+
+func (ld *loader) getObjectSize() int {
+	ld.mu.RLock()
+	defer ld.mu.RUnlock()
+	return ld.objectSize
+}
+
+func (ld *loader) getListLimit() int64 {
+	ld.mu.RLock()
+	defer ld.mu.RUnlock()
+	return ld.listLimit
+}
+
+// waitLimiter blocks until the next request is allowed,
+// and returns false if stopped while waiting.
+func waitLimiter(limiter *rate.Limiter, stopc chan struct{}, donec chan struct{}) bool {
+	rv := limiter.Reserve()
+	d := rv.Delay()
+	if d == 0 {
+		return true
+	}
+	select {
+	case <-stopc:
+		rv.Cancel()
+		return false
+	case <-donec:
+		rv.Cancel()
+		return false
+	case <-time.After(d):
+		return true
 	}
 }
 
 func (ld *loader) Start() {
 	ld.cfg.Logger.Info("starting load functions", zap.String("namespace-write", ld.cfg.NamespaceWrite), zap.Strings("namespaces-read", ld.cfg.NamespacesRead))
+	ld.start = time.Now()
 	if ld.cfg.ObjectSize > 0 {
+		ld.writeThroughput.Start()
 		go startWrites(
 			ld.cfg.Logger,
 			ld.cfg.Client.KubernetesClientSet(),
 			ld.cfg.ClientTimeout,
 			ld.cfg.Deadline,
 			ld.cfg.NamespaceWrite,
-			ld.cfg.ObjectSize,
+			ld.getObjectSize,
+			ld.limiter,
 			ld.cfg.Stopc,
 			ld.donec,
+			ld.writeThroughput,
 			ld.writeLatencies,
 		)
 	}
@@ -170,11 +277,14 @@ func (ld *loader) Start() {
 		ld.cfg.ClientTimeout,
 		ld.cfg.Deadline,
 		ld.cfg.NamespacesRead,
-		ld.cfg.ListLimit,
+		ld.getListLimit,
+		ld.limiter,
 		ld.cfg.Stopc,
 		ld.donec,
+		ld.readThroughput,
 		ld.readLatencies,
 	)
+	ld.readThroughput.Start()
 	ld.cfg.Logger.Info("started load functions", zap.String("namespace-write", ld.cfg.NamespaceWrite), zap.Strings("namespaces-read", ld.cfg.NamespacesRead))
 }
 
@@ -183,7 +293,10 @@ func (ld *loader) Stop() {
 	ld.donecCloseOnce.Do(func() {
 		close(ld.donec)
 	})
+	ld.took = time.Since(ld.start)
 	time.Sleep(5 * time.Second) // enough time to stop goroutines
+	ld.writeThroughput.Stop()
+	ld.readThroughput.Stop()
 	ld.cfg.Logger.Info("stopped and waited for load functions")
 }
 
@@ -191,8 +304,8 @@ func (ld *loader) Stop() {
 // ref. https://pkg.go.dev/github.com/prometheus/client_golang@v1.6.0/prometheus/promhttp?tab=doc#Handler
 func (ts *loader) CollectMetrics() (writeLatencies metrics.Durations, writesSummary metrics.RequestsSummary, readLatencies metrics.Durations, readsSummary metrics.RequestsSummary, err error) {
 	curTS := time.Now().UTC().Format(time.RFC3339Nano)
-	writesSummary = metrics.RequestsSummary{TestID: curTS}
-	readsSummary = metrics.RequestsSummary{TestID: curTS}
+	writesSummary = metrics.RequestsSummary{TestID: curTS, TotalDuration: ts.took}
+	readsSummary = metrics.RequestsSummary{TestID: curTS, TotalDuration: ts.took}
 
 	// https://pkg.go.dev/github.com/prometheus/client_golang/prometheus?tab=doc#Gatherer
 	mfs, err := prometheus.DefaultGatherer.Gather()
@@ -212,7 +325,7 @@ func (ts *loader) CollectMetrics() (writeLatencies metrics.Durations, writesSumm
 			gg := mf.Metric[0].GetGauge()
 			writesSummary.FailureTotal = gg.GetValue()
 		case "stresser_client_write_request_latency_milliseconds":
-			writesSummary.LatencyHistogram, err = metrics.ParseHistogram("milliseconds", mf.Metric[0].GetHistogram())
+			writesSummary.LatencyHistogram, err = metrics.ParseHistogram(metrics.ScaleMilliseconds, mf.Metric[0].GetHistogram())
 			if err != nil {
 				return nil, metrics.RequestsSummary{}, nil, metrics.RequestsSummary{}, err
 			}
@@ -224,7 +337,7 @@ func (ts *loader) CollectMetrics() (writeLatencies metrics.Durations, writesSumm
 			gg := mf.Metric[0].GetGauge()
 			readsSummary.FailureTotal = gg.GetValue()
 		case "stresser_client_read_request_latency_milliseconds":
-			readsSummary.LatencyHistogram, err = metrics.ParseHistogram("milliseconds", mf.Metric[0].GetHistogram())
+			readsSummary.LatencyHistogram, err = metrics.ParseHistogram(metrics.ScaleMilliseconds, mf.Metric[0].GetHistogram())
 			if err != nil {
 				return nil, metrics.RequestsSummary{}, nil, metrics.RequestsSummary{}, err
 			}
@@ -240,9 +353,17 @@ func (ts *loader) CollectMetrics() (writeLatencies metrics.Durations, writesSumm
 		ts.cfg.Logger.Info("sorted write latency results", zap.Int("total-data-points", writeLatencies.Len()), zap.String("took", time.Since(now).String()))
 		writesSummary.LantencyP50 = writeLatencies.PickLantencyP50()
 		writesSummary.LantencyP90 = writeLatencies.PickLantencyP90()
+		writesSummary.LantencyP95 = writeLatencies.PickLantencyP95()
 		writesSummary.LantencyP99 = writeLatencies.PickLantencyP99()
 		writesSummary.LantencyP999 = writeLatencies.PickLantencyP999()
 		writesSummary.LantencyP9999 = writeLatencies.PickLantencyP9999()
+		if ts.cfg.HistogramBackend == metrics.HistogramBackendHDR {
+			writesSummary.LatencyHistogram, err = metrics.HDRHistogramBuckets(ts.cfg.HDRSignificantFigures, writeLatencies)
+			if err != nil {
+				ts.cfg.Logger.Warn("failed to record HDR histogram", zap.Error(err))
+				return nil, metrics.RequestsSummary{}, nil, metrics.RequestsSummary{}, err
+			}
+		}
 
 		ts.cfg.Logger.Info("writing latency results in JSON to disk", zap.String("path", ts.cfg.RequestsRawWritesJSONPath))
 		wb, err := json.Marshal(writeLatencies)
@@ -277,9 +398,17 @@ func (ts *loader) CollectMetrics() (writeLatencies metrics.Durations, writesSumm
 		ts.cfg.Logger.Info("sorted read latency results", zap.Int("total-data-points", readLatencies.Len()), zap.String("took", time.Since(now).String()))
 		readsSummary.LantencyP50 = readLatencies.PickLantencyP50()
 		readsSummary.LantencyP90 = readLatencies.PickLantencyP90()
+		readsSummary.LantencyP95 = readLatencies.PickLantencyP95()
 		readsSummary.LantencyP99 = readLatencies.PickLantencyP99()
 		readsSummary.LantencyP999 = readLatencies.PickLantencyP999()
 		readsSummary.LantencyP9999 = readLatencies.PickLantencyP9999()
+		if ts.cfg.HistogramBackend == metrics.HistogramBackendHDR {
+			readsSummary.LatencyHistogram, err = metrics.HDRHistogramBuckets(ts.cfg.HDRSignificantFigures, readLatencies)
+			if err != nil {
+				ts.cfg.Logger.Warn("failed to record HDR histogram", zap.Error(err))
+				return nil, metrics.RequestsSummary{}, nil, metrics.RequestsSummary{}, err
+			}
+		}
 
 		ts.cfg.Logger.Info("writing latency results in JSON to disk", zap.String("path", ts.cfg.RequestsRawReadsJSONPath))
 		wb, err := json.Marshal(readLatencies)
@@ -361,18 +490,54 @@ func (ts *loader) CollectMetrics() (writeLatencies metrics.Durations, writesSumm
 	}
 	fmt.Fprintf(ts.cfg.LogWriter, "\n\nRequestsSummaryReadsTable:\n%s\n", readsSummary.Table())
 
+	if ts.cfg.ThroughputSampleInterval > 0 {
+		if ts.cfg.ObjectSize > 0 {
+			if err = ts.writeThroughputSeries(ts.writeThroughput.Series(curTS), ts.cfg.RequestsThroughputWritesJSONPath, ts.cfg.RequestsThroughputWritesJSONS3Key); err != nil {
+				return nil, metrics.RequestsSummary{}, nil, metrics.RequestsSummary{}, err
+			}
+		}
+		if err = ts.writeThroughputSeries(ts.readThroughput.Series(curTS), ts.cfg.RequestsThroughputReadsJSONPath, ts.cfg.RequestsThroughputReadsJSONS3Key); err != nil {
+			return nil, metrics.RequestsSummary{}, nil, metrics.RequestsSummary{}, err
+		}
+	}
+
 	return writeLatencies, writesSummary, readLatencies, readsSummary, nil
 }
 
+// writeThroughputSeries writes the throughput time series in JSON to disk,
+// and uploads to S3. It skips if the path is empty.
+func (ts *loader) writeThroughputSeries(series metrics.ThroughputSeries, fpath string, s3Key string) error {
+	if fpath == "" {
+		return nil
+	}
+	ts.cfg.Logger.Info("writing throughput results in JSON to disk", zap.String("path", fpath), zap.Int("samples", len(series.Samples)))
+	if err := ioutil.WriteFile(fpath, []byte(series.JSON()), 0600); err != nil {
+		ts.cfg.Logger.Warn("failed to write file", zap.Error(err))
+		return err
+	}
+	if s3Key == "" {
+		return nil
+	}
+	return aws_s3.Upload(
+		ts.cfg.Logger,
+		ts.cfg.S3API,
+		ts.cfg.S3BucketName,
+		s3Key,
+		fpath,
+	)
+}
+
 func startWrites(
 	lg *zap.Logger,
 	cli *kubernetes.Clientset,
 	timeout time.Duration,
 	deadline time.Time,
 	namespace string,
-	objectSize int,
+	objectSize func() int,
+	limiter *rate.Limiter,
 	stopc chan struct{},
 	donec chan struct{},
+	throughput *metrics.ThroughputRecorder,
 	writeLatencies chan<- metrics.Durations,
 ) {
 	lg.Info("starting writes")
@@ -394,7 +559,7 @@ func startWrites(
 		}
 	}()
 
-	val := randutil.String(objectSize)
+	val := randutil.String(objectSize())
 	cnt := 0
 	for {
 		cnt++
@@ -408,8 +573,18 @@ func startWrites(
 		default:
 		}
 
+		if sz := objectSize(); sz > 0 && sz != len(val) {
+			lg.Info("updating write object size", zap.Int("from", len(val)), zap.Int("to", sz))
+			val = randutil.String(sz)
+		}
+		if !waitLimiter(limiter, stopc, donec) {
+			lg.Info("writes stopped while rate limited")
+			return
+		}
+
 		key := fmt.Sprintf("secret%d%s", cnt, randutil.String(7))
 
+		throughput.Begin()
 		start := time.Now()
 		ctx, cancel := context.WithTimeout(context.Background(), timeout)
 		_, err := cli.
@@ -431,6 +606,7 @@ func startWrites(
 			}, metav1.CreateOptions{})
 		cancel()
 		took := time.Since(start)
+		throughput.Done(err)
 		tookMS := float64(took / time.Millisecond)
 		writeRequestLatencyMs.Observe(tookMS)
 		ds = append(ds, took)
@@ -453,7 +629,12 @@ func startWrites(
 		default:
 		}
 
+		if !waitLimiter(limiter, stopc, donec) {
+			lg.Info("writes stopped while rate limited")
+			return
+		}
 		key = fmt.Sprintf("configmap%d%s", cnt, randutil.String(7))
+		throughput.Begin()
 		start = time.Now()
 		ctx, cancel = context.WithTimeout(context.Background(), timeout)
 		_, err = cli.
@@ -476,6 +657,7 @@ func startWrites(
 			}, metav1.CreateOptions{})
 		cancel()
 		took = time.Since(start)
+		throughput.Done(err)
 		tookMS = float64(took / time.Millisecond)
 		writeRequestLatencyMs.Observe(tookMS)
 		ds = append(ds, took)
@@ -506,9 +688,11 @@ func startReads(
 	timeout time.Duration,
 	deadline time.Time,
 	ns []string,
-	listLimit int64,
+	listLimit func() int64,
+	limiter *rate.Limiter,
 	stopc chan struct{},
 	donec chan struct{},
+	throughput *metrics.ThroughputRecorder,
 	readLatencies chan<- metrics.Durations,
 ) {
 	lg.Info("starting reads", zap.Strings("namespaces", ns))
@@ -543,11 +727,17 @@ func startReads(
 		default:
 		}
 
+		if !waitLimiter(limiter, stopc, donec) {
+			lg.Info("reads stopped while rate limited")
+			return
+		}
+		throughput.Begin()
 		start := time.Now()
 		ctx, cancel := context.WithTimeout(context.Background(), timeout)
-		rs, err := cli.CoreV1().Nodes().List(ctx, metav1.ListOptions{Limit: listLimit})
+		rs, err := cli.CoreV1().Nodes().List(ctx, metav1.ListOptions{Limit: listLimit()})
 		cancel()
 		took := time.Since(start)
+		throughput.Done(err)
 		tookMS := float64(took / time.Millisecond)
 		readRequestLatencyMs.Observe(tookMS)
 		ds = append(ds, took)
@@ -562,11 +752,17 @@ func startReads(
 		}
 
 		for _, nv := range ns {
+			if !waitLimiter(limiter, stopc, donec) {
+				lg.Info("reads stopped while rate limited")
+				return
+			}
+			throughput.Begin()
 			start := time.Now()
 			ctx, cancel := context.WithTimeout(context.Background(), timeout)
-			pods, err := cli.CoreV1().Pods(nv).List(ctx, metav1.ListOptions{Limit: listLimit})
+			pods, err := cli.CoreV1().Pods(nv).List(ctx, metav1.ListOptions{Limit: listLimit()})
 			cancel()
 			took := time.Since(start)
+			throughput.Done(err)
 			tookMS := float64(took / time.Millisecond)
 			readRequestLatencyMs.Observe(tookMS)
 			ds = append(ds, took)
@@ -589,11 +785,17 @@ func startReads(
 			default:
 			}
 
+			if !waitLimiter(limiter, stopc, donec) {
+				lg.Info("reads stopped while rate limited")
+				return
+			}
+			throughput.Begin()
 			start = time.Now()
 			ctx, cancel = context.WithTimeout(context.Background(), timeout)
-			svcs, err := cli.CoreV1().Services(nv).List(ctx, metav1.ListOptions{Limit: listLimit})
+			svcs, err := cli.CoreV1().Services(nv).List(ctx, metav1.ListOptions{Limit: listLimit()})
 			cancel()
 			took = time.Since(start)
+			throughput.Done(err)
 			tookMS = float64(took / time.Millisecond)
 			readRequestLatencyMs.Observe(tookMS)
 			ds = append(ds, took)
@@ -616,11 +818,17 @@ func startReads(
 			default:
 			}
 
+			if !waitLimiter(limiter, stopc, donec) {
+				lg.Info("reads stopped while rate limited")
+				return
+			}
+			throughput.Begin()
 			start = time.Now()
 			ctx, cancel = context.WithTimeout(context.Background(), timeout)
-			eps, err := cli.CoreV1().Endpoints(nv).List(ctx, metav1.ListOptions{Limit: listLimit})
+			eps, err := cli.CoreV1().Endpoints(nv).List(ctx, metav1.ListOptions{Limit: listLimit()})
 			cancel()
 			took = time.Since(start)
+			throughput.Done(err)
 			tookMS = float64(took / time.Millisecond)
 			readRequestLatencyMs.Observe(tookMS)
 			ds = append(ds, took)
@@ -643,11 +851,17 @@ func startReads(
 			default:
 			}
 
+			if !waitLimiter(limiter, stopc, donec) {
+				lg.Info("reads stopped while rate limited")
+				return
+			}
+			throughput.Begin()
 			start = time.Now()
 			ctx, cancel = context.WithTimeout(context.Background(), timeout)
-			cms, err := cli.CoreV1().ConfigMaps(nv).List(ctx, metav1.ListOptions{Limit: listLimit})
+			cms, err := cli.CoreV1().ConfigMaps(nv).List(ctx, metav1.ListOptions{Limit: listLimit()})
 			cancel()
 			took = time.Since(start)
+			throughput.Done(err)
 			tookMS = float64(took / time.Millisecond)
 			readRequestLatencyMs.Observe(tookMS)
 			ds = append(ds, took)
@@ -670,11 +884,17 @@ func startReads(
 			default:
 			}
 
+			if !waitLimiter(limiter, stopc, donec) {
+				lg.Info("reads stopped while rate limited")
+				return
+			}
+			throughput.Begin()
 			start = time.Now()
 			ctx, cancel = context.WithTimeout(context.Background(), timeout)
-			ss, err := cli.CoreV1().Secrets(nv).List(ctx, metav1.ListOptions{Limit: listLimit})
+			ss, err := cli.CoreV1().Secrets(nv).List(ctx, metav1.ListOptions{Limit: listLimit()})
 			cancel()
 			took = time.Since(start)
+			throughput.Done(err)
 			tookMS = float64(took / time.Millisecond)
 			readRequestLatencyMs.Observe(tookMS)
 			ds = append(ds, took)
@@ -697,11 +917,17 @@ func startReads(
 			default:
 			}
 
+			if !waitLimiter(limiter, stopc, donec) {
+				lg.Info("reads stopped while rate limited")
+				return
+			}
+			throughput.Begin()
 			start = time.Now()
 			ctx, cancel = context.WithTimeout(context.Background(), timeout)
-			jobs, err := cli.BatchV1().Jobs(nv).List(ctx, metav1.ListOptions{Limit: listLimit})
+			jobs, err := cli.BatchV1().Jobs(nv).List(ctx, metav1.ListOptions{Limit: listLimit()})
 			cancel()
 			took = time.Since(start)
+			throughput.Done(err)
 			tookMS = float64(took / time.Millisecond)
 			readRequestLatencyMs.Observe(tookMS)
 			ds = append(ds, took)
@@ -724,11 +950,17 @@ func startReads(
 			default:
 			}
 
+			if !waitLimiter(limiter, stopc, donec) {
+				lg.Info("reads stopped while rate limited")
+				return
+			}
+			throughput.Begin()
 			start = time.Now()
 			ctx, cancel = context.WithTimeout(context.Background(), timeout)
-			cjbs, err := cli.BatchV1beta1().CronJobs(nv).List(ctx, metav1.ListOptions{Limit: listLimit})
+			cjbs, err := cli.BatchV1beta1().CronJobs(nv).List(ctx, metav1.ListOptions{Limit: listLimit()})
 			cancel()
 			took = time.Since(start)
+			throughput.Done(err)
 			tookMS = float64(took / time.Millisecond)
 			readRequestLatencyMs.Observe(tookMS)
 			ds = append(ds, took)
@@ -0,0 +1,179 @@
+package eks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	aws_v2 "github.com/aws/aws-sdk-go-v2/aws"
+	aws_asg_v2 "github.com/aws/aws-sdk-go-v2/service/autoscaling"
+	aws_ec2_v2 "github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go/aws"
+	aws_eks "github.com/aws/aws-sdk-go/service/eks"
+	"go.uber.org/zap"
+)
+
+// tagValueExemptKeys are the tag keys set by the tester per resource,
+// which take precedence over "Tags" (e.g. "Name"), so only the key is checked.
+var tagValueExemptKeys = map[string]struct{}{
+	"Name": {},
+}
+
+// checkTags checks that the EKS cluster, the ASGs of each managed node group,
+// their EC2 instances, and the created security groups all carry "Tags",
+// since the tag propagation to the ASGs and instances silently fails
+// (e.g. EKS does not propagate node group tags).
+func (ts *Tester) checkTags() error {
+	if len(ts.cfg.Tags) == 0 {
+		return nil
+	}
+	ts.lg.Info("checking tags", zap.Any("tags", ts.cfg.Tags))
+
+	var errs []string
+	report := func(resource string, want map[string]string, got map[string]string) {
+		if missing := missingTags(want, got); len(missing) > 0 {
+			errs = append(errs, fmt.Sprintf("%s missing %s", resource, strings.Join(missing, ", ")))
+			return
+		}
+		ts.lg.Info("checked tags", zap.String("resource", resource))
+	}
+
+	// attached cluster, role, and VPC are not created by the tester
+	if !ts.cfg.IsEnabledAttach() {
+		out, err := ts.eksAPIForCluster.DescribeCluster(&aws_eks.DescribeClusterInput{
+			Name: aws.String(ts.cfg.Name),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to describe cluster %q (%v)", ts.cfg.Name, err)
+		}
+		report(fmt.Sprintf("EKS cluster %q", ts.cfg.Name), ts.cfg.Tags, aws.StringValueMap(out.Cluster.Tags))
+	}
+
+	sgIDs := make(map[string]map[string]string)
+	if ts.cfg.VPC.Create && ts.cfg.VPC.SecurityGroupID != "" {
+		sgIDs[ts.cfg.VPC.SecurityGroupID] = ts.cfg.Tags
+	}
+
+	if ts.cfg.IsEnabledAddOnManagedNodeGroups() && ts.cfg.AddOnManagedNodeGroups.Created {
+		names := make([]string, 0, len(ts.cfg.AddOnManagedNodeGroups.MNGs))
+		for name := range ts.cfg.AddOnManagedNodeGroups.MNGs {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			cur := ts.cfg.AddOnManagedNodeGroups.MNGs[name]
+			want := ts.cfg.MergeTags(cur.Tags)
+			if cur.RemoteAccessSecurityGroupID != "" {
+				sgIDs[cur.RemoteAccessSecurityGroupID] = want
+			}
+			if cur.ASGName == "" {
+				errs = append(errs, fmt.Sprintf("MNG %q has no ASG", name))
+				continue
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+			out, err := ts.asgAPIV2.DescribeAutoScalingGroups(ctx, &aws_asg_v2.DescribeAutoScalingGroupsInput{
+				AutoScalingGroupNames: []string{cur.ASGName},
+			})
+			cancel()
+			if err != nil {
+				return fmt.Errorf("failed to describe ASG %q (%v)", cur.ASGName, err)
+			}
+			if len(out.AutoScalingGroups) != 1 {
+				errs = append(errs, fmt.Sprintf("ASG %q not found", cur.ASGName))
+				continue
+			}
+			asg := out.AutoScalingGroups[0]
+			got := make(map[string]string, len(asg.Tags))
+			notPropagated := make([]string, 0)
+			for _, tg := range asg.Tags {
+				k := aws_v2.ToString(tg.Key)
+				got[k] = aws_v2.ToString(tg.Value)
+				if _, ok := want[k]; ok && !aws_v2.ToBool(tg.PropagateAtLaunch) {
+					notPropagated = append(notPropagated, k)
+				}
+			}
+			report(fmt.Sprintf("MNG %q ASG %q", name, cur.ASGName), want, got)
+			if len(notPropagated) > 0 {
+				sort.Strings(notPropagated)
+				errs = append(errs, fmt.Sprintf("MNG %q ASG %q not propagating %s at launch", name, cur.ASGName, strings.Join(notPropagated, ", ")))
+			}
+
+			instanceIDs := make([]string, 0, len(asg.Instances))
+			for _, iv := range asg.Instances {
+				instanceIDs = append(instanceIDs, aws_v2.ToString(iv.InstanceId))
+			}
+			if len(instanceIDs) == 0 {
+				continue
+			}
+			ctx, cancel = context.WithTimeout(context.Background(), time.Minute)
+			insts, err := ts.ec2APIV2.DescribeInstances(ctx, &aws_ec2_v2.DescribeInstancesInput{
+				InstanceIds: instanceIDs,
+			})
+			cancel()
+			if err != nil {
+				return fmt.Errorf("failed to describe MNG %q instances (%v)", name, err)
+			}
+			for _, rv := range insts.Reservations {
+				for _, iv := range rv.Instances {
+					got := make(map[string]string, len(iv.Tags))
+					for _, tg := range iv.Tags {
+						got[aws_v2.ToString(tg.Key)] = aws_v2.ToString(tg.Value)
+					}
+					report(fmt.Sprintf("MNG %q instance %q", name, aws_v2.ToString(iv.InstanceId)), want, got)
+				}
+			}
+		}
+	}
+
+	if len(sgIDs) > 0 {
+		ids := make([]string, 0, len(sgIDs))
+		for id := range sgIDs {
+			ids = append(ids, id)
+		}
+		sort.Strings(ids)
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		out, err := ts.ec2APIV2.DescribeSecurityGroups(ctx, &aws_ec2_v2.DescribeSecurityGroupsInput{
+			GroupIds: ids,
+		})
+		cancel()
+		if err != nil {
+			return fmt.Errorf("failed to describe security groups %q (%v)", ids, err)
+		}
+		for _, sg := range out.SecurityGroups {
+			got := make(map[string]string, len(sg.Tags))
+			for _, tg := range sg.Tags {
+				got[aws_v2.ToString(tg.Key)] = aws_v2.ToString(tg.Value)
+			}
+			id := aws_v2.ToString(sg.GroupId)
+			report(fmt.Sprintf("security group %q", id), sgIDs[id], got)
+		}
+	}
+
+	if len(errs) > 0 {
+		return errors.New("tags not propagated: " + strings.Join(errs, "; "))
+	}
+	ts.lg.Info("checked tags")
+	return nil
+}
+
+// missingTags returns the keys of the wanted tags missing in the resource tags
+// in key order, with the values if mismatched (e.g. "Team=a (got b)").
+func missingTags(want map[string]string, got map[string]string) (missing []string) {
+	for k, v := range want {
+		gv, ok := got[k]
+		switch {
+		case !ok:
+			missing = append(missing, k)
+		case gv != v:
+			if _, exempt := tagValueExemptKeys[k]; !exempt {
+				missing = append(missing, fmt.Sprintf("%s=%s (got %s)", k, v, gv))
+			}
+		}
+	}
+	sort.Strings(missing)
+	return missing
+}
@@ -0,0 +1,27 @@
+package eks
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMissingTags(t *testing.T) {
+	tt := []struct {
+		want map[string]string
+		got  map[string]string
+		exp  []string
+	}{
+		{map[string]string{"a": "1"}, map[string]string{"a": "1", "b": "2"}, nil},
+		{map[string]string{"a": "1", "b": "2"}, map[string]string{"a": "1"}, []string{"b"}},
+		{map[string]string{"a": "1", "b": "2"}, nil, []string{"a", "b"}},
+		{map[string]string{"a": "1"}, map[string]string{"a": "2"}, []string{"a=1 (got 2)"}},
+		{map[string]string{"Name": "x"}, map[string]string{"Name": "y"}, nil},
+		{map[string]string{"Name": "x"}, map[string]string{}, []string{"Name"}},
+		{nil, map[string]string{"a": "1"}, nil},
+	}
+	for i, tv := range tt {
+		if v := missingTags(tv.want, tv.got); !reflect.DeepEqual(v, tv.exp) {
+			t.Fatalf("#%d: expected %q, got %q", i, tv.exp, v)
+		}
+	}
+}
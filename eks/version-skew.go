@@ -0,0 +1,210 @@
+package eks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-k8s-tester/eksconfig"
+	k8s_client "github.com/aws/aws-k8s-tester/pkg/k8s-client"
+	k8s_object "github.com/aws/aws-k8s-tester/pkg/k8s-object"
+	"go.uber.org/zap"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// checkVersionSkew checks that the nodes of each managed node group
+// registered with the expected kubelet version, within the supported
+// skew of the current control plane version, and runs the conformance-lite
+// pods if enabled. The result is appended to "VersionSkew.Checks".
+func (ts *Tester) checkVersionSkew(stage string) error {
+	if ts.k8sClient == nil {
+		return errors.New("nil k8s client")
+	}
+	sv, err := ts.k8sClient.FetchServerVersion()
+	if err != nil {
+		return fmt.Errorf("failed to fetch server version (%v)", err)
+	}
+	nodes, err := ts.k8sClient.ListNodes(1000, 5*time.Second)
+	if err != nil {
+		return fmt.Errorf("failed to list nodes (%v)", err)
+	}
+
+	expected := make(map[string]float64, len(ts.cfg.AddOnManagedNodeGroups.MNGs))
+	for name, cur := range ts.cfg.AddOnManagedNodeGroups.MNGs {
+		expected[name] = cur.KubeletVersion(ts.cfg.VersionValue)
+	}
+	kubelets, err := checkKubeletVersions(sv.VersionValue, expected, nodes)
+	ts.cfg.VersionSkew.Checks = append(ts.cfg.VersionSkew.Checks, eksconfig.VersionSkewCheck{
+		Stage:               stage,
+		ControlPlaneVersion: sv.VersionValue,
+		KubeletVersions:     kubelets,
+	})
+	ts.cfg.Sync()
+	ts.lg.Info("checked kubelet versions",
+		zap.String("stage", stage),
+		zap.Float64("control-plane-version", sv.VersionValue),
+		zap.Any("kubelet-versions", kubelets),
+		zap.Error(err),
+	)
+	if err != nil {
+		return err
+	}
+
+	if !ts.cfg.VersionSkew.ConformanceLite {
+		return nil
+	}
+	return ts.runConformanceLite()
+}
+
+// checkKubeletVersions returns the kubelet minor version of each node group,
+// or an error if any node group has no node, a node is not ready,
+// its kubelet version is not the expected one, or the skew with the
+// control plane is not supported.
+func checkKubeletVersions(controlPlaneVersion float64, expected map[string]float64, nodes []v1.Node) (map[string]float64, error) {
+	kubelets := make(map[string]float64, len(expected))
+	var errs []string
+	for _, node := range nodes {
+		name := node.Labels["NGName"]
+		want, ok := expected[name]
+		if !ok {
+			continue
+		}
+		ready := false
+		for _, cond := range node.Status.Conditions {
+			if cond.Type == v1.NodeReady && cond.Status == v1.ConditionTrue {
+				ready = true
+				break
+			}
+		}
+		if !ready {
+			errs = append(errs, fmt.Sprintf("node %q in %q not ready", node.Name, name))
+		}
+		ver := k8s_object.ParseNodeInfo(node.Status.NodeInfo).KubeletMinorVersionValue
+		if prev, ok := kubelets[name]; ok && eksconfig.MinorVersionSkew(prev, ver) != 0 {
+			errs = append(errs, fmt.Sprintf("node %q in %q has kubelet %.2f, other nodes %.2f", node.Name, name, ver, prev))
+		}
+		kubelets[name] = ver
+		if eksconfig.MinorVersionSkew(want, ver) != 0 {
+			errs = append(errs, fmt.Sprintf("node %q in %q has kubelet %.2f, expected %.2f", node.Name, name, ver, want))
+		}
+		skew, limit := eksconfig.MinorVersionSkew(controlPlaneVersion, ver), eksconfig.KubeletVersionSkewLimit(controlPlaneVersion)
+		if skew < 0 || skew > limit {
+			errs = append(errs, fmt.Sprintf("node %q in %q has kubelet %.2f, unsupported skew %d with control plane %.2f (limit %d)", node.Name, name, ver, skew, controlPlaneVersion, limit))
+		}
+	}
+	for name := range expected {
+		if _, ok := kubelets[name]; !ok {
+			errs = append(errs, fmt.Sprintf("no node registered in %q", name))
+		}
+	}
+	if len(errs) > 0 {
+		sort.Strings(errs)
+		return kubelets, fmt.Errorf("version skew check failed (%s)", strings.Join(errs, ", "))
+	}
+	return kubelets, nil
+}
+
+// runConformanceLite runs a pod on each managed node group, which
+// resolves the cluster DNS through the pod network, and reads its logs
+// through the kubelet. The namespace is deleted afterwards.
+func (ts *Tester) runConformanceLite() (err error) {
+	cli := ts.k8sClient.KubernetesClientSet()
+	ns := ts.cfg.VersionSkew.Namespace
+	if err = k8s_client.CreateNamespace(ts.lg, cli, ns); err != nil {
+		return err
+	}
+	defer func() {
+		if derr := k8s_client.DeleteNamespaceAndWait(
+			ts.lg,
+			cli,
+			ns,
+			k8s_client.DefaultNamespaceDeletionInterval,
+			k8s_client.DefaultNamespaceDeletionTimeout,
+			k8s_client.WithForceDelete(true),
+		); derr != nil && err == nil {
+			err = fmt.Errorf("failed to delete namespace %q (%v)", ns, derr)
+		}
+	}()
+
+	names := make([]string, 0, len(ts.cfg.AddOnManagedNodeGroups.MNGs))
+	for name := range ts.cfg.AddOnManagedNodeGroups.MNGs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		pod := &v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				GenerateName: "version-skew-",
+				Labels:       map[string]string{"NGName": name},
+			},
+			Spec: v1.PodSpec{
+				RestartPolicy: v1.RestartPolicyNever,
+				NodeSelector:  map[string]string{"NGName": name},
+				Tolerations:   []v1.Toleration{{Operator: v1.TolerationOpExists}},
+				Containers: []v1.Container{
+					{
+						Name:    "version-skew",
+						Image:   ts.cfg.VersionSkew.ConformanceLiteImage,
+						Command: []string{"sh", "-c", "nslookup kubernetes.default.svc.cluster.local && echo VERSION_SKEW_OK"},
+					},
+				},
+			},
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		pod, err = cli.CoreV1().Pods(ns).Create(ctx, pod, metav1.CreateOptions{})
+		cancel()
+		if err != nil {
+			return fmt.Errorf("failed to create conformance-lite pod for %q (%v)", name, err)
+		}
+		if err = ts.waitConformanceLitePod(ns, pod.Name); err != nil {
+			return fmt.Errorf("conformance-lite pod %q for %q failed (%v)", pod.Name, name, err)
+		}
+		ts.lg.Info("conformance-lite pod succeeded", zap.String("mng-name", name), zap.String("pod-name", pod.Name))
+	}
+	return nil
+}
+
+func (ts *Tester) waitConformanceLitePod(ns string, podName string) error {
+	cli := ts.k8sClient.KubernetesClientSet()
+	retryStart, waitDur := time.Now(), 5*time.Minute
+	for time.Since(retryStart) < waitDur {
+		select {
+		case <-ts.stopCreationCh:
+			return errors.New("conformance-lite pod check aborted")
+		case <-time.After(5 * time.Second):
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+		pod, err := cli.CoreV1().Pods(ns).Get(ctx, podName, metav1.GetOptions{})
+		cancel()
+		if err != nil {
+			ts.lg.Warn("failed to get pod", zap.String("pod-name", podName), zap.Error(err))
+			continue
+		}
+		switch pod.Status.Phase {
+		case v1.PodSucceeded:
+		case v1.PodFailed:
+			return fmt.Errorf("unexpected pod phase %q", pod.Status.Phase)
+		default:
+			ts.lg.Info("waiting for pod", zap.String("pod-name", podName), zap.String("pod-phase", string(pod.Status.Phase)))
+			continue
+		}
+
+		// logs are served by the kubelet, through the control plane
+		ctx, cancel = context.WithTimeout(context.Background(), 15*time.Second)
+		out, err := cli.CoreV1().Pods(ns).GetLogs(podName, &v1.PodLogOptions{}).DoRaw(ctx)
+		cancel()
+		if err != nil {
+			return fmt.Errorf("failed to get pod logs (%v)", err)
+		}
+		if !strings.Contains(string(out), "VERSION_SKEW_OK") {
+			return fmt.Errorf("unexpected pod logs %q", string(out))
+		}
+		return nil
+	}
+	return fmt.Errorf("pod not succeeded after %v", waitDur)
+}
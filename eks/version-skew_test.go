@@ -0,0 +1,77 @@
+package eks
+
+import (
+	"reflect"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func Test_checkKubeletVersions(t *testing.T) {
+	node := func(name string, mngName string, kubeletVersion string, ready v1.ConditionStatus) v1.Node {
+		return v1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{"NGName": mngName}},
+			Status: v1.NodeStatus{
+				Conditions: []v1.NodeCondition{{Type: v1.NodeReady, Status: ready}},
+				NodeInfo:   v1.NodeSystemInfo{KubeletVersion: kubeletVersion},
+			},
+		}
+	}
+	tt := []struct {
+		controlPlaneVersion float64
+		expected            map[string]float64
+		nodes               []v1.Node
+		kubelets            map[string]float64
+		err                 bool
+	}{
+		{
+			1.27,
+			map[string]float64{"mng-n": 1.27, "mng-n-2": 1.25},
+			[]v1.Node{
+				node("a", "mng-n", "v1.27.1-eks-2f008fe", v1.ConditionTrue),
+				node("b", "mng-n-2", "v1.25.9-eks-0a21954", v1.ConditionTrue),
+				node("c", "other", "v1.22.0", v1.ConditionFalse),
+			},
+			map[string]float64{"mng-n": 1.27, "mng-n-2": 1.25},
+			false,
+		},
+		{
+			1.27,
+			map[string]float64{"mng-n-2": 1.25},
+			[]v1.Node{node("b", "mng-n-2", "v1.25.9-eks-0a21954", v1.ConditionFalse)},
+			map[string]float64{"mng-n-2": 1.25},
+			true,
+		},
+		{
+			1.27,
+			map[string]float64{"mng-n-2": 1.25},
+			[]v1.Node{node("b", "mng-n-2", "v1.26.4-eks-0a21954", v1.ConditionTrue)},
+			map[string]float64{"mng-n-2": 1.26},
+			true,
+		},
+		{
+			1.28,
+			map[string]float64{"mng-n-3": 1.24},
+			[]v1.Node{node("b", "mng-n-3", "v1.24.13-eks-0a21954", v1.ConditionTrue)},
+			map[string]float64{"mng-n-3": 1.24},
+			true,
+		},
+		{
+			1.27,
+			map[string]float64{"mng-n": 1.27},
+			nil,
+			map[string]float64{},
+			true,
+		},
+	}
+	for i, tv := range tt {
+		kubelets, err := checkKubeletVersions(tv.controlPlaneVersion, tv.expected, tv.nodes)
+		if (err != nil) != tv.err {
+			t.Fatalf("#%d: expected error %v, got %v", i, tv.err, err)
+		}
+		if !reflect.DeepEqual(kubelets, tv.kubelets) {
+			t.Fatalf("#%d: expected %v, got %v", i, tv.kubelets, kubelets)
+		}
+	}
+}
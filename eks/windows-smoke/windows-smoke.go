@@ -0,0 +1,264 @@
+// Package windowssmoke implements tester for Windows Pod smoke test.
+package windowssmoke
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"time"
+
+	eks_tester "github.com/aws/aws-k8s-tester/eks/tester"
+	"github.com/aws/aws-k8s-tester/eksconfig"
+	k8s_client "github.com/aws/aws-k8s-tester/pkg/k8s-client"
+	"github.com/aws/aws-k8s-tester/pkg/timeutil"
+	"go.uber.org/zap"
+	v1 "k8s.io/api/core/v1"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/exec"
+)
+
+// Config defines Windows smoke test configuration.
+type Config struct {
+	Logger    *zap.Logger
+	LogWriter io.Writer
+	Stopc     chan struct{}
+	EKSConfig *eksconfig.Config
+	K8SClient k8s_client.EKS
+}
+
+const (
+	podName = "windows-smoke"
+	appName = "windows-smoke"
+
+	// expectedOutput is echoed by the Windows container.
+	expectedOutput = "aws-k8s-tester windows smoke"
+)
+
+var pkgName = reflect.TypeOf(tester{}).PkgPath()
+
+func (ts *tester) Name() string { return pkgName }
+
+func New(cfg Config) eks_tester.Tester {
+	cfg.Logger.Info("creating tester", zap.String("tester", pkgName))
+	return &tester{cfg: cfg}
+}
+
+type tester struct {
+	cfg Config
+}
+
+func (ts *tester) Create() (err error) {
+	if !ts.cfg.EKSConfig.IsEnabledAddOnWindowsSmoke() {
+		ts.cfg.Logger.Info("skipping tester.Create", zap.String("tester", pkgName))
+		return nil
+	}
+	if ts.cfg.EKSConfig.AddOnWindowsSmoke.Created {
+		ts.cfg.Logger.Info("skipping tester.Create", zap.String("tester", pkgName))
+		return nil
+	}
+
+	ts.cfg.EKSConfig.AddOnWindowsSmoke.Created = true
+	ts.cfg.EKSConfig.Sync()
+	createStart := time.Now()
+	defer func() {
+		createEnd := time.Now()
+		ts.cfg.EKSConfig.AddOnWindowsSmoke.TimeFrameCreate = timeutil.NewTimeFrame(createStart, createEnd)
+		ts.cfg.EKSConfig.Sync()
+	}()
+
+	if err = k8s_client.CreateNamespace(
+		ts.cfg.Logger,
+		ts.cfg.K8SClient.KubernetesClientSet(),
+		ts.cfg.EKSConfig.AddOnWindowsSmoke.Namespace,
+	); err != nil {
+		return err
+	}
+	if err = ts.createPod(); err != nil {
+		return err
+	}
+	if err = ts.checkPod(); err != nil {
+		return err
+	}
+
+	ts.cfg.Logger.Info("successfully created Pod", zap.String("pod-name", podName))
+	ts.cfg.EKSConfig.Sync()
+	return nil
+}
+
+func (ts *tester) createPod() (err error) {
+	pod := &v1.Pod{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "v1",
+			Kind:       "Pod",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name: podName,
+		},
+		Spec: v1.PodSpec{
+			RestartPolicy: v1.RestartPolicyOnFailure,
+			NodeSelector: map[string]string{
+				"kubernetes.io/os": "windows",
+			},
+			Containers: []v1.Container{
+				{
+					Name:    appName,
+					Image:   ts.cfg.EKSConfig.AddOnWindowsSmoke.Image,
+					Command: []string{"cmd", "/c", "echo " + expectedOutput},
+				},
+			},
+		},
+	}
+
+	ts.cfg.Logger.Info("creating Pod", zap.String("pod-name", podName), zap.String("image", ts.cfg.EKSConfig.AddOnWindowsSmoke.Image))
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	_, err = ts.cfg.K8SClient.
+		KubernetesClientSet().
+		CoreV1().
+		Pods(ts.cfg.EKSConfig.AddOnWindowsSmoke.Namespace).
+		Create(ctx, pod, metav1.CreateOptions{})
+	cancel()
+	if err != nil {
+		return fmt.Errorf("failed to create Pod (%v)", err)
+	}
+
+	ts.cfg.Logger.Info("created Pod")
+	ts.cfg.EKSConfig.Sync()
+	return nil
+}
+
+func (ts *tester) checkPod() error {
+	logArgs := []string{
+		ts.cfg.EKSConfig.KubectlPath,
+		"--kubeconfig=" + ts.cfg.EKSConfig.KubeConfigPath,
+		"--namespace=" + ts.cfg.EKSConfig.AddOnWindowsSmoke.Namespace,
+		"logs",
+		"pods/" + podName,
+		"--all-containers=true",
+		"--timestamps",
+	}
+	logsCmd := strings.Join(logArgs, " ")
+
+	ts.cfg.Logger.Info("checking Pod",
+		zap.String("pod-name", podName),
+		zap.String("container-name", appName),
+		zap.String("command-logs", logsCmd),
+	)
+
+	// Windows container images are large, and take minutes to pull
+	succeeded := false
+	retryStart, waitDur := time.Now(), 20*time.Minute
+	for time.Since(retryStart) < waitDur {
+		select {
+		case <-ts.cfg.Stopc:
+			return errors.New("windows-smoke pod check aborted")
+		case <-time.After(10 * time.Second):
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+		pout, err := ts.cfg.K8SClient.
+			KubernetesClientSet().
+			CoreV1().
+			Pods(ts.cfg.EKSConfig.AddOnWindowsSmoke.Namespace).
+			Get(
+				ctx,
+				podName,
+				metav1.GetOptions{},
+			)
+		cancel()
+		if err != nil {
+			ts.cfg.Logger.Info("failed to query Pod", zap.String("pod-name", podName), zap.Error(err))
+			continue
+		}
+		if pout.Status.Phase != v1.PodSucceeded {
+			ts.cfg.Logger.Info("unexpected Pod phase", zap.String("pod-name", podName), zap.String("pod-phase", fmt.Sprintf("%v", pout.Status.Phase)))
+			continue
+		}
+
+		ctx, cancel = context.WithTimeout(context.Background(), 15*time.Second)
+		output, err := exec.New().CommandContext(ctx, logArgs[0], logArgs[1:]...).CombinedOutput()
+		cancel()
+		out := string(output)
+		if err != nil {
+			ts.cfg.Logger.Warn("'kubectl logs' failed", zap.Error(err))
+		}
+		fmt.Fprintf(ts.cfg.LogWriter, "\n'%s' output:\n\n%s\n\n", logsCmd, out)
+		if !strings.Contains(out, expectedOutput) {
+			ts.cfg.Logger.Warn("unexpected logs output")
+			continue
+		}
+
+		succeeded = true
+		ts.cfg.Logger.Info("successfully checked Pod logs",
+			zap.String("pod-name", podName),
+			zap.String("node-name", pout.Spec.NodeName),
+		)
+		break
+	}
+
+	if !succeeded {
+		return fmt.Errorf("failed to run Windows Pod %q", podName)
+	}
+	ts.cfg.EKSConfig.Sync()
+	return nil
+}
+
+func (ts *tester) Delete() error {
+	if !ts.cfg.EKSConfig.IsEnabledAddOnWindowsSmoke() {
+		ts.cfg.Logger.Info("skipping tester.Delete", zap.String("tester", pkgName))
+		return nil
+	}
+	if !ts.cfg.EKSConfig.AddOnWindowsSmoke.Created {
+		ts.cfg.Logger.Info("skipping tester.Delete", zap.String("tester", pkgName))
+		return nil
+	}
+
+	deleteStart := time.Now()
+	defer func() {
+		deleteEnd := time.Now()
+		ts.cfg.EKSConfig.AddOnWindowsSmoke.TimeFrameDelete = timeutil.NewTimeFrame(deleteStart, deleteEnd)
+		ts.cfg.EKSConfig.Sync()
+	}()
+
+	var errs []string
+
+	ts.cfg.Logger.Info("deleting Pod", zap.String("pod-name", podName))
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	err := ts.cfg.K8SClient.
+		KubernetesClientSet().
+		CoreV1().
+		Pods(ts.cfg.EKSConfig.AddOnWindowsSmoke.Namespace).
+		Delete(
+			ctx,
+			podName,
+			metav1.DeleteOptions{},
+		)
+	cancel()
+	if err != nil && !apierrs.IsNotFound(err) && !strings.Contains(err.Error(), "not found") {
+		ts.cfg.Logger.Warn("failed to delete", zap.Error(err))
+		return fmt.Errorf("failed to delete Pod (%v)", err)
+	}
+	ts.cfg.Logger.Info("deleted Pod", zap.String("pod-name", podName), zap.Error(err))
+
+	if err := k8s_client.DeleteNamespaceAndWait(
+		ts.cfg.Logger,
+		ts.cfg.K8SClient.KubernetesClientSet(),
+		ts.cfg.EKSConfig.AddOnWindowsSmoke.Namespace,
+		k8s_client.DefaultNamespaceDeletionInterval,
+		k8s_client.DefaultNamespaceDeletionTimeout,
+		k8s_client.WithForceDelete(true),
+	); err != nil {
+		errs = append(errs, fmt.Sprintf("failed to delete Windows smoke namespace (%v)", err))
+	}
+
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, ", "))
+	}
+
+	ts.cfg.EKSConfig.AddOnWindowsSmoke.Created = false
+	ts.cfg.EKSConfig.Sync()
+	return nil
+}
@@ -0,0 +1,120 @@
+package eksconfig
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-k8s-tester/pkg/timeutil"
+	"github.com/aws/aws-sdk-go/service/eks"
+)
+
+// AddOnAccessEntries defines parameters for EKS cluster
+// add-on access entries validation, which maps a test IAM role
+// to an access policy and asserts kubectl access works with the role.
+// ref. https://docs.aws.amazon.com/eks/latest/userguide/access-entries.html
+type AddOnAccessEntries struct {
+	// Enable is 'true' to create this add-on.
+	Enable bool `json:"enable"`
+	// Created is true when the resource has been created.
+	// Used for delete operations.
+	Created         bool               `json:"created" read-only:"true"`
+	TimeFrameCreate timeutil.TimeFrame `json:"time-frame-create" read-only:"true"`
+	TimeFrameDelete timeutil.TimeFrame `json:"time-frame-delete" read-only:"true"`
+
+	// CleanupPolicy is the policy to clean up the add-on resources on delete,
+	// "delete", "retain", or "retain-on-failure" (see "CleanupPolicyRetainOnFailure").
+	// Defaults to "delete".
+	CleanupPolicy string `json:"cleanup-policy"`
+	// CreateFailed is true when the creation failed.
+	CreateFailed bool `json:"create-failed" read-only:"true"`
+
+	// RoleName is the name of the test IAM role to map.
+	// The role trusts the current AWS account, so that
+	// the tester can assume it.
+	RoleName string `json:"role-name"`
+	// RoleARN is the ARN of the test IAM role.
+	RoleARN string `json:"role-arn" read-only:"true"`
+	// AccessPolicyARN is the EKS access policy to associate with the test role.
+	// Defaults to "AmazonEKSViewPolicy", with the cluster scope.
+	// ref. https://docs.aws.amazon.com/eks/latest/userguide/access-policies.html
+	AccessPolicyARN string `json:"access-policy-arn"`
+	// KubeConfigPath is the KUBECONFIG path to access the cluster with the test role.
+	KubeConfigPath string `json:"kubeconfig-path" read-only:"true"`
+
+	// CallerIdentity is true to create an access entry for the tester caller
+	// identity (see "Status.AWSIAMRoleARN"), with "CallerIdentityAccessPolicyARN".
+	// The access entry is kept on delete, since the tester needs it to access
+	// the cluster.
+	CallerIdentity bool `json:"caller-identity"`
+	// CallerIdentityAccessPolicyARN is the EKS access policy to associate with
+	// the tester caller identity. Defaults to "AmazonEKSClusterAdminPolicy".
+	CallerIdentityAccessPolicyARN string `json:"caller-identity-access-policy-arn"`
+	// ExtraPrincipals maps the extra IAM role or user ARNs to the EKS access
+	// policies to associate, with the cluster scope. Empty policy defaults
+	// to "AccessPolicyARN".
+	ExtraPrincipals map[string]string `json:"extra-principals,omitempty"`
+
+	// Principals is the list of principals validated to list nodes, including
+	// the node group roles (see "AddOnNodeGroups.Role.ARN").
+	Principals []string `json:"principals" read-only:"true"`
+}
+
+// EnvironmentVariablePrefixAddOnAccessEntries is the environment variable prefix used for "eksconfig".
+const EnvironmentVariablePrefixAddOnAccessEntries = AWS_K8S_TESTER_EKS_PREFIX + "ADD_ON_ACCESS_ENTRIES_"
+
+// IsEnabledAddOnAccessEntries returns true if "AddOnAccessEntries" is enabled.
+// Otherwise, nil the field for "omitempty".
+func (cfg *Config) IsEnabledAddOnAccessEntries() bool {
+	if cfg.AddOnAccessEntries == nil {
+		return false
+	}
+	if cfg.AddOnAccessEntries.Enable {
+		return true
+	}
+	cfg.AddOnAccessEntries = nil
+	return false
+}
+
+func getDefaultAddOnAccessEntries() *AddOnAccessEntries {
+	return &AddOnAccessEntries{
+		Enable:         false,
+		CallerIdentity: true,
+	}
+}
+
+func (cfg *Config) validateAddOnAccessEntries() error {
+	if !cfg.IsEnabledAddOnAccessEntries() {
+		return nil
+	}
+	if cfg.AuthenticationMode != eks.AuthenticationModeApi && cfg.AuthenticationMode != eks.AuthenticationModeApiAndConfigMap {
+		return fmt.Errorf("AddOnAccessEntries.Enable true but AuthenticationMode %q", cfg.AuthenticationMode)
+	}
+	if cfg.AddOnAccessEntries.RoleName == "" {
+		cfg.AddOnAccessEntries.RoleName = cfg.Name + "-access-entries-role"
+	}
+	if len(cfg.AddOnAccessEntries.RoleName) > 64 {
+		return fmt.Errorf("AddOnAccessEntries.RoleName %q too long (expected <= 64)", cfg.AddOnAccessEntries.RoleName)
+	}
+	if cfg.AddOnAccessEntries.AccessPolicyARN == "" {
+		cfg.AddOnAccessEntries.AccessPolicyARN = fmt.Sprintf("arn:%s:eks::aws:cluster-access-policy/AmazonEKSViewPolicy", cfg.Partition)
+	}
+	if cfg.AddOnAccessEntries.CallerIdentity && cfg.AddOnAccessEntries.CallerIdentityAccessPolicyARN == "" {
+		cfg.AddOnAccessEntries.CallerIdentityAccessPolicyARN = fmt.Sprintf("arn:%s:eks::aws:cluster-access-policy/AmazonEKSClusterAdminPolicy", cfg.Partition)
+	}
+	for principalARN, policyARN := range cfg.AddOnAccessEntries.ExtraPrincipals {
+		if !strings.HasPrefix(principalARN, "arn:") || !strings.Contains(principalARN, ":iam::") {
+			return fmt.Errorf("invalid AddOnAccessEntries.ExtraPrincipals principal %q (expected IAM role or user ARN)", principalARN)
+		}
+		if policyARN == "" {
+			cfg.AddOnAccessEntries.ExtraPrincipals[principalARN] = cfg.AddOnAccessEntries.AccessPolicyARN
+			continue
+		}
+		if !strings.Contains(policyARN, ":cluster-access-policy/") {
+			return fmt.Errorf("invalid AddOnAccessEntries.ExtraPrincipals access policy %q for %q", policyARN, principalARN)
+		}
+	}
+	if cfg.AddOnAccessEntries.KubeConfigPath == "" {
+		cfg.AddOnAccessEntries.KubeConfigPath = strings.ReplaceAll(cfg.ConfigPath, ".yaml", "") + ".access-entries.kubeconfig.yaml"
+	}
+	return nil
+}
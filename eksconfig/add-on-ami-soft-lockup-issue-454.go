@@ -17,6 +17,13 @@ type AddOnAmiSoftLockupIssue454 struct {
 	TimeFrameCreate timeutil.TimeFrame `json:"time-frame-create" read-only:"true"`
 	TimeFrameDelete timeutil.TimeFrame `json:"time-frame-delete" read-only:"true"`
 
+	// CleanupPolicy is the policy to clean up the add-on resources on delete,
+	// "delete", "retain", or "retain-on-failure" (see "CleanupPolicyRetainOnFailure").
+	// Defaults to "delete".
+	CleanupPolicy string `json:"cleanup-policy"`
+	// CreateFailed is true when the creation failed.
+	CreateFailed bool `json:"create-failed" read-only:"true"`
+
 	// Namespace is the namespace to create objects in.
 	Namespace string `json:"namespace"`
 
@@ -20,6 +20,13 @@ type AddOnAppMesh struct {
 	TimeFrameCreate timeutil.TimeFrame `json:"time-frame-create" read-only:"true"`
 	TimeFrameDelete timeutil.TimeFrame `json:"time-frame-delete" read-only:"true"`
 
+	// CleanupPolicy is the policy to clean up the add-on resources on delete,
+	// "delete", "retain", or "retain-on-failure" (see "CleanupPolicyRetainOnFailure").
+	// Defaults to "delete".
+	CleanupPolicy string `json:"cleanup-policy"`
+	// CreateFailed is true when the creation failed.
+	CreateFailed bool `json:"create-failed" read-only:"true"`
+
 	// S3Dir is the S3 directory to store all test results.
 	// It is under the bucket "eksconfig.Config.S3BucketName".
 	S3Dir string `json:"s3-dir"`
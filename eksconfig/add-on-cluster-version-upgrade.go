@@ -27,6 +27,14 @@ type AddOnClusterVersionUpgrade struct {
 	// If empty, set default version.
 	Version      string  `json:"version"`
 	VersionValue float64 `json:"version-value" read-only:"true"`
+
+	// RerunAddOns is the list of add-ons (see "AddOnNames") to delete and
+	// re-create after the upgrade, to confirm that their workloads still run
+	// with the upgraded control plane (e.g. "jobs-pi,nlb-hello-world").
+	// The add-ons must be enabled.
+	RerunAddOns []string `json:"rerun-add-ons"`
+	// TimeFrameRerun is the time frame of re-running "RerunAddOns".
+	TimeFrameRerun timeutil.TimeFrame `json:"time-frame-rerun" read-only:"true"`
 }
 
 // EnvironmentVariablePrefixAddOnClusterVersionUpgrade is the environment variable prefix used for "eksconfig".
@@ -73,5 +81,38 @@ func (cfg *Config) validateAddOnClusterVersionUpgrade() error {
 		return fmt.Errorf("AddOnClusterVersionUpgrade only supports one minor version upgrade but got %.2f [invalid: %q -> %q]", delta, cfg.Version, cfg.AddOnClusterVersionUpgrade.Version)
 	}
 
+	// managed node groups are upgraded after the control plane,
+	// so the skew must stay within the limit in between
+	if cfg.IsEnabledAddOnManagedNodeGroups() {
+		limit := KubeletVersionSkewLimit(cfg.AddOnClusterVersionUpgrade.VersionValue)
+		for name, cur := range cfg.AddOnManagedNodeGroups.MNGs {
+			skew := MinorVersionSkew(cfg.AddOnClusterVersionUpgrade.VersionValue, cur.KubeletVersion(cfg.VersionValue))
+			if skew > limit {
+				return fmt.Errorf("AddOnClusterVersionUpgrade.Version %q would be %d minor versions newer than AddOnManagedNodeGroups.MNGs[%q] (limit %d)", cfg.AddOnClusterVersionUpgrade.Version, skew, name, limit)
+			}
+		}
+	}
+
+	seen := make(map[string]struct{}, len(cfg.AddOnClusterVersionUpgrade.RerunAddOns))
+	for i, name := range cfg.AddOnClusterVersionUpgrade.RerunAddOns {
+		if v, ok := addOnAliases[name]; ok {
+			name = v
+			cfg.AddOnClusterVersionUpgrade.RerunAddOns[i] = name
+		}
+		if _, ok := addOnDefaults[name]; !ok {
+			return fmt.Errorf("unknown AddOnClusterVersionUpgrade.RerunAddOns %q (available add-ons %q)", name, AddOnNames())
+		}
+		if name == "cluster-version-upgrade" {
+			return errors.New("AddOnClusterVersionUpgrade.RerunAddOns cannot include 'cluster-version-upgrade'")
+		}
+		if !cfg.isEnabledAddOnName(name) {
+			return fmt.Errorf("AddOnClusterVersionUpgrade.RerunAddOns %q but the add-on is not enabled", name)
+		}
+		if _, ok := seen[name]; ok {
+			return fmt.Errorf("duplicate AddOnClusterVersionUpgrade.RerunAddOns %q", name)
+		}
+		seen[name] = struct{}{}
+	}
+
 	return nil
 }
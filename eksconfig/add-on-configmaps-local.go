@@ -6,6 +6,7 @@ import (
 	"path"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/aws/aws-k8s-tester/pkg/metrics"
 	"github.com/aws/aws-k8s-tester/pkg/timeutil"
@@ -26,6 +27,13 @@ type AddOnConfigmapsLocal struct {
 	TimeFrameCreate timeutil.TimeFrame `json:"time-frame-create" read-only:"true"`
 	TimeFrameDelete timeutil.TimeFrame `json:"time-frame-delete" read-only:"true"`
 
+	// CleanupPolicy is the policy to clean up the add-on resources on delete,
+	// "delete", "retain", or "retain-on-failure" (see "CleanupPolicyRetainOnFailure").
+	// Defaults to "delete".
+	CleanupPolicy string `json:"cleanup-policy"`
+	// CreateFailed is true when the creation failed.
+	CreateFailed bool `json:"create-failed" read-only:"true"`
+
 	// S3Dir is the S3 directory to store all test results.
 	// It is under the bucket "eksconfig.Config.S3BucketName".
 	S3Dir string `json:"s3-dir"`
@@ -80,6 +88,18 @@ type AddOnConfigmapsLocal struct {
 	RequestsSummaryWritesCompareTableS3Key string                  `json:"requests-summary-writes-compare-table-s3-path" read-only:"true"`
 
 	//////////////////////////////////////////////////////////////////////////////
+
+	// SLOMaxErrorRatePercent is the maximum error rate in percent of
+	// each "RequestsSummary" result. Zero disables the check.
+	SLOMaxErrorRatePercent float64 `json:"slo-max-error-rate-percent"`
+	// SLOMaxLatencyP99 is the maximum p99 latency of each "RequestsSummary"
+	// result. Zero disables the check.
+	SLOMaxLatencyP99       time.Duration `json:"slo-max-latency-p99"`
+	SLOMaxLatencyP99String string        `json:"slo-max-latency-p99-string" read-only:"true"`
+	// SLOVerdict is the pass/fail verdict of the results against the SLO.
+	SLOVerdict metrics.SLOVerdict `json:"slo-verdict" read-only:"true"`
+
+	//////////////////////////////////////////////////////////////////////////////
 }
 
 // EnvironmentVariablePrefixAddOnConfigmapsLocal is the environment variable prefix used for "eksconfig".
@@ -115,8 +135,8 @@ func (cfg *Config) validateAddOnConfigmapsLocal() error {
 		return nil
 	}
 
-	if !cfg.IsEnabledAddOnNodeGroups() && !cfg.IsEnabledAddOnManagedNodeGroups() {
-		return errors.New("AddOnConfigmapsLocal.Enable true but no node group is enabled")
+	if !cfg.IsEnabledAddOnNodeGroups() && !cfg.IsEnabledAddOnManagedNodeGroups() && !cfg.IsEnabledAutoMode() && !cfg.IsEnabledAttach() {
+		return errors.New("AddOnConfigmapsLocal.Enable true but no node group, AutoMode, or Attach is enabled")
 	}
 
 	if cfg.AddOnConfigmapsLocal.S3Dir == "" {
@@ -225,5 +245,10 @@ func (cfg *Config) validateAddOnConfigmapsLocal() error {
 	}
 	//////////////////////////////////////////////////////////////////////////////
 
+	if err := validateSLO("AddOnConfigmapsLocal", cfg.AddOnConfigmapsLocal.SLOMaxErrorRatePercent, cfg.AddOnConfigmapsLocal.SLOMaxLatencyP99); err != nil {
+		return err
+	}
+	cfg.AddOnConfigmapsLocal.SLOMaxLatencyP99String = cfg.AddOnConfigmapsLocal.SLOMaxLatencyP99.String()
+
 	return nil
 }
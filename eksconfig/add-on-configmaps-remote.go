@@ -6,6 +6,7 @@ import (
 	"path"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/aws/aws-k8s-tester/pkg/metrics"
 	"github.com/aws/aws-k8s-tester/pkg/randutil"
@@ -27,6 +28,13 @@ type AddOnConfigmapsRemote struct {
 	TimeFrameCreate timeutil.TimeFrame `json:"time-frame-create" read-only:"true"`
 	TimeFrameDelete timeutil.TimeFrame `json:"time-frame-delete" read-only:"true"`
 
+	// CleanupPolicy is the policy to clean up the add-on resources on delete,
+	// "delete", "retain", or "retain-on-failure" (see "CleanupPolicyRetainOnFailure").
+	// Defaults to "delete".
+	CleanupPolicy string `json:"cleanup-policy"`
+	// CreateFailed is true when the creation failed.
+	CreateFailed bool `json:"create-failed" read-only:"true"`
+
 	// S3Dir is the S3 directory to store all test results.
 	// It is under the bucket "eksconfig.Config.S3BucketName".
 	S3Dir string `json:"s3-dir"`
@@ -108,6 +116,18 @@ type AddOnConfigmapsRemote struct {
 	RequestsSummaryWritesCompareTableS3Key string                  `json:"requests-summary-writes-compare-table-s3-path" read-only:"true"`
 
 	//////////////////////////////////////////////////////////////////////////////
+
+	// SLOMaxErrorRatePercent is the maximum error rate in percent of
+	// each "RequestsSummary" result. Zero disables the check.
+	SLOMaxErrorRatePercent float64 `json:"slo-max-error-rate-percent"`
+	// SLOMaxLatencyP99 is the maximum p99 latency of each "RequestsSummary"
+	// result. Zero disables the check.
+	SLOMaxLatencyP99       time.Duration `json:"slo-max-latency-p99"`
+	SLOMaxLatencyP99String string        `json:"slo-max-latency-p99-string" read-only:"true"`
+	// SLOVerdict is the pass/fail verdict of the results against the SLO.
+	SLOVerdict metrics.SLOVerdict `json:"slo-verdict" read-only:"true"`
+
+	//////////////////////////////////////////////////////////////////////////////
 }
 
 // EnvironmentVariablePrefixAddOnConfigmapsRemote is the environment variable prefix used for "eksconfig".
@@ -281,5 +301,10 @@ func (cfg *Config) validateAddOnConfigmapsRemote() error {
 	}
 	//////////////////////////////////////////////////////////////////////////////
 
+	if err := validateSLO("AddOnConfigmapsRemote", cfg.AddOnConfigmapsRemote.SLOMaxErrorRatePercent, cfg.AddOnConfigmapsRemote.SLOMaxLatencyP99); err != nil {
+		return err
+	}
+	cfg.AddOnConfigmapsRemote.SLOMaxLatencyP99String = cfg.AddOnConfigmapsRemote.SLOMaxLatencyP99.String()
+
 	return nil
 }
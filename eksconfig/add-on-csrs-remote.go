@@ -6,6 +6,7 @@ import (
 	"path"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/aws/aws-k8s-tester/pkg/metrics"
 	"github.com/aws/aws-k8s-tester/pkg/randutil"
@@ -27,6 +28,13 @@ type AddOnCSRsRemote struct {
 	TimeFrameCreate timeutil.TimeFrame `json:"time-frame-create" read-only:"true"`
 	TimeFrameDelete timeutil.TimeFrame `json:"time-frame-delete" read-only:"true"`
 
+	// CleanupPolicy is the policy to clean up the add-on resources on delete,
+	// "delete", "retain", or "retain-on-failure" (see "CleanupPolicyRetainOnFailure").
+	// Defaults to "delete".
+	CleanupPolicy string `json:"cleanup-policy"`
+	// CreateFailed is true when the creation failed.
+	CreateFailed bool `json:"create-failed" read-only:"true"`
+
 	// S3Dir is the S3 directory to store all test results.
 	// It is under the bucket "eksconfig.Config.S3BucketName".
 	S3Dir string `json:"s3-dir"`
@@ -113,6 +121,18 @@ type AddOnCSRsRemote struct {
 	RequestsSummaryWritesCompareTableS3Key string                  `json:"requests-summary-writes-compare-table-s3-path" read-only:"true"`
 
 	//////////////////////////////////////////////////////////////////////////////
+
+	// SLOMaxErrorRatePercent is the maximum error rate in percent of
+	// each "RequestsSummary" result. Zero disables the check.
+	SLOMaxErrorRatePercent float64 `json:"slo-max-error-rate-percent"`
+	// SLOMaxLatencyP99 is the maximum p99 latency of each "RequestsSummary"
+	// result. Zero disables the check.
+	SLOMaxLatencyP99       time.Duration `json:"slo-max-latency-p99"`
+	SLOMaxLatencyP99String string        `json:"slo-max-latency-p99-string" read-only:"true"`
+	// SLOVerdict is the pass/fail verdict of the results against the SLO.
+	SLOVerdict metrics.SLOVerdict `json:"slo-verdict" read-only:"true"`
+
+	//////////////////////////////////////////////////////////////////////////////
 }
 
 // EnvironmentVariablePrefixAddOnCSRsRemote is the environment variable prefix used for "eksconfig".
@@ -284,5 +304,10 @@ func (cfg *Config) validateAddOnCSRsRemote() error {
 	}
 	//////////////////////////////////////////////////////////////////////////////
 
+	if err := validateSLO("AddOnCSRsRemote", cfg.AddOnCSRsRemote.SLOMaxErrorRatePercent, cfg.AddOnCSRsRemote.SLOMaxLatencyP99); err != nil {
+		return err
+	}
+	cfg.AddOnCSRsRemote.SLOMaxLatencyP99String = cfg.AddOnCSRsRemote.SLOMaxLatencyP99.String()
+
 	return nil
 }
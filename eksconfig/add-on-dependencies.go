@@ -0,0 +1,121 @@
+package eksconfig
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// AddOnDependencies maps the add-on to the add-ons that it requires,
+// by their configuration field names (e.g. "add-on-prometheus-grafana").
+// The required add-ons must be enabled, and are installed before.
+// Node groups are required by most add-ons, and validated separately.
+var AddOnDependencies = map[string][]string{
+	"add-on-kubernetes-dashboard": {"add-on-metrics-server"},
+	"add-on-prometheus-grafana":   {"add-on-csi-ebs"},
+	"add-on-wordpress":            {"add-on-csi-ebs"},
+}
+
+// addOnField is the "Config" add-on field with its configuration field name.
+type addOnField struct {
+	name      string // e.g. "add-on-csi-ebs"
+	fieldName string // e.g. "AddOnCSIEBS"
+	index     int
+}
+
+// addOnFields returns the add-on fields in the order of declaration.
+func addOnFields() (fields []addOnField) {
+	tp := reflect.TypeOf(Config{})
+	for i := 0; i < tp.NumField(); i++ {
+		f := tp.Field(i)
+		name := strings.Split(f.Tag.Get("json"), ",")[0]
+		if !strings.HasPrefix(name, "add-on-") || f.Type.Kind() != reflect.Ptr {
+			continue
+		}
+		fields = append(fields, addOnField{name: name, fieldName: f.Name, index: i})
+	}
+	return fields
+}
+
+// isEnabledAddOn returns true if the add-on field is non-nil
+// and its "Enable" field is true.
+func (cfg *Config) isEnabledAddOn(f addOnField) bool {
+	v := reflect.ValueOf(cfg).Elem().Field(f.index)
+	if v.IsNil() {
+		return false
+	}
+	en := v.Elem().FieldByName("Enable")
+	return en.IsValid() && en.Kind() == reflect.Bool && en.Bool()
+}
+
+// EnabledAddOns returns the enabled add-on names
+// (e.g. "add-on-csi-ebs") in the order of declaration.
+func (cfg *Config) EnabledAddOns() (names []string) {
+	for _, f := range addOnFields() {
+		if cfg.isEnabledAddOn(f) {
+			names = append(names, f.name)
+		}
+	}
+	return names
+}
+
+// validateAddOnDependencies fails fast when an enabled add-on
+// requires a disabled add-on.
+func (cfg *Config) validateAddOnDependencies() error {
+	fields := make(map[string]addOnField)
+	for _, f := range addOnFields() {
+		fields[f.name] = f
+	}
+	for _, f := range addOnFields() {
+		if !cfg.isEnabledAddOn(f) {
+			continue
+		}
+		for _, dep := range AddOnDependencies[f.name] {
+			df, ok := fields[dep]
+			if !ok {
+				return fmt.Errorf("%s requires unknown add-on %q", f.fieldName, dep)
+			}
+			if !cfg.isEnabledAddOn(df) {
+				return fmt.Errorf("%s.Enable true but %s.Enable false (%q requires %q)", f.fieldName, df.fieldName, f.name, dep)
+			}
+		}
+	}
+	return nil
+}
+
+// OrderAddOns returns the add-on names reordered so that every
+// add-on comes after the add-ons that it requires, otherwise
+// keeping the given order. Unknown names are kept as is.
+func OrderAddOns(names []string) ([]string, error) {
+	given := make(map[string]bool, len(names))
+	for _, name := range names {
+		given[name] = true
+	}
+	placed := make(map[string]bool, len(names))
+	ordered := make([]string, 0, len(names))
+	for len(ordered) < len(names) {
+		progressed := false
+		for _, name := range names {
+			if placed[name] {
+				continue
+			}
+			ready := true
+			for _, dep := range AddOnDependencies[name] {
+				if given[dep] && !placed[dep] {
+					ready = false
+					break
+				}
+			}
+			if ready {
+				placed[name] = true
+				ordered = append(ordered, name)
+				progressed = true
+				break // restart from the beginning to keep the given order
+			}
+		}
+		if !progressed {
+			return nil, fmt.Errorf("add-on dependency cycle among %q", names)
+		}
+	}
+	return ordered, nil
+}
@@ -0,0 +1,97 @@
+package eksconfig
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/aws/aws-k8s-tester/pkg/timeutil"
+)
+
+// AddOnIPv6 defines parameters for EKS cluster
+// add-on IPv6 validation, which asserts Pods get IPv6 addresses
+// and a dual-stack load balancer serves traffic.
+// ref. https://docs.aws.amazon.com/eks/latest/userguide/cni-ipv6.html
+type AddOnIPv6 struct {
+	// Enable is 'true' to create this add-on.
+	Enable bool `json:"enable"`
+	// Created is true when the resource has been created.
+	// Used for delete operations.
+	Created         bool               `json:"created" read-only:"true"`
+	TimeFrameCreate timeutil.TimeFrame `json:"time-frame-create" read-only:"true"`
+	TimeFrameDelete timeutil.TimeFrame `json:"time-frame-delete" read-only:"true"`
+
+	// CleanupPolicy is the policy to clean up the add-on resources on delete,
+	// "delete", "retain", or "retain-on-failure" (see "CleanupPolicyRetainOnFailure").
+	// Defaults to "delete".
+	CleanupPolicy string `json:"cleanup-policy"`
+	// CreateFailed is true when the creation failed.
+	CreateFailed bool `json:"create-failed" read-only:"true"`
+
+	// Namespace is the namespace to create objects in.
+	Namespace string `json:"namespace"`
+
+	// DeploymentReplicas is the number of replicas to deploy using "Deployment" object.
+	DeploymentReplicas int32 `json:"deployment-replicas"`
+	// ServiceAnnotations is the annotations for the "LoadBalancer" type Service.
+	// IPv6 targets require the AWS Load Balancer Controller, which must
+	// be installed in the cluster with the default annotations.
+	// ref. https://docs.aws.amazon.com/eks/latest/userguide/network-load-balancing.html
+	ServiceAnnotations map[string]string `json:"service-annotations"`
+
+	// PodIPs is the list of Pod IPs found in the Deployment.
+	PodIPs []string `json:"pod-ips" read-only:"true"`
+	// ELBARN is the ARN of the load balancer created from the service.
+	ELBARN string `json:"elb-arn" read-only:"true"`
+	// ELBName is the name of the load balancer created from the service.
+	ELBName string `json:"elb-name" read-only:"true"`
+	// URL is the host name for the service.
+	URL string `json:"url" read-only:"true"`
+}
+
+// EnvironmentVariablePrefixAddOnIPv6 is the environment variable prefix used for "eksconfig".
+const EnvironmentVariablePrefixAddOnIPv6 = AWS_K8S_TESTER_EKS_PREFIX + "ADD_ON_IPV6_"
+
+// IsEnabledAddOnIPv6 returns true if "AddOnIPv6" is enabled.
+// Otherwise, nil the field for "omitempty".
+func (cfg *Config) IsEnabledAddOnIPv6() bool {
+	if cfg.AddOnIPv6 == nil {
+		return false
+	}
+	if cfg.AddOnIPv6.Enable {
+		return true
+	}
+	cfg.AddOnIPv6 = nil
+	return false
+}
+
+func getDefaultAddOnIPv6() *AddOnIPv6 {
+	return &AddOnIPv6{
+		Enable:             false,
+		DeploymentReplicas: 3,
+		ServiceAnnotations: map[string]string{
+			"service.beta.kubernetes.io/aws-load-balancer-type":            "external",
+			"service.beta.kubernetes.io/aws-load-balancer-nlb-target-type": "ip",
+			"service.beta.kubernetes.io/aws-load-balancer-scheme":          "internet-facing",
+			"service.beta.kubernetes.io/aws-load-balancer-ip-address-type": "dualstack",
+		},
+	}
+}
+
+func (cfg *Config) validateAddOnIPv6() error {
+	if !cfg.IsEnabledAddOnIPv6() {
+		return nil
+	}
+	if cfg.IPFamily != IPFamilyIPv6 {
+		return fmt.Errorf("AddOnIPv6.Enable true but IPFamily %q", cfg.IPFamily)
+	}
+	if !cfg.IsEnabledAddOnNodeGroups() && !cfg.IsEnabledAddOnManagedNodeGroups() {
+		return errors.New("AddOnIPv6.Enable true but no node group is enabled")
+	}
+	if cfg.AddOnIPv6.Namespace == "" {
+		cfg.AddOnIPv6.Namespace = cfg.Name + "-ipv6"
+	}
+	if cfg.AddOnIPv6.DeploymentReplicas == 0 {
+		cfg.AddOnIPv6.DeploymentReplicas = 3
+	}
+	return nil
+}
@@ -18,6 +18,13 @@ type AddOnJobsEcho struct {
 	TimeFrameCreate timeutil.TimeFrame `json:"time-frame-create" read-only:"true"`
 	TimeFrameDelete timeutil.TimeFrame `json:"time-frame-delete" read-only:"true"`
 
+	// CleanupPolicy is the policy to clean up the add-on resources on delete,
+	// "delete", "retain", or "retain-on-failure" (see "CleanupPolicyRetainOnFailure").
+	// Defaults to "delete".
+	CleanupPolicy string `json:"cleanup-policy"`
+	// CreateFailed is true when the creation failed.
+	CreateFailed bool `json:"create-failed" read-only:"true"`
+
 	// Namespace is the namespace to create objects in.
 	Namespace string `json:"namespace"`
 
@@ -86,8 +93,8 @@ func (cfg *Config) validateAddOnJobsEcho() error {
 	if !cfg.IsEnabledAddOnJobsEcho() {
 		return nil
 	}
-	if !cfg.IsEnabledAddOnNodeGroups() && !cfg.IsEnabledAddOnManagedNodeGroups() {
-		return errors.New("AddOnJobsEcho.Enable true but no node group is enabled")
+	if !cfg.IsEnabledAddOnNodeGroups() && !cfg.IsEnabledAddOnManagedNodeGroups() && !cfg.IsEnabledAutoMode() && !cfg.IsEnabledAttach() {
+		return errors.New("AddOnJobsEcho.Enable true but no node group, AutoMode, or Attach is enabled")
 	}
 	if cfg.AddOnJobsEcho.Namespace == "" {
 		cfg.AddOnJobsEcho.Namespace = cfg.Name + "-jobs-echo"
@@ -6,6 +6,7 @@ import (
 	"fmt"
 
 	"github.com/aws/aws-k8s-tester/ec2config"
+	aws_secrets "github.com/aws/aws-k8s-tester/pkg/aws/secrets"
 	"github.com/aws/aws-k8s-tester/pkg/randutil"
 	"github.com/aws/aws-k8s-tester/pkg/timeutil"
 	"github.com/aws/aws-sdk-go/service/eks"
@@ -23,11 +24,19 @@ type AddOnJupyterHub struct {
 	TimeFrameCreate timeutil.TimeFrame `json:"time-frame-create" read-only:"true"`
 	TimeFrameDelete timeutil.TimeFrame `json:"time-frame-delete" read-only:"true"`
 
+	// CleanupPolicy is the policy to clean up the add-on resources on delete,
+	// "delete", "retain", or "retain-on-failure" (see "CleanupPolicyRetainOnFailure").
+	// Defaults to "delete".
+	CleanupPolicy string `json:"cleanup-policy"`
+	// CreateFailed is true when the creation failed.
+	CreateFailed bool `json:"create-failed" read-only:"true"`
+
 	// Namespace is the namespace to create objects in.
 	Namespace string `json:"namespace"`
 
 	// ProxySecretToken is 32-byte hexadecimal encoded secret token string.
 	// e.g. "openssl rand -hex 32"
+	// Can be an "ssm://" or "secretsmanager://" URI (see "ResolveSecrets").
 	ProxySecretToken string `json:"proxy-secret-token"`
 
 	// NLBARN is the ARN of the NLB created from the service.
@@ -96,9 +105,11 @@ func (cfg *Config) validateAddOnJupyterHub() error {
 	if cfg.AddOnJupyterHub.ProxySecretToken == "" {
 		cfg.AddOnJupyterHub.ProxySecretToken = randutil.Hex(32)
 	}
-	_, err := hex.DecodeString(cfg.AddOnJupyterHub.ProxySecretToken)
-	if err != nil {
-		return fmt.Errorf("cannot hex decode AddOnJupyterHub.ProxySecretToken %q", err)
+	// secret URI not resolved yet (see "ResolveSecrets")
+	if !aws_secrets.IsURI(cfg.AddOnJupyterHub.ProxySecretToken) {
+		if _, err := hex.DecodeString(cfg.AddOnJupyterHub.ProxySecretToken); err != nil {
+			return fmt.Errorf("cannot hex decode AddOnJupyterHub.ProxySecretToken %q", err)
+		}
 	}
 
 	return nil
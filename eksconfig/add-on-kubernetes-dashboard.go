@@ -18,6 +18,13 @@ type AddOnKubernetesDashboard struct {
 	TimeFrameCreate timeutil.TimeFrame `json:"time-frame-create" read-only:"true"`
 	TimeFrameDelete timeutil.TimeFrame `json:"time-frame-delete" read-only:"true"`
 
+	// CleanupPolicy is the policy to clean up the add-on resources on delete,
+	// "delete", "retain", or "retain-on-failure" (see "CleanupPolicyRetainOnFailure").
+	// Defaults to "delete".
+	CleanupPolicy string `json:"cleanup-policy"`
+	// CreateFailed is true when the creation failed.
+	CreateFailed bool `json:"create-failed" read-only:"true"`
+
 	// AuthenticationToken is the authentication token for eks-admin service account.
 	AuthenticationToken string `json:"authentication-token,omitempty" read-only:"true"`
 	// URL is the host name for Kubernetes Dashboard service.
@@ -74,9 +81,6 @@ func (cfg *Config) validateAddOnKubernetesDashboard() error {
 	if !cfg.IsEnabledAddOnNodeGroups() && !cfg.IsEnabledAddOnManagedNodeGroups() {
 		return errors.New("AddOnKubernetesDashboard.Enable true but no node group is enabled")
 	}
-	if !cfg.IsEnabledAddOnMetricsServer() {
-		return errors.New("AddOnKubernetesDashboard.Enable true but AddOnMetricsServer.Enable false")
-	}
 	if cfg.AddOnKubernetesDashboard.URL == "" {
 		cfg.AddOnKubernetesDashboard.URL = defaultKubernetesDashboardURL
 	}
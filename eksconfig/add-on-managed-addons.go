@@ -0,0 +1,135 @@
+package eksconfig
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/aws/aws-k8s-tester/pkg/timeutil"
+	"github.com/aws/aws-sdk-go/service/eks"
+)
+
+// AddOnManagedAddons defines parameters for EKS cluster
+// add-on EKS managed add-ons, which installs, upgrades, and removes
+// the EKS managed add-ons (e.g. "vpc-cni", "coredns", "kube-proxy",
+// "aws-ebs-csi-driver") via EKS API, so that the add-on version
+// bumps can be regression-tested.
+// ref. https://docs.aws.amazon.com/eks/latest/userguide/eks-add-ons.html
+type AddOnManagedAddons struct {
+	// Enable is 'true' to create this add-on.
+	Enable bool `json:"enable"`
+	// Created is true when the resource has been created.
+	// Used for delete operations.
+	Created         bool               `json:"created" read-only:"true"`
+	TimeFrameCreate timeutil.TimeFrame `json:"time-frame-create" read-only:"true"`
+	TimeFrameDelete timeutil.TimeFrame `json:"time-frame-delete" read-only:"true"`
+
+	// CleanupPolicy is the policy to clean up the add-on resources on delete,
+	// "delete", "retain", or "retain-on-failure" (see "CleanupPolicyRetainOnFailure").
+	// Defaults to "delete".
+	CleanupPolicy string `json:"cleanup-policy"`
+	// CreateFailed is true when the creation failed.
+	CreateFailed bool `json:"create-failed" read-only:"true"`
+
+	// Addons maps the EKS managed add-on name (e.g. "vpc-cni") to its configuration.
+	// The add-ons are installed in the order of their names.
+	Addons map[string]ManagedAddon `json:"addons"`
+}
+
+// ManagedAddon defines an EKS managed add-on.
+type ManagedAddon struct {
+	// Version is the add-on version to install (e.g. "v1.18.3-eksbuild.2").
+	// Empty to install the version that EKS chooses for the cluster version.
+	// "default" or "latest" to resolve the default or the latest version
+	// compatible with the cluster version.
+	Version string `json:"version"`
+	// UpgradeVersion is the add-on version to upgrade to, once installed.
+	// Empty to skip the upgrade. "default" and "latest" are resolved
+	// the same way as "Version".
+	UpgradeVersion string `json:"upgrade-version"`
+	// ResolveConflicts is how to resolve the field value conflicts
+	// with the existing resources, "NONE", "OVERWRITE", or "PRESERVE".
+	// Defaults to "OVERWRITE", to adopt the self-managed add-on.
+	ResolveConflicts string `json:"resolve-conflicts"`
+	// ServiceAccountRoleARN is the IAM role for the add-on service account.
+	// Empty to use the node role.
+	ServiceAccountRoleARN string `json:"service-account-role-arn"`
+	// ConfigurationValues is the add-on configuration values in JSON or YAML,
+	// which must match the add-on configuration schema.
+	// ref. https://docs.aws.amazon.com/eks/latest/APIReference/API_DescribeAddonConfiguration.html
+	ConfigurationValues string `json:"configuration-values"`
+	// Preserve is true to keep the add-on software on the cluster on delete.
+	Preserve bool `json:"preserve"`
+
+	// Existing is true when the managed add-on already existed before
+	// the creation, and thus is not deleted.
+	Existing bool `json:"existing" read-only:"true"`
+	// InstalledVersion is the current add-on version.
+	InstalledVersion string `json:"installed-version" read-only:"true"`
+	// Status is the current add-on status.
+	Status string `json:"status" read-only:"true"`
+}
+
+const (
+	// ManagedAddonVersionDefault resolves the default add-on version
+	// for the cluster version.
+	ManagedAddonVersionDefault = "default"
+	// ManagedAddonVersionLatest resolves the latest add-on version
+	// compatible with the cluster version.
+	ManagedAddonVersionLatest = "latest"
+)
+
+// EnvironmentVariablePrefixAddOnManagedAddons is the environment variable prefix used for "eksconfig".
+const EnvironmentVariablePrefixAddOnManagedAddons = AWS_K8S_TESTER_EKS_PREFIX + "ADD_ON_MANAGED_ADDONS_"
+
+// IsEnabledAddOnManagedAddons returns true if "AddOnManagedAddons" is enabled.
+// Otherwise, nil the field for "omitempty".
+func (cfg *Config) IsEnabledAddOnManagedAddons() bool {
+	if cfg.AddOnManagedAddons == nil {
+		return false
+	}
+	if cfg.AddOnManagedAddons.Enable {
+		return true
+	}
+	cfg.AddOnManagedAddons = nil
+	return false
+}
+
+func getDefaultAddOnManagedAddons() *AddOnManagedAddons {
+	return &AddOnManagedAddons{
+		Enable: false,
+		Addons: map[string]ManagedAddon{
+			"vpc-cni":    {ResolveConflicts: eks.ResolveConflictsOverwrite},
+			"coredns":    {ResolveConflicts: eks.ResolveConflictsOverwrite},
+			"kube-proxy": {ResolveConflicts: eks.ResolveConflictsOverwrite},
+		},
+	}
+}
+
+func (cfg *Config) validateAddOnManagedAddons() error {
+	if !cfg.IsEnabledAddOnManagedAddons() {
+		return nil
+	}
+	if len(cfg.AddOnManagedAddons.Addons) == 0 {
+		return errors.New("AddOnManagedAddons.Enable true but empty Addons")
+	}
+	for name, cur := range cfg.AddOnManagedAddons.Addons {
+		switch {
+		case name == "vpc-cni" && cfg.IsEnabledAddOnCNIVPC():
+			return errors.New("AddOnManagedAddons.Addons[\"vpc-cni\"] but AddOnCNIVPC.Enable true")
+		case name == "aws-ebs-csi-driver" && cfg.IsEnabledAddOnCSIEBS():
+			return errors.New("AddOnManagedAddons.Addons[\"aws-ebs-csi-driver\"] but AddOnCSIEBS.Enable true")
+		}
+		switch cur.ResolveConflicts {
+		case "":
+			cur.ResolveConflicts = eks.ResolveConflictsOverwrite
+		case eks.ResolveConflictsOverwrite, eks.ResolveConflictsNone, eks.ResolveConflictsPreserve:
+		default:
+			return fmt.Errorf("AddOnManagedAddons.Addons[%q] unknown ResolveConflicts %q (expected %q)", name, cur.ResolveConflicts, eks.ResolveConflicts_Values())
+		}
+		if cur.UpgradeVersion != "" && cur.UpgradeVersion == cur.Version {
+			return fmt.Errorf("AddOnManagedAddons.Addons[%q] UpgradeVersion %q same as Version", name, cur.UpgradeVersion)
+		}
+		cfg.AddOnManagedAddons.Addons[name] = cur
+	}
+	return nil
+}
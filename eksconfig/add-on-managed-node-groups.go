@@ -76,6 +76,13 @@ type MNG struct {
 	// ref. https://docs.aws.amazon.com/eks/latest/userguide/eks-linux-ami-versions.html
 	ReleaseVersion      string  `json:"release-version,omitempty"`
 	ReleaseVersionValue float64 `json:"release-version-value" read-only:"true"`
+	// Version is the Kubernetes version of the node group (e.g. "1.29").
+	// If empty, EKS uses the cluster version. The version may be older than
+	// the cluster version within the kubelet version skew limit
+	// (see "KubeletVersionSkewLimit"), to test N-1 or N-2 node groups.
+	// ref. https://docs.aws.amazon.com/eks/latest/APIReference/API_CreateNodegroup.html
+	Version      string  `json:"version,omitempty"`
+	VersionValue float64 `json:"version-value" read-only:"true"`
 
 	// AMIType is the AMI type for the node group.
 	// Allowed values are AL2_x86_64, AL2_x86_64_GPU and AL2_ARM_64.
@@ -87,6 +94,11 @@ type MNG struct {
 	// ref. https://docs.aws.amazon.com/eks/latest/userguide/create-managed-node-group.html
 	// ref. https://docs.aws.amazon.com/AWSCloudFormation/latest/UserGuide/aws-resource-eks-nodegroup.html
 	InstanceTypes []string `json:"instance-types,omitempty"`
+	// GPUsPerNode is the expected number of "nvidia.com/gpu" allocatable per node,
+	// only for the "AL2_x86_64_GPU" AMI type. Defaults from "GPUInstanceTypes"
+	// if all instance types have the same number of GPUs. If zero, the tester
+	// only expects at least one GPU per node.
+	GPUsPerNode int `json:"gpus-per-node,omitempty"`
 	// VolumeSize is the node volume size.
 	// ref. https://docs.aws.amazon.com/eks/latest/userguide/create-managed-node-group.html
 	// ref. https://docs.aws.amazon.com/AWSCloudFormation/latest/UserGuide/aws-resource-eks-nodegroup.html
@@ -105,6 +117,28 @@ type MNG struct {
 	// ref. https://docs.aws.amazon.com/AWSCloudFormation/latest/UserGuide/aws-resource-eks-nodegroup.html
 	ASGDesiredCapacity int `json:"asg-desired-capacity,omitempty"`
 
+	// CapacityType is the capacity type of the node group.
+	// Allowed values are ON_DEMAND and SPOT. If empty, EKS defaults to ON_DEMAND.
+	// SPOT requires multiple "InstanceTypes", and defaults to "DefaultSpotInstanceTypes*".
+	// ref. https://docs.aws.amazon.com/eks/latest/userguide/managed-node-groups.html#managed-node-group-capacity-types
+	CapacityType string `json:"capacity-type,omitempty"`
+	// SubnetIDs is the subnets for the node group.
+	// If empty, uses "VPC.PublicSubnetIDs".
+	SubnetIDs []string `json:"subnet-ids,omitempty"`
+	// Labels is the Kubernetes labels applied to the nodes,
+	// in addition to the default "NodeType", "AMIType", "NGType",
+	// and "NGName" labels that cannot be overwritten.
+	// The tester verifies that the labels appear on the Node objects.
+	Labels map[string]string `json:"labels,omitempty"`
+	// Taints is the Kubernetes taints applied to the nodes.
+	// The tester verifies that the taints appear on the Node objects.
+	// ref. https://docs.aws.amazon.com/eks/latest/userguide/node-taints-managed-node-groups.html
+	Taints []MNGTaint `json:"taints,omitempty"`
+	// LaunchTemplate configures the EC2 launch template of the node group.
+	// If nil or not enabled, the node group is created without a launch template.
+	// ref. https://docs.aws.amazon.com/eks/latest/userguide/launch-templates.html
+	LaunchTemplate *MNGLaunchTemplate `json:"launch-template,omitempty"`
+
 	// CreateRequested is true if "CreateNodegroupRequest" has been sent.
 	CreateRequested bool `json:"create-requested" read-only:"true"`
 
@@ -112,7 +146,8 @@ type MNG struct {
 	PhysicalID string `json:"physical-id" read-only:"true"`
 
 	// RemoteAccessSecurityGroupID is the security group ID for the MNG.
-	// Returned from EKS MNG API.
+	// Returned from EKS MNG API, or the launch template security group
+	// created by the tester when "LaunchTemplate" is enabled.
 	RemoteAccessSecurityGroupID string `json:"remote-access-security-group-id" read-only:"true"`
 
 	// Status is the current status of EKS "Managed Node Group".
@@ -129,6 +164,123 @@ type MNG struct {
 	VersionUpgrade *MNGVersionUpgrade `json:"version-upgrade,omitempty"`
 }
 
+// KubeletVersion returns the expected kubelet minor version of the node group,
+// which is the version upgrade target once upgraded, or "clusterVersion"
+// if neither "Version" nor "ReleaseVersion" is set.
+func (cur MNG) KubeletVersion(clusterVersion float64) float64 {
+	switch {
+	case cur.VersionUpgrade != nil && cur.VersionUpgrade.Enable && cur.VersionUpgrade.Created:
+		return cur.VersionUpgrade.VersionValue
+	case cur.VersionValue > 0.0:
+		return cur.VersionValue
+	case cur.ReleaseVersionValue > 0.0:
+		return cur.ReleaseVersionValue
+	}
+	return clusterVersion
+}
+
+// MNGTaint is the Kubernetes taint for the managed node group.
+type MNGTaint struct {
+	Key   string `json:"key"`
+	Value string `json:"value,omitempty"`
+	// Effect is the EKS API taint effect.
+	// Allowed values are NO_SCHEDULE, NO_EXECUTE, and PREFER_NO_SCHEDULE.
+	Effect string `json:"effect"`
+}
+
+// MNGLaunchTemplate defines the EC2 launch template of the managed node group.
+// The tester creates the launch template with a node security group, and
+// the node group references the template instead of its "VolumeSize" and
+// remote access configuration. A new template version is created and rolled
+// out on "VersionUpgrade".
+type MNGLaunchTemplate struct {
+	// Enable is 'true' to create the node group with a launch template.
+	Enable bool `json:"enable"`
+	// UserData is the shell script run on the node before the EKS bootstrap.
+	// EKS merges the script into the node user data as a MIME multi-part.
+	// Not supported for the Bottlerocket AMI types.
+	UserData string `json:"user-data,omitempty"`
+	// UpgradeUserData is the shell script of the template version created
+	// on "VersionUpgrade". If empty, the new version keeps "UserData".
+	UpgradeUserData string `json:"upgrade-user-data,omitempty"`
+	// ImageID is the custom AMI ID of the node group (e.g. a candidate image
+	// built by an AMI pipeline). EKS does not merge its bootstrap user data
+	// for custom AMIs, so the tester generates the bootstrap for "AMIType",
+	// run after "UserData". "Version" and "ReleaseVersion" must be empty.
+	// ref. https://docs.aws.amazon.com/eks/latest/userguide/launch-templates.html#launch-template-custom-ami
+	ImageID string `json:"image-id,omitempty"`
+	// ImageIDSSMParameter is the AWS Systems Manager Parameter Store path
+	// of the custom AMI ID, resolved on creation.
+	// Overrides "ImageID" if both are set.
+	ImageIDSSMParameter string `json:"image-id-ssm-parameter,omitempty"`
+	// BlockDevices is the block device mappings of the node.
+	// If empty, the root volume of "VolumeSize" is used.
+	BlockDevices []MNGBlockDevice `json:"block-devices,omitempty"`
+	// MetadataHTTPTokens is "required" to enforce IMDSv2, or "optional".
+	MetadataHTTPTokens string `json:"metadata-http-tokens,omitempty"`
+	// MetadataHTTPPutResponseHopLimit is the IMDS response hop limit (1 to 64).
+	// Set to 2 for the pods to reach IMDSv2.
+	MetadataHTTPPutResponseHopLimit int32 `json:"metadata-http-put-response-hop-limit,omitempty"`
+	// Tags is the tags applied to the node instances and volumes.
+	Tags map[string]string `json:"tags,omitempty"`
+
+	// ID is the created launch template ID.
+	ID string `json:"id" read-only:"true"`
+	// Name is the created launch template name.
+	Name string `json:"name" read-only:"true"`
+	// Version is the launch template version used by the node group.
+	Version int64 `json:"version" read-only:"true"`
+	// ResolvedImageID is the custom AMI ID of the launch template,
+	// which the tester expects in the "NodeLabelNodegroupImage" node label.
+	ResolvedImageID string `json:"resolved-image-id" read-only:"true"`
+	// SecurityGroupID is the node security group ID of the launch template.
+	SecurityGroupID string `json:"security-group-id" read-only:"true"`
+}
+
+// MNGBlockDevice is the EBS block device mapping of the launch template.
+type MNGBlockDevice struct {
+	// DeviceName is the device name (e.g. "/dev/xvda" for the root volume).
+	DeviceName string `json:"device-name"`
+	// VolumeSize is the volume size in GiB.
+	VolumeSize int32 `json:"volume-size"`
+	// VolumeType is the EBS volume type. Defaults to "gp3".
+	VolumeType string `json:"volume-type,omitempty"`
+	// IOPS is the provisioned IOPS for "gp3", "io1", and "io2".
+	IOPS int32 `json:"iops,omitempty"`
+	// Throughput is the provisioned throughput in MiB/s for "gp3".
+	Throughput int32 `json:"throughput,omitempty"`
+	// Encrypted is true to encrypt the volume.
+	Encrypted bool `json:"encrypted"`
+}
+
+// MNGReservedLabels are the node labels set by the tester,
+// which cannot be overwritten by "MNG.Labels".
+var MNGReservedLabels = []string{"NodeType", "AMIType", "NGType", "NGName", NodeLabelNodegroupImage}
+
+// NodeLabelNodegroupImage is the node label of the managed node group AMI ID,
+// set by EKS, or by the tester bootstrap user data for the custom AMI.
+const NodeLabelNodegroupImage = "eks.amazonaws.com/nodegroup-image"
+
+// IsCustomAMI returns true if the node group launch template
+// uses the custom AMI of "ImageID" or "ImageIDSSMParameter".
+func (cur MNG) IsCustomAMI() bool {
+	lt := cur.LaunchTemplate
+	return lt != nil && lt.Enable && (lt.ImageID != "" || lt.ImageIDSSMParameter != "")
+}
+
+var (
+	// DefaultSpotInstanceTypesCPU is the default EC2 instance types for Spot CPU managed node group.
+	// Multiple instance types in the same size diversify the Spot capacity pools.
+	DefaultSpotInstanceTypesCPU = []string{DefaultNodeInstanceTypeCPU, "c5a.xlarge", "c5d.xlarge", "m5.xlarge"}
+	// DefaultSpotInstanceTypesARMCPU is the default EC2 instance types for Spot ARM CPU managed node group.
+	DefaultSpotInstanceTypesARMCPU = []string{DefaultNodeInstanceTypeARMCPU, "c6gd.xlarge", "m6g.xlarge"}
+	// DefaultSpotInstanceTypesGPU is the default EC2 instance types for Spot GPU managed node group.
+	// All are the same size with a single GPU, so that the capacity and
+	// the GPU count per node are the same regardless of the instance type
+	// that Spot launches.
+	DefaultSpotInstanceTypesGPU = []string{"g4dn.xlarge", "g5.xlarge", "g6.xlarge"}
+)
+
 // MNGScaleUpdate contains the minimum, maximum, and desired node counts for a nodegroup.
 // ref, https://docs.aws.amazon.com/cli/latest/reference/eks/update-nodegroup-config.html
 type MNGScaleUpdate struct {
@@ -323,6 +475,24 @@ func (cfg *Config) validateAddOnManagedNodeGroups() error {
 			}
 		}
 
+		if cur.Version != "" {
+			var err error
+			cur.VersionValue, err = strconv.ParseFloat(cur.Version, 64)
+			if err != nil {
+				return fmt.Errorf("AddOnManagedNodeGroups.MNGs[%q] invalid Version %q (%v)", cur.Name, cur.Version, err)
+			}
+			skew := MinorVersionSkew(cfg.VersionValue, cur.VersionValue)
+			if skew < 0 {
+				return fmt.Errorf("AddOnManagedNodeGroups.MNGs[%q] Version %q is newer than Parameters.Version %q", cur.Name, cur.Version, cfg.Version)
+			}
+			if limit := KubeletVersionSkewLimit(cfg.VersionValue); skew > limit {
+				return fmt.Errorf("AddOnManagedNodeGroups.MNGs[%q] Version %q is %d minor versions older than Parameters.Version %q (limit %d)", cur.Name, cur.Version, skew, cfg.Version, limit)
+			}
+			if cur.ReleaseVersionValue > 0.0 && MinorVersionSkew(cur.VersionValue, cur.ReleaseVersionValue) != 0 {
+				return fmt.Errorf("AddOnManagedNodeGroups.MNGs[%q] Version %q does not match ReleaseVersion %q", cur.Name, cur.Version, cur.ReleaseVersion)
+			}
+		}
+
 		if len(cur.ScaleUpdates) > 0 {
 			for idx := range cur.ScaleUpdates {
 				if !cur.ScaleUpdates[idx].Enable {
@@ -346,6 +516,10 @@ func (cfg *Config) validateAddOnManagedNodeGroups() error {
 
 		// check optional mng version upgrade add-on
 		if cur.VersionUpgrade != nil && cur.VersionUpgrade.Enable {
+			// custom AMI is not upgraded by "UpdateNodegroupVersion"
+			if cur.IsCustomAMI() {
+				return fmt.Errorf("AddOnManagedNodeGroups.MNGs[%q] VersionUpgrade not supported for custom AMI", cur.Name)
+			}
 			var err error
 			if cur.VersionUpgrade.InitialWaitString != "" {
 				cur.VersionUpgrade.InitialWait, err = time.ParseDuration(cur.VersionUpgrade.InitialWaitString)
@@ -368,7 +542,10 @@ func (cfg *Config) validateAddOnManagedNodeGroups() error {
 				return fmt.Errorf("AddOnManagedNodeGroups.MNGs[%q] invalid VersionUpgrade.Version %q (%v)", cur.Name, cur.VersionUpgrade.Version, err)
 			}
 			origVer := cfg.VersionValue
-			if cur.ReleaseVersionValue > 0.0 {
+			switch {
+			case cur.VersionValue > 0.0:
+				origVer = cur.VersionValue
+			case cur.ReleaseVersionValue > 0.0:
 				// e.g. "1.16" in "1.16.8-20200609"
 				origVer = cur.ReleaseVersionValue
 			}
@@ -380,7 +557,7 @@ func (cfg *Config) validateAddOnManagedNodeGroups() error {
 			// target version must match with the Kubernetes control plane version
 			// can't upgrade to 1.17 MNG when EKS is 1.16
 			// e.g. "Nodegroup Kubernetes version should be equal to Cluster kubernetes version 1.16 or NodeGroup kubernetes version 1.16"
-			if cur.ReleaseVersionValue == 0.0 && !cfg.IsEnabledAddOnClusterVersionUpgrade() {
+			if MinorVersionSkew(cfg.VersionValue, cur.VersionUpgrade.VersionValue) < 0 && !cfg.IsEnabledAddOnClusterVersionUpgrade() {
 				return fmt.Errorf("AddOnManagedNodeGroups.MNGs[%q] VersionUpgrade %q would diverge from Parameters.Version %q (IsEnabledAddOnClusterVersionUpgrade %v)", cur.Name, cur.VersionUpgrade.Version, cfg.Version, cfg.IsEnabledAddOnClusterVersionUpgrade())
 			}
 		}
@@ -408,26 +585,50 @@ func (cfg *Config) validateAddOnManagedNodeGroups() error {
 			if cur.RemoteAccessUserName != "ec2-user" {
 				return fmt.Errorf("AMIType %q but unexpected RemoteAccessUserName %q", cur.AMIType, cur.RemoteAccessUserName)
 			}
+		case eks.AMITypesBottlerocketX8664, eks.AMITypesBottlerocketArm64:
+			if cur.RemoteAccessUserName != "ec2-user" {
+				return fmt.Errorf("AMIType %q but unexpected RemoteAccessUserName %q", cur.AMIType, cur.RemoteAccessUserName)
+			}
 		default:
 			return fmt.Errorf("unknown ASGs[%q].AMIType %q", k, cur.AMIType)
 		}
 
 		switch cur.AMIType {
-		case eks.AMITypesAl2X8664:
+		case eks.AMITypesAl2X8664, eks.AMITypesBottlerocketX8664:
 			if len(cur.InstanceTypes) == 0 {
 				cur.InstanceTypes = []string{DefaultNodeInstanceTypeCPU}
+				if cur.CapacityType == eks.CapacityTypesSpot {
+					cur.InstanceTypes = append([]string(nil), DefaultSpotInstanceTypesCPU...)
+				}
 			}
 		case eks.AMITypesAl2X8664Gpu:
 			if len(cur.InstanceTypes) == 0 {
 				cur.InstanceTypes = []string{DefaultNodeInstanceTypeGPU}
+				if cur.CapacityType == eks.CapacityTypesSpot {
+					cur.InstanceTypes = append([]string(nil), DefaultSpotInstanceTypesGPU...)
+				}
 			}
-		case eks.AMITypesAl2Arm64:
+		case eks.AMITypesAl2Arm64, eks.AMITypesBottlerocketArm64:
 			if len(cur.InstanceTypes) == 0 {
 				cur.InstanceTypes = []string{DefaultNodeInstanceTypeARMCPU}
+				if cur.CapacityType == eks.CapacityTypesSpot {
+					cur.InstanceTypes = append([]string(nil), DefaultSpotInstanceTypesARMCPU...)
+				}
 			}
 		default:
 			return fmt.Errorf("unknown AddOnManagedNodeGroups.MNGs[%q].AMIType %q", k, cur.AMIType)
 		}
+		if cur.AMIType == eks.AMITypesAl2X8664Gpu && cur.GPUsPerNode == 0 {
+			cur.GPUsPerNode = gpusPerNode(cur.InstanceTypes)
+		}
+		if err := validateGPUsPerNode(cur.AMIType, cur.GPUsPerNode); err != nil {
+			return fmt.Errorf("AddOnManagedNodeGroups.MNGs[%q] %v", k, err)
+		}
+		for _, itp := range cur.InstanceTypes {
+			if arch := InstanceTypeArch(itp); arch != AMITypeArch(cur.AMIType) {
+				return fmt.Errorf("AddOnManagedNodeGroups.MNGs[%q].InstanceTypes %q is %s but AMIType %q is %s", k, itp, arch, cur.AMIType, AMITypeArch(cur.AMIType))
+			}
+		}
 
 		if cfg.IsEnabledAddOnNLBHelloWorld() || cfg.IsEnabledAddOnALB2048() {
 			for _, itp := range cur.InstanceTypes {
@@ -468,6 +669,39 @@ func (cfg *Config) validateAddOnManagedNodeGroups() error {
 			return fmt.Errorf("AddOnManagedNodeGroups.MNGs[%q].ASGDesiredCapacity %d > MNGMaxLimit %d", k, cur.ASGDesiredCapacity, MNGMaxLimit)
 		}
 
+		switch cur.CapacityType {
+		case "", eks.CapacityTypesOnDemand:
+		case eks.CapacityTypesSpot:
+			// a single instance type is more likely to run out of Spot capacity
+			// ref. https://docs.aws.amazon.com/eks/latest/userguide/managed-node-groups.html#managed-node-group-capacity-types
+			if len(cur.InstanceTypes) < 2 {
+				return fmt.Errorf("AddOnManagedNodeGroups.MNGs[%q].CapacityType %q requires multiple InstanceTypes, got %q", k, cur.CapacityType, cur.InstanceTypes)
+			}
+		default:
+			return fmt.Errorf("unknown AddOnManagedNodeGroups.MNGs[%q].CapacityType %q", k, cur.CapacityType)
+		}
+		for _, lk := range MNGReservedLabels {
+			if _, ok := cur.Labels[lk]; ok {
+				return fmt.Errorf("AddOnManagedNodeGroups.MNGs[%q].Labels has reserved key %q", k, lk)
+			}
+		}
+		for i, tv := range cur.Taints {
+			if tv.Key == "" {
+				return fmt.Errorf("AddOnManagedNodeGroups.MNGs[%q].Taints[%d].Key is empty", k, i)
+			}
+			switch tv.Effect {
+			case eks.TaintEffectNoSchedule, eks.TaintEffectNoExecute, eks.TaintEffectPreferNoSchedule:
+			default:
+				return fmt.Errorf("unknown AddOnManagedNodeGroups.MNGs[%q].Taints[%d].Effect %q", k, i, tv.Effect)
+			}
+		}
+
+		if cur.LaunchTemplate != nil && cur.LaunchTemplate.Enable {
+			if err := validateMNGLaunchTemplate(cur); err != nil {
+				return fmt.Errorf("AddOnManagedNodeGroups.MNGs[%q].LaunchTemplate %v", k, err)
+			}
+		}
+
 		if cfg.IsEnabledAddOnNLBHelloWorld() && cfg.AddOnNLBHelloWorld.DeploymentReplicas < int32(cur.ASGDesiredCapacity) {
 			cfg.AddOnNLBHelloWorld.DeploymentReplicas = int32(cur.ASGDesiredCapacity)
 		}
@@ -487,3 +721,66 @@ func (cfg *Config) validateAddOnManagedNodeGroups() error {
 	cfg.AddOnManagedNodeGroups.MNGs = processed
 	return nil
 }
+
+func validateMNGLaunchTemplate(cur MNG) error {
+	lt := cur.LaunchTemplate
+	switch cur.AMIType {
+	case eks.AMITypesBottlerocketX8664, eks.AMITypesBottlerocketArm64:
+		if lt.UserData != "" || lt.UpgradeUserData != "" {
+			return fmt.Errorf("UserData not supported for AMIType %q", cur.AMIType)
+		}
+	}
+	if cur.IsCustomAMI() {
+		// same as "AddOnNodeGroups", prefer "ImageIDSSMParameter"
+		if lt.ImageIDSSMParameter != "" {
+			lt.ImageID = ""
+		}
+		if lt.ImageID != "" && !strings.HasPrefix(lt.ImageID, "ami-") {
+			return fmt.Errorf("invalid ImageID %q (expected \"ami-\" prefix)", lt.ImageID)
+		}
+		// EKS rejects the AMI version of the custom AMI
+		if cur.Version != "" || cur.ReleaseVersion != "" {
+			return fmt.Errorf("custom AMI but Version %q, ReleaseVersion %q (expected empty)", cur.Version, cur.ReleaseVersion)
+		}
+	}
+	switch lt.MetadataHTTPTokens {
+	case "":
+		lt.MetadataHTTPTokens = "required"
+	case "required", "optional":
+	default:
+		return fmt.Errorf("unknown MetadataHTTPTokens %q (expected \"required\" or \"optional\")", lt.MetadataHTTPTokens)
+	}
+	switch {
+	case lt.MetadataHTTPPutResponseHopLimit == 0:
+		lt.MetadataHTTPPutResponseHopLimit = 2
+	case lt.MetadataHTTPPutResponseHopLimit < 1 || lt.MetadataHTTPPutResponseHopLimit > 64:
+		return fmt.Errorf("invalid MetadataHTTPPutResponseHopLimit %d (expected 1 to 64)", lt.MetadataHTTPPutResponseHopLimit)
+	}
+	if len(lt.BlockDevices) == 0 {
+		lt.BlockDevices = []MNGBlockDevice{{DeviceName: "/dev/xvda", VolumeSize: int32(cur.VolumeSize), VolumeType: "gp3"}}
+	}
+	devices := make(map[string]struct{}, len(lt.BlockDevices))
+	for i, bd := range lt.BlockDevices {
+		if bd.DeviceName == "" {
+			return fmt.Errorf("BlockDevices[%d].DeviceName is empty", i)
+		}
+		if _, ok := devices[bd.DeviceName]; ok {
+			return fmt.Errorf("BlockDevices[%d].DeviceName %q is redundant", i, bd.DeviceName)
+		}
+		devices[bd.DeviceName] = struct{}{}
+		if bd.VolumeSize <= 0 {
+			return fmt.Errorf("BlockDevices[%d].VolumeSize must be >0", i)
+		}
+		switch bd.VolumeType {
+		case "":
+			lt.BlockDevices[i].VolumeType = "gp3"
+		case "gp2", "gp3", "io1", "io2", "st1", "sc1", "standard":
+		default:
+			return fmt.Errorf("unknown BlockDevices[%d].VolumeType %q", i, bd.VolumeType)
+		}
+		if bd.Throughput > 0 && lt.BlockDevices[i].VolumeType != "gp3" {
+			return fmt.Errorf("BlockDevices[%d].Throughput only supported for \"gp3\" (got %q)", i, lt.BlockDevices[i].VolumeType)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,186 @@
+package eksconfig
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-k8s-tester/pkg/metrics"
+	"github.com/aws/aws-k8s-tester/pkg/timeutil"
+)
+
+// AddOnMNGRollingUpgrade defines parameters for EKS cluster
+// add-on managed node group rolling AMI upgrade tester, which deploys
+// a workload protected by a PodDisruptionBudget, rolls the managed node
+// group to a new AMI release via "UpdateNodegroupVersion", and validates
+// that the rolling replacement respects "MaxUnavailable" and the
+// PodDisruptionBudget. Set the managed node group "ReleaseVersion" to an
+// older AMI release, in order to roll to the latest.
+// ref. https://docs.aws.amazon.com/eks/latest/userguide/managed-node-update-behavior.html
+type AddOnMNGRollingUpgrade struct {
+	// Enable is 'true' to create this add-on.
+	Enable bool `json:"enable"`
+	// Created is true when the resource has been created.
+	// Used for delete operations.
+	Created         bool               `json:"created" read-only:"true"`
+	TimeFrameCreate timeutil.TimeFrame `json:"time-frame-create" read-only:"true"`
+	TimeFrameDelete timeutil.TimeFrame `json:"time-frame-delete" read-only:"true"`
+
+	// CleanupPolicy is the policy to clean up the add-on resources on delete,
+	// "delete", "retain", or "retain-on-failure" (see "CleanupPolicyRetainOnFailure").
+	// Defaults to "delete".
+	CleanupPolicy string `json:"cleanup-policy"`
+	// CreateFailed is true when the creation failed.
+	CreateFailed bool `json:"create-failed" read-only:"true"`
+
+	// Namespace is the namespace to create the test Deployment
+	// and its PodDisruptionBudget.
+	Namespace string `json:"namespace"`
+	// MNGName is the name of the managed node group to upgrade.
+	// Defaults to the first managed node group, in the order of names,
+	// that neither uses a custom AMI nor enables "VersionUpgrade".
+	MNGName string `json:"mng-name"`
+	// ReleaseVersion is the AMI release version to upgrade to
+	// (e.g. "1.18.9-20201211"). Leave empty to upgrade to the latest
+	// AMI release of the managed node group Kubernetes version.
+	ReleaseVersion string `json:"release-version"`
+	// MaxUnavailable is the maximum number of nodes unavailable at once
+	// during the upgrade, set via "UpdateNodegroupConfig" before the upgrade.
+	MaxUnavailable int `json:"max-unavailable"`
+
+	// DeploymentReplicas is the number of replicas of the test Deployment
+	// scheduled on the managed node group.
+	DeploymentReplicas int32 `json:"deployment-replicas"`
+	// PDBMaxUnavailable is the "maxUnavailable" of the PodDisruptionBudget
+	// of the test Deployment. Must be less than "DeploymentReplicas".
+	PDBMaxUnavailable int32 `json:"pdb-max-unavailable"`
+
+	// Timeout is the maximum duration for the upgrade to complete,
+	// since the "UpdateNodegroupVersion" request.
+	Timeout       time.Duration `json:"timeout,omitempty" read-only:"true"`
+	TimeoutString string        `json:"timeout-string,omitempty"`
+
+	// ReleaseVersionFrom is the AMI release version before the upgrade.
+	ReleaseVersionFrom string `json:"release-version-from" read-only:"true"`
+	// ReleaseVersionTo is the AMI release version after the upgrade.
+	ReleaseVersionTo string `json:"release-version-to" read-only:"true"`
+	// ObservedMaxUnavailable is the maximum number of old nodes observed
+	// cordoned or not Ready at once during the upgrade.
+	ObservedMaxUnavailable int `json:"observed-max-unavailable" read-only:"true"`
+	// ObservedMinReadyPods is the minimum number of Ready test pods
+	// observed during the upgrade.
+	ObservedMinReadyPods int32 `json:"observed-min-ready-pods" read-only:"true"`
+
+	//////////////////////////////////////////////////////////////////////////////
+
+	// RequestsSummaryReplace is the upgrade results, with the time for each
+	// old node to be drained and removed since the upgrade request.
+	RequestsSummaryReplace          metrics.RequestsSummary `json:"requests-summary-replace,omitempty" read-only:"true"`
+	RequestsSummaryReplaceJSONPath  string                  `json:"requests-summary-replace-json-path" read-only:"true"`
+	RequestsSummaryReplaceTablePath string                  `json:"requests-summary-replace-table-path" read-only:"true"`
+
+	//////////////////////////////////////////////////////////////////////////////
+}
+
+// EnvironmentVariablePrefixAddOnMNGRollingUpgrade is the environment variable prefix used for "eksconfig".
+const EnvironmentVariablePrefixAddOnMNGRollingUpgrade = AWS_K8S_TESTER_EKS_PREFIX + "ADD_ON_MNG_ROLLING_UPGRADE_"
+
+// IsEnabledAddOnMNGRollingUpgrade returns true if "AddOnMNGRollingUpgrade" is enabled.
+// Otherwise, nil the field for "omitempty".
+func (cfg *Config) IsEnabledAddOnMNGRollingUpgrade() bool {
+	if cfg.AddOnMNGRollingUpgrade == nil {
+		return false
+	}
+	if cfg.AddOnMNGRollingUpgrade.Enable {
+		return true
+	}
+	cfg.AddOnMNGRollingUpgrade = nil
+	return false
+}
+
+func getDefaultAddOnMNGRollingUpgrade() *AddOnMNGRollingUpgrade {
+	return &AddOnMNGRollingUpgrade{
+		Enable:             false,
+		MaxUnavailable:     1,
+		DeploymentReplicas: 3,
+		PDBMaxUnavailable:  1,
+		Timeout:            time.Hour,
+	}
+}
+
+func (cfg *Config) validateAddOnMNGRollingUpgrade() error {
+	if !cfg.IsEnabledAddOnMNGRollingUpgrade() {
+		return nil
+	}
+	if !cfg.IsEnabledAddOnManagedNodeGroups() {
+		return errors.New("AddOnMNGRollingUpgrade.Enable true but AddOnManagedNodeGroups.Enable false")
+	}
+	if cfg.AddOnMNGRollingUpgrade.MNGName == "" {
+		names := make([]string, 0, len(cfg.AddOnManagedNodeGroups.MNGs))
+		for k, cur := range cfg.AddOnManagedNodeGroups.MNGs {
+			if cur.IsCustomAMI() || (cur.VersionUpgrade != nil && cur.VersionUpgrade.Enable) {
+				continue
+			}
+			names = append(names, k)
+		}
+		if len(names) == 0 {
+			return errors.New("AddOnMNGRollingUpgrade.Enable true but no managed node group to upgrade")
+		}
+		sort.Strings(names)
+		cfg.AddOnMNGRollingUpgrade.MNGName = names[0]
+	}
+	cur, ok := cfg.AddOnManagedNodeGroups.MNGs[cfg.AddOnMNGRollingUpgrade.MNGName]
+	if !ok {
+		return fmt.Errorf("AddOnMNGRollingUpgrade.MNGName %q not found", cfg.AddOnMNGRollingUpgrade.MNGName)
+	}
+	// custom AMI is not upgraded by "UpdateNodegroupVersion"
+	if cur.IsCustomAMI() {
+		return fmt.Errorf("AddOnMNGRollingUpgrade.MNGName %q uses custom AMI", cfg.AddOnMNGRollingUpgrade.MNGName)
+	}
+	if cur.VersionUpgrade != nil && cur.VersionUpgrade.Enable {
+		return fmt.Errorf("AddOnMNGRollingUpgrade.MNGName %q already enables VersionUpgrade", cfg.AddOnMNGRollingUpgrade.MNGName)
+	}
+	if cfg.AddOnMNGRollingUpgrade.ReleaseVersion != "" && cfg.AddOnMNGRollingUpgrade.ReleaseVersion == cur.ReleaseVersion {
+		return fmt.Errorf("AddOnMNGRollingUpgrade.ReleaseVersion %q same as MNGs[%q].ReleaseVersion (nothing to upgrade)", cfg.AddOnMNGRollingUpgrade.ReleaseVersion, cur.Name)
+	}
+	// ref. https://docs.aws.amazon.com/eks/latest/APIReference/API_NodegroupUpdateConfig.html
+	if cfg.AddOnMNGRollingUpgrade.MaxUnavailable < 1 || cfg.AddOnMNGRollingUpgrade.MaxUnavailable > 100 {
+		return fmt.Errorf("invalid AddOnMNGRollingUpgrade.MaxUnavailable %d (expected 1 to 100)", cfg.AddOnMNGRollingUpgrade.MaxUnavailable)
+	}
+
+	if cfg.AddOnMNGRollingUpgrade.Namespace == "" {
+		cfg.AddOnMNGRollingUpgrade.Namespace = cfg.Name + "-mng-rolling-upgrade"
+	}
+	if cfg.AddOnMNGRollingUpgrade.PDBMaxUnavailable < 1 {
+		return fmt.Errorf("AddOnMNGRollingUpgrade.PDBMaxUnavailable %d must be >0", cfg.AddOnMNGRollingUpgrade.PDBMaxUnavailable)
+	}
+	// otherwise, the PodDisruptionBudget allows evicting all pods at once
+	if cfg.AddOnMNGRollingUpgrade.DeploymentReplicas <= cfg.AddOnMNGRollingUpgrade.PDBMaxUnavailable {
+		return fmt.Errorf("AddOnMNGRollingUpgrade.DeploymentReplicas %d <= PDBMaxUnavailable %d", cfg.AddOnMNGRollingUpgrade.DeploymentReplicas, cfg.AddOnMNGRollingUpgrade.PDBMaxUnavailable)
+	}
+
+	if cfg.AddOnMNGRollingUpgrade.TimeoutString != "" {
+		var err error
+		cfg.AddOnMNGRollingUpgrade.Timeout, err = time.ParseDuration(cfg.AddOnMNGRollingUpgrade.TimeoutString)
+		if err != nil {
+			return fmt.Errorf("invalid AddOnMNGRollingUpgrade.TimeoutString %q (%v)", cfg.AddOnMNGRollingUpgrade.TimeoutString, err)
+		}
+	}
+	if cfg.AddOnMNGRollingUpgrade.Timeout < 10*time.Minute {
+		return fmt.Errorf("AddOnMNGRollingUpgrade.Timeout %v too short (expected >= 10m)", cfg.AddOnMNGRollingUpgrade.Timeout)
+	}
+	cfg.AddOnMNGRollingUpgrade.TimeoutString = cfg.AddOnMNGRollingUpgrade.Timeout.String()
+
+	//////////////////////////////////////////////////////////////////////////////
+	if cfg.AddOnMNGRollingUpgrade.RequestsSummaryReplaceJSONPath == "" {
+		cfg.AddOnMNGRollingUpgrade.RequestsSummaryReplaceJSONPath = strings.ReplaceAll(cfg.ConfigPath, ".yaml", "") + "-mng-rolling-upgrade-requests-summary-replace.json"
+	}
+	if cfg.AddOnMNGRollingUpgrade.RequestsSummaryReplaceTablePath == "" {
+		cfg.AddOnMNGRollingUpgrade.RequestsSummaryReplaceTablePath = strings.ReplaceAll(cfg.ConfigPath, ".yaml", "") + "-mng-rolling-upgrade-requests-summary-replace.txt"
+	}
+	//////////////////////////////////////////////////////////////////////////////
+
+	return nil
+}
@@ -0,0 +1,142 @@
+package eksconfig
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-k8s-tester/pkg/metrics"
+	"github.com/aws/aws-k8s-tester/pkg/timeutil"
+)
+
+// AddOnMNGScale defines parameters for EKS cluster
+// add-on managed node group scale out/in tester, which scales
+// the managed node group from "ASGMinSize" to "ASGMaxSize" and back
+// via "UpdateNodegroupConfig", and records the time for each new node
+// to be Ready on scale-out, and for each node to be drained and removed
+// on scale-in.
+// ref. https://docs.aws.amazon.com/cli/latest/reference/eks/update-nodegroup-config.html
+type AddOnMNGScale struct {
+	// Enable is 'true' to create this add-on.
+	Enable bool `json:"enable"`
+	// Created is true when the resource has been created.
+	// Used for delete operations.
+	Created         bool               `json:"created" read-only:"true"`
+	TimeFrameCreate timeutil.TimeFrame `json:"time-frame-create" read-only:"true"`
+	TimeFrameDelete timeutil.TimeFrame `json:"time-frame-delete" read-only:"true"`
+
+	// CleanupPolicy is the policy to clean up the add-on resources on delete,
+	// "delete", "retain", or "retain-on-failure" (see "CleanupPolicyRetainOnFailure").
+	// Defaults to "delete".
+	CleanupPolicy string `json:"cleanup-policy"`
+	// CreateFailed is true when the creation failed.
+	CreateFailed bool `json:"create-failed" read-only:"true"`
+
+	// MNGName is the name of the managed node group to scale.
+	// Defaults to the first managed node group, in the order of names.
+	// "ASGMaxSize" must be greater than "ASGMinSize".
+	MNGName string `json:"mng-name"`
+
+	// Timeout is the maximum duration for each of scale-out and scale-in,
+	// since the "UpdateNodegroupConfig" request. Nodes not Ready (or not
+	// removed) within the timeout are counted as failures.
+	Timeout       time.Duration `json:"timeout,omitempty" read-only:"true"`
+	TimeoutString string        `json:"timeout-string,omitempty"`
+
+	//////////////////////////////////////////////////////////////////////////////
+
+	// RequestsSummaryScaleOut is the scale-out results,
+	// with the time-to-Ready of each new node.
+	RequestsSummaryScaleOut          metrics.RequestsSummary `json:"requests-summary-scale-out,omitempty" read-only:"true"`
+	RequestsSummaryScaleOutJSONPath  string                  `json:"requests-summary-scale-out-json-path" read-only:"true"`
+	RequestsSummaryScaleOutTablePath string                  `json:"requests-summary-scale-out-table-path" read-only:"true"`
+
+	// RequestsSummaryScaleIn is the scale-in results,
+	// with the time-to-drain of each removed node.
+	RequestsSummaryScaleIn          metrics.RequestsSummary `json:"requests-summary-scale-in,omitempty" read-only:"true"`
+	RequestsSummaryScaleInJSONPath  string                  `json:"requests-summary-scale-in-json-path" read-only:"true"`
+	RequestsSummaryScaleInTablePath string                  `json:"requests-summary-scale-in-table-path" read-only:"true"`
+
+	//////////////////////////////////////////////////////////////////////////////
+}
+
+// EnvironmentVariablePrefixAddOnMNGScale is the environment variable prefix used for "eksconfig".
+const EnvironmentVariablePrefixAddOnMNGScale = AWS_K8S_TESTER_EKS_PREFIX + "ADD_ON_MNG_SCALE_"
+
+// IsEnabledAddOnMNGScale returns true if "AddOnMNGScale" is enabled.
+// Otherwise, nil the field for "omitempty".
+func (cfg *Config) IsEnabledAddOnMNGScale() bool {
+	if cfg.AddOnMNGScale == nil {
+		return false
+	}
+	if cfg.AddOnMNGScale.Enable {
+		return true
+	}
+	cfg.AddOnMNGScale = nil
+	return false
+}
+
+func getDefaultAddOnMNGScale() *AddOnMNGScale {
+	return &AddOnMNGScale{
+		Enable:  false,
+		Timeout: 30 * time.Minute,
+	}
+}
+
+func (cfg *Config) validateAddOnMNGScale() error {
+	if !cfg.IsEnabledAddOnMNGScale() {
+		return nil
+	}
+	if !cfg.IsEnabledAddOnManagedNodeGroups() {
+		return errors.New("AddOnMNGScale.Enable true but AddOnManagedNodeGroups.Enable false")
+	}
+	if cfg.AddOnMNGScale.MNGName == "" {
+		names := make([]string, 0, len(cfg.AddOnManagedNodeGroups.MNGs))
+		for k := range cfg.AddOnManagedNodeGroups.MNGs {
+			names = append(names, k)
+		}
+		if len(names) == 0 {
+			return errors.New("AddOnMNGScale.Enable true but no managed node group")
+		}
+		sort.Strings(names)
+		cfg.AddOnMNGScale.MNGName = names[0]
+	}
+	cur, ok := cfg.AddOnManagedNodeGroups.MNGs[cfg.AddOnMNGScale.MNGName]
+	if !ok {
+		return fmt.Errorf("AddOnMNGScale.MNGName %q not found", cfg.AddOnMNGScale.MNGName)
+	}
+	if cur.ASGMaxSize <= cur.ASGMinSize {
+		return fmt.Errorf("AddOnMNGScale.MNGName %q ASGMaxSize %d <= ASGMinSize %d (nothing to scale)", cfg.AddOnMNGScale.MNGName, cur.ASGMaxSize, cur.ASGMinSize)
+	}
+
+	if cfg.AddOnMNGScale.TimeoutString != "" {
+		var err error
+		cfg.AddOnMNGScale.Timeout, err = time.ParseDuration(cfg.AddOnMNGScale.TimeoutString)
+		if err != nil {
+			return fmt.Errorf("invalid AddOnMNGScale.TimeoutString %q (%v)", cfg.AddOnMNGScale.TimeoutString, err)
+		}
+	}
+	if cfg.AddOnMNGScale.Timeout < 5*time.Minute {
+		return fmt.Errorf("AddOnMNGScale.Timeout %v too short (expected >= 5m)", cfg.AddOnMNGScale.Timeout)
+	}
+	cfg.AddOnMNGScale.TimeoutString = cfg.AddOnMNGScale.Timeout.String()
+
+	//////////////////////////////////////////////////////////////////////////////
+	if cfg.AddOnMNGScale.RequestsSummaryScaleOutJSONPath == "" {
+		cfg.AddOnMNGScale.RequestsSummaryScaleOutJSONPath = strings.ReplaceAll(cfg.ConfigPath, ".yaml", "") + "-mng-scale-requests-summary-scale-out.json"
+	}
+	if cfg.AddOnMNGScale.RequestsSummaryScaleOutTablePath == "" {
+		cfg.AddOnMNGScale.RequestsSummaryScaleOutTablePath = strings.ReplaceAll(cfg.ConfigPath, ".yaml", "") + "-mng-scale-requests-summary-scale-out.txt"
+	}
+	if cfg.AddOnMNGScale.RequestsSummaryScaleInJSONPath == "" {
+		cfg.AddOnMNGScale.RequestsSummaryScaleInJSONPath = strings.ReplaceAll(cfg.ConfigPath, ".yaml", "") + "-mng-scale-requests-summary-scale-in.json"
+	}
+	if cfg.AddOnMNGScale.RequestsSummaryScaleInTablePath == "" {
+		cfg.AddOnMNGScale.RequestsSummaryScaleInTablePath = strings.ReplaceAll(cfg.ConfigPath, ".yaml", "") + "-mng-scale-requests-summary-scale-in.txt"
+	}
+	//////////////////////////////////////////////////////////////////////////////
+
+	return nil
+}
@@ -17,6 +17,13 @@ type AddOnNLBHelloWorld struct {
 	TimeFrameCreate timeutil.TimeFrame `json:"time-frame-create" read-only:"true"`
 	TimeFrameDelete timeutil.TimeFrame `json:"time-frame-delete" read-only:"true"`
 
+	// CleanupPolicy is the policy to clean up the add-on resources on delete,
+	// "delete", "retain", or "retain-on-failure" (see "CleanupPolicyRetainOnFailure").
+	// Defaults to "delete".
+	CleanupPolicy string `json:"cleanup-policy"`
+	// CreateFailed is true when the creation failed.
+	CreateFailed bool `json:"create-failed" read-only:"true"`
+
 	// Namespace is the namespace to create objects in.
 	Namespace string `json:"namespace"`
 
@@ -62,8 +69,8 @@ func (cfg *Config) validateAddOnNLBHelloWorld() error {
 	if !cfg.IsEnabledAddOnNLBHelloWorld() {
 		return nil
 	}
-	if !cfg.IsEnabledAddOnNodeGroups() && !cfg.IsEnabledAddOnManagedNodeGroups() {
-		return errors.New("AddOnNLBHelloWorld.Enable true but no node group is enabled")
+	if !cfg.IsEnabledAddOnNodeGroups() && !cfg.IsEnabledAddOnManagedNodeGroups() && !cfg.IsEnabledAutoMode() && !cfg.IsEnabledAttach() {
+		return errors.New("AddOnNLBHelloWorld.Enable true but no node group, AutoMode, or Attach is enabled")
 	}
 	if cfg.AddOnNLBHelloWorld.Namespace == "" {
 		cfg.AddOnNLBHelloWorld.Namespace = cfg.Name + "-nlb-hello-world"
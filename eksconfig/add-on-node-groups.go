@@ -66,6 +66,11 @@ type ASG struct {
 	// e.g. '--pause-container-account 012345678901 --pause-container-version 3.3'
 	BootstrapArgs string `json:"bootstrap-args"`
 
+	// GPUsPerNode is the expected number of "nvidia.com/gpu" allocatable per node,
+	// only for the "AL2_x86_64_GPU" AMI type. Defaults from "GPUInstanceTypes".
+	// If zero, the tester only expects at least one GPU per node.
+	GPUsPerNode int `json:"gpus-per-node,omitempty"`
+
 	// ClusterAutoscaler is enabled to run cluster auto-scaler per node group.
 	// ref. https://github.com/kubernetes/autoscaler/tree/master/cluster-autoscaler
 	ClusterAutoscaler *NGClusterAutoscaler `json:"cluster-autoscaler,omitempty"`
@@ -204,6 +209,19 @@ func (cfg *Config) validateAddOnNodeGroups() error {
 			cur.RemoteAccessUserName = "ec2-user"
 		}
 
+		// default node group comes with Amazon Linux 2 SSM parameter
+		if cur.AMIType == ec2config.AMITypeWindowsServerCore2019X8664 &&
+			((cur.ImageID == "" && cur.ImageIDSSMParameter == "") || strings.HasPrefix(cur.ImageIDSSMParameter, "/aws/service/eks/optimized-ami/")) {
+			cur.ImageIDSSMParameter = fmt.Sprintf(windowsImageIDSSMParameter, cfg.Version)
+		}
+		if ec2config.IsBottlerocket(cur.AMIType) &&
+			((cur.ImageID == "" && cur.ImageIDSSMParameter == "") || strings.HasPrefix(cur.ImageIDSSMParameter, "/aws/service/eks/optimized-ami/")) {
+			arch := "x86_64"
+			if cur.AMIType == ec2config.AMITypeBottleRocketARM64 {
+				arch = "arm64"
+			}
+			cur.ImageIDSSMParameter = fmt.Sprintf(bottlerocketImageIDSSMParameter, cfg.Version, arch)
+		}
 		if cur.ImageID == "" && cur.ImageIDSSMParameter == "" {
 			return fmt.Errorf("%q both ImageID and ImageIDSSMParameter are empty", cur.Name)
 		}
@@ -216,7 +234,7 @@ func (cfg *Config) validateAddOnNodeGroups() error {
 		}
 
 		switch cur.AMIType {
-		case ec2config.AMITypeBottleRocketCPU:
+		case ec2config.AMITypeBottleRocketCPU, ec2config.AMITypeBottleRocketARM64:
 			if cur.RemoteAccessUserName != "ec2-user" {
 				return fmt.Errorf("AMIType %q but unexpected RemoteAccessUserName %q", cur.AMIType, cur.RemoteAccessUserName)
 			}
@@ -253,6 +271,10 @@ func (cfg *Config) validateAddOnNodeGroups() error {
 			if cur.InstanceType == "" {
 				cur.InstanceType = DefaultNodeInstanceTypeCPU
 			}
+		case ec2config.AMITypeBottleRocketARM64, fmt.Sprint(aws_eks_v2_types.AMITypesAl2Arm64):
+			if cur.InstanceType == "" {
+				cur.InstanceType = DefaultNodeInstanceTypeARMCPU
+			}
 		case fmt.Sprint(aws_eks_v2_types.AMITypesAl2X8664), ec2config.AMITypeWindowsServerCore2019X8664:
 			if cur.InstanceType == "" {
 				cur.InstanceType = DefaultNodeInstanceTypeCPU
@@ -264,6 +286,15 @@ func (cfg *Config) validateAddOnNodeGroups() error {
 		default:
 			return fmt.Errorf("unknown AddOnNodeGroups.ASGs[%q].AMIType %q", k, cur.AMIType)
 		}
+		if cur.AMIType == ec2config.AMITypeAL2X8664GPU && cur.GPUsPerNode == 0 {
+			cur.GPUsPerNode = gpusPerNode([]string{cur.InstanceType})
+		}
+		if err := validateGPUsPerNode(cur.AMIType, cur.GPUsPerNode); err != nil {
+			return fmt.Errorf("AddOnNodeGroups.ASGs[%q] %v", k, err)
+		}
+		if arch := InstanceTypeArch(cur.InstanceType); arch != AMITypeArch(cur.AMIType) {
+			return fmt.Errorf("AddOnNodeGroups.ASGs[%q].InstanceType %q is %s but AMIType %q is %s", k, cur.InstanceType, arch, cur.AMIType, AMITypeArch(cur.AMIType))
+		}
 
 		if cfg.IsEnabledAddOnNLBHelloWorld() || cfg.IsEnabledAddOnALB2048() {
 			// "m3.xlarge" or "c4.xlarge" will fail with "InvalidTarget: Targets {...} are not supported"
@@ -337,9 +368,46 @@ func (cfg *Config) validateAddOnNodeGroups() error {
 	}
 
 	cfg.AddOnNodeGroups.ASGs = processed
+
+	// CoreDNS and VPC resource controller only run on Linux
+	// ref. https://docs.aws.amazon.com/eks/latest/userguide/windows-support.html
+	if cfg.HasWindowsNodeGroup() {
+		linuxFound := cfg.IsEnabledAddOnManagedNodeGroups()
+		for _, cur := range cfg.AddOnNodeGroups.ASGs {
+			if cur.AMIType != ec2config.AMITypeWindowsServerCore2019X8664 {
+				linuxFound = true
+				break
+			}
+		}
+		if !linuxFound {
+			return fmt.Errorf("AMIType %q requires at least one Linux node group", ec2config.AMITypeWindowsServerCore2019X8664)
+		}
+	}
 	return nil
 }
 
+// windowsImageIDSSMParameter is the SSM parameter format for
+// the EKS optimized Windows AMI, with the Kubernetes version.
+const windowsImageIDSSMParameter = "/aws/service/ami-windows-latest/Windows_Server-2019-English-Core-EKS_Optimized-%s/image_id"
+
+// bottlerocketImageIDSSMParameter is the SSM parameter format for
+// the Bottlerocket AMI, with the Kubernetes version and architecture.
+// ref. https://github.com/bottlerocket-os/bottlerocket/blob/develop/QUICKSTART-EKS.md
+const bottlerocketImageIDSSMParameter = "/aws/service/bottlerocket/aws-k8s-%s/%s/latest/image_id"
+
+// HasWindowsNodeGroup returns true if any node group uses Windows AMI.
+func (cfg *Config) HasWindowsNodeGroup() bool {
+	if cfg.AddOnNodeGroups == nil || !cfg.AddOnNodeGroups.Enable {
+		return false
+	}
+	for _, cur := range cfg.AddOnNodeGroups.ASGs {
+		if cur.AMIType == ec2config.AMITypeWindowsServerCore2019X8664 {
+			return true
+		}
+	}
+	return false
+}
+
 func (addOn *AddOnNodeGroups) IsEnabledClusterAutoscaler() bool {
 	if addOn == nil {
 		return false
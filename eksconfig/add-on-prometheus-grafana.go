@@ -23,9 +23,17 @@ type AddOnPrometheusGrafana struct {
 	TimeFrameCreate timeutil.TimeFrame `json:"time-frame-create" read-only:"true"`
 	TimeFrameDelete timeutil.TimeFrame `json:"time-frame-delete" read-only:"true"`
 
+	// CleanupPolicy is the policy to clean up the add-on resources on delete,
+	// "delete", "retain", or "retain-on-failure" (see "CleanupPolicyRetainOnFailure").
+	// Defaults to "delete".
+	CleanupPolicy string `json:"cleanup-policy"`
+	// CreateFailed is true when the creation failed.
+	CreateFailed bool `json:"create-failed" read-only:"true"`
+
 	// GrafanaAdminUserName is the admin user for the Grafana service.
 	GrafanaAdminUserName string `json:"grafana-admin-user-name"`
 	// GrafanaAdminPassword is the admin password for the Grafana service.
+	// Can be an "ssm://" or "secretsmanager://" URI (see "ResolveSecrets").
 	GrafanaAdminPassword string `json:"grafana-admin-password"`
 	// GrafanaNLBARN is the ARN of the NLB created from the Grafana service.
 	GrafanaNLBARN string `json:"grafana-nlb-arn" read-only:"true"`
@@ -63,9 +71,6 @@ func (cfg *Config) validateAddOnPrometheusGrafana() error {
 	if !cfg.IsEnabledAddOnPrometheusGrafana() {
 		return nil
 	}
-	if !cfg.IsEnabledAddOnCSIEBS() {
-		return errors.New("AddOnPrometheusGrafana.Enable true but IsEnabledAddOnCSIEBS.Enable false")
-	}
 	if !cfg.IsEnabledAddOnNodeGroups() && !cfg.IsEnabledAddOnManagedNodeGroups() {
 		return errors.New("AddOnPrometheusGrafana.Enable true but no node group is enabled")
 	}
@@ -82,7 +87,7 @@ func (cfg *Config) validateAddOnPrometheusGrafana() error {
 			case ec2config.AMITypeAL2X8664,
 				ec2config.AMITypeAL2X8664GPU:
 				x86Found = true
-			case ec2config.AMITypeBottleRocketCPU:
+			case ec2config.AMITypeBottleRocketCPU, ec2config.AMITypeBottleRocketARM64:
 				rocketFound = true
 			}
 		}
@@ -97,7 +102,7 @@ func (cfg *Config) validateAddOnPrometheusGrafana() error {
 			case eks.AMITypesAl2X8664,
 				eks.AMITypesAl2X8664Gpu:
 				x86Found = true
-			case ec2config.AMITypeBottleRocketCPU:
+			case ec2config.AMITypeBottleRocketCPU, ec2config.AMITypeBottleRocketARM64:
 				rocketFound = true
 			}
 		}
@@ -0,0 +1,155 @@
+package eksconfig
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/aws/aws-k8s-tester/pkg/timeutil"
+	"github.com/aws/aws-sdk-go/service/eks"
+)
+
+// AddOnSpotInterruption defines parameters for EKS cluster
+// add-on Spot interruption validation, which interrupts a node of
+// the Spot managed node group, and verifies that the node is drained
+// and replaced, and that the test pods are rescheduled within "SLO".
+// ref. https://docs.aws.amazon.com/eks/latest/userguide/managed-node-groups.html#managed-node-group-capacity-types
+type AddOnSpotInterruption struct {
+	// Enable is 'true' to create this add-on.
+	Enable bool `json:"enable"`
+	// Created is true when the resource has been created.
+	// Used for delete operations.
+	Created         bool               `json:"created" read-only:"true"`
+	TimeFrameCreate timeutil.TimeFrame `json:"time-frame-create" read-only:"true"`
+	TimeFrameDelete timeutil.TimeFrame `json:"time-frame-delete" read-only:"true"`
+
+	// CleanupPolicy is the policy to clean up the add-on resources on delete,
+	// "delete", "retain", or "retain-on-failure" (see "CleanupPolicyRetainOnFailure").
+	// Defaults to "delete".
+	CleanupPolicy string `json:"cleanup-policy"`
+	// CreateFailed is true when the creation failed.
+	CreateFailed bool `json:"create-failed" read-only:"true"`
+
+	// Namespace is the namespace to create the test Deployment.
+	Namespace string `json:"namespace"`
+	// MNGName is the name of the Spot managed node group to interrupt.
+	// Defaults to the first Spot managed node group, in the order of names.
+	MNGName string `json:"mng-name"`
+	// DeploymentReplicas is the number of replicas of the test Deployment
+	// scheduled on the Spot managed node group.
+	DeploymentReplicas int32 `json:"deployment-replicas"`
+
+	// UseFIS is true to interrupt the Spot instance with the AWS Fault
+	// Injection Service "aws:ec2:send-spot-instance-interruptions" action,
+	// which sends the two-minute interruption notice. Otherwise, the instance
+	// is terminated via the Auto Scaling group, which the managed node group
+	// drains with its lifecycle hook.
+	// ref. https://docs.aws.amazon.com/fis/latest/userguide/fis-actions-reference.html#send-spot-instance-interruptions
+	UseFIS bool `json:"use-fis"`
+	// FISRoleName is the name of the IAM role that FIS assumes
+	// to interrupt the Spot instance.
+	FISRoleName string `json:"fis-role-name"`
+	// FISRoleARN is the ARN of the FIS IAM role.
+	FISRoleARN string `json:"fis-role-arn" read-only:"true"`
+	// FISExperimentTemplateID is the ID of the created FIS experiment template.
+	FISExperimentTemplateID string `json:"fis-experiment-template-id" read-only:"true"`
+	// FISExperimentID is the ID of the FIS experiment.
+	FISExperimentID string `json:"fis-experiment-id" read-only:"true"`
+
+	// SLO is the maximum duration, since the interruption, for the node
+	// to be drained and replaced, and for all test pods to be ready again.
+	SLO       time.Duration `json:"slo,omitempty" read-only:"true"`
+	SLOString string        `json:"slo-string,omitempty"`
+
+	// InterruptedInstanceID is the EC2 instance ID of the interrupted node.
+	InterruptedInstanceID string `json:"interrupted-instance-id" read-only:"true"`
+	// InterruptedNodeName is the name of the interrupted node.
+	InterruptedNodeName string `json:"interrupted-node-name" read-only:"true"`
+	// ReplacementNodeName is the name of the node that replaced the interrupted node.
+	ReplacementNodeName string `json:"replacement-node-name" read-only:"true"`
+	// RecoveryDurationString is the duration to recover from the interruption.
+	RecoveryDurationString string `json:"recovery-duration-string" read-only:"true"`
+}
+
+// EnvironmentVariablePrefixAddOnSpotInterruption is the environment variable prefix used for "eksconfig".
+const EnvironmentVariablePrefixAddOnSpotInterruption = AWS_K8S_TESTER_EKS_PREFIX + "ADD_ON_SPOT_INTERRUPTION_"
+
+// IsEnabledAddOnSpotInterruption returns true if "AddOnSpotInterruption" is enabled.
+// Otherwise, nil the field for "omitempty".
+func (cfg *Config) IsEnabledAddOnSpotInterruption() bool {
+	if cfg.AddOnSpotInterruption == nil {
+		return false
+	}
+	if cfg.AddOnSpotInterruption.Enable {
+		return true
+	}
+	cfg.AddOnSpotInterruption = nil
+	return false
+}
+
+func getDefaultAddOnSpotInterruption() *AddOnSpotInterruption {
+	return &AddOnSpotInterruption{
+		Enable:             false,
+		DeploymentReplicas: 3,
+		UseFIS:             false,
+		SLO:                10 * time.Minute,
+	}
+}
+
+func (cfg *Config) validateAddOnSpotInterruption() error {
+	if !cfg.IsEnabledAddOnSpotInterruption() {
+		return nil
+	}
+	if !cfg.IsEnabledAddOnManagedNodeGroups() {
+		return errors.New("AddOnSpotInterruption.Enable true but AddOnManagedNodeGroups.Enable false")
+	}
+	if cfg.AddOnSpotInterruption.MNGName == "" {
+		names := make([]string, 0, len(cfg.AddOnManagedNodeGroups.MNGs))
+		for k, cur := range cfg.AddOnManagedNodeGroups.MNGs {
+			if cur.CapacityType == eks.CapacityTypesSpot {
+				names = append(names, k)
+			}
+		}
+		if len(names) == 0 {
+			return errors.New("AddOnSpotInterruption.Enable true but no Spot managed node group")
+		}
+		sort.Strings(names)
+		cfg.AddOnSpotInterruption.MNGName = names[0]
+	}
+	cur, ok := cfg.AddOnManagedNodeGroups.MNGs[cfg.AddOnSpotInterruption.MNGName]
+	if !ok {
+		return fmt.Errorf("AddOnSpotInterruption.MNGName %q not found", cfg.AddOnSpotInterruption.MNGName)
+	}
+	if cur.CapacityType != eks.CapacityTypesSpot {
+		return fmt.Errorf("AddOnSpotInterruption.MNGName %q unexpected CapacityType %q (expected %q)", cfg.AddOnSpotInterruption.MNGName, cur.CapacityType, eks.CapacityTypesSpot)
+	}
+	// at least one other node keeps the pods running while the node is replaced
+	if cur.ASGMinSize < 2 {
+		return fmt.Errorf("AddOnSpotInterruption.MNGName %q ASGMinSize %d < 2", cfg.AddOnSpotInterruption.MNGName, cur.ASGMinSize)
+	}
+
+	if cfg.AddOnSpotInterruption.Namespace == "" {
+		cfg.AddOnSpotInterruption.Namespace = cfg.Name + "-spot-interruption"
+	}
+	if cfg.AddOnSpotInterruption.DeploymentReplicas == 0 {
+		return errors.New("AddOnSpotInterruption.DeploymentReplicas 0")
+	}
+	if cfg.AddOnSpotInterruption.UseFIS && cfg.AddOnSpotInterruption.FISRoleName == "" {
+		cfg.AddOnSpotInterruption.FISRoleName = cfg.Name + "-spot-interruption-fis-role"
+	}
+
+	if cfg.AddOnSpotInterruption.SLOString != "" {
+		var err error
+		cfg.AddOnSpotInterruption.SLO, err = time.ParseDuration(cfg.AddOnSpotInterruption.SLOString)
+		if err != nil {
+			return fmt.Errorf("invalid AddOnSpotInterruption.SLOString %q (%v)", cfg.AddOnSpotInterruption.SLOString, err)
+		}
+	}
+	// the FIS interruption notice is sent two minutes before the termination
+	if cfg.AddOnSpotInterruption.SLO < 3*time.Minute {
+		return fmt.Errorf("AddOnSpotInterruption.SLO %v too short (expected >= 3m)", cfg.AddOnSpotInterruption.SLO)
+	}
+	cfg.AddOnSpotInterruption.SLOString = cfg.AddOnSpotInterruption.SLO.String()
+	return nil
+}
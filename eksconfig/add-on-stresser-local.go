@@ -1,6 +1,7 @@
 package eksconfig
 
 import (
+	"fmt"
 	"path"
 	"path/filepath"
 	"strings"
@@ -24,6 +25,13 @@ type AddOnStresserLocal struct {
 	TimeFrameCreate timeutil.TimeFrame `json:"time-frame-create" read-only:"true"`
 	TimeFrameDelete timeutil.TimeFrame `json:"time-frame-delete" read-only:"true"`
 
+	// CleanupPolicy is the policy to clean up the add-on resources on delete,
+	// "delete", "retain", or "retain-on-failure" (see "CleanupPolicyRetainOnFailure").
+	// Defaults to "delete".
+	CleanupPolicy string `json:"cleanup-policy"`
+	// CreateFailed is true when the creation failed.
+	CreateFailed bool `json:"create-failed" read-only:"true"`
+
 	// S3Dir is the S3 directory to store all test results.
 	// It is under the bucket "eksconfig.Config.S3BucketName".
 	S3Dir string `json:"s3-dir"`
@@ -37,9 +45,17 @@ type AddOnStresserLocal struct {
 	// Sets "metav1.ListOptions".Limit field.
 	// 0 to list all.
 	ListLimit int64 `json:"list-limit"`
+	// QPS is the maximum number of write and read requests per second.
+	// 0 for no limit. Can be updated while running (see "LiveReload").
+	QPS float64 `json:"qps"`
 	// Duration is the duration to run load testing.
 	Duration       time.Duration `json:"duration,omitempty"`
 	DurationString string        `json:"duration-string,omitempty" read-only:"true"`
+	// ThroughputSampleInterval is the interval to sample the write and read
+	// throughput (QPS, error rate, in-flight requests) while running,
+	// to see the ramp behavior and the throttling onset. 0 to disable.
+	ThroughputSampleInterval       time.Duration `json:"throughput-sample-interval,omitempty"`
+	ThroughputSampleIntervalString string        `json:"throughput-sample-interval-string,omitempty" read-only:"true"`
 
 	//////////////////////////////////////////////////////////////////////////////
 
@@ -118,6 +134,25 @@ type AddOnStresserLocal struct {
 	RequestsSummaryReadsCompareTableS3Key string                  `json:"requests-summary-reads-compare-table-s3-path" read-only:"true"`
 
 	//////////////////////////////////////////////////////////////////////////////
+
+	RequestsThroughputWritesJSONPath  string `json:"requests-throughput-writes-json-path" read-only:"true"`
+	RequestsThroughputWritesJSONS3Key string `json:"requests-throughput-writes-json-s3-key" read-only:"true"`
+	RequestsThroughputReadsJSONPath   string `json:"requests-throughput-reads-json-path" read-only:"true"`
+	RequestsThroughputReadsJSONS3Key  string `json:"requests-throughput-reads-json-s3-key" read-only:"true"`
+
+	//////////////////////////////////////////////////////////////////////////////
+
+	// SLOMaxErrorRatePercent is the maximum error rate in percent of
+	// each "RequestsSummary" result. Zero disables the check.
+	SLOMaxErrorRatePercent float64 `json:"slo-max-error-rate-percent"`
+	// SLOMaxLatencyP99 is the maximum p99 latency of each "RequestsSummary"
+	// result. Zero disables the check.
+	SLOMaxLatencyP99       time.Duration `json:"slo-max-latency-p99"`
+	SLOMaxLatencyP99String string        `json:"slo-max-latency-p99-string" read-only:"true"`
+	// SLOVerdict is the pass/fail verdict of the results against the SLO.
+	SLOVerdict metrics.SLOVerdict `json:"slo-verdict" read-only:"true"`
+
+	//////////////////////////////////////////////////////////////////////////////
 }
 
 // EnvironmentVariablePrefixAddOnStresserLocal is the environment variable prefix used for "eksconfig".
@@ -142,6 +177,8 @@ func getDefaultAddOnStresserLocal() *AddOnStresserLocal {
 		ObjectSize: 0,
 		ListLimit:  0,
 		Duration:   time.Minute,
+
+		ThroughputSampleInterval: 10 * time.Second,
 	}
 }
 
@@ -158,11 +195,20 @@ func (cfg *Config) validateAddOnStresserLocal() error {
 		cfg.AddOnStresserLocal.Namespace = cfg.Name + "-stresser-local"
 	}
 
+	if cfg.AddOnStresserLocal.QPS < 0 {
+		return fmt.Errorf("invalid AddOnStresserLocal.QPS %v", cfg.AddOnStresserLocal.QPS)
+	}
+
 	if cfg.AddOnStresserLocal.Duration == time.Duration(0) {
 		cfg.AddOnStresserLocal.Duration = time.Minute
 	}
 	cfg.AddOnStresserLocal.DurationString = cfg.AddOnStresserLocal.Duration.String()
 
+	if cfg.AddOnStresserLocal.ThroughputSampleInterval < 0 {
+		return fmt.Errorf("invalid AddOnStresserLocal.ThroughputSampleInterval %v", cfg.AddOnStresserLocal.ThroughputSampleInterval)
+	}
+	cfg.AddOnStresserLocal.ThroughputSampleIntervalString = cfg.AddOnStresserLocal.ThroughputSampleInterval.String()
+
 	//////////////////////////////////////////////////////////////////////////////
 	if cfg.AddOnStresserLocal.RequestsRawWritesJSONPath == "" {
 		cfg.AddOnStresserLocal.RequestsRawWritesJSONPath = strings.ReplaceAll(cfg.ConfigPath, ".yaml", "") + "-stresser-local-requests-writes-raw.json"
@@ -339,5 +385,33 @@ func (cfg *Config) validateAddOnStresserLocal() error {
 	}
 	//////////////////////////////////////////////////////////////////////////////
 
+	//////////////////////////////////////////////////////////////////////////////
+	if cfg.AddOnStresserLocal.RequestsThroughputWritesJSONPath == "" {
+		cfg.AddOnStresserLocal.RequestsThroughputWritesJSONPath = strings.ReplaceAll(cfg.ConfigPath, ".yaml", "") + "-stresser-local-requests-throughput-writes.json"
+	}
+	if cfg.AddOnStresserLocal.RequestsThroughputWritesJSONS3Key == "" {
+		cfg.AddOnStresserLocal.RequestsThroughputWritesJSONS3Key = path.Join(
+			cfg.AddOnStresserLocal.S3Dir,
+			"requests-throughput-writes",
+			filepath.Base(cfg.AddOnStresserLocal.RequestsThroughputWritesJSONPath),
+		)
+	}
+	if cfg.AddOnStresserLocal.RequestsThroughputReadsJSONPath == "" {
+		cfg.AddOnStresserLocal.RequestsThroughputReadsJSONPath = strings.ReplaceAll(cfg.ConfigPath, ".yaml", "") + "-stresser-local-requests-throughput-reads.json"
+	}
+	if cfg.AddOnStresserLocal.RequestsThroughputReadsJSONS3Key == "" {
+		cfg.AddOnStresserLocal.RequestsThroughputReadsJSONS3Key = path.Join(
+			cfg.AddOnStresserLocal.S3Dir,
+			"requests-throughput-reads",
+			filepath.Base(cfg.AddOnStresserLocal.RequestsThroughputReadsJSONPath),
+		)
+	}
+	//////////////////////////////////////////////////////////////////////////////
+
+	if err := validateSLO("AddOnStresserLocal", cfg.AddOnStresserLocal.SLOMaxErrorRatePercent, cfg.AddOnStresserLocal.SLOMaxLatencyP99); err != nil {
+		return err
+	}
+	cfg.AddOnStresserLocal.SLOMaxLatencyP99String = cfg.AddOnStresserLocal.SLOMaxLatencyP99.String()
+
 	return nil
 }
@@ -2,6 +2,7 @@ package eksconfig
 
 import (
 	"errors"
+	"fmt"
 	"path"
 	"path/filepath"
 	"strings"
@@ -27,6 +28,13 @@ type AddOnStresserRemote struct {
 	TimeFrameCreate timeutil.TimeFrame `json:"time-frame-create" read-only:"true"`
 	TimeFrameDelete timeutil.TimeFrame `json:"time-frame-delete" read-only:"true"`
 
+	// CleanupPolicy is the policy to clean up the add-on resources on delete,
+	// "delete", "retain", or "retain-on-failure" (see "CleanupPolicyRetainOnFailure").
+	// Defaults to "delete".
+	CleanupPolicy string `json:"cleanup-policy"`
+	// CreateFailed is true when the creation failed.
+	CreateFailed bool `json:"create-failed" read-only:"true"`
+
 	// S3Dir is the S3 directory to store all test results.
 	// It is under the bucket "eksconfig.Config.S3BucketName".
 	S3Dir string `json:"s3-dir"`
@@ -45,6 +53,11 @@ type AddOnStresserRemote struct {
 	// RepositoryImageTag is the image tag for tester ECR image.
 	// e.g. "latest" for image URI "[ACCOUNT_ID].dkr.ecr.[REGION].amazonaws.com/aws/aws-k8s-tester:latest"
 	RepositoryImageTag string `json:"repository-image-tag,omitempty"`
+	// RepositoryImageArch is the CPU architecture of the tester ECR image,
+	// "amd64" or "arm64". The tester pods are scheduled on the nodes
+	// with the matching "kubernetes.io/arch" label.
+	// Defaults to "arm64" if all node groups are arm64, otherwise "amd64".
+	RepositoryImageArch string `json:"repository-image-arch,omitempty"`
 
 	// Completes is the desired number of successfully finished pods.
 	// Write QPS will be client QPS * replicas.
@@ -149,6 +162,18 @@ type AddOnStresserRemote struct {
 	RequestsSummaryReadsCompareTableS3Key string                  `json:"requests-summary-reads-compare-table-s3-path" read-only:"true"`
 
 	//////////////////////////////////////////////////////////////////////////////
+
+	// SLOMaxErrorRatePercent is the maximum error rate in percent of
+	// each "RequestsSummary" result. Zero disables the check.
+	SLOMaxErrorRatePercent float64 `json:"slo-max-error-rate-percent"`
+	// SLOMaxLatencyP99 is the maximum p99 latency of each "RequestsSummary"
+	// result. Zero disables the check.
+	SLOMaxLatencyP99       time.Duration `json:"slo-max-latency-p99"`
+	SLOMaxLatencyP99String string        `json:"slo-max-latency-p99-string" read-only:"true"`
+	// SLOVerdict is the pass/fail verdict of the results against the SLO.
+	SLOVerdict metrics.SLOVerdict `json:"slo-verdict" read-only:"true"`
+
+	//////////////////////////////////////////////////////////////////////////////
 }
 
 // EnvironmentVariablePrefixAddOnStresserRemote is the environment variable prefix used for "eksconfig".
@@ -212,6 +237,29 @@ func (cfg *Config) validateAddOnStresserRemote() error {
 	if cfg.AddOnStresserRemote.RepositoryImageTag == "" {
 		return errors.New("AddOnStresserRemote.RepositoryImageTag empty")
 	}
+	if cfg.AddOnStresserRemote.RepositoryImageArch == "" {
+		cfg.AddOnStresserRemote.RepositoryImageArch = ArchAMD64
+		if cfg.IsARM64Only() {
+			cfg.AddOnStresserRemote.RepositoryImageArch = ArchARM64
+		}
+	}
+	switch cfg.AddOnStresserRemote.RepositoryImageArch {
+	case ArchAMD64, ArchARM64:
+	default:
+		return fmt.Errorf("unknown AddOnStresserRemote.RepositoryImageArch %q", cfg.AddOnStresserRemote.RepositoryImageArch)
+	}
+	if archs := cfg.NodeArchs(); len(archs) > 0 {
+		found := false
+		for _, arch := range archs {
+			if arch == cfg.AddOnStresserRemote.RepositoryImageArch {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("AddOnStresserRemote.RepositoryImageArch %q but no node group for the architecture (node architectures %q)", cfg.AddOnStresserRemote.RepositoryImageArch, archs)
+		}
+	}
 
 	if cfg.AddOnStresserRemote.Duration == time.Duration(0) {
 		cfg.AddOnStresserRemote.Duration = time.Minute
@@ -405,5 +453,10 @@ func (cfg *Config) validateAddOnStresserRemote() error {
 	}
 	//////////////////////////////////////////////////////////////////////////////
 
+	if err := validateSLO("AddOnStresserRemote", cfg.AddOnStresserRemote.SLOMaxErrorRatePercent, cfg.AddOnStresserRemote.SLOMaxLatencyP99); err != nil {
+		return err
+	}
+	cfg.AddOnStresserRemote.SLOMaxLatencyP99String = cfg.AddOnStresserRemote.SLOMaxLatencyP99.String()
+
 	return nil
 }
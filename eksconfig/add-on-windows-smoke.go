@@ -0,0 +1,79 @@
+package eksconfig
+
+import (
+	"errors"
+
+	"github.com/aws/aws-k8s-tester/ec2config"
+	"github.com/aws/aws-k8s-tester/pkg/timeutil"
+)
+
+// AddOnWindowsSmoke defines parameters for EKS cluster
+// add-on Windows Pod smoke test.
+// ref. https://docs.aws.amazon.com/eks/latest/userguide/windows-support.html
+type AddOnWindowsSmoke struct {
+	// Enable is 'true' to create this add-on.
+	Enable bool `json:"enable"`
+	// Created is true when the resource has been created.
+	// Used for delete operations.
+	Created         bool               `json:"created" read-only:"true"`
+	TimeFrameCreate timeutil.TimeFrame `json:"time-frame-create" read-only:"true"`
+	TimeFrameDelete timeutil.TimeFrame `json:"time-frame-delete" read-only:"true"`
+
+	// CleanupPolicy is the policy to clean up the add-on resources on delete,
+	// "delete", "retain", or "retain-on-failure" (see "CleanupPolicyRetainOnFailure").
+	// Defaults to "delete".
+	CleanupPolicy string `json:"cleanup-policy"`
+	// CreateFailed is true when the creation failed.
+	CreateFailed bool `json:"create-failed" read-only:"true"`
+
+	// Namespace is the namespace to create objects in.
+	Namespace string `json:"namespace"`
+	// Image is the Windows container image to run.
+	// Must match the Windows Server version of the node AMI.
+	Image string `json:"image"`
+}
+
+// EnvironmentVariablePrefixAddOnWindowsSmoke is the environment variable prefix used for "eksconfig".
+const EnvironmentVariablePrefixAddOnWindowsSmoke = AWS_K8S_TESTER_EKS_PREFIX + "ADD_ON_WINDOWS_SMOKE_"
+
+// DefaultWindowsSmokeImage is the default Windows container image
+// for Windows Server 2019 nodes.
+const DefaultWindowsSmokeImage = "mcr.microsoft.com/windows/servercore:ltsc2019"
+
+// IsEnabledAddOnWindowsSmoke returns true if "AddOnWindowsSmoke" is enabled.
+// Otherwise, nil the field for "omitempty".
+func (cfg *Config) IsEnabledAddOnWindowsSmoke() bool {
+	if cfg.AddOnWindowsSmoke == nil {
+		return false
+	}
+	if cfg.AddOnWindowsSmoke.Enable {
+		return true
+	}
+	cfg.AddOnWindowsSmoke = nil
+	return false
+}
+
+func getDefaultAddOnWindowsSmoke() *AddOnWindowsSmoke {
+	return &AddOnWindowsSmoke{
+		Enable: false,
+		Image:  DefaultWindowsSmokeImage,
+	}
+}
+
+func (cfg *Config) validateAddOnWindowsSmoke() error {
+	if !cfg.IsEnabledAddOnWindowsSmoke() {
+		return nil
+	}
+	if !cfg.HasWindowsNodeGroup() {
+		return errors.New("AddOnWindowsSmoke requires " + ec2config.AMITypeWindowsServerCore2019X8664 + " node group")
+	}
+
+	if cfg.AddOnWindowsSmoke.Namespace == "" {
+		cfg.AddOnWindowsSmoke.Namespace = cfg.Name + "-windows-smoke"
+	}
+	if cfg.AddOnWindowsSmoke.Image == "" {
+		cfg.AddOnWindowsSmoke.Image = DefaultWindowsSmokeImage
+	}
+
+	return nil
+}
@@ -25,6 +25,13 @@ type AddOnWordpress struct {
 	TimeFrameCreate timeutil.TimeFrame `json:"time-frame-create" read-only:"true"`
 	TimeFrameDelete timeutil.TimeFrame `json:"time-frame-delete" read-only:"true"`
 
+	// CleanupPolicy is the policy to clean up the add-on resources on delete,
+	// "delete", "retain", or "retain-on-failure" (see "CleanupPolicyRetainOnFailure").
+	// Defaults to "delete".
+	CleanupPolicy string `json:"cleanup-policy"`
+	// CreateFailed is true when the creation failed.
+	CreateFailed bool `json:"create-failed" read-only:"true"`
+
 	// Namespace is the namespace to create objects in.
 	Namespace string `json:"namespace"`
 
@@ -32,6 +39,7 @@ type AddOnWordpress struct {
 	// ref. https://github.com/helm/charts/tree/master/stable/wordpress
 	UserName string `json:"user-name"`
 	// Password is the user password.
+	// Can be an "ssm://" or "secretsmanager://" URI (see "ResolveSecrets").
 	// ref. https://github.com/helm/charts/tree/master/stable/wordpress
 	Password string `json:"password"`
 
@@ -71,9 +79,6 @@ func (cfg *Config) validateAddOnWordpress() error {
 	if !cfg.IsEnabledAddOnWordpress() {
 		return nil
 	}
-	if !cfg.IsEnabledAddOnCSIEBS() {
-		return errors.New("AddOnWordpress.Enable true but IsEnabledAddOnCSIEBS.Enable false")
-	}
 	if !cfg.IsEnabledAddOnNodeGroups() && !cfg.IsEnabledAddOnManagedNodeGroups() {
 		return errors.New("AddOnWordpress.Enable true but no node group is enabled")
 	}
@@ -90,7 +95,7 @@ func (cfg *Config) validateAddOnWordpress() error {
 			case ec2config.AMITypeAL2X8664,
 				ec2config.AMITypeAL2X8664GPU:
 				x86Found = true
-			case ec2config.AMITypeBottleRocketCPU:
+			case ec2config.AMITypeBottleRocketCPU, ec2config.AMITypeBottleRocketARM64:
 				rocketFound = true
 			}
 		}
@@ -105,7 +110,7 @@ func (cfg *Config) validateAddOnWordpress() error {
 			case eks.AMITypesAl2X8664,
 				eks.AMITypesAl2X8664Gpu:
 				x86Found = true
-			case ec2config.AMITypeBottleRocketCPU:
+			case ec2config.AMITypeBottleRocketCPU, ec2config.AMITypeBottleRocketARM64:
 				rocketFound = true
 			}
 		}
@@ -0,0 +1,155 @@
+package eksconfig
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// AWS_K8S_TESTER_EKS_ADD_ONS is the environment variable to enable
+// a set of add-ons with their defaults, in a comma-separated list
+// of add-on names (e.g. "nlb-hello-world,jobs-pi,stresser"), instead
+// of setting "_ENABLE=true" environment variable for each add-on.
+// See "AddOnNames" for the available names.
+const AWS_K8S_TESTER_EKS_ADD_ONS = AWS_K8S_TESTER_EKS_PREFIX + "ADD_ONS"
+
+// addOnDefaults maps the add-on name (the "add-on-" JSON field name
+// without the prefix) to its default configuration.
+var addOnDefaults = map[string]func(cfg *Config) interface{}{
+	"cni-vpc":                   func(cfg *Config) interface{} { return getDefaultAddOnCNIVPC() },
+	"node-groups":               func(cfg *Config) interface{} { return getDefaultAddOnNodeGroups(cfg.Name) },
+	"managed-node-groups":       func(cfg *Config) interface{} { return getDefaultAddOnManagedNodeGroups(cfg.Name) },
+	"cw-agent":                  func(cfg *Config) interface{} { return getDefaultAddOnCWAgent() },
+	"fluentd":                   func(cfg *Config) interface{} { return getDefaultAddOnFluentd() },
+	"metrics-server":            func(cfg *Config) interface{} { return getDefaultAddOnMetricsServer() },
+	"conformance":               func(cfg *Config) interface{} { return getDefaultAddOnConformance() },
+	"app-mesh":                  func(cfg *Config) interface{} { return getDefaultAddOnAppMesh() },
+	"csi-ebs":                   func(cfg *Config) interface{} { return getDefaultAddOnCSIEBS() },
+	"kubernetes-dashboard":      func(cfg *Config) interface{} { return getDefaultAddOnKubernetesDashboard() },
+	"prometheus-grafana":        func(cfg *Config) interface{} { return getDefaultAddOnPrometheusGrafana() },
+	"php-apache":                func(cfg *Config) interface{} { return getDefaultAddOnPHPApache() },
+	"nlb-hello-world":           func(cfg *Config) interface{} { return getDefaultAddOnNLBHelloWorld() },
+	"nlb-guestbook":             func(cfg *Config) interface{} { return getDefaultAddOnNLBGuestbook() },
+	"alb-2048":                  func(cfg *Config) interface{} { return getDefaultAddOnALB2048() },
+	"jobs-pi":                   func(cfg *Config) interface{} { return getDefaultAddOnJobsPi() },
+	"jobs-echo":                 func(cfg *Config) interface{} { return getDefaultAddOnJobsEcho() },
+	"cron-jobs":                 func(cfg *Config) interface{} { return getDefaultAddOnCronJobs() },
+	"csrs-local":                func(cfg *Config) interface{} { return getDefaultAddOnCSRsLocal() },
+	"csrs-remote":               func(cfg *Config) interface{} { return getDefaultAddOnCSRsRemote() },
+	"configmaps-local":          func(cfg *Config) interface{} { return getDefaultAddOnConfigmapsLocal() },
+	"configmaps-remote":         func(cfg *Config) interface{} { return getDefaultAddOnConfigmapsRemote() },
+	"secrets-local":             func(cfg *Config) interface{} { return getDefaultAddOnSecretsLocal() },
+	"secrets-remote":            func(cfg *Config) interface{} { return getDefaultAddOnSecretsRemote() },
+	"fargate":                   func(cfg *Config) interface{} { return getDefaultAddOnFargate() },
+	"irsa":                      func(cfg *Config) interface{} { return getDefaultAddOnIRSA() },
+	"irsa-fargate":              func(cfg *Config) interface{} { return getDefaultAddOnIRSAFargate() },
+	"wordpress":                 func(cfg *Config) interface{} { return getDefaultAddOnWordpress() },
+	"jupyter-hub":               func(cfg *Config) interface{} { return getDefaultAddOnJupyterHub() },
+	"kubeflow":                  func(cfg *Config) interface{} { return getDefaultAddOnKubeflow() },
+	"cuda-vector-add":           func(cfg *Config) interface{} { return getDefaultAddOnCUDAVectorAdd() },
+	"windows-smoke":             func(cfg *Config) interface{} { return getDefaultAddOnWindowsSmoke() },
+	"ipv6":                      func(cfg *Config) interface{} { return getDefaultAddOnIPv6() },
+	"access-entries":            func(cfg *Config) interface{} { return getDefaultAddOnAccessEntries() },
+	"managed-addons":            func(cfg *Config) interface{} { return getDefaultAddOnManagedAddons() },
+	"spot-interruption":         func(cfg *Config) interface{} { return getDefaultAddOnSpotInterruption() },
+	"mng-scale":                 func(cfg *Config) interface{} { return getDefaultAddOnMNGScale() },
+	"mng-rolling-upgrade":       func(cfg *Config) interface{} { return getDefaultAddOnMNGRollingUpgrade() },
+	"cluster-loader-local":      func(cfg *Config) interface{} { return getDefaultAddOnClusterLoaderLocal() },
+	"cluster-loader-remote":     func(cfg *Config) interface{} { return getDefaultAddOnClusterLoaderRemote() },
+	"stresser-local":            func(cfg *Config) interface{} { return getDefaultAddOnStresserLocal() },
+	"stresser-remote":           func(cfg *Config) interface{} { return getDefaultAddOnStresserRemote() },
+	"stresser-remote-v2":        func(cfg *Config) interface{} { return getDefaultAddOnStresserRemoteV2() },
+	"cluster-version-upgrade":   func(cfg *Config) interface{} { return getDefaultAddOnClusterVersionUpgrade() },
+	"ami-soft-lockup-issue-454": func(cfg *Config) interface{} { return getDefaultAddOnAmiSoftLockupIssue454() },
+}
+
+// addOnAliases maps the short names of the add-ons that have
+// both local and remote testers to the local one.
+var addOnAliases = map[string]string{
+	"csrs":           "csrs-local",
+	"configmaps":     "configmaps-local",
+	"secrets":        "secrets-local",
+	"cluster-loader": "cluster-loader-local",
+	"stresser":       "stresser-local",
+}
+
+// AddOnNames returns the sorted list of add-on names
+// for "AWS_K8S_TESTER_EKS_ADD_ONS".
+func AddOnNames() (names []string) {
+	for k := range addOnDefaults {
+		names = append(names, k)
+	}
+	for k := range addOnAliases {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// updateAddOnsFromEnv enables the add-ons listed in
+// "AWS_K8S_TESTER_EKS_ADD_ONS", populating the defaults
+// for the ones that are not configured yet.
+func (cfg *Config) updateAddOnsFromEnv() error {
+	sv := os.Getenv(AWS_K8S_TESTER_EKS_ADD_ONS)
+	if sv == "" {
+		return nil
+	}
+	for _, name := range strings.Split(sv, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		if err := cfg.enableAddOn(name); err != nil {
+			return fmt.Errorf("failed to parse %q (environmental variable key %q, error %v)", sv, AWS_K8S_TESTER_EKS_ADD_ONS, err)
+		}
+	}
+	return nil
+}
+
+func (cfg *Config) enableAddOn(name string) error {
+	if v, ok := addOnAliases[name]; ok {
+		name = v
+	}
+	fn, ok := addOnDefaults[name]
+	if !ok {
+		return fmt.Errorf("unknown add-on %q (available add-ons %q)", name, AddOnNames())
+	}
+	fv, ok := cfg.addOnField(name)
+	if !ok {
+		return fmt.Errorf("add-on %q not found in configuration", name)
+	}
+	if fv.IsNil() {
+		fv.Set(reflect.ValueOf(fn(cfg)))
+	}
+	fv.Elem().FieldByName("Enable").SetBool(true)
+	return nil
+}
+
+// addOnField returns the configuration field of the add-on,
+// matched by the "add-on-" JSON field name.
+func (cfg *Config) addOnField(name string) (reflect.Value, bool) {
+	tp, vv := reflect.TypeOf(cfg).Elem(), reflect.ValueOf(cfg).Elem()
+	for i := 0; i < tp.NumField(); i++ {
+		jv := strings.Replace(tp.Field(i).Tag.Get("json"), ",omitempty", "", -1)
+		if jv == "add-on-"+name {
+			return vv.Field(i), true
+		}
+	}
+	return reflect.Value{}, false
+}
+
+// IsCreatedAddOn returns true if the add-on (see "AddOnNames")
+// is enabled and has been created.
+func (cfg *Config) IsCreatedAddOn(name string) bool {
+	if v, ok := addOnAliases[name]; ok {
+		name = v
+	}
+	if !cfg.isEnabledAddOnName(name) {
+		return false
+	}
+	fv, _ := cfg.addOnField(name)
+	cv := fv.Elem().FieldByName("Created")
+	return cv.IsValid() && cv.Kind() == reflect.Bool && cv.Bool()
+}
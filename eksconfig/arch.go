@@ -0,0 +1,69 @@
+package eksconfig
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/service/eks"
+)
+
+const (
+	// ArchAMD64 is the "kubernetes.io/arch" node label value for x86_64 nodes.
+	ArchAMD64 = "amd64"
+	// ArchARM64 is the "kubernetes.io/arch" node label value for Graviton nodes.
+	ArchARM64 = "arm64"
+)
+
+// AMITypeArch returns the CPU architecture of the AMI type.
+func AMITypeArch(amiType string) string {
+	switch amiType {
+	case eks.AMITypesAl2Arm64, eks.AMITypesBottlerocketArm64:
+		return ArchARM64
+	default:
+		return ArchAMD64
+	}
+}
+
+// e.g. "a1", "c6g", "m6gd", "c7gn", "im4gn", "is4gen", "g5g"
+var gravitonFamily = regexp.MustCompile(`^(a1|[a-z]+[0-9]+g[a-z]*)$`)
+
+// InstanceTypeArch returns the CPU architecture of the EC2 instance type.
+func InstanceTypeArch(instanceType string) string {
+	family := instanceType
+	if idx := strings.Index(instanceType, "."); idx > 0 {
+		family = instanceType[:idx]
+	}
+	if gravitonFamily.MatchString(family) {
+		return ArchARM64
+	}
+	return ArchAMD64
+}
+
+// NodeArchs returns the sorted CPU architectures of the enabled node groups
+// and managed node groups. Returns nil if no node group is enabled
+// (e.g. AutoMode, Fargate-only, or attached clusters).
+func (cfg *Config) NodeArchs() (archs []string) {
+	seen := make(map[string]struct{})
+	if cfg.IsEnabledAddOnNodeGroups() {
+		for _, cur := range cfg.AddOnNodeGroups.ASGs {
+			seen[AMITypeArch(cur.AMIType)] = struct{}{}
+		}
+	}
+	if cfg.IsEnabledAddOnManagedNodeGroups() {
+		for _, cur := range cfg.AddOnManagedNodeGroups.MNGs {
+			seen[AMITypeArch(cur.AMIType)] = struct{}{}
+		}
+	}
+	for arch := range seen {
+		archs = append(archs, arch)
+	}
+	sort.Strings(archs)
+	return archs
+}
+
+// IsARM64Only returns true if all enabled node groups run on arm64.
+func (cfg *Config) IsARM64Only() bool {
+	archs := cfg.NodeArchs()
+	return len(archs) == 1 && archs[0] == ArchARM64
+}
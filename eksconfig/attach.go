@@ -0,0 +1,82 @@
+package eksconfig
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+
+	"github.com/aws/aws-k8s-tester/pkg/fileutil"
+)
+
+// Attach defines the "attach" mode, where the tester adopts an existing
+// cluster (e.g. created by other tools) instead of creating one.
+// The cluster role, VPC, encryption key, and the cluster itself are neither
+// created nor deleted. The cluster endpoint, CA, version, role, and VPC are
+// discovered from the cluster "Name" in "Region", and only the enabled
+// add-ons are created and deleted.
+// Private-only endpoint clusters must be reachable from the tester host
+// (e.g. run within the VPC), since no bastion host is created.
+type Attach struct {
+	// Enable is 'true' to attach to the existing cluster.
+	Enable bool `json:"enable"`
+	// KubeConfigPath is the existing KUBECONFIG file path of the cluster.
+	// If empty, it is written by "aws eks update-kubeconfig" to "KubeConfigPath".
+	// If not empty, the cluster discovery is best-effort (e.g. non-EKS clusters).
+	KubeConfigPath string `json:"kubeconfig-path"`
+
+	// ClusterARN is the ARN of the attached cluster, once discovered.
+	ClusterARN string `json:"cluster-arn" read-only:"true"`
+}
+
+func getDefaultAttach() *Attach {
+	return &Attach{
+		Enable: false,
+	}
+}
+
+// IsEnabledAttach returns true if "Attach" is enabled.
+// Otherwise, nil the field for "omitempty".
+func (cfg *Config) IsEnabledAttach() bool {
+	if cfg.Attach == nil {
+		return false
+	}
+	if cfg.Attach.Enable {
+		return true
+	}
+	cfg.Attach = nil
+	return false
+}
+
+// validateAttach must be run before the validation of "Role",
+// "VPC", and "Bastion".
+func (cfg *Config) validateAttach() error {
+	if !cfg.IsEnabledAttach() {
+		return nil
+	}
+	if cfg.IsEnabledAutoMode() {
+		return errors.New("Attach.Enable true but AutoMode.Enable true")
+	}
+	if cfg.IsEnabledOutpost() {
+		return errors.New("Attach.Enable true but Outpost.Enable true")
+	}
+	if cfg.IsEnabledAddOnClusterVersionUpgrade() {
+		return errors.New("Attach.Enable true but AddOnClusterVersionUpgrade.Enable true (cannot upgrade a cluster not owned by the tester)")
+	}
+	if cfg.Attach.KubeConfigPath != "" {
+		p, err := filepath.Abs(cfg.Attach.KubeConfigPath)
+		if err != nil {
+			return fmt.Errorf("failed to 'filepath.Abs(%s)' %v", cfg.Attach.KubeConfigPath, err)
+		}
+		if !fileutil.Exist(p) {
+			return fmt.Errorf("Attach.KubeConfigPath %q does not exist", p)
+		}
+		cfg.Attach.KubeConfigPath = p
+		cfg.KubeConfigPath = p
+	}
+
+	// discovered from the attached cluster, never created nor deleted
+	cfg.Role.Create = false
+	cfg.VPC.Create = false
+	cfg.Encryption.CMKCreate = false
+	return nil
+}
@@ -0,0 +1,232 @@
+package eksconfig
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/aws/aws-sdk-go/service/eks"
+)
+
+// AutoMode defines the EKS Auto Mode cluster, where EKS manages the compute,
+// block storage, and load balancing capabilities of the cluster. No node group
+// is created, and the pods are scheduled onto the capacity auto-provisioned
+// by the built-in node pools (see "NodePools").
+// ref. https://docs.aws.amazon.com/eks/latest/userguide/automode.html
+type AutoMode struct {
+	// Enable is 'true' to create the cluster with EKS Auto Mode.
+	Enable bool `json:"enable"`
+	// NodePools is the list of built-in node pools,
+	// "general-purpose" and/or "system".
+	NodePools []string `json:"node-pools"`
+	// NodeRoleARN is the IAM role ARN of the auto-provisioned nodes.
+	// If empty, the cluster role is used (see "Role"), which trusts
+	// "ec2.amazonaws.com" by default.
+	NodeRoleARN string `json:"node-role-arn"`
+
+	// ScheduledPods is the number of add-on pods scheduled onto
+	// the auto-provisioned nodes.
+	ScheduledPods int `json:"scheduled-pods" read-only:"true"`
+	// Nodes is the list of the auto-provisioned node names.
+	Nodes []string `json:"nodes" read-only:"true"`
+}
+
+const (
+	// AutoModeNodePoolGeneralPurpose is the built-in node pool for general workloads.
+	AutoModeNodePoolGeneralPurpose = "general-purpose"
+	// AutoModeNodePoolSystem is the built-in node pool for critical add-ons.
+	AutoModeNodePoolSystem = "system"
+
+	// AutoModeComputeTypeLabel is the node label set on the nodes
+	// auto-provisioned by EKS Auto Mode, with "AutoModeComputeType" value.
+	AutoModeComputeTypeLabel = "eks.amazonaws.com/compute-type"
+	// AutoModeComputeType is the "AutoModeComputeTypeLabel" label value.
+	AutoModeComputeType = "auto"
+
+	// MinAutoModeVersion is the minimum Kubernetes version of EKS Auto Mode.
+	MinAutoModeVersion = 1.29
+)
+
+// AutoModeManagedPolicyARNs is the list of managed policies required
+// for the cluster role of EKS Auto Mode clusters.
+// ref. https://docs.aws.amazon.com/eks/latest/userguide/auto-cluster-iam-role.html
+var AutoModeManagedPolicyARNs = []string{
+	"arn:aws:iam::aws:policy/AmazonEKSClusterPolicy",
+	"arn:aws:iam::aws:policy/AmazonEKSComputePolicy",
+	"arn:aws:iam::aws:policy/AmazonEKSBlockStoragePolicy",
+	"arn:aws:iam::aws:policy/AmazonEKSLoadBalancingPolicy",
+	"arn:aws:iam::aws:policy/AmazonEKSNetworkingPolicy",
+}
+
+// autoModeNodeManagedPolicyARNs is the list of managed policies
+// for the auto-provisioned nodes, when the cluster role is reused
+// as the node role.
+// ref. https://docs.aws.amazon.com/eks/latest/userguide/auto-create-node-role.html
+var autoModeNodeManagedPolicyARNs = []string{
+	"arn:aws:iam::aws:policy/AmazonEKSWorkerNodeMinimalPolicy",
+	"arn:aws:iam::aws:policy/AmazonEC2ContainerRegistryPullOnly",
+}
+
+// autoModeAddOns is the set of add-ons (see "AddOnNames") validated with
+// EKS Auto Mode, whose pods only need the capacity auto-provisioned by
+// the built-in node pools. Other add-ons either depend on node groups
+// (e.g. SSH access, node labels) or install the components that EKS Auto
+// Mode already manages (e.g. VPC CNI, EBS CSI driver, load balancer controller).
+// The value is true if the add-on creates pods in its namespace,
+// and false if it only sends requests from the tester.
+var autoModeAddOns = map[string]bool{
+	"nlb-hello-world":  true,
+	"jobs-pi":          true,
+	"jobs-echo":        true,
+	"cron-jobs":        true,
+	"csrs-local":       false,
+	"configmaps-local": false,
+	"secrets-local":    false,
+	"stresser-local":   false,
+}
+
+func getDefaultAutoMode() *AutoMode {
+	return &AutoMode{
+		Enable:    false,
+		NodePools: []string{AutoModeNodePoolGeneralPurpose, AutoModeNodePoolSystem},
+	}
+}
+
+// IsEnabledAutoMode returns true if "AutoMode" is enabled.
+// Otherwise, nil the field for "omitempty".
+func (cfg *Config) IsEnabledAutoMode() bool {
+	if cfg.AutoMode == nil {
+		return false
+	}
+	if cfg.AutoMode.Enable {
+		return true
+	}
+	cfg.AutoMode = nil
+	return false
+}
+
+// AutoModeNodeRoleARN returns the node role ARN of the auto-provisioned nodes.
+func (cfg *Config) AutoModeNodeRoleARN() string {
+	if cfg.AutoMode.NodeRoleARN != "" {
+		return cfg.AutoMode.NodeRoleARN
+	}
+	return cfg.Role.ARN
+}
+
+// validateAutoMode must be run before the validation of "Role",
+// "AuthenticationMode", and add-ons.
+func (cfg *Config) validateAutoMode() error {
+	if !cfg.IsEnabledAutoMode() {
+		return nil
+	}
+	if cfg.VersionValue < MinAutoModeVersion {
+		return fmt.Errorf("AutoMode.Enable true but Version %q (expected >= %.2f)", cfg.Version, MinAutoModeVersion)
+	}
+	if len(cfg.AutoMode.NodePools) == 0 {
+		return fmt.Errorf("AutoMode.Enable true but empty AutoMode.NodePools")
+	}
+	for _, v := range cfg.AutoMode.NodePools {
+		switch v {
+		case AutoModeNodePoolGeneralPurpose, AutoModeNodePoolSystem:
+		default:
+			return fmt.Errorf("unknown AutoMode.NodePools %q (expected %q or %q)", v, AutoModeNodePoolGeneralPurpose, AutoModeNodePoolSystem)
+		}
+	}
+
+	// EKS Auto Mode nodes are registered with EKS access entries
+	switch cfg.AuthenticationMode {
+	case "", eks.AuthenticationModeConfigMap:
+		cfg.AuthenticationMode = eks.AuthenticationModeApi
+	}
+
+	if cfg.Role.Create {
+		if reflect.DeepEqual(cfg.Role.ManagedPolicyARNs, getDefaultRole().ManagedPolicyARNs) {
+			// default policies exceed the quota with the ones for EKS Auto Mode
+			cfg.Role.ManagedPolicyARNs = nil
+			if cfg.AutoMode.NodeRoleARN == "" {
+				cfg.Role.ManagedPolicyARNs = append(cfg.Role.ManagedPolicyARNs, autoModeNodeManagedPolicyARNs...)
+			}
+		}
+		for _, arn := range AutoModeManagedPolicyARNs {
+			found := false
+			for _, v := range cfg.Role.ManagedPolicyARNs {
+				if v == arn {
+					found = true
+					break
+				}
+			}
+			if !found {
+				cfg.Role.ManagedPolicyARNs = append(cfg.Role.ManagedPolicyARNs, arn)
+			}
+		}
+		sort.Strings(cfg.Role.ManagedPolicyARNs)
+
+		if cfg.AutoMode.NodeRoleARN == "" {
+			found := false
+			for _, v := range cfg.Role.ServicePrincipals {
+				if v == "ec2.amazonaws.com" {
+					found = true
+					break
+				}
+			}
+			if !found {
+				return fmt.Errorf("empty AutoMode.NodeRoleARN but Role.ServicePrincipals missing 'ec2.amazonaws.com' (%q)", cfg.Role.ServicePrincipals)
+			}
+		}
+	}
+
+	if cfg.AddOnNodeGroups != nil && cfg.AddOnNodeGroups.Enable {
+		return fmt.Errorf("AutoMode.Enable true but AddOnNodeGroups.Enable true (node groups are not created with EKS Auto Mode)")
+	}
+	if cfg.AddOnManagedNodeGroups != nil && cfg.AddOnManagedNodeGroups.Enable {
+		return fmt.Errorf("AutoMode.Enable true but AddOnManagedNodeGroups.Enable true (node groups are not created with EKS Auto Mode)")
+	}
+	var unsupported []string
+	for name := range addOnDefaults {
+		if _, ok := autoModeAddOns[name]; ok {
+			continue
+		}
+		if cfg.isEnabledAddOnName(name) {
+			unsupported = append(unsupported, name)
+		}
+	}
+	if len(unsupported) > 0 {
+		sort.Strings(unsupported)
+		supported := make([]string, 0, len(autoModeAddOns))
+		for name := range autoModeAddOns {
+			supported = append(supported, name)
+		}
+		sort.Strings(supported)
+		return fmt.Errorf("AutoMode.Enable true but add-ons %q not supported with EKS Auto Mode (expected any of %q)", unsupported, supported)
+	}
+	return nil
+}
+
+// isEnabledAddOnName returns true if the add-on field is set with "Enable" true.
+func (cfg *Config) isEnabledAddOnName(name string) bool {
+	fv, ok := cfg.addOnField(name)
+	if !ok || fv.IsNil() {
+		return false
+	}
+	ev := fv.Elem().FieldByName("Enable")
+	return ev.IsValid() && ev.Bool()
+}
+
+// AutoModeNamespaces returns the namespaces of the enabled add-ons
+// whose pods are scheduled onto the auto-provisioned nodes.
+func (cfg *Config) AutoModeNamespaces() (namespaces []string) {
+	names := make([]string, 0, len(autoModeAddOns))
+	for name, createsPods := range autoModeAddOns {
+		if createsPods && cfg.isEnabledAddOnName(name) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fv, _ := cfg.addOnField(name)
+		if nv := fv.Elem().FieldByName("Namespace"); nv.IsValid() && nv.String() != "" {
+			namespaces = append(namespaces, nv.String())
+		}
+	}
+	return namespaces
+}
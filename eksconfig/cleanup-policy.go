@@ -0,0 +1,139 @@
+package eksconfig
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Add-on cleanup policies, set with "CleanupPolicy" of each add-on.
+const (
+	// CleanupPolicyDelete deletes the add-on resources on delete.
+	CleanupPolicyDelete = "delete"
+	// CleanupPolicyRetain never deletes the add-on resources.
+	// While any add-on is retained, delete (including a failed "Up"
+	// with "OnFailureDelete" true) only deletes the other add-ons,
+	// and keeps the cluster, node groups, and VPC that the retained
+	// add-on runs on (see "Status.RetainedAddOns").
+	// Set the policy to "delete" and re-run delete to clean up.
+	CleanupPolicyRetain = "retain"
+	// CleanupPolicyRetainOnFailure retains the add-on resources only
+	// when the add-on creation failed, to keep the evidence for debugging.
+	// Same as "CleanupPolicyRetain" otherwise.
+	CleanupPolicyRetainOnFailure = "retain-on-failure"
+)
+
+// addOnsWithCleanupPolicy returns the enabled add-ons
+// with "CleanupPolicy", keyed by the field name (e.g. "AddOnWordpress").
+func (cfg *Config) addOnsWithCleanupPolicy() map[string]reflect.Value {
+	vs := make(map[string]reflect.Value)
+	cv := reflect.ValueOf(cfg).Elem()
+	for i := 0; i < cv.NumField(); i++ {
+		name := cv.Type().Field(i).Name
+		if !strings.HasPrefix(name, "AddOn") {
+			continue
+		}
+		fv := cv.Field(i)
+		if fv.Kind() != reflect.Ptr || fv.IsNil() || fv.Elem().Kind() != reflect.Struct {
+			continue
+		}
+		av := fv.Elem()
+		if !av.FieldByName("CleanupPolicy").IsValid() {
+			continue
+		}
+		if ev := av.FieldByName("Enable"); !ev.IsValid() || !ev.Bool() {
+			continue
+		}
+		vs[name] = av
+	}
+	return vs
+}
+
+func (cfg *Config) validateAddOnCleanupPolicies() error {
+	for name, av := range cfg.addOnsWithCleanupPolicy() {
+		pv := av.FieldByName("CleanupPolicy")
+		switch pv.String() {
+		case "":
+			pv.SetString(CleanupPolicyDelete)
+		case CleanupPolicyDelete, CleanupPolicyRetain, CleanupPolicyRetainOnFailure:
+		default:
+			return fmt.Errorf("unknown %s.CleanupPolicy %q (expected %q, %q, or %q)", name, pv.String(), CleanupPolicyDelete, CleanupPolicyRetain, CleanupPolicyRetainOnFailure)
+		}
+	}
+	return nil
+}
+
+// CreatedAddOns returns the names of created add-ons
+// (e.g. "AddOnWordpress"), to be passed to "SetAddOnsCreateFailed".
+func (cfg *Config) CreatedAddOns() map[string]struct{} {
+	cfg.mu.RLock()
+	defer cfg.mu.RUnlock()
+	created := make(map[string]struct{})
+	for name, av := range cfg.addOnsWithCleanupPolicy() {
+		if av.FieldByName("Created").Bool() {
+			created[name] = struct{}{}
+		}
+	}
+	return created
+}
+
+// SetAddOnsCreateFailed marks the add-ons created since "before"
+// (see "CreatedAddOns") as failed, and returns their names.
+// Each add-on sets "Created" at the start of its creation,
+// so the add-ons that failed are the ones newly created.
+func (cfg *Config) SetAddOnsCreateFailed(before map[string]struct{}) (failed []string) {
+	cfg.mu.Lock()
+	defer cfg.mu.Unlock()
+	for name, av := range cfg.addOnsWithCleanupPolicy() {
+		if _, ok := before[name]; ok || !av.FieldByName("Created").Bool() {
+			continue
+		}
+		av.FieldByName("CreateFailed").SetBool(true)
+		failed = append(failed, name)
+	}
+	sort.Strings(failed)
+	return failed
+}
+
+// RetainedAddOns returns the names of the created add-ons
+// whose resources must be retained by their cleanup policies.
+func (cfg *Config) RetainedAddOns() (retained []string) {
+	cfg.mu.RLock()
+	defer cfg.mu.RUnlock()
+	for name, av := range cfg.addOnsWithCleanupPolicy() {
+		if !av.FieldByName("Created").Bool() {
+			continue
+		}
+		switch av.FieldByName("CleanupPolicy").String() {
+		case CleanupPolicyRetain:
+		case CleanupPolicyRetainOnFailure:
+			if !av.FieldByName("CreateFailed").Bool() {
+				continue
+			}
+		default:
+			continue
+		}
+		retained = append(retained, name)
+	}
+	sort.Strings(retained)
+	return retained
+}
+
+// IsRetainedAddOn returns true if the add-on resources must be retained
+// by its cleanup policy (see "RetainedAddOns"), by the configuration
+// name of the add-on (e.g. "add-on-wordpress").
+func (cfg *Config) IsRetainedAddOn(name string) bool {
+	for _, f := range addOnFields() {
+		if f.name != name {
+			continue
+		}
+		for _, v := range cfg.RetainedAddOns() {
+			if v == f.fieldName {
+				return true
+			}
+		}
+		return false
+	}
+	return false
+}
@@ -0,0 +1,125 @@
+package eksconfig
+
+import (
+	"errors"
+	"fmt"
+	"net"
+)
+
+// CNICustomNetworking defines the VPC CNI custom networking, where pods
+// get IPs from the subnets of a secondary VPC CIDR, rather than from
+// the node subnets. The secondary CIDR and the pod subnets (one per
+// availability zone of the public subnets) are created with the VPC.
+// Before the node groups are created, the "ENIConfig" of each availability
+// zone is created, and the VPC CNI is configured with
+// "AWS_VPC_K8S_CNI_CUSTOM_NETWORK_CFG". Once the node groups are created,
+// the test pods must receive IPs from the secondary CIDR.
+// ref. https://docs.aws.amazon.com/eks/latest/userguide/cni-custom-network.html
+type CNICustomNetworking struct {
+	// Enable is 'true' to enable the VPC CNI custom networking.
+	Enable bool `json:"enable"`
+	// SecondaryCIDR is the secondary VPC CIDR block for pod IPs.
+	// Must not overlap with "VPC.CIDRs" (e.g. "100.64.0.0/16").
+	SecondaryCIDR string `json:"secondary-cidr"`
+	// PodSubnetCIDRs is the CIDR blocks for the pod subnets within
+	// "SecondaryCIDR", one for each of "VPC.PublicSubnetCIDRs".
+	PodSubnetCIDRs []string `json:"pod-subnet-cidrs"`
+	// Namespace is the namespace of the test pods.
+	Namespace string `json:"namespace"`
+	// Pods is the number of the test pods.
+	Pods int `json:"pods"`
+
+	// CIDRAssociationID is the association ID of the secondary CIDR.
+	CIDRAssociationID string `json:"cidr-association-id" read-only:"true"`
+	// PodSubnetIDs is the list of the pod subnet IDs.
+	PodSubnetIDs []string `json:"pod-subnet-ids" read-only:"true"`
+	// PodSubnetRouteTableAssociationIDs is the list of the route table
+	// association IDs of the pod subnets.
+	PodSubnetRouteTableAssociationIDs []string `json:"pod-subnet-route-table-association-ids" read-only:"true"`
+	// ENIConfigs maps the availability zone to the pod subnet ID
+	// of the created "ENIConfig".
+	ENIConfigs map[string]string `json:"eni-configs" read-only:"true"`
+	// PodIPs maps the test pod name to its IP.
+	PodIPs map[string]string `json:"pod-ips" read-only:"true"`
+}
+
+func getDefaultCNICustomNetworking() *CNICustomNetworking {
+	return &CNICustomNetworking{
+		Enable:        false,
+		SecondaryCIDR: "100.64.0.0/16",
+		PodSubnetCIDRs: []string{
+			"100.64.0.0/18",
+			"100.64.64.0/18",
+			"100.64.128.0/18",
+		},
+		Pods: 6,
+	}
+}
+
+// IsEnabledCNICustomNetworking returns true if "CNICustomNetworking" is enabled.
+// Otherwise, nil the field for "omitempty".
+func (cfg *Config) IsEnabledCNICustomNetworking() bool {
+	if cfg.CNICustomNetworking == nil {
+		return false
+	}
+	if cfg.CNICustomNetworking.Enable {
+		return true
+	}
+	cfg.CNICustomNetworking = nil
+	return false
+}
+
+// validateCNICustomNetworking must be run after "validateAttach".
+func (cfg *Config) validateCNICustomNetworking() error {
+	if !cfg.IsEnabledCNICustomNetworking() {
+		return nil
+	}
+	if !cfg.VPC.Create {
+		return errors.New("CNICustomNetworking.Enable true but VPC.Create false (secondary CIDR is only added to the tester VPC)")
+	}
+	if cfg.IsEnabledAutoMode() {
+		return errors.New("CNICustomNetworking.Enable true but AutoMode.Enable true")
+	}
+	if cfg.IPFamily == IPFamilyIPv6 {
+		return errors.New("CNICustomNetworking.Enable true but IPFamily is ipv6")
+	}
+	if !cfg.IsEnabledAddOnManagedNodeGroups() && !cfg.IsEnabledAddOnNodeGroups() {
+		return errors.New("CNICustomNetworking.Enable true but no AddOnManagedNodeGroups nor AddOnNodeGroups")
+	}
+
+	_, secondary, err := net.ParseCIDR(cfg.CNICustomNetworking.SecondaryCIDR)
+	if err != nil {
+		return fmt.Errorf("invalid CNICustomNetworking.SecondaryCIDR %q (%v)", cfg.CNICustomNetworking.SecondaryCIDR, err)
+	}
+	for _, cidr := range cfg.VPC.CIDRs {
+		_, vn, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return fmt.Errorf("invalid VPC.CIDRs %q (%v)", cidr, err)
+		}
+		if vn.Contains(secondary.IP) || secondary.Contains(vn.IP) {
+			return fmt.Errorf("CNICustomNetworking.SecondaryCIDR %q overlaps with VPC.CIDRs %q", cfg.CNICustomNetworking.SecondaryCIDR, cidr)
+		}
+	}
+	if len(cfg.CNICustomNetworking.PodSubnetCIDRs) != len(cfg.VPC.PublicSubnetCIDRs) {
+		return fmt.Errorf("expected %d CNICustomNetworking.PodSubnetCIDRs for VPC.PublicSubnetCIDRs, got %d", len(cfg.VPC.PublicSubnetCIDRs), len(cfg.CNICustomNetworking.PodSubnetCIDRs))
+	}
+	for _, cidr := range cfg.CNICustomNetworking.PodSubnetCIDRs {
+		ip, pn, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return fmt.Errorf("invalid CNICustomNetworking.PodSubnetCIDRs %q (%v)", cidr, err)
+		}
+		ones, _ := pn.Mask.Size()
+		sones, _ := secondary.Mask.Size()
+		if !secondary.Contains(ip) || ones < sones {
+			return fmt.Errorf("CNICustomNetworking.PodSubnetCIDRs %q not within SecondaryCIDR %q", cidr, cfg.CNICustomNetworking.SecondaryCIDR)
+		}
+	}
+
+	if cfg.CNICustomNetworking.Pods <= 0 {
+		return errors.New("CNICustomNetworking.Enable true but Pods <= 0")
+	}
+	if cfg.CNICustomNetworking.Namespace == "" {
+		cfg.CNICustomNetworking.Namespace = cfg.Name + "-cni-custom-networking"
+	}
+	return nil
+}
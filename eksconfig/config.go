@@ -19,11 +19,13 @@ import (
 	"time"
 
 	"github.com/aws/aws-k8s-tester/ec2config"
+	aws_secrets "github.com/aws/aws-k8s-tester/pkg/aws/secrets"
 	"github.com/aws/aws-k8s-tester/pkg/fileutil"
 	"github.com/aws/aws-k8s-tester/pkg/logutil"
 	"github.com/aws/aws-k8s-tester/pkg/randutil"
 	"github.com/aws/aws-k8s-tester/pkg/terminal"
 	aws_ec2_v2_types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/aws/aws-sdk-go/service/eks"
 	"github.com/mitchellh/colorstring"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/yaml" // must use "sigs.k8s.io/yaml"
@@ -32,6 +34,9 @@ import (
 // Config defines EKS configuration.
 type Config struct {
 	mu *sync.RWMutex
+	// secretURIs maps the resolved sensitive fields to their secret URIs.
+	// ref. "ResolveSecrets"
+	secretURIs map[*string]string
 
 	// TODO, Migrate metadata fields to here
 	metav1.TypeMeta   `json:",inline"`
@@ -128,14 +133,72 @@ type Config struct {
 	// All node groups and managed node groups are kept.
 	// Use this to use existing clusters to create/delete add-ons.
 	SkipDeleteClusterAndNodes bool `json:"skip-delete-cluster-and-nodes"`
+	// SkipQuotaCheck is true to skip the service quota preflight check
+	// before creating any resource (see "QuotaRequirements").
+	SkipQuotaCheck bool `json:"skip-quota-check"`
 
 	S3         *S3         `json:"s3"`
 	Encryption *Encryption `json:"encryption"`
 	Role       *Role       `json:"role"`
 	VPC        *VPC        `json:"vpc"`
-
-	// Tags defines EKS create cluster tags.
+	// Bastion is the bastion host for private-only endpoint clusters.
+	// Only created when "EndpointPrivateAccess" is true and
+	// "EndpointPublicAccess" is false.
+	Bastion *Bastion `json:"bastion,omitempty"`
+	// LiveReload defines the live-reload of add-on parameters for soak tests.
+	LiveReload *LiveReload `json:"live-reload,omitempty"`
+	// Regression defines the regression checks of the add-on results
+	// against the baselines from a prior run.
+	Regression *Regression `json:"regression,omitempty"`
+	// PrometheusEndpoint defines the local endpoint to serve the tester metrics.
+	PrometheusEndpoint *PrometheusEndpoint `json:"prometheus-endpoint,omitempty"`
+	// CWSummaries defines the CloudWatch publishing of the add-on results.
+	CWSummaries *CWSummaries `json:"cw-summaries,omitempty"`
+	// LatencyHistogram defines the latency histogram backend of the add-on results.
+	LatencyHistogram *LatencyHistogram `json:"latency-histogram"`
+	// OTLPExporter defines the OpenTelemetry export of the tester metrics.
+	OTLPExporter *OTLPExporter `json:"otlp-exporter,omitempty"`
+	// AutoMode defines the EKS Auto Mode cluster without node groups.
+	AutoMode *AutoMode `json:"auto-mode,omitempty"`
+	// OIDCProvider defines the IAM OpenID Connect provider of the cluster for IRSA.
+	OIDCProvider *OIDCProvider `json:"oidc-provider,omitempty"`
+	// Outpost defines the EKS local cluster on AWS Outposts.
+	Outpost *Outpost `json:"outpost,omitempty"`
+	// VersionSkew defines the version skew checks between the control plane and node groups.
+	VersionSkew *VersionSkew `json:"version-skew,omitempty"`
+	// Attach defines the "attach" mode to run add-ons against an existing cluster.
+	Attach *Attach `json:"attach,omitempty"`
+	// ControlPlaneLogging defines the control plane logging to CloudWatch Logs.
+	ControlPlaneLogging *ControlPlaneLogging `json:"control-plane-logging,omitempty"`
+	// KMSRotation defines the envelope encryption key rotation scenario.
+	KMSRotation *KMSRotation `json:"kms-rotation,omitempty"`
+	// CNICustomNetworking defines the VPC CNI custom networking with a secondary CIDR.
+	CNICustomNetworking *CNICustomNetworking `json:"cni-custom-networking,omitempty"`
+	// SecurityGroupsForPods defines the security groups for pods test.
+	SecurityGroupsForPods *SecurityGroupsForPods `json:"security-groups-for-pods,omitempty"`
+	// EndpointPublicAccessCheck defines the public endpoint access control test.
+	EndpointPublicAccessCheck *EndpointPublicAccessCheck `json:"endpoint-public-access-check,omitempty"`
+
+	// Tags defines the tags applied to the EKS cluster and all other resources
+	// the tester creates (e.g. CloudFormation stacks, EC2 instances, security groups,
+	// S3 objects), for cost allocation and garbage collection by tag.
+	// For resources other than the EKS cluster, the tags set by the tester
+	// (e.g. "Name") take precedence.
+	// Once created, the tags are verified on the EKS cluster, the managed
+	// node group ASGs and instances, and the security groups.
 	Tags map[string]string `json:"tags"`
+	// IAMPermissionsBoundaryARN is the IAM policy ARN set as the permissions
+	// boundary of every IAM role the tester creates (e.g. cluster role,
+	// node group roles, IRSA roles), for the accounts that require
+	// a permissions boundary on all new roles.
+	// Leave empty to create the roles without a permissions boundary.
+	// ref. https://docs.aws.amazon.com/IAM/latest/UserGuide/access_policies_boundaries.html
+	IAMPermissionsBoundaryARN string `json:"iam-permissions-boundary-arn"`
+	// IAMPath is the path of every IAM role the tester creates
+	// (e.g. "/eks-testing/"). Must begin and end with "/".
+	// Defaults to "/".
+	// ref. https://docs.aws.amazon.com/IAM/latest/UserGuide/reference_identifiers.html#identifiers-friendly-names
+	IAMPath string `json:"iam-path"`
 	// RequestHeaderKey defines EKS create cluster request header key.
 	RequestHeaderKey string `json:"request-header-key"`
 	// RequestHeaderValue defines EKS create cluster request header value.
@@ -152,6 +215,38 @@ type Config struct {
 	Version      string  `json:"version"`
 	VersionValue float64 `json:"version-value" read-only:"true"`
 
+	// IPFamily is the IP family to assign Kubernetes Pod and Service addresses,
+	// either "ipv4" or "ipv6". IPv6 requires Kubernetes 1.21 or later, and
+	// the VPC is created with an Amazon provided IPv6 CIDR block.
+	// Cannot be changed after cluster creation.
+	// ref. https://docs.aws.amazon.com/eks/latest/userguide/cni-ipv6.html
+	IPFamily string `json:"ip-family"`
+
+	// AuthenticationMode is the cluster authentication mode,
+	// "CONFIG_MAP", "API", or "API_AND_CONFIG_MAP". With "API" modes,
+	// node group roles are registered with EKS access entries
+	// instead of the "aws-auth" ConfigMap.
+	// Requires Kubernetes 1.23 or later for "API" modes.
+	// ref. https://docs.aws.amazon.com/eks/latest/userguide/access-entries.html
+	AuthenticationMode string `json:"authentication-mode"`
+
+	// EndpointPublicAccess is true to enable the public kube-apiserver endpoint.
+	// If both "EndpointPublicAccess" and "EndpointPrivateAccess" are false,
+	// defaults to public access (EKS default).
+	// ref. https://docs.aws.amazon.com/eks/latest/userguide/cluster-endpoint.html
+	EndpointPublicAccess bool `json:"endpoint-public-access"`
+	// EndpointPublicAccessCIDRs is the list of the IPv4 CIDR blocks allowed
+	// to access the public kube-apiserver endpoint.
+	// If empty, the endpoint allows all ("0.0.0.0/0").
+	// If restricted with node groups, "EndpointPrivateAccess" must be true
+	// for the nodes to reach the endpoint.
+	EndpointPublicAccessCIDRs []string `json:"endpoint-public-access-cidrs"`
+	// EndpointPrivateAccess is true to enable the private kube-apiserver endpoint
+	// within the VPC. If true with "EndpointPublicAccess" false, the endpoint is
+	// only reachable within the VPC, thus all kubectl and client-go requests
+	// are sent through the bastion host (see "Bastion").
+	EndpointPrivateAccess bool `json:"endpoint-private-access"`
+
 	// EKS internal only
 	// If empty, use default kube-controller-manager and kube-scheduler qps and burst
 	// ref. https://kubernetes.io/docs/reference/command-line-tools-reference/kube-controller-manager/
@@ -184,6 +279,8 @@ type Config struct {
 	RemoteAccessKeyName string `json:"remote-access-key-name,omitempty"`
 	// RemoteAccessPrivateKeyPath is the file path to store node group key pair private key.
 	// Thus, deployer must delete the private key right after node group creation.
+	// If "RemoteAccessKeyCreate" is false, can be an "ssm://" or "secretsmanager://"
+	// URI of the private key (see "ResolveSecrets").
 	// MAKE SURE PRIVATE KEY NEVER GETS UPLOADED TO CLOUD STORAGE AND DELETE AFTER USE!!!
 	// ref. https://docs.aws.amazon.com/eks/latest/userguide/create-managed-node-group.html
 	// ref. https://docs.aws.amazon.com/AWSCloudFormation/latest/UserGuide/aws-resource-eks-nodegroup.html
@@ -358,6 +455,34 @@ type Config struct {
 	// add-on cuda-vector-add.
 	AddOnCUDAVectorAdd *AddOnCUDAVectorAdd `json:"add-on-cuda-vector-add,omitempty"`
 
+	// AddOnWindowsSmoke defines parameters for EKS cluster
+	// add-on Windows Pod smoke test.
+	AddOnWindowsSmoke *AddOnWindowsSmoke `json:"add-on-windows-smoke,omitempty"`
+
+	// AddOnIPv6 defines parameters for EKS cluster
+	// add-on IPv6 validation.
+	AddOnIPv6 *AddOnIPv6 `json:"add-on-ipv6,omitempty"`
+
+	// AddOnAccessEntries defines parameters for EKS cluster
+	// add-on access entries validation.
+	AddOnAccessEntries *AddOnAccessEntries `json:"add-on-access-entries,omitempty"`
+
+	// AddOnManagedAddons defines parameters for EKS cluster
+	// add-on EKS managed add-ons lifecycle.
+	AddOnManagedAddons *AddOnManagedAddons `json:"add-on-managed-addons,omitempty"`
+
+	// AddOnSpotInterruption defines parameters for EKS cluster
+	// add-on Spot managed node group interruption validation.
+	AddOnSpotInterruption *AddOnSpotInterruption `json:"add-on-spot-interruption,omitempty"`
+
+	// AddOnMNGScale defines parameters for EKS cluster
+	// add-on managed node group scale out/in tester.
+	AddOnMNGScale *AddOnMNGScale `json:"add-on-mng-scale,omitempty"`
+
+	// AddOnMNGRollingUpgrade defines parameters for EKS cluster
+	// add-on managed node group rolling AMI upgrade tester.
+	AddOnMNGRollingUpgrade *AddOnMNGRollingUpgrade `json:"add-on-mng-rolling-upgrade,omitempty"`
+
 	// AddOnClusterLoaderLocal defines parameters for EKS cluster
 	// add-on cluster loader local.
 	// It generates loads from the local host machine.
@@ -437,10 +562,15 @@ func getDefaultS3() *S3 {
 type Encryption struct {
 	// CMKCreate is true to auto-create and delete KMS CMK
 	// for encryption feature.
+	// Set false with non-empty CMKARN to reuse an existing key
+	// (e.g. accounts where KMS key creation is restricted),
+	// which is never scheduled for deletion.
 	CMKCreate bool `json:"cmk-create"`
 	// CMKARN is the KMS CMK ARN for encryption feature.
 	// If not empty, the cluster is created with encryption feature
 	// enabled.
+	// If CMKCreate is false, the existing key must be an enabled
+	// symmetric key whose key policy permits the cluster role.
 	CMKARN string `json:"cmk-arn"`
 }
 
@@ -499,20 +629,37 @@ func getDefaultRole() *Role {
 
 type VPC struct {
 	// Create is true to auto-create and delete VPC.
+	// Set false with "ID" to bring your own VPC, which is never deleted.
 	Create bool `json:"create"`
 	// ID is the VPC ID for cluster creation.
 	// If not empty, VPC is reused and not deleted.
 	// If empty, VPC is created anew and deleted on cluster deletion.
-	ID              string `json:"id"`
-	SecurityGroupID string `json:"security-group-id" read-only:"true"`
+	ID string `json:"id"`
+	// SecurityGroupID is the control plane security group ID.
+	// If empty with an existing VPC, the non-default security group
+	// in the VPC is used.
+	SecurityGroupID string `json:"security-group-id"`
+	// AdditionalSecurityGroupIDs is the list of existing security group IDs
+	// to attach to the control plane, in addition to "SecurityGroupID".
+	AdditionalSecurityGroupIDs []string `json:"additional-security-group-ids,omitempty"`
 
 	// CIDRs is the list of CIDR blocks with IP range (CIDR notation) for the primary VPC Block.
 	// Must be a valid RFC 1918 CIDR range.
 	CIDRs []string `json:"cidrs"`
+	// IPv6CIDR is the Amazon provided IPv6 CIDR block (/56) for the VPC,
+	// only assigned when "IPFamily" is "ipv6". Each subnet gets a /64 block.
+	IPv6CIDR string `json:"ipv6-cidr,omitempty" read-only:"true"`
+	// EgressOnlyInternetGatewayID is the egress-only internet gateway
+	// for outbound IPv6 traffic from private subnets.
+	EgressOnlyInternetGatewayID string `json:"egress-only-internet-gateway-id,omitempty" read-only:"true"`
 
 	// PublicSubnetCIDRs is the CIDR blocks for public subnets.
-	PublicSubnetCIDRs                    []string `json:"public-subnet-cidrs"`
-	PublicSubnetIDs                      []string `json:"public-subnet-ids" read-only:"true"`
+	PublicSubnetCIDRs []string `json:"public-subnet-cidrs"`
+	// PublicSubnetIDs is the list of public subnet IDs.
+	// Node groups are launched in these subnets.
+	// If empty with an existing VPC, subnets are discovered
+	// by the "Network" tag ("Public" or "Private").
+	PublicSubnetIDs                      []string `json:"public-subnet-ids"`
 	InternetGatewayID                    string   `json:"internet-gateway-id" read-only:"true"`
 	PublicRouteTableID                   string   `json:"public-route-table-id" read-only:"true"`
 	PublicSubnetRouteTableAssociationIDs []string `json:"public-subnet-route-table-association-ids" read-only:"true"`
@@ -520,8 +667,10 @@ type VPC struct {
 	NATGatewayIDs                        []string `json:"nat-gateway-ids" read-only:"true"`
 
 	// PrivateSubnetCIDRs is the CIDR blocks for private subnets.
-	PrivateSubnetCIDRs                    []string `json:"private-subnet-cidrs,omitempty"`
-	PrivateSubnetIDs                      []string `json:"private-subnet-ids" read-only:"true"`
+	PrivateSubnetCIDRs []string `json:"private-subnet-cidrs,omitempty"`
+	// PrivateSubnetIDs is the list of private subnet IDs.
+	// Only used with "PublicSubnetIDs" for an existing VPC.
+	PrivateSubnetIDs                      []string `json:"private-subnet-ids"`
 	PrivateRouteTableIDs                  []string `json:"private-route-table-ids" read-only:"true"`
 	PrivateSubnetRouteTableAssociationIDs []string `json:"private-subnet-route-table-association-ids" read-only:"true"`
 
@@ -563,6 +712,122 @@ func getDefaultVPC() *VPC {
 	}
 }
 
+// Bastion defines the bastion host in a public subnet, to reach
+// the private-only kube-apiserver endpoint from outside the VPC.
+// The tester runs "ssh -D" to the bastion host for a local SOCKS5 proxy,
+// and sets "proxy-url" in KUBECONFIG for kubectl and client-go.
+type Bastion struct {
+	// InstanceType is the EC2 instance type for the bastion host.
+	InstanceType string `json:"instance-type"`
+	// ImageIDSSMParameter is the SSM parameter to resolve the bastion host AMI.
+	// Must be an Amazon Linux image for "ec2-user" SSH login.
+	ImageIDSSMParameter string `json:"image-id-ssm-parameter"`
+	// LocalProxyPort is the local port for the SOCKS5 proxy.
+	LocalProxyPort int `json:"local-proxy-port"`
+
+	// InstanceID is the bastion host EC2 instance ID.
+	InstanceID string `json:"instance-id" read-only:"true"`
+	// PublicIP is the bastion host public IP, used for SSH.
+	PublicIP string `json:"public-ip" read-only:"true"`
+	// SecurityGroupID is the security group for the bastion host.
+	SecurityGroupID string `json:"security-group-id" read-only:"true"`
+	// ProxyURL is the SOCKS5 proxy URL set in KUBECONFIG.
+	ProxyURL string `json:"proxy-url" read-only:"true"`
+}
+
+const (
+	// DefaultBastionInstanceType is the default bastion host instance type.
+	DefaultBastionInstanceType = "t3.micro"
+	// DefaultBastionImageIDSSMParameter is the default bastion host AMI SSM parameter.
+	DefaultBastionImageIDSSMParameter = "/aws/service/ami-amazon-linux-latest/amzn2-ami-hvm-x86_64-gp2"
+	// DefaultBastionLocalProxyPort is the default local SOCKS5 proxy port.
+	DefaultBastionLocalProxyPort = 1080
+)
+
+func getDefaultBastion() *Bastion {
+	return &Bastion{
+		InstanceType:        DefaultBastionInstanceType,
+		ImageIDSSMParameter: DefaultBastionImageIDSSMParameter,
+		LocalProxyPort:      DefaultBastionLocalProxyPort,
+	}
+}
+
+// MergeTags returns a copy of "Tags" merged with the given tags,
+// where the given tags take precedence, so that every resource
+// the tester creates can be cost-allocated and garbage-collected by tag.
+func (cfg *Config) MergeTags(tags map[string]string) map[string]string {
+	merged := make(map[string]string, len(cfg.Tags)+len(tags))
+	for k, v := range cfg.Tags {
+		merged[k] = v
+	}
+	for k, v := range tags {
+		merged[k] = v
+	}
+	return merged
+}
+
+// IsPrivateEndpointOnly returns true if the kube-apiserver endpoint
+// is only reachable within the VPC.
+func (cfg *Config) IsPrivateEndpointOnly() bool {
+	return cfg.EndpointPrivateAccess && !cfg.EndpointPublicAccess
+}
+
+func (cfg *Config) validateBastion() error {
+	if !cfg.IsPrivateEndpointOnly() || cfg.IsEnabledAttach() {
+		cfg.Bastion = nil
+		return nil
+	}
+	if cfg.Bastion == nil {
+		cfg.Bastion = getDefaultBastion()
+	}
+	if cfg.Bastion.InstanceType == "" {
+		cfg.Bastion.InstanceType = DefaultBastionInstanceType
+	}
+	if cfg.Bastion.ImageIDSSMParameter == "" {
+		cfg.Bastion.ImageIDSSMParameter = DefaultBastionImageIDSSMParameter
+	}
+	if cfg.Bastion.LocalProxyPort == 0 {
+		cfg.Bastion.LocalProxyPort = DefaultBastionLocalProxyPort
+	}
+	if cfg.Bastion.LocalProxyPort < 0 || cfg.Bastion.LocalProxyPort > 65535 {
+		return fmt.Errorf("invalid Bastion.LocalProxyPort %d", cfg.Bastion.LocalProxyPort)
+	}
+	return nil
+}
+
+// validateExisting validates the subnet and security group IDs
+// for an existing VPC. The subnets are checked against EKS requirements
+// (e.g. availability zones, free IP addresses) on cluster creation.
+// ref. https://docs.aws.amazon.com/eks/latest/userguide/network_reqs.html
+func (v *VPC) validateExisting() error {
+	if len(v.PublicSubnetIDs) == 0 && len(v.PrivateSubnetIDs) > 0 {
+		return fmt.Errorf("VPC.PrivateSubnetIDs %v requires non-empty VPC.PublicSubnetIDs for node groups", v.PrivateSubnetIDs)
+	}
+	subnets := append(append([]string{}, v.PublicSubnetIDs...), v.PrivateSubnetIDs...)
+	if len(subnets) == 1 {
+		return fmt.Errorf("unexpected number of subnets %v (expected at least 2)", subnets)
+	}
+	seen := make(map[string]struct{}, len(subnets))
+	for _, id := range subnets {
+		if !strings.HasPrefix(id, "subnet-") {
+			return fmt.Errorf("invalid subnet ID %q", id)
+		}
+		if _, ok := seen[id]; ok {
+			return fmt.Errorf("duplicate subnet ID %q", id)
+		}
+		seen[id] = struct{}{}
+	}
+	if v.SecurityGroupID != "" && !strings.HasPrefix(v.SecurityGroupID, "sg-") {
+		return fmt.Errorf("invalid VPC.SecurityGroupID %q", v.SecurityGroupID)
+	}
+	for _, id := range v.AdditionalSecurityGroupIDs {
+		if !strings.HasPrefix(id, "sg-") {
+			return fmt.Errorf("invalid VPC.AdditionalSecurityGroupIDs %q", id)
+		}
+	}
+	return nil
+}
+
 // Load loads configuration from YAML.
 // Useful when injecting shared configuration via ConfigMap.
 //
@@ -608,6 +873,9 @@ func Load(p string) (cfg *Config, err error) {
 func (cfg *Config) EvaluateCommandRefs() error {
 	cfg.mu.Lock()
 	err := cfg.evaluateCommandRefs()
+	if serr := cfg.unsafeSync(); serr != nil {
+		fmt.Fprintf(os.Stderr, "[WARN] failed to sync config files %v\n", serr)
+	}
 	cfg.mu.Unlock()
 	return err
 }
@@ -646,10 +914,6 @@ func (cfg *Config) evaluateCommandRefs() error {
 	if cfg.Status != nil && cfg.Status.ClusterARN != "" && strings.Contains(cfg.CommandAfterCreateAddOns, "GetRef.ClusterARN") {
 		cfg.CommandAfterCreateAddOns = strings.ReplaceAll(cfg.CommandAfterCreateAddOns, "GetRef.ClusterARN", cfg.Status.ClusterARN)
 	}
-	if serr := cfg.unsafeSync(); serr != nil {
-		fmt.Fprintf(os.Stderr, "[WARN] failed to sync config files %v\n", serr)
-	}
-
 	return nil
 }
 
@@ -679,7 +943,7 @@ func (cfg *Config) unsafeSync() (err error) {
 	}
 
 	var d []byte
-	d, err = yaml.Marshal(cfg)
+	d, err = cfg.unsafeMarshalYAML()
 	if err != nil {
 		return fmt.Errorf("failed to 'yaml.Marshal' %v", err)
 	}
@@ -851,6 +1115,11 @@ const (
 	MNGsMaxLimit = 10
 	// MNGMaxLimit is the maximum number of nodes per a "Managed Node Group".
 	MNGMaxLimit = 100
+
+	// IPFamilyIPv4 assigns IPv4 addresses to Pods and Services.
+	IPFamilyIPv4 = "ipv4"
+	// IPFamilyIPv6 assigns IPv6 addresses to Pods and Services.
+	IPFamilyIPv6 = "ipv6"
 )
 
 // NewDefault returns a default configuration.
@@ -902,8 +1171,32 @@ func NewDefault() *Config {
 		Role:       getDefaultRole(),
 		VPC:        getDefaultVPC(),
 
-		SigningName: "eks",
-		Version:     "1.27",
+		IAMPath: "/",
+
+		SigningName:        "eks",
+		Version:            "1.27",
+		IPFamily:           IPFamilyIPv4,
+		AuthenticationMode: eks.AuthenticationModeConfigMap,
+
+		EndpointPublicAccess:      true,
+		EndpointPrivateAccess:     false,
+		Bastion:                   getDefaultBastion(),
+		LiveReload:                getDefaultLiveReload(),
+		Regression:                getDefaultRegression(),
+		PrometheusEndpoint:        getDefaultPrometheusEndpoint(),
+		CWSummaries:               getDefaultCWSummaries(),
+		LatencyHistogram:          getDefaultLatencyHistogram(),
+		OTLPExporter:              getDefaultOTLPExporter(),
+		AutoMode:                  getDefaultAutoMode(),
+		OIDCProvider:              getDefaultOIDCProvider(),
+		Outpost:                   getDefaultOutpost(),
+		VersionSkew:               getDefaultVersionSkew(),
+		Attach:                    getDefaultAttach(),
+		ControlPlaneLogging:       getDefaultControlPlaneLogging(),
+		KMSRotation:               getDefaultKMSRotation(),
+		CNICustomNetworking:       getDefaultCNICustomNetworking(),
+		SecurityGroupsForPods:     getDefaultSecurityGroupsForPods(),
+		EndpointPublicAccessCheck: getDefaultEndpointPublicAccessCheck(),
 
 		RemoteAccessKeyCreate: true,
 		// keep in-sync with the default value in https://pkg.go.dev/k8s.io/kubernetes/test/e2e/framework#GetSigner
@@ -957,6 +1250,13 @@ func NewDefault() *Config {
 		AddOnJupyterHub:            getDefaultAddOnJupyterHub(),
 		AddOnKubeflow:              getDefaultAddOnKubeflow(),
 		AddOnCUDAVectorAdd:         getDefaultAddOnCUDAVectorAdd(),
+		AddOnWindowsSmoke:          getDefaultAddOnWindowsSmoke(),
+		AddOnIPv6:                  getDefaultAddOnIPv6(),
+		AddOnAccessEntries:         getDefaultAddOnAccessEntries(),
+		AddOnManagedAddons:         getDefaultAddOnManagedAddons(),
+		AddOnSpotInterruption:      getDefaultAddOnSpotInterruption(),
+		AddOnMNGScale:              getDefaultAddOnMNGScale(),
+		AddOnMNGRollingUpgrade:     getDefaultAddOnMNGRollingUpgrade(),
 		AddOnClusterLoaderLocal:    getDefaultAddOnClusterLoaderLocal(),
 		AddOnClusterLoaderRemote:   getDefaultAddOnClusterLoaderRemote(),
 		AddOnStresserLocal:         getDefaultAddOnStresserLocal(),
@@ -1004,7 +1304,10 @@ func (cfg *Config) ValidateAndSetDefaults() error {
 		}
 		cfg.mu.Unlock()
 	}()
+	return cfg.unsafeValidateAndSetDefaults()
+}
 
+func (cfg *Config) unsafeValidateAndSetDefaults() error {
 	// generically defaults and validates addons that are members of cfg.Spec
 	spec := reflect.ValueOf(cfg.Spec)
 	for i := 0; i < spec.NumField(); i++ {
@@ -1026,11 +1329,18 @@ func (cfg *Config) ValidateAndSetDefaults() error {
 	if err := cfg.validateAddOnManagedNodeGroups(); err != nil {
 		return fmt.Errorf("validateAddOnManagedNodeGroups failed [%v]", err)
 	}
+	if err := cfg.validateSecurityGroupsForPodsInstanceTypes(); err != nil {
+		return fmt.Errorf("validateSecurityGroupsForPodsInstanceTypes failed [%v]", err)
+	}
 
 	if err := cfg.validateAddOnCNIVPC(); err != nil {
 		return fmt.Errorf("validateAddOnCNIVPC failed [%v]", err)
 	}
 
+	if err := cfg.validateAddOnDependencies(); err != nil {
+		return fmt.Errorf("validateAddOnDependencies failed [%v]", err)
+	}
+
 	total := int32(0)
 	if cfg.IsEnabledAddOnNodeGroups() {
 		for _, cur := range cfg.AddOnNodeGroups.ASGs {
@@ -1135,6 +1445,27 @@ func (cfg *Config) ValidateAndSetDefaults() error {
 	if err := cfg.validateAddOnCUDAVectorAdd(); err != nil {
 		return fmt.Errorf("validateAddOnCUDAVectorAdd failed [%v]", err)
 	}
+	if err := cfg.validateAddOnWindowsSmoke(); err != nil {
+		return fmt.Errorf("validateAddOnWindowsSmoke failed [%v]", err)
+	}
+	if err := cfg.validateAddOnIPv6(); err != nil {
+		return fmt.Errorf("validateAddOnIPv6 failed [%v]", err)
+	}
+	if err := cfg.validateAddOnAccessEntries(); err != nil {
+		return fmt.Errorf("validateAddOnAccessEntries failed [%v]", err)
+	}
+	if err := cfg.validateAddOnManagedAddons(); err != nil {
+		return fmt.Errorf("validateAddOnManagedAddons failed [%v]", err)
+	}
+	if err := cfg.validateAddOnSpotInterruption(); err != nil {
+		return fmt.Errorf("validateAddOnSpotInterruption failed [%v]", err)
+	}
+	if err := cfg.validateAddOnMNGScale(); err != nil {
+		return fmt.Errorf("validateAddOnMNGScale failed [%v]", err)
+	}
+	if err := cfg.validateAddOnMNGRollingUpgrade(); err != nil {
+		return fmt.Errorf("validateAddOnMNGRollingUpgrade failed [%v]", err)
+	}
 
 	if err := cfg.validateAddOnClusterLoaderLocal(); err != nil {
 		return fmt.Errorf("validateAddOnClusterLoaderLocal failed [%v]", err)
@@ -1161,6 +1492,10 @@ func (cfg *Config) ValidateAndSetDefaults() error {
 		return fmt.Errorf("validateAddOnClusterVersionUpgrade failed [%v]", err)
 	}
 
+	if err := cfg.validateAddOnCleanupPolicies(); err != nil {
+		return fmt.Errorf("validateAddOnCleanupPolicies failed [%v]", err)
+	}
+
 	return nil
 }
 
@@ -1256,6 +1591,67 @@ func (cfg *Config) validateConfig() error {
 		return fmt.Errorf("cannot parse Parameters.Version %q (%v)", cfg.Version, err)
 	}
 
+	switch cfg.IPFamily {
+	case "":
+		cfg.IPFamily = IPFamilyIPv4
+	case IPFamilyIPv4:
+	case IPFamilyIPv6:
+		if cfg.VersionValue < 1.21 {
+			return fmt.Errorf("IPFamily %q requires Version >= 1.21 (got %q)", cfg.IPFamily, cfg.Version)
+		}
+		// ref. https://docs.aws.amazon.com/eks/latest/userguide/cni-ipv6.html
+		if cfg.HasWindowsNodeGroup() {
+			return fmt.Errorf("IPFamily %q does not support Windows node groups", cfg.IPFamily)
+		}
+	default:
+		return fmt.Errorf("unknown IPFamily %q (expected %q or %q)", cfg.IPFamily, IPFamilyIPv4, IPFamilyIPv6)
+	}
+
+	if err := cfg.validateAutoMode(); err != nil {
+		return err
+	}
+	if err := cfg.validateOutpost(); err != nil {
+		return err
+	}
+	if err := cfg.validateAttach(); err != nil {
+		return err
+	}
+	if err := cfg.validateSecurityGroupsForPods(); err != nil {
+		return err
+	}
+	if err := cfg.validateEndpointPublicAccessCheck(); err != nil {
+		return err
+	}
+	if err := cfg.validateEndpointPublicAccessCIDRs(); err != nil {
+		return err
+	}
+
+	switch {
+	case cfg.IAMPath == "":
+		cfg.IAMPath = "/"
+	case len(cfg.IAMPath) > 512:
+		return fmt.Errorf("IAMPath too long (%d characters, expected <= 512)", len(cfg.IAMPath))
+	case !strings.HasPrefix(cfg.IAMPath, "/") || !strings.HasSuffix(cfg.IAMPath, "/"):
+		return fmt.Errorf("IAMPath %q must begin and end with '/'", cfg.IAMPath)
+	}
+	if cfg.IAMPermissionsBoundaryARN != "" {
+		if !strings.HasPrefix(cfg.IAMPermissionsBoundaryARN, "arn:") || !strings.Contains(cfg.IAMPermissionsBoundaryARN, ":policy/") {
+			return fmt.Errorf("invalid IAMPermissionsBoundaryARN %q (expected IAM policy ARN)", cfg.IAMPermissionsBoundaryARN)
+		}
+	}
+
+	switch cfg.AuthenticationMode {
+	case "":
+		cfg.AuthenticationMode = eks.AuthenticationModeConfigMap
+	case eks.AuthenticationModeConfigMap:
+	case eks.AuthenticationModeApi, eks.AuthenticationModeApiAndConfigMap:
+		if cfg.VersionValue < 1.23 {
+			return fmt.Errorf("AuthenticationMode %q requires Version >= 1.23 (got %q)", cfg.AuthenticationMode, cfg.Version)
+		}
+	default:
+		return fmt.Errorf("unknown AuthenticationMode %q (expected %q, %q, or %q)", cfg.AuthenticationMode, eks.AuthenticationModeConfigMap, eks.AuthenticationModeApi, eks.AuthenticationModeApiAndConfigMap)
+	}
+
 	if len(cfg.Role.ServicePrincipals) == 0 {
 		return errors.New("empty Role.ServicePrincipals")
 	}
@@ -1295,6 +1691,10 @@ func (cfg *Config) validateConfig() error {
 			cfg.Role.Name = cfg.Name + "-role"
 		}
 	case false: // use existing one
+		if cfg.IsEnabledAttach() {
+			// discovered from the attached cluster
+			break
+		}
 		if cfg.Role.ARN == "" {
 			return fmt.Errorf("Role.Create false; expect non-empty RoleARN but got %q", cfg.Role.ARN)
 		}
@@ -1308,20 +1708,61 @@ func (cfg *Config) validateConfig() error {
 
 	switch cfg.VPC.Create {
 	case true: // need create one, or already created
-	// just ignore...
-	// could be populated from previous run
-	// do not error, so long as VPCCreate false, VPC won't be deleted
+		// just ignore...
+		// could be populated from previous run
+		// do not error, so long as VPCCreate false, VPC won't be deleted
+		if len(cfg.VPC.PublicSubnetCIDRs) < 2 {
+			return fmt.Errorf("unexpected number of VPC.PublicSubnetCIDRs %v (expected at least 2)", cfg.VPC.PublicSubnetCIDRs)
+		}
 	case false: // use existing one
+		if cfg.IsEnabledAttach() {
+			// discovered from the attached cluster
+			break
+		}
 		if cfg.VPC.ID == "" {
-			return fmt.Errorf("RoleCreate false; expect non-empty VPCID but got %q", cfg.VPC.ID)
+			return fmt.Errorf("VPC.Create false; expect non-empty VPC.ID but got %q", cfg.VPC.ID)
+		}
+		if err := cfg.VPC.validateExisting(); err != nil {
+			return err
 		}
 	}
 
 	if cfg.VPC.NodeGroupSecurityGroupName == "" {
 		cfg.VPC.NodeGroupSecurityGroupName = cfg.Name + "-node-group-security-group"
 	}
-	if len(cfg.VPC.PublicSubnetCIDRs) < 2 {
-		return fmt.Errorf("unexpected number of VPC.PublicSubnetCIDRs %v (expected at least 2)", cfg.VPC.PublicSubnetCIDRs)
+
+	if !cfg.EndpointPublicAccess && !cfg.EndpointPrivateAccess {
+		cfg.EndpointPublicAccess = true
+	}
+	if err := cfg.validateBastion(); err != nil {
+		return err
+	}
+	if err := cfg.validateLiveReload(); err != nil {
+		return err
+	}
+	if err := cfg.validatePrometheusEndpoint(); err != nil {
+		return err
+	}
+	if err := cfg.validateLatencyHistogram(); err != nil {
+		return err
+	}
+	if err := cfg.validateOTLPExporter(); err != nil {
+		return err
+	}
+	if err := cfg.validateOIDCProvider(); err != nil {
+		return err
+	}
+	if err := cfg.validateVersionSkew(); err != nil {
+		return err
+	}
+	if err := cfg.validateControlPlaneLogging(); err != nil {
+		return err
+	}
+	if err := cfg.validateKMSRotation(); err != nil {
+		return err
+	}
+	if err := cfg.validateCNICustomNetworking(); err != nil {
+		return err
 	}
 
 	switch cfg.Encryption.CMKCreate {
@@ -1334,6 +1775,9 @@ func (cfg *Config) validateConfig() error {
 
 	switch cfg.RemoteAccessKeyCreate {
 	case true: // need create one, or already created
+		if aws_secrets.IsURI(cfg.RemoteAccessPrivateKeyPath) {
+			return fmt.Errorf("RemoteAccessKeyCreate true; expect file path for RemoteAccessPrivateKeyPath but got secret URI %q", cfg.RemoteAccessPrivateKeyPath)
+		}
 		if cfg.RemoteAccessKeyName == "" {
 			cfg.RemoteAccessKeyName = cfg.Name + "-remote-access-key"
 		}
@@ -1348,13 +1792,19 @@ func (cfg *Config) validateConfig() error {
 		if cfg.RemoteAccessPrivateKeyPath == "" {
 			return fmt.Errorf("RemoteAccessKeyCreate false; expect non-empty RemoteAccessPrivateKeyPath but got %q", cfg.RemoteAccessPrivateKeyPath)
 		}
+		// secret URI not resolved yet (see "ResolveSecrets")
+		if aws_secrets.IsURI(cfg.RemoteAccessPrivateKeyPath) {
+			break
+		}
 		if !fileutil.Exist(cfg.RemoteAccessPrivateKeyPath) {
 			return fmt.Errorf("RemoteAccessPrivateKeyPath %q does not exist", cfg.RemoteAccessPrivateKeyPath)
 		}
 	}
-	keyDir := filepath.Dir(cfg.RemoteAccessPrivateKeyPath)
-	if err := fileutil.IsDirWriteable(keyDir); err != nil {
-		return err
+	if !aws_secrets.IsURI(cfg.RemoteAccessPrivateKeyPath) {
+		keyDir := filepath.Dir(cfg.RemoteAccessPrivateKeyPath)
+		if err := fileutil.IsDirWriteable(keyDir); err != nil {
+			return err
+		}
 	}
 
 	if cfg.KubectlCommandsOutputPath == "" {
@@ -1435,10 +1885,16 @@ func (cfg *Config) validateConfig() error {
 			return errors.New("empty S3BucketName")
 		}
 	}
+	if err := cfg.validateRegression(); err != nil {
+		return err
+	}
 
 	if cfg.CWNamespace == "" {
 		cfg.CWNamespace = "aws-k8s-tester-eks"
 	}
+	if err := cfg.validateCWSummaries(); err != nil {
+		return err
+	}
 
 	if cfg.Status == nil {
 		cfg.Status = &Status{
@@ -0,0 +1,97 @@
+package eksconfig
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go/service/eks"
+)
+
+// ControlPlaneLogging defines the control plane logging to CloudWatch Logs,
+// enabled on cluster creation, or updated on the existing cluster
+// (e.g. "Attach", or resumed from the previous states).
+// Once the cluster is healthy, the log streams of "VerifyLogTypes" are
+// checked to be receiving events, so that logging does not fail silently.
+// ref. https://docs.aws.amazon.com/eks/latest/userguide/control-plane-logs.html
+type ControlPlaneLogging struct {
+	// Enable is 'true' to enable the control plane logging.
+	Enable bool `json:"enable"`
+	// LogTypes is the list of the control plane log types to enable.
+	// Defaults to all log types.
+	LogTypes []string `json:"log-types"`
+	// VerifyLogTypes is the list of the log types whose log streams
+	// must be receiving events. Must be a subset of "LogTypes".
+	// Leave empty to skip the verification.
+	VerifyLogTypes []string `json:"verify-log-types"`
+	// VerifyTimeout is the duration to wait for the log events.
+	// The log delivery may take several minutes after the cluster creation.
+	VerifyTimeout       time.Duration `json:"verify-timeout"`
+	VerifyTimeoutString string        `json:"verify-timeout-string" read-only:"true"`
+
+	// LogGroupName is the CloudWatch Logs group name of the cluster.
+	LogGroupName string `json:"log-group-name" read-only:"true"`
+	// VerifiedLogStreams maps the verified log type
+	// to the log stream name that received the events.
+	VerifiedLogStreams map[string]string `json:"verified-log-streams" read-only:"true"`
+}
+
+func getDefaultControlPlaneLogging() *ControlPlaneLogging {
+	return &ControlPlaneLogging{
+		Enable:         false,
+		LogTypes:       eks.LogType_Values(),
+		VerifyLogTypes: []string{eks.LogTypeApi, eks.LogTypeAudit},
+		VerifyTimeout:  15 * time.Minute,
+	}
+}
+
+// IsEnabledControlPlaneLogging returns true if "ControlPlaneLogging" is enabled.
+// Otherwise, nil the field for "omitempty".
+func (cfg *Config) IsEnabledControlPlaneLogging() bool {
+	if cfg.ControlPlaneLogging == nil {
+		return false
+	}
+	if cfg.ControlPlaneLogging.Enable {
+		return true
+	}
+	cfg.ControlPlaneLogging = nil
+	return false
+}
+
+func (cfg *Config) validateControlPlaneLogging() error {
+	if !cfg.IsEnabledControlPlaneLogging() {
+		return nil
+	}
+	if len(cfg.ControlPlaneLogging.LogTypes) == 0 {
+		return errors.New("ControlPlaneLogging.Enable true but empty LogTypes")
+	}
+	enabled := make(map[string]struct{}, len(cfg.ControlPlaneLogging.LogTypes))
+	for _, tp := range cfg.ControlPlaneLogging.LogTypes {
+		if !isValidLogType(tp) {
+			return fmt.Errorf("unknown ControlPlaneLogging.LogTypes %q (valid %q)", tp, eks.LogType_Values())
+		}
+		enabled[tp] = struct{}{}
+	}
+	for _, tp := range cfg.ControlPlaneLogging.VerifyLogTypes {
+		if _, ok := enabled[tp]; !ok {
+			return fmt.Errorf("ControlPlaneLogging.VerifyLogTypes %q not found in LogTypes %q", tp, cfg.ControlPlaneLogging.LogTypes)
+		}
+	}
+
+	if cfg.ControlPlaneLogging.VerifyTimeout == time.Duration(0) {
+		cfg.ControlPlaneLogging.VerifyTimeout = 15 * time.Minute
+	}
+	cfg.ControlPlaneLogging.VerifyTimeoutString = cfg.ControlPlaneLogging.VerifyTimeout.String()
+
+	cfg.ControlPlaneLogging.LogGroupName = fmt.Sprintf("/aws/eks/%s/cluster", cfg.Name)
+	return nil
+}
+
+func isValidLogType(tp string) bool {
+	for _, v := range eks.LogType_Values() {
+		if tp == v {
+			return true
+		}
+	}
+	return false
+}
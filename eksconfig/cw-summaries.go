@@ -0,0 +1,63 @@
+package eksconfig
+
+import "fmt"
+
+// CWSummaries defines the CloudWatch publishing of the add-on
+// "RequestsSummary" results (e.g. "AddOnStresserLocal.RequestsSummaryWrites"),
+// to alarm on test regressions without parsing the S3 artifacts.
+// Each result is published with the "Summary" dimension of its name.
+// ref. "pkg/metrics.CloudWatchSink"
+type CWSummaries struct {
+	// Enable is 'true' to publish the results after all add-ons are created.
+	Enable bool `json:"enable"`
+	// Namespace is the CloudWatch namespace. Defaults to "CWNamespace".
+	Namespace string `json:"namespace"`
+	// Dimensions are the dimensions of every datum.
+	// Defaults to {"ClusterName": "<Name>"}.
+	Dimensions map[string]string `json:"dimensions"`
+}
+
+func getDefaultCWSummaries() *CWSummaries {
+	return &CWSummaries{
+		Enable: false,
+	}
+}
+
+// IsEnabledCWSummaries returns true if "CWSummaries" is enabled.
+// Otherwise, nil the field for "omitempty".
+func (cfg *Config) IsEnabledCWSummaries() bool {
+	if cfg.CWSummaries == nil {
+		return false
+	}
+	if cfg.CWSummaries.Enable {
+		return true
+	}
+	cfg.CWSummaries = nil
+	return false
+}
+
+func (cfg *Config) validateCWSummaries() error {
+	if !cfg.IsEnabledCWSummaries() {
+		return nil
+	}
+	if cfg.CWSummaries.Namespace == "" {
+		cfg.CWSummaries.Namespace = cfg.CWNamespace
+	}
+	if len(cfg.CWSummaries.Dimensions) == 0 {
+		cfg.CWSummaries.Dimensions = map[string]string{"ClusterName": cfg.Name}
+	}
+	// "Summary" dimension is added for each result
+	// ref. https://docs.aws.amazon.com/AmazonCloudWatch/latest/APIReference/API_MetricDatum.html
+	if len(cfg.CWSummaries.Dimensions) > 29 {
+		return fmt.Errorf("too many CWSummaries.Dimensions %d (expected <= 29)", len(cfg.CWSummaries.Dimensions))
+	}
+	for k, v := range cfg.CWSummaries.Dimensions {
+		if k == "" || v == "" {
+			return fmt.Errorf("empty CWSummaries.Dimensions name or value (%q=%q)", k, v)
+		}
+		if k == "Summary" {
+			return fmt.Errorf("reserved CWSummaries.Dimensions name %q", k)
+		}
+	}
+	return nil
+}
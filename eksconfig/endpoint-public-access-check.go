@@ -0,0 +1,131 @@
+package eksconfig
+
+import (
+	"errors"
+	"fmt"
+	"net"
+)
+
+// EndpointPublicAccessCheck defines the public endpoint access control test,
+// where the public kube-apiserver endpoint is restricted to
+// "EndpointPublicAccessCIDRs". Once the cluster is created, the API calls
+// from the tester must succeed, while the probe Lambda function, outside of
+// the cluster VPC and the allowed CIDRs, must fail to reach the endpoint.
+// ref. https://docs.aws.amazon.com/eks/latest/userguide/cluster-endpoint.html
+type EndpointPublicAccessCheck struct {
+	// Enable is 'true' to enable the public endpoint access control test.
+	Enable bool `json:"enable"`
+	// AddRunnerCIDR is true to add the public IP of the tester ("/32")
+	// to "EndpointPublicAccessCIDRs" before the cluster creation,
+	// so that the tester can reach the restricted endpoint.
+	AddRunnerCIDR bool `json:"add-runner-cidr"`
+	// RunnerIPURL is the URL that returns the public IP of the caller,
+	// used for the tester and the probe function.
+	RunnerIPURL string `json:"runner-ip-url"`
+	// ProbeRuntime is the Lambda runtime of the probe function.
+	ProbeRuntime string `json:"probe-runtime"`
+
+	// RunnerCIDR is the CIDR added for the tester public IP.
+	RunnerCIDR string `json:"runner-cidr" read-only:"true"`
+	// ProbeFunctionName is the name of the probe Lambda function.
+	ProbeFunctionName string `json:"probe-function-name" read-only:"true"`
+	// ProbeRoleName is the name of the probe Lambda function execution role.
+	ProbeRoleName string `json:"probe-role-name" read-only:"true"`
+	// ProbeSourceIP is the public IP of the probe function.
+	ProbeSourceIP string `json:"probe-source-ip" read-only:"true"`
+	// ProbeError is the error of the probe function reaching the endpoint,
+	// expected when the endpoint access is denied.
+	ProbeError string `json:"probe-error" read-only:"true"`
+}
+
+func getDefaultEndpointPublicAccessCheck() *EndpointPublicAccessCheck {
+	return &EndpointPublicAccessCheck{
+		Enable:        false,
+		AddRunnerCIDR: true,
+		RunnerIPURL:   "https://checkip.amazonaws.com",
+		ProbeRuntime:  "python3.12",
+	}
+}
+
+// IsEnabledEndpointPublicAccessCheck returns true if "EndpointPublicAccessCheck" is enabled.
+// Otherwise, nil the field for "omitempty".
+func (cfg *Config) IsEnabledEndpointPublicAccessCheck() bool {
+	if cfg.EndpointPublicAccessCheck == nil {
+		return false
+	}
+	if cfg.EndpointPublicAccessCheck.Enable {
+		return true
+	}
+	cfg.EndpointPublicAccessCheck = nil
+	return false
+}
+
+// IsEndpointPublicAccessRestricted returns true if the public endpoint
+// only allows the specific CIDRs.
+func (cfg *Config) IsEndpointPublicAccessRestricted() bool {
+	if !cfg.EndpointPublicAccess {
+		return false
+	}
+	if cfg.IsEnabledEndpointPublicAccessCheck() && cfg.EndpointPublicAccessCheck.AddRunnerCIDR {
+		return true
+	}
+	if len(cfg.EndpointPublicAccessCIDRs) == 0 {
+		return false
+	}
+	for _, v := range cfg.EndpointPublicAccessCIDRs {
+		if v == "0.0.0.0/0" {
+			return false
+		}
+	}
+	return true
+}
+
+func (cfg *Config) validateEndpointPublicAccessCIDRs() error {
+	if len(cfg.EndpointPublicAccessCIDRs) > 0 && !cfg.EndpointPublicAccess {
+		return fmt.Errorf("EndpointPublicAccessCIDRs %q but EndpointPublicAccess false", cfg.EndpointPublicAccessCIDRs)
+	}
+	for _, v := range cfg.EndpointPublicAccessCIDRs {
+		ip, _, err := net.ParseCIDR(v)
+		if err != nil {
+			return fmt.Errorf("invalid EndpointPublicAccessCIDRs %q (%v)", v, err)
+		}
+		if ip.To4() == nil {
+			return fmt.Errorf("invalid EndpointPublicAccessCIDRs %q (expected IPv4 CIDR)", v)
+		}
+	}
+	// nodes in the tester VPC reach the endpoint through the public IPs,
+	// unless the private endpoint is enabled
+	if cfg.IsEndpointPublicAccessRestricted() && !cfg.EndpointPrivateAccess &&
+		(cfg.IsEnabledAddOnNodeGroups() || cfg.IsEnabledAddOnManagedNodeGroups()) {
+		return errors.New("EndpointPublicAccessCIDRs restricted with node groups but EndpointPrivateAccess false")
+	}
+	return nil
+}
+
+func (cfg *Config) validateEndpointPublicAccessCheck() error {
+	if !cfg.IsEnabledEndpointPublicAccessCheck() {
+		return nil
+	}
+	if cfg.IsEnabledAttach() {
+		return errors.New("EndpointPublicAccessCheck.Enable true but Attach.Enable true")
+	}
+	if !cfg.EndpointPublicAccess {
+		return errors.New("EndpointPublicAccessCheck.Enable true but EndpointPublicAccess false")
+	}
+	if !cfg.IsEndpointPublicAccessRestricted() {
+		return fmt.Errorf("EndpointPublicAccessCheck.Enable true but EndpointPublicAccessCIDRs not restricted (%q)", cfg.EndpointPublicAccessCIDRs)
+	}
+	if cfg.EndpointPublicAccessCheck.RunnerIPURL == "" {
+		return errors.New("EndpointPublicAccessCheck.Enable true but empty RunnerIPURL")
+	}
+	if cfg.EndpointPublicAccessCheck.ProbeRuntime == "" {
+		return errors.New("EndpointPublicAccessCheck.Enable true but empty ProbeRuntime")
+	}
+	if cfg.EndpointPublicAccessCheck.ProbeFunctionName == "" {
+		cfg.EndpointPublicAccessCheck.ProbeFunctionName = cfg.Name + "-endpoint-probe"
+	}
+	if cfg.EndpointPublicAccessCheck.ProbeRoleName == "" {
+		cfg.EndpointPublicAccessCheck.ProbeRoleName = cfg.Name + "-endpoint-probe-role"
+	}
+	return nil
+}
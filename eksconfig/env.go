@@ -14,11 +14,28 @@ import (
 
 const (
 	// AWS_K8S_TESTER_EKS_PREFIX is the environment variable prefix used for "eksconfig".
-	AWS_K8S_TESTER_EKS_PREFIX            = "AWS_K8S_TESTER_EKS_"
-	AWS_K8S_TESTER_EKS_S3_PREFIX         = AWS_K8S_TESTER_EKS_PREFIX + "S3_"
-	AWS_K8S_TESTER_EKS_ENCRYPTION_PREFIX = AWS_K8S_TESTER_EKS_PREFIX + "ENCRYPTION_"
-	AWS_K8S_TESTER_EKS_ROLE_PREFIX       = AWS_K8S_TESTER_EKS_PREFIX + "ROLE_"
-	AWS_K8S_TESTER_EKS_VPC_PREFIX        = AWS_K8S_TESTER_EKS_PREFIX + "VPC_"
+	AWS_K8S_TESTER_EKS_PREFIX                              = "AWS_K8S_TESTER_EKS_"
+	AWS_K8S_TESTER_EKS_S3_PREFIX                           = AWS_K8S_TESTER_EKS_PREFIX + "S3_"
+	AWS_K8S_TESTER_EKS_ENCRYPTION_PREFIX                   = AWS_K8S_TESTER_EKS_PREFIX + "ENCRYPTION_"
+	AWS_K8S_TESTER_EKS_ROLE_PREFIX                         = AWS_K8S_TESTER_EKS_PREFIX + "ROLE_"
+	AWS_K8S_TESTER_EKS_VPC_PREFIX                          = AWS_K8S_TESTER_EKS_PREFIX + "VPC_"
+	AWS_K8S_TESTER_EKS_BASTION_PREFIX                      = AWS_K8S_TESTER_EKS_PREFIX + "BASTION_"
+	AWS_K8S_TESTER_EKS_LIVE_RELOAD_PREFIX                  = AWS_K8S_TESTER_EKS_PREFIX + "LIVE_RELOAD_"
+	AWS_K8S_TESTER_EKS_REGRESSION_PREFIX                   = AWS_K8S_TESTER_EKS_PREFIX + "REGRESSION_"
+	AWS_K8S_TESTER_EKS_PROMETHEUS_ENDPOINT_PREFIX          = AWS_K8S_TESTER_EKS_PREFIX + "PROMETHEUS_ENDPOINT_"
+	AWS_K8S_TESTER_EKS_CW_SUMMARIES_PREFIX                 = AWS_K8S_TESTER_EKS_PREFIX + "CW_SUMMARIES_"
+	AWS_K8S_TESTER_EKS_LATENCY_HISTOGRAM_PREFIX            = AWS_K8S_TESTER_EKS_PREFIX + "LATENCY_HISTOGRAM_"
+	AWS_K8S_TESTER_EKS_OTLP_EXPORTER_PREFIX                = AWS_K8S_TESTER_EKS_PREFIX + "OTLP_EXPORTER_"
+	AWS_K8S_TESTER_EKS_AUTO_MODE_PREFIX                    = AWS_K8S_TESTER_EKS_PREFIX + "AUTO_MODE_"
+	AWS_K8S_TESTER_EKS_OIDC_PROVIDER_PREFIX                = AWS_K8S_TESTER_EKS_PREFIX + "OIDC_PROVIDER_"
+	AWS_K8S_TESTER_EKS_OUTPOST_PREFIX                      = AWS_K8S_TESTER_EKS_PREFIX + "OUTPOST_"
+	AWS_K8S_TESTER_EKS_VERSION_SKEW_PREFIX                 = AWS_K8S_TESTER_EKS_PREFIX + "VERSION_SKEW_"
+	AWS_K8S_TESTER_EKS_ATTACH_PREFIX                       = AWS_K8S_TESTER_EKS_PREFIX + "ATTACH_"
+	AWS_K8S_TESTER_EKS_CONTROL_PLANE_LOGGING_PREFIX        = AWS_K8S_TESTER_EKS_PREFIX + "CONTROL_PLANE_LOGGING_"
+	AWS_K8S_TESTER_EKS_KMS_ROTATION_PREFIX                 = AWS_K8S_TESTER_EKS_PREFIX + "KMS_ROTATION_"
+	AWS_K8S_TESTER_EKS_CNI_CUSTOM_NETWORKING_PREFIX        = AWS_K8S_TESTER_EKS_PREFIX + "CNI_CUSTOM_NETWORKING_"
+	AWS_K8S_TESTER_EKS_SECURITY_GROUPS_FOR_PODS_PREFIX     = AWS_K8S_TESTER_EKS_PREFIX + "SECURITY_GROUPS_FOR_PODS_"
+	AWS_K8S_TESTER_EKS_ENDPOINT_PUBLIC_ACCESS_CHECK_PREFIX = AWS_K8S_TESTER_EKS_PREFIX + "ENDPOINT_PUBLIC_ACCESS_CHECK_"
 )
 
 // UpdateFromEnvs updates fields from environmental variables.
@@ -59,6 +76,12 @@ func (cfg *Config) UpdateFromEnvs() (err error) {
 		return fmt.Errorf("expected *Config, got %T", vv)
 	}
 
+	// enable add-ons with their defaults first,
+	// so that each add-on field can still be overwritten below
+	if err = cfg.updateAddOnsFromEnv(); err != nil {
+		return err
+	}
+
 	if cfg.S3 == nil {
 		cfg.S3 = &S3{}
 	}
@@ -111,6 +134,227 @@ func (cfg *Config) UpdateFromEnvs() (err error) {
 		return fmt.Errorf("expected *VPC, got %T", vv)
 	}
 
+	if cfg.Bastion == nil {
+		cfg.Bastion = &Bastion{}
+	}
+	vv, err = parseEnvs(AWS_K8S_TESTER_EKS_BASTION_PREFIX, cfg.Bastion)
+	if err != nil {
+		return err
+	}
+	if av, ok := vv.(*Bastion); ok {
+		cfg.Bastion = av
+	} else {
+		return fmt.Errorf("expected *Bastion, got %T", vv)
+	}
+
+	if cfg.LiveReload == nil {
+		cfg.LiveReload = &LiveReload{}
+	}
+	vv, err = parseEnvs(AWS_K8S_TESTER_EKS_LIVE_RELOAD_PREFIX, cfg.LiveReload)
+	if err != nil {
+		return err
+	}
+	if av, ok := vv.(*LiveReload); ok {
+		cfg.LiveReload = av
+	} else {
+		return fmt.Errorf("expected *LiveReload, got %T", vv)
+	}
+
+	if cfg.Regression == nil {
+		cfg.Regression = &Regression{}
+	}
+	vv, err = parseEnvs(AWS_K8S_TESTER_EKS_REGRESSION_PREFIX, cfg.Regression)
+	if err != nil {
+		return err
+	}
+	if av, ok := vv.(*Regression); ok {
+		cfg.Regression = av
+	} else {
+		return fmt.Errorf("expected *Regression, got %T", vv)
+	}
+
+	if cfg.PrometheusEndpoint == nil {
+		cfg.PrometheusEndpoint = &PrometheusEndpoint{}
+	}
+	vv, err = parseEnvs(AWS_K8S_TESTER_EKS_PROMETHEUS_ENDPOINT_PREFIX, cfg.PrometheusEndpoint)
+	if err != nil {
+		return err
+	}
+	if av, ok := vv.(*PrometheusEndpoint); ok {
+		cfg.PrometheusEndpoint = av
+	} else {
+		return fmt.Errorf("expected *PrometheusEndpoint, got %T", vv)
+	}
+
+	if cfg.CWSummaries == nil {
+		cfg.CWSummaries = &CWSummaries{}
+	}
+	vv, err = parseEnvs(AWS_K8S_TESTER_EKS_CW_SUMMARIES_PREFIX, cfg.CWSummaries)
+	if err != nil {
+		return err
+	}
+	if av, ok := vv.(*CWSummaries); ok {
+		cfg.CWSummaries = av
+	} else {
+		return fmt.Errorf("expected *CWSummaries, got %T", vv)
+	}
+
+	if cfg.LatencyHistogram == nil {
+		cfg.LatencyHistogram = &LatencyHistogram{}
+	}
+	vv, err = parseEnvs(AWS_K8S_TESTER_EKS_LATENCY_HISTOGRAM_PREFIX, cfg.LatencyHistogram)
+	if err != nil {
+		return err
+	}
+	if av, ok := vv.(*LatencyHistogram); ok {
+		cfg.LatencyHistogram = av
+	} else {
+		return fmt.Errorf("expected *LatencyHistogram, got %T", vv)
+	}
+
+	if cfg.OTLPExporter == nil {
+		cfg.OTLPExporter = &OTLPExporter{}
+	}
+	vv, err = parseEnvs(AWS_K8S_TESTER_EKS_OTLP_EXPORTER_PREFIX, cfg.OTLPExporter)
+	if err != nil {
+		return err
+	}
+	if av, ok := vv.(*OTLPExporter); ok {
+		cfg.OTLPExporter = av
+	} else {
+		return fmt.Errorf("expected *OTLPExporter, got %T", vv)
+	}
+
+	if cfg.AutoMode == nil {
+		cfg.AutoMode = &AutoMode{}
+	}
+	vv, err = parseEnvs(AWS_K8S_TESTER_EKS_AUTO_MODE_PREFIX, cfg.AutoMode)
+	if err != nil {
+		return err
+	}
+	if av, ok := vv.(*AutoMode); ok {
+		cfg.AutoMode = av
+	} else {
+		return fmt.Errorf("expected *AutoMode, got %T", vv)
+	}
+
+	if cfg.OIDCProvider == nil {
+		cfg.OIDCProvider = &OIDCProvider{}
+	}
+	vv, err = parseEnvs(AWS_K8S_TESTER_EKS_OIDC_PROVIDER_PREFIX, cfg.OIDCProvider)
+	if err != nil {
+		return err
+	}
+	if av, ok := vv.(*OIDCProvider); ok {
+		cfg.OIDCProvider = av
+	} else {
+		return fmt.Errorf("expected *OIDCProvider, got %T", vv)
+	}
+
+	if cfg.Outpost == nil {
+		cfg.Outpost = &Outpost{}
+	}
+	vv, err = parseEnvs(AWS_K8S_TESTER_EKS_OUTPOST_PREFIX, cfg.Outpost)
+	if err != nil {
+		return err
+	}
+	if av, ok := vv.(*Outpost); ok {
+		cfg.Outpost = av
+	} else {
+		return fmt.Errorf("expected *Outpost, got %T", vv)
+	}
+
+	if cfg.VersionSkew == nil {
+		cfg.VersionSkew = &VersionSkew{}
+	}
+	vv, err = parseEnvs(AWS_K8S_TESTER_EKS_VERSION_SKEW_PREFIX, cfg.VersionSkew)
+	if err != nil {
+		return err
+	}
+	if av, ok := vv.(*VersionSkew); ok {
+		cfg.VersionSkew = av
+	} else {
+		return fmt.Errorf("expected *VersionSkew, got %T", vv)
+	}
+
+	if cfg.Attach == nil {
+		cfg.Attach = &Attach{}
+	}
+	vv, err = parseEnvs(AWS_K8S_TESTER_EKS_ATTACH_PREFIX, cfg.Attach)
+	if err != nil {
+		return err
+	}
+	if av, ok := vv.(*Attach); ok {
+		cfg.Attach = av
+	} else {
+		return fmt.Errorf("expected *Attach, got %T", vv)
+	}
+
+	if cfg.ControlPlaneLogging == nil {
+		cfg.ControlPlaneLogging = &ControlPlaneLogging{}
+	}
+	vv, err = parseEnvs(AWS_K8S_TESTER_EKS_CONTROL_PLANE_LOGGING_PREFIX, cfg.ControlPlaneLogging)
+	if err != nil {
+		return err
+	}
+	if av, ok := vv.(*ControlPlaneLogging); ok {
+		cfg.ControlPlaneLogging = av
+	} else {
+		return fmt.Errorf("expected *ControlPlaneLogging, got %T", vv)
+	}
+
+	if cfg.KMSRotation == nil {
+		cfg.KMSRotation = &KMSRotation{}
+	}
+	vv, err = parseEnvs(AWS_K8S_TESTER_EKS_KMS_ROTATION_PREFIX, cfg.KMSRotation)
+	if err != nil {
+		return err
+	}
+	if av, ok := vv.(*KMSRotation); ok {
+		cfg.KMSRotation = av
+	} else {
+		return fmt.Errorf("expected *KMSRotation, got %T", vv)
+	}
+
+	if cfg.CNICustomNetworking == nil {
+		cfg.CNICustomNetworking = &CNICustomNetworking{}
+	}
+	vv, err = parseEnvs(AWS_K8S_TESTER_EKS_CNI_CUSTOM_NETWORKING_PREFIX, cfg.CNICustomNetworking)
+	if err != nil {
+		return err
+	}
+	if av, ok := vv.(*CNICustomNetworking); ok {
+		cfg.CNICustomNetworking = av
+	} else {
+		return fmt.Errorf("expected *CNICustomNetworking, got %T", vv)
+	}
+
+	if cfg.SecurityGroupsForPods == nil {
+		cfg.SecurityGroupsForPods = &SecurityGroupsForPods{}
+	}
+	vv, err = parseEnvs(AWS_K8S_TESTER_EKS_SECURITY_GROUPS_FOR_PODS_PREFIX, cfg.SecurityGroupsForPods)
+	if err != nil {
+		return err
+	}
+	if av, ok := vv.(*SecurityGroupsForPods); ok {
+		cfg.SecurityGroupsForPods = av
+	} else {
+		return fmt.Errorf("expected *SecurityGroupsForPods, got %T", vv)
+	}
+
+	if cfg.EndpointPublicAccessCheck == nil {
+		cfg.EndpointPublicAccessCheck = &EndpointPublicAccessCheck{}
+	}
+	vv, err = parseEnvs(AWS_K8S_TESTER_EKS_ENDPOINT_PUBLIC_ACCESS_CHECK_PREFIX, cfg.EndpointPublicAccessCheck)
+	if err != nil {
+		return err
+	}
+	if av, ok := vv.(*EndpointPublicAccessCheck); ok {
+		cfg.EndpointPublicAccessCheck = av
+	} else {
+		return fmt.Errorf("expected *EndpointPublicAccessCheck, got %T", vv)
+	}
+
 	if cfg.AddOnCNIVPC == nil {
 		cfg.AddOnCNIVPC = &AddOnCNIVPC{}
 	}
@@ -537,6 +781,97 @@ func (cfg *Config) UpdateFromEnvs() (err error) {
 		return fmt.Errorf("expected *AddOnCUDAVectorAdd, got %T", vv)
 	}
 
+	if cfg.AddOnWindowsSmoke == nil {
+		cfg.AddOnWindowsSmoke = &AddOnWindowsSmoke{}
+	}
+	vv, err = parseEnvs(EnvironmentVariablePrefixAddOnWindowsSmoke, cfg.AddOnWindowsSmoke)
+	if err != nil {
+		return err
+	}
+	if av, ok := vv.(*AddOnWindowsSmoke); ok {
+		cfg.AddOnWindowsSmoke = av
+	} else {
+		return fmt.Errorf("expected *AddOnWindowsSmoke, got %T", vv)
+	}
+
+	if cfg.AddOnIPv6 == nil {
+		cfg.AddOnIPv6 = &AddOnIPv6{}
+	}
+	vv, err = parseEnvs(EnvironmentVariablePrefixAddOnIPv6, cfg.AddOnIPv6)
+	if err != nil {
+		return err
+	}
+	if av, ok := vv.(*AddOnIPv6); ok {
+		cfg.AddOnIPv6 = av
+	} else {
+		return fmt.Errorf("expected *AddOnIPv6, got %T", vv)
+	}
+
+	if cfg.AddOnAccessEntries == nil {
+		cfg.AddOnAccessEntries = &AddOnAccessEntries{}
+	}
+	vv, err = parseEnvs(EnvironmentVariablePrefixAddOnAccessEntries, cfg.AddOnAccessEntries)
+	if err != nil {
+		return err
+	}
+	if av, ok := vv.(*AddOnAccessEntries); ok {
+		cfg.AddOnAccessEntries = av
+	} else {
+		return fmt.Errorf("expected *AddOnAccessEntries, got %T", vv)
+	}
+
+	if cfg.AddOnManagedAddons == nil {
+		cfg.AddOnManagedAddons = &AddOnManagedAddons{}
+	}
+	vv, err = parseEnvs(EnvironmentVariablePrefixAddOnManagedAddons, cfg.AddOnManagedAddons)
+	if err != nil {
+		return err
+	}
+	if av, ok := vv.(*AddOnManagedAddons); ok {
+		cfg.AddOnManagedAddons = av
+	} else {
+		return fmt.Errorf("expected *AddOnManagedAddons, got %T", vv)
+	}
+
+	if cfg.AddOnSpotInterruption == nil {
+		cfg.AddOnSpotInterruption = &AddOnSpotInterruption{}
+	}
+	vv, err = parseEnvs(EnvironmentVariablePrefixAddOnSpotInterruption, cfg.AddOnSpotInterruption)
+	if err != nil {
+		return err
+	}
+	if av, ok := vv.(*AddOnSpotInterruption); ok {
+		cfg.AddOnSpotInterruption = av
+	} else {
+		return fmt.Errorf("expected *AddOnSpotInterruption, got %T", vv)
+	}
+
+	if cfg.AddOnMNGScale == nil {
+		cfg.AddOnMNGScale = &AddOnMNGScale{}
+	}
+	vv, err = parseEnvs(EnvironmentVariablePrefixAddOnMNGScale, cfg.AddOnMNGScale)
+	if err != nil {
+		return err
+	}
+	if av, ok := vv.(*AddOnMNGScale); ok {
+		cfg.AddOnMNGScale = av
+	} else {
+		return fmt.Errorf("expected *AddOnMNGScale, got %T", vv)
+	}
+
+	if cfg.AddOnMNGRollingUpgrade == nil {
+		cfg.AddOnMNGRollingUpgrade = &AddOnMNGRollingUpgrade{}
+	}
+	vv, err = parseEnvs(EnvironmentVariablePrefixAddOnMNGRollingUpgrade, cfg.AddOnMNGRollingUpgrade)
+	if err != nil {
+		return err
+	}
+	if av, ok := vv.(*AddOnMNGRollingUpgrade); ok {
+		cfg.AddOnMNGRollingUpgrade = av
+	} else {
+		return fmt.Errorf("expected *AddOnMNGRollingUpgrade, got %T", vv)
+	}
+
 	if cfg.AddOnClusterLoaderLocal == nil {
 		cfg.AddOnClusterLoaderLocal = &AddOnClusterLoaderLocal{}
 	}
@@ -638,6 +973,7 @@ func parseEnvs(pfx string, addOn interface{}) (interface{}, error) {
 		jv = strings.Replace(jv, ",omitempty", "", -1)
 		jv = strings.ToUpper(strings.Replace(jv, "-", "_", -1))
 		env := pfx + jv
+		recordEnvLookup(env)
 		sv := os.Getenv(env)
 		if sv == "" {
 			continue
@@ -703,7 +1039,11 @@ func parseEnvs(pfx string, addOn interface{}) (interface{}, error) {
 			case "Tags",
 				"NodeSelector",
 				"DeploymentNodeSelector",
-				"DeploymentNodeSelector2048":
+				"DeploymentNodeSelector2048",
+				"BaselineS3Keys",
+				"Dimensions",
+				"ExtraPrincipals",
+				"Headers":
 				vv.Field(i).Set(reflect.ValueOf(make(map[string]string)))
 				mm := make(map[string]string)
 				if err := json.Unmarshal([]byte(sv), &mm); err != nil {
@@ -761,6 +1101,24 @@ func parseEnvs(pfx string, addOn interface{}) (interface{}, error) {
 				}
 				vv.Field(i).Set(reflect.ValueOf(mngs))
 
+			case "Addons":
+				addons := make(map[string]ManagedAddon)
+				if err := json.Unmarshal([]byte(sv), &addons); err != nil {
+					return nil, fmt.Errorf("failed to parse %q (field name %q, environmental variable key %q, error %v)", sv, fieldName, env, err)
+				}
+				for k, v := range addons {
+					tp2, vv2 := reflect.TypeOf(&v).Elem(), reflect.ValueOf(&v).Elem()
+					for j := 0; j < tp2.NumField(); j++ {
+						if tp2.Field(j).Tag.Get("read-only") != "true" {
+							continue
+						}
+						// skip updating read-only field
+						vv2.Field(j).Set(reflect.Zero(tp2.Field(j).Type))
+					}
+					addons[k] = v
+				}
+				vv.Field(i).Set(reflect.ValueOf(addons))
+
 			default:
 				return nil, fmt.Errorf("field %q not supported for reflect.Map", fieldName)
 			}
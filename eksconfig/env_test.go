@@ -1,19 +1,25 @@
 package eksconfig
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"os"
+	"path/filepath"
 	"reflect"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/aws/aws-k8s-tester/ec2config"
+	"github.com/aws/aws-k8s-tester/pkg/fileutil"
+	"github.com/aws/aws-k8s-tester/pkg/metrics"
 	"github.com/aws/aws-k8s-tester/pkg/randutil"
 	"github.com/aws/aws-sdk-go/service/eks"
 	"github.com/google/go-cmp/cmp"
 	"github.com/stretchr/testify/assert"
+	"sigs.k8s.io/yaml"
 )
 
 func TestEnv(t *testing.T) {
@@ -140,6 +146,8 @@ spec:
 	defer os.Unsetenv("AWS_K8S_TESTER_EKS_ADD_ON_CLUSTER_WAIT_BEFORE_UPGRADE_UPGRADE_VERSION")
 	os.Setenv("AWS_K8S_TESTER_EKS_ADD_ON_CLUSTER_VERSION_UPGRADE_VERSION", "1.19")
 	defer os.Unsetenv("AWS_K8S_TESTER_EKS_ADD_ON_CLUSTER_VERSION_UPGRADE_VERSION")
+	os.Setenv("AWS_K8S_TESTER_EKS_ADD_ON_CLUSTER_VERSION_UPGRADE_RERUN_ADD_ONS", "configmaps,csrs-local")
+	defer os.Unsetenv("AWS_K8S_TESTER_EKS_ADD_ON_CLUSTER_VERSION_UPGRADE_RERUN_ADD_ONS")
 
 	os.Setenv("AWS_K8S_TESTER_EKS_REMOTE_ACCESS_KEY_CREATE", "true")
 	defer os.Unsetenv("AWS_K8S_TESTER_EKS_REMOTE_ACCESS_KEY_CREATE")
@@ -532,6 +540,8 @@ spec:
 	defer os.Unsetenv("AWS_K8S_TESTER_EKS_ADD_ON_STRESSER_LOCAL_DURATION")
 	os.Setenv("AWS_K8S_TESTER_EKS_ADD_ON_STRESSER_LOCAL_LIST_LIMIT", "133")
 	defer os.Unsetenv("AWS_K8S_TESTER_EKS_ADD_ON_STRESSER_LOCAL_LIST_LIMIT")
+	os.Setenv("AWS_K8S_TESTER_EKS_ADD_ON_STRESSER_LOCAL_THROUGHPUT_SAMPLE_INTERVAL", "5s")
+	defer os.Unsetenv("AWS_K8S_TESTER_EKS_ADD_ON_STRESSER_LOCAL_THROUGHPUT_SAMPLE_INTERVAL")
 
 	os.Setenv("AWS_K8S_TESTER_EKS_ADD_ON_STRESSER_REMOTE_ENABLE", "true")
 	defer os.Unsetenv("AWS_K8S_TESTER_EKS_ADD_ON_STRESSER_REMOTE_ENABLE")
@@ -775,6 +785,9 @@ spec:
 	if cfg.AddOnClusterVersionUpgrade.Version != "1.19" {
 		t.Fatalf("unexpected AddOnClusterVersionUpgrade.Version %q", cfg.AddOnClusterVersionUpgrade.Version)
 	}
+	if !reflect.DeepEqual(cfg.AddOnClusterVersionUpgrade.RerunAddOns, []string{"configmaps", "csrs-local"}) {
+		t.Fatalf("unexpected AddOnClusterVersionUpgrade.RerunAddOns %q", cfg.AddOnClusterVersionUpgrade.RerunAddOns)
+	}
 
 	if !cfg.RemoteAccessKeyCreate {
 		t.Fatalf("unexpected cfg.RemoteAccessKeyCreate %v", cfg.RemoteAccessKeyCreate)
@@ -1470,6 +1483,9 @@ spec:
 	if cfg.AddOnStresserLocal.ListLimit != 133 {
 		t.Fatalf("unexpected cfg.AddOnStresserLocal.ListLimit %v", cfg.AddOnStresserLocal.ListLimit)
 	}
+	if cfg.AddOnStresserLocal.ThroughputSampleInterval != 5*time.Second {
+		t.Fatalf("unexpected cfg.AddOnStresserLocal.ThroughputSampleInterval %v", cfg.AddOnStresserLocal.ThroughputSampleInterval)
+	}
 
 	if !cfg.AddOnStresserRemote.Enable {
 		t.Fatalf("unexpected cfg.AddOnStresserRemote.Enable %v", cfg.AddOnStresserRemote.Enable)
@@ -1557,6 +1573,9 @@ spec:
 	if err := cfg.ValidateAndSetDefaults(); err != nil {
 		t.Fatal(err)
 	}
+	if !reflect.DeepEqual(cfg.AddOnClusterVersionUpgrade.RerunAddOns, []string{"configmaps-local", "csrs-local"}) {
+		t.Fatalf("unexpected AddOnClusterVersionUpgrade.RerunAddOns %q", cfg.AddOnClusterVersionUpgrade.RerunAddOns)
+	}
 
 	cfg.AddOnNLBHelloWorld.Enable = false
 	cfg.AddOnALB2048.Enable = false
@@ -2084,3 +2103,2607 @@ func TestEnvAddOnKubeflow(t *testing.T) {
 		t.Fatalf("unexpected cfg.AddOnKubeflow.BaseDir %q", cfg.AddOnKubeflow.BaseDir)
 	}
 }
+
+func TestLoadSpec(t *testing.T) {
+	dir, err := ioutil.TempDir(os.TempDir(), "eksconfig-spec")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	cfg := NewDefault()
+	cfg.LogColor = true
+	cfg.KubectlCommandsOutputPath = ""
+	cfg.RemoteAccessCommandsOutputPath = ""
+	cfg.Status = &Status{Up: true}
+	specPath := filepath.Join(dir, "cluster.yaml")
+	if err = cfg.WriteSpec(specPath); err != nil {
+		t.Fatal(err)
+	}
+	spec, err := ioutil.ReadFile(specPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := LoadSpec(specPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.Name != cfg.Name {
+		t.Fatalf("expected name %q, got %q", cfg.Name, loaded.Name)
+	}
+	if loaded.Status != nil {
+		t.Fatalf("unexpected status %+v", loaded.Status)
+	}
+	if loaded.ConfigPath != filepath.Join(dir, "cluster.state.yaml") {
+		t.Fatalf("unexpected state path %q", loaded.ConfigPath)
+	}
+
+	os.Setenv("AWS_K8S_TESTER_EKS_LOG_COLOR", "false")
+	defer os.Unsetenv("AWS_K8S_TESTER_EKS_LOG_COLOR")
+	if err = loaded.UpdateFromEnvs(); err != nil {
+		t.Fatal(err)
+	}
+	if loaded.LogColor {
+		t.Fatal("expected env to override spec")
+	}
+
+	after, err := ioutil.ReadFile(specPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(spec) != string(after) {
+		t.Fatal("spec file must not be overwritten")
+	}
+
+	ioutil.WriteFile(specPath, append(spec, []byte("status:\n  up: true\n")...), 0600)
+	if _, err = LoadSpec(specPath); err == nil {
+		t.Fatal("expected error for spec with status")
+	}
+}
+
+func TestValidateStrict(t *testing.T) {
+	cfg := NewDefault()
+	d, err := yaml.Marshal(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = ValidateStrict(d); err != nil {
+		t.Fatalf("default config must be valid: %v", err)
+	}
+
+	err = ValidateStrict([]byte(`
+name: test
+log-color: "maybe"
+add-on-node-groups:
+  enabled: true
+`))
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	for _, s := range []string{
+		`line 3: field "log-color" expected bool`,
+		`line 5: unknown field "add-on-node-groups.enabled"`,
+	} {
+		if !strings.Contains(err.Error(), s) {
+			t.Fatalf("expected %q, got %v", s, err)
+		}
+	}
+
+	if _, err = JSONSchema(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestUnknownEnvs(t *testing.T) {
+	cfg := NewDefault()
+	defer func() {
+		os.RemoveAll(cfg.ConfigPath)
+		os.RemoveAll(cfg.KubectlCommandsOutputPath)
+		os.RemoveAll(cfg.RemoteAccessCommandsOutputPath)
+	}()
+
+	os.Setenv("AWS_K8S_TESTER_EKS_LOG_COLOR", "false")
+	defer os.Unsetenv("AWS_K8S_TESTER_EKS_LOG_COLOR")
+	os.Setenv("AWS_K8S_TESTER_EKS_ADD_ON_NODEGROUPS_ENABLE", "true")
+	defer os.Unsetenv("AWS_K8S_TESTER_EKS_ADD_ON_NODEGROUPS_ENABLE")
+
+	err := cfg.UpdateFromEnvsStrict()
+	if err == nil || !strings.Contains(err.Error(), "AWS_K8S_TESTER_EKS_ADD_ON_NODEGROUPS_ENABLE") {
+		t.Fatalf("expected unknown env error, got %v", err)
+	}
+	if strings.Contains(err.Error(), "AWS_K8S_TESTER_EKS_LOG_COLOR") {
+		t.Fatalf("unexpected known env in error %v", err)
+	}
+}
+
+func TestApplyPreset(t *testing.T) {
+	cfg := NewDefault()
+	defer func() {
+		os.RemoveAll(cfg.ConfigPath)
+		os.RemoveAll(cfg.KubectlCommandsOutputPath)
+		os.RemoveAll(cfg.RemoteAccessCommandsOutputPath)
+	}()
+
+	if err := cfg.ApplyPreset("unknown"); err == nil {
+		t.Fatal("expected unknown preset error")
+	}
+	if err := cfg.ApplyPreset(PresetConformance); err != nil {
+		t.Fatal(err)
+	}
+
+	os.Setenv("AWS_K8S_TESTER_EKS_ADD_ON_CONFORMANCE_SONOBUOY_RUN_MODE", "quick")
+	defer os.Unsetenv("AWS_K8S_TESTER_EKS_ADD_ON_CONFORMANCE_SONOBUOY_RUN_MODE")
+	if err := cfg.UpdateFromEnvs(); err != nil {
+		t.Fatal(err)
+	}
+
+	if !cfg.AddOnConformance.Enable {
+		t.Fatal("expected AddOnConformance.Enable")
+	}
+	if cfg.AddOnConformance.SonobuoyRunTimeout != 6*time.Hour {
+		t.Fatalf("unexpected AddOnConformance.SonobuoyRunTimeout %v", cfg.AddOnConformance.SonobuoyRunTimeout)
+	}
+	if cfg.AddOnConformance.SonobuoyRunMode != "quick" {
+		t.Fatalf("expected env override, got AddOnConformance.SonobuoyRunMode %q", cfg.AddOnConformance.SonobuoyRunMode)
+	}
+	if !cfg.AddOnManagedNodeGroups.Enable || cfg.AddOnNodeGroups.Enable {
+		t.Fatalf("unexpected node groups %v, %v", cfg.AddOnManagedNodeGroups.Enable, cfg.AddOnNodeGroups.Enable)
+	}
+	for _, cur := range cfg.AddOnManagedNodeGroups.MNGs {
+		if cur.ASGDesiredCapacity != 3 {
+			t.Fatalf("unexpected ASGDesiredCapacity %d", cur.ASGDesiredCapacity)
+		}
+	}
+}
+
+func TestAddOnDependencies(t *testing.T) {
+	cfg := NewDefault()
+	defer func() {
+		os.RemoveAll(cfg.ConfigPath)
+		os.RemoveAll(cfg.KubectlCommandsOutputPath)
+		os.RemoveAll(cfg.RemoteAccessCommandsOutputPath)
+	}()
+
+	os.Setenv("AWS_K8S_TESTER_EKS_ADD_ON_MANAGED_NODE_GROUPS_ENABLE", `true`)
+	defer os.Unsetenv("AWS_K8S_TESTER_EKS_ADD_ON_MANAGED_NODE_GROUPS_ENABLE")
+	os.Setenv("AWS_K8S_TESTER_EKS_ADD_ON_KUBERNETES_DASHBOARD_ENABLE", "true")
+	defer os.Unsetenv("AWS_K8S_TESTER_EKS_ADD_ON_KUBERNETES_DASHBOARD_ENABLE")
+
+	if err := cfg.UpdateFromEnvs(); err != nil {
+		t.Fatal(err)
+	}
+	err := cfg.ValidateAndSetDefaults()
+	if err == nil || !strings.Contains(err.Error(), "AddOnMetricsServer.Enable false") {
+		t.Fatalf("expected dependency error, got %v", err)
+	}
+
+	cfg.AddOnMetricsServer.Enable = true
+	if err = cfg.ValidateAndSetDefaults(); err != nil {
+		t.Fatal(err)
+	}
+	if enabled := cfg.EnabledAddOns(); !reflect.DeepEqual(enabled, []string{"add-on-managed-node-groups", "add-on-metrics-server", "add-on-kubernetes-dashboard"}) {
+		t.Fatalf("unexpected enabled add-ons %q", enabled)
+	}
+
+	ordered, err := OrderAddOns([]string{"add-on-kubernetes-dashboard", "add-on-fluentd", "add-on-metrics-server"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(ordered, []string{"add-on-fluentd", "add-on-metrics-server", "add-on-kubernetes-dashboard"}) {
+		t.Fatalf("unexpected order %q", ordered)
+	}
+}
+
+func TestEnvAddOnManagedNodeGroupsScheduling(t *testing.T) {
+	cfg := NewDefault()
+	defer func() {
+		os.RemoveAll(cfg.ConfigPath)
+		os.RemoveAll(cfg.KubectlCommandsOutputPath)
+		os.RemoveAll(cfg.RemoteAccessCommandsOutputPath)
+	}()
+
+	os.Setenv("AWS_K8S_TESTER_EKS_ADD_ON_MANAGED_NODE_GROUPS_ENABLE", `true`)
+	defer os.Unsetenv("AWS_K8S_TESTER_EKS_ADD_ON_MANAGED_NODE_GROUPS_ENABLE")
+	os.Setenv("AWS_K8S_TESTER_EKS_ADD_ON_MANAGED_NODE_GROUPS_MNGS", `{"test-mng-cpu":{"name":"test-mng-cpu","ami-type":"AL2_x86_64","asg-min-size":1,"asg-max-size":1,"asg-desired-capacity":1,"labels":{"workload":"web"}},"test-mng-spot":{"name":"test-mng-spot","ami-type":"AL2_ARM_64","capacity-type":"SPOT","subnet-ids":["subnet-1"],"asg-min-size":2,"asg-max-size":2,"asg-desired-capacity":2,"taints":[{"key":"batch","value":"true","effect":"NO_SCHEDULE"}]}}`)
+	defer os.Unsetenv("AWS_K8S_TESTER_EKS_ADD_ON_MANAGED_NODE_GROUPS_MNGS")
+
+	if err := cfg.UpdateFromEnvs(); err != nil {
+		t.Fatal(err)
+	}
+	if err := cfg.ValidateAndSetDefaults(); err != nil {
+		t.Fatal(err)
+	}
+
+	cpu, spot := cfg.AddOnManagedNodeGroups.MNGs["test-mng-cpu"], cfg.AddOnManagedNodeGroups.MNGs["test-mng-spot"]
+	if !reflect.DeepEqual(cpu.Labels, map[string]string{"workload": "web"}) {
+		t.Fatalf("unexpected Labels %v", cpu.Labels)
+	}
+	if spot.CapacityType != "SPOT" || !reflect.DeepEqual(spot.SubnetIDs, []string{"subnet-1"}) {
+		t.Fatalf("unexpected CapacityType %q, SubnetIDs %q", spot.CapacityType, spot.SubnetIDs)
+	}
+	if !reflect.DeepEqual(spot.Taints, []MNGTaint{{Key: "batch", Value: "true", Effect: "NO_SCHEDULE"}}) {
+		t.Fatalf("unexpected Taints %+v", spot.Taints)
+	}
+	if cfg.TotalNodes != 3 {
+		t.Fatalf("unexpected TotalNodes %d", cfg.TotalNodes)
+	}
+
+	spot.Taints[0].Effect = "NoSchedule"
+	cfg.AddOnManagedNodeGroups.MNGs["test-mng-spot"] = spot
+	err := cfg.ValidateAndSetDefaults()
+	if err == nil || !strings.Contains(err.Error(), "Taints[0].Effect") {
+		t.Fatalf("expected invalid taint effect error, got %v", err)
+	}
+}
+
+func TestEnvAddOnManagedNodeGroupsLaunchTemplate(t *testing.T) {
+	cfg := NewDefault()
+	defer func() {
+		os.RemoveAll(cfg.ConfigPath)
+		os.RemoveAll(cfg.KubectlCommandsOutputPath)
+		os.RemoveAll(cfg.RemoteAccessCommandsOutputPath)
+	}()
+
+	os.Setenv("AWS_K8S_TESTER_EKS_ADD_ON_MANAGED_NODE_GROUPS_ENABLE", `true`)
+	defer os.Unsetenv("AWS_K8S_TESTER_EKS_ADD_ON_MANAGED_NODE_GROUPS_ENABLE")
+	os.Setenv("AWS_K8S_TESTER_EKS_ADD_ON_MANAGED_NODE_GROUPS_MNGS", `{"mng-lt":{"name":"mng-lt","ami-type":"AL2_x86_64","asg-min-size":1,"asg-max-size":1,"asg-desired-capacity":1,"volume-size":60,"launch-template":{"enable":true,"user-data":"echo hello","tags":{"team":"eks"}}},"mng-lt-devices":{"name":"mng-lt-devices","ami-type":"AL2_x86_64","asg-min-size":1,"asg-max-size":1,"asg-desired-capacity":1,"launch-template":{"enable":true,"metadata-http-tokens":"optional","metadata-http-put-response-hop-limit":1,"block-devices":[{"device-name":"/dev/xvda","volume-size":100,"iops":4000,"throughput":250},{"device-name":"/dev/xvdb","volume-size":200,"volume-type":"io2","iops":10000,"encrypted":true}]}}}`)
+	defer os.Unsetenv("AWS_K8S_TESTER_EKS_ADD_ON_MANAGED_NODE_GROUPS_MNGS")
+
+	if err := cfg.UpdateFromEnvs(); err != nil {
+		t.Fatal(err)
+	}
+	if err := cfg.ValidateAndSetDefaults(); err != nil {
+		t.Fatal(err)
+	}
+
+	lt := cfg.AddOnManagedNodeGroups.MNGs["mng-lt"].LaunchTemplate
+	if lt.UserData != "echo hello" || !reflect.DeepEqual(lt.Tags, map[string]string{"team": "eks"}) {
+		t.Fatalf("unexpected LaunchTemplate %+v", lt)
+	}
+	if lt.MetadataHTTPTokens != "required" || lt.MetadataHTTPPutResponseHopLimit != 2 {
+		t.Fatalf("unexpected metadata options %q, %d", lt.MetadataHTTPTokens, lt.MetadataHTTPPutResponseHopLimit)
+	}
+	if !reflect.DeepEqual(lt.BlockDevices, []MNGBlockDevice{{DeviceName: "/dev/xvda", VolumeSize: 60, VolumeType: "gp3"}}) {
+		t.Fatalf("unexpected BlockDevices %+v", lt.BlockDevices)
+	}
+	lt = cfg.AddOnManagedNodeGroups.MNGs["mng-lt-devices"].LaunchTemplate
+	if lt.MetadataHTTPTokens != "optional" || lt.MetadataHTTPPutResponseHopLimit != 1 {
+		t.Fatalf("unexpected metadata options %q, %d", lt.MetadataHTTPTokens, lt.MetadataHTTPPutResponseHopLimit)
+	}
+	expected := []MNGBlockDevice{
+		{DeviceName: "/dev/xvda", VolumeSize: 100, VolumeType: "gp3", IOPS: 4000, Throughput: 250},
+		{DeviceName: "/dev/xvdb", VolumeSize: 200, VolumeType: "io2", IOPS: 10000, Encrypted: true},
+	}
+	if !reflect.DeepEqual(lt.BlockDevices, expected) {
+		t.Fatalf("expected BlockDevices %+v, got %+v", expected, lt.BlockDevices)
+	}
+
+	lt.BlockDevices[1].Throughput = 500
+	err := cfg.ValidateAndSetDefaults()
+	if err == nil || !strings.Contains(err.Error(), "BlockDevices[1].Throughput") {
+		t.Fatalf("expected invalid throughput error, got %v", err)
+	}
+	lt.BlockDevices[1].Throughput = 0
+	lt.MetadataHTTPPutResponseHopLimit = 65
+	err = cfg.ValidateAndSetDefaults()
+	if err == nil || !strings.Contains(err.Error(), "MetadataHTTPPutResponseHopLimit") {
+		t.Fatalf("expected invalid hop limit error, got %v", err)
+	}
+	lt.MetadataHTTPPutResponseHopLimit = 2
+
+	rocket := cfg.AddOnManagedNodeGroups.MNGs["mng-lt"]
+	rocket.AMIType = "BOTTLEROCKET_x86_64"
+	rocket.RemoteAccessUserName = "ec2-user"
+	rocket.InstanceTypes = nil
+	cfg.AddOnManagedNodeGroups.MNGs["mng-lt"] = rocket
+	err = cfg.ValidateAndSetDefaults()
+	if err == nil || !strings.Contains(err.Error(), "UserData not supported") {
+		t.Fatalf("expected Bottlerocket user data error, got %v", err)
+	}
+}
+
+func TestEnvAddOnManagedNodeGroupsCustomAMI(t *testing.T) {
+	cfg := NewDefault()
+	defer func() {
+		os.RemoveAll(cfg.ConfigPath)
+		os.RemoveAll(cfg.KubectlCommandsOutputPath)
+		os.RemoveAll(cfg.RemoteAccessCommandsOutputPath)
+	}()
+
+	os.Setenv("AWS_K8S_TESTER_EKS_ADD_ON_MANAGED_NODE_GROUPS_ENABLE", `true`)
+	defer os.Unsetenv("AWS_K8S_TESTER_EKS_ADD_ON_MANAGED_NODE_GROUPS_ENABLE")
+	os.Setenv("AWS_K8S_TESTER_EKS_ADD_ON_MANAGED_NODE_GROUPS_MNGS", `{"mng-ami":{"name":"mng-ami","ami-type":"AL2_x86_64","asg-min-size":1,"asg-max-size":1,"asg-desired-capacity":1,"launch-template":{"enable":true,"image-id":"ami-0123456789abcdef0"}},"mng-ssm":{"name":"mng-ssm","ami-type":"AL2_x86_64","asg-min-size":1,"asg-max-size":1,"asg-desired-capacity":1,"launch-template":{"enable":true,"image-id":"ami-0123456789abcdef0","image-id-ssm-parameter":"/aws/service/eks/optimized-ami/1.18/amazon-linux-2/recommended/image_id"}}}`)
+	defer os.Unsetenv("AWS_K8S_TESTER_EKS_ADD_ON_MANAGED_NODE_GROUPS_MNGS")
+
+	if err := cfg.UpdateFromEnvs(); err != nil {
+		t.Fatal(err)
+	}
+	if err := cfg.ValidateAndSetDefaults(); err != nil {
+		t.Fatal(err)
+	}
+
+	cur := cfg.AddOnManagedNodeGroups.MNGs["mng-ami"]
+	if !cur.IsCustomAMI() || cur.LaunchTemplate.ImageID != "ami-0123456789abcdef0" {
+		t.Fatalf("unexpected LaunchTemplate %+v", cur.LaunchTemplate)
+	}
+	ssm := cfg.AddOnManagedNodeGroups.MNGs["mng-ssm"]
+	if !ssm.IsCustomAMI() || ssm.LaunchTemplate.ImageID != "" {
+		t.Fatalf("expected ImageID overridden by ImageIDSSMParameter, got %+v", ssm.LaunchTemplate)
+	}
+
+	cur.LaunchTemplate.ImageID = "0123456789abcdef0"
+	err := cfg.ValidateAndSetDefaults()
+	if err == nil || !strings.Contains(err.Error(), "invalid ImageID") {
+		t.Fatalf("expected invalid ImageID error, got %v", err)
+	}
+	cur.LaunchTemplate.ImageID = "ami-0123456789abcdef0"
+
+	cur.Version = cfg.Version
+	cfg.AddOnManagedNodeGroups.MNGs["mng-ami"] = cur
+	err = cfg.ValidateAndSetDefaults()
+	if err == nil || !strings.Contains(err.Error(), "custom AMI but Version") {
+		t.Fatalf("expected custom AMI version error, got %v", err)
+	}
+	cur.Version = ""
+
+	cur.VersionUpgrade = &MNGVersionUpgrade{Enable: true, Version: cfg.Version}
+	cfg.AddOnManagedNodeGroups.MNGs["mng-ami"] = cur
+	err = cfg.ValidateAndSetDefaults()
+	if err == nil || !strings.Contains(err.Error(), "VersionUpgrade not supported for custom AMI") {
+		t.Fatalf("expected custom AMI version upgrade error, got %v", err)
+	}
+}
+
+func TestEnvAddOnWindowsSmoke(t *testing.T) {
+	cfg := NewDefault()
+	defer func() {
+		os.RemoveAll(cfg.ConfigPath)
+		os.RemoveAll(cfg.KubectlCommandsOutputPath)
+		os.RemoveAll(cfg.RemoteAccessCommandsOutputPath)
+	}()
+
+	os.Setenv("AWS_K8S_TESTER_EKS_ADD_ON_NODE_GROUPS_ENABLE", `true`)
+	defer os.Unsetenv("AWS_K8S_TESTER_EKS_ADD_ON_NODE_GROUPS_ENABLE")
+	os.Setenv("AWS_K8S_TESTER_EKS_ADD_ON_NODE_GROUPS_ASGS", `{"ng-windows":{"name":"ng-windows","ami-type":"WINDOWS_SERVER_CORE_2019_x86_64","asg-min-size":1,"asg-max-size":1,"asg-desired-capacity":1}}`)
+	defer os.Unsetenv("AWS_K8S_TESTER_EKS_ADD_ON_NODE_GROUPS_ASGS")
+	os.Setenv("AWS_K8S_TESTER_EKS_ADD_ON_WINDOWS_SMOKE_ENABLE", "true")
+	defer os.Unsetenv("AWS_K8S_TESTER_EKS_ADD_ON_WINDOWS_SMOKE_ENABLE")
+
+	if err := cfg.UpdateFromEnvs(); err != nil {
+		t.Fatal(err)
+	}
+	err := cfg.ValidateAndSetDefaults()
+	if err == nil || !strings.Contains(err.Error(), "requires at least one Linux node group") {
+		t.Fatalf("expected Linux node group error, got %v", err)
+	}
+
+	os.Setenv("AWS_K8S_TESTER_EKS_ADD_ON_MANAGED_NODE_GROUPS_ENABLE", `true`)
+	defer os.Unsetenv("AWS_K8S_TESTER_EKS_ADD_ON_MANAGED_NODE_GROUPS_ENABLE")
+	os.Setenv("AWS_K8S_TESTER_EKS_ADD_ON_MANAGED_NODE_GROUPS_MNGS", `{"mng-linux":{"name":"mng-linux","ami-type":"AL2_x86_64","asg-min-size":1,"asg-max-size":1,"asg-desired-capacity":1}}`)
+	defer os.Unsetenv("AWS_K8S_TESTER_EKS_ADD_ON_MANAGED_NODE_GROUPS_MNGS")
+	os.Setenv("AWS_K8S_TESTER_EKS_ADD_ON_MANAGED_NODE_GROUPS_ROLE_CREATE", `true`)
+	defer os.Unsetenv("AWS_K8S_TESTER_EKS_ADD_ON_MANAGED_NODE_GROUPS_ROLE_CREATE")
+	if err := cfg.UpdateFromEnvs(); err != nil {
+		t.Fatal(err)
+	}
+	if err := cfg.ValidateAndSetDefaults(); err != nil {
+		t.Fatal(err)
+	}
+
+	if !cfg.HasWindowsNodeGroup() {
+		t.Fatal("expected Windows node group")
+	}
+	expectedSSM := fmt.Sprintf(windowsImageIDSSMParameter, cfg.Version)
+	if v := cfg.AddOnNodeGroups.ASGs["ng-windows"].ImageIDSSMParameter; v != expectedSSM {
+		t.Fatalf("expected ImageIDSSMParameter %q, got %q", expectedSSM, v)
+	}
+	if cfg.AddOnWindowsSmoke.Namespace != cfg.Name+"-windows-smoke" {
+		t.Fatalf("unexpected AddOnWindowsSmoke.Namespace %q", cfg.AddOnWindowsSmoke.Namespace)
+	}
+	if cfg.AddOnWindowsSmoke.Image != DefaultWindowsSmokeImage {
+		t.Fatalf("unexpected AddOnWindowsSmoke.Image %q", cfg.AddOnWindowsSmoke.Image)
+	}
+}
+
+func TestEnvAddOnNodeGroupsBottlerocket(t *testing.T) {
+	cfg := NewDefault()
+	defer func() {
+		os.RemoveAll(cfg.ConfigPath)
+		os.RemoveAll(cfg.KubectlCommandsOutputPath)
+		os.RemoveAll(cfg.RemoteAccessCommandsOutputPath)
+	}()
+
+	os.Setenv("AWS_K8S_TESTER_EKS_ADD_ON_NODE_GROUPS_ENABLE", `true`)
+	defer os.Unsetenv("AWS_K8S_TESTER_EKS_ADD_ON_NODE_GROUPS_ENABLE")
+	os.Setenv("AWS_K8S_TESTER_EKS_ADD_ON_NODE_GROUPS_ASGS", `{"ng-rocket-arm":{"name":"ng-rocket-arm","ami-type":"BOTTLEROCKET_ARM_64","asg-min-size":1,"asg-max-size":1,"asg-desired-capacity":1}}`)
+	defer os.Unsetenv("AWS_K8S_TESTER_EKS_ADD_ON_NODE_GROUPS_ASGS")
+	os.Setenv("AWS_K8S_TESTER_EKS_ADD_ON_MANAGED_NODE_GROUPS_ENABLE", `true`)
+	defer os.Unsetenv("AWS_K8S_TESTER_EKS_ADD_ON_MANAGED_NODE_GROUPS_ENABLE")
+	os.Setenv("AWS_K8S_TESTER_EKS_ADD_ON_MANAGED_NODE_GROUPS_MNGS", `{"mng-rocket":{"name":"mng-rocket","ami-type":"BOTTLEROCKET_x86_64","asg-min-size":1,"asg-max-size":1,"asg-desired-capacity":1}}`)
+	defer os.Unsetenv("AWS_K8S_TESTER_EKS_ADD_ON_MANAGED_NODE_GROUPS_MNGS")
+
+	if err := cfg.UpdateFromEnvs(); err != nil {
+		t.Fatal(err)
+	}
+	if err := cfg.ValidateAndSetDefaults(); err != nil {
+		t.Fatal(err)
+	}
+
+	ng := cfg.AddOnNodeGroups.ASGs["ng-rocket-arm"]
+	expectedSSM := fmt.Sprintf(bottlerocketImageIDSSMParameter, cfg.Version, "arm64")
+	if ng.ImageIDSSMParameter != expectedSSM {
+		t.Fatalf("expected ImageIDSSMParameter %q, got %q", expectedSSM, ng.ImageIDSSMParameter)
+	}
+	if ng.InstanceType != DefaultNodeInstanceTypeARMCPU {
+		t.Fatalf("unexpected InstanceType %q", ng.InstanceType)
+	}
+	mng := cfg.AddOnManagedNodeGroups.MNGs["mng-rocket"]
+	if !reflect.DeepEqual(mng.InstanceTypes, []string{DefaultNodeInstanceTypeCPU}) {
+		t.Fatalf("unexpected InstanceTypes %q", mng.InstanceTypes)
+	}
+
+	ng.KubeletExtraArgs = "--hello"
+	cfg.AddOnNodeGroups.ASGs["ng-rocket-arm"] = ng
+	err := cfg.ValidateAndSetDefaults()
+	if err == nil || !strings.Contains(err.Error(), "unexpected KubeletExtraArgs") {
+		t.Fatalf("expected KubeletExtraArgs error, got %v", err)
+	}
+}
+
+func TestEnvAddOnIPv6(t *testing.T) {
+	cfg := NewDefault()
+	defer func() {
+		os.RemoveAll(cfg.ConfigPath)
+		os.RemoveAll(cfg.KubectlCommandsOutputPath)
+		os.RemoveAll(cfg.RemoteAccessCommandsOutputPath)
+	}()
+
+	os.Setenv("AWS_K8S_TESTER_EKS_ADD_ON_MANAGED_NODE_GROUPS_ENABLE", `true`)
+	defer os.Unsetenv("AWS_K8S_TESTER_EKS_ADD_ON_MANAGED_NODE_GROUPS_ENABLE")
+	os.Setenv("AWS_K8S_TESTER_EKS_ADD_ON_IPV6_ENABLE", "true")
+	defer os.Unsetenv("AWS_K8S_TESTER_EKS_ADD_ON_IPV6_ENABLE")
+	os.Setenv("AWS_K8S_TESTER_EKS_ADD_ON_IPV6_DEPLOYMENT_REPLICAS", "5")
+	defer os.Unsetenv("AWS_K8S_TESTER_EKS_ADD_ON_IPV6_DEPLOYMENT_REPLICAS")
+
+	if err := cfg.UpdateFromEnvs(); err != nil {
+		t.Fatal(err)
+	}
+	err := cfg.ValidateAndSetDefaults()
+	if err == nil || !strings.Contains(err.Error(), "AddOnIPv6.Enable true but IPFamily") {
+		t.Fatalf("expected IPFamily error, got %v", err)
+	}
+
+	os.Setenv("AWS_K8S_TESTER_EKS_IP_FAMILY", "ipv6")
+	defer os.Unsetenv("AWS_K8S_TESTER_EKS_IP_FAMILY")
+	os.Setenv("AWS_K8S_TESTER_EKS_VERSION", "1.20")
+	defer os.Unsetenv("AWS_K8S_TESTER_EKS_VERSION")
+	if err := cfg.UpdateFromEnvs(); err != nil {
+		t.Fatal(err)
+	}
+	err = cfg.ValidateAndSetDefaults()
+	if err == nil || !strings.Contains(err.Error(), "requires Version >= 1.21") {
+		t.Fatalf("expected Version error, got %v", err)
+	}
+
+	os.Setenv("AWS_K8S_TESTER_EKS_VERSION", "1.27")
+	if err := cfg.UpdateFromEnvs(); err != nil {
+		t.Fatal(err)
+	}
+	if err := cfg.ValidateAndSetDefaults(); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.IPFamily != IPFamilyIPv6 {
+		t.Fatalf("unexpected IPFamily %q", cfg.IPFamily)
+	}
+	if cfg.AddOnIPv6.Namespace != cfg.Name+"-ipv6" {
+		t.Fatalf("unexpected AddOnIPv6.Namespace %q", cfg.AddOnIPv6.Namespace)
+	}
+	if cfg.AddOnIPv6.DeploymentReplicas != 5 {
+		t.Fatalf("unexpected AddOnIPv6.DeploymentReplicas %d", cfg.AddOnIPv6.DeploymentReplicas)
+	}
+	if v := cfg.AddOnIPv6.ServiceAnnotations["service.beta.kubernetes.io/aws-load-balancer-ip-address-type"]; v != "dualstack" {
+		t.Fatalf("unexpected ip-address-type annotation %q", v)
+	}
+}
+
+func TestEnvVPCExisting(t *testing.T) {
+	cfg := NewDefault()
+	defer func() {
+		os.RemoveAll(cfg.ConfigPath)
+		os.RemoveAll(cfg.KubectlCommandsOutputPath)
+		os.RemoveAll(cfg.RemoteAccessCommandsOutputPath)
+	}()
+
+	os.Setenv("AWS_K8S_TESTER_EKS_VPC_CREATE", "false")
+	defer os.Unsetenv("AWS_K8S_TESTER_EKS_VPC_CREATE")
+	os.Setenv("AWS_K8S_TESTER_EKS_VPC_ID", "vpc-0123")
+	defer os.Unsetenv("AWS_K8S_TESTER_EKS_VPC_ID")
+	os.Setenv("AWS_K8S_TESTER_EKS_VPC_PRIVATE_SUBNET_IDS", "subnet-a,subnet-b")
+	defer os.Unsetenv("AWS_K8S_TESTER_EKS_VPC_PRIVATE_SUBNET_IDS")
+
+	if err := cfg.UpdateFromEnvs(); err != nil {
+		t.Fatal(err)
+	}
+	err := cfg.ValidateAndSetDefaults()
+	if err == nil || !strings.Contains(err.Error(), "requires non-empty VPC.PublicSubnetIDs") {
+		t.Fatalf("expected VPC.PublicSubnetIDs error, got %v", err)
+	}
+
+	os.Setenv("AWS_K8S_TESTER_EKS_VPC_PUBLIC_SUBNET_IDS", "subnet-a")
+	defer os.Unsetenv("AWS_K8S_TESTER_EKS_VPC_PUBLIC_SUBNET_IDS")
+	if err := cfg.UpdateFromEnvs(); err != nil {
+		t.Fatal(err)
+	}
+	err = cfg.ValidateAndSetDefaults()
+	if err == nil || !strings.Contains(err.Error(), `duplicate subnet ID "subnet-a"`) {
+		t.Fatalf("expected duplicate subnet error, got %v", err)
+	}
+
+	os.Setenv("AWS_K8S_TESTER_EKS_VPC_PUBLIC_SUBNET_IDS", "subnet-c")
+	os.Setenv("AWS_K8S_TESTER_EKS_VPC_SECURITY_GROUP_ID", "invalid")
+	defer os.Unsetenv("AWS_K8S_TESTER_EKS_VPC_SECURITY_GROUP_ID")
+	if err := cfg.UpdateFromEnvs(); err != nil {
+		t.Fatal(err)
+	}
+	err = cfg.ValidateAndSetDefaults()
+	if err == nil || !strings.Contains(err.Error(), `invalid VPC.SecurityGroupID "invalid"`) {
+		t.Fatalf("expected security group error, got %v", err)
+	}
+
+	os.Setenv("AWS_K8S_TESTER_EKS_VPC_SECURITY_GROUP_ID", "sg-0123")
+	os.Setenv("AWS_K8S_TESTER_EKS_VPC_ADDITIONAL_SECURITY_GROUP_IDS", "sg-4567,sg-89ab")
+	defer os.Unsetenv("AWS_K8S_TESTER_EKS_VPC_ADDITIONAL_SECURITY_GROUP_IDS")
+	if err := cfg.UpdateFromEnvs(); err != nil {
+		t.Fatal(err)
+	}
+	if err := cfg.ValidateAndSetDefaults(); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(cfg.VPC.PublicSubnetIDs, []string{"subnet-c"}) {
+		t.Fatalf("unexpected VPC.PublicSubnetIDs %v", cfg.VPC.PublicSubnetIDs)
+	}
+	if !reflect.DeepEqual(cfg.VPC.PrivateSubnetIDs, []string{"subnet-a", "subnet-b"}) {
+		t.Fatalf("unexpected VPC.PrivateSubnetIDs %v", cfg.VPC.PrivateSubnetIDs)
+	}
+	if !reflect.DeepEqual(cfg.VPC.AdditionalSecurityGroupIDs, []string{"sg-4567", "sg-89ab"}) {
+		t.Fatalf("unexpected VPC.AdditionalSecurityGroupIDs %v", cfg.VPC.AdditionalSecurityGroupIDs)
+	}
+}
+
+func TestEnvEndpointPrivateOnly(t *testing.T) {
+	defaultCfg := NewDefault()
+	defer func() {
+		os.RemoveAll(defaultCfg.ConfigPath)
+		os.RemoveAll(defaultCfg.KubectlCommandsOutputPath)
+		os.RemoveAll(defaultCfg.RemoteAccessCommandsOutputPath)
+	}()
+
+	if err := defaultCfg.ValidateAndSetDefaults(); err != nil {
+		t.Fatal(err)
+	}
+	if defaultCfg.IsPrivateEndpointOnly() {
+		t.Fatal("unexpected private-only endpoint by default")
+	}
+	if defaultCfg.Bastion != nil {
+		t.Fatalf("unexpected Bastion %+v for public endpoint", defaultCfg.Bastion)
+	}
+
+	cfg := NewDefault()
+	defer func() {
+		os.RemoveAll(cfg.ConfigPath)
+		os.RemoveAll(cfg.KubectlCommandsOutputPath)
+		os.RemoveAll(cfg.RemoteAccessCommandsOutputPath)
+	}()
+	os.Setenv("AWS_K8S_TESTER_EKS_ENDPOINT_PUBLIC_ACCESS", "false")
+	defer os.Unsetenv("AWS_K8S_TESTER_EKS_ENDPOINT_PUBLIC_ACCESS")
+	os.Setenv("AWS_K8S_TESTER_EKS_ENDPOINT_PRIVATE_ACCESS", "true")
+	defer os.Unsetenv("AWS_K8S_TESTER_EKS_ENDPOINT_PRIVATE_ACCESS")
+	os.Setenv("AWS_K8S_TESTER_EKS_BASTION_LOCAL_PROXY_PORT", "2080")
+	defer os.Unsetenv("AWS_K8S_TESTER_EKS_BASTION_LOCAL_PROXY_PORT")
+
+	if err := cfg.UpdateFromEnvs(); err != nil {
+		t.Fatal(err)
+	}
+	if err := cfg.ValidateAndSetDefaults(); err != nil {
+		t.Fatal(err)
+	}
+	if !cfg.IsPrivateEndpointOnly() {
+		t.Fatal("expected private-only endpoint")
+	}
+	if cfg.Bastion == nil {
+		t.Fatal("expected Bastion for private-only endpoint")
+	}
+	if cfg.Bastion.InstanceType != DefaultBastionInstanceType {
+		t.Fatalf("unexpected Bastion.InstanceType %q", cfg.Bastion.InstanceType)
+	}
+	if cfg.Bastion.ImageIDSSMParameter != DefaultBastionImageIDSSMParameter {
+		t.Fatalf("unexpected Bastion.ImageIDSSMParameter %q", cfg.Bastion.ImageIDSSMParameter)
+	}
+	if cfg.Bastion.LocalProxyPort != 2080 {
+		t.Fatalf("unexpected Bastion.LocalProxyPort %d", cfg.Bastion.LocalProxyPort)
+	}
+}
+
+func TestEnvEncryptionExistingCMK(t *testing.T) {
+	cfg := NewDefault()
+	defer func() {
+		os.RemoveAll(cfg.ConfigPath)
+		os.RemoveAll(cfg.KubectlCommandsOutputPath)
+		os.RemoveAll(cfg.RemoteAccessCommandsOutputPath)
+	}()
+
+	os.Setenv("AWS_K8S_TESTER_EKS_ENCRYPTION_CMK_CREATE", "false")
+	defer os.Unsetenv("AWS_K8S_TESTER_EKS_ENCRYPTION_CMK_CREATE")
+	os.Setenv("AWS_K8S_TESTER_EKS_ENCRYPTION_CMK_ARN", "arn:aws:kms:us-west-2:123:key/330e3b1a-61c4-4be6-93e0-244180c9f169")
+	defer os.Unsetenv("AWS_K8S_TESTER_EKS_ENCRYPTION_CMK_ARN")
+
+	if err := cfg.UpdateFromEnvs(); err != nil {
+		t.Fatal(err)
+	}
+	if err := cfg.ValidateAndSetDefaults(); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Encryption.CMKCreate {
+		t.Fatal("unexpected Encryption.CMKCreate true")
+	}
+	if cfg.Encryption.CMKARN != "arn:aws:kms:us-west-2:123:key/330e3b1a-61c4-4be6-93e0-244180c9f169" {
+		t.Fatalf("unexpected Encryption.CMKARN %q", cfg.Encryption.CMKARN)
+	}
+}
+
+func TestEnvTagsMerge(t *testing.T) {
+	cfg := NewDefault()
+	defer func() {
+		os.RemoveAll(cfg.ConfigPath)
+		os.RemoveAll(cfg.KubectlCommandsOutputPath)
+		os.RemoveAll(cfg.RemoteAccessCommandsOutputPath)
+	}()
+
+	os.Setenv("AWS_K8S_TESTER_EKS_TAGS", `{"cost-center":"1234","Name":"from-config"}`)
+	defer os.Unsetenv("AWS_K8S_TESTER_EKS_TAGS")
+
+	if err := cfg.UpdateFromEnvs(); err != nil {
+		t.Fatal(err)
+	}
+	if err := cfg.ValidateAndSetDefaults(); err != nil {
+		t.Fatal(err)
+	}
+	merged := cfg.MergeTags(map[string]string{"Name": "from-tester", "Kind": "aws-k8s-tester"})
+	expected := map[string]string{"cost-center": "1234", "Name": "from-tester", "Kind": "aws-k8s-tester"}
+	if !reflect.DeepEqual(merged, expected) {
+		t.Fatalf("expected merged tags %v, got %v", expected, merged)
+	}
+	if cfg.Tags["Name"] != "from-config" {
+		t.Fatalf("unexpected Tags mutation %v", cfg.Tags)
+	}
+}
+
+func TestEnvIAMPermissionsBoundary(t *testing.T) {
+	cfg := NewDefault()
+	defer func() {
+		os.RemoveAll(cfg.ConfigPath)
+		os.RemoveAll(cfg.KubectlCommandsOutputPath)
+		os.RemoveAll(cfg.RemoteAccessCommandsOutputPath)
+	}()
+
+	if cfg.IAMPath != "/" {
+		t.Fatalf("unexpected default IAMPath %q", cfg.IAMPath)
+	}
+
+	os.Setenv("AWS_K8S_TESTER_EKS_IAM_PERMISSIONS_BOUNDARY_ARN", "arn:aws:iam::123:policy/eks-boundary")
+	defer os.Unsetenv("AWS_K8S_TESTER_EKS_IAM_PERMISSIONS_BOUNDARY_ARN")
+	os.Setenv("AWS_K8S_TESTER_EKS_IAM_PATH", "/eks-testing/")
+	defer os.Unsetenv("AWS_K8S_TESTER_EKS_IAM_PATH")
+
+	if err := cfg.UpdateFromEnvs(); err != nil {
+		t.Fatal(err)
+	}
+	if err := cfg.ValidateAndSetDefaults(); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.IAMPermissionsBoundaryARN != "arn:aws:iam::123:policy/eks-boundary" {
+		t.Fatalf("unexpected IAMPermissionsBoundaryARN %q", cfg.IAMPermissionsBoundaryARN)
+	}
+	if cfg.IAMPath != "/eks-testing/" {
+		t.Fatalf("unexpected IAMPath %q", cfg.IAMPath)
+	}
+
+	cfg.IAMPath = "eks-testing"
+	if err := cfg.ValidateAndSetDefaults(); err == nil {
+		t.Fatal("expected error for IAMPath without '/'")
+	}
+	cfg.IAMPath = ""
+	if err := cfg.ValidateAndSetDefaults(); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.IAMPath != "/" {
+		t.Fatalf("unexpected IAMPath %q", cfg.IAMPath)
+	}
+	cfg.IAMPermissionsBoundaryARN = "arn:aws:iam::123:role/eks-boundary"
+	if err := cfg.ValidateAndSetDefaults(); err == nil {
+		t.Fatal("expected error for non-policy IAMPermissionsBoundaryARN")
+	}
+}
+
+func TestEnvAddOns(t *testing.T) {
+	cfg := NewDefault()
+	defer func() {
+		os.RemoveAll(cfg.ConfigPath)
+		os.RemoveAll(cfg.KubectlCommandsOutputPath)
+		os.RemoveAll(cfg.RemoteAccessCommandsOutputPath)
+	}()
+
+	// every add-on field must be enabled by name
+	tp := reflect.TypeOf(cfg).Elem()
+	for i := 0; i < tp.NumField(); i++ {
+		jv := strings.Replace(tp.Field(i).Tag.Get("json"), ",omitempty", "", -1)
+		if !strings.HasPrefix(jv, "add-on-") {
+			continue
+		}
+		if _, ok := addOnDefaults[strings.TrimPrefix(jv, "add-on-")]; !ok {
+			t.Fatalf("add-on field %q not found in addOnDefaults", tp.Field(i).Name)
+		}
+	}
+
+	os.Setenv("AWS_K8S_TESTER_EKS_ADD_ONS", "nlb-hello-world, jobs-pi,unknown")
+	defer os.Unsetenv("AWS_K8S_TESTER_EKS_ADD_ONS")
+	err := cfg.UpdateFromEnvs()
+	if err == nil || !strings.Contains(err.Error(), `unknown add-on "unknown"`) {
+		t.Fatalf("expected unknown add-on error, got %v", err)
+	}
+
+	cfg.AddOnNLBHelloWorld = nil
+	cfg.AddOnStresserLocal = nil
+	os.Setenv("AWS_K8S_TESTER_EKS_ADD_ONS", "nlb-hello-world, jobs-pi,stresser")
+	os.Setenv("AWS_K8S_TESTER_EKS_ADD_ON_JOBS_PI_COMPLETES", "100")
+	defer os.Unsetenv("AWS_K8S_TESTER_EKS_ADD_ON_JOBS_PI_COMPLETES")
+	if err := cfg.UpdateFromEnvsStrict(); err != nil {
+		t.Fatal(err)
+	}
+	if !cfg.AddOnNLBHelloWorld.Enable {
+		t.Fatal("expected AddOnNLBHelloWorld.Enable")
+	}
+	if cfg.AddOnNLBHelloWorld.DeploymentReplicas != 3 {
+		t.Fatalf("unexpected AddOnNLBHelloWorld.DeploymentReplicas %d", cfg.AddOnNLBHelloWorld.DeploymentReplicas)
+	}
+	if !cfg.AddOnJobsPi.Enable {
+		t.Fatal("expected AddOnJobsPi.Enable")
+	}
+	if cfg.AddOnJobsPi.Completes != 100 {
+		t.Fatalf("unexpected AddOnJobsPi.Completes %d", cfg.AddOnJobsPi.Completes)
+	}
+	if !cfg.AddOnStresserLocal.Enable {
+		t.Fatal("expected AddOnStresserLocal.Enable")
+	}
+	if cfg.AddOnNLBGuestbook.Enable {
+		t.Fatal("unexpected AddOnNLBGuestbook.Enable")
+	}
+}
+
+func TestMigrate(t *testing.T) {
+	old := `
+name: test-cluster
+region: us-west-2
+log-color-override: false
+s3-bucket-name: my-bucket
+s3-bucket-create: false
+parameters:
+  version: "1.16"
+  tags:
+    hello: world
+  role-create: false
+  role-arn: arn:aws:iam::123:role/test-role
+  vpc-create: false
+  vpc-id: vpc-0123
+  vpc-cidr: 192.168.0.0/16
+  public-subnet-ids: [subnet-a, subnet-b]
+  encryption-cmk-create: false
+  encryption-cmk-arn: arn:aws:kms:us-west-2:123:key/0123
+  vpc-cfn-stack-id: unsupported
+add-on-config-maps-local:
+  enable: true
+  objects: 10
+removed-field: true
+`
+	cfg, err := Migrate([]byte(old))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Name != "test-cluster" || cfg.Region != "us-west-2" {
+		t.Fatalf("unexpected name/region %q/%q", cfg.Name, cfg.Region)
+	}
+	if cfg.LogColorOverride != "" {
+		t.Fatalf("unexpected LogColorOverride %q", cfg.LogColorOverride)
+	}
+	if cfg.S3 == nil || cfg.S3.BucketName != "my-bucket" || cfg.S3.BucketCreate {
+		t.Fatalf("unexpected S3 %+v", cfg.S3)
+	}
+	if cfg.Version != "1.16" {
+		t.Fatalf("unexpected Version %q", cfg.Version)
+	}
+	if !reflect.DeepEqual(cfg.Tags, map[string]string{"hello": "world"}) {
+		t.Fatalf("unexpected Tags %v", cfg.Tags)
+	}
+	if cfg.Role == nil || cfg.Role.Create || cfg.Role.ARN != "arn:aws:iam::123:role/test-role" {
+		t.Fatalf("unexpected Role %+v", cfg.Role)
+	}
+	if cfg.VPC == nil || cfg.VPC.Create || cfg.VPC.ID != "vpc-0123" {
+		t.Fatalf("unexpected VPC %+v", cfg.VPC)
+	}
+	if !reflect.DeepEqual(cfg.VPC.CIDRs, []string{"192.168.0.0/16"}) {
+		t.Fatalf("unexpected VPC.CIDRs %v", cfg.VPC.CIDRs)
+	}
+	if !reflect.DeepEqual(cfg.VPC.PublicSubnetIDs, []string{"subnet-a", "subnet-b"}) {
+		t.Fatalf("unexpected VPC.PublicSubnetIDs %v", cfg.VPC.PublicSubnetIDs)
+	}
+	if cfg.Encryption == nil || cfg.Encryption.CMKCreate || cfg.Encryption.CMKARN != "arn:aws:kms:us-west-2:123:key/0123" {
+		t.Fatalf("unexpected Encryption %+v", cfg.Encryption)
+	}
+	if cfg.AddOnConfigmapsLocal == nil || !cfg.AddOnConfigmapsLocal.Enable || cfg.AddOnConfigmapsLocal.Objects != 10 {
+		t.Fatalf("unexpected AddOnConfigmapsLocal %+v", cfg.AddOnConfigmapsLocal)
+	}
+
+	// current layout is loaded as it is
+	cur := NewDefault()
+	defer func() {
+		os.RemoveAll(cur.ConfigPath)
+		os.RemoveAll(cur.KubectlCommandsOutputPath)
+		os.RemoveAll(cur.RemoteAccessCommandsOutputPath)
+	}()
+	d, err := yaml.Marshal(cur)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg, err = Migrate(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Name != cur.Name || !reflect.DeepEqual(cfg.VPC, cur.VPC) {
+		t.Fatalf("unexpected migrated config %+v", cfg)
+	}
+}
+
+func TestEnvLiveReload(t *testing.T) {
+	cfg := NewDefault()
+	defer func() {
+		os.RemoveAll(cfg.ConfigPath)
+		os.RemoveAll(cfg.KubectlCommandsOutputPath)
+		os.RemoveAll(cfg.RemoteAccessCommandsOutputPath)
+	}()
+
+	os.Setenv("AWS_K8S_TESTER_EKS_LIVE_RELOAD_ENABLE", "true")
+	defer os.Unsetenv("AWS_K8S_TESTER_EKS_LIVE_RELOAD_ENABLE")
+	os.Setenv("AWS_K8S_TESTER_EKS_LIVE_RELOAD_INTERVAL", "30s")
+	defer os.Unsetenv("AWS_K8S_TESTER_EKS_LIVE_RELOAD_INTERVAL")
+	os.Setenv("AWS_K8S_TESTER_EKS_ADD_ON_STRESSER_LOCAL_ENABLE", "true")
+	defer os.Unsetenv("AWS_K8S_TESTER_EKS_ADD_ON_STRESSER_LOCAL_ENABLE")
+	os.Setenv("AWS_K8S_TESTER_EKS_ADD_ON_STRESSER_LOCAL_QPS", "10.5")
+	defer os.Unsetenv("AWS_K8S_TESTER_EKS_ADD_ON_STRESSER_LOCAL_QPS")
+
+	if err := cfg.UpdateFromEnvs(); err != nil {
+		t.Fatal(err)
+	}
+	if err := cfg.ValidateAndSetDefaults(); err != nil {
+		t.Fatal(err)
+	}
+	if !cfg.IsEnabledLiveReload() {
+		t.Fatal("expected LiveReload enabled")
+	}
+	if cfg.LiveReload.Interval != 30*time.Second {
+		t.Fatalf("unexpected LiveReload.Interval %v", cfg.LiveReload.Interval)
+	}
+	if cfg.LiveReload.Path == "" || cfg.LiveReload.Path == cfg.ConfigPath {
+		t.Fatalf("unexpected LiveReload.Path %q", cfg.LiveReload.Path)
+	}
+	if cfg.AddOnStresserLocal.QPS != 10.5 {
+		t.Fatalf("unexpected AddOnStresserLocal.QPS %v", cfg.AddOnStresserLocal.QPS)
+	}
+
+	changes, err := cfg.ApplyLiveReload([]byte(`
+add-on-stresser-local:
+  qps: 100
+  object-size: 8192
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 2 {
+		t.Fatalf("unexpected changes %v", changes)
+	}
+	qps, objectSize, listLimit := cfg.GetAddOnStresserLocalLoad()
+	if qps != 100 || objectSize != 8192 || listLimit != 0 {
+		t.Fatalf("unexpected load %v, %d, %d", qps, objectSize, listLimit)
+	}
+
+	// fields other than the reloadable ones are rejected
+	if _, err = cfg.ApplyLiveReload([]byte(`
+add-on-stresser-local:
+  qps: 200
+  namespace: hello
+`)); err == nil {
+		t.Fatal("expected error for non-reloadable field")
+	}
+	if _, err = cfg.ApplyLiveReload([]byte("region: us-east-1\n")); err == nil {
+		t.Fatal("expected error for non-reloadable field")
+	}
+	if _, err = cfg.ApplyLiveReload([]byte("add-on-stresser-local:\n  qps: -1\n")); err == nil {
+		t.Fatal("expected error for negative qps")
+	}
+	if cfg.AddOnStresserLocal.QPS != 100 {
+		t.Fatalf("unexpected AddOnStresserLocal.QPS %v", cfg.AddOnStresserLocal.QPS)
+	}
+}
+
+func TestEffective(t *testing.T) {
+	cfg := NewDefault()
+	cfg.ConfigPath = filepath.Join(os.TempDir(), cfg.Name+".yaml")
+	defer func() {
+		os.RemoveAll(cfg.ConfigPath)
+		os.RemoveAll(cfg.KubectlCommandsOutputPath)
+		os.RemoveAll(cfg.RemoteAccessCommandsOutputPath)
+	}()
+
+	os.Setenv("AWS_K8S_TESTER_EKS_ADD_ON_STRESSER_LOCAL_ENABLE", "true")
+	defer os.Unsetenv("AWS_K8S_TESTER_EKS_ADD_ON_STRESSER_LOCAL_ENABLE")
+	if err := cfg.UpdateFromEnvs(); err != nil {
+		t.Fatal(err)
+	}
+	// "UpdateFromEnvs" syncs the config file
+	os.RemoveAll(cfg.ConfigPath)
+
+	d, err := cfg.Effective("yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.AddOnStresserLocal.Namespace != "" {
+		t.Fatalf("unexpected receiver mutation %q", cfg.AddOnStresserLocal.Namespace)
+	}
+	if fileutil.Exist(cfg.ConfigPath) {
+		t.Fatalf("unexpected config file %q", cfg.ConfigPath)
+	}
+	ec := new(Config)
+	if err = yaml.Unmarshal(d, ec, yaml.DisallowUnknownFields); err != nil {
+		t.Fatal(err)
+	}
+	if ec.AddOnStresserLocal.Namespace != cfg.Name+"-stresser-local" {
+		t.Fatalf("unexpected AddOnStresserLocal.Namespace %q", ec.AddOnStresserLocal.Namespace)
+	}
+	if ec.AddOnStresserLocal.DurationString == "" {
+		t.Fatal("expected computed AddOnStresserLocal.DurationString")
+	}
+
+	d, err = cfg.Effective("json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ec = new(Config)
+	if err = json.Unmarshal(d, ec); err != nil {
+		t.Fatal(err)
+	}
+	if ec.Name != cfg.Name || ec.AddOnStresserLocal == nil {
+		t.Fatalf("unexpected effective config %+v", ec)
+	}
+
+	if _, err = cfg.Effective("toml"); err == nil {
+		t.Fatal("expected error for unknown format")
+	}
+}
+
+func TestQuotaRequirements(t *testing.T) {
+	cfg := &Config{
+		AvailabilityZoneNames: []string{"us-west-2a", "us-west-2b", "us-west-2c"},
+		VPC:                   &VPC{Create: true},
+		Status:                &Status{},
+		AddOnNodeGroups: &AddOnNodeGroups{
+			Enable: true,
+			ASGs: map[string]ASG{
+				"ng1": {ASG: ec2config.ASG{InstanceType: "c5.xlarge", ASGMinSize: 3, ASGDesiredCapacity: 2}},
+				"ng2": {ASG: ec2config.ASG{InstanceType: "c5.xlarge", ASGMinSize: 1, ASGDesiredCapacity: 4}},
+			},
+		},
+		AddOnManagedNodeGroups: &AddOnManagedNodeGroups{
+			Enable: true,
+			MNGs: map[string]MNG{
+				"mng1": {InstanceTypes: []string{"m5.large", "m5a.large"}, ASGMinSize: 2, ASGDesiredCapacity: 5, CapacityType: "SPOT"},
+				"mng2": {InstanceTypes: []string{"m5.large"}, ASGMinSize: 1, ASGDesiredCapacity: 1},
+			},
+		},
+	}
+	req := cfg.QuotaRequirements()
+	if req.Clusters != 1 || req.EIPs != 3 || req.NATGatewaysPerAZ != 1 {
+		t.Fatalf("unexpected requirements %+v", req)
+	}
+	if !reflect.DeepEqual(req.OnDemandInstances, map[string]int{"c5.xlarge": 7, "m5.large": 1}) {
+		t.Fatalf("unexpected OnDemandInstances %v", req.OnDemandInstances)
+	}
+	if !reflect.DeepEqual(req.SpotInstances, map[string]int{"m5.large": 5}) {
+		t.Fatalf("unexpected SpotInstances %v", req.SpotInstances)
+	}
+
+	// already created
+	cfg.Status.ClusterARN = "arn:aws:eks:us-west-2:123:cluster/test"
+	cfg.VPC.ID = "vpc-123"
+	cfg.AddOnNodeGroups.Created = true
+	cfg.AddOnManagedNodeGroups.Created = true
+	req = cfg.QuotaRequirements()
+	if req.Clusters != 0 || req.EIPs != 0 || len(req.OnDemandInstances) != 0 || len(req.SpotInstances) != 0 {
+		t.Fatalf("unexpected requirements %+v", req)
+	}
+}
+
+func TestResolveSecrets(t *testing.T) {
+	cfg := NewDefault()
+	defer func() {
+		os.RemoveAll(cfg.ConfigPath)
+		os.RemoveAll(cfg.KubectlCommandsOutputPath)
+		os.RemoveAll(cfg.RemoteAccessCommandsOutputPath)
+	}()
+
+	os.Setenv("AWS_K8S_TESTER_EKS_REMOTE_ACCESS_KEY_CREATE", "false")
+	defer os.Unsetenv("AWS_K8S_TESTER_EKS_REMOTE_ACCESS_KEY_CREATE")
+	os.Setenv("AWS_K8S_TESTER_EKS_REMOTE_ACCESS_KEY_NAME", "my-key")
+	defer os.Unsetenv("AWS_K8S_TESTER_EKS_REMOTE_ACCESS_KEY_NAME")
+	os.Setenv("AWS_K8S_TESTER_EKS_REMOTE_ACCESS_PRIVATE_KEY_PATH", "ssm:///test/private-key")
+	defer os.Unsetenv("AWS_K8S_TESTER_EKS_REMOTE_ACCESS_PRIVATE_KEY_PATH")
+	os.Setenv("AWS_K8S_TESTER_EKS_ADD_ON_MANAGED_NODE_GROUPS_ENABLE", "true")
+	defer os.Unsetenv("AWS_K8S_TESTER_EKS_ADD_ON_MANAGED_NODE_GROUPS_ENABLE")
+	os.Setenv("AWS_K8S_TESTER_EKS_ADD_ON_CSI_EBS_ENABLE", "true")
+	defer os.Unsetenv("AWS_K8S_TESTER_EKS_ADD_ON_CSI_EBS_ENABLE")
+	os.Setenv("AWS_K8S_TESTER_EKS_ADD_ON_WORDPRESS_ENABLE", "true")
+	defer os.Unsetenv("AWS_K8S_TESTER_EKS_ADD_ON_WORDPRESS_ENABLE")
+	os.Setenv("AWS_K8S_TESTER_EKS_ADD_ON_WORDPRESS_PASSWORD", "secretsmanager://test/wordpress#password")
+	defer os.Unsetenv("AWS_K8S_TESTER_EKS_ADD_ON_WORDPRESS_PASSWORD")
+
+	if err := cfg.UpdateFromEnvs(); err != nil {
+		t.Fatal(err)
+	}
+	if !cfg.HasSecretURIs() {
+		t.Fatal("expected secret URIs")
+	}
+	secrets := map[string]string{
+		"ssm:///test/private-key":                  "test-private-key",
+		"secretsmanager://test/wordpress#password": "test-password",
+	}
+	if err := cfg.ResolveSecrets(func(uri string) (string, error) {
+		v, ok := secrets[uri]
+		if !ok {
+			return "", fmt.Errorf("unknown secret %q", uri)
+		}
+		return v, nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(cfg.RemoteAccessPrivateKeyPath)
+	if cfg.HasSecretURIs() {
+		t.Fatal("unexpected unresolved secret URIs")
+	}
+	if cfg.AddOnWordpress.Password != "test-password" {
+		t.Fatalf("unexpected AddOnWordpress.Password %q", cfg.AddOnWordpress.Password)
+	}
+	d, err := ioutil.ReadFile(cfg.RemoteAccessPrivateKeyPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(d) != "test-private-key" {
+		t.Fatalf("unexpected private key %q", string(d))
+	}
+
+	if err = cfg.ValidateAndSetDefaults(); err != nil {
+		t.Fatal(err)
+	}
+	d, err = ioutil.ReadFile(cfg.ConfigPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(d, []byte("test-password")) || !bytes.Contains(d, []byte("secretsmanager://test/wordpress#password")) {
+		t.Fatalf("unexpected secrets in config file:\n%s", string(d))
+	}
+	if cfg.AddOnWordpress.Password != "test-password" {
+		t.Fatalf("unexpected AddOnWordpress.Password %q after sync", cfg.AddOnWordpress.Password)
+	}
+}
+
+func TestEnvAddOnCleanupPolicy(t *testing.T) {
+	cfg := NewDefault()
+	defer func() {
+		os.RemoveAll(cfg.ConfigPath)
+		os.RemoveAll(cfg.KubectlCommandsOutputPath)
+		os.RemoveAll(cfg.RemoteAccessCommandsOutputPath)
+	}()
+
+	os.Setenv("AWS_K8S_TESTER_EKS_ADD_ON_MANAGED_NODE_GROUPS_ENABLE", "true")
+	defer os.Unsetenv("AWS_K8S_TESTER_EKS_ADD_ON_MANAGED_NODE_GROUPS_ENABLE")
+	os.Setenv("AWS_K8S_TESTER_EKS_ADD_ON_CSI_EBS_ENABLE", "true")
+	defer os.Unsetenv("AWS_K8S_TESTER_EKS_ADD_ON_CSI_EBS_ENABLE")
+	os.Setenv("AWS_K8S_TESTER_EKS_ADD_ON_WORDPRESS_ENABLE", "true")
+	defer os.Unsetenv("AWS_K8S_TESTER_EKS_ADD_ON_WORDPRESS_ENABLE")
+	os.Setenv("AWS_K8S_TESTER_EKS_ADD_ON_WORDPRESS_CLEANUP_POLICY", "retain-on-failure")
+	defer os.Unsetenv("AWS_K8S_TESTER_EKS_ADD_ON_WORDPRESS_CLEANUP_POLICY")
+	os.Setenv("AWS_K8S_TESTER_EKS_ADD_ON_JOBS_PI_ENABLE", "true")
+	defer os.Unsetenv("AWS_K8S_TESTER_EKS_ADD_ON_JOBS_PI_ENABLE")
+
+	if err := cfg.UpdateFromEnvs(); err != nil {
+		t.Fatal(err)
+	}
+	if err := cfg.ValidateAndSetDefaults(); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.AddOnWordpress.CleanupPolicy != CleanupPolicyRetainOnFailure {
+		t.Fatalf("unexpected AddOnWordpress.CleanupPolicy %q", cfg.AddOnWordpress.CleanupPolicy)
+	}
+	if cfg.AddOnJobsPi.CleanupPolicy != CleanupPolicyDelete {
+		t.Fatalf("unexpected AddOnJobsPi.CleanupPolicy %q", cfg.AddOnJobsPi.CleanupPolicy)
+	}
+
+	// successful creation
+	cfg.AddOnJobsPi.Created = true
+	if failed := cfg.SetAddOnsCreateFailed(cfg.CreatedAddOns()); len(failed) != 0 {
+		t.Fatalf("unexpected failed add-ons %v", failed)
+	}
+
+	// failed creation
+	before := cfg.CreatedAddOns()
+	cfg.AddOnWordpress.Created = true
+	failed := cfg.SetAddOnsCreateFailed(before)
+	if !reflect.DeepEqual(failed, []string{"AddOnWordpress"}) || !cfg.AddOnWordpress.CreateFailed {
+		t.Fatalf("unexpected failed add-ons %v", failed)
+	}
+	if retained := cfg.RetainedAddOns(); !reflect.DeepEqual(retained, []string{"AddOnWordpress"}) {
+		t.Fatalf("unexpected retained add-ons %v", retained)
+	}
+	if !cfg.IsRetainedAddOn("add-on-wordpress") || cfg.IsRetainedAddOn("add-on-jobs-pi") {
+		t.Fatalf("unexpected retained add-ons %v", cfg.RetainedAddOns())
+	}
+
+	cfg.AddOnJobsPi.CleanupPolicy = "keep"
+	if err := cfg.ValidateAndSetDefaults(); err == nil {
+		t.Fatal("expected error for unknown cleanup policy")
+	}
+}
+
+func TestEnvAddOnAccessEntries(t *testing.T) {
+	cfg := NewDefault()
+	defer func() {
+		os.RemoveAll(cfg.ConfigPath)
+		os.RemoveAll(cfg.KubectlCommandsOutputPath)
+		os.RemoveAll(cfg.RemoteAccessCommandsOutputPath)
+	}()
+
+	os.Setenv("AWS_K8S_TESTER_EKS_ADD_ON_ACCESS_ENTRIES_ENABLE", "true")
+	defer os.Unsetenv("AWS_K8S_TESTER_EKS_ADD_ON_ACCESS_ENTRIES_ENABLE")
+
+	if err := cfg.UpdateFromEnvs(); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.AuthenticationMode != "CONFIG_MAP" {
+		t.Fatalf("unexpected AuthenticationMode %q", cfg.AuthenticationMode)
+	}
+	if err := cfg.ValidateAndSetDefaults(); err == nil {
+		t.Fatal("expected error for access entries with CONFIG_MAP authentication mode")
+	}
+
+	os.Setenv("AWS_K8S_TESTER_EKS_AUTHENTICATION_MODE", "API")
+	defer os.Unsetenv("AWS_K8S_TESTER_EKS_AUTHENTICATION_MODE")
+	os.Setenv("AWS_K8S_TESTER_EKS_ADD_ON_ACCESS_ENTRIES_ROLE_NAME", "hello-role")
+	defer os.Unsetenv("AWS_K8S_TESTER_EKS_ADD_ON_ACCESS_ENTRIES_ROLE_NAME")
+	os.Setenv("AWS_K8S_TESTER_EKS_ADD_ON_ACCESS_ENTRIES_EXTRA_PRINCIPALS", `{"arn:aws:iam::123456789012:role/admin":"arn:aws:eks::aws:cluster-access-policy/AmazonEKSAdminPolicy","arn:aws:iam::123456789012:user/viewer":""}`)
+	defer os.Unsetenv("AWS_K8S_TESTER_EKS_ADD_ON_ACCESS_ENTRIES_EXTRA_PRINCIPALS")
+
+	if err := cfg.UpdateFromEnvs(); err != nil {
+		t.Fatal(err)
+	}
+	if err := cfg.ValidateAndSetDefaults(); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.AuthenticationMode != "API" {
+		t.Fatalf("unexpected AuthenticationMode %q", cfg.AuthenticationMode)
+	}
+	if cfg.AddOnAccessEntries.RoleName != "hello-role" {
+		t.Fatalf("unexpected AddOnAccessEntries.RoleName %q", cfg.AddOnAccessEntries.RoleName)
+	}
+	if cfg.AddOnAccessEntries.AccessPolicyARN != "arn:aws:eks::aws:cluster-access-policy/AmazonEKSViewPolicy" {
+		t.Fatalf("unexpected AddOnAccessEntries.AccessPolicyARN %q", cfg.AddOnAccessEntries.AccessPolicyARN)
+	}
+	if !strings.HasSuffix(cfg.AddOnAccessEntries.KubeConfigPath, ".access-entries.kubeconfig.yaml") {
+		t.Fatalf("unexpected AddOnAccessEntries.KubeConfigPath %q", cfg.AddOnAccessEntries.KubeConfigPath)
+	}
+	if !cfg.AddOnAccessEntries.CallerIdentity {
+		t.Fatal("expected AddOnAccessEntries.CallerIdentity true")
+	}
+	if cfg.AddOnAccessEntries.CallerIdentityAccessPolicyARN != "arn:aws:eks::aws:cluster-access-policy/AmazonEKSClusterAdminPolicy" {
+		t.Fatalf("unexpected AddOnAccessEntries.CallerIdentityAccessPolicyARN %q", cfg.AddOnAccessEntries.CallerIdentityAccessPolicyARN)
+	}
+	expectedPrincipals := map[string]string{
+		"arn:aws:iam::123456789012:role/admin":  "arn:aws:eks::aws:cluster-access-policy/AmazonEKSAdminPolicy",
+		"arn:aws:iam::123456789012:user/viewer": "arn:aws:eks::aws:cluster-access-policy/AmazonEKSViewPolicy",
+	}
+	if !reflect.DeepEqual(cfg.AddOnAccessEntries.ExtraPrincipals, expectedPrincipals) {
+		t.Fatalf("unexpected AddOnAccessEntries.ExtraPrincipals %v", cfg.AddOnAccessEntries.ExtraPrincipals)
+	}
+
+	cfg.AddOnAccessEntries.ExtraPrincipals["my-role"] = ""
+	if err := cfg.ValidateAndSetDefaults(); err == nil {
+		t.Fatal("expected error for invalid AddOnAccessEntries.ExtraPrincipals")
+	}
+	delete(cfg.AddOnAccessEntries.ExtraPrincipals, "my-role")
+
+	cfg.AuthenticationMode = "IAM"
+	if err := cfg.ValidateAndSetDefaults(); err == nil {
+		t.Fatal("expected error for unknown authentication mode")
+	}
+}
+
+func TestEnvAddOnManagedAddons(t *testing.T) {
+	cfg := NewDefault()
+	defer func() {
+		os.RemoveAll(cfg.ConfigPath)
+		os.RemoveAll(cfg.KubectlCommandsOutputPath)
+		os.RemoveAll(cfg.RemoteAccessCommandsOutputPath)
+	}()
+
+	os.Setenv("AWS_K8S_TESTER_EKS_ADD_ON_MANAGED_ADDONS_ENABLE", "true")
+	defer os.Unsetenv("AWS_K8S_TESTER_EKS_ADD_ON_MANAGED_ADDONS_ENABLE")
+	os.Setenv("AWS_K8S_TESTER_EKS_ADD_ON_MANAGED_ADDONS_ADDONS", `{"vpc-cni":{"version":"v1.17.1-eksbuild.1","upgrade-version":"latest","resolve-conflicts":"PRESERVE","installed-version":"v1.0.0"},"aws-ebs-csi-driver":{"version":"default","service-account-role-arn":"arn:aws:iam::123456789012:role/ebs-csi","preserve":true}}`)
+	defer os.Unsetenv("AWS_K8S_TESTER_EKS_ADD_ON_MANAGED_ADDONS_ADDONS")
+
+	if err := cfg.UpdateFromEnvs(); err != nil {
+		t.Fatal(err)
+	}
+	if err := cfg.ValidateAndSetDefaults(); err != nil {
+		t.Fatal(err)
+	}
+	expectedAddons := map[string]ManagedAddon{
+		"vpc-cni": {
+			Version:          "v1.17.1-eksbuild.1",
+			UpgradeVersion:   ManagedAddonVersionLatest,
+			ResolveConflicts: "PRESERVE",
+		},
+		"aws-ebs-csi-driver": {
+			Version:               ManagedAddonVersionDefault,
+			ResolveConflicts:      "OVERWRITE",
+			ServiceAccountRoleARN: "arn:aws:iam::123456789012:role/ebs-csi",
+			Preserve:              true,
+		},
+	}
+	if !reflect.DeepEqual(cfg.AddOnManagedAddons.Addons, expectedAddons) {
+		t.Fatalf("unexpected AddOnManagedAddons.Addons %+v", cfg.AddOnManagedAddons.Addons)
+	}
+
+	cur := cfg.AddOnManagedAddons.Addons["vpc-cni"]
+	cur.UpgradeVersion = cur.Version
+	cfg.AddOnManagedAddons.Addons["vpc-cni"] = cur
+	if err := cfg.ValidateAndSetDefaults(); err == nil {
+		t.Fatal("expected error for UpgradeVersion same as Version")
+	}
+	cur.UpgradeVersion = ManagedAddonVersionLatest
+	cur.ResolveConflicts = "MERGE"
+	cfg.AddOnManagedAddons.Addons["vpc-cni"] = cur
+	if err := cfg.ValidateAndSetDefaults(); err == nil {
+		t.Fatal("expected error for unknown ResolveConflicts")
+	}
+	cur.ResolveConflicts = "NONE"
+	cfg.AddOnManagedAddons.Addons["vpc-cni"] = cur
+	cfg.AddOnCNIVPC = getDefaultAddOnCNIVPC()
+	cfg.AddOnCNIVPC.Enable = true
+	if err := cfg.validateAddOnManagedAddons(); err == nil {
+		t.Fatal("expected error for vpc-cni with AddOnCNIVPC")
+	}
+}
+
+func TestEnvAddOnSpotInterruption(t *testing.T) {
+	cfg := NewDefault()
+	defer func() {
+		os.RemoveAll(cfg.ConfigPath)
+		os.RemoveAll(cfg.KubectlCommandsOutputPath)
+		os.RemoveAll(cfg.RemoteAccessCommandsOutputPath)
+	}()
+
+	os.Setenv("AWS_K8S_TESTER_EKS_ADD_ON_MANAGED_NODE_GROUPS_ENABLE", "true")
+	defer os.Unsetenv("AWS_K8S_TESTER_EKS_ADD_ON_MANAGED_NODE_GROUPS_ENABLE")
+	os.Setenv("AWS_K8S_TESTER_EKS_ADD_ON_MANAGED_NODE_GROUPS_ROLE_CREATE", "true")
+	defer os.Unsetenv("AWS_K8S_TESTER_EKS_ADD_ON_MANAGED_NODE_GROUPS_ROLE_CREATE")
+	os.Setenv("AWS_K8S_TESTER_EKS_ADD_ON_MANAGED_NODE_GROUPS_MNGS", `{"mng-on-demand":{"name":"mng-on-demand","ami-type":"AL2_x86_64","asg-min-size":2,"asg-max-size":2,"asg-desired-capacity":2},"mng-spot":{"name":"mng-spot","ami-type":"AL2_x86_64","capacity-type":"SPOT","asg-min-size":2,"asg-max-size":3,"asg-desired-capacity":2}}`)
+	defer os.Unsetenv("AWS_K8S_TESTER_EKS_ADD_ON_MANAGED_NODE_GROUPS_MNGS")
+	os.Setenv("AWS_K8S_TESTER_EKS_ADD_ON_SPOT_INTERRUPTION_ENABLE", "true")
+	defer os.Unsetenv("AWS_K8S_TESTER_EKS_ADD_ON_SPOT_INTERRUPTION_ENABLE")
+	os.Setenv("AWS_K8S_TESTER_EKS_ADD_ON_SPOT_INTERRUPTION_USE_FIS", "true")
+	defer os.Unsetenv("AWS_K8S_TESTER_EKS_ADD_ON_SPOT_INTERRUPTION_USE_FIS")
+	os.Setenv("AWS_K8S_TESTER_EKS_ADD_ON_SPOT_INTERRUPTION_SLO_STRING", "7m")
+	defer os.Unsetenv("AWS_K8S_TESTER_EKS_ADD_ON_SPOT_INTERRUPTION_SLO_STRING")
+
+	if err := cfg.UpdateFromEnvs(); err != nil {
+		t.Fatal(err)
+	}
+	if err := cfg.ValidateAndSetDefaults(); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(cfg.AddOnManagedNodeGroups.MNGs["mng-spot"].InstanceTypes, DefaultSpotInstanceTypesCPU) {
+		t.Fatalf("unexpected InstanceTypes %q", cfg.AddOnManagedNodeGroups.MNGs["mng-spot"].InstanceTypes)
+	}
+	if !reflect.DeepEqual(cfg.AddOnManagedNodeGroups.MNGs["mng-on-demand"].InstanceTypes, []string{DefaultNodeInstanceTypeCPU}) {
+		t.Fatalf("unexpected InstanceTypes %q", cfg.AddOnManagedNodeGroups.MNGs["mng-on-demand"].InstanceTypes)
+	}
+	if n := gpusPerNode(DefaultSpotInstanceTypesGPU); n != 1 {
+		t.Fatalf("expected 1 GPU per node for %q, got %d", DefaultSpotInstanceTypesGPU, n)
+	}
+	if cfg.AddOnSpotInterruption.MNGName != "mng-spot" {
+		t.Fatalf("unexpected AddOnSpotInterruption.MNGName %q", cfg.AddOnSpotInterruption.MNGName)
+	}
+	if cfg.AddOnSpotInterruption.Namespace != cfg.Name+"-spot-interruption" {
+		t.Fatalf("unexpected AddOnSpotInterruption.Namespace %q", cfg.AddOnSpotInterruption.Namespace)
+	}
+	if !cfg.AddOnSpotInterruption.UseFIS || cfg.AddOnSpotInterruption.FISRoleName != cfg.Name+"-spot-interruption-fis-role" {
+		t.Fatalf("unexpected AddOnSpotInterruption.UseFIS %v, FISRoleName %q", cfg.AddOnSpotInterruption.UseFIS, cfg.AddOnSpotInterruption.FISRoleName)
+	}
+	if cfg.AddOnSpotInterruption.SLO != 7*time.Minute {
+		t.Fatalf("unexpected AddOnSpotInterruption.SLO %v", cfg.AddOnSpotInterruption.SLO)
+	}
+
+	cur := cfg.AddOnManagedNodeGroups.MNGs["mng-spot"]
+	cur.InstanceTypes = []string{"c5.xlarge"}
+	cfg.AddOnManagedNodeGroups.MNGs["mng-spot"] = cur
+	if err := cfg.ValidateAndSetDefaults(); err == nil {
+		t.Fatal("expected error for Spot managed node group with single instance type")
+	}
+	cur.InstanceTypes = DefaultSpotInstanceTypesCPU
+	cfg.AddOnManagedNodeGroups.MNGs["mng-spot"] = cur
+
+	cfg.AddOnSpotInterruption.MNGName = "mng-on-demand"
+	if err := cfg.ValidateAndSetDefaults(); err == nil {
+		t.Fatal("expected error for on-demand managed node group")
+	}
+	cfg.AddOnSpotInterruption.MNGName = "mng-spot"
+	cfg.AddOnSpotInterruption.SLOString = "1m"
+	if err := cfg.ValidateAndSetDefaults(); err == nil {
+		t.Fatal("expected error for too short SLO")
+	}
+}
+
+func TestEnvARM64NodeGroups(t *testing.T) {
+	cfg := NewDefault()
+	defer func() {
+		os.RemoveAll(cfg.ConfigPath)
+		os.RemoveAll(cfg.KubectlCommandsOutputPath)
+		os.RemoveAll(cfg.RemoteAccessCommandsOutputPath)
+	}()
+
+	os.Setenv("AWS_K8S_TESTER_EKS_ADD_ON_MANAGED_NODE_GROUPS_ENABLE", "true")
+	defer os.Unsetenv("AWS_K8S_TESTER_EKS_ADD_ON_MANAGED_NODE_GROUPS_ENABLE")
+	os.Setenv("AWS_K8S_TESTER_EKS_ADD_ON_MANAGED_NODE_GROUPS_ROLE_CREATE", "true")
+	defer os.Unsetenv("AWS_K8S_TESTER_EKS_ADD_ON_MANAGED_NODE_GROUPS_ROLE_CREATE")
+	os.Setenv("AWS_K8S_TESTER_EKS_ADD_ON_MANAGED_NODE_GROUPS_MNGS", `{"mng-arm":{"name":"mng-arm","ami-type":"AL2_ARM_64","asg-min-size":1,"asg-max-size":1,"asg-desired-capacity":1}}`)
+	defer os.Unsetenv("AWS_K8S_TESTER_EKS_ADD_ON_MANAGED_NODE_GROUPS_MNGS")
+	os.Setenv("AWS_K8S_TESTER_EKS_ADD_ON_STRESSER_REMOTE_ENABLE", "true")
+	defer os.Unsetenv("AWS_K8S_TESTER_EKS_ADD_ON_STRESSER_REMOTE_ENABLE")
+	os.Setenv("AWS_K8S_TESTER_EKS_ADD_ON_STRESSER_REMOTE_REPOSITORY_ACCOUNT_ID", "uri")
+	defer os.Unsetenv("AWS_K8S_TESTER_EKS_ADD_ON_STRESSER_REMOTE_REPOSITORY_ACCOUNT_ID")
+	os.Setenv("AWS_K8S_TESTER_EKS_ADD_ON_STRESSER_REMOTE_REPOSITORY_NAME", "stresser-repo-name")
+	defer os.Unsetenv("AWS_K8S_TESTER_EKS_ADD_ON_STRESSER_REMOTE_REPOSITORY_NAME")
+	os.Setenv("AWS_K8S_TESTER_EKS_ADD_ON_STRESSER_REMOTE_REPOSITORY_IMAGE_TAG", "stresser-repo-image-tag")
+	defer os.Unsetenv("AWS_K8S_TESTER_EKS_ADD_ON_STRESSER_REMOTE_REPOSITORY_IMAGE_TAG")
+
+	if err := cfg.UpdateFromEnvs(); err != nil {
+		t.Fatal(err)
+	}
+	if err := cfg.ValidateAndSetDefaults(); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(cfg.AddOnManagedNodeGroups.MNGs["mng-arm"].InstanceTypes, []string{DefaultNodeInstanceTypeARMCPU}) {
+		t.Fatalf("unexpected InstanceTypes %q", cfg.AddOnManagedNodeGroups.MNGs["mng-arm"].InstanceTypes)
+	}
+	if !reflect.DeepEqual(cfg.NodeArchs(), []string{ArchARM64}) || !cfg.IsARM64Only() {
+		t.Fatalf("unexpected NodeArchs %q", cfg.NodeArchs())
+	}
+	if cfg.AddOnStresserRemote.RepositoryImageArch != ArchARM64 {
+		t.Fatalf("unexpected AddOnStresserRemote.RepositoryImageArch %q", cfg.AddOnStresserRemote.RepositoryImageArch)
+	}
+
+	cfg.AddOnStresserRemote.RepositoryImageArch = ArchAMD64
+	if err := cfg.ValidateAndSetDefaults(); err == nil {
+		t.Fatal("expected error for amd64 stresser image on arm64-only cluster")
+	}
+	cfg.AddOnStresserRemote.RepositoryImageArch = ArchARM64
+
+	cur := cfg.AddOnManagedNodeGroups.MNGs["mng-arm"]
+	cur.InstanceTypes = []string{"c5.xlarge"}
+	cfg.AddOnManagedNodeGroups.MNGs["mng-arm"] = cur
+	if err := cfg.ValidateAndSetDefaults(); err == nil {
+		t.Fatal("expected error for x86 instance type with arm64 AMI type")
+	}
+
+	for itp, arch := range map[string]string{
+		"a1.large":      ArchARM64,
+		"c6g.xlarge":    ArchARM64,
+		"m6gd.2xlarge":  ArchARM64,
+		"c7gn.large":    ArchARM64,
+		"im4gn.large":   ArchARM64,
+		"g5g.xlarge":    ArchARM64,
+		"c5.xlarge":     ArchAMD64,
+		"g4dn.12xlarge": ArchAMD64,
+		"p3.8xlarge":    ArchAMD64,
+		"m5zn.large":    ArchAMD64,
+	} {
+		if v := InstanceTypeArch(itp); v != arch {
+			t.Fatalf("%q: expected %q, got %q", itp, arch, v)
+		}
+	}
+}
+
+func TestEnvAddOnManagedNodeGroupsGPU(t *testing.T) {
+	cfg := NewDefault()
+	defer func() {
+		os.RemoveAll(cfg.ConfigPath)
+		os.RemoveAll(cfg.KubectlCommandsOutputPath)
+		os.RemoveAll(cfg.RemoteAccessCommandsOutputPath)
+	}()
+
+	os.Setenv("AWS_K8S_TESTER_EKS_ADD_ON_MANAGED_NODE_GROUPS_ENABLE", "true")
+	defer os.Unsetenv("AWS_K8S_TESTER_EKS_ADD_ON_MANAGED_NODE_GROUPS_ENABLE")
+	os.Setenv("AWS_K8S_TESTER_EKS_ADD_ON_MANAGED_NODE_GROUPS_ROLE_CREATE", "true")
+	defer os.Unsetenv("AWS_K8S_TESTER_EKS_ADD_ON_MANAGED_NODE_GROUPS_ROLE_CREATE")
+	os.Setenv("AWS_K8S_TESTER_EKS_ADD_ON_MANAGED_NODE_GROUPS_MNGS", `{"mng-gpu":{"name":"mng-gpu","ami-type":"AL2_x86_64_GPU","asg-min-size":1,"asg-max-size":1,"asg-desired-capacity":1},"mng-gpu-mixed":{"name":"mng-gpu-mixed","ami-type":"AL2_x86_64_GPU","instance-types":["g5.xlarge","g5.12xlarge"],"asg-min-size":2,"asg-max-size":2,"asg-desired-capacity":2},"mng-cpu":{"name":"mng-cpu","ami-type":"AL2_x86_64","asg-min-size":1,"asg-max-size":1,"asg-desired-capacity":1}}`)
+	defer os.Unsetenv("AWS_K8S_TESTER_EKS_ADD_ON_MANAGED_NODE_GROUPS_MNGS")
+	os.Setenv("AWS_K8S_TESTER_EKS_ADD_ON_CUDA_VECTOR_ADD_ENABLE", "true")
+	defer os.Unsetenv("AWS_K8S_TESTER_EKS_ADD_ON_CUDA_VECTOR_ADD_ENABLE")
+
+	if err := cfg.UpdateFromEnvs(); err != nil {
+		t.Fatal(err)
+	}
+	if err := cfg.ValidateAndSetDefaults(); err != nil {
+		t.Fatal(err)
+	}
+	expected := []GPUNodeGroup{
+		{Name: "mng-gpu", GPUsPerNode: GPUInstanceTypes[DefaultNodeInstanceTypeGPU], MinNodes: 1},
+		{Name: "mng-gpu-mixed", GPUsPerNode: 0, MinNodes: 2},
+	}
+	if !reflect.DeepEqual(cfg.GPUNodeGroups(), expected) {
+		t.Fatalf("unexpected GPUNodeGroups %+v", cfg.GPUNodeGroups())
+	}
+
+	cur := cfg.AddOnManagedNodeGroups.MNGs["mng-cpu"]
+	cur.GPUsPerNode = 1
+	cfg.AddOnManagedNodeGroups.MNGs["mng-cpu"] = cur
+	if err := cfg.ValidateAndSetDefaults(); err == nil {
+		t.Fatal("expected error for GPUsPerNode with non-GPU AMI type")
+	}
+}
+
+func TestEnvRegression(t *testing.T) {
+	cfg := NewDefault()
+	defer func() {
+		os.RemoveAll(cfg.ConfigPath)
+		os.RemoveAll(cfg.KubectlCommandsOutputPath)
+		os.RemoveAll(cfg.RemoteAccessCommandsOutputPath)
+	}()
+
+	os.Setenv("AWS_K8S_TESTER_EKS_REGRESSION_ENABLE", "true")
+	defer os.Unsetenv("AWS_K8S_TESTER_EKS_REGRESSION_ENABLE")
+	os.Setenv("AWS_K8S_TESTER_EKS_REGRESSION_BASELINE_S3_KEYS", `{"AddOnStresserLocal.RequestsSummaryWrites":"prior/writes.json"}`)
+	defer os.Unsetenv("AWS_K8S_TESTER_EKS_REGRESSION_BASELINE_S3_KEYS")
+	os.Setenv("AWS_K8S_TESTER_EKS_REGRESSION_MAX_LATENCY_INCREASE_PERCENT", "50")
+	defer os.Unsetenv("AWS_K8S_TESTER_EKS_REGRESSION_MAX_LATENCY_INCREASE_PERCENT")
+	os.Setenv("AWS_K8S_TESTER_EKS_REGRESSION_FAIL_ON_REGRESSION", "true")
+	defer os.Unsetenv("AWS_K8S_TESTER_EKS_REGRESSION_FAIL_ON_REGRESSION")
+	os.Setenv("AWS_K8S_TESTER_EKS_ADD_ON_STRESSER_LOCAL_ENABLE", "true")
+	defer os.Unsetenv("AWS_K8S_TESTER_EKS_ADD_ON_STRESSER_LOCAL_ENABLE")
+
+	if err := cfg.UpdateFromEnvs(); err != nil {
+		t.Fatal(err)
+	}
+	if err := cfg.ValidateAndSetDefaults(); err != nil {
+		t.Fatal(err)
+	}
+	if !cfg.IsEnabledRegression() {
+		t.Fatal("expected Regression enabled")
+	}
+	if cfg.Regression.BaselineS3BucketName != cfg.S3.BucketName {
+		t.Fatalf("unexpected Regression.BaselineS3BucketName %q", cfg.Regression.BaselineS3BucketName)
+	}
+	if !reflect.DeepEqual(cfg.Regression.BaselineS3Keys, map[string]string{"AddOnStresserLocal.RequestsSummaryWrites": "prior/writes.json"}) {
+		t.Fatalf("unexpected Regression.BaselineS3Keys %v", cfg.Regression.BaselineS3Keys)
+	}
+	th := cfg.RegressionThresholds()
+	if th.MaxSuccessRateDropPercent != 1.0 || th.MaxLatencyIncreasePercent != 50 || !cfg.Regression.FailOnRegression {
+		t.Fatalf("unexpected Regression %+v", cfg.Regression)
+	}
+	rs := cfg.RequestsSummaries()
+	if _, ok := rs["AddOnStresserLocal.RequestsSummaryWrites"]; !ok {
+		t.Fatalf("expected AddOnStresserLocal.RequestsSummaryWrites in %v", rs)
+	}
+	if _, ok := rs["AddOnSecretsLocal.RequestsSummaryWrites"]; ok {
+		t.Fatal("unexpected result of disabled add-on")
+	}
+
+	regressed := cfg.SetRegressionReports(map[string]metrics.RegressionReport{
+		"AddOnStresserLocal.RequestsSummaryWrites": {Regressions: []metrics.Regression{{Metric: "latency-p99"}}},
+	})
+	if !reflect.DeepEqual(regressed, []string{"AddOnStresserLocal.RequestsSummaryWrites"}) || !cfg.Regression.Regressed {
+		t.Fatalf("unexpected regressed %v", regressed)
+	}
+
+	cfg.Regression.BaselineS3Keys["AddOnStresserLocal.Unknown"] = "prior/unknown.json"
+	if err := cfg.ValidateAndSetDefaults(); err == nil {
+		t.Fatal("expected error for unknown Regression.BaselineS3Keys name")
+	}
+}
+
+func TestEnvPrometheusEndpoint(t *testing.T) {
+	cfg := NewDefault()
+	defer func() {
+		os.RemoveAll(cfg.ConfigPath)
+		os.RemoveAll(cfg.KubectlCommandsOutputPath)
+		os.RemoveAll(cfg.RemoteAccessCommandsOutputPath)
+	}()
+
+	os.Setenv("AWS_K8S_TESTER_EKS_PROMETHEUS_ENDPOINT_ENABLE", "true")
+	defer os.Unsetenv("AWS_K8S_TESTER_EKS_PROMETHEUS_ENDPOINT_ENABLE")
+	os.Setenv("AWS_K8S_TESTER_EKS_PROMETHEUS_ENDPOINT_LISTEN_ADDRESS", "localhost:19101")
+	defer os.Unsetenv("AWS_K8S_TESTER_EKS_PROMETHEUS_ENDPOINT_LISTEN_ADDRESS")
+
+	if err := cfg.UpdateFromEnvs(); err != nil {
+		t.Fatal(err)
+	}
+	if err := cfg.ValidateAndSetDefaults(); err != nil {
+		t.Fatal(err)
+	}
+	if !cfg.IsEnabledPrometheusEndpoint() {
+		t.Fatal("expected PrometheusEndpoint enabled")
+	}
+	if cfg.PrometheusEndpoint.ListenAddress != "localhost:19101" {
+		t.Fatalf("unexpected PrometheusEndpoint.ListenAddress %q", cfg.PrometheusEndpoint.ListenAddress)
+	}
+	if cfg.PrometheusEndpoint.Path != "/metrics" {
+		t.Fatalf("unexpected PrometheusEndpoint.Path %q", cfg.PrometheusEndpoint.Path)
+	}
+
+	cfg.PrometheusEndpoint.ListenAddress = "9101"
+	if err := cfg.ValidateAndSetDefaults(); err == nil {
+		t.Fatal("expected error for invalid PrometheusEndpoint.ListenAddress")
+	}
+}
+
+func TestEnvOTLPExporter(t *testing.T) {
+	cfg := NewDefault()
+	defer func() {
+		os.RemoveAll(cfg.ConfigPath)
+		os.RemoveAll(cfg.KubectlCommandsOutputPath)
+		os.RemoveAll(cfg.RemoteAccessCommandsOutputPath)
+	}()
+
+	os.Setenv("AWS_K8S_TESTER_EKS_OTLP_EXPORTER_ENABLE", "true")
+	defer os.Unsetenv("AWS_K8S_TESTER_EKS_OTLP_EXPORTER_ENABLE")
+	os.Setenv("AWS_K8S_TESTER_EKS_OTLP_EXPORTER_ENDPOINT", "https://otlp.example.com/v1/metrics")
+	defer os.Unsetenv("AWS_K8S_TESTER_EKS_OTLP_EXPORTER_ENDPOINT")
+	os.Setenv("AWS_K8S_TESTER_EKS_OTLP_EXPORTER_HEADERS", `{"Authorization":"Bearer abc"}`)
+	defer os.Unsetenv("AWS_K8S_TESTER_EKS_OTLP_EXPORTER_HEADERS")
+	os.Setenv("AWS_K8S_TESTER_EKS_OTLP_EXPORTER_INTERVAL", "30s")
+	defer os.Unsetenv("AWS_K8S_TESTER_EKS_OTLP_EXPORTER_INTERVAL")
+
+	if err := cfg.UpdateFromEnvs(); err != nil {
+		t.Fatal(err)
+	}
+	if err := cfg.ValidateAndSetDefaults(); err != nil {
+		t.Fatal(err)
+	}
+	if !cfg.IsEnabledOTLPExporter() {
+		t.Fatal("expected OTLPExporter enabled")
+	}
+	if cfg.OTLPExporter.Endpoint != "https://otlp.example.com/v1/metrics" {
+		t.Fatalf("unexpected OTLPExporter.Endpoint %q", cfg.OTLPExporter.Endpoint)
+	}
+	if !reflect.DeepEqual(cfg.OTLPExporter.Headers, map[string]string{"Authorization": "Bearer abc"}) {
+		t.Fatalf("unexpected OTLPExporter.Headers %v", cfg.OTLPExporter.Headers)
+	}
+	if cfg.OTLPExporter.Interval != 30*time.Second {
+		t.Fatalf("unexpected OTLPExporter.Interval %v", cfg.OTLPExporter.Interval)
+	}
+	if cfg.OTLPExporter.IntervalString != "30s" {
+		t.Fatalf("unexpected OTLPExporter.IntervalString %q", cfg.OTLPExporter.IntervalString)
+	}
+
+	cfg.OTLPExporter.Endpoint = "localhost:4318"
+	if err := cfg.ValidateAndSetDefaults(); err == nil {
+		t.Fatal("expected error for invalid OTLPExporter.Endpoint")
+	}
+}
+
+func TestEnvSLO(t *testing.T) {
+	cfg := NewDefault()
+	defer func() {
+		os.RemoveAll(cfg.ConfigPath)
+		os.RemoveAll(cfg.KubectlCommandsOutputPath)
+		os.RemoveAll(cfg.RemoteAccessCommandsOutputPath)
+	}()
+
+	os.Setenv("AWS_K8S_TESTER_EKS_ADD_ON_MANAGED_NODE_GROUPS_ENABLE", `true`)
+	defer os.Unsetenv("AWS_K8S_TESTER_EKS_ADD_ON_MANAGED_NODE_GROUPS_ENABLE")
+	os.Setenv("AWS_K8S_TESTER_EKS_ADD_ON_STRESSER_LOCAL_ENABLE", "true")
+	defer os.Unsetenv("AWS_K8S_TESTER_EKS_ADD_ON_STRESSER_LOCAL_ENABLE")
+	os.Setenv("AWS_K8S_TESTER_EKS_ADD_ON_STRESSER_LOCAL_SLO_MAX_ERROR_RATE_PERCENT", "1.5")
+	defer os.Unsetenv("AWS_K8S_TESTER_EKS_ADD_ON_STRESSER_LOCAL_SLO_MAX_ERROR_RATE_PERCENT")
+	os.Setenv("AWS_K8S_TESTER_EKS_ADD_ON_STRESSER_LOCAL_SLO_MAX_LATENCY_P99", "100ms")
+	defer os.Unsetenv("AWS_K8S_TESTER_EKS_ADD_ON_STRESSER_LOCAL_SLO_MAX_LATENCY_P99")
+	os.Setenv("AWS_K8S_TESTER_EKS_ADD_ON_CONFIGMAPS_LOCAL_ENABLE", "true")
+	defer os.Unsetenv("AWS_K8S_TESTER_EKS_ADD_ON_CONFIGMAPS_LOCAL_ENABLE")
+
+	if err := cfg.UpdateFromEnvs(); err != nil {
+		t.Fatal(err)
+	}
+	if err := cfg.ValidateAndSetDefaults(); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.AddOnStresserLocal.SLOMaxErrorRatePercent != 1.5 {
+		t.Fatalf("unexpected AddOnStresserLocal.SLOMaxErrorRatePercent %v", cfg.AddOnStresserLocal.SLOMaxErrorRatePercent)
+	}
+	if cfg.AddOnStresserLocal.SLOMaxLatencyP99 != 100*time.Millisecond {
+		t.Fatalf("unexpected AddOnStresserLocal.SLOMaxLatencyP99 %v", cfg.AddOnStresserLocal.SLOMaxLatencyP99)
+	}
+	if cfg.AddOnStresserLocal.SLOMaxLatencyP99String != "100ms" {
+		t.Fatalf("unexpected AddOnStresserLocal.SLOMaxLatencyP99String %q", cfg.AddOnStresserLocal.SLOMaxLatencyP99String)
+	}
+
+	cfg.AddOnStresserLocal.RequestsSummaryWrites = metrics.RequestsSummary{SuccessTotal: 99, FailureTotal: 1, LantencyP99: 50 * time.Millisecond}
+	cfg.AddOnStresserLocal.RequestsSummaryReads = metrics.RequestsSummary{SuccessTotal: 100, LantencyP99: 200 * time.Millisecond}
+	cfg.AddOnConfigmapsLocal.RequestsSummaryWrites = metrics.RequestsSummary{SuccessTotal: 1, FailureTotal: 1}
+
+	// add-ons without any SLO are skipped
+	verdicts := cfg.EvaluateSLOs()
+	if len(verdicts) != 1 {
+		t.Fatalf("unexpected verdicts %+v", verdicts)
+	}
+	v := verdicts["AddOnStresserLocal"]
+	if v.Result != metrics.SLOResultFailed || len(v.Violations) != 1 || v.Violations[0].Summary != "RequestsSummaryReads" {
+		t.Fatalf("unexpected verdict %+v", v)
+	}
+	if !reflect.DeepEqual(cfg.AddOnStresserLocal.SLOVerdict, v) {
+		t.Fatalf("unexpected AddOnStresserLocal.SLOVerdict %+v", cfg.AddOnStresserLocal.SLOVerdict)
+	}
+	if cfg.AddOnConfigmapsLocal.SLOVerdict.Result != "" {
+		t.Fatalf("unexpected AddOnConfigmapsLocal.SLOVerdict %+v", cfg.AddOnConfigmapsLocal.SLOVerdict)
+	}
+
+	cfg.AddOnStresserLocal.SLOMaxErrorRatePercent = 101
+	if err := cfg.ValidateAndSetDefaults(); err == nil {
+		t.Fatal("expected error for invalid AddOnStresserLocal.SLOMaxErrorRatePercent")
+	}
+}
+
+func TestEnvCWSummaries(t *testing.T) {
+	cfg := NewDefault()
+	defer func() {
+		os.RemoveAll(cfg.ConfigPath)
+		os.RemoveAll(cfg.KubectlCommandsOutputPath)
+		os.RemoveAll(cfg.RemoteAccessCommandsOutputPath)
+	}()
+
+	os.Setenv("AWS_K8S_TESTER_EKS_CW_SUMMARIES_ENABLE", "true")
+	defer os.Unsetenv("AWS_K8S_TESTER_EKS_CW_SUMMARIES_ENABLE")
+
+	if err := cfg.UpdateFromEnvs(); err != nil {
+		t.Fatal(err)
+	}
+	if err := cfg.ValidateAndSetDefaults(); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.CWSummaries.Namespace != cfg.CWNamespace {
+		t.Fatalf("unexpected CWSummaries.Namespace %q", cfg.CWSummaries.Namespace)
+	}
+	if !reflect.DeepEqual(cfg.CWSummaries.Dimensions, map[string]string{"ClusterName": cfg.Name}) {
+		t.Fatalf("unexpected CWSummaries.Dimensions %v", cfg.CWSummaries.Dimensions)
+	}
+
+	os.Setenv("AWS_K8S_TESTER_EKS_CW_SUMMARIES_NAMESPACE", "nightly")
+	defer os.Unsetenv("AWS_K8S_TESTER_EKS_CW_SUMMARIES_NAMESPACE")
+	os.Setenv("AWS_K8S_TESTER_EKS_CW_SUMMARIES_DIMENSIONS", `{"TestSuite":"stress"}`)
+	defer os.Unsetenv("AWS_K8S_TESTER_EKS_CW_SUMMARIES_DIMENSIONS")
+	if err := cfg.UpdateFromEnvs(); err != nil {
+		t.Fatal(err)
+	}
+	if err := cfg.ValidateAndSetDefaults(); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.CWSummaries.Namespace != "nightly" {
+		t.Fatalf("unexpected CWSummaries.Namespace %q", cfg.CWSummaries.Namespace)
+	}
+	if !reflect.DeepEqual(cfg.CWSummaries.Dimensions, map[string]string{"TestSuite": "stress"}) {
+		t.Fatalf("unexpected CWSummaries.Dimensions %v", cfg.CWSummaries.Dimensions)
+	}
+
+	cfg.CWSummaries.Dimensions["Summary"] = "x"
+	if err := cfg.ValidateAndSetDefaults(); err == nil {
+		t.Fatal("expected error for reserved dimension")
+	}
+}
+
+func TestEnvLatencyHistogram(t *testing.T) {
+	cfg := NewDefault()
+	defer func() {
+		os.RemoveAll(cfg.ConfigPath)
+		os.RemoveAll(cfg.KubectlCommandsOutputPath)
+		os.RemoveAll(cfg.RemoteAccessCommandsOutputPath)
+	}()
+
+	if err := cfg.ValidateAndSetDefaults(); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.LatencyHistogram.Backend != metrics.HistogramBackendFixed {
+		t.Fatalf("unexpected LatencyHistogram.Backend %q", cfg.LatencyHistogram.Backend)
+	}
+
+	os.Setenv("AWS_K8S_TESTER_EKS_LATENCY_HISTOGRAM_BACKEND", "hdr")
+	defer os.Unsetenv("AWS_K8S_TESTER_EKS_LATENCY_HISTOGRAM_BACKEND")
+	os.Setenv("AWS_K8S_TESTER_EKS_LATENCY_HISTOGRAM_SIGNIFICANT_FIGURES", "4")
+	defer os.Unsetenv("AWS_K8S_TESTER_EKS_LATENCY_HISTOGRAM_SIGNIFICANT_FIGURES")
+
+	if err := cfg.UpdateFromEnvs(); err != nil {
+		t.Fatal(err)
+	}
+	if err := cfg.ValidateAndSetDefaults(); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.LatencyHistogram.Backend != metrics.HistogramBackendHDR {
+		t.Fatalf("unexpected LatencyHistogram.Backend %q", cfg.LatencyHistogram.Backend)
+	}
+	if cfg.LatencyHistogram.SignificantFigures != 4 {
+		t.Fatalf("unexpected LatencyHistogram.SignificantFigures %d", cfg.LatencyHistogram.SignificantFigures)
+	}
+
+	cfg.LatencyHistogram.SignificantFigures = 6
+	if err := cfg.ValidateAndSetDefaults(); err == nil {
+		t.Fatal("expected error for too many significant figures")
+	}
+	cfg.LatencyHistogram.SignificantFigures = 3
+	cfg.LatencyHistogram.Backend = "linear"
+	if err := cfg.ValidateAndSetDefaults(); err == nil {
+		t.Fatal("expected error for unknown backend")
+	}
+}
+
+func TestRequestsSummaryJSONArtifact(t *testing.T) {
+	cfg := NewDefault()
+	defer func() {
+		os.RemoveAll(cfg.ConfigPath)
+		os.RemoveAll(cfg.KubectlCommandsOutputPath)
+		os.RemoveAll(cfg.RemoteAccessCommandsOutputPath)
+	}()
+
+	cfg.AddOnStresserLocal = &AddOnStresserLocal{
+		RequestsSummaryWritesJSONPath:  "/tmp/writes-summary.json",
+		RequestsSummaryWritesJSONS3Key: "name/writes-summary.json",
+	}
+	p, k := cfg.RequestsSummaryJSONArtifact("AddOnStresserLocal.RequestsSummaryWrites")
+	if p != "/tmp/writes-summary.json" || k != "name/writes-summary.json" {
+		t.Fatalf("unexpected JSON artifact %q, %q", p, k)
+	}
+	cfg.AddOnStresserLocal = nil
+	if p, k = cfg.RequestsSummaryJSONArtifact("AddOnStresserLocal.RequestsSummaryWrites"); p != "" || k != "" {
+		t.Fatalf("unexpected JSON artifact %q, %q", p, k)
+	}
+	if p, k = cfg.RequestsSummaryJSONArtifact("unknown"); p != "" || k != "" {
+		t.Fatalf("unexpected JSON artifact %q, %q", p, k)
+	}
+}
+
+func TestEnvAutoMode(t *testing.T) {
+	cfg := NewDefault()
+	defer func() {
+		os.RemoveAll(cfg.ConfigPath)
+		os.RemoveAll(cfg.KubectlCommandsOutputPath)
+		os.RemoveAll(cfg.RemoteAccessCommandsOutputPath)
+	}()
+
+	os.Setenv("AWS_K8S_TESTER_EKS_VERSION", "1.30")
+	defer os.Unsetenv("AWS_K8S_TESTER_EKS_VERSION")
+	os.Setenv("AWS_K8S_TESTER_EKS_AUTO_MODE_ENABLE", "true")
+	defer os.Unsetenv("AWS_K8S_TESTER_EKS_AUTO_MODE_ENABLE")
+	os.Setenv("AWS_K8S_TESTER_EKS_AUTO_MODE_NODE_POOLS", "general-purpose")
+	defer os.Unsetenv("AWS_K8S_TESTER_EKS_AUTO_MODE_NODE_POOLS")
+	os.Setenv("AWS_K8S_TESTER_EKS_ADD_ONS", "nlb-hello-world,jobs-pi")
+	defer os.Unsetenv("AWS_K8S_TESTER_EKS_ADD_ONS")
+
+	if err := cfg.UpdateFromEnvs(); err != nil {
+		t.Fatal(err)
+	}
+	if err := cfg.ValidateAndSetDefaults(); err != nil {
+		t.Fatal(err)
+	}
+	if !cfg.IsEnabledAutoMode() {
+		t.Fatal("expected AutoMode enabled")
+	}
+	if !reflect.DeepEqual(cfg.AutoMode.NodePools, []string{"general-purpose"}) {
+		t.Fatalf("unexpected AutoMode.NodePools %q", cfg.AutoMode.NodePools)
+	}
+	if cfg.AuthenticationMode != eks.AuthenticationModeApi {
+		t.Fatalf("unexpected AuthenticationMode %q", cfg.AuthenticationMode)
+	}
+	for _, arn := range AutoModeManagedPolicyARNs {
+		found := false
+		for _, v := range cfg.Role.ManagedPolicyARNs {
+			if v == arn {
+				found = true
+				break
+			}
+		}
+		if !found {
+			t.Fatalf("Role.ManagedPolicyARNs missing %q (%q)", arn, cfg.Role.ManagedPolicyARNs)
+		}
+	}
+	if len(cfg.Role.ManagedPolicyARNs) > 9 {
+		t.Fatalf("too many Role.ManagedPolicyARNs %q", cfg.Role.ManagedPolicyARNs)
+	}
+	if ns := cfg.AutoModeNamespaces(); !reflect.DeepEqual(ns, []string{cfg.AddOnJobsPi.Namespace, cfg.AddOnNLBHelloWorld.Namespace}) {
+		t.Fatalf("unexpected AutoModeNamespaces %q", ns)
+	}
+
+	cfg.AddOnManagedNodeGroups = getDefaultAddOnManagedNodeGroups(cfg.Name)
+	cfg.AddOnManagedNodeGroups.Enable = true
+	if err := cfg.ValidateAndSetDefaults(); err == nil {
+		t.Fatal("expected error for AddOnManagedNodeGroups with AutoMode")
+	}
+	cfg.AddOnManagedNodeGroups = nil
+	cfg.AddOnCSIEBS = getDefaultAddOnCSIEBS()
+	cfg.AddOnCSIEBS.Enable = true
+	if err := cfg.ValidateAndSetDefaults(); err == nil {
+		t.Fatal("expected error for AddOnCSIEBS with AutoMode")
+	}
+}
+
+func TestEnvOIDCProvider(t *testing.T) {
+	defaultCfg := NewDefault()
+	defer func() {
+		os.RemoveAll(defaultCfg.ConfigPath)
+		os.RemoveAll(defaultCfg.KubectlCommandsOutputPath)
+		os.RemoveAll(defaultCfg.RemoteAccessCommandsOutputPath)
+	}()
+
+	if err := defaultCfg.ValidateAndSetDefaults(); err != nil {
+		t.Fatal(err)
+	}
+	if defaultCfg.IsEnabledOIDCProvider() {
+		t.Fatal("unexpected OIDCProvider enabled by default")
+	}
+
+	cfg := NewDefault()
+	defer func() {
+		os.RemoveAll(cfg.ConfigPath)
+		os.RemoveAll(cfg.KubectlCommandsOutputPath)
+		os.RemoveAll(cfg.RemoteAccessCommandsOutputPath)
+	}()
+	os.Setenv("AWS_K8S_TESTER_EKS_OIDC_PROVIDER_ENABLE", "true")
+	defer os.Unsetenv("AWS_K8S_TESTER_EKS_OIDC_PROVIDER_ENABLE")
+	if err := cfg.UpdateFromEnvs(); err != nil {
+		t.Fatal(err)
+	}
+	if err := cfg.ValidateAndSetDefaults(); err != nil {
+		t.Fatal(err)
+	}
+	if !cfg.IsEnabledOIDCProvider() {
+		t.Fatal("expected OIDCProvider enabled")
+	}
+
+	cfg.OIDCProvider.ServiceAccountRoles = map[string]string{"irsa": "arn:aws:iam::123:role/irsa"}
+	if err := cfg.ValidateAndSetDefaults(); err == nil {
+		t.Fatal("expected error for invalid OIDCProvider.ServiceAccountRoles key")
+	}
+	cfg.OIDCProvider.ServiceAccountRoles = map[string]string{ServiceAccountRoleKey("irsa", "irsa-service-account"): "arn:aws:iam::123:role/irsa"}
+	if err := cfg.ValidateAndSetDefaults(); err != nil {
+		t.Fatal(err)
+	}
+
+	os.Setenv("AWS_K8S_TESTER_EKS_OIDC_PROVIDER_CREATED", "true")
+	defer os.Unsetenv("AWS_K8S_TESTER_EKS_OIDC_PROVIDER_CREATED")
+	if err := cfg.UpdateFromEnvs(); err == nil {
+		t.Fatal("expected error for read-only OIDCProvider.Created")
+	}
+}
+
+func TestOIDCProviderAddOns(t *testing.T) {
+	cfg := NewDefault()
+	cfg.OIDCProvider = nil
+	cfg.AddOnIRSA = getDefaultAddOnIRSA()
+	cfg.AddOnIRSA.Enable = true
+	if err := cfg.validateOIDCProvider(); err != nil {
+		t.Fatal(err)
+	}
+	if !cfg.IsEnabledOIDCProvider() {
+		t.Fatal("expected OIDCProvider enabled for AddOnIRSA")
+	}
+}
+
+func TestEnvVersionSkew(t *testing.T) {
+	cfg := NewDefault()
+	defer func() {
+		os.RemoveAll(cfg.ConfigPath)
+		os.RemoveAll(cfg.KubectlCommandsOutputPath)
+		os.RemoveAll(cfg.RemoteAccessCommandsOutputPath)
+	}()
+
+	os.Setenv("AWS_K8S_TESTER_EKS_VERSION", "1.26")
+	defer os.Unsetenv("AWS_K8S_TESTER_EKS_VERSION")
+	os.Setenv("AWS_K8S_TESTER_EKS_VERSION_SKEW_ENABLE", "true")
+	defer os.Unsetenv("AWS_K8S_TESTER_EKS_VERSION_SKEW_ENABLE")
+	os.Setenv("AWS_K8S_TESTER_EKS_VERSION_SKEW_CONFORMANCE_LITE_IMAGE", "my-busybox")
+	defer os.Unsetenv("AWS_K8S_TESTER_EKS_VERSION_SKEW_CONFORMANCE_LITE_IMAGE")
+	os.Setenv("AWS_K8S_TESTER_EKS_ADD_ON_MANAGED_NODE_GROUPS_ENABLE", "true")
+	defer os.Unsetenv("AWS_K8S_TESTER_EKS_ADD_ON_MANAGED_NODE_GROUPS_ENABLE")
+	os.Setenv("AWS_K8S_TESTER_EKS_ADD_ON_MANAGED_NODE_GROUPS_MNGS", `{"mng-n-2":{"name":"mng-n-2","ami-type":"AL2_x86_64","version":"1.24","version-upgrade":{"enable":true,"version":"1.25"},"asg-min-size":1,"asg-max-size":1,"asg-desired-capacity":1}}`)
+	defer os.Unsetenv("AWS_K8S_TESTER_EKS_ADD_ON_MANAGED_NODE_GROUPS_MNGS")
+
+	if err := cfg.UpdateFromEnvs(); err != nil {
+		t.Fatal(err)
+	}
+	if err := cfg.ValidateAndSetDefaults(); err != nil {
+		t.Fatal(err)
+	}
+	if !cfg.IsEnabledVersionSkew() {
+		t.Fatal("expected VersionSkew enabled")
+	}
+	if !cfg.VersionSkew.ConformanceLite {
+		t.Fatal("expected VersionSkew.ConformanceLite by default")
+	}
+	if cfg.VersionSkew.ConformanceLiteImage != "my-busybox" {
+		t.Fatalf("unexpected VersionSkew.ConformanceLiteImage %q", cfg.VersionSkew.ConformanceLiteImage)
+	}
+	if cfg.VersionSkew.Namespace != cfg.Name+"-version-skew" {
+		t.Fatalf("unexpected VersionSkew.Namespace %q", cfg.VersionSkew.Namespace)
+	}
+	cur := cfg.AddOnManagedNodeGroups.MNGs["mng-n-2"]
+	if cur.VersionValue != 1.24 {
+		t.Fatalf("unexpected MNG VersionValue %v", cur.VersionValue)
+	}
+	if v := cur.KubeletVersion(cfg.VersionValue); v != 1.24 {
+		t.Fatalf("unexpected MNG KubeletVersion %v", v)
+	}
+
+	// N-2 is the limit before 1.28
+	cur.Version = "1.23"
+	cur.VersionUpgrade.Enable = false
+	cfg.AddOnManagedNodeGroups.MNGs["mng-n-2"] = cur
+	if err := cfg.ValidateAndSetDefaults(); err == nil {
+		t.Fatal("expected error for MNG Version skew")
+	}
+	cur.Version = "1.27"
+	cfg.AddOnManagedNodeGroups.MNGs["mng-n-2"] = cur
+	if err := cfg.ValidateAndSetDefaults(); err == nil {
+		t.Fatal("expected error for MNG Version newer than cluster")
+	}
+
+	// N-2 before the control plane upgrade would be N-3 after
+	cur.Version = "1.24"
+	cfg.AddOnManagedNodeGroups.MNGs["mng-n-2"] = cur
+	cfg.AddOnClusterVersionUpgrade = &AddOnClusterVersionUpgrade{Enable: true, Version: "1.27"}
+	if err := cfg.ValidateAndSetDefaults(); err == nil {
+		t.Fatal("expected error for MNG Version skew after cluster version upgrade")
+	}
+	cur.Version = "1.25"
+	cfg.AddOnManagedNodeGroups.MNGs["mng-n-2"] = cur
+	if err := cfg.ValidateAndSetDefaults(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestKubeletVersionSkewLimit(t *testing.T) {
+	tt := []struct {
+		controlPlaneVersion float64
+		nodeVersion         float64
+		skew                int
+		limit               int
+	}{
+		{1.27, 1.25, 2, 2},
+		{1.28, 1.25, 3, 3},
+		{1.30, 1.29, 1, 3},
+		{1.29, 1.30, -1, 3},
+	}
+	for i, tv := range tt {
+		if skew := MinorVersionSkew(tv.controlPlaneVersion, tv.nodeVersion); skew != tv.skew {
+			t.Fatalf("#%d: expected skew %d, got %d", i, tv.skew, skew)
+		}
+		if limit := KubeletVersionSkewLimit(tv.controlPlaneVersion); limit != tv.limit {
+			t.Fatalf("#%d: expected limit %d, got %d", i, tv.limit, limit)
+		}
+	}
+}
+
+func TestEnvOutpost(t *testing.T) {
+	cfg := NewDefault()
+	defer func() {
+		os.RemoveAll(cfg.ConfigPath)
+		os.RemoveAll(cfg.KubectlCommandsOutputPath)
+		os.RemoveAll(cfg.RemoteAccessCommandsOutputPath)
+	}()
+
+	os.Setenv("AWS_K8S_TESTER_EKS_OUTPOST_ENABLE", "true")
+	defer os.Unsetenv("AWS_K8S_TESTER_EKS_OUTPOST_ENABLE")
+	os.Setenv("AWS_K8S_TESTER_EKS_OUTPOST_OUTPOST_ARN", "arn:aws:outposts:us-west-2:123:outpost/op-0123")
+	defer os.Unsetenv("AWS_K8S_TESTER_EKS_OUTPOST_OUTPOST_ARN")
+	os.Setenv("AWS_K8S_TESTER_EKS_OUTPOST_SUBNET_IDS", "subnet-op1")
+	defer os.Unsetenv("AWS_K8S_TESTER_EKS_OUTPOST_SUBNET_IDS")
+	os.Setenv("AWS_K8S_TESTER_EKS_VPC_CREATE", "false")
+	defer os.Unsetenv("AWS_K8S_TESTER_EKS_VPC_CREATE")
+	os.Setenv("AWS_K8S_TESTER_EKS_VPC_ID", "vpc-id")
+	defer os.Unsetenv("AWS_K8S_TESTER_EKS_VPC_ID")
+	os.Setenv("AWS_K8S_TESTER_EKS_VPC_PUBLIC_SUBNET_IDS", "subnet-1,subnet-2")
+	defer os.Unsetenv("AWS_K8S_TESTER_EKS_VPC_PUBLIC_SUBNET_IDS")
+	os.Setenv("AWS_K8S_TESTER_EKS_ADD_ON_NODE_GROUPS_ENABLE", "true")
+	defer os.Unsetenv("AWS_K8S_TESTER_EKS_ADD_ON_NODE_GROUPS_ENABLE")
+
+	if err := cfg.UpdateFromEnvs(); err != nil {
+		t.Fatal(err)
+	}
+	if err := cfg.ValidateAndSetDefaults(); err != nil {
+		t.Fatal(err)
+	}
+	if !cfg.IsEnabledOutpost() {
+		t.Fatal("expected Outpost enabled")
+	}
+	if cfg.Outpost.ControlPlaneInstanceType != DefaultOutpostControlPlaneInstanceType {
+		t.Fatalf("unexpected Outpost.ControlPlaneInstanceType %q", cfg.Outpost.ControlPlaneInstanceType)
+	}
+	if !cfg.IsPrivateEndpointOnly() || cfg.Bastion == nil {
+		t.Fatalf("expected private-only endpoint with bastion (public %v, private %v)", cfg.EndpointPublicAccess, cfg.EndpointPrivateAccess)
+	}
+	if !reflect.DeepEqual(cfg.NodeGroupSubnetIDs(), []string{"subnet-op1"}) {
+		t.Fatalf("unexpected NodeGroupSubnetIDs %q", cfg.NodeGroupSubnetIDs())
+	}
+	for name, cur := range cfg.AddOnNodeGroups.ASGs {
+		if cur.VolumeType != OutpostVolumeType {
+			t.Fatalf("unexpected AddOnNodeGroups.ASGs[%q].VolumeType %q", name, cur.VolumeType)
+		}
+	}
+	found := false
+	for _, v := range cfg.Role.ManagedPolicyARNs {
+		if v == OutpostClusterPolicyARN {
+			found = true
+			break
+		}
+	}
+	if !found {
+		t.Fatalf("expected %q in Role.ManagedPolicyARNs %q", OutpostClusterPolicyARN, cfg.Role.ManagedPolicyARNs)
+	}
+	if cfg.TokenClusterID() != cfg.Name {
+		t.Fatalf("unexpected TokenClusterID %q before cluster creation", cfg.TokenClusterID())
+	}
+	cfg.Outpost.ClusterID = "b2b1b2c3-1234-5678-9abc-def012345678"
+	if cfg.TokenClusterID() != cfg.Outpost.ClusterID {
+		t.Fatalf("unexpected TokenClusterID %q", cfg.TokenClusterID())
+	}
+
+	cfg.AuthenticationMode = eks.AuthenticationModeApi
+	if err := cfg.ValidateAndSetDefaults(); err == nil {
+		t.Fatal("expected error for AuthenticationMode with Outpost")
+	}
+	cfg.AuthenticationMode = eks.AuthenticationModeConfigMap
+	cfg.AddOnManagedNodeGroups = getDefaultAddOnManagedNodeGroups(cfg.Name)
+	cfg.AddOnManagedNodeGroups.Enable = true
+	if err := cfg.ValidateAndSetDefaults(); err == nil {
+		t.Fatal("expected error for AddOnManagedNodeGroups with Outpost")
+	}
+
+	os.Setenv("AWS_K8S_TESTER_EKS_OUTPOST_CLUSTER_ID", "id")
+	defer os.Unsetenv("AWS_K8S_TESTER_EKS_OUTPOST_CLUSTER_ID")
+	if err := cfg.UpdateFromEnvs(); err == nil {
+		t.Fatal("expected error for read-only Outpost.ClusterID")
+	}
+}
+
+func TestLoadFleet(t *testing.T) {
+	dir, err := ioutil.TempDir(os.TempDir(), "eksconfig-fleet")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	spec := NewDefault()
+	spec.Region = "us-east-1"
+	spec.KubectlCommandsOutputPath = ""
+	spec.RemoteAccessCommandsOutputPath = ""
+	if err = spec.WriteSpec(filepath.Join(dir, "spec.yaml")); err != nil {
+		t.Fatal(err)
+	}
+	manifestPath := filepath.Join(dir, "fleet.yaml")
+	if err = ioutil.WriteFile(manifestPath, []byte(`
+name: nightly
+clusters:
+- name: a
+  region: us-west-2
+  spec: spec.yaml
+  add-ons: [jobs-pi]
+- name: b
+  spec: spec.yaml
+`), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := LoadFleet(manifestPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.Parallelism != 2 {
+		t.Fatalf("unexpected Parallelism %d", f.Parallelism)
+	}
+	if f.ResultsPath != filepath.Join(dir, "fleet.results.yaml") {
+		t.Fatalf("unexpected ResultsPath %q", f.ResultsPath)
+	}
+
+	os.Setenv("AWS_K8S_TESTER_EKS_NAME", "env-name")
+	defer os.Unsetenv("AWS_K8S_TESTER_EKS_NAME")
+	os.Setenv("AWS_K8S_TESTER_EKS_LOG_LEVEL", "debug")
+	defer os.Unsetenv("AWS_K8S_TESTER_EKS_LOG_LEVEL")
+	a, err := f.Config(0, (*Config).UpdateFromEnvs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := f.Config(1, (*Config).UpdateFromEnvs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.Name != "a" || b.Name != "b" {
+		t.Fatalf("unexpected names %q, %q", a.Name, b.Name)
+	}
+	if a.Region != "us-west-2" || b.Region != "us-east-1" {
+		t.Fatalf("unexpected regions %q, %q", a.Region, b.Region)
+	}
+	if a.LogLevel != "debug" {
+		t.Fatalf("expected env to override spec, got LogLevel %q", a.LogLevel)
+	}
+	if a.ConfigPath != filepath.Join(dir, "a.state.yaml") || b.ConfigPath != filepath.Join(dir, "b.state.yaml") {
+		t.Fatalf("unexpected state paths %q, %q", a.ConfigPath, b.ConfigPath)
+	}
+	if !a.IsEnabledAddOnJobsPi() || b.IsEnabledAddOnJobsPi() {
+		t.Fatal("expected AddOnJobsPi only in cluster 'a'")
+	}
+
+	rs := []FleetResult{{Name: "b", Took: time.Minute}, {Name: "a", Success: true}}
+	if err = f.WriteResults(rs); err != nil {
+		t.Fatal(err)
+	}
+	if rs[0].Name != "b" {
+		t.Fatal("WriteResults must not reorder the results")
+	}
+	d, err := ioutil.ReadFile(f.ResultsPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(d), "- config-path: \"\"\n  name: a\n") || !strings.Contains(string(d), "took-string: 1m0s") {
+		t.Fatalf("unexpected results %s", string(d))
+	}
+
+	for _, manifest := range []string{
+		"name: nightly\nclusters: []\n",
+		"name: nightly\nclusters:\n- name: a\n- name: a\n",
+		"name: nightly\nclusters:\n- name: a\n  add-ons: [kubeflow]\n",
+		"name: nightly\nclusters:\n- name: a\n  regions: us-west-2\n",
+	} {
+		if err = ioutil.WriteFile(manifestPath, []byte(manifest), 0600); err != nil {
+			t.Fatal(err)
+		}
+		if _, err = LoadFleet(manifestPath); err == nil {
+			t.Fatalf("expected error for %q", manifest)
+		}
+	}
+}
+
+func TestEnvAttach(t *testing.T) {
+	cfg := NewDefault()
+	defer func() {
+		os.RemoveAll(cfg.ConfigPath)
+		os.RemoveAll(cfg.KubectlCommandsOutputPath)
+		os.RemoveAll(cfg.RemoteAccessCommandsOutputPath)
+	}()
+
+	kubeconfig, err := ioutil.TempFile(os.TempDir(), "kubeconfig")
+	if err != nil {
+		t.Fatal(err)
+	}
+	kubeconfig.Close()
+	defer os.RemoveAll(kubeconfig.Name())
+
+	os.Setenv("AWS_K8S_TESTER_EKS_ATTACH_ENABLE", "true")
+	defer os.Unsetenv("AWS_K8S_TESTER_EKS_ATTACH_ENABLE")
+	os.Setenv("AWS_K8S_TESTER_EKS_ATTACH_KUBECONFIG_PATH", kubeconfig.Name())
+	defer os.Unsetenv("AWS_K8S_TESTER_EKS_ATTACH_KUBECONFIG_PATH")
+	os.Setenv("AWS_K8S_TESTER_EKS_ENDPOINT_PUBLIC_ACCESS", "false")
+	defer os.Unsetenv("AWS_K8S_TESTER_EKS_ENDPOINT_PUBLIC_ACCESS")
+	os.Setenv("AWS_K8S_TESTER_EKS_ENDPOINT_PRIVATE_ACCESS", "true")
+	defer os.Unsetenv("AWS_K8S_TESTER_EKS_ENDPOINT_PRIVATE_ACCESS")
+	os.Setenv("AWS_K8S_TESTER_EKS_ADD_ON_JOBS_PI_ENABLE", "true")
+	defer os.Unsetenv("AWS_K8S_TESTER_EKS_ADD_ON_JOBS_PI_ENABLE")
+
+	if err = cfg.UpdateFromEnvs(); err != nil {
+		t.Fatal(err)
+	}
+	if err = cfg.ValidateAndSetDefaults(); err != nil {
+		t.Fatal(err)
+	}
+	if !cfg.IsEnabledAttach() {
+		t.Fatal("expected Attach enabled")
+	}
+	if cfg.Role.Create || cfg.VPC.Create || cfg.Encryption.CMKCreate {
+		t.Fatalf("unexpected creation (role %v, VPC %v, CMK %v)", cfg.Role.Create, cfg.VPC.Create, cfg.Encryption.CMKCreate)
+	}
+	if cfg.KubeConfigPath != kubeconfig.Name() {
+		t.Fatalf("unexpected KubeConfigPath %q", cfg.KubeConfigPath)
+	}
+	if cfg.Bastion != nil {
+		t.Fatalf("unexpected Bastion %+v", cfg.Bastion)
+	}
+
+	cfg.AddOnClusterVersionUpgrade = &AddOnClusterVersionUpgrade{Enable: true}
+	if err = cfg.ValidateAndSetDefaults(); err == nil {
+		t.Fatal("expected error for AddOnClusterVersionUpgrade with Attach")
+	}
+	cfg.AddOnClusterVersionUpgrade = nil
+	cfg.Attach.KubeConfigPath = kubeconfig.Name() + "-not-exist"
+	if err = cfg.ValidateAndSetDefaults(); err == nil {
+		t.Fatal("expected error for non-existent Attach.KubeConfigPath")
+	}
+
+	os.Setenv("AWS_K8S_TESTER_EKS_ATTACH_CLUSTER_ARN", "arn")
+	defer os.Unsetenv("AWS_K8S_TESTER_EKS_ATTACH_CLUSTER_ARN")
+	if err = cfg.UpdateFromEnvs(); err == nil {
+		t.Fatal("expected error for read-only Attach.ClusterARN")
+	}
+}
+
+func TestEnvControlPlaneLogging(t *testing.T) {
+	cfg := NewDefault()
+	defer func() {
+		os.RemoveAll(cfg.ConfigPath)
+		os.RemoveAll(cfg.KubectlCommandsOutputPath)
+		os.RemoveAll(cfg.RemoteAccessCommandsOutputPath)
+	}()
+
+	os.Setenv("AWS_K8S_TESTER_EKS_CONTROL_PLANE_LOGGING_ENABLE", "true")
+	defer os.Unsetenv("AWS_K8S_TESTER_EKS_CONTROL_PLANE_LOGGING_ENABLE")
+	os.Setenv("AWS_K8S_TESTER_EKS_CONTROL_PLANE_LOGGING_VERIFY_TIMEOUT", "7m")
+	defer os.Unsetenv("AWS_K8S_TESTER_EKS_CONTROL_PLANE_LOGGING_VERIFY_TIMEOUT")
+
+	if err := cfg.UpdateFromEnvs(); err != nil {
+		t.Fatal(err)
+	}
+	if err := cfg.ValidateAndSetDefaults(); err != nil {
+		t.Fatal(err)
+	}
+	if !cfg.IsEnabledControlPlaneLogging() {
+		t.Fatal("expected ControlPlaneLogging enabled")
+	}
+	if !reflect.DeepEqual(cfg.ControlPlaneLogging.LogTypes, []string{"api", "audit", "authenticator", "controllerManager", "scheduler"}) {
+		t.Fatalf("unexpected ControlPlaneLogging.LogTypes %q", cfg.ControlPlaneLogging.LogTypes)
+	}
+	if !reflect.DeepEqual(cfg.ControlPlaneLogging.VerifyLogTypes, []string{"api", "audit"}) {
+		t.Fatalf("unexpected ControlPlaneLogging.VerifyLogTypes %q", cfg.ControlPlaneLogging.VerifyLogTypes)
+	}
+	if cfg.ControlPlaneLogging.VerifyTimeout != 7*time.Minute {
+		t.Fatalf("unexpected ControlPlaneLogging.VerifyTimeout %v", cfg.ControlPlaneLogging.VerifyTimeout)
+	}
+	if cfg.ControlPlaneLogging.LogGroupName != "/aws/eks/"+cfg.Name+"/cluster" {
+		t.Fatalf("unexpected ControlPlaneLogging.LogGroupName %q", cfg.ControlPlaneLogging.LogGroupName)
+	}
+
+	cfg.ControlPlaneLogging.LogTypes = []string{"api", "scheduler"}
+	if err := cfg.ValidateAndSetDefaults(); err == nil {
+		t.Fatal("expected error for VerifyLogTypes not in LogTypes")
+	}
+	cfg.ControlPlaneLogging.LogTypes = []string{"api", "audit", "kubelet"}
+	if err := cfg.ValidateAndSetDefaults(); err == nil {
+		t.Fatal("expected error for unknown log type")
+	}
+}
+
+func TestEnvKMSRotation(t *testing.T) {
+	cfg := NewDefault()
+	defer func() {
+		os.RemoveAll(cfg.ConfigPath)
+		os.RemoveAll(cfg.KubectlCommandsOutputPath)
+		os.RemoveAll(cfg.RemoteAccessCommandsOutputPath)
+	}()
+
+	os.Setenv("AWS_K8S_TESTER_EKS_KMS_ROTATION_ENABLE", "true")
+	defer os.Unsetenv("AWS_K8S_TESTER_EKS_KMS_ROTATION_ENABLE")
+	os.Setenv("AWS_K8S_TESTER_EKS_KMS_ROTATION_OBJECTS", "3")
+	defer os.Unsetenv("AWS_K8S_TESTER_EKS_KMS_ROTATION_OBJECTS")
+
+	if err := cfg.UpdateFromEnvs(); err != nil {
+		t.Fatal(err)
+	}
+	if err := cfg.ValidateAndSetDefaults(); err != nil {
+		t.Fatal(err)
+	}
+	if !cfg.IsEnabledKMSRotation() {
+		t.Fatal("expected KMSRotation enabled")
+	}
+	if cfg.KMSRotation.Objects != 3 {
+		t.Fatalf("unexpected KMSRotation.Objects %d", cfg.KMSRotation.Objects)
+	}
+	if cfg.KMSRotation.Namespace != cfg.Name+"-kms-rotation" {
+		t.Fatalf("unexpected KMSRotation.Namespace %q", cfg.KMSRotation.Namespace)
+	}
+
+	cfg.Encryption.CMKCreate = false
+	cfg.Encryption.CMKARN = ""
+	if err := cfg.ValidateAndSetDefaults(); err == nil {
+		t.Fatal("expected error for no encryption key")
+	}
+	cfg.Encryption.CMKARN = "arn:aws:kms:us-west-2:123:key/abc"
+	cfg.KMSRotation.Objects = 0
+	if err := cfg.ValidateAndSetDefaults(); err == nil {
+		t.Fatal("expected error for zero objects")
+	}
+}
+
+func TestEnvCNICustomNetworking(t *testing.T) {
+	cfg := NewDefault()
+	defer func() {
+		os.RemoveAll(cfg.ConfigPath)
+		os.RemoveAll(cfg.KubectlCommandsOutputPath)
+		os.RemoveAll(cfg.RemoteAccessCommandsOutputPath)
+	}()
+
+	os.Setenv("AWS_K8S_TESTER_EKS_CNI_CUSTOM_NETWORKING_ENABLE", "true")
+	defer os.Unsetenv("AWS_K8S_TESTER_EKS_CNI_CUSTOM_NETWORKING_ENABLE")
+	os.Setenv("AWS_K8S_TESTER_EKS_CNI_CUSTOM_NETWORKING_SECONDARY_CIDR", "100.65.0.0/16")
+	defer os.Unsetenv("AWS_K8S_TESTER_EKS_CNI_CUSTOM_NETWORKING_SECONDARY_CIDR")
+	os.Setenv("AWS_K8S_TESTER_EKS_CNI_CUSTOM_NETWORKING_POD_SUBNET_CIDRS", "100.65.0.0/18,100.65.64.0/18,100.65.128.0/18")
+	defer os.Unsetenv("AWS_K8S_TESTER_EKS_CNI_CUSTOM_NETWORKING_POD_SUBNET_CIDRS")
+	os.Setenv("AWS_K8S_TESTER_EKS_ADD_ON_MANAGED_NODE_GROUPS_ENABLE", "true")
+	defer os.Unsetenv("AWS_K8S_TESTER_EKS_ADD_ON_MANAGED_NODE_GROUPS_ENABLE")
+
+	if err := cfg.UpdateFromEnvs(); err != nil {
+		t.Fatal(err)
+	}
+	if err := cfg.ValidateAndSetDefaults(); err != nil {
+		t.Fatal(err)
+	}
+	if !cfg.IsEnabledCNICustomNetworking() {
+		t.Fatal("expected CNICustomNetworking enabled")
+	}
+	if cfg.CNICustomNetworking.SecondaryCIDR != "100.65.0.0/16" {
+		t.Fatalf("unexpected CNICustomNetworking.SecondaryCIDR %q", cfg.CNICustomNetworking.SecondaryCIDR)
+	}
+	if !reflect.DeepEqual(cfg.CNICustomNetworking.PodSubnetCIDRs, []string{"100.65.0.0/18", "100.65.64.0/18", "100.65.128.0/18"}) {
+		t.Fatalf("unexpected CNICustomNetworking.PodSubnetCIDRs %q", cfg.CNICustomNetworking.PodSubnetCIDRs)
+	}
+	if cfg.CNICustomNetworking.Namespace != cfg.Name+"-cni-custom-networking" {
+		t.Fatalf("unexpected CNICustomNetworking.Namespace %q", cfg.CNICustomNetworking.Namespace)
+	}
+
+	cfg.CNICustomNetworking.PodSubnetCIDRs = []string{"100.65.0.0/18", "100.66.64.0/18", "100.65.128.0/18"}
+	if err := cfg.ValidateAndSetDefaults(); err == nil {
+		t.Fatal("expected error for pod subnet not within secondary CIDR")
+	}
+	cfg.CNICustomNetworking.PodSubnetCIDRs = []string{"100.65.0.0/18", "100.65.64.0/18"}
+	if err := cfg.ValidateAndSetDefaults(); err == nil {
+		t.Fatal("expected error for pod subnets fewer than public subnets")
+	}
+	cfg.CNICustomNetworking.PodSubnetCIDRs = []string{"10.0.0.0/18", "10.0.64.0/18", "10.0.128.0/18"}
+	cfg.CNICustomNetworking.SecondaryCIDR = "10.0.0.0/16"
+	if err := cfg.ValidateAndSetDefaults(); err == nil {
+		t.Fatal("expected error for secondary CIDR overlapping with VPC CIDRs")
+	}
+}
+
+func TestEnvSecurityGroupsForPods(t *testing.T) {
+	cfg := NewDefault()
+	defer func() {
+		os.RemoveAll(cfg.ConfigPath)
+		os.RemoveAll(cfg.KubectlCommandsOutputPath)
+		os.RemoveAll(cfg.RemoteAccessCommandsOutputPath)
+	}()
+
+	os.Setenv("AWS_K8S_TESTER_EKS_SECURITY_GROUPS_FOR_PODS_ENABLE", "true")
+	defer os.Unsetenv("AWS_K8S_TESTER_EKS_SECURITY_GROUPS_FOR_PODS_ENABLE")
+	os.Setenv("AWS_K8S_TESTER_EKS_SECURITY_GROUPS_FOR_PODS_INSTANCE_TYPES", "c5.xlarge,m5.xlarge")
+	defer os.Unsetenv("AWS_K8S_TESTER_EKS_SECURITY_GROUPS_FOR_PODS_INSTANCE_TYPES")
+	os.Setenv("AWS_K8S_TESTER_EKS_ADD_ON_MANAGED_NODE_GROUPS_ENABLE", "true")
+	defer os.Unsetenv("AWS_K8S_TESTER_EKS_ADD_ON_MANAGED_NODE_GROUPS_ENABLE")
+
+	if err := cfg.UpdateFromEnvs(); err != nil {
+		t.Fatal(err)
+	}
+	if err := cfg.ValidateAndSetDefaults(); err != nil {
+		t.Fatal(err)
+	}
+	if !cfg.IsEnabledSecurityGroupsForPods() {
+		t.Fatal("expected SecurityGroupsForPods enabled")
+	}
+	if !reflect.DeepEqual(cfg.SecurityGroupsForPods.InstanceTypes, []string{"c5.xlarge", "m5.xlarge"}) {
+		t.Fatalf("unexpected SecurityGroupsForPods.InstanceTypes %q", cfg.SecurityGroupsForPods.InstanceTypes)
+	}
+	if cfg.SecurityGroupsForPods.Namespace != cfg.Name+"-security-groups-for-pods" {
+		t.Fatalf("unexpected SecurityGroupsForPods.Namespace %q", cfg.SecurityGroupsForPods.Namespace)
+	}
+	found := false
+	for _, v := range cfg.Role.ManagedPolicyARNs {
+		if v == SecurityGroupsForPodsPolicyARN {
+			found = true
+			break
+		}
+	}
+	if !found {
+		t.Fatalf("expected %q in Role.ManagedPolicyARNs, got %q", SecurityGroupsForPodsPolicyARN, cfg.Role.ManagedPolicyARNs)
+	}
+
+	for k, cur := range cfg.AddOnManagedNodeGroups.MNGs {
+		cur.InstanceTypes = []string{"t3.large"}
+		cfg.AddOnManagedNodeGroups.MNGs[k] = cur
+	}
+	if err := cfg.ValidateAndSetDefaults(); err == nil {
+		t.Fatal("expected error for managed node groups without trunk ENI instance types")
+	}
+}
+
+func TestEnvEndpointPublicAccessCheck(t *testing.T) {
+	cfg := NewDefault()
+	defer func() {
+		os.RemoveAll(cfg.ConfigPath)
+		os.RemoveAll(cfg.KubectlCommandsOutputPath)
+		os.RemoveAll(cfg.RemoteAccessCommandsOutputPath)
+	}()
+
+	os.Setenv("AWS_K8S_TESTER_EKS_ENDPOINT_PUBLIC_ACCESS_CIDRS", "203.0.113.0/24,198.51.100.7/32")
+	defer os.Unsetenv("AWS_K8S_TESTER_EKS_ENDPOINT_PUBLIC_ACCESS_CIDRS")
+	os.Setenv("AWS_K8S_TESTER_EKS_ENDPOINT_PUBLIC_ACCESS_CHECK_ENABLE", "true")
+	defer os.Unsetenv("AWS_K8S_TESTER_EKS_ENDPOINT_PUBLIC_ACCESS_CHECK_ENABLE")
+	os.Setenv("AWS_K8S_TESTER_EKS_ENDPOINT_PUBLIC_ACCESS_CHECK_ADD_RUNNER_CIDR", "false")
+	defer os.Unsetenv("AWS_K8S_TESTER_EKS_ENDPOINT_PUBLIC_ACCESS_CHECK_ADD_RUNNER_CIDR")
+	os.Setenv("AWS_K8S_TESTER_EKS_ENDPOINT_PUBLIC_ACCESS_CHECK_PROBE_RUNTIME", "python3.11")
+	defer os.Unsetenv("AWS_K8S_TESTER_EKS_ENDPOINT_PUBLIC_ACCESS_CHECK_PROBE_RUNTIME")
+
+	if err := cfg.UpdateFromEnvs(); err != nil {
+		t.Fatal(err)
+	}
+	if err := cfg.ValidateAndSetDefaults(); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(cfg.EndpointPublicAccessCIDRs, []string{"203.0.113.0/24", "198.51.100.7/32"}) {
+		t.Fatalf("unexpected EndpointPublicAccessCIDRs %q", cfg.EndpointPublicAccessCIDRs)
+	}
+	if !cfg.IsEnabledEndpointPublicAccessCheck() {
+		t.Fatal("expected EndpointPublicAccessCheck enabled")
+	}
+	if cfg.EndpointPublicAccessCheck.AddRunnerCIDR {
+		t.Fatal("unexpected EndpointPublicAccessCheck.AddRunnerCIDR true")
+	}
+	if cfg.EndpointPublicAccessCheck.ProbeRuntime != "python3.11" {
+		t.Fatalf("unexpected EndpointPublicAccessCheck.ProbeRuntime %q", cfg.EndpointPublicAccessCheck.ProbeRuntime)
+	}
+	if cfg.EndpointPublicAccessCheck.ProbeFunctionName != cfg.Name+"-endpoint-probe" {
+		t.Fatalf("unexpected EndpointPublicAccessCheck.ProbeFunctionName %q", cfg.EndpointPublicAccessCheck.ProbeFunctionName)
+	}
+
+	cfg.AddOnManagedNodeGroups = getDefaultAddOnManagedNodeGroups(cfg.Name)
+	cfg.AddOnManagedNodeGroups.Enable = true
+	if err := cfg.ValidateAndSetDefaults(); err == nil {
+		t.Fatal("expected error for restricted public endpoint with node groups but no private endpoint")
+	}
+	cfg.EndpointPrivateAccess = true
+	if err := cfg.ValidateAndSetDefaults(); err != nil {
+		t.Fatal(err)
+	}
+	cfg.EndpointPublicAccessCIDRs = []string{"0.0.0.0/0"}
+	if err := cfg.ValidateAndSetDefaults(); err == nil {
+		t.Fatal("expected error for unrestricted public endpoint")
+	}
+	cfg.EndpointPublicAccessCIDRs = []string{"203.0.113.0"}
+	if err := cfg.ValidateAndSetDefaults(); err == nil {
+		t.Fatal("expected error for invalid CIDR")
+	}
+}
+
+func TestEnvAddOnMNGScale(t *testing.T) {
+	cfg := NewDefault()
+	defer func() {
+		os.RemoveAll(cfg.ConfigPath)
+		os.RemoveAll(cfg.KubectlCommandsOutputPath)
+		os.RemoveAll(cfg.RemoteAccessCommandsOutputPath)
+	}()
+
+	os.Setenv("AWS_K8S_TESTER_EKS_ADD_ON_MANAGED_NODE_GROUPS_ENABLE", "true")
+	defer os.Unsetenv("AWS_K8S_TESTER_EKS_ADD_ON_MANAGED_NODE_GROUPS_ENABLE")
+	os.Setenv("AWS_K8S_TESTER_EKS_ADD_ON_MANAGED_NODE_GROUPS_ROLE_CREATE", "true")
+	defer os.Unsetenv("AWS_K8S_TESTER_EKS_ADD_ON_MANAGED_NODE_GROUPS_ROLE_CREATE")
+	os.Setenv("AWS_K8S_TESTER_EKS_ADD_ON_MANAGED_NODE_GROUPS_MNGS", `{"mng-a":{"name":"mng-a","ami-type":"AL2_x86_64","asg-min-size":1,"asg-max-size":3,"asg-desired-capacity":1},"mng-b":{"name":"mng-b","ami-type":"AL2_x86_64","asg-min-size":1,"asg-max-size":1,"asg-desired-capacity":1}}`)
+	defer os.Unsetenv("AWS_K8S_TESTER_EKS_ADD_ON_MANAGED_NODE_GROUPS_MNGS")
+	os.Setenv("AWS_K8S_TESTER_EKS_ADD_ON_MNG_SCALE_ENABLE", "true")
+	defer os.Unsetenv("AWS_K8S_TESTER_EKS_ADD_ON_MNG_SCALE_ENABLE")
+	os.Setenv("AWS_K8S_TESTER_EKS_ADD_ON_MNG_SCALE_TIMEOUT_STRING", "20m")
+	defer os.Unsetenv("AWS_K8S_TESTER_EKS_ADD_ON_MNG_SCALE_TIMEOUT_STRING")
+
+	if err := cfg.UpdateFromEnvs(); err != nil {
+		t.Fatal(err)
+	}
+	if err := cfg.ValidateAndSetDefaults(); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.AddOnMNGScale.MNGName != "mng-a" {
+		t.Fatalf("unexpected AddOnMNGScale.MNGName %q", cfg.AddOnMNGScale.MNGName)
+	}
+	if cfg.AddOnMNGScale.Timeout != 20*time.Minute {
+		t.Fatalf("unexpected AddOnMNGScale.Timeout %v", cfg.AddOnMNGScale.Timeout)
+	}
+	if cfg.AddOnMNGScale.RequestsSummaryScaleOutJSONPath == "" || cfg.AddOnMNGScale.RequestsSummaryScaleInTablePath == "" {
+		t.Fatalf("unexpected empty AddOnMNGScale summary paths %+v", cfg.AddOnMNGScale)
+	}
+
+	cfg.AddOnMNGScale.MNGName = "mng-b"
+	if err := cfg.ValidateAndSetDefaults(); err == nil {
+		t.Fatal("expected error for AddOnMNGScale with ASGMaxSize == ASGMinSize")
+	}
+	cfg.AddOnMNGScale.MNGName = "mng-a"
+	cfg.AddOnMNGScale.TimeoutString = "1m"
+	if err := cfg.ValidateAndSetDefaults(); err == nil {
+		t.Fatal("expected error for AddOnMNGScale.Timeout too short")
+	}
+}
+
+func TestEnvAddOnMNGRollingUpgrade(t *testing.T) {
+	cfg := NewDefault()
+	defer func() {
+		os.RemoveAll(cfg.ConfigPath)
+		os.RemoveAll(cfg.KubectlCommandsOutputPath)
+		os.RemoveAll(cfg.RemoteAccessCommandsOutputPath)
+	}()
+
+	os.Setenv("AWS_K8S_TESTER_EKS_ADD_ON_MANAGED_NODE_GROUPS_ENABLE", "true")
+	defer os.Unsetenv("AWS_K8S_TESTER_EKS_ADD_ON_MANAGED_NODE_GROUPS_ENABLE")
+	os.Setenv("AWS_K8S_TESTER_EKS_ADD_ON_MANAGED_NODE_GROUPS_ROLE_CREATE", "true")
+	defer os.Unsetenv("AWS_K8S_TESTER_EKS_ADD_ON_MANAGED_NODE_GROUPS_ROLE_CREATE")
+	os.Setenv("AWS_K8S_TESTER_EKS_ADD_ON_MANAGED_NODE_GROUPS_MNGS", `{"mng-a":{"name":"mng-a","ami-type":"AL2_x86_64","asg-min-size":2,"asg-max-size":2,"asg-desired-capacity":2,"launch-template":{"enable":true,"image-id":"ami-0123456789abcdef0"}},"mng-b":{"name":"mng-b","ami-type":"AL2_x86_64","asg-min-size":2,"asg-max-size":2,"asg-desired-capacity":2}}`)
+	defer os.Unsetenv("AWS_K8S_TESTER_EKS_ADD_ON_MANAGED_NODE_GROUPS_MNGS")
+	os.Setenv("AWS_K8S_TESTER_EKS_ADD_ON_MNG_ROLLING_UPGRADE_ENABLE", "true")
+	defer os.Unsetenv("AWS_K8S_TESTER_EKS_ADD_ON_MNG_ROLLING_UPGRADE_ENABLE")
+	os.Setenv("AWS_K8S_TESTER_EKS_ADD_ON_MNG_ROLLING_UPGRADE_MAX_UNAVAILABLE", "2")
+	defer os.Unsetenv("AWS_K8S_TESTER_EKS_ADD_ON_MNG_ROLLING_UPGRADE_MAX_UNAVAILABLE")
+	os.Setenv("AWS_K8S_TESTER_EKS_ADD_ON_MNG_ROLLING_UPGRADE_DEPLOYMENT_REPLICAS", "4")
+	defer os.Unsetenv("AWS_K8S_TESTER_EKS_ADD_ON_MNG_ROLLING_UPGRADE_DEPLOYMENT_REPLICAS")
+
+	if err := cfg.UpdateFromEnvs(); err != nil {
+		t.Fatal(err)
+	}
+	if err := cfg.ValidateAndSetDefaults(); err != nil {
+		t.Fatal(err)
+	}
+	// "mng-a" uses custom AMI
+	if cfg.AddOnMNGRollingUpgrade.MNGName != "mng-b" {
+		t.Fatalf("unexpected AddOnMNGRollingUpgrade.MNGName %q", cfg.AddOnMNGRollingUpgrade.MNGName)
+	}
+	if cfg.AddOnMNGRollingUpgrade.MaxUnavailable != 2 {
+		t.Fatalf("unexpected AddOnMNGRollingUpgrade.MaxUnavailable %d", cfg.AddOnMNGRollingUpgrade.MaxUnavailable)
+	}
+	if cfg.AddOnMNGRollingUpgrade.DeploymentReplicas != 4 || cfg.AddOnMNGRollingUpgrade.PDBMaxUnavailable != 1 {
+		t.Fatalf("unexpected AddOnMNGRollingUpgrade %+v", cfg.AddOnMNGRollingUpgrade)
+	}
+	if cfg.AddOnMNGRollingUpgrade.Namespace != cfg.Name+"-mng-rolling-upgrade" {
+		t.Fatalf("unexpected AddOnMNGRollingUpgrade.Namespace %q", cfg.AddOnMNGRollingUpgrade.Namespace)
+	}
+	if cfg.AddOnMNGRollingUpgrade.Timeout != time.Hour {
+		t.Fatalf("unexpected AddOnMNGRollingUpgrade.Timeout %v", cfg.AddOnMNGRollingUpgrade.Timeout)
+	}
+	if cfg.AddOnMNGRollingUpgrade.RequestsSummaryReplaceJSONPath == "" || cfg.AddOnMNGRollingUpgrade.RequestsSummaryReplaceTablePath == "" {
+		t.Fatalf("unexpected empty AddOnMNGRollingUpgrade summary paths %+v", cfg.AddOnMNGRollingUpgrade)
+	}
+
+	cfg.AddOnMNGRollingUpgrade.MNGName = "mng-a"
+	err := cfg.ValidateAndSetDefaults()
+	if err == nil || !strings.Contains(err.Error(), "uses custom AMI") {
+		t.Fatalf("expected custom AMI error, got %v", err)
+	}
+	cfg.AddOnMNGRollingUpgrade.MNGName = "mng-b"
+	cfg.AddOnMNGRollingUpgrade.PDBMaxUnavailable = 4
+	err = cfg.ValidateAndSetDefaults()
+	if err == nil || !strings.Contains(err.Error(), "PDBMaxUnavailable") {
+		t.Fatalf("expected PDBMaxUnavailable error, got %v", err)
+	}
+	cfg.AddOnMNGRollingUpgrade.PDBMaxUnavailable = 1
+	cfg.AddOnMNGRollingUpgrade.MaxUnavailable = 0
+	err = cfg.ValidateAndSetDefaults()
+	if err == nil || !strings.Contains(err.Error(), "MaxUnavailable") {
+		t.Fatalf("expected MaxUnavailable error, got %v", err)
+	}
+	cfg.AddOnMNGRollingUpgrade.MaxUnavailable = 1
+	cfg.AddOnMNGRollingUpgrade.TimeoutString = "5m"
+	if err := cfg.ValidateAndSetDefaults(); err == nil {
+		t.Fatal("expected error for AddOnMNGRollingUpgrade.Timeout too short")
+	}
+}
@@ -0,0 +1,240 @@
+package eksconfig
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-k8s-tester/pkg/fileutil"
+	"sigs.k8s.io/yaml" // must use "sigs.k8s.io/yaml"
+)
+
+// Fleet defines a set of clusters, possibly across regions, to be
+// created and deleted concurrently from one manifest file
+// (e.g. "aws-k8s-tester eks create fleet --manifest fleet.yaml").
+// Each cluster is loaded from its own spec file (see "LoadSpec"),
+// or the default configuration if empty, with the name, the region,
+// and the add-ons of the manifest applied on top.
+//
+// Example manifest:
+//
+//	name: nightly
+//	parallelism: 2
+//	clusters:
+//	- name: nightly-us-west-2
+//	  region: us-west-2
+//	  spec: us-west-2.yaml
+//	  add-ons: [conformance]
+//	- name: nightly-us-east-1
+//	  region: us-east-1
+type Fleet struct {
+	// Name is the name of the fleet.
+	Name string `json:"name"`
+	// Parallelism is the maximum number of clusters
+	// to create or delete concurrently.
+	Parallelism int `json:"parallelism"`
+	// ResultsPath is the file path to write the aggregated results.
+	// Defaults to the manifest path with ".results.yaml" suffix.
+	ResultsPath string `json:"results-path"`
+	// Clusters is the list of the clusters in the fleet.
+	Clusters []FleetCluster `json:"clusters"`
+
+	// manifestPath is the absolute path of the manifest file,
+	// to resolve the relative spec paths and the state paths.
+	manifestPath string
+}
+
+// FleetCluster defines a cluster in the fleet.
+type FleetCluster struct {
+	// Name is the cluster name, which must be unique in the fleet.
+	Name string `json:"name"`
+	// Region is the AWS region of the cluster.
+	// Defaults to the region of the spec.
+	Region string `json:"region,omitempty"`
+	// Spec is the YAML spec file path of the cluster, relative to
+	// the manifest file. If empty, the default configuration is used.
+	Spec string `json:"spec,omitempty"`
+	// AddOns is the list of the add-ons to enable on top of the spec
+	// (see "AddOnNames").
+	AddOns []string `json:"add-ons,omitempty"`
+}
+
+// FleetResult is the result of the operation on a cluster in the fleet.
+type FleetResult struct {
+	// Name is the cluster name.
+	Name string `json:"name"`
+	// Region is the AWS region of the cluster.
+	Region string `json:"region"`
+	// ConfigPath is the state file path of the cluster.
+	ConfigPath string `json:"config-path"`
+	// Success is true if the operation succeeded.
+	Success bool `json:"success"`
+	// Error is the error message if the operation failed.
+	Error string `json:"error,omitempty"`
+	// Took is the duration of the operation.
+	Took       time.Duration `json:"took"`
+	TookString string        `json:"took-string"`
+}
+
+// DefaultFleetParallelism is the default maximum number of clusters
+// to create or delete concurrently.
+const DefaultFleetParallelism = 3
+
+// fleetUnsupportedAddOns is the list of the add-ons that change
+// the process-wide states (e.g. working directory, environment variables),
+// which cannot be shared by the clusters in the same process.
+var fleetUnsupportedAddOns = []string{"kubeflow"}
+
+var fleetClusterNameRegex = regexp.MustCompile(`^[0-9A-Za-z][A-Za-z0-9\-_]*$`)
+
+// LoadFleet loads the fleet manifest file and validates it.
+func LoadFleet(p string) (f *Fleet, err error) {
+	var d []byte
+	d, err = ioutil.ReadFile(p)
+	if err != nil {
+		return nil, err
+	}
+	f = new(Fleet)
+	if err = yaml.Unmarshal(d, f, yaml.DisallowUnknownFields); err != nil {
+		return nil, fmt.Errorf("failed to parse fleet manifest %q (%v)", p, err)
+	}
+	f.manifestPath, err = filepath.Abs(p)
+	if err != nil {
+		return nil, err
+	}
+	if err = f.validate(); err != nil {
+		return nil, fmt.Errorf("invalid fleet manifest %q (%v)", p, err)
+	}
+	return f, nil
+}
+
+func (f *Fleet) validate() error {
+	if f.Name == "" {
+		return errors.New("empty Name")
+	}
+	if len(f.Clusters) == 0 {
+		return errors.New("empty Clusters")
+	}
+	names := make(map[string]struct{}, len(f.Clusters))
+	for i, cur := range f.Clusters {
+		if !fleetClusterNameRegex.MatchString(cur.Name) {
+			return fmt.Errorf("Clusters[%d] invalid Name %q", i, cur.Name)
+		}
+		if _, ok := names[cur.Name]; ok {
+			return fmt.Errorf("Clusters[%d] duplicate Name %q", i, cur.Name)
+		}
+		names[cur.Name] = struct{}{}
+		for _, name := range cur.AddOns {
+			for _, v := range fleetUnsupportedAddOns {
+				if name == v {
+					return fmt.Errorf("Clusters[%d] add-on %q not supported in a fleet", i, name)
+				}
+			}
+		}
+	}
+	if f.Parallelism <= 0 {
+		f.Parallelism = DefaultFleetParallelism
+	}
+	if f.Parallelism > len(f.Clusters) {
+		f.Parallelism = len(f.Clusters)
+	}
+	if f.ResultsPath == "" {
+		f.ResultsPath = strings.TrimSuffix(f.manifestPath, filepath.Ext(f.manifestPath)) + ".results.yaml"
+	}
+	return nil
+}
+
+// StatePath returns the state file path of the i-th cluster,
+// next to the manifest file, so that clusters sharing a spec
+// do not overwrite each other's states.
+func (f *Fleet) StatePath(i int) string {
+	return filepath.Join(filepath.Dir(f.manifestPath), f.Clusters[i].Name+".state.yaml")
+}
+
+// Config returns the configuration of the i-th cluster, resumed from
+// its previous states if any. "update" (e.g. "UpdateFromEnvs") is applied
+// on top of the spec, before the name, the region, and the add-ons
+// of the manifest. "ValidateAndSetDefaults" is not called.
+func (f *Fleet) Config(i int, update func(*Config) error) (cfg *Config, err error) {
+	cur := f.Clusters[i]
+	if cur.Spec != "" {
+		p := cur.Spec
+		if !filepath.IsAbs(p) {
+			p = filepath.Join(filepath.Dir(f.manifestPath), p)
+		}
+		cfg, err = LoadSpec(p)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		cfg = NewDefault()
+		// node group names are derived from the cluster name
+		cfg.Name = cur.Name
+		cfg.AddOnNodeGroups = getDefaultAddOnNodeGroups(cur.Name)
+		cfg.AddOnManagedNodeGroups = getDefaultAddOnManagedNodeGroups(cur.Name)
+	}
+	if update != nil {
+		if err = update(cfg); err != nil {
+			return nil, err
+		}
+	}
+
+	cfg.Name = cur.Name
+	if cur.Region != "" {
+		cfg.Region = cur.Region
+	}
+	cfg.ConfigPath = f.StatePath(i)
+	// derived from "ConfigPath" in "ValidateAndSetDefaults", and
+	// only logged to the file since the clusters run concurrently
+	cfg.KubeConfigPath = ""
+	cfg.KubectlCommandsOutputPath = ""
+	cfg.RemoteAccessCommandsOutputPath = ""
+	cfg.LogOutputs = []string{strings.TrimSuffix(cfg.ConfigPath, ".yaml") + ".log"}
+	for _, name := range cur.AddOns {
+		if err = cfg.enableAddOn(name); err != nil {
+			return nil, fmt.Errorf("cluster %q (%v)", cur.Name, err)
+		}
+	}
+	for _, name := range fleetUnsupportedAddOns {
+		if cfg.isEnabledAddOnName(name) {
+			return nil, fmt.Errorf("cluster %q add-on %q not supported in a fleet", cur.Name, name)
+		}
+	}
+
+	if fileutil.Exist(cfg.ConfigPath) {
+		// resume from the previous states (e.g. delete after create)
+		prev, perr := Load(cfg.ConfigPath)
+		if perr != nil {
+			return nil, fmt.Errorf("failed to load states %q (%v)", cfg.ConfigPath, perr)
+		}
+		cfg.Status = prev.Status
+	}
+	if cfg.mu == nil {
+		cfg.mu = new(sync.RWMutex)
+	}
+	return cfg, nil
+}
+
+// WriteResults writes the results sorted by the cluster name to "ResultsPath".
+func (f *Fleet) WriteResults(results []FleetResult) error {
+	rs := make([]FleetResult, len(results))
+	copy(rs, results)
+	sort.Slice(rs, func(i, j int) bool { return rs[i].Name < rs[j].Name })
+	for i := range rs {
+		rs[i].TookString = rs[i].Took.String()
+	}
+	d, err := yaml.Marshal(rs)
+	if err != nil {
+		return fmt.Errorf("failed to 'yaml.Marshal' %v", err)
+	}
+	if err = ioutil.WriteFile(f.ResultsPath, d, 0600); err != nil {
+		return fmt.Errorf("failed to write fleet results %q (%v)", f.ResultsPath, err)
+	}
+	return nil
+}
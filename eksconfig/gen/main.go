@@ -17,6 +17,13 @@ func main() {
 	if err := ioutil.WriteFile("eksconfig/README.md", []byte("\n```\n"+doc+"```\n"), 0666); err != nil {
 		panic(err)
 	}
+	schema, err := eksconfig.JSONSchema()
+	if err != nil {
+		panic(err)
+	}
+	if err = ioutil.WriteFile("eksconfig/schema.json", append(schema, '\n'), 0666); err != nil {
+		panic(err)
+	}
 	fmt.Println("generated")
 }
 
@@ -44,6 +51,74 @@ func createDoc() string {
 	b.WriteByte('\n')
 	b.WriteString(es.writeDoc(eksconfig.AWS_K8S_TESTER_EKS_VPC_PREFIX, &eksconfig.VPC{}))
 
+	b.WriteByte('\n')
+	b.WriteByte('\n')
+	b.WriteString(es.writeDoc(eksconfig.AWS_K8S_TESTER_EKS_BASTION_PREFIX, &eksconfig.Bastion{}))
+
+	b.WriteByte('\n')
+	b.WriteByte('\n')
+	b.WriteString(es.writeDoc(eksconfig.AWS_K8S_TESTER_EKS_LIVE_RELOAD_PREFIX, &eksconfig.LiveReload{}))
+
+	b.WriteByte('\n')
+	b.WriteByte('\n')
+	b.WriteString(es.writeDoc(eksconfig.AWS_K8S_TESTER_EKS_REGRESSION_PREFIX, &eksconfig.Regression{}))
+
+	b.WriteByte('\n')
+	b.WriteByte('\n')
+	b.WriteString(es.writeDoc(eksconfig.AWS_K8S_TESTER_EKS_PROMETHEUS_ENDPOINT_PREFIX, &eksconfig.PrometheusEndpoint{}))
+
+	b.WriteByte('\n')
+	b.WriteByte('\n')
+	b.WriteString(es.writeDoc(eksconfig.AWS_K8S_TESTER_EKS_CW_SUMMARIES_PREFIX, &eksconfig.CWSummaries{}))
+
+	b.WriteByte('\n')
+	b.WriteByte('\n')
+	b.WriteString(es.writeDoc(eksconfig.AWS_K8S_TESTER_EKS_LATENCY_HISTOGRAM_PREFIX, &eksconfig.LatencyHistogram{}))
+
+	b.WriteByte('\n')
+	b.WriteByte('\n')
+	b.WriteString(es.writeDoc(eksconfig.AWS_K8S_TESTER_EKS_OTLP_EXPORTER_PREFIX, &eksconfig.OTLPExporter{}))
+
+	b.WriteByte('\n')
+	b.WriteByte('\n')
+	b.WriteString(es.writeDoc(eksconfig.AWS_K8S_TESTER_EKS_AUTO_MODE_PREFIX, &eksconfig.AutoMode{}))
+
+	b.WriteByte('\n')
+	b.WriteByte('\n')
+	b.WriteString(es.writeDoc(eksconfig.AWS_K8S_TESTER_EKS_OIDC_PROVIDER_PREFIX, &eksconfig.OIDCProvider{}))
+
+	b.WriteByte('\n')
+	b.WriteByte('\n')
+	b.WriteString(es.writeDoc(eksconfig.AWS_K8S_TESTER_EKS_OUTPOST_PREFIX, &eksconfig.Outpost{}))
+
+	b.WriteByte('\n')
+	b.WriteByte('\n')
+	b.WriteString(es.writeDoc(eksconfig.AWS_K8S_TESTER_EKS_VERSION_SKEW_PREFIX, &eksconfig.VersionSkew{}))
+
+	b.WriteByte('\n')
+	b.WriteByte('\n')
+	b.WriteString(es.writeDoc(eksconfig.AWS_K8S_TESTER_EKS_ATTACH_PREFIX, &eksconfig.Attach{}))
+
+	b.WriteByte('\n')
+	b.WriteByte('\n')
+	b.WriteString(es.writeDoc(eksconfig.AWS_K8S_TESTER_EKS_CONTROL_PLANE_LOGGING_PREFIX, &eksconfig.ControlPlaneLogging{}))
+
+	b.WriteByte('\n')
+	b.WriteByte('\n')
+	b.WriteString(es.writeDoc(eksconfig.AWS_K8S_TESTER_EKS_KMS_ROTATION_PREFIX, &eksconfig.KMSRotation{}))
+
+	b.WriteByte('\n')
+	b.WriteByte('\n')
+	b.WriteString(es.writeDoc(eksconfig.AWS_K8S_TESTER_EKS_CNI_CUSTOM_NETWORKING_PREFIX, &eksconfig.CNICustomNetworking{}))
+
+	b.WriteByte('\n')
+	b.WriteByte('\n')
+	b.WriteString(es.writeDoc(eksconfig.AWS_K8S_TESTER_EKS_SECURITY_GROUPS_FOR_PODS_PREFIX, &eksconfig.SecurityGroupsForPods{}))
+
+	b.WriteByte('\n')
+	b.WriteByte('\n')
+	b.WriteString(es.writeDoc(eksconfig.AWS_K8S_TESTER_EKS_ENDPOINT_PUBLIC_ACCESS_CHECK_PREFIX, &eksconfig.EndpointPublicAccessCheck{}))
+
 	b.WriteByte('\n')
 	b.WriteByte('\n')
 	b.WriteString(es.writeDoc(eksconfig.AWS_K8S_TESTER_EKS_ADD_ON_CNI_VPC_PREFIX, &eksconfig.AddOnCNIVPC{}))
@@ -178,6 +253,34 @@ func createDoc() string {
 	b.WriteByte('\n')
 	b.WriteString(es.writeDoc(eksconfig.EnvironmentVariablePrefixAddOnCUDAVectorAdd, &eksconfig.AddOnCUDAVectorAdd{}))
 
+	b.WriteByte('\n')
+	b.WriteByte('\n')
+	b.WriteString(es.writeDoc(eksconfig.EnvironmentVariablePrefixAddOnWindowsSmoke, &eksconfig.AddOnWindowsSmoke{}))
+
+	b.WriteByte('\n')
+	b.WriteByte('\n')
+	b.WriteString(es.writeDoc(eksconfig.EnvironmentVariablePrefixAddOnIPv6, &eksconfig.AddOnIPv6{}))
+
+	b.WriteByte('\n')
+	b.WriteByte('\n')
+	b.WriteString(es.writeDoc(eksconfig.EnvironmentVariablePrefixAddOnAccessEntries, &eksconfig.AddOnAccessEntries{}))
+
+	b.WriteByte('\n')
+	b.WriteByte('\n')
+	b.WriteString(es.writeDoc(eksconfig.EnvironmentVariablePrefixAddOnManagedAddons, &eksconfig.AddOnManagedAddons{}))
+
+	b.WriteByte('\n')
+	b.WriteByte('\n')
+	b.WriteString(es.writeDoc(eksconfig.EnvironmentVariablePrefixAddOnSpotInterruption, &eksconfig.AddOnSpotInterruption{}))
+
+	b.WriteByte('\n')
+	b.WriteByte('\n')
+	b.WriteString(es.writeDoc(eksconfig.EnvironmentVariablePrefixAddOnMNGScale, &eksconfig.AddOnMNGScale{}))
+
+	b.WriteByte('\n')
+	b.WriteByte('\n')
+	b.WriteString(es.writeDoc(eksconfig.EnvironmentVariablePrefixAddOnMNGRollingUpgrade, &eksconfig.AddOnMNGRollingUpgrade{}))
+
 	b.WriteByte('\n')
 	b.WriteByte('\n')
 	b.WriteString(es.writeDoc(eksconfig.EnvironmentVariablePrefixAddOnClusterLoaderLocal, &eksconfig.AddOnClusterLoaderLocal{}))
@@ -207,6 +310,9 @@ func createDoc() string {
 		"# set the following *_ENABLE env vars to enable add-ons, rest are set with default values\n" +
 		strings.Join(es.envs, "\n") +
 		"\n\n" +
+		"# or, set the following env var to enable a set of add-ons by name\n" +
+		fmt.Sprintf("# %s=%q\n", eksconfig.AWS_K8S_TESTER_EKS_ADD_ONS, strings.Join(eksconfig.AddOnNames(), ",")) +
+		"\n" +
 		txt
 }
 
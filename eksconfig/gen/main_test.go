@@ -0,0 +1,33 @@
+package main
+
+import (
+	"io/ioutil"
+	"testing"
+
+	"github.com/aws/aws-k8s-tester/eksconfig"
+)
+
+// TestGenerated fails when "eksconfig/README.md" or "eksconfig/schema.json"
+// no longer match "eksconfig.Config". Run "go run ./eksconfig/gen" from the
+// repository root to regenerate them.
+func TestGenerated(t *testing.T) {
+	doc, err := ioutil.ReadFile("../README.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(doc) != "\n```\n"+createDoc()+"```\n" {
+		t.Fatal("eksconfig/README.md is stale; run 'go run ./eksconfig/gen'")
+	}
+
+	schema, err := eksconfig.JSONSchema()
+	if err != nil {
+		t.Fatal(err)
+	}
+	committed, err := ioutil.ReadFile("../schema.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(committed) != string(schema)+"\n" {
+		t.Fatal("eksconfig/schema.json is stale; run 'go run ./eksconfig/gen'")
+	}
+}
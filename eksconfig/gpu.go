@@ -0,0 +1,114 @@
+package eksconfig
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/aws/aws-k8s-tester/ec2config"
+	"github.com/aws/aws-sdk-go/service/eks"
+)
+
+// GPUInstanceTypes maps the NVIDIA GPU EC2 instance type to its number of GPUs.
+// ref. https://aws.amazon.com/ec2/instance-types/#Accelerated_Computing
+var GPUInstanceTypes = map[string]int{
+	"p2.xlarge":   1,
+	"p2.8xlarge":  8,
+	"p2.16xlarge": 16,
+
+	"p3.2xlarge":    1,
+	"p3.8xlarge":    4,
+	"p3.16xlarge":   8,
+	"p3dn.24xlarge": 8,
+
+	"p4d.24xlarge":  8,
+	"p4de.24xlarge": 8,
+	"p5.48xlarge":   8,
+
+	"g3s.xlarge":  1,
+	"g3.4xlarge":  1,
+	"g3.8xlarge":  2,
+	"g3.16xlarge": 4,
+
+	"g4dn.xlarge":   1,
+	"g4dn.2xlarge":  1,
+	"g4dn.4xlarge":  1,
+	"g4dn.8xlarge":  1,
+	"g4dn.16xlarge": 1,
+	"g4dn.12xlarge": 4,
+	"g4dn.metal":    8,
+
+	"g5.xlarge":   1,
+	"g5.2xlarge":  1,
+	"g5.4xlarge":  1,
+	"g5.8xlarge":  1,
+	"g5.16xlarge": 1,
+	"g5.12xlarge": 4,
+	"g5.24xlarge": 4,
+	"g5.48xlarge": 8,
+
+	"g6.xlarge":   1,
+	"g6.2xlarge":  1,
+	"g6.4xlarge":  1,
+	"g6.8xlarge":  1,
+	"g6.16xlarge": 1,
+	"g6.12xlarge": 4,
+	"g6.24xlarge": 4,
+	"g6.48xlarge": 8,
+}
+
+// gpusPerNode returns the number of GPUs per node for the instance types,
+// or zero if any instance type is unknown or the numbers differ.
+func gpusPerNode(instanceTypes []string) int {
+	n := 0
+	for _, itp := range instanceTypes {
+		cnt, ok := GPUInstanceTypes[itp]
+		if !ok || (n > 0 && n != cnt) {
+			return 0
+		}
+		n = cnt
+	}
+	return n
+}
+
+func validateGPUsPerNode(amiType string, gpus int) error {
+	if gpus < 0 {
+		return fmt.Errorf("invalid GPUsPerNode %d", gpus)
+	}
+	if gpus > 0 && amiType != eks.AMITypesAl2X8664Gpu {
+		return fmt.Errorf("GPUsPerNode %d but AMIType %q is not %q", gpus, amiType, eks.AMITypesAl2X8664Gpu)
+	}
+	return nil
+}
+
+// GPUNodeGroup is a node group or managed node group with the GPU AMI.
+type GPUNodeGroup struct {
+	// Name is the node group name, set in the "NGName" node label.
+	Name string
+	// GPUsPerNode is the expected "nvidia.com/gpu" allocatable per node.
+	// Zero to expect at least one GPU.
+	GPUsPerNode int
+	// MinNodes is the minimum number of nodes in the node group.
+	MinNodes int
+}
+
+// GPUNodeGroups returns the enabled GPU node groups, sorted by name.
+func (cfg *Config) GPUNodeGroups() (ngs []GPUNodeGroup) {
+	if cfg.IsEnabledAddOnNodeGroups() {
+		for _, cur := range cfg.AddOnNodeGroups.ASGs {
+			if cur.AMIType != ec2config.AMITypeAL2X8664GPU {
+				continue
+			}
+			ngs = append(ngs, GPUNodeGroup{Name: cur.Name, GPUsPerNode: cur.GPUsPerNode, MinNodes: int(cur.ASGMinSize)})
+		}
+	}
+	if cfg.IsEnabledAddOnManagedNodeGroups() {
+		for _, cur := range cfg.AddOnManagedNodeGroups.MNGs {
+			if cur.AMIType != eks.AMITypesAl2X8664Gpu {
+				continue
+			}
+			ngs = append(ngs, GPUNodeGroup{Name: cur.Name, GPUsPerNode: cur.GPUsPerNode, MinNodes: cur.ASGMinSize})
+		}
+	}
+	sort.Slice(ngs, func(i, j int) bool { return ngs[i].Name < ngs[j].Name })
+	return ngs
+}
@@ -0,0 +1,71 @@
+package eksconfig
+
+import (
+	"errors"
+)
+
+// KMSRotation defines the envelope encryption key rotation scenario,
+// run once the cluster is ACTIVE and the node groups are created.
+// It writes the test secrets, then rotates the cluster key, or associates
+// "Encryption.CMKARN" if the cluster has no envelope encryption (e.g. "Attach").
+// All secrets are then re-encrypted with the rotated key, and the test
+// secrets must remain readable and new secrets must encrypt successfully.
+// EKS cannot change the key once associated, so the key material is rotated
+// by enabling the KMS automatic key rotation.
+// ref. https://docs.aws.amazon.com/eks/latest/userguide/enable-kms.html
+type KMSRotation struct {
+	// Enable is 'true' to run the key rotation scenario.
+	Enable bool `json:"enable"`
+	// Namespace is the namespace of the test secrets.
+	Namespace string `json:"namespace"`
+	// Objects is the number of test secrets to write
+	// before and after the rotation.
+	Objects int `json:"objects"`
+
+	// KeyARN is the KMS key ARN of the cluster envelope encryption.
+	KeyARN string `json:"key-arn" read-only:"true"`
+	// KeyAssociated is true if the key was associated by the scenario.
+	KeyAssociated bool `json:"key-associated" read-only:"true"`
+	// ReencryptedSecrets is the number of secrets re-encrypted
+	// with the rotated key in all namespaces.
+	ReencryptedSecrets int `json:"reencrypted-secrets" read-only:"true"`
+}
+
+func getDefaultKMSRotation() *KMSRotation {
+	return &KMSRotation{
+		Enable:  false,
+		Objects: 10,
+	}
+}
+
+// IsEnabledKMSRotation returns true if "KMSRotation" is enabled.
+// Otherwise, nil the field for "omitempty".
+func (cfg *Config) IsEnabledKMSRotation() bool {
+	if cfg.KMSRotation == nil {
+		return false
+	}
+	if cfg.KMSRotation.Enable {
+		return true
+	}
+	cfg.KMSRotation = nil
+	return false
+}
+
+// validateKMSRotation must be run after "validateAttach".
+func (cfg *Config) validateKMSRotation() error {
+	if !cfg.IsEnabledKMSRotation() {
+		return nil
+	}
+	// the attached cluster key is discovered, otherwise
+	// the cluster is created without envelope encryption
+	if !cfg.IsEnabledAttach() && !cfg.Encryption.CMKCreate && cfg.Encryption.CMKARN == "" {
+		return errors.New("KMSRotation.Enable true but no Encryption.CMKARN with Encryption.CMKCreate false")
+	}
+	if cfg.KMSRotation.Objects <= 0 {
+		return errors.New("KMSRotation.Enable true but Objects <= 0")
+	}
+	if cfg.KMSRotation.Namespace == "" {
+		cfg.KMSRotation.Namespace = cfg.Name + "-kms-rotation"
+	}
+	return nil
+}
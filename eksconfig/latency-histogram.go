@@ -0,0 +1,49 @@
+package eksconfig
+
+import (
+	"fmt"
+
+	"github.com/aws/aws-k8s-tester/pkg/metrics"
+)
+
+// LatencyHistogram defines how the add-ons record the latency histograms
+// of "RequestsSummary" (e.g. "AddOnStresserLocal.RequestsSummaryWrites").
+// The fixed power-of-two buckets lose precision at the tails, so use
+// the "hdr" backend for tests where accurate p99.9+ latency matters.
+// ref. "pkg/metrics.HDRRecorder"
+type LatencyHistogram struct {
+	// Backend is either "fixed" (power-of-two buckets) or "hdr" (HdrHistogram buckets).
+	Backend string `json:"backend"`
+	// SignificantFigures is the number of significant decimal digits of
+	// the "hdr" backend buckets, from 1 to 5 (e.g. 3 for 0.1% precision).
+	SignificantFigures int `json:"significant-figures"`
+}
+
+func getDefaultLatencyHistogram() *LatencyHistogram {
+	return &LatencyHistogram{
+		Backend:            metrics.HistogramBackendFixed,
+		SignificantFigures: metrics.DefaultHDRSignificantFigures,
+	}
+}
+
+func (cfg *Config) validateLatencyHistogram() error {
+	if cfg.LatencyHistogram == nil {
+		cfg.LatencyHistogram = getDefaultLatencyHistogram()
+	}
+	switch cfg.LatencyHistogram.Backend {
+	case "":
+		cfg.LatencyHistogram.Backend = metrics.HistogramBackendFixed
+	case metrics.HistogramBackendFixed, metrics.HistogramBackendHDR:
+	default:
+		return fmt.Errorf("unknown LatencyHistogram.Backend %q", cfg.LatencyHistogram.Backend)
+	}
+	if cfg.LatencyHistogram.SignificantFigures == 0 {
+		cfg.LatencyHistogram.SignificantFigures = metrics.DefaultHDRSignificantFigures
+	}
+	if cfg.LatencyHistogram.SignificantFigures < metrics.MinHDRSignificantFigures ||
+		cfg.LatencyHistogram.SignificantFigures > metrics.MaxHDRSignificantFigures {
+		return fmt.Errorf("LatencyHistogram.SignificantFigures %d out of range [%d, %d]",
+			cfg.LatencyHistogram.SignificantFigures, metrics.MinHDRSignificantFigures, metrics.MaxHDRSignificantFigures)
+	}
+	return nil
+}
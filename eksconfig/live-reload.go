@@ -0,0 +1,154 @@
+package eksconfig
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"sigs.k8s.io/yaml"
+)
+
+// LiveReload defines the live-reload of a safe subset of add-on parameters,
+// so that load levels can be ramped in the middle of long-running soak tests
+// without restarting the tester. The tester periodically reads the reload file
+// (or S3 object) in the same YAML layout as the configuration, only with the
+// reloadable fields. For example:
+//
+//	add-on-stresser-local:
+//	  qps: 100
+//	  object-size: 8192
+//	  list-limit: 500
+//
+// Any other field is rejected, not to mutate cluster states on the fly.
+type LiveReload struct {
+	// Enable is 'true' to watch for parameter updates.
+	Enable bool `json:"enable"`
+	// Path is the local file path to watch.
+	// Defaults to the file next to "ConfigPath" with ".live-reload.yaml" suffix.
+	// The configuration file itself is not watched, since the tester
+	// overwrites it on every state change.
+	Path string `json:"path"`
+	// S3Key is the S3 object key to watch, in the bucket "S3.BucketName".
+	// If not empty, watches the S3 object instead of "Path".
+	S3Key string `json:"s3-key"`
+	// Interval is the interval to check for updates.
+	Interval       time.Duration `json:"interval"`
+	IntervalString string        `json:"interval-string" read-only:"true"`
+}
+
+const (
+	// DefaultLiveReloadInterval is the default interval to check for updates.
+	DefaultLiveReloadInterval = time.Minute
+	// MinLiveReloadInterval is the minimum interval to check for updates.
+	MinLiveReloadInterval = 10 * time.Second
+)
+
+func getDefaultLiveReload() *LiveReload {
+	return &LiveReload{
+		Enable:   false,
+		Interval: DefaultLiveReloadInterval,
+	}
+}
+
+// IsEnabledLiveReload returns true if "LiveReload" is enabled.
+// Otherwise, nil the field for "omitempty".
+func (cfg *Config) IsEnabledLiveReload() bool {
+	if cfg.LiveReload == nil {
+		return false
+	}
+	if cfg.LiveReload.Enable {
+		return true
+	}
+	cfg.LiveReload = nil
+	return false
+}
+
+func (cfg *Config) validateLiveReload() error {
+	if !cfg.IsEnabledLiveReload() {
+		return nil
+	}
+	if cfg.LiveReload.S3Key != "" && cfg.S3.BucketName == "" {
+		return fmt.Errorf("LiveReload.S3Key %q requires non-empty S3.BucketName", cfg.LiveReload.S3Key)
+	}
+	if cfg.LiveReload.Path == "" {
+		cfg.LiveReload.Path = strings.TrimSuffix(cfg.ConfigPath, filepath.Ext(cfg.ConfigPath)) + ".live-reload.yaml"
+	}
+	if cfg.LiveReload.Path == cfg.ConfigPath {
+		return fmt.Errorf("LiveReload.Path %q must be different than ConfigPath", cfg.LiveReload.Path)
+	}
+	if cfg.LiveReload.Interval == time.Duration(0) {
+		cfg.LiveReload.Interval = DefaultLiveReloadInterval
+	}
+	if cfg.LiveReload.Interval < MinLiveReloadInterval {
+		return fmt.Errorf("LiveReload.Interval %v too small (expected >= %v)", cfg.LiveReload.Interval, MinLiveReloadInterval)
+	}
+	cfg.LiveReload.IntervalString = cfg.LiveReload.Interval.String()
+	return nil
+}
+
+// liveReloadFields defines the fields that can be updated while running.
+// Nil fields are not updated.
+type liveReloadFields struct {
+	AddOnStresserLocal *liveReloadStresserLocal `json:"add-on-stresser-local,omitempty"`
+}
+
+type liveReloadStresserLocal struct {
+	QPS        *float64 `json:"qps,omitempty"`
+	ObjectSize *int     `json:"object-size,omitempty"`
+	ListLimit  *int64   `json:"list-limit,omitempty"`
+}
+
+// ApplyLiveReload updates the reloadable fields from the YAML, and
+// returns the list of changes (e.g. "add-on-stresser-local.qps 10 -> 100").
+// Returns an error if the YAML has any field that cannot be reloaded,
+// in which case no field is updated.
+func (cfg *Config) ApplyLiveReload(d []byte) (changes []string, err error) {
+	var fs liveReloadFields
+	if err = yaml.Unmarshal(d, &fs, yaml.DisallowUnknownFields); err != nil {
+		return nil, fmt.Errorf("unsupported live-reload fields (%v)", err)
+	}
+
+	cfg.mu.Lock()
+	defer cfg.mu.Unlock()
+
+	if sv := fs.AddOnStresserLocal; sv != nil {
+		if cfg.AddOnStresserLocal == nil || !cfg.AddOnStresserLocal.Enable {
+			return nil, errors.New("cannot live-reload disabled add-on-stresser-local")
+		}
+		if sv.QPS != nil && *sv.QPS < 0 {
+			return nil, fmt.Errorf("invalid add-on-stresser-local.qps %v", *sv.QPS)
+		}
+		if sv.ObjectSize != nil && *sv.ObjectSize < 0 {
+			return nil, fmt.Errorf("invalid add-on-stresser-local.object-size %d", *sv.ObjectSize)
+		}
+		if sv.ListLimit != nil && *sv.ListLimit < 0 {
+			return nil, fmt.Errorf("invalid add-on-stresser-local.list-limit %d", *sv.ListLimit)
+		}
+		if sv.QPS != nil && *sv.QPS != cfg.AddOnStresserLocal.QPS {
+			changes = append(changes, fmt.Sprintf("add-on-stresser-local.qps %v -> %v", cfg.AddOnStresserLocal.QPS, *sv.QPS))
+			cfg.AddOnStresserLocal.QPS = *sv.QPS
+		}
+		if sv.ObjectSize != nil && *sv.ObjectSize != cfg.AddOnStresserLocal.ObjectSize {
+			changes = append(changes, fmt.Sprintf("add-on-stresser-local.object-size %d -> %d", cfg.AddOnStresserLocal.ObjectSize, *sv.ObjectSize))
+			cfg.AddOnStresserLocal.ObjectSize = *sv.ObjectSize
+		}
+		if sv.ListLimit != nil && *sv.ListLimit != cfg.AddOnStresserLocal.ListLimit {
+			changes = append(changes, fmt.Sprintf("add-on-stresser-local.list-limit %d -> %d", cfg.AddOnStresserLocal.ListLimit, *sv.ListLimit))
+			cfg.AddOnStresserLocal.ListLimit = *sv.ListLimit
+		}
+	}
+	return changes, nil
+}
+
+// GetAddOnStresserLocalLoad returns the current load parameters
+// of "AddOnStresserLocal", which may be updated by "ApplyLiveReload".
+func (cfg *Config) GetAddOnStresserLocalLoad() (qps float64, objectSize int, listLimit int64) {
+	cfg.mu.RLock()
+	defer cfg.mu.RUnlock()
+	if cfg.AddOnStresserLocal == nil {
+		return 0, 0, 0
+	}
+	return cfg.AddOnStresserLocal.QPS, cfg.AddOnStresserLocal.ObjectSize, cfg.AddOnStresserLocal.ListLimit
+}
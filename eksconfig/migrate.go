@@ -0,0 +1,209 @@
+package eksconfig
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"sync"
+
+	"sigs.k8s.io/yaml"
+)
+
+// migration upgrades the older field layout in place,
+// and returns true if any field has been migrated.
+type migration struct {
+	desc    string
+	migrate func(m map[string]interface{}) bool
+}
+
+// migrations are applied in order, from the oldest layout to the newest.
+var migrations = []migration{
+	{desc: `move "parameters" fields to "role", "vpc", "encryption", and top-level fields`, migrate: migrateParameters},
+	{desc: `move "s3-bucket-*" fields to "s3"`, migrate: migrateS3},
+	{desc: `rename "add-on-config-maps-*" to "add-on-configmaps-*"`, migrate: migrateConfigmaps},
+	{desc: `change "log-color-override" from bool to string`, migrate: migrateLogColorOverride},
+}
+
+// Migrate upgrades the configuration in JSON (or YAML) persisted by
+// an older version of the tester to the current field layout, so that
+// the clusters created by an older binary can still be managed or deleted.
+// The fields that no longer exist are dropped with warnings.
+// The returned configuration is not written to disk.
+//
+// Example usage:
+//
+//	import "github.com/aws/aws-k8s-tester/eksconfig"
+//	d, err := ioutil.ReadFile(p)
+//	cfg, err := eksconfig.Migrate(d)
+//	cfg.ConfigPath = p
+//	err = cfg.Sync()
+func Migrate(oldJSON []byte) (*Config, error) {
+	d, err := yaml.YAMLToJSON(oldJSON)
+	if err != nil {
+		return nil, err
+	}
+	// keep the large integers (e.g. durations in nanoseconds) as they are
+	dec := json.NewDecoder(bytes.NewReader(d))
+	dec.UseNumber()
+	m := make(map[string]interface{})
+	if err = dec.Decode(&m); err != nil {
+		return nil, err
+	}
+	for _, mg := range migrations {
+		if mg.migrate(m) {
+			fmt.Fprintf(os.Stderr, "[INFO] migrated configuration (%s)\n", mg.desc)
+		}
+	}
+	d, err = json.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := new(Config)
+	if err = yaml.Unmarshal(d, cfg, yaml.DisallowUnknownFields); err != nil {
+		if verr := ValidateStrict(d); verr != nil {
+			fmt.Fprintf(os.Stderr, "[WARN] dropping unknown configuration fields %v\n", verr)
+		}
+		cfg = new(Config)
+		if err = yaml.Unmarshal(d, cfg); err != nil {
+			return nil, err
+		}
+	}
+	cfg.mu = new(sync.RWMutex)
+	return cfg, nil
+}
+
+// moveField moves the field "from" in the map "src" to the field "to" in the map "dst",
+// and returns true if moved. The existing field in "dst" is not overwritten.
+func moveField(src map[string]interface{}, from string, dst map[string]interface{}, to string) bool {
+	v, ok := src[from]
+	if !ok {
+		return false
+	}
+	delete(src, from)
+	if _, ok = dst[to]; !ok {
+		dst[to] = v
+	}
+	return true
+}
+
+// subMap returns the map field, creating one if not exists.
+func subMap(m map[string]interface{}, k string) map[string]interface{} {
+	if sm, ok := m[k].(map[string]interface{}); ok {
+		return sm
+	}
+	sm := make(map[string]interface{})
+	m[k] = sm
+	return sm
+}
+
+// older versions have the cluster parameters under "parameters".
+func migrateParameters(m map[string]interface{}) bool {
+	params, ok := m["parameters"].(map[string]interface{})
+	if !ok {
+		return false
+	}
+	delete(m, "parameters")
+
+	for _, k := range []string{
+		"version",
+		"tags",
+		"request-header-key",
+		"request-header-value",
+		"resolver-url",
+		"signing-name",
+	} {
+		moveField(params, k, m, k)
+	}
+
+	role := subMap(m, "role")
+	for from, to := range map[string]string{
+		"role-name":                "name",
+		"role-create":              "create",
+		"role-arn":                 "arn",
+		"role-service-principals":  "service-principals",
+		"role-managed-policy-arns": "managed-policy-arns",
+	} {
+		moveField(params, from, role, to)
+	}
+
+	vpc := subMap(m, "vpc")
+	for from, to := range map[string]string{
+		"vpc-create":                       "create",
+		"vpc-id":                           "id",
+		"public-subnet-ids":                "public-subnet-ids",
+		"private-subnet-ids":               "private-subnet-ids",
+		"dhcp-options-domain-name":         "dhcp-options-domain-name",
+		"dhcp-options-domain-name-servers": "dhcp-options-domain-name-servers",
+	} {
+		moveField(params, from, vpc, to)
+	}
+	if v, ok := params["vpc-cidr"]; ok {
+		delete(params, "vpc-cidr")
+		if s, ok := v.(string); ok && s != "" {
+			if _, ok = vpc["cidrs"]; !ok {
+				vpc["cidrs"] = []interface{}{s}
+			}
+		}
+	}
+
+	encryption := subMap(m, "encryption")
+	for from, to := range map[string]string{
+		"encryption-cmk-create": "cmk-create",
+		"encryption-cmk-arn":    "cmk-arn",
+	} {
+		moveField(params, from, encryption, to)
+	}
+
+	for k, v := range params {
+		fmt.Fprintf(os.Stderr, "[WARN] dropping unsupported field \"parameters.%s\" (%v)\n", k, v)
+	}
+	return true
+}
+
+// older versions have the S3 bucket fields at the top-level.
+func migrateS3(m map[string]interface{}) (migrated bool) {
+	for from, to := range map[string]string{
+		"s3-bucket-name":                      "bucket-name",
+		"s3-bucket-create":                    "bucket-create",
+		"s3-bucket-create-keep":               "bucket-create-keep",
+		"s3-bucket-lifecycle-expiration-days": "bucket-lifecycle-expiration-days",
+	} {
+		if _, ok := m[from]; !ok {
+			continue
+		}
+		moveField(m, from, subMap(m, "s3"), to)
+		migrated = true
+	}
+	return migrated
+}
+
+// ref. https://github.com/aws/aws-k8s-tester/blob/master/CHANGELOG/CHANGELOG-1.3.md
+func migrateConfigmaps(m map[string]interface{}) (migrated bool) {
+	for from, to := range map[string]string{
+		"add-on-config-maps-local":  "add-on-configmaps-local",
+		"add-on-config-maps-remote": "add-on-configmaps-remote",
+	} {
+		if moveField(m, from, m, to) {
+			migrated = true
+		}
+	}
+	return migrated
+}
+
+// ref. https://github.com/aws/aws-k8s-tester/blob/master/CHANGELOG/CHANGELOG-1.5.md
+func migrateLogColorOverride(m map[string]interface{}) bool {
+	b, ok := m["log-color-override"].(bool)
+	if !ok {
+		return false
+	}
+	if b {
+		m["log-color-override"] = strconv.FormatBool(b)
+	} else {
+		// "false" was the default, not to override
+		m["log-color-override"] = ""
+	}
+	return true
+}
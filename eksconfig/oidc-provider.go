@@ -0,0 +1,80 @@
+package eksconfig
+
+import (
+	"fmt"
+	"strings"
+)
+
+// OIDCProvider defines the IAM OpenID Connect provider of the cluster,
+// created after the cluster is active and deleted on teardown, so that
+// the add-ons can create IAM roles for service accounts (IRSA) with
+// "eks/oidc" instead of creating the provider by themselves.
+// ref. https://docs.aws.amazon.com/eks/latest/userguide/enable-iam-roles-for-service-accounts.html
+type OIDCProvider struct {
+	// Enable is 'true' to create the IAM OpenID Connect provider.
+	// Automatically enabled when any add-on requires IRSA (see "oidcProviderAddOns").
+	Enable bool `json:"enable"`
+
+	// Created is true if the provider was created by the tester,
+	// and false if the provider already existed.
+	Created bool `json:"created" read-only:"true"`
+	// ServiceAccountRoles maps the service account ("[namespace]/[name]")
+	// to its IRSA role ARN, which are deleted before the provider.
+	ServiceAccountRoles map[string]string `json:"service-account-roles,omitempty" read-only:"true"`
+}
+
+// oidcProviderAddOns is the set of add-ons (see "AddOnNames")
+// that require the IAM OpenID Connect provider.
+var oidcProviderAddOns = []string{
+	"irsa",
+	"irsa-fargate",
+}
+
+func getDefaultOIDCProvider() *OIDCProvider {
+	return &OIDCProvider{
+		Enable: false,
+	}
+}
+
+// IsEnabledOIDCProvider returns true if "OIDCProvider" is enabled.
+// Otherwise, nil the field for "omitempty".
+func (cfg *Config) IsEnabledOIDCProvider() bool {
+	if cfg.OIDCProvider == nil {
+		return false
+	}
+	if cfg.OIDCProvider.Enable {
+		return true
+	}
+	cfg.OIDCProvider = nil
+	return false
+}
+
+// ServiceAccountRoleKey returns the "OIDCProvider.ServiceAccountRoles" key
+// of the service account.
+func ServiceAccountRoleKey(namespace string, name string) string {
+	return namespace + "/" + name
+}
+
+func (cfg *Config) validateOIDCProvider() error {
+	for _, name := range oidcProviderAddOns {
+		if !cfg.isEnabledAddOnName(name) {
+			continue
+		}
+		if cfg.OIDCProvider == nil {
+			cfg.OIDCProvider = getDefaultOIDCProvider()
+		}
+		cfg.OIDCProvider.Enable = true
+	}
+	if !cfg.IsEnabledOIDCProvider() {
+		return nil
+	}
+	for k, v := range cfg.OIDCProvider.ServiceAccountRoles {
+		if len(strings.Split(k, "/")) != 2 {
+			return fmt.Errorf("invalid OIDCProvider.ServiceAccountRoles key %q (expected '[namespace]/[name]')", k)
+		}
+		if !strings.HasPrefix(v, "arn:") || !strings.Contains(v, ":role/") {
+			return fmt.Errorf("invalid OIDCProvider.ServiceAccountRoles role ARN %q for %q", v, k)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package eksconfig
+
+import (
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// OTLPExporter defines the OpenTelemetry metrics export over OTLP/HTTP,
+// to stream the tester metrics (e.g. stresser writes/reads totals and
+// latency histograms, and the EKS API latency of the cluster waiters)
+// to any OpenTelemetry-compatible backend while the tester runs,
+// instead of only writing the results at the end.
+// ref. "pkg/metrics.OTLPExporter"
+type OTLPExporter struct {
+	// Enable is 'true' to export the metrics.
+	Enable bool `json:"enable"`
+	// Endpoint is the OTLP/HTTP metrics endpoint of the collector.
+	Endpoint string `json:"endpoint"`
+	// Headers are the HTTP headers of every export request
+	// (e.g. {"Authorization": "Bearer ..."}).
+	Headers map[string]string `json:"headers,omitempty"`
+	// Interval is the interval to export the metrics.
+	Interval       time.Duration `json:"interval"`
+	IntervalString string        `json:"interval-string" read-only:"true"`
+}
+
+const (
+	// DefaultOTLPExporterEndpoint is the default OTLP/HTTP metrics endpoint
+	// of the local OpenTelemetry collector.
+	DefaultOTLPExporterEndpoint = "http://localhost:4318/v1/metrics"
+	// DefaultOTLPExporterInterval is the default interval to export the metrics.
+	DefaultOTLPExporterInterval = 15 * time.Second
+	// MinOTLPExporterInterval is the minimum interval to export the metrics.
+	MinOTLPExporterInterval = time.Second
+)
+
+func getDefaultOTLPExporter() *OTLPExporter {
+	return &OTLPExporter{
+		Enable:   false,
+		Endpoint: DefaultOTLPExporterEndpoint,
+		Interval: DefaultOTLPExporterInterval,
+	}
+}
+
+// IsEnabledOTLPExporter returns true if "OTLPExporter" is enabled.
+// Otherwise, nil the field for "omitempty".
+func (cfg *Config) IsEnabledOTLPExporter() bool {
+	if cfg.OTLPExporter == nil {
+		return false
+	}
+	if cfg.OTLPExporter.Enable {
+		return true
+	}
+	cfg.OTLPExporter = nil
+	return false
+}
+
+func (cfg *Config) validateOTLPExporter() error {
+	if !cfg.IsEnabledOTLPExporter() {
+		return nil
+	}
+	if cfg.OTLPExporter.Endpoint == "" {
+		cfg.OTLPExporter.Endpoint = DefaultOTLPExporterEndpoint
+	}
+	u, err := url.Parse(cfg.OTLPExporter.Endpoint)
+	if err != nil {
+		return fmt.Errorf("invalid OTLPExporter.Endpoint %q (%v)", cfg.OTLPExporter.Endpoint, err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("invalid OTLPExporter.Endpoint %q (expected http or https scheme)", cfg.OTLPExporter.Endpoint)
+	}
+	if u.Host == "" {
+		return fmt.Errorf("invalid OTLPExporter.Endpoint %q (empty host)", cfg.OTLPExporter.Endpoint)
+	}
+	for k := range cfg.OTLPExporter.Headers {
+		if k == "" {
+			return fmt.Errorf("empty OTLPExporter.Headers name")
+		}
+	}
+	if cfg.OTLPExporter.Interval == time.Duration(0) {
+		cfg.OTLPExporter.Interval = DefaultOTLPExporterInterval
+	}
+	if cfg.OTLPExporter.Interval < MinOTLPExporterInterval {
+		return fmt.Errorf("OTLPExporter.Interval %v too small (expected >= %v)", cfg.OTLPExporter.Interval, MinOTLPExporterInterval)
+	}
+	cfg.OTLPExporter.IntervalString = cfg.OTLPExporter.Interval.String()
+	return nil
+}
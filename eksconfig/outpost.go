@@ -0,0 +1,187 @@
+package eksconfig
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	aws_ec2_v2_types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	aws_eks_v2_types "github.com/aws/aws-sdk-go-v2/service/eks/types"
+	"github.com/aws/aws-sdk-go/service/eks"
+)
+
+// Outpost defines the EKS local cluster on AWS Outposts, whose control
+// plane instances run on the Outpost. The kube-apiserver endpoint is only
+// reachable within the VPC (see "Bastion"), and the nodes are launched
+// by "AddOnNodeGroups" in the Outpost subnets, since managed node groups
+// and Fargate are not supported.
+// ref. https://docs.aws.amazon.com/eks/latest/userguide/eks-outposts-local-cluster-overview.html
+type Outpost struct {
+	// Enable is 'true' to create a local cluster on the Outpost.
+	Enable bool `json:"enable"`
+	// OutpostARN is the ARN of the Outpost to create the control plane on.
+	OutpostARN string `json:"outpost-arn"`
+	// ControlPlaneInstanceType is the EC2 instance type of the control plane
+	// instances, which must be available on the Outpost.
+	ControlPlaneInstanceType string `json:"control-plane-instance-type"`
+	// ControlPlanePlacementGroupName is the optional placement group
+	// of the control plane instances.
+	ControlPlanePlacementGroupName string `json:"control-plane-placement-group-name"`
+	// SubnetIDs is the list of the Outpost subnets in "VPC",
+	// for the control plane instances and the node groups.
+	SubnetIDs []string `json:"subnet-ids"`
+
+	// ClusterID is the ID of the local cluster, which is used in place of
+	// the cluster name for authentication and node bootstrap.
+	ClusterID string `json:"cluster-id" read-only:"true"`
+}
+
+const (
+	// DefaultOutpostControlPlaneInstanceType is the default instance type
+	// of the control plane instances.
+	DefaultOutpostControlPlaneInstanceType = "m5.large"
+	// OutpostVolumeType is the EBS volume type available on Outposts.
+	OutpostVolumeType = aws_ec2_v2_types.VolumeTypeGp2
+)
+
+// OutpostClusterPolicyARN is the managed policy required
+// for the cluster role of local clusters.
+// ref. https://docs.aws.amazon.com/eks/latest/userguide/eks-outposts-vpc-subnet-requirements.html
+const OutpostClusterPolicyARN = "arn:aws:iam::aws:policy/AmazonEKSLocalOutpostClusterPolicy"
+
+func getDefaultOutpost() *Outpost {
+	return &Outpost{
+		Enable:                   false,
+		ControlPlaneInstanceType: DefaultOutpostControlPlaneInstanceType,
+	}
+}
+
+// IsEnabledOutpost returns true if "Outpost" is enabled.
+// Otherwise, nil the field for "omitempty".
+func (cfg *Config) IsEnabledOutpost() bool {
+	if cfg.Outpost == nil {
+		return false
+	}
+	if cfg.Outpost.Enable {
+		return true
+	}
+	cfg.Outpost = nil
+	return false
+}
+
+// NodeGroupSubnetIDs returns the subnets to launch the node group instances in.
+func (cfg *Config) NodeGroupSubnetIDs() []string {
+	if cfg.IsEnabledOutpost() {
+		return cfg.Outpost.SubnetIDs
+	}
+	return cfg.VPC.PublicSubnetIDs
+}
+
+// TokenClusterID returns the cluster identifier of the authentication tokens
+// ("x-k8s-aws-id"), which is the cluster ID for local clusters
+// and the cluster name otherwise.
+// ref. https://docs.aws.amazon.com/eks/latest/userguide/eks-outposts-local-cluster-create.html
+func (cfg *Config) TokenClusterID() string {
+	if cfg.IsEnabledOutpost() && cfg.Outpost.ClusterID != "" {
+		return cfg.Outpost.ClusterID
+	}
+	return cfg.Name
+}
+
+// validateOutpost must be run before the validation of "Role",
+// "VPC", and the endpoint access.
+func (cfg *Config) validateOutpost() error {
+	if !cfg.IsEnabledOutpost() {
+		return nil
+	}
+	if !strings.HasPrefix(cfg.Outpost.OutpostARN, "arn:") || !strings.Contains(cfg.Outpost.OutpostARN, ":outpost/") {
+		return fmt.Errorf("invalid Outpost.OutpostARN %q", cfg.Outpost.OutpostARN)
+	}
+	if cfg.Outpost.ControlPlaneInstanceType == "" {
+		cfg.Outpost.ControlPlaneInstanceType = DefaultOutpostControlPlaneInstanceType
+	}
+	if len(cfg.Outpost.SubnetIDs) == 0 {
+		return errors.New("Outpost.Enable true but empty Outpost.SubnetIDs")
+	}
+	for _, id := range cfg.Outpost.SubnetIDs {
+		if !strings.HasPrefix(id, "subnet-") {
+			return fmt.Errorf("invalid Outpost.SubnetIDs %q", id)
+		}
+	}
+	// Outpost subnets are created by the Outpost owner, not the tester
+	if cfg.VPC.Create {
+		return errors.New("Outpost.Enable true but VPC.Create true (expected an existing VPC with Outpost subnets)")
+	}
+
+	if cfg.IsEnabledAutoMode() {
+		return errors.New("Outpost.Enable true but AutoMode.Enable true")
+	}
+	if cfg.IPFamily == IPFamilyIPv6 {
+		return fmt.Errorf("Outpost.Enable true but IPFamily %q", cfg.IPFamily)
+	}
+	// local clusters do not support EKS access entries
+	switch cfg.AuthenticationMode {
+	case "", eks.AuthenticationModeConfigMap:
+	default:
+		return fmt.Errorf("Outpost.Enable true but AuthenticationMode %q (expected %q)", cfg.AuthenticationMode, eks.AuthenticationModeConfigMap)
+	}
+	// local clusters only have the private endpoint
+	cfg.EndpointPrivateAccess, cfg.EndpointPublicAccess = true, false
+
+	if cfg.Role.Create {
+		found := false
+		for _, v := range cfg.Role.ManagedPolicyARNs {
+			if v == OutpostClusterPolicyARN {
+				found = true
+				break
+			}
+		}
+		if !found {
+			cfg.Role.ManagedPolicyARNs = append(cfg.Role.ManagedPolicyARNs, OutpostClusterPolicyARN)
+			sort.Strings(cfg.Role.ManagedPolicyARNs)
+		}
+		// the control plane instances assume the cluster role
+		found = false
+		for _, v := range cfg.Role.ServicePrincipals {
+			if v == "ec2.amazonaws.com" {
+				found = true
+				break
+			}
+		}
+		if !found {
+			cfg.Role.ServicePrincipals = append(cfg.Role.ServicePrincipals, "ec2.amazonaws.com")
+		}
+	}
+
+	var unsupported []string
+	for _, name := range []string{"managed-node-groups", "fargate", "irsa", "irsa-fargate"} {
+		if cfg.isEnabledAddOnName(name) {
+			unsupported = append(unsupported, name)
+		}
+	}
+	if len(unsupported) > 0 {
+		return fmt.Errorf("Outpost.Enable true but add-ons %q not supported with local clusters", unsupported)
+	}
+
+	if cfg.IsEnabledAddOnNodeGroups() {
+		for name, cur := range cfg.AddOnNodeGroups.ASGs {
+			switch cur.AMIType {
+			case fmt.Sprint(aws_eks_v2_types.AMITypesAl2X8664),
+				fmt.Sprint(aws_eks_v2_types.AMITypesAl2Arm64),
+				fmt.Sprint(aws_eks_v2_types.AMITypesAl2X8664Gpu):
+			default:
+				return fmt.Errorf("Outpost.Enable true but AddOnNodeGroups.ASGs[%q].AMIType %q (expected Amazon Linux 2)", name, cur.AMIType)
+			}
+			switch cur.VolumeType {
+			case "", DefaultNodeVolumeType:
+				cur.VolumeType = OutpostVolumeType
+			case OutpostVolumeType:
+			default:
+				return fmt.Errorf("Outpost.Enable true but AddOnNodeGroups.ASGs[%q].VolumeType %q (expected %q)", name, cur.VolumeType, OutpostVolumeType)
+			}
+			cfg.AddOnNodeGroups.ASGs[name] = cur
+		}
+	}
+	return nil
+}
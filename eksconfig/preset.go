@@ -0,0 +1,144 @@
+package eksconfig
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/aws/aws-sdk-go/service/eks"
+)
+
+// AWS_K8S_TESTER_EKS_PRESET is the environment variable to select
+// the preset (see "ApplyPreset").
+const AWS_K8S_TESTER_EKS_PRESET = AWS_K8S_TESTER_EKS_PREFIX + "PRESET"
+
+const (
+	// PresetMinimal is the smallest cluster with a single CPU node
+	// and no test add-on.
+	PresetMinimal = "minimal"
+	// PresetConformance runs sonobuoy conformance tests against
+	// a 3-node managed node group.
+	PresetConformance = "conformance"
+	// PresetScale2000Nodes creates 2,000 self-managed nodes
+	// with the cluster loader.
+	PresetScale2000Nodes = "scale-2000-nodes"
+	// PresetGPU creates a GPU managed node group and
+	// runs the CUDA vector add test.
+	PresetGPU = "gpu"
+)
+
+var presets = map[string]func(cfg *Config){
+	PresetMinimal:        presetMinimal,
+	PresetConformance:    presetConformance,
+	PresetScale2000Nodes: presetScale2000Nodes,
+	PresetGPU:            presetGPU,
+}
+
+// Presets returns the sorted list of preset names.
+func Presets() (names []string) {
+	for k := range presets {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ApplyPreset pre-populates the add-ons, node counts, instance types,
+// and timeouts for the named preset. Empty name is a no-op.
+// It must be called before "UpdateFromEnvs", so that individual
+// fields can still be overridden by environment variables.
+//
+// Example usage:
+//
+//	import "github.com/aws/aws-k8s-tester/eksconfig"
+//	cfg := eksconfig.NewDefault()
+//	err := cfg.ApplyPreset(os.Getenv(eksconfig.AWS_K8S_TESTER_EKS_PRESET))
+//	err = cfg.UpdateFromEnvs()
+//	err = cfg.ValidateAndSetDefaults()
+func (cfg *Config) ApplyPreset(name string) error {
+	if name == "" {
+		return nil
+	}
+	fn, ok := presets[name]
+	if !ok {
+		return fmt.Errorf("unknown preset %q (available presets %q)", name, Presets())
+	}
+	cfg.mu.Lock()
+	defer cfg.mu.Unlock()
+	fn(cfg)
+	return nil
+}
+
+// disableAddOns disables all node groups and test add-ons,
+// so that the preset only enables what it needs.
+func disableAddOns(cfg *Config) {
+	cfg.AddOnNodeGroups = getDefaultAddOnNodeGroups(cfg.Name)
+	cfg.AddOnManagedNodeGroups = getDefaultAddOnManagedNodeGroups(cfg.Name)
+	cfg.AddOnConformance = getDefaultAddOnConformance()
+	cfg.AddOnClusterLoaderLocal = getDefaultAddOnClusterLoaderLocal()
+	cfg.AddOnCUDAVectorAdd = getDefaultAddOnCUDAVectorAdd()
+}
+
+func presetMinimal(cfg *Config) {
+	disableAddOns(cfg)
+	cfg.AddOnManagedNodeGroups.Enable = true
+	for k, cur := range cfg.AddOnManagedNodeGroups.MNGs {
+		cur.InstanceTypes = []string{"t3.large"}
+		cur.ASGMinSize, cur.ASGMaxSize, cur.ASGDesiredCapacity = 1, 1, 1
+		cfg.AddOnManagedNodeGroups.MNGs[k] = cur
+	}
+}
+
+func presetConformance(cfg *Config) {
+	disableAddOns(cfg)
+	cfg.AddOnManagedNodeGroups.Enable = true
+	for k, cur := range cfg.AddOnManagedNodeGroups.MNGs {
+		cur.ASGMinSize, cur.ASGMaxSize, cur.ASGDesiredCapacity = 3, 3, 3
+		cfg.AddOnManagedNodeGroups.MNGs[k] = cur
+	}
+	cfg.AddOnConformance.Enable = true
+	cfg.AddOnConformance.SonobuoyRunMode = "certified-conformance"
+	// "certified-conformance" takes >=3-hour
+	cfg.AddOnConformance.SonobuoyRunTimeout = 6 * time.Hour
+	cfg.AddOnConformance.SonobuoyDeleteTimeout = 10 * time.Minute
+}
+
+// presetScale2000Nodes uses self-managed node groups, since
+// managed node groups are limited to "MNGMaxLimit" nodes each.
+func presetScale2000Nodes(cfg *Config) {
+	disableAddOns(cfg)
+	cfg.AddOnNodeGroups.Enable = true
+	for k, cur := range cfg.AddOnNodeGroups.ASGs {
+		cur.InstanceType = "m5.large"
+		cur.ASGMinSize, cur.ASGMaxSize, cur.ASGDesiredCapacity = 2000, 2000, 2000
+		cfg.AddOnNodeGroups.ASGs[k] = cur
+	}
+	cfg.AddOnClusterLoaderLocal.Enable = true
+	cfg.AddOnClusterLoaderLocal.Nodes = 2000
+	cfg.AddOnClusterLoaderLocal.Timeout = 5 * time.Hour
+
+	// more clients to talk to the larger control plane
+	cfg.Clients = 10
+	cfg.ClientQPS = 50
+	cfg.ClientBurst = 100
+	cfg.ClientTimeout = time.Minute
+}
+
+func presetGPU(cfg *Config) {
+	disableAddOns(cfg)
+	cfg.AddOnManagedNodeGroups.Enable = true
+	cfg.AddOnManagedNodeGroups.MNGs = map[string]MNG{
+		cfg.Name + "-mng-gpu": {
+			Name:                 cfg.Name + "-mng-gpu",
+			RemoteAccessUserName: "ec2-user", // assume Amazon Linux 2
+			AMIType:              eks.AMITypesAl2X8664Gpu,
+			InstanceTypes:        []string{DefaultNodeInstanceTypeGPU},
+			VolumeSize:           DefaultNodeVolumeSize,
+			ASGMinSize:           1,
+			ASGMaxSize:           1,
+			ASGDesiredCapacity:   1,
+			VersionUpgrade:       &MNGVersionUpgrade{Enable: false},
+		},
+	}
+	cfg.AddOnCUDAVectorAdd.Enable = true
+}
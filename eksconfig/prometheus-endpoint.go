@@ -0,0 +1,69 @@
+package eksconfig
+
+import (
+	"fmt"
+	"net"
+	"strings"
+)
+
+// PrometheusEndpoint defines the local HTTP endpoint that exposes
+// the tester metrics in Prometheus text format while the tester runs
+// (e.g. stresser writes/reads totals and latency histograms, and
+// the EKS API latency of the cluster waiters), so that an external
+// Prometheus can scrape live progress of long load tests.
+type PrometheusEndpoint struct {
+	// Enable is 'true' to serve the metrics.
+	Enable bool `json:"enable"`
+	// ListenAddress is the address to listen on (e.g. ":9101").
+	// Use "localhost:9101" not to expose the metrics outside the host.
+	ListenAddress string `json:"listen-address"`
+	// Path is the HTTP path of the metrics (e.g. "/metrics").
+	Path string `json:"path"`
+}
+
+const (
+	// DefaultPrometheusEndpointListenAddress is the default listen address of the metrics endpoint.
+	DefaultPrometheusEndpointListenAddress = ":9101"
+	// DefaultPrometheusEndpointPath is the default HTTP path of the metrics endpoint.
+	DefaultPrometheusEndpointPath = "/metrics"
+)
+
+func getDefaultPrometheusEndpoint() *PrometheusEndpoint {
+	return &PrometheusEndpoint{
+		Enable:        false,
+		ListenAddress: DefaultPrometheusEndpointListenAddress,
+		Path:          DefaultPrometheusEndpointPath,
+	}
+}
+
+// IsEnabledPrometheusEndpoint returns true if "PrometheusEndpoint" is enabled.
+// Otherwise, nil the field for "omitempty".
+func (cfg *Config) IsEnabledPrometheusEndpoint() bool {
+	if cfg.PrometheusEndpoint == nil {
+		return false
+	}
+	if cfg.PrometheusEndpoint.Enable {
+		return true
+	}
+	cfg.PrometheusEndpoint = nil
+	return false
+}
+
+func (cfg *Config) validatePrometheusEndpoint() error {
+	if !cfg.IsEnabledPrometheusEndpoint() {
+		return nil
+	}
+	if cfg.PrometheusEndpoint.ListenAddress == "" {
+		cfg.PrometheusEndpoint.ListenAddress = DefaultPrometheusEndpointListenAddress
+	}
+	if _, _, err := net.SplitHostPort(cfg.PrometheusEndpoint.ListenAddress); err != nil {
+		return fmt.Errorf("invalid PrometheusEndpoint.ListenAddress %q (%v)", cfg.PrometheusEndpoint.ListenAddress, err)
+	}
+	if cfg.PrometheusEndpoint.Path == "" {
+		cfg.PrometheusEndpoint.Path = DefaultPrometheusEndpointPath
+	}
+	if !strings.HasPrefix(cfg.PrometheusEndpoint.Path, "/") {
+		return fmt.Errorf("PrometheusEndpoint.Path %q must start with '/'", cfg.PrometheusEndpoint.Path)
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package eksconfig
+
+import (
+	"github.com/aws/aws-sdk-go/service/eks"
+)
+
+// QuotaRequirements defines the minimum resources to launch with
+// the current configuration, to be checked against the service quotas
+// before creating any resource. Must be called after "ValidateAndSetDefaults"
+// and the availability zone discovery (see "AvailabilityZoneNames").
+type QuotaRequirements struct {
+	// OnDemandInstances maps each EC2 instance type to the number of
+	// on-demand instances to launch.
+	OnDemandInstances map[string]int
+	// SpotInstances maps each EC2 instance type to the number of
+	// spot instances to launch.
+	SpotInstances map[string]int
+	// EIPs is the number of Elastic IPs to allocate.
+	EIPs int
+	// NATGatewaysPerAZ is the number of NAT gateways to create in each availability zone.
+	NATGatewaysPerAZ int
+	// Clusters is the number of EKS clusters to create.
+	Clusters int
+}
+
+// QuotaRequirements returns the minimum resources to launch.
+// For managed node groups with multiple instance types,
+// only the first instance type is counted.
+func (cfg *Config) QuotaRequirements() (req QuotaRequirements) {
+	req = QuotaRequirements{
+		OnDemandInstances: make(map[string]int),
+		SpotInstances:     make(map[string]int),
+	}
+	if cfg.Status == nil || cfg.Status.ClusterARN == "" {
+		req.Clusters = 1
+	}
+	if cfg.VPC != nil && cfg.VPC.Create && cfg.VPC.ID == "" {
+		// one NAT gateway with an EIP for each public subnet
+		req.EIPs = len(cfg.AvailabilityZoneNames)
+		if req.EIPs > 0 {
+			req.NATGatewaysPerAZ = 1
+		}
+	}
+	if cfg.IsPrivateEndpointOnly() && cfg.Bastion != nil && cfg.Bastion.InstanceID == "" {
+		req.OnDemandInstances[cfg.Bastion.InstanceType]++
+	}
+	if cfg.IsEnabledAddOnNodeGroups() && !cfg.AddOnNodeGroups.Created {
+		for _, cur := range cfg.AddOnNodeGroups.ASGs {
+			n := int(cur.ASGDesiredCapacity)
+			if n < int(cur.ASGMinSize) {
+				n = int(cur.ASGMinSize)
+			}
+			req.OnDemandInstances[cur.InstanceType] += n
+		}
+	}
+	if cfg.IsEnabledAddOnManagedNodeGroups() && !cfg.AddOnManagedNodeGroups.Created {
+		for _, cur := range cfg.AddOnManagedNodeGroups.MNGs {
+			if len(cur.InstanceTypes) == 0 {
+				continue
+			}
+			n := cur.ASGDesiredCapacity
+			if n < cur.ASGMinSize {
+				n = cur.ASGMinSize
+			}
+			if cur.CapacityType == eks.CapacityTypesSpot {
+				req.SpotInstances[cur.InstanceTypes[0]] += n
+			} else {
+				req.OnDemandInstances[cur.InstanceTypes[0]] += n
+			}
+		}
+	}
+	return req
+}
@@ -0,0 +1,194 @@
+package eksconfig
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-k8s-tester/pkg/metrics"
+)
+
+// Regression defines the regression checks of the add-on "RequestsSummary"
+// results (e.g. "AddOnStresserLocal.RequestsSummaryWrites") against
+// the baselines from a prior run, downloaded from S3.
+type Regression struct {
+	// Enable is 'true' to compare the results against the baselines
+	// after all add-ons are created.
+	Enable bool `json:"enable"`
+	// BaselineS3BucketName is the S3 bucket of the baselines.
+	// Defaults to "S3.BucketName".
+	BaselineS3BucketName string `json:"baseline-s3-bucket-name"`
+	// BaselineS3Keys maps the "RequestsSummary" field name to the S3 key
+	// of the baseline "RequestsSummary" JSON (e.g. the prior run's
+	// "AddOnStresserLocal.RequestsSummaryWritesJSONS3Key"). For example:
+	//
+	//	{"AddOnStresserLocal.RequestsSummaryWrites":"my-prior-run/my-prior-run-stresser-local-requests-summary-writes.json"}
+	//
+	BaselineS3Keys map[string]string `json:"baseline-s3-keys"`
+
+	// MaxSuccessRateDropPercent is the maximum drop of the success rate
+	// in percentage points. Zero disables the check.
+	MaxSuccessRateDropPercent float64 `json:"max-success-rate-drop-percent"`
+	// MaxLatencyIncreasePercent is the maximum increase of each latency
+	// percentile in percent of the baseline. Zero disables the check.
+	MaxLatencyIncreasePercent float64 `json:"max-latency-increase-percent"`
+	// FailOnRegression is 'true' to fail the test on any regression.
+	// Otherwise, only marks "Regressed" as degraded.
+	FailOnRegression bool `json:"fail-on-regression"`
+
+	// Regressed is true if any result regressed beyond the thresholds.
+	Regressed bool `json:"regressed" read-only:"true"`
+	// Reports maps the "RequestsSummary" field name to its comparison report.
+	Reports map[string]metrics.RegressionReport `json:"reports" read-only:"true"`
+}
+
+func getDefaultRegression() *Regression {
+	return &Regression{
+		Enable:                    false,
+		MaxSuccessRateDropPercent: 1.0,
+		MaxLatencyIncreasePercent: 20.0,
+	}
+}
+
+// IsEnabledRegression returns true if "Regression" is enabled.
+// Otherwise, nil the field for "omitempty".
+func (cfg *Config) IsEnabledRegression() bool {
+	if cfg.Regression == nil {
+		return false
+	}
+	if cfg.Regression.Enable {
+		return true
+	}
+	cfg.Regression = nil
+	return false
+}
+
+func (cfg *Config) validateRegression() error {
+	if !cfg.IsEnabledRegression() {
+		return nil
+	}
+	if cfg.Regression.BaselineS3BucketName == "" {
+		cfg.Regression.BaselineS3BucketName = cfg.S3.BucketName
+	}
+	if cfg.Regression.BaselineS3BucketName == "" {
+		return errors.New("Regression.Enable true but empty Regression.BaselineS3BucketName and S3.BucketName")
+	}
+	if len(cfg.Regression.BaselineS3Keys) == 0 {
+		return errors.New("Regression.Enable true but empty Regression.BaselineS3Keys")
+	}
+	names := requestsSummaryNames()
+	for name, key := range cfg.Regression.BaselineS3Keys {
+		if _, ok := names[name]; !ok {
+			return fmt.Errorf("unknown Regression.BaselineS3Keys name %q (e.g. \"AddOnStresserLocal.RequestsSummaryWrites\")", name)
+		}
+		if key == "" {
+			return fmt.Errorf("empty Regression.BaselineS3Keys S3 key for %q", name)
+		}
+	}
+	if cfg.Regression.MaxSuccessRateDropPercent < 0 {
+		return fmt.Errorf("invalid Regression.MaxSuccessRateDropPercent %v", cfg.Regression.MaxSuccessRateDropPercent)
+	}
+	if cfg.Regression.MaxLatencyIncreasePercent < 0 {
+		return fmt.Errorf("invalid Regression.MaxLatencyIncreasePercent %v", cfg.Regression.MaxLatencyIncreasePercent)
+	}
+	return nil
+}
+
+// RegressionThresholds returns the thresholds of "Regression".
+func (cfg *Config) RegressionThresholds() metrics.RegressionThresholds {
+	if cfg.Regression == nil {
+		return metrics.RegressionThresholds{}
+	}
+	return metrics.RegressionThresholds{
+		MaxSuccessRateDropPercent: cfg.Regression.MaxSuccessRateDropPercent,
+		MaxLatencyIncreasePercent: cfg.Regression.MaxLatencyIncreasePercent,
+	}
+}
+
+var requestsSummaryType = reflect.TypeOf(metrics.RequestsSummary{})
+
+// requestsSummaryNames returns the names of all add-on
+// "RequestsSummary" fields (e.g. "AddOnStresserLocal.RequestsSummaryWrites").
+func requestsSummaryNames() map[string]struct{} {
+	names := make(map[string]struct{})
+	ct := reflect.TypeOf(Config{})
+	for i := 0; i < ct.NumField(); i++ {
+		f := ct.Field(i)
+		if !strings.HasPrefix(f.Name, "AddOn") || f.Type.Kind() != reflect.Ptr || f.Type.Elem().Kind() != reflect.Struct {
+			continue
+		}
+		at := f.Type.Elem()
+		for j := 0; j < at.NumField(); j++ {
+			if at.Field(j).Type == requestsSummaryType {
+				names[f.Name+"."+at.Field(j).Name] = struct{}{}
+			}
+		}
+	}
+	return names
+}
+
+// RequestsSummaries returns the "RequestsSummary" results of the
+// enabled add-ons, keyed by the field name (e.g. "AddOnStresserLocal.RequestsSummaryWrites").
+func (cfg *Config) RequestsSummaries() map[string]metrics.RequestsSummary {
+	cfg.mu.RLock()
+	defer cfg.mu.RUnlock()
+	rs := make(map[string]metrics.RequestsSummary)
+	cv := reflect.ValueOf(cfg).Elem()
+	for name := range requestsSummaryNames() {
+		ss := strings.SplitN(name, ".", 2)
+		fv := cv.FieldByName(ss[0])
+		if fv.IsNil() {
+			continue
+		}
+		if ev := fv.Elem().FieldByName("Enable"); ev.IsValid() && !ev.Bool() {
+			continue
+		}
+		rs[name] = fv.Elem().FieldByName(ss[1]).Interface().(metrics.RequestsSummary)
+	}
+	return rs
+}
+
+// RequestsSummaryJSONArtifact returns the local path and the S3 key of the
+// JSON artifact of the "RequestsSummary" with the name (e.g.
+// "AddOnStresserLocal.RequestsSummaryWritesJSONPath" for
+// "AddOnStresserLocal.RequestsSummaryWrites"), or empty strings if none.
+func (cfg *Config) RequestsSummaryJSONArtifact(name string) (jsonPath string, jsonS3Key string) {
+	cfg.mu.RLock()
+	defer cfg.mu.RUnlock()
+	ss := strings.SplitN(name, ".", 2)
+	if len(ss) != 2 {
+		return "", ""
+	}
+	fv := reflect.ValueOf(cfg).Elem().FieldByName(ss[0])
+	if !fv.IsValid() || fv.Kind() != reflect.Ptr || fv.IsNil() {
+		return "", ""
+	}
+	if v := fv.Elem().FieldByName(ss[1] + "JSONPath"); v.IsValid() && v.Kind() == reflect.String {
+		jsonPath = v.String()
+	}
+	if v := fv.Elem().FieldByName(ss[1] + "JSONS3Key"); v.IsValid() && v.Kind() == reflect.String {
+		jsonS3Key = v.String()
+	}
+	return jsonPath, jsonS3Key
+}
+
+// SetRegressionReports records the regression reports, and
+// returns the sorted names of the regressed results.
+func (cfg *Config) SetRegressionReports(reports map[string]metrics.RegressionReport) (regressed []string) {
+	cfg.mu.Lock()
+	defer cfg.mu.Unlock()
+	if cfg.Regression == nil {
+		return nil
+	}
+	cfg.Regression.Reports = reports
+	for name, r := range reports {
+		if r.Regressed() {
+			regressed = append(regressed, name)
+		}
+	}
+	sort.Strings(regressed)
+	cfg.Regression.Regressed = len(regressed) > 0
+	return regressed
+}
@@ -0,0 +1,133 @@
+package eksconfig
+
+import (
+	"encoding"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// Schema is the JSON Schema (draft-07) of a configuration field.
+type Schema struct {
+	SchemaURI            string             `json:"$schema,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	ReadOnly             bool               `json:"readOnly,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	AdditionalProperties interface{}        `json:"additionalProperties,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+}
+
+// JSONSchema returns the JSON Schema of "Config", generated from the
+// struct fields and "json" tags. Objects reject unknown fields
+// ("additionalProperties": false), and "read-only" fields are marked
+// "readOnly". Use it for editor validation of YAML specs (see "LoadSpec").
+func JSONSchema() ([]byte, error) {
+	s := schemaOf(reflect.TypeOf(Config{}), make(map[reflect.Type]bool))
+	s.SchemaURI = "http://json-schema.org/draft-07/schema#"
+	return json.MarshalIndent(s, "", "  ")
+}
+
+var (
+	durationType        = reflect.TypeOf(time.Duration(0))
+	timeType            = reflect.TypeOf(time.Time{})
+	jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// hasCustomUnmarshal returns true if the type decodes itself,
+// in which case its schema cannot be derived from the fields.
+func hasCustomUnmarshal(tp reflect.Type) bool {
+	pt := reflect.PtrTo(tp)
+	return pt.Implements(jsonUnmarshalerType) || pt.Implements(textUnmarshalerType)
+}
+
+func schemaOf(tp reflect.Type, visiting map[reflect.Type]bool) *Schema {
+	for tp.Kind() == reflect.Ptr {
+		tp = tp.Elem()
+	}
+	switch {
+	case tp == durationType:
+		return &Schema{Type: "integer", Description: "nanoseconds"}
+	case tp == timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case hasCustomUnmarshal(tp):
+		return &Schema{}
+	}
+
+	switch tp.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if tp.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: schemaOf(tp.Elem(), visiting)}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: schemaOf(tp.Elem(), visiting)}
+	case reflect.Struct:
+		if visiting[tp] { // recursive type
+			return &Schema{Type: "object"}
+		}
+		visiting[tp] = true
+		defer delete(visiting, tp)
+
+		s := &Schema{Type: "object", Properties: make(map[string]*Schema), AdditionalProperties: false}
+		for _, f := range jsonFields(tp) {
+			fs := schemaOf(f.Type, visiting)
+			if f.Tag.Get("read-only") == "true" {
+				fs.ReadOnly = true
+			}
+			s.Properties[f.name] = fs
+		}
+		return s
+	}
+	// interface{} accepts any value
+	return &Schema{}
+}
+
+// jsonField is the struct field with its JSON key.
+type jsonField struct {
+	reflect.StructField
+	name string
+}
+
+// jsonFields returns the exported fields as encoded by "encoding/json",
+// with the fields of embedded structs inlined.
+func jsonFields(tp reflect.Type) (fields []jsonField) {
+	for i := 0; i < tp.NumField(); i++ {
+		f := tp.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name := strings.Split(tag, ",")[0]
+		if f.Anonymous && name == "" {
+			et := f.Type
+			if et.Kind() == reflect.Ptr {
+				et = et.Elem()
+			}
+			if et.Kind() == reflect.Struct {
+				fields = append(fields, jsonFields(et)...)
+				continue
+			}
+		}
+		if f.PkgPath != "" { // unexported
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		fields = append(fields, jsonField{StructField: f, name: name})
+	}
+	return fields
+}
@@ -0,0 +1,104 @@
+package eksconfig
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	aws_secrets "github.com/aws/aws-k8s-tester/pkg/aws/secrets"
+	"github.com/aws/aws-k8s-tester/pkg/randutil"
+	"sigs.k8s.io/yaml"
+)
+
+// SecretResolver returns the secret value of the URI
+// (e.g. "ssm:///my-cluster/grafana-password" or "secretsmanager://my-secret#password").
+// ref. "pkg/aws/secrets.Resolver"
+type SecretResolver func(uri string) (string, error)
+
+// secretField is a sensitive field that can be set with a secret URI.
+type secretField struct {
+	name  string
+	value *string
+	// isPath is true if the field is a file path,
+	// in which case the secret is written to a local file.
+	isPath bool
+}
+
+// secretFields returns the sensitive fields.
+func (cfg *Config) secretFields() (fs []secretField) {
+	fs = append(fs, secretField{name: "RemoteAccessPrivateKeyPath", value: &cfg.RemoteAccessPrivateKeyPath, isPath: true})
+	if cfg.AddOnPrometheusGrafana != nil {
+		fs = append(fs, secretField{name: "AddOnPrometheusGrafana.GrafanaAdminPassword", value: &cfg.AddOnPrometheusGrafana.GrafanaAdminPassword})
+	}
+	if cfg.AddOnJupyterHub != nil {
+		fs = append(fs, secretField{name: "AddOnJupyterHub.ProxySecretToken", value: &cfg.AddOnJupyterHub.ProxySecretToken})
+	}
+	if cfg.AddOnWordpress != nil {
+		fs = append(fs, secretField{name: "AddOnWordpress.Password", value: &cfg.AddOnWordpress.Password})
+	}
+	return fs
+}
+
+// HasSecretURIs returns true if any sensitive field is set with
+// an unresolved "ssm://" or "secretsmanager://" URI.
+func (cfg *Config) HasSecretURIs() bool {
+	for _, f := range cfg.secretFields() {
+		if aws_secrets.IsURI(*f.value) {
+			return true
+		}
+	}
+	return false
+}
+
+// ResolveSecrets resolves the sensitive fields set with "ssm://" or
+// "secretsmanager://" URIs (e.g. from environment variables), so that
+// secrets need not be stored in plaintext. Must be called after
+// "UpdateFromEnvs" and before "ValidateAndSetDefaults".
+// The configuration file keeps the URIs, never the resolved secrets.
+// For "RemoteAccessPrivateKeyPath", the resolved private key is written
+// to a temporary file, and the field is set to the file path.
+func (cfg *Config) ResolveSecrets(resolve SecretResolver) error {
+	cfg.mu.Lock()
+	defer cfg.mu.Unlock()
+
+	for _, f := range cfg.secretFields() {
+		uri := *f.value
+		if !aws_secrets.IsURI(uri) {
+			continue
+		}
+		v, err := resolve(uri)
+		if err != nil {
+			return fmt.Errorf("failed to resolve %s %q (%v)", f.name, uri, err)
+		}
+		if f.isPath {
+			p := filepath.Join(os.TempDir(), randutil.String(10)+".insecure.key")
+			if err = ioutil.WriteFile(p, []byte(v), 0600); err != nil {
+				return fmt.Errorf("failed to write %s %q (%v)", f.name, p, err)
+			}
+			v = p
+		}
+		if cfg.secretURIs == nil {
+			cfg.secretURIs = make(map[*string]string)
+		}
+		cfg.secretURIs[f.value] = uri
+		*f.value = v
+	}
+	return nil
+}
+
+// unsafeMarshalYAML marshals the configuration with
+// the resolved secrets replaced by their URIs.
+func (cfg *Config) unsafeMarshalYAML() ([]byte, error) {
+	resolved := make(map[*string]string, len(cfg.secretURIs))
+	for p, uri := range cfg.secretURIs {
+		resolved[p] = *p
+		*p = uri
+	}
+	defer func() {
+		for p, v := range resolved {
+			*p = v
+		}
+	}()
+	return yaml.Marshal(cfg)
+}
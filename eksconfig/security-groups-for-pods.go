@@ -0,0 +1,136 @@
+package eksconfig
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// SecurityGroupsForPods defines the security groups for pods test, where
+// the annotated pods get the branch ENIs with their own security groups.
+// Before the node groups are created, the VPC CNI is configured with
+// "ENABLE_POD_ENI". Once the node groups are created, a server and a client
+// pod are matched by "SecurityGroupPolicy" with the separate security groups,
+// and the client must only reach the server once the server security group
+// allows the client security group.
+// ref. https://docs.aws.amazon.com/eks/latest/userguide/security-groups-for-pods.html
+type SecurityGroupsForPods struct {
+	// Enable is 'true' to enable the security groups for pods.
+	Enable bool `json:"enable"`
+	// InstanceTypes is the list of the instance types that support the
+	// trunk ENI (e.g. Nitro "m5", "c5", not "t3"). The test pods are only
+	// scheduled to these instance types, and at least one managed node group
+	// must only use these instance types.
+	// ref. https://github.com/aws/amazon-vpc-resource-controller-k8s/blob/master/pkg/aws/vpc/limits.go
+	InstanceTypes []string `json:"instance-types"`
+	// Namespace is the namespace of the test pods.
+	Namespace string `json:"namespace"`
+	// Image is the container image of the test pods,
+	// with "httpd" and "wget" (e.g. "busybox").
+	Image string `json:"image"`
+
+	// ServerSecurityGroupID is the security group ID of the server pod.
+	ServerSecurityGroupID string `json:"server-security-group-id" read-only:"true"`
+	// ClientSecurityGroupID is the security group ID of the client pod.
+	ClientSecurityGroupID string `json:"client-security-group-id" read-only:"true"`
+	// BranchENIs maps the test pod name to its branch ENI ID.
+	BranchENIs map[string]string `json:"branch-enis" read-only:"true"`
+}
+
+// SecurityGroupsForPodsPolicyARN is the managed policy required for
+// the cluster role to manage the trunk and branch ENIs.
+const SecurityGroupsForPodsPolicyARN = "arn:aws:iam::aws:policy/AmazonEKSVPCResourceController"
+
+func getDefaultSecurityGroupsForPods() *SecurityGroupsForPods {
+	return &SecurityGroupsForPods{
+		Enable: false,
+		InstanceTypes: []string{
+			"c5.large",
+			"c5.xlarge",
+			"c5.2xlarge",
+			"m5.large",
+			"m5.xlarge",
+			"m5.2xlarge",
+			"r5.large",
+			"r5.xlarge",
+			"r5.2xlarge",
+		},
+		Image: "busybox",
+	}
+}
+
+// IsEnabledSecurityGroupsForPods returns true if "SecurityGroupsForPods" is enabled.
+// Otherwise, nil the field for "omitempty".
+func (cfg *Config) IsEnabledSecurityGroupsForPods() bool {
+	if cfg.SecurityGroupsForPods == nil {
+		return false
+	}
+	if cfg.SecurityGroupsForPods.Enable {
+		return true
+	}
+	cfg.SecurityGroupsForPods = nil
+	return false
+}
+
+// validateSecurityGroupsForPods must be run before the validation of "Role",
+// since the cluster role policy is added.
+func (cfg *Config) validateSecurityGroupsForPods() error {
+	if !cfg.IsEnabledSecurityGroupsForPods() {
+		return nil
+	}
+	if cfg.IsEnabledAutoMode() {
+		return errors.New("SecurityGroupsForPods.Enable true but AutoMode.Enable true")
+	}
+	if !cfg.IsEnabledAddOnManagedNodeGroups() {
+		return errors.New("SecurityGroupsForPods.Enable true but no AddOnManagedNodeGroups")
+	}
+	if len(cfg.SecurityGroupsForPods.InstanceTypes) == 0 {
+		return errors.New("SecurityGroupsForPods.Enable true but empty InstanceTypes")
+	}
+	if cfg.SecurityGroupsForPods.Image == "" {
+		return errors.New("SecurityGroupsForPods.Enable true but empty Image")
+	}
+	if cfg.SecurityGroupsForPods.Namespace == "" {
+		cfg.SecurityGroupsForPods.Namespace = cfg.Name + "-security-groups-for-pods"
+	}
+
+	if cfg.Role.Create {
+		found := false
+		for _, v := range cfg.Role.ManagedPolicyARNs {
+			if v == SecurityGroupsForPodsPolicyARN {
+				found = true
+				break
+			}
+		}
+		if !found {
+			cfg.Role.ManagedPolicyARNs = append(cfg.Role.ManagedPolicyARNs, SecurityGroupsForPodsPolicyARN)
+			sort.Strings(cfg.Role.ManagedPolicyARNs)
+		}
+	}
+	return nil
+}
+
+// validateSecurityGroupsForPodsInstanceTypes must be run after
+// "validateAddOnManagedNodeGroups", which defaults the instance types.
+func (cfg *Config) validateSecurityGroupsForPodsInstanceTypes() error {
+	if !cfg.IsEnabledSecurityGroupsForPods() {
+		return nil
+	}
+	compatible := make(map[string]struct{}, len(cfg.SecurityGroupsForPods.InstanceTypes))
+	for _, v := range cfg.SecurityGroupsForPods.InstanceTypes {
+		compatible[v] = struct{}{}
+	}
+	for _, cur := range cfg.AddOnManagedNodeGroups.MNGs {
+		all := len(cur.InstanceTypes) > 0
+		for _, v := range cur.InstanceTypes {
+			if _, ok := compatible[v]; !ok {
+				all = false
+				break
+			}
+		}
+		if all {
+			return nil
+		}
+	}
+	return fmt.Errorf("SecurityGroupsForPods.Enable true but no AddOnManagedNodeGroups.MNGs with only InstanceTypes %q", cfg.SecurityGroupsForPods.InstanceTypes)
+}
@@ -0,0 +1,69 @@
+package eksconfig
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-k8s-tester/pkg/metrics"
+)
+
+// validateSLO validates the SLO fields of the add-on
+// (e.g. "AddOnStresserLocal.SLOMaxErrorRatePercent").
+func validateSLO(addOn string, maxErrorRatePercent float64, maxLatencyP99 time.Duration) error {
+	if maxErrorRatePercent < 0 || maxErrorRatePercent > 100 {
+		return fmt.Errorf("invalid %s.SLOMaxErrorRatePercent %v (expected [0, 100])", addOn, maxErrorRatePercent)
+	}
+	if maxLatencyP99 < 0 {
+		return fmt.Errorf("invalid %s.SLOMaxLatencyP99 %v", addOn, maxLatencyP99)
+	}
+	return nil
+}
+
+var sloVerdictType = reflect.TypeOf(metrics.SLOVerdict{})
+
+// EvaluateSLOs evaluates the "RequestsSummary" results of the enabled
+// add-ons against their SLOs (e.g. "AddOnStresserLocal.SLOMaxLatencyP99"),
+// records the verdicts in "SLOVerdict" of each add-on, and returns the
+// verdicts keyed by the add-on field name (e.g. "AddOnStresserLocal").
+// Add-ons without any SLO are skipped.
+func (cfg *Config) EvaluateSLOs() map[string]metrics.SLOVerdict {
+	results := make(map[string]map[string]metrics.RequestsSummary)
+	for name, rs := range cfg.RequestsSummaries() {
+		ss := strings.SplitN(name, ".", 2)
+		if _, ok := results[ss[0]]; !ok {
+			results[ss[0]] = make(map[string]metrics.RequestsSummary)
+		}
+		results[ss[0]][ss[1]] = rs
+	}
+	addOns := make([]string, 0, len(results))
+	for addOn := range results {
+		addOns = append(addOns, addOn)
+	}
+	sort.Strings(addOns)
+
+	cfg.mu.Lock()
+	defer cfg.mu.Unlock()
+	verdicts := make(map[string]metrics.SLOVerdict)
+	cv := reflect.ValueOf(cfg).Elem()
+	for _, addOn := range addOns {
+		av := cv.FieldByName(addOn).Elem()
+		ev, lv, vv := av.FieldByName("SLOMaxErrorRatePercent"), av.FieldByName("SLOMaxLatencyP99"), av.FieldByName("SLOVerdict")
+		if !ev.IsValid() || !lv.IsValid() || !vv.IsValid() || vv.Type() != sloVerdictType {
+			continue
+		}
+		slo := metrics.SLO{
+			MaxErrorRatePercent: ev.Float(),
+			MaxLatencyP99:       time.Duration(lv.Int()),
+		}
+		if slo.IsZero() {
+			continue
+		}
+		v := slo.Evaluate(results[addOn])
+		vv.Set(reflect.ValueOf(v))
+		verdicts[addOn] = v
+	}
+	return verdicts
+}
@@ -1,5 +1,17 @@
 package eksconfig
 
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"sigs.k8s.io/yaml" // must use "sigs.k8s.io/yaml"
+)
+
 // Spec contains specs for addons
 type Spec struct {
 	// ClusterAutoscaler defines the addon's spec
@@ -11,3 +23,105 @@ type Spec struct {
 	// ClusterLoader2 defines the addon's spec
 	ClusterLoader *ClusterLoaderSpec `json:"clusterLoader,omitempty"`
 }
+
+// LoadSpec loads the test definition from a YAML spec file
+// (e.g. "--config cluster.yaml"), which has the same field set as
+// the configuration file but must not contain "status".
+// Unlike "Load", the spec file is never written, so that it can live
+// in version control. The states are persisted to "ConfigPath" instead,
+// which defaults to the spec path with ".state.yaml" suffix
+// (e.g. "cluster.state.yaml"). Environment variables are applied
+// on top via "UpdateFromEnvs".
+//
+// Example usage:
+//
+//	import "github.com/aws/aws-k8s-tester/eksconfig"
+//	cfg, err := eksconfig.LoadSpec("cluster.yaml")
+//	err = cfg.UpdateFromEnvs()
+//	err = cfg.ValidateAndSetDefaults()
+//
+// Do not set default values in this function.
+func LoadSpec(p string) (cfg *Config, err error) {
+	var d []byte
+	d, err = ioutil.ReadFile(p)
+	if err != nil {
+		return nil, err
+	}
+	cfg = new(Config)
+	if err = yaml.Unmarshal(d, cfg, yaml.DisallowUnknownFields); err != nil {
+		return nil, fmt.Errorf("failed to parse spec %q (%v)", p, err)
+	}
+	if cfg.Status != nil {
+		return nil, errors.New("spec must not contain read-only 'status'")
+	}
+	cfg.mu = new(sync.RWMutex)
+
+	var ap string
+	ap, err = filepath.Abs(p)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.ConfigPath == "" || cfg.ConfigPath == p || cfg.ConfigPath == ap {
+		cfg.ConfigPath = SpecStatePath(ap)
+	}
+	return cfg, nil
+}
+
+// SpecStatePath returns the default state file path for the spec path.
+func SpecStatePath(specPath string) string {
+	ext := filepath.Ext(specPath)
+	return strings.TrimSuffix(specPath, ext) + ".state.yaml"
+}
+
+// WriteSpec writes the configuration without the read-only "status"
+// to the YAML spec file, so that it can be loaded by "LoadSpec".
+func (cfg *Config) WriteSpec(p string) error {
+	cfg.mu.RLock()
+	spec := *cfg
+	cfg.mu.RUnlock()
+
+	spec.Status = nil
+	spec.ConfigPath = ""
+	d, err := yaml.Marshal(&spec)
+	if err != nil {
+		return fmt.Errorf("failed to 'yaml.Marshal' %v", err)
+	}
+	if err = ioutil.WriteFile(p, d, 0600); err != nil {
+		return fmt.Errorf("failed to write spec %q (%v)", p, err)
+	}
+	return nil
+}
+
+// Effective returns the configuration with all defaults, derived names,
+// and computed fields populated as "ValidateAndSetDefaults" does, in "json"
+// or "yaml" format, to review exactly what the tester intends to create.
+// No AWS API call is made, and neither the receiver nor the configuration
+// file is modified.
+func (cfg *Config) Effective(format string) ([]byte, error) {
+	if format != "json" && format != "yaml" {
+		return nil, fmt.Errorf("unknown format %q (expected \"json\" or \"yaml\")", format)
+	}
+	if cfg.mu == nil {
+		cfg.mu = new(sync.RWMutex)
+	}
+	cfg.mu.Lock()
+	d, err := cfg.unsafeMarshalYAML()
+	cfg.mu.Unlock()
+	if err != nil {
+		return nil, fmt.Errorf("failed to 'yaml.Marshal' %v", err)
+	}
+
+	ec := new(Config)
+	if err = yaml.Unmarshal(d, ec); err != nil {
+		return nil, fmt.Errorf("failed to 'yaml.Unmarshal' %v", err)
+	}
+	ec.mu = new(sync.RWMutex)
+	if err = ec.unsafeValidateAndSetDefaults(); err != nil {
+		return nil, err
+	}
+
+	if format == "json" {
+		return json.MarshalIndent(ec, "", "  ")
+	}
+	return yaml.Marshal(ec)
+}
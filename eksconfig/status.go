@@ -6,6 +6,7 @@ import (
 	"time"
 
 	k8s_client "github.com/aws/aws-k8s-tester/pkg/k8s-client"
+	"github.com/aws/aws-k8s-tester/pkg/metrics"
 	"github.com/aws/aws-k8s-tester/pkg/timeutil"
 	aws_eks "github.com/aws/aws-sdk-go/service/eks"
 )
@@ -38,6 +39,12 @@ type Status struct {
 	// ClusterAPIServerEndpoint is the cluster endpoint of the EKS cluster,
 	// required for KUBECONFIG write.
 	ClusterAPIServerEndpoint string `json:"cluster-api-server-endpoint"`
+	// ClusterEndpointPublicAccess is true if the cluster endpoint
+	// is reachable from outside the VPC, as reported by EKS API.
+	ClusterEndpointPublicAccess bool `json:"cluster-endpoint-public-access"`
+	// ClusterEndpointPrivateAccess is true if the cluster endpoint
+	// is reachable within the VPC, as reported by EKS API.
+	ClusterEndpointPrivateAccess bool `json:"cluster-endpoint-private-access"`
 	// ClusterOIDCIssuerURL is the issuer URL for the OpenID Connect
 	// (https://openid.net/connect/) identity provider .
 	ClusterOIDCIssuerURL string `json:"cluster-oidc-issuer-url"`
@@ -58,6 +65,15 @@ type Status struct {
 	ClusterStatusCurrent string `json:"cluster-status-current"`
 	// ClusterStatus represents the status of the cluster.
 	ClusterStatus []ClusterStatus `json:"cluster-status"`
+	// ClusterAPILatency is the latency of the EKS describe calls
+	// (e.g. "DescribeCluster", "DescribeUpdate") made by the cluster waiters,
+	// accumulated across create, upgrade, and delete.
+	ClusterAPILatency metrics.RequestsSummary `json:"cluster-api-latency" read-only:"true"`
+
+	// RetainedAddOns is the add-ons retained by their cleanup policies
+	// on the last delete (e.g. "AddOnWordpress"). The cluster, node groups,
+	// and VPC are not deleted while any add-on is retained.
+	RetainedAddOns []string `json:"retained-add-ons" read-only:"true"`
 
 	// ClusterAutoscaler defines the addon's status
 	ClusterAutoscaler *ClusterAutoscalerStatus `json:"clusterAutoscaler,omitempty"`
@@ -148,3 +164,53 @@ func (cfg *Config) RecordStatus(status string) {
 	cfg.Status.ClusterStatus = copied
 	cfg.unsafeSync()
 }
+
+// GetClusterAPILatency returns the EKS API latency summary
+// recorded by "RecordClusterAPILatency".
+func (cfg *Config) GetClusterAPILatency() metrics.RequestsSummary {
+	cfg.mu.RLock()
+	defer cfg.mu.RUnlock()
+	if cfg.Status == nil {
+		return metrics.RequestsSummary{}
+	}
+	return cfg.Status.ClusterAPILatency
+}
+
+// RecordClusterAPILatency merges the EKS API latency summary
+// from a cluster waiter into "ClusterAPILatency".
+func (cfg *Config) RecordClusterAPILatency(rs metrics.RequestsSummary) error {
+	cfg.mu.Lock()
+	defer cfg.mu.Unlock()
+
+	if cfg.Status == nil {
+		cfg.Status = &Status{}
+	}
+	if cfg.Status.ClusterAPILatency.SuccessTotal+cfg.Status.ClusterAPILatency.FailureTotal == 0 {
+		cfg.Status.ClusterAPILatency = rs
+		return cfg.unsafeSync()
+	}
+
+	// percentiles cannot be combined, so keep the worst observed
+	prev := cfg.Status.ClusterAPILatency
+	combined, err := metrics.CombineRequestsSummaries(prev, rs)
+	if err != nil {
+		return err
+	}
+	combined.TestID = rs.TestID
+	combined.TotalDuration = prev.TotalDuration + rs.TotalDuration
+	combined.LantencyP50 = maxDuration(prev.LantencyP50, rs.LantencyP50)
+	combined.LantencyP90 = maxDuration(prev.LantencyP90, rs.LantencyP90)
+	combined.LantencyP95 = maxDuration(prev.LantencyP95, rs.LantencyP95)
+	combined.LantencyP99 = maxDuration(prev.LantencyP99, rs.LantencyP99)
+	combined.LantencyP999 = maxDuration(prev.LantencyP999, rs.LantencyP999)
+	combined.LantencyP9999 = maxDuration(prev.LantencyP9999, rs.LantencyP9999)
+	cfg.Status.ClusterAPILatency = combined
+	return cfg.unsafeSync()
+}
+
+func maxDuration(a, b time.Duration) time.Duration {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,200 @@
+package eksconfig
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	yamlv3 "gopkg.in/yaml.v3"
+)
+
+// ValidateStrict validates the YAML configuration (or spec) against
+// the "Config" fields, and returns all unknown fields and type mismatches
+// with their line numbers and field paths
+// (e.g. `line 12: unknown field "add-on-fluentd.enabled"`).
+// It does not validate the field values (see "ValidateAndSetDefaults").
+func ValidateStrict(d []byte) error {
+	var doc yamlv3.Node
+	if err := yamlv3.Unmarshal(d, &doc); err != nil {
+		return err
+	}
+	if doc.Kind != yamlv3.DocumentNode || len(doc.Content) == 0 {
+		return nil
+	}
+	var errs []string
+	validateNode(doc.Content[0], reflect.TypeOf(Config{}), "", &errs)
+	if len(errs) == 0 {
+		return nil
+	}
+	return fmt.Errorf("invalid configuration (%d errors):\n%s", len(errs), strings.Join(errs, "\n"))
+}
+
+func validateNode(n *yamlv3.Node, tp reflect.Type, path string, errs *[]string) {
+	if n.Kind == yamlv3.AliasNode {
+		n = n.Alias
+	}
+	if n.Kind == yamlv3.ScalarNode && n.Tag == "!!null" {
+		return
+	}
+	for tp.Kind() == reflect.Ptr {
+		tp = tp.Elem()
+	}
+	if tp != durationType && tp != timeType && hasCustomUnmarshal(tp) {
+		return
+	}
+
+	mismatch := func(expected string) {
+		got := n.Tag
+		if n.Kind == yamlv3.ScalarNode {
+			got = fmt.Sprintf("%s %q", n.Tag, n.Value)
+		}
+		*errs = append(*errs, fmt.Sprintf("line %d: field %q expected %s, got %s", n.Line, path, expected, got))
+	}
+
+	if tp == timeType {
+		if n.Kind != yamlv3.ScalarNode {
+			mismatch("timestamp")
+		}
+		return
+	}
+
+	switch tp.Kind() {
+	case reflect.Struct:
+		if n.Kind != yamlv3.MappingNode {
+			mismatch("object")
+			return
+		}
+		fields := make(map[string]reflect.Type)
+		for _, f := range jsonFields(tp) {
+			fields[f.name] = f.Type
+		}
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			k, v := n.Content[i], n.Content[i+1]
+			fp := joinFieldPath(path, k.Value)
+			ft, ok := fields[k.Value]
+			if !ok {
+				*errs = append(*errs, fmt.Sprintf("line %d: unknown field %q", k.Line, fp))
+				continue
+			}
+			validateNode(v, ft, fp, errs)
+		}
+
+	case reflect.Map:
+		if n.Kind != yamlv3.MappingNode {
+			mismatch("object")
+			return
+		}
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			validateNode(n.Content[i+1], tp.Elem(), joinFieldPath(path, n.Content[i].Value), errs)
+		}
+
+	case reflect.Slice, reflect.Array:
+		if tp.Elem().Kind() == reflect.Uint8 {
+			if n.Kind != yamlv3.ScalarNode {
+				mismatch("string")
+			}
+			return
+		}
+		if n.Kind != yamlv3.SequenceNode {
+			mismatch("array")
+			return
+		}
+		for i, v := range n.Content {
+			validateNode(v, tp.Elem(), fmt.Sprintf("%s[%d]", path, i), errs)
+		}
+
+	case reflect.Bool:
+		if n.Kind != yamlv3.ScalarNode || n.Tag != "!!bool" {
+			mismatch("bool")
+		}
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if n.Kind != yamlv3.ScalarNode || n.Tag != "!!int" {
+			if tp == durationType {
+				mismatch("integer (nanoseconds)")
+			} else {
+				mismatch("integer")
+			}
+		}
+
+	case reflect.Float32, reflect.Float64:
+		if n.Kind != yamlv3.ScalarNode || (n.Tag != "!!int" && n.Tag != "!!float") {
+			mismatch("number")
+		}
+
+	case reflect.String:
+		// non-string scalars (e.g. version 1.29) are converted to string
+		if n.Kind != yamlv3.ScalarNode {
+			mismatch("string")
+		}
+	}
+}
+
+func joinFieldPath(path string, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// nonFieldEnvs are the environment variables with the "AWS_K8S_TESTER_EKS_"
+// prefix that are not configuration fields.
+var nonFieldEnvs = map[string]struct{}{
+	AWS_K8S_TESTER_EKS_PREFIX + "CONFIG":       {}, // YAML overrides, see "UpdateFromEnvs"
+	AWS_K8S_TESTER_EKS_PREFIX + "CONFIG_INPUT": {}, // kubetest2 deployer
+	AWS_K8S_TESTER_EKS_PRESET:                  {}, // see "ApplyPreset"
+	AWS_K8S_TESTER_EKS_ADD_ONS:                 {}, // see "updateAddOnsFromEnv"
+}
+
+var (
+	lookedUpEnvsMu sync.Mutex
+	lookedUpEnvs   = make(map[string]struct{})
+)
+
+// recordEnvLookup records the environment variable key
+// looked up by "parseEnvs", for "UnknownEnvs".
+func recordEnvLookup(env string) {
+	lookedUpEnvsMu.Lock()
+	lookedUpEnvs[env] = struct{}{}
+	lookedUpEnvsMu.Unlock()
+}
+
+// UnknownEnvs returns the environment variables with the
+// "AWS_K8S_TESTER_EKS_" prefix that do not map to any configuration
+// field (e.g. typo'd or wrong add-on prefix), which are otherwise
+// silently ignored. It must be called after "UpdateFromEnvs".
+func UnknownEnvs() (unknown []string) {
+	lookedUpEnvsMu.Lock()
+	defer lookedUpEnvsMu.Unlock()
+	for _, kv := range os.Environ() {
+		k := strings.SplitN(kv, "=", 2)[0]
+		if !strings.HasPrefix(k, AWS_K8S_TESTER_EKS_PREFIX) {
+			continue
+		}
+		if _, ok := nonFieldEnvs[k]; ok {
+			continue
+		}
+		if _, ok := lookedUpEnvs[k]; !ok {
+			unknown = append(unknown, k)
+		}
+	}
+	sort.Strings(unknown)
+	return unknown
+}
+
+// UpdateFromEnvsStrict is the same as "UpdateFromEnvs", but returns
+// an error if any "AWS_K8S_TESTER_EKS_" environment variable
+// does not map to a configuration field (see "UnknownEnvs").
+func (cfg *Config) UpdateFromEnvsStrict() error {
+	if err := cfg.UpdateFromEnvs(); err != nil {
+		return err
+	}
+	if unknown := UnknownEnvs(); len(unknown) > 0 {
+		return fmt.Errorf("unknown environmental variables %q", unknown)
+	}
+	return nil
+}
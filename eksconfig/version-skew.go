@@ -0,0 +1,92 @@
+package eksconfig
+
+import (
+	"errors"
+	"math"
+)
+
+// VersionSkew defines the version skew checks between the control plane
+// and the managed node groups, whose "MNG.Version" may be older than the
+// cluster "Version" (e.g. N-1, N-2) within the supported skew envelope.
+// The checks run after the node groups are created, and again after the
+// control plane and node group version upgrades.
+// ref. https://kubernetes.io/releases/version-skew-policy/#kubelet
+type VersionSkew struct {
+	// Enable is 'true' to check the version skew.
+	Enable bool `json:"enable"`
+	// Namespace is the namespace of the conformance-lite pods.
+	Namespace string `json:"namespace"`
+	// ConformanceLite is 'true' to run a pod on each node group, which
+	// resolves the cluster DNS, and to read its logs through the kubelet.
+	ConformanceLite bool `json:"conformance-lite"`
+	// ConformanceLiteImage is the container image of the conformance-lite pods.
+	ConformanceLiteImage string `json:"conformance-lite-image"`
+
+	// Checks is the list of the version skew check results.
+	Checks []VersionSkewCheck `json:"checks" read-only:"true"`
+}
+
+// VersionSkewCheck is the result of one version skew check.
+type VersionSkewCheck struct {
+	// Stage is the stage of the check (e.g. "create", "upgrade").
+	Stage string `json:"stage"`
+	// ControlPlaneVersion is the kube-apiserver minor version (e.g. 1.30).
+	ControlPlaneVersion float64 `json:"control-plane-version"`
+	// KubeletVersions maps the managed node group name
+	// to the kubelet minor version of its nodes.
+	KubeletVersions map[string]float64 `json:"kubelet-versions"`
+}
+
+func getDefaultVersionSkew() *VersionSkew {
+	return &VersionSkew{
+		Enable:               false,
+		ConformanceLite:      true,
+		ConformanceLiteImage: "busybox",
+	}
+}
+
+// IsEnabledVersionSkew returns true if "VersionSkew" is enabled.
+// Otherwise, nil the field for "omitempty".
+func (cfg *Config) IsEnabledVersionSkew() bool {
+	if cfg.VersionSkew == nil {
+		return false
+	}
+	if cfg.VersionSkew.Enable {
+		return true
+	}
+	cfg.VersionSkew = nil
+	return false
+}
+
+// KubeletVersionSkewLimit returns the number of minor versions that
+// the kubelet may be older than the control plane version.
+// Kubernetes 1.28 extended the limit from 2 to 3 minor versions.
+func KubeletVersionSkewLimit(controlPlaneVersion float64) int {
+	if MinorVersionSkew(controlPlaneVersion, 1.28) >= 0 {
+		return 3
+	}
+	return 2
+}
+
+// MinorVersionSkew returns the number of minor versions that
+// "nodeVersion" is older than "controlPlaneVersion" (e.g. 2 for 1.30 and 1.28),
+// which is negative if the node version is newer.
+func MinorVersionSkew(controlPlaneVersion float64, nodeVersion float64) int {
+	return int(math.Round((controlPlaneVersion - nodeVersion) * 100))
+}
+
+func (cfg *Config) validateVersionSkew() error {
+	if !cfg.IsEnabledVersionSkew() {
+		return nil
+	}
+	if !cfg.IsEnabledAddOnManagedNodeGroups() {
+		return errors.New("VersionSkew.Enable true but no AddOnManagedNodeGroups")
+	}
+	if cfg.VersionSkew.Namespace == "" {
+		cfg.VersionSkew.Namespace = cfg.Name + "-version-skew"
+	}
+	if cfg.VersionSkew.ConformanceLite && cfg.VersionSkew.ConformanceLiteImage == "" {
+		return errors.New("VersionSkew.ConformanceLite true but empty ConformanceLiteImage")
+	}
+	return nil
+}
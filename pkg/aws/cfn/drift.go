@@ -0,0 +1,180 @@
+package cfn
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-k8s-tester/pkg/ctxutil"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudformation"
+	"github.com/aws/aws-sdk-go/service/cloudformation/cloudformationiface"
+	"go.uber.org/zap"
+)
+
+// ErrStackDrifted is matched by "StackDriftError" via "errors.Is".
+var ErrStackDrifted = errors.New("stack drifted")
+
+// DriftedResource represents a stack resource that was modified
+// or deleted out-of-band.
+type DriftedResource struct {
+	LogicalResourceID  string `json:"logical-resource-id"`
+	PhysicalResourceID string `json:"physical-resource-id"`
+	ResourceType       string `json:"resource-type"`
+	// DriftStatus is either "MODIFIED" or "DELETED".
+	DriftStatus string `json:"drift-status"`
+	// PropertyDifferences are the property paths with the expected
+	// and actual values (e.g. "/Tags/0/Value: abc -> xyz").
+	PropertyDifferences []string `json:"property-differences"`
+}
+
+func (dr DriftedResource) String() string {
+	s := fmt.Sprintf("%s %s (%s, %s)", dr.ResourceType, dr.LogicalResourceID, dr.PhysicalResourceID, dr.DriftStatus)
+	if len(dr.PropertyDifferences) > 0 {
+		s += " [" + strings.Join(dr.PropertyDifferences, ", ") + "]"
+	}
+	return s
+}
+
+// StackDriftError is returned when the stack has drifted resources.
+type StackDriftError struct {
+	StackID   string
+	Resources []DriftedResource
+}
+
+func (e *StackDriftError) Error() string {
+	ss := make([]string, 0, len(e.Resources))
+	for _, v := range e.Resources {
+		ss = append(ss, v.String())
+	}
+	return fmt.Sprintf("stack %q drifted (%d resources: %s)", e.StackID, len(e.Resources), strings.Join(ss, "; "))
+}
+
+// Is returns true if the target is "ErrStackDrifted".
+func (e *StackDriftError) Is(target error) bool {
+	return target == ErrStackDrifted
+}
+
+// DetectDrift triggers stack drift detection and polls its status
+// until the detection completes. It returns the drifted resources
+// with "StackDriftError" if any, nil if the stack is in sync.
+// Use it to assert that long-running stacks (e.g. VPC, IAM role)
+// have not been modified out-of-band.
+func DetectDrift(
+	ctx context.Context,
+	stopc chan struct{},
+	lg *zap.Logger,
+	cfnAPI cloudformationiface.CloudFormationAPI,
+	stackID string,
+	pollInterval time.Duration,
+) ([]DriftedResource, error) {
+	dout, err := cfnAPI.DetectStackDriftWithContext(ctx, &cloudformation.DetectStackDriftInput{
+		StackName: aws.String(stackID),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to detect stack drift %q (%v)", stackID, err)
+	}
+	detectionID := aws.StringValue(dout.StackDriftDetectionId)
+	lg.Info("detecting stack drift",
+		zap.String("stack-id", stackID),
+		zap.String("detection-id", detectionID),
+		zap.String("poll-interval", pollInterval.String()),
+		zap.String("ctx-time-left", ctxutil.TimeLeftTillDeadline(ctx)),
+	)
+
+	for ctx.Err() == nil {
+		select {
+		case <-ctx.Done():
+			lg.Warn("wait aborted, ctx done", zap.Error(ctx.Err()))
+			return nil, ctx.Err()
+		case <-stopc:
+			lg.Warn("wait stopped, stopc closed", zap.Error(ctx.Err()))
+			return nil, errors.New("wait stopped")
+		case <-time.After(pollInterval):
+		}
+
+		output, err := cfnAPI.DescribeStackDriftDetectionStatusWithContext(ctx, &cloudformation.DescribeStackDriftDetectionStatusInput{
+			StackDriftDetectionId: aws.String(detectionID),
+		})
+		if err != nil {
+			lg.Warn("describe stack drift detection status failed; retrying", zap.Error(err))
+			continue
+		}
+
+		status := aws.StringValue(output.DetectionStatus)
+		lg.Info("poll",
+			zap.String("stack-id", stackID),
+			zap.String("detection-status", status),
+			zap.String("drift-status", aws.StringValue(output.StackDriftStatus)),
+			zap.String("ctx-time-left", ctxutil.TimeLeftTillDeadline(ctx)),
+		)
+		switch status {
+		case cloudformation.StackDriftDetectionStatusDetectionInProgress:
+			continue
+		case cloudformation.StackDriftDetectionStatusDetectionFailed:
+			// some resources may not support drift detection
+			return nil, fmt.Errorf("stack drift detection failed %q (%s)", stackID, aws.StringValue(output.DetectionStatusReason))
+		}
+
+		if aws.StringValue(output.StackDriftStatus) != cloudformation.StackDriftStatusDrifted {
+			lg.Info("stack in sync", zap.String("stack-id", stackID))
+			return nil, nil
+		}
+		drifted, err := describeDriftedResources(ctx, cfnAPI, stackID)
+		if err != nil {
+			return nil, err
+		}
+		lg.Warn("stack drifted",
+			zap.String("stack-id", stackID),
+			zap.Int("drifted-resources", len(drifted)),
+		)
+		return drifted, &StackDriftError{StackID: stackID, Resources: drifted}
+	}
+	return nil, ctx.Err()
+}
+
+// describeDriftedResources returns the modified or deleted stack resources
+// from the last drift detection.
+func describeDriftedResources(ctx context.Context, cfnAPI cloudformationiface.CloudFormationAPI, stackID string) (drifted []DriftedResource, err error) {
+	input := &cloudformation.DescribeStackResourceDriftsInput{
+		StackName: aws.String(stackID),
+		StackResourceDriftStatusFilters: aws.StringSlice([]string{
+			cloudformation.StackResourceDriftStatusModified,
+			cloudformation.StackResourceDriftStatusDeleted,
+		}),
+	}
+	for {
+		output, err := cfnAPI.DescribeStackResourceDriftsWithContext(ctx, input)
+		if err != nil {
+			return nil, fmt.Errorf("failed to describe stack resource drifts %q (%v)", stackID, err)
+		}
+		for _, v := range output.StackResourceDrifts {
+			if v == nil {
+				continue
+			}
+			dr := DriftedResource{
+				LogicalResourceID:  aws.StringValue(v.LogicalResourceId),
+				PhysicalResourceID: aws.StringValue(v.PhysicalResourceId),
+				ResourceType:       aws.StringValue(v.ResourceType),
+				DriftStatus:        aws.StringValue(v.StackResourceDriftStatus),
+			}
+			for _, pd := range v.PropertyDifferences {
+				if pd == nil {
+					continue
+				}
+				dr.PropertyDifferences = append(dr.PropertyDifferences, fmt.Sprintf("%s: %s -> %s",
+					aws.StringValue(pd.PropertyPath),
+					aws.StringValue(pd.ExpectedValue),
+					aws.StringValue(pd.ActualValue),
+				))
+			}
+			drifted = append(drifted, dr)
+		}
+		if aws.StringValue(output.NextToken) == "" {
+			return drifted, nil
+		}
+		input.NextToken = output.NextToken
+	}
+}
@@ -0,0 +1,235 @@
+package cfn
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/cloudformation"
+	"github.com/aws/aws-sdk-go/service/cloudformation/cloudformationiface"
+	"go.uber.org/zap"
+)
+
+type fakeDetection struct {
+	detectionStatus string
+	driftStatus     string
+	reason          string
+	err             error
+}
+
+// fakeCFNAPI returns the drift detection statuses in order,
+// and repeats the last status once exhausted.
+type fakeCFNAPI struct {
+	cloudformationiface.CloudFormationAPI
+
+	detectErr  error
+	detections []fakeDetection
+	// pages are the drifted resources returned per page.
+	pages    [][]*cloudformation.StackResourceDrift
+	driftErr error
+
+	describeCalls int
+	filters       []string
+}
+
+func (f *fakeCFNAPI) DetectStackDriftWithContext(ctx aws.Context, input *cloudformation.DetectStackDriftInput, opts ...request.Option) (*cloudformation.DetectStackDriftOutput, error) {
+	if f.detectErr != nil {
+		return nil, f.detectErr
+	}
+	return &cloudformation.DetectStackDriftOutput{StackDriftDetectionId: aws.String("detection-1")}, nil
+}
+
+func (f *fakeCFNAPI) DescribeStackDriftDetectionStatusWithContext(ctx aws.Context, input *cloudformation.DescribeStackDriftDetectionStatusInput, opts ...request.Option) (*cloudformation.DescribeStackDriftDetectionStatusOutput, error) {
+	f.describeCalls++
+	idx := f.describeCalls - 1
+	if idx >= len(f.detections) {
+		idx = len(f.detections) - 1
+	}
+	dv := f.detections[idx]
+	if dv.err != nil {
+		return nil, dv.err
+	}
+	return &cloudformation.DescribeStackDriftDetectionStatusOutput{
+		StackDriftDetectionId: input.StackDriftDetectionId,
+		DetectionStatus:       aws.String(dv.detectionStatus),
+		DetectionStatusReason: aws.String(dv.reason),
+		StackDriftStatus:      aws.String(dv.driftStatus),
+	}, nil
+}
+
+func (f *fakeCFNAPI) DescribeStackResourceDriftsWithContext(ctx aws.Context, input *cloudformation.DescribeStackResourceDriftsInput, opts ...request.Option) (*cloudformation.DescribeStackResourceDriftsOutput, error) {
+	if f.driftErr != nil {
+		return nil, f.driftErr
+	}
+	f.filters = aws.StringValueSlice(input.StackResourceDriftStatusFilters)
+	idx := 0
+	if input.NextToken != nil {
+		idx, _ = strconv.Atoi(aws.StringValue(input.NextToken))
+	}
+	output := &cloudformation.DescribeStackResourceDriftsOutput{StackResourceDrifts: f.pages[idx]}
+	if idx+1 < len(f.pages) {
+		output.NextToken = aws.String(strconv.Itoa(idx + 1))
+	}
+	return output, nil
+}
+
+func TestDetectDrift(t *testing.T) {
+	inProgress := fakeDetection{detectionStatus: cloudformation.StackDriftDetectionStatusDetectionInProgress}
+	pages := [][]*cloudformation.StackResourceDrift{
+		{
+			{
+				LogicalResourceId:        aws.String("VPC"),
+				PhysicalResourceId:       aws.String("vpc-1"),
+				ResourceType:             aws.String("AWS::EC2::VPC"),
+				StackResourceDriftStatus: aws.String(cloudformation.StackResourceDriftStatusModified),
+				PropertyDifferences: []*cloudformation.PropertyDifference{
+					{PropertyPath: aws.String("/Tags/0/Value"), ExpectedValue: aws.String("abc"), ActualValue: aws.String("xyz")},
+				},
+			},
+		},
+		{
+			{
+				LogicalResourceId:        aws.String("Role"),
+				PhysicalResourceId:       aws.String("role-1"),
+				ResourceType:             aws.String("AWS::IAM::Role"),
+				StackResourceDriftStatus: aws.String(cloudformation.StackResourceDriftStatusDeleted),
+			},
+		},
+	}
+
+	tt := []struct {
+		name         string
+		api          *fakeCFNAPI
+		timeout      time.Duration
+		pollInterval time.Duration
+		stop         bool
+
+		expDrifted []DriftedResource
+		expCalls   int
+		expErr     func(error) bool
+	}{
+		{
+			name: "in sync",
+			api: &fakeCFNAPI{detections: []fakeDetection{
+				inProgress,
+				{err: errors.New("Throttling: Rate exceeded")},
+				{detectionStatus: cloudformation.StackDriftDetectionStatusDetectionComplete, driftStatus: cloudformation.StackDriftStatusInSync},
+			}},
+			expCalls: 3,
+		},
+		{
+			name: "drifted",
+			api: &fakeCFNAPI{
+				detections: []fakeDetection{
+					inProgress,
+					{detectionStatus: cloudformation.StackDriftDetectionStatusDetectionComplete, driftStatus: cloudformation.StackDriftStatusDrifted},
+				},
+				pages: pages,
+			},
+			expDrifted: []DriftedResource{
+				{
+					LogicalResourceID:   "VPC",
+					PhysicalResourceID:  "vpc-1",
+					ResourceType:        "AWS::EC2::VPC",
+					DriftStatus:         cloudformation.StackResourceDriftStatusModified,
+					PropertyDifferences: []string{"/Tags/0/Value: abc -> xyz"},
+				},
+				{
+					LogicalResourceID:  "Role",
+					PhysicalResourceID: "role-1",
+					ResourceType:       "AWS::IAM::Role",
+					DriftStatus:        cloudformation.StackResourceDriftStatusDeleted,
+				},
+			},
+			expCalls: 2,
+			expErr: func(err error) bool {
+				var driftErr *StackDriftError
+				return errors.Is(err, ErrStackDrifted) && errors.As(err, &driftErr) && len(driftErr.Resources) == 2
+			},
+		},
+		{
+			name: "detection failed",
+			api: &fakeCFNAPI{detections: []fakeDetection{
+				{detectionStatus: cloudformation.StackDriftDetectionStatusDetectionFailed, reason: "resource type not supported"},
+			}},
+			expCalls: 1,
+			expErr: func(err error) bool {
+				return err != nil && strings.Contains(err.Error(), "resource type not supported")
+			},
+		},
+		{
+			name:   "detect stack drift failed",
+			api:    &fakeCFNAPI{detectErr: errors.New("ValidationError: Stack does not exist")},
+			expErr: func(err error) bool { return err != nil && strings.Contains(err.Error(), "Stack does not exist") },
+		},
+		{
+			name: "describe stack resource drifts failed",
+			api: &fakeCFNAPI{
+				detections: []fakeDetection{
+					{detectionStatus: cloudformation.StackDriftDetectionStatusDetectionComplete, driftStatus: cloudformation.StackDriftStatusDrifted},
+				},
+				driftErr: errors.New("AccessDenied"),
+			},
+			expCalls: 1,
+			expErr:   func(err error) bool { return err != nil && strings.Contains(err.Error(), "AccessDenied") },
+		},
+		{
+			name:     "timeout",
+			api:      &fakeCFNAPI{detections: []fakeDetection{inProgress}},
+			timeout:  50 * time.Millisecond,
+			expErr:   func(err error) bool { return errors.Is(err, context.DeadlineExceeded) },
+			expCalls: -1,
+		},
+		{
+			name:         "stopped",
+			api:          &fakeCFNAPI{detections: []fakeDetection{inProgress}},
+			pollInterval: time.Hour,
+			stop:         true,
+			expErr:       func(err error) bool { return err != nil && strings.Contains(err.Error(), "wait stopped") },
+		},
+	}
+	for _, tv := range tt {
+		t.Run(tv.name, func(t *testing.T) {
+			timeout, pollInterval := tv.timeout, tv.pollInterval
+			if timeout == 0 {
+				timeout = 10 * time.Second
+			}
+			if pollInterval == 0 {
+				pollInterval = time.Millisecond
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), timeout)
+			defer cancel()
+			stopc := make(chan struct{})
+			if tv.stop {
+				close(stopc)
+			}
+
+			drifted, err := DetectDrift(ctx, stopc, zap.NewExample(), tv.api, "my-stack", pollInterval)
+			if tv.expErr == nil && err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if tv.expErr != nil && !tv.expErr(err) {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if !reflect.DeepEqual(drifted, tv.expDrifted) {
+				t.Fatalf("expected drifted resources %+v, got %+v", tv.expDrifted, drifted)
+			}
+			// the number of polls until timeout varies
+			if tv.expCalls >= 0 && tv.api.describeCalls != tv.expCalls {
+				t.Fatalf("expected DescribeStackDriftDetectionStatus calls %d, got %d", tv.expCalls, tv.api.describeCalls)
+			}
+			if len(tv.expDrifted) > 0 && !reflect.DeepEqual(tv.api.filters, []string{
+				cloudformation.StackResourceDriftStatusModified,
+				cloudformation.StackResourceDriftStatusDeleted,
+			}) {
+				t.Fatalf("unexpected drift status filters %q", tv.api.filters)
+			}
+		})
+	}
+}
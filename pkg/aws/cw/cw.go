@@ -88,8 +88,12 @@ func PutData(lg *zap.Logger, cwAPI cloudwatchiface.CloudWatchAPI, namespace stri
 			}
 
 			time.Sleep(500 * time.Millisecond)
+			batch = make([]*cloudwatch.MetricDatum, 0, batchSize)
 		}
 	}
+	if len(batch) == 0 {
+		return err
+	}
 
 	lg.Info("sending last batch", zap.Int("last-batch", len(batch)))
 	req, _ := cwAPI.PutMetricDataRequest(&cloudwatch.PutMetricDataInput{
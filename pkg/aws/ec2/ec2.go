@@ -5,6 +5,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sort"
 	"time"
 
 	"github.com/aws/aws-k8s-tester/pkg/ctxutil"
@@ -173,3 +174,48 @@ func pollUntilRunning(
 	)
 	return ec2Instances, err
 }
+
+// AppendTags appends the extra tags to the EC2 tag list in key order,
+// skipping the keys that are already in the list.
+func AppendTags(tags []aws_ec2_v2_types.Tag, extra map[string]string) []aws_ec2_v2_types.Tag {
+	for _, k := range newTagKeys(tags, extra) {
+		tags = append(tags, aws_ec2_v2_types.Tag{
+			Key:   aws_v2.String(k),
+			Value: aws_v2.String(extra[k]),
+		})
+	}
+	return tags
+}
+
+// AppendASGTags appends the extra tags to the ASG tag list in key order,
+// skipping the keys that are already in the list. Appended tags are
+// propagated to the EC2 instances launched by the ASG.
+func AppendASGTags(tags []aws_asg_v2_types.Tag, extra map[string]string) []aws_asg_v2_types.Tag {
+	existing := make([]aws_ec2_v2_types.Tag, 0, len(tags))
+	for _, tg := range tags {
+		existing = append(existing, aws_ec2_v2_types.Tag{Key: tg.Key})
+	}
+	for _, k := range newTagKeys(existing, extra) {
+		tags = append(tags, aws_asg_v2_types.Tag{
+			Key:               aws_v2.String(k),
+			Value:             aws_v2.String(extra[k]),
+			PropagateAtLaunch: aws_v2.Bool(true),
+		})
+	}
+	return tags
+}
+
+func newTagKeys(tags []aws_ec2_v2_types.Tag, extra map[string]string) (keys []string) {
+	existing := make(map[string]struct{}, len(tags))
+	for _, tg := range tags {
+		existing[aws_v2.ToString(tg.Key)] = struct{}{}
+	}
+	for k := range extra {
+		if _, ok := existing[k]; ok {
+			continue
+		}
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
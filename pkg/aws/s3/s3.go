@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"net/url"
 	"os"
 	"path"
 	"path/filepath"
@@ -149,7 +150,10 @@ func Upload(
 	s3API s3iface.S3API,
 	bucket string,
 	s3Key string,
-	fpath string) error {
+	fpath string,
+	opts ...OpOption) error {
+	ret := Op{}
+	ret.applyOpts(opts)
 
 	if !fileutil.Exist(fpath) {
 		return fmt.Errorf("file %q does not exist; failed to upload to %s/%s", fpath, bucket, s3Key)
@@ -188,6 +192,7 @@ func Upload(
 				"Kind": aws.String("aws-k8s-tester"),
 				"User": aws.String(user.Get()),
 			},
+			Tagging: ret.tagging(),
 		})
 		if err == nil {
 			lg.Info("uploaded",
@@ -225,7 +230,10 @@ func UploadBody(
 	s3API s3iface.S3API,
 	bucket string,
 	s3Key string,
-	body io.ReadSeeker) (err error) {
+	body io.ReadSeeker,
+	opts ...OpOption) (err error) {
+	ret := Op{}
+	ret.applyOpts(opts)
 
 	lg.Info("uploading",
 		zap.String("s3-bucket", bucket),
@@ -246,6 +254,7 @@ func UploadBody(
 			"Kind": aws.String("aws-k8s-tester"),
 			"User": aws.String(user.Get()),
 		},
+		Tagging: ret.tagging(),
 	})
 	if err == nil {
 		lg.Info("uploaded",
@@ -672,6 +681,7 @@ type Op struct {
 	verbose   bool
 	overwrite bool
 	timeout   time.Duration
+	tags      map[string]string
 }
 
 // OpOption configures archiver operations.
@@ -692,6 +702,28 @@ func WithTimeout(timeout time.Duration) OpOption {
 	return func(op *Op) { op.timeout = timeout }
 }
 
+// WithTags configures object tags for uploads.
+func WithTags(tags map[string]string) OpOption {
+	return func(op *Op) { op.tags = tags }
+}
+
+func (op *Op) tagging() *string {
+	return NewTagging(op.tags)
+}
+
+// NewTagging returns the URL-encoded object tags (e.g. "Key1=Value1&Key2=Value2")
+// for PutObject requests, or nil if the tags are empty.
+func NewTagging(tags map[string]string) *string {
+	if len(tags) == 0 {
+		return nil
+	}
+	vs := url.Values{}
+	for k, v := range tags {
+		vs.Set(k, v)
+	}
+	return aws.String(vs.Encode())
+}
+
 func (op *Op) applyOpts(opts []OpOption) {
 	for _, opt := range opts {
 		opt(op)
@@ -0,0 +1,123 @@
+// Package secrets implements secret resolution from SSM Parameter Store and Secrets Manager.
+package secrets
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/secretsmanager"
+	"github.com/aws/aws-sdk-go/service/secretsmanager/secretsmanageriface"
+	"github.com/aws/aws-sdk-go/service/ssm"
+	"github.com/aws/aws-sdk-go/service/ssm/ssmiface"
+	"go.uber.org/zap"
+)
+
+const (
+	// SchemeSSM is the URI scheme for SSM Parameter Store parameters.
+	// e.g. "ssm:///my-cluster/grafana-password" for parameter "/my-cluster/grafana-password".
+	SchemeSSM = "ssm://"
+	// SchemeSecretsManager is the URI scheme for Secrets Manager secrets.
+	// e.g. "secretsmanager://my-secret" or "secretsmanager://my-secret#password"
+	// to read the "password" key of the JSON secret string.
+	SchemeSecretsManager = "secretsmanager://"
+)
+
+// IsURI returns true if the value is a secret URI.
+func IsURI(s string) bool {
+	return strings.HasPrefix(s, SchemeSSM) || strings.HasPrefix(s, SchemeSecretsManager)
+}
+
+// ParseURI parses the secret URI into the scheme, the SSM parameter name
+// or Secrets Manager secret ID, and the optional JSON key.
+func ParseURI(uri string) (scheme string, id string, key string, err error) {
+	switch {
+	case strings.HasPrefix(uri, SchemeSSM):
+		scheme, id = SchemeSSM, strings.TrimPrefix(uri, SchemeSSM)
+	case strings.HasPrefix(uri, SchemeSecretsManager):
+		scheme, id = SchemeSecretsManager, strings.TrimPrefix(uri, SchemeSecretsManager)
+		if idx := strings.LastIndex(id, "#"); idx >= 0 {
+			id, key = id[:idx], id[idx+1:]
+			if key == "" {
+				return "", "", "", fmt.Errorf("empty JSON key in %q", uri)
+			}
+		}
+	default:
+		return "", "", "", fmt.Errorf("unknown secret URI scheme %q (expected %q or %q)", uri, SchemeSSM, SchemeSecretsManager)
+	}
+	if id == "" {
+		return "", "", "", fmt.Errorf("empty secret name in %q", uri)
+	}
+	return scheme, id, key, nil
+}
+
+// Resolver resolves secret URIs.
+type Resolver struct {
+	Logger            *zap.Logger
+	SSMAPI            ssmiface.SSMAPI
+	SecretsManagerAPI secretsmanageriface.SecretsManagerAPI
+}
+
+// Resolve returns the secret value of the URI.
+// SSM "SecureString" parameters are decrypted.
+// The secret value is never logged.
+func (r *Resolver) Resolve(uri string) (string, error) {
+	scheme, id, key, err := ParseURI(uri)
+	if err != nil {
+		return "", err
+	}
+	r.Logger.Info("resolving secret", zap.String("uri", uri))
+
+	switch scheme {
+	case SchemeSSM:
+		if r.SSMAPI == nil {
+			return "", errors.New("empty SSMAPI")
+		}
+		out, err := r.SSMAPI.GetParameter(&ssm.GetParameterInput{
+			Name:           aws.String(id),
+			WithDecryption: aws.Bool(true),
+		})
+		if err != nil {
+			return "", fmt.Errorf("failed to get SSM parameter %q (%v)", id, err)
+		}
+		if out.Parameter == nil {
+			return "", fmt.Errorf("SSM parameter %q not found", id)
+		}
+		return aws.StringValue(out.Parameter.Value), nil
+
+	case SchemeSecretsManager:
+		if r.SecretsManagerAPI == nil {
+			return "", errors.New("empty SecretsManagerAPI")
+		}
+		out, err := r.SecretsManagerAPI.GetSecretValue(&secretsmanager.GetSecretValueInput{
+			SecretId: aws.String(id),
+		})
+		if err != nil {
+			return "", fmt.Errorf("failed to get secret %q (%v)", id, err)
+		}
+		v := aws.StringValue(out.SecretString)
+		if v == "" && len(out.SecretBinary) > 0 {
+			v = string(out.SecretBinary)
+		}
+		if key == "" {
+			return v, nil
+		}
+		kv := make(map[string]interface{})
+		if err = json.Unmarshal([]byte(v), &kv); err != nil {
+			return "", fmt.Errorf("secret %q is not a JSON object (%v)", id, err)
+		}
+		fv, ok := kv[key]
+		if !ok {
+			return "", fmt.Errorf("secret %q has no key %q", id, key)
+		}
+		switch tv := fv.(type) {
+		case string:
+			return tv, nil
+		default:
+			return fmt.Sprint(tv), nil
+		}
+	}
+	return "", fmt.Errorf("unknown secret URI scheme %q", scheme)
+}
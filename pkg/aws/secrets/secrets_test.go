@@ -0,0 +1,41 @@
+package secrets
+
+import "testing"
+
+func TestParseURI(t *testing.T) {
+	tt := []struct {
+		uri    string
+		scheme string
+		id     string
+		key    string
+		err    bool
+	}{
+		{uri: "ssm:///my-cluster/grafana-password", scheme: SchemeSSM, id: "/my-cluster/grafana-password"},
+		{uri: "ssm://grafana-password", scheme: SchemeSSM, id: "grafana-password"},
+		{uri: "secretsmanager://my-secret", scheme: SchemeSecretsManager, id: "my-secret"},
+		{uri: "secretsmanager://my-secret#password", scheme: SchemeSecretsManager, id: "my-secret", key: "password"},
+		{uri: "secretsmanager://arn:aws:secretsmanager:us-west-2:123:secret:my-secret-AbCdEf#password", scheme: SchemeSecretsManager, id: "arn:aws:secretsmanager:us-west-2:123:secret:my-secret-AbCdEf", key: "password"},
+		{uri: "secretsmanager://my-secret#", err: true},
+		{uri: "ssm://", err: true},
+		{uri: "s3://bucket/key", err: true},
+		{uri: "plaintext", err: true},
+	}
+	for i, tv := range tt {
+		scheme, id, key, err := ParseURI(tv.uri)
+		if tv.err {
+			if err == nil {
+				t.Fatalf("#%d: expected error for %q", i, tv.uri)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("#%d: %v", i, err)
+		}
+		if scheme != tv.scheme || id != tv.id || key != tv.key {
+			t.Fatalf("#%d: expected (%q, %q, %q), got (%q, %q, %q)", i, tv.scheme, tv.id, tv.key, scheme, id, key)
+		}
+		if !IsURI(tv.uri) {
+			t.Fatalf("#%d: expected secret URI %q", i, tv.uri)
+		}
+	}
+}
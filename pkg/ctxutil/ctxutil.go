@@ -3,31 +3,77 @@ package ctxutil
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 )
 
+// ErrStopped is the cause of the context cancelled by
+// the stop channel (see "WithStopc" and "context.Cause").
+var ErrStopped = errors.New("stop channel closed")
+
+// WithStopc returns a copy of the context that is cancelled when
+// the stop channel is closed, with "ErrStopped" as the cause,
+// so that callers only need to watch the context.
+// The cancel function must be called to release the resources.
+func WithStopc(ctx context.Context, stopc <-chan struct{}) (context.Context, context.CancelFunc) {
+	cctx, cancel := context.WithCancelCause(ctx)
+	go func() {
+		select {
+		case <-stopc:
+			cancel(ErrStopped)
+		case <-cctx.Done():
+		}
+	}()
+	return cctx, func() { cancel(nil) }
+}
+
+// IsStopped returns true if the context was cancelled
+// by the stop channel (see "WithStopc").
+func IsStopped(ctx context.Context) bool {
+	return errors.Is(context.Cause(ctx), ErrStopped)
+}
+
+// TimeLeft returns the time.Duration left till deadline,
+// and false if the context has no deadline.
+// It returns zero if the context is already done.
+func TimeLeft(ctx context.Context) (time.Duration, bool) {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return time.Duration(0), false
+	}
+	if ctx.Err() != nil {
+		return time.Duration(0), true
+	}
+	left := deadline.UTC().Sub(time.Now().UTC())
+	if left < 0 {
+		left = time.Duration(0)
+	}
+	return left, true
+}
+
 // TimeLeftTillDeadline returns the humanized string for time-left
 // till deadline if there's any.
 func TimeLeftTillDeadline(ctx context.Context) string {
 	if ctx.Err() != nil {
 		return fmt.Sprintf("ctx error (%v)", ctx.Err())
 	}
-	deadline, ok := ctx.Deadline()
+	left, ok := TimeLeft(ctx)
 	if !ok {
 		return "∞"
 	}
-	return deadline.UTC().Sub(time.Now().UTC()).String()
+	return left.String()
 }
 
 // DurationTillDeadline returns the time.Duration left till deadline.
+// It returns an hour if the context has no deadline.
 func DurationTillDeadline(ctx context.Context) time.Duration {
 	if ctx.Err() != nil {
 		return time.Duration(0)
 	}
-	deadline, ok := ctx.Deadline()
+	left, ok := TimeLeft(ctx)
 	if !ok {
 		return time.Hour
 	}
-	return deadline.UTC().Sub(time.Now().UTC())
+	return left
 }
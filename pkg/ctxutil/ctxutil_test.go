@@ -14,3 +14,51 @@ func TestTimeLeftTillDeadline(t *testing.T) {
 	cancel()
 	fmt.Println(TimeLeftTillDeadline(ctx))
 }
+
+func TestTimeLeft(t *testing.T) {
+	if left, ok := TimeLeft(context.TODO()); ok || left != 0 {
+		t.Fatalf("expected no deadline, got %v %v", left, ok)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Hour)
+	left, ok := TimeLeft(ctx)
+	if !ok {
+		t.Fatal("expected deadline")
+	}
+	if left <= 0 || left > time.Hour {
+		t.Fatalf("unexpected time left %v", left)
+	}
+
+	cancel()
+	if left, ok = TimeLeft(ctx); !ok || left != 0 {
+		t.Fatalf("expected zero time left after cancel, got %v %v", left, ok)
+	}
+}
+
+func TestWithStopc(t *testing.T) {
+	stopc := make(chan struct{})
+	ctx, cancel := WithStopc(context.Background(), stopc)
+	defer cancel()
+	if IsStopped(ctx) {
+		t.Fatal("unexpected stopped before close")
+	}
+	close(stopc)
+	select {
+	case <-ctx.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("ctx not cancelled after stopc closed")
+	}
+	if !IsStopped(ctx) {
+		t.Fatalf("expected stopped, got cause %v", context.Cause(ctx))
+	}
+
+	ctx, cancel = WithStopc(context.Background(), nil)
+	cancel()
+	<-ctx.Done()
+	if IsStopped(ctx) {
+		t.Fatal("unexpected stopped after cancel")
+	}
+	if ctx.Err() != context.Canceled {
+		t.Fatalf("expected %v, got %v", context.Canceled, ctx.Err())
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"io"
 	"io/ioutil"
 	"net/http"
+	"net/url"
 	"os"
 	"strconv"
 	"time"
@@ -37,13 +38,30 @@ func Read(lg *zap.Logger, progressWriter io.Writer, downloadURL string) (data []
 // ReadInsecure downloads the file with progress bar.
 // The progress is written to the writer.
 func ReadInsecure(lg *zap.Logger, progressWriter io.Writer, downloadURL string) (data []byte, err error) {
+	return ReadInsecureProxy(lg, progressWriter, downloadURL, "")
+}
+
+// ReadInsecureProxy downloads the file with progress bar,
+// via the proxy URL (e.g. "socks5://127.0.0.1:1080").
+// If the proxy URL is empty, it connects directly.
+// The progress is written to the writer.
+func ReadInsecureProxy(lg *zap.Logger, progressWriter io.Writer, downloadURL string, proxyURL string) (data []byte, err error) {
+	tr := &http.Transport{
+		TLSClientConfig: &tls.Config{
+			InsecureSkipVerify: true,
+		},
+	}
+	if proxyURL != "" {
+		u, err := url.Parse(proxyURL)
+		if err != nil {
+			return nil, err
+		}
+		tr.Proxy = http.ProxyURL(u)
+	}
 	cli := &http.Client{
-		Timeout: 5 * time.Second,
-		Transport: &http.Transport{
-			TLSClientConfig: &tls.Config{
-				InsecureSkipVerify: true,
-			},
-		}}
+		Timeout:   5 * time.Second,
+		Transport: tr,
+	}
 	rd, closeFunc, err := createReader(lg, cli, progressWriter, downloadURL)
 	if err != nil {
 		return nil, err
@@ -10,6 +10,7 @@ import (
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"net/url"
 	"os"
 	"path"
 	"path/filepath"
@@ -56,6 +57,10 @@ type EKSConfig struct {
 	// ClusterName is the EKS cluster name.
 	// Used for EKS auth provider configuration.
 	ClusterName string
+	// ClusterID is the cluster identifier of the authentication tokens,
+	// which differs from "ClusterName" for EKS local clusters on Outposts.
+	// If empty, "ClusterName" is used.
+	ClusterID string
 	// ClusterAPIServerEndpoint is the EKS kube-apiserver endpoint.
 	// Use for kubeconfig.
 	ClusterAPIServerEndpoint string
@@ -82,6 +87,9 @@ type EKSConfig struct {
 	EnablePrompt bool
 	// Dir is the directory to store all upgrade/rollback files.
 	Dir string
+	// ProxyURL is the proxy URL for kube-apiserver requests
+	// (e.g. "socks5://127.0.0.1:1080" for private-only cluster endpoint).
+	ProxyURL string
 
 	S3API                              s3iface.S3API
 	S3BucketName                       string
@@ -317,6 +325,14 @@ func createClient(cfg *EKSConfig) (cli *kubernetes.Clientset, ext *apiextensions
 		err = errors.New("failed to create restclient.Config config")
 		return nil, nil, err
 	}
+	if cfg.ProxyURL != "" {
+		u, perr := url.Parse(cfg.ProxyURL)
+		if perr != nil {
+			return nil, nil, perr
+		}
+		cfg.Logger.Info("using proxy for kube-apiserver requests", zap.String("proxy-url", cfg.ProxyURL))
+		kcfg.Proxy = http.ProxyURL(u)
+	}
 
 	if cfg.ClusterAPIServerEndpoint == "" {
 		cfg.ClusterAPIServerEndpoint = kcfg.Host
@@ -476,6 +492,7 @@ func createClientConfigEKS(cfg *EKSConfig) *restclient.Config {
 			Config: map[string]string{
 				"region":       cfg.Region,
 				"cluster-name": cfg.ClusterName,
+				"cluster-id":   cfg.ClusterID,
 			},
 		},
 	}
@@ -495,6 +512,10 @@ func newAuthProviderEKS(_ string, config map[string]string, _ restclient.AuthPro
 		return nil, fmt.Errorf("'clientcmdapi.AuthProviderConfig' does not include 'cluster-name' key %+v", config)
 	}
 
+	if clusterID := config["cluster-id"]; clusterID != "" {
+		clusterName = clusterID
+	}
+
 	sess := session.Must(session.NewSession(aws.NewConfig().WithRegion(awsRegion)))
 	return &eksAuthProvider{ts: newTokenSourceEKS(sess, clusterName)}, nil
 }
@@ -600,7 +621,7 @@ func (e *eks) checkHealth() error {
 	fmt.Printf("\n\"kubectl version\" info output:\n%s\n\n", vf.String())
 
 	ep := e.cfg.ClusterAPIServerEndpoint + "/version"
-	output, err = httputil.ReadInsecure(e.cfg.Logger, ioutil.Discard, ep)
+	output, err = httputil.ReadInsecureProxy(e.cfg.Logger, ioutil.Discard, ep, e.cfg.ProxyURL)
 	if err != nil {
 		return err
 	}
@@ -654,7 +675,7 @@ func (e *eks) checkHealth() error {
 	fmt.Printf("\n\"kubectl get cs\" output:\n%s\n\n", out)
 
 	ep = e.cfg.ClusterAPIServerEndpoint + "/healthz?verbose"
-	output, err = httputil.ReadInsecure(e.cfg.Logger, ioutil.Discard, ep)
+	output, err = httputil.ReadInsecureProxy(e.cfg.Logger, ioutil.Discard, ep, e.cfg.ProxyURL)
 	if err != nil {
 		return err
 	}
@@ -822,7 +843,7 @@ func (e *eks) FetchServerVersion() (ServerVersionInfo, error) {
 func (e *eks) fetchServerVersion() (ServerVersionInfo, error) {
 	ep := e.cfg.ClusterAPIServerEndpoint + "/version"
 	e.cfg.Logger.Info("fetching version", zap.String("url", ep))
-	d, err := httputil.ReadInsecure(e.cfg.Logger, ioutil.Discard, ep)
+	d, err := httputil.ReadInsecureProxy(e.cfg.Logger, ioutil.Discard, ep, e.cfg.ProxyURL)
 	if err != nil {
 		return ServerVersionInfo{}, nil
 	}
@@ -0,0 +1,113 @@
+package metrics
+
+import (
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/aws/aws-k8s-tester/pkg/aws/cw"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudwatch"
+	"github.com/aws/aws-sdk-go/service/cloudwatch/cloudwatchiface"
+	"go.uber.org/zap"
+)
+
+// CloudWatchSink publishes "RequestsSummary" to CloudWatch,
+// so that alarms can be set on test regressions (e.g. nightly runs)
+// without parsing the S3 artifacts.
+type CloudWatchSink struct {
+	Logger *zap.Logger
+	CWAPI  cloudwatchiface.CloudWatchAPI
+	// Namespace is the CloudWatch namespace.
+	Namespace string
+	// Dimensions are the dimensions of every datum (e.g. {"ClusterName": "my-cluster"}).
+	// The summary name is added as the "Summary" dimension.
+	Dimensions map[string]string
+}
+
+// CloudWatch metric names published by "CloudWatchSink".
+const (
+	CloudWatchMetricSuccessTotal = "SuccessTotal"
+	CloudWatchMetricFailureTotal = "FailureTotal"
+	CloudWatchMetricSuccessRate  = "SuccessRate"
+	CloudWatchMetricLatencyP50   = "LatencyP50"
+	CloudWatchMetricLatencyP90   = "LatencyP90"
+	CloudWatchMetricLatencyP95   = "LatencyP95"
+	CloudWatchMetricLatencyP99   = "LatencyP99"
+	CloudWatchMetricLatencyP999  = "LatencyP99.9"
+)
+
+// Datums returns the CloudWatch datums of the summary with the name
+// (e.g. "AddOnStresserLocal.RequestsSummaryWrites"): success/failure counts,
+// success rate in percent, and latency percentiles (see "Percentiles")
+// in milliseconds. Latency percentiles are omitted if not recorded.
+func (s *CloudWatchSink) Datums(name string, rs RequestsSummary, now time.Time) (datums []*cloudwatch.MetricDatum) {
+	dims := s.dimensions(name)
+	tv := aws.Time(now.UTC())
+	add := func(metric string, unit string, v float64) {
+		datums = append(datums, &cloudwatch.MetricDatum{
+			Timestamp:  tv,
+			MetricName: aws.String(metric),
+			Dimensions: dims,
+			Unit:       aws.String(unit),
+			Value:      aws.Float64(v),
+		})
+	}
+
+	add(CloudWatchMetricSuccessTotal, cloudwatch.StandardUnitCount, rs.SuccessTotal)
+	add(CloudWatchMetricFailureTotal, cloudwatch.StandardUnitCount, rs.FailureTotal)
+	if rs.SuccessTotal+rs.FailureTotal > 0 {
+		add(CloudWatchMetricSuccessRate, cloudwatch.StandardUnitPercent, rs.SuccessRate()*100.0)
+	}
+
+	pct := rs.Percentiles()
+	if pct.Source == "" {
+		return datums
+	}
+	add(CloudWatchMetricLatencyP50, cloudwatch.StandardUnitMilliseconds, toMilliseconds(pct.P50))
+	add(CloudWatchMetricLatencyP90, cloudwatch.StandardUnitMilliseconds, toMilliseconds(pct.P90))
+	add(CloudWatchMetricLatencyP95, cloudwatch.StandardUnitMilliseconds, toMilliseconds(pct.P95))
+	add(CloudWatchMetricLatencyP99, cloudwatch.StandardUnitMilliseconds, toMilliseconds(pct.P99))
+	add(CloudWatchMetricLatencyP999, cloudwatch.StandardUnitMilliseconds, toMilliseconds(pct.P999))
+	return datums
+}
+
+// dimensions returns the sorted dimensions, for deterministic datums.
+func (s *CloudWatchSink) dimensions(name string) []*cloudwatch.Dimension {
+	keys := make([]string, 0, len(s.Dimensions))
+	for k := range s.Dimensions {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	dims := make([]*cloudwatch.Dimension, 0, len(keys)+1)
+	for _, k := range keys {
+		dims = append(dims, &cloudwatch.Dimension{Name: aws.String(k), Value: aws.String(s.Dimensions[k])})
+	}
+	return append(dims, &cloudwatch.Dimension{Name: aws.String("Summary"), Value: aws.String(name)})
+}
+
+// Publish publishes the summaries keyed by name to CloudWatch.
+func (s *CloudWatchSink) Publish(summaries map[string]RequestsSummary) error {
+	if s.CWAPI == nil {
+		return errors.New("empty CWAPI")
+	}
+	if s.Namespace == "" {
+		return errors.New("empty Namespace")
+	}
+	names := make([]string, 0, len(summaries))
+	for name := range summaries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	now := time.Now()
+	datums := make([]*cloudwatch.MetricDatum, 0)
+	for _, name := range names {
+		datums = append(datums, s.Datums(name, summaries[name], now)...)
+	}
+	s.Logger.Info("publishing summaries to CloudWatch",
+		zap.String("namespace", s.Namespace),
+		zap.Strings("summaries", names),
+	)
+	return cw.PutData(s.Logger, s.CWAPI, s.Namespace, 20, datums...)
+}
@@ -0,0 +1,106 @@
+package metrics
+
+import (
+	"bytes"
+	"encoding/csv"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+)
+
+// Row types of "RequestsSummary.CSV".
+const (
+	// CSVRowTypeSummary is the row of the totals and rates
+	// (e.g. "success-total", "success-rate").
+	CSVRowTypeSummary = "summary"
+	// CSVRowTypeBucket is the row of a latency histogram bucket count.
+	CSVRowTypeBucket = "bucket"
+	// CSVRowTypePercentile is the row of a latency percentile in milliseconds
+	// (see "Percentiles").
+	CSVRowTypePercentile = "percentile"
+)
+
+// CSVHeader is the header of "RequestsSummary.CSV".
+var CSVHeader = []string{"test-id", "type", "name", "scale", "lower-bound", "upper-bound", "value"}
+
+// CSV returns the comma-separated "RequestsSummary", with the header
+// "CSVHeader" and one row per total, histogram bucket, and percentile,
+// so that the results can be loaded into spreadsheets and Athena.
+// Every row has the same columns, and the columns that do not apply
+// are empty (e.g. bounds of the percentile rows). The upper bound
+// of the open-ended top bucket is "+Inf".
+func (rs RequestsSummary) CSV() string {
+	buf := bytes.NewBuffer(nil)
+	rs.WriteCSV(buf, ',')
+	return buf.String()
+}
+
+// TSV returns the tab-separated "CSV".
+func (rs RequestsSummary) TSV() string {
+	buf := bytes.NewBuffer(nil)
+	rs.WriteCSV(buf, '\t')
+	return buf.String()
+}
+
+// WriteCSV writes "CSV" with the field delimiter (e.g. ',' or '\t').
+func (rs RequestsSummary) WriteCSV(w io.Writer, comma rune) error {
+	cw := csv.NewWriter(w)
+	cw.Comma = comma
+	if err := cw.Write(CSVHeader); err != nil {
+		return err
+	}
+	return cw.WriteAll(rs.csvRows())
+}
+
+func (rs RequestsSummary) csvRows() (rows [][]string) {
+	summary := func(name string, v float64) {
+		rows = append(rows, []string{rs.TestID, CSVRowTypeSummary, name, "", "", "", formatCSVFloat(v)})
+	}
+	summary("success-total", rs.SuccessTotal)
+	summary("failure-total", rs.FailureTotal)
+	summary("success-rate", rs.SuccessRate())
+	summary("failure-rate", rs.FailureRate())
+	summary("total-duration-seconds", rs.TotalDuration.Seconds())
+	summary("throughput", rs.Throughput())
+
+	buckets := make(HistogramBuckets, len(rs.LatencyHistogram))
+	copy(buckets, rs.LatencyHistogram)
+	sort.Stable(buckets)
+	for _, b := range buckets {
+		rows = append(rows, []string{
+			rs.TestID,
+			CSVRowTypeBucket,
+			"latency-histogram",
+			b.Scale,
+			formatCSVFloat(b.LowerBound),
+			formatCSVFloat(b.UpperBound),
+			strconv.FormatUint(b.Count, 10),
+		})
+	}
+
+	pct := rs.Percentiles()
+	if pct.Source == "" {
+		return rows
+	}
+	for _, p := range []struct {
+		name string
+		v    float64
+	}{
+		{"p50", toMilliseconds(pct.P50)},
+		{"p90", toMilliseconds(pct.P90)},
+		{"p95", toMilliseconds(pct.P95)},
+		{"p99", toMilliseconds(pct.P99)},
+		{"p99.9", toMilliseconds(pct.P999)},
+	} {
+		rows = append(rows, []string{rs.TestID, CSVRowTypePercentile, p.name, ScaleMilliseconds, "", "", formatCSVFloat(p.v)})
+	}
+	return rows
+}
+
+func formatCSVFloat(v float64) string {
+	if v == math.MaxFloat64 {
+		return "+Inf"
+	}
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
@@ -0,0 +1,126 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.uber.org/zap"
+)
+
+// requestsSummaryCollector exposes "RequestsSummary" in Prometheus format.
+type requestsSummaryCollector struct {
+	summary func() RequestsSummary
+
+	successDesc *prometheus.Desc
+	failureDesc *prometheus.Desc
+	latencyDesc *prometheus.Desc
+}
+
+// NewRequestsSummaryCollector returns a Prometheus collector of the
+// "RequestsSummary" returned by the function on every scrape, for results
+// not recorded in Prometheus metrics (e.g. "LatencyRecorder" of the waiters).
+// It exposes "<namespace>_<subsystem>_requests_success_total",
+// "<namespace>_<subsystem>_requests_failure_total", and the latency
+// histogram "<namespace>_<subsystem>_request_latency_<scale>".
+func NewRequestsSummaryCollector(namespace string, subsystem string, scale string, summary func() RequestsSummary) prometheus.Collector {
+	return &requestsSummaryCollector{
+		summary: summary,
+		successDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, subsystem, "requests_success_total"),
+			"Total number of successful requests.",
+			nil, nil,
+		),
+		failureDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, subsystem, "requests_failure_total"),
+			"Total number of failed requests.",
+			nil, nil,
+		),
+		latencyDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, subsystem, "request_latency_"+scale),
+			"Bucketed histogram of request latency in "+scale+".",
+			nil, nil,
+		),
+	}
+}
+
+func (c *requestsSummaryCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.successDesc
+	ch <- c.failureDesc
+	ch <- c.latencyDesc
+}
+
+func (c *requestsSummaryCollector) Collect(ch chan<- prometheus.Metric) {
+	rs := c.summary()
+	ch <- prometheus.MustNewConstMetric(c.successDesc, prometheus.CounterValue, rs.SuccessTotal)
+	ch <- prometheus.MustNewConstMetric(c.failureDesc, prometheus.CounterValue, rs.FailureTotal)
+
+	// Prometheus buckets are cumulative counts by upper bound,
+	// and the open-ended top bucket is implied by the sample count
+	if rs.LatencyHistogram.Validate() != nil || len(rs.LatencyHistogram) == 0 {
+		return
+	}
+	cumulative := rs.LatencyHistogram.Cumulative()
+	buckets := make(map[float64]uint64, len(rs.LatencyHistogram))
+	sum := 0.0
+	for idx, b := range rs.LatencyHistogram {
+		if b.UpperBound != math.MaxFloat64 {
+			buckets[b.UpperBound] = cumulative[idx]
+		}
+		// estimated from bucket midpoints
+		sum += (b.LowerBound + hdrUpperBound(b)) / 2.0 * float64(b.Count)
+	}
+	ch <- prometheus.MustNewConstHistogram(c.latencyDesc, rs.LatencyHistogram.Total(), sum, buckets)
+}
+
+// Server serves the Prometheus metrics over HTTP.
+type Server struct {
+	lg  *zap.Logger
+	srv *http.Server
+	ln  net.Listener
+}
+
+// StartServer starts serving the metrics of the gatherer in Prometheus
+// text format at the path (e.g. "/metrics") on the address (e.g. ":9101"),
+// so that an external Prometheus can scrape live progress of long tests.
+func StartServer(lg *zap.Logger, addr string, path string, gatherer prometheus.Gatherer) (*Server, error) {
+	if gatherer == nil {
+		return nil, errors.New("nil gatherer")
+	}
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	mux := http.NewServeMux()
+	mux.Handle(path, promhttp.HandlerFor(gatherer, promhttp.HandlerOpts{}))
+	s := &Server{
+		lg:  lg,
+		srv: &http.Server{Handler: mux},
+		ln:  ln,
+	}
+	go func() {
+		if err := s.srv.Serve(ln); err != nil && err != http.ErrServerClosed {
+			lg.Warn("metrics server failed", zap.Error(err))
+		}
+	}()
+	lg.Info("started metrics server", zap.String("address", ln.Addr().String()), zap.String("path", path))
+	return s, nil
+}
+
+// Addr returns the listening address.
+func (s *Server) Addr() string {
+	return s.ln.Addr().String()
+}
+
+// Stop stops the server.
+func (s *Server) Stop() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	err := s.srv.Shutdown(ctx)
+	cancel()
+	s.lg.Info("stopped metrics server", zap.Error(err))
+}
@@ -0,0 +1,204 @@
+package metrics
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math"
+	"math/bits"
+	"sort"
+	"time"
+)
+
+// HDRHistogram encodes the latency histogram in the HdrHistogram
+// percentile distribution text format (".hgrm"), as written by
+// "AbstractHistogram.outputPercentileDistribution" and read by
+// HdrHistogram plotting tools. Values are in the histogram scale units.
+//
+// The open-ended top bucket (upper bound "math.MaxFloat64") is capped
+// at twice its lower bound, since the format requires finite values.
+// Mean and standard deviation are estimated from bucket midpoints.
+//
+// ref. https://github.com/HdrHistogram/HdrHistogram
+// ref. http://hdrhistogram.github.io/HdrHistogram/plotFiles.html
+func (rs RequestsSummary) HDRHistogram() ([]byte, error) {
+	buckets := rs.LatencyHistogram
+	if len(buckets) == 0 {
+		return nil, errors.New("empty latency histogram")
+	}
+	if err := buckets.Validate(); err != nil {
+		return nil, err
+	}
+
+	total := buckets.Total()
+	if total == 0 {
+		return nil, errors.New("empty latency histogram counts")
+	}
+
+	// mean and standard deviation from bucket midpoints
+	mean, max := 0.0, 0.0
+	for _, b := range buckets {
+		if b.Count == 0 {
+			continue
+		}
+		hi := hdrUpperBound(b)
+		mean += (b.LowerBound + hi) / 2.0 * float64(b.Count)
+		max = hi
+	}
+	mean /= float64(total)
+	variance := 0.0
+	for _, b := range buckets {
+		if b.Count == 0 {
+			continue
+		}
+		d := (b.LowerBound+hdrUpperBound(b))/2.0 - mean
+		variance += d * d * float64(b.Count)
+	}
+	stddev := math.Sqrt(variance / float64(total))
+
+	buf := bytes.NewBuffer(nil)
+	fmt.Fprintf(buf, "%12s %14s %10s %14s\n\n", "Value", "Percentile", "TotalCount", "1/(1-Percentile)")
+
+	for idx, cumulative := range buckets.Cumulative() {
+		b := buckets[idx]
+		pct := float64(cumulative) / float64(total)
+		if cumulative == total {
+			fmt.Fprintf(buf, "%12.3f %2.12f %10d\n", hdrUpperBound(b), 1.0, cumulative)
+			break
+		}
+		fmt.Fprintf(buf, "%12.3f %2.12f %10d %14.2f\n", hdrUpperBound(b), pct, cumulative, 1.0/(1.0-pct))
+	}
+
+	fmt.Fprintf(buf, "#[Mean    = %12.3f, StdDeviation   = %12.3f]\n", mean, stddev)
+	fmt.Fprintf(buf, "#[Max     = %12.3f, Total count    = %12d]\n", max, total)
+	fmt.Fprintf(buf, "#[Buckets = %12d, SubBuckets     = %12d]\n", len(buckets), 1)
+	return buf.Bytes(), nil
+}
+
+// hdrUpperBound returns the finite upper bound of the bucket,
+// capping the open-ended top bucket at twice its lower bound.
+func hdrUpperBound(b HistogramBucket) float64 {
+	if b.UpperBound != math.MaxFloat64 {
+		return b.UpperBound
+	}
+	if b.LowerBound <= 0 {
+		return 1
+	}
+	return 2 * b.LowerBound
+}
+
+// Histogram backends of the latency histogram in "RequestsSummary".
+const (
+	// HistogramBackendFixed records the latencies in the fixed power-of-two
+	// buckets of the Prometheus histograms (e.g. 0.5 ms * 2^n).
+	HistogramBackendFixed = "fixed"
+	// HistogramBackendHDR records the latencies in the log-linear buckets
+	// of "HDRRecorder", for accurate tail (p99.9+) latencies.
+	HistogramBackendHDR = "hdr"
+)
+
+const (
+	// DefaultHDRSignificantFigures is the default number of significant
+	// decimal digits of "HDRRecorder" (i.e. 0.1% relative precision).
+	DefaultHDRSignificantFigures = 3
+	// MinHDRSignificantFigures is the minimum significant figures of "HDRRecorder".
+	MinHDRSignificantFigures = 1
+	// MaxHDRSignificantFigures is the maximum significant figures of "HDRRecorder".
+	MaxHDRSignificantFigures = 5
+)
+
+// HDRRecorder records latencies in the HdrHistogram log-linear buckets:
+// values are recorded in microseconds, exactly below the sub-bucket count,
+// and above it, each power-of-two range is split into the same number of
+// linear sub-buckets, so that every bucket width is within the configured
+// significant figures of its value. It keeps the tail precision the fixed
+// power-of-two buckets lose (e.g. 4.096 to 8.192 sec in one bucket).
+// It is not safe for concurrent use.
+//
+// ref. https://github.com/HdrHistogram/HdrHistogram
+type HDRRecorder struct {
+	subBucketBits int
+	counts        map[uint64]uint64
+	total         uint64
+}
+
+// NewHDRRecorder returns a new recorder with the number of significant
+// decimal digits (e.g. 3 for 0.1% relative precision).
+func NewHDRRecorder(significantFigures int) (*HDRRecorder, error) {
+	if significantFigures < MinHDRSignificantFigures || significantFigures > MaxHDRSignificantFigures {
+		return nil, fmt.Errorf("significant figures %d out of range [%d, %d]", significantFigures, MinHDRSignificantFigures, MaxHDRSignificantFigures)
+	}
+	// e.g. 3 significant figures needs 2048 sub-buckets (>= 2 * 10^3)
+	largest := uint64(2 * math.Pow10(significantFigures))
+	return &HDRRecorder{
+		subBucketBits: bits.Len64(largest - 1),
+		counts:        make(map[uint64]uint64),
+	}, nil
+}
+
+// Record records the latency. Negative latencies are recorded as zero.
+func (r *HDRRecorder) Record(d time.Duration) {
+	v := uint64(0)
+	if d > 0 {
+		v = uint64(d / time.Microsecond)
+	}
+	lower, _ := r.bucket(v)
+	r.counts[lower]++
+	r.total++
+}
+
+// Total returns the number of recorded latencies.
+func (r *HDRRecorder) Total() uint64 {
+	return r.total
+}
+
+// bucket returns the lower bound and the width of the bucket
+// of the value in microseconds.
+func (r *HDRRecorder) bucket(v uint64) (lower uint64, width uint64) {
+	shift := bits.Len64(v) - r.subBucketBits
+	if shift <= 0 {
+		return v, 1
+	}
+	return v >> uint(shift) << uint(shift), 1 << uint(shift)
+}
+
+// Buckets returns the recorded latencies as "HistogramBuckets" in milliseconds.
+// Only the recorded buckets are returned, with the empty ranges in between
+// merged into one bucket, and the open-ended top bucket is empty.
+// It returns nil if no latency was recorded.
+func (r *HDRRecorder) Buckets() HistogramBuckets {
+	if r.total == 0 {
+		return nil
+	}
+	lowers := make([]uint64, 0, len(r.counts))
+	for lower := range r.counts {
+		lowers = append(lowers, lower)
+	}
+	sort.Slice(lowers, func(i, j int) bool { return lowers[i] < lowers[j] })
+
+	// microseconds to milliseconds
+	ms := func(v uint64) float64 { return float64(v) / 1000.0 }
+	buckets := make(HistogramBuckets, 0, 2*len(lowers)+1)
+	prev := uint64(0)
+	for _, lower := range lowers {
+		if lower > prev {
+			buckets = append(buckets, HistogramBucket{Scale: ScaleMilliseconds, LowerBound: ms(prev), UpperBound: ms(lower)})
+		}
+		_, width := r.bucket(lower)
+		buckets = append(buckets, HistogramBucket{Scale: ScaleMilliseconds, LowerBound: ms(lower), UpperBound: ms(lower + width), Count: r.counts[lower]})
+		prev = lower + width
+	}
+	return append(buckets, HistogramBucket{Scale: ScaleMilliseconds, LowerBound: ms(prev), UpperBound: math.MaxFloat64})
+}
+
+// HDRHistogramBuckets returns the latencies in "HDRRecorder" buckets.
+func HDRHistogramBuckets(significantFigures int, ds Durations) (HistogramBuckets, error) {
+	r, err := NewHDRRecorder(significantFigures)
+	if err != nil {
+		return nil, err
+	}
+	for _, d := range ds {
+		r.Record(d)
+	}
+	return r.Buckets(), nil
+}
@@ -7,9 +7,11 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io/ioutil"
 	"math"
 	"os"
 	"sort"
+	"strconv"
 	"time"
 
 	aws_s3 "github.com/aws/aws-k8s-tester/pkg/aws/s3"
@@ -135,6 +137,8 @@ type RequestsSummary struct {
 	SuccessTotal float64 `json:"success-total" read-only:"true"`
 	// FailureTotal is the number of failed client requests.
 	FailureTotal float64 `json:"failure-total" read-only:"true"`
+	// TotalDuration is the wall-clock duration of the client requests.
+	TotalDuration time.Duration `json:"total-duration" read-only:"true"`
 	// LatencyHistogram is the client requests latency histogram.
 	LatencyHistogram HistogramBuckets `json:"latency-histogram" read-only:"true"`
 
@@ -142,6 +146,8 @@ type RequestsSummary struct {
 	LantencyP50 time.Duration `json:"latency-p50" read-only:"true"`
 	// LantencyP90 is the 90-percentile latency.
 	LantencyP90 time.Duration `json:"latency-p90" read-only:"true"`
+	// LantencyP95 is the 95-percentile latency.
+	LantencyP95 time.Duration `json:"latency-p95" read-only:"true"`
 	// LantencyP99 is the 99-percentile latency.
 	LantencyP99 time.Duration `json:"latency-p99" read-only:"true"`
 	// LantencyP999 is the 99.9-percentile latency.
@@ -150,42 +156,190 @@ type RequestsSummary struct {
 	LantencyP9999 time.Duration `json:"latency-p99.99" read-only:"true"`
 }
 
+// SuccessRate returns the ratio of successful requests in [0, 1].
+// It returns 0 if there is no request.
+func (rs RequestsSummary) SuccessRate() float64 {
+	total := rs.SuccessTotal + rs.FailureTotal
+	if total == 0 {
+		return 0
+	}
+	return rs.SuccessTotal / total
+}
+
+// FailureRate returns the ratio of failed requests in [0, 1].
+// It returns 0 if there is no request.
+func (rs RequestsSummary) FailureRate() float64 {
+	total := rs.SuccessTotal + rs.FailureTotal
+	if total == 0 {
+		return 0
+	}
+	return rs.FailureTotal / total
+}
+
+// Throughput returns the total number of requests per second.
+// It returns 0 if "TotalDuration" is not set.
+func (rs RequestsSummary) Throughput() float64 {
+	if rs.TotalDuration <= 0 {
+		return 0
+	}
+	return (rs.SuccessTotal + rs.FailureTotal) / rs.TotalDuration.Seconds()
+}
+
+// Sources of "LatencyPercentiles".
+const (
+	// PercentilesSourceSamples means the percentiles are picked
+	// from the raw latency samples (e.g. "Durations.PickLantencyP50").
+	PercentilesSourceSamples = "samples"
+	// PercentilesSourceHistogram means the percentiles are estimated
+	// from the latency histogram buckets (see "HistogramBuckets.Percentile").
+	PercentilesSourceHistogram = "histogram"
+)
+
+// LatencyPercentiles is the latency percentiles of "RequestsSummary".
+type LatencyPercentiles struct {
+	// Source is "samples" or "histogram", or empty if no latency was recorded.
+	Source string        `json:"source"`
+	P50    time.Duration `json:"p50"`
+	P90    time.Duration `json:"p90"`
+	P95    time.Duration `json:"p95"`
+	P99    time.Duration `json:"p99"`
+	P999   time.Duration `json:"p99.9"`
+}
+
+// Percentiles returns the latency percentiles, picked from the raw samples
+// when recorded (any of "LantencyP*" is set), otherwise estimated from
+// the latency histogram. Estimates in the open-ended top bucket are
+// its lower bound, so they understate the true value.
+func (rs RequestsSummary) Percentiles() LatencyPercentiles {
+	if rs.LantencyP50 > 0 || rs.LantencyP90 > 0 || rs.LantencyP95 > 0 || rs.LantencyP99 > 0 || rs.LantencyP999 > 0 || rs.LantencyP9999 > 0 {
+		return LatencyPercentiles{
+			Source: PercentilesSourceSamples,
+			P50:    rs.LantencyP50,
+			P90:    rs.LantencyP90,
+			P95:    rs.LantencyP95,
+			P99:    rs.LantencyP99,
+			P999:   rs.LantencyP999,
+		}
+	}
+
+	buckets := make(HistogramBuckets, len(rs.LatencyHistogram))
+	copy(buckets, rs.LatencyHistogram)
+	sort.Stable(buckets)
+	if len(buckets) == 0 || buckets.Validate() != nil || buckets.Total() == 0 {
+		return LatencyPercentiles{}
+	}
+	unit := scaleUnits[buckets[0].Scale]
+	pick := func(q float64) time.Duration {
+		v, err := buckets.Percentile(q)
+		if err != nil {
+			return 0
+		}
+		return time.Duration(v * float64(unit))
+	}
+	return LatencyPercentiles{
+		Source: PercentilesSourceHistogram,
+		P50:    pick(0.5),
+		P90:    pick(0.9),
+		P95:    pick(0.95),
+		P99:    pick(0.99),
+		P999:   pick(0.999),
+	}
+}
+
+// requestsSummaryJSON is "RequestsSummary" with computed fields.
+type requestsSummaryJSON struct {
+	requestsSummary
+	SuccessRate        float64            `json:"success-rate"`
+	FailureRate        float64            `json:"failure-rate"`
+	Throughput         float64            `json:"throughput"`
+	LatencyPercentiles LatencyPercentiles `json:"latency-percentiles"`
+}
+
+type requestsSummary RequestsSummary
+
+// JSON returns the JSON-encoded "RequestsSummary" with computed fields.
+// The output is deterministic: buckets are sorted by lower bound,
+// and bounds are encoded as in "HistogramBucket.MarshalJSON".
 func (rs RequestsSummary) JSON() string {
-	b, _ := json.Marshal(rs)
+	b, _ := json.Marshal(rs.toJSON())
+	return string(b)
+}
+
+// JSONIndent is "JSON" with indentation, for human review.
+func (rs RequestsSummary) JSONIndent() string {
+	b, _ := json.MarshalIndent(rs.toJSON(), "", "  ")
 	return string(b)
 }
 
+func (rs RequestsSummary) toJSON() requestsSummaryJSON {
+	if len(rs.LatencyHistogram) > 0 {
+		buckets := make(HistogramBuckets, len(rs.LatencyHistogram))
+		copy(buckets, rs.LatencyHistogram)
+		sort.Stable(buckets)
+		rs.LatencyHistogram = buckets
+	}
+	return requestsSummaryJSON{
+		requestsSummary:    requestsSummary(rs),
+		SuccessRate:        rs.SuccessRate(),
+		FailureRate:        rs.FailureRate(),
+		Throughput:         rs.Throughput(),
+		LatencyPercentiles: rs.Percentiles(),
+	}
+}
+
 func (rs RequestsSummary) Table() string {
+	pct := rs.Percentiles()
 	return fmt.Sprintf(`
 TEST ID: %q
 
         TOTAL: %.2f
 SUCCESS TOTAL: %.2f
 FAILURE TOTAL: %.2f
+ SUCCESS RATE: %.2f %%
+ FAILURE RATE: %.2f %%
+     DURATION: %s
+   THROUGHPUT: %.2f requests/sec
 
 `,
 		rs.TestID,
 		rs.SuccessTotal+rs.FailureTotal,
 		rs.SuccessTotal,
 		rs.FailureTotal,
+		rs.SuccessRate()*100.0,
+		rs.FailureRate()*100.0,
+		rs.TotalDuration,
+		rs.Throughput(),
 	) +
-		rs.LatencyHistogram.Table() +
+		rs.latencyHistogramTable() +
 		fmt.Sprintf(`
    50-percentile Latency: %s
    90-percentile Latency: %s
+   95-percentile Latency: %s
    99-percentile Latency: %s
  99.9-percentile Latency: %s
 99.99-percentile Latency: %s
+      Percentiles Source: %q
 
 `,
-			rs.LantencyP50,
-			rs.LantencyP90,
-			rs.LantencyP99,
-			rs.LantencyP999,
+			pct.P50,
+			pct.P90,
+			pct.P95,
+			pct.P99,
+			pct.P999,
 			rs.LantencyP9999,
+			pct.Source,
 		)
 }
 
+// latencyHistogramTable renders the latency histogram,
+// or the error if the histogram scale is invalid.
+func (rs RequestsSummary) latencyHistogramTable() string {
+	if _, err := rs.LatencyHistogram.Scale(); err != nil {
+		return fmt.Sprintf("LATENCY HISTOGRAM: invalid scale (%v)\n", err)
+	}
+	return rs.LatencyHistogram.Table()
+}
+
 // DurationWithLabel is the duration with label.
 // ref. https://en.wikipedia.org/wiki/Kolmogorov%E2%80%93Smirnov_test
 type DurationWithLabel struct {
@@ -230,6 +384,23 @@ func (ds DurationWithLabels) PickLantencyP90() DurationWithLabel {
 	return ds[idx]
 }
 
+// PickLantencyP95 returns the latency assuming durations are already sorted.
+func (ds DurationWithLabels) PickLantencyP95() DurationWithLabel {
+	n := len(ds)
+	if n == 0 {
+		return DurationWithLabel{}
+	}
+	if n == 1 {
+		return ds[0]
+	}
+
+	idx := n * 95 / 100
+	if idx >= n {
+		return ds[n-1]
+	}
+	return ds[idx]
+}
+
 // PickLantencyP99 returns the latency assuming durations are already sorted.
 func (ds DurationWithLabels) PickLantencyP99() DurationWithLabel {
 	n := len(ds)
@@ -352,6 +523,23 @@ func (ds Durations) PickLantencyP90() time.Duration {
 	return ds[idx]
 }
 
+// PickLantencyP95 returns the latency assuming durations are already sorted.
+func (ds Durations) PickLantencyP95() time.Duration {
+	n := len(ds)
+	if n == 0 {
+		return time.Duration(0)
+	}
+	if n == 1 {
+		return ds[0]
+	}
+
+	idx := n * 95 / 100
+	if idx >= n {
+		return ds[n-1]
+	}
+	return ds[idx]
+}
+
 // PickLantencyP99 returns the latency assuming durations are already sorted.
 func (ds Durations) PickLantencyP99() time.Duration {
 	n := len(ds)
@@ -416,6 +604,66 @@ func (bucket HistogramBucket) String() string {
 	return string(b)
 }
 
+// boundInf is the JSON sentinel for the open-ended upper bound "math.MaxFloat64".
+const boundInf = "+Inf"
+
+type histogramBucketJSON struct {
+	Scale      string          `json:"scale"`
+	LowerBound json.RawMessage `json:"lower-bound"`
+	UpperBound json.RawMessage `json:"upper-bound"`
+	Count      uint64          `json:"count"`
+}
+
+// MarshalJSON encodes the bounds in fixed decimal notation without exponent,
+// and "math.MaxFloat64" as the string "+Inf", so the output is byte-stable.
+func (bucket HistogramBucket) MarshalJSON() ([]byte, error) {
+	return json.Marshal(histogramBucketJSON{
+		Scale:      bucket.Scale,
+		LowerBound: marshalBound(bucket.LowerBound),
+		UpperBound: marshalBound(bucket.UpperBound),
+		Count:      bucket.Count,
+	})
+}
+
+// UnmarshalJSON decodes the bounds either as numbers or as "+Inf".
+func (bucket *HistogramBucket) UnmarshalJSON(b []byte) (err error) {
+	var v histogramBucketJSON
+	if err = json.Unmarshal(b, &v); err != nil {
+		return err
+	}
+	bucket.Scale, bucket.Count = v.Scale, v.Count
+	if bucket.LowerBound, err = unmarshalBound(v.LowerBound); err != nil {
+		return fmt.Errorf("invalid lower bound (%v)", err)
+	}
+	if bucket.UpperBound, err = unmarshalBound(v.UpperBound); err != nil {
+		return fmt.Errorf("invalid upper bound (%v)", err)
+	}
+	return nil
+}
+
+func marshalBound(f float64) json.RawMessage {
+	if f == math.MaxFloat64 {
+		return json.RawMessage(strconv.Quote(boundInf))
+	}
+	return json.RawMessage(strconv.FormatFloat(f, 'f', -1, 64))
+}
+
+func unmarshalBound(b json.RawMessage) (float64, error) {
+	if len(b) == 0 {
+		return 0, nil
+	}
+	var s string
+	if json.Unmarshal(b, &s) == nil {
+		if s != boundInf {
+			return 0, fmt.Errorf("unknown bound %q", s)
+		}
+		return math.MaxFloat64, nil
+	}
+	var f float64
+	err := json.Unmarshal(b, &f)
+	return f, err
+}
+
 type HistogramBuckets []HistogramBucket
 
 func (buckets HistogramBuckets) Len() int { return len(buckets) }
@@ -430,11 +678,145 @@ func (buckets HistogramBuckets) Swap(i, j int) {
 	buckets[j] = t
 }
 
+// Cumulative returns the running totals of the bucket counts,
+// aligned to the buckets. It returns an empty slice for empty buckets.
+func (buckets HistogramBuckets) Cumulative() []uint64 {
+	cumulative := make([]uint64, len(buckets))
+	var sum uint64
+	for idx, b := range buckets {
+		sum += b.Count
+		cumulative[idx] = sum
+	}
+	return cumulative
+}
+
+// Total returns the total count across all buckets.
+func (buckets HistogramBuckets) Total() uint64 {
+	var total uint64
+	for _, b := range buckets {
+		total += b.Count
+	}
+	return total
+}
+
+// Validate returns an error if the buckets are not sorted by lower bound,
+// are not contiguous (each lower bound must equal the previous upper bound),
+// have inconsistent or unknown scales (see "Scale"), or the last bucket
+// is not open-ended (upper bound "math.MaxFloat64"). Empty buckets are valid.
+func (buckets HistogramBuckets) Validate() error {
+	n := len(buckets)
+	if n == 0 {
+		return nil
+	}
+	if _, err := buckets.Scale(); err != nil {
+		return err
+	}
+	for idx, cur := range buckets {
+		if cur.LowerBound > cur.UpperBound {
+			return fmt.Errorf("bucket %d lower bound %f > upper bound %f", idx, cur.LowerBound, cur.UpperBound)
+		}
+		if idx == 0 {
+			continue
+		}
+		prev := buckets[idx-1]
+		if cur.LowerBound < prev.LowerBound {
+			return fmt.Errorf("bucket %d lower bound %f < bucket %d lower bound %f (not sorted)", idx, cur.LowerBound, idx-1, prev.LowerBound)
+		}
+		if cur.LowerBound > prev.UpperBound {
+			return fmt.Errorf("gap between bucket %d upper bound %f and bucket %d lower bound %f", idx-1, prev.UpperBound, idx, cur.LowerBound)
+		}
+		if cur.LowerBound < prev.UpperBound {
+			return fmt.Errorf("overlap between bucket %d upper bound %f and bucket %d lower bound %f", idx-1, prev.UpperBound, idx, cur.LowerBound)
+		}
+	}
+	if buckets[n-1].UpperBound != math.MaxFloat64 {
+		return fmt.Errorf("last bucket %d upper bound %f is not open-ended", n-1, buckets[n-1].UpperBound)
+	}
+	return nil
+}
+
+// Percentile returns the estimated value at the quantile q (e.g. 0.99 for p99),
+// linearly interpolated within the bucket that contains the quantile.
+// The open-ended top bucket cannot be interpolated, so its lower bound is returned.
+// Use "PercentileRange" to get the error bound of the estimate.
+func (buckets HistogramBuckets) Percentile(q float64) (float64, error) {
+	idx, frac, err := buckets.findQuantile(q)
+	if err != nil {
+		return 0, err
+	}
+	b := buckets[idx]
+	if b.UpperBound == math.MaxFloat64 {
+		return b.LowerBound, nil
+	}
+	return b.LowerBound + (b.UpperBound-b.LowerBound)*frac, nil
+}
+
+// PercentileRange returns the bounds of the bucket that contains the quantile q,
+// which bracket the true value (e.g. "p99 is between 256ms and 512ms").
+// The upper bound is "math.MaxFloat64" if the quantile falls in the open-ended top bucket.
+func (buckets HistogramBuckets) PercentileRange(q float64) (lower float64, upper float64, err error) {
+	idx, _, err := buckets.findQuantile(q)
+	if err != nil {
+		return 0, 0, err
+	}
+	return buckets[idx].LowerBound, buckets[idx].UpperBound, nil
+}
+
+// findQuantile returns the index of the bucket that contains the quantile q,
+// and the fraction of the bucket count below the quantile.
+func (buckets HistogramBuckets) findQuantile(q float64) (idx int, frac float64, err error) {
+	if math.IsNaN(q) || q < 0 || q > 1 {
+		return 0, 0, fmt.Errorf("invalid quantile %f (must be in [0, 1])", q)
+	}
+	if err = buckets.Validate(); err != nil {
+		return 0, 0, err
+	}
+	cumulative := buckets.Cumulative()
+	total := buckets.Total()
+	if total == 0 {
+		return 0, 0, errors.New("empty histogram")
+	}
+
+	rank := q * float64(total)
+	for i, b := range buckets {
+		if b.Count == 0 {
+			continue
+		}
+		if float64(cumulative[i]) >= rank {
+			below := cumulative[i] - b.Count
+			return i, (rank - float64(below)) / float64(b.Count), nil
+		}
+	}
+	// unreachable, since rank <= total
+	return 0, 0, fmt.Errorf("quantile %f not found", q)
+}
+
+// ParseRequestsSummary parses "RequestsSummary" from JSON,
+// normalizes the histogram scale (e.g. "ms" to "milliseconds"),
+// and validates its latency histogram.
+func ParseRequestsSummary(b []byte) (rs RequestsSummary, err error) {
+	if err = json.Unmarshal(b, &rs); err != nil {
+		return RequestsSummary{}, err
+	}
+	if err = rs.LatencyHistogram.normalizeScales(); err != nil {
+		return RequestsSummary{}, fmt.Errorf("invalid latency histogram (%v)", err)
+	}
+	if err = rs.LatencyHistogram.Validate(); err != nil {
+		return RequestsSummary{}, fmt.Errorf("invalid latency histogram (%v)", err)
+	}
+	return rs, nil
+}
+
 // ParseHistogram parses Prometheus histogram.
+// The scale is normalized (e.g. "ms" to "milliseconds").
 func ParseHistogram(scale string, histo *dto.Histogram) (buckets HistogramBuckets, err error) {
 	if histo == nil {
 		return nil, errors.New("nil Histogram")
 	}
+	scale, err = NormalizeScale(scale)
+	if err != nil {
+		return nil, err
+	}
 
 	total := *histo.SampleCount
 	n := len(histo.Bucket)
@@ -463,40 +845,277 @@ func ParseHistogram(scale string, histo *dto.Histogram) (buckets HistogramBucket
 	return buckets, nil
 }
 
-// MergeHistograms merges two histograms.
-func MergeHistograms(a HistogramBuckets, b HistogramBuckets) (HistogramBuckets, error) {
-	counts := make(map[HistogramBucket]uint64)
-	for _, cur := range a {
-		key := HistogramBucket{
-			Scale:      cur.Scale,
-			LowerBound: cur.LowerBound,
-			UpperBound: cur.UpperBound,
+// MergeHistograms merges the histograms from multiple workers (e.g. remote
+// stresser Pods) into one. Empty histograms are skipped, and the others are
+// converted to the scale of the first non-empty histogram. Histograms with
+// the same bucket boundaries are summed bucket by bucket. Otherwise, the
+// buckets are aligned to the boundaries shared by all histograms by merging
+// adjacent buckets, so that no count is split across buckets. Empty buckets
+// do not limit the alignment (e.g. the sparse "HDRRecorder" buckets).
+func MergeHistograms(hs ...HistogramBuckets) (HistogramBuckets, error) {
+	scale := ""
+	aligned := make([]HistogramBuckets, 0, len(hs))
+	for idx, h := range hs {
+		if len(h) == 0 {
+			continue
+		}
+		cur := make(HistogramBuckets, len(h))
+		copy(cur, h)
+		sort.Sort(cur)
+		if err := cur.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid histogram %d (%v)", idx, err)
+		}
+		if scale == "" {
+			scale = cur[0].Scale
+		}
+		cur.convertScale(scale)
+		aligned = append(aligned, cur)
+	}
+	if len(aligned) == 0 {
+		return nil, nil
+	}
+
+	same := true
+	for _, cur := range aligned[1:] {
+		if matchBuckets(aligned[0], cur) != nil {
+			same = false
+			break
+		}
+	}
+	if same {
+		merged := make(HistogramBuckets, len(aligned[0]))
+		copy(merged, aligned[0])
+		for _, cur := range aligned[1:] {
+			for i := range cur {
+				merged[i].Count += cur[i].Count
+			}
+		}
+		return merged, nil
+	}
+
+	// a boundary is shared if every histogram has a bucket starting there,
+	// starts above it, or has an empty bucket across it (splits no count)
+	bounds := make(map[float64]struct{})
+	for _, cur := range aligned {
+		for _, b := range cur {
+			bounds[b.LowerBound] = struct{}{}
 		}
-		counts[key] = cur.Count
 	}
-	for _, cur := range b {
-		key := HistogramBucket{
-			Scale:      cur.Scale,
-			LowerBound: cur.LowerBound,
-			UpperBound: cur.UpperBound,
+	cuts := make([]float64, 0, len(bounds))
+	for bound := range bounds {
+		shared := true
+		for _, cur := range aligned {
+			if bound <= cur[0].LowerBound {
+				continue
+			}
+			i := sort.Search(len(cur), func(i int) bool { return cur[i].LowerBound >= bound })
+			if i < len(cur) && cur[i].LowerBound == bound {
+				continue
+			}
+			if cur[i-1].Count != 0 {
+				shared = false
+				break
+			}
 		}
-		prevCount, ok := counts[key]
-		if !ok {
-			return nil, fmt.Errorf("[%f, %f] not found in previous counts", cur.LowerBound, cur.UpperBound)
+		if shared {
+			cuts = append(cuts, bound)
+		}
+	}
+	sort.Float64s(cuts)
+
+	merged := make(HistogramBuckets, len(cuts))
+	for i, lower := range cuts {
+		upper := math.MaxFloat64
+		if i+1 < len(cuts) {
+			upper = cuts[i+1]
 		}
-		counts[key] = prevCount + cur.Count
+		merged[i] = HistogramBucket{Scale: scale, LowerBound: lower, UpperBound: upper}
 	}
-	hs := make(HistogramBuckets, 0, len(counts))
-	for k, v := range counts {
-		hs = append(hs, HistogramBucket{
-			Scale:      k.Scale,
-			LowerBound: k.LowerBound,
-			UpperBound: k.UpperBound,
-			Count:      v,
-		})
+	for _, cur := range aligned {
+		for _, b := range cur {
+			if b.Count == 0 {
+				continue
+			}
+			// no shared boundary within the bucket, so its lower bound
+			// locates the merged bucket that contains the whole bucket
+			i := sort.Search(len(cuts), func(i int) bool { return cuts[i] > b.LowerBound }) - 1
+			merged[i].Count += b.Count
+		}
 	}
-	sort.Sort(HistogramBuckets(hs))
-	return hs, nil
+	return merged, nil
+}
+
+// convertScale converts the bucket bounds to the scale in place.
+// The open-ended upper bound is kept as is.
+func (buckets HistogramBuckets) convertScale(scale string) {
+	from, to := scaleUnits[buckets[0].Scale], scaleUnits[scale]
+	if from == to {
+		return
+	}
+	convert := func(v float64) float64 {
+		if v == math.MaxFloat64 {
+			return v
+		}
+		// multiply or divide by the integer ratio, to get the same
+		// bounds as the ones recorded in the scale (e.g. 500µs to 0.5ms)
+		if from > to {
+			return v * float64(from/to)
+		}
+		return v / float64(to/from)
+	}
+	for idx := range buckets {
+		buckets[idx].Scale = scale
+		buckets[idx].LowerBound = convert(buckets[idx].LowerBound)
+		buckets[idx].UpperBound = convert(buckets[idx].UpperBound)
+	}
+}
+
+// CombineRequestsSummaries combines multiple "RequestsSummary" from
+// concurrent workers into one. Success and failure totals are summed,
+// and the latency histograms are merged (see "MergeHistograms"), with
+// buckets aligned if the workers recorded different bucket boundaries.
+// "TotalDuration" is the maximum across summaries rather than the sum,
+// since the workers run concurrently and the combined wall-clock duration
+// is bounded by the slowest worker. Latency percentiles are not combined,
+// since they cannot be derived from per-worker percentiles.
+// The test ID of the first summary is used.
+func CombineRequestsSummaries(rs ...RequestsSummary) (combined RequestsSummary, err error) {
+	hs := make([]HistogramBuckets, 0, len(rs))
+	for idx, cur := range rs {
+		if idx == 0 {
+			combined.TestID = cur.TestID
+		}
+		combined.SuccessTotal += cur.SuccessTotal
+		combined.FailureTotal += cur.FailureTotal
+		if cur.TotalDuration > combined.TotalDuration {
+			combined.TotalDuration = cur.TotalDuration
+		}
+		if err = cur.LatencyHistogram.Validate(); err != nil {
+			return RequestsSummary{}, fmt.Errorf("invalid latency histogram in summary %d (%v)", idx, err)
+		}
+		hs = append(hs, cur.LatencyHistogram)
+	}
+	combined.LatencyHistogram, err = MergeHistograms(hs...)
+	if err != nil {
+		return RequestsSummary{}, err
+	}
+	return combined, nil
+}
+
+// WeightMode defines how per-worker latency histograms are weighted
+// in "CombineRequestsSummariesWeighted".
+type WeightMode string
+
+const (
+	// ByCount scales each histogram so that every worker contributes
+	// the same number of requests (the mean request count across workers).
+	ByCount WeightMode = "by-count"
+	// ByDuration scales each histogram to the mean run duration across workers,
+	// so that every worker contributes at its request rate rather than
+	// at its run length. Requires non-zero "TotalDuration" in every summary.
+	ByDuration WeightMode = "by-duration"
+)
+
+// CombineRequestsSummariesWeighted combines multiple "RequestsSummary" like
+// "CombineRequestsSummaries", but normalizes each worker's latency histogram
+// by its request count or duration before summing, so that long-running
+// workers (stragglers) do not over-weight the aggregate distribution.
+// All non-empty histograms must have the same bucket boundaries.
+//
+// Success and failure totals are summed without weights. The weighted bucket
+// counts are accumulated as floating point, and each bucket is rounded to the
+// nearest integer (half away from zero) only once after summing, so the combined
+// histogram count may differ from the sum of request counts by the rounding.
+func CombineRequestsSummariesWeighted(weightBy WeightMode, rs ...RequestsSummary) (combined RequestsSummary, err error) {
+	if weightBy != ByCount && weightBy != ByDuration {
+		return RequestsSummary{}, fmt.Errorf("unknown weight mode %q", weightBy)
+	}
+
+	var base HistogramBuckets
+	var counts []float64
+	var durations []float64
+	for idx, cur := range rs {
+		if idx == 0 {
+			combined.TestID = cur.TestID
+		}
+		combined.SuccessTotal += cur.SuccessTotal
+		combined.FailureTotal += cur.FailureTotal
+		if cur.TotalDuration > combined.TotalDuration {
+			combined.TotalDuration = cur.TotalDuration
+		}
+		if err = cur.LatencyHistogram.Validate(); err != nil {
+			return RequestsSummary{}, fmt.Errorf("invalid latency histogram in summary %d (%v)", idx, err)
+		}
+		if len(cur.LatencyHistogram) == 0 {
+			continue
+		}
+		if base == nil {
+			base = cur.LatencyHistogram
+		} else if err = matchBuckets(base, cur.LatencyHistogram); err != nil {
+			return RequestsSummary{}, fmt.Errorf("summary %d (%v)", idx, err)
+		}
+		if weightBy == ByDuration && cur.TotalDuration <= 0 {
+			return RequestsSummary{}, fmt.Errorf("summary %d has no total duration to weight by", idx)
+		}
+		counts = append(counts, float64(cur.LatencyHistogram.Total()))
+		durations = append(durations, float64(cur.TotalDuration))
+	}
+	if base == nil {
+		return combined, nil
+	}
+
+	meanCount, meanDuration := 0.0, 0.0
+	for i := range counts {
+		meanCount += counts[i]
+		meanDuration += durations[i]
+	}
+	meanCount /= float64(len(counts))
+	meanDuration /= float64(len(durations))
+
+	sums := make([]float64, len(base))
+	i := 0
+	for _, cur := range rs {
+		if len(cur.LatencyHistogram) == 0 {
+			continue
+		}
+		weight := 0.0
+		switch weightBy {
+		case ByCount:
+			if counts[i] > 0 {
+				weight = meanCount / counts[i]
+			}
+		case ByDuration:
+			weight = meanDuration / durations[i]
+		}
+		for j, b := range cur.LatencyHistogram {
+			sums[j] += float64(b.Count) * weight
+		}
+		i++
+	}
+
+	combined.LatencyHistogram = make(HistogramBuckets, len(base))
+	for j, b := range base {
+		combined.LatencyHistogram[j] = HistogramBucket{
+			Scale:      b.Scale,
+			LowerBound: b.LowerBound,
+			UpperBound: b.UpperBound,
+			Count:      uint64(math.Round(sums[j])),
+		}
+	}
+	return combined, nil
+}
+
+// matchBuckets returns an error if the bucket boundaries and scales differ.
+func matchBuckets(a HistogramBuckets, b HistogramBuckets) error {
+	if len(a) != len(b) {
+		return fmt.Errorf("bucket count %d != %d", len(b), len(a))
+	}
+	for i := range a {
+		if a[i].Scale != b[i].Scale || a[i].LowerBound != b[i].LowerBound || a[i].UpperBound != b[i].UpperBound {
+			return fmt.Errorf("bucket %d [%f, %f] does not match [%f, %f]", i, b[i].LowerBound, b[i].UpperBound, a[i].LowerBound, a[i].UpperBound)
+		}
+	}
+	return nil
 }
 
 // Table converts "HistogramBuckets" to table.
@@ -510,15 +1129,19 @@ func (buckets HistogramBuckets) Table() string {
 	tb.SetColWidth(1500)
 	tb.SetCenterSeparator("*")
 	tb.SetAlignment(tablewriter.ALIGN_CENTER)
-	tb.SetCaption(true, fmt.Sprintf("	(%q scale)", buckets[0].Scale))
+	scale, err := buckets.Scale()
+	if err != nil {
+		scale = fmt.Sprintf("invalid scale (%v)", err)
+	}
+	tb.SetCaption(true, fmt.Sprintf("	(%q scale)", scale))
 	tb.SetHeader([]string{"lower bound", "upper bound", "count"})
 	for _, v := range buckets {
 		lo := fmt.Sprintf("%f", v.LowerBound)
-		if v.Scale == "milliseconds" {
+		if v.Scale == ScaleMilliseconds {
 			lo = fmt.Sprintf("%.3f", v.LowerBound)
 		}
 		hi := fmt.Sprintf("%f", v.UpperBound)
-		if v.Scale == "milliseconds" {
+		if v.Scale == ScaleMilliseconds {
 			hi = fmt.Sprintf("%.3f", v.UpperBound)
 		}
 		if v.UpperBound == math.MaxFloat64 {
@@ -579,8 +1202,14 @@ func DownloadRequestsSummaryFromS3(lg *zap.Logger, s3API s3iface.S3API, bucketNa
 	}
 	defer rf.Close()
 
-	if err = json.NewDecoder(rf).Decode(&rs); err != nil {
-		lg.Warn("failed to decode a JSON file", zap.Error(err))
+	b, err := ioutil.ReadAll(rf)
+	if err != nil {
+		lg.Warn("failed to read a file", zap.Error(err))
+		return RequestsSummary{}, err
+	}
+	rs, err = ParseRequestsSummary(b)
+	if err != nil {
+		lg.Warn("failed to parse requests summary", zap.Error(err))
 		return RequestsSummary{}, err
 	}
 	return rs, nil
@@ -1,15 +1,21 @@
 package metrics
 
 import (
+	"encoding/json"
 	"fmt"
+	"io/ioutil"
 	"math"
 	"math/rand"
+	"net/http"
+	"net/http/httptest"
 	"reflect"
 	"sort"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
 )
 
 func TestRequestsSummary(t *testing.T) {
@@ -242,3 +248,925 @@ func TestMergeHistogram(t *testing.T) {
 		t.Fatalf("expected %+v, got %+v", combined, rs)
 	}
 }
+
+func TestMergeHistogramsAligned(t *testing.T) {
+	// worker "a" records finer buckets than worker "b"
+	a := HistogramBuckets([]HistogramBucket{
+		{Scale: "milliseconds", LowerBound: 0, UpperBound: 0.5, Count: 1},
+		{Scale: "milliseconds", LowerBound: 0.5, UpperBound: 1, Count: 2},
+		{Scale: "milliseconds", LowerBound: 1, UpperBound: 2, Count: 3},
+		{Scale: "milliseconds", LowerBound: 2, UpperBound: 4, Count: 4},
+		{Scale: "milliseconds", LowerBound: 4, UpperBound: math.MaxFloat64, Count: 5},
+	})
+	b := HistogramBuckets([]HistogramBucket{
+		{Scale: "milliseconds", LowerBound: 0, UpperBound: 1, Count: 10},
+		{Scale: "milliseconds", LowerBound: 1, UpperBound: 4, Count: 20},
+		{Scale: "milliseconds", LowerBound: 4, UpperBound: math.MaxFloat64, Count: 30},
+	})
+	// worker "c" records in microseconds, starting at 2ms
+	c := HistogramBuckets([]HistogramBucket{
+		{Scale: "microseconds", LowerBound: 4000, UpperBound: math.MaxFloat64, Count: 200},
+		{Scale: "microseconds", LowerBound: 2000, UpperBound: 4000, Count: 100},
+	})
+
+	expected := HistogramBuckets([]HistogramBucket{
+		{Scale: "milliseconds", LowerBound: 0, UpperBound: 1, Count: 13},
+		{Scale: "milliseconds", LowerBound: 1, UpperBound: 4, Count: 127},
+		{Scale: "milliseconds", LowerBound: 4, UpperBound: math.MaxFloat64, Count: 235},
+	})
+	rs, err := MergeHistograms(a, nil, b, c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(expected, rs) {
+		t.Fatalf("expected %+v, got %+v", expected, rs)
+	}
+	if rs.Total() != a.Total()+b.Total()+c.Total() {
+		t.Fatalf("total expected %d, got %d", a.Total()+b.Total()+c.Total(), rs.Total())
+	}
+	if a[1].Count != 2 || c[0].Scale != "microseconds" {
+		t.Fatalf("inputs must not be modified, got %+v, %+v", a, c)
+	}
+
+	if _, err = MergeHistograms(a, HistogramBuckets([]HistogramBucket{
+		{Scale: "milliseconds", LowerBound: 0, UpperBound: 1, Count: 1},
+	})); err == nil {
+		t.Fatal("expected error for histogram without open-ended bucket")
+	}
+
+	crs, err := CombineRequestsSummaries(
+		RequestsSummary{TestID: "a", SuccessTotal: 15, LatencyHistogram: a},
+		RequestsSummary{TestID: "b", SuccessTotal: 60, LatencyHistogram: b},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if crs.TestID != "a" || crs.SuccessTotal != 75 || crs.LatencyHistogram.Total() != 75 || len(crs.LatencyHistogram) != 3 {
+		t.Fatalf("unexpected combined summary %+v", crs)
+	}
+}
+
+func TestCombineRequestsSummaries(t *testing.T) {
+	a := RequestsSummary{
+		TestID:        "a",
+		SuccessTotal:  90,
+		FailureTotal:  10,
+		TotalDuration: 10 * time.Second,
+		LatencyHistogram: HistogramBuckets([]HistogramBucket{
+			{Scale: "milliseconds", LowerBound: 0, UpperBound: 0.5, Count: 40},
+			{Scale: "milliseconds", LowerBound: 0.5, UpperBound: math.MaxFloat64, Count: 60},
+		}),
+	}
+	b := RequestsSummary{
+		TestID:        "b",
+		SuccessTotal:  100,
+		FailureTotal:  0,
+		TotalDuration: 20 * time.Second,
+		LatencyHistogram: HistogramBuckets([]HistogramBucket{
+			{Scale: "milliseconds", LowerBound: 0, UpperBound: 0.5, Count: 50},
+			{Scale: "milliseconds", LowerBound: 0.5, UpperBound: math.MaxFloat64, Count: 50},
+		}),
+	}
+
+	rs, err := CombineRequestsSummaries(a, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rs.TestID != "a" {
+		t.Fatalf("unexpected test ID %q", rs.TestID)
+	}
+	if rs.TotalDuration != 20*time.Second {
+		t.Fatalf("expected max total duration 20s, got %v", rs.TotalDuration)
+	}
+	if rs.SuccessRate() != 0.95 {
+		t.Fatalf("expected success rate 0.95, got %f", rs.SuccessRate())
+	}
+	if rs.FailureRate() != 0.05 {
+		t.Fatalf("expected failure rate 0.05, got %f", rs.FailureRate())
+	}
+	if rs.Throughput() != 10 {
+		t.Fatalf("expected throughput 10, got %f", rs.Throughput())
+	}
+	expected := HistogramBuckets([]HistogramBucket{
+		{Scale: "milliseconds", LowerBound: 0, UpperBound: 0.5, Count: 90},
+		{Scale: "milliseconds", LowerBound: 0.5, UpperBound: math.MaxFloat64, Count: 110},
+	})
+	if !reflect.DeepEqual(expected, rs.LatencyHistogram) {
+		t.Fatalf("expected %+v, got %+v", expected, rs.LatencyHistogram)
+	}
+
+	var empty RequestsSummary
+	if empty.SuccessRate() != 0 || empty.FailureRate() != 0 || empty.Throughput() != 0 {
+		t.Fatalf("expected zero rates for empty summary, got %s", empty.JSON())
+	}
+}
+
+func TestCombineRequestsSummariesWeighted(t *testing.T) {
+	fast := RequestsSummary{
+		TestID:        "fast",
+		SuccessTotal:  10,
+		TotalDuration: time.Minute,
+		LatencyHistogram: HistogramBuckets([]HistogramBucket{
+			{Scale: "milliseconds", LowerBound: 0, UpperBound: 1, Count: 10},
+			{Scale: "milliseconds", LowerBound: 1, UpperBound: math.MaxFloat64, Count: 0},
+		}),
+	}
+	slow := RequestsSummary{
+		TestID:        "slow",
+		SuccessTotal:  30,
+		TotalDuration: 3 * time.Minute,
+		LatencyHistogram: HistogramBuckets([]HistogramBucket{
+			{Scale: "milliseconds", LowerBound: 0, UpperBound: 1, Count: 0},
+			{Scale: "milliseconds", LowerBound: 1, UpperBound: math.MaxFloat64, Count: 30},
+		}),
+	}
+
+	// mean count 20: fast x2, slow x2/3
+	combined, err := CombineRequestsSummariesWeighted(ByCount, fast, slow)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if combined.TestID != "fast" || combined.SuccessTotal != 40 || combined.TotalDuration != 3*time.Minute {
+		t.Fatalf("unexpected combined summary %+v", combined)
+	}
+	if combined.LatencyHistogram[0].Count != 20 || combined.LatencyHistogram[1].Count != 20 {
+		t.Fatalf("unexpected weighted histogram %+v", combined.LatencyHistogram)
+	}
+
+	// mean duration 2m: fast x2, slow x2/3
+	combined, err = CombineRequestsSummariesWeighted(ByDuration, fast, slow)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if combined.LatencyHistogram[0].Count != 20 || combined.LatencyHistogram[1].Count != 20 {
+		t.Fatalf("unexpected weighted histogram %+v", combined.LatencyHistogram)
+	}
+
+	fast.TotalDuration = 0
+	if _, err = CombineRequestsSummariesWeighted(ByDuration, fast, slow); err == nil {
+		t.Fatal("expected error for zero duration")
+	}
+
+	slow.LatencyHistogram = HistogramBuckets([]HistogramBucket{
+		{Scale: "milliseconds", LowerBound: 0, UpperBound: 2, Count: 0},
+		{Scale: "milliseconds", LowerBound: 2, UpperBound: math.MaxFloat64, Count: 30},
+	})
+	if _, err = CombineRequestsSummariesWeighted(ByCount, fast, slow); err == nil {
+		t.Fatal("expected error for mismatched buckets")
+	}
+}
+
+func TestHistogramBucketsValidate(t *testing.T) {
+	tt := []struct {
+		buckets HistogramBuckets
+		valid   bool
+	}{
+		{
+			buckets: nil,
+			valid:   true,
+		},
+		{
+			buckets: HistogramBuckets([]HistogramBucket{
+				{Scale: "milliseconds", LowerBound: 0, UpperBound: 0.5},
+				{Scale: "milliseconds", LowerBound: 0.5, UpperBound: 1},
+				{Scale: "milliseconds", LowerBound: 1, UpperBound: math.MaxFloat64},
+			}),
+			valid: true,
+		},
+		{ // gap
+			buckets: HistogramBuckets([]HistogramBucket{
+				{Scale: "milliseconds", LowerBound: 0, UpperBound: 0.5},
+				{Scale: "milliseconds", LowerBound: 1, UpperBound: math.MaxFloat64},
+			}),
+			valid: false,
+		},
+		{ // overlap
+			buckets: HistogramBuckets([]HistogramBucket{
+				{Scale: "milliseconds", LowerBound: 0, UpperBound: 2},
+				{Scale: "milliseconds", LowerBound: 1, UpperBound: math.MaxFloat64},
+			}),
+			valid: false,
+		},
+		{ // not sorted
+			buckets: HistogramBuckets([]HistogramBucket{
+				{Scale: "milliseconds", LowerBound: 1, UpperBound: math.MaxFloat64},
+				{Scale: "milliseconds", LowerBound: 0, UpperBound: 1},
+			}),
+			valid: false,
+		},
+		{ // inconsistent scale
+			buckets: HistogramBuckets([]HistogramBucket{
+				{Scale: "milliseconds", LowerBound: 0, UpperBound: 1},
+				{Scale: "seconds", LowerBound: 1, UpperBound: math.MaxFloat64},
+			}),
+			valid: false,
+		},
+		{ // unknown scale
+			buckets: HistogramBuckets([]HistogramBucket{
+				{Scale: "ms", LowerBound: 0, UpperBound: math.MaxFloat64},
+			}),
+			valid: false,
+		},
+		{ // not open-ended
+			buckets: HistogramBuckets([]HistogramBucket{
+				{Scale: "milliseconds", LowerBound: 0, UpperBound: 1},
+				{Scale: "milliseconds", LowerBound: 1, UpperBound: 2},
+			}),
+			valid: false,
+		},
+	}
+	for i, tv := range tt {
+		err := tv.buckets.Validate()
+		if tv.valid && err != nil {
+			t.Fatalf("#%d: unexpected error %v", i, err)
+		}
+		if !tv.valid && err == nil {
+			t.Fatalf("#%d: expected error", i)
+		}
+	}
+}
+
+func TestHistogramBucketsCumulative(t *testing.T) {
+	var empty HistogramBuckets
+	if c := empty.Cumulative(); len(c) != 0 {
+		t.Fatalf("expected empty cumulative, got %v", c)
+	}
+	if total := empty.Total(); total != 0 {
+		t.Fatalf("expected zero total, got %d", total)
+	}
+
+	buckets := HistogramBuckets([]HistogramBucket{
+		{Scale: "milliseconds", LowerBound: 0, UpperBound: 1, Count: 3},
+		{Scale: "milliseconds", LowerBound: 1, UpperBound: 2, Count: 0},
+		{Scale: "milliseconds", LowerBound: 2, UpperBound: math.MaxFloat64, Count: 5},
+	})
+	if c := buckets.Cumulative(); !reflect.DeepEqual(c, []uint64{3, 3, 8}) {
+		t.Fatalf("unexpected cumulative %v", c)
+	}
+	if total := buckets.Total(); total != 8 {
+		t.Fatalf("expected total 8, got %d", total)
+	}
+}
+
+func TestHistogramBucketsPercentile(t *testing.T) {
+	buckets := HistogramBuckets([]HistogramBucket{
+		{Scale: "milliseconds", LowerBound: 0, UpperBound: 256, Count: 90},
+		{Scale: "milliseconds", LowerBound: 256, UpperBound: 512, Count: 9},
+		{Scale: "milliseconds", LowerBound: 512, UpperBound: math.MaxFloat64, Count: 1},
+	})
+	tt := []struct {
+		q     float64
+		value float64
+		lower float64
+		upper float64
+	}{
+		{q: 0.45, value: 128, lower: 0, upper: 256},
+		{q: 0.9, value: 256, lower: 0, upper: 256},
+		{q: 0.99, value: 512, lower: 256, upper: 512},
+		{q: 0.999, value: 512, lower: 512, upper: math.MaxFloat64},
+	}
+	for i, tv := range tt {
+		v, err := buckets.Percentile(tv.q)
+		if err != nil {
+			t.Fatalf("#%d: unexpected error %v", i, err)
+		}
+		if v != tv.value {
+			t.Fatalf("#%d: percentile %f expected %f, got %f", i, tv.q, tv.value, v)
+		}
+		lower, upper, err := buckets.PercentileRange(tv.q)
+		if err != nil {
+			t.Fatalf("#%d: unexpected error %v", i, err)
+		}
+		if lower != tv.lower || upper != tv.upper {
+			t.Fatalf("#%d: percentile %f expected [%f, %f], got [%f, %f]", i, tv.q, tv.lower, tv.upper, lower, upper)
+		}
+	}
+
+	if _, err := buckets.Percentile(1.5); err == nil {
+		t.Fatal("expected error for invalid quantile")
+	}
+	if _, _, err := HistogramBuckets(nil).PercentileRange(0.5); err == nil {
+		t.Fatal("expected error for empty histogram")
+	}
+}
+
+func TestNormalizeScale(t *testing.T) {
+	tt := []struct {
+		scale string
+		exp   string
+		valid bool
+	}{
+		{scale: "milliseconds", exp: ScaleMilliseconds, valid: true},
+		{scale: "ms", exp: ScaleMilliseconds, valid: true},
+		{scale: "Millisecond", exp: ScaleMilliseconds, valid: true},
+		{scale: "us", exp: ScaleMicroseconds, valid: true},
+		{scale: "seconds", exp: ScaleSeconds, valid: true},
+		{scale: "minutes", valid: false},
+		{scale: "", valid: false},
+	}
+	for i, tv := range tt {
+		scale, err := NormalizeScale(tv.scale)
+		if tv.valid && err != nil {
+			t.Fatalf("#%d: unexpected error %v", i, err)
+		}
+		if !tv.valid && err == nil {
+			t.Fatalf("#%d: expected error", i)
+		}
+		if scale != tv.exp {
+			t.Fatalf("#%d: expected %q, got %q", i, tv.exp, scale)
+		}
+	}
+
+	rs, err := ParseRequestsSummary([]byte(`{"latency-histogram":[{"scale":"ms","lower-bound":0,"upper-bound":1.7976931348623157e+308,"count":1}]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if scale, err := rs.LatencyHistogram.Scale(); err != nil || scale != ScaleMilliseconds {
+		t.Fatalf("expected %q, got %q (%v)", ScaleMilliseconds, scale, err)
+	}
+}
+
+func TestRequestsSummaryJSONIndent(t *testing.T) {
+	rs := RequestsSummary{
+		TestID:        "test",
+		SuccessTotal:  3,
+		FailureTotal:  1,
+		TotalDuration: 2 * time.Second,
+		LatencyHistogram: HistogramBuckets([]HistogramBucket{
+			{Scale: "milliseconds", LowerBound: 0.5, UpperBound: math.MaxFloat64, Count: 1},
+			{Scale: "milliseconds", LowerBound: 0, UpperBound: 0.5, Count: 3},
+		}),
+		LantencyP50: time.Millisecond,
+	}
+	exp := `{
+  "test-id": "test",
+  "success-total": 3,
+  "failure-total": 1,
+  "total-duration": 2000000000,
+  "latency-histogram": [
+    {
+      "scale": "milliseconds",
+      "lower-bound": 0,
+      "upper-bound": 0.5,
+      "count": 3
+    },
+    {
+      "scale": "milliseconds",
+      "lower-bound": 0.5,
+      "upper-bound": "+Inf",
+      "count": 1
+    }
+  ],
+  "latency-p50": 1000000,
+  "latency-p90": 0,
+  "latency-p95": 0,
+  "latency-p99": 0,
+  "latency-p99.9": 0,
+  "latency-p99.99": 0,
+  "success-rate": 0.75,
+  "failure-rate": 0.25,
+  "throughput": 2,
+  "latency-percentiles": {
+    "source": "samples",
+    "p50": 1000000,
+    "p90": 0,
+    "p95": 0,
+    "p99": 0,
+    "p99.9": 0
+  }
+}`
+	if s := rs.JSONIndent(); s != exp {
+		t.Fatalf("expected\n%s\n\ngot\n%s", exp, s)
+	}
+	// input must not be reordered
+	if rs.LatencyHistogram[0].LowerBound != 0.5 {
+		t.Fatalf("unexpected input modification %+v", rs.LatencyHistogram)
+	}
+
+	rs2, err := ParseRequestsSummary([]byte(rs.JSON()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rs2.LatencyHistogram[1].UpperBound != math.MaxFloat64 {
+		t.Fatalf("expected open-ended upper bound, got %+v", rs2.LatencyHistogram[1])
+	}
+}
+
+func TestRequestsSummaryPercentiles(t *testing.T) {
+	rs := RequestsSummary{
+		LatencyHistogram: HistogramBuckets([]HistogramBucket{
+			{Scale: "milliseconds", LowerBound: 40, UpperBound: math.MaxFloat64, Count: 2},
+			{Scale: "milliseconds", LowerBound: 0, UpperBound: 10, Count: 50},
+			{Scale: "milliseconds", LowerBound: 10, UpperBound: 20, Count: 40},
+			{Scale: "milliseconds", LowerBound: 20, UpperBound: 40, Count: 8},
+		}),
+	}
+	pct := rs.Percentiles()
+	if pct.Source != PercentilesSourceHistogram {
+		t.Fatalf("unexpected source %q", pct.Source)
+	}
+	for i, tv := range []struct {
+		got      time.Duration
+		expected time.Duration
+	}{
+		{pct.P50, 10 * time.Millisecond},
+		{pct.P90, 20 * time.Millisecond},
+		{pct.P95, 32500 * time.Microsecond},
+		{pct.P99, 40 * time.Millisecond},
+		{pct.P999, 40 * time.Millisecond},
+	} {
+		if d := tv.got - tv.expected; d > time.Microsecond || d < -time.Microsecond {
+			t.Fatalf("#%d: expected %v, got %v", i, tv.expected, tv.got)
+		}
+	}
+	if !strings.Contains(rs.Table(), `Percentiles Source: "histogram"`) {
+		t.Fatalf("unexpected table %s", rs.Table())
+	}
+
+	// raw samples take precedence
+	rs.LantencyP95 = 25 * time.Millisecond
+	if pct = rs.Percentiles(); pct.Source != PercentilesSourceSamples || pct.P95 != 25*time.Millisecond || pct.P50 != 0 {
+		t.Fatalf("unexpected percentiles %+v", pct)
+	}
+
+	if pct = (RequestsSummary{}).Percentiles(); pct != (LatencyPercentiles{}) {
+		t.Fatalf("unexpected percentiles %+v", pct)
+	}
+}
+
+func TestRequestsSummaryCSV(t *testing.T) {
+	rs := RequestsSummary{
+		TestID:        "x",
+		SuccessTotal:  9,
+		FailureTotal:  1,
+		TotalDuration: 10 * time.Second,
+		LatencyHistogram: HistogramBuckets([]HistogramBucket{
+			{Scale: "milliseconds", LowerBound: 1, UpperBound: math.MaxFloat64, Count: 5},
+			{Scale: "milliseconds", LowerBound: 0, UpperBound: 1, Count: 5},
+		}),
+		LantencyP50:  time.Millisecond,
+		LantencyP90:  2 * time.Millisecond,
+		LantencyP95:  2 * time.Millisecond,
+		LantencyP99:  3 * time.Millisecond,
+		LantencyP999: 3500 * time.Microsecond,
+	}
+	expected := `test-id,type,name,scale,lower-bound,upper-bound,value
+x,summary,success-total,,,,9
+x,summary,failure-total,,,,1
+x,summary,success-rate,,,,0.9
+x,summary,failure-rate,,,,0.1
+x,summary,total-duration-seconds,,,,10
+x,summary,throughput,,,,1
+x,bucket,latency-histogram,milliseconds,0,1,5
+x,bucket,latency-histogram,milliseconds,1,+Inf,5
+x,percentile,p50,milliseconds,,,1
+x,percentile,p90,milliseconds,,,2
+x,percentile,p95,milliseconds,,,2
+x,percentile,p99,milliseconds,,,3
+x,percentile,p99.9,milliseconds,,,3.5
+`
+	if s := rs.CSV(); s != expected {
+		t.Fatalf("expected\n%s\ngot\n%s", expected, s)
+	}
+	if s := rs.TSV(); s != strings.Replace(expected, ",", "\t", -1) {
+		t.Fatalf("unexpected TSV\n%s", s)
+	}
+
+	// no percentile rows without latencies
+	s := (RequestsSummary{TestID: "y"}).CSV()
+	if strings.Count(s, "\n") != 7 || strings.Contains(s, CSVRowTypePercentile) {
+		t.Fatalf("unexpected CSV\n%s", s)
+	}
+}
+
+func TestHDRHistogram(t *testing.T) {
+	rs := RequestsSummary{
+		LatencyHistogram: HistogramBuckets([]HistogramBucket{
+			{Scale: "milliseconds", LowerBound: 0, UpperBound: 0.5, Count: 0},
+			{Scale: "milliseconds", LowerBound: 0.5, UpperBound: 1, Count: 2},
+			{Scale: "milliseconds", LowerBound: 1, UpperBound: 2, Count: 2},
+			{Scale: "milliseconds", LowerBound: 2, UpperBound: math.MaxFloat64, Count: 0},
+		}),
+	}
+	b, err := rs.HDRHistogram()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `       Value     Percentile TotalCount 1/(1-Percentile)
+
+       0.500 0.000000000000          0           1.00
+       1.000 0.500000000000          2           2.00
+       2.000 1.000000000000          4
+#[Mean    =        1.125, StdDeviation   =        0.375]
+#[Max     =        2.000, Total count    =            4]
+#[Buckets =            4, SubBuckets     =            1]
+`
+	if string(b) != expected {
+		t.Fatalf("expected\n%s\ngot\n%s", expected, string(b))
+	}
+
+	if _, err = (RequestsSummary{}).HDRHistogram(); err == nil {
+		t.Fatal("expected error for empty histogram")
+	}
+}
+
+func TestHDRRecorder(t *testing.T) {
+	if _, err := NewHDRRecorder(0); err == nil {
+		t.Fatal("expected error for zero significant figures")
+	}
+	r, err := NewHDRRecorder(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Buckets() != nil {
+		t.Fatal("expected nil buckets for empty recorder")
+	}
+	// 32 sub-buckets, exact below 32 microseconds
+	r.Record(10 * time.Microsecond)
+	r.Record(100 * time.Microsecond)
+	r.Record(5 * time.Millisecond)
+	r.Record(5 * time.Millisecond)
+	expected := HistogramBuckets{
+		{Scale: ScaleMilliseconds, LowerBound: 0, UpperBound: 0.01, Count: 0},
+		{Scale: ScaleMilliseconds, LowerBound: 0.01, UpperBound: 0.011, Count: 1},
+		{Scale: ScaleMilliseconds, LowerBound: 0.011, UpperBound: 0.1, Count: 0},
+		{Scale: ScaleMilliseconds, LowerBound: 0.1, UpperBound: 0.104, Count: 1},
+		{Scale: ScaleMilliseconds, LowerBound: 0.104, UpperBound: 4.864, Count: 0},
+		{Scale: ScaleMilliseconds, LowerBound: 4.864, UpperBound: 5.12, Count: 2},
+		{Scale: ScaleMilliseconds, LowerBound: 5.12, UpperBound: math.MaxFloat64, Count: 0},
+	}
+	buckets := r.Buckets()
+	if !reflect.DeepEqual(buckets, expected) {
+		t.Fatalf("expected %v, got %v", expected, buckets)
+	}
+	if err = buckets.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if r.Total() != 4 || buckets.Total() != 4 {
+		t.Fatalf("unexpected totals %d, %d", r.Total(), buckets.Total())
+	}
+
+	// tail latencies stay within 0.1% with 3 significant figures,
+	// while the fixed buckets would record 4.096 to 8.192 sec in one
+	ds := make(Durations, 0, 1000)
+	for i := 1; i <= 1000; i++ {
+		ds = append(ds, time.Duration(i)*10*time.Millisecond)
+	}
+	buckets, err = HDRHistogramBuckets(DefaultHDRSignificantFigures, ds)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lower, upper, err := buckets.PercentileRange(0.999)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lower > 9990 || upper < 9990 || (upper-lower)/lower > 0.001 {
+		t.Fatalf("unexpected p99.9 range [%f, %f]", lower, upper)
+	}
+
+	// sparse buckets of workers are aligned without losing precision
+	merged, err := MergeHistograms(expected, buckets)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if merged.Total() != 1004 || len(merged) < len(buckets) {
+		t.Fatalf("unexpected merged histogram %d buckets, total %d", len(merged), merged.Total())
+	}
+}
+
+func TestThroughputRecorder(t *testing.T) {
+	var nilRecorder *ThroughputRecorder
+	nilRecorder.Start()
+	nilRecorder.Begin()
+	nilRecorder.Done(nil)
+	nilRecorder.Stop()
+	if len(nilRecorder.Series("x").Samples) != 0 {
+		t.Fatal("unexpected samples from nil recorder")
+	}
+	if NewThroughputRecorder(0) != nil {
+		t.Fatal("expected nil recorder for zero interval")
+	}
+
+	r := NewThroughputRecorder(time.Hour)
+	now := time.Now()
+	r.last = now
+	for i := 0; i < 4; i++ {
+		r.Begin()
+	}
+	r.Done(nil)
+	r.Done(nil)
+	r.Done(fmt.Errorf("throttled"))
+	r.sample(now.Add(2 * time.Second))
+	r.Done(nil)
+	r.sample(now.Add(4 * time.Second))
+	r.sample(now.Add(6 * time.Second))
+
+	series := r.Series("x")
+	if series.TestID != "x" || series.Interval != time.Hour || len(series.Samples) != 3 {
+		t.Fatalf("unexpected series %+v", series)
+	}
+	s0, s1, s2 := series.Samples[0], series.Samples[1], series.Samples[2]
+	if s0.Requests != 3 || s0.Failures != 1 || s0.QPS != 1.5 || s0.InFlight != 1 || s0.MaxInFlight != 4 {
+		t.Fatalf("unexpected sample %+v", s0)
+	}
+	if s0.ErrorRate < 0.333 || s0.ErrorRate > 0.334 {
+		t.Fatalf("unexpected error rate %v", s0.ErrorRate)
+	}
+	if s1.Requests != 1 || s1.Failures != 0 || s1.QPS != 0.5 || s1.InFlight != 0 || s1.MaxInFlight != 1 {
+		t.Fatalf("unexpected sample %+v", s1)
+	}
+	if s2.Requests != 0 || s2.QPS != 0 || s2.ErrorRate != 0 || s2.MaxInFlight != 0 {
+		t.Fatalf("unexpected sample %+v", s2)
+	}
+	if !strings.Contains(series.JSON(), `"max-in-flight":4`) {
+		t.Fatalf("unexpected JSON %s", series.JSON())
+	}
+
+	// stop records the last partial interval
+	r = NewThroughputRecorder(time.Hour)
+	r.Start()
+	r.Begin()
+	r.Done(nil)
+	r.Stop()
+	r.Stop()
+	if samples := r.Series("").Samples; len(samples) != 1 || samples[0].Requests != 1 {
+		t.Fatalf("unexpected samples %+v", samples)
+	}
+}
+
+func TestLatencyRecorder(t *testing.T) {
+	r := NewLatencyRecorder(1, 10, 100)
+	r.Observe(500*time.Microsecond, nil)
+	r.Observe(5*time.Millisecond, nil)
+	r.Observe(10*time.Millisecond, fmt.Errorf("throttled"))
+	r.Observe(time.Second, nil)
+
+	rs := r.Summary("test")
+	if rs.SuccessTotal != 3 || rs.FailureTotal != 1 {
+		t.Fatalf("unexpected totals %v/%v", rs.SuccessTotal, rs.FailureTotal)
+	}
+	if err := rs.LatencyHistogram.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	expected := HistogramBuckets{
+		{Scale: ScaleMilliseconds, LowerBound: 0, UpperBound: 1, Count: 1},
+		{Scale: ScaleMilliseconds, LowerBound: 1, UpperBound: 10, Count: 2},
+		{Scale: ScaleMilliseconds, LowerBound: 10, UpperBound: 100, Count: 0},
+		{Scale: ScaleMilliseconds, LowerBound: 100, UpperBound: math.MaxFloat64, Count: 1},
+	}
+	if !reflect.DeepEqual(rs.LatencyHistogram, expected) {
+		t.Fatalf("expected %v, got %v", expected, rs.LatencyHistogram)
+	}
+	if rs.LantencyP50 != 10*time.Millisecond {
+		t.Fatalf("unexpected p50 %v", rs.LantencyP50)
+	}
+
+	if len(NewLatencyRecorder().Summary("").LatencyHistogram) != 17 {
+		t.Fatal("unexpected default buckets")
+	}
+}
+
+func TestRequestsSummaryCompareTo(t *testing.T) {
+	baseline := RequestsSummary{
+		TestID:       "baseline",
+		SuccessTotal: 995,
+		FailureTotal: 5,
+		LantencyP50:  10 * time.Millisecond,
+		LantencyP90:  20 * time.Millisecond,
+		LantencyP99:  100 * time.Millisecond,
+	}
+	th := RegressionThresholds{MaxSuccessRateDropPercent: 1, MaxLatencyIncreasePercent: 20}
+
+	cur := baseline
+	cur.TestID = "current"
+	cur.SuccessTotal, cur.FailureTotal = 990, 10
+	cur.LantencyP50 = 11 * time.Millisecond
+	if r := cur.CompareTo(baseline, th); r.Regressed() || r.Err() != nil {
+		t.Fatalf("unexpected regressions %+v", r.Regressions)
+	}
+
+	cur.SuccessTotal, cur.FailureTotal = 980, 20
+	cur.LantencyP99 = 150 * time.Millisecond
+	cur.LantencyP95 = 500 * time.Millisecond // no baseline
+	r := cur.CompareTo(baseline, th)
+	if !r.Regressed() || r.Err() == nil {
+		t.Fatal("expected regressions")
+	}
+	if len(r.Regressions) != 2 {
+		t.Fatalf("unexpected regressions %+v", r.Regressions)
+	}
+	if r.Regressions[0].Metric != "success-rate" || math.Abs(r.Regressions[0].Delta-1.5) > 0.001 {
+		t.Fatalf("unexpected regression %+v", r.Regressions[0])
+	}
+	if r.Regressions[1].Metric != "latency-p99" || math.Abs(r.Regressions[1].Delta-50) > 0.001 {
+		t.Fatalf("unexpected regression %+v", r.Regressions[1])
+	}
+
+	// zero thresholds disable the checks
+	if r = cur.CompareTo(baseline, RegressionThresholds{}); r.Regressed() {
+		t.Fatalf("unexpected regressions %+v", r.Regressions)
+	}
+}
+
+func TestSLOEvaluate(t *testing.T) {
+	writes := RequestsSummary{
+		TestID:       "writes",
+		SuccessTotal: 995,
+		FailureTotal: 5,
+		LantencyP99:  80 * time.Millisecond,
+	}
+	reads := RequestsSummary{
+		TestID:       "reads",
+		SuccessTotal: 900,
+		FailureTotal: 100,
+		LantencyP99:  150 * time.Millisecond,
+	}
+	slo := SLO{MaxErrorRatePercent: 1, MaxLatencyP99: 100 * time.Millisecond}
+
+	v := slo.Evaluate(map[string]RequestsSummary{"RequestsSummaryWrites": writes, "RequestsSummaryEmpty": {}})
+	if !v.Passed() || v.Err() != nil {
+		t.Fatalf("unexpected verdict %+v", v)
+	}
+	if !reflect.DeepEqual(v.Evaluated, []string{"RequestsSummaryWrites"}) {
+		t.Fatalf("unexpected evaluated %v", v.Evaluated)
+	}
+
+	v = slo.Evaluate(map[string]RequestsSummary{"RequestsSummaryWrites": writes, "RequestsSummaryReads": reads})
+	if v.Passed() || v.Result != SLOResultFailed || v.Err() == nil {
+		t.Fatalf("unexpected verdict %+v", v)
+	}
+	if len(v.Violations) != 2 {
+		t.Fatalf("unexpected violations %+v", v.Violations)
+	}
+	if v.Violations[0].Summary != "RequestsSummaryReads" || v.Violations[0].Metric != "error-rate" || math.Abs(v.Violations[0].Current-10) > 0.001 {
+		t.Fatalf("unexpected violation %+v", v.Violations[0])
+	}
+	if v.Violations[1].Metric != "latency-p99" || v.Violations[1].Current != 150 || v.Violations[1].Objective != 100 {
+		t.Fatalf("unexpected violation %+v", v.Violations[1])
+	}
+
+	// no result evaluated
+	if v = slo.Evaluate(nil); v.Result != "" {
+		t.Fatalf("unexpected verdict %+v", v)
+	}
+}
+
+func TestMetricsServer(t *testing.T) {
+	r := NewLatencyRecorder(1, 10, 100)
+	r.Observe(500*time.Microsecond, nil)
+	r.Observe(5*time.Millisecond, nil)
+	r.Observe(time.Second, fmt.Errorf("throttled"))
+
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(NewRequestsSummaryCollector("test", "waiter", ScaleMilliseconds, func() RequestsSummary { return r.Summary("test") }))
+
+	srv, err := StartServer(zap.NewExample(), "127.0.0.1:0", "/metrics", reg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer srv.Stop()
+
+	resp, err := http.Get("http://" + srv.Addr() + "/metrics")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, exp := range []string{
+		"test_waiter_requests_success_total 2",
+		"test_waiter_requests_failure_total 1",
+		`test_waiter_request_latency_milliseconds_bucket{le="1"} 1`,
+		`test_waiter_request_latency_milliseconds_bucket{le="100"} 2`,
+		`test_waiter_request_latency_milliseconds_bucket{le="+Inf"} 3`,
+		"test_waiter_request_latency_milliseconds_count 3",
+	} {
+		if !strings.Contains(string(b), exp) {
+			t.Fatalf("expected %q in\n%s", exp, string(b))
+		}
+	}
+}
+
+func TestCloudWatchSinkDatums(t *testing.T) {
+	sink := &CloudWatchSink{Dimensions: map[string]string{"TestSuite": "nightly", "ClusterName": "my-cluster"}}
+	rs := RequestsSummary{
+		SuccessTotal: 9,
+		FailureTotal: 1,
+		LantencyP50:  10 * time.Millisecond,
+		LantencyP99:  1500 * time.Microsecond,
+	}
+	datums := sink.Datums("AddOnStresserLocal.RequestsSummaryWrites", rs, time.Now())
+	expected := map[string]float64{
+		CloudWatchMetricSuccessTotal: 9,
+		CloudWatchMetricFailureTotal: 1,
+		CloudWatchMetricSuccessRate:  90,
+		CloudWatchMetricLatencyP50:   10,
+		CloudWatchMetricLatencyP90:   0,
+		CloudWatchMetricLatencyP95:   0,
+		CloudWatchMetricLatencyP99:   1.5,
+		CloudWatchMetricLatencyP999:  0,
+	}
+	if len(datums) != len(expected) {
+		t.Fatalf("expected %d datums, got %d", len(expected), len(datums))
+	}
+	for _, d := range datums {
+		if v, ok := expected[*d.MetricName]; !ok || v != *d.Value {
+			t.Fatalf("unexpected datum %s=%v", *d.MetricName, *d.Value)
+		}
+		var dims []string
+		for _, dim := range d.Dimensions {
+			dims = append(dims, *dim.Name+"="+*dim.Value)
+		}
+		if !reflect.DeepEqual(dims, []string{"ClusterName=my-cluster", "TestSuite=nightly", "Summary=AddOnStresserLocal.RequestsSummaryWrites"}) {
+			t.Fatalf("unexpected dimensions %v", dims)
+		}
+	}
+
+	// no latency recorded
+	if datums = sink.Datums("empty", RequestsSummary{}, time.Now()); len(datums) != 2 {
+		t.Fatalf("expected 2 datums, got %d", len(datums))
+	}
+}
+
+func TestOTLPExporter(t *testing.T) {
+	r := NewLatencyRecorder(1, 10, 100)
+	r.Observe(500*time.Microsecond, nil)
+	r.Observe(5*time.Millisecond, nil)
+	r.Observe(time.Second, fmt.Errorf("throttled"))
+
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(NewRequestsSummaryCollector("test", "waiter", ScaleMilliseconds, func() RequestsSummary { return r.Summary("test") }))
+
+	reqc := make(chan otlpRequest, 10)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if v := req.Header.Get("Authorization"); v != "Bearer abc" {
+			t.Errorf("unexpected Authorization header %q", v)
+		}
+		if v := req.Header.Get("Content-Type"); v != "application/json" {
+			t.Errorf("unexpected Content-Type header %q", v)
+		}
+		var body otlpRequest
+		if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+			t.Error(err)
+		}
+		reqc <- body
+	}))
+	defer ts.Close()
+
+	e := &OTLPExporter{
+		Logger:             zap.NewExample(),
+		Endpoint:           ts.URL + "/v1/metrics",
+		Headers:            map[string]string{"Authorization": "Bearer abc"},
+		ResourceAttributes: map[string]string{"service.name": "aws-k8s-tester"},
+		Interval:           time.Hour,
+		Gatherer:           reg,
+	}
+	if err := e.Start(); err != nil {
+		t.Fatal(err)
+	}
+	e.Stop()
+
+	var body otlpRequest
+	select {
+	case body = <-reqc:
+	default:
+		t.Fatal("expected final export on stop")
+	}
+	if len(body.ResourceMetrics) != 1 || len(body.ResourceMetrics[0].ScopeMetrics) != 1 {
+		t.Fatalf("unexpected request %+v", body)
+	}
+	attrs := body.ResourceMetrics[0].Resource.Attributes
+	if len(attrs) != 1 || attrs[0].Key != "service.name" || attrs[0].Value.StringValue != "aws-k8s-tester" {
+		t.Fatalf("unexpected resource attributes %+v", attrs)
+	}
+
+	ms := make(map[string]otlpMetric)
+	for _, m := range body.ResourceMetrics[0].ScopeMetrics[0].Metrics {
+		ms[m.Name] = m
+	}
+	success := ms["test_waiter_requests_success_total"]
+	if success.Sum == nil || !success.Sum.IsMonotonic || len(success.Sum.DataPoints) != 1 || success.Sum.DataPoints[0].AsDouble != 2 {
+		t.Fatalf("unexpected success counter %+v", success)
+	}
+	latency := ms["test_waiter_request_latency_milliseconds"]
+	if latency.Histogram == nil || len(latency.Histogram.DataPoints) != 1 {
+		t.Fatalf("unexpected latency histogram %+v", latency)
+	}
+	dp := latency.Histogram.DataPoints[0]
+	if dp.Count != "3" {
+		t.Fatalf("unexpected count %q", dp.Count)
+	}
+	if !reflect.DeepEqual(dp.ExplicitBounds, []float64{1, 10, 100}) {
+		t.Fatalf("unexpected explicit bounds %v", dp.ExplicitBounds)
+	}
+	if !reflect.DeepEqual(dp.BucketCounts, []string{"1", "1", "0", "1"}) {
+		t.Fatalf("unexpected bucket counts %v", dp.BucketCounts)
+	}
+
+	e = &OTLPExporter{Logger: zap.NewExample(), Endpoint: "localhost:4318", Interval: time.Second, Gatherer: reg}
+	if err := e.Start(); err == nil {
+		t.Fatal("expected error for invalid endpoint")
+	}
+}
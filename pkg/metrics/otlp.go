@@ -0,0 +1,331 @@
+package metrics
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"go.uber.org/zap"
+)
+
+// OTLPExporter periodically pushes the metrics of the gatherer to an
+// OpenTelemetry collector over OTLP/HTTP (JSON encoding), so that the
+// latency histograms and counters stream to any OpenTelemetry-compatible
+// backend while the tester runs, instead of only being written at the end.
+// Counters are exported as cumulative monotonic sums, gauges as gauges,
+// and histograms as cumulative explicit-bucket histograms.
+// Prometheus summaries are not exported.
+// ref. https://opentelemetry.io/docs/specs/otlp/#otlphttp
+type OTLPExporter struct {
+	Logger *zap.Logger
+	// Client is the HTTP client. Defaults to the client with 10-second timeout.
+	Client *http.Client
+	// Endpoint is the OTLP/HTTP metrics endpoint
+	// (e.g. "http://localhost:4318/v1/metrics").
+	Endpoint string
+	// Headers are the HTTP headers of every request (e.g. authentication).
+	Headers map[string]string
+	// ResourceAttributes are the resource attributes of every metric
+	// (e.g. {"service.name": "aws-k8s-tester"}).
+	ResourceAttributes map[string]string
+	// Interval is the interval to push the metrics.
+	Interval time.Duration
+	// Gatherer is the source of the metrics.
+	Gatherer prometheus.Gatherer
+
+	startTime time.Time
+	stopc     chan struct{}
+	donec     chan struct{}
+	stopOnce  sync.Once
+}
+
+// OTLPScopeName is the instrumentation scope name of the exported metrics.
+const OTLPScopeName = "github.com/aws/aws-k8s-tester/pkg/metrics"
+
+// Start starts pushing the metrics every interval.
+func (e *OTLPExporter) Start() error {
+	if e.Gatherer == nil {
+		return errors.New("nil Gatherer")
+	}
+	u, err := url.Parse(e.Endpoint)
+	if err != nil {
+		return fmt.Errorf("invalid Endpoint %q (%v)", e.Endpoint, err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("invalid Endpoint %q (expected http or https)", e.Endpoint)
+	}
+	if e.Interval <= 0 {
+		return fmt.Errorf("invalid Interval %v", e.Interval)
+	}
+	if e.Client == nil {
+		e.Client = &http.Client{Timeout: 10 * time.Second}
+	}
+	e.startTime = time.Now()
+	e.stopc = make(chan struct{})
+	e.donec = make(chan struct{})
+
+	go func() {
+		defer close(e.donec)
+		ticker := time.NewTicker(e.Interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-e.stopc:
+				return
+			case <-ticker.C:
+			}
+			if err := e.Export(); err != nil {
+				e.Logger.Warn("failed to export metrics to OTLP endpoint", zap.Error(err))
+			}
+		}
+	}()
+	e.Logger.Info("started OTLP exporter", zap.String("endpoint", e.Endpoint), zap.Duration("interval", e.Interval))
+	return nil
+}
+
+// Stop stops the periodic push, and pushes the final metrics.
+func (e *OTLPExporter) Stop() {
+	e.stopOnce.Do(func() {
+		if e.stopc == nil {
+			return
+		}
+		close(e.stopc)
+		<-e.donec
+		err := e.Export()
+		e.Logger.Info("stopped OTLP exporter", zap.Error(err))
+	})
+}
+
+// Export gathers and pushes the metrics once.
+func (e *OTLPExporter) Export() error {
+	mfs, err := e.Gatherer.Gather()
+	if err != nil && len(mfs) == 0 {
+		return err
+	}
+	if len(mfs) == 0 {
+		return nil
+	}
+	d, err := json.Marshal(e.request(mfs, time.Now()))
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, e.Endpoint, bytes.NewReader(d))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range e.Headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := e.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	body, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 1024))
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("OTLP endpoint %q returned %q (%s)", e.Endpoint, resp.Status, string(body))
+	}
+	return nil
+}
+
+// OTLP/JSON request body.
+// ref. https://github.com/open-telemetry/opentelemetry-proto/blob/main/opentelemetry/proto/metrics/v1/metrics.proto
+type otlpRequest struct {
+	ResourceMetrics []otlpResourceMetrics `json:"resourceMetrics"`
+}
+
+type otlpResourceMetrics struct {
+	Resource     otlpResource       `json:"resource"`
+	ScopeMetrics []otlpScopeMetrics `json:"scopeMetrics"`
+}
+
+type otlpResource struct {
+	Attributes []otlpKeyValue `json:"attributes,omitempty"`
+}
+
+type otlpScopeMetrics struct {
+	Scope   otlpScope    `json:"scope"`
+	Metrics []otlpMetric `json:"metrics"`
+}
+
+type otlpScope struct {
+	Name string `json:"name"`
+}
+
+type otlpKeyValue struct {
+	Key   string       `json:"key"`
+	Value otlpAnyValue `json:"value"`
+}
+
+type otlpAnyValue struct {
+	StringValue string `json:"stringValue"`
+}
+
+type otlpMetric struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description,omitempty"`
+	Sum         *otlpSum       `json:"sum,omitempty"`
+	Gauge       *otlpGauge     `json:"gauge,omitempty"`
+	Histogram   *otlpHistogram `json:"histogram,omitempty"`
+}
+
+// otlpAggregationTemporalityCumulative is "AGGREGATION_TEMPORALITY_CUMULATIVE".
+const otlpAggregationTemporalityCumulative = 2
+
+type otlpSum struct {
+	DataPoints             []otlpNumberDataPoint `json:"dataPoints"`
+	AggregationTemporality int                   `json:"aggregationTemporality"`
+	IsMonotonic            bool                  `json:"isMonotonic"`
+}
+
+type otlpGauge struct {
+	DataPoints []otlpNumberDataPoint `json:"dataPoints"`
+}
+
+type otlpHistogram struct {
+	DataPoints             []otlpHistogramDataPoint `json:"dataPoints"`
+	AggregationTemporality int                      `json:"aggregationTemporality"`
+}
+
+// 64-bit integers are encoded as strings in OTLP/JSON.
+type otlpNumberDataPoint struct {
+	Attributes        []otlpKeyValue `json:"attributes,omitempty"`
+	StartTimeUnixNano string         `json:"startTimeUnixNano,omitempty"`
+	TimeUnixNano      string         `json:"timeUnixNano"`
+	AsDouble          float64        `json:"asDouble"`
+}
+
+type otlpHistogramDataPoint struct {
+	Attributes        []otlpKeyValue `json:"attributes,omitempty"`
+	StartTimeUnixNano string         `json:"startTimeUnixNano"`
+	TimeUnixNano      string         `json:"timeUnixNano"`
+	Count             string         `json:"count"`
+	Sum               float64        `json:"sum"`
+	BucketCounts      []string       `json:"bucketCounts"`
+	ExplicitBounds    []float64      `json:"explicitBounds"`
+}
+
+func (e *OTLPExporter) request(mfs []*dto.MetricFamily, now time.Time) otlpRequest {
+	start := strconv.FormatInt(e.startTime.UnixNano(), 10)
+	ts := strconv.FormatInt(now.UnixNano(), 10)
+
+	ms := make([]otlpMetric, 0, len(mfs))
+	for _, mf := range mfs {
+		m := otlpMetric{Name: mf.GetName(), Description: mf.GetHelp()}
+		switch mf.GetType() {
+		case dto.MetricType_COUNTER:
+			m.Sum = &otlpSum{AggregationTemporality: otlpAggregationTemporalityCumulative, IsMonotonic: true}
+			for _, pm := range mf.GetMetric() {
+				m.Sum.DataPoints = append(m.Sum.DataPoints, otlpNumberDataPoint{
+					Attributes:        otlpLabels(pm.GetLabel()),
+					StartTimeUnixNano: start,
+					TimeUnixNano:      ts,
+					AsDouble:          pm.GetCounter().GetValue(),
+				})
+			}
+
+		case dto.MetricType_GAUGE, dto.MetricType_UNTYPED:
+			m.Gauge = &otlpGauge{}
+			for _, pm := range mf.GetMetric() {
+				v := pm.GetGauge().GetValue()
+				if mf.GetType() == dto.MetricType_UNTYPED {
+					v = pm.GetUntyped().GetValue()
+				}
+				m.Gauge.DataPoints = append(m.Gauge.DataPoints, otlpNumberDataPoint{
+					Attributes:   otlpLabels(pm.GetLabel()),
+					TimeUnixNano: ts,
+					AsDouble:     v,
+				})
+			}
+
+		case dto.MetricType_HISTOGRAM:
+			m.Histogram = &otlpHistogram{AggregationTemporality: otlpAggregationTemporalityCumulative}
+			for _, pm := range mf.GetMetric() {
+				dp := otlpHistogramPoint(pm.GetHistogram())
+				dp.Attributes = otlpLabels(pm.GetLabel())
+				dp.StartTimeUnixNano = start
+				dp.TimeUnixNano = ts
+				m.Histogram.DataPoints = append(m.Histogram.DataPoints, dp)
+			}
+
+		default:
+			continue
+		}
+		ms = append(ms, m)
+	}
+
+	return otlpRequest{
+		ResourceMetrics: []otlpResourceMetrics{
+			{
+				Resource: otlpResource{Attributes: otlpAttributes(e.ResourceAttributes)},
+				ScopeMetrics: []otlpScopeMetrics{
+					{
+						Scope:   otlpScope{Name: OTLPScopeName},
+						Metrics: ms,
+					},
+				},
+			},
+		},
+	}
+}
+
+// otlpHistogramPoint converts the Prometheus cumulative buckets
+// to the OTLP per-bucket counts, where the last count is of the
+// open-ended top bucket (upper than the last explicit bound).
+func otlpHistogramPoint(h *dto.Histogram) otlpHistogramDataPoint {
+	dp := otlpHistogramDataPoint{
+		Count:          strconv.FormatUint(h.GetSampleCount(), 10),
+		Sum:            h.GetSampleSum(),
+		BucketCounts:   make([]string, 0, len(h.GetBucket())+1),
+		ExplicitBounds: make([]float64, 0, len(h.GetBucket())),
+	}
+	prev := uint64(0)
+	for _, b := range h.GetBucket() {
+		if math.IsInf(b.GetUpperBound(), 1) {
+			continue
+		}
+		dp.ExplicitBounds = append(dp.ExplicitBounds, b.GetUpperBound())
+		dp.BucketCounts = append(dp.BucketCounts, strconv.FormatUint(b.GetCumulativeCount()-prev, 10))
+		prev = b.GetCumulativeCount()
+	}
+	dp.BucketCounts = append(dp.BucketCounts, strconv.FormatUint(h.GetSampleCount()-prev, 10))
+	return dp
+}
+
+func otlpLabels(lps []*dto.LabelPair) []otlpKeyValue {
+	if len(lps) == 0 {
+		return nil
+	}
+	kvs := make([]otlpKeyValue, 0, len(lps))
+	for _, lp := range lps {
+		kvs = append(kvs, otlpKeyValue{Key: lp.GetName(), Value: otlpAnyValue{StringValue: lp.GetValue()}})
+	}
+	return kvs
+}
+
+// otlpAttributes returns the sorted attributes, for deterministic requests.
+func otlpAttributes(m map[string]string) []otlpKeyValue {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	kvs := make([]otlpKeyValue, 0, len(keys))
+	for _, k := range keys {
+		kvs = append(kvs, otlpKeyValue{Key: k, Value: otlpAnyValue{StringValue: m[k]}})
+	}
+	return kvs
+}
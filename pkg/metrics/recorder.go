@@ -0,0 +1,99 @@
+package metrics
+
+import (
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+// LatencyRecorder records request latencies into a "RequestsSummary",
+// for callers that do not register Prometheus histograms
+// (e.g. EKS API calls made by the waiters). It is safe for concurrent use.
+type LatencyRecorder struct {
+	mu        sync.Mutex
+	start     time.Time
+	bounds    []float64
+	counts    []uint64
+	success   float64
+	failure   float64
+	latencies Durations
+}
+
+// NewLatencyRecorder returns a new recorder with the upper bounds
+// in milliseconds (e.g. 1, 2, 4, ...). The open-ended top bucket is added.
+// If no bound is given, the exponential bounds from 1 ms to 32 sec are used.
+func NewLatencyRecorder(boundsMs ...float64) *LatencyRecorder {
+	if len(boundsMs) == 0 {
+		boundsMs = make([]float64, 16)
+		for i := range boundsMs {
+			boundsMs[i] = math.Pow(2, float64(i))
+		}
+	}
+	bounds := make([]float64, len(boundsMs))
+	copy(bounds, boundsMs)
+	sort.Float64s(bounds)
+	return &LatencyRecorder{
+		start:  time.Now(),
+		bounds: bounds,
+		counts: make([]uint64, len(bounds)+1),
+	}
+}
+
+// Observe records the latency of one request.
+// Failed requests are counted, and their latencies are recorded as well.
+func (r *LatencyRecorder) Observe(took time.Duration, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err == nil {
+		r.success++
+	} else {
+		r.failure++
+	}
+	r.latencies = append(r.latencies, took)
+
+	ms := float64(took) / float64(time.Millisecond)
+	idx := sort.Search(len(r.bounds), func(i int) bool { return ms <= r.bounds[i] })
+	r.counts[idx]++
+}
+
+// Summary returns the summary of the recorded requests, with the test ID.
+// "TotalDuration" is the time since the recorder was created.
+func (r *LatencyRecorder) Summary(testID string) RequestsSummary {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	rs := RequestsSummary{
+		TestID:           testID,
+		SuccessTotal:     r.success,
+		FailureTotal:     r.failure,
+		TotalDuration:    time.Since(r.start),
+		LatencyHistogram: make(HistogramBuckets, len(r.counts)),
+	}
+	lower := 0.0
+	for idx, cnt := range r.counts {
+		upper := math.MaxFloat64
+		if idx < len(r.bounds) {
+			upper = r.bounds[idx]
+		}
+		rs.LatencyHistogram[idx] = HistogramBucket{
+			Scale:      ScaleMilliseconds,
+			LowerBound: lower,
+			UpperBound: upper,
+			Count:      cnt,
+		}
+		lower = upper
+	}
+
+	ds := make(Durations, len(r.latencies))
+	copy(ds, r.latencies)
+	sort.Sort(ds)
+	rs.LantencyP50 = ds.PickLantencyP50()
+	rs.LantencyP90 = ds.PickLantencyP90()
+	rs.LantencyP95 = ds.PickLantencyP95()
+	rs.LantencyP99 = ds.PickLantencyP99()
+	rs.LantencyP999 = ds.PickLantencyP999()
+	rs.LantencyP9999 = ds.PickLantencyP9999()
+	return rs
+}
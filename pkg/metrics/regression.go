@@ -0,0 +1,150 @@
+package metrics
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/olekukonko/tablewriter"
+)
+
+// RegressionThresholds defines the thresholds to detect regressions
+// against a baseline "RequestsSummary". Zero thresholds disable the checks.
+type RegressionThresholds struct {
+	// MaxSuccessRateDropPercent is the maximum drop of the success rate
+	// in percentage points (e.g. 1.0 allows 99.5 % to 98.5 %).
+	MaxSuccessRateDropPercent float64 `json:"max-success-rate-drop-percent"`
+	// MaxLatencyIncreasePercent is the maximum increase of each latency
+	// percentile (p50, p90, p95, p99, p99.9) in percent of the baseline
+	// (e.g. 20.0 allows 100 ms to 120 ms).
+	MaxLatencyIncreasePercent float64 `json:"max-latency-increase-percent"`
+}
+
+// Regression is a metric that regressed beyond its threshold.
+type Regression struct {
+	// Metric is the metric name (e.g. "success-rate", "latency-p99").
+	Metric string `json:"metric"`
+	// Baseline is the baseline value, in percent for the success rate,
+	// and in milliseconds for the latencies.
+	Baseline float64 `json:"baseline"`
+	// Current is the current value, in the same unit as "Baseline".
+	Current float64 `json:"current"`
+	// Delta is the drop in percentage points for the success rate,
+	// and the increase in percent of the baseline for the latencies.
+	Delta float64 `json:"delta"`
+	// Threshold is the threshold that "Delta" exceeded.
+	Threshold float64 `json:"threshold"`
+}
+
+// RegressionReport is the result of "RequestsSummary.CompareTo".
+type RegressionReport struct {
+	BaselineTestID string               `json:"baseline-test-id" read-only:"true"`
+	TestID         string               `json:"test-id" read-only:"true"`
+	Thresholds     RegressionThresholds `json:"thresholds" read-only:"true"`
+	Regressions    []Regression         `json:"regressions" read-only:"true"`
+}
+
+// Regressed returns true if any metric regressed beyond its threshold.
+func (r RegressionReport) Regressed() bool {
+	return len(r.Regressions) > 0
+}
+
+// Err returns the error describing the regressions,
+// or nil if there is no regression.
+func (r RegressionReport) Err() error {
+	if !r.Regressed() {
+		return nil
+	}
+	ss := make([]string, 0, len(r.Regressions))
+	for _, v := range r.Regressions {
+		ss = append(ss, fmt.Sprintf("%s %.3f -> %.3f (delta %.3f > threshold %.3f)", v.Metric, v.Baseline, v.Current, v.Delta, v.Threshold))
+	}
+	return fmt.Errorf("%q regressed from baseline %q: %s", r.TestID, r.BaselineTestID, strings.Join(ss, ", "))
+}
+
+func (r RegressionReport) JSON() string {
+	b, _ := json.Marshal(r)
+	return string(b)
+}
+
+func (r RegressionReport) Table() string {
+	buf := bytes.NewBuffer(nil)
+	tb := tablewriter.NewWriter(buf)
+	tb.SetAutoWrapText(false)
+	tb.SetColWidth(1500)
+	tb.SetCenterSeparator("*")
+	tb.SetAlignment(tablewriter.ALIGN_CENTER)
+	tb.SetCaption(true, fmt.Sprintf("(%d regression(s) from baseline %q to %q)", len(r.Regressions), r.BaselineTestID, r.TestID))
+	tb.SetHeader([]string{"Metric", "Baseline", "Current", "Delta", "Threshold"})
+	for _, v := range r.Regressions {
+		tb.Append([]string{
+			v.Metric,
+			fmt.Sprintf("%.3f", v.Baseline),
+			fmt.Sprintf("%.3f", v.Current),
+			fmt.Sprintf("%.3f", v.Delta),
+			fmt.Sprintf("%.3f", v.Threshold),
+		})
+	}
+	tb.Render()
+	return buf.String()
+}
+
+// CompareTo compares the summary against the baseline (e.g. a prior run),
+// and reports the metrics that regressed beyond the thresholds.
+// Latency percentiles are compared as in "Percentiles", and a percentile
+// is skipped if the baseline has no value for it.
+func (rs RequestsSummary) CompareTo(baseline RequestsSummary, thresholds RegressionThresholds) RegressionReport {
+	r := RegressionReport{
+		BaselineTestID: baseline.TestID,
+		TestID:         rs.TestID,
+		Thresholds:     thresholds,
+	}
+
+	if thresholds.MaxSuccessRateDropPercent > 0 && baseline.SuccessTotal+baseline.FailureTotal > 0 {
+		a, b := baseline.SuccessRate()*100.0, rs.SuccessRate()*100.0
+		if drop := a - b; drop > thresholds.MaxSuccessRateDropPercent {
+			r.Regressions = append(r.Regressions, Regression{
+				Metric:    "success-rate",
+				Baseline:  a,
+				Current:   b,
+				Delta:     drop,
+				Threshold: thresholds.MaxSuccessRateDropPercent,
+			})
+		}
+	}
+
+	if thresholds.MaxLatencyIncreasePercent > 0 {
+		a, b := baseline.Percentiles(), rs.Percentiles()
+		for _, v := range []struct {
+			metric string
+			a, b   float64
+		}{
+			{"latency-p50", toMilliseconds(a.P50), toMilliseconds(b.P50)},
+			{"latency-p90", toMilliseconds(a.P90), toMilliseconds(b.P90)},
+			{"latency-p95", toMilliseconds(a.P95), toMilliseconds(b.P95)},
+			{"latency-p99", toMilliseconds(a.P99), toMilliseconds(b.P99)},
+			{"latency-p99.9", toMilliseconds(a.P999), toMilliseconds(b.P999)},
+		} {
+			if v.a <= 0 {
+				continue
+			}
+			if increase := (v.b - v.a) / v.a * 100.0; increase > thresholds.MaxLatencyIncreasePercent {
+				r.Regressions = append(r.Regressions, Regression{
+					Metric:    v.metric,
+					Baseline:  v.a,
+					Current:   v.b,
+					Delta:     increase,
+					Threshold: thresholds.MaxLatencyIncreasePercent,
+				})
+			}
+		}
+	}
+
+	return r
+}
+
+func toMilliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
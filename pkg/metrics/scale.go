@@ -0,0 +1,81 @@
+package metrics
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	// ScaleMicroseconds is the histogram scale in microseconds.
+	ScaleMicroseconds = "microseconds"
+	// ScaleMilliseconds is the histogram scale in milliseconds.
+	ScaleMilliseconds = "milliseconds"
+	// ScaleSeconds is the histogram scale in seconds.
+	ScaleSeconds = "seconds"
+)
+
+// scaleUnits maps the canonical scale to its unit duration.
+var scaleUnits = map[string]time.Duration{
+	ScaleMicroseconds: time.Microsecond,
+	ScaleMilliseconds: time.Millisecond,
+	ScaleSeconds:      time.Second,
+}
+
+// scaleAliases maps the known spellings to the canonical scale.
+var scaleAliases = map[string]string{
+	"us":           ScaleMicroseconds,
+	"µs":           ScaleMicroseconds,
+	"microsecond":  ScaleMicroseconds,
+	"microseconds": ScaleMicroseconds,
+	"ms":           ScaleMilliseconds,
+	"millisecond":  ScaleMilliseconds,
+	"milliseconds": ScaleMilliseconds,
+	"s":            ScaleSeconds,
+	"sec":          ScaleSeconds,
+	"second":       ScaleSeconds,
+	"seconds":      ScaleSeconds,
+}
+
+// NormalizeScale returns the canonical scale (e.g. "ms" to "milliseconds"),
+// or an error if the scale is unknown.
+func NormalizeScale(scale string) (string, error) {
+	v, ok := scaleAliases[strings.ToLower(strings.TrimSpace(scale))]
+	if !ok {
+		return "", fmt.Errorf("unknown histogram scale %q", scale)
+	}
+	return v, nil
+}
+
+// Scale returns the scale shared by all buckets, or an error if
+// the buckets disagree or the scale is not one of the known constants.
+// It returns an empty string for empty buckets.
+func (buckets HistogramBuckets) Scale() (string, error) {
+	if len(buckets) == 0 {
+		return "", nil
+	}
+	scale := buckets[0].Scale
+	switch scale {
+	case ScaleMicroseconds, ScaleMilliseconds, ScaleSeconds:
+	default:
+		return "", fmt.Errorf("bucket 0 has unknown scale %q", scale)
+	}
+	for idx, cur := range buckets {
+		if cur.Scale != scale {
+			return "", fmt.Errorf("bucket %d scale %q != bucket 0 scale %q", idx, cur.Scale, scale)
+		}
+	}
+	return scale, nil
+}
+
+// normalizeScales rewrites the bucket scales to the canonical ones in place.
+func (buckets HistogramBuckets) normalizeScales() error {
+	for idx := range buckets {
+		scale, err := NormalizeScale(buckets[idx].Scale)
+		if err != nil {
+			return fmt.Errorf("bucket %d (%v)", idx, err)
+		}
+		buckets[idx].Scale = scale
+	}
+	return nil
+}
@@ -0,0 +1,150 @@
+package metrics
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/olekukonko/tablewriter"
+)
+
+// SLO defines the service level objectives of "RequestsSummary" results.
+// Zero objectives disable the checks.
+type SLO struct {
+	// MaxErrorRatePercent is the maximum ratio of failed requests
+	// in percent (e.g. 1.0 allows 1 failure out of 100 requests).
+	MaxErrorRatePercent float64 `json:"max-error-rate-percent"`
+	// MaxLatencyP99 is the maximum p99 latency (see "Percentiles").
+	MaxLatencyP99 time.Duration `json:"max-latency-p99"`
+}
+
+// IsZero returns true if no objective is set.
+func (slo SLO) IsZero() bool {
+	return slo.MaxErrorRatePercent <= 0 && slo.MaxLatencyP99 <= 0
+}
+
+const (
+	// SLOResultPassed is the verdict when all results met the objectives.
+	SLOResultPassed = "passed"
+	// SLOResultFailed is the verdict when any result violated the objectives.
+	SLOResultFailed = "failed"
+)
+
+// SLOViolation is a result metric that violated its objective.
+type SLOViolation struct {
+	// Summary is the name of the violating result (e.g. "RequestsSummaryWrites").
+	Summary string `json:"summary"`
+	// Metric is the metric name (e.g. "error-rate", "latency-p99").
+	Metric string `json:"metric"`
+	// Current is the result value, in percent for the error rate,
+	// and in milliseconds for the latency.
+	Current float64 `json:"current"`
+	// Objective is the objective that "Current" exceeded,
+	// in the same unit as "Current".
+	Objective float64 `json:"objective"`
+}
+
+// SLOVerdict is the result of "SLO.Evaluate".
+type SLOVerdict struct {
+	// Result is "passed" or "failed", or empty if no result was evaluated.
+	Result     string         `json:"result" read-only:"true"`
+	Objectives SLO            `json:"objectives" read-only:"true"`
+	Evaluated  []string       `json:"evaluated" read-only:"true"`
+	Violations []SLOViolation `json:"violations" read-only:"true"`
+}
+
+// Passed returns true if all results met the objectives.
+func (v SLOVerdict) Passed() bool {
+	return v.Result == SLOResultPassed
+}
+
+// Err returns the error describing the violations,
+// or nil if all results met the objectives.
+func (v SLOVerdict) Err() error {
+	if len(v.Violations) == 0 {
+		return nil
+	}
+	ss := make([]string, 0, len(v.Violations))
+	for _, vv := range v.Violations {
+		ss = append(ss, fmt.Sprintf("%s %s %.3f > objective %.3f", vv.Summary, vv.Metric, vv.Current, vv.Objective))
+	}
+	return fmt.Errorf("violated SLO: %s", strings.Join(ss, ", "))
+}
+
+func (v SLOVerdict) JSON() string {
+	b, _ := json.Marshal(v)
+	return string(b)
+}
+
+func (v SLOVerdict) Table() string {
+	buf := bytes.NewBuffer(nil)
+	tb := tablewriter.NewWriter(buf)
+	tb.SetAutoWrapText(false)
+	tb.SetColWidth(1500)
+	tb.SetCenterSeparator("*")
+	tb.SetAlignment(tablewriter.ALIGN_CENTER)
+	tb.SetCaption(true, fmt.Sprintf("(SLO %s, %d violation(s) of %d result(s))", v.Result, len(v.Violations), len(v.Evaluated)))
+	tb.SetHeader([]string{"Summary", "Metric", "Current", "Objective"})
+	for _, vv := range v.Violations {
+		tb.Append([]string{
+			vv.Summary,
+			vv.Metric,
+			fmt.Sprintf("%.3f", vv.Current),
+			fmt.Sprintf("%.3f", vv.Objective),
+		})
+	}
+	tb.Render()
+	return buf.String()
+}
+
+// Evaluate evaluates the results keyed by name against the objectives.
+// Results without any request are skipped, and the p99 latency objective
+// is skipped for the results without any recorded latency.
+func (slo SLO) Evaluate(summaries map[string]RequestsSummary) SLOVerdict {
+	names := make([]string, 0, len(summaries))
+	for name := range summaries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	v := SLOVerdict{Objectives: slo}
+	for _, name := range names {
+		rs := summaries[name]
+		if rs.SuccessTotal+rs.FailureTotal == 0 {
+			continue
+		}
+		v.Evaluated = append(v.Evaluated, name)
+
+		if slo.MaxErrorRatePercent > 0 {
+			if cur := rs.FailureRate() * 100.0; cur > slo.MaxErrorRatePercent {
+				v.Violations = append(v.Violations, SLOViolation{
+					Summary:   name,
+					Metric:    "error-rate",
+					Current:   cur,
+					Objective: slo.MaxErrorRatePercent,
+				})
+			}
+		}
+		if slo.MaxLatencyP99 > 0 {
+			pct := rs.Percentiles()
+			if pct.Source != "" && pct.P99 > slo.MaxLatencyP99 {
+				v.Violations = append(v.Violations, SLOViolation{
+					Summary:   name,
+					Metric:    "latency-p99",
+					Current:   toMilliseconds(pct.P99),
+					Objective: toMilliseconds(slo.MaxLatencyP99),
+				})
+			}
+		}
+	}
+	switch {
+	case len(v.Violations) > 0:
+		v.Result = SLOResultFailed
+	case len(v.Evaluated) > 0:
+		v.Result = SLOResultPassed
+	}
+	return v
+}
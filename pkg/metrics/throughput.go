@@ -0,0 +1,184 @@
+package metrics
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+)
+
+// ThroughputSample is the throughput of one sampling interval.
+type ThroughputSample struct {
+	// Time is the end of the interval.
+	Time time.Time `json:"time"`
+	// Requests is the number of requests completed in the interval.
+	Requests uint64 `json:"requests"`
+	// Failures is the number of failed requests completed in the interval.
+	Failures uint64 `json:"failures"`
+	// QPS is the number of requests completed per second in the interval.
+	QPS float64 `json:"qps"`
+	// ErrorRate is the ratio of failed requests in [0, 1] in the interval.
+	// It is 0 if no request was completed.
+	ErrorRate float64 `json:"error-rate"`
+	// InFlight is the number of in-flight requests at the end of the interval.
+	InFlight int64 `json:"in-flight"`
+	// MaxInFlight is the maximum number of in-flight requests in the interval.
+	MaxInFlight int64 `json:"max-in-flight"`
+}
+
+// ThroughputSeries is the time series of the throughput samples,
+// to see the ramp behavior and the throttling onset of load tests
+// rather than only the end-of-run aggregates of "RequestsSummary".
+type ThroughputSeries struct {
+	// TestID is the test ID.
+	TestID string `json:"test-id"`
+	// Interval is the sampling interval.
+	Interval time.Duration `json:"interval"`
+	// Samples are the samples in time order.
+	Samples []ThroughputSample `json:"samples"`
+}
+
+// JSON returns the JSON-encoded "ThroughputSeries".
+func (s ThroughputSeries) JSON() string {
+	b, _ := json.Marshal(s)
+	return string(b)
+}
+
+// ThroughputRecorder samples the throughput of the requests every interval
+// (QPS, error rate, in-flight requests). It is safe for concurrent use,
+// and all methods are no-op on the nil recorder, so that callers can
+// disable the sampling with the nil recorder.
+type ThroughputRecorder struct {
+	interval time.Duration
+
+	mu          sync.Mutex
+	started     bool
+	last        time.Time
+	requests    uint64
+	failures    uint64
+	inFlight    int64
+	maxInFlight int64
+	samples     []ThroughputSample
+
+	stopOnce *sync.Once
+	stopc    chan struct{}
+	donec    chan struct{}
+}
+
+// NewThroughputRecorder returns a new recorder that samples every interval.
+// It returns nil if the interval is not positive.
+func NewThroughputRecorder(interval time.Duration) *ThroughputRecorder {
+	if interval <= 0 {
+		return nil
+	}
+	return &ThroughputRecorder{
+		interval: interval,
+		stopOnce: new(sync.Once),
+		stopc:    make(chan struct{}),
+		donec:    make(chan struct{}),
+	}
+}
+
+// Start starts sampling in the background until "Stop".
+func (r *ThroughputRecorder) Start() {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	r.started = true
+	r.last = time.Now()
+	r.mu.Unlock()
+	go func() {
+		defer close(r.donec)
+		ticker := time.NewTicker(r.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-r.stopc:
+				return
+			case now := <-ticker.C:
+				r.sample(now)
+			}
+		}
+	}()
+}
+
+// Stop stops sampling, and records the last partial interval if started.
+func (r *ThroughputRecorder) Stop() {
+	if r == nil {
+		return
+	}
+	r.stopOnce.Do(func() {
+		r.mu.Lock()
+		started := r.started
+		r.mu.Unlock()
+		close(r.stopc)
+		if started {
+			<-r.donec
+			r.sample(time.Now())
+		}
+	})
+}
+
+// Begin marks the start of one request.
+func (r *ThroughputRecorder) Begin() {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	r.inFlight++
+	if r.inFlight > r.maxInFlight {
+		r.maxInFlight = r.inFlight
+	}
+	r.mu.Unlock()
+}
+
+// Done marks the completion of one request started with "Begin".
+func (r *ThroughputRecorder) Done(err error) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	r.inFlight--
+	r.requests++
+	if err != nil {
+		r.failures++
+	}
+	r.mu.Unlock()
+}
+
+// sample records the interval since the last sample, and resets the counts.
+func (r *ThroughputRecorder) sample(now time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	s := ThroughputSample{
+		Time:        now.UTC(),
+		Requests:    r.requests,
+		Failures:    r.failures,
+		InFlight:    r.inFlight,
+		MaxInFlight: r.maxInFlight,
+	}
+	if took := now.Sub(r.last); took > 0 {
+		s.QPS = float64(r.requests) / took.Seconds()
+	}
+	if r.requests > 0 {
+		s.ErrorRate = float64(r.failures) / float64(r.requests)
+	}
+	r.samples = append(r.samples, s)
+
+	r.last = now
+	r.requests, r.failures = 0, 0
+	r.maxInFlight = r.inFlight
+}
+
+// Series returns the samples recorded so far, with the test ID.
+func (r *ThroughputRecorder) Series(testID string) ThroughputSeries {
+	if r == nil {
+		return ThroughputSeries{TestID: testID}
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	samples := make([]ThroughputSample, len(r.samples))
+	copy(samples, r.samples)
+	return ThroughputSeries{TestID: testID, Interval: r.interval, Samples: samples}
+}
@@ -0,0 +1,146 @@
+// Package wait implements a generic resource poller, shared by
+// the cluster, cluster update, add-on, and Fargate profile waiters.
+package wait
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+
+	"github.com/aws/aws-k8s-tester/pkg/ctxutil"
+	"github.com/aws/aws-k8s-tester/pkg/spinner"
+	"go.uber.org/zap"
+)
+
+// ErrWaitStopped is returned when the wait is stopped via stop channel.
+var ErrWaitStopped = errors.New("wait stopped")
+
+// Poller periodically describes a resource until its classifier reports
+// a terminal state. T is the status type sent to the caller, which
+// usually carries the resource and the error.
+type Poller[T any] struct {
+	// Describe fetches the resource. Required.
+	Describe func(ctx context.Context) (T, error)
+	// Classify returns the status to send for each describe result,
+	// and true if the status is terminal. Required.
+	Classify func(v T, err error) (status T, done bool)
+	// Abort returns the status to send when the context is done or
+	// the poller is stopped, with "ctx.Err()" or "ErrWaitStopped"
+	// (also for the context cancelled via "ctxutil.WithStopc"). Required.
+	Abort func(err error) T
+
+	// InitialWait returns the wait after the first successful
+	// non-terminal describe call. Defaults to no initial wait.
+	InitialWait func(status T) time.Duration
+	// PollInterval is the wait between describe calls.
+	PollInterval time.Duration
+	// NextWait overrides "PollInterval" for the next describe call
+	// (e.g. backoff). Optional.
+	NextWait func(status T) time.Duration
+	// Emit returns false to not send the non-terminal status
+	// (e.g. unchanged status). Terminal and abort statuses are
+	// always sent. Defaults to sending every status.
+	Emit func(status T) bool
+
+	// LogWriter shows a spinner with "SpinnerMessage" during the initial wait.
+	// Optional.
+	LogWriter      io.Writer
+	SpinnerMessage string
+}
+
+// Poll starts polling in a goroutine. The very first describe call is
+// made without wait, in case the resource has already reached the desired
+// state. The channel is closed after the terminal or abort status is sent.
+func (p Poller[T]) Poll(ctx context.Context, stopc chan struct{}, lg *zap.Logger) <-chan T {
+	ch := make(chan T, 10)
+	go func() {
+		defer close(ch)
+
+		// very first poll should be no-wait
+		// in case resource has already reached desired status
+		// wait from second interation
+		waitDur := time.Duration(0)
+
+		first := true
+		for ctx.Err() == nil {
+			select {
+			case <-ctx.Done():
+				lg.Warn("wait aborted, ctx done", zap.Error(ctx.Err()))
+				ch <- p.Abort(ContextErr(ctx))
+				return
+
+			case <-stopc:
+				lg.Warn("wait stopped, stopc closed", zap.Error(ctx.Err()))
+				ch <- p.Abort(ErrWaitStopped)
+				return
+
+			case <-time.After(waitDur):
+			}
+
+			v, err := p.Describe(ctx)
+			status, done := p.Classify(v, err)
+			if done {
+				ch <- status
+				return
+			}
+			if p.Emit == nil || p.Emit(status) {
+				ch <- status
+			}
+
+			waitDur = p.PollInterval
+			if p.NextWait != nil {
+				waitDur = p.NextWait(status)
+			}
+			if err != nil || !first {
+				continue
+			}
+			first = false
+
+			if p.InitialWait == nil {
+				continue
+			}
+			firstWait := p.InitialWait(status)
+			lg.Info("sleeping", zap.Duration("initial-wait", firstWait))
+			var sp *spinner.Spinner
+			if p.LogWriter != nil {
+				s := spinner.New(p.LogWriter, p.SpinnerMessage)
+				sp = &s
+				sp.Restart()
+			}
+			select {
+			case <-ctx.Done():
+				stopSpinner(sp)
+				lg.Warn("wait aborted, ctx done", zap.Error(ctx.Err()))
+				ch <- p.Abort(ContextErr(ctx))
+				return
+			case <-stopc:
+				stopSpinner(sp)
+				lg.Warn("wait stopped, stopc closed", zap.Error(ctx.Err()))
+				ch <- p.Abort(ErrWaitStopped)
+				return
+			case <-time.After(firstWait):
+				stopSpinner(sp)
+			}
+		}
+
+		lg.Warn("wait aborted, ctx done", zap.Error(ctx.Err()))
+		ch <- p.Abort(ContextErr(ctx))
+	}()
+	return ch
+}
+
+func stopSpinner(sp *spinner.Spinner) {
+	if sp != nil {
+		sp.Stop()
+	}
+}
+
+// ContextErr returns "ErrWaitStopped" if the context was cancelled
+// by the stop channel (see "ctxutil.WithStopc"), or "ctx.Err()" otherwise.
+func ContextErr(ctx context.Context) error {
+	if ctxutil.IsStopped(ctx) {
+		return ErrWaitStopped
+	}
+	return ctx.Err()
+}
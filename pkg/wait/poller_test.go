@@ -0,0 +1,276 @@
+package wait
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-k8s-tester/pkg/ctxutil"
+	"go.uber.org/zap"
+)
+
+var (
+	errThrottled = errors.New("throttled")
+	errFailed    = errors.New("failed")
+)
+
+type testStatus struct {
+	state string
+	err   error
+}
+
+func (s testStatus) String() string {
+	if s.err != nil {
+		return "error: " + s.err.Error()
+	}
+	return s.state
+}
+
+type fakeDescribe struct {
+	results []testStatus
+	calls   int
+}
+
+func (f *fakeDescribe) Describe(ctx context.Context) (testStatus, error) {
+	f.calls++
+	idx := f.calls - 1
+	if idx >= len(f.results) {
+		idx = len(f.results) - 1
+	}
+	return f.results[idx], f.results[idx].err
+}
+
+func classify(v testStatus, err error) (testStatus, bool) {
+	switch {
+	case errors.Is(err, errFailed):
+		return testStatus{err: err}, true
+	case err != nil:
+		return testStatus{err: err}, false
+	case v.state == "ACTIVE":
+		return v, true
+	case v.state == "FAILED":
+		return testStatus{state: v.state, err: errFailed}, true
+	}
+	return v, false
+}
+
+func TestPoller(t *testing.T) {
+	tt := []struct {
+		name         string
+		results      []testStatus
+		pollInterval time.Duration
+		initialWait  time.Duration
+		nextWait     time.Duration
+		emitChanged  bool
+		timeout      time.Duration
+		// stopAfter closes the stop channel after receiving the number of statuses.
+		stopAfter int
+		// withStopc closes the stop channel of the context (see "ctxutil.WithStopc"),
+		// instead of the stop channel of the poller.
+		withStopc bool
+
+		expStatuses     []string
+		expCalls        int
+		expInitialWaits int
+		expNextWaits    int
+		expAbortErr     error
+	}{
+		{
+			name:        "already in desired state",
+			results:     []testStatus{{state: "ACTIVE"}},
+			expStatuses: []string{"ACTIVE"},
+			expCalls:    1,
+		},
+		{
+			name:        "poll until desired state",
+			results:     []testStatus{{state: "CREATING"}, {state: "CREATING"}, {state: "ACTIVE"}},
+			expStatuses: []string{"CREATING", "CREATING", "ACTIVE"},
+			expCalls:    3,
+		},
+		{
+			name:        "failed state",
+			results:     []testStatus{{state: "CREATING"}, {state: "FAILED"}},
+			expStatuses: []string{"CREATING", "error: failed"},
+			expCalls:    2,
+		},
+		{
+			name:        "retry describe errors",
+			results:     []testStatus{{err: errThrottled}, {state: "CREATING"}, {err: errThrottled}, {state: "ACTIVE"}},
+			expStatuses: []string{"error: throttled", "CREATING", "error: throttled", "ACTIVE"},
+			expCalls:    4,
+		},
+		{
+			name:        "terminal describe error",
+			results:     []testStatus{{err: errThrottled}, {err: errFailed}},
+			expStatuses: []string{"error: throttled", "error: failed"},
+			expCalls:    2,
+		},
+		{
+			name:        "emit only changed statuses",
+			results:     []testStatus{{state: "CREATING"}, {state: "CREATING"}, {state: "UPDATING"}, {state: "UPDATING"}, {state: "ACTIVE"}},
+			emitChanged: true,
+			expStatuses: []string{"CREATING", "UPDATING", "ACTIVE"},
+			expCalls:    5,
+		},
+		{
+			name:            "initial wait once after first successful describe",
+			results:         []testStatus{{err: errThrottled}, {state: "CREATING"}, {state: "CREATING"}, {state: "ACTIVE"}},
+			initialWait:     time.Millisecond,
+			expStatuses:     []string{"error: throttled", "CREATING", "CREATING", "ACTIVE"},
+			expCalls:        4,
+			expInitialWaits: 1,
+		},
+		{
+			name:         "next wait overrides poll interval",
+			results:      []testStatus{{state: "CREATING"}, {state: "CREATING"}, {state: "ACTIVE"}},
+			pollInterval: time.Hour,
+			nextWait:     time.Millisecond,
+			expStatuses:  []string{"CREATING", "CREATING", "ACTIVE"},
+			expCalls:     3,
+			expNextWaits: 2,
+		},
+		{
+			name:         "stopped while polling",
+			results:      []testStatus{{state: "CREATING"}},
+			pollInterval: time.Hour,
+			stopAfter:    1,
+			expStatuses:  []string{"CREATING", "error: wait stopped"},
+			expCalls:     1,
+			expAbortErr:  ErrWaitStopped,
+		},
+		{
+			name:            "stopped during initial wait",
+			results:         []testStatus{{state: "CREATING"}},
+			initialWait:     time.Hour,
+			stopAfter:       1,
+			expStatuses:     []string{"CREATING", "error: wait stopped"},
+			expCalls:        1,
+			expInitialWaits: 1,
+			expAbortErr:     ErrWaitStopped,
+		},
+		{
+			name:         "stopped via context",
+			results:      []testStatus{{state: "CREATING"}},
+			pollInterval: time.Hour,
+			stopAfter:    1,
+			withStopc:    true,
+			expStatuses:  []string{"CREATING", "error: wait stopped"},
+			expCalls:     1,
+			expAbortErr:  ErrWaitStopped,
+		},
+		{
+			name:         "context timeout",
+			results:      []testStatus{{state: "CREATING"}},
+			pollInterval: time.Hour,
+			timeout:      10 * time.Millisecond,
+			expStatuses:  []string{"CREATING", "error: " + context.DeadlineExceeded.Error()},
+			expCalls:     1,
+			expAbortErr:  context.DeadlineExceeded,
+		},
+		{
+			name:            "context timeout during initial wait",
+			results:         []testStatus{{state: "CREATING"}},
+			initialWait:     time.Hour,
+			timeout:         10 * time.Millisecond,
+			expStatuses:     []string{"CREATING", "error: " + context.DeadlineExceeded.Error()},
+			expCalls:        1,
+			expInitialWaits: 1,
+			expAbortErr:     context.DeadlineExceeded,
+		},
+	}
+	for _, tv := range tt {
+		t.Run(tv.name, func(t *testing.T) {
+			timeout := tv.timeout
+			if timeout == 0 {
+				timeout = 10 * time.Second
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), timeout)
+			defer cancel()
+
+			stopc, pollStopc := make(chan struct{}), make(chan struct{})
+			if tv.withStopc {
+				var stopCancel context.CancelFunc
+				ctx, stopCancel = ctxutil.WithStopc(ctx, stopc)
+				defer stopCancel()
+			} else {
+				pollStopc = stopc
+			}
+
+			fd := &fakeDescribe{results: tv.results}
+			initialWaits, nextWaits := 0, 0
+			var abortErr error
+			p := Poller[testStatus]{
+				Describe: fd.Describe,
+				Classify: classify,
+				Abort: func(err error) testStatus {
+					abortErr = err
+					return testStatus{err: err}
+				},
+				PollInterval: tv.pollInterval,
+			}
+			if tv.initialWait > 0 {
+				p.InitialWait = func(testStatus) time.Duration {
+					initialWaits++
+					return tv.initialWait
+				}
+			}
+			if tv.nextWait > 0 {
+				p.NextWait = func(testStatus) time.Duration {
+					nextWaits++
+					return tv.nextWait
+				}
+			}
+			if tv.emitChanged {
+				prev := ""
+				p.Emit = func(status testStatus) bool {
+					changed := status.String() != prev
+					prev = status.String()
+					return changed
+				}
+			}
+
+			statuses := []string{}
+			for sv := range p.Poll(ctx, pollStopc, zap.NewNop()) {
+				statuses = append(statuses, sv.String())
+				if len(statuses) == tv.stopAfter {
+					close(stopc)
+				}
+			}
+
+			if !reflect.DeepEqual(statuses, tv.expStatuses) {
+				t.Fatalf("expected statuses %q, got %q", tv.expStatuses, statuses)
+			}
+			if fd.calls != tv.expCalls {
+				t.Fatalf("expected describe calls %d, got %d", tv.expCalls, fd.calls)
+			}
+			if initialWaits != tv.expInitialWaits {
+				t.Fatalf("expected initial waits %d, got %d", tv.expInitialWaits, initialWaits)
+			}
+			if nextWaits != tv.expNextWaits {
+				t.Fatalf("expected next waits %d, got %d", tv.expNextWaits, nextWaits)
+			}
+			if !errors.Is(abortErr, tv.expAbortErr) {
+				t.Fatalf("expected abort error %v, got %v", tv.expAbortErr, abortErr)
+			}
+		})
+	}
+}
+
+func TestContextErr(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := ContextErr(ctx); err != context.Canceled {
+		t.Fatalf("expected %v, got %v", context.Canceled, err)
+	}
+
+	stopc := make(chan struct{})
+	ctx, cancel = ctxutil.WithStopc(context.Background(), stopc)
+	defer cancel()
+	close(stopc)
+	<-ctx.Done()
+	if err := ContextErr(ctx); err != ErrWaitStopped {
+		t.Fatalf("expected %v, got %v", ErrWaitStopped, err)
+	}
+}